@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+var (
+	archRepo string
+	archDir  string
+)
+
+var archCmd = &cobra.Command{
+	Use:   "arch",
+	Short: "Manages the architectural summaries of indexed repositories",
+}
+
+var archRegenerateCmd = &cobra.Command{
+	Use:   "regenerate",
+	Short: "Regenerates architectural summaries even where they are up to date",
+	Long: `Regenerates the architectural summaries of a repository from its clone and
+replaces the stored ones. Indexing only regenerates the summaries of directories
+whose files changed; use this after changing the summary prompt or model, or to
+replace summaries that turned out poor.
+
+Every summary passes the same quality check as during indexing: summaries that
+are too short or repeat the prompt are retried at a higher temperature and then
+with ai.summary_fallback_model, and are not stored if they still fail.`,
+	Example: `  warden-cli arch regenerate --repo owner/app
+  warden-cli arch regenerate --repo owner/app --dir internal/billing`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		repo, err := app.RepoMgr.GetRepoRecord(ctx, archRepo)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("repository %s is not managed by Code-Warden", archRepo)
+			}
+			return fmt.Errorf("failed to retrieve repository: %w", err)
+		}
+		if _, err := os.Stat(repo.ClonePath); err != nil {
+			return fmt.Errorf("clone of %s is not available at %s: %w", archRepo, repo.ClonePath, err)
+		}
+
+		n, err := app.RAGService.RegenerateArchSummaries(ctx, repo.QdrantCollectionName, repo.Embedder(app.Cfg.AI.EmbedderModel), repo.ClonePath, archDir)
+		if err != nil {
+			return fmt.Errorf("failed to regenerate architectural summaries: %w", err)
+		}
+		slog.Info("✅ Architectural summaries regenerated", "repo", repo.FullName, "dir", archDir, "summaries", n)
+		return nil
+	},
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	archRegenerateCmd.Flags().StringVar(&archRepo, "repo", "", "Repository whose summaries to regenerate (owner/repo)")
+	archRegenerateCmd.Flags().StringVar(&archDir, "dir", "", "Only regenerate this directory, relative to the repository root, and the ones below it")
+	_ = archRegenerateCmd.MarkFlagRequired("repo")
+	archCmd.AddCommand(archRegenerateCmd)
+	rootCmd.AddCommand(archCmd)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/suppress"
+)
+
+var baselineFile string
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline [pr-url]",
+	Short: "Accept the current findings of a Pull Request in a review baseline",
+	Long: `Review a GitHub Pull Request and write every finding to a baseline file.
+
+Findings listed in the repository's ` + suppress.BaselineFile + ` are left out
+of later reviews, both on GitHub and in the CLI. Commit the file to the default
+branch to accept known issues when adopting Code-Warden on an existing codebase.
+The existing baseline is ignored while reviewing, so running the command again
+regenerates it from scratch.
+
+Examples:
+  warden-cli baseline https://github.com/owner/repo/pull/123
+  warden-cli baseline --file path/to/repo/` + suppress.BaselineFile + ` https://github.com/owner/repo/pull/123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBaseline,
+}
+
+func init() { //nolint:gochecknoinits // Cobra command registration
+	baselineCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output with timing information")
+	baselineCmd.Flags().StringVar(&baselineFile, "file", suppress.BaselineFile, "Path of the baseline file to write")
+	rootCmd.AddCommand(baselineCmd)
+}
+
+func runBaseline(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+	prURL := args[0]
+
+	timer := newStepTimer(5, verbose)
+	printHeader(prURL)
+
+	timer.step("Initializing application")
+	appInstance, cleanup, err := initializeReviewApp(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	timer.done()
+
+	review, err := executeReviewFlow(ctx, appInstance, prURL, true, timer)
+	if err != nil {
+		return err
+	}
+
+	baseline := suppress.NewBaseline(review.Suggestions)
+	if err := baseline.WriteFile(baselineFile); err != nil {
+		return err
+	}
+
+	//nolint:gosec // CLI output, errors are intentionally ignored
+	successColor.Printf("✅ Wrote %d accepted finding(s) to %s\n", len(baseline.Findings), baselineFile)
+	return nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/gitutil"
+	ragreview "github.com/sevigo/code-warden/internal/rag/review"
+	"github.com/sevigo/code-warden/internal/report"
+	reviewpkg "github.com/sevigo/code-warden/internal/review"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+var (
+	checkStaged  bool
+	checkBase    string
+	checkPrePush bool
+	checkRemote  string
+	checkFailOn  string
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Review local changes before they are committed or pushed",
+	Long: `Review the changes of the local repository in the current directory.
+
+With --staged the changes staged for the next commit are reviewed, with --base
+the commits on HEAD since it forked from the given ref, like a pull request
+into it. With --pre-push the refs a pre-push hook receives on stdin are
+reviewed: each from the commit the remote has to the pushed one, new
+branches since they forked from the default branch of --remote. The repository must be registered (warden-term /add) so that its
+index provides the context; changes since the last scan are not indexed.
+
+Findings are printed to the terminal. The command exits with code 2 when any
+suggestion has the --fail-on severity or higher, and with code 1 on errors.
+The hooks of 'warden-cli hook install' run it.
+
+Examples:
+  warden-cli check --staged
+  warden-cli check --staged --fail-on critical
+  warden-cli check --base origin/main --output json`,
+	Args: cobra.NoArgs,
+	RunE: runCheck,
+}
+
+func init() { //nolint:gochecknoinits // Cobra command registration
+	checkCmd.Flags().BoolVar(&checkStaged, "staged", false, "Review the changes staged for the next commit")
+	checkCmd.Flags().StringVar(&checkBase, "base", "", "Review the commits on HEAD since it forked from this ref")
+	checkCmd.Flags().BoolVar(&checkPrePush, "pre-push", false, "Review the refs a pre-push hook reads from stdin")
+	checkCmd.Flags().StringVar(&checkRemote, "remote", "origin", "Remote pushed to, for --pre-push")
+	checkCmd.Flags().StringVar(&checkFailOn, "fail-on", "high",
+		"Exit with code 2 if any suggestion has this severity or higher (low, medium, high, critical)")
+	checkCmd.Flags().StringVarP(&reviewOutput, "output", "o", report.FormatText,
+		"Output format: "+strings.Join(report.Formats, ", "))
+	checkCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output with timing information")
+	checkCmd.MarkFlagsMutuallyExclusive("staged", "base", "pre-push")
+	checkCmd.MarkFlagsOneRequired("staged", "base", "pre-push")
+	rootCmd.AddCommand(checkCmd)
+}
+
+func runCheck(cmd *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	if !slices.Contains(report.Formats, reviewOutput) {
+		return fmt.Errorf("invalid --output %q, expected one of %s", reviewOutput, strings.Join(report.Formats, ", "))
+	}
+	failOn, err := report.ParseSeverity(checkFailOn)
+	if err != nil {
+		return fmt.Errorf("invalid --fail-on: %w", err)
+	}
+	if reviewOutput != report.FormatText {
+		color.Output = color.Error
+		if err := os.Setenv("LOGGING_OUTPUT", "stderr"); err != nil {
+			return fmt.Errorf("failed to redirect logs: %w", err)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	root, err := gitutil.RepoRoot(ctx, wd)
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	timer := newStepTimer(3, verbose)
+	//nolint:gosec // CLI output, errors are intentionally ignored
+	titleColor.Fprintln(color.Output, "🔍 Code Warden - Local Check")
+
+	timer.step("Computing diff")
+	target, diff, err := localDiff(ctx, root)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(diff) == "" {
+		//nolint:gosec // CLI output
+		successColor.Fprintf(color.Output, "✅ No %s to review.\n", target)
+		return nil
+	}
+	timer.done()
+
+	return reviewLocalChanges(ctx, cmd, root, target, diff, failOn, timer)
+}
+
+// localDiff returns what --staged, --base or --pre-push selects in the
+// repository at root, and its diff.
+func localDiff(ctx context.Context, root string) (string, string, error) {
+	if checkPrePush {
+		refs, err := gitutil.ParsePushedRefs(os.Stdin)
+		if err != nil {
+			return "", "", err
+		}
+		diff, err := gitutil.PushDiff(ctx, root, checkRemote, refs)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to diff pushed commits: %w", err)
+		}
+		return "pushed changes", diff, nil
+	}
+	if checkStaged {
+		diff, err := gitutil.StagedDiff(ctx, root)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to diff staged changes: %w", err)
+		}
+		return "staged changes", diff, nil
+	}
+	diff, err := gitutil.DiffRange(ctx, root, checkBase, "HEAD")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to diff against %s: %w", checkBase, err)
+	}
+	return "changes since " + checkBase, diff, nil
+}
+
+// reviewLocalChanges reviews diff, the target changes of the registered
+// repository at root, with the repository's index as context, prints or
+// writes the review as --output selects and fails with exitFindings when a
+// suggestion reaches failOn, if set.
+func reviewLocalChanges(ctx context.Context, cmd *cobra.Command, root, target, diff, failOn string, timer *stepTimer) error {
+	changedFiles := ragreview.ParseDiff(diff)
+	timer.infof("Files: %d", len(changedFiles))
+
+	timer.step("Initializing application")
+	appInstance, cleanup, err := initializeReviewApp(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	repo, err := appInstance.RepoMgr.GetRepoRecordByPath(ctx, root)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("repository at %s is not registered\n\nTip: Register and index it with /add in warden-term", root)
+		}
+		return fmt.Errorf("failed to get repo record: %w", err)
+	}
+	timer.infof("Repository: %s", repo.FullName)
+	timer.done()
+
+	repoConfig, err := config.LoadRepoConfig(root)
+	if err != nil {
+		if !errors.Is(err, config.ErrConfigNotFound) {
+			appInstance.Logger.Warn("failed to parse .code-warden.yml, using defaults", "error", err)
+		}
+		repoConfig = core.DefaultRepoConfig()
+	}
+	owner, name, _ := strings.Cut(repo.FullName, "/")
+	event := &core.GitHubEvent{
+		Type:         core.FullReview,
+		RepoOwner:    owner,
+		RepoName:     name,
+		RepoFullName: repo.FullName,
+		PRTitle:      "Local review of " + target,
+		HeadSHA:      gitutil.HeadSHA(ctx, root),
+	}
+
+	timer.step("Generating review")
+	review, err := executeReview(ctx, appInstance, reviewpkg.Params{
+		RepoConfig:   repoConfig,
+		Repo:         repo,
+		Event:        event,
+		Diff:         diff,
+		ChangedFiles: changedFiles,
+	}, false, timer)
+	if err != nil {
+		return err
+	}
+	timer.done()
+
+	if err := writeReviewOutput(review, root); err != nil {
+		return err
+	}
+
+	if failOn == "" {
+		return nil
+	}
+	if n := report.CountAtLeast(review, failOn); n > 0 {
+		cmd.SilenceUsage, cmd.SilenceErrors = true, true
+		return &exitCodeError{
+			code: exitFindings,
+			msg:  fmt.Sprintf("%d suggestion(s) with severity %s or higher", n, failOn),
+		}
+	}
+	return nil
+}
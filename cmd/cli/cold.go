@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var freezeCmd = &cobra.Command{
+	Use:   "freeze [owner/repo]",
+	Short: "Move a repository's vector collection to cold storage",
+	Long: `Snapshots the repository's Qdrant collection to the cold storage directory
+and drops it from Qdrant, freeing memory and disk for rarely reviewed repositories.
+
+The collection is restored automatically the next time the repository is reviewed,
+asked about or otherwise searched, at the cost of a slower first request.
+Requires the qdrant vector store.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		if err := app.RepoMgr.FreezeRepo(ctx, args[0]); err != nil {
+			return fmt.Errorf("failed to freeze repository: %w", err)
+		}
+		slog.Info("✅ Repository moved to cold storage", "repo", args[0])
+		return nil
+	},
+}
+
+var thawCmd = &cobra.Command{
+	Use:   "thaw [owner/repo]",
+	Short: "Restore a repository's vector collection from cold storage",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		if err := app.RepoMgr.ThawRepo(ctx, args[0]); err != nil {
+			return fmt.Errorf("failed to thaw repository: %w", err)
+		}
+		slog.Info("✅ Repository restored from cold storage", "repo", args[0])
+		return nil
+	},
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	rootCmd.AddCommand(freezeCmd)
+	rootCmd.AddCommand(thawCmd)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [pr-url]",
+	Short: "Explain a GitHub Pull Request to someone new to the codebase",
+	Long: `Write a walkthrough of a GitHub Pull Request for someone unfamiliar with
+the codebase: what changed, why it likely changed, which subsystems are
+involved and in which order to read the files.
+
+The walkthrough uses the same repository context as a review but makes no
+suggestions. Comment /explain on a PR to have it posted there instead.
+
+Examples:
+  warden-cli explain https://github.com/owner/repo/pull/123`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExplain,
+}
+
+func init() { //nolint:gochecknoinits // Cobra command registration
+	explainCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output with timing information")
+	rootCmd.AddCommand(explainCmd)
+}
+
+func runExplain(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+	prURL := args[0]
+
+	timer := newStepTimer(5, verbose)
+	printHeader(prURL)
+
+	timer.step("Initializing application")
+	appInstance, cleanup, err := initializeReviewApp(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	timer.done()
+
+	event, ghClient, repo, err := preparePullRequest(ctx, appInstance, prURL, timer)
+	if err != nil {
+		return err
+	}
+	event.Type = core.ExplainPR
+
+	timer.step("Writing walkthrough")
+	diff, err := ghClient.GetPullRequestDiff(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get PR diff: %w", err)
+	}
+	changedFiles, err := ghClient.GetChangedFiles(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get changed files: %w", err)
+	}
+	if commits, err := ghClient.GetPullRequestCommits(ctx, event.RepoOwner, event.RepoName, event.PRNumber); err == nil {
+		event.CommitMessages = commits
+	}
+
+	walkthrough, err := appInstance.RAGService.GenerateWalkthrough(ctx, nil, repo, event, diff, changedFiles)
+	if err != nil {
+		return fmt.Errorf("walkthrough failed: %w\n\nTip: Check that the LLM service is running", err)
+	}
+	timer.done()
+
+	fmt.Println()
+	fmt.Println(walkthrough)
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var feedbackRepo string
+
+var feedbackCmd = &cobra.Command{
+	Use:   "feedback",
+	Short: "Shows suggestion acceptance per model and prompt version",
+	Long: `Aggregates the 👍/👎 reactions and "/warden helpful|wrong" replies recorded on
+posted review suggestions, grouped by the model and prompt version that produced them.`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		ctx := context.Background()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		metrics, err := app.Store.GetFeedbackMetrics(ctx, feedbackRepo)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve feedback metrics: %w", err)
+		}
+
+		if outputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(metrics)
+		}
+
+		if len(metrics) == 0 {
+			slog.Info("No review feedback has been recorded yet.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "MODEL\tPROMPT VERSION\tSUGGESTIONS\tHELPFUL\tWRONG\tACCEPTANCE")
+		for _, m := range metrics {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%.0f%%\n",
+				m.Model,
+				m.PromptVersion,
+				m.Suggestions,
+				m.Helpful,
+				m.Wrong,
+				m.AcceptanceRate*100,
+			)
+		}
+		return w.Flush()
+	},
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	feedbackCmd.Flags().StringVar(&feedbackRepo, "repo", "", "Limit metrics to one repository (owner/repo)")
+	feedbackCmd.Flags().BoolVar(&outputJSON, "json", false, "Output metrics as JSON")
+	rootCmd.AddCommand(feedbackCmd)
+}
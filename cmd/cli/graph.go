@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/archgraph"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+var (
+	graphFormat string
+	graphOutput string
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [owner/repo]",
+	Short: "Exports the architecture graph of a repository",
+	Long: `Exports the directories of an indexed repository, their architectural
+summaries and the imports between them.
+
+Formats:
+  dot       Graphviz source; summaries become node tooltips
+  mermaid   Mermaid flowchart source
+  markdown  Onboarding report with an embedded Mermaid diagram and every
+            directory summary, rendered inline by GitHub
+
+Examples:
+  warden-cli graph owner/repo --format dot | dot -Tsvg > architecture.svg
+  warden-cli graph owner/repo --format markdown --output ARCHITECTURE.md`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		repo, err := app.RepoMgr.GetRepoRecord(ctx, args[0])
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("repository %s is not managed by Code-Warden", args[0])
+			}
+			return fmt.Errorf("failed to retrieve repository: %w", err)
+		}
+
+		graph, err := app.RAGService.ArchGraph(ctx, repo.QdrantCollectionName, repo.Embedder(app.Cfg.AI.EmbedderModel), repo.ClonePath)
+		if err != nil {
+			return fmt.Errorf("failed to build architecture graph: %w", err)
+		}
+
+		var out string
+		if graphFormat == "json" {
+			data, err := json.MarshalIndent(graph, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode graph: %w", err)
+			}
+			out = string(data) + "\n"
+		} else if out, err = graph.Render(graphFormat, repo.FullName); err != nil {
+			return err
+		}
+
+		if graphOutput == "" {
+			_, err = os.Stdout.WriteString(out)
+			return err
+		}
+		if err := os.WriteFile(graphOutput, []byte(out), 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", graphOutput, err)
+		}
+		return nil
+	},
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	graphCmd.Flags().StringVar(&graphFormat, "format", archgraph.FormatMermaid, "Output format: json, "+strings.Join(archgraph.Formats, ", "))
+	graphCmd.Flags().StringVarP(&graphOutput, "output", "o", "", "Write to a file instead of stdout")
+	rootCmd.AddCommand(graphCmd)
+}
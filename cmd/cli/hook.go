@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/gitutil"
+	"github.com/sevigo/code-warden/internal/report"
+)
+
+var (
+	hookType   string
+	hookForce  bool
+	hookFailOn string
+)
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manages the git hooks that review local changes",
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Installs a git hook that runs 'warden-cli check'",
+	Long: `Installs a git hook in the repository of the current directory.
+
+The pre-commit hook reviews the staged changes, the pre-push hook the commits
+each pushed ref adds to the remote. Findings with the --fail-on severity or higher
+reject the commit or push; when the review itself fails, for example because
+the LLM is not reachable, the hook only warns. Skip it once with --no-verify.
+
+Examples:
+  warden-cli hook install
+  warden-cli hook install --type pre-push --fail-on critical`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		ctx := context.Background()
+		failOn, err := report.ParseSeverity(hookFailOn)
+		if err != nil {
+			return fmt.Errorf("invalid --fail-on: %w", err)
+		}
+		wd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		hooksDir, err := gitutil.HooksDir(ctx, wd)
+		if err != nil {
+			return fmt.Errorf("not in a git repository: %w", err)
+		}
+		// The hook runs this binary by its path, git hooks do not see the
+		// PATH of an interactive shell in every client.
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate warden-cli: %w", err)
+		}
+		selection := "--staged"
+		if hookType == gitutil.HookPrePush {
+			// git passes the remote as $1 and the pushed refs on stdin.
+			selection = `--pre-push --remote "$1"`
+		}
+		command := fmt.Sprintf("%s check %s --fail-on %s", shellQuote(exe), selection, strings.ToLower(failOn))
+
+		path, err := gitutil.InstallHook(hooksDir, hookType, command, hookForce)
+		if err != nil {
+			return err
+		}
+		//nolint:gosec // CLI output
+		successColor.Printf("✓ Installed %s hook at %s\n", hookType, path)
+		return nil
+	},
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	hookInstallCmd.Flags().StringVar(&hookType, "type", gitutil.HookPreCommit,
+		"Hook to install: "+gitutil.HookPreCommit+" or "+gitutil.HookPrePush)
+	hookInstallCmd.Flags().BoolVar(&hookForce, "force", false, "Replace a hook installed by another tool")
+	hookInstallCmd.Flags().StringVar(&hookFailOn, "fail-on", "high",
+		"Reject the commit or push on suggestions with this severity or higher (low, medium, high, critical)")
+	hookCmd.AddCommand(hookInstallCmd)
+	rootCmd.AddCommand(hookCmd)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Inspects, exports and imports the code index of repositories",
+}
+
+var indexStatsCmd = &cobra.Command{
+	Use:   "stats [owner/repo]",
+	Short: "Shows what the last full index of a repository covers",
+	Long: `Shows the files of a repository by language, how many of them are indexed,
+the average number of chunks per indexed file and why the other files were left
+out. The stats are recorded by every full index of the repository.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		repo, err := app.Store.GetRepositoryByFullName(ctx, args[0])
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("repository %s is not managed by Code-Warden", args[0])
+			}
+			return fmt.Errorf("failed to retrieve repository: %w", err)
+		}
+
+		stats, err := app.Store.GetIndexStats(ctx, repo.ID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				slog.Info("No index stats recorded yet, they are computed by the next full index.", "repo", args[0])
+				return nil
+			}
+			return fmt.Errorf("failed to retrieve index stats: %w", err)
+		}
+
+		if outputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(stats)
+		}
+
+		fmt.Printf("Indexed %d of %d files into %d chunks (%.1f per file) on %s in %s.\n\n",
+			stats.IndexedFiles, stats.Files, stats.Chunks, stats.AvgChunksPerFile,
+			stats.ComputedAt.Format(time.RFC822), (time.Duration(stats.DurationMs) * time.Millisecond).Round(time.Second))
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "LANGUAGE\tFILES\tINDEXED\tCHUNKS\tAVG CHUNKS")
+		for _, lang := range stats.Languages {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%.1f\n",
+				lang.Language,
+				lang.Files,
+				lang.IndexedFiles,
+				lang.Chunks,
+				lang.AvgChunksPerFile,
+			)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		if len(stats.Skipped) == 0 {
+			return nil
+		}
+		reasons := make([]string, 0, len(stats.Skipped))
+		for reason := range stats.Skipped {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "SKIPPED BECAUSE\tFILES")
+		for _, reason := range reasons {
+			fmt.Fprintf(w, "%s\t%d\n", reason, stats.Skipped[reason])
+		}
+		return w.Flush()
+	},
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	indexStatsCmd.Flags().BoolVar(&outputJSON, "json", false, "Output stats as JSON")
+	indexCmd.AddCommand(indexStatsCmd)
+	rootCmd.AddCommand(indexCmd)
+}
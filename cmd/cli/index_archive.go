@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/indexarchive"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+var (
+	indexExportOutput string
+	indexImportPath   string
+	indexImportServer string
+)
+
+var indexExportCmd = &cobra.Command{
+	Use:   "export [owner/repo]",
+	Short: "Writes a repository's index to a portable archive",
+	Long: `Writes the points of a repository's Qdrant collection, vectors and payload,
+together with its repository record and indexed files, to a gzip-compressed
+archive. Importing the archive elsewhere makes the index usable there without
+embedding the code again, e.g. to build it once in CI and load it on developer
+machines.`,
+	Example: `  warden-cli index export owner/app -o app.index.gz`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		if !app.Cfg.Storage.UsesQdrant() {
+			return fmt.Errorf("index export requires the qdrant vector store, not %q", app.Cfg.Storage.VectorStoreProvider)
+		}
+
+		output := indexExportOutput
+		if output == "" {
+			output = strings.ReplaceAll(args[0], "/", "-") + ".index.gz"
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create archive: %w", err)
+		}
+		exporter := storage.NewQdrantExporter(app.Cfg.Storage.QdrantHTTPURL)
+		manifest, err := indexarchive.Export(ctx, f, app.Store, exporter, args[0], app.Cfg.AI.EmbedderModel)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(output)
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("repository %s is not managed by Code-Warden", args[0])
+			}
+			return fmt.Errorf("failed to export index: %w", err)
+		}
+
+		slog.Info("✅ Index exported",
+			"repo", manifest.Repo,
+			"file", output,
+			"points", manifest.Points,
+			"files", len(manifest.Files),
+			"embedder", manifest.EmbedderModel,
+		)
+		return nil
+	},
+}
+
+var indexImportCmd = &cobra.Command{
+	Use:   "import [archive]",
+	Short: "Loads a repository's index from an archive",
+	Long: `Loads an archive written by "index export". The repository's Qdrant collection
+is replaced by the archived points and its record is created, or updated, with
+the indexed commit and files of the archive, so the next scan only indexes what
+changed since.
+
+Queries against the imported index use the embedder model it was built with,
+which must therefore be available here too.
+
+A running server keeps the search results it cached for the repository for up
+to storage.query_cache.ttl. With --server, they are dropped through its admin
+API, authenticated with server.admin_token.`,
+	Example: `  warden-cli index import app.index.gz --path ~/src/app
+  warden-cli index import app.index.gz --server https://warden.example.com`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		if !app.Cfg.Storage.UsesQdrant() {
+			return fmt.Errorf("index import requires the qdrant vector store, not %q", app.Cfg.Storage.VectorStoreProvider)
+		}
+
+		clonePath := indexImportPath
+		if clonePath != "" {
+			if clonePath, err = filepath.Abs(clonePath); err != nil {
+				return fmt.Errorf("failed to resolve path: %w", err)
+			}
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer f.Close()
+
+		exporter := storage.NewQdrantExporter(app.Cfg.Storage.QdrantHTTPURL)
+		manifest, err := indexarchive.Import(ctx, f, app.Store, exporter, clonePath, app.Logger)
+		if err != nil {
+			return fmt.Errorf("failed to import index: %w", err)
+		}
+		if manifest.EmbedderModel != app.Cfg.AI.EmbedderModel {
+			slog.Warn("The index was built with another embedder model than the configured one; it stays in use for this repository.",
+				"index", manifest.EmbedderModel,
+				"configured", app.Cfg.AI.EmbedderModel,
+			)
+		}
+
+		slog.Info("✅ Index imported",
+			"repo", manifest.Repo,
+			"sha", manifest.LastIndexedSHA,
+			"points", manifest.Points,
+			"files", len(manifest.Files),
+		)
+		if indexImportServer == "" {
+			slog.Info("A running server may serve cached search results for the repository until storage.query_cache.ttl passes; pass --server to drop them.")
+			return nil
+		}
+		if err := invalidateServerQueryCache(ctx, http.DefaultClient, indexImportServer, app.Cfg.Server.AdminToken, manifest.Repo); err != nil {
+			return fmt.Errorf("index imported, but failed to drop the cached searches of the server: %w", err)
+		}
+		slog.Info("Dropped the cached searches of the server", "server", indexImportServer)
+		return nil
+	},
+}
+
+// invalidateServerQueryCache asks the server at serverURL to drop its cached
+// searches of repo through the admin API.
+func invalidateServerQueryCache(ctx context.Context, client *http.Client, serverURL, adminToken, repo string) error {
+	if adminToken == "" {
+		return errors.New("server.admin_token is not configured")
+	}
+	endpoint := strings.TrimSuffix(serverURL, "/") + "/api/v1/admin/query-cache?" + url.Values{"repo": {repo}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("server answered %s", resp.Status)
+	}
+	return nil
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	indexExportCmd.Flags().StringVarP(&indexExportOutput, "output", "o", "", "Archive to write (default owner-repo.index.gz)")
+	indexImportCmd.Flags().StringVar(&indexImportPath, "path", "", "Local checkout of the repository, recorded as its clone path")
+	indexImportCmd.Flags().StringVar(&indexImportServer, "server", "", "URL of a running server whose cached searches of the repository to drop")
+	indexCmd.AddCommand(indexExportCmd)
+	indexCmd.AddCommand(indexImportCmd)
+}
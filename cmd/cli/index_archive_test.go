@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidateServerQueryCache(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		if r.URL.Query().Get("repo") == "owner/missing" {
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+
+	require.NoError(t, invalidateServerQueryCache(ctx, srv.Client(), srv.URL+"/", "t0ken", "owner/app"))
+	assert.Equal(t, http.MethodDelete, got.Method)
+	assert.Equal(t, "/api/v1/admin/query-cache", got.URL.Path)
+	assert.Equal(t, "owner/app", got.URL.Query().Get("repo"))
+	assert.Equal(t, "Bearer t0ken", got.Header.Get("Authorization"))
+
+	assert.ErrorContains(t, invalidateServerQueryCache(ctx, srv.Client(), srv.URL, "t0ken", "owner/missing"), "404")
+	assert.ErrorContains(t, invalidateServerQueryCache(ctx, srv.Client(), srv.URL, "", "owner/app"), "server.admin_token")
+}
@@ -1,12 +1,33 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
 	"os"
+
+	"github.com/sevigo/code-warden/internal/sandbox"
 )
 
+// exitCodeError ends the CLI with a specific exit code instead of 1, for
+// outcomes that CI pipelines act on rather than failures of the command.
+type exitCodeError struct {
+	code int
+	msg  string
+}
+
+func (e *exitCodeError) Error() string {
+	return e.msg
+}
+
 func main() {
+	sandbox.ServeParseWorker()
 	if err := Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			fmt.Fprintln(os.Stderr, exitErr.msg)
+			os.Exit(exitErr.code)
+		}
 		slog.Error("cli failed to run", "error", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+var (
+	notifyRepo      string
+	notifyPerReview bool
+	notifyDigest    bool
+)
+
+var notifyCmd = &cobra.Command{
+	Use:   "notify",
+	Short: "Manage email notification subscriptions",
+	Long: `Subscribes email addresses to a message for every completed review and to
+the daily digest of reviews, critical and high findings and failed jobs.
+
+Subscriptions are per repository, or for all repositories without --repo.
+Messages are only sent with notifications.email.enabled set.`,
+}
+
+var notifySubscribeCmd = &cobra.Command{
+	Use:   "subscribe <email>",
+	Short: "Subscribe an address, or change its subscription",
+	Example: `  warden-cli notify subscribe alice@example.com --digest
+  warden-cli notify subscribe bob@example.com --repo owner/app --per-review --digest`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		addr, err := mail.ParseAddress(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid email address %q: %w", args[0], err)
+		}
+		if !notifyPerReview && !notifyDigest {
+			return errors.New("choose --per-review, --digest or both")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		sub := &storage.EmailSubscription{
+			Email:        addr.Address,
+			RepoFullName: notifyRepo,
+			PerReview:    notifyPerReview,
+			Digest:       notifyDigest,
+		}
+		if err := app.Store.UpsertEmailSubscription(ctx, sub); err != nil {
+			return err
+		}
+		fmt.Printf("Subscribed %s to %s of %s.\n", sub.Email, notifyKinds(sub), notifyScope(sub.RepoFullName))
+		if !app.Cfg.Notifications.Email.Enabled {
+			fmt.Println("Note: notifications.email.enabled is off, no messages are sent.")
+		}
+		return nil
+	},
+}
+
+var notifyUnsubscribeCmd = &cobra.Command{
+	Use:   "unsubscribe <email>",
+	Short: "Remove the subscription of an address",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		if err := app.Store.DeleteEmailSubscription(ctx, args[0], notifyRepo); err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("%s has no subscription to %s", args[0], notifyScope(notifyRepo))
+			}
+			return err
+		}
+		fmt.Printf("Unsubscribed %s from %s.\n", args[0], notifyScope(notifyRepo))
+		return nil
+	},
+}
+
+var notifyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the subscriptions",
+	Args:  cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		subs, err := app.Store.ListEmailSubscriptions(ctx)
+		if err != nil {
+			return err
+		}
+		if outputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(subs)
+		}
+		if len(subs) == 0 {
+			fmt.Println("No subscriptions.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "EMAIL\tREPOSITORY\tPER REVIEW\tDIGEST\tLAST DIGEST")
+		for _, s := range subs {
+			last := "-"
+			if s.LastDigestAt != nil {
+				last = s.LastDigestAt.Format(time.RFC822)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%t\t%t\t%s\n", s.Email, notifyScope(s.RepoFullName), s.PerReview, s.Digest, last)
+		}
+		return w.Flush()
+	},
+}
+
+func notifyScope(repo string) string {
+	if repo == "" {
+		return "all repositories"
+	}
+	return repo
+}
+
+func notifyKinds(sub *storage.EmailSubscription) string {
+	switch {
+	case sub.PerReview && sub.Digest:
+		return "review messages and the daily digest"
+	case sub.PerReview:
+		return "review messages"
+	default:
+		return "the daily digest"
+	}
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	notifySubscribeCmd.Flags().StringVar(&notifyRepo, "repo", "", "Limit the subscription to one repository (owner/repo)")
+	notifySubscribeCmd.Flags().BoolVar(&notifyPerReview, "per-review", false, "Send a message for every completed review")
+	notifySubscribeCmd.Flags().BoolVar(&notifyDigest, "digest", false, "Include the repositories in the daily digest")
+	notifyUnsubscribeCmd.Flags().StringVar(&notifyRepo, "repo", "", "Repository of the subscription (owner/repo)")
+	notifyListCmd.Flags().BoolVar(&outputJSON, "json", false, "Output the subscriptions as JSON")
+	notifyCmd.AddCommand(notifySubscribeCmd, notifyUnsubscribeCmd, notifyListCmd)
+	rootCmd.AddCommand(notifyCmd)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	reviewpkg "github.com/sevigo/code-warden/internal/review"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+var (
+	regenerateModel      string
+	regeneratePromptFile string
+)
+
+var regenerateCmd = &cobra.Command{
+	Use:   "regenerate <review-id>",
+	Short: "Replays the inputs of a stored review with another model or prompt",
+	Long: `Replays the archived prompt inputs of a stored review (diff, retrieved context
+and the other template data) with another model or prompt template and prints
+the original and the regenerated review side by side.
+
+The inputs are archived with every review while ai.archive_review_inputs is on.
+Split reviews are compared group by group, consensus reviews against the
+synthesized answer.
+
+Examples:
+  warden-cli review regenerate 42 --model qwen2.5-coder:32b
+  warden-cli review regenerate 42 --prompt-file my_code_review.prompt`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRegenerate,
+}
+
+func init() { //nolint:gochecknoinits // Cobra command registration
+	regenerateCmd.Flags().StringVar(&regenerateModel, "model", "", "Model to regenerate with (default: the generator model)")
+	regenerateCmd.Flags().StringVar(&regeneratePromptFile, "prompt-file", "", "Prompt template to use instead of the archived one")
+	regenerateCmd.Flags().BoolVar(&outputJSON, "json", false, "Output the comparison as JSON")
+	reviewCmd.AddCommand(regenerateCmd)
+}
+
+func runRegenerate(_ *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid review ID %q: %w", args[0], err)
+	}
+	var promptTemplate string
+	if regeneratePromptFile != "" {
+		data, err := os.ReadFile(regeneratePromptFile)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt file: %w", err)
+		}
+		promptTemplate = string(data)
+	}
+
+	app, cleanup, err := InitializeApp(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	review, err := app.Store.GetReview(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("review %d not found", id)
+		}
+		return fmt.Errorf("failed to retrieve review: %w", err)
+	}
+	inputs, err := app.Store.GetReviewInputs(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("no inputs archived for review %d, it predates the archive or ai.archive_review_inputs is off", id)
+		}
+		return fmt.Errorf("failed to retrieve review inputs: %w", err)
+	}
+
+	comparisons := make([]*reviewpkg.Comparison, 0, len(inputs))
+	for _, in := range inputs {
+		original, regenerated, err := app.RAGService.RegenerateReview(ctx, in, regenerateModel, promptTemplate)
+		if err != nil {
+			return err
+		}
+		c := reviewpkg.Compare(original, regenerated)
+		c.Group = in.Group
+		comparisons = append(comparisons, c)
+	}
+
+	if outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(comparisons)
+	}
+
+	fmt.Printf("# Review %d of %s PR #%d at %s", review.ID, review.RepoFullName, review.PRNumber, truncateSHA(review.HeadSHA))
+	if review.TreeSHA != "" {
+		fmt.Printf(" (tree %s)", truncateSHA(review.TreeSHA))
+	}
+	fmt.Print("\n\n")
+	for _, c := range comparisons {
+		fmt.Println(c.Markdown())
+	}
+	return nil
+}
@@ -3,6 +3,9 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -13,12 +16,22 @@ import (
 	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/gitutil"
+	"github.com/sevigo/code-warden/internal/report"
 	reviewpkg "github.com/sevigo/code-warden/internal/review"
 	"github.com/sevigo/code-warden/internal/storage"
 	"github.com/sevigo/code-warden/internal/stringsutil"
 )
 
-var verbose bool
+var (
+	verbose          bool
+	reviewOutput     string
+	reviewOutputFile string
+	reviewFailOn     string
+)
+
+// exitFindings is the exit code when --fail-on finds suggestions at or above
+// the threshold. Errors exit with 1.
+const exitFindings = 2
 
 // Color definitions for terminal output.
 var (
@@ -38,15 +51,26 @@ var reviewCmd = &cobra.Command{
 The review command fetches the PR diff, builds context from the repository's
 vector store, and uses an LLM to generate a structured code review.
 
+With --output junit, checkstyle or json the review is written as a
+machine-readable report for CI pipelines, to stdout or --output-file. With
+--fail-on the command exits with code 2 when any suggestion has that severity
+or higher, and with code 1 on errors.
+
 Examples:
   warden-cli review https://github.com/owner/repo/pull/123
-  warden-cli review --verbose https://github.com/owner/repo/pull/123`,
+  warden-cli review --verbose https://github.com/owner/repo/pull/123
+  warden-cli review --output junit --output-file review.xml --fail-on high https://github.com/owner/repo/pull/123`,
 	Args: cobra.ExactArgs(1),
 	RunE: runReview,
 }
 
 func init() { //nolint:gochecknoinits // Cobra command registration
 	reviewCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output with timing information")
+	reviewCmd.Flags().StringVarP(&reviewOutput, "output", "o", report.FormatText,
+		"Output format: "+strings.Join(report.Formats, ", "))
+	reviewCmd.Flags().StringVar(&reviewOutputFile, "output-file", "", "Write the report to a file instead of stdout")
+	reviewCmd.Flags().StringVar(&reviewFailOn, "fail-on", "",
+		"Exit with code 2 if any suggestion has this severity or higher (low, medium, high, critical)")
 	rootCmd.AddCommand(reviewCmd)
 }
 
@@ -56,6 +80,8 @@ type stepTimer struct {
 	totalSteps int
 	start      time.Time
 	verbose    bool
+	// quiet suppresses the step names, for reviews that run concurrently.
+	quiet bool
 }
 
 func newStepTimer(totalSteps int, verboseMode bool) *stepTimer {
@@ -69,11 +95,13 @@ func newStepTimer(totalSteps int, verboseMode bool) *stepTimer {
 func (t *stepTimer) step(name string) {
 	t.stepNum++
 	t.start = time.Now()
-	if t.verbose {
+	switch {
+	case t.quiet:
+	case t.verbose:
 		//nolint:gosec // CLI output, errors are intentionally ignored
 		titleColor.Printf("\n🔧 Step %d/%d: %s...\n", t.stepNum, t.totalSteps, name)
-	} else {
-		fmt.Printf("%s...\n", name)
+	default:
+		fmt.Fprintf(color.Output, "%s...\n", name)
 	}
 }
 
@@ -92,10 +120,31 @@ func (t *stepTimer) infof(format string, args ...any) {
 	}
 }
 
-func runReview(_ *cobra.Command, args []string) error {
+func runReview(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 	prURL := args[0]
 
+	if !slices.Contains(report.Formats, reviewOutput) {
+		return fmt.Errorf("invalid --output %q, expected one of %s", reviewOutput, strings.Join(report.Formats, ", "))
+	}
+	var failOn string
+	if reviewFailOn != "" {
+		var err error
+		if failOn, err = report.ParseSeverity(reviewFailOn); err != nil {
+			return fmt.Errorf("invalid --fail-on: %w", err)
+		}
+	}
+	if reviewOutput == report.FormatText && reviewOutputFile != "" {
+		return fmt.Errorf("--output-file requires --output %s", strings.Join(report.Formats[1:], ", "))
+	}
+	if reviewOutput != report.FormatText && reviewOutputFile == "" {
+		// Keep stdout clean for the report; progress and logs go to stderr.
+		color.Output = color.Error
+		if err := os.Setenv("LOGGING_OUTPUT", "stderr"); err != nil {
+			return fmt.Errorf("failed to redirect logs: %w", err)
+		}
+	}
+
 	timer := newStepTimer(5, verbose)
 	overallStart := time.Now()
 
@@ -111,7 +160,7 @@ func runReview(_ *cobra.Command, args []string) error {
 	timer.done()
 
 	// 2-5. Execute Review Flow
-	review, err := executeReviewFlow(ctx, appInstance, prURL, timer)
+	review, err := executeReviewFlow(ctx, appInstance, prURL, false, timer)
 	if err != nil {
 		return err
 	}
@@ -122,7 +171,53 @@ func runReview(_ *cobra.Command, args []string) error {
 		dimColor.Printf("\n⏱️  Total time: %s\n", time.Since(overallStart).Round(time.Millisecond))
 	}
 
+	if err := writeReviewOutput(review, prURL); err != nil {
+		return err
+	}
+
+	if failOn != "" {
+		if n := report.CountAtLeast(review, failOn); n > 0 {
+			cmd.SilenceUsage, cmd.SilenceErrors = true, true
+			return &exitCodeError{
+				code: exitFindings,
+				msg:  fmt.Sprintf("%d suggestion(s) with severity %s or higher", n, failOn),
+			}
+		}
+	}
+	return nil
+}
+
+// writeReviewOutput prints the review for humans, writes the --output report,
+// or both when the report goes to --output-file.
+func writeReviewOutput(review *core.StructuredReview, prURL string) error {
+	if reviewOutput == report.FormatText {
+		printReview(review)
+		return nil
+	}
+
+	if reviewOutputFile == "" {
+		return writeReport(os.Stdout, review, prURL)
+	}
+
 	printReview(review)
+	f, err := os.Create(reviewOutputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := writeReport(f, review, prURL); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return nil
+}
+
+func writeReport(w io.Writer, review *core.StructuredReview, prURL string) error {
+	if err := report.Write(w, reviewOutput, review, prURL); err != nil {
+		return fmt.Errorf("failed to write %s report: %w", reviewOutput, err)
+	}
 	return nil
 }
 
@@ -130,12 +225,31 @@ func initializeReviewApp(ctx context.Context) (*app.App, func(), error) {
 	return InitializeApp(ctx, true)
 }
 
-func executeReviewFlow(ctx context.Context, appInstance *app.App, prURL string, timer *stepTimer) (*core.StructuredReview, error) {
+func executeReviewFlow(ctx context.Context, appInstance *app.App, prURL string, ignoreBaseline bool, timer *stepTimer) (*core.StructuredReview, error) {
+	event, ghClient, repo, err := preparePullRequest(ctx, appInstance, prURL, timer)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Generate Review
+	timer.step("Generating review")
+	review, err := generateReviewWithModels(ctx, appInstance, repo, event, ghClient, ignoreBaseline, timer)
+	if err != nil {
+		return nil, err
+	}
+	timer.done()
+
+	return review, nil
+}
+
+// preparePullRequest fetches the PR metadata, syncs the repository and brings
+// its index up to date (steps 2-4 of a review).
+func preparePullRequest(ctx context.Context, appInstance *app.App, prURL string, timer *stepTimer) (*core.GitHubEvent, github.Client, *storage.Repository, error) {
 	// 2. Parse URL and fetch PR metadata
 	timer.step("Fetching PR metadata")
 	event, ghClient, err := fetchPRMetadata(ctx, appInstance, prURL, timer)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	timer.done()
 
@@ -143,35 +257,27 @@ func executeReviewFlow(ctx context.Context, appInstance *app.App, prURL string,
 	timer.step("Syncing repository")
 	syncResult, repo, err := syncRepository(ctx, appInstance, event, timer)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	timer.done()
 
 	// 4. Indexing
 	timer.step("Updating index")
 	if err := handleIndexing(ctx, appInstance, syncResult, repo, timer); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	// Save the indexed SHA before the LLM call so we don't lose indexing progress if review fails
 	if event.HeadSHA != "" {
 		if err := appInstance.RepoMgr.UpdateRepoSHA(ctx, event.RepoFullName, event.HeadSHA); err != nil {
-			return nil, fmt.Errorf("failed to update repo SHA: %w", err)
+			return nil, nil, nil, fmt.Errorf("failed to update repo SHA: %w", err)
 		}
 	}
 	timer.done()
 
-	// 5. Generate Review
-	timer.step("Generating review")
-	review, err := generateReviewWithModels(ctx, appInstance, repo, event, ghClient, timer)
-	if err != nil {
-		return nil, err
-	}
-	timer.done()
-
-	return review, nil
+	return event, ghClient, repo, nil
 }
 
-func generateReviewWithModels(ctx context.Context, appInstance *app.App, repo *storage.Repository, event *core.GitHubEvent, ghClient github.Client, timer *stepTimer) (*core.StructuredReview, error) {
+func generateReviewWithModels(ctx context.Context, appInstance *app.App, repo *storage.Repository, event *core.GitHubEvent, ghClient github.Client, ignoreBaseline bool, timer *stepTimer) (*core.StructuredReview, error) {
 	diff, err := ghClient.GetPullRequestDiff(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get PR diff: %w", err)
@@ -181,18 +287,27 @@ func generateReviewWithModels(ctx context.Context, appInstance *app.App, repo *s
 		return nil, fmt.Errorf("failed to get changed files: %w", err)
 	}
 
+	return executeReview(ctx, appInstance, reviewpkg.Params{
+		Repo:         repo,
+		Event:        event,
+		Diff:         diff,
+		ChangedFiles: changedFiles,
+	}, ignoreBaseline, timer)
+}
+
+// executeReview reviews the diff of params with the configured models.
+func executeReview(ctx context.Context, appInstance *app.App, params reviewpkg.Params, ignoreBaseline bool, timer *stepTimer) (*core.StructuredReview, error) {
 	executor := reviewpkg.NewExecutor(appInstance.RAGService, reviewpkg.Config{
 		ComparisonModels: appInstance.Cfg.AI.ComparisonModels,
 		ReviewsDir:       appInstance.Cfg.AI.ReviewsDir,
+		IgnoreBaseline:   ignoreBaseline,
+		MaxDiffTokens:    appInstance.Cfg.AI.MaxDiffTokens,
+		MaxDiffKB:        appInstance.Cfg.Limits.MaxDiffKB,
+		SplitConcurrency: appInstance.Cfg.AI.SplitReviewConcurrency,
 		Logger:           appInstance.Logger,
 	})
 
-	result, err := executor.Execute(ctx, reviewpkg.Params{
-		Repo:         repo,
-		Event:        event,
-		Diff:         diff,
-		ChangedFiles: changedFiles,
-	})
+	result, err := executor.Execute(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("review failed: %w\n\nTip: Check that the LLM service is running", err)
 	}
@@ -333,6 +448,10 @@ func printReview(review *core.StructuredReview) {
 			//nolint:gosec // CLI output
 			dimColor.Printf("   Category: %s\n", s.Category)
 		}
+		if s.CWE != "" {
+			//nolint:gosec // CLI output
+			dimColor.Printf("   CWE: %s (%s)\n", s.CWE, core.CWEURL(s.CWE))
+		}
 		fmt.Println()
 		//nolint:gosec // CLI output
 		infoColor.Printf("%s\n", s.Comment)
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	gogithub "github.com/google/go-github/v73/github"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sevigo/code-warden/internal/app"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/gitutil"
+	"github.com/sevigo/code-warden/internal/report"
+)
+
+var (
+	batchRepo        string
+	batchState       string
+	batchMatch       string
+	batchFile        string
+	batchLimit       int
+	batchConcurrency int
+	batchFailOn      string
+)
+
+var reviewBatchCmd = &cobra.Command{
+	Use:   "review-batch [pr-url...]",
+	Short: "Review several GitHub Pull Requests and summarize the results",
+	Long: `Review several GitHub Pull Requests and print a summary table with the
+verdict, the suggestions per severity and the duration of each review.
+
+The pull requests are given as URLs, read one per line from --file ("-" for
+stdin), or listed from --repo in --state. --match keeps the pull requests of
+--repo whose head branch matches a glob pattern.
+
+Up to --concurrency reviews run at once. Syncing and indexing a repository
+runs for one of its pull requests at a time, since they share the clone.
+A failed review is reported in the table and does not stop the others.
+
+With --json the results are written as JSON for automation. With --fail-on
+the command exits with code 2 when any review has a suggestion of that
+severity or higher, and with code 1 when a review failed.
+
+Examples:
+  warden-cli review-batch https://github.com/owner/repo/pull/1 https://github.com/owner/repo/pull/2
+  warden-cli review-batch --repo owner/repo --state open --match 'feature/*'
+  warden-cli review-batch --file prs.txt --concurrency 4 --json --fail-on high`,
+	RunE: runReviewBatch,
+}
+
+func init() { //nolint:gochecknoinits // Cobra command registration
+	reviewBatchCmd.Flags().StringVar(&batchRepo, "repo", "", "Review the pull requests of this repository (owner/repo)")
+	reviewBatchCmd.Flags().StringVar(&batchState, "state", "open", "State of the pull requests of --repo: open, closed or all")
+	reviewBatchCmd.Flags().StringVar(&batchMatch, "match", "", "Only review pull requests of --repo whose head branch matches this glob")
+	reviewBatchCmd.Flags().StringVar(&batchFile, "file", "", `Read PR URLs from a file, one per line ("-" for stdin)`)
+	reviewBatchCmd.Flags().IntVar(&batchLimit, "limit", 0, "Review at most this many pull requests (0 for all)")
+	reviewBatchCmd.Flags().IntVarP(&batchConcurrency, "concurrency", "c", 2, "Number of reviews to run at once")
+	reviewBatchCmd.Flags().StringVar(&batchFailOn, "fail-on", "",
+		"Exit with code 2 if any suggestion has this severity or higher (low, medium, high, critical)")
+	reviewBatchCmd.Flags().BoolVar(&outputJSON, "json", false, "Output the results as JSON")
+	rootCmd.AddCommand(reviewBatchCmd)
+}
+
+// batchResult is the outcome of one review of a batch.
+type batchResult struct {
+	URL         string         `json:"url"`
+	Title       string         `json:"title,omitempty"`
+	Verdict     string         `json:"verdict,omitempty"`
+	Suggestions int            `json:"suggestions"`
+	Severities  map[string]int `json:"severities"`
+	DurationMS  int64          `json:"duration_ms"`
+	Error       string         `json:"error,omitempty"`
+
+	review *core.StructuredReview
+}
+
+func runReviewBatch(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	var failOn string
+	if batchFailOn != "" {
+		var err error
+		if failOn, err = report.ParseSeverity(batchFailOn); err != nil {
+			return fmt.Errorf("invalid --fail-on: %w", err)
+		}
+	}
+	if batchConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if batchRepo == "" && batchMatch != "" {
+		return fmt.Errorf("--match requires --repo")
+	}
+	if _, err := path.Match(batchMatch, ""); err != nil {
+		return fmt.Errorf("invalid --match: %w", err)
+	}
+	if outputJSON {
+		// Keep stdout clean for the JSON; progress and logs go to stderr.
+		color.Output = color.Error
+		if err := os.Setenv("LOGGING_OUTPUT", "stderr"); err != nil {
+			return fmt.Errorf("failed to redirect logs: %w", err)
+		}
+	}
+
+	appInstance, cleanup, err := initializeReviewApp(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	urls, err := batchURLs(ctx, appInstance, args)
+	if err != nil {
+		return err
+	}
+
+	//nolint:gosec // CLI output, errors are intentionally ignored
+	titleColor.Fprintf(color.Output, "🚀 Code Warden - reviewing %d pull requests (%d at a time)\n\n", len(urls), batchConcurrency)
+
+	results := reviewBatch(ctx, urls, batchConcurrency, func(ctx context.Context, prURL string, repoLock *sync.Mutex) *batchResult {
+		return reviewOne(ctx, appInstance, prURL, repoLock)
+	})
+
+	if outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	} else if err := printBatchSummary(os.Stdout, results); err != nil {
+		return err
+	}
+
+	err = batchExitError(results, failOn)
+	cmd.SilenceUsage = true
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		cmd.SilenceErrors = true
+	}
+	return err
+}
+
+// batchURLs collects the PR URLs of the batch from args, --file and --repo;
+// see batchTargets.
+func batchURLs(ctx context.Context, appInstance *app.App, args []string) ([]string, error) {
+	var fromFile []string
+	if batchFile != "" {
+		var err error
+		if fromFile, err = readURLFile(batchFile); err != nil {
+			return nil, err
+		}
+	}
+
+	var listed []*gogithub.PullRequest
+	if batchRepo != "" {
+		owner, repo, ok := strings.Cut(batchRepo, "/")
+		if !ok || owner == "" || repo == "" {
+			return nil, fmt.Errorf("invalid --repo %q, expected owner/repo", batchRepo)
+		}
+		if appInstance.Cfg.GitHub.Token == "" {
+			return nil, fmt.Errorf("GITHUB_TOKEN is not set\n\nTip: Set CW_GITHUB_TOKEN or GITHUB_TOKEN environment variable")
+		}
+		ghClient := github.NewPATClient(ctx, appInstance.Cfg.GitHub.Token, appInstance.Logger)
+		var err error
+		if listed, err = ghClient.ListPullRequests(ctx, owner, repo, batchState); err != nil {
+			return nil, err
+		}
+	}
+	return batchTargets(args, fromFile, listed, batchMatch, batchLimit)
+}
+
+// batchTargets returns the PR URLs of a batch: those of args, then those
+// read from --file, then the listed pull requests whose head branch matches
+// match, if set. Duplicates are dropped, an invalid URL fails the whole batch
+// before any review starts, and only the first limit URLs are kept when limit
+// is positive.
+func batchTargets(args, fromFile []string, listed []*gogithub.PullRequest, match string, limit int) ([]string, error) {
+	urls := slices.Concat(args, fromFile)
+	for _, pr := range listed {
+		if match != "" {
+			if ok, _ := path.Match(match, pr.GetHead().GetRef()); !ok {
+				continue
+			}
+		}
+		urls = append(urls, pr.GetHTMLURL())
+	}
+
+	seen := make(map[string]bool, len(urls))
+	unique := urls[:0]
+	for _, u := range urls {
+		u = strings.TrimSuffix(strings.TrimSpace(u), "/")
+		if u == "" || seen[u] {
+			continue
+		}
+		if _, _, _, err := gitutil.ParsePullRequestURL(u); err != nil {
+			return nil, fmt.Errorf("invalid PR URL: %w\n\nExpected format: https://github.com/owner/repo/pull/123", err)
+		}
+		seen[u] = true
+		unique = append(unique, u)
+	}
+	if len(unique) == 0 {
+		return nil, fmt.Errorf("no pull requests to review\n\nTip: Pass PR URLs, --file or --repo")
+	}
+	if limit > 0 && len(unique) > limit {
+		unique = unique[:limit]
+	}
+	return unique, nil
+}
+
+// readURLFile reads PR URLs from name, or stdin for "-", skipping blank lines
+// and # comments.
+func readURLFile(name string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open --file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	var urls []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		urls = append(urls, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read --file: %w", err)
+	}
+	return urls, nil
+}
+
+// batchReviewFunc reviews one pull request of a batch, holding repoLock
+// while its repository is synced and indexed. Failures are reported in the
+// result.
+type batchReviewFunc func(ctx context.Context, prURL string, repoLock *sync.Mutex) *batchResult
+
+// reviewBatch reviews urls with up to concurrency reviews at once and returns
+// the results in the order of urls. A failed review does not stop the others.
+func reviewBatch(ctx context.Context, urls []string, concurrency int, review batchReviewFunc) []*batchResult {
+	results := make([]*batchResult, len(urls))
+	var (
+		locksMu   sync.Mutex
+		repoLocks = make(map[string]*sync.Mutex)
+	)
+	lockRepo := func(prURL string) *sync.Mutex {
+		owner, repo, _, _ := gitutil.ParsePullRequestURL(prURL)
+		locksMu.Lock()
+		defer locksMu.Unlock()
+		key := owner + "/" + repo
+		if repoLocks[key] == nil {
+			repoLocks[key] = &sync.Mutex{}
+		}
+		return repoLocks[key]
+	}
+
+	var g errgroup.Group
+	g.SetLimit(concurrency)
+	for i, prURL := range urls {
+		g.Go(func() error {
+			results[i] = review(ctx, prURL, lockRepo(prURL))
+			printBatchProgress(results[i])
+			return nil
+		})
+	}
+	_ = g.Wait()
+	return results
+}
+
+// reviewOne reviews prURL, holding repoLock while the repository is synced
+// and indexed.
+func reviewOne(ctx context.Context, appInstance *app.App, prURL string, repoLock *sync.Mutex) *batchResult {
+	start := time.Now()
+	result := &batchResult{URL: prURL, Severities: make(map[string]int)}
+	timer := newStepTimer(4, false)
+	timer.quiet = true
+
+	repoLock.Lock()
+	event, ghClient, repo, err := preparePullRequest(ctx, appInstance, prURL, timer)
+	repoLock.Unlock()
+	if err == nil {
+		result.Title = event.PRTitle
+		result.review, err = generateReviewWithModels(ctx, appInstance, repo, event, ghClient, false, timer)
+	}
+	result.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Verdict = result.review.Verdict
+	result.Suggestions = len(result.review.Suggestions)
+	for _, s := range result.review.Suggestions {
+		if rank := core.SeverityRank(s.Severity); rank > 0 {
+			result.Severities[core.Severities[rank-1]]++
+		}
+	}
+	return result
+}
+
+func printBatchProgress(result *batchResult) {
+	duration := time.Duration(result.DurationMS) * time.Millisecond
+	if result.Error != "" {
+		//nolint:gosec // CLI output, errors are intentionally ignored
+		warnColor.Fprintf(color.Output, "✗ %s failed after %s\n", result.URL, duration.Round(time.Second))
+		return
+	}
+	//nolint:gosec // CLI output, errors are intentionally ignored
+	successColor.Fprintf(color.Output, "✓ %s: %s, %d suggestions (%s)\n",
+		result.URL, result.Verdict, result.Suggestions, duration.Round(time.Second))
+}
+
+// printBatchSummary writes the summary table of a batch, followed by the
+// errors of the failed reviews.
+func printBatchSummary(w io.Writer, results []*batchResult) error {
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "PR\tVERDICT\tCRITICAL\tHIGH\tMEDIUM\tLOW\tDURATION")
+	for _, r := range results {
+		verdict := r.Verdict
+		if r.Error != "" {
+			verdict = "ERROR"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%d\t%s\n",
+			batchPRName(r.URL), verdict,
+			r.Severities["Critical"], r.Severities["High"], r.Severities["Medium"], r.Severities["Low"],
+			(time.Duration(r.DurationMS) * time.Millisecond).Round(time.Second),
+		)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "\n%s: %s\n", batchPRName(r.URL), r.Error)
+		}
+	}
+	return nil
+}
+
+// batchPRName shortens a PR URL to owner/repo#123.
+func batchPRName(prURL string) string {
+	owner, repo, number, err := gitutil.ParsePullRequestURL(prURL)
+	if err != nil {
+		return prURL
+	}
+	return fmt.Sprintf("%s/%s#%d", owner, repo, number)
+}
+
+// batchExitError returns the error that sets the exit code of a batch: 1 when
+// a review failed, which takes precedence, 2 when --fail-on found
+// suggestions, and nil otherwise.
+func batchExitError(results []*batchResult, failOn string) error {
+	failed, flagged := 0, 0
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			failed++
+		case failOn != "" && report.CountAtLeast(r.review, failOn) > 0:
+			flagged++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d reviews failed", failed, len(results))
+	}
+	if flagged > 0 {
+		return &exitCodeError{
+			code: exitFindings,
+			msg:  fmt.Sprintf("%d pull request(s) with suggestions of severity %s or higher", flagged, failOn),
+		}
+	}
+	return nil
+}
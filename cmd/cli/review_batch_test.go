@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	gogithub "github.com/google/go-github/v73/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+func listedPR(url, branch string) *gogithub.PullRequest {
+	return &gogithub.PullRequest{HTMLURL: &url, Head: &gogithub.PullRequestBranch{Ref: &branch}}
+}
+
+func TestBatchTargets(t *testing.T) {
+	const (
+		pr1 = "https://github.com/owner/repo/pull/1"
+		pr2 = "https://github.com/owner/repo/pull/2"
+		pr3 = "https://github.com/owner/repo/pull/3"
+	)
+	listed := []*gogithub.PullRequest{listedPR(pr2, "feature/login"), listedPR(pr3, "fix/typo")}
+
+	tests := []struct {
+		name     string
+		args     []string
+		fromFile []string
+		listed   []*gogithub.PullRequest
+		match    string
+		limit    int
+		want     []string
+		wantErr  string
+	}{
+		{name: "args, file and listed in order", args: []string{pr1}, fromFile: []string{pr3}, listed: listed, want: []string{pr1, pr3, pr2}},
+		{name: "duplicates and trailing slashes", args: []string{pr1, pr1 + "/", " " + pr2}, fromFile: []string{pr2}, want: []string{pr1, pr2}},
+		{name: "match filters listed only", args: []string{pr1}, listed: listed, match: "feature/*", want: []string{pr1, pr2}},
+		{name: "limit", args: []string{pr1, pr2, pr3}, limit: 2, want: []string{pr1, pr2}},
+		{name: "invalid URL fails the batch", args: []string{pr1, "https://github.com/owner/repo/issues/4"}, wantErr: "invalid PR URL"},
+		{name: "nothing to review", listed: listed, match: "release/*", wantErr: "no pull requests to review"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := batchTargets(tt.args, tt.fromFile, tt.listed, tt.match, tt.limit)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReviewBatch_ContinuesAfterFailure(t *testing.T) {
+	urls := []string{
+		"https://github.com/owner/repo/pull/1",
+		"https://github.com/owner/repo/pull/2",
+		"https://github.com/owner/other/pull/3",
+	}
+	var (
+		mu    sync.Mutex
+		locks = map[string]*sync.Mutex{}
+	)
+	results := reviewBatch(context.Background(), urls, 2, func(_ context.Context, prURL string, repoLock *sync.Mutex) *batchResult {
+		mu.Lock()
+		locks[prURL] = repoLock
+		mu.Unlock()
+		if strings.HasSuffix(prURL, "/1") {
+			return &batchResult{URL: prURL, Error: "sync failed"}
+		}
+		return &batchResult{URL: prURL, Verdict: "APPROVE"}
+	})
+
+	require.Len(t, results, 3)
+	for i, r := range results {
+		assert.Equal(t, urls[i], r.URL, "results keep the order of the URLs")
+	}
+	assert.Equal(t, "sync failed", results[0].Error)
+	assert.Equal(t, "APPROVE", results[1].Verdict, "a failed review does not stop the others")
+	assert.Equal(t, "APPROVE", results[2].Verdict)
+	assert.Same(t, locks[urls[0]], locks[urls[1]], "pull requests of a repository share its lock")
+	assert.NotSame(t, locks[urls[0]], locks[urls[2]])
+}
+
+func TestBatchExitError(t *testing.T) {
+	high := &batchResult{review: &core.StructuredReview{Suggestions: []core.Suggestion{{Severity: "High"}}}}
+	low := &batchResult{review: &core.StructuredReview{Suggestions: []core.Suggestion{{Severity: "Low"}}}}
+	failed := &batchResult{Error: "review failed"}
+
+	tests := []struct {
+		name     string
+		results  []*batchResult
+		failOn   string
+		wantCode int // 0 for no error, 1 for a plain error
+	}{
+		{name: "all clean", results: []*batchResult{low, low}, failOn: "High", wantCode: 0},
+		{name: "no threshold", results: []*batchResult{high}, wantCode: 0},
+		{name: "findings at the threshold", results: []*batchResult{low, high}, failOn: "High", wantCode: exitFindings},
+		{name: "failure", results: []*batchResult{low, failed}, wantCode: 1},
+		{name: "failure wins over findings", results: []*batchResult{high, failed}, failOn: "High", wantCode: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := batchExitError(tt.results, tt.failOn)
+			var exitErr *exitCodeError
+			switch {
+			case tt.wantCode == 0:
+				require.NoError(t, err)
+			case errors.As(err, &exitErr):
+				assert.Equal(t, tt.wantCode, exitErr.code)
+			default:
+				require.Error(t, err)
+				assert.Equal(t, 1, tt.wantCode, "plain errors exit with code 1: %v", err)
+			}
+		})
+	}
+}
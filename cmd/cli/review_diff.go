@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/gitutil"
+	"github.com/sevigo/code-warden/internal/report"
+)
+
+var (
+	reviewDiffRepo  string
+	reviewDiffPatch string
+	reviewDiffBase  string
+	reviewDiffHead  string
+	reviewDiffRange string
+)
+
+var reviewDiffCmd = &cobra.Command{
+	Use:   "review-diff",
+	Short: "Review a local diff or patch file without GitHub",
+	Long: `Review a diff built from a local repository, or a patch file, without
+contacting GitHub. The context comes from the index of the repository, which
+must be registered (warden-term /add), so reviews work in air-gapped setups
+with a local LLM.
+
+With --base and --head the changes of head since it forked from base are
+reviewed, like a pull request from head into base; --range base..head is
+short for both. With --patch a git diff or git format-patch file is
+reviewed, "-" reads it from stdin.
+
+--output, --output-file and --fail-on work as for 'warden-cli review'.
+
+Examples:
+  warden-cli review-diff --repo . --base main --head feature/login
+  warden-cli review-diff --repo . --range main..feature/login
+  warden-cli review-diff --repo /src/app --patch fix.patch --output json
+  git diff main | warden-cli review-diff --repo . --patch - --fail-on high`,
+	Args: cobra.NoArgs,
+	RunE: runReviewDiff,
+}
+
+func init() { //nolint:gochecknoinits // Cobra command registration
+	reviewDiffCmd.Flags().StringVar(&reviewDiffRepo, "repo", ".", "Path of the local repository")
+	reviewDiffCmd.Flags().StringVar(&reviewDiffPatch, "patch", "", `Patch file to review, "-" for stdin`)
+	reviewDiffCmd.Flags().StringVar(&reviewDiffBase, "base", "", "Ref the changes are reviewed against")
+	reviewDiffCmd.Flags().StringVar(&reviewDiffHead, "head", "HEAD", "Ref whose changes are reviewed")
+	reviewDiffCmd.Flags().StringVar(&reviewDiffRange, "range", "", "Range base..head to review, instead of --base and --head")
+	reviewDiffCmd.Flags().StringVarP(&reviewOutput, "output", "o", report.FormatText,
+		"Output format: "+strings.Join(report.Formats, ", "))
+	reviewDiffCmd.Flags().StringVar(&reviewOutputFile, "output-file", "", "Write the report to a file instead of stdout")
+	reviewDiffCmd.Flags().StringVar(&reviewFailOn, "fail-on", "",
+		"Exit with code 2 if any suggestion has this severity or higher (low, medium, high, critical)")
+	reviewDiffCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output with timing information")
+	reviewDiffCmd.MarkFlagsMutuallyExclusive("patch", "base", "range")
+	reviewDiffCmd.MarkFlagsMutuallyExclusive("range", "head")
+	reviewDiffCmd.MarkFlagsOneRequired("patch", "base", "range")
+	rootCmd.AddCommand(reviewDiffCmd)
+}
+
+func runReviewDiff(cmd *cobra.Command, _ []string) error {
+	ctx := context.Background()
+
+	if !slices.Contains(report.Formats, reviewOutput) {
+		return fmt.Errorf("invalid --output %q, expected one of %s", reviewOutput, strings.Join(report.Formats, ", "))
+	}
+	var failOn string
+	if reviewFailOn != "" {
+		var err error
+		if failOn, err = report.ParseSeverity(reviewFailOn); err != nil {
+			return fmt.Errorf("invalid --fail-on: %w", err)
+		}
+	}
+	if reviewOutput == report.FormatText && reviewOutputFile != "" {
+		return fmt.Errorf("--output-file requires --output %s", strings.Join(report.Formats[1:], ", "))
+	}
+	if reviewOutput != report.FormatText && reviewOutputFile == "" {
+		color.Output = color.Error
+		if err := os.Setenv("LOGGING_OUTPUT", "stderr"); err != nil {
+			return fmt.Errorf("failed to redirect logs: %w", err)
+		}
+	}
+
+	root, err := gitutil.RepoRoot(ctx, reviewDiffRepo)
+	if err != nil {
+		return fmt.Errorf("%s is not a git repository: %w", reviewDiffRepo, err)
+	}
+
+	timer := newStepTimer(3, verbose)
+	//nolint:gosec // CLI output, errors are intentionally ignored
+	titleColor.Fprintln(color.Output, "🔍 Code Warden - Local Diff Review")
+
+	timer.step("Computing diff")
+	target, diff, err := reviewDiffInput(ctx, root)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(diff) == "" {
+		//nolint:gosec // CLI output
+		successColor.Fprintf(color.Output, "✅ No %s to review.\n", target)
+		return nil
+	}
+	timer.done()
+
+	return reviewLocalChanges(ctx, cmd, root, target, diff, failOn, timer)
+}
+
+// reviewDiffInput returns what --patch, --range or --base and --head select,
+// and its diff.
+func reviewDiffInput(ctx context.Context, root string) (string, string, error) {
+	if reviewDiffRange != "" {
+		var err error
+		if reviewDiffBase, reviewDiffHead, err = gitutil.ParseRange(reviewDiffRange); err != nil {
+			return "", "", err
+		}
+	}
+	if reviewDiffBase != "" {
+		diff, err := gitutil.DiffRange(ctx, root, reviewDiffBase, reviewDiffHead)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to diff %s against %s: %w", reviewDiffHead, reviewDiffBase, err)
+		}
+		return fmt.Sprintf("changes of %s since %s", reviewDiffHead, reviewDiffBase), diff, nil
+	}
+
+	in := io.Reader(os.Stdin)
+	if reviewDiffPatch != "-" {
+		f, err := os.Open(reviewDiffPatch)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read patch: %w", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	diff, err := gitutil.ReadPatch(in)
+	if errors.Is(err, gitutil.ErrNotPatch) {
+		return "", "", fmt.Errorf("%s is %w\n\nTip: Create it with git diff or git format-patch", reviewDiffPatch, err)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return "patch " + reviewDiffPatch, diff, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage the keys credentials are encrypted with",
+	Long: `Credentials stored in the database, such as cached GitHub installation
+tokens, are encrypted with AES-GCM under security.encryption_key.
+
+To rotate the key, move the current key to security.previous_encryption_keys,
+set a new security.encryption_key, run "warden-cli secrets rotate" and then
+remove the previous key.`,
+}
+
+var secretsGenerateKeyCmd = &cobra.Command{
+	Use:   "generate-key",
+	Short: "Print a new random encryption key",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return fmt.Errorf("failed to generate key: %w", err)
+		}
+		fmt.Println(base64.StdEncoding.EncodeToString(key))
+		return nil
+	},
+}
+
+var secretsRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Re-encrypt stored credentials with the current encryption key",
+	RunE: func(_ *cobra.Command, _ []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		result, err := app.Vault.Rotate(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to rotate credentials: %w", err)
+		}
+		if outputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(result)
+		}
+		fmt.Printf("Re-encrypted %d credentials, %d were already current, deleted %d expired.\n",
+			result.Rotated, result.Current, result.Expired)
+		return nil
+	},
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	secretsRotateCmd.Flags().BoolVar(&outputJSON, "json", false, "Output the result as JSON")
+	secretsCmd.AddCommand(secretsGenerateKeyCmd, secretsRotateCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
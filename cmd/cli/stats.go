@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/repostats"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+var statsRepo string
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Shows what a repository's index holds and what its reviews found",
+	Long: `Shows the documents of a repository's vector collection by chunk type, the
+commit and date it was last indexed at, and the number of saved reviews with
+their average number of suggestions and most frequent categories.
+
+The documents are counted in the vector store; if it cannot be reached, the
+other statistics are still shown.`,
+	Example: `  warden-cli stats --repo owner/app
+  warden-cli stats --repo owner/app --json`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		ctx := context.Background()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		repo, err := app.Store.GetRepositoryByFullName(ctx, statsRepo)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("repository %s is not managed by Code-Warden", statsRepo)
+			}
+			return fmt.Errorf("failed to retrieve repository: %w", err)
+		}
+
+		stats, err := repostats.Collect(ctx, app.Store, app.VectorStore, repo, app.Cfg.AI.EmbedderModel, app.Logger)
+		if err != nil {
+			return fmt.Errorf("failed to collect repository stats: %w", err)
+		}
+
+		if outputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(stats)
+		}
+		printRepoStats(stats)
+		return nil
+	},
+}
+
+func printRepoStats(stats *repostats.Stats) {
+	fmt.Printf("Repository:   %s\n", stats.Repo)
+	fmt.Printf("Collection:   %s (%s)\n", stats.Collection, stats.EmbedderModel)
+	lastIndexed := "never"
+	if stats.LastIndexedSHA != "" {
+		lastIndexed = stats.LastIndexedSHA
+		if !stats.LastIndexedAt.IsZero() {
+			lastIndexed += " on " + stats.LastIndexedAt.Format(time.RFC822)
+		}
+	}
+	fmt.Printf("Last indexed: %s\n", lastIndexed)
+
+	switch {
+	case stats.Cold:
+		fmt.Println("Documents:    none, the collection is in cold storage")
+	case stats.Index == nil:
+		fmt.Printf("Documents:    unknown (%s)\n", stats.IndexError)
+	default:
+		size := ""
+		if stats.Index.SizeBytes > 0 {
+			size = fmt.Sprintf(", %.1f MiB", float64(stats.Index.SizeBytes)/(1<<20))
+		}
+		fmt.Printf("Documents:    %d%s\n", stats.Index.Documents, size)
+	}
+
+	reviews := stats.Reviews
+	fmt.Printf("Reviews:      %d, %.1f suggestions per review\n", reviews.Count, reviews.AvgSuggestions)
+
+	if stats.Index != nil && len(stats.Index.ByChunkType) > 0 {
+		chunkTypes := make([]string, 0, len(stats.Index.ByChunkType))
+		for chunkType := range stats.Index.ByChunkType {
+			chunkTypes = append(chunkTypes, chunkType)
+		}
+		sort.Strings(chunkTypes)
+
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "CHUNK TYPE\tDOCUMENTS")
+		for _, chunkType := range chunkTypes {
+			fmt.Fprintf(w, "%s\t%d\n", chunkType, stats.Index.ByChunkType[chunkType])
+		}
+		_ = w.Flush()
+	}
+
+	if len(reviews.TopCategories) > 0 {
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "CATEGORY\tSUGGESTIONS")
+		for _, c := range reviews.TopCategories {
+			fmt.Fprintf(w, "%s\t%d\n", c.Category, c.Count)
+		}
+		_ = w.Flush()
+	}
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	statsCmd.Flags().StringVar(&statsRepo, "repo", "", "Repository to report on (owner/repo)")
+	statsCmd.Flags().BoolVar(&outputJSON, "json", false, "Output stats as JSON")
+	_ = statsCmd.MarkFlagRequired("repo")
+	rootCmd.AddCommand(statsCmd)
+}
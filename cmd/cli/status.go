@@ -43,16 +43,21 @@ var statusCmd = &cobra.Command{
 		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-		fmt.Fprintln(w, "REPOSITORY\tLAST INDEXED SHA\tQDRANT COLLECTION\tLAST UPDATED")
+		fmt.Fprintln(w, "REPOSITORY\tLAST INDEXED SHA\tQDRANT COLLECTION\tTIER\tLAST UPDATED")
 		for _, repo := range repos {
 			sha := repo.LastIndexedSHA
 			if len(sha) > 7 {
 				sha = sha[:7]
 			}
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			tier := "hot"
+			if repo.IsCold() {
+				tier = "cold"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 				repo.FullName,
 				sha,
 				repo.QdrantCollectionName,
+				tier,
 				repo.UpdatedAt.Format(time.RFC822),
 			)
 		}
@@ -44,7 +44,7 @@ If the repository has never been indexed, it will perform an initial full scan.`
 		}
 		slog.Info("Local repository update analysis complete", "repo", updateResult.RepoFullName, "head_sha", updateResult.HeadSHA)
 
-		repoConfig := config.LoadRepoConfigWithDefaults(updateResult.RepoPath, updateResult.RepoFullName, slog.Default())
+		repoConfig := config.LoadRepoConfigWithDefaults(updateResult.RepoPath, updateResult.RepoFullName, nil, slog.Default())
 
 		repoRecord, err := app.RepoMgr.GetRepoRecord(ctx, updateResult.RepoFullName)
 		if err != nil {
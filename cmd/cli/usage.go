@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+var (
+	usageRepo   string
+	usageDays   int
+	usageByStep bool
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Shows LLM token usage and estimated cost per repository, model and day",
+	Long: `Aggregates the prompt and completion tokens recorded for every LLM call by day,
+repository and model. Costs are estimated from usage.prices; models without a
+price are shown with "-". Token counts marked "~" include estimates for
+providers that report none.`,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if usageDays < 1 {
+			return errors.New("--days must be at least 1")
+		}
+		ctx := context.Background()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(usageDays - 1))
+		totals, err := app.Store.GetLLMUsage(ctx, storage.LLMUsageFilter{
+			RepoFullName: usageRepo,
+			Since:        since,
+			ByStep:       usageByStep,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to retrieve llm usage: %w", err)
+		}
+
+		if outputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(totals)
+		}
+
+		if len(totals) == 0 {
+			slog.Info("No LLM usage has been recorded in this period.")
+			return nil
+		}
+
+		var total float64
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+		fmt.Fprintln(w, "DAY\tREPOSITORY\tMODEL\tSTEP\tCALLS\tPROMPT\tCOMPLETION\tCOST")
+		for _, t := range totals {
+			cost := "-"
+			if c, ok := app.Cfg.Usage.Cost(t.Model, t.PromptTokens, t.CompletionTokens); ok {
+				cost = fmt.Sprintf("%.4f", c)
+				total += c
+			}
+			estimated := ""
+			if t.EstimatedCalls > 0 {
+				estimated = "~"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s%d\t%s%d\t%s\n",
+				t.Day.Format(time.DateOnly),
+				t.RepoFullName,
+				t.Model,
+				t.Step,
+				t.Calls,
+				estimated, t.PromptTokens,
+				estimated, t.CompletionTokens,
+				cost,
+			)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\nEstimated cost of priced models: %.4f\n", total)
+		return nil
+	},
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	usageCmd.Flags().StringVar(&usageRepo, "repo", "", "Limit usage to one repository (owner/repo)")
+	usageCmd.Flags().IntVar(&usageDays, "days", 30, "Number of days to report, including today")
+	usageCmd.Flags().BoolVar(&usageByStep, "by-step", false, "Split the usage by review step")
+	usageCmd.Flags().BoolVar(&outputJSON, "json", false, "Output usage as JSON")
+	rootCmd.AddCommand(usageCmd)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/vectorgc"
+	"github.com/sevigo/code-warden/internal/vectormigrate"
+)
+
+var (
+	gcDryRun bool
+	gcYes    bool
+	gcGrace  time.Duration
+
+	migrateRepo    string
+	migrateToModel string
+	migrateKeepOld bool
+	migrateAbort   bool
+)
+
+var vectorCmd = &cobra.Command{
+	Use:   "vector",
+	Short: "Manage the collections in the vector store",
+}
+
+var vectorGCCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete vector collections no repository refers to",
+	Long: `Lists the collections in the vector store and compares them with the
+collections of the repositories in the database. Collections of deleted or
+renamed repositories, and their time-travel snapshots, are orphans.
+
+Orphans are recorded when first seen and deleted once they have been orphaned
+for the grace period (storage.collection_gc.grace_period, 7 days by default).
+Only collections named by Code-Warden ("repo-...") are ever touched.
+
+Without --yes the orphans due for deletion are listed and you are asked to
+confirm; --dry-run only lists them.`,
+	Example: `  warden-cli vector gc --dry-run
+  warden-cli vector gc --grace 0 --yes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		if outputJSON && !gcDryRun && !gcYes {
+			return errors.New("--json needs --dry-run or --yes, as there is no prompt to confirm")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+		defer cancel()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		opts := vectorgc.Options{DryRun: true, GracePeriod: app.Cfg.Storage.CollectionGC.GracePeriod}
+		if cmd.Flags().Changed("grace") {
+			opts.GracePeriod = gcGrace
+		}
+		collector := vectorgc.New(app.Cfg, app.Store, app.VectorStore, app.Logger)
+
+		if !gcDryRun && !gcYes {
+			preview, err := collector.Run(ctx, opts)
+			if err != nil {
+				return fmt.Errorf("failed to find orphaned collections: %w", err)
+			}
+			printGCReport(preview, opts.GracePeriod)
+			if due := preview.Due(); due > 0 && !confirm(fmt.Sprintf("Delete %d collection(s)?", due)) {
+				fmt.Println("Aborted, nothing was deleted.")
+				return nil
+			}
+		}
+
+		opts.DryRun = gcDryRun
+		report, runErr := collector.Run(ctx, opts)
+		if report == nil {
+			return fmt.Errorf("failed to collect orphaned collections: %w", runErr)
+		}
+		if outputJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return err
+			}
+		} else if gcDryRun || gcYes {
+			printGCReport(report, opts.GracePeriod)
+		} else {
+			fmt.Printf("Deleted %d collection(s).\n", report.Deleted())
+		}
+		if runErr != nil {
+			return fmt.Errorf("failed to collect some orphaned collections: %w", runErr)
+		}
+		return nil
+	},
+}
+
+var vectorMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Move a repository to another embedder model",
+	Long: `Re-embeds a repository with another embedder model without a window in
+which it has no usable index:
+
+  1. builds a new collection with the model next to the current one,
+  2. checks that it holds at least 95% of the files and documents recorded
+     for the repository and answers a search,
+  3. points the repository at the new collection and model in one
+     transaction,
+  4. deletes the old collection, unless --keep-old is given.
+
+Every step is recorded. When a migration fails or is interrupted, running the
+same command again resumes it; --abort drops the collection it built instead.`,
+	Example: `  warden-cli vector migrate --repo owner/app --to-model bge-m3
+  warden-cli vector migrate --repo owner/app --abort`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		if !migrateAbort && migrateToModel == "" {
+			return errors.New("--to-model is required")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 12*time.Hour)
+		defer cancel()
+
+		app, cleanup, err := InitializeApp(ctx, false)
+		if err != nil {
+			return err
+		}
+		defer cleanup()
+
+		repo, err := app.Store.GetRepositoryByFullName(ctx, migrateRepo)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("repository %s is not managed by Code-Warden", migrateRepo)
+			}
+			return fmt.Errorf("failed to retrieve repository: %w", err)
+		}
+
+		migrator := vectormigrate.New(app.Cfg, app.Store, app.RAGService, app.VectorStore, app.Logger)
+		if migrateAbort {
+			if err := migrator.Abort(ctx, repo); err != nil {
+				return err
+			}
+			fmt.Printf("Aborted the embedder migration of %s.\n", repo.FullName)
+			return nil
+		}
+
+		repoConfig := config.LoadRepoConfigWithDefaults(repo.ClonePath, repo.FullName, nil, slog.Default())
+		mig, err := migrator.Migrate(ctx, repoConfig, repo, vectormigrate.Options{TargetModel: migrateToModel, KeepOld: migrateKeepOld})
+		if err != nil {
+			if mig != nil && mig.Status != storage.MigrationDone {
+				return fmt.Errorf("migration stopped while %s, run the command again to resume: %w", mig.Status, err)
+			}
+			return err
+		}
+		fmt.Printf("%s now uses %s in %s (%d files, %d documents).\n",
+			repo.FullName, mig.TargetModel, mig.TargetCollection, mig.Files, mig.Chunks)
+		return nil
+	},
+}
+
+func printGCReport(report *vectorgc.Report, grace time.Duration) {
+	fmt.Printf("%d repository collection(s) in the vector store, %d orphaned.\n", report.Collections, len(report.Orphans))
+	if len(report.Orphans) == 0 {
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "COLLECTION\tORPHANED SINCE\tSTATUS")
+	for _, o := range report.Orphans {
+		var status string
+		switch {
+		case o.Error != "":
+			status = "failed: " + o.Error
+		case o.Deleted:
+			status = "deleted"
+		case o.Due && report.DryRun:
+			status = "would be deleted"
+		case o.Due:
+			status = "due"
+		default:
+			status = "kept until " + o.FirstSeenAt.Add(grace).Format(time.RFC822)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", o.Name, o.FirstSeenAt.Format(time.RFC822), status)
+	}
+	_ = w.Flush()
+}
+
+// confirm asks a yes/no question on the terminal; anything but "y" or "yes"
+// is a no.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func init() { //nolint:gochecknoinits // Cobra's init function for command registration
+	vectorGCCmd.Flags().BoolVar(&gcDryRun, "dry-run", false, "List orphaned collections without recording or deleting anything")
+	vectorGCCmd.Flags().BoolVarP(&gcYes, "yes", "y", false, "Delete orphans past the grace period without asking")
+	vectorGCCmd.Flags().DurationVar(&gcGrace, "grace", 0, "Grace period before an orphan is deleted (default storage.collection_gc.grace_period)")
+	vectorGCCmd.Flags().BoolVar(&outputJSON, "json", false, "Output the report as JSON")
+	vectorCmd.AddCommand(vectorGCCmd)
+
+	vectorMigrateCmd.Flags().StringVar(&migrateRepo, "repo", "", "Repository to migrate (owner/repo)")
+	vectorMigrateCmd.Flags().StringVar(&migrateToModel, "to-model", "", "Embedder model to move the repository to")
+	vectorMigrateCmd.Flags().BoolVar(&migrateKeepOld, "keep-old", false, "Leave the old collection for the collection GC instead of deleting it")
+	vectorMigrateCmd.Flags().BoolVar(&migrateAbort, "abort", false, "Abort the migration in progress and delete the collection it built")
+	_ = vectorMigrateCmd.MarkFlagRequired("repo")
+	vectorCmd.AddCommand(vectorMigrateCmd)
+	rootCmd.AddCommand(vectorCmd)
+}
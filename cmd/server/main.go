@@ -7,10 +7,12 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/sevigo/code-warden/internal/sandbox"
 	"github.com/sevigo/code-warden/internal/wire"
 )
 
 func main() {
+	sandbox.ServeParseWorker()
 	if err := run(); err != nil {
 		fmt.Println("application failed to run", err)
 		os.Exit(1)
@@ -10,11 +10,22 @@ import (
 	"github.com/sevigo/code-warden/internal/app"
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
+	indexpkg "github.com/sevigo/code-warden/internal/rag/index"
 	"github.com/sevigo/code-warden/internal/repomanager"
 	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/timetravel"
 	"github.com/sevigo/code-warden/internal/wire"
 )
 
+// warmRepo returns repo with its collection restored from cold storage, for
+// commands that query it.
+func warmRepo(ctx context.Context, app *app.App, repo *storage.Repository) (*storage.Repository, error) {
+	if !repo.IsCold() {
+		return repo, nil
+	}
+	return app.RepoMgr.GetRepoRecord(ctx, repo.FullName)
+}
+
 func initializeAppCmd() tea.Cmd {
 	return func() tea.Msg {
 		app, cleanup, err := wire.InitializeApp(context.Background())
@@ -36,7 +47,7 @@ func initializeAppCmd() tea.Cmd {
 	}
 }
 
-func scanRepoCmd(app *app.App, path, repoFullName string, force bool) tea.Cmd {
+func scanRepoCmd(app *app.App, path, repoFullName string, force bool, progressFn indexpkg.ProgressFunc) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
 		updateResult, err := app.RepoMgr.ScanLocalRepo(ctx, path, repoFullName, force)
@@ -66,7 +77,7 @@ func scanRepoCmd(app *app.App, path, repoFullName string, force bool) tea.Cmd {
 				repoConfig,
 				repoRecord,
 				updateResult.RepoPath,
-				nil,
+				progressFn,
 			)
 		} else if len(updateResult.FilesToAddOrUpdate) > 0 || len(updateResult.FilesToDelete) > 0 {
 			err = app.RAGService.UpdateRepoContext(
@@ -76,7 +87,7 @@ func scanRepoCmd(app *app.App, path, repoFullName string, force bool) tea.Cmd {
 				updateResult.RepoPath,
 				updateResult.FilesToAddOrUpdate,
 				updateResult.FilesToDelete,
-				nil,
+				progressFn,
 			)
 		}
 		if err != nil {
@@ -93,14 +104,58 @@ func scanRepoCmd(app *app.App, path, repoFullName string, force bool) tea.Cmd {
 	}
 }
 
-func answerQuestionCmd(app *app.App, collectionName, embedderModelName, question string, history []string) tea.Cmd {
+// scanProgress carries the indexing progress of a scan to the UI.
+type scanProgress struct {
+	updates chan scanProgressMsg
+	done    chan struct{}
+}
+
+// report hands the progress to the UI. Reports the UI has not picked up yet
+// are not waited for; the next one supersedes them.
+func (p *scanProgress) report(done, total int) {
+	select {
+	case p.updates <- scanProgressMsg{done: done, total: total}:
+	default:
+	}
+}
+
+// waitForScanProgressCmd waits for the next progress report of p. The UI
+// issues it again after each one.
+func waitForScanProgressCmd(p *scanProgress) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-p.updates:
+			return msg
+		case <-p.done:
+			return nil
+		}
+	}
+}
+
+// scanWithProgressCmd scans a repository like scanRepoCmd and reports the
+// indexing progress to p. Failures are reported as a scanCompleteMsg, so
+// that the progress display ends.
+func scanWithProgressCmd(app *app.App, path, repoFullName string, force bool, p *scanProgress) tea.Cmd {
+	scan := scanRepoCmd(app, path, repoFullName, force, p.report)
+	return func() tea.Msg {
+		defer close(p.done)
+		msg := scan()
+		if failed, ok := msg.(errorMsg); ok {
+			return scanCompleteMsg{repoPath: path, repoFullName: repoFullName, err: failed.err}
+		}
+		return msg
+	}
+}
+
+func askAtCmd(app *app.App, timeTravel *timetravel.Service, repo *storage.Repository, ref, question string) tea.Cmd {
 	return func() tea.Msg {
 		ctx := context.Background()
-		answer, err := app.RAGService.AnswerQuestion(ctx, collectionName, embedderModelName, question, history)
+		answer, err := timeTravel.AnswerQuestion(ctx, repo, ref, question, nil)
 		if err != nil {
+			app.Logger.Warn("failed to answer question at past commit", "ref", ref, "error", err)
 			return errorMsg{err}
 		}
-		return answerCompleteMsg{content: answer}
+		return answerCompleteMsg{content: answer.Text, citations: answer.Citations}
 	}
 }
 
@@ -127,6 +182,16 @@ func addRepoCmd(app *app.App, fullName, path string) tea.Cmd {
 	}
 }
 
+func removeRepoCmd(app *app.App, fullName string) tea.Cmd {
+	return func() tea.Msg {
+		if app.Dispatcher != nil {
+			app.Dispatcher.CancelRepo(fullName)
+		}
+		err := app.RepoMgr.DeleteRepo(context.Background(), fullName)
+		return repoRemovedMsg{repoFullName: fullName, err: err}
+	}
+}
+
 func loadReposCmd(app *app.App) tea.Cmd {
 	return func() tea.Msg {
 		repos, err := app.Store.GetAllRepositories(context.Background())
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/sevigo/code-warden/internal/app"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// resolveGroup looks up the repositories named by /group, in the order
+// given and without duplicates.
+func resolveGroup(names []string, repos []*storage.Repository) ([]*storage.Repository, error) {
+	byName := make(map[string]*storage.Repository, len(repos))
+	for _, repo := range repos {
+		byName[repo.FullName] = repo
+	}
+	var group []*storage.Repository
+	seen := make(map[string]bool)
+	for _, name := range names {
+		repo, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("repository '%s' not found", name)
+		}
+		if !seen[name] {
+			seen[name] = true
+			group = append(group, repo)
+		}
+	}
+	if len(group) < 2 {
+		return nil, fmt.Errorf("a group needs at least two repositories, use /select for one")
+	}
+	return group, nil
+}
+
+func groupNames(group []*storage.Repository) []string {
+	names := make([]string, 0, len(group))
+	for _, repo := range group {
+		names = append(names, repo.FullName)
+	}
+	return names
+}
+
+// askGroupCmd answers a question across the repositories of a group. Group
+// conversations are not recorded in a session; only their latest
+// chatSummarizeAfter messages are sent with the question.
+func askGroupCmd(app *app.App, group []*storage.Repository, question string, turns []string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		repos := make([]core.RepoCollection, 0, len(group))
+		for _, repo := range group {
+			repo, err := warmRepo(ctx, app, repo)
+			if err != nil {
+				return errorMsg{err}
+			}
+			repos = append(repos, core.RepoCollection{Repo: repo.FullName, Collection: repo.QdrantCollectionName, EmbedderModel: repo.EmbedderModel})
+		}
+		history := turns[max(0, len(turns)-chatSummarizeAfter):]
+		answer, err := app.RAGService.AnswerQuestionAcross(ctx, repos, app.Cfg.AI.EmbedderModel, question, history)
+		if err != nil {
+			return errorMsg{err}
+		}
+		return answerCompleteMsg{content: answer.Text, citations: answer.Citations, followUp: true}
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+func TestResolveGroup(t *testing.T) {
+	repos := []*storage.Repository{{FullName: "acme/api"}, {FullName: "acme/web"}, {FullName: "acme/docs"}}
+	tests := []struct {
+		names   []string
+		want    []string
+		wantErr bool
+	}{
+		{names: []string{"acme/web", "acme/api"}, want: []string{"acme/web", "acme/api"}},
+		{names: []string{"acme/api", "acme/web", "acme/api"}, want: []string{"acme/api", "acme/web"}},
+		{names: []string{"acme/api", "acme/api"}, wantErr: true},
+		{names: []string{"acme/api", "acme/cli"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		group, err := resolveGroup(tt.names, repos)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolveGroup(%q) error = %v, wantErr %v", tt.names, err, tt.wantErr)
+			continue
+		}
+		if got := groupNames(group); err == nil && !slices.Equal(got, tt.want) {
+			t.Errorf("resolveGroup(%q) = %q, want %q", tt.names, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/sevigo/code-warden/internal/app"
+	"github.com/sevigo/code-warden/internal/core"
+	ragreview "github.com/sevigo/code-warden/internal/rag/review"
+)
+
+const (
+	// recentReviewsLimit is how many reviews /history lists without a
+	// repository or pull request.
+	recentReviewsLimit = 20
+	// suggestionsPerPage is how many suggestions /show, /next and /prev
+	// render at a time.
+	suggestionsPerPage = 5
+)
+
+// openedReview is the review shown by /show, with the page of suggestions
+// on screen.
+type openedReview struct {
+	review *core.Review
+	parsed *core.StructuredReview
+	page   int
+}
+
+func (o *openedReview) pages() int {
+	return max(1, (len(o.parsed.Suggestions)+suggestionsPerPage-1)/suggestionsPerPage)
+}
+
+// historyQuery is what /history lists: the reviews of a pull request, of a
+// repository or the latest ones across all repositories.
+type historyQuery struct {
+	repo string
+	pr   int
+}
+
+// parseHistoryArgs reads "/history [pr|owner/repo]". A pull request number
+// refers to the selected repository.
+func parseHistoryArgs(args []string, selectedRepo string) (historyQuery, error) {
+	switch len(args) {
+	case 0:
+		return historyQuery{repo: selectedRepo}, nil
+	case 1:
+		arg := strings.TrimPrefix(args[0], "#")
+		if pr, err := strconv.Atoi(arg); err == nil {
+			if pr <= 0 {
+				return historyQuery{}, fmt.Errorf("invalid pull request number: %s", args[0])
+			}
+			if selectedRepo == "" {
+				return historyQuery{}, fmt.Errorf("select a repository to list the reviews of pull request #%d", pr)
+			}
+			return historyQuery{repo: selectedRepo, pr: pr}, nil
+		}
+		if !strings.Contains(arg, "/") {
+			return historyQuery{}, fmt.Errorf("expected a pull request number or owner/repo, got %q", args[0])
+		}
+		return historyQuery{repo: arg}, nil
+	default:
+		return historyQuery{}, fmt.Errorf("USAGE: /history [pr|owner/repo]")
+	}
+}
+
+func (q historyQuery) title() string {
+	switch {
+	case q.pr > 0:
+		return fmt.Sprintf("REVIEWS OF %s#%d", q.repo, q.pr)
+	case q.repo != "":
+		return "REVIEWS OF " + q.repo
+	default:
+		return "RECENT REVIEWS"
+	}
+}
+
+func loadHistoryCmd(app *app.App, q historyQuery) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		var reviews []*core.Review
+		var err error
+		switch {
+		case q.pr > 0:
+			reviews, err = app.Store.GetAllReviewsForPR(ctx, q.repo, q.pr)
+		case q.repo != "":
+			reviews, err = app.Store.GetReviewsForRepo(ctx, q.repo)
+		default:
+			reviews, err = app.Store.GetRecentReviews(ctx, recentReviewsLimit)
+		}
+		return historyLoadedMsg{title: q.title(), reviews: reviews, err: err}
+	}
+}
+
+// parseStoredReview parses the raw model output a review was saved as. Output
+// that parses as neither JSON, XML nor the legacy format is shown as the
+// summary.
+func parseStoredReview(logger *slog.Logger, content string) *core.StructuredReview {
+	parser := ragreview.NewStructuredReviewParser(logger)
+	parser.PreferJSON = !strings.Contains(content, "<review")
+	parsed, err := parser.Parse(context.Background(), content)
+	if err != nil || parsed == nil {
+		return &core.StructuredReview{Summary: content}
+	}
+	return parsed
+}
+
+// historyList renders the listing of /history; the numbers are those /show
+// takes.
+func historyList(reviews []*core.Review) string {
+	var b strings.Builder
+	for i, r := range reviews {
+		sha := r.HeadSHA
+		if len(sha) > 7 {
+			sha = sha[:7]
+		}
+		fmt.Fprintf(&b, "\n  [%d] %s#%d  %s  %s", i+1, r.RepoFullName, r.PRNumber, sha, r.CreatedAt.Format("2006-01-02 15:04"))
+		if r.Degraded {
+			b.WriteString("  (degraded)")
+		}
+	}
+	return b.String()
+}
+
+// markdown renders the page of the review on screen. The first page starts
+// with the summary.
+func (o *openedReview) markdown() string {
+	var b strings.Builder
+	r, parsed := o.review, o.parsed
+	fmt.Fprintf(&b, "## %s#%d\n\n", r.RepoFullName, r.PRNumber)
+	fmt.Fprintf(&b, "Reviewed `%s` on %s", r.HeadSHA, r.CreatedAt.Format("2006-01-02 15:04"))
+	if parsed.Verdict != "" {
+		fmt.Fprintf(&b, " · **%s**", parsed.Verdict)
+	}
+	b.WriteString("\n\n")
+	if o.page == 0 && parsed.Summary != "" {
+		b.WriteString(parsed.Summary)
+		b.WriteString("\n\n")
+	}
+
+	if len(parsed.Suggestions) == 0 {
+		b.WriteString("_No suggestions._\n")
+		return b.String()
+	}
+	start := o.page * suggestionsPerPage
+	end := min(start+suggestionsPerPage, len(parsed.Suggestions))
+	fmt.Fprintf(&b, "### Suggestions %d–%d of %d\n\n", start+1, end, len(parsed.Suggestions))
+	for i, s := range parsed.Suggestions[start:end] {
+		fmt.Fprintf(&b, "**%d. %s** `%s:%d`", start+i+1, s.Severity, s.FilePath, s.LineNumber)
+		if s.Category != "" {
+			fmt.Fprintf(&b, " · %s", s.Category)
+		}
+		b.WriteString("\n\n")
+		b.WriteString(s.Comment)
+		b.WriteString("\n\n")
+		if s.CodeSuggestion != "" {
+			fmt.Fprintf(&b, "```\n%s\n```\n\n", strings.TrimRight(s.CodeSuggestion, "\n"))
+		}
+	}
+	if o.pages() > 1 {
+		fmt.Fprintf(&b, "_Page %d of %d — /next, /prev_\n", o.page+1, o.pages())
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+func TestParseHistoryArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		selected string
+		want     historyQuery
+		wantErr  bool
+	}{
+		{args: nil, selected: "", want: historyQuery{}},
+		{args: nil, selected: "acme/api", want: historyQuery{repo: "acme/api"}},
+		{args: []string{"#12"}, selected: "acme/api", want: historyQuery{repo: "acme/api", pr: 12}},
+		{args: []string{"acme/web"}, selected: "acme/api", want: historyQuery{repo: "acme/web"}},
+		{args: []string{"12"}, selected: "", wantErr: true},
+		{args: []string{"0"}, selected: "acme/api", wantErr: true},
+		{args: []string{"api"}, selected: "", wantErr: true},
+		{args: []string{"acme/api", "3"}, selected: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseHistoryArgs(tt.args, tt.selected)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseHistoryArgs(%q, %q) error = %v, wantErr %v", tt.args, tt.selected, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseHistoryArgs(%q, %q) = %+v, want %+v", tt.args, tt.selected, got, tt.want)
+		}
+	}
+}
+
+func TestOpenedReviewPaging(t *testing.T) {
+	parsed := &core.StructuredReview{Summary: "Looks mostly fine.", Verdict: core.VerdictComment}
+	for i := range 7 {
+		parsed.Suggestions = append(parsed.Suggestions, core.Suggestion{
+			FilePath: "main.go", LineNumber: i + 1, Severity: "Low", Comment: fmt.Sprintf("comment %d", i+1),
+		})
+	}
+	o := &openedReview{
+		review: &core.Review{RepoFullName: "acme/api", PRNumber: 3, HeadSHA: "abc1234", CreatedAt: time.Now()},
+		parsed: parsed,
+	}
+	if o.pages() != 2 {
+		t.Fatalf("pages() = %d, want 2", o.pages())
+	}
+
+	first := o.markdown()
+	for _, want := range []string{"Looks mostly fine.", "Suggestions 1–5 of 7", "comment 5", "Page 1 of 2"} {
+		if !strings.Contains(first, want) {
+			t.Errorf("first page is missing %q:\n%s", want, first)
+		}
+	}
+
+	o.page = 1
+	second := o.markdown()
+	if strings.Contains(second, "Looks mostly fine.") || strings.Contains(second, "comment 5") {
+		t.Errorf("second page repeats the first:\n%s", second)
+	}
+	if !strings.Contains(second, "Suggestions 6–7 of 7") {
+		t.Errorf("second page is missing its suggestions:\n%s", second)
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+
+	"github.com/sevigo/code-warden/internal/app"
+	"github.com/sevigo/code-warden/internal/pathutil"
+	"github.com/sevigo/code-warden/internal/rag/metadata"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// inspectChunkLimit caps how many chunks /inspect fetches for a file.
+const inspectChunkLimit = 200
+
+// inspectedChunk is one stored chunk of the file shown by /inspect.
+type inspectedChunk struct {
+	line, endLine int
+	chunkType     string
+	identifier    string
+	size          int
+	lastModified  time.Time
+}
+
+func chunkFromDocument(doc schema.Document) inspectedChunk {
+	c := inspectedChunk{
+		line:    metadata.ExtractLineNumber(doc.Metadata),
+		endLine: int(metadata.ExtractInt64(doc.Metadata, "end_line")),
+		size:    len(doc.PageContent),
+	}
+	c.chunkType, _ = doc.Metadata["chunk_type"].(string)
+	c.identifier, _ = doc.Metadata["identifier"].(string)
+	if ts := metadata.ExtractInt64(doc.Metadata, "last_modified"); ts > 0 {
+		c.lastModified = time.Unix(ts, 0)
+	}
+	return c
+}
+
+func inspectFileCmd(app *app.App, repo *storage.Repository, path string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		files, err := app.Store.GetFilesForRepo(ctx, repo.ID)
+		if err != nil {
+			return inspectLoadedMsg{path: path, err: fmt.Errorf("failed to load indexed files: %w", err)}
+		}
+		record, tracked := files[path]
+
+		repo, err = warmRepo(ctx, app, repo)
+		if err != nil {
+			return inspectLoadedMsg{path: path, err: err}
+		}
+		store := app.VectorStore.ForRepo(repo.QdrantCollectionName, repo.Embedder(app.Cfg.AI.EmbedderModel))
+		docs, err := store.SimilaritySearch(ctx, path, inspectChunkLimit,
+			vectorstores.WithFilters(map[string]any{"source": path}))
+		if err != nil {
+			return inspectLoadedMsg{path: path, err: fmt.Errorf("failed to query chunks: %w", err)}
+		}
+
+		chunks := make([]inspectedChunk, 0, len(docs))
+		for _, doc := range docs {
+			chunks = append(chunks, chunkFromDocument(doc))
+		}
+		msg := inspectLoadedMsg{path: path, chunks: chunks}
+		if tracked {
+			msg.record = &record
+		}
+		return msg
+	}
+}
+
+// inspectMarkdown renders the chunks of a file in the order they appear in
+// it, with the index time and chunk count of its file record. Summaries
+// stored under the file's path, such as arch chunks, are listed too, so the
+// counts of the record and the vector store can differ.
+func inspectMarkdown(path string, record *storage.FileRecord, chunks []inspectedChunk) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", path)
+	if record != nil {
+		fmt.Fprintf(&b, "Indexed %s · hash `%s` · %d chunks recorded\n\n",
+			record.LastIndexedAt.Format("2006-01-02 15:04"), shortHash(record.FileHash), record.ChunkCount)
+	} else {
+		b.WriteString("_Not in the file table, the file was never indexed or was skipped._\n\n")
+	}
+	if len(chunks) == 0 {
+		b.WriteString("_No chunks in the vector store._\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%d chunks in the vector store\n\n", len(chunks))
+
+	slices.SortStableFunc(chunks, func(a, b inspectedChunk) int {
+		return cmp.Or(cmp.Compare(a.line, b.line), cmp.Compare(a.chunkType, b.chunkType))
+	})
+	b.WriteString("| # | Lines | Type | Identifier | Size | Last modified |\n")
+	b.WriteString("|---|-------|------|------------|------|---------------|\n")
+	for i, c := range chunks {
+		lines := "–"
+		if c.line > 0 {
+			lines = fmt.Sprintf("%d–%d", c.line, max(c.line, c.endLine))
+		}
+		modified := "–"
+		if !c.lastModified.IsZero() {
+			modified = c.lastModified.Format("2006-01-02")
+		}
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %d | %s |\n",
+			i+1, lines, orDash(c.chunkType), orDash(escapeCell(c.identifier)), c.size, modified)
+	}
+	return b.String()
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "–"
+	}
+	return s
+}
+
+// escapeCell keeps an identifier from breaking the markdown table.
+func escapeCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+// cleanInspectPath turns the argument of /inspect into the repository
+// relative, slash separated path chunks are stored under.
+func cleanInspectPath(arg string) string {
+	return pathutil.Clean(arg)
+}
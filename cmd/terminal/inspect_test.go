@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sevigo/goframe/schema"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+func TestInspectMarkdown(t *testing.T) {
+	docs := []schema.Document{
+		{PageContent: "func Serve() {}", Metadata: map[string]any{
+			"source": "server.go", "line": float64(40), "end_line": float64(52), "chunk_type": "code", "identifier": "Serve",
+		}},
+		{PageContent: "package server", Metadata: map[string]any{
+			"source": "server.go", "line": 1, "end_line": 12, "chunk_type": "code", "identifier": "a|b",
+			"last_modified": time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC).Unix(),
+		}},
+	}
+	var chunks []inspectedChunk
+	for _, doc := range docs {
+		chunks = append(chunks, chunkFromDocument(doc))
+	}
+	record := &storage.FileRecord{FilePath: "server.go", FileHash: "0123456789abcdef", ChunkCount: 2, LastIndexedAt: time.Now()}
+
+	got := inspectMarkdown("server.go", record, chunks)
+	for _, want := range []string{"`0123456789ab`", "2 chunks recorded", "| 1 | 1–12 | code | a\\|b | 14 | 2026-03-01 |", "| 2 | 40–52 | code | Serve | 15 | – |"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("inspectMarkdown is missing %q:\n%s", want, got)
+		}
+	}
+
+	got = inspectMarkdown("gen.pb.go", nil, nil)
+	if !strings.Contains(got, "Not in the file table") || !strings.Contains(got, "No chunks") {
+		t.Errorf("inspectMarkdown of an unindexed file:\n%s", got)
+	}
+}
+
+func TestCleanInspectPath(t *testing.T) {
+	for arg, want := range map[string]string{"./cmd/main.go": "cmd/main.go", "internal//app/": "internal/app", "a.go": "a.go"} {
+		if got := cleanInspectPath(arg); got != want {
+			t.Errorf("cleanInspectPath(%q) = %q, want %q", arg, got, want)
+		}
+	}
+}
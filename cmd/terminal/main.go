@@ -9,9 +9,11 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/sandbox"
 )
 
 func main() {
+	sandbox.ServeParseWorker()
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		fmt.Printf("Failed to load configuration: %v\n", err)
@@ -55,7 +57,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	p := tea.NewProgram(initialModel(theme), tea.WithAltScreen())
+	p := tea.NewProgram(initialModel(theme, cfg), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		slog.Error("error running program", "error", err)
 		fmt.Printf("Error running program: %v\n", err)
@@ -2,6 +2,8 @@ package main
 
 import (
 	"github.com/sevigo/code-warden/internal/app"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/health"
 	"github.com/sevigo/code-warden/internal/storage"
 )
 
@@ -26,8 +28,22 @@ type repoAddedMsg struct {
 	err          error
 }
 
-// Represents a complete, non-streaming answer from the LLM.
-type answerCompleteMsg struct{ content string }
+// Indicates that a repository was unregistered. err may be set even though
+// the repository was removed, when some of its data could not be deleted.
+type repoRemovedMsg struct {
+	repoFullName string
+	err          error
+}
+
+// Represents a complete, non-streaming answer from the LLM and the chunks it
+// cites. followUp is set for answers that continue the conversation, and
+// session for those recorded in a chat session.
+type answerCompleteMsg struct {
+	content   string
+	citations []core.Citation
+	followUp  bool
+	session   *storage.ChatSession
+}
 
 type explainCompleteMsg struct {
 	path    string
@@ -42,7 +58,56 @@ func (e errorMsg) Error() string {
 	return e.err.Error()
 }
 
+// Carries the reviews listed by /history.
+type historyLoadedMsg struct {
+	title   string
+	reviews []*core.Review
+	err     error
+}
+
 type reposLoadedMsg struct {
 	repos []*storage.Repository
 	err   error
 }
+
+// Carries the chunks of the file shown by /inspect.
+type inspectLoadedMsg struct {
+	path   string
+	record *storage.FileRecord
+	chunks []inspectedChunk
+	err    error
+}
+
+// Carries the chat sessions listed by /sessions.
+type sessionsLoadedMsg struct {
+	repoFullName string
+	sessions     []*storage.ChatSession
+	err          error
+}
+
+// Carries the session reopened by /resume and its messages.
+type sessionResumedMsg struct {
+	session  *storage.ChatSession
+	messages []*storage.ChatMessage
+	err      error
+}
+
+// Carries a status change of the /watch file watcher. changed counts the
+// files of a finished update.
+type watchStatusMsg struct {
+	repoFullName string
+	status       string
+	changed      int
+	err          error
+}
+
+// Carries the outcome of the /setup connectivity checks.
+type setupChecksMsg struct {
+	checks []health.Check
+}
+
+// Carries the indexing progress of a scan: done of total files.
+type scanProgressMsg struct {
+	done  int
+	total int
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -13,7 +16,10 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/sevigo/code-warden/internal/app"
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/timetravel"
 )
 
 const asciiLogo = `
@@ -32,10 +38,15 @@ const asciiLogo = `
 `
 
 type model struct {
-	styles    styles
+	styles styles
+	// cfg is the configuration loaded at startup, used by /setup while the
+	// application is not running.
+	cfg       *config.Config
 	app       *app.App
 	cleanup   func()
 	isLoading bool
+	// timeTravel answers /ask --at questions against past commits.
+	timeTravel *timetravel.Service
 
 	// UI Components
 	viewport viewport.Model
@@ -44,13 +55,39 @@ type model struct {
 	renderer *glamour.TermRenderer
 
 	// --- REFACTORED STATE MANAGEMENT ---
-	availableRepos      []*storage.Repository
-	selectedRepo        *storage.Repository
+	availableRepos []*storage.Repository
+	selectedRepo   *storage.Repository
+	// repoGroup holds the repositories questions go to after /group, in
+	// place of selectedRepo.
+	repoGroup           []*storage.Repository
 	history             []string
 	conversationHistory []string
+	// chatSession is the session the conversation is recorded in, nil until
+	// its first question; sessionList holds the sessions last listed by
+	// /sessions.
+	chatSession *storage.ChatSession
+	sessionList []*storage.ChatSession
+
+	// reviewList holds the reviews last listed by /history, and shownReview
+	// the one opened with /show.
+	reviewList  []*core.Review
+	shownReview *openedReview
+
+	// watcher keeps the index of a repository in step with its working tree
+	// after /watch; watchStatus is shown in the footer.
+	watcher     *repoWatcher
+	watchStatus string
+
+	// scan reports the progress of the running scan, shown next to the
+	// spinner as scanStatus.
+	scan       *scanProgress
+	scanStatus string
+
+	// setup is the state of the /setup wizard while it runs.
+	setup *setupWizard
 }
 
-func initialModel(theme ThemeName) *model {
+func initialModel(theme ThemeName, cfg *config.Config) *model {
 	styles := GetTheme(theme)
 	ta := textarea.New()
 	ta.Placeholder = "Enter a command or ask a question..."
@@ -72,6 +109,7 @@ func initialModel(theme ThemeName) *model {
 
 	return &model{
 		styles:    styles,
+		cfg:       cfg,
 		textarea:  ta,
 		spinner:   sp,
 		isLoading: true,
@@ -112,12 +150,28 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 	case repoAddedMsg:
 		return m, m.handleRepoAddedMsg(msg)
+	case repoRemovedMsg:
+		return m, m.handleRepoRemovedMsg(msg)
+	case historyLoadedMsg:
+		m.handleHistoryLoadedMsg(msg)
+	case inspectLoadedMsg:
+		m.handleInspectLoadedMsg(msg)
+	case sessionsLoadedMsg:
+		m.handleSessionsLoadedMsg(msg)
+	case sessionResumedMsg:
+		m.handleSessionResumedMsg(msg)
 	case scanCompleteMsg:
 		return m, m.handleScanCompleteMsg(msg)
+	case scanProgressMsg:
+		return m, m.handleScanProgressMsg(msg)
+	case setupChecksMsg:
+		return m, m.handleSetupChecksMsg(msg)
 	case explainCompleteMsg:
 		m.handleExplainCompleteMsg(msg)
 	case answerCompleteMsg:
 		m.handleAnswerCompleteMsg(msg)
+	case watchStatusMsg:
+		return m, m.handleWatchStatusMsg(msg)
 	case errorMsg:
 		m.isLoading = false
 		m.history = append(m.history, m.styles.error.Render("⚠ "+msg.err.Error()))
@@ -133,24 +187,34 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m *model) View() string {
-	if m.app == nil {
+	if m.app == nil && m.isLoading && m.setup == nil {
 		return fmt.Sprintf("\n  %s BOOTING SYSTEM...\n\n", m.spinner.View())
 	}
 
 	var statusParts []string
-	if m.selectedRepo != nil {
+	switch {
+	case m.selectedRepo != nil:
 		statusParts = append(statusParts, fmt.Sprintf("REPO: %s", m.selectedRepo.FullName))
 		if len(m.selectedRepo.LastIndexedSHA) >= 7 {
 			statusParts = append(statusParts, fmt.Sprintf("COMMIT: %s", m.selectedRepo.LastIndexedSHA[:7]))
 		}
-	} else {
+	case len(m.repoGroup) > 0:
+		statusParts = append(statusParts, fmt.Sprintf("REPOS: %s", strings.Join(groupNames(m.repoGroup), ", ")))
+	default:
 		statusParts = append(statusParts, "REPO: None Selected")
 	}
+	if m.watcher != nil {
+		statusParts = append(statusParts, fmt.Sprintf("WATCH: %s (%s)", m.watcher.repo.FullName, m.watchStatus))
+	}
 	status := m.styles.inactive.Render(strings.Join(statusParts, " │ "))
 
 	loadingIndicator := ""
 	if m.isLoading {
-		loadingIndicator = " " + m.spinner.View() + " " + m.styles.success.Render("PROCESSING...")
+		activity := "PROCESSING..."
+		if m.scanStatus != "" {
+			activity = m.scanStatus
+		}
+		loadingIndicator = " " + m.spinner.View() + " " + m.styles.success.Render(activity)
 	}
 
 	return m.styles.app.Render(
@@ -166,6 +230,10 @@ func (m *model) View() string {
 func (m *model) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
 	switch msg.Type {
 	case tea.KeyCtrlC, tea.KeyEsc:
+		m.stopWatching()
+		if m.timeTravel != nil {
+			m.timeTravel.Close(context.Background())
+		}
 		if m.cleanup != nil {
 			m.cleanup()
 		}
@@ -184,10 +252,19 @@ func (m *model) handleAppInitializedMsg(msg appInitializedMsg) tea.Cmd {
 	m.isLoading = false
 	if msg.err != nil {
 		m.history = append(m.history, m.styles.error.Render(msg.err.Error()))
+		if m.setup != nil && m.setup.step == setupConnecting {
+			m.handleSetupAppInitialized(msg.err)
+			return nil
+		}
+		m.history = append(m.history, m.styles.inactive.Render("Run /setup to check the services Code-Warden needs."))
 		return nil
 	}
 	m.app = msg.app
 	m.cleanup = msg.cleanup
+	m.timeTravel = timetravel.New(m.app.Cfg, m.app.RAGService, m.app.GitClient, m.app.Logger)
+	if m.setup != nil && m.setup.step == setupConnecting {
+		m.handleSetupAppInitialized(nil)
+	}
 	return loadReposCmd(m.app)
 }
 
@@ -208,6 +285,9 @@ func (m *model) handleReposLoadedMsg(msg reposLoadedMsg) tea.Cmd {
 }
 
 func (m *model) handleRepoAddedMsg(msg repoAddedMsg) tea.Cmd {
+	if m.setup != nil && m.setup.step == setupScanning {
+		return m.handleSetupRepoAdded(msg)
+	}
 	m.isLoading = true
 	if msg.err != nil {
 		m.isLoading = false
@@ -215,11 +295,66 @@ func (m *model) handleRepoAddedMsg(msg repoAddedMsg) tea.Cmd {
 		return loadReposCmd(m.app)
 	}
 	m.history = append(m.history, m.styles.success.Render(fmt.Sprintf("✅ REPO REGISTERED: %s", msg.repoFullName)), m.styles.command.Render("→ Starting initial scan..."))
-	return tea.Batch(m.spinner.Tick, scanRepoCmd(m.app, msg.repoPath, msg.repoFullName, true))
+	return tea.Batch(m.spinner.Tick, m.startScan(msg.repoPath, msg.repoFullName, true))
+}
+
+func (m *model) handleRepoRemovedMsg(msg repoRemovedMsg) tea.Cmd {
+	m.isLoading = false
+	if msg.err != nil {
+		m.history = append(m.history, m.styles.error.Render("REMOVE FAILED: "+msg.err.Error()))
+	} else {
+		m.history = append(m.history, m.styles.success.Render(fmt.Sprintf("✓ REPO REMOVED: %s", msg.repoFullName)))
+	}
+	if m.watcher != nil && m.watcher.repo.FullName == msg.repoFullName {
+		m.stopWatching()
+	}
+	if m.selectedRepo != nil && m.selectedRepo.FullName == msg.repoFullName {
+		m.selectedRepo = nil
+		m.resetConversation()
+	}
+	if slices.Contains(groupNames(m.repoGroup), msg.repoFullName) {
+		m.repoGroup = nil
+		m.resetConversation()
+	}
+	return loadReposCmd(m.app)
+}
+
+func (m *model) handleHistoryLoadedMsg(msg historyLoadedMsg) {
+	m.isLoading = false
+	if msg.err != nil {
+		m.history = append(m.history, m.styles.error.Render("HISTORY FAILED: "+msg.err.Error()))
+		return
+	}
+	m.reviewList = msg.reviews
+	if len(msg.reviews) == 0 {
+		m.history = append(m.history, m.styles.inactive.Render("No reviews found."))
+		return
+	}
+	m.history = append(m.history, m.styles.success.Render(msg.title+":")+historyList(msg.reviews),
+		m.styles.inactive.Render("Open one with /show [n]."))
+}
+
+func (m *model) handleInspectLoadedMsg(msg inspectLoadedMsg) {
+	m.isLoading = false
+	if msg.err != nil {
+		m.history = append(m.history, m.styles.error.Render("INSPECT FAILED: "+msg.err.Error()))
+		return
+	}
+	content := inspectMarkdown(msg.path, msg.record, msg.chunks)
+	formatted, err := m.renderer.Render(content)
+	if err != nil {
+		formatted = content
+	}
+	m.history = append(m.history, formatted)
 }
 
 func (m *model) handleScanCompleteMsg(msg scanCompleteMsg) tea.Cmd {
 	m.isLoading = false
+	m.scan = nil
+	m.scanStatus = ""
+	if m.setup != nil && m.setup.step == setupScanning {
+		return m.handleSetupScanComplete(msg)
+	}
 	if msg.err != nil {
 		m.history = append(m.history, m.styles.error.Render("SCAN FAILED: "+msg.err.Error()))
 		return nil
@@ -230,14 +365,83 @@ func (m *model) handleScanCompleteMsg(msg scanCompleteMsg) tea.Cmd {
 	})
 }
 
+// startScan scans a repository and shows the indexing progress.
+func (m *model) startScan(path, repoFullName string, force bool) tea.Cmd {
+	m.scan = &scanProgress{updates: make(chan scanProgressMsg, 1), done: make(chan struct{})}
+	m.scanStatus = "SCANNING..."
+	return tea.Batch(scanWithProgressCmd(m.app, path, repoFullName, force, m.scan), waitForScanProgressCmd(m.scan))
+}
+
+func (m *model) handleScanProgressMsg(msg scanProgressMsg) tea.Cmd {
+	if m.scan == nil {
+		return nil
+	}
+	if msg.total > 0 {
+		m.scanStatus = fmt.Sprintf("INDEXING %d%% (%d/%d files)", min(msg.done, msg.total)*100/msg.total, msg.done, msg.total)
+	}
+	if m.setup != nil && m.setup.step == setupScanning {
+		m.history[m.setup.progressLine] = m.styles.command.Render(progressBar(msg.done, msg.total, progressBarWidth))
+	}
+	return waitForScanProgressCmd(m.scan)
+}
+
 func (m *model) handleAnswerCompleteMsg(msg answerCompleteMsg) {
 	m.isLoading = false
-	formattedAnswer, err := m.renderer.Render(msg.content)
+	// The sources are shown, but not passed back as conversation history.
+	content := msg.content + sourcesFooter(msg.citations)
+	formattedAnswer, err := m.renderer.Render(content)
 	if err != nil {
-		formattedAnswer = msg.content
+		formattedAnswer = content
 	}
 	m.history[len(m.history)-1] = formattedAnswer
-	m.conversationHistory = append(m.conversationHistory, fmt.Sprintf("AI: %s", msg.content))
+	if msg.followUp {
+		m.chatSession = msg.session
+		m.conversationHistory = append(m.conversationHistory, fmt.Sprintf("AI: %s", msg.content))
+	}
+}
+
+func (m *model) handleSessionsLoadedMsg(msg sessionsLoadedMsg) {
+	m.isLoading = false
+	if msg.err != nil {
+		m.history = append(m.history, m.styles.error.Render("SESSIONS FAILED: "+msg.err.Error()))
+		return
+	}
+	m.sessionList = msg.sessions
+	if len(msg.sessions) == 0 {
+		m.history = append(m.history, m.styles.inactive.Render("No conversations found."))
+		return
+	}
+	m.history = append(m.history, m.styles.success.Render("CONVERSATIONS ABOUT "+msg.repoFullName+":")+sessionList(msg.sessions),
+		m.styles.inactive.Render("Continue one with /resume [n]."))
+}
+
+func (m *model) handleSessionResumedMsg(msg sessionResumedMsg) {
+	m.isLoading = false
+	if msg.err != nil {
+		m.history = append(m.history, m.styles.error.Render("RESUME FAILED: "+msg.err.Error()))
+		return
+	}
+	m.chatSession = msg.session
+	m.conversationHistory = nil
+	for _, message := range msg.messages {
+		m.conversationHistory = append(m.conversationHistory, messageLine(message))
+	}
+	m.history = append(m.history, m.styles.success.Render(fmt.Sprintf("✓ Resumed: %s (%d messages)", msg.session.Title, len(msg.messages))))
+	replay := msg.messages[max(0, len(msg.messages)-chatReplayMessages):]
+	if len(replay) < len(msg.messages) {
+		m.history = append(m.history, m.styles.inactive.Render(fmt.Sprintf("… %d earlier messages", len(msg.messages)-len(replay))))
+	}
+	for _, message := range replay {
+		if message.Role == storage.ChatRoleUser {
+			m.history = append(m.history, m.styles.prompt.Render("► ")+message.Content)
+			continue
+		}
+		formatted, err := m.renderer.Render(message.Content)
+		if err != nil {
+			formatted = message.Content
+		}
+		m.history = append(m.history, formatted)
+	}
 }
 
 func (m *model) handleExplainCompleteMsg(msg explainCompleteMsg) {
@@ -255,28 +459,62 @@ func (m *model) handleExplainCompleteMsg(msg explainCompleteMsg) {
 
 func (m *model) processCommand(input string) tea.Cmd {
 	m.history = append(m.history, m.styles.prompt.Render("► ")+input)
+	if m.setup != nil && !strings.HasPrefix(input, "/") {
+		return m.answerSetup(input)
+	}
 	parts := strings.Fields(input)
 	command := parts[0]
 	args := parts[1:]
 
+	// Without the application only the commands that need none work.
+	if m.app == nil && !slices.Contains([]string{"/setup", "/help", "/h", "/exit", "/quit"}, command) {
+		m.history = append(m.history, m.styles.error.Render("Code-Warden is not running. Run /setup to check the services it needs."))
+		return nil
+	}
+
 	switch command {
+	case "/setup":
+		return m.processSetupCommand(args)
 	case "/add":
 		return m.processAddCommand(args)
 	case "/list", "/ls":
 		return m.processListCommand()
 	case "/select":
 		return m.processSelectCommand(args)
+	case "/group":
+		return m.processGroupCommand(args)
 	case "/rescan":
 		return m.processRescanCommand(args)
+	case "/remove", "/rm":
+		return m.processRemoveCommand(args)
+	case "/history":
+		return m.processHistoryCommand(args)
+	case "/show":
+		return m.processShowCommand(args)
+	case "/next":
+		return m.turnReviewPage(1)
+	case "/prev":
+		return m.turnReviewPage(-1)
 	case "/explain":
 		return m.processExplainCommand(args)
+	case "/inspect":
+		return m.processInspectCommand(args)
+	case "/ask":
+		return m.processAskCommand(input)
+	case "/sessions":
+		return m.processSessionsCommand()
+	case "/resume":
+		return m.processResumeCommand(args)
 	case "/new", "/reset":
-		m.conversationHistory = nil
+		m.resetConversation()
 		m.history = append(m.history, m.styles.inactive.Render("🧹 Conversation history cleared."))
 		return nil
 	case "/help", "/h":
 		return m.processHelpCommand()
+	case "/watch":
+		return m.processWatchCommand(args)
 	case "/exit", "/quit":
+		m.stopWatching()
 		return tea.Quit
 	default: // Treat as a question
 		return m.processQuestion(input)
@@ -315,8 +553,10 @@ func (m *model) processSelectCommand(args []string) tea.Cmd {
 	for _, repo := range m.availableRepos {
 		if repo.FullName == args[0] {
 			m.selectedRepo = repo
+			m.repoGroup = nil
 			m.history = append(m.history, m.styles.success.Render(fmt.Sprintf("✓ Context set to: %s", args[0])))
-			m.conversationHistory = nil // Reset history on repo switch
+			m.resetConversation() // Reset history on repo switch
+			m.sessionList = nil
 			return nil
 		}
 	}
@@ -324,6 +564,55 @@ func (m *model) processSelectCommand(args []string) tea.Cmd {
 	return nil
 }
 
+func (m *model) processGroupCommand(args []string) tea.Cmd {
+	if len(args) < 2 {
+		m.history = append(m.history, m.styles.error.Render("USAGE: /group [name] [name]..."))
+		return nil
+	}
+	group, err := resolveGroup(args, m.availableRepos)
+	if err != nil {
+		m.history = append(m.history, m.styles.error.Render(err.Error()))
+		return nil
+	}
+	m.repoGroup = group
+	m.selectedRepo = nil
+	m.sessionList = nil
+	m.resetConversation()
+	m.history = append(m.history, m.styles.success.Render(fmt.Sprintf("✓ Context set to: %s", strings.Join(groupNames(group), ", "))),
+		m.styles.inactive.Render("Questions now search all of them. Group conversations are not saved."))
+	return nil
+}
+
+// resetConversation starts a new conversation; the next question opens a new
+// chat session.
+func (m *model) resetConversation() {
+	m.conversationHistory = nil
+	m.chatSession = nil
+}
+
+func (m *model) processSessionsCommand() tea.Cmd {
+	if m.selectedRepo == nil {
+		m.history = append(m.history, m.styles.error.Render("No repository selected. Use /select [name] first."))
+		return nil
+	}
+	m.isLoading = true
+	return tea.Batch(m.spinner.Tick, loadSessionsCmd(m.app, m.selectedRepo))
+}
+
+func (m *model) processResumeCommand(args []string) tea.Cmd {
+	if len(args) != 1 {
+		m.history = append(m.history, m.styles.error.Render("USAGE: /resume [n] (a number listed by /sessions)"))
+		return nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(m.sessionList) {
+		m.history = append(m.history, m.styles.error.Render(fmt.Sprintf("No conversation [%s], list them with /sessions first.", args[0])))
+		return nil
+	}
+	m.isLoading = true
+	return tea.Batch(m.spinner.Tick, resumeSessionCmd(m.app, m.sessionList[n-1]))
+}
+
 func (m *model) processRescanCommand(args []string) tea.Cmd {
 	var repoName string
 	switch {
@@ -340,20 +629,101 @@ func (m *model) processRescanCommand(args []string) tea.Cmd {
 		if repo.FullName == repoName {
 			m.isLoading = true
 			m.history = append(m.history, m.styles.command.Render(fmt.Sprintf("→ Re-scanning %s for updates...", repoName)))
-			return tea.Batch(m.spinner.Tick, scanRepoCmd(m.app, repo.ClonePath, repoName, false))
+			return tea.Batch(m.spinner.Tick, m.startScan(repo.ClonePath, repoName, false))
 		}
 	}
 	m.history = append(m.history, m.styles.error.Render(fmt.Sprintf("Repository '%s' not found.", repoName)))
 	return nil
 }
 
+func (m *model) processRemoveCommand(args []string) tea.Cmd {
+	if len(args) != 1 {
+		m.history = append(m.history, m.styles.error.Render("USAGE: /remove [name]"))
+		return nil
+	}
+	for _, repo := range m.availableRepos {
+		if repo.FullName == args[0] {
+			m.isLoading = true
+			m.history = append(m.history, m.styles.command.Render(fmt.Sprintf("→ Removing %s...", args[0])))
+			return tea.Batch(m.spinner.Tick, removeRepoCmd(m.app, args[0]))
+		}
+	}
+	m.history = append(m.history, m.styles.error.Render(fmt.Sprintf("Repository '%s' not found.", args[0])))
+	return nil
+}
+
+func (m *model) processHistoryCommand(args []string) tea.Cmd {
+	selected := ""
+	if m.selectedRepo != nil {
+		selected = m.selectedRepo.FullName
+	}
+	q, err := parseHistoryArgs(args, selected)
+	if err != nil {
+		m.history = append(m.history, m.styles.error.Render(err.Error()))
+		return nil
+	}
+	m.isLoading = true
+	return tea.Batch(m.spinner.Tick, loadHistoryCmd(m.app, q))
+}
+
+func (m *model) processShowCommand(args []string) tea.Cmd {
+	if len(args) != 1 {
+		m.history = append(m.history, m.styles.error.Render("USAGE: /show [n] (a number listed by /history)"))
+		return nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(m.reviewList) {
+		m.history = append(m.history, m.styles.error.Render(fmt.Sprintf("No review [%s], list them with /history first.", args[0])))
+		return nil
+	}
+	review := m.reviewList[n-1]
+	m.shownReview = &openedReview{review: review, parsed: parseStoredReview(m.app.Logger, review.ReviewContent)}
+	m.renderShownReview()
+	return nil
+}
+
+func (m *model) turnReviewPage(delta int) tea.Cmd {
+	if m.shownReview == nil {
+		m.history = append(m.history, m.styles.error.Render("Open a review with /show [n] first."))
+		return nil
+	}
+	page := m.shownReview.page + delta
+	if page < 0 || page >= m.shownReview.pages() {
+		m.history = append(m.history, m.styles.inactive.Render("No more suggestions that way."))
+		return nil
+	}
+	m.shownReview.page = page
+	m.renderShownReview()
+	return nil
+}
+
+func (m *model) renderShownReview() {
+	content := m.shownReview.markdown()
+	formatted, err := m.renderer.Render(content)
+	if err != nil {
+		formatted = content
+	}
+	m.history = append(m.history, formatted)
+}
+
 func (m *model) processHelpCommand() tea.Cmd {
 	helpText := m.styles.success.Render("COMMANDS:") + `
+  /setup [cancel]      Check the services, register a first repo, write a config.
   /add [name] [path]   Register & scan a local repository.
   /list, /ls           List all available repositories.
   /select [name]       Set the active repository for questions.
+  /group [name]...     Ask questions across several repositories at once.
   /rescan [name?]      Re-scan a repo for updates (defaults to selected).
+  /remove [name]       Unregister a repo and delete its index (local clones stay).
+  /history [pr|repo]   List past reviews (of a PR of the selected repo, or a repo).
+  /show [n]            Show review [n] of the /history list.
+  /next, /prev         Page through the suggestions of the shown review.
   /explain [path]      Explain a directory or file using arch summaries.
+  /inspect [path]      Show how a file was chunked and indexed.
+  /ask --at [ref] [q]  Ask about the code at a past commit, branch or tag.
+  /watch [stop]        Keep the selected repo's index in step with its working tree.
+  /sessions            List past conversations about the selected repo.
+  /resume [n]          Continue conversation [n] of the /sessions list.
   /new                 Start a new conversation.
   /help                Show this help message.
   /exit, /quit         Exit the application.`
@@ -361,6 +731,25 @@ func (m *model) processHelpCommand() tea.Cmd {
 	return nil
 }
 
+func (m *model) processAskCommand(input string) tea.Cmd {
+	ref, question, ok := timetravel.ParseAsk(input)
+	if !ok {
+		m.history = append(m.history, m.styles.error.Render("USAGE: /ask --at [ref] [question]"))
+		return nil
+	}
+	if m.selectedRepo == nil {
+		m.history = append(m.history, m.styles.error.Render("No repository selected. Use /select [name] first."))
+		return nil
+	}
+	// Answers about the past do not continue the current conversation.
+	m.isLoading = true
+	m.history = append(m.history, m.styles.command.Render(fmt.Sprintf("→ ANALYZING AT %s (the first question about a commit indexes it)... ", ref)))
+	return tea.Batch(
+		m.spinner.Tick,
+		askAtCmd(m.app, m.timeTravel, m.selectedRepo, ref, question),
+	)
+}
+
 func (m *model) processExplainCommand(args []string) tea.Cmd {
 	if len(args) != 1 {
 		m.history = append(m.history, m.styles.error.Render("USAGE: /explain [path]"))
@@ -378,13 +767,34 @@ func (m *model) processExplainCommand(args []string) tea.Cmd {
 		explainPathCmd(
 			m.app,
 			m.selectedRepo.QdrantCollectionName,
-			m.app.Cfg.AI.EmbedderModel,
+			m.selectedRepo.Embedder(m.app.Cfg.AI.EmbedderModel),
 			path,
 		),
 	)
 }
 
+func (m *model) processInspectCommand(args []string) tea.Cmd {
+	if len(args) != 1 {
+		m.history = append(m.history, m.styles.error.Render("USAGE: /inspect [path]"))
+		return nil
+	}
+	if m.selectedRepo == nil {
+		m.history = append(m.history, m.styles.error.Render("No repository selected. Use /select [name] first."))
+		return nil
+	}
+	path := cleanInspectPath(args[0])
+	m.isLoading = true
+	m.history = append(m.history, m.styles.command.Render(fmt.Sprintf("→ INSPECTING: %s...", path)))
+	return tea.Batch(m.spinner.Tick, inspectFileCmd(m.app, m.selectedRepo, path))
+}
+
 func (m *model) processQuestion(input string) tea.Cmd {
+	if len(m.repoGroup) > 0 {
+		m.conversationHistory = append(m.conversationHistory, fmt.Sprintf("User: %s", input))
+		m.isLoading = true
+		m.history = append(m.history, m.styles.command.Render(fmt.Sprintf("→ ANALYZING %d REPOSITORIES... ", len(m.repoGroup))))
+		return tea.Batch(m.spinner.Tick, askGroupCmd(m.app, m.repoGroup, input, slices.Clone(m.conversationHistory)))
+	}
 	if m.selectedRepo == nil {
 		m.history = append(m.history, m.styles.error.Render("No repository selected. Use /select [name] first."))
 		return nil
@@ -395,12 +805,65 @@ func (m *model) processQuestion(input string) tea.Cmd {
 
 	return tea.Batch(
 		m.spinner.Tick,
-		answerQuestionCmd(
-			m.app,
-			m.selectedRepo.QdrantCollectionName,
-			m.app.Cfg.AI.EmbedderModel,
-			input,
-			m.conversationHistory,
-		),
+		askInSessionCmd(m.app, chatTurn{
+			repo:     m.selectedRepo,
+			session:  m.chatSession,
+			question: input,
+			turns:    slices.Clone(m.conversationHistory),
+		}),
 	)
 }
+
+func (m *model) processWatchCommand(args []string) tea.Cmd {
+	switch {
+	case len(args) == 1 && args[0] == "stop":
+		if m.watcher == nil {
+			m.history = append(m.history, m.styles.inactive.Render("Not watching any repository."))
+			return nil
+		}
+		name := m.watcher.repo.FullName
+		m.stopWatching()
+		m.history = append(m.history, m.styles.success.Render(fmt.Sprintf("✓ Stopped watching %s", name)))
+		return nil
+	case len(args) != 0:
+		m.history = append(m.history, m.styles.error.Render("USAGE: /watch or /watch stop"))
+		return nil
+	case m.selectedRepo == nil:
+		m.history = append(m.history, m.styles.error.Render("No repository selected. Use /select [name] first."))
+		return nil
+	}
+	m.stopWatching()
+	w, err := startWatcher(m.app, m.selectedRepo)
+	if err != nil {
+		m.history = append(m.history, m.styles.error.Render("WATCH FAILED: "+err.Error()))
+		return nil
+	}
+	m.watcher = w
+	m.watchStatus = "watching"
+	m.history = append(m.history, m.styles.success.Render(fmt.Sprintf("✓ Watching %s", w.root)),
+		m.styles.inactive.Render("Changes are indexed in the background. Stop with /watch stop."))
+	return waitForWatchCmd(w)
+}
+
+func (m *model) handleWatchStatusMsg(msg watchStatusMsg) tea.Cmd {
+	if m.watcher == nil || m.watcher.repo.FullName != msg.repoFullName {
+		return nil
+	}
+	m.watchStatus = msg.status
+	switch {
+	case msg.err != nil:
+		m.history = append(m.history, m.styles.error.Render("WATCH: "+msg.err.Error()))
+	case msg.changed > 0:
+		m.history = append(m.history, m.styles.inactive.Render(fmt.Sprintf("↻ Indexed %d changed files of %s", msg.changed, msg.repoFullName)))
+	}
+	return waitForWatchCmd(m.watcher)
+}
+
+// stopWatching stops the /watch file watcher, if any.
+func (m *model) stopWatching() {
+	if m.watcher != nil {
+		m.watcher.Stop()
+		m.watcher = nil
+		m.watchStatus = ""
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/sevigo/code-warden/internal/app"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/stringsutil"
+)
+
+const (
+	// chatSessionsLimit is how many sessions /sessions lists.
+	chatSessionsLimit = 20
+	// chatKeepRecent is how many of the latest messages are always sent to
+	// the model verbatim.
+	chatKeepRecent = 10
+	// chatSummarizeAfter is how many messages may pile up after the summary
+	// before the older ones are condensed into it.
+	chatSummarizeAfter = 20
+	// chatReplayMessages is how many messages /resume shows again.
+	chatReplayMessages = 6
+	// chatTitleLen caps the title a session takes from its first question.
+	chatTitleLen = 60
+)
+
+// chatTurn is a question of the conversation about repo. session is nil for
+// the first question, which starts a new session; turns holds the messages
+// of the session so far as "User: ..." and "AI: ..." lines, ending with the
+// question.
+type chatTurn struct {
+	repo     *storage.Repository
+	session  *storage.ChatSession
+	question string
+	turns    []string
+}
+
+// summaryCut returns how many of total messages the summary should cover
+// so that at most chatKeepRecent of them are sent verbatim, or false while
+// fewer than chatSummarizeAfter messages follow the summarized ones.
+func summaryCut(total, summarized int) (int, bool) {
+	if total-summarized <= chatSummarizeAfter {
+		return summarized, false
+	}
+	return total - chatKeepRecent, true
+}
+
+// promptHistory is the history a question is asked with: the summary of
+// the older messages followed by the recent ones.
+func promptHistory(summary string, recent []string) []string {
+	if summary == "" {
+		return recent
+	}
+	return append([]string{"Summary of the earlier conversation: " + summary}, recent...)
+}
+
+// messageLine renders a stored message as a line of conversation history.
+func messageLine(msg *storage.ChatMessage) string {
+	if msg.Role == storage.ChatRoleAssistant {
+		return "AI: " + msg.Content
+	}
+	return "User: " + msg.Content
+}
+
+// askInSessionCmd answers a question and records it and its answer in the
+// session, condensing older messages into the session summary first when
+// too many have piled up. Failing to record a message is logged, not
+// reported, so that the chat keeps working.
+func askInSessionCmd(app *app.App, turn chatTurn) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		session := turn.session
+		if session == nil {
+			session = &storage.ChatSession{
+				RepositoryID: turn.repo.ID,
+				Title:        stringsutil.Truncate(turn.question, chatTitleLen, "..."),
+			}
+			if err := app.Store.CreateChatSession(ctx, session); err != nil {
+				return errorMsg{err}
+			}
+		} else {
+			copied := *session
+			session = &copied
+		}
+		question := &storage.ChatMessage{SessionID: session.ID, Role: storage.ChatRoleUser, Content: turn.question}
+		if err := app.Store.AddChatMessage(ctx, question); err != nil {
+			app.Logger.Warn("failed to save chat question", "session", session.ID, "error", err)
+		}
+
+		if cut, ok := summaryCut(len(turn.turns), session.SummarizedMessages); ok {
+			summary, err := app.RAGService.SummarizeConversation(ctx, session.Summary, turn.turns[session.SummarizedMessages:cut])
+			if err != nil {
+				app.Logger.Warn("failed to summarize conversation, sending it in full", "session", session.ID, "error", err)
+			} else if err := app.Store.UpdateChatSummary(ctx, session.ID, summary, cut); err != nil {
+				app.Logger.Warn("failed to save conversation summary", "session", session.ID, "error", err)
+			} else {
+				session.Summary, session.SummarizedMessages = summary, cut
+			}
+		}
+
+		recent := turn.turns[min(session.SummarizedMessages, len(turn.turns)):]
+		history := promptHistory(session.Summary, recent)
+		repo, err := warmRepo(ctx, app, turn.repo)
+		if err != nil {
+			return errorMsg{err}
+		}
+		answer, err := app.RAGService.AnswerQuestion(ctx, repo.QdrantCollectionName, repo.Embedder(app.Cfg.AI.EmbedderModel), turn.question, history)
+		if err != nil {
+			return errorMsg{err}
+		}
+		reply := &storage.ChatMessage{SessionID: session.ID, Role: storage.ChatRoleAssistant, Content: answer.Text}
+		if err := app.Store.AddChatMessage(ctx, reply); err != nil {
+			app.Logger.Warn("failed to save chat answer", "session", session.ID, "error", err)
+		}
+		return answerCompleteMsg{content: answer.Text, citations: answer.Citations, followUp: true, session: session}
+	}
+}
+
+func loadSessionsCmd(app *app.App, repo *storage.Repository) tea.Cmd {
+	return func() tea.Msg {
+		sessions, err := app.Store.ListChatSessions(context.Background(), repo.ID, chatSessionsLimit)
+		return sessionsLoadedMsg{repoFullName: repo.FullName, sessions: sessions, err: err}
+	}
+}
+
+func resumeSessionCmd(app *app.App, session *storage.ChatSession) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		// Reload the session for a summary written since it was listed.
+		current, err := app.Store.GetChatSession(ctx, session.ID)
+		if err != nil {
+			return sessionResumedMsg{err: fmt.Errorf("failed to load session %d: %w", session.ID, err)}
+		}
+		msgs, err := app.Store.GetChatMessages(ctx, session.ID)
+		if err != nil {
+			return sessionResumedMsg{err: err}
+		}
+		return sessionResumedMsg{session: current, messages: msgs}
+	}
+}
+
+// sessionList renders the listing of /sessions; the numbers are those
+// /resume takes.
+func sessionList(sessions []*storage.ChatSession) string {
+	var b strings.Builder
+	for i, s := range sessions {
+		fmt.Fprintf(&b, "\n  [%d] %s  %s  (%d messages)", i+1, s.UpdatedAt.Format("2006-01-02 15:04"), s.Title, s.MessageCount)
+	}
+	return b.String()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSummaryCut(t *testing.T) {
+	tests := []struct {
+		total, summarized int
+		want              int
+		wantOK            bool
+	}{
+		{total: 4, summarized: 0, want: 0},
+		{total: chatSummarizeAfter, summarized: 0, want: 0},
+		{total: chatSummarizeAfter + 1, summarized: 0, want: chatSummarizeAfter + 1 - chatKeepRecent, wantOK: true},
+		{total: 30, summarized: 12, want: 12},
+		{total: 40, summarized: 12, want: 40 - chatKeepRecent, wantOK: true},
+	}
+	for _, tt := range tests {
+		got, ok := summaryCut(tt.total, tt.summarized)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("summaryCut(%d, %d) = %d, %v, want %d, %v", tt.total, tt.summarized, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestPromptHistory(t *testing.T) {
+	recent := []string{"User: where is auth?", "AI: internal/auth"}
+	if got := promptHistory("", recent); !slices.Equal(got, recent) {
+		t.Errorf("promptHistory without summary = %q, want %q", got, recent)
+	}
+	got := promptHistory("The user asked about login.", recent)
+	want := []string{"Summary of the earlier conversation: The user asked about login.", "User: where is auth?", "AI: internal/auth"}
+	if !slices.Equal(got, want) {
+		t.Errorf("promptHistory = %q, want %q", got, want)
+	}
+}
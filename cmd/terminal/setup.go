@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/sevigo/code-warden/internal/app"
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/health"
+)
+
+// setupStep is the step a /setup run is at.
+type setupStep int
+
+const (
+	// setupChecking waits for the connectivity checks.
+	setupChecking setupStep = iota
+	// setupConnecting waits for the application to start after the checks
+	// passed, when it failed to start before.
+	setupConnecting
+	setupRepoName
+	setupRepoPath
+	// setupScanning waits for the first repository to be registered and
+	// indexed.
+	setupScanning
+	setupWriteConfig
+)
+
+const (
+	// starterConfigFile is where /setup writes the starter config, the first
+	// place the configuration is looked for.
+	starterConfigFile = "config.yaml"
+	// progressBarWidth is the number of cells of the scan progress bar.
+	progressBarWidth = 40
+)
+
+// setupHints tell how to fix a failed connectivity check.
+var setupHints = map[string]string{
+	"postgres":           "Start it with `docker compose up -d db` and check the database settings.",
+	"vector_store":       "Start Qdrant with `docker compose up -d qdrant` and check storage.qdrant_host.",
+	"ollama":             "Start Ollama with `ollama serve` and check ai.ollama_host.",
+	"gemini":             "Check the API key in AI_GEMINI_API_KEY.",
+	"github_private_key": "Only the server needs it, to review pull requests.",
+}
+
+// setupWizard is the state of a /setup run.
+type setupWizard struct {
+	step     setupStep
+	repoName string
+	// failed is set when a service is down or the scan failed.
+	failed bool
+	// progressLine is the history line showing the progress of the first
+	// scan.
+	progressLine int
+}
+
+// setupChecksCmd checks the services of cfg. The database is pinged through
+// the application when it is running and with a connection of its own
+// otherwise.
+func setupChecksCmd(cfg *config.Config, app *app.App) tea.Cmd {
+	return func() tea.Msg {
+		pingDB := postgresProbe(&cfg.Database)
+		if app != nil {
+			pingDB = app.DB.PingContext
+		}
+		checks, _ := health.NewChecker(cfg, pingDB).Check(context.Background())
+		return setupChecksMsg{checks: checks}
+	}
+}
+
+// postgresProbe connects to the database of cfg.
+func postgresProbe(cfg *config.DBConfig) health.ProbeFunc {
+	return func(ctx context.Context) error {
+		conn, err := sqlx.ConnectContext(ctx, "postgres", cfg.GetDSN())
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// progressBar renders the progress of a scan, done of total files.
+func progressBar(done, total, width int) string {
+	if total <= 0 {
+		return "[" + strings.Repeat("░", width) + "] preparing..."
+	}
+	done = min(done, total)
+	filled := done * width / total
+	return fmt.Sprintf("[%s%s] %3d%% %d/%d files",
+		strings.Repeat("█", filled), strings.Repeat("░", width-filled), done*100/total, done, total)
+}
+
+// findConfigFile returns the config file LoadConfig reads, or "" when there
+// is none.
+func findConfigFile() string {
+	paths := []string{starterConfigFile}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".code-warden", "config.yaml"))
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// starterConfigTemplate holds the settings /setup writes. Secrets are left
+// out: without a config file they came from environment variables, which
+// keep working.
+var starterConfigTemplate = template.Must(template.New("config").Parse(`# Code-Warden configuration, written by /setup in the terminal UI.
+# config.yaml.example describes all settings. Environment variables override
+# any value: database.password -> DATABASE_PASSWORD.
+
+database:
+  host: {{printf "%q" .Database.Host}}
+  port: {{.Database.Port}}
+  database: {{printf "%q" .Database.Database}}
+  username: {{printf "%q" .Database.Username}}
+  ssl_mode: {{printf "%q" .Database.SSLMode}}
+  # password: set DATABASE_PASSWORD instead of storing it here.
+
+storage:
+  vector_store_provider: {{printf "%q" .Storage.VectorStoreProvider}}
+{{- if .Storage.QdrantHost}}
+  qdrant_host: {{printf "%q" .Storage.QdrantHost}}
+{{- end}}
+{{- if .Storage.WeaviateURL}}
+  weaviate_url: {{printf "%q" .Storage.WeaviateURL}}
+{{- end}}
+  repo_path: {{printf "%q" .Storage.RepoPath}}
+
+ai:
+  llm_provider: {{printf "%q" .AI.LLMProvider}}
+  embedder_provider: {{printf "%q" .AI.EmbedderProvider}}
+  ollama_host: {{printf "%q" .AI.OllamaHost}}
+  generator_model: {{printf "%q" .AI.GeneratorModel}}
+  embedder_model: {{printf "%q" .AI.EmbedderModel}}
+  # gemini_api_key: set AI_GEMINI_API_KEY instead of storing it here.
+`))
+
+// writeStarterConfig writes the settings of cfg needed to start to path. An
+// existing file is never overwritten.
+func writeStarterConfig(path string, cfg *config.Config) error {
+	var buf bytes.Buffer
+	if err := starterConfigTemplate.Execute(&buf, cfg); err != nil {
+		return fmt.Errorf("failed to render config: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("%s exists already, it is not overwritten", path)
+		}
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// config returns the configuration of the running application, or the one
+// loaded at startup while it is not running.
+func (m *model) config() *config.Config {
+	if m.app != nil {
+		return m.app.Cfg
+	}
+	return m.cfg
+}
+
+func (m *model) processSetupCommand(args []string) tea.Cmd {
+	switch {
+	case len(args) == 1 && args[0] == "cancel":
+		if m.setup == nil {
+			m.history = append(m.history, m.styles.inactive.Render("No setup in progress."))
+			return nil
+		}
+		m.setup = nil
+		m.history = append(m.history, m.styles.inactive.Render("Setup cancelled."))
+		return nil
+	case len(args) != 0:
+		m.history = append(m.history, m.styles.error.Render("USAGE: /setup or /setup cancel"))
+		return nil
+	}
+	m.setup = &setupWizard{step: setupChecking}
+	m.isLoading = true
+	m.history = append(m.history, m.styles.command.Render("→ Checking the services Code-Warden needs..."))
+	return tea.Batch(m.spinner.Tick, setupChecksCmd(m.config(), m.app))
+}
+
+func (m *model) handleSetupChecksMsg(msg setupChecksMsg) tea.Cmd {
+	m.isLoading = false
+	if m.setup == nil || m.setup.step != setupChecking {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString(m.styles.success.Render("SERVICES:"))
+	for _, check := range msg.checks {
+		if check.Status == health.CheckOK {
+			fmt.Fprintf(&b, "\n  %s %-20s %dms", m.styles.success.Render("✓"), check.Name, check.LatencyMs)
+			continue
+		}
+		// The private key is only needed for GitHub reviews.
+		if check.Name != "github_private_key" {
+			m.setup.failed = true
+		}
+		fmt.Fprintf(&b, "\n  %s %-20s %s", m.styles.error.Render("✗"), check.Name, check.Error)
+		if hint := setupHints[check.Name]; hint != "" {
+			b.WriteString("\n      " + m.styles.inactive.Render(hint))
+		}
+	}
+	m.history = append(m.history, b.String())
+
+	switch {
+	case m.setup.failed:
+		m.history = append(m.history, m.styles.error.Render("Fix the failing services, then run /setup again."))
+		m.askSetupConfig()
+		return nil
+	case m.app == nil:
+		m.setup.step = setupConnecting
+		m.isLoading = true
+		m.history = append(m.history, m.styles.command.Render("→ Starting Code-Warden..."))
+		return tea.Batch(m.spinner.Tick, initializeAppCmd())
+	}
+	m.askSetupRepo()
+	return nil
+}
+
+// handleSetupAppInitialized continues /setup once the application started
+// after the connectivity checks passed.
+func (m *model) handleSetupAppInitialized(err error) {
+	if err != nil {
+		m.setup.failed = true
+		m.askSetupConfig()
+		return
+	}
+	m.askSetupRepo()
+}
+
+func (m *model) askSetupRepo() {
+	m.setup.step = setupRepoName
+	m.history = append(m.history, m.styles.success.Render("✓ All services are reachable."),
+		m.styles.command.Render("Name of the repository to register, e.g. my-project (or \"skip\"):"))
+}
+
+// askSetupConfig offers to write a starter config, unless a config file is
+// used already, and otherwise finishes the setup.
+func (m *model) askSetupConfig() {
+	if path := findConfigFile(); path != "" {
+		m.history = append(m.history, m.styles.inactive.Render(fmt.Sprintf("Using the configuration in %s.", path)))
+		m.finishSetup()
+		return
+	}
+	m.setup.step = setupWriteConfig
+	m.history = append(m.history, m.styles.command.Render(
+		fmt.Sprintf("No config file found. Write the current settings to %s? (yes/no)", starterConfigFile)))
+}
+
+// answerSetup takes input as the answer to the question /setup asked last.
+func (m *model) answerSetup(input string) tea.Cmd {
+	switch m.setup.step {
+	case setupRepoName:
+		if input == "skip" {
+			m.askSetupConfig()
+			return nil
+		}
+		if strings.ContainsAny(input, " \t") {
+			m.history = append(m.history, m.styles.error.Render("The name must not contain spaces."))
+			return nil
+		}
+		m.setup.repoName = input
+		m.setup.step = setupRepoPath
+		m.history = append(m.history, m.styles.command.Render("Path to its local clone:"))
+		return nil
+	case setupRepoPath:
+		path, err := filepath.Abs(input)
+		if err == nil {
+			var info os.FileInfo
+			if info, err = os.Stat(path); err == nil && !info.IsDir() {
+				err = fmt.Errorf("%s is not a directory", path)
+			}
+		}
+		if err != nil {
+			m.history = append(m.history, m.styles.error.Render(err.Error()), m.styles.command.Render("Path to its local clone:"))
+			return nil
+		}
+		m.setup.step = setupScanning
+		m.isLoading = true
+		m.history = append(m.history, m.styles.command.Render(fmt.Sprintf("→ Registering %s...", m.setup.repoName)))
+		return tea.Batch(m.spinner.Tick, addRepoCmd(m.app, m.setup.repoName, path))
+	case setupWriteConfig:
+		switch strings.ToLower(input) {
+		case "y", "yes":
+			if err := writeStarterConfig(starterConfigFile, m.config()); err != nil {
+				m.history = append(m.history, m.styles.error.Render("CONFIG FAILED: "+err.Error()))
+			} else {
+				m.history = append(m.history, m.styles.success.Render("✓ Wrote "+starterConfigFile),
+					m.styles.inactive.Render("Secrets stay in environment variables, like DATABASE_PASSWORD and AI_GEMINI_API_KEY."))
+			}
+		case "n", "no":
+		default:
+			m.history = append(m.history, m.styles.error.Render("Answer yes or no."))
+			return nil
+		}
+		m.finishSetup()
+		return nil
+	}
+	m.history = append(m.history, m.styles.inactive.Render("Setup is busy, wait for the current step to finish or /setup cancel."))
+	return nil
+}
+
+// handleSetupRepoAdded starts the initial scan of the repository registered
+// by /setup.
+func (m *model) handleSetupRepoAdded(msg repoAddedMsg) tea.Cmd {
+	if msg.err != nil {
+		m.isLoading = false
+		m.setup.step = setupRepoName
+		m.history = append(m.history, m.styles.error.Render("REGISTER FAILED: "+msg.err.Error()),
+			m.styles.command.Render("Name of the repository to register (or \"skip\"):"))
+		return nil
+	}
+	m.history = append(m.history, m.styles.success.Render(fmt.Sprintf("✅ REPO REGISTERED: %s", msg.repoFullName)),
+		m.styles.command.Render("→ Starting initial scan..."),
+		m.styles.command.Render(progressBar(0, 0, progressBarWidth)))
+	m.setup.progressLine = len(m.history) - 1
+	return tea.Batch(m.spinner.Tick, m.startScan(msg.repoPath, msg.repoFullName, true))
+}
+
+// handleSetupScanComplete ends the initial scan of /setup. The repository
+// list is reloaded, which selects the repository when it is the only one.
+func (m *model) handleSetupScanComplete(msg scanCompleteMsg) tea.Cmd {
+	if msg.err != nil {
+		m.setup.failed = true
+		m.history = append(m.history, m.styles.error.Render("SCAN FAILED: "+msg.err.Error()),
+			m.styles.inactive.Render(fmt.Sprintf("Retry it with /rescan %s.", msg.repoFullName)))
+	} else {
+		m.history = append(m.history, m.styles.success.Render(fmt.Sprintf("✓ REPO INDEXED: %s", msg.repoFullName)))
+	}
+	m.askSetupConfig()
+	return loadReposCmd(m.app)
+}
+
+func (m *model) finishSetup() {
+	if m.setup.failed {
+		m.history = append(m.history, m.styles.inactive.Render("Setup finished with problems, see above."))
+	} else {
+		m.history = append(m.history, m.styles.success.Render("✓ SETUP COMPLETE"),
+			m.styles.inactive.Render("Ask a question about the selected repository, or type /help for commands."))
+	}
+	m.setup = nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		done, total int
+		want        string
+	}{
+		{done: 0, total: 0, want: "[░░░░░░░░░░] preparing..."},
+		{done: 0, total: 8, want: "[░░░░░░░░░░]   0% 0/8 files"},
+		{done: 4, total: 8, want: "[█████░░░░░]  50% 4/8 files"},
+		{done: 9, total: 8, want: "[██████████] 100% 8/8 files"},
+	}
+	for _, tt := range tests {
+		if got := progressBar(tt.done, tt.total, 10); got != tt.want {
+			t.Errorf("progressBar(%d, %d) = %q, want %q", tt.done, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestWriteStarterConfig(t *testing.T) {
+	cfg := &config.Config{
+		Database: config.DBConfig{Host: "db", Port: 5433, Database: "warden", Username: "warden", Password: "db-secret", SSLMode: "disable"},
+		Storage:  config.StorageConfig{VectorStoreProvider: config.VectorStoreQdrant, QdrantHost: "qdrant:6334", RepoPath: "./data/repos"},
+		AI:       config.AIConfig{LLMProvider: "gemini", EmbedderProvider: "ollama", GeminiAPIKey: "api-secret", GeneratorModel: "gemini-2.5-pro", EmbedderModel: "nomic-embed-text"},
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := writeStarterConfig(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "-secret") {
+		t.Errorf("starter config contains a secret:\n%s", data)
+	}
+
+	var written struct {
+		Database struct {
+			Host string `yaml:"host"`
+			Port int    `yaml:"port"`
+		} `yaml:"database"`
+		Storage struct {
+			QdrantHost  string `yaml:"qdrant_host"`
+			WeaviateURL string `yaml:"weaviate_url"`
+		} `yaml:"storage"`
+		AI struct {
+			GeneratorModel string `yaml:"generator_model"`
+		} `yaml:"ai"`
+	}
+	if err := yaml.Unmarshal(data, &written); err != nil {
+		t.Fatalf("starter config is not valid YAML: %v\n%s", err, data)
+	}
+	if written.Database.Host != "db" || written.Database.Port != 5433 {
+		t.Errorf("database = %+v, want db:5433", written.Database)
+	}
+	if written.Storage.QdrantHost != "qdrant:6334" || written.Storage.WeaviateURL != "" {
+		t.Errorf("storage = %+v, want only the Qdrant host", written.Storage)
+	}
+	if written.AI.GeneratorModel != "gemini-2.5-pro" {
+		t.Errorf("generator_model = %q, want gemini-2.5-pro", written.AI.GeneratorModel)
+	}
+
+	if err := writeStarterConfig(path, cfg); err == nil {
+		t.Error("an existing config was overwritten")
+	}
+}
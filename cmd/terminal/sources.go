@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// sourcesFooter renders the citations of an answer as a markdown list under
+// a "Sources" heading, or nothing for an answer without any. Citations of
+// answers across repositories name their repository first.
+func sourcesFooter(citations []core.Citation) string {
+	if len(citations) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("\n\n---\n\n**Sources**\n\n")
+	for _, c := range citations {
+		b.WriteString("- ")
+		if c.Repo != "" {
+			fmt.Fprintf(&b, "%s ", c.Repo)
+		}
+		fmt.Fprintf(&b, "`%s", c.Source)
+		switch {
+		case c.LineStart > 0 && c.LineEnd > c.LineStart:
+			fmt.Fprintf(&b, ":%d-%d", c.LineStart, c.LineEnd)
+		case c.LineStart > 0:
+			fmt.Fprintf(&b, ":%d", c.LineStart)
+		}
+		b.WriteString("`")
+		if c.Score > 0 {
+			fmt.Fprintf(&b, " (%.2f)", c.Score)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
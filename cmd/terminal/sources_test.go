@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+func TestSourcesFooter(t *testing.T) {
+	if got := sourcesFooter(nil); got != "" {
+		t.Errorf("sourcesFooter(nil) = %q, want no footer", got)
+	}
+
+	got := sourcesFooter([]core.Citation{
+		{Source: "internal/auth/jwt.go", LineStart: 12, LineEnd: 40, Score: 0.8312},
+		{Source: "main.go", LineStart: 7, LineEnd: 7},
+		{Source: "internal/auth"},
+	})
+	for _, want := range []string{"**Sources**", "- `internal/auth/jwt.go:12-40` (0.83)", "- `main.go:7`\n", "- `internal/auth`\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("sourcesFooter is missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestSourcesFooterAcrossRepos(t *testing.T) {
+	got := sourcesFooter([]core.Citation{{Repo: "acme/web", Source: "src/login.ts", LineStart: 1, LineEnd: 4}})
+	if want := "- acme/web `src/login.ts:1-4`\n"; !strings.Contains(got, want) {
+		t.Errorf("sourcesFooter is missing %q:\n%s", want, got)
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/sevigo/code-warden/internal/app"
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/pathutil"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// watchDebounce is how long the working tree must stay quiet before the
+// files changed in it are indexed, so that a save of many files or a
+// checkout is indexed at once.
+const watchDebounce = 2 * time.Second
+
+// repoWatcher keeps the index of a local repository in step with its working
+// tree: it watches the tree with fsnotify and, once changes settle, updates
+// the vector store with the changed and deleted files.
+type repoWatcher struct {
+	app         *app.App
+	repo        *storage.Repository
+	root        string
+	repoConfig  *core.RepoConfig
+	excludeDirs []string
+	fs          *fsnotify.Watcher
+	// updates carries the status changes to the UI.
+	updates chan watchStatusMsg
+	cancel  context.CancelFunc
+	done    chan struct{}
+	// pending holds the repository-relative paths changed since the last
+	// update; it is only touched by run.
+	pending map[string]struct{}
+}
+
+// startWatcher watches the working tree of repo.
+func startWatcher(app *app.App, repo *storage.Repository) (*repoWatcher, error) {
+	root, err := pathutil.FromFileURL(repo.ClonePath)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(root); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("working tree %s of %s is not a directory", root, repo.FullName)
+	}
+	repoConfig, err := config.LoadRepoConfig(root)
+	if err != nil {
+		if !errors.Is(err, config.ErrConfigNotFound) {
+			app.Logger.Warn("failed to parse .code-warden.yml, using defaults", "error", err, "repo", repo.FullName)
+		}
+		repoConfig = core.DefaultRepoConfig()
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	w := &repoWatcher{
+		app:         app,
+		repo:        repo,
+		root:        root,
+		repoConfig:  repoConfig,
+		excludeDirs: core.BuildExcludeDirs(repoConfig.ExcludeDirs),
+		fs:          fsw,
+		updates:     make(chan watchStatusMsg, 1),
+		done:        make(chan struct{}),
+		pending:     make(map[string]struct{}),
+	}
+	if err := w.addTree(root); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	go w.run(ctx)
+	return w, nil
+}
+
+// Stop stops watching and waits for an update in progress to end.
+func (w *repoWatcher) Stop() {
+	w.cancel()
+	<-w.done
+}
+
+// addTree watches dir and its subdirectories; fsnotify watches single
+// directories only. Excluded directories are not watched.
+func (w *repoWatcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// The directory may be gone already; its deletion is seen anyway.
+			return nil //nolint:nilerr // a vanished directory is not an error
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != w.root && slices.Contains(w.excludeDirs, d.Name()) {
+			return filepath.SkipDir
+		}
+		if err := w.fs.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		return nil
+	})
+}
+
+func (w *repoWatcher) run(ctx context.Context) {
+	defer close(w.done)
+	defer w.fs.Close()
+
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+	defer timer.Stop()
+	indexed := make(chan watchStatusMsg, 1)
+	indexing := false
+
+	for {
+		select {
+		case <-ctx.Done():
+			if indexing {
+				<-indexed
+			}
+			return
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return
+			}
+			if w.record(event) {
+				timer.Reset(watchDebounce)
+			}
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return
+			}
+			w.send(ctx, watchStatusMsg{repoFullName: w.repo.FullName, status: "watch error", err: err})
+		case <-timer.C:
+			// Changes that arrive during an update wait for the next one.
+			if indexing || len(w.pending) == 0 {
+				continue
+			}
+			changed := w.takePending()
+			indexing = true
+			w.send(ctx, watchStatusMsg{repoFullName: w.repo.FullName, status: fmt.Sprintf("indexing %d changes", len(changed))})
+			go func() { indexed <- w.index(ctx, changed) }()
+		case msg := <-indexed:
+			indexing = false
+			w.send(ctx, msg)
+			if len(w.pending) > 0 {
+				timer.Reset(watchDebounce)
+			}
+		}
+	}
+}
+
+// record notes the file of event as changed and watches directories created
+// in the tree. It reports whether the event may change the index.
+func (w *repoWatcher) record(event fsnotify.Event) bool {
+	rel, err := pathutil.Rel(w.root, event.Name)
+	if err != nil || pathutil.EscapesRoot(rel) || rel == "." || w.excluded(rel) {
+		return false
+	}
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if err := w.addTree(event.Name); err != nil {
+				w.app.Logger.Warn("failed to watch new directory", "path", event.Name, "error", err)
+			}
+			return false
+		}
+	}
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	w.pending[rel] = struct{}{}
+	return true
+}
+
+// excluded reports whether rel lies in an excluded directory or is hidden,
+// as files the indexer skips are.
+func (w *repoWatcher) excluded(rel string) bool {
+	parts := strings.Split(rel, "/")
+	for _, dir := range parts[:len(parts)-1] {
+		if slices.Contains(w.excludeDirs, dir) {
+			return true
+		}
+	}
+	return strings.HasPrefix(parts[len(parts)-1], ".")
+}
+
+func (w *repoWatcher) takePending() []string {
+	changed := make([]string, 0, len(w.pending))
+	for rel := range w.pending {
+		changed = append(changed, rel)
+	}
+	clear(w.pending)
+	slices.Sort(changed)
+	return changed
+}
+
+// index updates the vector store with the changed files. Files that no
+// longer exist are deleted from it.
+func (w *repoWatcher) index(ctx context.Context, changed []string) watchStatusMsg {
+	update, remove := splitChanges(w.root, changed)
+	err := w.app.RAGService.UpdateRepoContext(ctx, w.repoConfig, w.repo, w.root, update, remove, nil)
+	if err != nil {
+		w.app.Logger.Warn("failed to index working tree changes", "repo", w.repo.FullName, "error", err)
+		return watchStatusMsg{repoFullName: w.repo.FullName, status: "update failed", err: err}
+	}
+	return watchStatusMsg{
+		repoFullName: w.repo.FullName,
+		status:       "up to date " + time.Now().Format("15:04:05"),
+		changed:      len(changed),
+	}
+}
+
+// splitChanges divides the changed repository-relative paths into the files
+// to index again and those that were deleted. Directories are dropped: their
+// files have events of their own.
+func splitChanges(root string, changed []string) (update, remove []string) {
+	for _, rel := range changed {
+		info, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel)))
+		switch {
+		case err != nil:
+			remove = append(remove, rel)
+		case info.Mode().IsRegular():
+			update = append(update, rel)
+		}
+	}
+	return update, remove
+}
+
+// send hands msg to the UI unless the watcher is stopping.
+func (w *repoWatcher) send(ctx context.Context, msg watchStatusMsg) {
+	select {
+	case w.updates <- msg:
+	case <-ctx.Done():
+	}
+}
+
+// waitForWatchCmd waits for the next status change of w. The UI issues it
+// again after each one.
+func waitForWatchCmd(w *repoWatcher) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case msg := <-w.updates:
+			return msg
+		case <-w.done:
+			return nil
+		}
+	}
+}
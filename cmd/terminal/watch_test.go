@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+func TestSplitChanges(t *testing.T) {
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "pkg", "a.go"), []byte("package pkg"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	update, remove := splitChanges(root, []string{"pkg", "pkg/a.go", "pkg/gone.go"})
+	if !slices.Equal(update, []string{"pkg/a.go"}) {
+		t.Errorf("update = %v, want [pkg/a.go]", update)
+	}
+	if !slices.Equal(remove, []string{"pkg/gone.go"}) {
+		t.Errorf("remove = %v, want [pkg/gone.go]", remove)
+	}
+}
+
+func TestWatcherRecord(t *testing.T) {
+	root := t.TempDir()
+	w := &repoWatcher{
+		root:        root,
+		excludeDirs: core.BuildExcludeDirs(nil),
+		pending:     make(map[string]struct{}),
+	}
+	events := []fsnotify.Event{
+		{Name: filepath.Join(root, "main.go"), Op: fsnotify.Write},
+		{Name: filepath.Join(root, "main.go"), Op: fsnotify.Chmod},
+		{Name: filepath.Join(root, "node_modules", "x", "index.js"), Op: fsnotify.Create},
+		{Name: filepath.Join(root, ".env"), Op: fsnotify.Write},
+		{Name: filepath.Join(root, "internal", "old.go"), Op: fsnotify.Remove},
+	}
+	var recorded int
+	for _, event := range events {
+		if w.record(event) {
+			recorded++
+		}
+	}
+	if recorded != 2 {
+		t.Errorf("recorded %d events, want 2", recorded)
+	}
+	if got := w.takePending(); !slices.Equal(got, []string{"internal/old.go", "main.go"}) {
+		t.Errorf("pending = %v, want [internal/old.go main.go]", got)
+	}
+	if len(w.pending) != 0 {
+		t.Error("takePending left paths pending")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/google/go-github/v73/github"
+
+	"github.com/sevigo/code-warden/internal/redact"
 )
 
 // maxErrorDisplayLength is the maximum number of characters shown in GitHub
@@ -95,13 +97,19 @@ func (o *Orchestrator) postSessionCompleted(ctx context.Context, session *Sessio
 	o.postIssueComment(ctx, session.Issue, body)
 }
 
+// redactSecrets removes credentials from error text before it is posted to GitHub.
+func redactSecrets(s string) string {
+	redacted, _ := redact.Secrets(s)
+	return redacted
+}
+
 func (o *Orchestrator) postSessionFailed(ctx context.Context, session *Session, errMsg string) {
 	body := fmt.Sprintf(
 		"❌ **Implementation failed** — session `%s`\n\n"+
 			"The agent could not complete the implementation.\n\n"+
 			"<details><summary>Error detail</summary>\n\n```\n%s\n```\n</details>\n\n"+
 			"You can retry by commenting `/implement` again.",
-		session.ID, truncateString(errMsg, maxErrorDisplayLength),
+		session.ID, truncateString(redactSecrets(errMsg), maxErrorDisplayLength),
 	)
 	o.postIssueComment(ctx, session.Issue, body)
 }
@@ -192,7 +200,7 @@ func (o *Orchestrator) deferCheckRunCompletion(ctx context.Context, session *Ses
 	summary := fmt.Sprintf("Session `%s` completed.", session.ID)
 	if s := session.GetStatus(); s == StatusFailed {
 		conclusion = "failure"
-		summary = fmt.Sprintf("Session `%s` failed: %s", session.ID, session.GetError())
+		summary = fmt.Sprintf("Session `%s` failed: %s", session.ID, redactSecrets(session.GetError()))
 	} else if s == StatusDraft {
 		conclusion = "action_required"
 		summary = fmt.Sprintf("Session `%s` yielded a draft PR for human review.", session.ID)
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/sandbox"
 )
 
 // Formatter runs language-specific formatters on files written by the agent.
@@ -17,6 +18,8 @@ import (
 // format_command run once before the review phase (configured in .code-warden.yml).
 type Formatter struct {
 	logger *slog.Logger
+	// sandbox isolates the repository's format_command; nil runs it on the host.
+	sandbox sandbox.Runner
 }
 
 // NewFormatter creates a Formatter with the given logger.
@@ -75,8 +78,7 @@ func (f *Formatter) FormatProject(ctx context.Context, workspaceRoot, command st
 	fmtCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(fmtCtx, parts[0], parts[1:]...) //nolint:gosec // G204: command from repo config
-	cmd.Dir = workspaceRoot
+	cmd := sandbox.OrDirect(f.sandbox).Command(fmtCtx, workspaceRoot, parts[0], parts[1:]...)
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		f.logger.Warn("auto-format: project format command failed",
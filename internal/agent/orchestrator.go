@@ -22,6 +22,7 @@ import (
 	"github.com/sevigo/code-warden/internal/globalmcp"
 	"github.com/sevigo/code-warden/internal/mcp"
 	"github.com/sevigo/code-warden/internal/rag"
+	"github.com/sevigo/code-warden/internal/sandbox"
 	"github.com/sevigo/code-warden/internal/storage"
 )
 
@@ -79,6 +80,10 @@ type Config struct {
 
 	// PublishIterations is the max iterations for the publish loop (push + PR).
 	PublishIterations int `yaml:"publish_iterations"`
+
+	// Sandbox isolates commands taken from the repository (verify and
+	// format commands). Nil runs them directly on the host.
+	Sandbox sandbox.Runner `yaml:"-"`
 }
 
 // Default budget values used when the corresponding Config field is zero.
@@ -183,6 +188,7 @@ func NewOrchestrator(
 			ComparisonModels: config.ComparisonModels,
 			ReviewsDir:       config.ReviewsDir,
 			AgentMode:        true,
+			Sandbox:          config.Sandbox,
 		},
 	)
 
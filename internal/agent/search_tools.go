@@ -7,11 +7,11 @@ import (
 	"io/fs"
 	"log/slog"
 	"os/exec"
-	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/mcp"
 	"github.com/sevigo/code-warden/internal/mcp/tools"
 )
@@ -332,7 +332,7 @@ func newFindWalkFn(root, searchRoot, pattern string, limit int, matches *[]strin
 			return nil //nolint:nilerr // same guarantee as above
 		}
 
-		if !matchGlob(pattern, filepath.ToSlash(relToSearch)) {
+		if !core.MatchGlob(pattern, filepath.ToSlash(relToSearch)) {
 			return nil
 		}
 
@@ -345,55 +345,6 @@ func newFindWalkFn(root, searchRoot, pattern string, limit int, matches *[]strin
 	}
 }
 
-// matchGlob reports whether relPath matches the given glob pattern.
-// Supports ** as a multi-segment wildcard and bare patterns (no /) as
-// basename-only matches.
-func matchGlob(pattern, relPath string) bool {
-	pattern = filepath.ToSlash(pattern)
-	relPath = filepath.ToSlash(relPath)
-
-	if !strings.Contains(pattern, "**") {
-		if !strings.Contains(pattern, "/") {
-			// Basename-only: *.go, foo.go
-			m, _ := path.Match(pattern, path.Base(relPath))
-			return m
-		}
-		// Path-rooted: internal/agent/*.go
-		m, _ := path.Match(pattern, relPath)
-		return m
-	}
-	return matchDoublestar(pattern, relPath)
-}
-
-// matchDoublestar matches a pattern that contains at least one ** against s.
-// ** matches zero or more path segments.
-func matchDoublestar(pattern, s string) bool {
-	prefix, after, _ := strings.Cut(pattern, "**")
-	rest := strings.TrimPrefix(after, "/")
-
-	// The prefix (everything before **) must match the beginning of s.
-	if prefix != "" {
-		if !strings.HasPrefix(s+"/", prefix) {
-			return false
-		}
-		s = strings.TrimPrefix(s, strings.TrimSuffix(prefix, "/"))
-		s = strings.TrimPrefix(s, "/")
-	}
-
-	// ** matches zero or more segments. Try each possible split.
-	if rest == "" {
-		return true // trailing ** matches everything
-	}
-	parts := strings.Split(s, "/")
-	for i := 0; i <= len(parts); i++ {
-		candidate := strings.Join(parts[i:], "/")
-		if matchGlob(rest, candidate) {
-			return true
-		}
-	}
-	return false
-}
-
 // searchTools returns the workspace search tools (grep + find).
 // Both are read-only and safe to register in the planner loop.
 func searchTools() []mcp.Tool {
@@ -227,33 +227,6 @@ func TestFindTool_ScopedToSubdir(t *testing.T) {
 	assert.Contains(t, files[0], "internal")
 }
 
-// ── matchGlob unit tests ──────────────────────────────────────────────────────
-
-func TestMatchGlob_Basename(t *testing.T) {
-	assert.True(t, matchGlob("*.go", "foo.go"))
-	assert.True(t, matchGlob("*.go", "src/foo.go"))
-	assert.False(t, matchGlob("*.go", "foo.txt"))
-}
-
-func TestMatchGlob_WithPath(t *testing.T) {
-	assert.True(t, matchGlob("internal/agent/*.go", "internal/agent/foo.go"))
-	assert.False(t, matchGlob("internal/agent/*.go", "internal/other/foo.go"))
-}
-
-func TestMatchGlob_DoubleStar(t *testing.T) {
-	assert.True(t, matchGlob("**/*_test.go", "foo_test.go"))
-	assert.True(t, matchGlob("**/*_test.go", "a/b/foo_test.go"))
-	assert.False(t, matchGlob("**/*_test.go", "a/b/foo.go"))
-	assert.True(t, matchGlob("**/*.go", "main.go"))
-	assert.True(t, matchGlob("**/*.go", "a/b/c/main.go"))
-}
-
-func TestMatchGlob_DoubleStarWithPrefix(t *testing.T) {
-	assert.True(t, matchGlob("internal/**/*.go", "internal/agent/foo.go"))
-	assert.True(t, matchGlob("internal/**/*.go", "internal/a/b/foo.go"))
-	assert.False(t, matchGlob("internal/**/*.go", "cmd/main.go"))
-}
-
 // ── read_file continuation hint tests ────────────────────────────────────────
 
 func TestReadFileTool_TruncationHint(t *testing.T) {
@@ -1397,7 +1397,7 @@ func (o *Orchestrator) formatProject(ctx context.Context, ws *agentWorkspace) st
 	if o.repoConfig == nil || o.repoConfig.FormatCommand == "" {
 		return ""
 	}
-	formatter := NewFormatter(o.logger)
+	formatter := &Formatter{logger: o.logger, sandbox: o.config.Sandbox}
 	if !formatter.FormatProject(ctx, ws.dir, o.repoConfig.FormatCommand) {
 		return ""
 	}
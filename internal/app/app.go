@@ -5,15 +5,22 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/sevigo/code-warden/internal/artifacts"
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/db"
 	"github.com/sevigo/code-warden/internal/gitutil"
 	"github.com/sevigo/code-warden/internal/globalmcp"
+	"github.com/sevigo/code-warden/internal/health"
+	"github.com/sevigo/code-warden/internal/jobs"
+	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/notify"
 	"github.com/sevigo/code-warden/internal/rag"
 	"github.com/sevigo/code-warden/internal/repomanager"
+	"github.com/sevigo/code-warden/internal/secrets"
 	"github.com/sevigo/code-warden/internal/server"
 	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/vectorgc"
 )
 
 // App holds the main dependencies of the application.
@@ -29,6 +36,19 @@ type App struct {
 	Server      *server.Server
 	GitClient   *gitutil.Client
 	MCPServer   *globalmcp.Server
+	Artifacts   *artifacts.Store
+	// CheckRunReaper concludes check runs left in progress by crashed jobs.
+	CheckRunReaper *jobs.CheckRunReaper
+	// HealthMonitor decides whether queued review jobs may run.
+	HealthMonitor *health.Monitor
+	// CollectionGC deletes vector collections of deleted repositories.
+	CollectionGC *vectorgc.Collector
+	// Notifier sends review emails and the daily digests.
+	Notifier *notify.Notifier
+	// Vault stores credentials encrypted; nil without an encryption key.
+	Vault *secrets.Vault
+	// Warmer loads the models before the first review needs them.
+	Warmer *llm.Warmer
 }
 
 // NewApp creates a new App instance.
@@ -43,6 +63,13 @@ func NewApp(
 	srv *server.Server,
 	gitClient *gitutil.Client,
 	mcpServer *globalmcp.Server,
+	artifactStore *artifacts.Store,
+	checkRunReaper *jobs.CheckRunReaper,
+	healthMonitor *health.Monitor,
+	collectionGC *vectorgc.Collector,
+	notifier *notify.Notifier,
+	vault *secrets.Vault,
+	warmer *llm.Warmer,
 	logger *slog.Logger,
 ) *App {
 	logger.Info("initializing Code Warden application",
@@ -65,11 +92,21 @@ func NewApp(
 		Server:      srv,
 		GitClient:   gitClient,
 		MCPServer:   mcpServer,
+		Artifacts:   artifactStore,
 		Logger:      logger,
+
+		CheckRunReaper: checkRunReaper,
+		HealthMonitor:  healthMonitor,
+		CollectionGC:   collectionGC,
+		Notifier:       notifier,
+		Vault:          vault,
+		Warmer:         warmer,
 	}
 }
 
-// Start runs the HTTP server and MCP server.
+// Start runs the HTTP server and MCP server, the check run reaper, the health
+// monitor, the collection garbage collector, the email digests and the model
+// warm-up.
 func (a *App) Start() error {
 	a.Logger.Info("application config",
 		"port", a.Cfg.Server.Port,
@@ -84,6 +121,28 @@ func (a *App) Start() error {
 		}
 	}
 
+	if a.CheckRunReaper != nil {
+		a.CheckRunReaper.Start()
+	}
+
+	if a.HealthMonitor != nil {
+		a.HealthMonitor.Start()
+	}
+
+	if a.CollectionGC != nil {
+		a.CollectionGC.Start()
+	}
+
+	if a.Notifier != nil {
+		a.Notifier.Start()
+	}
+
+	// The warm-up runs in the background, so a slow or unreachable model
+	// server does not delay serving webhooks.
+	if a.Warmer != nil {
+		a.Warmer.Start()
+	}
+
 	if err := a.Server.Start(); err != nil {
 		a.Logger.Error("failed to start HTTP server", "error", err)
 		return err
@@ -108,9 +167,29 @@ func (a *App) Stop() error {
 		}
 	}
 
+	if a.CheckRunReaper != nil {
+		a.CheckRunReaper.Stop()
+	}
+
+	if a.CollectionGC != nil {
+		a.CollectionGC.Stop()
+	}
+
+	if a.Notifier != nil {
+		a.Notifier.Stop()
+	}
+
+	if a.Warmer != nil {
+		a.Warmer.Stop()
+	}
+
 	// Stop the job dispatcher, allowing in-flight jobs to finish.
 	a.Dispatcher.Stop()
 
+	if a.HealthMonitor != nil {
+		a.HealthMonitor.Stop()
+	}
+
 	// Stop the HTTP server to prevent new incoming requests.
 	if a.Server != nil {
 		if err := a.Server.Stop(); err != nil {
@@ -0,0 +1,131 @@
+// Package applyfix applies the code suggestion of a posted review comment to
+// the file it was made on.
+package applyfix
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+var (
+	// ErrNoSuggestion is returned when a comment holds no suggestion block.
+	ErrNoSuggestion = errors.New("comment has no code suggestion")
+	// ErrConflict is returned when the lines a suggestion replaces changed
+	// since it was made and cannot be found unambiguously.
+	ErrConflict = errors.New("the lines of the suggestion changed since it was made")
+	// ErrAlreadyApplied is returned when the file already holds the
+	// suggested code instead of the lines it replaces.
+	ErrAlreadyApplied = errors.New("the suggestion is already applied")
+)
+
+// Patch replaces lines StartLine to Line of a file, which read Original
+// when the suggestion was made, with Replacement.
+type Patch struct {
+	StartLine   int
+	Line        int
+	Original    []string
+	Replacement []string
+}
+
+// ExtractSuggestion returns the lines of the first ```suggestion block of a
+// comment body.
+func ExtractSuggestion(body string) ([]string, error) {
+	const fence = "```suggestion\n"
+	start := strings.Index(body, fence)
+	if start < 0 {
+		return nil, ErrNoSuggestion
+	}
+	rest := body[start+len(fence):]
+	end := strings.Index(rest, "```")
+	if end < 0 {
+		return nil, ErrNoSuggestion
+	}
+	code := strings.TrimSuffix(rest[:end], "\n")
+	if code == "" {
+		// An empty suggestion deletes the lines.
+		return []string{}, nil
+	}
+	return strings.Split(code, "\n"), nil
+}
+
+// ThreadLines returns the lines of the new file that a comment covering
+// startLine to line was made on: the last lines of its diff hunk. startLine
+// is zero for a single-line comment.
+func ThreadLines(diffHunk string, startLine, line int) []string {
+	var lines []string
+	for _, l := range strings.Split(diffHunk, "\n") {
+		if l == "" || strings.HasPrefix(l, "@@") || strings.HasPrefix(l, "-") || strings.HasPrefix(l, `\`) {
+			continue
+		}
+		lines = append(lines, l[1:])
+	}
+	n := 1
+	if startLine > 0 && startLine < line {
+		n = line - startLine + 1
+	}
+	n = min(n, len(lines))
+	return lines[len(lines)-n:]
+}
+
+// Apply returns content with p applied. The original lines are expected at
+// their position; if commits since moved them, they are looked up elsewhere
+// in the file and replaced if they occur exactly once. Otherwise Apply
+// returns ErrConflict, or ErrAlreadyApplied when the replacement is found
+// instead.
+func Apply(content []byte, p Patch) ([]byte, error) {
+	if len(p.Original) == 0 {
+		return nil, ErrConflict
+	}
+	text := string(content)
+	trailingNewline := strings.HasSuffix(text, "\n")
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	start := p.StartLine
+	if start <= 0 || start > p.Line {
+		start = p.Line
+	}
+	at := start - 1
+	if !matchesAt(lines, p.Original, at) {
+		found := findAll(lines, p.Original)
+		if len(found) != 1 {
+			if len(p.Replacement) > 0 && len(findAll(lines, p.Replacement)) > 0 {
+				return nil, ErrAlreadyApplied
+			}
+			return nil, ErrConflict
+		}
+		at = found[0]
+	}
+
+	patched := slices.Concat(lines[:at], p.Replacement, lines[at+len(p.Original):])
+	out := strings.Join(patched, "\n")
+	if trailingNewline {
+		out += "\n"
+	}
+	return []byte(out), nil
+}
+
+// matchesAt reports whether lines holds want starting at index at. Trailing
+// whitespace is ignored.
+func matchesAt(lines, want []string, at int) bool {
+	if at < 0 || at+len(want) > len(lines) {
+		return false
+	}
+	for i, w := range want {
+		if strings.TrimRight(lines[at+i], " \t\r") != strings.TrimRight(w, " \t\r") {
+			return false
+		}
+	}
+	return true
+}
+
+// findAll returns the indexes at which lines holds want.
+func findAll(lines, want []string) []int {
+	var found []int
+	for i := 0; i+len(want) <= len(lines); i++ {
+		if matchesAt(lines, want, i) {
+			found = append(found, i)
+		}
+	}
+	return found
+}
@@ -0,0 +1,86 @@
+package applyfix
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractSuggestion(t *testing.T) {
+	lines, err := ExtractSuggestion("**🟠 High**\n\nCheck the error.\n\n```suggestion\n\tif err != nil {\n\t\treturn err\n\t}\n```\n\n<!-- code-warden:suggestion -->")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"\tif err != nil {", "\t\treturn err", "\t}"}, lines)
+
+	lines, err = ExtractSuggestion("Remove this line.\n\n```suggestion\n```")
+	require.NoError(t, err)
+	assert.Empty(t, lines, "an empty suggestion deletes the lines")
+
+	_, err = ExtractSuggestion("Consider a constant.\n\n```go\nconst x = 1\n```")
+	assert.ErrorIs(t, err, ErrNoSuggestion)
+}
+
+func TestThreadLines(t *testing.T) {
+	hunk := "@@ -1,4 +1,5 @@\n package main\n-var a = 1\n+var a = 2\n+var b = 3\n func main() {}"
+	assert.Equal(t, []string{"func main() {}"}, ThreadLines(hunk, 0, 4))
+	assert.Equal(t, []string{"var b = 3", "func main() {}"}, ThreadLines(hunk, 3, 4))
+	assert.Len(t, ThreadLines(hunk, 1, 40), 4, "ranges longer than the hunk are capped")
+}
+
+func TestApply(t *testing.T) {
+	content := []byte("package main\n\nfunc main() {\n\tx := load()\n\tuse(x)\n}\n")
+	patch := Patch{
+		StartLine:   4,
+		Line:        4,
+		Original:    []string{"\tx := load()"},
+		Replacement: []string{"\tx, err := load()", "\tif err != nil {", "\t\tpanic(err)", "\t}"},
+	}
+	want := "package main\n\nfunc main() {\n\tx, err := load()\n\tif err != nil {\n\t\tpanic(err)\n\t}\n\tuse(x)\n}\n"
+
+	tests := []struct {
+		name    string
+		content string
+		patch   Patch
+		want    string
+		wantErr error
+	}{
+		{name: "in place", content: string(content), patch: patch, want: want},
+		{
+			name:    "moved by a later commit",
+			content: "package main\n\nimport \"os\"\n\nfunc main() {\n\tx := load()\n\tuse(x)\n}\n",
+			patch:   patch,
+			want:    "package main\n\nimport \"os\"\n\nfunc main() {\n\tx, err := load()\n\tif err != nil {\n\t\tpanic(err)\n\t}\n\tuse(x)\n}\n",
+		},
+		{
+			name:    "changed by a later commit",
+			content: "package main\n\nfunc main() {\n\tx := loadAll()\n\tuse(x)\n}\n",
+			patch:   patch,
+			wantErr: ErrConflict,
+		},
+		{
+			name:    "ambiguous",
+			content: "package main\n\nfunc a() {\n\tx := load()\n}\n\nfunc b() {\n\tx := load()\n}\n",
+			patch:   Patch{StartLine: 9, Line: 9, Original: patch.Original, Replacement: patch.Replacement},
+			wantErr: ErrConflict,
+		},
+		{name: "already applied", content: want, patch: patch, wantErr: ErrAlreadyApplied},
+		{
+			name:    "delete lines",
+			content: string(content),
+			patch:   Patch{StartLine: 4, Line: 5, Original: []string{"\tx := load()", "\tuse(x)"}, Replacement: []string{}},
+			want:    "package main\n\nfunc main() {\n}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Apply([]byte(tt.content), tt.patch)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
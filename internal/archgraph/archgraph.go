@@ -0,0 +1,270 @@
+// Package archgraph builds a graph of a repository's directories, their
+// architectural summaries and the imports between them, and renders it as
+// Graphviz DOT, Mermaid or a Markdown report.
+package archgraph
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// RootDir is the path arch summaries use for the repository root.
+const RootDir = "root"
+
+// Output formats accepted by Render.
+const (
+	FormatDOT      = "dot"
+	FormatMermaid  = "mermaid"
+	FormatMarkdown = "markdown"
+)
+
+// Formats lists the formats Render accepts.
+var Formats = []string{FormatDOT, FormatMermaid, FormatMarkdown}
+
+// Edge kinds.
+const (
+	EdgeContains = "contains"
+	EdgeImports  = "imports"
+)
+
+// maxLabelSummary bounds the summary excerpt shown in Mermaid node labels.
+const maxLabelSummary = 80
+
+// Directory is the input for one directory of the repository.
+type Directory struct {
+	Path    string
+	Summary string
+	// Imports are the import paths found in the directory's files, as the
+	// language writes them.
+	Imports []string
+}
+
+// Node is a directory in the graph.
+type Node struct {
+	Path    string `json:"path"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// Edge connects two directories, either a parent to a child directory or an
+// importing directory to an imported one.
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+// Graph is the architecture graph of a repository.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// Build builds the graph of dirs. Imports are resolved to directories of the
+// repository by path suffix, so module-qualified Go imports, relative
+// JavaScript imports and dotted Python imports all resolve; imports of
+// external packages are dropped.
+func Build(dirs []Directory) *Graph {
+	g := &Graph{}
+	known := make(map[string]bool, len(dirs))
+	for _, d := range dirs {
+		known[d.Path] = true
+	}
+
+	sorted := slices.Clone(dirs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	seen := make(map[Edge]bool)
+	addEdge := func(e Edge) {
+		if e.From == e.To || seen[e] {
+			return
+		}
+		seen[e] = true
+		g.Edges = append(g.Edges, e)
+	}
+
+	for _, d := range sorted {
+		g.Nodes = append(g.Nodes, Node{Path: d.Path, Summary: strings.TrimSpace(d.Summary)})
+		if parent := parentOf(d.Path, known); parent != "" {
+			addEdge(Edge{From: parent, To: d.Path, Kind: EdgeContains})
+		}
+		for _, imp := range d.Imports {
+			if target := resolveImport(d.Path, imp, known); target != "" {
+				addEdge(Edge{From: d.Path, To: target, Kind: EdgeImports})
+			}
+		}
+	}
+	return g
+}
+
+// parentOf returns the closest known ancestor of dir, falling back to the
+// root directory.
+func parentOf(dir string, known map[string]bool) string {
+	if dir == RootDir {
+		return ""
+	}
+	for p := path.Dir(dir); p != "." && p != "/"; p = path.Dir(p) {
+		if known[p] {
+			return p
+		}
+	}
+	if known[RootDir] {
+		return RootDir
+	}
+	return ""
+}
+
+// resolveImport maps an import path to a known directory, or returns "".
+func resolveImport(dir, imp string, known map[string]bool) string {
+	imp = strings.Trim(strings.TrimSpace(imp), `"'`)
+	if imp == "" {
+		return ""
+	}
+
+	if strings.HasPrefix(imp, "./") || strings.HasPrefix(imp, "../") {
+		base := dir
+		if base == RootDir {
+			base = "."
+		}
+		target := path.Join(base, imp)
+		// The import may name a file or module inside the directory.
+		for _, candidate := range []string{target, path.Dir(target)} {
+			if candidate == "." {
+				candidate = RootDir
+			}
+			if known[candidate] {
+				return candidate
+			}
+		}
+		return ""
+	}
+
+	candidates := []string{strings.TrimSuffix(imp, "/")}
+	if !strings.Contains(imp, "/") && strings.Contains(imp, ".") {
+		candidates = append(candidates, strings.ReplaceAll(imp, ".", "/"))
+	}
+	best := ""
+	for _, c := range candidates {
+		for k := range known {
+			if k == RootDir || len(k) <= len(best) {
+				continue
+			}
+			if c == k || strings.HasSuffix(c, "/"+k) {
+				best = k
+			}
+		}
+	}
+	return best
+}
+
+// Render renders g in format, one of FormatDOT, FormatMermaid or
+// FormatMarkdown. title heads the Markdown report.
+func (g *Graph) Render(format, title string) (string, error) {
+	switch format {
+	case FormatDOT:
+		return g.DOT(), nil
+	case FormatMermaid:
+		return g.Mermaid(), nil
+	case FormatMarkdown:
+		return g.Markdown(title), nil
+	default:
+		return "", fmt.Errorf("unknown graph format %q (want one of %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// DOT renders g as a Graphviz digraph. Summaries become node tooltips;
+// containment edges are dashed.
+func (g *Graph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph architecture {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box, style=rounded, fontname=\"Helvetica\"];\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s", dotQuote(n.Path))
+		if n.Summary != "" {
+			fmt.Fprintf(&b, " [tooltip=%s]", dotQuote(n.Summary))
+		}
+		b.WriteString(";\n")
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if e.Kind == EdgeContains {
+			b.WriteString(" [style=dashed, arrowhead=none]")
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders g as a Mermaid flowchart. Node labels show the directory
+// and the first sentence of its summary; containment edges are dotted.
+func (g *Graph) Mermaid() string {
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, n := range g.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[n.Path] = id
+		label := mermaidEscape(n.Path)
+		if s := firstSentence(n.Summary, maxLabelSummary); s != "" {
+			label = "<b>" + label + "</b><br/>" + mermaidEscape(s)
+		}
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", id, label)
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if e.Kind == EdgeContains {
+			arrow = "-.-"
+		}
+		fmt.Fprintf(&b, "  %s %s %s\n", ids[e.From], arrow, ids[e.To])
+	}
+	return b.String()
+}
+
+// Markdown renders an onboarding report: the Mermaid diagram, which GitHub
+// and most Markdown viewers render inline, followed by every directory's
+// summary.
+func (g *Graph) Markdown(title string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s architecture\n\n", title)
+	b.WriteString("Dotted lines link a directory to its subdirectories, arrows point from a directory to the directories it imports.\n\n")
+	b.WriteString("```mermaid\n")
+	b.WriteString(g.Mermaid())
+	b.WriteString("```\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "\n## `%s`\n\n", n.Path)
+		if n.Summary == "" {
+			b.WriteString("_No summary yet._\n")
+			continue
+		}
+		b.WriteString(n.Summary)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "\n", " ").Replace(s)
+}
+
+// firstSentence returns the first sentence of s, cut to at most maxLen runes.
+func firstSentence(s string, maxLen int) string {
+	s = strings.TrimSpace(strings.TrimLeft(s, "# "))
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, ". "); i >= 0 {
+		s = s[:i+1]
+	}
+	if r := []rune(s); len(r) > maxLen {
+		s = string(r[:maxLen-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,81 @@
+package archgraph
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGraph() *Graph {
+	return Build([]Directory{
+		{Path: "internal/core", Summary: "Domain types. Shared by every package."},
+		{Path: RootDir, Summary: "Entry point."},
+		{Path: "internal/server", Imports: []string{
+			"github.com/acme/api/internal/core",
+			"github.com/go-chi/chi/v5",
+			"net/http",
+		}},
+		{Path: "ui/src/routes", Imports: []string{"../lib/api", "react"}},
+		{Path: "ui/src/lib"},
+		{Path: "app", Imports: []string{"app.models", "app"}},
+		{Path: "app/models"},
+	})
+}
+
+func TestBuild(t *testing.T) {
+	g := testGraph()
+
+	paths := make([]string, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		paths = append(paths, n.Path)
+	}
+	assert.Equal(t, []string{"app", "app/models", "internal/core", "internal/server", RootDir, "ui/src/lib", "ui/src/routes"}, paths)
+
+	assert.ElementsMatch(t, []Edge{
+		{From: RootDir, To: "app", Kind: EdgeContains},
+		{From: "app", To: "app/models", Kind: EdgeContains},
+		{From: "app", To: "app/models", Kind: EdgeImports},
+		{From: RootDir, To: "internal/core", Kind: EdgeContains},
+		{From: RootDir, To: "internal/server", Kind: EdgeContains},
+		{From: "internal/server", To: "internal/core", Kind: EdgeImports},
+		{From: RootDir, To: "ui/src/lib", Kind: EdgeContains},
+		{From: RootDir, To: "ui/src/routes", Kind: EdgeContains},
+		{From: "ui/src/routes", To: "ui/src/lib", Kind: EdgeImports},
+	}, g.Edges)
+}
+
+func TestRender(t *testing.T) {
+	g := Build([]Directory{
+		{Path: "internal/core", Summary: `Domain "types". Shared by every package.`},
+		{Path: "internal/server", Imports: []string{"github.com/acme/api/internal/core"}},
+	})
+
+	dot, err := g.Render(FormatDOT, "acme/api")
+	require.NoError(t, err)
+	assert.Equal(t, `digraph architecture {
+  rankdir=LR;
+  node [shape=box, style=rounded, fontname="Helvetica"];
+  "internal/core" [tooltip="Domain \"types\". Shared by every package."];
+  "internal/server";
+  "internal/server" -> "internal/core";
+}
+`, dot)
+
+	mermaid, err := g.Render(FormatMermaid, "acme/api")
+	require.NoError(t, err)
+	assert.Equal(t, `flowchart LR
+  n0["<b>internal/core</b><br/>Domain #quot;types#quot;."]
+  n1["internal/server"]
+  n1 --> n0
+`, mermaid)
+
+	report, err := g.Render(FormatMarkdown, "acme/api")
+	require.NoError(t, err)
+	assert.Contains(t, report, "# acme/api architecture")
+	assert.Contains(t, report, "```mermaid\n"+mermaid+"```\n")
+	assert.Contains(t, report, "## `internal/server`\n\n_No summary yet._")
+
+	_, err = g.Render("svg", "acme/api")
+	require.Error(t, err)
+}
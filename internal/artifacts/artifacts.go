@@ -0,0 +1,126 @@
+// Package artifacts stores generated files such as project structure docs,
+// architectural comparisons and raw model reviews outside of repository
+// clones. Artifacts are namespaced per repository and pruned by age and count.
+package artifacts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/objectstore"
+)
+
+const rootPrefix = "artifacts"
+
+// Store writes artifacts to an object store under
+// "artifacts/<owner>/<repo>/<name>".
+type Store struct {
+	objects    objectstore.Store
+	retention  time.Duration
+	maxPerRepo int
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+// New creates a new [Store] with the retention limits from cfg.
+func New(objects objectstore.Store, cfg config.ArtifactsConfig, logger *slog.Logger) *Store {
+	return &Store{
+		objects:    objects,
+		retention:  time.Duration(cfg.RetentionDays) * 24 * time.Hour,
+		maxPerRepo: cfg.MaxPerRepo,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// NewLocal creates a [Store] in dir on the local filesystem without
+// retention limits, for callers that manage an explicit output directory.
+func NewLocal(dir string, logger *slog.Logger) *Store {
+	return New(objectstore.NewLocal(dir), config.ArtifactsConfig{}, logger)
+}
+
+// Key returns the object key of artifact name for repoFullName ("owner/repo").
+func Key(repoFullName, name string) (string, error) {
+	prefix, err := repoPrefix(repoFullName)
+	if err != nil {
+		return "", err
+	}
+	clean := path.Clean(name)
+	if name == "" || clean == "." || path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("invalid artifact name %q", name)
+	}
+	return prefix + clean, nil
+}
+
+// repoPrefix returns the key prefix, with trailing slash, for a repository.
+func repoPrefix(repoFullName string) (string, error) {
+	owner, repo, ok := strings.Cut(repoFullName, "/")
+	if !ok || !validSegment(owner) || !validSegment(repo) || strings.Contains(repo, "/") {
+		return "", fmt.Errorf("invalid repository name %q", repoFullName)
+	}
+	return path.Join(rootPrefix, owner, repo) + "/", nil
+}
+
+func validSegment(s string) bool {
+	return s != "" && s != "." && s != ".."
+}
+
+// Save writes content as artifact name of repoFullName, replacing any
+// existing artifact with the same name.
+func (s *Store) Save(ctx context.Context, repoFullName, name, content string) error {
+	key, err := Key(repoFullName, name)
+	if err != nil {
+		return err
+	}
+	if err := s.objects.Put(ctx, key, strings.NewReader(content)); err != nil {
+		return fmt.Errorf("failed to save artifact %s: %w", name, err)
+	}
+	s.logger.Debug("saved artifact", "repo", repoFullName, "key", key)
+	return nil
+}
+
+// Prune deletes the artifacts of repoFullName that are older than the
+// retention period or beyond the newest MaxPerRepo. It returns the number
+// of artifacts deleted.
+func (s *Store) Prune(ctx context.Context, repoFullName string) (int, error) {
+	if s.retention <= 0 && s.maxPerRepo <= 0 {
+		return 0, nil
+	}
+	prefix, err := repoPrefix(repoFullName)
+	if err != nil {
+		return 0, err
+	}
+
+	objects, err := s.objects.List(ctx, prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list artifacts for %s: %w", repoFullName, err)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].ModTime.After(objects[j].ModTime)
+	})
+
+	cutoff := s.now().Add(-s.retention)
+	deleted := 0
+	for i, obj := range objects {
+		expired := s.retention > 0 && obj.ModTime.Before(cutoff)
+		overLimit := s.maxPerRepo > 0 && i >= s.maxPerRepo
+		if !expired && !overLimit {
+			continue
+		}
+		if err := s.objects.Delete(ctx, obj.Key); err != nil {
+			return deleted, fmt.Errorf("failed to delete artifact %s: %w", obj.Key, err)
+		}
+		deleted++
+	}
+
+	if deleted > 0 {
+		s.logger.Info("pruned artifacts", "repo", repoFullName, "deleted", deleted, "kept", len(objects)-deleted)
+	}
+	return deleted, nil
+}
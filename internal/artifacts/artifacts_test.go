@@ -0,0 +1,85 @@
+package artifacts
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/objectstore"
+)
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		repo    string
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{repo: "octo/repo", name: "project_structure.md", want: "artifacts/octo/repo/project_structure.md"},
+		{repo: "octo/repo", name: "reviews/pr7.md", want: "artifacts/octo/repo/reviews/pr7.md"},
+		{repo: "octo/repo", name: "../other/x.md", wantErr: true},
+		{repo: "octo/repo", name: "", wantErr: true},
+		{repo: "octo/repo", name: ".", wantErr: true},
+		{repo: "octo", name: "x.md", wantErr: true},
+		{repo: "../repo", name: "x.md", wantErr: true},
+		{repo: "octo/a/b", name: "x.md", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.repo+"|"+tt.name, func(t *testing.T) {
+			got, err := Key(tt.repo, tt.name)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := New(objectstore.NewLocal(root), config.ArtifactsConfig{RetentionDays: 7, MaxPerRepo: 2}, logger)
+
+	now := time.Now()
+	ages := map[string]time.Duration{
+		"newest.md":   time.Hour,
+		"newer.md":    2 * time.Hour,
+		"over_max.md": 3 * time.Hour,
+		"expired.md":  10 * 24 * time.Hour,
+	}
+	for name, age := range ages {
+		require.NoError(t, s.Save(ctx, "octo/repo", name, name))
+		key, err := Key("octo/repo", name)
+		require.NoError(t, err)
+		mtime := now.Add(-age)
+		require.NoError(t, os.Chtimes(filepath.Join(root, filepath.FromSlash(key)), mtime, mtime))
+	}
+	require.NoError(t, s.Save(ctx, "octo/other", "kept.md", "x"))
+
+	deleted, err := s.Prune(ctx, "octo/repo")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+
+	remaining, err := objectstore.NewLocal(root).List(ctx, "artifacts/")
+	require.NoError(t, err)
+	var keys []string
+	for _, o := range remaining {
+		keys = append(keys, o.Key)
+	}
+	assert.ElementsMatch(t, []string{
+		"artifacts/octo/repo/newest.md",
+		"artifacts/octo/repo/newer.md",
+		"artifacts/octo/other/kept.md",
+	}, keys)
+}
@@ -0,0 +1,105 @@
+// Package commitcheck checks pull request commit messages against the
+// Conventional Commits specification and the conventions a repository sets
+// in the commit_hygiene section of .code-warden.yml.
+package commitcheck
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// DefaultMaxSubjectLength applies when commit_hygiene.max_subject_length is
+// not set.
+const DefaultMaxSubjectLength = 72
+
+// DefaultTypes are the Conventional Commits types allowed when
+// commit_hygiene.types is not set.
+var DefaultTypes = []string{"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}
+
+// conventionalRegex matches "type(scope)!: description".
+var conventionalRegex = regexp.MustCompile(`^([a-zA-Z]+)(\([^()]+\))?(!)?: \S`)
+
+var (
+	fixupRegex = regexp.MustCompile(`^(fixup|squash|amend)! `)
+	wipRegex   = regexp.MustCompile(`(?i)^\W*wip\b`)
+	mergeRegex = regexp.MustCompile(`^Merge (branch|pull request|remote-tracking branch) `)
+)
+
+// Check checks the commit messages of a pull request. Merge commits are
+// skipped. An invalid subject_pattern is reported as an error, and the other
+// checks still run without it.
+func Check(messages []string, cfg core.CommitHygieneConfig) (*core.CommitHygiene, error) {
+	var patternErr error
+	var pattern *regexp.Regexp
+	if cfg.SubjectPattern != "" {
+		pattern, patternErr = regexp.Compile(cfg.SubjectPattern)
+		if patternErr != nil {
+			patternErr = fmt.Errorf("invalid commit_hygiene.subject_pattern: %w", patternErr)
+		}
+	}
+	maxLength := cfg.MaxSubjectLength
+	if maxLength <= 0 {
+		maxLength = DefaultMaxSubjectLength
+	}
+	types := cfg.Types
+	if len(types) == 0 {
+		types = DefaultTypes
+	}
+
+	result := &core.CommitHygiene{Commits: len(messages)}
+	for _, msg := range messages {
+		subject, rest, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+		subject = strings.TrimSpace(subject)
+		if mergeRegex.MatchString(subject) {
+			continue
+		}
+
+		var problems []string
+		switch {
+		case subject == "":
+			problems = append(problems, "empty commit message")
+		case fixupRegex.MatchString(subject):
+			problems = append(problems, "fixup commit; squash it before merging")
+		case wipRegex.MatchString(subject):
+			problems = append(problems, "work-in-progress commit; squash or reword it before merging")
+		default:
+			problems = checkSubject(subject, maxLength, cfg.Conventional, types, pattern)
+		}
+		if rest != "" && strings.TrimSpace(strings.SplitN(rest, "\n", 2)[0]) != "" {
+			problems = append(problems, "no blank line between subject and body")
+		}
+
+		if len(problems) > 0 {
+			result.Issues = append(result.Issues, core.CommitIssue{Subject: subject, Problems: problems})
+		}
+	}
+	return result, patternErr
+}
+
+func checkSubject(subject string, maxLength int, conventional bool, types []string, pattern *regexp.Regexp) []string {
+	var problems []string
+	if n := utf8.RuneCountInString(subject); n > maxLength {
+		problems = append(problems, fmt.Sprintf("subject is %d characters, the limit is %d", n, maxLength))
+	}
+	if strings.HasSuffix(subject, ".") && !strings.HasSuffix(subject, "...") {
+		problems = append(problems, "subject ends with a period")
+	}
+	if conventional {
+		m := conventionalRegex.FindStringSubmatch(subject)
+		switch {
+		case m == nil:
+			problems = append(problems, `not a Conventional Commits subject ("type(scope): description")`)
+		case !slices.Contains(types, strings.ToLower(m[1])):
+			problems = append(problems, fmt.Sprintf("type %q is not one of %s", m[1], strings.Join(types, ", ")))
+		}
+	}
+	if pattern != nil && !pattern.MatchString(subject) {
+		problems = append(problems, fmt.Sprintf("subject does not match `%s`", pattern))
+	}
+	return problems
+}
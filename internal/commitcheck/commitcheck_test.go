@@ -0,0 +1,82 @@
+package commitcheck
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		cfg     core.CommitHygieneConfig
+		want    []string
+	}{
+		{name: "clean", message: "Add pagination to the repos endpoint\n\nBody text."},
+		{name: "merge commits are skipped", message: "Merge branch 'main' into feature."},
+		{name: "fixup", message: "fixup! Add pagination", want: []string{"fixup commit; squash it before merging"}},
+		{name: "wip", message: "WIP: pagination", want: []string{"work-in-progress commit; squash or reword it before merging"}},
+		{name: "period", message: "Add pagination.", want: []string{"subject ends with a period"}},
+		{
+			name:    "too long",
+			message: strings.Repeat("a", 80),
+			want:    []string{"subject is 80 characters, the limit is 72"},
+		},
+		{
+			name:    "custom length",
+			message: "Add pagination to the repos endpoint",
+			cfg:     core.CommitHygieneConfig{MaxSubjectLength: 20},
+			want:    []string{"subject is 36 characters, the limit is 20"},
+		},
+		{
+			name:    "no blank line before body",
+			message: "Add pagination\nBody text.",
+			want:    []string{"no blank line between subject and body"},
+		},
+		{name: "conventional", message: "feat(api)!: add pagination", cfg: core.CommitHygieneConfig{Conventional: true}},
+		{
+			name:    "not conventional",
+			message: "Add pagination",
+			cfg:     core.CommitHygieneConfig{Conventional: true},
+			want:    []string{`not a Conventional Commits subject ("type(scope): description")`},
+		},
+		{
+			name:    "unknown type",
+			message: "feature: add pagination",
+			cfg:     core.CommitHygieneConfig{Conventional: true, Types: []string{"feat", "fix"}},
+			want:    []string{`type "feature" is not one of feat, fix`},
+		},
+		{
+			name:    "subject pattern",
+			message: "Add pagination",
+			cfg:     core.CommitHygieneConfig{SubjectPattern: `^[A-Z]+-[0-9]+ `},
+			want:    []string{"subject does not match `^[A-Z]+-[0-9]+ `"},
+		},
+		{name: "subject pattern matches", message: "API-12 Add pagination", cfg: core.CommitHygieneConfig{SubjectPattern: `^[A-Z]+-[0-9]+ `}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Check([]string{tt.message}, tt.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, 1, result.Commits)
+			if tt.want == nil {
+				assert.Empty(t, result.Issues)
+				return
+			}
+			require.Len(t, result.Issues, 1)
+			assert.Equal(t, tt.want, result.Issues[0].Problems)
+		})
+	}
+}
+
+func TestCheck_InvalidPattern(t *testing.T) {
+	result, err := Check([]string{"Add pagination."}, core.CommitHygieneConfig{SubjectPattern: "("})
+	require.Error(t, err)
+	require.Len(t, result.Issues, 1)
+	assert.Equal(t, []string{"subject ends with a period"}, result.Issues[0].Problems)
+}
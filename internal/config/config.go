@@ -5,30 +5,77 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/cryptoutil"
 	"github.com/sevigo/code-warden/internal/logger"
+	"github.com/sevigo/code-warden/internal/pathutil"
 )
 
 const (
 	llmProviderGemini = "gemini"
+
+	// ReviewOutputXML and ReviewOutputJSON are the supported values of
+	// ai.review_output_format.
+	ReviewOutputXML  = "xml"
+	ReviewOutputJSON = "json"
+
+	// WorktreeReset and WorktreeFail are the supported values of
+	// storage.worktree_policy.
+	WorktreeReset = "reset"
+	WorktreeFail  = "fail"
+
+	// VectorStoreQdrant, VectorStorePgvector, VectorStoreWeaviate and
+	// VectorStoreMemory are the supported values of
+	// storage.vector_store_provider.
+	VectorStoreQdrant   = "qdrant"
+	VectorStorePgvector = "pgvector"
+	VectorStoreWeaviate = "weaviate"
+	VectorStoreMemory   = "memory"
+
+	// SandboxNone, SandboxProcess and SandboxContainer are the supported
+	// values of sandbox.backend.
+	SandboxNone      = "none"
+	SandboxProcess   = "process"
+	SandboxContainer = "container"
+
+	// LinterGolangCI, LinterESLint and LinterRuff are the supported values
+	// of static_analysis.linters.
+	LinterGolangCI = "golangci-lint"
+	LinterESLint   = "eslint"
+	LinterRuff     = "ruff"
 )
 
 // Config represents the top-level configuration structure.
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	GitHub   GitHubConfig   `mapstructure:"github"`
-	AI       AIConfig       `mapstructure:"ai"`
-	Agent    AgentConfig    `mapstructure:"agent"`
-	Database DBConfig       `mapstructure:"database"`
-	Storage  StorageConfig  `mapstructure:"storage"`
-	Logging  logger.Config  `mapstructure:"logging"`
-	Features FeaturesConfig `mapstructure:"features"`
-	Warden   WardenConfig   `mapstructure:"warden"`
+	Server         ServerConfig         `mapstructure:"server"`
+	GitHub         GitHubConfig         `mapstructure:"github"`
+	AI             AIConfig             `mapstructure:"ai"`
+	Agent          AgentConfig          `mapstructure:"agent"`
+	Database       DBConfig             `mapstructure:"database"`
+	Storage        StorageConfig        `mapstructure:"storage"`
+	Logging        logger.Config        `mapstructure:"logging"`
+	Features       FeaturesConfig       `mapstructure:"features"`
+	Warden         WardenConfig         `mapstructure:"warden"`
+	Network        NetworkConfig        `mapstructure:"network"`
+	Git            GitConfig            `mapstructure:"git"`
+	OrgConfig      OrgConfig            `mapstructure:"org_config"`
+	Sandbox        SandboxConfig        `mapstructure:"sandbox"`
+	Limits         LimitsConfig         `mapstructure:"limits"`
+	Dependencies   DependenciesConfig   `mapstructure:"dependencies"`
+	StaticAnalysis StaticAnalysisConfig `mapstructure:"static_analysis"`
+	Notifications  NotificationsConfig  `mapstructure:"notifications"`
+	Usage          UsageConfig          `mapstructure:"usage"`
+	LLMMiddleware  LLMMiddlewareConfig  `mapstructure:"llm_middleware"`
+	Security       SecurityConfig       `mapstructure:"security"`
 }
 
 // AgentConfig holds configuration for the autonomous agent system.
@@ -213,6 +260,100 @@ type ServerConfig struct {
 	Port       string `mapstructure:"port"`
 	MaxWorkers int    `mapstructure:"max_workers"`
 	Theme      string `mapstructure:"theme"`
+	// AdminToken enables the admin API under /api/v1/admin, which requires
+	// it as a bearer token. The admin API is disabled when empty.
+	AdminToken string `mapstructure:"admin_token"`
+	// DashboardToken enables the review activity page under /activity,
+	// which requires it as a bearer token, a cookie or a "token" query
+	// parameter. The page is disabled when empty.
+	DashboardToken string `mapstructure:"dashboard_token"`
+	// StaleCheckRunAfter is how long a check run may stay in progress before
+	// the reaper concludes it. Zero disables the reaper.
+	StaleCheckRunAfter time.Duration `mapstructure:"stale_check_run_after"`
+	// CheckRunReapInterval is how often the reaper looks for stale check runs.
+	CheckRunReapInterval time.Duration `mapstructure:"check_run_reap_interval"`
+	// StaleCheckRunConclusion is the conclusion the reaper gives stale check
+	// runs: neutral, failure, cancelled or timed_out.
+	StaleCheckRunConclusion string `mapstructure:"stale_check_run_conclusion"`
+	// StaleReviewRetries is how often the reaper queues a review again whose
+	// check run went stale, as long as its pull request is open and at the
+	// same commit. Zero never queues it again.
+	StaleReviewRetries int `mapstructure:"stale_review_retries"`
+	// JobTimeout is how long a review job may run before it is cancelled
+	// and its check run concluded as timed out. Zero means no limit.
+	JobTimeout time.Duration `mapstructure:"job_timeout"`
+	// Admission holds review jobs back while the services they depend on
+	// are unhealthy.
+	Admission AdmissionConfig `mapstructure:"admission"`
+	// CircuitBreaker makes calls to a failing LLM, embedder or vector
+	// database fail fast.
+	CircuitBreaker CircuitBreakerConfig `mapstructure:"circuit_breaker"`
+	// Scheduling decides which queued job a free worker runs next.
+	Scheduling SchedulingConfig `mapstructure:"scheduling"`
+	// TraceReviews is how many recent reviews keep their log records in
+	// memory for GET /api/v1/reviews/{id}/trace. Zero disables tracing.
+	TraceReviews int `mapstructure:"trace_reviews"`
+}
+
+// StaleCheckRunConclusions are the conclusions the reaper can give stale
+// check runs.
+var StaleCheckRunConclusions = []string{"neutral", "failure", "cancelled", "timed_out"}
+
+// Priority classes of queued jobs, highest first.
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// SchedulingConfig is the policy by which workers pick queued jobs: the job
+// of the highest priority class first, alternating between repositories
+// within a class, and the oldest first within a repository.
+type SchedulingConfig struct {
+	// Priorities overrides the priority class of job kinds: "review",
+	// "rereview", "security", "explain", "feedback" and "reply" are high, "implement"
+	// is normal and "scan" (repository onboarding) is low by default.
+	Priorities map[string]string `mapstructure:"priorities"`
+	// MaxJobsPerRepo caps how many jobs of one repository run at once, so
+	// that one busy repository cannot occupy all workers. Zero means no cap.
+	MaxJobsPerRepo int `mapstructure:"max_jobs_per_repo"`
+	// AgingInterval raises a queued job by one priority class for every
+	// interval it has waited, so that low-priority jobs are not starved.
+	// Zero disables aging.
+	AgingInterval time.Duration `mapstructure:"aging_interval"`
+}
+
+// AdmissionConfig controls when queued review jobs are held instead of run
+// because the LLM provider or Qdrant is failing.
+type AdmissionConfig struct {
+	// Enabled turns admission control on.
+	Enabled bool `mapstructure:"enabled"`
+	// MaxLLMErrorRate holds jobs while at least this fraction of the LLM calls
+	// made within LLMWindow failed.
+	MaxLLMErrorRate float64 `mapstructure:"max_llm_error_rate"`
+	// MinLLMCalls is how many calls LLMWindow must contain before the error
+	// rate is taken into account.
+	MinLLMCalls int           `mapstructure:"min_llm_calls"`
+	LLMWindow   time.Duration `mapstructure:"llm_window"`
+	// MaxQdrantLatency holds jobs while the Qdrant health probe fails or
+	// takes longer than this.
+	MaxQdrantLatency time.Duration `mapstructure:"max_qdrant_latency"`
+	// ProbeInterval is how often Qdrant is probed.
+	ProbeInterval time.Duration `mapstructure:"probe_interval"`
+	// InitialBackoff is how long a held job waits before admission is checked
+	// again. The wait doubles up to MaxBackoff.
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+}
+
+// CircuitBreakerConfig controls the circuit breakers around the generator
+// LLM, the embedders and the vector database. After FailureThreshold
+// consecutive failures of a backend its calls fail immediately for Cooldown,
+// after which a single call probes whether it has recovered.
+type CircuitBreakerConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	FailureThreshold int           `mapstructure:"failure_threshold"`
+	Cooldown         time.Duration `mapstructure:"cooldown"`
 }
 
 type GitHubConfig struct {
@@ -220,8 +361,111 @@ type GitHubConfig struct {
 	WebhookSecret  string `mapstructure:"webhook_secret"`
 	PrivateKeyPath string `mapstructure:"private_key_path"`
 	Token          string `mapstructure:"token"` // For CLI or preload
+	// Onboarding sets up the repositories of new installations.
+	Onboarding OnboardingConfig `mapstructure:"onboarding"`
+	// AutoReview reviews pull requests without a /review command.
+	AutoReview AutoReviewConfig `mapstructure:"auto_review"`
+	// Comments controls how earlier review comments are kept up to date.
+	Comments CommentsConfig `mapstructure:"comments"`
+	// ApplyFix lets collaborators apply suggestions with "/warden apply".
+	ApplyFix ApplyFixConfig `mapstructure:"apply_fix"`
+	// Webhook bounds the webhook deliveries accepted and rejects replays.
+	Webhook WebhookConfig `mapstructure:"webhook"`
+}
+
+// WebhookConfig hardens the intake of GitHub webhook deliveries beyond their
+// signature.
+type WebhookConfig struct {
+	// MaxPayloadKB rejects larger deliveries. GitHub caps payloads at 25 MB.
+	MaxPayloadKB int `mapstructure:"max_payload_kb"`
+	// DeliveryTTL is how long the X-GitHub-Delivery IDs of accepted signed
+	// deliveries are remembered, so a replay of one is rejected; so is a
+	// manual redelivery, which reuses the ID. Zero disables replay
+	// protection.
+	DeliveryTTL time.Duration `mapstructure:"delivery_ttl"`
+}
+
+// OnboardingConfig controls what happens when the GitHub App is installed on
+// an account or granted access to more repositories.
+type OnboardingConfig struct {
+	// Enabled registers the repositories of new installations.
+	Enabled bool `mapstructure:"enabled"`
+	// Repos limits onboarding to repositories matching "owner/repo",
+	// "owner/*" or "*". When empty, every repository is onboarded.
+	Repos []string `mapstructure:"repos"`
+	// Index indexes onboarded repositories in the background, so the first
+	// review does not have to wait for it.
+	Index bool `mapstructure:"index"`
+	// WelcomeIssue opens an issue with setup instructions in every newly
+	// registered repository.
+	WelcomeIssue bool `mapstructure:"welcome_issue"`
+}
+
+// Allows reports whether repoFullName ("owner/repo") is onboarded.
+func (c *OnboardingConfig) Allows(repoFullName string) bool {
+	return len(c.Repos) == 0 || matchRepo(c.Repos, repoFullName)
+}
+
+// AutoReviewConfig controls reviews triggered by pull_request events, when a
+// pull request is opened or new commits are pushed to it.
+type AutoReviewConfig struct {
+	// Enabled queues a review for the pull_request actions in Events.
+	Enabled bool `mapstructure:"enabled"`
+	// Events are the pull_request actions that trigger a review: "opened",
+	// "reopened", "synchronize" and "ready_for_review".
+	Events []string `mapstructure:"events"`
+	// Repos limits auto-review to repositories matching "owner/repo",
+	// "owner/*" or "*". When empty, every repository is reviewed.
+	Repos []string `mapstructure:"repos"`
+	// Drafts also reviews draft pull requests.
+	Drafts bool `mapstructure:"drafts"`
+	// Debounce is how long a pull request must go without another event
+	// before it is reviewed, so that rapid pushes trigger one review of the
+	// final commit. Zero reviews on every event.
+	Debounce time.Duration `mapstructure:"debounce"`
+}
+
+// AutoReviewEvents are the pull_request actions auto-review can trigger on.
+var AutoReviewEvents = []string{"opened", "reopened", "synchronize", "ready_for_review"}
+
+// Triggers reports whether the pull_request action of a pull request in
+// repoFullName ("owner/repo") queues a review.
+func (c *AutoReviewConfig) Triggers(repoFullName, action string) bool {
+	return c.Enabled && slices.Contains(c.Events, action) &&
+		(len(c.Repos) == 0 || matchRepo(c.Repos, repoFullName))
+}
+
+// CommentsConfig controls what happens to the comments of earlier reviews
+// when a pull request is reviewed again.
+type CommentsConfig struct {
+	// HideOutdated marks and minimizes inline comments whose lines are no
+	// longer part of the diff.
+	HideOutdated bool `mapstructure:"hide_outdated"`
+	// SummaryInPlace keeps the review summary in one pull request comment,
+	// edited on every review, with the earlier rounds folded underneath.
+	SummaryInPlace bool `mapstructure:"summary_in_place"`
 }
 
+// ApplyFixConfig controls "/warden apply", which commits the code suggestion
+// of a review comment. It needs the Contents: Read & Write permission.
+type ApplyFixConfig struct {
+	// Enabled accepts "/warden apply" replies from collaborators.
+	Enabled bool `mapstructure:"enabled"`
+	// Mode is "pull_request" to commit to a new branch and open a pull
+	// request against the reviewed one, or "commit" to push to the reviewed
+	// branch directly.
+	Mode string `mapstructure:"mode"`
+	// BranchPrefix names the branches of "pull_request" mode, followed by
+	// the pull request number and the comment ID.
+	BranchPrefix string `mapstructure:"branch_prefix"`
+}
+
+// Modes of "/warden apply".
+const (
+	ApplyFixPullRequest = "pull_request"
+	ApplyFixCommit      = "commit"
+)
+
 type AIConfig struct {
 	LLMProvider          string   `mapstructure:"llm_provider"`
 	EmbedderProvider     string   `mapstructure:"embedder_provider"`
@@ -242,15 +486,19 @@ type AIConfig struct {
 	MaxConcurrentReviews int      `mapstructure:"max_concurrent_reviews"`
 	MaxComparisonModels  int      `mapstructure:"max_comparison_models"`
 	HyDEConcurrency      int      `mapstructure:"hyde_concurrency"`
-	ConsensusTimeout     string   `mapstructure:"consensus_timeout"` // Timeout for individual model reviews in consensus mode (e.g., "5m")
-	ConsensusQuorum      float64  `mapstructure:"consensus_quorum"`  // Percentage of models that must finish before synthesis (0.0 to 1.0)
+	HyDEModel            string   `mapstructure:"hyde_model"`            // Model that writes the hypothetical code (empty = generator_model); a small coder model cuts HyDE latency
+	HyDEMaxFiles         int      `mapstructure:"hyde_max_files"`        // Max changed files HyDE generates code for, largest patches first; per profile in retrieval.<profile>.hyde_max_files
+	ConsensusTimeout     string   `mapstructure:"consensus_timeout"`     // Timeout for individual model reviews in consensus mode (e.g., "5m")
+	ConsensusQuorum      float64  `mapstructure:"consensus_quorum"`      // Percentage of models that must finish before synthesis (0.0 to 1.0)
+	ConsensusMaxWorkers  int      `mapstructure:"consensus_max_workers"` // Max models generating reviews in parallel in consensus mode
 
 	// Thinking/Reasoning Mode - for models that support it (DeepSeek-R1, Qwen 3, etc.)
 	EnableThinking bool   `mapstructure:"enable_thinking"` // Enable thinking/reasoning mode
 	ThinkingEffort string `mapstructure:"thinking_effort"` // "low", "medium", "high" (for GPT-OSS models)
 
 	// Model Memory Management
-	ModelKeepAlive string `mapstructure:"model_keep_alive"` // How long to keep models loaded (e.g., "10m", "1h", "0" to unload immediately)
+	ModelKeepAlive string       `mapstructure:"model_keep_alive"` // How long to keep models loaded (e.g., "10m", "1h", "0" to unload immediately)
+	Warmup         WarmupConfig `mapstructure:"warmup"`           // Load the generator and embedder at startup instead of on the first review
 
 	// HTTP Client Overrides
 	HTTPResponseHeaderTimeout string `mapstructure:"http_response_header_timeout"` // Timeout for waiting for HTTP response headers (e.g., "30s", "120s")
@@ -262,9 +510,61 @@ type AIConfig struct {
 	RetrievalScoreThreshold float32 `mapstructure:"retrieval_score_threshold"` // Min cosine similarity to include a retrieved doc (0.0 = disabled)
 	RerankMinScore          float32 `mapstructure:"rerank_min_score"`          // Min reranker score to keep a doc after reranking (0.0 = disabled)
 
+	// Retrieval sets retrieval depth and context size per review profile
+	// (quick/standard/thorough). .code-warden.yml may override them within
+	// safe bounds; see RetrievalFor.
+	Retrieval core.RetrievalModes `mapstructure:"retrieval"`
+
+	// Large Diffs
+	MaxDiffTokens          int `mapstructure:"max_diff_tokens"`          // Diffs estimated above this are reviewed in groups of directories and merged (0 = never split)
+	SplitReviewConcurrency int `mapstructure:"split_review_concurrency"` // Max group reviews of a split diff running in parallel
+
+	// Indexing
+	IndexWorkers int `mapstructure:"index_workers"` // Files read, parsed and chunked in parallel when changed files are re-indexed
+
+	// Architectural Summaries
+	SummaryWorkers        int    `mapstructure:"summary_workers"`        // Directory summaries generated in parallel during indexing; fewer while the provider rate limits
+	ComparisonConcurrency int    `mapstructure:"comparison_concurrency"` // Max LLM calls in parallel when comparing the summaries of comparison_models
+	SummaryFallbackModel  string `mapstructure:"summary_fallback_model"` // Model asked for a summary the generator failed twice to write well (empty = none)
+
+	// Vector Store Outages
+	DegradedReviews bool `mapstructure:"degraded_reviews"` // Review the diff without repository context when the vector store is unavailable instead of failing
+
+	// Prompt Archive
+	ArchiveReviewInputs       bool `mapstructure:"archive_review_inputs"`        // Store the prompt inputs of each review so `review regenerate` can replay them
+	ReviewInputsRetentionDays int  `mapstructure:"review_inputs_retention_days"` // Delete archived prompt inputs older than this (0 = keep forever)
+
 	// Review Output Options
 	EnableCodeSuggestions bool   `mapstructure:"enable_code_suggestions"` // Include code suggestions in review comments (GitHub suggestion blocks)
 	ReviewsDir            string `mapstructure:"reviews_dir"`             // Directory to save review artifacts (default: "reviews")
+	ReviewOutputFormat    string `mapstructure:"review_output_format"`    // "xml" (default) or "json"; JSON falls back to the XML parser on malformed output
+
+	// Prompt Experiments
+	PromptExperiments []PromptExperimentConfig `mapstructure:"prompt_experiments"` // Review a share of pull requests with a variant of a prompt to compare feedback between versions
+
+	// Data Residency
+	LocalOnly bool `mapstructure:"local_only"` // Refuse to start if any provider would send code to an external API
+}
+
+// PromptExperimentConfig renders a prompt with the template of VariantFile
+// for Percent of the pull requests. The reviews and feedback of both record
+// their prompt version, so the two can be compared.
+type PromptExperimentConfig struct {
+	Prompt      string `mapstructure:"prompt"`       // Prompt key, e.g. "code_review"
+	VariantFile string `mapstructure:"variant_file"` // Path of the variant template
+	Percent     int    `mapstructure:"percent"`      // Share of pull requests reviewed with the variant, 0 to 100
+}
+
+// WarmupConfig sends the generator and embedder a tiny request at startup,
+// so that providers that load models lazily, like Ollama, have them loaded
+// before the first review. Failures are logged and never block startup.
+type WarmupConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Timeout time.Duration `mapstructure:"timeout"` // Max time each model may take to answer, including loading it
+	// KeepAliveInterval repeats the requests this often so that the models
+	// stay loaded between reviews (0 = warm up once). It should be shorter
+	// than model_keep_alive.
+	KeepAliveInterval time.Duration `mapstructure:"keep_alive_interval"`
 }
 
 func (c *AIConfig) Validate() error {
@@ -277,17 +577,61 @@ func (c *AIConfig) Validate() error {
 	if c.ConsensusQuorum < 0 || c.ConsensusQuorum > 1 {
 		return errors.New("ai.consensus_quorum must be between 0.0 and 1.0")
 	}
+	if c.ConsensusMaxWorkers < 1 {
+		return errors.New("ai.consensus_max_workers must be >= 1")
+	}
 	if err := c.validateModels(); err != nil {
 		return err
 	}
 	return c.validatePaths()
 }
 
+func (c *AIConfig) validatePromptExperiments() error {
+	seen := make(map[string]bool, len(c.PromptExperiments))
+	for i, e := range c.PromptExperiments {
+		if e.Prompt == "" || e.VariantFile == "" {
+			return fmt.Errorf("ai.prompt_experiments[%d] needs a prompt and a variant_file", i)
+		}
+		if e.Percent < 0 || e.Percent > 100 {
+			return fmt.Errorf("ai.prompt_experiments[%d].percent must be between 0 and 100", i)
+		}
+		if seen[e.Prompt] {
+			return fmt.Errorf("ai.prompt_experiments has more than one experiment for prompt %q", e.Prompt)
+		}
+		seen[e.Prompt] = true
+	}
+	return nil
+}
+
+func (c *AIConfig) validateSummaries() error {
+	if c.SummaryWorkers < 1 {
+		return errors.New("ai.summary_workers must be >= 1")
+	}
+	if c.ComparisonConcurrency < 1 {
+		return errors.New("ai.comparison_concurrency must be >= 1")
+	}
+	return nil
+}
+
+func (c *AIConfig) validateWarmup() error {
+	if c.Warmup.KeepAliveInterval < 0 {
+		return errors.New("ai.warmup.keep_alive_interval must not be negative")
+	}
+	if c.Warmup.Enabled && c.Warmup.Timeout <= 0 {
+		return errors.New("ai.warmup.timeout must be positive when warm-up is enabled")
+	}
+	return nil
+}
+
+// maxComparisonModels caps the number of consensus models to prevent
+// timeout cascades.
+const maxComparisonModels = 10
+
 func (c *AIConfig) validateModels() error {
-	if len(c.ComparisonModels) > 10 {
+	if len(c.ComparisonModels) > maxComparisonModels {
 		return errors.New("comparison_models cannot exceed 10 to prevent timeout cascades")
 	}
-	if c.MaxComparisonModels > 10 {
+	if c.MaxComparisonModels > maxComparisonModels {
 		return errors.New("max_comparison_models cannot exceed 10")
 	}
 
@@ -314,20 +658,18 @@ func (c *AIConfig) validatePaths() error {
 }
 
 func validateComparisonPath(p string) error {
-	clean := filepath.Clean(p)
-
 	// Cross-platform absolute path check
-	if filepath.IsAbs(clean) || strings.HasPrefix(clean, "/") || strings.HasPrefix(clean, "\\") {
+	if filepath.IsAbs(p) || pathutil.IsAbs(p) {
 		return fmt.Errorf("comparison_paths must be relative: %s", p)
 	}
 
 	// Traversal check
-	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) || strings.HasPrefix(clean, "../") {
+	if pathutil.EscapesRoot(p) {
 		return fmt.Errorf("comparison_paths cannot contain traversal components: %s", p)
 	}
 
 	// Symlink validation
-	return validateSymlink(clean, p)
+	return validateSymlink(filepath.Clean(p), p)
 }
 
 func validateSymlink(clean, original string) error {
@@ -352,8 +694,379 @@ func validateSymlink(clean, original string) error {
 }
 
 type StorageConfig struct {
+	// VectorStoreProvider selects the vector database: "qdrant" (default),
+	// "pgvector" to keep embeddings in the Postgres database, "weaviate" for
+	// an existing Weaviate cluster, or "memory" for tests and throwaway runs.
+	// Set it with VECTOR_STORE_PROVIDER.
+	VectorStoreProvider string `mapstructure:"vector_store_provider"`
+
+	// WeaviateURL is the REST endpoint of Weaviate, e.g.
+	// "http://localhost:8080", and WeaviateAPIKey its API key, if any. Set
+	// them with WEAVIATE_URL and WEAVIATE_API_KEY.
+	WeaviateURL    string `mapstructure:"weaviate_url"`
+	WeaviateAPIKey string `mapstructure:"weaviate_api_key"`
+
 	QdrantHost string `mapstructure:"qdrant_host"`
 	RepoPath   string `mapstructure:"repo_path"`
+
+	// Cold storage: collections of rarely reviewed repos are snapshotted via
+	// the Qdrant REST API into the object store and restored on demand.
+	QdrantHTTPURL string `mapstructure:"qdrant_http_url"`
+
+	// ObjectStore holds snapshots and generated artifacts outside of clones.
+	ObjectStore ObjectStoreConfig `mapstructure:"object_store"`
+
+	// Artifacts controls retention of generated files (project structure,
+	// architectural comparisons, raw model reviews) in the object store.
+	Artifacts ArtifactsConfig `mapstructure:"artifacts"`
+
+	// WorktreePolicy decides what happens when a managed clone has
+	// uncommitted changes before it is updated: "reset" (default) discards
+	// them, "fail" aborts the job so the clone can be inspected.
+	WorktreePolicy string `mapstructure:"worktree_policy"`
+
+	// ReviewSnapshots checks out the synced commit into a temporary worktree
+	// for each review, so retrieval keeps reading the same files while other
+	// jobs move the clone on.
+	ReviewSnapshots bool `mapstructure:"review_snapshots"`
+
+	// CollectionGC deletes vector collections left behind by deleted or
+	// renamed repositories.
+	CollectionGC CollectionGCConfig `mapstructure:"collection_gc"`
+
+	// QueryCache keeps the results of recent similarity searches, so a diff
+	// reviewed again or a repeated question does not reach the embedder and
+	// the vector database.
+	QueryCache QueryCacheConfig `mapstructure:"query_cache"`
+}
+
+// QueryCacheConfig sizes the similarity search cache. Results are dropped
+// when the collection they came from changes. Zero max_entries disables it.
+type QueryCacheConfig struct {
+	MaxEntries int           `mapstructure:"max_entries"`
+	TTL        time.Duration `mapstructure:"ttl"`
+}
+
+// CollectionGCConfig controls the garbage collection of vector collections no
+// repository refers to. Only collections named by Code-Warden are considered.
+type CollectionGCConfig struct {
+	// Interval is how often the server collects orphaned collections; 0
+	// (the default) leaves it to `warden-cli vector gc`.
+	Interval time.Duration `mapstructure:"interval"`
+	// GracePeriod is how long a collection must stay orphaned before it is
+	// deleted, so that a repository being renamed or re-added keeps its index.
+	GracePeriod time.Duration `mapstructure:"grace_period"`
+}
+
+// UsesQdrant reports whether embeddings are kept in Qdrant.
+func (c *StorageConfig) UsesQdrant() bool {
+	return c.VectorStoreProvider == "" || c.VectorStoreProvider == VectorStoreQdrant
+}
+
+// NetworkConfig configures outbound connections for networks that require a
+// proxy or inspect TLS with a private certificate authority. Empty proxy
+// fields fall back to the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment
+// variables.
+type NetworkConfig struct {
+	HTTPSProxy string `mapstructure:"https_proxy"`
+	HTTPProxy  string `mapstructure:"http_proxy"`
+	NoProxy    string `mapstructure:"no_proxy"`
+	// CABundle is a PEM file of extra root certificates trusted for all
+	// outbound TLS connections in addition to the system roots.
+	CABundle string `mapstructure:"ca_bundle"`
+}
+
+// OrgConfig locates organization-wide repository defaults: the
+// .code-warden.yml in a designated repository of each organization (or user
+// account), which every repository's own .code-warden.yml merges over.
+type OrgConfig struct {
+	// Repo is the name of that repository, e.g. ".code-warden" for
+	// "<org>/.code-warden". Empty disables organization defaults.
+	Repo string `mapstructure:"repo"`
+	// RefreshInterval is how long fetched organization defaults are cached.
+	RefreshInterval time.Duration `mapstructure:"refresh_interval"`
+}
+
+// DependenciesConfig configures the analysis of dependencies added or upgraded
+// in manifest files (go.mod, package.json, requirements.txt, Cargo.toml).
+type DependenciesConfig struct {
+	// Enabled looks up known vulnerabilities and licenses of the changed
+	// dependencies during reviews. Only package names and versions are sent.
+	Enabled bool `mapstructure:"enabled"`
+	// OSVURL is the base URL of the OSV.dev API.
+	OSVURL string `mapstructure:"osv_url"`
+	// DepsDevURL is the base URL of the deps.dev API, used for licenses.
+	// Empty disables the license check.
+	DepsDevURL string `mapstructure:"deps_dev_url"`
+	// FlaggedLicenses are SPDX license identifiers reported as findings,
+	// matched by prefix so that "GPL" covers "GPL-3.0-only".
+	FlaggedLicenses []string `mapstructure:"flagged_licenses"`
+}
+
+// StaticAnalysisConfig configures the linters run on the head of reviewed
+// pull requests. Their findings on changed lines are shown to the model and
+// added to the review.
+type StaticAnalysisConfig struct {
+	// Enabled runs the linters during reviews. They execute in the
+	// configured sandbox, so the linters must be installed in its image when
+	// the container backend is used.
+	Enabled bool `mapstructure:"enabled"`
+	// Linters are the linters to run, each only when the pull request
+	// changes files it checks: "golangci-lint" (v2), "eslint" and "ruff".
+	Linters []string `mapstructure:"linters"`
+	// Timeout bounds each linter run.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxFindings caps the findings the model did not report itself that
+	// are added to a review.
+	MaxFindings int `mapstructure:"max_findings"`
+}
+
+// NotificationsConfig configures the notifications sent about reviews.
+type NotificationsConfig struct {
+	Email EmailConfig `mapstructure:"email"`
+}
+
+// EmailConfig configures email notifications. Who receives which messages
+// is stored in the database and managed with "warden-cli notify".
+type EmailConfig struct {
+	// Enabled sends per-review messages and daily digests to subscribers.
+	Enabled bool `mapstructure:"enabled"`
+	// SMTPHost and SMTPPort address the mail server. STARTTLS is used when
+	// the server offers it.
+	SMTPHost string `mapstructure:"smtp_host"`
+	SMTPPort int    `mapstructure:"smtp_port"`
+	// Username and Password authenticate with PLAIN auth; an empty username
+	// sends without authentication.
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// From is the sender address.
+	From string `mapstructure:"from"`
+	// DigestHour is the hour of the day (0-23, server time) from which the
+	// daily digest is sent.
+	DigestHour int `mapstructure:"digest_hour"`
+}
+
+// Middlewares of llm_middleware.chain.
+const (
+	MiddlewareLogging    = "logging"
+	MiddlewareCache      = "cache"
+	MiddlewareModeration = "moderation"
+)
+
+// LLMMiddlewareConfig declares the middleware chain that every LLM call
+// passes through: the generator, the fast model used for HyDE and query
+// expansion, the consensus models and the reranker alike. Token accounting
+// (see UsageConfig) always runs innermost, so cached responses cost nothing.
+type LLMMiddlewareConfig struct {
+	// Chain names the middlewares to apply, outermost first: "logging"
+	// logs every request and response at debug level, "cache" answers
+	// repeated prompts from memory and "moderation" rejects calls whose
+	// prompt or response matches a blocked pattern.
+	Chain      []string            `mapstructure:"chain"`
+	Cache      LLMCacheConfig      `mapstructure:"cache"`
+	Moderation LLMModerationConfig `mapstructure:"moderation"`
+}
+
+// LLMCacheConfig configures the "cache" middleware, which keys responses by
+// a hash of the model, the messages and the call options.
+type LLMCacheConfig struct {
+	TTL        time.Duration `mapstructure:"ttl"`
+	MaxEntries int           `mapstructure:"max_entries"`
+}
+
+// LLMModerationConfig configures the "moderation" middleware.
+type LLMModerationConfig struct {
+	// BlockedPatterns are regular expressions; a call whose prompt or
+	// response matches one fails without its response being used.
+	BlockedPatterns []string `mapstructure:"blocked_patterns"`
+}
+
+// UsageConfig configures the accounting of LLM token usage.
+type UsageConfig struct {
+	// Enabled records the token counts of every LLM call in the database.
+	Enabled bool `mapstructure:"enabled"`
+	// Prices estimate the cost of the usage per model. Models without a
+	// price, such as local ones, are reported without cost.
+	Prices []ModelPrice `mapstructure:"prices"`
+}
+
+// ModelPrice is the price of a model in currency units per million tokens.
+// It is a list entry rather than a map key because model names contain dots.
+type ModelPrice struct {
+	Model            string  `mapstructure:"model"`
+	InputPerMillion  float64 `mapstructure:"input_per_million"`
+	OutputPerMillion float64 `mapstructure:"output_per_million"`
+}
+
+// Cost returns the estimated cost of the tokens of a model, and false if the
+// model has no price.
+func (c *UsageConfig) Cost(model string, promptTokens, completionTokens int64) (float64, bool) {
+	for _, p := range c.Prices {
+		if p.Model == model {
+			return (float64(promptTokens)*p.InputPerMillion + float64(completionTokens)*p.OutputPerMillion) / 1e6, true
+		}
+	}
+	return 0, false
+}
+
+// SecurityConfig holds the keys credentials are encrypted with before they
+// are stored in the database. Keys are 32 random bytes, base64 encoded, as
+// printed by "openssl rand -base64 32". Without a key no credential is
+// stored.
+type SecurityConfig struct {
+	// EncryptionKey is the key new credentials are encrypted with.
+	// EncryptionKeyFile reads it from a file instead, such as a secret
+	// mounted from a KMS.
+	EncryptionKey     string `mapstructure:"encryption_key"`
+	EncryptionKeyFile string `mapstructure:"encryption_key_file"`
+	// PreviousEncryptionKeys still decrypt credentials after a key rotation
+	// until "warden-cli secrets rotate" has re-encrypted them.
+	PreviousEncryptionKeys []string `mapstructure:"previous_encryption_keys"`
+}
+
+// EncryptionKeys returns the decoded primary and previous keys, and a nil
+// primary key when none is configured.
+func (s *SecurityConfig) EncryptionKeys() (primary []byte, previous [][]byte, err error) {
+	encoded := s.EncryptionKey
+	if s.EncryptionKeyFile != "" {
+		data, err := os.ReadFile(s.EncryptionKeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read security.encryption_key_file: %w", err)
+		}
+		encoded = string(data)
+	}
+	if encoded == "" {
+		return nil, nil, nil
+	}
+	if primary, err = cryptoutil.ParseKey(encoded); err != nil {
+		return nil, nil, fmt.Errorf("invalid security.encryption_key: %w", err)
+	}
+	for i, k := range s.PreviousEncryptionKeys {
+		key, err := cryptoutil.ParseKey(k)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid security.previous_encryption_keys[%d]: %w", i, err)
+		}
+		previous = append(previous, key)
+	}
+	return primary, previous, nil
+}
+
+// SandboxConfig isolates commands taken from repositories, such as
+// verify_commands and format_command, from the host.
+type SandboxConfig struct {
+	// Backend is "none" (default), "process" or "container".
+	Backend string `mapstructure:"backend"`
+
+	// MemoryMB, CPUSeconds and MaxProcesses limit each command; zero means
+	// unlimited. The process backend counts MaxProcesses per user, so pair
+	// it with User. CPUSeconds does not apply to parse workers, which live
+	// for many files; ParseTimeout bounds them instead.
+	MemoryMB     int `mapstructure:"memory_mb"`
+	CPUSeconds   int `mapstructure:"cpu_seconds"`
+	MaxProcesses int `mapstructure:"max_processes"`
+
+	// User runs process-sandboxed commands as this unprivileged account,
+	// which requires code-warden to run as root. Empty keeps the current user.
+	User string `mapstructure:"user"`
+
+	// Engine is the container CLI, "docker" (default) or "podman".
+	Engine string `mapstructure:"engine"`
+	// Image is the container image commands run in; it must provide the
+	// repository's toolchain.
+	Image string `mapstructure:"image"`
+	// Runtime selects an OCI runtime such as "runsc" (gVisor).
+	Runtime string `mapstructure:"runtime"`
+	// Network gives containers network access; by default they have none.
+	Network bool `mapstructure:"network"`
+	// SeccompProfile is a seccomp profile applied to containers instead of
+	// the engine's default one. It never applies to parsing, which is only
+	// isolated with the process backend.
+	SeccompProfile string `mapstructure:"seccomp_profile"`
+
+	// IsolateParsing parses repository files in worker processes started
+	// through the process backend instead of in the server, so a parser
+	// crash or exploit stays within the worker's user and limits.
+	IsolateParsing bool `mapstructure:"isolate_parsing"`
+	// ParseWorkers is the number of parse workers.
+	ParseWorkers int `mapstructure:"parse_workers"`
+	// ParseTimeout bounds the parsing of one file; a worker that exceeds
+	// it is killed and the file is skipped.
+	ParseTimeout time.Duration `mapstructure:"parse_timeout"`
+}
+
+// LimitsConfig bounds the work done for one repository or pull request so
+// that oversized ones are skipped with a note instead of exhausting memory or
+// time. Repositories can lower the limits in .code-warden.yml but not raise
+// them. Zero means unlimited.
+type LimitsConfig struct {
+	// MaxFileSizeKB skips larger files when indexing.
+	MaxFileSizeKB int `mapstructure:"max_file_size_kb"`
+	// MaxFiles caps the files in the index of a repository; files beyond it
+	// are left out.
+	MaxFiles int `mapstructure:"max_files"`
+	// MaxDiffKB caps the diff of a review; the files that do not fit are
+	// listed as not reviewed in the review summary.
+	MaxDiffKB int `mapstructure:"max_diff_kb"`
+}
+
+// GitConfig selects how managed clones authenticate to their git remote.
+// Repositories not listed in SSHRepos use HTTPS with the installation token.
+type GitConfig struct {
+	// SSHRepos lists repositories cloned and fetched over SSH, as "owner/repo",
+	// "owner/*" or "*" for all repositories.
+	SSHRepos []string `mapstructure:"ssh_repos"`
+	SSHUser  string   `mapstructure:"ssh_user"`
+	// SSHKeyPath is the private key used for SSH remotes. When empty, keys are
+	// taken from the ssh-agent at SSH_AUTH_SOCK.
+	SSHKeyPath    string `mapstructure:"ssh_key_path"`
+	SSHKnownHosts string `mapstructure:"ssh_known_hosts"`
+}
+
+// UseSSH reports whether repoFullName ("owner/repo") is configured for SSH.
+func (c *GitConfig) UseSSH(repoFullName string) bool {
+	return matchRepo(c.SSHRepos, repoFullName)
+}
+
+// matchRepo reports whether repoFullName matches one of the case-insensitive
+// patterns "owner/repo", "owner/*" or "*".
+func matchRepo(patterns []string, repoFullName string) bool {
+	name := strings.ToLower(repoFullName)
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "*" {
+			return true
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ArtifactsConfig sets per-repository retention for generated artifacts.
+// A zero value disables the corresponding limit.
+type ArtifactsConfig struct {
+	// RetentionDays deletes artifacts older than this many days.
+	RetentionDays int `mapstructure:"retention_days"`
+	// MaxPerRepo keeps at most this many artifacts per repository, newest first.
+	MaxPerRepo int `mapstructure:"max_per_repo"`
+}
+
+// ObjectStoreConfig selects where large artifacts and snapshots are kept.
+type ObjectStoreConfig struct {
+	// Backend is "local" (default), "s3" or "gcs". GCS is accessed through
+	// its S3-compatible XML API using HMAC keys.
+	Backend string `mapstructure:"backend"`
+	// Dir is the root directory for the local backend.
+	Dir string `mapstructure:"dir"`
+
+	Bucket          string `mapstructure:"bucket"`
+	Prefix          string `mapstructure:"prefix"`
+	Endpoint        string `mapstructure:"endpoint"`
+	Region          string `mapstructure:"region"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	PathStyle       bool   `mapstructure:"path_style"`
 }
 
 type FeaturesConfig struct {
@@ -472,6 +1185,15 @@ func LoadConfig() (*Config, error) {
 	// Map env vars like SERVER_PORT to server.port
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
+	if err := v.BindEnv("storage.vector_store_provider", "STORAGE_VECTOR_STORE_PROVIDER", "VECTOR_STORE_PROVIDER"); err != nil {
+		return nil, fmt.Errorf("failed to bind VECTOR_STORE_PROVIDER: %w", err)
+	}
+	if err := v.BindEnv("storage.weaviate_url", "STORAGE_WEAVIATE_URL", "WEAVIATE_URL"); err != nil {
+		return nil, fmt.Errorf("failed to bind WEAVIATE_URL: %w", err)
+	}
+	if err := v.BindEnv("storage.weaviate_api_key", "STORAGE_WEAVIATE_API_KEY", "WEAVIATE_API_KEY"); err != nil {
+		return nil, fmt.Errorf("failed to bind WEAVIATE_API_KEY: %w", err)
+	}
 
 	// 4. Unmarshal
 	var cfg Config
@@ -479,6 +1201,14 @@ func LoadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal configuration: %w", err)
 	}
 
+	// Local-only mode is a hard guarantee, so refuse to start rather than
+	// letting a later validation step be skipped by some entry point.
+	if cfg.AI.LocalOnly {
+		if err := cfg.AI.ValidateLocalOnly(); err != nil {
+			return nil, err
+		}
+	}
+
 	// Post-process / construct derived values if needed (e.g., DSN)
 	// (Note: DSN construction logic moved to where it's used or handled here if purely config-derived)
 
@@ -490,9 +1220,46 @@ func setDefaults(v *viper.Viper) {
 	// Server
 	v.SetDefault("server.port", "8080")
 	v.SetDefault("server.max_workers", 5)
+	v.SetDefault("server.admin_token", "")
+	v.SetDefault("server.dashboard_token", "")
+	v.SetDefault("server.stale_check_run_after", "2h")
+	v.SetDefault("server.check_run_reap_interval", "10m")
+	v.SetDefault("server.stale_check_run_conclusion", "neutral")
+	v.SetDefault("server.stale_review_retries", 0)
+	v.SetDefault("server.job_timeout", "30m")
+	v.SetDefault("server.trace_reviews", 200)
+	v.SetDefault("server.admission.enabled", true)
+	v.SetDefault("server.admission.max_llm_error_rate", 0.5)
+	v.SetDefault("server.admission.min_llm_calls", 5)
+	v.SetDefault("server.admission.llm_window", "5m")
+	v.SetDefault("server.admission.max_qdrant_latency", "2s")
+	v.SetDefault("server.admission.probe_interval", "15s")
+	v.SetDefault("server.admission.initial_backoff", "10s")
+	v.SetDefault("server.admission.max_backoff", "5m")
+	v.SetDefault("server.circuit_breaker.enabled", true)
+	v.SetDefault("server.circuit_breaker.failure_threshold", 5)
+	v.SetDefault("server.circuit_breaker.cooldown", "30s")
+	v.SetDefault("server.scheduling.max_jobs_per_repo", 0)
+	v.SetDefault("server.scheduling.aging_interval", "5m")
 
 	// GitHub
 	v.SetDefault("github.private_key_path", "keys/code-warden-app.private-key.pem")
+	v.SetDefault("github.onboarding.enabled", true)
+	v.SetDefault("github.onboarding.repos", []string{})
+	v.SetDefault("github.onboarding.index", false)
+	v.SetDefault("github.onboarding.welcome_issue", true)
+	v.SetDefault("github.auto_review.enabled", false)
+	v.SetDefault("github.auto_review.events", AutoReviewEvents)
+	v.SetDefault("github.auto_review.repos", []string{})
+	v.SetDefault("github.auto_review.drafts", false)
+	v.SetDefault("github.auto_review.debounce", "2m")
+	v.SetDefault("github.comments.hide_outdated", true)
+	v.SetDefault("github.comments.summary_in_place", true)
+	v.SetDefault("github.apply_fix.enabled", false)
+	v.SetDefault("github.apply_fix.mode", ApplyFixPullRequest)
+	v.SetDefault("github.apply_fix.branch_prefix", "code-warden/fix-")
+	v.SetDefault("github.webhook.max_payload_kb", 25*1024)
+	v.SetDefault("github.webhook.delivery_ttl", "72h")
 
 	// AI
 	v.SetDefault("ai.llm_provider", "ollama")
@@ -510,19 +1277,115 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("ai.rerank_min_score", float32(0.0)) // 0.0 = disabled; set e.g. 0.1 to drop weak reranked docs
 	v.SetDefault("ai.max_context_summaries", 1000)
 	v.SetDefault("ai.hyde_concurrency", 5)
+	v.SetDefault("ai.hyde_max_files", 10)
 	v.SetDefault("ai.enable_thinking", false)               // Disabled by default - enable per model
 	v.SetDefault("ai.thinking_effort", "medium")            // "low", "medium", "high"
 	v.SetDefault("ai.model_keep_alive", "10m")              // Keep models loaded for 10 minutes
 	v.SetDefault("ai.http_response_header_timeout", "180s") // 3 minutes for slow model loading
 	v.SetDefault("ai.http_request_timeout", "600s")         // 10 minutes overall timeout for large requests
 	v.SetDefault("ai.consensus_quorum", 0.66)
+	v.SetDefault("ai.consensus_max_workers", 2)
 	v.SetDefault("ai.context_token_budget", 100000)   // Tuned for 200K-256K context models; leaves ~100K for prompt + diff + output
 	v.SetDefault("ai.retrieval_score_threshold", 0.0) // 0.0 = disabled; set e.g. 0.3 to filter weak matches
 	v.SetDefault("ai.enable_code_suggestions", true)  // Include code suggestions by default
+	v.SetDefault("ai.local_only", false)
+	v.SetDefault("ai.review_output_format", ReviewOutputXML)
+	v.SetDefault("ai.max_diff_tokens", 40000)
+	v.SetDefault("ai.split_review_concurrency", 2)
+	v.SetDefault("ai.index_workers", 4)
+	v.SetDefault("ai.summary_workers", 5)
+	v.SetDefault("ai.comparison_concurrency", 10)
+	v.SetDefault("ai.degraded_reviews", true)
+	v.SetDefault("ai.archive_review_inputs", true)
+	v.SetDefault("ai.review_inputs_retention_days", 90)
+	v.SetDefault("ai.retrieval.quick.docs_per_query", 6)
+	v.SetDefault("ai.retrieval.quick.rerank_top_k", 3)
+	v.SetDefault("ai.retrieval.standard.docs_per_query", 10)
+	v.SetDefault("ai.retrieval.standard.rerank_top_k", 5)
+	v.SetDefault("ai.retrieval.thorough.docs_per_query", 15)
+	v.SetDefault("ai.retrieval.thorough.rerank_top_k", 8)
+	v.SetDefault("ai.warmup.enabled", false)
+	v.SetDefault("ai.warmup.timeout", "3m") // Like http_response_header_timeout, which covers model loading
+	v.SetDefault("ai.warmup.keep_alive_interval", 0)
 
 	// Storage
+	v.SetDefault("storage.vector_store_provider", VectorStoreQdrant)
 	v.SetDefault("storage.qdrant_host", "localhost:6334")
 	v.SetDefault("storage.repo_path", "./data/repos")
+	v.SetDefault("storage.qdrant_http_url", "http://localhost:6333")
+	v.SetDefault("storage.object_store.backend", "local")
+	v.SetDefault("storage.object_store.dir", "./data/objects")
+	v.SetDefault("storage.artifacts.retention_days", 30)
+	v.SetDefault("storage.artifacts.max_per_repo", 200)
+	v.SetDefault("storage.worktree_policy", WorktreeReset)
+	v.SetDefault("storage.collection_gc.interval", "0s")
+	v.SetDefault("storage.collection_gc.grace_period", "168h")
+	v.SetDefault("storage.review_snapshots", true)
+	v.SetDefault("storage.query_cache.max_entries", 512)
+	v.SetDefault("storage.query_cache.ttl", "10m")
+
+	// Network
+	v.SetDefault("network.https_proxy", "")
+	v.SetDefault("network.http_proxy", "")
+	v.SetDefault("network.no_proxy", "")
+	v.SetDefault("network.ca_bundle", "")
+
+	// Git
+	v.SetDefault("git.ssh_repos", []string{})
+	v.SetDefault("git.ssh_user", "git")
+	v.SetDefault("git.ssh_key_path", "")
+	v.SetDefault("git.ssh_known_hosts", "")
+
+	// Organization defaults
+	v.SetDefault("org_config.repo", "")
+	v.SetDefault("org_config.refresh_interval", "15m")
+
+	// Dependencies
+	v.SetDefault("dependencies.enabled", true)
+	v.SetDefault("dependencies.osv_url", "https://api.osv.dev")
+	v.SetDefault("dependencies.deps_dev_url", "https://api.deps.dev")
+	v.SetDefault("dependencies.flagged_licenses", []string{"AGPL", "GPL", "LGPL", "SSPL", "EUPL", "CC-BY-NC"})
+
+	// Static analysis
+	v.SetDefault("static_analysis.enabled", false)
+	v.SetDefault("static_analysis.linters", []string{LinterGolangCI, LinterESLint, LinterRuff})
+	v.SetDefault("static_analysis.timeout", "5m")
+	v.SetDefault("static_analysis.max_findings", 20)
+
+	v.SetDefault("notifications.email.enabled", false)
+	v.SetDefault("notifications.email.smtp_host", "")
+	v.SetDefault("notifications.email.smtp_port", 587)
+	v.SetDefault("notifications.email.username", "")
+	v.SetDefault("notifications.email.password", "")
+	v.SetDefault("notifications.email.from", "")
+	v.SetDefault("notifications.email.digest_hour", 8)
+	v.SetDefault("usage.enabled", true)
+	v.SetDefault("llm_middleware.chain", []string{})
+	v.SetDefault("llm_middleware.cache.ttl", "1h")
+	v.SetDefault("llm_middleware.cache.max_entries", 1000)
+	v.SetDefault("llm_middleware.moderation.blocked_patterns", []string{})
+	v.SetDefault("security.encryption_key", "")
+	v.SetDefault("security.encryption_key_file", "")
+
+	// Sandbox
+	v.SetDefault("sandbox.backend", SandboxNone)
+	v.SetDefault("sandbox.memory_mb", 0)
+	v.SetDefault("sandbox.cpu_seconds", 0)
+	v.SetDefault("sandbox.max_processes", 0)
+	v.SetDefault("sandbox.user", "")
+	v.SetDefault("sandbox.engine", "docker")
+	v.SetDefault("sandbox.image", "")
+	v.SetDefault("sandbox.runtime", "")
+	v.SetDefault("sandbox.network", false)
+	v.SetDefault("sandbox.seccomp_profile", "")
+	v.SetDefault("sandbox.isolate_parsing", false)
+	v.SetDefault("sandbox.parse_workers", 4)
+	v.SetDefault("sandbox.parse_timeout", "30s")
+
+	// Limits
+	v.SetDefault("limits.max_file_size_kb", 1024)
+	v.SetDefault("limits.max_files", 20000)
+	v.SetDefault("limits.max_diff_kb", 1024)
 
 	// Logging
 	v.SetDefault("logging.level", "info")
@@ -583,6 +1446,65 @@ func (c *Config) Validate() error {
 	if err := c.validateStorage(); err != nil {
 		errs = append(errs, err.Error())
 	}
+	if err := c.validateGit(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := c.validateSandbox(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := c.validateLimits(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := c.validateStaticAnalysis(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := c.validateNotifications(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := c.validateUsage(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := c.validateLLMMiddleware(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := c.validateSecurity(); err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, pattern := range c.GitHub.Onboarding.Repos {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid github.onboarding.repos pattern %q: %v", pattern, err))
+		}
+	}
+	for _, pattern := range c.GitHub.AutoReview.Repos {
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid github.auto_review.repos pattern %q: %v", pattern, err))
+		}
+	}
+	for _, event := range c.GitHub.AutoReview.Events {
+		if !slices.Contains(AutoReviewEvents, event) {
+			errs = append(errs, fmt.Sprintf("unknown github.auto_review.events action %q, expected one of %s", event, strings.Join(AutoReviewEvents, ", ")))
+		}
+	}
+	if c.GitHub.AutoReview.Debounce < 0 {
+		errs = append(errs, "github.auto_review.debounce must not be negative")
+	}
+	if a := c.GitHub.ApplyFix; a.Enabled {
+		if a.Mode != ApplyFixPullRequest && a.Mode != ApplyFixCommit {
+			errs = append(errs, fmt.Sprintf("github.apply_fix.mode must be '%s' or '%s'", ApplyFixPullRequest, ApplyFixCommit))
+		}
+		if a.Mode == ApplyFixPullRequest && a.BranchPrefix == "" {
+			errs = append(errs, "github.apply_fix.branch_prefix must not be empty")
+		}
+	}
+	if c.GitHub.Webhook.MaxPayloadKB <= 0 {
+		errs = append(errs, "github.webhook.max_payload_kb must be positive")
+	}
+	if c.GitHub.Webhook.DeliveryTTL < 0 {
+		errs = append(errs, "github.webhook.delivery_ttl must not be negative")
+	}
+	if c.OrgConfig.RefreshInterval < 0 {
+		errs = append(errs, "org_config.refresh_interval must not be negative")
+	}
 	if err := c.Warden.Validate(); err != nil {
 		errs = append(errs, err.Error())
 	}
@@ -618,6 +1540,28 @@ func (c *Config) validateAI() error {
 		errs = append(errs, err.Error())
 	}
 
+	if f := c.AI.ReviewOutputFormat; f != "" && f != ReviewOutputXML && f != ReviewOutputJSON {
+		errs = append(errs, "ai.review_output_format must be 'xml' or 'json'")
+	}
+
+	if err := c.AI.validatePromptExperiments(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := c.AI.validateWarmup(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if err := c.AI.validateSummaries(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	if c.AI.LocalOnly {
+		if err := c.AI.ValidateLocalOnly(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
 	if len(errs) > 0 {
 		return errors.New(strings.Join(errs, "; "))
 	}
@@ -628,6 +1572,53 @@ func (c *Config) validateServer() error {
 	if c.Server.MaxWorkers <= 0 {
 		return errors.New("server.max_workers must be positive")
 	}
+	if c.Server.StaleCheckRunAfter < 0 {
+		return errors.New("server.stale_check_run_after must not be negative")
+	}
+	if c.Server.JobTimeout < 0 {
+		return errors.New("server.job_timeout must not be negative")
+	}
+	if c.Server.TraceReviews < 0 {
+		return errors.New("server.trace_reviews must not be negative")
+	}
+	if c.Server.StaleCheckRunAfter > 0 && c.Server.CheckRunReapInterval <= 0 {
+		return errors.New("server.check_run_reap_interval must be positive")
+	}
+	if c.Server.StaleCheckRunAfter > 0 && !slices.Contains(StaleCheckRunConclusions, c.Server.StaleCheckRunConclusion) {
+		return fmt.Errorf("server.stale_check_run_conclusion must be one of %s, got %q", strings.Join(StaleCheckRunConclusions, ", "), c.Server.StaleCheckRunConclusion)
+	}
+	if c.Server.StaleReviewRetries < 0 {
+		return errors.New("server.stale_review_retries must not be negative")
+	}
+	if err := c.Server.Scheduling.validate(); err != nil {
+		return err
+	}
+	if a := c.Server.Admission; a.Enabled {
+		if a.MaxLLMErrorRate <= 0 || a.MaxLLMErrorRate > 1 {
+			return errors.New("server.admission.max_llm_error_rate must be between 0 and 1")
+		}
+		if a.LLMWindow <= 0 || a.ProbeInterval <= 0 || a.MaxQdrantLatency <= 0 {
+			return errors.New("server.admission.llm_window, probe_interval and max_qdrant_latency must be positive")
+		}
+		if a.InitialBackoff <= 0 || a.MaxBackoff < a.InitialBackoff {
+			return errors.New("server.admission.initial_backoff must be positive and not above max_backoff")
+		}
+	}
+	if b := c.Server.CircuitBreaker; b.Enabled && (b.FailureThreshold < 1 || b.Cooldown <= 0) {
+		return errors.New("server.circuit_breaker.failure_threshold and cooldown must be positive")
+	}
+	return nil
+}
+
+func (s SchedulingConfig) validate() error {
+	for kind, class := range s.Priorities {
+		if class != PriorityHigh && class != PriorityNormal && class != PriorityLow {
+			return fmt.Errorf("server.scheduling.priorities.%s must be '%s', '%s' or '%s'", kind, PriorityHigh, PriorityNormal, PriorityLow)
+		}
+	}
+	if s.MaxJobsPerRepo < 0 || s.AgingInterval < 0 {
+		return errors.New("server.scheduling.max_jobs_per_repo and aging_interval must not be negative")
+	}
 	return nil
 }
 
@@ -654,8 +1645,192 @@ func (c *Config) validateDatabase() error {
 }
 
 func (c *Config) validateStorage() error {
-	if c.Storage.QdrantHost == "" {
-		return errors.New("storage.qdrant_host is required")
+	switch c.Storage.VectorStoreProvider {
+	case "", VectorStoreQdrant:
+		if c.Storage.QdrantHost == "" {
+			return errors.New("storage.qdrant_host is required")
+		}
+	case VectorStoreWeaviate:
+		if c.Storage.WeaviateURL == "" {
+			return errors.New("storage.weaviate_url is required for the weaviate vector store")
+		}
+	case VectorStorePgvector, VectorStoreMemory:
+	default:
+		return fmt.Errorf("unsupported storage.vector_store_provider %q, expected qdrant, pgvector, weaviate or memory", c.Storage.VectorStoreProvider)
+	}
+	if c.Storage.CollectionGC.Interval < 0 || c.Storage.CollectionGC.GracePeriod < 0 {
+		return errors.New("storage.collection_gc.interval and grace_period must not be negative")
+	}
+	if qc := c.Storage.QueryCache; qc.MaxEntries < 0 || (qc.MaxEntries > 0 && qc.TTL <= 0) {
+		return errors.New("storage.query_cache.max_entries must not be negative and ttl must be positive")
+	}
+	switch c.Storage.ObjectStore.Backend {
+	case "", "local":
+	case "s3", "gcs":
+		if c.Storage.ObjectStore.Bucket == "" {
+			return fmt.Errorf("storage.object_store.bucket is required for backend %q", c.Storage.ObjectStore.Backend)
+		}
+	default:
+		return fmt.Errorf("unsupported storage.object_store.backend %q", c.Storage.ObjectStore.Backend)
+	}
+	if c.Storage.Artifacts.RetentionDays < 0 || c.Storage.Artifacts.MaxPerRepo < 0 {
+		return errors.New("storage.artifacts.retention_days and max_per_repo must not be negative")
+	}
+	if p := c.Storage.WorktreePolicy; p != "" && p != WorktreeReset && p != WorktreeFail {
+		return errors.New("storage.worktree_policy must be 'reset' or 'fail'")
+	}
+	return nil
+}
+
+func (c *Config) validateGit() error {
+	for _, pattern := range c.Git.SSHRepos {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid git.ssh_repos pattern %q: %w", pattern, err)
+		}
+	}
+	if len(c.Git.SSHRepos) == 0 {
+		return nil
+	}
+	if c.Git.SSHUser == "" {
+		return errors.New("git.ssh_user is required when git.ssh_repos is set")
+	}
+	for key, file := range map[string]string{
+		"git.ssh_key_path":    c.Git.SSHKeyPath,
+		"git.ssh_known_hosts": c.Git.SSHKnownHosts,
+	} {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateSandbox() error {
+	sb := c.Sandbox
+	if sb.MemoryMB < 0 || sb.CPUSeconds < 0 || sb.MaxProcesses < 0 {
+		return errors.New("sandbox limits must not be negative")
+	}
+	if sb.SeccompProfile != "" && sb.Backend != SandboxContainer {
+		return errors.New("sandbox.seccomp_profile requires the container backend")
+	}
+	if sb.IsolateParsing {
+		if sb.Backend != SandboxProcess {
+			return errors.New("sandbox.isolate_parsing requires the process backend")
+		}
+		if sb.ParseWorkers <= 0 || sb.ParseTimeout <= 0 {
+			return errors.New("sandbox.parse_workers and sandbox.parse_timeout must be positive")
+		}
+	}
+	switch sb.Backend {
+	case "", SandboxNone, SandboxProcess:
+		return nil
+	case SandboxContainer:
+		if sb.Image == "" {
+			return errors.New("sandbox.image is required for the container backend")
+		}
+		if sb.Engine != "" && sb.Engine != "docker" && sb.Engine != "podman" {
+			return errors.New("sandbox.engine must be 'docker' or 'podman'")
+		}
+		return nil
+	default:
+		return fmt.Errorf("sandbox.backend must be '%s', '%s' or '%s'", SandboxNone, SandboxProcess, SandboxContainer)
+	}
+}
+
+func (c *Config) validateLimits() error {
+	l := c.Limits
+	if l.MaxFileSizeKB < 0 || l.MaxFiles < 0 || l.MaxDiffKB < 0 {
+		return errors.New("limits must not be negative")
+	}
+	return nil
+}
+
+func (c *Config) validateStaticAnalysis() error {
+	sa := c.StaticAnalysis
+	if !sa.Enabled {
+		return nil
+	}
+	for _, name := range sa.Linters {
+		if name != LinterGolangCI && name != LinterESLint && name != LinterRuff {
+			return fmt.Errorf("static_analysis.linters: unknown linter %q, must be '%s', '%s' or '%s'", name, LinterGolangCI, LinterESLint, LinterRuff)
+		}
+	}
+	if sa.Timeout <= 0 {
+		return errors.New("static_analysis.timeout must be positive")
+	}
+	if sa.MaxFindings < 0 {
+		return errors.New("static_analysis.max_findings must not be negative")
+	}
+	return nil
+}
+
+func (c *Config) validateNotifications() error {
+	email := c.Notifications.Email
+	if !email.Enabled {
+		return nil
+	}
+	if email.SMTPHost == "" || email.From == "" {
+		return errors.New("notifications.email.smtp_host and from are required when email notifications are enabled")
+	}
+	if email.DigestHour < 0 || email.DigestHour > 23 {
+		return errors.New("notifications.email.digest_hour must be between 0 and 23")
+	}
+	return nil
+}
+
+func (c *Config) validateUsage() error {
+	seen := make(map[string]bool, len(c.Usage.Prices))
+	for i, p := range c.Usage.Prices {
+		if p.Model == "" {
+			return fmt.Errorf("usage.prices[%d].model is required", i)
+		}
+		if p.InputPerMillion < 0 || p.OutputPerMillion < 0 {
+			return fmt.Errorf("usage.prices of model %q must not be negative", p.Model)
+		}
+		if seen[p.Model] {
+			return fmt.Errorf("usage.prices has more than one price for model %q", p.Model)
+		}
+		seen[p.Model] = true
+	}
+	return nil
+}
+
+func (c *Config) validateLLMMiddleware() error {
+	m := c.LLMMiddleware
+	seen := make(map[string]bool, len(m.Chain))
+	for _, name := range m.Chain {
+		if name != MiddlewareLogging && name != MiddlewareCache && name != MiddlewareModeration {
+			return fmt.Errorf("llm_middleware.chain: unknown middleware %q, must be '%s', '%s' or '%s'", name, MiddlewareLogging, MiddlewareCache, MiddlewareModeration)
+		}
+		if seen[name] {
+			return fmt.Errorf("llm_middleware.chain lists %q more than once", name)
+		}
+		seen[name] = true
+	}
+	if seen[MiddlewareCache] && (m.Cache.TTL <= 0 || m.Cache.MaxEntries <= 0) {
+		return errors.New("llm_middleware.cache.ttl and max_entries must be positive")
+	}
+	for _, pattern := range m.Moderation.BlockedPatterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid llm_middleware.moderation.blocked_patterns entry %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validateSecurity() error {
+	if c.Security.EncryptionKey != "" && c.Security.EncryptionKeyFile != "" {
+		return errors.New("set only one of security.encryption_key and security.encryption_key_file")
+	}
+	primary, _, err := c.Security.EncryptionKeys()
+	if err != nil {
+		return err
+	}
+	if primary == nil && len(c.Security.PreviousEncryptionKeys) > 0 {
+		return errors.New("security.previous_encryption_keys requires security.encryption_key")
 	}
 	return nil
 }
@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"testing"
+	"time"
 )
 
 func TestValidateComparisonPath(t *testing.T) {
@@ -127,3 +129,365 @@ func TestValidateModels(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateLocalOnly(t *testing.T) {
+	local := AIConfig{
+		LLMProvider:      "ollama",
+		EmbedderProvider: "ollama",
+		OllamaHost:       "http://localhost:11434",
+		GeneratorModel:   "qwen2.5-coder",
+		EmbedderModel:    "nomic-embed-text",
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(c *AIConfig)
+		wantErr bool
+	}{
+		{name: "all local", mutate: func(*AIConfig) {}, wantErr: false},
+		{name: "docker service host", mutate: func(c *AIConfig) { c.OllamaHost = "http://ollama:11434" }, wantErr: false},
+		{name: "private network host", mutate: func(c *AIConfig) { c.OllamaHost = "http://10.0.0.5:11434" }, wantErr: false},
+		{name: "gemini generator", mutate: func(c *AIConfig) { c.LLMProvider = "gemini" }, wantErr: true},
+		{name: "gemini embedder", mutate: func(c *AIConfig) { c.EmbedderProvider = "gemini" }, wantErr: true},
+		{name: "public ollama host", mutate: func(c *AIConfig) { c.OllamaHost = "https://ollama.com" }, wantErr: true},
+		{name: "cloud generator model", mutate: func(c *AIConfig) { c.GeneratorModel = "gpt-oss:120b-cloud" }, wantErr: true},
+		{name: "cloud hyde model", mutate: func(c *AIConfig) { c.HyDEModel = "qwen3-coder:480b-cloud" }, wantErr: true},
+		{name: "cloud consensus model", mutate: func(c *AIConfig) { c.ComparisonModels = []string{"qwen3-coder:480b-cloud"} }, wantErr: true},
+		{name: "cloud reranker ignored when disabled", mutate: func(c *AIConfig) { c.RerankerModel = "x:cloud" }, wantErr: false},
+		{name: "cloud reranker when enabled", mutate: func(c *AIConfig) {
+			c.EnableReranking = true
+			c.RerankerModel = "x:cloud"
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := local
+			tt.mutate(&cfg)
+			err := cfg.ValidateLocalOnly()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateLocalOnly() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrExternalProvider) {
+				t.Errorf("expected ErrExternalProvider, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGitConfigUseSSH(t *testing.T) {
+	cfg := GitConfig{SSHRepos: []string{"acme/api", "Internal/*"}}
+
+	tests := []struct {
+		repo string
+		want bool
+	}{
+		{repo: "acme/api", want: true},
+		{repo: "ACME/API", want: true},
+		{repo: "acme/web", want: false},
+		{repo: "internal/tools", want: true},
+		{repo: "other/internal", want: false},
+	}
+	for _, tt := range tests {
+		if got := cfg.UseSSH(tt.repo); got != tt.want {
+			t.Errorf("UseSSH(%q) = %v, want %v", tt.repo, got, tt.want)
+		}
+	}
+
+	all := GitConfig{SSHRepos: []string{"*"}}
+	if !all.UseSSH("any/repo") {
+		t.Error(`UseSSH with "*" should match every repository`)
+	}
+	if (&GitConfig{}).UseSSH("acme/api") {
+		t.Error("UseSSH without ssh_repos should be false")
+	}
+}
+
+func TestOnboardingConfigAllows(t *testing.T) {
+	if !(&OnboardingConfig{}).Allows("acme/api") {
+		t.Error("Allows without repos should onboard every repository")
+	}
+	cfg := OnboardingConfig{Repos: []string{"acme/*"}}
+	if !cfg.Allows("Acme/API") {
+		t.Error(`Allows("Acme/API") should match "acme/*"`)
+	}
+	if cfg.Allows("other/api") {
+		t.Error(`Allows("other/api") should not match "acme/*"`)
+	}
+}
+
+func TestAutoReviewConfigTriggers(t *testing.T) {
+	cfg := AutoReviewConfig{Enabled: true, Events: []string{"opened", "synchronize"}, Repos: []string{"acme/*"}}
+	if !cfg.Triggers("acme/api", "synchronize") {
+		t.Error(`Triggers("acme/api", "synchronize") should queue a review`)
+	}
+	if cfg.Triggers("acme/api", "closed") {
+		t.Error(`Triggers("acme/api", "closed") should not queue a review`)
+	}
+	if cfg.Triggers("other/api", "opened") {
+		t.Error(`Triggers("other/api", "opened") should not match "acme/*"`)
+	}
+	cfg.Enabled = false
+	if cfg.Triggers("acme/api", "opened") {
+		t.Error("Triggers should not queue reviews when auto-review is disabled")
+	}
+}
+
+func TestValidateGit(t *testing.T) {
+	tests := []struct {
+		name    string
+		git     GitConfig
+		wantErr bool
+	}{
+		{name: "https only", git: GitConfig{}, wantErr: false},
+		{name: "ssh via agent", git: GitConfig{SSHRepos: []string{"acme/*"}, SSHUser: "git"}, wantErr: false},
+		{name: "missing user", git: GitConfig{SSHRepos: []string{"acme/*"}}, wantErr: true},
+		{name: "bad pattern", git: GitConfig{SSHRepos: []string{"acme/["}, SSHUser: "git"}, wantErr: true},
+		{name: "missing key file", git: GitConfig{SSHRepos: []string{"*"}, SSHUser: "git", SSHKeyPath: "/nonexistent/id_ed25519"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Git: tt.git}
+			if err := cfg.validateGit(); (err != nil) != tt.wantErr {
+				t.Errorf("validateGit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePromptExperiments(t *testing.T) {
+	tests := []struct {
+		name        string
+		experiments []PromptExperimentConfig
+		wantErr     bool
+	}{
+		{name: "none", experiments: nil, wantErr: false},
+		{name: "valid", experiments: []PromptExperimentConfig{{Prompt: "code_review", VariantFile: "prompts/code_review.v2.prompt", Percent: 20}}, wantErr: false},
+		{name: "missing file", experiments: []PromptExperimentConfig{{Prompt: "code_review", Percent: 20}}, wantErr: true},
+		{name: "percent above 100", experiments: []PromptExperimentConfig{{Prompt: "code_review", VariantFile: "v2.prompt", Percent: 120}}, wantErr: true},
+		{name: "duplicate prompt", experiments: []PromptExperimentConfig{
+			{Prompt: "code_review", VariantFile: "a.prompt", Percent: 10},
+			{Prompt: "code_review", VariantFile: "b.prompt", Percent: 10},
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai := &AIConfig{PromptExperiments: tt.experiments}
+			if err := ai.validatePromptExperiments(); (err != nil) != tt.wantErr {
+				t.Errorf("validatePromptExperiments() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSecurity(t *testing.T) {
+	const key = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=" // 32 bytes
+	tests := []struct {
+		name     string
+		security SecurityConfig
+		wantErr  bool
+	}{
+		{name: "none", security: SecurityConfig{}, wantErr: false},
+		{name: "valid", security: SecurityConfig{EncryptionKey: key, PreviousEncryptionKeys: []string{key}}, wantErr: false},
+		{name: "short key", security: SecurityConfig{EncryptionKey: "c2hvcnQ="}, wantErr: true},
+		{name: "key and key file", security: SecurityConfig{EncryptionKey: key, EncryptionKeyFile: "key"}, wantErr: true},
+		{name: "previous without primary", security: SecurityConfig{PreviousEncryptionKeys: []string{key}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Security: tt.security}
+			if err := c.validateSecurity(); (err != nil) != tt.wantErr {
+				t.Errorf("validateSecurity() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUsageConfigCost(t *testing.T) {
+	usage := UsageConfig{Prices: []ModelPrice{{Model: "gemini-2.5-pro", InputPerMillion: 1.25, OutputPerMillion: 10}}}
+
+	cost, ok := usage.Cost("gemini-2.5-pro", 2_000_000, 100_000)
+	if !ok || cost != 3.5 {
+		t.Errorf("Cost() = %v, %v; want 3.5, true", cost, ok)
+	}
+	if _, ok := usage.Cost("qwen3-coder", 1000, 1000); ok {
+		t.Error("Cost() of a model without a price should report false")
+	}
+
+	cfg := &Config{Usage: UsageConfig{Prices: []ModelPrice{{Model: "a", InputPerMillion: 1}, {Model: "a", InputPerMillion: 2}}}}
+	if err := cfg.validateUsage(); err == nil {
+		t.Error("validateUsage() should reject two prices for one model")
+	}
+}
+
+func TestValidateSandbox(t *testing.T) {
+	tests := []struct {
+		name    string
+		sandbox SandboxConfig
+		wantErr bool
+	}{
+		{name: "default", sandbox: SandboxConfig{}, wantErr: false},
+		{name: "process", sandbox: SandboxConfig{Backend: SandboxProcess, MemoryMB: 2048, MaxProcesses: 256}, wantErr: false},
+		{name: "container", sandbox: SandboxConfig{Backend: SandboxContainer, Engine: "podman", Image: "golang:1.26", Runtime: "runsc"}, wantErr: false},
+		{name: "container without image", sandbox: SandboxConfig{Backend: SandboxContainer}, wantErr: true},
+		{name: "unknown engine", sandbox: SandboxConfig{Backend: SandboxContainer, Engine: "lxc", Image: "golang:1.26"}, wantErr: true},
+		{name: "unknown backend", sandbox: SandboxConfig{Backend: "vm"}, wantErr: true},
+		{name: "negative limit", sandbox: SandboxConfig{Backend: SandboxProcess, CPUSeconds: -1}, wantErr: true},
+		{name: "seccomp profile", sandbox: SandboxConfig{Backend: SandboxContainer, Image: "golang:1.26", SeccompProfile: "/etc/code-warden/seccomp.json"}, wantErr: false},
+		{name: "seccomp profile without container", sandbox: SandboxConfig{Backend: SandboxProcess, SeccompProfile: "/etc/code-warden/seccomp.json"}, wantErr: true},
+		{name: "isolated parsing", sandbox: SandboxConfig{Backend: SandboxProcess, User: "warden", IsolateParsing: true, ParseWorkers: 4, ParseTimeout: 30 * time.Second}, wantErr: false},
+		{name: "isolated parsing without process backend", sandbox: SandboxConfig{IsolateParsing: true, ParseWorkers: 4, ParseTimeout: 30 * time.Second}, wantErr: true},
+		{name: "isolated parsing without workers", sandbox: SandboxConfig{Backend: SandboxProcess, IsolateParsing: true, ParseTimeout: 30 * time.Second}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Sandbox: tt.sandbox}
+			if err := cfg.validateSandbox(); (err != nil) != tt.wantErr {
+				t.Errorf("validateSandbox() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLimits(t *testing.T) {
+	cfg := &Config{Limits: LimitsConfig{MaxFileSizeKB: 1024, MaxFiles: 20000, MaxDiffKB: 1024}}
+	if err := cfg.validateLimits(); err != nil {
+		t.Errorf("validateLimits() error = %v", err)
+	}
+	cfg.Limits.MaxFiles = -1
+	if err := cfg.validateLimits(); err == nil {
+		t.Error("validateLimits() accepted a negative limit")
+	}
+}
+
+func TestValidateWarmup(t *testing.T) {
+	tests := []struct {
+		name    string
+		w       WarmupConfig
+		wantErr bool
+	}{
+		{name: "disabled", w: WarmupConfig{}, wantErr: false},
+		{name: "enabled", w: WarmupConfig{Enabled: true, Timeout: time.Minute, KeepAliveInterval: 5 * time.Minute}, wantErr: false},
+		{name: "enabled without timeout", w: WarmupConfig{Enabled: true}, wantErr: true},
+		{name: "negative interval", w: WarmupConfig{KeepAliveInterval: -time.Minute}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ai := &AIConfig{Warmup: tt.w}
+			if err := ai.validateWarmup(); (err != nil) != tt.wantErr {
+				t.Errorf("validateWarmup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSummaries(t *testing.T) {
+	tests := []struct {
+		name    string
+		ai      AIConfig
+		wantErr bool
+	}{
+		{name: "defaults", ai: AIConfig{SummaryWorkers: 5, ComparisonConcurrency: 10}, wantErr: false},
+		{name: "no summary workers", ai: AIConfig{ComparisonConcurrency: 10}, wantErr: true},
+		{name: "no comparison concurrency", ai: AIConfig{SummaryWorkers: 5}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.ai.validateSummaries(); (err != nil) != tt.wantErr {
+				t.Errorf("validateSummaries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateServerStaleCheckRuns(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  ServerConfig
+		wantErr bool
+	}{
+		{name: "reaper disabled", server: ServerConfig{}, wantErr: false},
+		{name: "failure", server: ServerConfig{StaleCheckRunAfter: time.Hour, CheckRunReapInterval: time.Minute, StaleCheckRunConclusion: "failure", StaleReviewRetries: 1}, wantErr: false},
+		{name: "unknown conclusion", server: ServerConfig{StaleCheckRunAfter: time.Hour, CheckRunReapInterval: time.Minute, StaleCheckRunConclusion: "stale"}, wantErr: true},
+		{name: "negative retries", server: ServerConfig{StaleReviewRetries: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.server.MaxWorkers = 1
+			cfg := &Config{Server: tt.server}
+			if err := cfg.validateServer(); (err != nil) != tt.wantErr {
+				t.Errorf("validateServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLLMMiddleware(t *testing.T) {
+	cache := LLMCacheConfig{TTL: time.Hour, MaxEntries: 1000}
+	tests := []struct {
+		name    string
+		m       LLMMiddlewareConfig
+		wantErr bool
+	}{
+		{name: "none", m: LLMMiddlewareConfig{}, wantErr: false},
+		{name: "all", m: LLMMiddlewareConfig{Chain: []string{MiddlewareLogging, MiddlewareModeration, MiddlewareCache}, Cache: cache}, wantErr: false},
+		{name: "unknown", m: LLMMiddlewareConfig{Chain: []string{"retry"}}, wantErr: true},
+		{name: "duplicate", m: LLMMiddlewareConfig{Chain: []string{MiddlewareLogging, MiddlewareLogging}}, wantErr: true},
+		{name: "cache without ttl", m: LLMMiddlewareConfig{Chain: []string{MiddlewareCache}, Cache: LLMCacheConfig{MaxEntries: 10}}, wantErr: true},
+		{name: "invalid pattern", m: LLMMiddlewareConfig{Moderation: LLMModerationConfig{BlockedPatterns: []string{"(unclosed"}}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{LLMMiddleware: tt.m}
+			if err := cfg.validateLLMMiddleware(); (err != nil) != tt.wantErr {
+				t.Errorf("validateLLMMiddleware() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStaticAnalysis(t *testing.T) {
+	tests := []struct {
+		name    string
+		sa      StaticAnalysisConfig
+		wantErr bool
+	}{
+		{name: "disabled", sa: StaticAnalysisConfig{Linters: []string{"pylint"}}, wantErr: false},
+		{name: "defaults", sa: StaticAnalysisConfig{Enabled: true, Linters: []string{LinterGolangCI, LinterESLint, LinterRuff}, Timeout: 5 * time.Minute, MaxFindings: 20}, wantErr: false},
+		{name: "unknown linter", sa: StaticAnalysisConfig{Enabled: true, Linters: []string{"pylint"}, Timeout: time.Minute}, wantErr: true},
+		{name: "no timeout", sa: StaticAnalysisConfig{Enabled: true, Linters: []string{LinterRuff}}, wantErr: true},
+		{name: "negative max findings", sa: StaticAnalysisConfig{Enabled: true, Timeout: time.Minute, MaxFindings: -1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{StaticAnalysis: tt.sa}
+			if err := cfg.validateStaticAnalysis(); (err != nil) != tt.wantErr {
+				t.Errorf("validateStaticAnalysis() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateStorageVectorStoreProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		storage StorageConfig
+		wantErr bool
+	}{
+		{name: "qdrant", storage: StorageConfig{VectorStoreProvider: VectorStoreQdrant, QdrantHost: "localhost:6334"}, wantErr: false},
+		{name: "qdrant without host", storage: StorageConfig{VectorStoreProvider: VectorStoreQdrant}, wantErr: true},
+		{name: "pgvector without qdrant host", storage: StorageConfig{VectorStoreProvider: VectorStorePgvector}, wantErr: false},
+		{name: "memory", storage: StorageConfig{VectorStoreProvider: VectorStoreMemory}, wantErr: false},
+		{name: "weaviate", storage: StorageConfig{VectorStoreProvider: VectorStoreWeaviate, WeaviateURL: "http://localhost:8080"}, wantErr: false},
+		{name: "weaviate without url", storage: StorageConfig{VectorStoreProvider: VectorStoreWeaviate}, wantErr: true},
+		{name: "unknown provider", storage: StorageConfig{VectorStoreProvider: "milvus"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Storage: tt.storage}
+			if err := cfg.validateStorage(); (err != nil) != tt.wantErr {
+				t.Errorf("validateStorage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
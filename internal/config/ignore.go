@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/pathutil"
+)
+
+const (
+	// gitignoreFile lists files git leaves untracked, relative to the
+	// directory it is in.
+	gitignoreFile = ".gitignore"
+	// gitInfoExclude lists files git leaves untracked in this clone only.
+	gitInfoExclude = ".git/info/exclude"
+	// wardenignoreFile lists files left out of the index only, in the same
+	// syntax as .gitignore.
+	wardenignoreFile = ".wardenignore"
+)
+
+// LoadIgnorePatterns returns the gitignore-style patterns of the repository
+// at repoPath, relative to its root, in the order git gives them precedence:
+// .git/info/exclude, the root .gitignore and the .gitignore files of
+// subdirectories, shallower ones first, and last the root .wardenignore, so
+// that a "!pattern" there re-includes files git ignores. Without
+// withGitignore only .wardenignore is read. The global excludes file of the
+// user (core.excludesFile) is never read, so that every clone of a
+// repository is indexed alike. Missing files contribute no patterns; blank
+// lines and comments are kept for core.ParsePathPatterns to skip.
+func LoadIgnorePatterns(repoPath string, withGitignore bool) ([]string, error) {
+	var patterns []string
+	if withGitignore {
+		files, err := findGitignores(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range slices.Concat([]string{gitInfoExclude}, files) {
+			dir := ""
+			if name != gitInfoExclude {
+				dir = path.Dir(name)
+			}
+			lines, err := readIgnoreFile(repoPath, name)
+			if err != nil {
+				return nil, err
+			}
+			for _, line := range lines {
+				patterns = append(patterns, rebasePattern(dir, line))
+			}
+		}
+	}
+	lines, err := readIgnoreFile(repoPath, wardenignoreFile)
+	if err != nil {
+		return nil, err
+	}
+	return append(patterns, lines...), nil
+}
+
+// findGitignores returns the .gitignore files below repoPath relative to it,
+// shallower ones first. Directories excluded by default, such as .git and
+// node_modules, are not searched.
+func findGitignores(repoPath string) ([]string, error) {
+	excluded := core.DefaultExcludedDirsSet()
+	var files []string
+	err := filepath.WalkDir(repoPath, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != repoPath && excluded[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != gitignoreFile {
+			return nil
+		}
+		rel, err := pathutil.Rel(repoPath, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find %s files: %w", gitignoreFile, err)
+	}
+	slices.SortStableFunc(files, func(a, b string) int {
+		return strings.Count(a, "/") - strings.Count(b, "/")
+	})
+	return files, nil
+}
+
+// readIgnoreFile returns the lines of the ignore file name of repoPath, or
+// none when it does not exist.
+func readIgnoreFile(repoPath, name string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(repoPath, filepath.FromSlash(name)))
+	// In a worktree .git is a file, so .git/info/exclude is not a directory.
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, syscall.ENOTDIR) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	var lines []string
+	for line := range strings.SplitSeq(string(data), "\n") {
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+	}
+	return lines, nil
+}
+
+// rebasePattern rewrites a pattern of the .gitignore in dir, relative to the
+// repository root, to match the same paths from the root: anchored patterns
+// are anchored at dir, the others match at any depth below it.
+func rebasePattern(dir, line string) string {
+	trimmed := strings.TrimSpace(line)
+	if dir == "" || dir == "." || trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return line
+	}
+	negate := ""
+	if rest, ok := strings.CutPrefix(trimmed, "!"); ok {
+		negate, trimmed = "!", rest
+	}
+	if strings.Contains(strings.TrimSuffix(trimmed, "/"), "/") {
+		if !strings.Contains(trimmed, "**") {
+			// Only patterns without ** match dir as a glob; core.MatchGlob
+			// compares the part before ** literally.
+			dir = globEscaper.Replace(dir)
+		}
+		return negate + "/" + dir + "/" + strings.TrimPrefix(trimmed, "/")
+	}
+	return negate + "/" + dir + "/**/" + trimmed
+}
+
+// globEscaper escapes the glob metacharacters of a directory name, such as
+// the brackets of a Next.js route like app/[id], as character classes;
+// backslashes are path separators to core.ParsePathPatterns.
+var globEscaper = strings.NewReplacer("*", "[*]", "?", "[?]", "[", "[[]")
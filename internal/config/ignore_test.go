@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+func TestLoadIgnorePatterns(t *testing.T) {
+	repoPath := t.TempDir()
+
+	patterns, err := LoadIgnorePatterns(repoPath, true)
+	require.NoError(t, err)
+	assert.Empty(t, patterns, "missing ignore files contribute nothing")
+
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, ".gitignore"), []byte("# build output\r\ndist/\n*.log\n"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, ".wardenignore"), []byte("fixtures/\n!dist/types.d.ts\n"), 0o600))
+
+	patterns, err = LoadIgnorePatterns(repoPath, true)
+	require.NoError(t, err)
+	filter := (&core.RepoConfig{}).PathFilter(patterns)
+	assert.False(t, filter.Allows("dist/app.js"))
+	assert.False(t, filter.Allows("logs/server.log"))
+	assert.False(t, filter.Allows("test/fixtures/a.json"))
+	assert.True(t, filter.Allows("dist/types.d.ts"), ".wardenignore applies after .gitignore")
+	assert.True(t, filter.Allows("src/main.go"))
+
+	patterns, err = LoadIgnorePatterns(repoPath, false)
+	require.NoError(t, err)
+	filter = (&core.RepoConfig{}).PathFilter(patterns)
+	assert.True(t, filter.Allows("dist/app.js"), "without .gitignore")
+	assert.False(t, filter.Allows("test/fixtures/a.json"))
+
+	filter = (&core.RepoConfig{Exclude: []string{"!*.log"}}).PathFilter([]string{"*.log"})
+	assert.True(t, filter.Allows("logs/server.log"), "exclude patterns apply after ignore files")
+}
+
+func TestLoadIgnorePatterns_Nested(t *testing.T) {
+	repoPath := t.TempDir()
+	files := map[string]string{
+		".git/info/exclude":         "scratch/\n",
+		".gitignore":                "*.log\n",
+		"web/.gitignore":            "dist/\n/local.json\nsrc/gen/\n!keep.log\n",
+		"web/app/[id]/.gitignore":   "/cache.json\nsub/out.json\n",
+		"node_modules/x/.gitignore": "*.go\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repoPath, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o750))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	patterns, err := LoadIgnorePatterns(repoPath, true)
+	require.NoError(t, err)
+	filter := (&core.RepoConfig{}).PathFilter(patterns)
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "scratch/notes.md", want: false},
+		{path: "server.log", want: false},
+		{path: "web/dist/app.js", want: false},
+		{path: "web/pkg/dist/app.js", want: false},
+		{path: "dist/app.js", want: true},
+		{path: "web/local.json", want: false},
+		{path: "web/pkg/local.json", want: true},
+		{path: "web/src/gen/a.ts", want: false},
+		{path: "web/pkg/src/gen/a.ts", want: true},
+		{path: "src/gen/a.ts", want: true},
+		{path: "web/keep.log", want: true},
+		{path: "keep.log", want: false},
+		{path: "web/app/[id]/cache.json", want: false},
+		{path: "web/app/i/cache.json", want: true},
+		{path: "web/app/[id]/sub/out.json", want: false},
+		{path: "main.go", want: true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, filter.Allows(tt.path), tt.path)
+	}
+
+	patterns, err = LoadIgnorePatterns(repoPath, false)
+	require.NoError(t, err)
+	assert.Empty(t, patterns, "without .gitignore only .wardenignore is read")
+}
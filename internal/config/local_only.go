@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrExternalProvider is returned when local-only mode is requested but a
+// configured AI provider would send code to an external API.
+var ErrExternalProvider = errors.New("local-only mode forbids external AI providers")
+
+// ExternalProviders lists every configured AI endpoint that would send code
+// off the host: Gemini is always external, Ollama is external when its host
+// is a public address or a model carries an Ollama cloud tag.
+func (c *AIConfig) ExternalProviders() []string {
+	var external []string
+
+	if c.LLMProvider == llmProviderGemini {
+		external = append(external, "generator (gemini)")
+	}
+	if c.EmbedderProvider == llmProviderGemini {
+		external = append(external, "embedder (gemini)")
+	}
+
+	usesOllama := c.LLMProvider != llmProviderGemini || c.EmbedderProvider != llmProviderGemini
+	if usesOllama && c.OllamaHost != "" && !isLocalHost(c.OllamaHost) {
+		external = append(external, fmt.Sprintf("ollama host %s", c.OllamaHost))
+	}
+
+	models := map[string]string{
+		"generator": c.GeneratorModel,
+		"fast":      c.FastModel,
+		"embedder":  c.EmbedderModel,
+		"hyde":      c.HyDEModel,
+	}
+	if c.EnableReranking {
+		models["reranker"] = c.RerankerModel
+	}
+	for role, model := range models {
+		if isCloudModel(model) {
+			external = append(external, fmt.Sprintf("%s model %s", role, model))
+		}
+	}
+	for _, model := range c.ComparisonModels {
+		if isCloudModel(model) {
+			external = append(external, fmt.Sprintf("consensus model %s", model))
+		}
+	}
+
+	return external
+}
+
+// ValidateLocalOnly returns ErrExternalProvider listing the offending
+// providers if any configured provider is not local.
+func (c *AIConfig) ValidateLocalOnly() error {
+	external := c.ExternalProviders()
+	if len(external) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrExternalProvider, strings.Join(external, ", "))
+}
+
+// isCloudModel reports whether an Ollama model tag is served by ollama.com
+// rather than the local daemon (e.g. "gpt-oss:120b-cloud").
+func isCloudModel(model string) bool {
+	return strings.HasSuffix(model, "-cloud") || strings.HasSuffix(model, ":cloud")
+}
+
+// isLocalHost reports whether an endpoint URL points at this host or a
+// private network. Single-label hostnames (e.g. a docker-compose service
+// named "ollama") are treated as local.
+func isLocalHost(endpoint string) bool {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return false
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") ||
+		strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast()
+	}
+	return !strings.Contains(host, ".")
+}
@@ -6,6 +6,8 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 
@@ -19,26 +21,79 @@ var (
 
 // LoadRepoConfig loads and parses the .code-warden.yml file from a repository path.
 func LoadRepoConfig(repoPath string) (*core.RepoConfig, error) {
+	return LoadRepoConfigOver(repoPath, nil)
+}
+
+// LoadRepoConfigOver loads the .code-warden.yml file from a repository path on
+// top of base, usually the organization defaults; see [MergeRepoConfig]. A nil
+// base stands for core.DefaultRepoConfig. If the file does not exist, a copy
+// of base is returned together with ErrConfigNotFound.
+func LoadRepoConfigOver(repoPath string, base *core.RepoConfig) (*core.RepoConfig, error) {
 	configPath := filepath.Join(repoPath, ".code-warden.yml")
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return core.DefaultRepoConfig(), ErrConfigNotFound
+			config, _ := MergeRepoConfig(base, nil)
+			return config, ErrConfigNotFound
 		}
 		return nil, fmt.Errorf("failed to read .code-warden.yml: %w", err)
 	}
+	return MergeRepoConfig(base, data)
+}
+
+// MergeRepoConfig parses .code-warden.yml content on top of base. Settings
+// present in data override base, while custom_instructions, the exclude_*
+// lists, include, exclude, indexing.generated_patterns, rules and
+// sub_projects are combined with base so organization-wide rules keep
+// applying; exclude patterns and rules from data come last and so take
+// precedence. A nil base stands for core.DefaultRepoConfig. base is not
+// modified.
+func MergeRepoConfig(base *core.RepoConfig, data []byte) (*core.RepoConfig, error) {
+	if base == nil {
+		base = core.DefaultRepoConfig()
+	}
 
-	config := core.DefaultRepoConfig()
-	if err := yaml.Unmarshal(data, config); err != nil {
+	merged := *base
+	merged.VerifyCommands = slices.Clone(base.VerifyCommands)
+	merged.ConsensusModels = slices.Clone(base.ConsensusModels)
+	merged.CommitHygiene.Types = slices.Clone(base.CommitHygiene.Types)
+	if err := yaml.Unmarshal(data, &merged); err != nil {
 		return nil, fmt.Errorf("%w: %w", ErrConfigParsing, err)
 	}
-	return config, nil
+
+	own := core.DefaultRepoConfig()
+	if err := yaml.Unmarshal(data, own); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrConfigParsing, err)
+	}
+	merged.CustomInstructions = appendUnique(base.CustomInstructions, own.CustomInstructions)
+	merged.ExcludeDirs = appendUnique(base.ExcludeDirs, own.ExcludeDirs)
+	merged.ExcludeExts = appendUnique(base.ExcludeExts, own.ExcludeExts)
+	merged.ExcludeFiles = appendUnique(base.ExcludeFiles, own.ExcludeFiles)
+	merged.Include = appendUnique(base.Include, own.Include)
+	merged.Exclude = slices.Concat(base.Exclude, own.Exclude)
+	merged.Indexing.GeneratedPatterns = appendUnique(base.Indexing.GeneratedPatterns, own.Indexing.GeneratedPatterns)
+	merged.Rules = slices.Concat(base.Rules, own.Rules)
+	merged.SubProjects = slices.Concat(base.SubProjects, own.SubProjects)
+	return &merged, nil
 }
 
-// LoadRepoConfigWithDefaults loads the repo config and returns defaults on error.
-// It logs appropriate messages based on whether the config was not found or failed to parse.
-func LoadRepoConfigWithDefaults(repoPath, repoFullName string, logger *slog.Logger) *core.RepoConfig {
-	repoConfig, err := LoadRepoConfig(repoPath)
+// appendUnique returns a new slice with the elements of a followed by those
+// of b that are not already present.
+func appendUnique(a, b []string) []string {
+	out := make([]string, 0, len(a)+len(b))
+	for _, s := range slices.Concat(a, b) {
+		if !slices.Contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// LoadRepoConfigWithDefaults loads the repo config on top of orgDefaults
+// (nil for none) and returns the defaults on error. It logs appropriate
+// messages based on whether the config was not found or failed to parse.
+func LoadRepoConfigWithDefaults(repoPath, repoFullName string, orgDefaults *core.RepoConfig, logger *slog.Logger) *core.RepoConfig {
+	repoConfig, err := LoadRepoConfigOver(repoPath, orgDefaults)
 	if err == nil {
 		return repoConfig
 	}
@@ -47,10 +102,108 @@ func LoadRepoConfigWithDefaults(repoPath, repoFullName string, logger *slog.Logg
 		if logger != nil {
 			logger.Info("no .code-warden.yml found, using defaults", "repo", repoFullName)
 		}
-	} else {
-		if logger != nil {
-			logger.Warn("failed to parse .code-warden.yml, using defaults", "error", err, "repo", repoFullName)
+		return repoConfig
+	}
+	if logger != nil {
+		logger.Warn("failed to parse .code-warden.yml, using defaults", "error", err, "repo", repoFullName)
+	}
+	defaults, _ := MergeRepoConfig(orgDefaults, nil)
+	return defaults
+}
+
+// ConsensusModelsFor returns the models to use for a consensus review of a
+// repository: none when the repository disables consensus, its own
+// consensus_models when set, and ai.comparison_models otherwise. Repository
+// overrides are de-duplicated and capped at max_comparison_models (or the
+// hard limit of 10).
+func (c *AIConfig) ConsensusModelsFor(repoConfig *core.RepoConfig) []string {
+	if repoConfig != nil && repoConfig.DisableConsensus {
+		return nil
+	}
+	if repoConfig == nil || len(repoConfig.ConsensusModels) == 0 {
+		return c.ComparisonModels
+	}
+
+	limit := c.MaxComparisonModels
+	if limit < 1 || limit > maxComparisonModels {
+		limit = maxComparisonModels
+	}
+	seen := make(map[string]bool, len(repoConfig.ConsensusModels))
+	models := make([]string, 0, len(repoConfig.ConsensusModels))
+	for _, m := range repoConfig.ConsensusModels {
+		m = strings.TrimSpace(m)
+		if m == "" || seen[m] {
+			continue
 		}
+		seen[m] = true
+		models = append(models, m)
+		if len(models) == limit {
+			break
+		}
+	}
+	return models
+}
+
+// Bounds of the retrieval settings. They keep a .code-warden.yml override
+// from turning a review into a vector store scan or overflowing the model's
+// context window.
+const (
+	minDocsPerQuery   = 1
+	maxDocsPerQuery   = 50
+	minRerankTopK     = 1
+	maxRerankTopK     = 20
+	minContextTokens  = 2000
+	minHyDEMaxFiles   = 1
+	maxHyDEMaxFiles   = 50
+	maxRecallSize     = 100
+	maxScoreThreshold = 1
+
+	defaultHyDEMaxFiles = 10
+)
+
+// defaultRetrieval applies when ai.retrieval leaves a setting unset.
+var defaultRetrieval = core.RetrievalModes{
+	Quick:    core.RetrievalSettings{DocsPerQuery: 6, RerankTopK: 3},
+	Standard: core.RetrievalSettings{DocsPerQuery: 10, RerankTopK: 5},
+	Thorough: core.RetrievalSettings{DocsPerQuery: 15, RerankTopK: 8},
+}
+
+// RetrievalFor returns the retrieval settings for a review with the given
+// profile: the server's ai.retrieval settings for the profile, overridden by
+// the repository's retrieval settings, clamped to safe bounds. The context
+// token budget defaults to and never exceeds ai.context_token_budget, HyDE
+// defaults to ai.enable_hyde and ai.hyde_max_files.
+func (c *AIConfig) RetrievalFor(profile core.ReviewProfile, repoConfig *core.RepoConfig) core.RetrievalSettings {
+	settings := defaultRetrieval.For(profile).Override(c.Retrieval.For(profile))
+	if repoConfig != nil {
+		settings = settings.Override(repoConfig.Retrieval.For(profile))
+	}
+
+	maxContextTokens := c.ContextTokenBudget
+	if maxContextTokens <= 0 {
+		maxContextTokens = 16000 // Same fallback as the context packer
+	}
+	if settings.ContextTokens <= 0 {
+		settings.ContextTokens = maxContextTokens
+	}
+	settings.DocsPerQuery = min(max(settings.DocsPerQuery, minDocsPerQuery), maxDocsPerQuery)
+	settings.RerankTopK = min(max(settings.RerankTopK, minRerankTopK), maxRerankTopK)
+	settings.ContextTokens = min(max(settings.ContextTokens, minContextTokens), max(maxContextTokens, minContextTokens))
+
+	hyde := settings.HyDEEnabled(c.EnableHyDE)
+	settings.HyDE = &hyde
+	if settings.HyDEMaxFiles <= 0 {
+		settings.HyDEMaxFiles = c.HyDEMaxFiles
+	}
+	if settings.HyDEMaxFiles <= 0 {
+		settings.HyDEMaxFiles = defaultHyDEMaxFiles
+	}
+	settings.HyDEMaxFiles = min(max(settings.HyDEMaxFiles, minHyDEMaxFiles), maxHyDEMaxFiles)
+	// Unset recall size and score threshold keep their defaults, twice
+	// docs_per_query and ai.retrieval_score_threshold.
+	if settings.RecallSize > 0 {
+		settings.RecallSize = min(max(settings.RecallSize, settings.DocsPerQuery), maxRecallSize)
 	}
-	return core.DefaultRepoConfig()
+	settings.ScoreThreshold = min(max(settings.ScoreThreshold, 0), maxScoreThreshold)
+	return settings
 }
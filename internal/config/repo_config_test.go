@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/core"
 )
 
 func TestLoadRepoConfig(t *testing.T) {
@@ -25,6 +27,9 @@ exclude_exts:
 exclude_files:
   - "README.md"
   - "internal/core/repo_config.go"
+consensus_models:
+  - "qwen3-coder:30b"
+disable_consensus: true
 `
 		err := os.WriteFile(filepath.Join(repoPath, ".code-warden.yml"), []byte(configContent), 0644)
 		require.NoError(t, err)
@@ -35,6 +40,8 @@ exclude_files:
 		assert.Equal(t, []string{"dist", "build"}, cfg.ExcludeDirs)
 		assert.Equal(t, []string{".txt", "log"}, cfg.ExcludeExts)
 		assert.Equal(t, []string{"README.md", "internal/core/repo_config.go"}, cfg.ExcludeFiles)
+		assert.Equal(t, []string{"qwen3-coder:30b"}, cfg.ConsensusModels)
+		assert.True(t, cfg.DisableConsensus)
 	})
 
 	t.Run("missing config file returns defaults", func(t *testing.T) {
@@ -59,3 +66,177 @@ exclude_files:
 		assert.Nil(t, cfg)
 	})
 }
+
+func TestConsensusModelsFor(t *testing.T) {
+	ai := AIConfig{ComparisonModels: []string{"kimi", "deepseek"}, MaxComparisonModels: 3}
+
+	tests := []struct {
+		name       string
+		repoConfig *core.RepoConfig
+		want       []string
+	}{
+		{name: "nil repo config uses server models", repoConfig: nil, want: []string{"kimi", "deepseek"}},
+		{name: "no override uses server models", repoConfig: &core.RepoConfig{}, want: []string{"kimi", "deepseek"}},
+		{name: "disabled", repoConfig: &core.RepoConfig{DisableConsensus: true, ConsensusModels: []string{"qwen"}}, want: nil},
+		{
+			name:       "override is de-duplicated and capped",
+			repoConfig: &core.RepoConfig{ConsensusModels: []string{"qwen", " qwen ", "", "llama", "gemma", "phi"}},
+			want:       []string{"qwen", "llama", "gemma"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ai.ConsensusModelsFor(tt.repoConfig))
+		})
+	}
+}
+
+func TestRetrievalFor(t *testing.T) {
+	ai := AIConfig{
+		ContextTokenBudget: 50000,
+		EnableHyDE:         true,
+		HyDEMaxFiles:       8,
+		Retrieval: core.RetrievalModes{
+			Standard: core.RetrievalSettings{DocsPerQuery: 12},
+			Thorough: core.RetrievalSettings{ContextTokens: 80000},
+		},
+	}
+	on, off := true, false
+
+	tests := []struct {
+		name       string
+		profile    core.ReviewProfile
+		repoConfig *core.RepoConfig
+		want       core.RetrievalSettings
+	}{
+		{
+			name:    "built-in defaults fill unset server settings",
+			profile: core.ProfileQuick,
+			want:    core.RetrievalSettings{DocsPerQuery: 6, RerankTopK: 3, ContextTokens: 50000, HyDE: &on, HyDEMaxFiles: 8},
+		},
+		{
+			name:    "server settings",
+			profile: core.ProfileStandard,
+			want:    core.RetrievalSettings{DocsPerQuery: 12, RerankTopK: 5, ContextTokens: 50000, HyDE: &on, HyDEMaxFiles: 8},
+		},
+		{
+			name:    "context tokens never exceed the budget",
+			profile: core.ProfileThorough,
+			want:    core.RetrievalSettings{DocsPerQuery: 15, RerankTopK: 8, ContextTokens: 50000, HyDE: &on, HyDEMaxFiles: 8},
+		},
+		{
+			name:    "repository overrides the profile",
+			profile: core.ProfileStandard,
+			repoConfig: &core.RepoConfig{Retrieval: core.RetrievalModes{
+				Standard: core.RetrievalSettings{RerankTopK: 7, ContextTokens: 30000, HyDE: &off},
+				Thorough: core.RetrievalSettings{DocsPerQuery: 40},
+			}},
+			want: core.RetrievalSettings{DocsPerQuery: 12, RerankTopK: 7, ContextTokens: 30000, HyDE: &off, HyDEMaxFiles: 8},
+		},
+		{
+			name:    "repository overrides are clamped",
+			profile: core.ProfileQuick,
+			repoConfig: &core.RepoConfig{Retrieval: core.RetrievalModes{
+				Quick: core.RetrievalSettings{DocsPerQuery: 1000, RerankTopK: -1, ContextTokens: 10, HyDEMaxFiles: 500},
+			}},
+			want: core.RetrievalSettings{DocsPerQuery: 50, RerankTopK: 1, ContextTokens: 2000, HyDE: &on, HyDEMaxFiles: 50},
+		},
+		{
+			name:    "recall size, score threshold and chunk types",
+			profile: core.ProfileStandard,
+			repoConfig: &core.RepoConfig{Retrieval: core.RetrievalModes{
+				Standard: core.RetrievalSettings{RecallSize: 5, ScoreThreshold: 1.5, ExcludeChunkTypes: []string{"arch"}},
+			}},
+			want: core.RetrievalSettings{
+				DocsPerQuery: 12, RerankTopK: 5, ContextTokens: 50000, HyDE: &on, HyDEMaxFiles: 8,
+				RecallSize: 12, ScoreThreshold: 1, ExcludeChunkTypes: []string{"arch"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ai.RetrievalFor(tt.profile, tt.repoConfig))
+		})
+	}
+}
+
+func TestMergeRepoConfig(t *testing.T) {
+	org := &core.RepoConfig{
+		CustomInstructions: []string{"Follow the org style guide"},
+		ExcludeDirs:        []string{"vendor", "third_party"},
+		Exclude:            []string{"testdata/"},
+		ConsensusModels:    []string{"qwen3-coder:30b"},
+		LocalOnly:          true,
+		MinSeverity:        "Medium",
+		Rules:              []core.PathRule{{Paths: []string{"**/*.sql"}, MinSeverity: "High"}},
+		CommitHygiene:      core.CommitHygieneConfig{Enabled: true, Conventional: true, Types: []string{"feat", "fix"}},
+		Retrieval:          core.RetrievalModes{Thorough: core.RetrievalSettings{DocsPerQuery: 20}},
+		Indexing:           core.IndexingConfig{GeneratedPatterns: []string{"*_mock.go"}, MaxFileSizeKB: 256},
+		SubProjects:        []core.SubProject{{Name: "shared", Path: "libs"}},
+	}
+
+	repo := []byte(`
+custom_instructions:
+  - "Prefer table tests"
+rules:
+  - paths: ["internal/auth/**"]
+    instructions: ["Focus on authentication bypasses"]
+    min_severity: Low
+exclude_dirs:
+  - "dist"
+  - "vendor"
+exclude:
+  - "!testdata/golden/"
+local_only: false
+commit_hygiene:
+  max_subject_length: 50
+retrieval:
+  thorough:
+    rerank_top_k: 10
+indexing:
+  generated_patterns: ["api/client/**"]
+sub_projects:
+  - name: billing
+    path: services/billing
+    language: Go
+    context_paths: [libs/money]
+`)
+	merged, err := MergeRepoConfig(org, repo)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Follow the org style guide", "Prefer table tests"}, merged.CustomInstructions)
+	assert.Equal(t, []string{"vendor", "third_party", "dist"}, merged.ExcludeDirs)
+	assert.Equal(t, []string{"testdata/", "!testdata/golden/"}, merged.Exclude, "repo exclude patterns come last")
+	assert.True(t, merged.PathFilter(nil).Allows("testdata/golden/out.json"))
+	assert.Equal(t, []string{"qwen3-coder:30b"}, merged.ConsensusModels, "unset repo settings inherit org values")
+	assert.False(t, merged.LocalOnly, "an explicit repo value overrides the org")
+	assert.Equal(t, "Medium", merged.MinSeverity)
+	require.Len(t, merged.Rules, 2)
+	assert.Equal(t, "High", merged.MinSeverityFor("db/schema.sql"), "org rules keep applying")
+	assert.Equal(t, "Low", merged.MinSeverityFor("internal/auth/login.go"))
+	assert.Equal(t, core.CommitHygieneConfig{Enabled: true, Conventional: true, Types: []string{"feat", "fix"}, MaxSubjectLength: 50},
+		merged.CommitHygiene, "commit_hygiene settings merge field by field")
+	assert.Equal(t, core.RetrievalSettings{DocsPerQuery: 20, RerankTopK: 10}, merged.Retrieval.Thorough, "retrieval settings merge field by field")
+	assert.Equal(t, core.IndexingConfig{GeneratedPatterns: []string{"*_mock.go", "api/client/**"}, MaxFileSizeKB: 256}, merged.Indexing)
+	assert.Equal(t, []core.SubProject{
+		{Name: "shared", Path: "libs"},
+		{Name: "billing", Path: "services/billing", Language: "Go", ContextPaths: []string{"libs/money"}},
+	}, merged.SubProjects)
+
+	assert.Equal(t, []string{"vendor", "third_party"}, org.ExcludeDirs, "org defaults are not modified")
+	assert.True(t, org.LocalOnly)
+
+	t.Run("missing repo file inherits org defaults", func(t *testing.T) {
+		cfg, err := LoadRepoConfigOver(t.TempDir(), org)
+		assert.ErrorIs(t, err, ErrConfigNotFound)
+		require.NotNil(t, cfg)
+		assert.Equal(t, org.ExcludeDirs, cfg.ExcludeDirs)
+		assert.True(t, cfg.LocalOnly)
+	})
+
+	t.Run("invalid repo file falls back to org defaults", func(t *testing.T) {
+		repoPath := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, ".code-warden.yml"), []byte("invalid: yaml: content"), 0644))
+		cfg := LoadRepoConfigWithDefaults(repoPath, "acme/api", org, nil)
+		assert.Equal(t, "Medium", cfg.MinSeverity)
+	})
+}
@@ -0,0 +1,30 @@
+package core
+
+// Answer is the reply to a question about a repository, with the chunks it
+// was based on.
+type Answer struct {
+	Text      string     `json:"answer"`
+	Citations []Citation `json:"citations,omitempty"`
+}
+
+// Citation points at a chunk of the repository an answer was based on.
+// Chunks without lines, such as architecture summaries of a directory, have
+// no LineStart, and those not found by the similarity search no Score. Repo
+// is only set for answers across several repositories.
+type Citation struct {
+	Repo      string  `json:"repo,omitempty"`
+	Source    string  `json:"source"`
+	LineStart int     `json:"line_start,omitempty"`
+	LineEnd   int     `json:"line_end,omitempty"`
+	Score     float32 `json:"score,omitempty"`
+}
+
+// RepoCollection is a repository a question is asked about and the vector
+// collection it is indexed in.
+type RepoCollection struct {
+	Repo       string
+	Collection string
+	// EmbedderModel is the model the collection was built with; empty for
+	// the model the question is asked with.
+	EmbedderModel string
+}
@@ -5,6 +5,7 @@ package core
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/google/go-github/v73/github"
@@ -20,8 +21,65 @@ const (
 	ReReview
 	// ImplementIssue indicates an autonomous agent should implement the issue.
 	ImplementIssue
+	// RecordFeedback records a rating on a posted review suggestion.
+	RecordFeedback
+	// SecurityReview indicates a review restricted to security vulnerabilities.
+	SecurityReview
+	// ExplainPR indicates a walkthrough of the PR for someone new to the
+	// codebase should be posted instead of a review.
+	ExplainPR
+	// OnboardRepository registers a repository the GitHub App was just
+	// installed on.
+	OnboardRepository
+	// SuggestionReply answers a "/warden explain", "/warden fix" or
+	// "/warden apply" reply to a posted suggestion in its thread.
+	SuggestionReply
 )
 
+// String returns the name of the job kind, as recorded in job runs and
+// configured in server.scheduling.priorities.
+func (t ReviewType) String() string {
+	switch t {
+	case FullReview:
+		return "review"
+	case ReReview:
+		return "rereview"
+	case ImplementIssue:
+		return "implement"
+	case RecordFeedback:
+		return "feedback"
+	case SecurityReview:
+		return "security"
+	case ExplainPR:
+		return "explain"
+	case OnboardRepository:
+		return "scan"
+	case SuggestionReply:
+		return "reply"
+	default:
+		return fmt.Sprintf("ReviewType(%d)", int(t))
+	}
+}
+
+// Feedback signals a maintainer can give on a posted suggestion, either with a
+// 👍/👎 reaction or by replying "/warden helpful" or "/warden wrong".
+const (
+	FeedbackHelpful = "helpful"
+	FeedbackWrong   = "wrong"
+)
+
+// Replies a maintainer can ask for on a posted suggestion with
+// "/warden explain", "/warden fix" or "/warden apply".
+const (
+	ReplyExplain = "explain"
+	ReplyFix     = "fix"
+	ReplyApply   = "apply"
+)
+
+// writeAssociations are the author associations of users who may push to
+// a repository and thus ask for "/warden apply".
+var writeAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
 // GitHubEvent represents a simplified, internal view of a GitHub webhook event.
 // It is constructed from raw GitHub webhook payloads and serves as the primary
 // data carrier for triggering code review jobs.
@@ -38,7 +96,7 @@ type GitHubEvent struct {
 	PRBody   string // The body/description of the pull request
 	HeadSHA  string // The HEAD commit SHA of the PR
 
-	// Type specifies whether this is a FullReview or a ReReview request.
+	// Type specifies whether this is a FullReview, ReReview or SecurityReview request.
 	Type ReviewType
 
 	// UserInstructions captures optional text provided with the command
@@ -50,20 +108,44 @@ type GitHubEvent struct {
 	// Populated before review generation and included in the RAG context query.
 	CommitMessages []string
 
+	// StaticAnalysis holds the findings of the configured linters on the
+	// head of the PR. Populated before review generation.
+	StaticAnalysis []LintFinding
+
 	Commenter      string // The GitHub username that triggered the review
 	InstallationID int64  // The GitHub App installation ID
 
+	// ReviewID correlates the log records of the job; see WithReviewID.
+	// Set at webhook intake, or by the dispatcher for other jobs.
+	ReviewID string
+
+	// AutoReview is set for reviews queued by a pull_request event rather
+	// than a command.
+	AutoReview bool
+
+	// Attempt counts how often the job was queued again because its check
+	// run went stale, e.g. after a crash; see server.stale_review_retries.
+	Attempt int
+
 	// Fields for ImplementIssue type
 	IssueNumber int    // The issue number (for /implement commands)
 	IssueTitle  string // The title of the issue
 	IssueBody   string // The body/description of the issue
+
+	// Fields for RecordFeedback type
+	FeedbackCommentID int64  // The review comment (posted suggestion) being rated
+	FeedbackSignal    string // FeedbackHelpful or FeedbackWrong
+
+	// Fields for SuggestionReply type
+	ThreadCommentID int64  // The review comment (posted suggestion) replied to
+	ReplyCommand    string // ReplyExplain, ReplyFix or ReplyApply
 }
 
 // EventFromIssueComment transforms a raw GitHub IssueCommentEvent into the application's
 // internal GitHubEvent representation. It acts as an anti-corruption layer, validating
 // the incoming webhook payload and extracting all necessary data before it's processed
-// by a job. It specifically filters for comments that are "/review" or "/rereview"
-// commands on pull requests.
+// by a job. It specifically filters for comments that are "/review", "/rereview",
+// "/security" or "/explain" commands on pull requests.
 //
 // Returns an error if the comment is not on a pull request, the command is invalid,
 // or required information is missing from the event.
@@ -112,7 +194,11 @@ func EventFromIssueComment(event *github.IssueCommentEvent) (*GitHubEvent, error
 	}, nil
 }
 
-const reReviewCmd = "/rereview"
+const (
+	reReviewCmd = "/rereview"
+	securityCmd = "/security"
+	explainCmd  = "/explain"
+)
 
 // sanitizeInstructions normalizes instructions by replacing whitespace characters
 // with spaces and removing control characters. This prevents injection attacks
@@ -138,14 +224,20 @@ func parseReviewCommand(commentBody string) (ReviewType, string, error) {
 	if commentBody == "/review" {
 		return FullReview, "", nil
 	}
+	if commentBody == securityCmd {
+		return SecurityReview, "", nil
+	}
+	if commentBody == explainCmd {
+		return ExplainPR, "", nil
+	}
 
 	if !strings.HasPrefix(commentBody, reReviewCmd) {
-		return 0, "", fmt.Errorf("comment is not a valid review command: expected /review or /rereview")
+		return 0, "", fmt.Errorf("comment is not a valid review command: expected /review, /rereview, /security or /explain")
 	}
 
 	// Ensure it's "/rereview" exactly or "/rereview " (with space)
 	if commentBody != reReviewCmd && !strings.HasPrefix(commentBody, reReviewCmd+" ") {
-		return 0, "", fmt.Errorf("comment is not a valid review command: expected /review or /rereview")
+		return 0, "", fmt.Errorf("comment is not a valid review command: expected /review, /rereview, /security or /explain")
 	}
 
 	args := strings.TrimPrefix(commentBody, reReviewCmd)
@@ -222,3 +314,217 @@ func parseImplementInstructions(commentBody string) string {
 
 	return sanitizeInstructions(instructions)
 }
+
+const feedbackCmd = "/warden"
+
+// FeedbackEventFromReviewComment transforms a reply to one of our inline review
+// comments into a RecordFeedback event. Only "/warden helpful" and
+// "/warden wrong" replies are accepted; anything after the signal is ignored.
+func FeedbackEventFromReviewComment(event *github.PullRequestReviewCommentEvent) (*GitHubEvent, error) {
+	if event.GetAction() != "created" {
+		return nil, fmt.Errorf("review comment action %q is not handled", event.GetAction())
+	}
+
+	comment := event.GetComment()
+	signal, ok := ParseFeedbackCommand(comment.GetBody())
+	if !ok {
+		return nil, fmt.Errorf("comment is not a feedback command: expected /warden helpful or /warden wrong")
+	}
+	if comment.GetInReplyTo() == 0 {
+		return nil, fmt.Errorf("feedback command must be a reply to a review suggestion")
+	}
+
+	repo := event.GetRepo()
+	if repo == nil || repo.GetOwner() == nil || repo.GetOwner().GetLogin() == "" || repo.GetName() == "" {
+		return nil, fmt.Errorf("repository or owner information is missing from the event")
+	}
+
+	prNumber := event.GetPullRequest().GetNumber()
+	if prNumber <= 0 {
+		return nil, fmt.Errorf("invalid pull request number: %d", prNumber)
+	}
+
+	if comment.GetUser() == nil || comment.GetUser().GetLogin() == "" {
+		return nil, fmt.Errorf("commenter information is missing from the event")
+	}
+
+	if event.GetInstallation() == nil || event.GetInstallation().GetID() == 0 {
+		return nil, fmt.Errorf("installation ID is missing from the event")
+	}
+
+	return &GitHubEvent{
+		Type:              RecordFeedback,
+		RepoOwner:         repo.GetOwner().GetLogin(),
+		RepoName:          repo.GetName(),
+		RepoFullName:      repo.GetFullName(),
+		RepoCloneURL:      repo.GetCloneURL(),
+		Language:          repo.GetLanguage(),
+		InstallationID:    event.GetInstallation().GetID(),
+		PRNumber:          prNumber,
+		PRTitle:           event.GetPullRequest().GetTitle(),
+		HeadSHA:           event.GetPullRequest().GetHead().GetSHA(),
+		Commenter:         comment.GetUser().GetLogin(),
+		FeedbackCommentID: comment.GetInReplyTo(),
+		FeedbackSignal:    signal,
+	}, nil
+}
+
+// ReplyEventFromReviewComment transforms a "/warden explain", "/warden fix"
+// or "/warden apply" reply to one of our inline review comments into a
+// SuggestionReply event. Text after the command is passed on as
+// instructions. "/warden apply" is only accepted from users who can push to
+// the repository.
+func ReplyEventFromReviewComment(event *github.PullRequestReviewCommentEvent) (*GitHubEvent, error) {
+	if event.GetAction() != "created" {
+		return nil, fmt.Errorf("review comment action %q is not handled", event.GetAction())
+	}
+
+	comment := event.GetComment()
+	command, instructions, ok := ParseReplyCommand(comment.GetBody())
+	if !ok {
+		return nil, fmt.Errorf("comment is not a reply command: expected /warden explain, /warden fix or /warden apply")
+	}
+	if comment.GetInReplyTo() == 0 {
+		return nil, fmt.Errorf("reply command must be a reply to a review suggestion")
+	}
+	if command == ReplyApply && !slices.Contains(writeAssociations, comment.GetAuthorAssociation()) {
+		return nil, fmt.Errorf("/warden apply by %s is not allowed: only collaborators can apply suggestions", comment.GetUser().GetLogin())
+	}
+
+	repo := event.GetRepo()
+	if repo == nil || repo.GetOwner() == nil || repo.GetOwner().GetLogin() == "" || repo.GetName() == "" {
+		return nil, fmt.Errorf("repository or owner information is missing from the event")
+	}
+
+	prNumber := event.GetPullRequest().GetNumber()
+	if prNumber <= 0 {
+		return nil, fmt.Errorf("invalid pull request number: %d", prNumber)
+	}
+
+	if comment.GetUser() == nil || comment.GetUser().GetLogin() == "" {
+		return nil, fmt.Errorf("commenter information is missing from the event")
+	}
+
+	if event.GetInstallation() == nil || event.GetInstallation().GetID() == 0 {
+		return nil, fmt.Errorf("installation ID is missing from the event")
+	}
+
+	return &GitHubEvent{
+		Type:             SuggestionReply,
+		RepoOwner:        repo.GetOwner().GetLogin(),
+		RepoName:         repo.GetName(),
+		RepoFullName:     repo.GetFullName(),
+		RepoCloneURL:     repo.GetCloneURL(),
+		Language:         repo.GetLanguage(),
+		InstallationID:   event.GetInstallation().GetID(),
+		PRNumber:         prNumber,
+		PRTitle:          event.GetPullRequest().GetTitle(),
+		PRBody:           event.GetPullRequest().GetBody(),
+		HeadSHA:          event.GetPullRequest().GetHead().GetSHA(),
+		Commenter:        comment.GetUser().GetLogin(),
+		UserInstructions: instructions,
+		ThreadCommentID:  comment.GetInReplyTo(),
+		ReplyCommand:     command,
+	}, nil
+}
+
+// EventFromPullRequest transforms a pull_request event into a FullReview
+// event for auto-review. Which actions trigger a review is decided by the
+// caller; closed pull requests are rejected. The sender of the event, who
+// opened or pushed to the pull request, is recorded as the commenter.
+func EventFromPullRequest(event *github.PullRequestEvent) (*GitHubEvent, error) {
+	pr := event.GetPullRequest()
+	if pr == nil || pr.GetNumber() <= 0 {
+		return nil, fmt.Errorf("pull request information is missing from the event")
+	}
+	if pr.GetState() == "closed" {
+		return nil, fmt.Errorf("pull request #%d is closed", pr.GetNumber())
+	}
+
+	repo := event.GetRepo()
+	if repo == nil || repo.GetOwner() == nil || repo.GetOwner().GetLogin() == "" || repo.GetName() == "" {
+		return nil, fmt.Errorf("repository or owner information is missing from the event")
+	}
+
+	if event.GetInstallation() == nil || event.GetInstallation().GetID() == 0 {
+		return nil, fmt.Errorf("installation ID is missing from the event")
+	}
+
+	return &GitHubEvent{
+		Type:           FullReview,
+		RepoOwner:      repo.GetOwner().GetLogin(),
+		RepoName:       repo.GetName(),
+		RepoFullName:   repo.GetFullName(),
+		RepoCloneURL:   repo.GetCloneURL(),
+		Language:       repo.GetLanguage(),
+		InstallationID: event.GetInstallation().GetID(),
+		PRNumber:       pr.GetNumber(),
+		PRTitle:        pr.GetTitle(),
+		PRBody:         pr.GetBody(),
+		HeadSHA:        pr.GetHead().GetSHA(),
+		Commenter:      event.GetSender().GetLogin(),
+		AutoReview:     true,
+	}, nil
+}
+
+// OnboardEventsFromInstallation returns an OnboardRepository event for each
+// repository an installation was created with or granted access to. The
+// installation payload only names the repositories, so their clone URLs are
+// derived from their full names. Repositories without a full name are
+// skipped.
+func OnboardEventsFromInstallation(installation *github.Installation, repos []*github.Repository) ([]*GitHubEvent, error) {
+	if installation.GetID() == 0 {
+		return nil, fmt.Errorf("installation ID is missing from the event")
+	}
+
+	events := make([]*GitHubEvent, 0, len(repos))
+	for _, repo := range repos {
+		owner, name, ok := strings.Cut(repo.GetFullName(), "/")
+		if !ok || owner == "" || name == "" {
+			continue
+		}
+		events = append(events, &GitHubEvent{
+			Type:           OnboardRepository,
+			RepoOwner:      owner,
+			RepoName:       name,
+			RepoFullName:   repo.GetFullName(),
+			RepoCloneURL:   "https://github.com/" + repo.GetFullName() + ".git",
+			InstallationID: installation.GetID(),
+			Commenter:      installation.GetAccount().GetLogin(),
+		})
+	}
+	return events, nil
+}
+
+// ParseFeedbackCommand reports whether body is a "/warden helpful" or
+// "/warden wrong" command and returns the corresponding signal.
+func ParseFeedbackCommand(body string) (string, bool) {
+	fields := strings.Fields(strings.ToLower(strings.TrimSpace(body)))
+	if len(fields) < 2 || fields[0] != feedbackCmd {
+		return "", false
+	}
+	switch fields[1] {
+	case FeedbackHelpful:
+		return FeedbackHelpful, true
+	case FeedbackWrong:
+		return FeedbackWrong, true
+	default:
+		return "", false
+	}
+}
+
+// ParseReplyCommand reports whether body is a "/warden explain",
+// "/warden fix" or "/warden apply" command and returns the command and the
+// sanitized text following it.
+func ParseReplyCommand(body string) (command, instructions string, ok bool) {
+	fields := strings.Fields(strings.TrimSpace(body))
+	if len(fields) < 2 || strings.ToLower(fields[0]) != feedbackCmd {
+		return "", "", false
+	}
+	switch command = strings.ToLower(fields[1]); command {
+	case ReplyExplain, ReplyFix, ReplyApply:
+		return command, sanitizeInstructions(strings.Join(fields[2:], " ")), true
+	default:
+		return "", "", false
+	}
+}
@@ -0,0 +1,230 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/google/go-github/v73/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFeedbackCommand(t *testing.T) {
+	tests := []struct {
+		body       string
+		wantSignal string
+		wantOK     bool
+	}{
+		{body: "/warden helpful", wantSignal: FeedbackHelpful, wantOK: true},
+		{body: "  /Warden WRONG this is a false positive", wantSignal: FeedbackWrong, wantOK: true},
+		{body: "/warden", wantOK: false},
+		{body: "/warden maybe", wantOK: false},
+		{body: "looks helpful to me", wantOK: false},
+		{body: "/review", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			signal, ok := ParseFeedbackCommand(tt.body)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantSignal, signal)
+		})
+	}
+}
+
+func TestParseReviewCommand(t *testing.T) {
+	tests := []struct {
+		body             string
+		wantType         ReviewType
+		wantInstructions string
+		wantErr          bool
+	}{
+		{body: "/review", wantType: FullReview},
+		{body: "/rereview", wantType: ReReview},
+		{body: "/rereview check security", wantType: ReReview, wantInstructions: "check security"},
+		{body: "/security", wantType: SecurityReview},
+		{body: "/securityreview", wantErr: true},
+		{body: "/explain", wantType: ExplainPR},
+		{body: "/explain internal/core", wantErr: true},
+		{body: "/rereviewed", wantErr: true},
+		{body: "please review", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			reviewType, instructions, err := parseReviewCommand(tt.body)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantType, reviewType)
+			assert.Equal(t, tt.wantInstructions, instructions)
+		})
+	}
+}
+
+func TestFeedbackEventFromReviewComment(t *testing.T) {
+	newEvent := func(action, body string, inReplyTo int64) *github.PullRequestReviewCommentEvent {
+		return &github.PullRequestReviewCommentEvent{
+			Action: github.Ptr(action),
+			Comment: &github.PullRequestComment{
+				Body:      github.Ptr(body),
+				InReplyTo: github.Ptr(inReplyTo),
+				User:      &github.User{Login: github.Ptr("alice")},
+			},
+			PullRequest: &github.PullRequest{Number: github.Ptr(7)},
+			Repo: &github.Repository{
+				Name:     github.Ptr("repo"),
+				FullName: github.Ptr("octo/repo"),
+				Owner:    &github.User{Login: github.Ptr("octo")},
+			},
+			Installation: &github.Installation{ID: github.Ptr(int64(99))},
+		}
+	}
+
+	event, err := FeedbackEventFromReviewComment(newEvent("created", "/warden wrong", 42))
+	require.NoError(t, err)
+	assert.Equal(t, RecordFeedback, event.Type)
+	assert.Equal(t, int64(42), event.FeedbackCommentID)
+	assert.Equal(t, FeedbackWrong, event.FeedbackSignal)
+	assert.Equal(t, "alice", event.Commenter)
+	assert.Equal(t, 7, event.PRNumber)
+	assert.Equal(t, int64(99), event.InstallationID)
+
+	_, err = FeedbackEventFromReviewComment(newEvent("edited", "/warden wrong", 42))
+	assert.Error(t, err, "edits are ignored")
+	_, err = FeedbackEventFromReviewComment(newEvent("created", "/warden wrong", 0))
+	assert.Error(t, err, "top-level comments are not replies")
+	_, err = FeedbackEventFromReviewComment(newEvent("created", "nice catch", 42))
+	assert.Error(t, err, "plain replies are not feedback")
+}
+
+func TestParseReplyCommand(t *testing.T) {
+	tests := []struct {
+		body             string
+		wantCommand      string
+		wantInstructions string
+		wantOK           bool
+	}{
+		{body: "/warden explain", wantCommand: ReplyExplain, wantOK: true},
+		{body: "  /Warden FIX keep the public API", wantCommand: ReplyFix, wantInstructions: "keep the public API", wantOK: true},
+		{body: "/warden apply", wantCommand: ReplyApply, wantOK: true},
+		{body: "/warden helpful", wantOK: false},
+		{body: "/warden", wantOK: false},
+		{body: "please explain", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			command, instructions, ok := ParseReplyCommand(tt.body)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantCommand, command)
+			assert.Equal(t, tt.wantInstructions, instructions)
+		})
+	}
+}
+
+func TestReplyEventFromReviewComment(t *testing.T) {
+	newEvent := func(body string, inReplyTo int64) *github.PullRequestReviewCommentEvent {
+		return &github.PullRequestReviewCommentEvent{
+			Action: github.Ptr("created"),
+			Comment: &github.PullRequestComment{
+				Body:              github.Ptr(body),
+				InReplyTo:         github.Ptr(inReplyTo),
+				User:              &github.User{Login: github.Ptr("alice")},
+				AuthorAssociation: github.Ptr("CONTRIBUTOR"),
+			},
+			PullRequest: &github.PullRequest{Number: github.Ptr(7), Head: &github.PullRequestBranch{SHA: github.Ptr("abc123")}},
+			Repo: &github.Repository{
+				Name:     github.Ptr("repo"),
+				FullName: github.Ptr("octo/repo"),
+				Owner:    &github.User{Login: github.Ptr("octo")},
+			},
+			Installation: &github.Installation{ID: github.Ptr(int64(99))},
+		}
+	}
+
+	event, err := ReplyEventFromReviewComment(newEvent("/warden fix use errors.Is", 42))
+	require.NoError(t, err)
+	assert.Equal(t, SuggestionReply, event.Type)
+	assert.Equal(t, ReplyFix, event.ReplyCommand)
+	assert.Equal(t, "use errors.Is", event.UserInstructions)
+	assert.Equal(t, int64(42), event.ThreadCommentID)
+	assert.Equal(t, "abc123", event.HeadSHA)
+	assert.Equal(t, "reply", event.Type.String())
+
+	_, err = ReplyEventFromReviewComment(newEvent("/warden explain", 0))
+	assert.Error(t, err, "top-level comments are not replies")
+	_, err = ReplyEventFromReviewComment(newEvent("/warden wrong", 42))
+	assert.Error(t, err, "feedback is not a reply command")
+
+	_, err = ReplyEventFromReviewComment(newEvent("/warden apply", 42))
+	assert.Error(t, err, "contributors cannot push")
+	collaborator := newEvent("/warden apply", 42)
+	collaborator.Comment.AuthorAssociation = github.Ptr("COLLABORATOR")
+	event, err = ReplyEventFromReviewComment(collaborator)
+	require.NoError(t, err)
+	assert.Equal(t, ReplyApply, event.ReplyCommand)
+}
+
+func TestEventFromPullRequest(t *testing.T) {
+	newEvent := func(state string) *github.PullRequestEvent {
+		return &github.PullRequestEvent{
+			Action: github.Ptr("synchronize"),
+			PullRequest: &github.PullRequest{
+				Number: github.Ptr(7),
+				State:  github.Ptr(state),
+				Title:  github.Ptr("Add login"),
+				Head:   &github.PullRequestBranch{SHA: github.Ptr("abc123")},
+			},
+			Repo: &github.Repository{
+				Name:     github.Ptr("repo"),
+				FullName: github.Ptr("octo/repo"),
+				Owner:    &github.User{Login: github.Ptr("octo")},
+			},
+			Sender:       &github.User{Login: github.Ptr("alice")},
+			Installation: &github.Installation{ID: github.Ptr(int64(99))},
+		}
+	}
+
+	event, err := EventFromPullRequest(newEvent("open"))
+	require.NoError(t, err)
+	assert.Equal(t, FullReview, event.Type)
+	assert.True(t, event.AutoReview)
+	assert.Equal(t, 7, event.PRNumber)
+	assert.Equal(t, "abc123", event.HeadSHA)
+	assert.Equal(t, "alice", event.Commenter)
+	assert.Equal(t, int64(99), event.InstallationID)
+
+	_, err = EventFromPullRequest(newEvent("closed"))
+	assert.Error(t, err, "closed pull requests are not reviewed")
+
+	noInstallation := newEvent("open")
+	noInstallation.Installation = nil
+	_, err = EventFromPullRequest(noInstallation)
+	assert.Error(t, err)
+}
+
+func TestOnboardEventsFromInstallation(t *testing.T) {
+	installation := &github.Installation{
+		ID:      github.Ptr(int64(99)),
+		Account: &github.User{Login: github.Ptr("octo")},
+	}
+	repos := []*github.Repository{
+		{Name: github.Ptr("repo"), FullName: github.Ptr("octo/repo")},
+		{Name: github.Ptr("broken")},
+	}
+
+	events, err := OnboardEventsFromInstallation(installation, repos)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, &GitHubEvent{
+		Type:           OnboardRepository,
+		RepoOwner:      "octo",
+		RepoName:       "repo",
+		RepoFullName:   "octo/repo",
+		RepoCloneURL:   "https://github.com/octo/repo.git",
+		InstallationID: 99,
+		Commenter:      "octo",
+	}, events[0])
+
+	_, err = OnboardEventsFromInstallation(&github.Installation{}, repos)
+	assert.Error(t, err)
+}
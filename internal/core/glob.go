@@ -0,0 +1,57 @@
+package core
+
+import (
+	"path"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/pathutil"
+)
+
+// MatchGlob reports whether relPath matches the given glob pattern.
+// Supports ** as a multi-segment wildcard and bare patterns (no /) as
+// basename-only matches.
+func MatchGlob(pattern, relPath string) bool {
+	pattern = pathutil.ToSlash(pattern)
+	relPath = pathutil.ToSlash(relPath)
+
+	if !strings.Contains(pattern, "**") {
+		if !strings.Contains(pattern, "/") {
+			// Basename-only: *.go, foo.go
+			m, _ := path.Match(pattern, path.Base(relPath))
+			return m
+		}
+		// Path-rooted: internal/agent/*.go
+		m, _ := path.Match(pattern, relPath)
+		return m
+	}
+	return matchDoublestar(pattern, relPath)
+}
+
+// matchDoublestar matches a pattern that contains at least one ** against s.
+// ** matches zero or more path segments.
+func matchDoublestar(pattern, s string) bool {
+	prefix, after, _ := strings.Cut(pattern, "**")
+	rest := strings.TrimPrefix(after, "/")
+
+	// The prefix (everything before **) must match the beginning of s.
+	if prefix != "" {
+		if !strings.HasPrefix(s+"/", prefix) {
+			return false
+		}
+		s = strings.TrimPrefix(s, strings.TrimSuffix(prefix, "/"))
+		s = strings.TrimPrefix(s, "/")
+	}
+
+	// ** matches zero or more segments. Try each possible split.
+	if rest == "" {
+		return true // trailing ** matches everything
+	}
+	parts := strings.Split(s, "/")
+	for i := 0; i <= len(parts); i++ {
+		candidate := strings.Join(parts[i:], "/")
+		if MatchGlob(rest, candidate) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchGlob_Basename(t *testing.T) {
+	assert.True(t, MatchGlob("*.go", "foo.go"))
+	assert.True(t, MatchGlob("*.go", "src/foo.go"))
+	assert.False(t, MatchGlob("*.go", "foo.txt"))
+}
+
+func TestMatchGlob_WithPath(t *testing.T) {
+	assert.True(t, MatchGlob("internal/agent/*.go", "internal/agent/foo.go"))
+	assert.False(t, MatchGlob("internal/agent/*.go", "internal/other/foo.go"))
+}
+
+func TestMatchGlob_DoubleStar(t *testing.T) {
+	assert.True(t, MatchGlob("**/*_test.go", "foo_test.go"))
+	assert.True(t, MatchGlob("**/*_test.go", "a/b/foo_test.go"))
+	assert.False(t, MatchGlob("**/*_test.go", "a/b/foo.go"))
+	assert.True(t, MatchGlob("**/*.go", "main.go"))
+	assert.True(t, MatchGlob("**/*.go", "a/b/c/main.go"))
+}
+
+func TestMatchGlob_DoubleStarWithPrefix(t *testing.T) {
+	assert.True(t, MatchGlob("internal/**/*.go", "internal/agent/foo.go"))
+	assert.True(t, MatchGlob("internal/**/*.go", "internal/a/b/foo.go"))
+	assert.False(t, MatchGlob("internal/**/*.go", "cmd/main.go"))
+}
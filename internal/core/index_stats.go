@@ -0,0 +1,145 @@
+package core
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reasons a file of a repository is not in its index.
+const (
+	SkipUnsupportedExtension = "unsupported_extension"
+	SkipExcludedExtension    = "excluded_extension"
+	SkipGenerated            = "generated"
+	SkipBinary               = "binary"
+	SkipTooLarge             = "too_large"
+	SkipSubProjectExcluded   = "excluded_by_sub_project"
+	// SkipExcludedPattern covers files left out by the include and exclude
+	// patterns of the repository.
+	SkipExcludedPattern = "excluded_by_pattern"
+	// SkipFileLimit covers files beyond the repository's max_files limit.
+	SkipFileLimit = "file_limit"
+	// SkipNotLoaded covers files the loader dropped itself, mostly generated
+	// code it recognises and files it could not read.
+	SkipNotLoaded = "not_loaded"
+)
+
+// IndexStats describes what the last full index of a repository covers. Files
+// in hidden and excluded directories are not counted.
+type IndexStats struct {
+	ComputedAt time.Time `json:"computed_at"`
+	DurationMs int64     `json:"duration_ms"`
+	// Files is the number of files considered for the index.
+	Files        int `json:"files"`
+	IndexedFiles int `json:"indexed_files"`
+	// Chunks counts the documents stored for the indexed files.
+	Chunks           int     `json:"chunks"`
+	AvgChunksPerFile float64 `json:"avg_chunks_per_file"`
+	// Skipped counts the files left out of the index by reason.
+	Skipped   map[string]int  `json:"skipped"`
+	Languages []LanguageStats `json:"languages"`
+}
+
+// LanguageStats is the index coverage of one language.
+type LanguageStats struct {
+	Language         string  `json:"language"`
+	Files            int     `json:"files"`
+	IndexedFiles     int     `json:"indexed_files"`
+	Chunks           int     `json:"chunks"`
+	AvgChunksPerFile float64 `json:"avg_chunks_per_file"`
+}
+
+// IndexStatsBuilder accumulates IndexStats file by file. It is not safe for
+// concurrent use.
+type IndexStatsBuilder struct {
+	stats     IndexStats
+	languages map[string]*LanguageStats
+}
+
+// NewIndexStatsBuilder creates an empty [IndexStatsBuilder].
+func NewIndexStatsBuilder() *IndexStatsBuilder {
+	return &IndexStatsBuilder{
+		stats:     IndexStats{Skipped: make(map[string]int)},
+		languages: make(map[string]*LanguageStats),
+	}
+}
+
+func (b *IndexStatsBuilder) language(file string) *LanguageStats {
+	name := LanguageOf(file)
+	lang, ok := b.languages[name]
+	if !ok {
+		lang = &LanguageStats{Language: name}
+		b.languages[name] = lang
+	}
+	return lang
+}
+
+// Indexed records a file that is in the index with its number of chunks.
+func (b *IndexStatsBuilder) Indexed(file string, chunks int) {
+	lang := b.language(file)
+	lang.Files++
+	lang.IndexedFiles++
+	lang.Chunks += chunks
+	b.stats.Files++
+	b.stats.IndexedFiles++
+	b.stats.Chunks += chunks
+}
+
+// Skipped records a file left out of the index for reason.
+func (b *IndexStatsBuilder) Skipped(file, reason string) {
+	b.language(file).Files++
+	b.stats.Files++
+	b.stats.Skipped[reason]++
+}
+
+// Build returns the stats, with languages ordered by file count.
+func (b *IndexStatsBuilder) Build(duration time.Duration) *IndexStats {
+	stats := b.stats
+	stats.ComputedAt = time.Now()
+	stats.DurationMs = duration.Milliseconds()
+	stats.AvgChunksPerFile = average(stats.Chunks, stats.IndexedFiles)
+	stats.Languages = make([]LanguageStats, 0, len(b.languages))
+	for _, lang := range b.languages {
+		lang.AvgChunksPerFile = average(lang.Chunks, lang.IndexedFiles)
+		stats.Languages = append(stats.Languages, *lang)
+	}
+	sort.Slice(stats.Languages, func(i, j int) bool {
+		a, c := stats.Languages[i], stats.Languages[j]
+		if a.Files != c.Files {
+			return a.Files > c.Files
+		}
+		return a.Language < c.Language
+	})
+	return &stats
+}
+
+func average(total, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	return float64(total) / float64(n)
+}
+
+// languageNames maps file extensions to language names.
+var languageNames = map[string]string{
+	".go": "Go", ".py": "Python", ".js": "JavaScript", ".jsx": "JavaScript", ".mjs": "JavaScript", ".cjs": "JavaScript",
+	".ts": "TypeScript", ".tsx": "TypeScript", ".java": "Java", ".kt": "Kotlin", ".kts": "Kotlin", ".rs": "Rust",
+	".rb": "Ruby", ".php": "PHP", ".cs": "C#", ".c": "C", ".h": "C", ".cpp": "C++", ".cc": "C++", ".cxx": "C++",
+	".hpp": "C++", ".swift": "Swift", ".scala": "Scala", ".sh": "Shell", ".bash": "Shell", ".sql": "SQL",
+	".md": "Markdown", ".yaml": "YAML", ".yml": "YAML", ".json": "JSON", ".toml": "TOML", ".proto": "Protocol Buffers",
+	".html": "HTML", ".css": "CSS", ".scss": "CSS", ".vue": "Vue", ".svelte": "Svelte", ".lua": "Lua", ".dart": "Dart",
+}
+
+// LanguageOf returns the language of a file from its extension, the bare
+// extension for unknown ones, or "other" for files without one.
+func LanguageOf(file string) string {
+	ext := strings.ToLower(path.Ext(file))
+	if name, ok := languageNames[ext]; ok {
+		return name
+	}
+	if ext == "" {
+		return "other"
+	}
+	return strings.TrimPrefix(ext, ".")
+}
@@ -0,0 +1,43 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexStatsBuilder(t *testing.T) {
+	b := NewIndexStatsBuilder()
+	b.Indexed("main.go", 4)
+	b.Indexed("pkg/util.go", 2)
+	b.Skipped("api/service.pb.go", SkipGenerated)
+	b.Indexed("web/app.ts", 3)
+	b.Skipped("assets/logo.png", SkipUnsupportedExtension)
+
+	stats := b.Build(1500 * time.Millisecond)
+
+	assert.Equal(t, int64(1500), stats.DurationMs)
+	assert.Equal(t, 5, stats.Files)
+	assert.Equal(t, 3, stats.IndexedFiles)
+	assert.Equal(t, 9, stats.Chunks)
+	assert.InDelta(t, 3.0, stats.AvgChunksPerFile, 0.001)
+	assert.Equal(t, map[string]int{SkipGenerated: 1, SkipUnsupportedExtension: 1}, stats.Skipped)
+	assert.Equal(t, []LanguageStats{
+		{Language: "Go", Files: 3, IndexedFiles: 2, Chunks: 6, AvgChunksPerFile: 3},
+		{Language: "TypeScript", Files: 1, IndexedFiles: 1, Chunks: 3, AvgChunksPerFile: 3},
+		{Language: "png", Files: 1},
+	}, stats.Languages)
+}
+
+func TestLanguageOf(t *testing.T) {
+	tests := map[string]string{
+		"cmd/main.go":   "Go",
+		"src/App.TSX":   "TypeScript",
+		"data/file.xyz": "xyz",
+		"Makefile":      "other",
+	}
+	for file, want := range tests {
+		assert.Equal(t, want, LanguageOf(file), file)
+	}
+}
@@ -5,6 +5,18 @@ package core
 
 import (
 	"context"
+	"errors"
+	"time"
+)
+
+// Causes with which a JobDispatcher cancels the context of a job, as
+// reported by context.Cause.
+var (
+	// ErrJobTimedOut stops a job that ran longer than server.job_timeout.
+	ErrJobTimedOut = errors.New("job exceeded the maximum job duration")
+	// ErrJobCancelled stops a job cancelled through the API or because its
+	// repository was deleted.
+	ErrJobCancelled = errors.New("job was cancelled")
 )
 
 // JobDispatcher defines the contract for a system that can accept and queue
@@ -17,10 +29,48 @@ type JobDispatcher interface {
 	// The provided ctx can be used for tracing or a short deadline for the
 	// enqueue operation itself.
 	Dispatch(ctx context.Context, event *GitHubEvent) error
+	// CancelRepo cancels the queued and running jobs of a repository and
+	// returns how many it cancelled. Queued jobs are dropped without running.
+	CancelRepo(repoFullName string) int
+	// CancelJob cancels a queued or running job by the ID ActiveJobs
+	// reports, and returns false if there is no such job.
+	CancelJob(id int64) bool
+	// ActiveJobs lists the queued and running jobs, oldest first.
+	ActiveJobs() []ActiveJob
+	// QueueStats reports how many jobs are waiting and running.
+	QueueStats() QueueStats
 	// Stop gracefully shuts down the dispatcher and its worker pool, waiting for active jobs to complete.
 	Stop()
 }
 
+// QueueStats is a snapshot of the job queue of a JobDispatcher.
+type QueueStats struct {
+	Queued   int `json:"queued"`
+	Running  int `json:"running"`
+	Capacity int `json:"capacity"`
+	Workers  int `json:"workers"`
+}
+
+// Job states reported by ActiveJobs.
+const (
+	JobQueued  = "queued"
+	JobRunning = "running"
+)
+
+// ActiveJob is a job waiting in or taken from the queue of a JobDispatcher.
+// Its ID is only valid while the job is active.
+type ActiveJob struct {
+	ID        int64      `json:"id"`
+	Kind      string     `json:"kind"`
+	Repo      string     `json:"repo"`
+	PRNumber  int        `json:"pr_number,omitempty"`
+	State     string     `json:"state"`
+	QueuedAt  time.Time  `json:"queued_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// ReviewID names the trace of the job's log records.
+	ReviewID string `json:"review_id,omitempty"`
+}
+
 // SessionCanceller can cancel a running agent session by its ID.
 // It is implemented by the jobs layer and passed to the webhook handler
 // so that /cancel <session-id> comments can stop in-flight sessions.
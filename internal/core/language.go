@@ -0,0 +1,237 @@
+package core
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// languageFileNames maps base names of files without a telling extension to
+// their language.
+var languageFileNames = map[string]string{
+	"dockerfile": "Dockerfile", "containerfile": "Dockerfile", "makefile": "Makefile", "gnumakefile": "Makefile",
+	"rakefile": "Ruby", "gemfile": "Ruby", "jenkinsfile": "Groovy", "justfile": "Just",
+}
+
+// shebangLanguages maps interpreters named in a shebang line to languages.
+var shebangLanguages = map[string]string{
+	"sh": "Shell", "bash": "Shell", "zsh": "Shell", "dash": "Shell", "ksh": "Shell",
+	"python": "Python", "python3": "Python", "python2": "Python",
+	"node": "JavaScript", "deno": "TypeScript", "bun": "TypeScript", "ts-node": "TypeScript",
+	"ruby": "Ruby", "perl": "Perl", "php": "PHP", "lua": "Lua",
+}
+
+// cppHeaderMarkers are constructs that make a .h file C++ rather than C.
+var cppHeaderMarkers = regexp.MustCompile(`(?m)^\s*(class\s+\w+|namespace\s+\w+|template\s*<)|std::|\bpublic:|\bprivate:`)
+
+// DetectLanguage returns the language of file, relative to the repository,
+// from its name and, where the name is not conclusive, from content, which
+// may be the whole file or the lines of a patch. It returns "" when the
+// language is unknown.
+func DetectLanguage(file, content string) string {
+	base := strings.ToLower(path.Base(file))
+	if lang, ok := languageFileNames[base]; ok {
+		return lang
+	}
+	if strings.HasPrefix(base, "dockerfile.") {
+		return "Dockerfile"
+	}
+
+	ext := path.Ext(base)
+	switch {
+	case ext == ".h" && cppHeaderMarkers.MatchString(content):
+		return "C++"
+	case ext == "":
+		return shebangLanguage(content)
+	}
+	if lang, ok := languageNames[ext]; ok {
+		return lang
+	}
+	return ""
+}
+
+// shebangLanguage returns the language of the interpreter of the shebang
+// line content starts with, or "".
+func shebangLanguage(content string) string {
+	line, _, _ := strings.Cut(content, "\n")
+	line, ok := strings.CutPrefix(strings.TrimSpace(line), "#!")
+	if !ok {
+		return ""
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := path.Base(fields[0])
+	if interpreter == "env" {
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") {
+				interpreter = field
+				break
+			}
+		}
+	}
+	return shebangLanguages[interpreter]
+}
+
+// LanguageShare is the part of a change in one language.
+type LanguageShare struct {
+	Language string `json:"language"`
+	Files    int    `json:"files"`
+	// Lines counts the added and removed lines of the files.
+	Lines int `json:"lines"`
+}
+
+// LanguageBreakdownBuilder collects the languages of the files of a change.
+type LanguageBreakdownBuilder struct {
+	shares map[string]*LanguageShare
+}
+
+// Add counts file in lang with lines changed lines. Files of unknown
+// language are not counted.
+func (b *LanguageBreakdownBuilder) Add(lang string, lines int) {
+	if lang == "" {
+		return
+	}
+	if b.shares == nil {
+		b.shares = make(map[string]*LanguageShare)
+	}
+	share, ok := b.shares[lang]
+	if !ok {
+		share = &LanguageShare{Language: lang}
+		b.shares[lang] = share
+	}
+	share.Files++
+	share.Lines += lines
+}
+
+// Build returns the shares, the largest by changed lines first.
+func (b *LanguageBreakdownBuilder) Build() []LanguageShare {
+	shares := make([]LanguageShare, 0, len(b.shares))
+	for _, share := range b.shares {
+		shares = append(shares, *share)
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		a, c := shares[i], shares[j]
+		if a.Lines != c.Lines {
+			return a.Lines > c.Lines
+		}
+		if a.Files != c.Files {
+			return a.Files > c.Files
+		}
+		return a.Language < c.Language
+	})
+	return shares
+}
+
+// FormatLanguageShares renders shares for a prompt, e.g.
+// "Go (3 files, 120 lines), TypeScript (1 file, 8 lines)".
+func FormatLanguageShares(shares []LanguageShare) string {
+	parts := make([]string, len(shares))
+	for i, share := range shares {
+		parts[i] = fmt.Sprintf("%s (%s, %s)", share.Language, plural(share.Files, "file"), plural(share.Lines, "line"))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// snippetSignals are constructs that identify the language of a code
+// snippet. Each match is one signal; see SnippetLanguage.
+var snippetSignals = map[string][]*regexp.Regexp{
+	"Go": {
+		regexp.MustCompile(`(?m)^package \w+$`),
+		regexp.MustCompile(`\w+ := `),
+		regexp.MustCompile(`\bfunc (\(\w+ \*?\w+\) )?\w*\(`),
+		regexp.MustCompile(`if err != nil`),
+	},
+	"Python": {
+		regexp.MustCompile(`(?m)^\s*def \w+\(.*\)( -> .+)?:\s*$`),
+		regexp.MustCompile(`(?m)^\s*(elif|except)\b.*:\s*$`),
+		regexp.MustCompile(`\bself\.\w+`),
+		regexp.MustCompile(`(?m)^\s*from [\w.]+ import \w+`),
+	},
+	"JavaScript": {
+		regexp.MustCompile(`(?m)^\s*(const|let) \w+ = `),
+		regexp.MustCompile(`\) => \{`),
+		regexp.MustCompile(`console\.log\(`),
+		regexp.MustCompile(`===|!==`),
+	},
+	"Java": {
+		regexp.MustCompile(`\b(public|private|protected)( static)?( final)? [\w<>\[\]]+ \w+\s*[({=;]`),
+		regexp.MustCompile(`System\.out\.print`),
+		regexp.MustCompile(`@Override`),
+	},
+	"Rust": {
+		regexp.MustCompile(`\bfn \w+(<.*>)?\(`),
+		regexp.MustCompile(`\blet mut \w+`),
+		regexp.MustCompile(`(?m)^\s*impl\b`),
+		regexp.MustCompile(`\w+!\(`),
+	},
+	"Ruby": {
+		regexp.MustCompile(`(?m)^\s*def \w+[?!]?(\(.*\))?\s*$`),
+		regexp.MustCompile(`(?m)^\s*end\s*$`),
+		regexp.MustCompile(`\bputs `),
+	},
+	"PHP": {
+		regexp.MustCompile(`<\?php`),
+		regexp.MustCompile(`\$this->`),
+		regexp.MustCompile(`\$\w+ = `),
+	},
+}
+
+// languageFamilies groups languages whose code may appear in each other's
+// files, like JavaScript in TypeScript.
+var languageFamilies = map[string]string{
+	"TypeScript": "JavaScript", "Vue": "JavaScript", "Svelte": "JavaScript",
+	"C++": "C",
+}
+
+// SnippetLanguage returns the language a code snippet is written in when it
+// carries at least two signals of one language and more than of any other,
+// or "" when it is not clearly one of the languages it knows.
+func SnippetLanguage(code string) string {
+	best, bestScore, tie := "", 0, false
+	for lang, signals := range snippetSignals {
+		score := 0
+		for _, signal := range signals {
+			if signal.MatchString(code) {
+				score++
+			}
+		}
+		switch {
+		case score > bestScore:
+			best, bestScore, tie = lang, score, false
+		case score == bestScore:
+			tie = true
+		}
+	}
+	if bestScore < 2 || tie {
+		return ""
+	}
+	return best
+}
+
+// LanguageMismatch reports whether code, proposed for a file in fileLang,
+// is clearly written in another language.
+func LanguageMismatch(fileLang, code string) bool {
+	if _, known := snippetSignals[family(fileLang)]; !known {
+		return false
+	}
+	snippet := SnippetLanguage(code)
+	return snippet != "" && family(snippet) != family(fileLang)
+}
+
+func family(lang string) string {
+	if f, ok := languageFamilies[lang]; ok {
+		return f
+	}
+	return lang
+}
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		file, content, want string
+	}{
+		{"internal/server/server.go", "", "Go"},
+		{"web/src/App.tsx", "", "TypeScript"},
+		{"deploy/Dockerfile", "FROM golang", "Dockerfile"},
+		{"Dockerfile.dev", "", "Dockerfile"},
+		{"Makefile", "", "Makefile"},
+		{"scripts/release", "#!/usr/bin/env bash\nset -e\n", "Shell"},
+		{"bin/migrate", "#!/usr/bin/env -S python3 -u\nimport sys\n", "Python"},
+		{"bin/tool", "no shebang here", ""},
+		{"include/buffer.h", "typedef struct buffer buffer;\n", "C"},
+		{"include/buffer.h", "namespace io {\nclass Buffer {};\n}\n", "C++"},
+		{"assets/logo.xyz", "", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, DetectLanguage(tt.file, tt.content), tt.file)
+	}
+}
+
+func TestLanguageBreakdown(t *testing.T) {
+	var b LanguageBreakdownBuilder
+	b.Add("Go", 100)
+	b.Add("SQL", 8)
+	b.Add("Go", 20)
+	b.Add("", 50)
+
+	shares := b.Build()
+	assert.Equal(t, []LanguageShare{{Language: "Go", Files: 2, Lines: 120}, {Language: "SQL", Files: 1, Lines: 8}}, shares)
+	assert.Equal(t, "Go (2 files, 120 lines), SQL (1 file, 8 lines)", FormatLanguageShares(shares))
+}
+
+func TestSnippetLanguage(t *testing.T) {
+	goCode := "if err != nil {\n\treturn fmt.Errorf(\"load: %w\", err)\n}\ncfg := load()"
+	pyCode := "def load(self, path):\n    self.path = path\n"
+	jsCode := "const cfg = load();\nif (cfg === null) { console.log('missing') }"
+
+	assert.Equal(t, "Go", SnippetLanguage(goCode))
+	assert.Equal(t, "Python", SnippetLanguage(pyCode))
+	assert.Equal(t, "JavaScript", SnippetLanguage(jsCode))
+	assert.Empty(t, SnippetLanguage("x = x + 1"), "a single weak signal is not conclusive")
+
+	assert.True(t, LanguageMismatch("Go", pyCode))
+	assert.False(t, LanguageMismatch("Go", goCode))
+	assert.False(t, LanguageMismatch("TypeScript", jsCode), "JavaScript is at home in TypeScript files")
+	assert.False(t, LanguageMismatch("YAML", goCode), "files of languages without signals are not checked")
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"path"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/pathutil"
+)
+
+// pathPattern is one parsed gitignore-style pattern.
+type pathPattern struct {
+	glob     string
+	negate   bool // "!pattern" re-includes what earlier patterns matched
+	dirOnly  bool // "pattern/" only matches directories
+	anchored bool // "/pattern" or "a/pattern" is relative to the root
+}
+
+// PathPatterns is an ordered list of gitignore-style patterns. A pattern
+// without a slash matches a file or directory name at any depth, a leading
+// or inner slash anchors it to the repository root, a trailing slash
+// restricts it to directories, ** spans any number of directories and a
+// leading ! negates it. A pattern that matches a directory matches every
+// file below it. The last pattern that matches a path decides.
+type PathPatterns []pathPattern
+
+// ParsePathPatterns parses gitignore-style lines, skipping blank lines and
+// # comments.
+func ParsePathPatterns(lines []string) PathPatterns {
+	patterns := make(PathPatterns, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var p pathPattern
+		if rest, ok := strings.CutPrefix(line, "!"); ok {
+			p.negate = true
+			line = rest
+		}
+		line = strings.TrimPrefix(line, `\`) // "\#file" and "\!file" are literal
+		line = pathutil.ToSlash(line)
+		if rest, ok := strings.CutSuffix(line, "/"); ok {
+			p.dirOnly = true
+			line = rest
+		}
+		if rest, ok := strings.CutPrefix(line, "/"); ok {
+			p.anchored = true
+			line = rest
+		}
+		p.anchored = p.anchored || strings.Contains(line, "/")
+		if line == "" {
+			continue
+		}
+		p.glob = line
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// Matches reports whether the last pattern matching relPath, a file relative
+// to the repository root, is not negated.
+func (ps PathPatterns) Matches(relPath string) bool {
+	return ps.match(relPath, false)
+}
+
+// hasNegations reports whether any pattern re-includes paths.
+func (ps PathPatterns) hasNegations() bool {
+	for _, p := range ps {
+		if p.negate {
+			return true
+		}
+	}
+	return false
+}
+
+func (ps PathPatterns) match(relPath string, isDir bool) bool {
+	segments := strings.Split(strings.Trim(pathutil.ToSlash(relPath), "/"), "/")
+	matched := false
+	for _, p := range ps {
+		if p.matches(segments, isDir) {
+			matched = !p.negate
+		}
+	}
+	return matched
+}
+
+// matches reports whether p matches the path made of segments or one of its
+// parent directories.
+func (p pathPattern) matches(segments []string, isDir bool) bool {
+	for i := 1; i <= len(segments); i++ {
+		if p.dirOnly && i == len(segments) && !isDir {
+			continue
+		}
+		if p.matchPath(segments[:i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p pathPattern) matchPath(segments []string) bool {
+	if !p.anchored {
+		m, _ := path.Match(p.glob, segments[len(segments)-1])
+		return m
+	}
+	relPath := strings.Join(segments, "/")
+	if strings.Contains(p.glob, "**") {
+		return matchDoublestar(p.glob, relPath)
+	}
+	m, _ := path.Match(p.glob, relPath)
+	return m
+}
+
+// PathFilter decides which files of a repository are indexed by the include
+// and exclude patterns of its configuration. A nil PathFilter allows every
+// file.
+type PathFilter struct {
+	include PathPatterns
+	exclude PathPatterns
+}
+
+// NewPathFilter returns a filter for include and exclude patterns, or nil when
+// both are empty. With include patterns a file must match one of them; the
+// exclude patterns are applied afterwards and can leave out included files.
+func NewPathFilter(include, exclude []string) *PathFilter {
+	f := &PathFilter{include: ParsePathPatterns(include), exclude: ParsePathPatterns(exclude)}
+	if len(f.include) == 0 && len(f.exclude) == 0 {
+		return nil
+	}
+	return f
+}
+
+// Allows reports whether the file at relPath passes the patterns.
+func (f *PathFilter) Allows(relPath string) bool {
+	if f == nil {
+		return true
+	}
+	if len(f.include) > 0 && !f.include.Matches(relPath) {
+		return false
+	}
+	return !f.exclude.Matches(relPath)
+}
+
+// SkipsDir reports whether no file below the directory relDir can pass the
+// patterns, so a walk need not descend into it. Directories are never
+// skipped while a negated exclude pattern could re-include files below them.
+func (f *PathFilter) SkipsDir(relDir string) bool {
+	if f == nil || f.exclude.hasNegations() {
+		return false
+	}
+	return f.exclude.match(relDir, true)
+}
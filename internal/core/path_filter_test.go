@@ -0,0 +1,51 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathFilter_Allows(t *testing.T) {
+	tests := []struct {
+		name             string
+		include, exclude []string
+		path             string
+		want             bool
+	}{
+		{name: "no patterns", path: "main.go", want: true},
+		{name: "basename at any depth", exclude: []string{"*.snap"}, path: "web/ui/__snapshots__/a.snap", want: false},
+		{name: "directory name at any depth", exclude: []string{"testdata"}, path: "pkg/testdata/in.json", want: false},
+		{name: "directory only pattern skips files", exclude: []string{"docs/"}, path: "docs", want: true},
+		{name: "directory only pattern skips contents", exclude: []string{"docs/"}, path: "docs/guide.md", want: false},
+		{name: "anchored pattern", exclude: []string{"/build.go"}, path: "tools/build.go", want: true},
+		{name: "anchored pattern at root", exclude: []string{"/build.go"}, path: "build.go", want: false},
+		{name: "inner slash anchors", exclude: []string{"api/*.json"}, path: "web/api/a.json", want: true},
+		{name: "double star", exclude: []string{"internal/**/mocks/"}, path: "internal/a/b/mocks/store.go", want: false},
+		{name: "negation re-includes", exclude: []string{"*.json", "!config/*.json"}, path: "config/app.json", want: true},
+		{name: "last match wins", exclude: []string{"!config/*.json", "*.json"}, path: "config/app.json", want: false},
+		{name: "comments and blank lines", exclude: []string{"# *.go", "", "  "}, path: "main.go", want: true},
+		{name: "escaped hash", exclude: []string{`\#notes.md`}, path: "#notes.md", want: false},
+		{name: "include requires a match", include: []string{"src/"}, path: "scripts/run.py", want: false},
+		{name: "include matches directory", include: []string{"src/"}, path: "src/app/main.go", want: true},
+		{name: "exclude applies after include", include: []string{"src/"}, exclude: []string{"*_test.go"}, path: "src/a_test.go", want: false},
+		{name: "negated include", include: []string{"**/*.go", "!cmd/**"}, path: "cmd/main.go", want: false},
+		{name: "windows separators", exclude: []string{`gen\`}, path: "gen/a.go", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewPathFilter(tt.include, tt.exclude).Allows(tt.path))
+		})
+	}
+}
+
+func TestPathFilter_SkipsDir(t *testing.T) {
+	assert.False(t, NewPathFilter(nil, nil).SkipsDir("vendor"), "a nil filter skips nothing")
+
+	f := NewPathFilter([]string{"src/"}, []string{"dist/", "*.snap"})
+	assert.True(t, f.SkipsDir("web/dist"))
+	assert.False(t, f.SkipsDir("scripts"), "include patterns never skip directories")
+
+	f = NewPathFilter(nil, []string{"dist/", "!dist/keep.js"})
+	assert.False(t, f.SkipsDir("dist"), "a negation may re-include files below the directory")
+}
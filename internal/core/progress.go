@@ -0,0 +1,43 @@
+package core
+
+import "context"
+
+// ReviewStage is a step of a running review, reported to whoever follows
+// its progress, such as the check run on the pull request.
+type ReviewStage string
+
+const (
+	// StageSyncing fetches the repository and the pull request.
+	StageSyncing ReviewStage = "syncing"
+	// StageIndexing brings the index up to date with the default branch;
+	// it reports how many of the changed files are done.
+	StageIndexing ReviewStage = "indexing"
+	// StageRetrieving gathers the repository context of the changed files.
+	StageRetrieving ReviewStage = "retrieving"
+	// StageGenerating waits for the LLM to write the review.
+	StageGenerating ReviewStage = "generating"
+	// StagePosting posts the review comments.
+	StagePosting ReviewStage = "posting"
+)
+
+// ReviewProgressFunc receives the stage of a review and, for stages that work
+// through items, how many of total are done. It must be safe for concurrent
+// use.
+type ReviewProgressFunc func(stage ReviewStage, done, total int)
+
+type reviewProgressKey struct{}
+
+// WithReviewProgress returns a context that reports review stages to fn. The
+// stages are reported from deep within the review pipeline, so fn travels
+// with the context instead of being passed to every step. A nil fn stops
+// reporting, e.g. for parts of a review that report progress otherwise.
+func WithReviewProgress(ctx context.Context, fn ReviewProgressFunc) context.Context {
+	return context.WithValue(ctx, reviewProgressKey{}, fn)
+}
+
+// ReportReviewStage reports a stage to the ReviewProgressFunc of ctx, if any.
+func ReportReviewStage(ctx context.Context, stage ReviewStage, done, total int) {
+	if fn, _ := ctx.Value(reviewProgressKey{}).(ReviewProgressFunc); fn != nil {
+		fn(stage, done, total)
+	}
+}
@@ -1,6 +1,9 @@
 package core
 
-import "strings"
+import (
+	"slices"
+	"strings"
+)
 
 // DefaultExcludedDirs are directories excluded from scanning and indexing by default.
 var DefaultExcludedDirs = []string{".git", ".github", "vendor", "node_modules", "target", "build"}
@@ -75,6 +78,17 @@ type RepoConfig struct {
 	// Example: ["config/secrets.json", "scripts/temp.py"]
 	ExcludeFiles []string `yaml:"exclude_files"`
 
+	// Include limits indexing to files matching one of these gitignore-style
+	// patterns. Empty includes every file the other settings allow; include
+	// patterns never bring back files the exclude_* lists leave out.
+	// Example: ["src/", "**/*.go"]
+	Include []string `yaml:"include"`
+
+	// Exclude leaves out files matching these gitignore-style patterns. They
+	// are applied after Include, the last matching pattern wins and a leading
+	// ! re-includes a file. Example: ["testdata/", "*.snap", "!keep.snap"]
+	Exclude []string `yaml:"exclude"`
+
 	// VerifyCommands are commands to run before code review (e.g., lint, test).
 	// Example: ["make lint", "make test"] or ["go vet ./...", "go test ./..."]
 	// If empty, defaults to ["make lint", "make test"].
@@ -94,6 +108,186 @@ type RepoConfig struct {
 	// format all modified files (e.g. "npm run format", "ruff format .").
 	// If empty, no batch formatting is performed.
 	FormatCommand string `yaml:"format_command"`
+
+	// DisableSecretRedaction turns off the scan that replaces likely secrets
+	// (API keys, private keys, JWTs, connection strings) in the diff and
+	// retrieved context with placeholders before prompts reach the LLM.
+	DisableSecretRedaction bool `yaml:"disable_secret_redaction"`
+
+	// LocalOnly refuses to index or review this repository when any
+	// configured AI provider would send code to an external API.
+	LocalOnly bool `yaml:"local_only"`
+
+	// ConsensusModels overrides the server's ai.comparison_models for this
+	// repository. Example: ["qwen3-coder:30b", "deepseek-r1:32b"]
+	ConsensusModels []string `yaml:"consensus_models"`
+
+	// DisableConsensus forces single-model reviews for this repository even
+	// when the server has consensus models configured.
+	DisableConsensus bool `yaml:"disable_consensus"`
+
+	// EmbedderModel selects the embedder model this repository is indexed
+	// and searched with instead of the server's ai.embedder_model. It takes
+	// effect on the first index; an existing index is moved to another
+	// model with "warden-cli vector migrate".
+	EmbedderModel string `yaml:"embedder_model"`
+
+	// MinSeverity drops review suggestions below this severity ("Low",
+	// "Medium", "High" or "Critical"). Empty keeps all suggestions.
+	MinSeverity string `yaml:"min_severity"`
+
+	// MaxDiffKB lowers the server's limits.max_diff_kb: files of a larger
+	// diff that do not fit are left out of the review and listed in its
+	// summary.
+	MaxDiffKB int `yaml:"max_diff_kb"`
+
+	// Rules focus the review on specific paths, CODEOWNERS-style: each rule
+	// adds instructions for matching changed files and may override
+	// MinSeverity for them.
+	Rules []PathRule `yaml:"rules"`
+
+	// SubProjects divide a monorepo into parts with their own language,
+	// instructions and exclude lists. Reviews retrieve context from the
+	// sub-projects a pull request touches and summarize each of them.
+	SubProjects []SubProject `yaml:"sub_projects"`
+
+	// CommitHygiene checks the commit messages of the pull request and adds
+	// a "Commit hygiene" section to the review summary.
+	CommitHygiene CommitHygieneConfig `yaml:"commit_hygiene"`
+
+	// DisableMissingTests turns off the review stage that pairs changed files
+	// with their tests and suggests test cases for those left unchanged.
+	DisableMissingTests bool `yaml:"disable_missing_tests"`
+
+	// DisableDependencyCheck turns off the vulnerability and license lookups
+	// for dependencies added or upgraded in manifest files.
+	DisableDependencyCheck bool `yaml:"disable_dependency_check"`
+
+	// DisableStaticAnalysis turns off the linters run on the head of the
+	// pull request when the server enables them.
+	DisableStaticAnalysis bool `yaml:"disable_static_analysis"`
+
+	// Retrieval overrides the server's ai.retrieval settings per review
+	// profile. Values are clamped to the server's safe bounds.
+	// Example: {thorough: {docs_per_query: 20, rerank_top_k: 10}}
+	Retrieval RetrievalModes `yaml:"retrieval"`
+
+	// Indexing controls which files are skipped when the repository is
+	// embedded.
+	Indexing IndexingConfig `yaml:"indexing"`
+}
+
+// PathFilter returns the filter of the include and exclude patterns, or nil
+// when there are none. ignore holds the patterns of the repository's ignore
+// files, which apply before Exclude, so "!pattern" there re-includes files
+// they leave out.
+func (c *RepoConfig) PathFilter(ignore []string) *PathFilter {
+	if c == nil {
+		return NewPathFilter(nil, ignore)
+	}
+	return NewPathFilter(c.Include, slices.Concat(ignore, c.Exclude))
+}
+
+// IndexingConfig selects the files left out of the repository index besides
+// the exclude_* lists. Generated code, binary content and files larger than
+// MaxFileSizeKB are skipped by default.
+type IndexingConfig struct {
+	// IncludeGenerated indexes generated code such as *.pb.go files or files
+	// with a "Code generated ... DO NOT EDIT." header.
+	IncludeGenerated bool `yaml:"include_generated"`
+
+	// GeneratedPatterns are additional glob patterns of generated files,
+	// matched like rule paths. Example: ["*_mock.go", "api/client/**"]
+	GeneratedPatterns []string `yaml:"generated_patterns"`
+
+	// MaxFileSizeKB skips files larger than this many kilobytes. Defaults to
+	// 1024 when zero; the server's limits.max_file_size_kb caps it.
+	MaxFileSizeKB int `yaml:"max_file_size_kb"`
+
+	// MaxFiles lowers the server's limits.max_files, the number of files
+	// kept in the index.
+	MaxFiles int `yaml:"max_files"`
+
+	// DisableGitignore indexes files that .gitignore files, at the root and
+	// in subdirectories, and .git/info/exclude list. Patterns in
+	// .wardenignore still apply; the user's global excludes file is never
+	// read.
+	DisableGitignore bool `yaml:"disable_gitignore"`
+}
+
+// EffectiveLimit returns the lower of a repository and a server limit, where
+// zero means unset: repositories can tighten the server's limits but not
+// loosen them.
+func EffectiveLimit(repo, server int) int {
+	switch {
+	case repo <= 0:
+		return max(server, 0)
+	case server <= 0:
+		return repo
+	default:
+		return min(repo, server)
+	}
+}
+
+// CommitHygieneConfig selects the commit message conventions a review checks.
+type CommitHygieneConfig struct {
+	// Enabled turns the commit message checks on.
+	Enabled bool `yaml:"enabled"`
+
+	// Conventional requires Conventional Commits subjects such as
+	// "feat(api): add pagination".
+	Conventional bool `yaml:"conventional"`
+
+	// Types are the Conventional Commits types allowed. Empty allows the
+	// common set (feat, fix, docs, refactor, ...).
+	Types []string `yaml:"types"`
+
+	// MaxSubjectLength limits the first line of a message. Defaults to 72
+	// when zero.
+	MaxSubjectLength int `yaml:"max_subject_length"`
+
+	// SubjectPattern is a regular expression every subject must match, e.g.
+	// "^[A-Z]+-[0-9]+ " to require a ticket reference.
+	SubjectPattern string `yaml:"subject_pattern"`
+}
+
+// PathRule applies review instructions and a severity threshold to files
+// matching any of its patterns.
+type PathRule struct {
+	// Paths are glob patterns relative to the repository root. ** matches any
+	// number of directories and patterns without a slash match base names.
+	// Example: ["internal/auth/**", "*.sql"]
+	Paths []string `yaml:"paths"`
+
+	// Instructions are added to the prompt when a changed file matches.
+	Instructions []string `yaml:"instructions"`
+
+	// MinSeverity overrides RepoConfig.MinSeverity for matching files.
+	MinSeverity string `yaml:"min_severity"`
+}
+
+// Matches reports whether filePath matches any of the rule's patterns.
+func (r PathRule) Matches(filePath string) bool {
+	filePath = strings.TrimPrefix(filePath, "./")
+	for _, pattern := range r.Paths {
+		if MatchGlob(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// MinSeverityFor returns the minimum severity to report for filePath. As in
+// CODEOWNERS, the last matching rule that sets min_severity wins; without one,
+// MinSeverity applies.
+func (c *RepoConfig) MinSeverityFor(filePath string) string {
+	minSeverity := c.MinSeverity
+	for _, rule := range c.Rules {
+		if rule.MinSeverity != "" && rule.Matches(filePath) {
+			minSeverity = rule.MinSeverity
+		}
+	}
+	return minSeverity
 }
 
 // DefaultRepoConfig returns a config with default values.
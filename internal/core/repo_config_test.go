@@ -136,3 +136,31 @@ func TestDefaultRepoConfig(t *testing.T) {
 	assert.Empty(t, cfg.ExcludeExts)
 	assert.Empty(t, cfg.ExcludeFiles)
 }
+
+func TestRepoConfigMinSeverityFor(t *testing.T) {
+	cfg := &RepoConfig{
+		MinSeverity: "Medium",
+		Rules: []PathRule{
+			{Paths: []string{"internal/**"}, MinSeverity: "High"},
+			{Paths: []string{"internal/auth/**"}, MinSeverity: "Low"},
+			{Paths: []string{"*.sql"}, Instructions: []string{"Check for missing indexes"}},
+		},
+	}
+
+	assert.Equal(t, "Medium", cfg.MinSeverityFor("cmd/main.go"))
+	assert.Equal(t, "High", cfg.MinSeverityFor("internal/api/handler.go"))
+	assert.Equal(t, "Low", cfg.MinSeverityFor("internal/auth/token.go"), "the last matching rule wins")
+	assert.Equal(t, "Low", cfg.MinSeverityFor("./internal/auth/token.go"))
+	assert.Equal(t, "High", cfg.MinSeverityFor("internal/db/schema.sql"), "rules without min_severity don't override")
+
+	assert.True(t, cfg.Rules[2].Matches("migrations/001_init.sql"))
+	assert.False(t, cfg.Rules[1].Matches("internal/authz/policy.go"))
+}
+
+func TestEffectiveLimit(t *testing.T) {
+	assert.Equal(t, 1024, EffectiveLimit(0, 1024), "the server limit applies by default")
+	assert.Equal(t, 256, EffectiveLimit(256, 1024), "repositories can lower it")
+	assert.Equal(t, 1024, EffectiveLimit(4096, 1024), "but not raise it")
+	assert.Equal(t, 4096, EffectiveLimit(4096, 0))
+	assert.Equal(t, 0, EffectiveLimit(0, 0))
+}
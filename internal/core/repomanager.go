@@ -24,6 +24,10 @@ type UpdateResult struct {
 	// This is what gets persisted as LastIndexedSHA in the database.
 	DefaultBranchSHA string
 
+	// TreeSHA is the tree of DefaultBranchSHA, the exact file contents that
+	// reviews of this sync read. Empty when it could not be resolved.
+	TreeSHA string
+
 	// DefaultBranchChanged is true when the default branch advanced since the
 	// last indexed SHA, meaning the Qdrant collection must be updated.
 	DefaultBranchChanged bool
@@ -0,0 +1,105 @@
+package core
+
+import "slices"
+
+// RetrievalSettings controls how much repository context a review retrieves.
+// Zero values fall back to the built-in defaults.
+type RetrievalSettings struct {
+	// DocsPerQuery is the number of documents kept per search query. HyDE
+	// fetches twice as many as candidates for reranking.
+	DocsPerQuery int `yaml:"docs_per_query" mapstructure:"docs_per_query" json:"docs_per_query,omitempty"`
+	// RerankTopK is the number of documents kept per changed file after
+	// reranking.
+	RerankTopK int `yaml:"rerank_top_k" mapstructure:"rerank_top_k" json:"rerank_top_k,omitempty"`
+	// ContextTokens is the token budget of the packed context.
+	ContextTokens int `yaml:"context_tokens" mapstructure:"context_tokens" json:"context_tokens,omitempty"`
+	// HyDE turns the generation of hypothetical code for changed files on
+	// or off. Nil uses the server's ai.enable_hyde.
+	HyDE *bool `yaml:"hyde" mapstructure:"hyde" json:"hyde,omitempty"`
+	// HyDEMaxFiles is the number of changed files, largest patches first,
+	// that HyDE generates code for.
+	HyDEMaxFiles int `yaml:"hyde_max_files" mapstructure:"hyde_max_files" json:"hyde_max_files,omitempty"`
+	// RecallSize is the number of candidates HyDE fetches per query for the
+	// BM25 pre-filter and the reranker. Zero fetches twice DocsPerQuery.
+	RecallSize int `yaml:"recall_size" mapstructure:"recall_size" json:"recall_size,omitempty"`
+	// ScoreThreshold is the minimum similarity of a retrieved document,
+	// between 0 and 1. Zero uses the server's ai.retrieval_score_threshold.
+	ScoreThreshold float32 `yaml:"score_threshold" mapstructure:"score_threshold" json:"score_threshold,omitempty"`
+	// ExcludeChunkTypes are chunk types left out of the context, e.g. "arch"
+	// or "toc". Test files are always left out of the similarity searches.
+	ExcludeChunkTypes []string `yaml:"exclude_chunk_types" mapstructure:"exclude_chunk_types" json:"exclude_chunk_types,omitempty"`
+	// Scope limits the retrieved code to these directories, the sub-projects
+	// a pull request touches. Empty retrieves from the whole repository. It
+	// is set per review, not configured.
+	Scope []string `yaml:"-" mapstructure:"-" json:"scope,omitempty"`
+}
+
+// HyDEEnabled reports whether HyDE is on, using def when HyDE is unset.
+func (s RetrievalSettings) HyDEEnabled(def bool) bool {
+	if s.HyDE == nil {
+		return def
+	}
+	return *s.HyDE
+}
+
+// RetrievalModes holds RetrievalSettings per review profile.
+type RetrievalModes struct {
+	Quick    RetrievalSettings `yaml:"quick" mapstructure:"quick"`
+	Standard RetrievalSettings `yaml:"standard" mapstructure:"standard"`
+	Thorough RetrievalSettings `yaml:"thorough" mapstructure:"thorough"`
+}
+
+// For returns the settings of profile. Unknown profiles use Standard.
+func (m RetrievalModes) For(profile ReviewProfile) RetrievalSettings {
+	switch profile {
+	case ProfileQuick:
+		return m.Quick
+	case ProfileThorough:
+		return m.Thorough
+	default:
+		return m.Standard
+	}
+}
+
+// Override returns s with the non-zero fields of o applied.
+func (s RetrievalSettings) Override(o RetrievalSettings) RetrievalSettings {
+	if o.DocsPerQuery != 0 {
+		s.DocsPerQuery = o.DocsPerQuery
+	}
+	if o.RerankTopK != 0 {
+		s.RerankTopK = o.RerankTopK
+	}
+	if o.ContextTokens != 0 {
+		s.ContextTokens = o.ContextTokens
+	}
+	if o.HyDE != nil {
+		s.HyDE = o.HyDE
+	}
+	if o.HyDEMaxFiles != 0 {
+		s.HyDEMaxFiles = o.HyDEMaxFiles
+	}
+	if o.RecallSize != 0 {
+		s.RecallSize = o.RecallSize
+	}
+	if o.ScoreThreshold != 0 {
+		s.ScoreThreshold = o.ScoreThreshold
+	}
+	if o.ExcludeChunkTypes != nil {
+		s.ExcludeChunkTypes = o.ExcludeChunkTypes
+	}
+	return s
+}
+
+// ExcludesChunkType reports whether chunks of chunkType are left out.
+func (s RetrievalSettings) ExcludesChunkType(chunkType string) bool {
+	return slices.Contains(s.ExcludeChunkTypes, chunkType)
+}
+
+// RetrievalRecord is the retrieval a review ran with, kept so the review can
+// be reproduced.
+type RetrievalRecord struct {
+	// Profile is the profile estimated from the diff before retrieval. The
+	// final review profile may be higher once the impact radius is known.
+	Profile ReviewProfile `json:"profile"`
+	RetrievalSettings
+}
@@ -28,16 +28,50 @@ type Review struct {
 	HeadSHA string `db:"head_sha"`
 	// ReviewContent is the raw content of the review generated by the LLM.
 	ReviewContent string `db:"review_content"`
+	// Degraded reports that the review was written without repository
+	// context because the vector store was unavailable.
+	Degraded bool `db:"degraded"`
+	// TreeSHA is the git tree of the repository snapshot that retrieval and
+	// prompt assembly read, so the review can be reproduced against the same
+	// file contents. Empty for reviews without a snapshot.
+	TreeSHA string `db:"tree_sha"`
+	// PromptVersion identifies the prompt template the review was written
+	// with, so that reviews of a prompt experiment can be told apart.
+	PromptVersion string `db:"prompt_version"`
 	// CreatedAt is the timestamp when the review was created.
 	CreatedAt time.Time `db:"created_at"`
 }
 
+// PromptInputs are the inputs a review prompt was rendered from. They are
+// archived with the review, so that it can be generated again with another
+// model or prompt from the same diff and retrieved context.
+type PromptInputs struct {
+	// Group names the diff group of a split review.
+	Group string `json:"group,omitempty"`
+	// PromptKey and PromptVersion identify the prompt template.
+	PromptKey     string `json:"prompt_key"`
+	PromptVersion string `json:"prompt_version"`
+	// Model is the model the prompt was sent to; for consensus reviews the
+	// comma-separated list of models.
+	Model         string `json:"model"`
+	ReviewProfile string `json:"review_profile,omitempty"`
+	// Data is the template data: the redacted diff, the retrieved context
+	// and definitions, instructions and the output format.
+	Data map[string]string `json:"data"`
+	// Output is the raw answer the review was parsed from.
+	Output string `json:"output"`
+}
+
 // ReReviewData is a type-safe struct for rendering re-review prompts.
 // It contains all the context needed for the LLM to perform a follow-up
 // review of changes since a previous review was generated.
 type ReReviewData struct {
-	// Language is the programming language of the repository.
+	// Language is the primary language of the changed files, or of the
+	// repository when none is known.
 	Language string
+	// Languages is the breakdown of the languages of the changed files when
+	// there is more than one, e.g. "Go (3 files, 120 lines), SQL (1 file, 8 lines)".
+	Languages string
 	// OriginalReview is the content of the previous review being followed up on.
 	OriginalReview string
 	// NewDiff contains the code changes since the original review.
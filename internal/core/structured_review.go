@@ -3,6 +3,50 @@
 // allowing for flexible and decoupled implementations of the application's logic.
 package core
 
+import (
+	"strconv"
+	"strings"
+)
+
+// Severities lists the suggestion severities from lowest to highest.
+var Severities = []string{"Low", "Medium", "High", "Critical"}
+
+// SeverityRank returns the rank of severity, from 1 for "Low" to 4 for
+// "Critical", matched case-insensitively. Unknown severities rank 0.
+func SeverityRank(severity string) int {
+	for i, s := range Severities {
+		if strings.EqualFold(s, severity) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// NormalizeCWE returns id in canonical "CWE-<n>" form. It accepts the forms
+// models commonly produce, such as "cwe-89", "CWE 89" and "89", and returns
+// an empty string for anything else.
+func NormalizeCWE(id string) string {
+	id = strings.TrimSpace(id)
+	if len(id) >= 3 && strings.EqualFold(id[:3], "CWE") {
+		id = strings.TrimLeft(id[3:], "-: ")
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil || n <= 0 {
+		return ""
+	}
+	return "CWE-" + strconv.Itoa(n)
+}
+
+// CWEURL returns the MITRE page of a CWE identifier, or an empty string if
+// id is not a valid identifier.
+func CWEURL(id string) string {
+	id = NormalizeCWE(id)
+	if id == "" {
+		return ""
+	}
+	return "https://cwe.mitre.org/data/definitions/" + strings.TrimPrefix(id, "CWE-") + ".html"
+}
+
 // Suggestion represents a single piece of feedback for a specific line of code.
 // It contains the location, severity, and description of a potential issue,
 // along with optional code suggestions for fixing the problem.
@@ -19,6 +63,9 @@ type Suggestion struct {
 	// Category classifies the type of issue.
 	// Common values are "Best Practice", "Bug", "Style", and "Security".
 	Category string `json:"category" xml:"category"`
+	// CWE is the Common Weakness Enumeration identifier of a security
+	// finding in "CWE-<n>" form, or empty.
+	CWE string `json:"cwe,omitempty" xml:"cwe,omitempty"`
 	// Comment is the human-readable description of the issue and its context.
 	Comment string `json:"comment" xml:"comment"`
 	// Confidence is the LLM's confidence score for this suggestion (0-100).
@@ -30,6 +77,10 @@ type Suggestion struct {
 	// Source is the citation for where this finding originated (anti-hallucination grounding).
 	// Format: "diff:L{line}", "context:{file}:{line}", "inference:{type}", or "external:{description}"
 	Source string `json:"source,omitempty" xml:"source,omitempty"`
+	// Origin names the static analysis tool and rule that reported the
+	// finding, e.g. "golangci-lint:errcheck", or is empty for findings of the
+	// model alone. This is Go-computed metadata, not LLM output.
+	Origin string `json:"origin,omitempty" xml:"-"`
 }
 
 // StructuredReview represents the complete output from the LLM in a structured,
@@ -46,6 +97,9 @@ type StructuredReview struct {
 	Confidence int `json:"confidence,omitempty" xml:"confidence,omitempty"`
 	// Suggestions is a list of specific code review feedback items.
 	Suggestions []Suggestion `json:"suggestions" xml:"suggestions>suggestion"`
+	// ModelRatings holds the synthesizer's rating of each individual model
+	// review. It is only populated for consensus reviews.
+	ModelRatings []ModelRating `json:"model_ratings,omitempty" xml:"model_ratings>rating,omitempty"`
 	// ReviewProfile is the computed profile for this review (quick/standard/thorough).
 	// This is Go-computed metadata, not LLM output.
 	ReviewProfile string `json:"review_profile,omitempty"`
@@ -55,6 +109,158 @@ type StructuredReview struct {
 	// ImpactRadius is the number of dependent files affected by this change.
 	// This is Go-computed metadata, not LLM output.
 	ImpactRadius int `json:"impact_radius,omitempty"`
+	// Model is the model (or "consensus") that produced the review.
+	// This is Go-computed metadata, not LLM output.
+	Model string `json:"model,omitempty"`
+	// PromptVersion identifies the prompt template revision used for the review.
+	// This is Go-computed metadata, not LLM output.
+	PromptVersion string `json:"prompt_version,omitempty"`
+	// CommitHygiene holds the results of the commit message checks when the
+	// repository enables them. This is Go-computed metadata, not LLM output.
+	CommitHygiene *CommitHygiene `json:"commit_hygiene,omitempty"`
+	// MissingTests lists changed files whose tests were not changed, with
+	// suggested test cases. It is filled by a separate review stage.
+	MissingTests []MissingTest `json:"missing_tests,omitempty"`
+	// Dependencies holds the vulnerability and license lookups for the
+	// dependencies the pull request adds or upgrades. This is Go-computed
+	// metadata, not LLM output.
+	Dependencies *DependencyReport `json:"dependencies,omitempty"`
+	// SubProjects summarizes the review per monorepo sub-project the pull
+	// request touches. This is Go-computed metadata, not LLM output.
+	SubProjects []SubProjectSummary `json:"sub_projects,omitempty"`
+	// Retrieval records the retrieval depth and context size the review ran
+	// with. This is Go-computed metadata, not LLM output.
+	Retrieval *RetrievalRecord `json:"retrieval,omitempty"`
+	// Degraded reports that the vector store was unavailable and the review
+	// was written from the diff alone. This is Go-computed metadata, not LLM
+	// output.
+	Degraded bool `json:"degraded,omitempty"`
+	// Delta compares the findings with those of the previous review of the
+	// pull request, when there is one. This is Go-computed metadata, not LLM
+	// output.
+	Delta *ReviewDelta `json:"delta,omitempty"`
+	// Inputs are the prompts the review was generated from, archived with
+	// the saved review. They are not part of the review output.
+	Inputs []PromptInputs `json:"-" xml:"-"`
+}
+
+// ReviewDelta sorts the findings of a review of new commits against the
+// previous review of the pull request.
+type ReviewDelta struct {
+	// PreviousSHA is the commit the previous review was of.
+	PreviousSHA string `json:"previous_sha"`
+	// New are the findings the previous review did not report.
+	New []Suggestion `json:"new"`
+	// Open are the findings of the previous review that are still reported,
+	// at their current location.
+	Open []Suggestion `json:"open"`
+	// Resolved are the findings of the previous review no longer reported.
+	Resolved []Suggestion `json:"resolved"`
+}
+
+// DependencyReport summarizes the dependency changes of a review.
+type DependencyReport struct {
+	// Checked is the number of added or upgraded dependencies looked up.
+	Checked int `json:"checked"`
+	// Findings lists the dependencies with known vulnerabilities or flagged
+	// licenses, in diff order.
+	Findings []DependencyFinding `json:"findings,omitempty"`
+}
+
+// DependencyFinding describes an added or upgraded dependency that needs
+// attention.
+type DependencyFinding struct {
+	// Ecosystem is the OSV ecosystem, e.g. "Go", "npm" or "PyPI".
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	// PreviousVersion is set when the dependency was upgraded or downgraded.
+	PreviousVersion string `json:"previous_version,omitempty"`
+	// File is the manifest that declares the dependency.
+	File            string          `json:"file"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+	// Licenses are the SPDX expressions that matched the flagged licenses.
+	Licenses []string `json:"licenses,omitempty"`
+}
+
+// Vulnerability is a known vulnerability affecting a dependency version.
+type Vulnerability struct {
+	// ID is the OSV identifier, e.g. "GHSA-35jh-r3h4-6jhm".
+	ID string `json:"id"`
+	// Aliases are other identifiers of the vulnerability, such as CVEs.
+	Aliases []string `json:"aliases,omitempty"`
+	Summary string   `json:"summary,omitempty"`
+}
+
+// LintFinding is a problem a linter reported on the head of a pull request.
+type LintFinding struct {
+	// Tool is the linter, e.g. "golangci-lint".
+	Tool string `json:"tool"`
+	// Rule identifies the check within the tool, e.g. "errcheck" or "F401".
+	Rule     string `json:"rule,omitempty"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column,omitempty"`
+	// Severity is "Low" for warnings and "Medium" for errors.
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Origin returns the origin tag of suggestions made from f.
+func (f LintFinding) Origin() string {
+	if f.Rule == "" {
+		return f.Tool
+	}
+	return f.Tool + ":" + f.Rule
+}
+
+// SubProjectSummary counts the changed files and the suggestions of a review
+// in one sub-project.
+type SubProjectSummary struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Files int    `json:"files"`
+	// Suggestions counts the suggestions by severity.
+	Suggestions map[string]int `json:"suggestions,omitempty"`
+}
+
+// MissingTest suggests tests for a changed file whose tests the pull request
+// does not change.
+type MissingTest struct {
+	// File is the changed production file.
+	File string `json:"file"`
+	// TestFile is the test file the cases belong in.
+	TestFile string `json:"test_file"`
+	// Exists reports whether TestFile already exists in the repository.
+	Exists bool `json:"exists"`
+	// Cases are the suggested test cases, one sentence each.
+	Cases []string `json:"cases"`
+}
+
+// CommitHygiene summarizes the commit message checks of a review.
+type CommitHygiene struct {
+	// Commits is the number of commits checked.
+	Commits int `json:"commits"`
+	// Issues lists the commits with problems, in commit order.
+	Issues []CommitIssue `json:"issues,omitempty"`
+}
+
+// CommitIssue lists the problems found in one commit message.
+type CommitIssue struct {
+	// Subject is the first line of the commit message.
+	Subject string `json:"subject"`
+	// Problems describe what does not follow the conventions.
+	Problems []string `json:"problems"`
+}
+
+// ModelRating is the consensus synthesizer's assessment of one model's review.
+type ModelRating struct {
+	// Model is the name of the rated model.
+	Model string `json:"model" xml:"model"`
+	// Score rates the usefulness and accuracy of the model's review (1-10).
+	Score int `json:"score" xml:"score"`
+	// Rationale briefly explains the score.
+	Rationale string `json:"rationale,omitempty" xml:"rationale,omitempty"`
 }
 
 // ReReviewResult represents the expected structured output from the LLM
@@ -65,3 +271,18 @@ type ReReviewResult struct {
 	// Summary is a high-level overview of the re-review findings.
 	Summary string `json:"summary"`
 }
+
+// SuggestionThread is a posted inline suggestion that a "/warden explain" or
+// "/warden fix" reply asks about.
+type SuggestionThread struct {
+	// Path is the file the suggestion is on.
+	Path string
+	// StartLine and Line are the lines the suggestion covers; StartLine is
+	// zero for a single line.
+	StartLine int
+	Line      int
+	// Suggestion is the text of the posted comment.
+	Suggestion string
+	// DiffHunk is the diff up to and including Line.
+	DiffHunk string
+}
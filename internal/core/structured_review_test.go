@@ -0,0 +1,33 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeverityRank(t *testing.T) {
+	assert.Equal(t, 1, SeverityRank("Low"))
+	assert.Equal(t, 3, SeverityRank("high"))
+	assert.Equal(t, 4, SeverityRank("CRITICAL"))
+	assert.Equal(t, 0, SeverityRank("Nitpick"))
+	assert.Equal(t, 0, SeverityRank(""))
+}
+
+func TestNormalizeCWE(t *testing.T) {
+	for in, want := range map[string]string{
+		"CWE-89":  "CWE-89",
+		"cwe-089": "CWE-89",
+		"CWE 79":  "CWE-79",
+		"cwe:22":  "CWE-22",
+		" 918 ":   "CWE-918",
+		"":        "",
+		"CWE-":    "",
+		"CWE-0":   "",
+		"OWASP-1": "",
+	} {
+		assert.Equal(t, want, NormalizeCWE(in), in)
+	}
+	assert.Equal(t, "https://cwe.mitre.org/data/definitions/89.html", CWEURL("cwe-89"))
+	assert.Empty(t, CWEURL("none"))
+}
@@ -0,0 +1,183 @@
+package core
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/pathutil"
+)
+
+// SubProject is a part of a monorepo below a path prefix with conventions of
+// its own. Its settings apply in addition to those of the repository.
+type SubProject struct {
+	// Name identifies the sub-project in review summaries. Defaults to Path.
+	Name string `yaml:"name"`
+
+	// Path is the directory of the sub-project relative to the repository
+	// root. Example: "services/billing"
+	Path string `yaml:"path"`
+
+	// Language is the main language of the sub-project, told to the LLM
+	// for its files. Example: "Go"
+	Language string `yaml:"language"`
+
+	// CustomInstructions are added to the prompt when the pull request
+	// changes files of the sub-project.
+	CustomInstructions []string `yaml:"custom_instructions"`
+
+	// ExcludeDirs, ExcludeExts and ExcludeFiles leave files of the
+	// sub-project out of the index, like the repository-wide lists.
+	// ExcludeFiles are relative to Path.
+	ExcludeDirs  []string `yaml:"exclude_dirs"`
+	ExcludeExts  []string `yaml:"exclude_exts"`
+	ExcludeFiles []string `yaml:"exclude_files"`
+
+	// ContextPaths are further directories, such as shared libraries, whose
+	// code is retrieved as context for changes in the sub-project.
+	ContextPaths []string `yaml:"context_paths"`
+}
+
+// DisplayName returns Name, or Path when the sub-project has no name.
+func (p SubProject) DisplayName() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return pathutil.Clean(p.Path)
+}
+
+// Contains reports whether the repository-relative file lies in the
+// sub-project.
+func (p SubProject) Contains(file string) bool {
+	return p.Path != "" && pathutil.Within(file, p.Path)
+}
+
+// Excludes reports whether file, a file of the sub-project, is left out of
+// the index by the sub-project's exclude lists.
+func (p SubProject) Excludes(file string) bool {
+	rel := strings.TrimPrefix(pathutil.Clean(file), pathutil.Clean(p.Path)+"/")
+	dir, _ := path.Split(rel)
+	for _, part := range strings.Split(strings.TrimSuffix(dir, "/"), "/") {
+		if part != "" && slices.Contains(p.ExcludeDirs, part) {
+			return true
+		}
+	}
+	ext := strings.TrimPrefix(strings.ToLower(path.Ext(rel)), ".")
+	for _, excluded := range p.ExcludeExts {
+		if ext != "" && strings.TrimPrefix(strings.ToLower(excluded), ".") == ext {
+			return true
+		}
+	}
+	for _, excluded := range p.ExcludeFiles {
+		if pathutil.Clean(excluded) == rel {
+			return true
+		}
+	}
+	return false
+}
+
+// SubProjectFor returns the sub-project file belongs to, the one with the
+// longest path when sub-projects are nested, or nil.
+func (c *RepoConfig) SubProjectFor(file string) *SubProject {
+	var found *SubProject
+	for i := range c.SubProjects {
+		p := &c.SubProjects[i]
+		if p.Contains(file) && (found == nil || len(pathutil.Clean(p.Path)) > len(pathutil.Clean(found.Path))) {
+			found = p
+		}
+	}
+	return found
+}
+
+// SubProjectFiles groups files by the sub-project they belong to. Groups
+// follow the order of the configuration; files outside every sub-project
+// are returned separately.
+func (c *RepoConfig) SubProjectFiles(files []string) (groups []SubProjectGroup, outside []string) {
+	byProject := make(map[*SubProject][]string)
+	for _, file := range files {
+		p := c.SubProjectFor(file)
+		if p == nil {
+			outside = append(outside, file)
+			continue
+		}
+		byProject[p] = append(byProject[p], file)
+	}
+	for i := range c.SubProjects {
+		p := &c.SubProjects[i]
+		if files, ok := byProject[p]; ok {
+			groups = append(groups, SubProjectGroup{SubProject: p, Files: files})
+		}
+	}
+	return groups, outside
+}
+
+// SubProjectGroup is a sub-project and the changed files in it.
+type SubProjectGroup struct {
+	SubProject *SubProject
+	Files      []string
+}
+
+// RetrievalScope returns the directories context for a change of files is
+// retrieved from: the sub-projects they touch and their context paths. It
+// returns nil, the whole repository, when a file lies outside every
+// sub-project, since root-level changes may affect any of them.
+func (c *RepoConfig) RetrievalScope(files []string) []string {
+	groups, outside := c.SubProjectFiles(files)
+	if len(groups) == 0 || len(outside) > 0 {
+		return nil
+	}
+	var scope []string
+	for _, g := range groups {
+		for _, dir := range append([]string{g.SubProject.Path}, g.SubProject.ContextPaths...) {
+			if dir = pathutil.Clean(dir); !slices.Contains(scope, dir) {
+				scope = append(scope, dir)
+			}
+		}
+	}
+	return scope
+}
+
+// InScope reports whether the repository-relative file lies in one of the
+// directories of scope. Every file is in an empty scope.
+func InScope(file string, scope []string) bool {
+	if len(scope) == 0 {
+		return true
+	}
+	for _, dir := range scope {
+		if pathutil.Within(file, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// SummarizeSubProjects returns the summary of each sub-project with changed
+// files, counting the suggestions on its files by severity, or nil when the
+// repository has no sub-projects or none is touched.
+func (c *RepoConfig) SummarizeSubProjects(changedFiles []string, suggestions []Suggestion) []SubProjectSummary {
+	groups, _ := c.SubProjectFiles(changedFiles)
+	if len(groups) == 0 {
+		return nil
+	}
+	summaries := make([]SubProjectSummary, len(groups))
+	index := make(map[*SubProject]int, len(groups))
+	for i, g := range groups {
+		summaries[i] = SubProjectSummary{
+			Name:  g.SubProject.DisplayName(),
+			Path:  pathutil.Clean(g.SubProject.Path),
+			Files: len(g.Files),
+		}
+		index[g.SubProject] = i
+	}
+	for _, s := range suggestions {
+		i, ok := index[c.SubProjectFor(s.FilePath)]
+		if !ok {
+			continue
+		}
+		if summaries[i].Suggestions == nil {
+			summaries[i].Suggestions = make(map[string]int)
+		}
+		summaries[i].Suggestions[s.Severity]++
+	}
+	return summaries
+}
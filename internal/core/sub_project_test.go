@@ -0,0 +1,70 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func monorepoConfig() *RepoConfig {
+	return &RepoConfig{
+		SubProjects: []SubProject{
+			{Name: "services", Path: "services"},
+			{Name: "billing", Path: "services/billing/", ContextPaths: []string{"libs/money"}},
+			{Path: "web", ExcludeDirs: []string{"fixtures"}, ExcludeExts: []string{"snap"}, ExcludeFiles: []string{"src/legacy.js"}},
+		},
+	}
+}
+
+func TestSubProjectFor(t *testing.T) {
+	c := monorepoConfig()
+
+	require.NotNil(t, c.SubProjectFor("services/billing/invoice.go"))
+	assert.Equal(t, "billing", c.SubProjectFor("services/billing/invoice.go").Name, "the nested sub-project wins")
+	assert.Equal(t, "services", c.SubProjectFor("services/auth/login.go").Name)
+	assert.Equal(t, "web", c.SubProjectFor("web/src/app.ts").DisplayName())
+	assert.Nil(t, c.SubProjectFor("webhooks/handler.go"), "prefixes match whole directories")
+	assert.Nil(t, c.SubProjectFor("go.mod"))
+}
+
+func TestSubProjectExcludes(t *testing.T) {
+	web := monorepoConfig().SubProjects[2]
+
+	assert.True(t, web.Excludes("web/test/fixtures/user.json"))
+	assert.True(t, web.Excludes("web/src/__snapshots__/app.snap"))
+	assert.True(t, web.Excludes("web/src/legacy.js"))
+	assert.False(t, web.Excludes("web/src/app.ts"))
+	assert.False(t, web.Excludes("web/fixtures.ts"))
+}
+
+func TestRetrievalScope(t *testing.T) {
+	c := monorepoConfig()
+
+	assert.Equal(t, []string{"services/billing", "libs/money", "web"},
+		c.RetrievalScope([]string{"services/billing/invoice.go", "web/src/app.ts"}))
+	assert.Nil(t, c.RetrievalScope([]string{"services/billing/invoice.go", "Makefile"}), "root-level changes search the whole repository")
+	assert.Nil(t, (&RepoConfig{}).RetrievalScope([]string{"main.go"}))
+
+	assert.True(t, InScope("libs/money/money.go", []string{"services/billing", "libs/money"}))
+	assert.False(t, InScope("web/src/app.ts", []string{"services/billing"}))
+	assert.True(t, InScope("web/src/app.ts", nil))
+}
+
+func TestSummarizeSubProjects(t *testing.T) {
+	c := monorepoConfig()
+	summaries := c.SummarizeSubProjects(
+		[]string{"web/src/app.ts", "services/billing/invoice.go", "services/billing/tax.go", "README.md"},
+		[]Suggestion{
+			{FilePath: "services/billing/tax.go", Severity: "High"},
+			{FilePath: "services/billing/invoice.go", Severity: "High"},
+			{FilePath: "README.md", Severity: "Low"},
+		},
+	)
+
+	assert.Equal(t, []SubProjectSummary{
+		{Name: "billing", Path: "services/billing", Files: 2, Suggestions: map[string]int{"High": 2}},
+		{Name: "web", Path: "web", Files: 1},
+	}, summaries)
+	assert.Nil(t, (&RepoConfig{}).SummarizeSubProjects([]string{"main.go"}, nil))
+}
@@ -0,0 +1,30 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type reviewIDKey struct{}
+
+// NewReviewID returns a random ID that correlates the log records of one
+// job, from webhook intake to its result.
+func NewReviewID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithReviewID returns a context whose log records belong to the trace of
+// the review id. Like the usage scope, it travels with the context because
+// the records are written deep within the pipeline.
+func WithReviewID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, reviewIDKey{}, id)
+}
+
+// ReviewID returns the review ID of ctx, or "" when it has none.
+func ReviewID(ctx context.Context) string {
+	id, _ := ctx.Value(reviewIDKey{}).(string)
+	return id
+}
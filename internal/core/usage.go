@@ -0,0 +1,57 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Steps that LLM token usage is accounted to, besides the job kinds (see
+// ReviewType.String), which are the step of calls made outside these.
+const (
+	UsageStepConsensus     = "consensus"
+	UsageStepSynthesis     = "synthesis"
+	UsageStepHyDE          = "hyde"
+	UsageStepRetrieval     = "retrieval"
+	UsageStepInvestigation = "investigation"
+	UsageStepSummary       = "summary"
+)
+
+// LLMUsage is the token count of one LLM call.
+type LLMUsage struct {
+	ID int64 `db:"id"`
+	// RepoFullName and Step are what the call was made for; see
+	// WithUsageRepo and WithUsageStep.
+	RepoFullName string `db:"repo_full_name"`
+	Step         string `db:"step"`
+	Model        string `db:"model"`
+	// PromptTokens and CompletionTokens are taken from the provider's
+	// response, or estimated from the text when it reports none.
+	PromptTokens     int       `db:"prompt_tokens"`
+	CompletionTokens int       `db:"completion_tokens"`
+	Estimated        bool      `db:"estimated"`
+	CreatedAt        time.Time `db:"created_at"`
+}
+
+type usageRepoKey struct{}
+
+type usageStepKey struct{}
+
+// WithUsageRepo returns a context whose LLM calls are accounted to the
+// repository repoFullName. Like the review progress, it travels with the
+// context because the calls are made deep within the pipeline.
+func WithUsageRepo(ctx context.Context, repoFullName string) context.Context {
+	return context.WithValue(ctx, usageRepoKey{}, repoFullName)
+}
+
+// WithUsageStep returns a context whose LLM calls are accounted to step.
+func WithUsageStep(ctx context.Context, step string) context.Context {
+	return context.WithValue(ctx, usageStepKey{}, step)
+}
+
+// UsageScope returns the repository and step the LLM calls of ctx are
+// accounted to; either is empty when not set.
+func UsageScope(ctx context.Context) (repoFullName, step string) {
+	repoFullName, _ = ctx.Value(usageRepoKey{}).(string)
+	step, _ = ctx.Value(usageStepKey{}).(string)
+	return repoFullName, step
+}
@@ -0,0 +1,130 @@
+package cryptoutil
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sealedPrefix versions the format of sealed values:
+// "v1:<key id>:<base64 of nonce and ciphertext>".
+const sealedPrefix = "v1"
+
+// ErrUnknownKey is returned when a value was sealed with a key the keyring
+// does not hold.
+var ErrUnknownKey = errors.New("value was sealed with an unknown key")
+
+// Keyring seals values with AES-256-GCM under its primary key and opens
+// values sealed under the primary or any previous key, so that keys can be
+// rotated without losing what was sealed before.
+type Keyring struct {
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// ParseKey decodes a base64 (standard or URL encoding) 32-byte key.
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		if key, err = base64.URLEncoding.DecodeString(s); err != nil {
+			return nil, errors.New("key is not base64 encoded")
+		}
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key must be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+// NewKeyring creates a keyring that seals with primary and also opens values
+// sealed with previous keys.
+func NewKeyring(primary []byte, previous ...[]byte) (*Keyring, error) {
+	k := &Keyring{aeads: make(map[string]cipher.AEAD, 1+len(previous))}
+	for i, key := range append([][]byte{primary}, previous...) {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d: %w", i, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %d: %w", i, err)
+		}
+		id := KeyID(key)
+		if i == 0 {
+			k.primary = id
+		}
+		k.aeads[id] = aead
+	}
+	return k, nil
+}
+
+// KeyID identifies a key without revealing it: the first 8 hex characters
+// of its SHA-256 digest.
+func KeyID(key []byte) string {
+	h := sha256.Sum256(key)
+	return hex.EncodeToString(h[:4])
+}
+
+// PrimaryKeyID returns the id of the key new values are sealed with.
+func (k *Keyring) PrimaryKeyID() string {
+	return k.primary
+}
+
+// Seal encrypts plaintext under the primary key. The additional data, such
+// as the name of the value, is authenticated but not stored: Open must be
+// given the same, so that a sealed value cannot be moved to another name.
+func (k *Keyring) Seal(plaintext, additionalData []byte) (string, error) {
+	aead := k.aeads[k.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, additionalData)
+	return sealedPrefix + ":" + k.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value sealed by Seal under any key of the keyring.
+func (k *Keyring) Open(sealed string, additionalData []byte) ([]byte, error) {
+	id, data, err := splitSealed(sealed)
+	if err != nil {
+		return nil, err
+	}
+	aead, ok := k.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("%w %s", ErrUnknownKey, id)
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("sealed value is truncated")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sealed value: %w", err)
+	}
+	return plaintext, nil
+}
+
+// SealedKeyID returns the id of the key sealed was sealed with.
+func SealedKeyID(sealed string) (string, error) {
+	id, _, err := splitSealed(sealed)
+	return id, err
+}
+
+func splitSealed(sealed string) (string, []byte, error) {
+	parts := strings.SplitN(sealed, ":", 3)
+	if len(parts) != 3 || parts[0] != sealedPrefix {
+		return "", nil, errors.New("value is not sealed")
+	}
+	data, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, fmt.Errorf("sealed value is corrupt: %w", err)
+	}
+	return parts[1], data, nil
+}
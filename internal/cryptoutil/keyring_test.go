@@ -0,0 +1,53 @@
+package cryptoutil
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring_SealOpen(t *testing.T) {
+	oldKey, newKey := bytes.Repeat([]byte{1}, 32), bytes.Repeat([]byte{2}, 32)
+	old, err := NewKeyring(oldKey)
+	require.NoError(t, err)
+	sealed, err := old.Seal([]byte("ghs_token"), []byte("installation:1"))
+	require.NoError(t, err)
+	assert.NotContains(t, sealed, "ghs_token")
+	assert.True(t, strings.HasPrefix(sealed, "v1:"+KeyID(oldKey)+":"))
+
+	// After a rotation the old value still opens, and new values use the
+	// new key.
+	rotated, err := NewKeyring(newKey, oldKey)
+	require.NoError(t, err)
+	plaintext, err := rotated.Open(sealed, []byte("installation:1"))
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_token", string(plaintext))
+	resealed, err := rotated.Seal(plaintext, nil)
+	require.NoError(t, err)
+	id, err := SealedKeyID(resealed)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.PrimaryKeyID(), id)
+
+	_, err = rotated.Open(sealed, []byte("installation:2"))
+	require.Error(t, err, "additional data must match")
+	_, err = old.Open(resealed, nil)
+	require.ErrorIs(t, err, ErrUnknownKey)
+	_, err = old.Open("plain text", nil)
+	require.Error(t, err)
+}
+
+func TestParseKey(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+	parsed, err := ParseKey(" " + base64.StdEncoding.EncodeToString(key) + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed)
+
+	_, err = ParseKey(base64.StdEncoding.EncodeToString(key[:16]))
+	require.ErrorContains(t, err, "must be 32 bytes")
+	_, err = ParseKey("not base64!")
+	require.Error(t, err)
+}
@@ -0,0 +1,2 @@
+ALTER TABLE repositories DROP COLUMN IF EXISTS cold_snapshot_key;
+ALTER TABLE repositories DROP COLUMN IF EXISTS cold_since;
@@ -0,0 +1,2 @@
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS cold_snapshot_key TEXT NOT NULL DEFAULT '';
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS cold_since TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS review_feedback (
+    id             BIGSERIAL PRIMARY KEY,
+    repo_full_name TEXT NOT NULL,
+    pr_number      INTEGER NOT NULL,
+    comment_id     BIGINT NOT NULL,
+    file_path      TEXT NOT NULL DEFAULT '',
+    line           INTEGER NOT NULL DEFAULT 0,
+    severity       TEXT NOT NULL DEFAULT '',
+    category       TEXT NOT NULL DEFAULT '',
+    model          TEXT NOT NULL DEFAULT '',
+    prompt_version TEXT NOT NULL DEFAULT '',
+    user_login     TEXT NOT NULL,
+    source         TEXT NOT NULL,
+    signal         TEXT NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (comment_id, user_login, source)
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_feedback_repo ON review_feedback (repo_full_name);
+CREATE INDEX IF NOT EXISTS idx_review_feedback_model ON review_feedback (model, prompt_version);
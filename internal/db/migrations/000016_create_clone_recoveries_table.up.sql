@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS clone_recoveries (
+    id             BIGSERIAL PRIMARY KEY,
+    repo_full_name TEXT NOT NULL,
+    clone_path     TEXT NOT NULL,
+    reason         TEXT NOT NULL,
+    error          TEXT NOT NULL DEFAULT '',
+    succeeded      BOOLEAN NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_clone_recoveries_repo ON clone_recoveries (repo_full_name);
+CREATE INDEX IF NOT EXISTS idx_clone_recoveries_created_at ON clone_recoveries (created_at DESC);
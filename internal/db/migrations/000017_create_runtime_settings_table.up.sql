@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS runtime_settings (
+    key        TEXT PRIMARY KEY,
+    value      JSONB NOT NULL,
+    updated_by TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS settings_audit (
+    id         BIGSERIAL PRIMARY KEY,
+    key        TEXT NOT NULL,
+    old_value  JSONB,
+    new_value  JSONB,
+    actor      TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_settings_audit_created_at ON settings_audit (created_at DESC);
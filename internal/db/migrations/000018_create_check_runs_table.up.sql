@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS check_runs (
+    id              BIGINT PRIMARY KEY,
+    installation_id BIGINT NOT NULL,
+    repo_owner      TEXT NOT NULL,
+    repo_name       TEXT NOT NULL,
+    name            TEXT NOT NULL DEFAULT '',
+    head_sha        TEXT NOT NULL DEFAULT '',
+    status          TEXT NOT NULL DEFAULT 'in_progress',
+    conclusion      TEXT NOT NULL DEFAULT '',
+    started_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_check_runs_in_progress ON check_runs (started_at) WHERE status = 'in_progress';
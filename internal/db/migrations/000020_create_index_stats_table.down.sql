@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS index_stats;
+
+ALTER TABLE repository_files DROP COLUMN IF EXISTS chunk_count;
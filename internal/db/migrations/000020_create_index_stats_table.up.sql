@@ -0,0 +1,7 @@
+ALTER TABLE repository_files ADD COLUMN IF NOT EXISTS chunk_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS index_stats (
+    repository_id INTEGER PRIMARY KEY REFERENCES repositories(id) ON DELETE CASCADE,
+    stats         JSONB NOT NULL,
+    computed_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
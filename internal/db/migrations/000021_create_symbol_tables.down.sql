@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS symbol_references;
+DROP TABLE IF EXISTS symbol_definitions;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS symbol_definitions (
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    file_path     TEXT NOT NULL,
+    symbol        TEXT NOT NULL,
+    kind          TEXT NOT NULL DEFAULT '',
+    line_start    INTEGER NOT NULL DEFAULT 0,
+    line_end      INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (repository_id, file_path, symbol, line_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_symbol_definitions_symbol ON symbol_definitions (repository_id, symbol);
+
+CREATE TABLE IF NOT EXISTS symbol_references (
+    repository_id INTEGER NOT NULL REFERENCES repositories(id) ON DELETE CASCADE,
+    file_path     TEXT NOT NULL,
+    line          INTEGER NOT NULL DEFAULT 0,
+    symbol        TEXT NOT NULL,
+    PRIMARY KEY (repository_id, file_path, line, symbol)
+);
+
+CREATE INDEX IF NOT EXISTS idx_symbol_references_symbol ON symbol_references (repository_id, symbol);
@@ -0,0 +1 @@
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS tree_sha TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS review_inputs (
+    review_id  BIGINT PRIMARY KEY REFERENCES reviews(id) ON DELETE CASCADE,
+    inputs     JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_review_inputs_created_at ON review_inputs (created_at);
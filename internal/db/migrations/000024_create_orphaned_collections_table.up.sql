@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS orphaned_collections (
+    name          TEXT PRIMARY KEY,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
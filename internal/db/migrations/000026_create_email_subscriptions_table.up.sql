@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS email_subscriptions (
+    id             BIGSERIAL PRIMARY KEY,
+    email          TEXT NOT NULL,
+    repo_full_name TEXT NOT NULL DEFAULT '',
+    per_review     BOOLEAN NOT NULL DEFAULT FALSE,
+    digest         BOOLEAN NOT NULL DEFAULT FALSE,
+    last_digest_at TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (email, repo_full_name)
+);
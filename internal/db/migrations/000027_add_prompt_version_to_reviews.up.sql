@@ -0,0 +1 @@
+ALTER TABLE reviews ADD COLUMN IF NOT EXISTS prompt_version TEXT NOT NULL DEFAULT '';
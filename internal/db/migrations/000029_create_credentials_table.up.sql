@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS credentials (
+    name       TEXT PRIMARY KEY,
+    -- AES-GCM sealed value, prefixed with the version and the key id.
+    ciphertext TEXT NOT NULL,
+    expires_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
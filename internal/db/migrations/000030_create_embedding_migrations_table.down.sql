@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS embedding_migrations;
+ALTER TABLE repositories DROP COLUMN IF EXISTS embedder_model;
@@ -0,0 +1,18 @@
+-- The embedder model a repository's collection was built with; empty means
+-- the server's ai.embedder_model.
+ALTER TABLE repositories ADD COLUMN IF NOT EXISTS embedder_model TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS embedding_migrations (
+    repository_id     INTEGER PRIMARY KEY REFERENCES repositories(id) ON DELETE CASCADE,
+    source_collection TEXT NOT NULL,
+    source_model      TEXT NOT NULL,
+    target_collection TEXT NOT NULL,
+    target_model      TEXT NOT NULL,
+    -- building, built, flipped or done
+    status            TEXT NOT NULL,
+    files             INTEGER NOT NULL DEFAULT 0,
+    chunks            INTEGER NOT NULL DEFAULT 0,
+    error             TEXT NOT NULL DEFAULT '',
+    started_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+ALTER TABLE check_runs DROP COLUMN IF EXISTS attempt;
+ALTER TABLE check_runs DROP COLUMN IF EXISTS job_kind;
+ALTER TABLE check_runs DROP COLUMN IF EXISTS pr_number;
@@ -0,0 +1,5 @@
+-- The job a check run was created by, so the reaper can queue a review
+-- again when its check run went stale. attempt counts those retries.
+ALTER TABLE check_runs ADD COLUMN IF NOT EXISTS pr_number INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE check_runs ADD COLUMN IF NOT EXISTS job_kind TEXT NOT NULL DEFAULT '';
+ALTER TABLE check_runs ADD COLUMN IF NOT EXISTS attempt INTEGER NOT NULL DEFAULT 0;
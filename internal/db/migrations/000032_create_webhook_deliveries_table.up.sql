@@ -0,0 +1,8 @@
+-- IDs of accepted GitHub webhook deliveries (X-GitHub-Delivery), kept for
+-- github.webhook.delivery_ttl to reject replays.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    delivery_id TEXT PRIMARY KEY,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_received_at ON webhook_deliveries (received_at);
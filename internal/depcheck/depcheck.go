@@ -0,0 +1,240 @@
+// Package depcheck analyzes the dependencies a pull request adds or upgrades
+// in manifest files: it looks up known vulnerabilities in OSV.dev and
+// licenses in deps.dev and reports the dependencies that need attention.
+package depcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+const (
+	// maxDependencies limits the lookups per review.
+	maxDependencies = 25
+	// maxConcurrentLookups limits parallel requests to the APIs.
+	maxConcurrentLookups = 4
+)
+
+// depsDevSystems maps OSV ecosystems to deps.dev package systems.
+var depsDevSystems = map[string]string{
+	EcosystemGo:    "go",
+	EcosystemNPM:   "npm",
+	EcosystemPyPI:  "pypi",
+	EcosystemCargo: "cargo",
+}
+
+// Checker looks up the dependencies changed by a pull request.
+type Checker struct {
+	cfg    config.DependenciesConfig
+	client *http.Client
+}
+
+// NewChecker creates a Checker. A nil client uses http.DefaultClient.
+func NewChecker(cfg config.DependenciesConfig, client *http.Client) *Checker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Checker{cfg: cfg, client: client}
+}
+
+// Check looks up the dependencies added or upgraded in changedFiles. It
+// returns nil when no manifest changes a dependency. Failed lookups are
+// joined into the error, and the report still holds the other findings.
+func (c *Checker) Check(ctx context.Context, changedFiles []internalgithub.ChangedFile) (*core.DependencyReport, error) {
+	changes := Changes(changedFiles)
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	if len(changes) > maxDependencies {
+		changes = changes[:maxDependencies]
+	}
+
+	findings := make([]*core.DependencyFinding, len(changes))
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentLookups)
+	for i, change := range changes {
+		g.Go(func() error {
+			finding, err := c.check(ctx, change)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+			findings[i] = finding
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	report := &core.DependencyReport{Checked: len(changes)}
+	for _, f := range findings {
+		if f != nil {
+			report.Findings = append(report.Findings, *f)
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// check returns the finding for one dependency, or nil when it has no known
+// vulnerabilities or flagged licenses.
+func (c *Checker) check(ctx context.Context, change Change) (*core.DependencyFinding, error) {
+	vulns, err := c.vulnerabilities(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+	var flagged []string
+	if c.cfg.DepsDevURL != "" {
+		licenses, err := c.licenses(ctx, change)
+		if err != nil {
+			return nil, err
+		}
+		flagged = c.flaggedLicenses(licenses)
+	}
+	if len(vulns) == 0 && len(flagged) == 0 {
+		return nil, nil
+	}
+	return &core.DependencyFinding{
+		Ecosystem:       change.Ecosystem,
+		Name:            change.Name,
+		Version:         change.Version,
+		PreviousVersion: change.PreviousVersion,
+		File:            change.File,
+		Vulnerabilities: vulns,
+		Licenses:        flagged,
+	}, nil
+}
+
+type osvQuery struct {
+	Version string     `json:"version"`
+	Package osvPackage `json:"package"`
+}
+
+type osvPackage struct {
+	Name      string `json:"name"`
+	Ecosystem string `json:"ecosystem"`
+}
+
+type osvResponse struct {
+	Vulns []struct {
+		ID      string   `json:"id"`
+		Summary string   `json:"summary"`
+		Aliases []string `json:"aliases"`
+	} `json:"vulns"`
+}
+
+// vulnerabilities queries OSV.dev for the known vulnerabilities of a version.
+func (c *Checker) vulnerabilities(ctx context.Context, change Change) ([]core.Vulnerability, error) {
+	version := change.Version
+	if change.Ecosystem == EcosystemGo {
+		// OSV records Go module versions without the "v" prefix.
+		version = strings.TrimPrefix(version, "v")
+	}
+	body, err := json.Marshal(osvQuery{Version: version, Package: osvPackage{Name: change.Name, Ecosystem: change.Ecosystem}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OSV query: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.cfg.OSVURL, "/")+"/v1/query", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OSV request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var resp osvResponse
+	if err := c.do(req, &resp); err != nil {
+		return nil, fmt.Errorf("failed to query OSV for %s@%s: %w", change.Name, change.Version, err)
+	}
+	vulns := make([]core.Vulnerability, 0, len(resp.Vulns))
+	for _, v := range resp.Vulns {
+		vulns = append(vulns, core.Vulnerability{ID: v.ID, Aliases: v.Aliases, Summary: v.Summary})
+	}
+	return vulns, nil
+}
+
+type depsDevVersion struct {
+	Licenses []string `json:"licenses"`
+}
+
+// licenses queries deps.dev for the licenses of a version. Versions deps.dev
+// does not know have no licenses.
+func (c *Checker) licenses(ctx context.Context, change Change) ([]string, error) {
+	system, ok := depsDevSystems[change.Ecosystem]
+	if !ok {
+		return nil, nil
+	}
+	endpoint := fmt.Sprintf("%s/v3/systems/%s/packages/%s/versions/%s",
+		strings.TrimSuffix(c.cfg.DepsDevURL, "/"), system, url.PathEscape(change.Name), url.PathEscape(change.Version))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create deps.dev request: %w", err)
+	}
+
+	var resp depsDevVersion
+	if err := c.do(req, &resp); err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query deps.dev for %s@%s: %w", change.Name, change.Version, err)
+	}
+	return resp.Licenses, nil
+}
+
+// licenseSeparators splits SPDX expressions such as "(MIT OR GPL-2.0)" into
+// identifiers.
+var licenseSeparators = strings.NewReplacer("(", " ", ")", " ")
+
+// flaggedLicenses returns the license expressions with an identifier that
+// starts with a flagged one.
+func (c *Checker) flaggedLicenses(licenses []string) []string {
+	var flagged []string
+	for _, license := range licenses {
+		ids := " " + licenseSeparators.Replace(license)
+		for _, prefix := range c.cfg.FlaggedLicenses {
+			if prefix != "" && strings.Contains(ids, " "+prefix) {
+				flagged = append(flagged, license)
+				break
+			}
+		}
+	}
+	return flagged
+}
+
+var errNotFound = errors.New("not found")
+
+// do sends req and decodes a JSON response into v.
+func (c *Checker) do(req *http.Request, v any) error {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
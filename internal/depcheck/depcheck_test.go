@@ -0,0 +1,96 @@
+package depcheck
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+func TestCheck(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/query", func(w http.ResponseWriter, r *http.Request) {
+		var q osvQuery
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			t.Errorf("failed to decode OSV query: %v", err)
+		}
+		if q.Package.Name == "github.com/vuln/mod" {
+			if q.Version != "1.0.0" || q.Package.Ecosystem != EcosystemGo {
+				t.Errorf("unexpected OSV query: %+v", q)
+			}
+			_, _ = w.Write([]byte(`{"vulns":[{"id":"GO-2024-0001","summary":"Panic on input","aliases":["CVE-2024-0001"]}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("GET /v3/systems/go/packages/{name}/versions/{version}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("name") {
+		case "github.com/copyleft/mod":
+			_, _ = w.Write([]byte(`{"licenses":["(MIT OR GPL-3.0-only)"]}`))
+		case "github.com/vuln/mod":
+			_, _ = w.Write([]byte(`{"licenses":["MIT"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	checker := NewChecker(config.DependenciesConfig{
+		Enabled:         true,
+		OSVURL:          srv.URL,
+		DepsDevURL:      srv.URL,
+		FlaggedLicenses: []string{"GPL"},
+	}, srv.Client())
+	changedFiles := []internalgithub.ChangedFile{{
+		Filename: "go.mod",
+		Patch:    "+require github.com/vuln/mod v1.0.0\n+require github.com/copyleft/mod v2.0.0\n+require github.com/unknown/mod v0.1.0",
+	}}
+
+	report, err := checker.Check(context.Background(), changedFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &core.DependencyReport{
+		Checked: 3,
+		Findings: []core.DependencyFinding{
+			{
+				Ecosystem: EcosystemGo, Name: "github.com/vuln/mod", Version: "v1.0.0", File: "go.mod",
+				Vulnerabilities: []core.Vulnerability{{ID: "GO-2024-0001", Aliases: []string{"CVE-2024-0001"}, Summary: "Panic on input"}},
+			},
+			{
+				Ecosystem: EcosystemGo, Name: "github.com/copyleft/mod", Version: "v2.0.0", File: "go.mod",
+				Vulnerabilities: []core.Vulnerability{}, Licenses: []string{"(MIT OR GPL-3.0-only)"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("Check() = %+v, want %+v", report, want)
+	}
+
+	if report, err := checker.Check(context.Background(), []internalgithub.ChangedFile{{Filename: "main.go", Patch: "+x"}}); report != nil || err != nil {
+		t.Errorf("Check() without manifest changes = %+v, %v", report, err)
+	}
+}
+
+func TestCheckReportsFailedLookups(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	checker := NewChecker(config.DependenciesConfig{OSVURL: srv.URL}, srv.Client())
+	report, err := checker.Check(context.Background(), []internalgithub.ChangedFile{{Filename: "requirements.txt", Patch: "+django==4.2.0"}})
+	if err == nil {
+		t.Fatal("expected an error for a failed lookup")
+	}
+	if report == nil || report.Checked != 1 || len(report.Findings) != 0 {
+		t.Errorf("unexpected partial report: %+v", report)
+	}
+}
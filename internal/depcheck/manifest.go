@@ -0,0 +1,224 @@
+package depcheck
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+// OSV ecosystem names.
+const (
+	EcosystemGo    = "Go"
+	EcosystemNPM   = "npm"
+	EcosystemPyPI  = "PyPI"
+	EcosystemCargo = "crates.io"
+)
+
+// Change is a dependency added or upgraded by a pull request.
+type Change struct {
+	Ecosystem string
+	Name      string
+	// Version is the resolved version, without range operators.
+	Version string
+	// PreviousVersion is set when the diff also removes an older version.
+	PreviousVersion string
+	// File is the manifest that declares the dependency.
+	File string
+}
+
+// dependency is one manifest entry parsed from a diff line.
+type dependency struct {
+	name    string
+	version string
+}
+
+// manifestParser parses one diff line of a manifest. section is the last
+// section header seen in the hunk, or "" when the hunk does not show one.
+type manifestParser struct {
+	ecosystem string
+	parse     func(line, section string) (dependency, bool)
+	// header returns the section a line opens, if any.
+	header func(line string) (string, bool)
+}
+
+// Changes returns the dependencies added or upgraded in the manifest files
+// among changedFiles. Entries whose version is unchanged, and version ranges
+// that do not name a version, are skipped.
+func Changes(changedFiles []internalgithub.ChangedFile) []Change {
+	var changes []Change
+	for _, f := range changedFiles {
+		parser, ok := parserFor(f.Filename)
+		if !ok || f.Patch == "" {
+			continue
+		}
+		added, removed := parsePatch(f.Patch, parser)
+		for _, dep := range added {
+			change := Change{Ecosystem: parser.ecosystem, Name: dep.name, Version: dep.version, File: f.Filename}
+			if old, ok := removed[dep.name]; ok {
+				if old == dep.version {
+					continue
+				}
+				change.PreviousVersion = old
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+func parserFor(file string) (manifestParser, bool) {
+	base := path.Base(file)
+	switch {
+	case base == "go.mod":
+		return manifestParser{ecosystem: EcosystemGo, parse: parseGoMod, header: goModHeader}, true
+	case base == "package.json":
+		return manifestParser{ecosystem: EcosystemNPM, parse: parsePackageJSON, header: packageJSONHeader}, true
+	case strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt"):
+		return manifestParser{ecosystem: EcosystemPyPI, parse: parseRequirements}, true
+	case base == "Cargo.toml":
+		return manifestParser{ecosystem: EcosystemCargo, parse: parseCargoToml, header: tomlHeader}, true
+	default:
+		return manifestParser{}, false
+	}
+}
+
+// parsePatch returns the entries the patch adds, in order, and the versions
+// of the entries it removes by name.
+func parsePatch(patch string, parser manifestParser) ([]dependency, map[string]string) {
+	var added []dependency
+	removed := make(map[string]string)
+	section := ""
+	for line := range strings.SplitSeq(patch, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			section = ""
+			continue
+		}
+		if strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") || line == "" {
+			continue
+		}
+		marker, content := line[0], line[1:]
+		if parser.header != nil {
+			if h, ok := parser.header(content); ok {
+				section = h
+				continue
+			}
+		}
+		dep, ok := parser.parse(content, section)
+		if !ok {
+			continue
+		}
+		switch marker {
+		case '+':
+			if !slices.ContainsFunc(added, func(d dependency) bool { return d.name == dep.name }) {
+				added = append(added, dep)
+			}
+		case '-':
+			removed[dep.name] = dep.version
+		}
+	}
+	return added, removed
+}
+
+var goModVersionRegex = regexp.MustCompile(`^v\d+\.\d+\.\d+\S*$`)
+
+func goModHeader(line string) (string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 2 && fields[1] == "(" {
+		return fields[0], true
+	}
+	if len(fields) == 1 && fields[0] == ")" {
+		return "", true
+	}
+	return "", false
+}
+
+func parseGoMod(line, section string) (dependency, bool) {
+	line, _, _ = strings.Cut(line, "//")
+	fields := strings.Fields(line)
+	if len(fields) == 3 && fields[0] == "require" {
+		fields = fields[1:]
+	} else if section != "" && section != "require" {
+		return dependency{}, false
+	}
+	if len(fields) != 2 || !goModVersionRegex.MatchString(fields[1]) {
+		return dependency{}, false
+	}
+	return dependency{name: fields[0], version: fields[1]}, true
+}
+
+var (
+	jsonHeaderRegex = regexp.MustCompile(`^\s*"([^"]+)"\s*:\s*\{`)
+	jsonEntryRegex  = regexp.MustCompile(`^\s*"([^"]+)"\s*:\s*"([^"]*)"`)
+	npmVersionRegex = regexp.MustCompile(`^(?:\^|~|>=|=|v)?\s*(\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?)$`)
+)
+
+// packageJSONKeys are top-level and engines keys whose values look like
+// versions but are not dependencies.
+var packageJSONKeys = []string{"version", "node", "npm", "yarn", "pnpm", "packageManager"}
+
+func packageJSONHeader(line string) (string, bool) {
+	if m := jsonHeaderRegex.FindStringSubmatch(line); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+func parsePackageJSON(line, section string) (dependency, bool) {
+	if section != "" && !strings.HasSuffix(section, "ependencies") {
+		return dependency{}, false
+	}
+	m := jsonEntryRegex.FindStringSubmatch(line)
+	if m == nil || slices.Contains(packageJSONKeys, m[1]) {
+		return dependency{}, false
+	}
+	v := npmVersionRegex.FindStringSubmatch(strings.TrimSpace(m[2]))
+	if v == nil {
+		return dependency{}, false
+	}
+	return dependency{name: m[1], version: v[1]}, true
+}
+
+var requirementRegex = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)(?:\[[^\]]*\])?\s*(?:==|~=|===)\s*([0-9][0-9A-Za-z.+!-]*)`)
+
+func parseRequirements(line, _ string) (dependency, bool) {
+	m := requirementRegex.FindStringSubmatch(line)
+	if m == nil {
+		return dependency{}, false
+	}
+	return dependency{name: strings.ToLower(m[1]), version: m[2]}, true
+}
+
+var (
+	tomlHeaderRegex = regexp.MustCompile(`^\s*\[([^\]]+)\]\s*$`)
+	cargoEntryRegex = regexp.MustCompile(`^\s*([A-Za-z0-9_-]+)\s*=\s*(?:"([^"]+)"|\{.*\bversion\s*=\s*"([^"]+)")`)
+	cargoVerRegex   = regexp.MustCompile(`^(?:\^|~|=)?\s*(\d+(?:\.\d+){0,2}(?:-[0-9A-Za-z.-]+)?)$`)
+)
+
+// cargoPackageKeys are [package] keys, skipped when a hunk does not show its
+// section header.
+var cargoPackageKeys = []string{"name", "version", "edition", "rust-version", "authors", "description", "license", "repository", "homepage", "documentation", "readme", "publish"}
+
+func tomlHeader(line string) (string, bool) {
+	if m := tomlHeaderRegex.FindStringSubmatch(line); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+func parseCargoToml(line, section string) (dependency, bool) {
+	if section != "" && !strings.HasSuffix(section, "dependencies") {
+		return dependency{}, false
+	}
+	m := cargoEntryRegex.FindStringSubmatch(line)
+	if m == nil || (section == "" && slices.Contains(cargoPackageKeys, m[1])) {
+		return dependency{}, false
+	}
+	v := cargoVerRegex.FindStringSubmatch(m[2] + m[3])
+	if v == nil {
+		return dependency{}, false
+	}
+	return dependency{name: m[1], version: v[1]}, true
+}
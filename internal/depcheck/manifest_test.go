@@ -0,0 +1,113 @@
+package depcheck
+
+import (
+	"reflect"
+	"testing"
+
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+func TestChanges(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		patch string
+		want  []Change
+	}{
+		{
+			name: "go.mod require block and single line",
+			file: "go.mod",
+			patch: `@@ -3,8 +3,9 @@ go 1.22
+ require (
+-	github.com/google/uuid v1.3.0
++	github.com/google/uuid v1.6.0
++	golang.org/x/net v0.17.0 // indirect
+ 	github.com/spf13/cobra v1.8.0
+ )
++require example.com/single v0.1.0
+ replace (
++	example.com/old => example.com/new v1.0.0
+ )
++exclude example.com/bad v1.2.3`,
+			want: []Change{
+				{Ecosystem: EcosystemGo, Name: "github.com/google/uuid", Version: "v1.6.0", PreviousVersion: "v1.3.0", File: "go.mod"},
+				{Ecosystem: EcosystemGo, Name: "golang.org/x/net", Version: "v0.17.0", File: "go.mod"},
+				{Ecosystem: EcosystemGo, Name: "example.com/single", Version: "v0.1.0", File: "go.mod"},
+			},
+		},
+		{
+			name: "package.json dependencies only",
+			file: "web/package.json",
+			patch: `@@ -1,12 +1,14 @@
+ {
+-  "version": "1.0.0",
++  "version": "1.1.0",
+   "engines": {
++    "node": ">=18.0.0"
+   },
+   "dependencies": {
+-    "lodash": "^4.17.11",
++    "lodash": "^4.17.15",
++    "react": "18.2.0",
++    "local": "file:../local",
++    "wide": "*"
+   },
+   "scripts": {
++    "build": "1.0.0"
+   }`,
+			want: []Change{
+				{Ecosystem: EcosystemNPM, Name: "lodash", Version: "4.17.15", PreviousVersion: "4.17.11", File: "web/package.json"},
+				{Ecosystem: EcosystemNPM, Name: "react", Version: "18.2.0", File: "web/package.json"},
+			},
+		},
+		{
+			name: "requirements pins only",
+			file: "requirements-dev.txt",
+			patch: `@@ -1,3 +1,5 @@
+-Django==4.2.0
++Django==4.2.7
++requests[socks]==2.31.0 ; python_version >= "3.8"
++flask>=2.0
++# pytest==7.0.0
+ numpy==1.26.0`,
+			want: []Change{
+				{Ecosystem: EcosystemPyPI, Name: "django", Version: "4.2.7", PreviousVersion: "4.2.0", File: "requirements-dev.txt"},
+				{Ecosystem: EcosystemPyPI, Name: "requests", Version: "2.31.0", File: "requirements-dev.txt"},
+			},
+		},
+		{
+			name: "Cargo.toml dependency sections",
+			file: "Cargo.toml",
+			patch: `@@ -1,8 +1,9 @@
+ [package]
+-version = "0.1.0"
++version = "0.2.0"
+ [dependencies]
++serde = { version = "1.0.190", features = ["derive"] }
++tokio = "1.33"
++local = { path = "../local" }`,
+			want: []Change{
+				{Ecosystem: EcosystemCargo, Name: "serde", Version: "1.0.190", File: "Cargo.toml"},
+				{Ecosystem: EcosystemCargo, Name: "tokio", Version: "1.33", File: "Cargo.toml"},
+			},
+		},
+		{
+			name:  "unchanged version is skipped",
+			file:  "go.mod",
+			patch: "@@ -1 +1 @@\n-\tgithub.com/a/b v1.0.0\n+\tgithub.com/a/b v1.0.0 // indirect",
+		},
+		{
+			name:  "other files are ignored",
+			file:  "main.go",
+			patch: "+require example.com/x v1.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Changes([]internalgithub.ChangedFile{{Filename: tt.file, Patch: tt.patch}})
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Changes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+// Package feedback records how maintainers rate posted review suggestions, so
+// acceptance can be compared across models and prompt versions.
+package feedback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// reactionSignals maps GitHub reaction content to a feedback signal. Other
+// reactions carry no rating and are ignored.
+var reactionSignals = map[string]string{
+	"+1": core.FeedbackHelpful,
+	"-1": core.FeedbackWrong,
+}
+
+// Collector turns reactions and "/warden" replies on Code-Warden suggestions
+// into review_feedback rows.
+type Collector struct {
+	store  storage.FeedbackStore
+	logger *slog.Logger
+}
+
+// NewCollector creates a new [Collector].
+func NewCollector(store storage.FeedbackStore, logger *slog.Logger) *Collector {
+	return &Collector{store: store, logger: logger}
+}
+
+// RecordReply stores the signal from a "/warden helpful|wrong" reply. The
+// rated comment must be a suggestion posted by Code-Warden as botLogin.
+func (c *Collector) RecordReply(ctx context.Context, gh internalgithub.Client, event *core.GitHubEvent, botLogin string) error {
+	parent, err := gh.GetReviewComment(ctx, event.RepoOwner, event.RepoName, event.FeedbackCommentID)
+	if err != nil {
+		return fmt.Errorf("failed to get rated review comment %d: %w", event.FeedbackCommentID, err)
+	}
+	meta, ok := internalgithub.ParseSuggestion(parent, botLogin)
+	if !ok {
+		c.logger.Info("ignoring feedback on a comment not posted by code-warden",
+			"repo", event.RepoFullName, "comment_id", parent.ID, "user", event.Commenter)
+		return nil
+	}
+
+	fb := newFeedback(event.RepoFullName, event.PRNumber, parent, meta)
+	fb.UserLogin = event.Commenter
+	fb.Source = storage.FeedbackSourceReply
+	fb.Signal = event.FeedbackSignal
+	if err := c.store.UpsertReviewFeedback(ctx, fb); err != nil {
+		return err
+	}
+	c.logger.Info("recorded review feedback", "repo", event.RepoFullName, "pr", event.PRNumber,
+		"comment_id", parent.ID, "signal", fb.Signal, "model", fb.Model, "prompt_version", fb.PromptVersion)
+	return nil
+}
+
+// SyncReactions re-reads the 👍/👎 reactions on every Code-Warden suggestion,
+// posted as botLogin, in a pull request. GitHub does not send webhooks for
+// reactions, so they are polled whenever other activity on the pull request
+// is processed.
+func (c *Collector) SyncReactions(ctx context.Context, gh internalgithub.Client, owner, repo string, prNumber int, botLogin string) error {
+	comments, err := gh.ListReviewComments(ctx, owner, repo, prNumber)
+	if err != nil {
+		return fmt.Errorf("failed to list review comments: %w", err)
+	}
+
+	repoFullName := owner + "/" + repo
+	synced := 0
+	for i := range comments {
+		comment := &comments[i]
+		meta, ok := internalgithub.ParseSuggestion(comment, botLogin)
+		if !ok {
+			continue
+		}
+
+		reactions, err := gh.ListReviewCommentReactions(ctx, owner, repo, comment.ID)
+		if err != nil {
+			return fmt.Errorf("failed to list reactions for comment %d: %w", comment.ID, err)
+		}
+
+		var rows []*storage.ReviewFeedback
+		for _, r := range reactions {
+			signal, ok := reactionSignals[r.Content]
+			if !ok || r.User == "" || strings.HasSuffix(r.User, "[bot]") {
+				continue
+			}
+			fb := newFeedback(repoFullName, prNumber, comment, meta)
+			fb.UserLogin = r.User
+			fb.Source = storage.FeedbackSourceReaction
+			fb.Signal = signal
+			rows = append(rows, fb)
+		}
+		if err := c.store.ReplaceReactionFeedback(ctx, comment.ID, rows); err != nil {
+			return err
+		}
+		synced++
+	}
+
+	c.logger.Debug("synced suggestion reactions", "repo", repoFullName, "pr", prNumber, "suggestions", synced)
+	return nil
+}
+
+func newFeedback(repoFullName string, prNumber int, comment *internalgithub.ReviewComment, meta internalgithub.SuggestionMeta) *storage.ReviewFeedback {
+	return &storage.ReviewFeedback{
+		RepoFullName:  repoFullName,
+		PRNumber:      prNumber,
+		CommentID:     comment.ID,
+		FilePath:      comment.Path,
+		Line:          comment.Line,
+		Severity:      meta.Severity,
+		Category:      meta.Category,
+		Model:         meta.Model,
+		PromptVersion: meta.PromptVersion,
+	}
+}
@@ -0,0 +1,108 @@
+package feedback
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+var testMeta = internalgithub.SuggestionMeta{Model: "qwen", PromptVersion: "abc123", Severity: "High", Category: "Bug"}
+
+const testBot = "code-warden[bot]"
+
+func newTestCollector(t *testing.T) (*Collector, *mocks.MockClient, *mocks.MockStore) {
+	ctrl := gomock.NewController(t)
+	gh := mocks.NewMockClient(ctrl)
+	store := mocks.NewMockStore(ctrl)
+	return NewCollector(store, slog.New(slog.NewTextHandler(io.Discard, nil))), gh, store
+}
+
+func TestCollector_RecordReply(t *testing.T) {
+	event := &core.GitHubEvent{
+		RepoOwner:         "octo",
+		RepoName:          "repo",
+		RepoFullName:      "octo/repo",
+		PRNumber:          7,
+		Commenter:         "alice",
+		FeedbackCommentID: 42,
+		FeedbackSignal:    core.FeedbackWrong,
+	}
+
+	t.Run("suggestion", func(t *testing.T) {
+		c, gh, store := newTestCollector(t)
+		gh.EXPECT().GetReviewComment(gomock.Any(), "octo", "repo", int64(42)).Return(&internalgithub.ReviewComment{
+			ID: 42, Path: "main.go", Line: 10, User: testBot, Body: "nil deref\n\n" + internalgithub.FeedbackMarker(testMeta),
+		}, nil)
+		store.EXPECT().UpsertReviewFeedback(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, fb *storage.ReviewFeedback) error {
+				assert.Equal(t, "octo/repo", fb.RepoFullName)
+				assert.Equal(t, int64(42), fb.CommentID)
+				assert.Equal(t, "main.go", fb.FilePath)
+				assert.Equal(t, "qwen", fb.Model)
+				assert.Equal(t, "abc123", fb.PromptVersion)
+				assert.Equal(t, "alice", fb.UserLogin)
+				assert.Equal(t, storage.FeedbackSourceReply, fb.Source)
+				assert.Equal(t, core.FeedbackWrong, fb.Signal)
+				return nil
+			})
+
+		require.NoError(t, c.RecordReply(context.Background(), gh, event, testBot))
+	})
+
+	t.Run("comment not posted by code-warden", func(t *testing.T) {
+		c, gh, _ := newTestCollector(t)
+		gh.EXPECT().GetReviewComment(gomock.Any(), "octo", "repo", int64(42)).
+			Return(&internalgithub.ReviewComment{ID: 42, User: "alice", Body: "human comment"}, nil)
+
+		require.NoError(t, c.RecordReply(context.Background(), gh, event, testBot))
+	})
+
+	t.Run("marker copied into a human comment", func(t *testing.T) {
+		c, gh, _ := newTestCollector(t)
+		gh.EXPECT().GetReviewComment(gomock.Any(), "octo", "repo", int64(42)).
+			Return(&internalgithub.ReviewComment{ID: 42, User: "alice", Body: "> nil deref\n\n" + internalgithub.FeedbackMarker(testMeta)}, nil)
+
+		require.NoError(t, c.RecordReply(context.Background(), gh, event, testBot))
+	})
+}
+
+func TestCollector_SyncReactions(t *testing.T) {
+	c, gh, store := newTestCollector(t)
+
+	gh.EXPECT().ListReviewComments(gomock.Any(), "octo", "repo", 7).Return([]internalgithub.ReviewComment{
+		{ID: 1, Path: "a.go", Line: 3, User: testBot, Body: "fix this\n\n" + internalgithub.FeedbackMarker(testMeta)},
+		{ID: 2, Path: "a.go", Line: 5, User: "alice", Body: "a human comment"},
+		{ID: 3, Path: "a.go", Line: 7, User: "alice", Body: "copied\n\n" + internalgithub.FeedbackMarker(testMeta)},
+	}, nil)
+	gh.EXPECT().ListReviewCommentReactions(gomock.Any(), "octo", "repo", int64(1)).Return([]internalgithub.Reaction{
+		{User: "alice", Content: "+1"},
+		{User: "bob", Content: "-1"},
+		{User: "carol", Content: "eyes"},
+		{User: "ci[bot]", Content: "+1"},
+	}, nil)
+	store.EXPECT().ReplaceReactionFeedback(gomock.Any(), int64(1), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, rows []*storage.ReviewFeedback) error {
+			require.Len(t, rows, 2)
+			assert.Equal(t, "alice", rows[0].UserLogin)
+			assert.Equal(t, core.FeedbackHelpful, rows[0].Signal)
+			assert.Equal(t, "bob", rows[1].UserLogin)
+			assert.Equal(t, core.FeedbackWrong, rows[1].Signal)
+			for _, r := range rows {
+				assert.Equal(t, storage.FeedbackSourceReaction, r.Source)
+				assert.Equal(t, "qwen", r.Model)
+			}
+			return nil
+		})
+
+	require.NoError(t, c.SyncReactions(context.Background(), gh, "octo", "repo", 7, testBot))
+}
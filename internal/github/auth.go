@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
 	"github.com/google/go-github/v73/github"
@@ -16,24 +18,101 @@ import (
 	"github.com/sevigo/code-warden/internal/config"
 )
 
+// newAppClient creates a client authenticated as the GitHub App itself, for
+// the App API (installation tokens and lookups).
+func newAppClient(cfg *config.Config) (*github.Client, error) {
+	privateKey, err := os.ReadFile(cfg.GitHub.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key from %s: %w", cfg.GitHub.PrivateKeyPath, err)
+	}
+	appTransport, err := ghinstallation.NewAppsTransport(http.DefaultTransport, cfg.GitHub.AppID, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub App transport: %w", err)
+	}
+	return github.NewClient(&http.Client{Transport: appTransport}), nil
+}
+
+var (
+	appLoginMu sync.Mutex
+	appLogin   string
+)
+
+// AppLogin returns the login the GitHub App posts comments as,
+// "<app-slug>[bot]". It is looked up once per process.
+func AppLogin(ctx context.Context, cfg *config.Config) (string, error) {
+	appLoginMu.Lock()
+	defer appLoginMu.Unlock()
+	if appLogin != "" {
+		return appLogin, nil
+	}
+
+	appClient, err := newAppClient(cfg)
+	if err != nil {
+		return "", err
+	}
+	app, _, err := appClient.Apps.Get(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to get GitHub App: %w", err)
+	}
+	if app.GetSlug() == "" {
+		return "", fmt.Errorf("GitHub App %d has no slug", cfg.GitHub.AppID)
+	}
+	appLogin = app.GetSlug() + "[bot]"
+	return appLogin, nil
+}
+
+// TokenCache keeps installation tokens across jobs and restarts, encrypted;
+// *secrets.Vault implements it.
+type TokenCache interface {
+	Get(ctx context.Context, name string) (string, bool, error)
+	Put(ctx context.Context, name, value string, expiresAt time.Time) error
+}
+
+var (
+	tokenCacheMu sync.RWMutex
+	tokenCache   TokenCache
+)
+
+// tokenRefreshMargin is how long before its expiry a cached installation
+// token is replaced, so that it does not expire during a job.
+const tokenRefreshMargin = 15 * time.Minute
+
+// UseTokenCache makes CreateInstallationClient reuse the installation tokens
+// kept in cache process-wide; nil disables the cache.
+func UseTokenCache(cache TokenCache) {
+	tokenCacheMu.Lock()
+	defer tokenCacheMu.Unlock()
+	tokenCache = cache
+}
+
+func installationTokenName(installationID int64) string {
+	return fmt.Sprintf("github/installation/%d/token", installationID)
+}
+
 // CreateInstallationClient creates a GitHub client that is authenticated as a specific application installation.
 // It will now return the client, the raw token string, and an error.
+// With a token cache (see UseTokenCache), a cached token is reused until
+// shortly before it expires.
 func CreateInstallationClient(ctx context.Context, cfg *config.Config, installationID int64, logger *slog.Logger) (Client, string, error) {
-	logger.Info("Creating GitHub installation client", "installation_id", installationID)
-
-	// Load private key
-	privateKey, err := os.ReadFile(cfg.GitHub.PrivateKeyPath)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to read private key from %s: %w", cfg.GitHub.PrivateKeyPath, err)
+	tokenCacheMu.RLock()
+	cache := tokenCache
+	tokenCacheMu.RUnlock()
+	if cache != nil {
+		cached, ok, err := cache.Get(ctx, installationTokenName(installationID))
+		if err != nil {
+			logger.Warn("failed to read cached installation token", "installation_id", installationID, "error", err)
+		}
+		if ok {
+			return newInstallationClient(ctx, cached, logger), cached, nil
+		}
 	}
 
-	// Create JWT source
-	// We use the apps transport to interact with the GitHub App API (e.g. to get installation tokens)
-	appTransport, err := ghinstallation.NewAppsTransport(http.DefaultTransport, cfg.GitHub.AppID, privateKey)
+	logger.Info("Creating GitHub installation client", "installation_id", installationID)
+
+	appClient, err := newAppClient(cfg)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to create GitHub App transport: %w", err)
+		return nil, "", err
 	}
-	appClient := github.NewClient(&http.Client{Transport: appTransport})
 
 	// Get the installation token
 	token, _, err := appClient.Apps.CreateInstallationToken(ctx, installationID, nil)
@@ -45,28 +124,29 @@ func CreateInstallationClient(ctx context.Context, cfg *config.Config, installat
 	}
 	logger.Info("Successfully created installation token", "installation_id", installationID, "expires_at", token.GetExpiresAt())
 
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token.GetToken()})
-	tc := oauth2.NewClient(ctx, ts)
-	installationClient := github.NewClient(tc)
+	if cache != nil && !token.GetExpiresAt().IsZero() {
+		expiresAt := token.GetExpiresAt().Add(-tokenRefreshMargin)
+		if err := cache.Put(ctx, installationTokenName(installationID), token.GetToken(), expiresAt); err != nil {
+			logger.Warn("failed to cache installation token", "installation_id", installationID, "error", err)
+		}
+	}
 
-	return NewGitHubClient(installationClient, logger), token.GetToken(), nil
+	return newInstallationClient(ctx, token.GetToken(), logger), token.GetToken(), nil
+}
+
+func newInstallationClient(ctx context.Context, token string, logger *slog.Logger) Client {
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	tc := oauth2.NewClient(ctx, ts)
+	return NewGitHubClient(github.NewClient(tc), logger)
 }
 
 // GetInstallationIDForRepo looks up the installation ID for a repository using GitHub App credentials.
 // This is used when a repo is added via UI and we need to find its installation ID.
 func GetInstallationIDForRepo(ctx context.Context, cfg *config.Config, repoFullName string, logger *slog.Logger) (int64, error) {
-	// Load private key
-	privateKey, err := os.ReadFile(cfg.GitHub.PrivateKeyPath)
-	if err != nil {
-		return 0, fmt.Errorf("failed to read private key from %s: %w", cfg.GitHub.PrivateKeyPath, err)
-	}
-
-	// Create JWT transport for App API calls
-	appTransport, err := ghinstallation.NewAppsTransport(http.DefaultTransport, cfg.GitHub.AppID, privateKey)
+	appClient, err := newAppClient(cfg)
 	if err != nil {
-		return 0, fmt.Errorf("failed to create GitHub App transport: %w", err)
+		return 0, err
 	}
-	appClient := github.NewClient(&http.Client{Transport: appTransport})
 
 	// Parse owner/repo
 	parts := strings.Split(repoFullName, "/")
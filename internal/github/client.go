@@ -3,13 +3,20 @@ package github
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/google/go-github/v73/github"
 	"golang.org/x/oauth2"
 )
 
+// ErrNotFound is returned when a requested file or resource does not exist.
+var ErrNotFound = errors.New("not found")
+
 // ChangedFile holds the filename and patch data for a single file
 // included in a pull request. This helps in focusing the review on specific changes.
 type ChangedFile struct {
@@ -25,6 +32,32 @@ type DraftReviewComment struct {
 	Body      string
 }
 
+// ReviewComment is an inline pull request review comment.
+type ReviewComment struct {
+	ID        int64
+	NodeID    string // GraphQL ID, for MinimizeComment
+	InReplyTo int64
+	Path      string
+	Line      int
+	StartLine int    // Set for multi-line comments
+	DiffHunk  string // The diff up to and including Line
+	Body      string
+	User      string
+}
+
+// IssueComment is a top-level comment on an issue or pull request.
+type IssueComment struct {
+	ID   int64
+	Body string
+	User string
+}
+
+// Reaction is a single emoji reaction left by a user.
+type Reaction struct {
+	User    string
+	Content string // "+1", "-1", "laugh", "confused", "heart", "hooray", "rocket" or "eyes"
+}
+
 // PullRequestOptions contains options for creating a pull request.
 type PullRequestOptions struct {
 	Title string
@@ -67,15 +100,38 @@ type Client interface {
 	CreateCommentID(ctx context.Context, owner, repo string, number int, body string) (int64, error)
 	// UpdateComment edits an existing comment body in-place.
 	UpdateComment(ctx context.Context, owner, repo string, commentID int64, body string) error
+	// ListIssueComments returns the top-level comments of an issue or pull
+	// request, oldest first.
+	ListIssueComments(ctx context.Context, owner, repo string, number int) ([]IssueComment, error)
 	CreateReview(ctx context.Context, owner, repo string, number int, commitSHA, body string, comments []DraftReviewComment) error
 	CreateCheckRun(ctx context.Context, owner, repo string, opts github.CreateCheckRunOptions) (*github.CheckRun, error)
 	UpdateCheckRun(ctx context.Context, owner, repo string, checkRunID int64, opts github.UpdateCheckRunOptions) (*github.CheckRun, error)
 
+	// Review comment feedback
+	ListReviewComments(ctx context.Context, owner, repo string, number int) ([]ReviewComment, error)
+	GetReviewComment(ctx context.Context, owner, repo string, commentID int64) (*ReviewComment, error)
+	ListReviewCommentReactions(ctx context.Context, owner, repo string, commentID int64) ([]Reaction, error)
+	// UpdateReviewComment edits the body of an inline review comment.
+	UpdateReviewComment(ctx context.Context, owner, repo string, commentID int64, body string) error
+	// MinimizeComment hides a comment, given its GraphQL node ID, for a
+	// reason such as "OUTDATED" or "RESOLVED".
+	MinimizeComment(ctx context.Context, nodeID, reason string) error
+	// ReplyToReviewComment posts body in the thread of an inline review
+	// comment.
+	ReplyToReviewComment(ctx context.Context, owner, repo string, number int, commentID int64, body string) error
+
 	// New methods for agent operations
 	CreatePullRequest(ctx context.Context, owner, repo string, opts PullRequestOptions) (*github.PullRequest, error)
 	ListIssues(ctx context.Context, owner, repo string, opts IssueOptions) ([]Issue, error)
+	// ListPullRequests returns the pull requests of a repository in state
+	// ("open", "closed" or "all"), newest first.
+	ListPullRequests(ctx context.Context, owner, repo, state string) ([]*github.PullRequest, error)
 	GetIssue(ctx context.Context, owner, repo string, number int) (*Issue, error)
+	CreateIssue(ctx context.Context, owner, repo, title, body string) (*Issue, error)
 	GetBranch(ctx context.Context, owner, repo, branch string) (*github.Branch, error)
+	// GetFileContent returns the content of a file on the default branch, or
+	// ErrNotFound when the repository or file does not exist.
+	GetFileContent(ctx context.Context, owner, repo, path string) ([]byte, error)
 }
 
 type gitHubClient struct {
@@ -244,6 +300,156 @@ func (g *gitHubClient) UpdateComment(ctx context.Context, owner, repo string, co
 	return err
 }
 
+// ListIssueComments retrieves all top-level comments of an issue or pull
+// request, following pagination.
+func (g *gitHubClient) ListIssueComments(ctx context.Context, owner, repo string, number int) ([]IssueComment, error) {
+	var all []IssueComment
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, resp, err := g.client.Issues.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			g.logger.Error("failed to list comments", "owner", owner, "repo", repo, "pr", number, "error", err)
+			return nil, err
+		}
+		for _, c := range comments {
+			all = append(all, IssueComment{ID: c.GetID(), Body: c.GetBody(), User: c.GetUser().GetLogin()})
+		}
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// ListReviewComments retrieves all inline review comments on a pull request,
+// following pagination.
+func (g *gitHubClient) ListReviewComments(ctx context.Context, owner, repo string, number int) ([]ReviewComment, error) {
+	var all []ReviewComment
+	opts := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		comments, resp, err := g.client.PullRequests.ListComments(ctx, owner, repo, number, opts)
+		if err != nil {
+			g.logger.Error("failed to list review comments", "owner", owner, "repo", repo, "pr", number, "error", err)
+			return nil, err
+		}
+		for _, c := range comments {
+			all = append(all, toReviewComment(c))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+// GetReviewComment retrieves a single inline review comment by ID.
+func (g *gitHubClient) GetReviewComment(ctx context.Context, owner, repo string, commentID int64) (*ReviewComment, error) {
+	c, _, err := g.client.PullRequests.GetComment(ctx, owner, repo, commentID)
+	if err != nil {
+		g.logger.Error("failed to get review comment", "owner", owner, "repo", repo, "comment_id", commentID, "error", err)
+		return nil, err
+	}
+	rc := toReviewComment(c)
+	return &rc, nil
+}
+
+// ListReviewCommentReactions retrieves all reactions on an inline review comment.
+func (g *gitHubClient) ListReviewCommentReactions(ctx context.Context, owner, repo string, commentID int64) ([]Reaction, error) {
+	var all []Reaction
+	opts := &github.ListReactionOptions{ListOptions: github.ListOptions{PerPage: 100}}
+
+	for {
+		reactions, resp, err := g.client.Reactions.ListPullRequestCommentReactions(ctx, owner, repo, commentID, opts)
+		if err != nil {
+			g.logger.Error("failed to list review comment reactions", "owner", owner, "repo", repo, "comment_id", commentID, "error", err)
+			return nil, err
+		}
+		for _, r := range reactions {
+			all = append(all, Reaction{User: r.GetUser().GetLogin(), Content: r.GetContent()})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return all, nil
+}
+
+func toReviewComment(c *github.PullRequestComment) ReviewComment {
+	return ReviewComment{
+		ID:        c.GetID(),
+		NodeID:    c.GetNodeID(),
+		InReplyTo: c.GetInReplyTo(),
+		Path:      c.GetPath(),
+		Line:      c.GetLine(),
+		StartLine: c.GetStartLine(),
+		DiffHunk:  c.GetDiffHunk(),
+		Body:      c.GetBody(),
+		User:      c.GetUser().GetLogin(),
+	}
+}
+
+// UpdateReviewComment edits the body of an inline review comment.
+func (g *gitHubClient) UpdateReviewComment(ctx context.Context, owner, repo string, commentID int64, body string) error {
+	_, _, err := g.client.PullRequests.EditComment(ctx, owner, repo, commentID, &github.PullRequestComment{Body: &body})
+	if err != nil {
+		g.logger.Error("failed to update review comment", "owner", owner, "repo", repo, "comment_id", commentID, "error", err)
+	}
+	return err
+}
+
+// ReplyToReviewComment posts a reply in the thread of an inline review comment.
+func (g *gitHubClient) ReplyToReviewComment(ctx context.Context, owner, repo string, number int, commentID int64, body string) error {
+	_, _, err := g.client.PullRequests.CreateCommentInReplyTo(ctx, owner, repo, number, body, commentID)
+	if err != nil {
+		g.logger.Error("failed to reply to review comment", "owner", owner, "repo", repo, "comment_id", commentID, "error", err)
+	}
+	return err
+}
+
+const minimizeCommentMutation = `mutation($id: ID!, $reason: ReportedContentClassifiers!) {
+  minimizeComment(input: {subjectId: $id, classifier: $reason}) { minimizedComment { isMinimized } }
+}`
+
+// MinimizeComment hides a comment with the GraphQL minimizeComment
+// mutation, which has no REST equivalent.
+func (g *gitHubClient) MinimizeComment(ctx context.Context, nodeID, reason string) error {
+	req, err := g.client.NewRequest(http.MethodPost, graphQLURL(g.client.BaseURL), map[string]any{
+		"query":     minimizeCommentMutation,
+		"variables": map[string]string{"id": nodeID, "reason": reason},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build minimize request: %w", err)
+	}
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := g.client.Do(ctx, req, &result); err != nil {
+		return fmt.Errorf("failed to minimize comment %s: %w", nodeID, err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("failed to minimize comment %s: %s", nodeID, result.Errors[0].Message)
+	}
+	return nil
+}
+
+// graphQLURL returns the GraphQL endpoint of the REST API at baseURL:
+// https://api.github.com/graphql, or /api/graphql on GitHub Enterprise
+// Server, whose REST API is served under /api/v3/.
+func graphQLURL(baseURL *url.URL) string {
+	u := *baseURL
+	if prefix, ok := strings.CutSuffix(u.Path, "/api/v3/"); ok {
+		u.Path = prefix + "/api/graphql"
+		return u.String()
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/graphql"
+	return u.String()
+}
+
 // CreateCheckRun creates a new check run.
 func (g *gitHubClient) CreateCheckRun(ctx context.Context, owner, repo string, opts github.CreateCheckRunOptions) (*github.CheckRun, error) {
 	checkRun, _, err := g.client.Checks.CreateCheckRun(ctx, owner, repo, opts)
@@ -384,6 +590,48 @@ func (g *gitHubClient) GetIssue(ctx context.Context, owner, repo string, number
 	}, nil
 }
 
+// CreateIssue opens a new issue.
+func (g *gitHubClient) CreateIssue(ctx context.Context, owner, repo, title, body string) (*Issue, error) {
+	issue, _, err := g.client.Issues.Create(ctx, owner, repo, &github.IssueRequest{
+		Title: &title,
+		Body:  &body,
+	})
+	if err != nil {
+		g.logger.Error("failed to create issue", "owner", owner, "repo", repo, "error", err)
+		return nil, err
+	}
+
+	g.logger.Info("created issue", "owner", owner, "repo", repo, "issue", issue.GetNumber(), "url", issue.GetHTMLURL())
+	return &Issue{
+		Number: issue.GetNumber(),
+		Title:  issue.GetTitle(),
+		Body:   issue.GetBody(),
+		State:  issue.GetState(),
+		URL:    issue.GetHTMLURL(),
+	}, nil
+}
+
+// ListPullRequests lists the pull requests of a repository, following
+// pagination.
+func (g *gitHubClient) ListPullRequests(ctx context.Context, owner, repo, state string) ([]*github.PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State:       state,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	var all []*github.PullRequest
+	for {
+		prs, resp, err := g.client.PullRequests.List(ctx, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests of %s/%s: %w", owner, repo, err)
+		}
+		all = append(all, prs...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
 // GetBranch retrieves a single branch by its name.
 func (g *gitHubClient) GetBranch(ctx context.Context, owner, repo, branch string) (*github.Branch, error) {
 	b, _, err := g.client.Repositories.GetBranch(ctx, owner, repo, branch, 0)
@@ -393,3 +641,22 @@ func (g *gitHubClient) GetBranch(ctx context.Context, owner, repo, branch string
 	}
 	return b, nil
 }
+
+// GetFileContent retrieves the content of a file on the repository's default branch.
+func (g *gitHubClient) GetFileContent(ctx context.Context, owner, repo, path string) ([]byte, error) {
+	file, _, resp, err := g.client.Repositories.GetContents(ctx, owner, repo, path, nil)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get %s from %s/%s: %w", path, owner, repo, err)
+	}
+	if file == nil {
+		return nil, fmt.Errorf("failed to get %s from %s/%s: path is a directory", path, owner, repo)
+	}
+	content, err := file.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s from %s/%s: %w", path, owner, repo, err)
+	}
+	return []byte(content), nil
+}
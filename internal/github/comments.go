@@ -0,0 +1,160 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/stringsutil"
+)
+
+const (
+	summaryMarkerPrefix = "<!-- code-warden:summary "
+	historyStart        = "<!-- code-warden:history -->"
+	historyEnd          = "<!-- /code-warden:history -->"
+	outdatedMarker      = "<!-- code-warden:outdated -->"
+
+	// maxSummaryHistory caps the earlier rounds kept in the summary comment.
+	maxSummaryHistory = 20
+)
+
+// HideOutdatedComments marks Code-Warden's inline comments on the pull
+// request, those botLogin posted, whose line is no longer part of the diff,
+// and minimizes them as outdated. validLines maps each file of the diff to
+// its commentable lines. Comments already marked are skipped. It returns how
+// many were marked.
+func HideOutdatedComments(ctx context.Context, client Client, event *core.GitHubEvent, botLogin string, validLines map[string]map[int]struct{}, logger *slog.Logger) (int, error) {
+	comments, err := client.ListReviewComments(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list review comments: %w", err)
+	}
+
+	hidden := 0
+	for _, c := range comments {
+		if c.InReplyTo != 0 || strings.Contains(c.Body, outdatedMarker) {
+			continue
+		}
+		if _, ok := ParseSuggestion(&c, botLogin); !ok {
+			continue
+		}
+		if _, ok := validLines[c.Path][c.Line]; ok && c.Line > 0 {
+			continue
+		}
+
+		body := "> ⚠️ **Outdated** — this line changed after the review and is no longer part of the diff.\n\n" +
+			c.Body + "\n" + outdatedMarker
+		if err := client.UpdateReviewComment(ctx, event.RepoOwner, event.RepoName, c.ID, body); err != nil {
+			return hidden, fmt.Errorf("failed to mark comment %d outdated: %w", c.ID, err)
+		}
+		hidden++
+		// Minimizing needs the GraphQL API; the comment is marked either way.
+		if c.NodeID != "" {
+			if err := client.MinimizeComment(ctx, c.NodeID, "OUTDATED"); err != nil {
+				logger.Warn("failed to minimize outdated comment", "comment_id", c.ID, "error", err)
+			}
+		}
+	}
+	return hidden, nil
+}
+
+// upsertSummary edits the summary comment of the pull request to show
+// review, moving the round it replaces to its history, or posts it when the
+// pull request has none yet. It returns the ID of the comment.
+func (s *statusUpdater) upsertSummary(ctx context.Context, event *core.GitHubEvent, review *core.StructuredReview) (int64, error) {
+	comments, err := s.client.ListIssueComments(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list comments: %w", err)
+	}
+
+	var previous *IssueComment
+	for i := len(comments) - 1; i >= 0; i-- {
+		if strings.Contains(comments[i].Body, summaryMarkerPrefix) {
+			previous = &comments[i]
+			break
+		}
+	}
+
+	if previous != nil {
+		body := buildSummaryComment(event, review, previous.Body)
+		if err := s.client.UpdateComment(ctx, event.RepoOwner, event.RepoName, previous.ID, body); err == nil {
+			return previous.ID, nil
+		}
+		s.logger.Warn("failed to update summary comment, posting a new one", "comment_id", previous.ID, "error", err)
+	}
+
+	body := buildSummaryComment(event, review, "")
+	return s.client.CreateCommentID(ctx, event.RepoOwner, event.RepoName, event.PRNumber, body)
+}
+
+// buildSummaryComment renders the summary comment of review. previous is the
+// body of the comment it replaces, whose round and history are kept under
+// "Earlier reviews".
+func buildSummaryComment(event *core.GitHubEvent, review *core.StructuredReview, previous string) string {
+	var history []string
+	if previous != "" {
+		if entry := summaryHistoryEntry(previous); entry != "" {
+			history = append(history, entry)
+		}
+		history = append(history, parseSummaryHistory(previous)...)
+	}
+	if len(history) > maxSummaryHistory {
+		history = history[:maxSummaryHistory]
+	}
+
+	var sb strings.Builder
+	sb.WriteString(formatReviewSummary(review))
+	if len(history) > 0 {
+		fmt.Fprintf(&sb, "\n\n<details>\n<summary>Earlier reviews (%d)</summary>\n\n%s\n", len(history), historyStart)
+		for _, entry := range history {
+			sb.WriteString(entry + "\n")
+		}
+		sb.WriteString(historyEnd + "\n</details>")
+	}
+
+	v := url.Values{}
+	v.Set("sha", event.HeadSHA)
+	v.Set("verdict", review.Verdict)
+	v.Set("suggestions", strconv.Itoa(len(review.Suggestions)))
+	sb.WriteString("\n\n" + summaryMarkerPrefix + v.Encode() + " -->")
+	return sb.String()
+}
+
+// summaryHistoryEntry renders the round shown by a summary comment as a line
+// of the history, or returns "" when body has no summary marker.
+func summaryHistoryEntry(body string) string {
+	start := strings.LastIndex(body, summaryMarkerPrefix)
+	if start < 0 {
+		return ""
+	}
+	rest := body[start+len(summaryMarkerPrefix):]
+	end := strings.Index(rest, " -->")
+	if end < 0 {
+		return ""
+	}
+	v, err := url.ParseQuery(rest[:end])
+	if err != nil {
+		return ""
+	}
+	verdict := v.Get("verdict")
+	return fmt.Sprintf("- `%s` %s %s · %s suggestion(s)", stringsutil.TruncateSHA(v.Get("sha")), verdictIcon(verdict), verdict, v.Get("suggestions"))
+}
+
+// parseSummaryHistory returns the history lines of a summary comment.
+func parseSummaryHistory(body string) []string {
+	start := strings.Index(body, historyStart)
+	end := strings.Index(body, historyEnd)
+	if start < 0 || end < start {
+		return nil
+	}
+	var entries []string
+	for _, line := range strings.Split(body[start+len(historyStart):end], "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			entries = append(entries, line)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,114 @@
+package github_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+func TestHideOutdatedComments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockClient := mocks.NewMockClient(ctrl)
+	event := &core.GitHubEvent{RepoOwner: "owner", RepoName: "repo", PRNumber: 7}
+	marker := github.FeedbackMarker(github.SuggestionMeta{Severity: "High"})
+	const bot = "code-warden[bot]"
+
+	mockClient.EXPECT().ListReviewComments(gomock.Any(), "owner", "repo", 7).Return([]github.ReviewComment{
+		{ID: 1, NodeID: "n1", Path: "a.go", Line: 10, User: bot, Body: "still there\n\n" + marker},
+		{ID: 2, NodeID: "n2", Path: "a.go", Line: 0, User: bot, Body: "line gone\n\n" + marker},
+		{ID: 3, NodeID: "n3", Path: "b.go", Line: 5, User: bot, Body: "file left the diff\n\n" + marker},
+		{ID: 4, Path: "a.go", Line: 0, User: "alice", Body: "a human comment"},
+		{ID: 5, Path: "a.go", Line: 0, User: bot, InReplyTo: 2, Body: "reply\n\n" + marker},
+		{ID: 6, Path: "a.go", Line: 0, User: bot, Body: "marked before\n\n" + marker + "\n<!-- code-warden:outdated -->"},
+		{ID: 7, Path: "a.go", Line: 0, User: "alice", Body: "copied suggestion\n\n" + marker},
+	}, nil)
+	for _, c := range []struct {
+		id     int64
+		nodeID string
+	}{{2, "n2"}, {3, "n3"}} {
+		mockClient.EXPECT().UpdateReviewComment(gomock.Any(), "owner", "repo", c.id, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, _ int64, body string) error {
+				assert.True(t, strings.HasPrefix(body, "> ⚠️ **Outdated**"))
+				return nil
+			})
+		mockClient.EXPECT().MinimizeComment(gomock.Any(), c.nodeID, "OUTDATED").Return(nil)
+	}
+
+	validLines := map[string]map[int]struct{}{"a.go": {10: {}}}
+	hidden, err := github.HideOutdatedComments(context.Background(), mockClient, event, bot, validLines, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	assert.Equal(t, 2, hidden)
+}
+
+func TestPostStructuredReview_SummaryInPlace(t *testing.T) {
+	event := &core.GitHubEvent{RepoOwner: "owner", RepoName: "repo", PRNumber: 7, HeadSHA: "bbbbbbbbbb"}
+	review := &core.StructuredReview{
+		Verdict:     "COMMENT",
+		Suggestions: []core.Suggestion{{FilePath: "a.go", LineNumber: 3, Severity: "Low", Comment: "Nit"}},
+	}
+
+	t.Run("first review posts the summary", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockClient := mocks.NewMockClient(ctrl)
+		updater := github.NewStatusUpdater(mockClient, slog.New(slog.DiscardHandler), false, github.WithSummaryInPlace())
+
+		mockClient.EXPECT().ListIssueComments(gomock.Any(), "owner", "repo", 7).Return(nil, nil)
+		mockClient.EXPECT().CreateCommentID(gomock.Any(), "owner", "repo", 7, gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, _ int, body string) (int64, error) {
+				assert.Contains(t, body, "<!-- code-warden:summary ")
+				assert.NotContains(t, body, "Earlier reviews")
+				return 42, nil
+			})
+		mockClient.EXPECT().CreateReview(gomock.Any(), "owner", "repo", 7, "bbbbbbbbbb", gomock.Any(), gomock.Len(1)).
+			DoAndReturn(func(_ context.Context, _, _ string, _ int, _, body string, _ []github.DraftReviewComment) error {
+				assert.Contains(t, body, "#issuecomment-42")
+				return nil
+			})
+
+		require.NoError(t, updater.PostStructuredReview(context.Background(), event, review))
+	})
+
+	t.Run("later review edits it and keeps the history", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockClient := mocks.NewMockClient(ctrl)
+		updater := github.NewStatusUpdater(mockClient, slog.New(slog.DiscardHandler), false, github.WithSummaryInPlace())
+
+		previous := "## Summary\n\n<details>\n<summary>Earlier reviews (1)</summary>\n\n<!-- code-warden:history -->\n" +
+			"- `0000000` 🚫 REQUEST_CHANGES · 4 suggestion(s)\n<!-- /code-warden:history -->\n</details>\n\n" +
+			"<!-- code-warden:summary sha=aaaaaaaaaa&suggestions=2&verdict=APPROVE -->"
+		mockClient.EXPECT().ListIssueComments(gomock.Any(), "owner", "repo", 7).Return([]github.IssueComment{
+			{ID: 1, Body: "unrelated"},
+			{ID: 9, Body: previous},
+		}, nil)
+		mockClient.EXPECT().UpdateComment(gomock.Any(), "owner", "repo", int64(9), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, _ string, _ int64, body string) error {
+				assert.Contains(t, body, "Earlier reviews (2)")
+				assert.Less(t, strings.Index(body, "`aaaaaaa` ✅ APPROVE · 2 suggestion(s)"), strings.Index(body, "`0000000`"))
+				assert.Contains(t, body, "sha=bbbbbbbbbb")
+				return nil
+			})
+		mockClient.EXPECT().CreateReview(gomock.Any(), "owner", "repo", 7, "bbbbbbbbbb", gomock.Any(), gomock.Len(1)).Return(nil)
+
+		require.NoError(t, updater.PostStructuredReview(context.Background(), event, review))
+	})
+
+	t.Run("no inline comments skips the review", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		mockClient := mocks.NewMockClient(ctrl)
+		updater := github.NewStatusUpdater(mockClient, slog.New(slog.DiscardHandler), false, github.WithSummaryInPlace())
+
+		mockClient.EXPECT().ListIssueComments(gomock.Any(), "owner", "repo", 7).Return(nil, nil)
+		mockClient.EXPECT().CreateCommentID(gomock.Any(), "owner", "repo", 7, gomock.Any()).Return(int64(42), nil)
+
+		require.NoError(t, updater.PostStructuredReview(context.Background(), event, &core.StructuredReview{Verdict: "APPROVE"}))
+	})
+}
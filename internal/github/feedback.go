@@ -0,0 +1,64 @@
+package github
+
+import (
+	"net/url"
+	"strings"
+)
+
+const feedbackMarkerPrefix = "<!-- code-warden:suggestion "
+
+// SuggestionMeta is the provenance embedded in every posted inline suggestion
+// so reactions and "/warden" replies can later be attributed to the model and
+// prompt version that produced it.
+type SuggestionMeta struct {
+	Model         string
+	PromptVersion string
+	Severity      string
+	Category      string
+}
+
+// FeedbackMarker renders meta as a hidden HTML comment appended to an inline
+// review comment.
+func FeedbackMarker(meta SuggestionMeta) string {
+	v := url.Values{}
+	v.Set("model", meta.Model)
+	v.Set("prompt", meta.PromptVersion)
+	v.Set("severity", meta.Severity)
+	v.Set("category", meta.Category)
+	return feedbackMarkerPrefix + v.Encode() + " -->"
+}
+
+// ParseSuggestion extracts the SuggestionMeta of a review comment that
+// botLogin posted. It returns false for comments of anyone else, since
+// quoting or copying a suggestion also copies its marker.
+func ParseSuggestion(comment *ReviewComment, botLogin string) (SuggestionMeta, bool) {
+	if botLogin == "" || comment.User != botLogin {
+		return SuggestionMeta{}, false
+	}
+	return ParseFeedbackMarker(comment.Body)
+}
+
+// ParseFeedbackMarker extracts the SuggestionMeta from a comment body. It
+// returns false for bodies without a marker. It does not check who wrote the
+// body; use ParseSuggestion for comments.
+func ParseFeedbackMarker(body string) (SuggestionMeta, bool) {
+	start := strings.LastIndex(body, feedbackMarkerPrefix)
+	if start < 0 {
+		return SuggestionMeta{}, false
+	}
+	rest := body[start+len(feedbackMarkerPrefix):]
+	end := strings.Index(rest, " -->")
+	if end < 0 {
+		return SuggestionMeta{}, false
+	}
+	v, err := url.ParseQuery(rest[:end])
+	if err != nil {
+		return SuggestionMeta{}, false
+	}
+	return SuggestionMeta{
+		Model:         v.Get("model"),
+		PromptVersion: v.Get("prompt"),
+		Severity:      v.Get("severity"),
+		Category:      v.Get("category"),
+	}, true
+}
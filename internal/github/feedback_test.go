@@ -0,0 +1,34 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFeedbackMarker_RoundTrip(t *testing.T) {
+	meta := SuggestionMeta{Model: "consensus:a, b", PromptVersion: "0a1b2c3d4e5f+json", Severity: "High", Category: "Bug -->"}
+	body := "**Observation:** nil deref\n\n" + FeedbackMarker(meta)
+
+	got, ok := ParseFeedbackMarker(body)
+	assert.True(t, ok)
+	assert.Equal(t, meta, got)
+
+	_, ok = ParseFeedbackMarker("a human comment")
+	assert.False(t, ok)
+}
+
+func TestParseSuggestion_RequiresBotAuthor(t *testing.T) {
+	meta := SuggestionMeta{Model: "qwen", Severity: "High"}
+	body := "nil deref\n\n" + FeedbackMarker(meta)
+
+	got, ok := ParseSuggestion(&ReviewComment{User: "code-warden[bot]", Body: body}, "code-warden[bot]")
+	assert.True(t, ok)
+	assert.Equal(t, meta, got)
+
+	_, ok = ParseSuggestion(&ReviewComment{User: "mallory", Body: "> " + body}, "code-warden[bot]")
+	assert.False(t, ok, "a quoted marker must not count")
+
+	_, ok = ParseSuggestion(&ReviewComment{User: "", Body: body}, "")
+	assert.False(t, ok, "an unknown bot login matches nobody")
+}
@@ -0,0 +1,141 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/google/go-github/v73/github"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// Permission is an access level a GitHub App installation needs on one of
+// its repository permissions, named as in the App settings API.
+type Permission struct {
+	Name  string // e.g. "checks" or "pull_requests"
+	Level string // "read" or "write"
+}
+
+func (p Permission) String() string {
+	return p.Name + ":" + p.Level
+}
+
+// RequiredPermissions are the repository permissions reviews need: check
+// runs report progress, reviews and comments go to pull requests, and the
+// repository is cloned and its configuration read.
+var RequiredPermissions = []Permission{
+	{Name: "checks", Level: "write"},
+	{Name: "pull_requests", Level: "write"},
+	{Name: "contents", Level: "read"},
+}
+
+// MissingPermission is a required permission an installation lacks.
+type MissingPermission struct {
+	Permission
+	// Granted is the level the installation has, or "" when it has none.
+	Granted string
+}
+
+// PermissionError reports the required permissions an installation lacks.
+type PermissionError struct {
+	InstallationID int64
+	Missing        []MissingPermission
+}
+
+func (e *PermissionError) Error() string {
+	parts := make([]string, len(e.Missing))
+	for i, m := range e.Missing {
+		parts[i] = fmt.Sprintf("%s (granted: %s)", m.Permission, grantedOrNone(m.Granted))
+	}
+	return fmt.Sprintf("GitHub App installation %d is missing permissions %s; grant them in the App settings and accept the new permissions on the installation",
+		e.InstallationID, strings.Join(parts, ", "))
+}
+
+// Markdown renders the error as a pull request comment for the people who can
+// fix the installation.
+func (e *PermissionError) Markdown() string {
+	var b strings.Builder
+	b.WriteString("### ⚠️ Code-Warden cannot review this pull request\n\n")
+	b.WriteString("The GitHub App installation is missing permissions it needs:\n\n")
+	for _, m := range e.Missing {
+		fmt.Fprintf(&b, "- **%s**: needs `%s`, granted `%s`\n", permissionTitle(m.Name), m.Level, grantedOrNone(m.Granted))
+	}
+	b.WriteString("\nAn owner of the App can add them under **Settings → Developer settings → GitHub Apps → Permissions & events**. ")
+	b.WriteString("The organization or account that installed the App then has to accept the new permissions on the installation page before `/review` works.")
+	return b.String()
+}
+
+func grantedOrNone(level string) string {
+	if level == "" {
+		return "none"
+	}
+	return level
+}
+
+func permissionTitle(name string) string {
+	switch name {
+	case "pull_requests":
+		return "Pull requests"
+	default:
+		return strings.ToUpper(name[:1]) + name[1:]
+	}
+}
+
+// CheckPermissions compares the permissions granted to an installation with
+// RequiredPermissions and returns a *PermissionError for the missing ones.
+func CheckPermissions(installationID int64, granted *github.InstallationPermissions) error {
+	var missing []MissingPermission
+	for _, required := range RequiredPermissions {
+		level := grantedLevel(granted, required.Name)
+		if levelRank(level) < levelRank(required.Level) {
+			missing = append(missing, MissingPermission{Permission: required, Granted: level})
+		}
+	}
+	if len(missing) > 0 {
+		return &PermissionError{InstallationID: installationID, Missing: missing}
+	}
+	return nil
+}
+
+func grantedLevel(granted *github.InstallationPermissions, name string) string {
+	switch name {
+	case "checks":
+		return granted.GetChecks()
+	case "pull_requests":
+		return granted.GetPullRequests()
+	case "contents":
+		return granted.GetContents()
+	default:
+		return ""
+	}
+}
+
+func levelRank(level string) int {
+	switch level {
+	case "read":
+		return 1
+	case "write":
+		return 2
+	case "admin":
+		return 3
+	default:
+		return 0
+	}
+}
+
+// GetInstallationPermissions returns the permissions granted to an
+// installation.
+func GetInstallationPermissions(ctx context.Context, cfg *config.Config, installationID int64, logger *slog.Logger) (*github.InstallationPermissions, error) {
+	appClient, err := newAppClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	installation, _, err := appClient.Apps.GetInstallation(ctx, installationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get installation %d: %w", installationID, err)
+	}
+	logger.Debug("fetched installation permissions", "installation_id", installationID)
+	return installation.GetPermissions(), nil
+}
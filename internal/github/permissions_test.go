@@ -0,0 +1,84 @@
+package github
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v73/github"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	tests := []struct {
+		name    string
+		granted *github.InstallationPermissions
+		missing []string
+	}{
+		{
+			name: "all granted",
+			granted: &github.InstallationPermissions{
+				Checks: github.Ptr("write"), PullRequests: github.Ptr("write"), Contents: github.Ptr("read"),
+			},
+		},
+		{
+			name: "higher levels satisfy lower ones",
+			granted: &github.InstallationPermissions{
+				Checks: github.Ptr("admin"), PullRequests: github.Ptr("write"), Contents: github.Ptr("write"),
+			},
+		},
+		{
+			name: "read is not write",
+			granted: &github.InstallationPermissions{
+				Checks: github.Ptr("read"), PullRequests: github.Ptr("write"), Contents: github.Ptr("read"),
+			},
+			missing: []string{"checks:write/read"},
+		},
+		{
+			name:    "nothing granted",
+			granted: nil,
+			missing: []string{"checks:write/", "pull_requests:write/", "contents:read/"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckPermissions(7, tt.granted)
+			if len(tt.missing) == 0 {
+				if err != nil {
+					t.Fatalf("CheckPermissions() = %v, want nil", err)
+				}
+				return
+			}
+			var permErr *PermissionError
+			if !errors.As(err, &permErr) {
+				t.Fatalf("CheckPermissions() = %v, want a *PermissionError", err)
+			}
+			var got []string
+			for _, m := range permErr.Missing {
+				got = append(got, m.Permission.String()+"/"+m.Granted)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.missing, ",") {
+				t.Errorf("missing = %v, want %v", got, tt.missing)
+			}
+		})
+	}
+}
+
+func TestPermissionError_Messages(t *testing.T) {
+	err := &PermissionError{InstallationID: 7, Missing: []MissingPermission{
+		{Permission: Permission{Name: "checks", Level: "write"}, Granted: "read"},
+		{Permission: Permission{Name: "pull_requests", Level: "write"}},
+	}}
+
+	msg := err.Error()
+	for _, want := range []string{"installation 7", "checks:write (granted: read)", "pull_requests:write (granted: none)"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Error() = %q, missing %q", msg, want)
+		}
+	}
+	md := err.Markdown()
+	for _, want := range []string{"**Checks**: needs `write`, granted `read`", "**Pull requests**: needs `write`, granted `none`", "accept the new permissions"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown() = %q, missing %q", md, want)
+		}
+	}
+}
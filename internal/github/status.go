@@ -10,6 +10,7 @@ import (
 	"github.com/google/go-github/v73/github"
 
 	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/stringsutil"
 )
 
 // Severity emojis
@@ -39,6 +40,7 @@ const (
 // and posting comments on pull requests.
 type StatusUpdater interface {
 	InProgress(ctx context.Context, event *core.GitHubEvent, title, summary string) (int64, error)
+	Progress(ctx context.Context, event *core.GitHubEvent, checkRunID int64, title, summary string) error
 	Completed(ctx context.Context, event *core.GitHubEvent, checkRunID int64, conclusion, title, summary string) error
 	PostStructuredReview(ctx context.Context, event *core.GitHubEvent, review *core.StructuredReview) error
 	PostSimpleComment(ctx context.Context, event *core.GitHubEvent, body string) error
@@ -48,15 +50,31 @@ type statusUpdater struct {
 	client                Client
 	logger                *slog.Logger
 	enableCodeSuggestions bool
+	summaryInPlace        bool
+}
+
+// StatusUpdaterOption configures a StatusUpdater.
+type StatusUpdaterOption func(*statusUpdater)
+
+// WithSummaryInPlace keeps the review summary in a single pull request
+// comment, edited on every review, instead of the body of each review.
+func WithSummaryInPlace() StatusUpdaterOption {
+	return func(s *statusUpdater) {
+		s.summaryInPlace = true
+	}
 }
 
 // NewStatusUpdater creates and returns a new instance of a statusUpdater.
-func NewStatusUpdater(client Client, logger *slog.Logger, enableCodeSuggestions bool) StatusUpdater {
-	return &statusUpdater{
+func NewStatusUpdater(client Client, logger *slog.Logger, enableCodeSuggestions bool, opts ...StatusUpdaterOption) StatusUpdater {
+	s := &statusUpdater{
 		client:                client,
 		logger:                logger,
 		enableCodeSuggestions: enableCodeSuggestions,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // PostSimpleComment posts a single, general comment on the pull request.
@@ -82,6 +100,19 @@ func (s *statusUpdater) InProgress(ctx context.Context, event *core.GitHubEvent,
 	return checkRun.GetID(), nil
 }
 
+// Progress updates the output of a Check Run that is still in progress.
+func (s *statusUpdater) Progress(ctx context.Context, event *core.GitHubEvent, checkRunID int64, title, summary string) error {
+	opts := github.UpdateCheckRunOptions{
+		Status: github.Ptr("in_progress"),
+		Output: &github.CheckRunOutput{
+			Title:   &title,
+			Summary: &summary,
+		},
+	}
+	_, err := s.client.UpdateCheckRun(ctx, event.RepoOwner, event.RepoName, checkRunID, opts)
+	return err
+}
+
 // Completed updates an existing GitHub Check Run to a "completed" status.
 func (s *statusUpdater) Completed(ctx context.Context, event *core.GitHubEvent, checkRunID int64, conclusion, title, summary string) error {
 	now := time.Now()
@@ -123,6 +154,12 @@ func (s *statusUpdater) PostStructuredReview(ctx context.Context, event *core.Gi
 		if formattedComment == "" {
 			continue
 		}
+		formattedComment += "\n\n" + FeedbackMarker(SuggestionMeta{
+			Model:         review.Model,
+			PromptVersion: review.PromptVersion,
+			Severity:      sug.Severity,
+			Category:      sug.Category,
+		})
 
 		// Use validated StartLine (normalized by validator if it was invalid)
 		startLine := sug.StartLine
@@ -137,6 +174,18 @@ func (s *statusUpdater) PostStructuredReview(ctx context.Context, event *core.Gi
 		})
 	}
 
+	if s.summaryInPlace {
+		summaryID, err := s.upsertSummary(ctx, event, review)
+		if err != nil {
+			return fmt.Errorf("failed to post review summary: %w", err)
+		}
+		if len(comments) == 0 {
+			return nil
+		}
+		body := fmt.Sprintf("Findings for `%s`; see the [review summary](#issuecomment-%d).", stringsutil.TruncateSHA(event.HeadSHA), summaryID)
+		return s.client.CreateReview(ctx, event.RepoOwner, event.RepoName, event.PRNumber, event.HeadSHA, body, comments)
+	}
+
 	formattedSummary := formatReviewSummary(review)
 	return s.client.CreateReview(ctx, event.RepoOwner, event.RepoName, event.PRNumber, event.HeadSHA, formattedSummary, comments)
 }
@@ -161,6 +210,9 @@ func formatInlineComment(ctx context.Context, sug core.Suggestion) string {
 	if sug.Category != "" {
 		fmt.Fprintf(&sb, " — %s", sug.Category)
 	}
+	if url := core.CWEURL(sug.CWE); url != "" {
+		fmt.Fprintf(&sb, " · [%s](%s)", sug.CWE, url)
+	}
 	sb.WriteString("\n\n")
 
 	// 2. Process Comment
@@ -201,6 +253,12 @@ func formatInlineComment(ctx context.Context, sug core.Suggestion) string {
 		fmt.Fprintf(&sb, "*📍 Source: `%s`*", sug.Source)
 	}
 
+	// 6. Name the linter that reported the finding
+	if sug.Origin != "" {
+		sb.WriteString("\n\n")
+		fmt.Fprintf(&sb, "*🔎 Reported by static analysis: `%s`*", sug.Origin)
+	}
+
 	return sb.String()
 }
 
@@ -330,6 +388,31 @@ func formatReviewSummary(review *core.StructuredReview) string {
 		sb.WriteString(stats)
 	}
 
+	if review.Delta != nil {
+		sb.WriteString(buildReviewDelta(review.Delta))
+	}
+
+	if review.CommitHygiene != nil {
+		sb.WriteString(buildCommitHygiene(review.CommitHygiene))
+	}
+
+	if len(review.MissingTests) > 0 {
+		sb.WriteString(buildMissingTests(review.MissingTests))
+	}
+
+	if len(review.SubProjects) > 0 {
+		sb.WriteString(buildSubProjects(review.SubProjects))
+	}
+
+	if review.Dependencies != nil {
+		sb.WriteString(buildDependencies(review.Dependencies))
+	}
+
+	// Per-model ratings (consensus reviews only)
+	if len(review.ModelRatings) > 0 {
+		sb.WriteString(buildModelRatingsTable(review.ModelRatings))
+	}
+
 	sb.WriteString("\n\n---\n")
 	sb.WriteString("> 💡 Reply with `/rereview` to trigger a new review.")
 
@@ -371,6 +454,151 @@ func buildCompactStats(suggestions []core.Suggestion) string {
 	return fmt.Sprintf("*Found %d suggestion(s): %s*\n\n", total, strings.Join(parts, ", "))
 }
 
+// buildCommitHygiene renders the commit message checks as a section listing
+// each commit that needs attention.
+func buildCommitHygiene(hygiene *core.CommitHygiene) string {
+	var sb strings.Builder
+	sb.WriteString("### 📝 Commit hygiene\n\n")
+	if len(hygiene.Issues) == 0 {
+		fmt.Fprintf(&sb, "All %d commit(s) follow the commit conventions.\n\n", hygiene.Commits)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "%d of %d commit(s) need attention:\n\n", len(hygiene.Issues), hygiene.Commits)
+	for _, issue := range hygiene.Issues {
+		subject := strings.ReplaceAll(issue.Subject, "`", "'")
+		if subject == "" {
+			subject = "(empty)"
+		}
+		fmt.Fprintf(&sb, "- `%s` — %s\n", subject, strings.Join(issue.Problems, "; "))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// buildMissingTests renders the suggested test cases for changed files whose
+// tests were not changed.
+func buildMissingTests(missing []core.MissingTest) string {
+	var sb strings.Builder
+	sb.WriteString("### 🧪 Missing tests\n\n")
+	for _, m := range missing {
+		action := "add to"
+		if !m.Exists {
+			action = "create"
+		}
+		fmt.Fprintf(&sb, "**`%s`** — %s `%s`:\n", m.File, action, m.TestFile)
+		for _, c := range m.Cases {
+			fmt.Fprintf(&sb, "- %s\n", c)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// deltaTitleLen bounds the first line of a finding listed in the delta.
+const deltaTitleLen = 120
+
+// buildReviewDelta renders how the findings changed since the previous
+// review: the new ones are posted inline, the still open and resolved ones
+// are listed.
+func buildReviewDelta(delta *core.ReviewDelta) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### 🔁 Since the review of `%s`\n\n", stringsutil.TruncateSHA(delta.PreviousSHA))
+	fmt.Fprintf(&sb, "🆕 %d new · ⏳ %d still open · ✅ %d resolved\n\n", len(delta.New), len(delta.Open), len(delta.Resolved))
+	for _, section := range []struct {
+		title    string
+		findings []core.Suggestion
+	}{
+		{"⏳ Still open", delta.Open},
+		{"✅ Resolved", delta.Resolved},
+	} {
+		if len(section.findings) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "<details>\n<summary>%s (%d)</summary>\n\n", section.title, len(section.findings))
+		for _, f := range section.findings {
+			title, _, _ := strings.Cut(strings.TrimSpace(f.Comment), "\n")
+			fmt.Fprintf(&sb, "- %s **%s:%d** %s\n", SeverityEmoji(f.Severity), f.FilePath, f.LineNumber,
+				stringsutil.Truncate(title, deltaTitleLen, "..."))
+		}
+		sb.WriteString("\n</details>\n\n")
+	}
+	return sb.String()
+}
+
+// buildSubProjects renders a table of the sub-projects the pull request
+// touches with their changed files and suggestions by severity.
+func buildSubProjects(summaries []core.SubProjectSummary) string {
+	var sb strings.Builder
+	sb.WriteString("### 🗂️ Sub-projects\n\n")
+	sb.WriteString("| Sub-project | Files | Suggestions |\n")
+	sb.WriteString("|-------------|-------|-------------|\n")
+	for _, p := range summaries {
+		var parts []string
+		for _, severity := range []string{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow} {
+			if count := p.Suggestions[severity]; count > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", count, severity))
+			}
+		}
+		suggestions := strings.Join(parts, ", ")
+		if suggestions == "" {
+			suggestions = "none"
+		}
+		fmt.Fprintf(&sb, "| %s (`%s/`) | %d | %s |\n", p.Name, p.Path, p.Files, suggestions)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// buildDependencies renders the vulnerability and license findings of the
+// added or upgraded dependencies.
+func buildDependencies(report *core.DependencyReport) string {
+	var sb strings.Builder
+	sb.WriteString("### 📦 Dependency changes\n\n")
+	if len(report.Findings) == 0 {
+		fmt.Fprintf(&sb, "No known vulnerabilities or flagged licenses in %d added or upgraded dependencies.\n\n", report.Checked)
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "%d of %d added or upgraded dependencies need attention:\n\n", len(report.Findings), report.Checked)
+	for _, f := range report.Findings {
+		version := f.Version
+		if f.PreviousVersion != "" {
+			version = f.PreviousVersion + " → " + f.Version
+		}
+		fmt.Fprintf(&sb, "- **`%s`** %s (%s, `%s`)\n", f.Name, version, f.Ecosystem, f.File)
+		for _, v := range f.Vulnerabilities {
+			line := fmt.Sprintf("[%s](https://osv.dev/vulnerability/%s)", v.ID, v.ID)
+			if len(v.Aliases) > 0 {
+				line += " (" + strings.Join(v.Aliases, ", ") + ")"
+			}
+			if v.Summary != "" {
+				line += ": " + v.Summary
+			}
+			fmt.Fprintf(&sb, "  - %s %s\n", SeverityEmojiHigh, line)
+		}
+		if len(f.Licenses) > 0 {
+			fmt.Fprintf(&sb, "  - ⚖️ License: %s\n", strings.Join(f.Licenses, ", "))
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// buildModelRatingsTable renders the consensus synthesizer's per-model
+// ratings as a collapsible markdown table.
+func buildModelRatingsTable(ratings []core.ModelRating) string {
+	var sb strings.Builder
+	sb.WriteString("<details>\n<summary>📊 Model ratings</summary>\n\n")
+	sb.WriteString("| Model | Score | Rationale |\n|---|---|---|\n")
+	cell := strings.NewReplacer("|", "\\|", "\r", " ", "\n", " ")
+	for _, r := range ratings {
+		fmt.Fprintf(&sb, "| `%s` | %d/10 | %s |\n", r.Model, r.Score, cell.Replace(r.Rationale))
+	}
+	sb.WriteString("\n</details>\n\n")
+	return sb.String()
+}
+
 // SeverityEmoji returns the emoji for a given severity level
 func SeverityEmoji(severity string) string {
 	switch severity {
@@ -35,6 +35,20 @@ func TestFormatInlineComment(t *testing.T) {
 				"> [!CAUTION]",
 			},
 		},
+		{
+			name: "CWE identifier links to MITRE",
+			sug: core.Suggestion{
+				FilePath:   "db.go",
+				LineNumber: 12,
+				Severity:   "Critical",
+				Category:   "Injection",
+				CWE:        "CWE-89",
+				Comment:    "The query is built from request input.",
+			},
+			contains: []string{
+				"**🔴 Critical** — Injection · [CWE-89](https://cwe.mitre.org/data/definitions/89.html)",
+			},
+		},
 		{
 			name: "high severity uses GitHub alert",
 			sug: core.Suggestion{
@@ -189,6 +203,20 @@ func TestFormatInlineComment(t *testing.T) {
 				"```suggestion\nfunc fast() {\n  // optimized\n}\n```",
 			},
 		},
+		{
+			name: "names the linter of a static analysis finding",
+			sug: core.Suggestion{
+				FilePath:   "store.go",
+				LineNumber: 31,
+				Severity:   "Medium",
+				Category:   "Static Analysis",
+				Comment:    "**golangci-lint** (`errcheck`): Error return value is not checked",
+				Origin:     "golangci-lint:errcheck",
+			},
+			contains: []string{
+				"*🔎 Reported by static analysis: `golangci-lint:errcheck`*",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -289,6 +317,136 @@ func TestFormatReviewSummary(t *testing.T) {
 				"🟢 1 Low",
 			},
 		},
+		{
+			name: "consensus review shows model ratings",
+			review: &core.StructuredReview{
+				Verdict: "COMMENT",
+				Summary: "Merged findings.",
+				ModelRatings: []core.ModelRating{
+					{Model: "qwen3-coder", Score: 9, Rationale: "Found the race | no noise."},
+					{Model: "llama3", Score: 4, Rationale: "Mostly\nstyle nits."},
+				},
+			},
+			contains: []string{
+				"<summary>📊 Model ratings</summary>",
+				"| `qwen3-coder` | 9/10 | Found the race \\| no noise. |",
+				"| `llama3` | 4/10 | Mostly style nits. |",
+			},
+		},
+		{
+			name: "sub-project section",
+			review: &core.StructuredReview{
+				Verdict: "COMMENT",
+				SubProjects: []core.SubProjectSummary{
+					{Name: "billing", Path: "services/billing", Files: 3, Suggestions: map[string]int{"High": 1, "Low": 2}},
+					{Name: "web", Path: "web", Files: 1},
+				},
+			},
+			contains: []string{
+				"### 🗂️ Sub-projects",
+				"| billing (`services/billing/`) | 3 | 1 High, 2 Low |",
+				"| web (`web/`) | 1 | none |",
+			},
+		},
+		{
+			name: "delta since the previous review",
+			review: &core.StructuredReview{
+				Verdict:     "COMMENT",
+				Suggestions: []core.Suggestion{{Severity: "High"}},
+				Delta: &core.ReviewDelta{
+					PreviousSHA: "abc1234def",
+					New:         []core.Suggestion{{Severity: "High"}},
+					Open:        []core.Suggestion{{Severity: "Medium", FilePath: "api.go", LineNumber: 12, Comment: "Unchecked error.\nDetails."}},
+					Resolved: []core.Suggestion{
+						{Severity: "Low", FilePath: "db.go", LineNumber: 3, Comment: "Typo."},
+						{Severity: "Critical", FilePath: "auth.go", LineNumber: 40, Comment: "SQL injection."},
+					},
+				},
+			},
+			contains: []string{
+				"### 🔁 Since the review of `abc1234`",
+				"🆕 1 new · ⏳ 1 still open · ✅ 2 resolved",
+				"<summary>⏳ Still open (1)</summary>",
+				"- 🟡 **api.go:12** Unchecked error.\n",
+				"<summary>✅ Resolved (2)</summary>",
+				"- 🔴 **auth.go:40** SQL injection.",
+			},
+			excludes: []string{"Details."},
+		},
+		{
+			name: "commit hygiene section",
+			review: &core.StructuredReview{
+				Verdict: "COMMENT",
+				CommitHygiene: &core.CommitHygiene{
+					Commits: 3,
+					Issues: []core.CommitIssue{
+						{Subject: "wip `x`", Problems: []string{"work-in-progress commit", "subject ends with a period"}},
+					},
+				},
+			},
+			contains: []string{
+				"### 📝 Commit hygiene",
+				"1 of 3 commit(s) need attention:",
+				"- `wip 'x'` — work-in-progress commit; subject ends with a period",
+			},
+		},
+		{
+			name: "clean commit hygiene",
+			review: &core.StructuredReview{
+				Verdict:       "APPROVE",
+				CommitHygiene: &core.CommitHygiene{Commits: 2},
+			},
+			contains: []string{"All 2 commit(s) follow the commit conventions."},
+		},
+		{
+			name:     "no commit hygiene section when disabled",
+			review:   &core.StructuredReview{Verdict: "APPROVE"},
+			excludes: []string{"Commit hygiene"},
+		},
+		{
+			name: "missing tests section",
+			review: &core.StructuredReview{
+				Verdict: "COMMENT",
+				MissingTests: []core.MissingTest{
+					{File: "internal/api/page.go", TestFile: "internal/api/page_test.go", Exists: true, Cases: []string{"Paginate with a zero limit returns an error"}},
+					{File: "web/src/list.ts", TestFile: "web/src/list.test.ts", Cases: []string{"renders an empty list"}},
+				},
+			},
+			contains: []string{
+				"### 🧪 Missing tests",
+				"**`internal/api/page.go`** — add to `internal/api/page_test.go`:\n- Paginate with a zero limit returns an error",
+				"**`web/src/list.ts`** — create `web/src/list.test.ts`:\n- renders an empty list",
+			},
+		},
+		{
+			name: "dependency findings section",
+			review: &core.StructuredReview{
+				Verdict: "COMMENT",
+				Dependencies: &core.DependencyReport{
+					Checked: 3,
+					Findings: []core.DependencyFinding{{
+						Ecosystem: "npm", Name: "lodash", Version: "4.17.15", PreviousVersion: "4.17.11", File: "package.json",
+						Vulnerabilities: []core.Vulnerability{{ID: "GHSA-35jh-r3h4-6jhm", Aliases: []string{"CVE-2021-23337"}, Summary: "Command Injection in lodash"}},
+						Licenses:        []string{"GPL-3.0-only"},
+					}},
+				},
+			},
+			contains: []string{
+				"### 📦 Dependency changes",
+				"1 of 3 added or upgraded dependencies need attention:",
+				"- **`lodash`** 4.17.11 → 4.17.15 (npm, `package.json`)",
+				"[GHSA-35jh-r3h4-6jhm](https://osv.dev/vulnerability/GHSA-35jh-r3h4-6jhm) (CVE-2021-23337): Command Injection in lodash",
+				"⚖️ License: GPL-3.0-only",
+			},
+		},
+		{
+			name: "clean dependency changes",
+			review: &core.StructuredReview{
+				Verdict:      "APPROVE",
+				Dependencies: &core.DependencyReport{Checked: 2},
+			},
+			contains: []string{"No known vulnerabilities or flagged licenses in 2 added or upgraded dependencies."},
+		},
 	}
 
 	for _, tt := range tests {
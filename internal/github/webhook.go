@@ -0,0 +1,230 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v73/github"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// ErrNoWebhookSecret is the cause of rejecting a signed delivery when no
+// github.webhook_secret is configured to check its signature with.
+var ErrNoWebhookSecret = errors.New("github.webhook_secret is not configured")
+
+// deliveryPruneInterval is how often the WebhookVerifier forgets expired
+// delivery IDs.
+const deliveryPruneInterval = time.Hour
+
+// WebhookDeliveries remembers the IDs of accepted webhook deliveries; see
+// storage.WebhookDeliveryStore.
+type WebhookDeliveries interface {
+	ClaimWebhookDelivery(ctx context.Context, id string, notBefore time.Time) (bool, error)
+	ReleaseWebhookDelivery(ctx context.Context, id string) error
+	DeleteWebhookDeliveriesBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// WebhookError rejects a webhook delivery with an HTTP status and a reason
+// safe to return to the sender.
+type WebhookError struct {
+	Status int
+	Reason string
+	Err    error
+}
+
+func (e *WebhookError) Error() string {
+	if e.Err != nil {
+		return e.Reason + ": " + e.Err.Error()
+	}
+	return e.Reason
+}
+
+func (e *WebhookError) Unwrap() error {
+	return e.Err
+}
+
+// WebhookDelivery is a verified webhook delivery.
+type WebhookDelivery struct {
+	// ID is the X-GitHub-Delivery header.
+	ID string
+	// Event is the X-GitHub-Event header, e.g. "pull_request".
+	Event string
+	// Payload is the JSON payload, also for form-encoded deliveries.
+	Payload []byte
+}
+
+// WebhookVerifier checks GitHub webhook deliveries before they are parsed:
+// their size, their HMAC signature and, when deliveries are remembered, that
+// they were not accepted before.
+type WebhookVerifier struct {
+	secret     []byte
+	maxBytes   int64
+	ttl        time.Duration
+	deliveries WebhookDeliveries
+	logger     *slog.Logger
+	now        func() time.Time
+
+	mu         sync.Mutex
+	lastPruned time.Time
+}
+
+// NewWebhookVerifier creates a WebhookVerifier for deliveries signed with
+// secret. Replays are only rejected when deliveries is not nil and
+// cfg.DeliveryTTL is positive.
+func NewWebhookVerifier(secret string, cfg config.WebhookConfig, deliveries WebhookDeliveries, logger *slog.Logger) *WebhookVerifier {
+	return &WebhookVerifier{
+		secret:     []byte(secret),
+		maxBytes:   int64(cfg.MaxPayloadKB) * 1024,
+		ttl:        cfg.DeliveryTTL,
+		deliveries: deliveries,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// Verify reads the delivery of r and returns it, or a *WebhookError with
+// the status to answer: 400 for missing headers or a malformed payload, 401
+// for a missing or wrong signature, 409 for a replayed delivery, 413 for a
+// payload over the size limit and 415 for an unsupported content type.
+//
+// Only deliveries with a verified signature are remembered, so unsigned
+// requests cannot use up delivery IDs; without a secret nothing is. A
+// remembered delivery that could not be processed should be passed to
+// Forget, so that GitHub can deliver it again. A manual redelivery of an
+// accepted delivery reuses its ID and is rejected within the TTL.
+func (v *WebhookVerifier) Verify(r *http.Request) (*WebhookDelivery, error) {
+	delivery := &WebhookDelivery{
+		ID:    r.Header.Get(github.DeliveryIDHeader),
+		Event: r.Header.Get(github.EventTypeHeader),
+	}
+	if delivery.Event == "" {
+		return nil, &WebhookError{Status: http.StatusBadRequest, Reason: "missing " + github.EventTypeHeader + " header"}
+	}
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (contentType != "application/json" && contentType != "application/x-www-form-urlencoded") {
+		return nil, &WebhookError{Status: http.StatusUnsupportedMediaType, Reason: "unsupported content type", Err: err}
+	}
+
+	body, err := v.readBody(r)
+	if err != nil {
+		return nil, err
+	}
+
+	signature := r.Header.Get(github.SHA256SignatureHeader)
+	if signature == "" {
+		signature = r.Header.Get(github.SHA1SignatureHeader)
+	}
+	if signature == "" && len(v.secret) > 0 {
+		return nil, &WebhookError{Status: http.StatusUnauthorized, Reason: "missing signature"}
+	}
+	if signature != "" && len(v.secret) == 0 {
+		return nil, &WebhookError{Status: http.StatusUnauthorized, Reason: "invalid signature", Err: ErrNoWebhookSecret}
+	}
+	if signature != "" {
+		if err := github.ValidateSignature(signature, body, v.secret); err != nil {
+			return nil, &WebhookError{Status: http.StatusUnauthorized, Reason: "invalid signature", Err: err}
+		}
+	}
+
+	// The signature covers the raw body; extract the JSON from it.
+	delivery.Payload, err = github.ValidatePayloadFromBody(contentType, bytes.NewReader(body), "", nil)
+	if err != nil || len(delivery.Payload) == 0 {
+		return nil, &WebhookError{Status: http.StatusBadRequest, Reason: "malformed payload", Err: err}
+	}
+
+	if signature != "" {
+		if err := v.claim(r.Context(), delivery.ID); err != nil {
+			return nil, err
+		}
+	}
+	return delivery, nil
+}
+
+// readBody reads the body of r, failing with 413 once it exceeds maxBytes. A
+// maxBytes of zero reads it whole.
+func (v *WebhookVerifier) readBody(r *http.Request) ([]byte, error) {
+	if v.maxBytes <= 0 {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, &WebhookError{Status: http.StatusBadRequest, Reason: "failed to read payload", Err: err}
+		}
+		return body, nil
+	}
+	tooLarge := &WebhookError{Status: http.StatusRequestEntityTooLarge, Reason: fmt.Sprintf("payload exceeds %d bytes", v.maxBytes)}
+	if r.ContentLength > v.maxBytes {
+		return nil, tooLarge
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, v.maxBytes+1))
+	if err != nil {
+		return nil, &WebhookError{Status: http.StatusBadRequest, Reason: "failed to read payload", Err: err}
+	}
+	if int64(len(body)) > v.maxBytes {
+		return nil, tooLarge
+	}
+	return body, nil
+}
+
+// claim remembers a delivery ID, failing for IDs accepted within the TTL. A
+// store that cannot be reached lets the delivery through rather than drop
+// it.
+func (v *WebhookVerifier) claim(ctx context.Context, id string) error {
+	if v.deliveries == nil || v.ttl <= 0 {
+		return nil
+	}
+	if id == "" {
+		return &WebhookError{Status: http.StatusBadRequest, Reason: "missing " + github.DeliveryIDHeader + " header"}
+	}
+
+	now := v.now()
+	claimed, err := v.deliveries.ClaimWebhookDelivery(ctx, id, now.Add(-v.ttl))
+	if err != nil {
+		v.logger.WarnContext(ctx, "failed to record webhook delivery, accepting it without replay check", "delivery", id, "error", err)
+		return nil
+	}
+	if !claimed {
+		return &WebhookError{Status: http.StatusConflict, Reason: "delivery " + id + " was already processed"}
+	}
+	v.prune(ctx, now)
+	return nil
+}
+
+// prune forgets expired delivery IDs, at most once per deliveryPruneInterval.
+func (v *WebhookVerifier) prune(ctx context.Context, now time.Time) {
+	v.mu.Lock()
+	if now.Sub(v.lastPruned) < deliveryPruneInterval {
+		v.mu.Unlock()
+		return
+	}
+	v.lastPruned = now
+	v.mu.Unlock()
+
+	deleted, err := v.deliveries.DeleteWebhookDeliveriesBefore(ctx, now.Add(-v.ttl))
+	if err != nil {
+		v.logger.WarnContext(ctx, "failed to prune webhook deliveries", "error", err)
+		return
+	}
+	if deleted > 0 {
+		v.logger.DebugContext(ctx, "pruned webhook deliveries", "count", deleted)
+	}
+}
+
+// Forget releases the ID of a delivery that failed, so a redelivery of it
+// from GitHub is accepted.
+func (v *WebhookVerifier) Forget(ctx context.Context, delivery *WebhookDelivery) {
+	if v.deliveries == nil || v.ttl <= 0 || delivery == nil || delivery.ID == "" {
+		return
+	}
+	if err := v.deliveries.ReleaseWebhookDelivery(context.WithoutCancel(ctx), delivery.ID); err != nil {
+		v.logger.WarnContext(ctx, "failed to release webhook delivery", "delivery", delivery.ID, "error", err)
+	}
+}
@@ -0,0 +1,232 @@
+package github
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+const testSecret = "s3cret"
+
+// fakeDeliveries keeps delivery IDs and when they were accepted in memory.
+type fakeDeliveries struct {
+	seen map[string]time.Time
+}
+
+func (d *fakeDeliveries) ClaimWebhookDelivery(_ context.Context, id string, notBefore time.Time) (bool, error) {
+	if at, ok := d.seen[id]; ok && !at.Before(notBefore) {
+		return false, nil
+	}
+	// notBefore is now minus the one-hour TTL of newTestVerifier.
+	d.seen[id] = notBefore.Add(time.Hour)
+	return true, nil
+}
+
+func (d *fakeDeliveries) ReleaseWebhookDelivery(_ context.Context, id string) error {
+	delete(d.seen, id)
+	return nil
+}
+
+func (d *fakeDeliveries) DeleteWebhookDeliveriesBefore(_ context.Context, cutoff time.Time) (int64, error) {
+	var n int64
+	for id, at := range d.seen {
+		if at.Before(cutoff) {
+			delete(d.seen, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookRequest(id, contentType, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/webhook/github", strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	r.Header.Set("X-GitHub-Event", "pull_request")
+	r.Header.Set("X-GitHub-Delivery", id)
+	r.Header.Set("X-Hub-Signature-256", sign(body))
+	return r
+}
+
+func newTestVerifier(deliveries WebhookDeliveries) *WebhookVerifier {
+	cfg := config.WebhookConfig{MaxPayloadKB: 1, DeliveryTTL: time.Hour}
+	v := NewWebhookVerifier(testSecret, cfg, deliveries, slog.New(slog.DiscardHandler))
+	v.now = func() time.Time { return time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC) }
+	return v
+}
+
+func requireStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	var whErr *WebhookError
+	require.True(t, errors.As(err, &whErr), "expected a WebhookError, got %v", err)
+	assert.Equal(t, status, whErr.Status, whErr.Error())
+}
+
+func TestWebhookVerifier_Accepts(t *testing.T) {
+	v := newTestVerifier(nil)
+
+	delivery, err := v.Verify(webhookRequest("1", "application/json; charset=utf-8", `{"action":"opened"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "1", delivery.ID)
+	assert.Equal(t, "pull_request", delivery.Event)
+	assert.JSONEq(t, `{"action":"opened"}`, string(delivery.Payload))
+
+	form := url.Values{"payload": {`{"action":"closed"}`}}.Encode()
+	delivery, err = v.Verify(webhookRequest("2", "application/x-www-form-urlencoded", form))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"action":"closed"}`, string(delivery.Payload))
+}
+
+func TestWebhookVerifier_NoSecret(t *testing.T) {
+	v := NewWebhookVerifier("", config.WebhookConfig{MaxPayloadKB: 1}, nil, slog.New(slog.DiscardHandler))
+
+	_, err := v.Verify(webhookRequest("1", "application/json", `{"action":"opened"}`))
+	requireStatus(t, err, http.StatusUnauthorized)
+	assert.ErrorIs(t, err, ErrNoWebhookSecret, "the log names the missing secret")
+}
+
+func TestWebhookVerifier_UnsignedNotRemembered(t *testing.T) {
+	deliveries := &fakeDeliveries{seen: make(map[string]time.Time)}
+	v := NewWebhookVerifier("", config.WebhookConfig{MaxPayloadKB: 1, DeliveryTTL: time.Hour}, deliveries, slog.New(slog.DiscardHandler))
+
+	for range 2 {
+		r := webhookRequest("abc", "application/json", `{"action":"opened"}`)
+		r.Header.Del("X-Hub-Signature-256")
+		_, err := v.Verify(r)
+		require.NoError(t, err)
+	}
+	assert.Empty(t, deliveries.seen, "unsigned deliveries do not use up delivery IDs")
+}
+
+func TestWebhookVerifier_Rejects(t *testing.T) {
+	body := `{"action":"opened"}`
+	tests := []struct {
+		name   string
+		req    func() *http.Request
+		status int
+	}{
+		{
+			name: "tampered payload",
+			req: func() *http.Request {
+				r := webhookRequest("1", "application/json", `{"action":"closed"}`)
+				r.Header.Set("X-Hub-Signature-256", sign(body))
+				return r
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "tampered signature",
+			req: func() *http.Request {
+				r := webhookRequest("1", "application/json", body)
+				r.Header.Set("X-Hub-Signature-256", "sha256="+strings.Repeat("0", 64))
+				return r
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "missing signature",
+			req: func() *http.Request {
+				r := webhookRequest("1", "application/json", body)
+				r.Header.Del("X-Hub-Signature-256")
+				return r
+			},
+			status: http.StatusUnauthorized,
+		},
+		{
+			name: "missing event",
+			req: func() *http.Request {
+				r := webhookRequest("1", "application/json", body)
+				r.Header.Del("X-GitHub-Event")
+				return r
+			},
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "unsupported content type",
+			req:    func() *http.Request { return webhookRequest("1", "text/plain", body) },
+			status: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "payload too large",
+			req: func() *http.Request {
+				return webhookRequest("1", "application/json", `{"a":"`+strings.Repeat("x", 1024)+`"}`)
+			},
+			status: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name: "payload too large without content length",
+			req: func() *http.Request {
+				r := webhookRequest("1", "application/json", `{"a":"`+strings.Repeat("x", 1024)+`"}`)
+				r.ContentLength = -1
+				return r
+			},
+			status: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "malformed payload",
+			req:    func() *http.Request { return webhookRequest("1", "application/x-www-form-urlencoded", "foo=bar") },
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "missing delivery ID",
+			req: func() *http.Request {
+				r := webhookRequest("1", "application/json", body)
+				r.Header.Del("X-GitHub-Delivery")
+				return r
+			},
+			status: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deliveries := &fakeDeliveries{seen: make(map[string]time.Time)}
+			_, err := newTestVerifier(deliveries).Verify(tt.req())
+			requireStatus(t, err, tt.status)
+			assert.Empty(t, deliveries.seen, "rejected deliveries are not remembered")
+		})
+	}
+}
+
+func TestWebhookVerifier_Replay(t *testing.T) {
+	deliveries := &fakeDeliveries{seen: make(map[string]time.Time)}
+	v := newTestVerifier(deliveries)
+	body := `{"action":"opened"}`
+
+	delivery, err := v.Verify(webhookRequest("abc", "application/json", body))
+	require.NoError(t, err)
+
+	_, err = v.Verify(webhookRequest("abc", "application/json", body))
+	requireStatus(t, err, http.StatusConflict)
+
+	_, err = v.Verify(webhookRequest("def", "application/json", body))
+	require.NoError(t, err, "other deliveries are still accepted")
+
+	v.Forget(context.Background(), delivery)
+	_, err = v.Verify(webhookRequest("abc", "application/json", body))
+	require.NoError(t, err, "a forgotten delivery can be redelivered")
+
+	start := v.now()
+	v.now = func() time.Time { return start.Add(2 * time.Hour) }
+	_, err = v.Verify(webhookRequest("abc", "application/json", body))
+	require.NoError(t, err, "deliveries older than the TTL are accepted again")
+	assert.NotContains(t, deliveries.seen, "def", "expired deliveries are pruned")
+}
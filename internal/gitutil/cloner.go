@@ -3,11 +3,15 @@ package gitutil
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -20,6 +24,46 @@ import (
 // Client handles interacting with Git repositories.
 type Client struct {
 	Logger *slog.Logger
+	// SSH configures authentication for SSH remotes.
+	SSH SSHOptions
+}
+
+// SSHOptions selects the credentials git uses for SSH remotes. With no key
+// configured, ssh falls back to the agent at SSH_AUTH_SOCK and ~/.ssh.
+type SSHOptions struct {
+	KeyPath        string
+	KnownHostsPath string
+}
+
+// command returns a GIT_SSH_COMMAND value for the options, or "" to leave
+// git's default ssh invocation untouched.
+func (o SSHOptions) command() string {
+	if o.KeyPath == "" && o.KnownHostsPath == "" {
+		return ""
+	}
+	args := []string{"ssh", "-o", "BatchMode=yes"}
+	if o.KeyPath != "" {
+		args = append(args, "-i", shellQuote(o.KeyPath), "-o", "IdentitiesOnly=yes")
+	}
+	if o.KnownHostsPath != "" {
+		args = append(args, "-o", "UserKnownHostsFile="+shellQuote(o.KnownHostsPath))
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s for the shell that git runs GIT_SSH_COMMAND with.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// commandEnv returns the environment for git subprocesses: terminal prompts
+// are disabled and SSH remotes use the configured credentials.
+func (c *Client) commandEnv() []string {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if sshCommand := c.SSH.command(); sshCommand != "" {
+		env = append(env, "GIT_SSH_COMMAND="+sshCommand)
+	}
+	return env
 }
 
 // NewClient returns a new Client instance.
@@ -34,22 +78,26 @@ func NewClient(logger *slog.Logger) *Client {
 func (c *Client) Open(path string) (*git.Repository, error) {
 	repo, err := git.PlainOpen(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open repository at %s: %w", path, err)
+		err = fmt.Errorf("failed to open repository at %s: %w", path, err)
+		if errors.Is(err, git.ErrRepositoryNotExists) {
+			return nil, err
+		}
+		return nil, &CorruptionError{Reason: CorruptionUnreadable, Err: err}
 	}
 	return repo, nil
 }
 
 // Clone clones a repository to a specific path. It does not checkout a specific SHA.
 func (c *Client) Clone(ctx context.Context, repoURL, path, token string) (*git.Repository, error) {
-	authURL, err := c.getAuthenticatedURL(repoURL, token)
+	auth, err := c.authEnv(repoURL, token)
 	if err != nil {
 		return nil, err
 	}
 
 	c.Logger.InfoContext(ctx, "cloning repository", "url", repoURL, "path", path)
 	// Use git CLI to clone with longpaths enabled and credential helper disabled to avoid Keychain prompts/conflicts
-	cmd := exec.CommandContext(ctx, "git", "-c", "core.longpaths=true", "-c", "credential.helper=", "clone", authURL, path)
-	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd := exec.CommandContext(ctx, "git", "-c", "core.longpaths=true", "-c", "credential.helper=", "clone", repoURL, path)
+	cmd.Env = append(c.commandEnv(), auth...)
 
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return nil, fmt.Errorf("git clone failed: %s: %w", c.maskToken(string(out), token), err)
@@ -71,14 +119,26 @@ func (c *Client) Fetch(ctx context.Context, path string, token string, refSpecs
 	if err != nil {
 		c.Logger.WarnContext(ctx, "failed to get remote URL for authenticated fetch, trying as-is", "error", err)
 	}
+	// Clones made before origin was cleaned up after cloning still carry
+	// an old token in .git/config.
+	if cleanURL, ok := stripCredentials(repoURL); ok {
+		if err := c.SetRemoteURL(ctx, path, cleanURL); err != nil {
+			return fmt.Errorf("failed to remove credentials from origin: %w", err)
+		}
+		c.Logger.InfoContext(ctx, "removed stored credentials from origin")
+		repoURL = cleanURL
+	}
 
-	authURL, err := c.getAuthenticatedURL(repoURL, token)
+	auth, err := c.authEnv(repoURL, token)
 	if err != nil {
 		return err
 	}
 
-	// args for fetch: use origin then the authURL if provided
-	args := []string{"-c", "core.longpaths=true", "-c", "credential.helper=", "fetch", authURL, "--force"}
+	remote := repoURL
+	if remote == "" {
+		remote = "origin"
+	}
+	args := []string{"-c", "core.longpaths=true", "-c", "credential.helper=", "fetch", remote, "--force"}
 	args = append(args, refSpecs...)
 
 	// Retry logic for transient errors (e.g. 500 Internal Server Error)
@@ -92,7 +152,7 @@ func (c *Client) Fetch(ctx context.Context, path string, token string, refSpecs
 		}
 		cmd := exec.CommandContext(ctx, "git", args...)
 		cmd.Dir = path
-		cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+		cmd.Env = append(c.commandEnv(), auth...)
 
 		// If this is not the first attempt, log a warning and wait
 		if i > 0 {
@@ -111,7 +171,11 @@ func (c *Client) Fetch(ctx context.Context, path string, token string, refSpecs
 		}
 
 		if out, cmdErr := cmd.CombinedOutput(); cmdErr != nil {
-			err = fmt.Errorf("git fetch failed: %s: %w", c.maskToken(string(out), token), cmdErr)
+			err = classifyCorruption(string(out), fmt.Errorf("git fetch failed: %s: %w", c.maskToken(string(out), token), cmdErr))
+			// A damaged clone will not heal on retry.
+			if isCorruption(err) {
+				return err
+			}
 			continue
 		}
 
@@ -136,11 +200,19 @@ func (c *Client) MergeFF(ctx context.Context, path string) error {
 	return nil
 }
 
+// urlCredentialsRegex matches the userinfo of HTTP(S) URLs, e.g. the
+// "x-access-token:<token>@" git prints when a fetch or clone fails.
+var urlCredentialsRegex = regexp.MustCompile(`(?i)\b(https?://)[^\s/@]+@`)
+
+// maskToken removes token and any credentials embedded in URLs from git
+// output before it is logged or returned in an error. URL credentials are
+// scrubbed even without a token, since a clone's remote may still carry an
+// older one.
 func (c *Client) maskToken(input, token string) string {
-	if token == "" {
-		return input
+	if token != "" {
+		input = strings.ReplaceAll(input, token, "[MASKED]")
 	}
-	return strings.ReplaceAll(input, token, "[MASKED]")
+	return urlCredentialsRegex.ReplaceAllString(input, "${1}[MASKED]@")
 }
 
 func (c *Client) getRemoteURL(ctx context.Context, path, remoteName string) (string, error) {
@@ -153,6 +225,44 @@ func (c *Client) getRemoteURL(ctx context.Context, path, remoteName string) (str
 	return strings.TrimSpace(string(out)), nil
 }
 
+// RemoteURL returns the URL of the 'origin' remote of the repository at path.
+// Credentials embedded in HTTPS URLs are removed.
+func (c *Client) RemoteURL(ctx context.Context, path string) (string, error) {
+	remoteURL, err := c.getRemoteURL(ctx, path, "origin")
+	if err != nil {
+		return "", fmt.Errorf("git remote get-url failed: %w", err)
+	}
+	if cleanURL, ok := stripCredentials(remoteURL); ok {
+		remoteURL = cleanURL
+	}
+	return remoteURL, nil
+}
+
+// stripCredentials removes the userinfo from an HTTP(S) URL. It reports
+// whether remoteURL had any.
+func stripCredentials(remoteURL string) (string, bool) {
+	if IsSSHURL(remoteURL) {
+		return remoteURL, false
+	}
+	parsed, err := url.Parse(remoteURL)
+	if err != nil || parsed.User == nil {
+		return remoteURL, false
+	}
+	parsed.User = nil
+	return parsed.String(), true
+}
+
+// SetRemoteURL points the 'origin' remote of the repository at path to remoteURL,
+// e.g. when a repository moves between HTTPS and SSH.
+func (c *Client) SetRemoteURL(ctx context.Context, path, remoteURL string) error {
+	cmd := exec.CommandContext(ctx, "git", "remote", "set-url", "origin", remoteURL)
+	cmd.Dir = path
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git remote set-url failed: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
 // Checkout switches the repository's worktree to a specific commit using git CLI.
 func (c *Client) Checkout(ctx context.Context, path string, sha string) error {
 	c.Logger.Info("checking out commit", "sha", sha)
@@ -160,7 +270,7 @@ func (c *Client) Checkout(ctx context.Context, path string, sha string) error {
 	// Ensure we don't have lingering locks by using force, and enable longpaths
 	cmd := exec.CommandContext(ctx, "git", "-c", "core.longpaths=true", "checkout", "--force", sha)
 	cmd.Dir = path
-	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = c.commandEnv()
 
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git checkout failed: %s: %w", string(out), err)
@@ -176,14 +286,120 @@ func (c *Client) ResetToUpstream(ctx context.Context, path string) error {
 
 	cmd := exec.CommandContext(ctx, "git", "-c", "core.longpaths=true", "reset", "--hard", "@{u}")
 	cmd.Dir = path
-	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = c.commandEnv()
 
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git reset --hard @{u} failed: %s: %w", string(out), err)
+		return classifyCorruption(string(out), fmt.Errorf("git reset --hard @{u} failed: %s: %w", string(out), err))
 	}
 	return nil
 }
 
+// DirtyFiles returns the paths in the worktree at path that have uncommitted
+// changes, including untracked files. Ignored files are not reported.
+func (c *Client) DirtyFiles(ctx context.Context, path string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", "-c", "core.longpaths=true", "status", "--porcelain", "--untracked-files=all")
+	cmd.Dir = path
+	cmd.Env = c.commandEnv()
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status failed: %w", err)
+	}
+
+	var files []string
+	for line := range strings.SplitSeq(string(out), "\n") {
+		// Porcelain format: two status characters, a space, then the path.
+		if len(line) > 3 {
+			files = append(files, line[3:])
+		}
+	}
+	return files, nil
+}
+
+// CleanWorktree discards all uncommitted changes in the worktree at path and
+// removes untracked files and directories. Ignored files are kept.
+func (c *Client) CleanWorktree(ctx context.Context, path string) error {
+	c.Logger.InfoContext(ctx, "discarding uncommitted changes in worktree", "path", path)
+
+	for _, args := range [][]string{
+		{"-c", "core.longpaths=true", "reset", "--hard", "HEAD"},
+		{"-c", "core.longpaths=true", "clean", "-fd"},
+	} {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = path
+		cmd.Env = c.commandEnv()
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s failed: %s: %w", args[2], strings.TrimSpace(string(out)), err)
+		}
+	}
+	return nil
+}
+
+// ResolveCommit returns the SHA of the commit that ref (a SHA, branch or tag)
+// names in the repository at path. Only refs present in the local clone are
+// resolved; nothing is fetched.
+func (c *Client) ResolveCommit(ctx context.Context, path, ref string) (string, error) {
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return "", fmt.Errorf("invalid git ref %q", ref)
+	}
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "--end-of-options", ref+"^{commit}")
+	cmd.Dir = path
+	cmd.Env = c.commandEnv()
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git ref %q not found in the local clone: %w", ref, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// TreeSHA returns the SHA of the tree of the commit sha in the repository at
+// path. The tree SHA identifies the exact file contents of the commit; `git
+// archive <tree>` extracts them again.
+func (c *Client) TreeSHA(ctx context.Context, path, sha string) (string, error) {
+	if sha == "" || strings.HasPrefix(sha, "-") {
+		return "", fmt.Errorf("invalid commit %q", sha)
+	}
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "--end-of-options", sha+"^{tree}")
+	cmd.Dir = path
+	cmd.Env = c.commandEnv()
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the tree of %q: %w", sha, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// AddWorktree checks out sha into a temporary detached worktree of the
+// repository at path and returns its path with a cleanup function that
+// removes it again. The main worktree is left untouched.
+func (c *Client) AddWorktree(ctx context.Context, path, sha string) (string, func(), error) {
+	parent, err := os.MkdirTemp("", "code-warden-worktree-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	worktree := filepath.Join(parent, "tree")
+	cleanup := func() {
+		// The context may be done by now; removal must still happen.
+		cmd := exec.CommandContext(context.Background(), "git", "worktree", "remove", "--force", worktree)
+		cmd.Dir = path
+		cmd.Env = c.commandEnv()
+		if out, err := cmd.CombinedOutput(); err != nil {
+			c.Logger.Warn("failed to remove worktree", "path", worktree, "output", strings.TrimSpace(string(out)), "error", err)
+		}
+		if err := os.RemoveAll(parent); err != nil {
+			c.Logger.Error("failed to remove worktree directory", "path", parent, "error", err)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "-c", "core.longpaths=true", "worktree", "add", "--detach", worktree, sha)
+	cmd.Dir = path
+	cmd.Env = c.commandEnv()
+	if out, err := cmd.CombinedOutput(); err != nil {
+		_ = os.RemoveAll(parent)
+		return "", nil, fmt.Errorf("git worktree add failed: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return worktree, cleanup, nil
+}
+
 // Diff calculates the difference between two SHAs in an open repository.
 func (c *Client) Diff(repo *git.Repository, oldSHA, newSHA string) (added, modified, deleted []string, err error) {
 	// Get commit objects
@@ -264,41 +480,57 @@ func (c *Client) CloneAndCheckoutTemp(ctx context.Context, repoURL, sha, token s
 	return repoPath, cleanup, nil
 }
 
-func (c *Client) getAuthenticatedURL(repoURL, token string) (string, error) {
+// authEnv returns the environment entries that authenticate git with token
+// against the HTTP(S) remote repoURL. The token is sent in an Authorization
+// header configured through GIT_CONFIG_* variables, so it appears neither on
+// the command line of git, which the process list shows to every user, nor
+// in .git/config. Local paths and SSH remotes get none.
+func (c *Client) authEnv(repoURL, token string) ([]string, error) {
 	// Handle local paths. file:// is explicitly blocked for security (SSRF risk).
 	if strings.HasPrefix(strings.ToLower(repoURL), "file://") {
-		return "", fmt.Errorf("file:// URLs are not supported for security reasons")
+		return nil, fmt.Errorf("file:// URLs are not supported for security reasons")
 	}
-	if !strings.Contains(repoURL, "://") {
-		return repoURL, nil
+	// Local paths and SSH remotes carry no token; SSH authenticates with
+	// the key or agent from c.SSH.
+	if !strings.Contains(repoURL, "://") || IsSSHURL(repoURL) {
+		return nil, nil
 	}
 
 	if !strings.HasPrefix(repoURL, "https://") && !strings.HasPrefix(repoURL, "http://") {
-		return "", fmt.Errorf("invalid repository URL: %s", repoURL)
+		return nil, fmt.Errorf("invalid repository URL: %s", repoURL)
 	}
 
 	parsedURL, err := url.Parse(repoURL)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse repository URL '%s': %w", repoURL, err)
+		return nil, fmt.Errorf("failed to parse repository URL '%s': %w", repoURL, err)
 	}
-
-	// Only add authentication if token is provided
-	if token != "" {
-		// Use x-access-token for all types of GitHub tokens.
-		// This explicitly tells GitHub the "password" is a token, which is the most reliable way.
-		parsedURL.User = url.UserPassword("x-access-token", token)
+	if token == "" {
+		return nil, nil
 	}
-	return parsedURL.String(), nil
+
+	// Use x-access-token for all types of GitHub tokens. The header is
+	// scoped to the host, so a redirect to another one does not carry it.
+	credentials := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http." + parsedURL.Scheme + "://" + parsedURL.Host + "/.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + credentials,
+	}, nil
 }
 
 // GetHeadSHA returns the current HEAD SHA of the repository at the given path.
 func (c *Client) GetHeadSHA(ctx context.Context, path string) (string, error) {
 	cmd := exec.CommandContext(ctx, "git", "-c", "core.longpaths=true", "rev-parse", "HEAD")
 	cmd.Dir = path
-	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = c.commandEnv()
 	out, err := cmd.Output()
 	if err != nil {
-		return "", fmt.Errorf("git rev-parse failed: %w", err)
+		var stderr string
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			stderr = string(exitErr.Stderr)
+		}
+		return "", classifyCorruption(stderr, fmt.Errorf("git rev-parse failed: %w", err))
 	}
 	return strings.TrimSpace(string(out)), nil
 }
@@ -0,0 +1,185 @@
+package gitutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// ErrNonFastForward is returned by PushCommit when the branch moved on the
+// remote and the commit would overwrite its new commits.
+var ErrNonFastForward = errors.New("branch moved on the remote")
+
+// tokenAuth authenticates go-git against GitHub with an installation or
+// personal access token.
+func tokenAuth(token string) *githttp.BasicAuth {
+	if token == "" {
+		return nil
+	}
+	return &githttp.BasicAuth{Username: "x-access-token", Password: token}
+}
+
+// FetchRef fetches refSpec from remoteURL into repo with go-git, e.g.
+// "+refs/pull/7/head:refs/code-warden/pull/7".
+func FetchRef(ctx context.Context, repo *git.Repository, remoteURL, token, refSpec string) error {
+	err := repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteURL: remoteURL,
+		Auth:      tokenAuth(token),
+		RefSpecs:  []config.RefSpec{config.RefSpec(refSpec)},
+		Force:     true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("failed to fetch %s: %w", refSpec, err)
+	}
+	return nil
+}
+
+// ReadFile returns the content of path in commit.
+func ReadFile(repo *git.Repository, commit, path string) ([]byte, error) {
+	c, err := repo.CommitObject(plumbing.NewHash(commit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", commit, err)
+	}
+	file, err := c.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s at %s: %w", path, commit, err)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, commit, err)
+	}
+	return []byte(content), nil
+}
+
+// CommitFile creates a commit on top of parent that sets the existing file
+// path to content, and returns its hash. Only objects are written: the
+// worktree, the index and the branches of repo are left alone.
+func CommitFile(repo *git.Repository, parent, path string, content []byte, message string, author object.Signature) (string, error) {
+	parentCommit, err := repo.CommitObject(plumbing.NewHash(parent))
+	if err != nil {
+		return "", fmt.Errorf("failed to get commit %s: %w", parent, err)
+	}
+	tree, err := parentCommit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the tree of %s: %w", parent, err)
+	}
+
+	blob := repo.Storer.NewEncodedObject()
+	blob.SetType(plumbing.BlobObject)
+	w, err := blob.Writer()
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(content); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	blobHash, err := repo.Storer.SetEncodedObject(blob)
+	if err != nil {
+		return "", fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	treeHash, err := replaceBlob(repo.Storer, tree, strings.Split(path, "/"), blobHash)
+	if err != nil {
+		return "", fmt.Errorf("failed to update %s: %w", path, err)
+	}
+
+	commit := &object.Commit{
+		Author:       author,
+		Committer:    author,
+		Message:      message,
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{parentCommit.Hash},
+	}
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return "", err
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return "", fmt.Errorf("failed to write commit: %w", err)
+	}
+	return hash.String(), nil
+}
+
+// replaceBlob writes a copy of tree in which the file at parts points to
+// blob, and the copies of the trees leading to it, and returns its hash.
+func replaceBlob(s storer.EncodedObjectStorer, tree *object.Tree, parts []string, blob plumbing.Hash) (plumbing.Hash, error) {
+	entries := append([]object.TreeEntry(nil), tree.Entries...)
+	i := -1
+	for j, e := range entries {
+		if e.Name == parts[0] {
+			i = j
+			break
+		}
+	}
+	if i < 0 {
+		return plumbing.ZeroHash, fmt.Errorf("%s: %w", parts[0], object.ErrFileNotFound)
+	}
+
+	if len(parts) == 1 {
+		if entries[i].Mode == filemode.Dir {
+			return plumbing.ZeroHash, fmt.Errorf("%s is a directory", parts[0])
+		}
+		entries[i].Hash = blob
+	} else {
+		if entries[i].Mode != filemode.Dir {
+			return plumbing.ZeroHash, fmt.Errorf("%s is not a directory", parts[0])
+		}
+		sub, err := tree.Tree(parts[0])
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		hash, err := replaceBlob(s, sub, parts[1:], blob)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries[i].Hash = hash
+	}
+
+	obj := s.NewEncodedObject()
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.SetEncodedObject(obj)
+}
+
+// PushCommit pushes commit to branch of remoteURL. The branch is created if
+// it does not exist; an existing branch is only fast-forwarded, and
+// ErrNonFastForward is returned when commit does not descend from it.
+func PushCommit(ctx context.Context, repo *git.Repository, remoteURL, token, commit, branch string) error {
+	// go-git pushes references, not hashes: point a temporary one at commit.
+	local := plumbing.ReferenceName("refs/code-warden/push/" + branch)
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(local, plumbing.NewHash(commit))); err != nil {
+		return fmt.Errorf("failed to create reference: %w", err)
+	}
+	defer func() { _ = repo.Storer.RemoveReference(local) }()
+
+	err := repo.PushContext(ctx, &git.PushOptions{
+		RemoteName: git.DefaultRemoteName,
+		RemoteURL:  remoteURL,
+		Auth:       tokenAuth(token),
+		RefSpecs:   []config.RefSpec{config.RefSpec(fmt.Sprintf("%s:refs/heads/%s", local, branch))},
+	})
+	switch {
+	case err == nil, errors.Is(err, git.NoErrAlreadyUpToDate):
+		return nil
+	// go-git has no sentinel for a rejected update, and cannot tell whether
+	// the remote head descends from commit when it lacks that head locally.
+	case strings.Contains(err.Error(), "non-fast-forward update"), errors.Is(err, plumbing.ErrObjectNotFound):
+		return ErrNonFastForward
+	default:
+		return fmt.Errorf("failed to push %s: %w", branch, err)
+	}
+}
@@ -0,0 +1,61 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitFileAndPush(t *testing.T) {
+	ctx := context.Background()
+	author := object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()}
+
+	remoteDir := t.TempDir()
+	remote, err := git.PlainInit(remoteDir, false)
+	require.NoError(t, err)
+	w, err := remote.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(remoteDir, "pkg", "store"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(remoteDir, "pkg", "store", "db.go"), []byte("package store\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(remoteDir, "README.md"), []byte("readme\n"), 0o644))
+	_, err = w.Add(".")
+	require.NoError(t, err)
+	base, err := w.Commit("initial", &git.CommitOptions{Author: &author})
+	require.NoError(t, err)
+
+	repo, err := git.PlainClone(t.TempDir(), false, &git.CloneOptions{URL: remoteDir})
+	require.NoError(t, err)
+	require.NoError(t, FetchRef(ctx, repo, remoteDir, "", "+refs/heads/master:refs/code-warden/test"))
+
+	commit, err := CommitFile(repo, base.String(), "pkg/store/db.go", []byte("package store\n\nvar x = 1\n"), "Apply fix", author)
+	require.NoError(t, err)
+	content, err := ReadFile(repo, commit, "pkg/store/db.go")
+	require.NoError(t, err)
+	assert.Equal(t, "package store\n\nvar x = 1\n", string(content))
+	readme, err := ReadFile(repo, commit, "README.md")
+	require.NoError(t, err)
+	assert.Equal(t, "readme\n", string(readme), "other files are kept")
+
+	require.NoError(t, PushCommit(ctx, repo, remoteDir, "", commit, "code-warden/fix-1"))
+	ref, err := remote.Reference(plumbing.NewBranchReferenceName("code-warden/fix-1"), true)
+	require.NoError(t, err)
+	assert.Equal(t, commit, ref.Hash().String())
+	_, err = repo.Reference("refs/code-warden/push/code-warden/fix-1", false)
+	assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound, "the temporary reference is removed")
+
+	// A commit that does not descend from the branch must not replace it.
+	other, err := CommitFile(repo, base.String(), "README.md", []byte("other\n"), "Other fix", author)
+	require.NoError(t, err)
+	assert.ErrorIs(t, PushCommit(ctx, repo, remoteDir, "", other, "code-warden/fix-1"), ErrNonFastForward)
+
+	_, err = CommitFile(repo, base.String(), "pkg/missing.go", nil, "Missing", author)
+	assert.Error(t, err, "only existing files can be changed")
+}
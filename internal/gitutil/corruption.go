@@ -0,0 +1,78 @@
+package gitutil
+
+import (
+	"errors"
+	"strings"
+)
+
+// Reasons reported by [CorruptionError].
+const (
+	CorruptionMissingObjects = "missing_objects"
+	CorruptionStaleLock      = "stale_lock"
+	CorruptionNotRepository  = "not_a_repository"
+	CorruptionUnreadable     = "unreadable_repository"
+)
+
+// CorruptionError reports a git failure caused by a damaged clone rather than
+// by the remote or the network. Such clones do not heal on retry and should
+// be replaced with a fresh clone.
+type CorruptionError struct {
+	// Reason is one of the Corruption* constants.
+	Reason string
+	Err    error
+}
+
+func (e *CorruptionError) Error() string {
+	return "corrupted clone (" + e.Reason + "): " + e.Err.Error()
+}
+
+func (e *CorruptionError) Unwrap() error {
+	return e.Err
+}
+
+// CorruptionReason returns the reason if err is, or wraps, a [CorruptionError].
+func CorruptionReason(err error) (string, bool) {
+	var ce *CorruptionError
+	if errors.As(err, &ce) {
+		return ce.Reason, true
+	}
+	return "", false
+}
+
+func isCorruption(err error) bool {
+	_, ok := CorruptionReason(err)
+	return ok
+}
+
+// corruptionPatterns maps git CLI output fragments to a corruption reason.
+// They cover objects lost to interrupted fetches or disk errors, and lock
+// files left behind when a git process was killed mid-operation.
+var corruptionPatterns = []struct {
+	fragment string
+	reason   string
+}{
+	{"missing blob", CorruptionMissingObjects},
+	{"missing tree", CorruptionMissingObjects},
+	{"missing commit", CorruptionMissingObjects},
+	{"bad object", CorruptionMissingObjects},
+	{"did not send all necessary objects", CorruptionMissingObjects},
+	{"object file", CorruptionMissingObjects}, // "object file ... is empty"
+	{"is corrupt", CorruptionMissingObjects},
+	{"packfile", CorruptionMissingObjects}, // "packfile ... cannot be accessed"
+	{"unable to read", CorruptionMissingObjects},
+	{".lock': file exists", CorruptionStaleLock},
+	{"cannot lock ref", CorruptionStaleLock},
+	{"not a git repository", CorruptionNotRepository},
+}
+
+// classifyCorruption wraps err in a [CorruptionError] when the git output
+// indicates a damaged clone, and returns err unchanged otherwise.
+func classifyCorruption(output string, err error) error {
+	lower := strings.ToLower(output)
+	for _, p := range corruptionPatterns {
+		if strings.Contains(lower, p.fragment) {
+			return &CorruptionError{Reason: p.reason, Err: err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,68 @@
+package gitutil
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyCorruption(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		wantReason string
+	}{
+		{
+			name:       "stale index lock",
+			output:     "fatal: Unable to create '/data/repos/o/r/.git/index.lock': File exists.",
+			wantReason: CorruptionStaleLock,
+		},
+		{
+			name:       "stale ref lock",
+			output:     "error: cannot lock ref 'refs/remotes/origin/main': Unable to create lock",
+			wantReason: CorruptionStaleLock,
+		},
+		{
+			name:       "empty object file",
+			output:     "error: object file .git/objects/4b/825dc6 is empty\nfatal: loose object 4b825dc6 is corrupt",
+			wantReason: CorruptionMissingObjects,
+		},
+		{
+			name:       "missing tree",
+			output:     "fatal: unable to read tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904",
+			wantReason: CorruptionMissingObjects,
+		},
+		{
+			name:       "interrupted fetch",
+			output:     "error: remote did not send all necessary objects",
+			wantReason: CorruptionMissingObjects,
+		},
+		{
+			name:       "not a repository",
+			output:     "fatal: not a git repository (or any of the parent directories): .git",
+			wantReason: CorruptionNotRepository,
+		},
+		{
+			name:   "network failure is not corruption",
+			output: "fatal: unable to access 'https://github.com/o/r/': Could not resolve host: github.com",
+		},
+		{
+			name:   "auth failure is not corruption",
+			output: "remote: Repository not found.\nfatal: Authentication failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cause := errors.New("exit status 128")
+			err := classifyCorruption(tt.output, fmt.Errorf("git fetch failed: %w", cause))
+
+			reason, ok := CorruptionReason(err)
+			assert.Equal(t, tt.wantReason != "", ok)
+			assert.Equal(t, tt.wantReason, reason)
+			assert.ErrorIs(t, err, cause)
+		})
+	}
+}
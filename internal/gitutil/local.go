@@ -0,0 +1,266 @@
+package gitutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// RepoRoot returns the top-level directory of the working tree dir is in.
+func RepoRoot(ctx context.Context, dir string) (string, error) {
+	out, err := runLocalGit(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// HeadSHA returns the commit HEAD points to, or "" in a repository without
+// commits.
+func HeadSHA(ctx context.Context, dir string) string {
+	out, err := runLocalGit(ctx, dir, "rev-parse", "--verify", "--quiet", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// StagedDiff returns the unified diff of the changes staged in the index of
+// the repository at dir against HEAD, what the next commit would contain.
+// External diff drivers and color are disabled so the diff parses like one
+// from GitHub.
+func StagedDiff(ctx context.Context, dir string) (string, error) {
+	return runLocalGit(ctx, dir, "diff", "--cached", "--no-color", "--no-ext-diff", "--no-renames")
+}
+
+// DiffRange returns the unified diff of head against its merge base with
+// base, like the diff of a pull request from head into base.
+func DiffRange(ctx context.Context, dir, base, head string) (string, error) {
+	if base == "" || head == "" {
+		return "", fmt.Errorf("invalid range %q...%q: both refs are required", base, head)
+	}
+	if strings.HasPrefix(base, "-") || strings.HasPrefix(head, "-") {
+		return "", fmt.Errorf("invalid range %q...%q: refs cannot start with -", base, head)
+	}
+	return runLocalGit(ctx, dir, "diff", "--no-color", "--no-ext-diff", "--no-renames", base+"..."+head, "--")
+}
+
+// ParseRange splits a "base..head" or "base...head" range into its refs; a
+// missing head stands for HEAD. Both forms select the changes of head since
+// it forked from base, as DiffRange does.
+func ParseRange(spec string) (base, head string, err error) {
+	sep := "..."
+	if !strings.Contains(spec, sep) {
+		sep = ".."
+	}
+	base, head, ok := strings.Cut(spec, sep)
+	if !ok || base == "" || strings.Contains(head, "..") {
+		return "", "", fmt.Errorf("invalid range %q, expected base..head", spec)
+	}
+	if head == "" {
+		head = "HEAD"
+	}
+	return base, head, nil
+}
+
+// ErrNotPatch is returned by ReadPatch for input that is not a git diff.
+var ErrNotPatch = errors.New("not a git diff")
+
+// ReadPatch reads a git diff or git format-patch file from r. Empty input,
+// nothing to review, is returned as is.
+func ReadPatch(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read patch: %w", err)
+	}
+	diff := string(data)
+	if strings.TrimSpace(diff) != "" && !strings.Contains(diff, "diff --git ") {
+		return "", ErrNotPatch
+	}
+	return diff, nil
+}
+
+// PushedRef is one of the lines git writes to the standard input of a
+// pre-push hook: "<local ref> <local sha> <remote ref> <remote sha>".
+type PushedRef struct {
+	LocalRef  string
+	LocalSHA  string
+	RemoteRef string
+	RemoteSHA string
+}
+
+// IsDeletion reports whether the push deletes the remote ref; git passes
+// an all-zero sha for the missing side.
+func (r PushedRef) IsDeletion() bool {
+	return strings.Trim(r.LocalSHA, "0") == ""
+}
+
+// IsNew reports whether the push creates the remote ref.
+func (r PushedRef) IsNew() bool {
+	return strings.Trim(r.RemoteSHA, "0") == ""
+}
+
+// ParsePushedRefs parses the standard input of a pre-push hook.
+func ParsePushedRefs(r io.Reader) ([]PushedRef, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pushed refs: %w", err)
+	}
+	var refs []PushedRef
+	for line := range strings.SplitSeq(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("invalid pre-push line %q, expected <local ref> <local sha> <remote ref> <remote sha>", line)
+		}
+		refs = append(refs, PushedRef{LocalRef: fields[0], LocalSHA: fields[1], RemoteRef: fields[2], RemoteSHA: fields[3]})
+	}
+	return refs, nil
+}
+
+// PushDiff returns the diff of the commits refs push to remote from the
+// repository at dir: for each ref the changes of its local commit since the
+// remote one, and for a new ref, or a remote commit missing locally, since its
+// merge base with the default branch of remote. Deletions are skipped.
+func PushDiff(ctx context.Context, dir, remote string, refs []PushedRef) (string, error) {
+	var diff strings.Builder
+	for _, ref := range refs {
+		if ref.IsDeletion() {
+			continue
+		}
+		base := ref.RemoteSHA
+		if ref.IsNew() || !hasCommit(ctx, dir, base) {
+			var err error
+			if base, err = DefaultBranch(ctx, dir, remote); err != nil {
+				return "", fmt.Errorf("no base to review new ref %s against: %w", ref.RemoteRef, err)
+			}
+		}
+		d, err := DiffRange(ctx, dir, base, ref.LocalSHA)
+		if err != nil {
+			return "", err
+		}
+		diff.WriteString(d)
+	}
+	return diff.String(), nil
+}
+
+// DefaultBranch returns the remote-tracking branch of the default branch of
+// remote, such as origin/main: the one refs/remotes/<remote>/HEAD points to,
+// or else <remote>/main or <remote>/master.
+func DefaultBranch(ctx context.Context, dir, remote string) (string, error) {
+	if out, err := runLocalGit(ctx, dir, "symbolic-ref", "--quiet", "--short", "refs/remotes/"+remote+"/HEAD"); err == nil {
+		return strings.TrimSpace(out), nil
+	}
+	for _, name := range []string{"main", "master"} {
+		branch := remote + "/" + name
+		if hasCommit(ctx, dir, "refs/remotes/"+branch) {
+			return branch, nil
+		}
+	}
+	return "", fmt.Errorf("default branch of %s not found, run git remote set-head %s --auto", remote, remote)
+}
+
+// hasCommit reports whether rev names a commit of the repository at dir.
+func hasCommit(ctx context.Context, dir, rev string) bool {
+	_, err := runLocalGit(ctx, dir, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	return err == nil
+}
+
+func runLocalGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(stderr.String()), err)
+	}
+	return string(out), nil
+}
+
+// Hooks InstallHook can install.
+const (
+	HookPreCommit = "pre-commit"
+	HookPrePush   = "pre-push"
+)
+
+// hookMarker identifies hooks written by InstallHook, which it may replace.
+const hookMarker = "# Installed by code-warden."
+
+// ErrHookExists is returned by InstallHook when a hook that it did not write
+// is in the way.
+var ErrHookExists = errors.New("a hook of another tool is installed")
+
+// HooksDir returns the directory git runs the hooks of the repository at dir
+// from, honouring core.hooksPath.
+func HooksDir(ctx context.Context, dir string) (string, error) {
+	out, err := runLocalGit(ctx, dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	hooks := strings.TrimSpace(out)
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	return hooks, nil
+}
+
+// HookScript returns the shell script of hook, which runs command. An exit
+// code of 2 from command, findings at or above the threshold, rejects the
+// commit or push; other failures, like an unreachable LLM, are reported but
+// do not block.
+func HookScript(hook, command string) (string, error) {
+	if !slices.Contains([]string{HookPreCommit, HookPrePush}, hook) {
+		return "", fmt.Errorf("unsupported hook %q, expected %s or %s", hook, HookPreCommit, HookPrePush)
+	}
+	return fmt.Sprintf(`#!/bin/sh
+%s
+# Reviews the changes of this %s; skip with --no-verify.
+%s
+status=$?
+if [ "$status" -eq 2 ]; then
+	exit 1
+fi
+if [ "$status" -ne 0 ]; then
+	echo "code-warden: review failed (exit $status), not blocking the %s" >&2
+fi
+exit 0
+`, hookMarker, strings.TrimPrefix(hook, "pre-"), command, strings.TrimPrefix(hook, "pre-")), nil
+}
+
+// InstallHook writes the script of hook running command to hooksDir. A hook
+// that InstallHook did not write is only replaced with force.
+func InstallHook(hooksDir, hook, command string, force bool) (string, error) {
+	script, err := HookScript(hook, command)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(hooksDir, hook)
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil && !force && !bytes.Contains(existing, []byte(hookMarker)):
+		return "", fmt.Errorf("%w at %s, use --force to replace it", ErrHookExists, path)
+	case err != nil && !errors.Is(err, os.ErrNotExist):
+		return "", fmt.Errorf("failed to read existing hook: %w", err)
+	}
+	if err := os.MkdirAll(hooksDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	//nolint:gosec // G306: hooks must be executable
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return "", fmt.Errorf("failed to write hook: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file.
+	if err := os.Chmod(path, 0o755); err != nil { //nolint:gosec // G302: hooks must be executable
+		return "", fmt.Errorf("failed to make hook executable: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,261 @@
+package gitutil
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStagedDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	gitRun(t, dir, "init", "-q")
+	commitFile(t, dir, "main.go", "package main\n")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unstaged.go"), []byte("package main\n"), 0o644))
+	gitRun(t, dir, "add", "main.go")
+
+	diff, err := StagedDiff(ctx, dir)
+	require.NoError(t, err)
+	assert.Contains(t, diff, "diff --git a/main.go b/main.go")
+	assert.Contains(t, diff, "+func main() {}")
+	assert.NotContains(t, diff, "unstaged.go", "only staged changes are reviewed")
+
+	root, err := RepoRoot(ctx, filepath.Join(dir, "."))
+	require.NoError(t, err)
+	wantRoot, _ := filepath.EvalSymlinks(dir)
+	gotRoot, _ := filepath.EvalSymlinks(root)
+	assert.Equal(t, wantRoot, gotRoot)
+	assert.Len(t, HeadSHA(ctx, dir), 40)
+}
+
+func TestInstallHook(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := InstallHook(dir, HookPreCommit, "warden-cli check --staged", false)
+	require.NoError(t, err)
+	script, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(script), "#!/bin/sh\n"))
+	assert.Contains(t, string(script), "warden-cli check --staged")
+
+	// Its own hook is replaced without --force.
+	_, err = InstallHook(dir, HookPreCommit, "warden-cli check --staged --fail-on critical", false)
+	require.NoError(t, err)
+
+	foreign := filepath.Join(dir, HookPrePush)
+	require.NoError(t, os.WriteFile(foreign, []byte("#!/bin/sh\nmake lint\n"), 0o755))
+	_, err = InstallHook(dir, HookPrePush, `warden-cli check --pre-push --remote "$1"`, false)
+	require.ErrorIs(t, err, ErrHookExists)
+	_, err = InstallHook(dir, HookPrePush, `warden-cli check --pre-push --remote "$1"`, true)
+	require.NoError(t, err)
+
+	_, err = InstallHook(dir, "post-merge", "true", false)
+	require.Error(t, err)
+}
+
+func gitRun(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestDiffRange(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	gitRun(t, dir, "init", "-q", "-b", "main")
+	commitFile(t, dir, "main.go", "package main\n")
+	gitRun(t, dir, "checkout", "-q", "-b", "feature")
+	commitFile(t, dir, "feature.go", "package main\n\nfunc feature() {}\n")
+	gitRun(t, dir, "checkout", "-q", "main")
+	commitFile(t, dir, "later.go", "package main\n")
+
+	tests := []struct {
+		name       string
+		base, head string
+		want       []string
+		notWant    []string
+		wantEmpty  bool
+		wantErr    bool
+	}{
+		{name: "branch since fork", base: "main", head: "feature", want: []string{"+func feature() {}"}, notWant: []string{"later.go"}},
+		{name: "same ref", base: "main", head: "main", wantEmpty: true},
+		{name: "head already merged", base: "feature", head: "feature~1", wantEmpty: true},
+		{name: "unknown ref", base: "main", head: "missing", wantErr: true},
+		{name: "missing base", base: "", head: "feature", wantErr: true},
+		{name: "option as ref", base: "--output=/tmp/x", head: "feature", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := DiffRange(ctx, dir, tt.base, tt.head)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantEmpty {
+				assert.Empty(t, diff)
+			}
+			for _, s := range tt.want {
+				assert.Contains(t, diff, s)
+			}
+			for _, s := range tt.notWant {
+				assert.NotContains(t, diff, s)
+			}
+		})
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		spec       string
+		base, head string
+		wantErr    bool
+	}{
+		{spec: "main..feature", base: "main", head: "feature"},
+		{spec: "main...feature", base: "main", head: "feature"},
+		{spec: "origin/main..", base: "origin/main", head: "HEAD"},
+		{spec: "v1.2.0...HEAD~2", base: "v1.2.0", head: "HEAD~2"},
+		{spec: "main", wantErr: true},
+		{spec: "..feature", wantErr: true},
+		{spec: "a..b..c", wantErr: true},
+		{spec: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		base, head, err := ParseRange(tt.spec)
+		if tt.wantErr {
+			assert.Error(t, err, tt.spec)
+			continue
+		}
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.base, base, tt.spec)
+		assert.Equal(t, tt.head, head, tt.spec)
+	}
+}
+
+func TestReadPatch(t *testing.T) {
+	patch := "From 1234 Mon Sep 17 00:00:00 2001\nSubject: [PATCH] fix\n\ndiff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n"
+	tests := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{name: "format-patch", input: patch},
+		{name: "empty", input: ""},
+		{name: "whitespace only", input: "\n  \n"},
+		{name: "not a diff", input: "just some notes\n", wantErr: ErrNotPatch},
+		{name: "plain unified diff", input: "--- a/main.go\n+++ b/main.go\n@@ -1 +1 @@\n-old\n+new\n", wantErr: ErrNotPatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := ReadPatch(strings.NewReader(tt.input))
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.input, diff)
+		})
+	}
+}
+
+func TestPushDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	gitRun(t, dir, "init", "-q", "-b", "main")
+	commitFile(t, dir, "pushed.go", "package main\n")
+	pushed := gitOutput(t, dir, "rev-parse", "HEAD")
+	gitRun(t, dir, "update-ref", "refs/remotes/origin/main", pushed)
+	gitRun(t, dir, "symbolic-ref", "refs/remotes/origin/HEAD", "refs/remotes/origin/main")
+	commitFile(t, dir, "unpushed.go", "package main\n\nfunc unpushed() {}\n")
+	mainSHA := gitOutput(t, dir, "rev-parse", "HEAD")
+	gitRun(t, dir, "checkout", "-q", "-b", "feature", pushed)
+	commitFile(t, dir, "feature.go", "package main\n\nfunc feature() {}\n")
+	featureSHA := gitOutput(t, dir, "rev-parse", "HEAD")
+	zero := strings.Repeat("0", 40)
+
+	tests := []struct {
+		name    string
+		stdin   string
+		want    []string
+		notWant []string
+		wantErr bool
+	}{
+		{
+			name:    "update of an existing branch",
+			stdin:   "refs/heads/main " + mainSHA + " refs/heads/main " + pushed + "\n",
+			want:    []string{"+func unpushed() {}"},
+			notWant: []string{"b/pushed.go", "feature.go"},
+		},
+		{
+			name:    "new branch since the default branch",
+			stdin:   "refs/heads/feature " + featureSHA + " refs/heads/feature " + zero + "\n",
+			want:    []string{"+func feature() {}"},
+			notWant: []string{"unpushed.go", "b/pushed.go"},
+		},
+		{
+			name:  "several refs",
+			stdin: "refs/heads/main " + mainSHA + " refs/heads/main " + pushed + "\nrefs/heads/feature " + featureSHA + " refs/heads/topic " + zero + "\n",
+			want:  []string{"+func unpushed() {}", "+func feature() {}"},
+		},
+		{
+			name:    "deletion is skipped",
+			stdin:   "(delete) " + zero + " refs/heads/old " + pushed + "\n",
+			notWant: []string{"diff --git"},
+		},
+		{
+			name:    "remote commit missing locally",
+			stdin:   "refs/heads/feature " + featureSHA + " refs/heads/feature " + strings.Repeat("ab", 20) + "\n",
+			want:    []string{"+func feature() {}"},
+			notWant: []string{"unpushed.go"},
+		},
+		{name: "malformed line", stdin: "refs/heads/main " + mainSHA + "\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, err := ParsePushedRefs(strings.NewReader(tt.stdin))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			diff, err := PushDiff(ctx, dir, "origin", refs)
+			require.NoError(t, err)
+			for _, s := range tt.want {
+				assert.Contains(t, diff, s)
+			}
+			for _, s := range tt.notWant {
+				assert.NotContains(t, diff, s)
+			}
+		})
+	}
+
+	t.Run("new branch without a default branch", func(t *testing.T) {
+		refs := []PushedRef{{LocalRef: "refs/heads/feature", LocalSHA: featureSHA, RemoteRef: "refs/heads/feature", RemoteSHA: zero}}
+		_, err := PushDiff(ctx, dir, "upstream", refs)
+		require.Error(t, err)
+	})
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	require.NoError(t, err)
+	return strings.TrimSpace(string(out))
+}
@@ -2,12 +2,17 @@ package gitutil
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
-var prURLRegex = regexp.MustCompile(`github\.com/([^/]+)/([^/]+)/pull/(\d+)$`)
+var (
+	prURLRegex = regexp.MustCompile(`github\.com/([^/]+)/([^/]+)/pull/(\d+)$`)
+	// scpURLRegex matches scp-like SSH remotes such as git@github.com:owner/repo.git.
+	scpURLRegex = regexp.MustCompile(`^[\w.-]+@[\w.-]+:[^/]`)
+)
 
 // ParsePullRequestURL parses a GitHub Pull Request URL and extracts the owner, repo, and PR number.
 // Supported format: https://github.com/{owner}/{repo}/pull/{number}
@@ -31,3 +36,30 @@ func ParsePullRequestURL(url string) (owner, repo string, prNumber int, err erro
 
 	return owner, repo, prNumber, nil
 }
+
+// IsSSHURL reports whether remoteURL is an SSH remote, either ssh://... or
+// the scp-like user@host:path form.
+func IsSSHURL(remoteURL string) bool {
+	return strings.HasPrefix(strings.ToLower(remoteURL), "ssh://") || scpURLRegex.MatchString(remoteURL)
+}
+
+// SSHURL converts an HTTPS clone URL such as https://github.com/owner/repo.git
+// to the scp-like SSH form user@github.com:owner/repo.git. SSH URLs are
+// returned unchanged.
+func SSHURL(cloneURL, user string) (string, error) {
+	if IsSSHURL(cloneURL) {
+		return cloneURL, nil
+	}
+	parsed, err := url.Parse(cloneURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return "", fmt.Errorf("cannot derive an SSH URL from %q", cloneURL)
+	}
+	repoPath := strings.Trim(parsed.Path, "/")
+	if repoPath == "" {
+		return "", fmt.Errorf("cannot derive an SSH URL from %q: no repository path", cloneURL)
+	}
+	if !strings.HasSuffix(repoPath, ".git") {
+		repoPath += ".git"
+	}
+	return user + "@" + parsed.Hostname() + ":" + repoPath, nil
+}
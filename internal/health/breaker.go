@@ -0,0 +1,185 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, wrapped with the backend and its last error,
+// by calls rejected because the circuit of their backend is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Breaker states.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// BackendError marks an error as caused by Backend. Errors of one backend
+// often surface through another, like embedder errors through the vector
+// database client; a Breaker only counts errors of its own backend.
+type BackendError struct {
+	Backend string
+	Err     error
+}
+
+func (e *BackendError) Error() string { return e.Backend + ": " + e.Err.Error() }
+
+func (e *BackendError) Unwrap() error { return e.Err }
+
+// BreakerStatus is a snapshot of a Breaker.
+type BreakerStatus struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	// Failures is the number of consecutive failed calls.
+	Failures int `json:"failures"`
+	// OpenedAt and RetryAt are when the circuit opened and when the next
+	// call will probe the backend.
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	RetryAt   *time.Time `json:"retry_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+	// Opens counts how often the circuit opened, Rejected how many calls
+	// failed fast since the process started.
+	Opens    int64 `json:"opens"`
+	Rejected int64 `json:"rejected"`
+}
+
+// Breaker is a circuit breaker for one backend. After threshold consecutive
+// failures the circuit opens and calls are rejected with ErrCircuitOpen for
+// the cooldown. The first call after the cooldown probes the backend: its
+// success closes the circuit, its failure opens it for another cooldown.
+// A nil *Breaker allows every call. It is safe for concurrent use.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+	lastErr  error
+	opens    int64
+	rejected int64
+}
+
+// NewBreaker creates a closed Breaker for the backend name.
+func NewBreaker(name string, threshold int, cooldown time.Duration, logger *slog.Logger) *Breaker {
+	return &Breaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Name returns the name of the backend.
+func (b *Breaker) Name() string { return b.name }
+
+// Allow returns an error wrapping ErrCircuitOpen when the call must fail
+// fast. Every allowed call must be followed by Record.
+func (b *Breaker) Allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.openedAt.IsZero() {
+		return nil
+	}
+	retryAt := b.openedAt.Add(b.cooldown)
+	if !b.probing && !b.now().Before(retryAt) {
+		b.probing = true
+		b.logger.Info("probing backend after circuit breaker cooldown", "backend", b.name)
+		return nil
+	}
+	b.rejected++
+	return fmt.Errorf("%w: %s failed %d times in a row (last error: %v), next attempt after %s",
+		ErrCircuitOpen, b.name, b.failures, b.lastErr, retryAt.Format(time.RFC3339))
+}
+
+// Record records the outcome of a call allowed by Allow. Cancelled calls,
+// rejections and errors of other backends say nothing about the backend and
+// are ignored.
+func (b *Breaker) Record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err != nil && !b.counts(err) {
+		b.probing = false
+		return
+	}
+	if err == nil {
+		if !b.openedAt.IsZero() {
+			b.logger.Info("closing circuit breaker, backend recovered", "backend", b.name, "open_for", b.now().Sub(b.openedAt).Round(time.Second))
+		}
+		b.failures = 0
+		b.openedAt = time.Time{}
+		b.probing = false
+		return
+	}
+
+	b.failures++
+	b.lastErr = err
+	if b.probing || (b.openedAt.IsZero() && b.failures >= b.threshold) {
+		if b.openedAt.IsZero() {
+			b.opens++
+			b.logger.Warn("opening circuit breaker, backend is failing", "backend", b.name, "failures", b.failures, "cooldown", b.cooldown, "error", err)
+		}
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+func (b *Breaker) counts(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var backendErr *BackendError
+	return !errors.As(err, &backendErr) || backendErr.Backend == b.name
+}
+
+// Do runs call unless the circuit is open and records its outcome.
+func (b *Breaker) Do(call func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	err := call()
+	b.Record(err)
+	return err
+}
+
+// Status returns a snapshot of the breaker.
+func (b *Breaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := BreakerStatus{
+		Name:     b.name,
+		State:    BreakerClosed,
+		Failures: b.failures,
+		Opens:    b.opens,
+		Rejected: b.rejected,
+	}
+	if b.lastErr != nil {
+		status.LastError = b.lastErr.Error()
+	}
+	if !b.openedAt.IsZero() {
+		openedAt, retryAt := b.openedAt, b.openedAt.Add(b.cooldown)
+		status.OpenedAt, status.RetryAt = &openedAt, &retryAt
+		status.State = BreakerOpen
+		if b.probing {
+			status.State = BreakerHalfOpen
+		}
+	}
+	return status
+}
@@ -0,0 +1,104 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+func newTestBreaker(now *time.Time) *Breaker {
+	b := NewBreaker("qdrant", 3, 30*time.Second, slog.New(slog.DiscardHandler))
+	b.now = func() time.Time { return *now }
+	return b
+}
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	b := newTestBreaker(&now)
+	failure := errors.New("connection refused")
+
+	for range 2 {
+		require.ErrorIs(t, b.Do(func() error { return failure }), failure)
+	}
+	b.Record(nil) // a success resets the count
+	for range 3 {
+		require.NoError(t, b.Allow())
+		b.Record(failure)
+	}
+	status := b.Status()
+	assert.Equal(t, BreakerOpen, status.State)
+	assert.Equal(t, 3, status.Failures)
+	assert.Equal(t, now.Add(30*time.Second), *status.RetryAt)
+
+	err := b.Do(func() error { t.Fatal("call made while the circuit is open"); return nil })
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Contains(t, err.Error(), "qdrant failed 3 times in a row (last error: connection refused)")
+
+	// After the cooldown a single call probes the backend.
+	now = now.Add(31 * time.Second)
+	require.NoError(t, b.Allow())
+	assert.Equal(t, BreakerHalfOpen, b.Status().State)
+	require.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+	b.Record(failure)
+	status = b.Status()
+	assert.Equal(t, BreakerOpen, status.State)
+	assert.Equal(t, now, *status.OpenedAt)
+
+	now = now.Add(31 * time.Second)
+	require.NoError(t, b.Do(func() error { return nil }))
+	status = b.Status()
+	assert.Equal(t, BreakerClosed, status.State)
+	assert.Zero(t, status.Failures)
+	assert.Equal(t, int64(1), status.Opens)
+	assert.Equal(t, int64(2), status.Rejected)
+}
+
+func TestBreaker_IgnoredErrors(t *testing.T) {
+	now := time.Now()
+	b := newTestBreaker(&now)
+	for _, err := range []error{
+		context.Canceled,
+		fmt.Errorf("search: %w", &BackendError{Backend: "embedder", Err: errors.New("ollama down")}),
+		fmt.Errorf("%w: embedder failed", ErrCircuitOpen),
+	} {
+		for range 3 {
+			b.Record(err)
+		}
+	}
+	assert.Equal(t, BreakerClosed, b.Status().State)
+
+	for range 3 {
+		b.Record(&BackendError{Backend: "qdrant", Err: errors.New("unavailable")})
+	}
+	assert.Equal(t, BreakerOpen, b.Status().State)
+}
+
+func TestMonitor_Breakers(t *testing.T) {
+	cfg := &config.Config{Server: config.ServerConfig{CircuitBreaker: config.CircuitBreakerConfig{
+		Enabled:          true,
+		FailureThreshold: 1,
+		Cooldown:         time.Minute,
+	}}}
+	m := NewMonitor(cfg, slog.New(slog.DiscardHandler))
+	assert.Same(t, m.Breaker("generator"), m.Breaker("generator"))
+	m.Breaker("qdrant").Record(errors.New("unavailable"))
+
+	status := m.Status()
+	require.Len(t, status.Breakers, 2)
+	assert.Equal(t, "generator", status.Breakers[0].Name)
+	assert.Equal(t, BreakerOpen, status.Breakers[1].State)
+	assert.False(t, status.Paused, "admission control is disabled")
+
+	cfg.Server.CircuitBreaker.Enabled = false
+	assert.Nil(t, NewMonitor(cfg, slog.New(slog.DiscardHandler)).Breaker("qdrant"))
+	var nilBreaker *Breaker
+	assert.NoError(t, nilBreaker.Do(func() error { return nil }))
+}
@@ -0,0 +1,156 @@
+package health
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// Check statuses.
+const (
+	CheckOK    = "ok"
+	CheckError = "error"
+)
+
+// Check is the outcome of probing one dependency.
+type Check struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type namedProbe struct {
+	name  string
+	probe ProbeFunc
+}
+
+// Checker probes the dependencies the server needs to accept and run
+// reviews: the database, the vector store, the LLM providers and the GitHub
+// App private key.
+type Checker struct {
+	probes  []namedProbe
+	timeout time.Duration
+}
+
+// checkTimeout bounds each probe, below the usual Kubernetes probe timeout.
+const checkTimeout = 3 * time.Second
+
+// NewChecker creates a Checker for the services of cfg. pingDB checks the
+// database connection.
+func NewChecker(cfg *config.Config, pingDB ProbeFunc) *Checker {
+	c := &Checker{timeout: checkTimeout}
+	c.add("postgres", pingDB)
+	c.add("vector_store", vectorStoreProbe(cfg))
+	for _, provider := range llmProviders(cfg) {
+		c.add(provider, llmProbe(cfg, provider))
+	}
+	if cfg.GitHub.PrivateKeyPath != "" {
+		c.add("github_private_key", privateKeyProbe(cfg.GitHub.PrivateKeyPath))
+	}
+	return c
+}
+
+func (c *Checker) add(name string, probe ProbeFunc) {
+	if probe != nil {
+		c.probes = append(c.probes, namedProbe{name: name, probe: probe})
+	}
+}
+
+// Check runs all probes concurrently and reports whether all passed.
+func (c *Checker) Check(ctx context.Context) ([]Check, bool) {
+	checks := make([]Check, len(c.probes))
+	var wg sync.WaitGroup
+	for i, p := range c.probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+			start := time.Now()
+			err := p.probe(ctx)
+			checks[i] = Check{Name: p.name, Status: CheckOK, LatencyMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				checks[i].Status, checks[i].Error = CheckError, err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	healthy := true
+	for _, check := range checks {
+		healthy = healthy && check.Status == CheckOK
+	}
+	return checks, healthy
+}
+
+// vectorStoreProbe returns the probe of the Qdrant or Weaviate of cfg, or
+// nil when the vector store needs no own probe (pgvector lives in the
+// database, the memory store in the process).
+func vectorStoreProbe(cfg *config.Config) ProbeFunc {
+	switch {
+	case cfg.Storage.UsesQdrant():
+		return HTTPProbe(qdrantHealthURL(cfg.Storage.QdrantHost))
+	case cfg.Storage.VectorStoreProvider == config.VectorStoreWeaviate:
+		return HTTPProbe(strings.TrimSuffix(cfg.Storage.WeaviateURL, "/") + "/v1/.well-known/ready")
+	default:
+		return nil
+	}
+}
+
+// llmProviders returns the distinct providers of the generator and the
+// embedder.
+func llmProviders(cfg *config.Config) []string {
+	providers := []string{cfg.AI.LLMProvider}
+	if embedder := cfg.AI.EmbedderProvider; embedder != "" && embedder != cfg.AI.LLMProvider {
+		providers = append(providers, embedder)
+	}
+	return providers
+}
+
+// geminiModelsURL lists one model, the cheapest call that proves the API key
+// is accepted.
+const geminiModelsURL = "https://generativelanguage.googleapis.com/v1beta/models?pageSize=1"
+
+// llmProbe lists the models of provider.
+func llmProbe(cfg *config.Config, provider string) ProbeFunc {
+	if provider == "gemini" {
+		return httpProbe(geminiModelsURL, http.Header{"X-Goog-Api-Key": {cfg.AI.GeminiAPIKey}})
+	}
+	host := cfg.AI.OllamaHost
+	if host == "" {
+		host = "http://localhost:11434"
+	}
+	return HTTPProbe(strings.TrimSuffix(host, "/") + "/api/tags")
+}
+
+// privateKeyProbe checks that the GitHub App private key at path can be
+// read and parsed, as it must be for every installation token.
+func privateKeyProbe(path string) ProbeFunc {
+	return func(context.Context) error {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read private key: %w", err)
+		}
+		block, _ := pem.Decode(data)
+		if block == nil {
+			return errors.New("private key is not PEM encoded")
+		}
+		if _, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+			return nil
+		}
+		if _, err := x509.ParsePKCS8PrivateKey(block.Bytes); err != nil {
+			return fmt.Errorf("failed to parse private key: %w", err)
+		}
+		return nil
+	}
+}
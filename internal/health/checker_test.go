@@ -0,0 +1,64 @@
+package health
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+func TestChecker_Check(t *testing.T) {
+	c := &Checker{timeout: time.Second}
+	c.add("postgres", func(context.Context) error { return nil })
+	c.add("vector_store", nil) // skipped
+	c.add("ollama", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	checks, healthy := c.Check(context.Background())
+	assert.False(t, healthy)
+	require.Len(t, checks, 2)
+	assert.Equal(t, Check{Name: "postgres", Status: CheckOK}, checks[0])
+	assert.Equal(t, CheckError, checks[1].Status)
+	assert.Contains(t, checks[1].Error, "deadline exceeded")
+}
+
+func TestNewChecker(t *testing.T) {
+	cfg := &config.Config{
+		AI:      config.AIConfig{LLMProvider: "gemini", EmbedderProvider: "ollama"},
+		Storage: config.StorageConfig{VectorStoreProvider: config.VectorStorePgvector},
+	}
+	c := NewChecker(cfg, func(context.Context) error { return errors.New("refused") })
+	var names []string
+	for _, p := range c.probes {
+		names = append(names, p.name)
+	}
+	assert.Equal(t, []string{"postgres", "gemini", "ollama"}, names)
+}
+
+func TestPrivateKeyProbe(t *testing.T) {
+	dir := t.TempDir()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	valid := filepath.Join(dir, "app.pem")
+	require.NoError(t, os.WriteFile(valid, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600))
+	invalid := filepath.Join(dir, "invalid.pem")
+	require.NoError(t, os.WriteFile(invalid, []byte("not a key"), 0o600))
+
+	ctx := context.Background()
+	require.NoError(t, privateKeyProbe(valid)(ctx))
+	assert.ErrorContains(t, privateKeyProbe(invalid)(ctx), "not PEM encoded")
+	assert.ErrorContains(t, privateKeyProbe(filepath.Join(dir, "missing.pem"))(ctx), "failed to read private key")
+}
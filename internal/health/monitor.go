@@ -0,0 +1,279 @@
+// Package health tracks the health of the services that reviews depend on, so
+// that review jobs can be held back while those services are failing instead
+// of being run and failing.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// Status is a snapshot of the health of the review dependencies.
+type Status struct {
+	// Paused reports that review jobs are being held.
+	Paused bool `json:"paused"`
+	// Since is when admission was paused.
+	Since *time.Time `json:"since,omitempty"`
+	// Reasons explains why admission is paused.
+	Reasons []string `json:"reasons,omitempty"`
+	// LLMCalls and LLMErrorRate describe the LLM calls within the window.
+	LLMCalls     int     `json:"llm_calls"`
+	LLMErrorRate float64 `json:"llm_error_rate"`
+	// QdrantLatencyMs is the latency of the last Qdrant probe.
+	QdrantLatencyMs int64  `json:"qdrant_latency_ms"`
+	QdrantError     string `json:"qdrant_error,omitempty"`
+	// Breakers are the circuit breakers of the backends, by name.
+	Breakers []BreakerStatus `json:"breakers,omitempty"`
+}
+
+// ProbeFunc checks a service and returns an error when it is unavailable.
+type ProbeFunc func(ctx context.Context) error
+
+// llmCall is the outcome of one LLM call.
+type llmCall struct {
+	at     time.Time
+	failed bool
+}
+
+// Monitor records LLM call outcomes and probes Qdrant in the background to
+// decide whether review jobs should be admitted. It is safe for concurrent
+// use. When admission control is disabled it never reports a pause.
+type Monitor struct {
+	cfg    config.AdmissionConfig
+	cbCfg  config.CircuitBreakerConfig
+	probe  ProbeFunc
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu            sync.Mutex
+	calls         []llmCall
+	qdrantLatency time.Duration
+	qdrantErr     error
+	pausedSince   time.Time
+	breakers      map[string]*Breaker
+
+	stopCh   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMonitor creates a Monitor that probes the Qdrant or Weaviate of cfg.
+// With another vector store only LLM calls decide admission.
+func NewMonitor(cfg *config.Config, logger *slog.Logger) *Monitor {
+	probe := vectorStoreProbe(cfg)
+	if probe == nil {
+		probe = func(context.Context) error { return nil }
+	}
+	return &Monitor{
+		cfg:      cfg.Server.Admission,
+		cbCfg:    cfg.Server.CircuitBreaker,
+		probe:    probe,
+		logger:   logger,
+		now:      time.Now,
+		breakers: make(map[string]*Breaker),
+		stopCh:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Breaker returns the circuit breaker of the backend name, creating it on
+// first use, or nil when circuit breakers are disabled.
+func (m *Monitor) Breaker(name string) *Breaker {
+	if !m.cbCfg.Enabled {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.breakers[name]
+	if !ok {
+		b = NewBreaker(name, m.cbCfg.FailureThreshold, m.cbCfg.Cooldown, m.logger)
+		m.breakers[name] = b
+	}
+	return b
+}
+
+// RecordLLM records the outcome of an LLM call. Calls cancelled by their
+// caller say nothing about the provider and are ignored.
+func (m *Monitor) RecordLLM(err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, llmCall{at: m.now(), failed: err != nil})
+	m.evaluateLocked()
+}
+
+// Status returns the current health and whether admission is paused.
+func (m *Monitor) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.evaluateLocked()
+}
+
+// Start probes Qdrant every probe_interval in the background. It does
+// nothing when admission control is disabled.
+func (m *Monitor) Start() {
+	if !m.cfg.Enabled {
+		close(m.done)
+		return
+	}
+	go func() {
+		defer close(m.done)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-m.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		m.probeQdrant(ctx)
+		ticker := time.NewTicker(m.cfg.ProbeInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stopCh:
+				return
+			case <-ticker.C:
+				m.probeQdrant(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the background probing. It must only be called after Start.
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() { close(m.stopCh) })
+	<-m.done
+}
+
+func (m *Monitor) probeQdrant(ctx context.Context) {
+	// A probe that cannot finish within twice the latency limit fails.
+	ctx, cancel := context.WithTimeout(ctx, 2*m.cfg.MaxQdrantLatency)
+	defer cancel()
+	start := m.now()
+	err := m.probe(ctx)
+	latency := m.now().Sub(start)
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return // stopping
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.qdrantLatency = latency
+	m.qdrantErr = err
+	m.evaluateLocked()
+}
+
+// evaluateLocked drops LLM calls that left the window, computes the status
+// and logs when admission is paused or resumed. m.mu must be held.
+func (m *Monitor) evaluateLocked() Status {
+	cutoff := m.now().Add(-m.cfg.LLMWindow)
+	first := 0
+	for first < len(m.calls) && m.calls[first].at.Before(cutoff) {
+		first++
+	}
+	m.calls = m.calls[first:]
+
+	status := Status{
+		LLMCalls:        len(m.calls),
+		QdrantLatencyMs: m.qdrantLatency.Milliseconds(),
+	}
+	if len(m.calls) > 0 {
+		failed := 0
+		for _, c := range m.calls {
+			if c.failed {
+				failed++
+			}
+		}
+		status.LLMErrorRate = float64(failed) / float64(len(m.calls))
+	}
+	if m.qdrantErr != nil {
+		status.QdrantError = m.qdrantErr.Error()
+	}
+	for _, name := range slices.Sorted(maps.Keys(m.breakers)) {
+		status.Breakers = append(status.Breakers, m.breakers[name].Status())
+	}
+	if !m.cfg.Enabled {
+		return status
+	}
+
+	if status.LLMCalls >= m.cfg.MinLLMCalls && status.LLMErrorRate >= m.cfg.MaxLLMErrorRate {
+		status.Reasons = append(status.Reasons, fmt.Sprintf("%.0f%% of %d LLM calls in the last %s failed", status.LLMErrorRate*100, status.LLMCalls, m.cfg.LLMWindow))
+	}
+	switch {
+	case m.qdrantErr != nil:
+		status.Reasons = append(status.Reasons, "Qdrant health probe failed: "+m.qdrantErr.Error())
+	case m.qdrantLatency > m.cfg.MaxQdrantLatency:
+		status.Reasons = append(status.Reasons, fmt.Sprintf("Qdrant responded in %s, above the %s limit", m.qdrantLatency.Round(time.Millisecond), m.cfg.MaxQdrantLatency))
+	}
+
+	status.Paused = len(status.Reasons) > 0
+	switch {
+	case status.Paused && m.pausedSince.IsZero():
+		m.pausedSince = m.now()
+		m.logger.Warn("pausing review job admission, dependencies are unhealthy", "reasons", status.Reasons)
+	case !status.Paused && !m.pausedSince.IsZero():
+		m.logger.Info("resuming review job admission", "paused_for", m.now().Sub(m.pausedSince).Round(time.Second))
+		m.pausedSince = time.Time{}
+	}
+	if status.Paused {
+		since := m.pausedSince
+		status.Since = &since
+	}
+	return status
+}
+
+// HTTPProbe returns a probe that GETs url and fails on errors and non-2xx
+// responses.
+func HTTPProbe(url string) ProbeFunc {
+	return httpProbe(url, nil)
+}
+
+// httpProbe is HTTPProbe with request headers, like API keys.
+func httpProbe(url string, header http.Header) ProbeFunc {
+	client := &http.Client{}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return fmt.Errorf("failed to create probe request: %w", err)
+		}
+		maps.Copy(req.Header, header)
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status %s", resp.Status)
+		}
+		return nil
+	}
+}
+
+// qdrantHealthURL returns the HTTP health endpoint of the Qdrant at host,
+// which is usually configured with its gRPC port.
+func qdrantHealthURL(host string) string {
+	if rest, ok := strings.CutSuffix(host, ":6334"); ok {
+		host = rest + ":6333"
+	}
+	if !strings.HasPrefix(host, "http") {
+		host = "http://" + host
+	}
+	return host + "/healthz"
+}
@@ -0,0 +1,103 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+func newTestMonitor(now *time.Time, probe ProbeFunc) *Monitor {
+	cfg := &config.Config{Server: config.ServerConfig{Admission: config.AdmissionConfig{
+		Enabled:          true,
+		MaxLLMErrorRate:  0.5,
+		MinLLMCalls:      4,
+		LLMWindow:        5 * time.Minute,
+		MaxQdrantLatency: time.Second,
+		ProbeInterval:    time.Minute,
+	}}}
+	m := NewMonitor(cfg, slog.New(slog.DiscardHandler))
+	m.now = func() time.Time { return *now }
+	m.probe = probe
+	return m
+}
+
+func TestMonitor_LLMErrorRate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := newTestMonitor(&now, nil)
+
+	// Too few calls to judge the provider.
+	for range 3 {
+		m.RecordLLM(errors.New("503"))
+	}
+	assert.False(t, m.Status().Paused)
+
+	m.RecordLLM(nil)
+	m.RecordLLM(context.Canceled) // ignored
+	status := m.Status()
+	require.True(t, status.Paused)
+	assert.Equal(t, 4, status.LLMCalls)
+	assert.InDelta(t, 0.75, status.LLMErrorRate, 0.001)
+	assert.Contains(t, status.Reasons[0], "75% of 4 LLM calls")
+	assert.Equal(t, now, *status.Since)
+
+	// Failures age out of the window, which lets the next job through.
+	now = now.Add(6 * time.Minute)
+	status = m.Status()
+	assert.False(t, status.Paused)
+	assert.Zero(t, status.LLMCalls)
+}
+
+func TestMonitor_Qdrant(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var probeErr error
+	delay := 10 * time.Millisecond
+	m := newTestMonitor(&now, func(context.Context) error {
+		now = now.Add(delay)
+		return probeErr
+	})
+
+	m.probeQdrant(context.Background())
+	assert.False(t, m.Status().Paused)
+	assert.Equal(t, int64(10), m.Status().QdrantLatencyMs)
+
+	delay = 1500 * time.Millisecond
+	m.probeQdrant(context.Background())
+	status := m.Status()
+	require.True(t, status.Paused)
+	assert.Contains(t, status.Reasons[0], "above the 1s limit")
+
+	probeErr = errors.New("connection refused")
+	delay = 0
+	m.probeQdrant(context.Background())
+	status = m.Status()
+	require.True(t, status.Paused)
+	assert.Contains(t, status.Reasons[0], "connection refused")
+
+	probeErr = nil
+	m.probeQdrant(context.Background())
+	assert.False(t, m.Status().Paused)
+}
+
+func TestMonitor_Disabled(t *testing.T) {
+	now := time.Now()
+	m := newTestMonitor(&now, nil)
+	m.cfg.Enabled = false
+	for range 5 {
+		m.RecordLLM(errors.New("503"))
+	}
+	status := m.Status()
+	assert.False(t, status.Paused)
+	assert.Equal(t, 5, status.LLMCalls)
+}
+
+func TestQdrantHealthURL(t *testing.T) {
+	assert.Equal(t, "http://qdrant:6333/healthz", qdrantHealthURL("qdrant:6334"))
+	assert.Equal(t, "https://q.example.com/healthz", qdrantHealthURL("https://q.example.com"))
+}
@@ -0,0 +1,208 @@
+// Package indexarchive moves the index of a repository between environments:
+// the points of its vector collection and its repository record are written
+// to one portable archive, so an index built once, e.g. in CI, can be loaded
+// elsewhere without embedding the code again.
+//
+// An archive is a gzip-compressed stream of JSON values: the Manifest, then
+// the collection as written by storage.CollectionExporter.
+package indexarchive
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/repomanager"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// FormatVersion is the version of the archives written by Export. Import
+// rejects archives of any other version.
+const FormatVersion = 1
+
+// Store is the part of storage.Store the repository record is read from and
+// written to.
+type Store interface {
+	GetRepositoryByFullName(ctx context.Context, fullName string) (*storage.Repository, error)
+	CreateRepository(ctx context.Context, repo *storage.Repository) error
+	UpdateRepository(ctx context.Context, repo *storage.Repository) error
+	GetFilesForRepo(ctx context.Context, repoID int64) (map[string]storage.FileRecord, error)
+	UpsertFiles(ctx context.Context, repoID int64, files []storage.FileRecord) error
+	DeleteFiles(ctx context.Context, repoID int64, paths []string) error
+}
+
+// Manifest is the part of the repository record an archive carries. Fields
+// that only make sense where the index was built, such as the clone path or
+// database ids, are left out.
+type Manifest struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Repo       string    `json:"repo"`
+	// EmbedderModel is the model the points were embedded with. Queries
+	// against the imported collection must use the same model.
+	EmbedderModel    string    `json:"embedder_model"`
+	LastIndexedSHA   string    `json:"last_indexed_sha"`
+	GeneratedContext string    `json:"generated_context,omitempty"`
+	ContextUpdatedAt time.Time `json:"context_updated_at,omitzero"`
+	// Files are the indexed files, so the next scan only re-indexes what
+	// changed since LastIndexedSHA.
+	Files []File `json:"files"`
+
+	// Points is the number of points exported or imported. It is not part
+	// of the archive.
+	Points int `json:"-"`
+}
+
+// File is an indexed file of the repository.
+type File struct {
+	Path   string `json:"path"`
+	Hash   string `json:"hash"`
+	Chunks int    `json:"chunks"`
+}
+
+// Export writes the archive of repoFullName to w. embedderModel is the
+// configured model, used for repositories that record none.
+func Export(ctx context.Context, w io.Writer, store Store, exporter storage.CollectionExporter, repoFullName, embedderModel string) (*Manifest, error) {
+	rec, err := store.GetRepositoryByFullName(ctx, repoFullName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+	if rec.IsCold() {
+		return nil, fmt.Errorf("repository %s is in cold storage, thaw it before exporting", repoFullName)
+	}
+	files, err := store.GetFilesForRepo(ctx, rec.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get files: %w", err)
+	}
+
+	manifest := &Manifest{
+		Version:          FormatVersion,
+		ExportedAt:       time.Now().UTC(),
+		Repo:             rec.FullName,
+		EmbedderModel:    rec.Embedder(embedderModel),
+		LastIndexedSHA:   rec.LastIndexedSHA,
+		GeneratedContext: rec.GeneratedContext,
+		Files:            make([]File, 0, len(files)),
+	}
+	if rec.ContextUpdatedAt.Valid {
+		manifest.ContextUpdatedAt = rec.ContextUpdatedAt.Time
+	}
+	for _, path := range slices.Sorted(maps.Keys(files)) {
+		f := files[path]
+		manifest.Files = append(manifest.Files, File{Path: f.FilePath, Hash: f.FileHash, Chunks: f.ChunkCount})
+	}
+
+	gz := gzip.NewWriter(w)
+	if err := json.NewEncoder(gz).Encode(manifest); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	points, err := exporter.Export(ctx, rec.QdrantCollectionName, gz)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export collection: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	manifest.Points = points
+	return manifest, nil
+}
+
+// Import loads the archive read from r. The collection of the repository is
+// replaced by the archived one and the repository record is created, or
+// updated, to match it. clonePath is the local checkout of the repository;
+// when empty, an existing record keeps its path.
+func Import(ctx context.Context, r io.Reader, store Store, exporter storage.CollectionExporter, clonePath string, logger *slog.Logger) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+
+	dec := json.NewDecoder(gz)
+	var manifest Manifest
+	if err := dec.Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if manifest.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported archive version %d, expected %d", manifest.Version, FormatVersion)
+	}
+	if manifest.Repo == "" {
+		return nil, errors.New("archive names no repository")
+	}
+
+	rec, err := store.GetRepositoryByFullName(ctx, manifest.Repo)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		rec = &storage.Repository{
+			FullName:             manifest.Repo,
+			QdrantCollectionName: repomanager.GenerateCollectionName(manifest.Repo),
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	case rec.IsCold():
+		return nil, fmt.Errorf("repository %s is in cold storage, thaw or delete it before importing", manifest.Repo)
+	}
+
+	// Load the points before touching the record, so a failed import never
+	// leaves a record that claims an index the collection does not hold.
+	points, err := exporter.Import(ctx, rec.QdrantCollectionName, io.MultiReader(dec.Buffered(), gz))
+	if err != nil {
+		return nil, fmt.Errorf("failed to import collection: %w", err)
+	}
+	manifest.Points = points
+
+	rec.LastIndexedSHA = manifest.LastIndexedSHA
+	rec.EmbedderModel = manifest.EmbedderModel
+	rec.GeneratedContext = manifest.GeneratedContext
+	rec.ContextUpdatedAt.Time = manifest.ContextUpdatedAt
+	rec.ContextUpdatedAt.Valid = !manifest.ContextUpdatedAt.IsZero()
+	if clonePath != "" {
+		rec.ClonePath = clonePath
+	}
+	if rec.ID == 0 {
+		err = store.CreateRepository(ctx, rec)
+	} else {
+		err = store.UpdateRepository(ctx, rec)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save repository: %w", err)
+	}
+
+	if err := replaceFiles(ctx, store, rec.ID, manifest.Files); err != nil {
+		return nil, err
+	}
+	logger.InfoContext(ctx, "repository index imported",
+		"repo", manifest.Repo,
+		"collection", rec.QdrantCollectionName,
+		"points", points,
+		"files", len(manifest.Files),
+	)
+	return &manifest, nil
+}
+
+// replaceFiles makes files the tracked files of the repository.
+func replaceFiles(ctx context.Context, store Store, repoID int64, files []File) error {
+	existing, err := store.GetFilesForRepo(ctx, repoID)
+	if err != nil {
+		return fmt.Errorf("failed to get files: %w", err)
+	}
+	records := make([]storage.FileRecord, 0, len(files))
+	for _, f := range files {
+		delete(existing, f.Path)
+		records = append(records, storage.FileRecord{RepositoryID: repoID, FilePath: f.Path, FileHash: f.Hash, ChunkCount: f.Chunks})
+	}
+	if err := store.DeleteFiles(ctx, repoID, slices.Sorted(maps.Keys(existing))); err != nil {
+		return fmt.Errorf("failed to delete stale files: %w", err)
+	}
+	if err := store.UpsertFiles(ctx, repoID, records); err != nil {
+		return fmt.Errorf("failed to save files: %w", err)
+	}
+	return nil
+}
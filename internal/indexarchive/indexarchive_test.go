@@ -0,0 +1,192 @@
+package indexarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// fakeStore keeps repositories and their files in memory.
+type fakeStore struct {
+	repos  map[string]*storage.Repository
+	files  map[int64]map[string]storage.FileRecord
+	nextID int64
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{repos: make(map[string]*storage.Repository), files: make(map[int64]map[string]storage.FileRecord)}
+}
+
+func (s *fakeStore) GetRepositoryByFullName(_ context.Context, fullName string) (*storage.Repository, error) {
+	rec, ok := s.repos[fullName]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	cp := *rec
+	return &cp, nil
+}
+
+func (s *fakeStore) CreateRepository(_ context.Context, repo *storage.Repository) error {
+	s.nextID++
+	repo.ID = s.nextID
+	cp := *repo
+	s.repos[repo.FullName] = &cp
+	return nil
+}
+
+func (s *fakeStore) UpdateRepository(_ context.Context, repo *storage.Repository) error {
+	cp := *repo
+	s.repos[repo.FullName] = &cp
+	return nil
+}
+
+func (s *fakeStore) GetFilesForRepo(_ context.Context, repoID int64) (map[string]storage.FileRecord, error) {
+	files := make(map[string]storage.FileRecord)
+	for path, f := range s.files[repoID] {
+		files[path] = f
+	}
+	return files, nil
+}
+
+func (s *fakeStore) UpsertFiles(_ context.Context, repoID int64, files []storage.FileRecord) error {
+	if s.files[repoID] == nil {
+		s.files[repoID] = make(map[string]storage.FileRecord)
+	}
+	for _, f := range files {
+		s.files[repoID][f.FilePath] = f
+	}
+	return nil
+}
+
+func (s *fakeStore) DeleteFiles(_ context.Context, repoID int64, paths []string) error {
+	for _, path := range paths {
+		delete(s.files[repoID], path)
+	}
+	return nil
+}
+
+// fakeExporter keeps collections as the raw stream it was given.
+type fakeExporter struct {
+	collections map[string]string
+}
+
+func (e *fakeExporter) Export(_ context.Context, collection string, w io.Writer) (int, error) {
+	data := e.collections[collection]
+	_, err := io.WriteString(w, data)
+	return strings.Count(data, "\n"), err
+}
+
+func (e *fakeExporter) Import(_ context.Context, collection string, r io.Reader) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	// The manifest's trailing newline precedes the collection stream.
+	e.collections[collection] = strings.TrimPrefix(string(data), "\n")
+	return strings.Count(e.collections[collection], "\n"), nil
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.DiscardHandler)
+	const points = "{\"vectors\":{}}\n{\"id\":1}\n{\"id\":2}\n"
+
+	ci := newFakeStore()
+	require.NoError(t, ci.CreateRepository(ctx, &storage.Repository{
+		FullName:             "owner/app",
+		ClonePath:            "/ci/app",
+		QdrantCollectionName: "ci-owner-app",
+		LastIndexedSHA:       "abc123",
+		GeneratedContext:     "a shop",
+		ContextUpdatedAt:     sql.NullTime{Time: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), Valid: true},
+	}))
+	require.NoError(t, ci.UpsertFiles(ctx, 1, []storage.FileRecord{
+		{FilePath: "main.go", FileHash: "h1", ChunkCount: 3},
+		{FilePath: "shop/price.go", FileHash: "h2", ChunkCount: 1},
+	}))
+	ciVectors := &fakeExporter{collections: map[string]string{"ci-owner-app": points}}
+
+	var archive bytes.Buffer
+	exported, err := Export(ctx, &archive, ci, ciVectors, "owner/app", "nomic-embed-text")
+	require.NoError(t, err)
+	assert.Equal(t, 3, exported.Points)
+	assert.Equal(t, "nomic-embed-text", exported.EmbedderModel)
+	assert.Equal(t, []File{{Path: "main.go", Hash: "h1", Chunks: 3}, {Path: "shop/price.go", Hash: "h2", Chunks: 1}}, exported.Files)
+
+	dev := newFakeStore()
+	require.NoError(t, dev.CreateRepository(ctx, &storage.Repository{FullName: "owner/app", ClonePath: "/home/dev/app", QdrantCollectionName: "dev-owner-app"}))
+	require.NoError(t, dev.UpsertFiles(ctx, 1, []storage.FileRecord{{FilePath: "gone.go", FileHash: "h0"}}))
+	devVectors := &fakeExporter{collections: make(map[string]string)}
+
+	imported, err := Import(ctx, &archive, dev, devVectors, "", logger)
+	require.NoError(t, err)
+	assert.Equal(t, 3, imported.Points)
+	assert.Equal(t, points, devVectors.collections["dev-owner-app"], "points go into the local collection")
+
+	rec := dev.repos["owner/app"]
+	assert.Equal(t, "/home/dev/app", rec.ClonePath, "the local clone path is kept")
+	assert.Equal(t, "abc123", rec.LastIndexedSHA)
+	assert.Equal(t, "nomic-embed-text", rec.EmbedderModel)
+	assert.Equal(t, "a shop", rec.GeneratedContext)
+	assert.True(t, rec.ContextUpdatedAt.Valid)
+
+	files, err := dev.GetFilesForRepo(ctx, rec.ID)
+	require.NoError(t, err)
+	assert.Len(t, files, 2)
+	assert.NotContains(t, files, "gone.go")
+	assert.Equal(t, 3, files["main.go"].ChunkCount)
+}
+
+func TestImport_NewRepository(t *testing.T) {
+	ctx := context.Background()
+	ci := newFakeStore()
+	require.NoError(t, ci.CreateRepository(ctx, &storage.Repository{FullName: "owner/app", QdrantCollectionName: "c"}))
+
+	var archive bytes.Buffer
+	_, err := Export(ctx, &archive, ci, &fakeExporter{collections: map[string]string{"c": "{}\n"}}, "owner/app", "m")
+	require.NoError(t, err)
+
+	dev := newFakeStore()
+	devVectors := &fakeExporter{collections: make(map[string]string)}
+	_, err = Import(ctx, &archive, dev, devVectors, "/src/app", slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	rec := dev.repos["owner/app"]
+	require.NotNil(t, rec)
+	assert.Equal(t, "/src/app", rec.ClonePath)
+	assert.Equal(t, "repo-owner-app", rec.QdrantCollectionName)
+	assert.Contains(t, devVectors.collections, rec.QdrantCollectionName)
+}
+
+func TestExportImport_Rejects(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.DiscardHandler)
+	vectors := &fakeExporter{collections: make(map[string]string)}
+
+	store := newFakeStore()
+	require.NoError(t, store.CreateRepository(ctx, &storage.Repository{FullName: "owner/cold", ColdSnapshotKey: "snapshots/x"}))
+	_, err := Export(ctx, io.Discard, store, vectors, "owner/cold", "m")
+	assert.ErrorContains(t, err, "cold storage")
+
+	_, err = Import(ctx, strings.NewReader("not gzip"), store, vectors, "", logger)
+	assert.Error(t, err)
+
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	_, _ = io.WriteString(gz, `{"version": 99, "repo": "owner/app"}`)
+	require.NoError(t, gz.Close())
+	_, err = Import(ctx, &archive, store, vectors, "", logger)
+	assert.ErrorContains(t, err, "unsupported archive version 99")
+	assert.Empty(t, vectors.collections)
+}
@@ -0,0 +1,156 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/sevigo/code-warden/internal/applyfix"
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/gitutil"
+)
+
+// applyAuthor signs the commits of "/warden apply".
+var applyAuthor = object.Signature{Name: "code-warden[bot]", Email: "code-warden[bot]@users.noreply.github.com"}
+
+// runApplySuggestion handles a "/warden apply" reply: it applies the code
+// suggestion of the thread to the head of the pull request and either pushes
+// the commit to its branch or opens a follow-up pull request against it.
+// The commit is written to the managed clone's object store only; its
+// worktree and branches are left alone.
+func (j *ReviewJob) runApplySuggestion(ctx context.Context, event *core.GitHubEvent) (err error) {
+	j.logger.InfoContext(ctx, "🩹 Applying suggestion", "repo", event.RepoFullName, "pr", event.PRNumber, "comment_id", event.ThreadCommentID)
+	finish := j.startJobRun(ctx, "reply", event, "webhook:/warden apply")
+	defer func() { finish(ctx, err) }()
+
+	ghClient, token, err := github.CreateInstallationClient(ctx, j.cfg, event.InstallationID, j.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	reply := func(body string) error {
+		if err := ghClient.ReplyToReviewComment(ctx, event.RepoOwner, event.RepoName, event.PRNumber, event.ThreadCommentID, body); err != nil {
+			return fmt.Errorf("failed to post reply: %w", err)
+		}
+		return nil
+	}
+
+	applyCfg := j.cfg.GitHub.ApplyFix
+	if !applyCfg.Enabled {
+		return reply("`/warden apply` is not enabled on this Code-Warden instance.")
+	}
+
+	comment, err := ghClient.GetReviewComment(ctx, event.RepoOwner, event.RepoName, event.ThreadCommentID)
+	if err != nil {
+		return fmt.Errorf("failed to get review comment %d: %w", event.ThreadCommentID, err)
+	}
+	botLogin, err := github.AppLogin(ctx, j.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to look up the app login: %w", err)
+	}
+	if _, ok := github.ParseSuggestion(comment, botLogin); !ok {
+		return reply("`/warden apply` only works as a reply to a Code-Warden suggestion.")
+	}
+	if comment.Line == 0 {
+		return reply("This suggestion is outdated: its line is no longer part of the diff. Run `/review` for a fresh review.")
+	}
+	replacement, err := applyfix.ExtractSuggestion(comment.Body)
+	if errors.Is(err, applyfix.ErrNoSuggestion) {
+		return reply("This comment has no code suggestion to apply. Reply `/warden fix` to ask for one.")
+	}
+	if err != nil {
+		return err
+	}
+
+	pr, err := ghClient.GetPullRequest(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get pull request #%d: %w", event.PRNumber, err)
+	}
+	if pr.GetHead().GetRepo().GetFullName() != event.RepoFullName {
+		return reply("Suggestions cannot be applied to pull requests from forks.")
+	}
+	headRef, headSHA := pr.GetHead().GetRef(), pr.GetHead().GetSHA()
+
+	record, err := j.store.GetRepositoryByFullName(ctx, event.RepoFullName)
+	if err != nil || record.ClonePath == "" {
+		return reply("This repository has no local clone yet. Run `/review` first, then reply `/warden apply` again.")
+	}
+	mutex := j.getRepoMutex(event.RepoFullName)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	repo, err := gitutil.NewClient(j.logger).Open(record.ClonePath)
+	if err != nil {
+		return err
+	}
+	pullRef := fmt.Sprintf("refs/code-warden/pull/%d", event.PRNumber)
+	if err := gitutil.FetchRef(ctx, repo, event.RepoCloneURL, token, fmt.Sprintf("+refs/pull/%d/head:%s", event.PRNumber, pullRef)); err != nil {
+		return err
+	}
+	content, err := gitutil.ReadFile(repo, headSHA, comment.Path)
+	if err != nil {
+		return reply(fmt.Sprintf("`%s` no longer exists on `%s`, so the suggestion cannot be applied.", comment.Path, headRef))
+	}
+	patched, err := applyfix.Apply(content, applyfix.Patch{
+		StartLine:   comment.StartLine,
+		Line:        comment.Line,
+		Original:    applyfix.ThreadLines(comment.DiffHunk, comment.StartLine, comment.Line),
+		Replacement: replacement,
+	})
+	switch {
+	case errors.Is(err, applyfix.ErrAlreadyApplied):
+		return reply(fmt.Sprintf("This suggestion is already applied on `%s`.", headRef))
+	case errors.Is(err, applyfix.ErrConflict):
+		return reply(fmt.Sprintf("The lines of this suggestion changed on `%s` since it was made, so it cannot be applied cleanly. Run `/review` for a fresh review.", headRef))
+	case err != nil:
+		return err
+	}
+
+	suggestionURL := fmt.Sprintf("%s#discussion_r%d", pr.GetHTMLURL(), event.ThreadCommentID)
+	author := applyAuthor
+	author.When = time.Now()
+	message := fmt.Sprintf("Apply Code-Warden suggestion to %s\n\nRequested by @%s in %s", comment.Path, event.Commenter, suggestionURL)
+	commit, err := gitutil.CommitFile(repo, headSHA, comment.Path, patched, message, author)
+	if err != nil {
+		return err
+	}
+
+	if applyCfg.Mode == config.ApplyFixCommit {
+		err := gitutil.PushCommit(ctx, repo, event.RepoCloneURL, token, commit, headRef)
+		if errors.Is(err, gitutil.ErrNonFastForward) {
+			return reply(fmt.Sprintf("`%s` moved while the suggestion was applied. Reply `/warden apply` again.", headRef))
+		}
+		if err != nil {
+			return err
+		}
+		j.logger.InfoContext(ctx, "applied suggestion", "repo", event.RepoFullName, "pr", event.PRNumber, "commit", commit)
+		return reply(fmt.Sprintf("✅ Applied in %s.", commit[:7]))
+	}
+
+	branch := fmt.Sprintf("%s%d-%d", applyCfg.BranchPrefix, event.PRNumber, event.ThreadCommentID)
+	if err := gitutil.ValidateBranchName(branch); err != nil {
+		return err
+	}
+	err = gitutil.PushCommit(ctx, repo, event.RepoCloneURL, token, commit, branch)
+	if errors.Is(err, gitutil.ErrNonFastForward) {
+		return reply(fmt.Sprintf("Branch `%s` already holds other changes. Delete it and reply `/warden apply` again.", branch))
+	}
+	if err != nil {
+		return err
+	}
+	fixPR, err := ghClient.CreatePullRequest(ctx, event.RepoOwner, event.RepoName, github.PullRequestOptions{
+		Title: fmt.Sprintf("Apply Code-Warden suggestion to %s", comment.Path),
+		Body:  fmt.Sprintf("Applies the [Code-Warden suggestion](%s) on #%d, as requested by @%s.", suggestionURL, event.PRNumber, event.Commenter),
+		Head:  branch,
+		Base:  headRef,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open pull request for %s: %w", branch, err)
+	}
+	j.logger.InfoContext(ctx, "opened pull request for suggestion", "repo", event.RepoFullName, "pr", event.PRNumber, "fix_pr", fixPR.GetNumber())
+	return reply(fmt.Sprintf("✅ Opened #%d with this suggestion against `%s`.", fixPR.GetNumber(), headRef))
+}
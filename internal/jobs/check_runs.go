@@ -0,0 +1,322 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	gogithub "github.com/google/go-github/v73/github"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+const (
+	checkRunCompleted  = "completed"
+	staleCheckRunTitle = "Run Interrupted"
+	// staleCheckRunSummary is shown on check runs whose job never finished,
+	// typically because the server restarted while it was running.
+	staleCheckRunSummary = "Code-Warden stopped before this run finished, most likely because the server " +
+		"restarted. No result is available; comment `/review` on the pull request to run it again."
+	// staleCheckRunRequeuedSummary is shown instead when the review is
+	// queued again.
+	staleCheckRunRequeuedSummary = "Code-Warden stopped before this run finished, most likely because the server " +
+		"restarted. No result is available; the review was queued to run again."
+)
+
+// requeueKinds are the jobs the CheckRunReaper queues again when their check
+// run went stale, by the kind recorded with the check run.
+var requeueKinds = map[string]core.ReviewType{
+	core.FullReview.String():     core.FullReview,
+	core.ReReview.String():       core.ReReview,
+	core.SecurityReview.String(): core.SecurityReview,
+}
+
+// checkRunTracker records the check runs created through a GitHub client, so
+// runs left in progress by a crashed job can be found by the CheckRunReaper.
+type checkRunTracker struct {
+	github.Client
+	event  *core.GitHubEvent
+	store  storage.CheckRunStore
+	active *sync.Map
+	logger *slog.Logger
+}
+
+// trackCheckRuns wraps the installation client of event's job so that its
+// check runs are recorded in the store, together with the job, and marked
+// active until they complete.
+func (j *ReviewJob) trackCheckRuns(client github.Client, event *core.GitHubEvent) github.Client {
+	return &checkRunTracker{
+		Client: client,
+		event:  event,
+		store:  j.store,
+		active: &j.activeCheckRuns,
+		logger: j.logger,
+	}
+}
+
+// CreateCheckRun creates the check run and records it while in progress.
+// Failing to record it is logged and does not fail the job.
+func (t *checkRunTracker) CreateCheckRun(ctx context.Context, owner, repo string, opts gogithub.CreateCheckRunOptions) (*gogithub.CheckRun, error) {
+	checkRun, err := t.Client.CreateCheckRun(ctx, owner, repo, opts)
+	if err != nil || checkRun.GetID() == 0 || opts.GetStatus() == checkRunCompleted {
+		return checkRun, err
+	}
+
+	t.active.Store(checkRun.GetID(), struct{}{})
+	run := &storage.CheckRun{
+		ID:             checkRun.GetID(),
+		InstallationID: t.event.InstallationID,
+		RepoOwner:      owner,
+		RepoName:       repo,
+		Name:           opts.Name,
+		HeadSHA:        opts.HeadSHA,
+		PRNumber:       t.event.PRNumber,
+		JobKind:        t.event.Type.String(),
+		Attempt:        t.event.Attempt,
+		Status:         storage.CheckRunInProgress,
+	}
+	if err := t.store.InsertCheckRun(ctx, run); err != nil {
+		t.logger.WarnContext(ctx, "failed to record check run", "check_run_id", run.ID, "repo", owner+"/"+repo, "error", err)
+	}
+	return checkRun, nil
+}
+
+// UpdateCheckRun updates the check run and marks it completed once it has
+// concluded.
+func (t *checkRunTracker) UpdateCheckRun(ctx context.Context, owner, repo string, checkRunID int64, opts gogithub.UpdateCheckRunOptions) (*gogithub.CheckRun, error) {
+	checkRun, err := t.Client.UpdateCheckRun(ctx, owner, repo, checkRunID, opts)
+	if err != nil || opts.GetStatus() != checkRunCompleted {
+		return checkRun, err
+	}
+
+	t.active.Delete(checkRunID)
+	if err := t.store.CompleteCheckRun(ctx, checkRunID, opts.GetConclusion()); err != nil {
+		t.logger.WarnContext(ctx, "failed to mark check run completed", "check_run_id", checkRunID, "error", err)
+	}
+	return checkRun, nil
+}
+
+// CheckRunReaper concludes check runs that were left in progress by a job
+// that never finished, so pull requests do not show a check pending forever.
+type CheckRunReaper struct {
+	cfg        *config.Config
+	store      storage.CheckRunStore
+	job        *ReviewJob
+	dispatcher core.JobDispatcher
+	logger     *slog.Logger
+	newClient  func(ctx context.Context, installationID int64) (github.Client, error)
+
+	stopCh   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewCheckRunReaper creates a new CheckRunReaper. Check runs of jobs that are
+// still running in job are never reaped. Reviews are queued again on
+// dispatcher, up to server.stale_review_retries times.
+func NewCheckRunReaper(cfg *config.Config, store storage.Store, job *ReviewJob, dispatcher core.JobDispatcher, logger *slog.Logger) *CheckRunReaper {
+	return &CheckRunReaper{
+		cfg:        cfg,
+		store:      store,
+		job:        job,
+		dispatcher: dispatcher,
+		logger:     logger,
+		newClient: func(ctx context.Context, installationID int64) (github.Client, error) {
+			client, _, err := github.CreateInstallationClient(ctx, cfg, installationID, logger)
+			return client, err
+		},
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start reaps the check runs left over from a previous process and then keeps
+// reaping runs older than server.stale_check_run_after in the background.
+// It does nothing when the reaper is disabled.
+func (r *CheckRunReaper) Start() {
+	staleAfter := r.cfg.Server.StaleCheckRunAfter
+	if staleAfter <= 0 {
+		close(r.done)
+		return
+	}
+	startedAt := time.Now()
+
+	go func() {
+		defer close(r.done)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-r.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		// Nothing started by an earlier process can still be running.
+		r.reap(ctx, startedAt)
+
+		ticker := time.NewTicker(r.cfg.Server.CheckRunReapInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.stopCh:
+				return
+			case <-ticker.C:
+				r.reap(ctx, time.Now().Add(-staleAfter))
+			}
+		}
+	}()
+}
+
+// Stop stops the background reaping and waits for a pass in progress to end.
+// It must only be called after Start.
+func (r *CheckRunReaper) Stop() {
+	r.stopOnce.Do(func() { close(r.stopCh) })
+	<-r.done
+}
+
+func (r *CheckRunReaper) reap(ctx context.Context, startedBefore time.Time) {
+	reaped, err := r.Reap(ctx, startedBefore)
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to reap stale check runs", "error", err)
+	}
+	if reaped > 0 {
+		r.logger.InfoContext(ctx, "concluded stale check runs", "count", reaped)
+	}
+}
+
+// Reap concludes the in-progress check runs started before the given time
+// that do not belong to a running job, with server.stale_check_run_conclusion,
+// and returns how many it concluded. A check run that fails to update is
+// retried on the next pass. Reviews whose pull request is still open at the
+// same commit are queued again while retries are left.
+func (r *CheckRunReaper) Reap(ctx context.Context, startedBefore time.Time) (int, error) {
+	runs, err := r.store.ListInProgressCheckRuns(ctx, startedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	clients := make(map[int64]github.Client)
+	reaped := 0
+	var errs []error
+	for _, run := range runs {
+		if ctx.Err() != nil {
+			return reaped, ctx.Err()
+		}
+		if r.job != nil && r.job.isCheckRunActive(run.ID) {
+			continue
+		}
+
+		client, ok := clients[run.InstallationID]
+		if !ok {
+			client, err = r.newClient(ctx, run.InstallationID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to create GitHub client for installation %d: %w", run.InstallationID, err))
+				continue
+			}
+			clients[run.InstallationID] = client
+		}
+
+		retry := r.retryEvent(ctx, client, run)
+		if err := r.conclude(ctx, client, run, retry != nil); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		reaped++
+		if retry != nil {
+			r.requeue(ctx, retry, run)
+		}
+	}
+	return reaped, errors.Join(errs...)
+}
+
+// conclude marks a stale check run as concluded on GitHub and completed in
+// the store. A check run that no longer exists on GitHub is only marked
+// completed. requeued picks the summary telling whether the review runs again.
+func (r *CheckRunReaper) conclude(ctx context.Context, client github.Client, run *storage.CheckRun, requeued bool) error {
+	conclusion := r.cfg.Server.StaleCheckRunConclusion
+	if conclusion == "" {
+		conclusion = "neutral"
+	}
+	summary := staleCheckRunSummary
+	if requeued {
+		summary = staleCheckRunRequeuedSummary
+	}
+	_, err := client.UpdateCheckRun(ctx, run.RepoOwner, run.RepoName, run.ID, gogithub.UpdateCheckRunOptions{
+		Status:      gogithub.Ptr(checkRunCompleted),
+		Conclusion:  gogithub.Ptr(conclusion),
+		CompletedAt: &gogithub.Timestamp{Time: time.Now()},
+		Output: &gogithub.CheckRunOutput{
+			Title:   gogithub.Ptr(staleCheckRunTitle),
+			Summary: gogithub.Ptr(summary),
+		},
+	})
+	if err != nil {
+		var ghErr *gogithub.ErrorResponse
+		if !errors.As(err, &ghErr) || ghErr.Response == nil || ghErr.Response.StatusCode != http.StatusNotFound {
+			return fmt.Errorf("failed to conclude check run %d in %s/%s: %w", run.ID, run.RepoOwner, run.RepoName, err)
+		}
+		r.logger.InfoContext(ctx, "stale check run no longer exists on GitHub", "check_run_id", run.ID, "repo", run.RepoOwner+"/"+run.RepoName)
+		return r.store.CompleteCheckRun(ctx, run.ID, "")
+	}
+
+	r.logger.InfoContext(ctx, "concluded stale check run", "check_run_id", run.ID, "repo", run.RepoOwner+"/"+run.RepoName,
+		"name", run.Name, "started_at", run.StartedAt)
+	return r.store.CompleteCheckRun(ctx, run.ID, conclusion)
+}
+
+// retryEvent returns the event that queues the job of a stale check run
+// again, or nil if it is not to be queued: retries are disabled or used up,
+// the job is no review, or its pull request was closed or has moved on to
+// another commit, which a newer review covers.
+func (r *CheckRunReaper) retryEvent(ctx context.Context, client github.Client, run *storage.CheckRun) *core.GitHubEvent {
+	kind, ok := requeueKinds[run.JobKind]
+	if !ok || r.dispatcher == nil || run.PRNumber <= 0 || run.Attempt >= r.cfg.Server.StaleReviewRetries {
+		return nil
+	}
+	pr, err := client.GetPullRequest(ctx, run.RepoOwner, run.RepoName, run.PRNumber)
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to get pull request of stale check run, not queueing it again",
+			"check_run_id", run.ID, "repo", run.RepoOwner+"/"+run.RepoName, "pr", run.PRNumber, "error", err)
+		return nil
+	}
+	if pr.GetState() != "open" || pr.GetHead().GetSHA() != run.HeadSHA {
+		return nil
+	}
+
+	repo := pr.GetBase().GetRepo()
+	return &core.GitHubEvent{
+		Type:           kind,
+		RepoOwner:      run.RepoOwner,
+		RepoName:       run.RepoName,
+		RepoFullName:   run.RepoOwner + "/" + run.RepoName,
+		RepoCloneURL:   repo.GetCloneURL(),
+		Language:       repo.GetLanguage(),
+		PRNumber:       run.PRNumber,
+		PRTitle:        pr.GetTitle(),
+		PRBody:         pr.GetBody(),
+		HeadSHA:        run.HeadSHA,
+		InstallationID: run.InstallationID,
+		Attempt:        run.Attempt + 1,
+	}
+}
+
+// requeue queues the retry of a stale check run's review. A full queue is
+// logged; the concluded check run already tells to run /review again.
+func (r *CheckRunReaper) requeue(ctx context.Context, event *core.GitHubEvent, run *storage.CheckRun) {
+	if err := r.dispatcher.Dispatch(ctx, event); err != nil {
+		r.logger.WarnContext(ctx, "failed to queue review of stale check run again",
+			"check_run_id", run.ID, "repo", event.RepoFullName, "pr", event.PRNumber, "error", err)
+		return
+	}
+	r.logger.InfoContext(ctx, "queued review of stale check run again",
+		"check_run_id", run.ID, "repo", event.RepoFullName, "pr", event.PRNumber, "kind", run.JobKind, "attempt", event.Attempt)
+}
@@ -0,0 +1,170 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	gogithub "github.com/google/go-github/v73/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+func TestCheckRunTracker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStore(ctrl)
+	ghClient := mocks.NewMockClient(ctrl)
+	job := &ReviewJob{store: store, logger: slog.New(slog.NewTextHandler(os.Stdout, nil))}
+	client := job.trackCheckRuns(ghClient, &core.GitHubEvent{InstallationID: 7, PRNumber: 12, Type: core.SecurityReview, Attempt: 1})
+	ctx := context.Background()
+
+	ghClient.EXPECT().CreateCheckRun(ctx, "owner", "repo", gomock.Any()).
+		Return(&gogithub.CheckRun{ID: gogithub.Ptr(int64(42))}, nil)
+	store.EXPECT().InsertCheckRun(ctx, &storage.CheckRun{
+		ID: 42, InstallationID: 7, RepoOwner: "owner", RepoName: "repo",
+		Name: "Code-Warden Review", HeadSHA: "abc", PRNumber: 12, JobKind: "security", Attempt: 1,
+		Status: storage.CheckRunInProgress,
+	}).Return(nil)
+	_, err := client.CreateCheckRun(ctx, "owner", "repo", gogithub.CreateCheckRunOptions{
+		Name: "Code-Warden Review", HeadSHA: "abc", Status: gogithub.Ptr("in_progress"),
+	})
+	require.NoError(t, err)
+	assert.True(t, job.isCheckRunActive(42))
+
+	// In-progress updates leave the check run active.
+	ghClient.EXPECT().UpdateCheckRun(ctx, "owner", "repo", int64(42), gomock.Any()).Return(&gogithub.CheckRun{}, nil)
+	_, err = client.UpdateCheckRun(ctx, "owner", "repo", 42, gogithub.UpdateCheckRunOptions{Status: gogithub.Ptr("in_progress")})
+	require.NoError(t, err)
+	assert.True(t, job.isCheckRunActive(42))
+
+	ghClient.EXPECT().UpdateCheckRun(ctx, "owner", "repo", int64(42), gomock.Any()).Return(&gogithub.CheckRun{}, nil)
+	store.EXPECT().CompleteCheckRun(ctx, int64(42), "success").Return(nil)
+	_, err = client.UpdateCheckRun(ctx, "owner", "repo", 42, gogithub.UpdateCheckRunOptions{
+		Status: gogithub.Ptr("completed"), Conclusion: gogithub.Ptr("success"),
+	})
+	require.NoError(t, err)
+	assert.False(t, job.isCheckRunActive(42))
+}
+
+func TestCheckRunReaper_Reap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStore(ctrl)
+	ghClient := mocks.NewMockClient(ctrl)
+	ctx := context.Background()
+	before := time.Now()
+
+	job := &ReviewJob{}
+	job.activeCheckRuns.Store(int64(3), struct{}{})
+
+	reaper := NewCheckRunReaper(&config.Config{}, store, job, nil, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	clientsCreated := 0
+	reaper.newClient = func(_ context.Context, installationID int64) (github.Client, error) {
+		clientsCreated++
+		if installationID == 9 {
+			return nil, errors.New("installation removed")
+		}
+		return ghClient, nil
+	}
+
+	store.EXPECT().ListInProgressCheckRuns(ctx, before).Return([]*storage.CheckRun{
+		{ID: 1, InstallationID: 7, RepoOwner: "owner", RepoName: "repo"},
+		{ID: 2, InstallationID: 7, RepoOwner: "owner", RepoName: "gone"},
+		{ID: 3, InstallationID: 7, RepoOwner: "owner", RepoName: "repo"},
+		{ID: 4, InstallationID: 9, RepoOwner: "other", RepoName: "repo"},
+		{ID: 5, InstallationID: 7, RepoOwner: "owner", RepoName: "flaky"},
+	}, nil)
+
+	ghClient.EXPECT().UpdateCheckRun(ctx, "owner", "repo", int64(1), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, _ int64, opts gogithub.UpdateCheckRunOptions) (*gogithub.CheckRun, error) {
+			assert.Equal(t, "completed", opts.GetStatus())
+			assert.Equal(t, "neutral", opts.GetConclusion())
+			assert.Contains(t, opts.Output.GetSummary(), "/review")
+			return &gogithub.CheckRun{}, nil
+		})
+	store.EXPECT().CompleteCheckRun(ctx, int64(1), "neutral").Return(nil)
+
+	notFound := &gogithub.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+	ghClient.EXPECT().UpdateCheckRun(ctx, "owner", "gone", int64(2), gomock.Any()).Return(nil, notFound)
+	store.EXPECT().CompleteCheckRun(ctx, int64(2), "").Return(nil)
+
+	ghClient.EXPECT().UpdateCheckRun(ctx, "owner", "flaky", int64(5), gomock.Any()).Return(nil, errors.New("timeout"))
+
+	reaped, err := reaper.Reap(ctx, before)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "installation 9")
+	assert.Contains(t, err.Error(), "check run 5")
+	assert.Equal(t, 2, reaped)
+	assert.Equal(t, 2, clientsCreated)
+}
+
+func TestCheckRunReaper_Requeue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStore(ctrl)
+	ghClient := mocks.NewMockClient(ctrl)
+	ctx := context.Background()
+	before := time.Now()
+
+	cfg := &config.Config{Server: config.ServerConfig{StaleCheckRunConclusion: "failure", StaleReviewRetries: 1}}
+	dispatcher := &recordingDispatcher{}
+	reaper := NewCheckRunReaper(cfg, store, &ReviewJob{}, dispatcher, slog.New(slog.DiscardHandler))
+	reaper.newClient = func(context.Context, int64) (github.Client, error) { return ghClient, nil }
+
+	store.EXPECT().ListInProgressCheckRuns(ctx, before).Return([]*storage.CheckRun{
+		{ID: 1, InstallationID: 7, RepoOwner: "owner", RepoName: "repo", HeadSHA: "abc", PRNumber: 10, JobKind: "review"},
+		{ID: 2, InstallationID: 7, RepoOwner: "owner", RepoName: "repo", HeadSHA: "abc", PRNumber: 11, JobKind: "review", Attempt: 1},
+		{ID: 3, InstallationID: 7, RepoOwner: "owner", RepoName: "repo", HeadSHA: "old", PRNumber: 12, JobKind: "rereview"},
+		{ID: 4, InstallationID: 7, RepoOwner: "owner", RepoName: "repo", HeadSHA: "abc", PRNumber: 13, JobKind: "explain"},
+	}, nil)
+
+	openPR := func(sha string) *gogithub.PullRequest {
+		return &gogithub.PullRequest{
+			State: gogithub.Ptr("open"),
+			Title: gogithub.Ptr("Fix prices"),
+			Head:  &gogithub.PullRequestBranch{SHA: gogithub.Ptr(sha)},
+			Base:  &gogithub.PullRequestBranch{Repo: &gogithub.Repository{CloneURL: gogithub.Ptr("https://github.com/owner/repo.git")}},
+		}
+	}
+	ghClient.EXPECT().GetPullRequest(ctx, "owner", "repo", 10).Return(openPR("abc"), nil)
+	ghClient.EXPECT().GetPullRequest(ctx, "owner", "repo", 12).Return(openPR("new"), nil)
+
+	summaries := map[int64]string{}
+	ghClient.EXPECT().UpdateCheckRun(ctx, "owner", "repo", gomock.Any(), gomock.Any()).Times(4).
+		DoAndReturn(func(_ context.Context, _, _ string, id int64, opts gogithub.UpdateCheckRunOptions) (*gogithub.CheckRun, error) {
+			assert.Equal(t, "failure", opts.GetConclusion())
+			summaries[id] = opts.Output.GetSummary()
+			return &gogithub.CheckRun{}, nil
+		})
+	store.EXPECT().CompleteCheckRun(ctx, gomock.Any(), "failure").Times(4).Return(nil)
+
+	reaped, err := reaper.Reap(ctx, before)
+	require.NoError(t, err)
+	assert.Equal(t, 4, reaped)
+
+	events := dispatcher.dispatched()
+	require.Len(t, events, 1, "only the review with retries left whose PR is still at the same commit")
+	assert.Equal(t, core.FullReview, events[0].Type)
+	assert.Equal(t, 10, events[0].PRNumber)
+	assert.Equal(t, "owner/repo", events[0].RepoFullName)
+	assert.Equal(t, "https://github.com/owner/repo.git", events[0].RepoCloneURL)
+	assert.Equal(t, "Fix prices", events[0].PRTitle)
+	assert.Equal(t, 1, events[0].Attempt)
+	assert.Contains(t, summaries[1], "queued to run again")
+	assert.Contains(t, summaries[2], "/review")
+}
+
+func TestCheckRunReaper_Disabled(t *testing.T) {
+	reaper := NewCheckRunReaper(&config.Config{}, nil, nil, nil, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	reaper.Start()
+	reaper.Stop()
+}
@@ -0,0 +1,107 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// Debouncer holds auto-review events of a pull request until it has gone a
+// while without another one, and then dispatches the latest. Rapid pushes to
+// a pull request thus trigger one review, of the commit pushed last.
+type Debouncer struct {
+	dispatcher core.JobDispatcher
+	delay      time.Duration
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	pending map[string]*pendingReview
+	stopped bool
+}
+
+// pendingReview is the latest event of a pull request waiting for its timer.
+type pendingReview struct {
+	event *core.GitHubEvent
+	timer *time.Timer
+}
+
+// NewDebouncer creates a Debouncer that dispatches to dispatcher once a pull
+// request has had no event for delay. A zero delay dispatches every event
+// right away.
+func NewDebouncer(dispatcher core.JobDispatcher, delay time.Duration, logger *slog.Logger) *Debouncer {
+	return &Debouncer{
+		dispatcher: dispatcher,
+		delay:      delay,
+		logger:     logger,
+		pending:    make(map[string]*pendingReview),
+	}
+}
+
+// Schedule queues event for dispatch, replacing the event of the same pull
+// request still waiting and restarting its delay.
+func (d *Debouncer) Schedule(ctx context.Context, event *core.GitHubEvent) error {
+	if event.ReviewID == "" {
+		event.ReviewID = core.ReviewID(ctx)
+	}
+	if d.delay <= 0 {
+		return d.dispatcher.Dispatch(ctx, event)
+	}
+
+	key := fmt.Sprintf("%s#%d", event.RepoFullName, event.PRNumber)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.stopped {
+		return fmt.Errorf("auto-review is shutting down, dropping review of %s", key)
+	}
+	if p, ok := d.pending[key]; ok {
+		p.timer.Stop()
+		d.logger.DebugContext(ctx, "debouncing auto-review", "repo", event.RepoFullName, "pr", event.PRNumber,
+			"replaced_sha", p.event.HeadSHA, "sha", event.HeadSHA)
+	}
+	p := &pendingReview{event: event}
+	p.timer = time.AfterFunc(d.delay, func() { d.fire(key, p) })
+	d.pending[key] = p
+	return nil
+}
+
+// fire dispatches p unless a later event replaced it.
+func (d *Debouncer) fire(key string, p *pendingReview) {
+	d.mu.Lock()
+	if d.pending[key] != p || d.stopped {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.pending, key)
+	d.mu.Unlock()
+
+	// The webhook request that scheduled the event is long gone.
+	ctx := core.WithReviewID(context.Background(), p.event.ReviewID)
+	if err := d.dispatcher.Dispatch(ctx, p.event); err != nil {
+		d.logger.ErrorContext(ctx, "failed to dispatch auto-review", "repo", p.event.RepoFullName, "pr", p.event.PRNumber, "error", err)
+		return
+	}
+	d.logger.InfoContext(ctx, "auto-review dispatched", "repo", p.event.RepoFullName, "pr", p.event.PRNumber, "sha", p.event.HeadSHA)
+}
+
+// Pending returns how many pull requests wait for their delay to pass.
+func (d *Debouncer) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.pending)
+}
+
+// Stop drops the waiting events. The next push to their pull requests
+// schedules them again.
+func (d *Debouncer) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stopped = true
+	for key, p := range d.pending {
+		p.timer.Stop()
+		delete(d.pending, key)
+	}
+}
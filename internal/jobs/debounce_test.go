@@ -0,0 +1,75 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// recordingDispatcher records the events dispatched to it.
+type recordingDispatcher struct {
+	core.JobDispatcher
+	mu     sync.Mutex
+	events []*core.GitHubEvent
+}
+
+func (d *recordingDispatcher) Dispatch(_ context.Context, event *core.GitHubEvent) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events = append(d.events, event)
+	return nil
+}
+
+func (d *recordingDispatcher) dispatched() []*core.GitHubEvent {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]*core.GitHubEvent(nil), d.events...)
+}
+
+func TestDebouncer_DispatchesLatestEvent(t *testing.T) {
+	rec := &recordingDispatcher{}
+	d := NewDebouncer(rec, 50*time.Millisecond, slog.New(slog.DiscardHandler))
+	defer d.Stop()
+	ctx := context.Background()
+
+	for _, sha := range []string{"a1", "b2", "c3"} {
+		require.NoError(t, d.Schedule(ctx, &core.GitHubEvent{RepoFullName: "owner/app", PRNumber: 1, HeadSHA: sha}))
+	}
+	require.NoError(t, d.Schedule(ctx, &core.GitHubEvent{RepoFullName: "owner/app", PRNumber: 2, HeadSHA: "d4"}))
+	assert.Equal(t, 2, d.Pending())
+
+	require.Eventually(t, func() bool { return len(rec.dispatched()) == 2 }, time.Second, 5*time.Millisecond)
+	shas := map[int]string{}
+	for _, event := range rec.dispatched() {
+		shas[event.PRNumber] = event.HeadSHA
+	}
+	assert.Equal(t, map[int]string{1: "c3", 2: "d4"}, shas, "only the last event of each pull request is reviewed")
+	assert.Zero(t, d.Pending())
+}
+
+func TestDebouncer_NoDelay(t *testing.T) {
+	rec := &recordingDispatcher{}
+	d := NewDebouncer(rec, 0, slog.New(slog.DiscardHandler))
+
+	require.NoError(t, d.Schedule(context.Background(), &core.GitHubEvent{RepoFullName: "owner/app", PRNumber: 1}))
+	assert.Len(t, rec.dispatched(), 1)
+}
+
+func TestDebouncer_StopDropsPending(t *testing.T) {
+	rec := &recordingDispatcher{}
+	d := NewDebouncer(rec, 20*time.Millisecond, slog.New(slog.DiscardHandler))
+	ctx := context.Background()
+
+	require.NoError(t, d.Schedule(ctx, &core.GitHubEvent{RepoFullName: "owner/app", PRNumber: 1}))
+	d.Stop()
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, rec.dispatched())
+	assert.Error(t, d.Schedule(ctx, &core.GitHubEvent{RepoFullName: "owner/app", PRNumber: 1}))
+}
@@ -2,43 +2,93 @@
 package jobs
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/health"
 )
 
 type jobPayload struct {
-	ctx   context.Context
-	event *core.GitHubEvent
+	id     int64
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	event  *core.GitHubEvent
+	// rank and queuedAt are set by jobQueue.push.
+	rank     int
+	queuedAt time.Time
+	// startedAt is set when a worker starts the job, guarded by jobsMu.
+	startedAt time.Time
+}
+
+// jobQueueCapacity is how many jobs may wait for a worker.
+const jobQueueCapacity = 100
+
+// admissionStatus reports whether jobs may run; *health.Monitor implements it.
+type admissionStatus interface {
+	Status() health.Status
 }
 
 // dispatcher implements core.JobDispatcher and manages a pool of worker goroutines
-// for processing GitHub events as code review jobs.
+// for processing GitHub events as code review jobs. Workers take jobs by
+// priority and in turns between repositories (see jobQueue).
 type dispatcher struct {
 	reviewJob  core.Job
-	jobQueue   chan *jobPayload
+	jobQueue   *jobQueue
 	maxWorkers int
 	wg         sync.WaitGroup
 	logger     *slog.Logger
 	mainCtx    context.Context
+
+	// admission holds jobs in the queue while it reports a pause.
+	admission      admissionStatus
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	stopCh         chan struct{}
+
+	// jobTimeout bounds how long a review job may run; zero means no limit.
+	jobTimeout time.Duration
+
+	// jobs holds the queued and running jobs of each repository and byID
+	// the same jobs by ID, so that CancelRepo and CancelJob can cancel them.
+	jobsMu sync.Mutex
+	jobs   map[string]map[*jobPayload]struct{}
+	byID   map[int64]*jobPayload
+	nextID atomic.Int64
+
+	// running counts the jobs workers are processing.
+	running atomic.Int32
 }
 
-// NewDispatcher initializes a dispatcher with a worker pool.
-func NewDispatcher(ctx context.Context, reviewJob core.Job, cfg *config.Config, logger *slog.Logger) core.JobDispatcher {
+// NewDispatcher initializes a dispatcher with a worker pool. Workers hold
+// their next job while monitor reports unhealthy dependencies.
+func NewDispatcher(ctx context.Context, reviewJob core.Job, cfg *config.Config, monitor *health.Monitor, logger *slog.Logger) core.JobDispatcher {
 	maxWorkers := cfg.Server.MaxWorkers
 	if maxWorkers <= 0 {
 		maxWorkers = 1
 	}
 	d := &dispatcher{
-		reviewJob:  reviewJob,
-		maxWorkers: maxWorkers,
-		jobQueue:   make(chan *jobPayload, 100),
-		logger:     logger,
-		mainCtx:    ctx,
+		reviewJob:      reviewJob,
+		maxWorkers:     maxWorkers,
+		jobQueue:       newJobQueue(jobQueueCapacity, newSchedulingPolicy(cfg.Server.Scheduling)),
+		logger:         logger,
+		mainCtx:        ctx,
+		initialBackoff: cfg.Server.Admission.InitialBackoff,
+		maxBackoff:     cfg.Server.Admission.MaxBackoff,
+		stopCh:         make(chan struct{}),
+		jobTimeout:     cfg.Server.JobTimeout,
+		jobs:           make(map[string]map[*jobPayload]struct{}),
+		byID:           make(map[int64]*jobPayload),
+	}
+	if monitor != nil && cfg.Server.Admission.Enabled {
+		d.admission = monitor
 	}
 	d.startWorkers()
 	return d
@@ -57,32 +107,115 @@ func (d *dispatcher) startWorker(workerID int) {
 	defer d.wg.Done()
 	d.logger.Info("starting review worker", "id", workerID)
 
-	for payload := range d.jobQueue {
-		d.processEvent(payload.ctx, workerID, payload.event)
+	for {
+		payload, ok := d.jobQueue.pop()
+		if !ok {
+			break
+		}
+		d.runPayload(workerID, payload)
+		d.jobQueue.done(payload)
 	}
 
 	d.logger.Info("shutting down review worker", "id", workerID)
 }
 
+func (d *dispatcher) runPayload(workerID int, payload *jobPayload) {
+	defer d.untrack(payload)
+	if !d.admit(workerID, payload.event) {
+		d.logger.WarnContext(payload.ctx, "dropping held review job on shutdown",
+			"repo", payload.event.RepoFullName,
+			"pr", payload.event.PRNumber,
+		)
+		return
+	}
+	if payload.ctx.Err() != nil {
+		d.logger.InfoContext(payload.ctx, "skipping cancelled review job",
+			"repo", payload.event.RepoFullName,
+			"pr", payload.event.PRNumber,
+		)
+		return
+	}
+	d.running.Add(1)
+	defer d.running.Add(-1)
+	d.jobsMu.Lock()
+	payload.startedAt = time.Now()
+	d.jobsMu.Unlock()
+
+	ctx := payload.ctx
+	if d.jobTimeout > 0 && isReviewKind(payload.event.Type) {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, d.jobTimeout, core.ErrJobTimedOut)
+		defer cancel()
+	}
+	// LLM calls are accounted to the repository and, unless a step of the
+	// job says otherwise, to the job kind.
+	ctx = core.WithUsageStep(core.WithUsageRepo(ctx, payload.event.RepoFullName), payload.event.Type.String())
+	d.processEvent(ctx, workerID, payload.event)
+}
+
+// isReviewKind reports whether server.job_timeout applies to a job kind.
+// Agent sessions and repository scans have their own limits.
+func isReviewKind(kind core.ReviewType) bool {
+	switch kind {
+	case core.FullReview, core.ReReview, core.SecurityReview, core.ExplainPR:
+		return true
+	default:
+		return false
+	}
+}
+
+// admit waits until the dependencies of review jobs are healthy, re-checking
+// with exponential backoff, so that a job is held in the queue rather than run
+// and failed. It returns false when the dispatcher stops while waiting.
+func (d *dispatcher) admit(workerID int, event *core.GitHubEvent) bool {
+	if d.admission == nil {
+		return true
+	}
+	backoff := d.initialBackoff
+	for {
+		status := d.admission.Status()
+		if !status.Paused {
+			return true
+		}
+		d.logger.Info("holding review job, dependencies are unhealthy",
+			"worker_id", workerID,
+			"repo", event.RepoFullName,
+			"pr", event.PRNumber,
+			"reasons", status.Reasons,
+			"retry_in", backoff,
+		)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-d.stopCh:
+			timer.Stop()
+			return false
+		case <-d.mainCtx.Done():
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, d.maxBackoff)
+	}
+}
+
 // processEvent logs and runs a review job for a GitHub event.
-// Uses the main context (not the HTTP request context) to avoid cancellation
-// when the HTTP request completes.
-func (d *dispatcher) processEvent(_ context.Context, workerID int, event *core.GitHubEvent) {
-	d.logger.Info("worker processing job",
+// ctx is the job's own context, derived from the main context (not the HTTP
+// request context) to avoid cancellation when the HTTP request completes.
+func (d *dispatcher) processEvent(ctx context.Context, workerID int, event *core.GitHubEvent) {
+	d.logger.InfoContext(ctx, "worker processing job",
 		"worker_id", workerID,
 		"repo", event.RepoFullName,
+		"kind", event.Type.String(),
 	)
 
 	defer func() {
 		if r := recover(); r != nil {
-			d.logger.Error("panic recovered in review job", "panic", r, "repo", event.RepoFullName)
+			d.logger.ErrorContext(ctx, "panic recovered in review job", "panic", r, "repo", event.RepoFullName)
 		}
 	}()
 
-	// Use main context (server lifecycle), not the HTTP request context
-	// which gets canceled when the webhook response is sent.
-	if err := d.reviewJob.Run(d.mainCtx, event); err != nil {
-		d.logger.Error("code review job failed",
+	if err := d.reviewJob.Run(ctx, event); err != nil {
+		d.logger.ErrorContext(ctx, "code review job failed",
 			"repo", event.RepoFullName,
 			"pr", event.PRNumber,
 			"error", err,
@@ -94,27 +227,139 @@ func (d *dispatcher) processEvent(_ context.Context, workerID int, event *core.G
 // The HTTP request context is not used for the actual job execution -
 // instead the server's main context is used to avoid cancellation
 // after the webhook response is sent.
-func (d *dispatcher) Dispatch(_ context.Context, event *core.GitHubEvent) error {
-	d.logger.Info("queuing code review job", "repo", event.RepoFullName, "pr", event.PRNumber)
+// The job logs under the review ID of the event, taken from ctx or made up
+// when the event has none.
+func (d *dispatcher) Dispatch(ctx context.Context, event *core.GitHubEvent) error {
+	if event.ReviewID == "" {
+		event.ReviewID = cmp.Or(core.ReviewID(ctx), core.NewReviewID())
+	}
+	// Each job runs under its own context derived from the server lifecycle,
+	// not the HTTP request context which gets canceled when the webhook
+	// response is sent.
+	ctx, cancel := context.WithCancelCause(core.WithReviewID(d.mainCtx, event.ReviewID))
+	d.logger.InfoContext(ctx, "queuing code review job", "repo", event.RepoFullName, "pr", event.PRNumber)
 
-	select {
-	case d.jobQueue <- &jobPayload{ctx: d.mainCtx, event: event}:
+	payload := &jobPayload{id: d.nextID.Add(1), ctx: ctx, cancel: cancel, event: event}
+	if d.track(payload) {
 		return nil
-	default:
-		d.logger.Warn("ALERT: Job queue is full, dropping review job",
-			slog.String("repo", event.RepoFullName),
-			slog.Int("pr", event.PRNumber),
-			slog.Int("queue_capacity", cap(d.jobQueue)),
-		)
-		return fmt.Errorf("job queue is full, cannot accept new review job (repo: %s, pr: %d, capacity: %d)",
-			event.RepoFullName, event.PRNumber, cap(d.jobQueue))
+	}
+	cancel(nil)
+	d.logger.WarnContext(ctx, "ALERT: Job queue is full, dropping review job",
+		slog.String("repo", event.RepoFullName),
+		slog.Int("pr", event.PRNumber),
+		slog.Int("queue_capacity", d.jobQueue.capacity),
+	)
+	return fmt.Errorf("job queue is full, cannot accept new review job (repo: %s, pr: %d, capacity: %d)",
+		event.RepoFullName, event.PRNumber, d.jobQueue.capacity)
+}
+
+// CancelRepo cancels the queued and running jobs of a repository. Running
+// jobs stop at their next context check; queued ones are skipped.
+func (d *dispatcher) CancelRepo(repoFullName string) int {
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
+	n := 0
+	for payload := range d.jobs[repoFullName] {
+		payload.cancel(core.ErrJobCancelled)
+		n++
+	}
+	if n > 0 {
+		d.logger.Info("cancelled review jobs", "repo", repoFullName, "count", n)
+	}
+	return n
+}
+
+// CancelJob cancels a queued or running job by its ID.
+func (d *dispatcher) CancelJob(id int64) bool {
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
+	payload, ok := d.byID[id]
+	if !ok {
+		return false
+	}
+	payload.cancel(core.ErrJobCancelled)
+	d.logger.Info("cancelled job", "id", id, "repo", payload.event.RepoFullName, "pr", payload.event.PRNumber)
+	return true
+}
+
+// ActiveJobs lists the queued and running jobs, oldest first.
+func (d *dispatcher) ActiveJobs() []core.ActiveJob {
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
+	jobs := make([]core.ActiveJob, 0, len(d.byID))
+	for _, payload := range d.byID {
+		job := core.ActiveJob{
+			ID:       payload.id,
+			Kind:     payload.event.Type.String(),
+			Repo:     payload.event.RepoFullName,
+			PRNumber: payload.event.PRNumber,
+			State:    core.JobQueued,
+			QueuedAt: payload.queuedAt,
+			ReviewID: payload.event.ReviewID,
+		}
+		if !payload.startedAt.IsZero() {
+			started := payload.startedAt
+			job.State = core.JobRunning
+			job.StartedAt = &started
+		}
+		jobs = append(jobs, job)
+	}
+	slices.SortFunc(jobs, func(a, b core.ActiveJob) int {
+		if c := a.QueuedAt.Compare(b.QueuedAt); c != 0 {
+			return c
+		}
+		return int(a.ID - b.ID)
+	})
+	return jobs
+}
+
+// QueueStats reports the jobs waiting in the queue and those being run.
+func (d *dispatcher) QueueStats() core.QueueStats {
+	return core.QueueStats{
+		Queued:   d.jobQueue.len(),
+		Running:  int(d.running.Load()),
+		Capacity: d.jobQueue.capacity,
+		Workers:  d.maxWorkers,
+	}
+}
+
+// track queues a job and records it as active. It reports false if the
+// queue is full. The job is pushed under jobsMu because push sets queuedAt,
+// which ActiveJobs reads.
+func (d *dispatcher) track(payload *jobPayload) bool {
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
+	if !d.jobQueue.push(payload) {
+		return false
+	}
+	repo := payload.event.RepoFullName
+	if d.jobs[repo] == nil {
+		d.jobs[repo] = make(map[*jobPayload]struct{})
+	}
+	d.jobs[repo][payload] = struct{}{}
+	d.byID[payload.id] = payload
+	return true
+}
+
+func (d *dispatcher) untrack(payload *jobPayload) {
+	payload.cancel(nil)
+	d.jobsMu.Lock()
+	defer d.jobsMu.Unlock()
+	repo := payload.event.RepoFullName
+	delete(d.jobs[repo], payload)
+	delete(d.byID, payload.id)
+	if len(d.jobs[repo]) == 0 {
+		delete(d.jobs, repo)
 	}
 }
 
 // Stop gracefully shuts down the dispatcher, waiting for all workers to finish.
+// Queued jobs still run unless admission is paused, in which case they are
+// dropped.
 func (d *dispatcher) Stop() {
 	d.logger.Info("stopping dispatcher and waiting for jobs to finish")
-	close(d.jobQueue)
+	close(d.stopCh)
+	d.jobQueue.close()
 	d.wg.Wait()
 	d.logger.Info("all review jobs have finished")
 }
@@ -0,0 +1,167 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/health"
+)
+
+// pausedFor reports a pause for its first n status checks.
+type pausedFor struct{ n atomic.Int32 }
+
+func (p *pausedFor) Status() health.Status {
+	if p.n.Add(-1) >= 0 {
+		return health.Status{Paused: true, Reasons: []string{"LLM down"}}
+	}
+	return health.Status{}
+}
+
+func newTestDispatcher(admission admissionStatus) *dispatcher {
+	return &dispatcher{
+		logger:         slog.New(slog.DiscardHandler),
+		mainCtx:        context.Background(),
+		admission:      admission,
+		initialBackoff: time.Millisecond,
+		maxBackoff:     2 * time.Millisecond,
+		stopCh:         make(chan struct{}),
+		jobs:           make(map[string]map[*jobPayload]struct{}),
+		byID:           make(map[int64]*jobPayload),
+	}
+}
+
+func TestDispatcherAdmit(t *testing.T) {
+	event := &core.GitHubEvent{RepoFullName: "owner/repo", PRNumber: 1}
+
+	// A held job is admitted once the dependencies recover.
+	p := &pausedFor{}
+	p.n.Store(3)
+	assert.True(t, newTestDispatcher(p).admit(0, event))
+	assert.Negative(t, p.n.Load(), "admission should be re-checked until healthy")
+
+	// Without admission control jobs always run.
+	assert.True(t, newTestDispatcher(nil).admit(0, event))
+
+	// A job still held when the dispatcher stops is not run.
+	p.n.Store(1 << 20)
+	d := newTestDispatcher(p)
+	close(d.stopCh)
+	assert.False(t, d.admit(0, event))
+}
+
+// blockingJob runs until its context is cancelled.
+type blockingJob struct{ started chan string }
+
+func (j *blockingJob) Run(ctx context.Context, event *core.GitHubEvent) error {
+	j.started <- event.RepoFullName
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestDispatcherCancelRepo(t *testing.T) {
+	job := &blockingJob{started: make(chan string, 3)}
+	d := newTestDispatcher(nil)
+	d.reviewJob = job
+	d.maxWorkers = 1
+	d.jobQueue = newJobQueue(10, newSchedulingPolicy(config.SchedulingConfig{}))
+	d.startWorkers()
+
+	ctx := context.Background()
+	require.NoError(t, d.Dispatch(ctx, &core.GitHubEvent{RepoFullName: "owner/app", PRNumber: 1}))
+	assert.Equal(t, "owner/app", <-job.started)
+	require.NoError(t, d.Dispatch(ctx, &core.GitHubEvent{RepoFullName: "owner/app", PRNumber: 2}))
+	require.NoError(t, d.Dispatch(ctx, &core.GitHubEvent{RepoFullName: "owner/other", PRNumber: 3}))
+	assert.Equal(t, core.QueueStats{Queued: 2, Running: 1, Capacity: 10, Workers: 1}, d.QueueStats())
+
+	// Both jobs of owner/app are cancelled, so the worker moves on to the
+	// job of owner/other without running the queued one.
+	assert.Equal(t, 2, d.CancelRepo("owner/app"))
+	assert.Equal(t, "owner/other", <-job.started)
+	assert.Equal(t, 1, d.CancelRepo("owner/other"))
+	d.Stop()
+	assert.Zero(t, d.CancelRepo("owner/app"))
+	assert.Empty(t, job.started)
+}
+
+// causeJob runs until its context is done and reports the cause.
+type causeJob struct {
+	started chan int
+	causes  chan error
+}
+
+func (j *causeJob) Run(ctx context.Context, event *core.GitHubEvent) error {
+	j.started <- event.PRNumber
+	<-ctx.Done()
+	j.causes <- context.Cause(ctx)
+	return ctx.Err()
+}
+
+func newCauseDispatcher(t *testing.T, timeout time.Duration) (*dispatcher, *causeJob) {
+	t.Helper()
+	job := &causeJob{started: make(chan int, 3), causes: make(chan error, 3)}
+	d := newTestDispatcher(nil)
+	d.reviewJob = job
+	d.maxWorkers = 1
+	d.jobTimeout = timeout
+	d.jobQueue = newJobQueue(10, newSchedulingPolicy(config.SchedulingConfig{}))
+	d.startWorkers()
+	t.Cleanup(d.Stop)
+	return d, job
+}
+
+func TestDispatcherCancelJob(t *testing.T) {
+	d, job := newCauseDispatcher(t, 0)
+	ctx := context.Background()
+	require.NoError(t, d.Dispatch(ctx, &core.GitHubEvent{RepoFullName: "owner/app", Type: core.FullReview, PRNumber: 1}))
+	assert.Equal(t, 1, <-job.started)
+	require.NoError(t, d.Dispatch(ctx, &core.GitHubEvent{RepoFullName: "owner/app", Type: core.FullReview, PRNumber: 2}))
+	require.NoError(t, d.Dispatch(ctx, &core.GitHubEvent{RepoFullName: "owner/app", Type: core.FullReview, PRNumber: 3}))
+
+	active := d.ActiveJobs()
+	require.Len(t, active, 3)
+	assert.Equal(t, core.JobRunning, active[0].State)
+	assert.NotNil(t, active[0].StartedAt)
+	assert.Equal(t, "review", active[0].Kind)
+	assert.Equal(t, core.JobQueued, active[1].State)
+	assert.Nil(t, active[1].StartedAt)
+
+	// The queued job is dropped without running.
+	assert.True(t, d.CancelJob(active[1].ID))
+	assert.True(t, d.CancelJob(active[0].ID))
+	assert.ErrorIs(t, <-job.causes, core.ErrJobCancelled)
+	assert.Equal(t, 3, <-job.started)
+	assert.False(t, d.CancelJob(active[0].ID), "finished jobs cannot be cancelled")
+	assert.False(t, d.CancelJob(9999))
+
+	require.True(t, d.CancelJob(active[2].ID))
+	assert.ErrorIs(t, <-job.causes, core.ErrJobCancelled)
+}
+
+func TestDispatcherJobTimeout(t *testing.T) {
+	d, job := newCauseDispatcher(t, 10*time.Millisecond)
+	ctx := context.Background()
+
+	require.NoError(t, d.Dispatch(ctx, &core.GitHubEvent{RepoFullName: "owner/app", Type: core.FullReview, PRNumber: 1}))
+	assert.Equal(t, 1, <-job.started)
+	assert.ErrorIs(t, <-job.causes, core.ErrJobTimedOut)
+
+	// The timeout applies to reviews only.
+	require.NoError(t, d.Dispatch(ctx, &core.GitHubEvent{RepoFullName: "owner/app", Type: core.ImplementIssue, PRNumber: 2}))
+	assert.Equal(t, 2, <-job.started)
+	select {
+	case cause := <-job.causes:
+		t.Fatalf("agent session stopped with %v", cause)
+	case <-time.After(50 * time.Millisecond):
+	}
+	require.Len(t, d.ActiveJobs(), 1)
+	require.True(t, d.CancelJob(d.ActiveJobs()[0].ID))
+	assert.ErrorIs(t, <-job.causes, core.ErrJobCancelled)
+}
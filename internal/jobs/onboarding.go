@@ -0,0 +1,144 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/repomanager"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+const welcomeIssueTitle = "Code-Warden is set up for this repository"
+
+// runOnboarding registers a repository the GitHub App was just installed on.
+// A repository that is registered for the first time also gets a welcome
+// issue and is indexed, as configured in github.onboarding. Repositories
+// that are already registered, e.g. after the app was reinstalled, are left
+// alone.
+func (j *ReviewJob) runOnboarding(ctx context.Context, event *core.GitHubEvent) error {
+	j.logger.InfoContext(ctx, "👋 Onboarding repository", "repo", event.RepoFullName, "installation_id", event.InstallationID)
+	created, err := j.registerRepository(ctx, event)
+	if err != nil || !created {
+		return err
+	}
+
+	onboarding := j.cfg.GitHub.Onboarding
+	if !onboarding.WelcomeIssue && !onboarding.Index {
+		return nil
+	}
+	ghClient, ghToken, err := github.CreateInstallationClient(ctx, j.cfg, event.InstallationID, j.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	if onboarding.WelcomeIssue {
+		j.postWelcomeIssue(ctx, ghClient, event)
+	}
+	if !onboarding.Index {
+		return nil
+	}
+
+	finish := j.startJobRun(ctx, "scan", event, "webhook:installation")
+	err = j.indexRepository(ctx, ghClient, ghToken, event)
+	finish(ctx, err)
+	return err
+}
+
+// registerRepository creates the record of a repository and reports whether
+// it did; it does not clone anything. The first review or the onboarding
+// index clones the repository into the recorded path.
+func (j *ReviewJob) registerRepository(ctx context.Context, event *core.GitHubEvent) (bool, error) {
+	_, err := j.store.GetRepositoryByFullName(ctx, event.RepoFullName)
+	if err == nil {
+		j.logger.InfoContext(ctx, "repository is already registered, skipping onboarding", "repo", event.RepoFullName)
+		return false, nil
+	}
+	if !errors.Is(err, storage.ErrNotFound) {
+		return false, fmt.Errorf("failed to look up repository %s: %w", event.RepoFullName, err)
+	}
+
+	repo := &storage.Repository{
+		FullName:             event.RepoFullName,
+		ClonePath:            filepath.Join(j.cfg.Storage.RepoPath, event.RepoFullName),
+		QdrantCollectionName: repomanager.GenerateCollectionName(event.RepoFullName),
+		InstallationID:       event.InstallationID,
+	}
+	if err := j.store.CreateRepository(ctx, repo); err != nil {
+		return false, fmt.Errorf("failed to register repository %s: %w", event.RepoFullName, err)
+	}
+	j.logger.InfoContext(ctx, "registered repository", "repo", event.RepoFullName)
+	return true, nil
+}
+
+// postWelcomeIssue opens an issue explaining how to use Code-Warden. Failures
+// are logged only, e.g. when the installation lacks the Issues permission;
+// the repository is registered either way.
+func (j *ReviewJob) postWelcomeIssue(ctx context.Context, ghClient github.Client, event *core.GitHubEvent) {
+	issue, err := ghClient.CreateIssue(ctx, event.RepoOwner, event.RepoName, welcomeIssueTitle, j.welcomeIssueBody())
+	if err != nil {
+		j.logger.WarnContext(ctx, "failed to open welcome issue", "repo", event.RepoFullName, "error", err)
+		return
+	}
+	j.logger.InfoContext(ctx, "opened welcome issue", "repo", event.RepoFullName, "issue", issue.Number)
+}
+
+func (j *ReviewJob) welcomeIssueBody() string {
+	var b strings.Builder
+	b.WriteString("👋 Code-Warden now has access to this repository.\n\n")
+	b.WriteString("### Reviewing pull requests\n\n")
+	b.WriteString("Comment on a pull request to start:\n\n")
+	b.WriteString("- `/review` reviews the pull request.\n")
+	b.WriteString("- `/rereview` reviews the changes since the last review.\n")
+	b.WriteString("- `/security` reviews the pull request for security vulnerabilities only.\n")
+	b.WriteString("- `/explain` posts a walkthrough of the pull request for newcomers.\n")
+	if j.cfg.Agent.Enabled {
+		b.WriteString("- `/implement` on an issue asks the agent to implement it and open a pull request.\n")
+	}
+	b.WriteString("\nReply `/warden helpful` or `/warden wrong` to a review suggestion, or react with 👍/👎, to rate it.\n")
+	b.WriteString("Reply `/warden explain` for a longer rationale or `/warden fix` for a patch.\n")
+	if j.cfg.GitHub.ApplyFix.Enabled {
+		b.WriteString("Collaborators can reply `/warden apply` to commit a suggestion.\n")
+	}
+	b.WriteString("\n")
+	b.WriteString("### Configuration\n\n")
+	b.WriteString("Add a `.code-warden.yml` to the root of the default branch to tune reviews for this repository, ")
+	b.WriteString("e.g. `custom_instructions` or `exclude_dirs`.\n\n")
+	if j.cfg.GitHub.Onboarding.Index {
+		b.WriteString("The repository is being indexed now, so the first review can start right away.\n\n")
+	} else {
+		b.WriteString("The repository is indexed on its first review, which therefore takes a little longer.\n\n")
+	}
+	b.WriteString("You can close this issue.")
+	return b.String()
+}
+
+// indexRepository clones the default branch of a newly registered repository
+// and indexes it, like the first review would.
+func (j *ReviewJob) indexRepository(ctx context.Context, ghClient github.Client, ghToken string, event *core.GitHubEvent) error {
+	mutex := j.getRepoMutex(event.RepoFullName)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	updateResult, err := j.repoMgr.SyncRepo(ctx, event, ghToken)
+	if err != nil {
+		return fmt.Errorf("failed to sync repository: %w", err)
+	}
+	repo, err := j.repoMgr.GetRepoRecord(ctx, event.RepoFullName)
+	if err != nil || repo == nil {
+		return fmt.Errorf("failed to retrieve repository record after sync for %s: %w", event.RepoFullName, err)
+	}
+
+	repoConfig := j.loadAndProcessRepoConfig(ctx, ghClient, event, updateResult.RepoPath)
+	if err := j.enforceLocalOnly(repoConfig); err != nil {
+		return err
+	}
+	if err := j.updateVectorStoreAndSHA(ctx, repoConfig, repo, updateResult); err != nil {
+		return err
+	}
+	j.logger.InfoContext(ctx, "indexed onboarded repository", "repo", event.RepoFullName, "sha", updateResult.DefaultBranchSHA)
+	return nil
+}
@@ -0,0 +1,78 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+func TestRegisterRepository(t *testing.T) {
+	ctx := context.Background()
+	event := &core.GitHubEvent{RepoOwner: "owner", RepoName: "repo", RepoFullName: "owner/repo", InstallationID: 7}
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStore(ctrl)
+	job := &ReviewJob{
+		cfg:    &config.Config{Storage: config.StorageConfig{RepoPath: "/data/repos"}},
+		store:  store,
+		logger: slog.New(slog.NewTextHandler(os.Stdout, nil)),
+	}
+
+	store.EXPECT().GetRepositoryByFullName(ctx, "owner/repo").Return(nil, storage.ErrNotFound)
+	store.EXPECT().CreateRepository(ctx, &storage.Repository{
+		FullName:             "owner/repo",
+		ClonePath:            filepath.Join("/data/repos", "owner/repo"),
+		QdrantCollectionName: "repo-owner-repo",
+		InstallationID:       7,
+	}).Return(nil)
+	created, err := job.registerRepository(ctx, event)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// A repository that is already registered is not onboarded again.
+	store.EXPECT().GetRepositoryByFullName(ctx, "owner/repo").Return(&storage.Repository{FullName: "owner/repo"}, nil)
+	created, err = job.registerRepository(ctx, event)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	store.EXPECT().GetRepositoryByFullName(ctx, "owner/repo").Return(nil, errors.New("connection refused"))
+	_, err = job.registerRepository(ctx, event)
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestPostWelcomeIssue(t *testing.T) {
+	ctx := context.Background()
+	event := &core.GitHubEvent{RepoOwner: "owner", RepoName: "repo", RepoFullName: "owner/repo", InstallationID: 7}
+	ctrl := gomock.NewController(t)
+	ghClient := mocks.NewMockClient(ctrl)
+	job := &ReviewJob{
+		cfg:    &config.Config{GitHub: config.GitHubConfig{Onboarding: config.OnboardingConfig{Index: true}}},
+		logger: slog.New(slog.NewTextHandler(os.Stdout, nil)),
+	}
+
+	ghClient.EXPECT().CreateIssue(ctx, "owner", "repo", welcomeIssueTitle, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _, _, body string) (*github.Issue, error) {
+			assert.Contains(t, body, "`/review`")
+			assert.Contains(t, body, "being indexed now")
+			assert.NotContains(t, body, "`/implement`", "the agent is disabled")
+			return &github.Issue{Number: 1}, nil
+		})
+	job.postWelcomeIssue(ctx, ghClient, event)
+
+	// A missing Issues permission does not fail onboarding.
+	ghClient.EXPECT().CreateIssue(ctx, "owner", "repo", welcomeIssueTitle, gomock.Any()).
+		Return(nil, errors.New("403 Resource not accessible by integration"))
+	job.postWelcomeIssue(ctx, ghClient, event)
+}
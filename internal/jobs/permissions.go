@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+)
+
+// checkPermissions verifies, on the first review of a repository, that its
+// installation has github.RequiredPermissions. When some are missing the job
+// fails with an error naming them, and the pull request gets a comment
+// explaining how to grant them if the installation can still comment, rather
+// than the review failing later with an opaque 403. Permissions that cannot
+// be fetched are logged and the review goes ahead.
+func (j *ReviewJob) checkPermissions(ctx context.Context, ghClient github.Client, event *core.GitHubEvent) error {
+	if _, ok := j.permittedRepos.Load(event.RepoFullName); ok || j.permissions == nil {
+		return nil
+	}
+
+	granted, err := j.permissions(ctx, event.InstallationID)
+	if err != nil {
+		j.logger.WarnContext(ctx, "failed to fetch installation permissions, continuing without the check",
+			"repo", event.RepoFullName, "installation_id", event.InstallationID, "error", err)
+		return nil
+	}
+
+	err = github.CheckPermissions(event.InstallationID, granted)
+	var permErr *github.PermissionError
+	if !errors.As(err, &permErr) {
+		j.permittedRepos.Store(event.RepoFullName, struct{}{})
+		return nil
+	}
+
+	j.logger.ErrorContext(ctx, "GitHub App installation is missing permissions", "repo", event.RepoFullName, "error", err)
+	if cErr := ghClient.CreateComment(ctx, event.RepoOwner, event.RepoName, event.PRNumber, permErr.Markdown()); cErr != nil {
+		j.logger.WarnContext(ctx, "failed to comment on missing permissions", "repo", event.RepoFullName, "error", cErr)
+	}
+	return err
+}
@@ -0,0 +1,61 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	gogithub "github.com/google/go-github/v73/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	ctx := context.Background()
+	event := &core.GitHubEvent{RepoOwner: "owner", RepoName: "repo", RepoFullName: "owner/repo", PRNumber: 3, InstallationID: 7}
+	granted := &gogithub.InstallationPermissions{
+		Checks: gogithub.Ptr("write"), PullRequests: gogithub.Ptr("read"), Contents: gogithub.Ptr("read"),
+	}
+	var fetches int
+	job := &ReviewJob{
+		logger: slog.New(slog.NewTextHandler(os.Stdout, nil)),
+		permissions: func(_ context.Context, installationID int64) (*gogithub.InstallationPermissions, error) {
+			fetches++
+			assert.Equal(t, int64(7), installationID)
+			return granted, nil
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	ghClient := mocks.NewMockClient(ctrl)
+	ghClient.EXPECT().CreateComment(ctx, "owner", "repo", 3, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, _ string, _ int, body string) error {
+			assert.Contains(t, body, "**Pull requests**: needs `write`, granted `read`")
+			return errors.New("403 Resource not accessible by integration")
+		})
+
+	err := job.checkPermissions(ctx, ghClient, event)
+	var permErr *github.PermissionError
+	require.ErrorAs(t, err, &permErr)
+	assert.True(t, strings.Contains(err.Error(), "pull_requests:write"))
+
+	// Once granted, the repository is checked no more.
+	granted.PullRequests = gogithub.Ptr("write")
+	require.NoError(t, job.checkPermissions(ctx, ghClient, event))
+	require.NoError(t, job.checkPermissions(ctx, ghClient, event))
+	assert.Equal(t, 2, fetches)
+
+	// Failing to fetch the permissions does not block reviews.
+	job.permissions = func(context.Context, int64) (*gogithub.InstallationPermissions, error) {
+		return nil, errors.New("network down")
+	}
+	require.NoError(t, job.checkPermissions(ctx, ghClient, &core.GitHubEvent{RepoFullName: "owner/other", InstallationID: 7}))
+}
@@ -0,0 +1,92 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+)
+
+// progressUpdateInterval is the least time between two check run updates
+// within a stage, so that indexing a large change does not use up the
+// installation's API rate limit.
+const progressUpdateInterval = 10 * time.Second
+
+// checkRunProgress shows the stage of a running review on its check run.
+// A new stage is shown at once; progress within a stage is throttled.
+type checkRunProgress struct {
+	ctx           context.Context
+	statusUpdater github.StatusUpdater
+	event         *core.GitHubEvent
+	checkRunID    int64
+	logger        *slog.Logger
+	now           func() time.Time
+
+	mu      sync.Mutex
+	stage   core.ReviewStage
+	updated time.Time
+}
+
+func newCheckRunProgress(ctx context.Context, statusUpdater github.StatusUpdater, event *core.GitHubEvent, checkRunID int64, logger *slog.Logger) *checkRunProgress {
+	return &checkRunProgress{
+		ctx:           ctx,
+		statusUpdater: statusUpdater,
+		event:         event,
+		checkRunID:    checkRunID,
+		logger:        logger,
+		now:           time.Now,
+	}
+}
+
+// report implements core.ReviewProgressFunc.
+func (p *checkRunProgress) report(stage core.ReviewStage, done, total int) {
+	if p.statusUpdater == nil || p.checkRunID <= 0 {
+		return
+	}
+	p.mu.Lock()
+	now := p.now()
+	finished := total > 0 && done >= total
+	if stage == p.stage && !finished && now.Sub(p.updated) < progressUpdateInterval {
+		p.mu.Unlock()
+		return
+	}
+	p.stage, p.updated = stage, now
+	p.mu.Unlock()
+
+	title, summary := stageMessage(stage, done, total)
+	if err := p.statusUpdater.Progress(p.ctx, p.event, p.checkRunID, title, summary); err != nil {
+		p.logger.Warn("failed to update check run progress", "error", err, "stage", stage,
+			"repo", p.event.RepoFullName, "pr", p.event.PRNumber)
+	}
+}
+
+// stageMessage returns the check run title and summary of a review stage.
+func stageMessage(stage core.ReviewStage, done, total int) (string, string) {
+	switch stage {
+	case core.StageSyncing:
+		return "Syncing repository", "Fetching the latest changes of the repository and the pull request."
+	case core.StageIndexing:
+		if total > 0 {
+			percent := min(done, total) * 100 / total
+			return fmt.Sprintf("Indexing files: %d%% (%d/%d)", percent, done, total),
+				fmt.Sprintf("Updating the code index with the default branch: %d of %d files embedded and stored (%d%%). "+
+					"The first index of a large repository takes several minutes.", done, total, percent)
+		}
+		return "Indexing files", "Updating the code index with the changes of the default branch."
+	case core.StageRetrieving:
+		return "Retrieving context", "Gathering the repository context of the changed files."
+	case core.StageGenerating:
+		if total > 0 {
+			return "Generating review", fmt.Sprintf("Large pull request: reviewing %d groups of related directories.", total)
+		}
+		return "Generating review", "Waiting for the model to write the review."
+	case core.StagePosting:
+		return "Posting review", "Posting the review comments on the pull request."
+	default:
+		return "Review in progress", string(stage)
+	}
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+)
+
+// progressRecorder records the titles of check run progress updates.
+type progressRecorder struct {
+	github.StatusUpdater
+	titles []string
+}
+
+func (r *progressRecorder) Progress(_ context.Context, _ *core.GitHubEvent, _ int64, title, _ string) error {
+	r.titles = append(r.titles, title)
+	return nil
+}
+
+func TestCheckRunProgress(t *testing.T) {
+	rec := &progressRecorder{}
+	clock := &testClock{t: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)}
+	p := newCheckRunProgress(context.Background(), rec, &core.GitHubEvent{RepoFullName: "owner/app", PRNumber: 1}, 42, slog.New(slog.DiscardHandler))
+	p.now = clock.now
+
+	p.report(core.StageSyncing, 0, 0)
+	p.report(core.StageIndexing, 10, 40)
+	// Progress within a stage waits for the update interval...
+	p.report(core.StageIndexing, 20, 40)
+	clock.t = clock.t.Add(progressUpdateInterval)
+	p.report(core.StageIndexing, 30, 40)
+	// ...unless the stage is finished.
+	p.report(core.StageIndexing, 40, 40)
+	p.report(core.StageRetrieving, 0, 0)
+	p.report(core.StageGenerating, 0, 0)
+	p.report(core.StagePosting, 0, 0)
+
+	assert.Equal(t, []string{
+		"Syncing repository",
+		"Indexing files: 25% (10/40)",
+		"Indexing files: 75% (30/40)",
+		"Indexing files: 100% (40/40)",
+		"Retrieving context",
+		"Generating review",
+		"Posting review",
+	}, rec.titles)
+}
+
+func TestCheckRunProgressThroughContext(t *testing.T) {
+	rec := &progressRecorder{}
+	p := newCheckRunProgress(context.Background(), rec, &core.GitHubEvent{}, 42, slog.New(slog.DiscardHandler))
+	ctx := core.WithReviewProgress(context.Background(), p.report)
+
+	core.ReportReviewStage(ctx, core.StageRetrieving, 0, 0)
+	// Parts of a review can stop reporting.
+	core.ReportReviewStage(core.WithReviewProgress(ctx, nil), core.StageGenerating, 0, 0)
+	core.ReportReviewStage(context.Background(), core.StagePosting, 0, 0)
+
+	assert.Equal(t, []string{"Retrieving context"}, rec.titles)
+}
@@ -0,0 +1,192 @@
+package jobs
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// Priority classes as ranks, lower runs first.
+const (
+	rankHigh = iota
+	rankNormal
+	rankLow
+)
+
+var priorityRanks = map[string]int{
+	config.PriorityHigh:   rankHigh,
+	config.PriorityNormal: rankNormal,
+	config.PriorityLow:    rankLow,
+}
+
+// defaultPriorities are the ranks of the job kinds not configured in
+// server.scheduling.priorities. Commands someone waits for run first,
+// agent sessions next and scans of newly installed repositories last.
+var defaultPriorities = map[core.ReviewType]int{
+	core.FullReview:        rankHigh,
+	core.ReReview:          rankHigh,
+	core.SecurityReview:    rankHigh,
+	core.ExplainPR:         rankHigh,
+	core.RecordFeedback:    rankHigh,
+	core.SuggestionReply:   rankHigh,
+	core.ImplementIssue:    rankNormal,
+	core.OnboardRepository: rankLow,
+}
+
+// schedulingPolicy decides which queued job runs next.
+type schedulingPolicy struct {
+	priorities map[string]int
+	maxPerRepo int
+	aging      time.Duration
+}
+
+func newSchedulingPolicy(cfg config.SchedulingConfig) schedulingPolicy {
+	p := schedulingPolicy{
+		priorities: make(map[string]int),
+		maxPerRepo: cfg.MaxJobsPerRepo,
+		aging:      cfg.AgingInterval,
+	}
+	for kind, rank := range defaultPriorities {
+		p.priorities[kind.String()] = rank
+	}
+	for kind, class := range cfg.Priorities {
+		if rank, ok := priorityRanks[class]; ok {
+			p.priorities[kind] = rank
+		}
+	}
+	return p
+}
+
+// rank returns the priority rank of an event; unknown kinds are normal.
+func (p schedulingPolicy) rank(event *core.GitHubEvent) int {
+	if rank, ok := p.priorities[event.Type.String()]; ok {
+		return rank
+	}
+	return rankNormal
+}
+
+// effectiveRank is the rank of a job raised by one class for every aging
+// interval it has waited.
+func (p schedulingPolicy) effectiveRank(payload *jobPayload, now time.Time) int {
+	if p.aging <= 0 {
+		return payload.rank
+	}
+	return max(payload.rank-int(now.Sub(payload.queuedAt)/p.aging), rankHigh)
+}
+
+// jobQueue is a bounded queue of jobs that hands workers the next job by
+// the scheduling policy rather than in arrival order.
+type jobQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	capacity int
+	policy   schedulingPolicy
+	now      func() time.Time
+
+	// items holds the queued jobs in arrival order.
+	items []*jobPayload
+	// running counts the jobs taken from the queue per repository until
+	// they are done.
+	running map[string]int
+	// served holds when a job of each repository was last taken, as a
+	// sequence number, so that repositories take turns.
+	served map[string]uint64
+	seq    uint64
+	closed bool
+}
+
+func newJobQueue(capacity int, policy schedulingPolicy) *jobQueue {
+	q := &jobQueue{
+		capacity: capacity,
+		policy:   policy,
+		now:      time.Now,
+		running:  make(map[string]int),
+		served:   make(map[string]uint64),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues a job and reports false if the queue is full or closed.
+func (q *jobQueue) push(payload *jobPayload) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed || len(q.items) >= q.capacity {
+		return false
+	}
+	payload.rank = q.policy.rank(payload.event)
+	payload.queuedAt = q.now()
+	q.items = append(q.items, payload)
+	q.cond.Signal()
+	return true
+}
+
+// pop waits for the next job to run. It returns false once the queue is
+// closed and empty. The job counts as running for its repository until done
+// is called.
+func (q *jobQueue) pop() (*jobPayload, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if i := q.next(); i >= 0 {
+			payload := q.items[i]
+			q.items = append(q.items[:i], q.items[i+1:]...)
+			repo := payload.event.RepoFullName
+			q.running[repo]++
+			q.seq++
+			q.served[repo] = q.seq
+			return payload, true
+		}
+		if q.closed && len(q.items) == 0 {
+			return nil, false
+		}
+		q.cond.Wait()
+	}
+}
+
+// next returns the index of the job to run next, or -1 when every queued
+// job belongs to a repository at its cap. It picks the lowest effective
+// rank, then the repository served longest ago, then the oldest job.
+func (q *jobQueue) next() int {
+	now := q.now()
+	best, bestRank := -1, 0
+	for i, payload := range q.items {
+		repo := payload.event.RepoFullName
+		if q.policy.maxPerRepo > 0 && q.running[repo] >= q.policy.maxPerRepo {
+			continue
+		}
+		rank := q.policy.effectiveRank(payload, now)
+		if best < 0 || rank < bestRank ||
+			(rank == bestRank && q.served[repo] < q.served[q.items[best].event.RepoFullName]) {
+			best, bestRank = i, rank
+		}
+	}
+	return best
+}
+
+// done marks a job taken with pop as finished.
+func (q *jobQueue) done(payload *jobPayload) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	repo := payload.event.RepoFullName
+	if q.running[repo]--; q.running[repo] <= 0 {
+		delete(q.running, repo)
+	}
+	q.cond.Broadcast()
+}
+
+// close makes pop return false once the queued jobs are taken.
+func (q *jobQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+func (q *jobQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
@@ -0,0 +1,146 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time { return c.t }
+
+func newTestQueue(cfg config.SchedulingConfig, capacity int) (*jobQueue, *testClock) {
+	clock := &testClock{t: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)}
+	q := newJobQueue(capacity, newSchedulingPolicy(cfg))
+	q.now = clock.now
+	return q, clock
+}
+
+func queueJob(t *testing.T, q *jobQueue, repo string, kind core.ReviewType, pr int) {
+	t.Helper()
+	ctx, cancel := context.WithCancelCause(context.Background())
+	t.Cleanup(func() { cancel(nil) })
+	payload := &jobPayload{ctx: ctx, cancel: cancel, event: &core.GitHubEvent{RepoFullName: repo, Type: kind, PRNumber: pr}}
+	require.True(t, q.push(payload))
+}
+
+// popAll takes every queued job, marking each done before taking the next,
+// and returns them as "repo#pr".
+func popAll(q *jobQueue) []string {
+	var order []string
+	for q.len() > 0 {
+		payload, _ := q.pop()
+		order = append(order, fmt.Sprintf("%s#%d", payload.event.RepoFullName, payload.event.PRNumber))
+		q.done(payload)
+	}
+	return order
+}
+
+func TestJobQueue_HigherPriorityFirst(t *testing.T) {
+	q, _ := newTestQueue(config.SchedulingConfig{}, 10)
+	queueJob(t, q, "owner/new", core.OnboardRepository, 1)
+	queueJob(t, q, "owner/app", core.ImplementIssue, 2)
+	queueJob(t, q, "owner/app", core.FullReview, 3)
+
+	assert.Equal(t, []string{"owner/app#3", "owner/app#2", "owner/new#1"}, popAll(q))
+}
+
+func TestJobQueue_ConfiguredPriorities(t *testing.T) {
+	q, _ := newTestQueue(config.SchedulingConfig{Priorities: map[string]string{"scan": "high", "review": "low"}}, 10)
+	queueJob(t, q, "owner/app", core.FullReview, 1)
+	queueJob(t, q, "owner/app", core.ImplementIssue, 2)
+	queueJob(t, q, "owner/new", core.OnboardRepository, 3)
+
+	assert.Equal(t, []string{"owner/new#3", "owner/app#2", "owner/app#1"}, popAll(q))
+}
+
+func TestJobQueue_RepositoriesTakeTurns(t *testing.T) {
+	q, _ := newTestQueue(config.SchedulingConfig{}, 10)
+	for pr := 1; pr <= 3; pr++ {
+		queueJob(t, q, "owner/busy", core.FullReview, pr)
+	}
+	queueJob(t, q, "owner/quiet", core.FullReview, 4)
+	queueJob(t, q, "owner/other", core.FullReview, 5)
+
+	assert.Equal(t, []string{"owner/busy#1", "owner/quiet#4", "owner/other#5", "owner/busy#2", "owner/busy#3"}, popAll(q))
+}
+
+func TestJobQueue_MaxJobsPerRepo(t *testing.T) {
+	q, _ := newTestQueue(config.SchedulingConfig{MaxJobsPerRepo: 1}, 10)
+	queueJob(t, q, "owner/busy", core.FullReview, 1)
+	queueJob(t, q, "owner/busy", core.FullReview, 2)
+	queueJob(t, q, "owner/other", core.OnboardRepository, 3)
+
+	first, _ := q.pop()
+	assert.Equal(t, 1, first.event.PRNumber)
+	// owner/busy is at its cap, so the low-priority job of another
+	// repository runs before the second review of owner/busy.
+	second, _ := q.pop()
+	assert.Equal(t, 3, second.event.PRNumber)
+
+	taken := make(chan *jobPayload)
+	go func() {
+		p, _ := q.pop()
+		taken <- p
+	}()
+	select {
+	case <-taken:
+		t.Fatal("a second job of owner/busy ran while the first was running")
+	case <-time.After(20 * time.Millisecond):
+	}
+	q.done(first)
+	assert.Equal(t, 2, (<-taken).event.PRNumber)
+}
+
+func TestJobQueue_AgingAvoidsStarvation(t *testing.T) {
+	q, clock := newTestQueue(config.SchedulingConfig{AgingInterval: time.Minute}, 100)
+	queueJob(t, q, "owner/new", core.OnboardRepository, 1)
+
+	// A steady stream of reviews keeps the scan waiting at first.
+	for pr := 2; pr <= 4; pr++ {
+		queueJob(t, q, "owner/app", core.FullReview, pr)
+		payload, _ := q.pop()
+		assert.Equal(t, pr, payload.event.PRNumber)
+		q.done(payload)
+	}
+
+	// After two aging intervals the scan ranks as high as the reviews and
+	// its repository has not been served yet, so it runs next.
+	clock.t = clock.t.Add(2 * time.Minute)
+	queueJob(t, q, "owner/app", core.FullReview, 5)
+	payload, _ := q.pop()
+	assert.Equal(t, 1, payload.event.PRNumber)
+	q.done(payload)
+	assert.Equal(t, []string{"owner/app#5"}, popAll(q))
+}
+
+func TestJobQueue_WithoutAgingLowPriorityWaits(t *testing.T) {
+	q, clock := newTestQueue(config.SchedulingConfig{}, 100)
+	queueJob(t, q, "owner/new", core.OnboardRepository, 1)
+	clock.t = clock.t.Add(time.Hour)
+	queueJob(t, q, "owner/app", core.FullReview, 2)
+
+	assert.Equal(t, []string{"owner/app#2", "owner/new#1"}, popAll(q))
+}
+
+func TestJobQueue_FullAndClosed(t *testing.T) {
+	q, _ := newTestQueue(config.SchedulingConfig{}, 1)
+	queueJob(t, q, "owner/app", core.FullReview, 1)
+	assert.False(t, q.push(&jobPayload{event: &core.GitHubEvent{RepoFullName: "owner/app"}}), "queue is full")
+
+	q.close()
+	payload, ok := q.pop()
+	require.True(t, ok, "queued jobs are still taken after close")
+	q.done(payload)
+	_, ok = q.pop()
+	assert.False(t, ok)
+	assert.False(t, q.push(&jobPayload{event: &core.GitHubEvent{RepoFullName: "owner/app"}}), "queue is closed")
+}
@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+	ragreview "github.com/sevigo/code-warden/internal/rag/review"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// replyHeadings open the reply posted for each "/warden" reply command.
+var replyHeadings = map[string]string{
+	core.ReplyExplain: "**💡 Explanation**",
+	core.ReplyFix:     "**🔧 Suggested fix**",
+}
+
+// runSuggestionReply answers a "/warden explain" or "/warden fix" reply to a
+// posted suggestion in its thread. Unlike a review it creates no check run
+// and does not sync the repository: the thread's diff hunk is the code, and
+// the index, if the repository has one, supplies the context.
+func (j *ReviewJob) runSuggestionReply(ctx context.Context, event *core.GitHubEvent) (err error) {
+	if event.ReplyCommand == core.ReplyApply {
+		return j.runApplySuggestion(ctx, event)
+	}
+	j.logger.InfoContext(ctx, "💬 Replying to suggestion", "repo", event.RepoFullName, "pr", event.PRNumber,
+		"comment_id", event.ThreadCommentID, "command", event.ReplyCommand)
+	finish := j.startJobRun(ctx, "reply", event, "webhook:/warden "+event.ReplyCommand)
+	defer func() { finish(ctx, err) }()
+
+	ghClient, _, err := github.CreateInstallationClient(ctx, j.cfg, event.InstallationID, j.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	reply := func(body string) error {
+		if err := ghClient.ReplyToReviewComment(ctx, event.RepoOwner, event.RepoName, event.PRNumber, event.ThreadCommentID, body); err != nil {
+			return fmt.Errorf("failed to post reply: %w", err)
+		}
+		return nil
+	}
+
+	comment, err := ghClient.GetReviewComment(ctx, event.RepoOwner, event.RepoName, event.ThreadCommentID)
+	if err != nil {
+		return fmt.Errorf("failed to get review comment %d: %w", event.ThreadCommentID, err)
+	}
+	botLogin, err := github.AppLogin(ctx, j.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to look up the app login: %w", err)
+	}
+	if _, ok := github.ParseSuggestion(comment, botLogin); !ok {
+		return reply(fmt.Sprintf("`/warden %s` only works as a reply to a Code-Warden suggestion.", event.ReplyCommand))
+	}
+	if comment.Line == 0 {
+		return reply("This suggestion is outdated: its line is no longer part of the diff. Run `/review` for a fresh review.")
+	}
+
+	repo, repoConfig := j.replyContext(ctx, ghClient, event)
+	if err := j.enforceLocalOnly(repoConfig); err != nil {
+		return err
+	}
+	thread := &core.SuggestionThread{
+		Path:       comment.Path,
+		StartLine:  comment.StartLine,
+		Line:       comment.Line,
+		Suggestion: comment.Body,
+		DiffHunk:   comment.DiffHunk,
+	}
+	body, err := j.ragService.ReplyToSuggestion(ctx, repoConfig, repo, event, thread)
+	if errors.Is(err, ragreview.ErrNoPatch) {
+		return reply("I could not write a patch for this suggestion. Try `/warden explain`, or `/warden fix` with more details on what you expect.")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate reply: %w", err)
+	}
+	if err := reply(replyHeadings[event.ReplyCommand] + "\n\n" + body); err != nil {
+		return err
+	}
+	j.syncFeedbackReactions(ctx, ghClient, event)
+	return nil
+}
+
+// replyContext returns the indexed repository of event and its
+// configuration, restoring its index from cold storage if needed.
+// Repositories that are not indexed are answered without context.
+func (j *ReviewJob) replyContext(ctx context.Context, ghClient github.Client, event *core.GitHubEvent) (*storage.Repository, *core.RepoConfig) {
+	repo, err := j.repoMgr.GetRepoRecord(ctx, event.RepoFullName)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			j.logger.WarnContext(ctx, "failed to load repository, replying without context", "repo", event.RepoFullName, "error", err)
+		}
+		return nil, core.DefaultRepoConfig()
+	}
+	return repo, j.loadAndProcessRepoConfig(ctx, ghClient, event, repo.ClonePath)
+}
@@ -10,16 +10,26 @@ import (
 	"sync"
 	"time"
 
+	gogithub "github.com/google/go-github/v73/github"
+
 	"github.com/sevigo/code-warden/internal/agent"
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/feedback"
 	"github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/globalmcp"
+	"github.com/sevigo/code-warden/internal/health"
+	"github.com/sevigo/code-warden/internal/notify"
+	"github.com/sevigo/code-warden/internal/orgconfig"
 	"github.com/sevigo/code-warden/internal/rag"
+	"github.com/sevigo/code-warden/internal/redact"
 	"github.com/sevigo/code-warden/internal/repomanager"
 	reviewpkg "github.com/sevigo/code-warden/internal/review"
+	"github.com/sevigo/code-warden/internal/sandbox"
+	"github.com/sevigo/code-warden/internal/settings"
 	"github.com/sevigo/code-warden/internal/storage"
 	"github.com/sevigo/code-warden/internal/stringsutil"
+	"github.com/sevigo/code-warden/internal/suppress"
 )
 
 type ReviewJob struct {
@@ -30,10 +40,22 @@ type ReviewJob struct {
 	repoMgr           repomanager.RepoManager
 	logger            *slog.Logger
 	globalMCPRegistry *globalmcp.WorkspaceRegistry
+	feedback          *feedback.Collector
+	orgConfig         *orgconfig.Loader
+	settings          *settings.Manager
+	notifier          *notify.Notifier
 	repoMutexes       sync.Map
 	// activeSessions maps session ID → orchestrator for in-flight implement jobs.
 	// Used by CancelSession to honour /cancel <id> webhook commands.
 	activeSessions sync.Map
+	// activeCheckRuns holds the IDs of check runs whose job is still running,
+	// so the CheckRunReaper leaves them alone.
+	activeCheckRuns sync.Map
+	// permissions fetches the permissions granted to an installation, and
+	// permittedRepos holds the repositories whose installation had all of
+	// the required ones.
+	permissions    func(ctx context.Context, installationID int64) (*gogithub.InstallationPermissions, error)
+	permittedRepos sync.Map
 }
 
 // CancelSession cancels a running agent session. Implements core.SessionCanceller.
@@ -58,6 +80,8 @@ func NewReviewJob(
 	repoMgr repomanager.RepoManager,
 	logger *slog.Logger,
 	globalMCPRegistry *globalmcp.WorkspaceRegistry,
+	settingsMgr *settings.Manager,
+	notifier *notify.Notifier,
 ) *ReviewJob {
 	return &ReviewJob{
 		cfg:               cfg,
@@ -67,7 +91,29 @@ func NewReviewJob(
 		repoMgr:           repoMgr,
 		logger:            logger,
 		globalMCPRegistry: globalMCPRegistry,
+		feedback:          feedback.NewCollector(store, logger),
+		orgConfig:         orgconfig.NewLoader(cfg.OrgConfig, logger),
+		settings:          settingsMgr,
+		notifier:          notifier,
+		permissions: func(ctx context.Context, installationID int64) (*gogithub.InstallationPermissions, error) {
+			return github.GetInstallationPermissions(ctx, cfg, installationID, logger)
+		},
+	}
+}
+
+// isCheckRunActive reports whether a job of this process still owns the check run.
+func (j *ReviewJob) isCheckRunActive(id int64) bool {
+	_, ok := j.activeCheckRuns.Load(id)
+	return ok
+}
+
+// aiConfig returns the AI configuration for a new job, with the runtime
+// overrides made through the admin API applied.
+func (j *ReviewJob) aiConfig() config.AIConfig {
+	if j.settings == nil {
+		return j.cfg.AI
 	}
+	return j.settings.AI()
 }
 
 // getRepoMutex returns a mutex for the given repository to prevent concurrent operations.
@@ -85,7 +131,7 @@ func (j *ReviewJob) getRepoMutex(repoFullName string) *sync.Mutex {
 // Run acts as a router, directing the event to the correct review flow.
 func (j *ReviewJob) Run(ctx context.Context, event *core.GitHubEvent) error {
 	// Log the command type
-	j.logger.Info("processing GitHub event",
+	j.logger.InfoContext(ctx, "processing GitHub event",
 		"type", event.Type,
 		"repo", event.RepoFullName,
 		"pr", event.PRNumber,
@@ -93,7 +139,7 @@ func (j *ReviewJob) Run(ctx context.Context, event *core.GitHubEvent) error {
 		"commenter", event.Commenter)
 
 	if err := j.validateInputs(event); err != nil {
-		j.logger.Error("Input validation failed", "error", err)
+		j.logger.ErrorContext(ctx, "Input validation failed", "error", err)
 		return err
 	}
 
@@ -102,17 +148,31 @@ func (j *ReviewJob) Run(ctx context.Context, event *core.GitHubEvent) error {
 		return j.runFullReview(ctx, event)
 	case core.ReReview:
 		return j.runReReview(ctx, event)
+	case core.SecurityReview:
+		return j.runSecurityReview(ctx, event)
+	case core.ExplainPR:
+		return j.runExplainPR(ctx, event)
 	case core.ImplementIssue:
 		return j.runImplementIssue(ctx, event)
+	case core.RecordFeedback:
+		return j.runRecordFeedback(ctx, event)
+	case core.OnboardRepository:
+		return j.runOnboarding(ctx, event)
+	case core.SuggestionReply:
+		return j.runSuggestionReply(ctx, event)
 	default:
 		return fmt.Errorf("unknown review type: %v", event.Type)
 	}
 }
 
-// runFullReview handles the initial `/review` command.
+// runFullReview handles the initial `/review` command and auto-reviews.
 func (j *ReviewJob) runFullReview(ctx context.Context, event *core.GitHubEvent) error {
-	j.logger.Info("🚀 Starting Code Review", "repo", event.RepoFullName, "pr", event.PRNumber)
-	finish := j.startJobRun(ctx, "review", event, "webhook:/review")
+	j.logger.InfoContext(ctx, "🚀 Starting Code Review", "repo", event.RepoFullName, "pr", event.PRNumber)
+	triggeredBy := "webhook:/review"
+	if event.AutoReview {
+		triggeredBy = "webhook:pull_request"
+	}
+	finish := j.startJobRun(ctx, "review", event, triggeredBy)
 	err := j.executeReviewWorkflow(ctx, event, "Code Review", "AI analysis in progress...")
 	finish(ctx, err)
 	return err
@@ -120,13 +180,103 @@ func (j *ReviewJob) runFullReview(ctx context.Context, event *core.GitHubEvent)
 
 // runReReview handles the `/rereview` command.
 func (j *ReviewJob) runReReview(ctx context.Context, event *core.GitHubEvent) error {
-	j.logger.Info("🔄 Starting Re-Review", "repo", event.RepoFullName, "pr", event.PRNumber)
+	j.logger.InfoContext(ctx, "🔄 Starting Re-Review", "repo", event.RepoFullName, "pr", event.PRNumber)
 	finish := j.startJobRun(ctx, "rereview", event, "webhook:/rereview")
 	err := j.executeReReviewWorkflow(ctx, event)
 	finish(ctx, err)
 	return err
 }
 
+// runSecurityReview handles the `/security` command.
+func (j *ReviewJob) runSecurityReview(ctx context.Context, event *core.GitHubEvent) error {
+	j.logger.InfoContext(ctx, "🛡️ Starting Security Review", "repo", event.RepoFullName, "pr", event.PRNumber)
+	finish := j.startJobRun(ctx, "security", event, "webhook:/security")
+	err := j.executeReviewWorkflow(ctx, event, "Security Review", "Security analysis in progress...")
+	finish(ctx, err)
+	return err
+}
+
+// runExplainPR handles the `/explain` command.
+func (j *ReviewJob) runExplainPR(ctx context.Context, event *core.GitHubEvent) error {
+	j.logger.InfoContext(ctx, "🧭 Starting PR Walkthrough", "repo", event.RepoFullName, "pr", event.PRNumber)
+	finish := j.startJobRun(ctx, "explain", event, "webhook:/explain")
+	err := j.executeWalkthroughWorkflow(ctx, event)
+	finish(ctx, err)
+	return err
+}
+
+// executeWalkthroughWorkflow posts a walkthrough of the PR for newcomers as
+// a comment. It is not a review: nothing is saved and no suggestions are made.
+func (j *ReviewJob) executeWalkthroughWorkflow(ctx context.Context, event *core.GitHubEvent) (err error) {
+	env, err := j.setupReviewEnvironment(ctx, event, "PR Walkthrough", "Writing a walkthrough for newcomers...")
+	if err != nil {
+		return err
+	}
+	defer env.release()
+	ctx = core.WithReviewProgress(ctx, env.progress)
+	defer func() {
+		if err != nil {
+			j.updateStatusOnError(ctx, env.statusUpdater, event, env.checkRunID, err)
+		}
+	}()
+
+	diff, err := env.ghClient.GetPullRequestDiff(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get PR diff: %w", err)
+	}
+	changedFiles, err := env.ghClient.GetChangedFiles(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get changed files: %w", err)
+	}
+	if commits, cErr := env.ghClient.GetPullRequestCommits(ctx, event.RepoOwner, event.RepoName, event.PRNumber); cErr == nil {
+		event.CommitMessages = commits
+	} else {
+		j.logger.WarnContext(ctx, "failed to fetch commit messages, walkthrough will proceed without them", "error", cErr)
+	}
+
+	walkthrough, err := j.ragService.GenerateWalkthrough(ctx, env.repoConfig, env.repo, event, diff, changedFiles)
+	if err != nil {
+		return fmt.Errorf("failed to generate walkthrough: %w", err)
+	}
+	core.ReportReviewStage(ctx, core.StagePosting, 0, 0)
+	if err = env.ghClient.CreateComment(ctx, event.RepoOwner, event.RepoName, event.PRNumber, walkthrough); err != nil {
+		return fmt.Errorf("failed to post walkthrough comment: %w", err)
+	}
+
+	return env.statusUpdater.Completed(ctx, event, env.checkRunID, "success", "Walkthrough Posted", "PR walkthrough posted as a comment.")
+}
+
+// runRecordFeedback handles a "/warden helpful|wrong" reply and refreshes the
+// reaction-based feedback for the whole pull request.
+func (j *ReviewJob) runRecordFeedback(ctx context.Context, event *core.GitHubEvent) error {
+	ghClient, _, err := github.CreateInstallationClient(ctx, j.cfg, event.InstallationID, j.logger)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub client: %w", err)
+	}
+	botLogin, err := github.AppLogin(ctx, j.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to look up the app login: %w", err)
+	}
+	if err := j.feedback.RecordReply(ctx, ghClient, event, botLogin); err != nil {
+		return fmt.Errorf("failed to record feedback: %w", err)
+	}
+	j.syncFeedbackReactions(ctx, ghClient, event)
+	return nil
+}
+
+// syncFeedbackReactions polls reactions on posted suggestions. Failures are
+// logged only; feedback collection must never fail a review.
+func (j *ReviewJob) syncFeedbackReactions(ctx context.Context, ghClient github.Client, event *core.GitHubEvent) {
+	botLogin, err := github.AppLogin(ctx, j.cfg)
+	if err != nil {
+		j.logger.WarnContext(ctx, "failed to look up the app login, not syncing suggestion reactions", "error", err)
+		return
+	}
+	if err := j.feedback.SyncReactions(ctx, ghClient, event.RepoOwner, event.RepoName, event.PRNumber, botLogin); err != nil {
+		j.logger.WarnContext(ctx, "failed to sync suggestion reactions", "repo", event.RepoFullName, "pr", event.PRNumber, "error", err)
+	}
+}
+
 // startJobRun records a job as "running" and returns a function to finalize it.
 func (j *ReviewJob) startJobRun(ctx context.Context, jobType string, event *core.GitHubEvent, triggeredBy string) func(context.Context, error) {
 	startedAt := time.Now()
@@ -139,7 +289,7 @@ func (j *ReviewJob) startJobRun(ctx context.Context, jobType string, event *core
 		TriggeredAt:  startedAt,
 	})
 	if err != nil {
-		j.logger.Warn("failed to record job run start", "type", jobType, "error", err)
+		j.logger.WarnContext(ctx, "failed to record job run start", "type", jobType, "error", err)
 		jobID = 0
 	}
 	return func(ctx context.Context, runErr error) {
@@ -149,10 +299,16 @@ func (j *ReviewJob) startJobRun(ctx context.Context, jobType string, event *core
 		status := "completed"
 		if runErr != nil {
 			status = "failed"
+			switch cause := context.Cause(ctx); {
+			case errors.Is(cause, core.ErrJobTimedOut):
+				status = "timed_out"
+			case errors.Is(cause, core.ErrJobCancelled):
+				status = "cancelled"
+			}
 		}
 		completedAt := time.Now()
-		if updateErr := j.store.UpdateJobRun(ctx, jobID, status, completedAt, completedAt.Sub(startedAt).Milliseconds()); updateErr != nil {
-			j.logger.Warn("failed to update job run", "id", jobID, "error", updateErr)
+		if updateErr := j.store.UpdateJobRun(context.WithoutCancel(ctx), jobID, status, completedAt, completedAt.Sub(startedAt).Milliseconds()); updateErr != nil {
+			j.logger.WarnContext(ctx, "failed to update job run", "id", jobID, "error", updateErr)
 		}
 	}
 }
@@ -161,14 +317,14 @@ func (j *ReviewJob) startJobRun(ctx context.Context, jobType string, event *core
 //
 //nolint:funlen // Complex workflow requiring multiple sequential steps
 func (j *ReviewJob) runImplementIssue(ctx context.Context, event *core.GitHubEvent) error {
-	j.logger.Info("🤖 Starting Issue Implementation",
+	j.logger.InfoContext(ctx, "🤖 Starting Issue Implementation",
 		"repo", event.RepoFullName,
 		"issue", event.IssueNumber,
 		"title", event.IssueTitle)
 
 	// Check if agent is enabled
 	if !j.cfg.Agent.Enabled {
-		j.logger.Warn("agent functionality is disabled")
+		j.logger.WarnContext(ctx, "agent functionality is disabled")
 		return fmt.Errorf("agent functionality is disabled; enable it in config to use /implement")
 	}
 
@@ -177,6 +333,7 @@ func (j *ReviewJob) runImplementIssue(ctx context.Context, event *core.GitHubEve
 	if err != nil {
 		return fmt.Errorf("failed to create GitHub client: %w", err)
 	}
+	ghClient = j.trackCheckRuns(ghClient, event)
 
 	// 2. Sync the repository to get the latest code
 	updateResult, err := j.repoMgr.SyncRepo(ctx, event, ghToken)
@@ -191,10 +348,13 @@ func (j *ReviewJob) runImplementIssue(ctx context.Context, event *core.GitHubEve
 	}
 
 	// 4. Load repository config
-	repoConfig := j.loadAndProcessRepoConfig(updateResult.RepoPath, event.RepoFullName)
+	repoConfig := j.loadAndProcessRepoConfig(ctx, ghClient, event, updateResult.RepoPath)
+	if err := j.enforceLocalOnly(repoConfig); err != nil {
+		return err
+	}
 
 	// 5. Get scoped vector store for this repo
-	scopedStore := j.vectorStore.ForRepo(repo.QdrantCollectionName, j.cfg.AI.EmbedderModel)
+	scopedStore := j.vectorStore.ForRepo(repo.QdrantCollectionName, repo.Embedder(j.cfg.AI.EmbedderModel))
 
 	// 6. Parse agent timeout
 	timeout, err := j.cfg.Agent.GetTimeout()
@@ -202,20 +362,26 @@ func (j *ReviewJob) runImplementIssue(ctx context.Context, event *core.GitHubEve
 		return fmt.Errorf("invalid agent timeout: %w", err)
 	}
 
+	// Repository commands run by the agent execute in the configured sandbox.
+	runner, err := sandbox.New(j.cfg.Sandbox)
+	if err != nil {
+		return fmt.Errorf("failed to create sandbox: %w", err)
+	}
+
 	// 7. Create orchestrator
 	// For agent iterations, use a single randomly-selected comparison model (if configured)
 	// instead of full consensus review. This provides better quality than the generator model
 	// alone while keeping review time within the 60-second MCP tool timeout.
 	// Full consensus review (3 models) takes 90-180+ seconds which causes client timeouts.
 	var agentComparisonModel []string
-	if len(j.cfg.AI.ComparisonModels) > 0 {
+	if comparisonModels := j.aiConfig().ComparisonModels; len(comparisonModels) > 0 {
 		// Randomly select one model from the comparison models
 		//nolint:gosec // G404: Random selection of review model, not security-sensitive
-		selectedModel := j.cfg.AI.ComparisonModels[rand.IntN(len(j.cfg.AI.ComparisonModels))]
+		selectedModel := comparisonModels[rand.IntN(len(comparisonModels))]
 		agentComparisonModel = []string{selectedModel}
-		j.logger.Info("agent using single comparison model for faster review",
+		j.logger.InfoContext(ctx, "agent using single comparison model for faster review",
 			"selected_model", selectedModel,
-			"available_models", j.cfg.AI.ComparisonModels)
+			"available_models", comparisonModels)
 	}
 
 	orchestrator := agent.NewOrchestrator(
@@ -246,6 +412,7 @@ func (j *ReviewJob) runImplementIssue(ctx context.Context, event *core.GitHubEve
 			ReviewRounds:          j.cfg.Agent.ReviewRounds,
 			FixIterations:         j.cfg.Agent.FixIterations,
 			PublishIterations:     j.cfg.Agent.PublishIterations,
+			Sandbox:               runner,
 		},
 		j.logger,
 		j.globalMCPRegistry,
@@ -259,7 +426,7 @@ func (j *ReviewJob) runImplementIssue(ctx context.Context, event *core.GitHubEve
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 		if err := orchestrator.Shutdown(shutdownCtx); err != nil {
-			j.logger.Error("failed to shutdown orchestrator", "error", err)
+			j.logger.ErrorContext(ctx, "failed to shutdown orchestrator", "error", err)
 		}
 	}()
 
@@ -294,7 +461,7 @@ func (j *ReviewJob) runImplementIssue(ctx context.Context, event *core.GitHubEve
 
 // waitForAgentSession monitors the agent session until completion or timeout.
 func (j *ReviewJob) waitForAgentSession(ctx context.Context, orchestrator *agent.Orchestrator, session *agent.Session, timeout time.Duration) (*agent.Result, error) {
-	j.logger.Info("agent session started, waiting for completion",
+	j.logger.InfoContext(ctx, "agent session started, waiting for completion",
 		"session_id", session.ID,
 		"timeout", timeout)
 
@@ -308,13 +475,13 @@ func (j *ReviewJob) waitForAgentSession(ctx context.Context, orchestrator *agent
 		select {
 		case <-timeoutCtx.Done():
 			if err := orchestrator.CancelSession(session.ID); err != nil {
-				j.logger.Error("failed to cancel session on timeout", "error", err)
+				j.logger.ErrorContext(ctx, "failed to cancel session on timeout", "error", err)
 			}
 			return nil, fmt.Errorf("agent session timed out after %v", timeout)
 
 		case <-ticker.C:
 			snapshot := session.Snapshot()
-			j.logger.Info("agent session status",
+			j.logger.InfoContext(ctx, "agent session status",
 				"session_id", session.ID,
 				"status", snapshot.Status,
 				"duration", time.Since(snapshot.StartedAt).Round(time.Second))
@@ -324,7 +491,7 @@ func (j *ReviewJob) waitForAgentSession(ctx context.Context, orchestrator *agent
 				if snapshot.Result == nil {
 					return nil, fmt.Errorf("agent completed with no result")
 				}
-				j.logger.Info("agent session completed",
+				j.logger.InfoContext(ctx, "agent session completed",
 					"session_id", session.ID,
 					"pr_number", snapshot.Result.PRNumber,
 					"pr_url", snapshot.Result.PRURL,
@@ -370,16 +537,21 @@ func (j *ReviewJob) executeReReviewWorkflow(ctx context.Context, event *core.Git
 	if err != nil {
 		return err
 	}
+	defer reviewEnv.release()
+	ctx = core.WithReviewProgress(ctx, reviewEnv.progress)
 	defer func() {
 		if err != nil {
 			j.updateStatusOnError(ctx, reviewEnv.statusUpdater, event, reviewEnv.checkRunID, err)
 		}
 	}()
 
+	// Reactions on the previous round's suggestions are only visible by polling.
+	j.syncFeedbackReactions(ctx, reviewEnv.ghClient, event)
+
 	// 1. Fetch the latest review from the database
 	lastReview, err := j.store.GetLatestReviewForPR(ctx, event.RepoFullName, event.PRNumber)
 	if err != nil {
-		j.logger.Warn("failed to fetch last review for re-review", "error", err)
+		j.logger.WarnContext(ctx, "failed to fetch last review for re-review", "error", err)
 		// Fallback: If no previous review, run a full review instead
 		err = j.executeReviewWorkflow(ctx, event, "Code Review (Fallback)", "No previous review found, running full review...")
 		return err
@@ -395,17 +567,19 @@ func (j *ReviewJob) executeReReviewWorkflow(ctx context.Context, event *core.Git
 	if commits, cErr := reviewEnv.ghClient.GetPullRequestCommits(ctx, event.RepoOwner, event.RepoName, event.PRNumber); cErr == nil {
 		event.CommitMessages = commits
 	} else {
-		j.logger.Warn("failed to fetch commit messages for re-review, proceeding without them", "error", cErr)
+		j.logger.WarnContext(ctx, "failed to fetch commit messages for re-review, proceeding without them", "error", cErr)
 	}
 
 	// 3. Generate Re-Review using RAG service
-	structuredReview, rawReReview, err := j.ragService.GenerateReReview(ctx, reviewEnv.repo, event, lastReview, reviewEnv.ghClient, changedFiles)
+	structuredReview, rawReReview, err := j.ragService.GenerateReReview(ctx, reviewEnv.repoConfig, reviewEnv.repo, event, lastReview, reviewEnv.ghClient, changedFiles)
 	if err != nil {
 		err = fmt.Errorf("failed to generate re-review: %w", err)
 		return err
 	}
+	reviewpkg.Suppress(structuredReview, suppress.FromChangedFiles(changedFiles), reviewEnv.repo.ClonePath, false, j.logger)
 
 	// 4. Post the result
+	core.ReportReviewStage(ctx, core.StagePosting, 0, 0)
 	if err = reviewEnv.statusUpdater.PostStructuredReview(ctx, event, structuredReview); err != nil {
 		return fmt.Errorf("failed to post re-review comment: %w", err)
 	}
@@ -422,11 +596,14 @@ func (j *ReviewJob) executeReReviewWorkflow(ctx context.Context, event *core.Git
 		PRNumber:      event.PRNumber,
 		HeadSHA:       event.HeadSHA,
 		ReviewContent: reReviewContent,
+		TreeSHA:       reviewEnv.updateResult.TreeSHA,
+		PromptVersion: structuredReview.PromptVersion,
 	}
 	if err = j.store.SaveReview(ctx, dbReview); err != nil {
-		j.logger.Warn("failed to save re-review to database (failing to avoid inconsistent state)", "error", err)
+		j.logger.WarnContext(ctx, "failed to save re-review to database (failing to avoid inconsistent state)", "error", err)
 		return fmt.Errorf("failed to save re-review: %w", err)
 	}
+	j.archiveInputs(ctx, dbReview.ID, structuredReview.Inputs)
 
 	return reviewEnv.statusUpdater.Completed(ctx, event, reviewEnv.checkRunID, "success", "Re-Review Complete", "Follow-up analysis finished.")
 }
@@ -436,6 +613,8 @@ func (j *ReviewJob) executeReviewWorkflow(ctx context.Context, event *core.GitHu
 	if err != nil {
 		return err
 	}
+	defer reviewEnv.release()
+	ctx = core.WithReviewProgress(ctx, reviewEnv.progress)
 	defer func() {
 		if err != nil {
 			j.updateStatusOnError(ctx, reviewEnv.statusUpdater, event, reviewEnv.checkRunID, err)
@@ -448,7 +627,7 @@ func (j *ReviewJob) executeReviewWorkflow(ctx context.Context, event *core.GitHu
 		// Mark check run as completed so the PR status doesn't stay pending
 		if err := reviewEnv.statusUpdater.Completed(ctx, event, reviewEnv.checkRunID,
 			"success", "Review Already Exists", "This commit was already reviewed."); err != nil {
-			j.logger.Warn("failed to mark check run as completed for skipped review",
+			j.logger.WarnContext(ctx, "failed to mark check run as completed for skipped review",
 				"error", err, "repo", event.RepoFullName, "pr", event.PRNumber)
 		}
 		return nil
@@ -458,18 +637,38 @@ func (j *ReviewJob) executeReviewWorkflow(ctx context.Context, event *core.GitHu
 	if err != nil {
 		return err
 	}
+	// A split review returns the groups that finished before the job was
+	// stopped; they are reported with the cancellation instead of posted.
+	if ctx.Err() != nil {
+		return &partialReviewError{review: structuredReview, err: context.Cause(ctx)}
+	}
 
 	return j.completeReview(ctx, event, reviewEnv, structuredReview, rawReview, validFiles)
 }
 
+// partialReviewError is returned when a review job is stopped after part of
+// the review was generated.
+type partialReviewError struct {
+	review *core.StructuredReview
+	err    error
+}
+
+func (e *partialReviewError) Error() string { return e.err.Error() }
+func (e *partialReviewError) Unwrap() error { return e.err }
+
 type reviewEnvironment struct {
 	ghClient      github.Client
+	ghToken       string
 	repo          *storage.Repository
 	statusUpdater github.StatusUpdater
 	checkRunID    int64
 	updateResult  *core.UpdateResult
 	repoConfig    *core.RepoConfig
 	skipReview    bool // Set to true if review should be skipped (duplicate SHA)
+	// release removes the snapshot that repo points to, if any.
+	release func()
+	// progress shows the stages of the review on its check run.
+	progress core.ReviewProgressFunc
 }
 
 // setupReviewEnvironment initializes clients, syncs the repo to the default branch,
@@ -481,6 +680,8 @@ func (j *ReviewJob) setupReviewEnvironment(ctx context.Context, event *core.GitH
 	if err != nil {
 		return nil, err
 	}
+	progress := newCheckRunProgress(ctx, statusUpdater, event, checkRunID, j.logger).report
+	ctx = core.WithReviewProgress(ctx, progress)
 
 	// ── Mutex: protect only the Git sync + optional Qdrant update phase ──────
 	// The lock is acquired here and released at the end of this function.
@@ -488,6 +689,7 @@ func (j *ReviewJob) setupReviewEnvironment(ctx context.Context, event *core.GitH
 	mutex := j.getRepoMutex(event.RepoFullName)
 	mutex.Lock()
 
+	core.ReportReviewStage(ctx, core.StageSyncing, 0, 0)
 	updateResult, syncErr := j.repoMgr.SyncRepo(ctx, event, ghToken)
 	if syncErr != nil {
 		mutex.Unlock() // release before error return
@@ -504,16 +706,28 @@ func (j *ReviewJob) setupReviewEnvironment(ctx context.Context, event *core.GitH
 		return nil, repoErr
 	}
 
+	repoConfig := j.loadAndProcessRepoConfig(ctx, ghClient, event, updateResult.RepoPath)
+	if err := j.enforceLocalOnly(repoConfig); err != nil {
+		mutex.Unlock()
+		j.updateStatusOnError(ctx, statusUpdater, event, checkRunID, err)
+		return nil, err
+	}
+
 	// Update vector store only when the default branch has new commits.
 	// PR diffs are NEVER written to Qdrant; they are passed in-memory to the LLM.
 	if updateResult.IsInitialClone || updateResult.DefaultBranchChanged {
-		if vsErr := j.updateVectorStoreAndSHA(ctx, j.loadAndProcessRepoConfig(updateResult.RepoPath, event.RepoFullName), repo, updateResult); vsErr != nil {
-			mutex.Unlock()
-			j.updateStatusOnError(ctx, statusUpdater, event, checkRunID, vsErr)
-			return nil, vsErr
+		if vsErr := j.updateVectorStoreAndSHA(ctx, repoConfig, repo, updateResult); vsErr != nil {
+			if !j.aiConfig().DegradedReviews {
+				mutex.Unlock()
+				j.updateStatusOnError(ctx, statusUpdater, event, checkRunID, vsErr)
+				return nil, vsErr
+			}
+			// The SHA stays unchanged, so the next review retries the update.
+			j.logger.WarnContext(ctx, "failed to update repository index, reviewing off the existing index",
+				"error", vsErr, "repo", event.RepoFullName, "pr", event.PRNumber)
 		}
 	} else {
-		j.logger.Info("default branch unchanged — skipping Qdrant update, running review off existing index",
+		j.logger.InfoContext(ctx, "default branch unchanged — skipping Qdrant update, running review off existing index",
 			"repo", event.RepoFullName,
 			"default_branch_sha", updateResult.DefaultBranchSHA,
 		)
@@ -526,31 +740,55 @@ func (j *ReviewJob) setupReviewEnvironment(ctx context.Context, event *core.GitH
 	if event.Type == core.FullReview {
 		existing, err := j.store.GetLatestReviewForPR(ctx, event.RepoFullName, event.PRNumber)
 		if err != nil {
-			j.logger.Warn("failed to check for existing review", "error", err, "repo", event.RepoFullName, "pr", event.PRNumber)
+			j.logger.WarnContext(ctx, "failed to check for existing review", "error", err, "repo", event.RepoFullName, "pr", event.PRNumber)
 			// Continue with review on error - don't block reviews if DB check fails
 		} else if existing != nil && existing.HeadSHA == event.HeadSHA {
-			j.logger.Info("Skipping review — same SHA already reviewed (detected under mutex)",
+			j.logger.InfoContext(ctx, "Skipping review — same SHA already reviewed (detected under mutex)",
 				"repo", event.RepoFullName, "pr", event.PRNumber, "sha", event.HeadSHA)
 			skipReview = true
 		}
 	}
 
+	// ── Pin the synced files before the lock is released ────────────────────
+	// Later syncs may move the clone while this review still reads from it.
+	release := func() {}
+	if !skipReview && j.cfg.Storage.ReviewSnapshots {
+		repo, release = j.pinSnapshot(ctx, repo, updateResult)
+	}
+
 	// ── Release lock before any LLM call ─────────────────────────────────────
 	mutex.Unlock()
 
-	repoConfig := j.loadAndProcessRepoConfig(updateResult.RepoPath, event.RepoFullName)
-
 	return &reviewEnvironment{
 		ghClient:      ghClient,
+		ghToken:       ghToken,
 		repo:          repo,
 		statusUpdater: statusUpdater,
 		checkRunID:    checkRunID,
 		updateResult:  updateResult,
 		repoConfig:    repoConfig,
 		skipReview:    skipReview,
+		release:       release,
+		progress:      progress,
 	}, nil
 }
 
+// pinSnapshot returns a copy of repo that points to a worktree of the synced
+// commit, with the function that removes it. When the worktree cannot be
+// created, the review reads the clone.
+func (j *ReviewJob) pinSnapshot(ctx context.Context, repo *storage.Repository, updateResult *core.UpdateResult) (*storage.Repository, func()) {
+	path, release, err := j.repoMgr.PinSnapshot(ctx, updateResult)
+	if err != nil {
+		j.logger.WarnContext(ctx, "failed to pin repository snapshot, reviewing off the clone", "repo", repo.FullName, "error", err)
+		return repo, func() {}
+	}
+	j.logger.InfoContext(ctx, "reviewing pinned repository snapshot", "repo", repo.FullName,
+		"sha", updateResult.DefaultBranchSHA, "tree_sha", updateResult.TreeSHA)
+	pinned := *repo
+	pinned.ClonePath = path
+	return &pinned, release
+}
+
 // processRepository fetches the PR diff and changed files from GitHub, validates them,
 // and runs the LLM-based review. The Qdrant index is NOT modified here.
 func (j *ReviewJob) processRepository(ctx context.Context, event *core.GitHubEvent, env *reviewEnvironment) (*core.StructuredReview, string, map[string]map[int]struct{}, error) {
@@ -568,23 +806,34 @@ func (j *ReviewJob) processRepository(ctx context.Context, event *core.GitHubEve
 	if commits, cErr := env.ghClient.GetPullRequestCommits(ctx, event.RepoOwner, event.RepoName, event.PRNumber); cErr == nil {
 		event.CommitMessages = commits
 	} else {
-		j.logger.Warn("failed to fetch commit messages, review will proceed without them", "error", cErr)
+		j.logger.WarnContext(ctx, "failed to fetch commit messages, review will proceed without them", "error", cErr)
 	}
+	event.StaticAnalysis = j.runStaticAnalysis(ctx, event, env, changedFiles)
 
 	validLineMaps := make(map[string]map[int]struct{})
 	for _, f := range changedFiles {
 		lines, err := github.ParseValidLinesFromPatch(f.Patch, j.logger)
 		if err != nil {
-			j.logger.Error("failed to parse valid lines from patch", "file", f.Filename, "error", err)
+			j.logger.ErrorContext(ctx, "failed to parse valid lines from patch", "file", f.Filename, "error", err)
 			continue
 		}
 		validLineMaps[f.Filename] = lines
 	}
 
+	ai := j.aiConfig()
 	executor := reviewpkg.NewExecutor(j.ragService, reviewpkg.Config{
-		ComparisonModels: j.cfg.AI.ComparisonModels,
+		ComparisonModels: ai.ConsensusModelsFor(env.repoConfig),
 		ReviewsDir:       j.cfg.AI.ReviewsDir,
-		Logger:           j.logger,
+		MaxDiffTokens:    ai.MaxDiffTokens,
+		MaxDiffKB:        j.cfg.Limits.MaxDiffKB,
+		SplitConcurrency: ai.SplitReviewConcurrency,
+		Progress: func(done, total int, group string) {
+			summary := fmt.Sprintf("Large pull request: reviewed %d of %d groups (last: %s).", done, total, group)
+			if err := env.statusUpdater.Progress(ctx, event, env.checkRunID, "Reviewing in groups", summary); err != nil {
+				j.logger.WarnContext(ctx, "failed to update check run progress", "error", err, "repo", event.RepoFullName, "pr", event.PRNumber)
+			}
+		},
+		Logger: j.logger,
 	})
 
 	result, err := executor.Execute(ctx, reviewpkg.Params{
@@ -607,8 +856,18 @@ func (j *ReviewJob) completeReview(ctx context.Context, event *core.GitHubEvent,
 	// Filter out non-code file suggestions first
 	structuredReview.Suggestions = FilterNonCodeSuggestions(j.logger, structuredReview.Suggestions)
 
+	// On new commits only the new findings are posted; the others are listed
+	// as still open or resolved.
+	if event.Type == core.FullReview {
+		structuredReview.Delta = j.reviewDelta(ctx, event, structuredReview)
+	}
+
 	// Validate and filter suggestions to prevent 422 errors
 	inlineSuggestions, offDiffSuggestions := ValidateSuggestionsByLine(j.logger, structuredReview.Suggestions, validLineMaps)
+	if structuredReview.Delta != nil {
+		inlineSuggestions = reviewpkg.OnlyNew(structuredReview.Delta, inlineSuggestions)
+		offDiffSuggestions = reviewpkg.OnlyNew(structuredReview.Delta, offDiffSuggestions)
+	}
 	structuredReview.Suggestions = inlineSuggestions
 
 	// If there are off-diff suggestions, append them to the summary in a collapsible section
@@ -616,41 +875,125 @@ func (j *ReviewJob) completeReview(ctx context.Context, event *core.GitHubEvent,
 		structuredReview.Summary = appendOffDiffSuggestions(structuredReview.Summary, offDiffSuggestions)
 	}
 
-	// Save to DB first - the unique constraint (repo_full_name, pr_number, head_sha) prevents duplicates.
-	// If another concurrent webhook already saved a review for this SHA, we get ErrDuplicateReview.
+	// Security reviews are supplementary and are not saved as the review of
+	// the commit, so they neither block a later /review of the same SHA nor
+	// become the baseline of /rereview.
+	if event.Type != core.SecurityReview {
+		if saved, err := j.saveReview(ctx, event, env, rawReview, structuredReview); err != nil || !saved {
+			return err
+		}
+	}
+
+	// Only post to GitHub after successful DB save (prevents duplicate comments)
+	core.ReportReviewStage(ctx, core.StagePosting, 0, 0)
+	if err := env.statusUpdater.PostStructuredReview(ctx, event, structuredReview); err != nil {
+		return fmt.Errorf("failed to post review comment to GitHub: %w", err)
+	}
+
+	// Comments of earlier reviews on lines that left the diff only add noise.
+	if j.cfg.GitHub.Comments.HideOutdated && event.Type == core.FullReview {
+		if botLogin, err := github.AppLogin(ctx, j.cfg); err != nil {
+			j.logger.WarnContext(ctx, "failed to look up the app login, not hiding outdated review comments", "error", err)
+		} else if hidden, err := github.HideOutdatedComments(ctx, env.ghClient, event, botLogin, validLineMaps, j.logger); err != nil {
+			j.logger.WarnContext(ctx, "failed to hide outdated review comments", "error", err, "repo", event.RepoFullName, "pr", event.PRNumber)
+		} else if hidden > 0 {
+			j.logger.InfoContext(ctx, "hid outdated review comments", "count", hidden, "repo", event.RepoFullName, "pr", event.PRNumber)
+		}
+	}
+
+	// A degraded review completes as neutral, so the missing context shows on
+	// the pull request without failing it.
+	conclusion, completedSummary := "success", "AI analysis finished."
+	if structuredReview.Degraded {
+		conclusion = "neutral"
+		completedSummary = "AI analysis finished without repository context because the code index was unavailable. " +
+			"Findings that depend on code outside the diff may be missing; run `/review` again once the index is back."
+	}
+	if err := env.statusUpdater.Completed(ctx, event, env.checkRunID, conclusion, "Review Complete", completedSummary); err != nil {
+		return fmt.Errorf("failed to update completion status on GitHub: %w", err)
+	}
+
+	j.notifier.ReviewCompleted(ctx, event, structuredReview)
+
+	j.logger.InfoContext(ctx, "Full review job completed successfully")
+	return nil
+}
+
+// reviewDelta compares review with the previous review of the pull request,
+// which is of an earlier commit. It returns nil when the pull request has no
+// previous review or it cannot be loaded.
+func (j *ReviewJob) reviewDelta(ctx context.Context, event *core.GitHubEvent, review *core.StructuredReview) *core.ReviewDelta {
+	previous, err := j.store.GetLatestReviewForPR(ctx, event.RepoFullName, event.PRNumber)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		j.logger.WarnContext(ctx, "failed to load previous review, posting all findings", "error", err, "repo", event.RepoFullName, "pr", event.PRNumber)
+		return nil
+	}
+	if previous == nil || previous.HeadSHA == event.HeadSHA {
+		return nil
+	}
+	delta := reviewpkg.Delta(reviewpkg.ParseSaved(ctx, j.logger, previous.ReviewContent), review, previous.HeadSHA)
+	j.logger.InfoContext(ctx, "compared review with previous review", "repo", event.RepoFullName, "pr", event.PRNumber,
+		"previous_sha", previous.HeadSHA, "new", len(delta.New), "open", len(delta.Open), "resolved", len(delta.Resolved))
+	return delta
+}
+
+// saveReview saves the review of the event's commit before it is posted. The
+// unique constraint (repo_full_name, pr_number, head_sha) prevents duplicates:
+// if another concurrent webhook already saved a review for this SHA, the check
+// run is completed and false is returned, so the review is not posted twice.
+func (j *ReviewJob) saveReview(ctx context.Context, event *core.GitHubEvent, env *reviewEnvironment, rawReview string, review *core.StructuredReview) (bool, error) {
 	dbReview := &core.Review{
 		RepoFullName:  event.RepoFullName,
 		PRNumber:      event.PRNumber,
 		HeadSHA:       event.HeadSHA,
 		ReviewContent: rawReview,
+		Degraded:      review.Degraded,
+		TreeSHA:       env.updateResult.TreeSHA,
+		PromptVersion: review.PromptVersion,
 	}
 	err := j.store.SaveReview(ctx, dbReview)
 	if err != nil {
 		if errors.Is(err, storage.ErrDuplicateReview) {
 			// Another concurrent webhook already completed this review.
 			// We still need to mark the check run as complete, but skip posting duplicate comments.
-			j.logger.Info("Review already saved by concurrent webhook, skipping duplicate post",
+			j.logger.InfoContext(ctx, "Review already saved by concurrent webhook, skipping duplicate post",
 				"repo", event.RepoFullName, "pr", event.PRNumber, "sha", event.HeadSHA)
 			if completeErr := env.statusUpdater.Completed(ctx, event, env.checkRunID, "success", "Review Complete", "AI analysis finished."); completeErr != nil {
-				j.logger.Warn("failed to update completion status", "error", completeErr)
+				j.logger.WarnContext(ctx, "failed to update completion status", "error", completeErr)
 			}
-			return nil
+			return false, nil
 		}
-		j.logger.Error("failed to save review to database", "error", err)
-		return fmt.Errorf("failed to save review record to database: %w", err)
+		j.logger.ErrorContext(ctx, "failed to save review to database", "error", err)
+		return false, fmt.Errorf("failed to save review record to database: %w", err)
 	}
+	j.archiveInputs(ctx, dbReview.ID, review.Inputs)
+	return true, nil
+}
 
-	// Only post to GitHub after successful DB save (prevents duplicate comments)
-	if err := env.statusUpdater.PostStructuredReview(ctx, event, structuredReview); err != nil {
-		return fmt.Errorf("failed to post review comment to GitHub: %w", err)
+// archiveInputs stores the prompt inputs of a saved review for `review
+// regenerate` and deletes those past the retention period. Failures only
+// cost the ability to regenerate, so they are logged.
+func (j *ReviewJob) archiveInputs(ctx context.Context, reviewID int64, inputs []core.PromptInputs) {
+	ai := j.aiConfig()
+	if !ai.ArchiveReviewInputs || len(inputs) == 0 {
+		return
 	}
-
-	if err := env.statusUpdater.Completed(ctx, event, env.checkRunID, "success", "Review Complete", "AI analysis finished."); err != nil {
-		return fmt.Errorf("failed to update completion status on GitHub: %w", err)
+	if err := j.store.SaveReviewInputs(ctx, reviewID, inputs); err != nil {
+		j.logger.WarnContext(ctx, "failed to archive review inputs", "review_id", reviewID, "error", err)
+		return
+	}
+	if ai.ReviewInputsRetentionDays <= 0 {
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -ai.ReviewInputsRetentionDays)
+	if n, err := j.store.DeleteReviewInputsBefore(ctx, cutoff); err != nil {
+		j.logger.WarnContext(ctx, "failed to prune archived review inputs", "error", err)
+	} else if n > 0 {
+		j.logger.InfoContext(ctx, "pruned archived review inputs", "deleted", n)
 	}
-
-	j.logger.Info("Full review job completed successfully")
-	return nil
 }
 
 // appendOffDiffSuggestions adds off-diff suggestions to the summary in a collapsible section.
@@ -704,7 +1047,9 @@ func truncateTitle(title string, maxLen int) string {
 // It persists DefaultBranchSHA (not the PR HeadSHA) as LastIndexedSHA to keep
 // the Qdrant baseline aligned with main.
 func (j *ReviewJob) updateVectorStoreAndSHA(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, updateResult *core.UpdateResult) error {
-	if err := j.ragService.SyncRepoIndex(ctx, repoConfig, repo, updateResult, nil); err != nil {
+	// Within a review the progress shows on its check run.
+	progressFn := func(done, total int) { core.ReportReviewStage(ctx, core.StageIndexing, done, total) }
+	if err := j.ragService.SyncRepoIndex(ctx, repoConfig, repo, updateResult, progressFn); err != nil {
 		return fmt.Errorf("failed to sync repository index: %w", err)
 	}
 
@@ -714,13 +1059,13 @@ func (j *ReviewJob) updateVectorStoreAndSHA(ctx context.Context, repoConfig *cor
 	if shaToStore == "" {
 		// Defensive fallback — should not happen with the new sync logic
 		shaToStore = updateResult.HeadSHA
-		j.logger.Warn("DefaultBranchSHA was empty, falling back to HeadSHA for persistence",
+		j.logger.WarnContext(ctx, "DefaultBranchSHA was empty, falling back to HeadSHA for persistence",
 			"repo", repo.FullName,
 		)
 	}
 
 	if err := j.repoMgr.UpdateRepoSHA(ctx, repo.FullName, shaToStore); err != nil {
-		j.logger.Error("CRITICAL: Vector store updated but failed to persist new SHA in database.",
+		j.logger.ErrorContext(ctx, "CRITICAL: Vector store updated but failed to persist new SHA in database.",
 			"error", err, "repo", repo.FullName, "new_sha", shaToStore)
 		return fmt.Errorf("CRITICAL: failed to update last indexed SHA after vector store update: %w", err)
 	}
@@ -733,6 +1078,10 @@ func (j *ReviewJob) setupReview(ctx context.Context, event *core.GitHubEvent, ti
 	if err != nil {
 		return nil, "", nil, 0, fmt.Errorf("failed to create GitHub client: %w", err)
 	}
+	ghClient = j.trackCheckRuns(ghClient, event)
+	if err := j.checkPermissions(ctx, ghClient, event); err != nil {
+		return nil, "", nil, 0, err
+	}
 
 	pr, err := ghClient.GetPullRequest(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
 	if err != nil {
@@ -743,7 +1092,11 @@ func (j *ReviewJob) setupReview(ctx context.Context, event *core.GitHubEvent, ti
 	}
 	event.HeadSHA = pr.GetHead().GetSHA()
 
-	statusUpdater := github.NewStatusUpdater(ghClient, j.logger, j.cfg.AI.EnableCodeSuggestions)
+	var updaterOpts []github.StatusUpdaterOption
+	if j.cfg.GitHub.Comments.SummaryInPlace {
+		updaterOpts = append(updaterOpts, github.WithSummaryInPlace())
+	}
+	statusUpdater := github.NewStatusUpdater(ghClient, j.logger, j.cfg.AI.EnableCodeSuggestions, updaterOpts...)
 	checkRunID, err := statusUpdater.InProgress(ctx, event, title, summary)
 	if err != nil {
 		return nil, "", nil, 0, fmt.Errorf("failed to set in-progress status: %w", err)
@@ -752,13 +1105,75 @@ func (j *ReviewJob) setupReview(ctx context.Context, event *core.GitHubEvent, ti
 	return ghClient, ghToken, statusUpdater, checkRunID, nil
 }
 
+// updateStatusOnError concludes the check run of a failed job. Jobs stopped
+// by the dispatcher conclude as "timed_out" or "cancelled", with the part of
+// the review generated so far, if any.
 func (j *ReviewJob) updateStatusOnError(ctx context.Context, statusUpdater github.StatusUpdater, event *core.GitHubEvent, checkRunID int64, jobErr error) {
-	j.logger.Error("Review job step failed", "error", jobErr, "repo", event.RepoFullName)
-	if statusUpdater != nil && checkRunID > 0 {
-		if err := statusUpdater.Completed(ctx, event, checkRunID, "failure", "Review Failed", jobErr.Error()); err != nil {
-			j.logger.Error("Failed to update failure status on GitHub", "original_error", jobErr, "status_update_error", err)
+	j.logger.ErrorContext(ctx, "Review job step failed", "error", jobErr, "repo", event.RepoFullName)
+	if statusUpdater == nil || checkRunID <= 0 {
+		return
+	}
+	// The summary is public on the PR; wrapped errors can carry tokens or DSNs.
+	conclusion, title, summary := "failure", "Review Failed", redact.Error(jobErr).Error()
+	switch cause := context.Cause(ctx); {
+	case errors.Is(cause, core.ErrJobTimedOut):
+		conclusion, title = "timed_out", "Review Timed Out"
+		summary = fmt.Sprintf("The review did not finish within the maximum job duration (%s) and was stopped.", j.cfg.Server.JobTimeout)
+	case errors.Is(cause, core.ErrJobCancelled):
+		conclusion, title = "cancelled", "Review Cancelled"
+		summary = "The review was cancelled before it finished."
+	case errors.Is(jobErr, health.ErrCircuitOpen):
+		title = "Review Failed: Backend Unavailable"
+		summary = "A service the review depends on is failing, so the review was stopped instead of waiting for it. " +
+			"The service is probed again automatically; re-request the review once it has recovered.\n\n" + summary
+	}
+	var partial *partialReviewError
+	if errors.As(jobErr, &partial) && partial.review != nil {
+		summary = appendPartialReview(summary, partial.review)
+	}
+
+	// The job context is done when the job was stopped, but the check run
+	// must still be concluded.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusUpdateTimeout)
+	defer cancel()
+	if err := statusUpdater.Completed(ctx, event, checkRunID, conclusion, title, summary); err != nil {
+		j.logger.ErrorContext(ctx, "Failed to update failure status on GitHub", "original_error", jobErr, "status_update_error", err)
+	}
+}
+
+// statusUpdateTimeout bounds the check run update of a stopped job.
+const statusUpdateTimeout = 30 * time.Second
+
+// maxPartialSuggestions caps the findings listed in the check run of a
+// stopped review, and maxCheckRunSummary its length, below GitHub's limit of
+// 65535 characters.
+const (
+	maxPartialSuggestions = 20
+	maxCheckRunSummary    = 60000
+)
+
+// appendPartialReview adds the summary and findings of a partial review to a
+// check run summary.
+func appendPartialReview(summary string, review *core.StructuredReview) string {
+	var sb strings.Builder
+	sb.WriteString(summary)
+	sb.WriteString("\n\n### Partial results\n\n")
+	sb.WriteString(strings.TrimSpace(review.Summary))
+	sb.WriteString("\n")
+	for i, s := range review.Suggestions {
+		if i == maxPartialSuggestions {
+			fmt.Fprintf(&sb, "\n…and %d more finding(s).\n", len(review.Suggestions)-i)
+			break
+		}
+		if i == 0 {
+			sb.WriteString("\n")
 		}
+		fmt.Fprintf(&sb, "- **%s:%d** %s %s: %s\n", s.FilePath, s.LineNumber, github.SeverityEmoji(s.Severity), s.Severity, extractBriefTitle(s.Comment))
 	}
+	if sb.Len() > maxCheckRunSummary {
+		return strings.ToValidUTF8(sb.String()[:maxCheckRunSummary], "") + "\n\n…(truncated)"
+	}
+	return sb.String()
 }
 
 func (j *ReviewJob) validateInputs(event *core.GitHubEvent) error {
@@ -774,10 +1189,24 @@ func (j *ReviewJob) validateInputs(event *core.GitHubEvent) error {
 
 	// Validate based on event type
 	switch event.Type {
-	case core.FullReview, core.ReReview:
+	case core.FullReview, core.ReReview, core.SecurityReview, core.ExplainPR:
 		if event.PRNumber <= 0 {
 			return fmt.Errorf("pull request number must be positive for review, got: %d", event.PRNumber)
 		}
+	case core.RecordFeedback:
+		if event.PRNumber <= 0 {
+			return fmt.Errorf("pull request number must be positive for feedback, got: %d", event.PRNumber)
+		}
+		if event.FeedbackCommentID <= 0 {
+			return fmt.Errorf("feedback comment ID must be positive, got: %d", event.FeedbackCommentID)
+		}
+	case core.SuggestionReply:
+		if event.PRNumber <= 0 {
+			return fmt.Errorf("pull request number must be positive for reply, got: %d", event.PRNumber)
+		}
+		if event.ThreadCommentID <= 0 {
+			return fmt.Errorf("thread comment ID must be positive, got: %d", event.ThreadCommentID)
+		}
 	case core.ImplementIssue:
 		if event.IssueNumber <= 0 {
 			return fmt.Errorf("issue number must be positive for implement, got: %d", event.IssueNumber)
@@ -787,8 +1216,26 @@ func (j *ReviewJob) validateInputs(event *core.GitHubEvent) error {
 	return nil
 }
 
-func (j *ReviewJob) loadAndProcessRepoConfig(repoPath, repoFullName string) *core.RepoConfig {
-	return config.LoadRepoConfigWithDefaults(repoPath, repoFullName, j.logger)
+// loadAndProcessRepoConfig loads the repository's .code-warden.yml merged
+// over its organization defaults, if any.
+func (j *ReviewJob) loadAndProcessRepoConfig(ctx context.Context, ghClient github.Client, event *core.GitHubEvent, repoPath string) *core.RepoConfig {
+	orgDefaults := j.orgConfig.Get(ctx, ghClient, event.RepoOwner)
+	return config.LoadRepoConfigWithDefaults(repoPath, event.RepoFullName, orgDefaults, j.logger)
+}
+
+// enforceLocalOnly rejects repositories that opted into local-only inference
+// via .code-warden.yml while a configured provider would send code off the host.
+// The repository's own consensus_models are checked in place of the server's.
+func (j *ReviewJob) enforceLocalOnly(repoConfig *core.RepoConfig) error {
+	if !repoConfig.LocalOnly {
+		return nil
+	}
+	ai := j.aiConfig()
+	ai.ComparisonModels = ai.ConsensusModelsFor(repoConfig)
+	if err := ai.ValidateLocalOnly(); err != nil {
+		return fmt.Errorf("repository requires local-only inference: %w", err)
+	}
+	return nil
 }
 
 // firstNonEmpty returns the first non-empty string from the given strings.
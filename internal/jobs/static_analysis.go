@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/gitutil"
+	"github.com/sevigo/code-warden/internal/lint"
+	"github.com/sevigo/code-warden/internal/sandbox"
+)
+
+// runStaticAnalysis runs the configured linters on the head of the pull
+// request, checked out into a temporary worktree of the clone, and returns
+// their findings on the changed files. Failures are logged and leave the
+// review without lint findings.
+func (j *ReviewJob) runStaticAnalysis(ctx context.Context, event *core.GitHubEvent, env *reviewEnvironment, changedFiles []github.ChangedFile) []core.LintFinding {
+	if !j.cfg.StaticAnalysis.Enabled || env.repoConfig.DisableStaticAnalysis || event.HeadSHA == "" || env.updateResult == nil {
+		return nil
+	}
+	// The linters run repository code and configuration, so they execute in
+	// the configured sandbox like the agent's commands.
+	sb, err := sandbox.New(j.cfg.Sandbox)
+	if err != nil {
+		j.logger.WarnContext(ctx, "failed to create sandbox, reviewing without static analysis", "repo", event.RepoFullName, "error", err)
+		return nil
+	}
+	runner, err := lint.NewRunner(j.cfg.StaticAnalysis, sb, j.logger)
+	if err != nil {
+		j.logger.WarnContext(ctx, "failed to create linter runner, reviewing without static analysis", "repo", event.RepoFullName, "error", err)
+		return nil
+	}
+
+	dir, release, err := j.checkoutHead(ctx, event, env)
+	if err != nil {
+		j.logger.WarnContext(ctx, "failed to check out pull request head, reviewing without static analysis",
+			"repo", event.RepoFullName, "pr", event.PRNumber, "error", err)
+		return nil
+	}
+	defer release()
+
+	files := make([]string, len(changedFiles))
+	for i, f := range changedFiles {
+		files[i] = f.Filename
+	}
+	findings := runner.Run(ctx, dir, files)
+	j.logger.InfoContext(ctx, "static analysis finished", "repo", event.RepoFullName, "pr", event.PRNumber, "findings", len(findings))
+	return findings
+}
+
+// checkoutHead fetches the head of the pull request into the clone and
+// checks it out into a temporary worktree, leaving the clone's own worktree
+// on the default branch.
+func (j *ReviewJob) checkoutHead(ctx context.Context, event *core.GitHubEvent, env *reviewEnvironment) (string, func(), error) {
+	mutex := j.getRepoMutex(event.RepoFullName)
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	git := gitutil.NewClient(j.logger)
+	clonePath := env.updateResult.RepoPath
+	refSpec := fmt.Sprintf("+refs/pull/%d/head:refs/code-warden/pull/%d", event.PRNumber, event.PRNumber)
+	if err := git.Fetch(ctx, clonePath, env.ghToken, refSpec); err != nil {
+		return "", nil, err
+	}
+	return git.AddWorktree(ctx, clonePath, event.HeadSHA)
+}
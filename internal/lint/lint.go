@@ -0,0 +1,168 @@
+// Package lint runs static analysis tools on the checked-out head of a pull
+// request and turns their reports into findings for the review.
+package lint
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/sandbox"
+)
+
+// maxOutput caps the report read from a linter.
+const maxOutput = 16 << 20
+
+// Linter runs one static analysis tool and parses its report.
+type Linter interface {
+	// Name is the name of the tool, as used in static_analysis.linters.
+	Name() string
+	// Matches reports whether the linter checks the file at path.
+	Matches(path string) bool
+	// Command returns the command line that lints files, paths relative to
+	// the workspace. Its report must go to stdout.
+	Command(files []string) []string
+	// Parse reads the report of the command. File paths in the findings may
+	// be absolute; Run makes them relative to the workspace.
+	Parse(out []byte) ([]core.LintFinding, error)
+}
+
+// builtin holds the linters that static_analysis.linters can name.
+var builtin = map[string]Linter{
+	config.LinterGolangCI: golangCI{},
+	config.LinterESLint:   eslint{},
+	config.LinterRuff:     ruff{},
+}
+
+// Runner runs the configured linters in a sandbox.
+type Runner struct {
+	linters []Linter
+	sandbox sandbox.Runner
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// NewRunner returns a Runner for the linters of cfg that executes them
+// through sb.
+func NewRunner(cfg config.StaticAnalysisConfig, sb sandbox.Runner, logger *slog.Logger) (*Runner, error) {
+	r := &Runner{sandbox: sandbox.OrDirect(sb), timeout: cfg.Timeout, logger: logger}
+	for _, name := range cfg.Linters {
+		l, ok := builtin[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown linter %q", name)
+		}
+		r.linters = append(r.linters, l)
+	}
+	return r, nil
+}
+
+// Run lints the changed files in dir, the checked-out head of the pull
+// request, and returns the findings on them, ordered by file and line and
+// without duplicates. A linter that fails or is not installed is logged and
+// skipped, so Run never fails the review.
+func (r *Runner) Run(ctx context.Context, dir string, changedFiles []string) []core.LintFinding {
+	var findings []core.LintFinding
+	for _, l := range r.linters {
+		files := slices.DeleteFunc(slices.Clone(changedFiles), func(f string) bool { return !l.Matches(f) })
+		if len(files) == 0 {
+			continue
+		}
+		found, err := r.run(ctx, l, dir, files)
+		if err != nil {
+			r.logger.Warn("linter failed, reviewing without its findings", "linter", l.Name(), "error", err)
+			continue
+		}
+		r.logger.Info("linter finished", "linter", l.Name(), "files", len(files), "findings", len(found))
+		findings = append(findings, found...)
+	}
+	return dedupe(findings)
+}
+
+// run runs l on files and keeps the findings on them.
+func (r *Runner) run(ctx context.Context, l Linter, dir string, files []string) ([]core.LintFinding, error) {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+	argv := l.Command(files)
+	cmd := r.sandbox.Command(ctx, dir, argv[0], argv[1:]...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &limitedBuffer{buf: &stdout, limit: maxOutput}
+	cmd.Stderr = &limitedBuffer{buf: &stderr, limit: 64 << 10}
+	runErr := cmd.Run()
+	// Linters exit non-zero when they find problems: only an empty or
+	// unreadable report is a failure.
+	if stdout.Len() == 0 {
+		if runErr != nil {
+			return nil, fmt.Errorf("%w: %s", runErr, strings.TrimSpace(stderr.String()))
+		}
+		return nil, nil
+	}
+	found, err := l.Parse(stdout.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report: %w", err)
+	}
+
+	kept := found[:0]
+	for _, f := range found {
+		rel, ok := matchFile(f.FilePath, files)
+		if !ok || f.Line <= 0 {
+			continue
+		}
+		f.FilePath = rel
+		f.Tool = l.Name()
+		kept = append(kept, f)
+	}
+	return kept, nil
+}
+
+// matchFile returns the changed file that p, a path relative to the
+// workspace or an absolute one inside it or inside a container, refers to.
+func matchFile(p string, files []string) (string, bool) {
+	p = path.Clean(strings.ReplaceAll(p, "\\", "/"))
+	p = strings.TrimPrefix(p, "./")
+	for _, f := range files {
+		if p == f || strings.HasSuffix(p, "/"+f) {
+			return f, true
+		}
+	}
+	return "", false
+}
+
+// dedupe sorts findings by file, line and tool and drops those that report
+// the same rule on the same line twice.
+func dedupe(findings []core.LintFinding) []core.LintFinding {
+	slices.SortStableFunc(findings, func(a, b core.LintFinding) int {
+		if c := strings.Compare(a.FilePath, b.FilePath); c != 0 {
+			return c
+		}
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return strings.Compare(a.Tool, b.Tool)
+	})
+	return slices.CompactFunc(findings, func(a, b core.LintFinding) bool {
+		return a.FilePath == b.FilePath && a.Line == b.Line && a.Tool == b.Tool && a.Rule == b.Rule && a.Message == b.Message
+	})
+}
+
+// limitedBuffer discards writes past limit instead of growing without bound.
+type limitedBuffer struct {
+	buf   *bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
@@ -0,0 +1,97 @@
+package lint
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/sandbox"
+)
+
+// reportLinter "lints" by printing the report file in the workspace.
+type reportLinter struct{ name string }
+
+func (l reportLinter) Name() string                  { return l.name }
+func (reportLinter) Matches(p string) bool           { return strings.HasSuffix(p, ".go") }
+func (reportLinter) Command(files []string) []string { return []string{"cat", "report.json"} }
+func (reportLinter) Parse(out []byte) ([]core.LintFinding, error) {
+	return golangCI{}.Parse(out)
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	report := `{"Issues":[
+		{"FromLinter":"errcheck","Text":"Error return value is not checked","Pos":{"Filename":"/workspace/pkg/a.go","Line":7,"Column":2}},
+		{"FromLinter":"errcheck","Text":"Error return value is not checked","Pos":{"Filename":"pkg/a.go","Line":7,"Column":2}},
+		{"FromLinter":"unused","Text":"func helper is unused","Severity":"warning","Pos":{"Filename":"pkg/a.go","Line":3,"Column":6}},
+		{"FromLinter":"govet","Text":"unrelated","Pos":{"Filename":"pkg/other.go","Line":1,"Column":1}}
+	]}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "report.json"), []byte(report), 0o644))
+
+	r := &Runner{linters: []Linter{reportLinter{name: "fake"}, eslint{}}, sandbox: sandbox.Direct{}, logger: slog.New(slog.DiscardHandler)}
+	findings := r.Run(context.Background(), dir, []string{"pkg/a.go", "README.md"})
+
+	require.Len(t, findings, 2, "duplicates and unchanged files are dropped, eslint has no files")
+	assert.Equal(t, core.LintFinding{Tool: "fake", Rule: "unused", FilePath: "pkg/a.go", Line: 3, Column: 6, Severity: "Low", Message: "func helper is unused"}, findings[0])
+	assert.Equal(t, "pkg/a.go", findings[1].FilePath)
+	assert.Equal(t, 7, findings[1].Line)
+	assert.Equal(t, "fake:errcheck", findings[1].Origin())
+}
+
+func TestRunSkipsFailingLinter(t *testing.T) {
+	r := &Runner{linters: []Linter{reportLinter{name: "fake"}}, sandbox: sandbox.Direct{}, logger: slog.New(slog.DiscardHandler)}
+	assert.Empty(t, r.Run(context.Background(), t.TempDir(), []string{"main.go"}), "a missing report is skipped")
+}
+
+func TestNewRunner(t *testing.T) {
+	r, err := NewRunner(config.StaticAnalysisConfig{Linters: []string{config.LinterGolangCI, config.LinterRuff}}, nil, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	assert.Len(t, r.linters, 2)
+
+	_, err = NewRunner(config.StaticAnalysisConfig{Linters: []string{"pylint"}}, nil, slog.New(slog.DiscardHandler))
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	eslintReport := `[{"filePath":"/repo/web/app.ts","messages":[
+		{"ruleId":"no-unused-vars","severity":2,"message":"'x' is assigned a value but never used.","line":4,"column":7},
+		{"ruleId":"eqeqeq","severity":1,"message":"Expected '===' and instead saw '=='.","line":9,"column":11},
+		{"ruleId":null,"fatal":true,"severity":2,"message":"Parsing error","line":1,"column":1}
+	]}]`
+	findings, err := eslint{}.Parse([]byte(eslintReport))
+	require.NoError(t, err)
+	require.Len(t, findings, 2, "parse errors are not findings")
+	assert.Equal(t, "Medium", findings[0].Severity)
+	assert.Equal(t, "Low", findings[1].Severity)
+	assert.Equal(t, "eqeqeq", findings[1].Rule)
+
+	ruffReport := `[
+		{"code":"F401","message":"os imported but unused","filename":"/repo/app/main.py","location":{"row":1,"column":8}},
+		{"code":"E501","message":"Line too long (120 > 88)","filename":"/repo/app/main.py","location":{"row":12,"column":89}}
+	]`
+	findings, err = ruff{}.Parse([]byte(ruffReport))
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.Equal(t, core.LintFinding{Rule: "F401", FilePath: "/repo/app/main.py", Line: 1, Column: 8, Severity: "Medium", Message: "os imported but unused"}, findings[0])
+	assert.Equal(t, "Low", findings[1].Severity)
+
+	_, err = ruff{}.Parse([]byte("error: unexpected argument"))
+	assert.Error(t, err)
+}
+
+func TestCommand(t *testing.T) {
+	assert.Equal(t,
+		[]string{"golangci-lint", "run", "--output.json.path=stdout", "--show-stats=false", "--issues-exit-code=0", "./.", "./pkg/store"},
+		golangCI{}.Command([]string{"pkg/store/db.go", "main.go", "pkg/store/tx.go"}))
+	assert.Equal(t,
+		[]string{"ruff", "check", "--output-format", "json", "--exit-zero", "--force-exclude", "./-weird.py"},
+		ruff{}.Command([]string{"-weird.py"}))
+}
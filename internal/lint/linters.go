@@ -0,0 +1,183 @@
+package lint
+
+import (
+	"encoding/json"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// Severities of lint findings: errors are worth more attention than
+// warnings, but a linter never knows enough to call a finding High.
+const (
+	severityError   = "Medium"
+	severityWarning = "Low"
+)
+
+// relative prefixes files with "./" so that none is read as a flag.
+func relative(files []string) []string {
+	out := make([]string, len(files))
+	for i, f := range files {
+		out[i] = "./" + f
+	}
+	return out
+}
+
+// golangCI runs golangci-lint v2 on the packages of the changed Go files.
+type golangCI struct{}
+
+func (golangCI) Name() string { return config.LinterGolangCI }
+
+func (golangCI) Matches(p string) bool { return strings.HasSuffix(p, ".go") }
+
+func (golangCI) Command(files []string) []string {
+	var pkgs []string
+	for _, f := range files {
+		pkg := "./" + path.Dir(f)
+		if !slices.Contains(pkgs, pkg) {
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	slices.Sort(pkgs)
+	return append([]string{"golangci-lint", "run", "--output.json.path=stdout", "--show-stats=false", "--issues-exit-code=0"}, pkgs...)
+}
+
+func (golangCI) Parse(out []byte) ([]core.LintFinding, error) {
+	var report struct {
+		Issues []struct {
+			FromLinter string `json:"FromLinter"`
+			Text       string `json:"Text"`
+			Severity   string `json:"Severity"`
+			Pos        struct {
+				Filename string `json:"Filename"`
+				Line     int    `json:"Line"`
+				Column   int    `json:"Column"`
+			} `json:"Pos"`
+		} `json:"Issues"`
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		return nil, err
+	}
+	findings := make([]core.LintFinding, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		severity := severityError
+		if s := strings.ToLower(issue.Severity); s == "warning" || s == "info" || s == "low" {
+			severity = severityWarning
+		}
+		findings = append(findings, core.LintFinding{
+			Rule:     issue.FromLinter,
+			FilePath: issue.Pos.Filename,
+			Line:     issue.Pos.Line,
+			Column:   issue.Pos.Column,
+			Severity: severity,
+			Message:  issue.Text,
+		})
+	}
+	return findings, nil
+}
+
+// eslint runs ESLint with the repository's own configuration.
+type eslint struct{}
+
+func (eslint) Name() string { return config.LinterESLint }
+
+func (eslint) Matches(p string) bool {
+	switch path.Ext(p) {
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts":
+		return true
+	}
+	return false
+}
+
+func (eslint) Command(files []string) []string {
+	return append([]string{"eslint", "--format", "json", "--no-error-on-unmatched-pattern"}, relative(files)...)
+}
+
+func (eslint) Parse(out []byte) ([]core.LintFinding, error) {
+	var report []struct {
+		FilePath string `json:"filePath"`
+		Messages []struct {
+			RuleID   string `json:"ruleId"`
+			Severity int    `json:"severity"`
+			Message  string `json:"message"`
+			Line     int    `json:"line"`
+			Column   int    `json:"column"`
+			Fatal    bool   `json:"fatal"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		return nil, err
+	}
+	var findings []core.LintFinding
+	for _, file := range report {
+		for _, m := range file.Messages {
+			// Fatal messages are parse errors of files the repository's
+			// configuration does not cover, not findings.
+			if m.Fatal {
+				continue
+			}
+			severity := severityWarning
+			if m.Severity == 2 {
+				severity = severityError
+			}
+			findings = append(findings, core.LintFinding{
+				Rule:     m.RuleID,
+				FilePath: file.FilePath,
+				Line:     m.Line,
+				Column:   m.Column,
+				Severity: severity,
+				Message:  m.Message,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// ruff runs Ruff's linter with the repository's own configuration.
+type ruff struct{}
+
+func (ruff) Name() string { return config.LinterRuff }
+
+func (ruff) Matches(p string) bool {
+	return strings.HasSuffix(p, ".py") || strings.HasSuffix(p, ".pyi")
+}
+
+func (ruff) Command(files []string) []string {
+	return append([]string{"ruff", "check", "--output-format", "json", "--exit-zero", "--force-exclude"}, relative(files)...)
+}
+
+func (ruff) Parse(out []byte) ([]core.LintFinding, error) {
+	var report []struct {
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+		Filename string `json:"filename"`
+		Location struct {
+			Row    int `json:"row"`
+			Column int `json:"column"`
+		} `json:"location"`
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		return nil, err
+	}
+	findings := make([]core.LintFinding, 0, len(report))
+	for _, v := range report {
+		// Pyflakes (F) rules catch actual bugs such as undefined names;
+		// most others are about style.
+		severity := severityWarning
+		if strings.HasPrefix(v.Code, "F") {
+			severity = severityError
+		}
+		findings = append(findings, core.LintFinding{
+			Rule:     v.Code,
+			FilePath: v.Filename,
+			Line:     v.Location.Row,
+			Column:   v.Location.Column,
+			Severity: severity,
+			Message:  v.Message,
+		})
+	}
+	return findings, nil
+}
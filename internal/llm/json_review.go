@@ -0,0 +1,168 @@
+package llm
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// ReviewJSONSchema is the JSON Schema the model is asked to follow when
+// ai.review_output_format is "json". jsonReview and jsonSuggestion mirror
+// its properties.
+//
+//go:embed review.schema.json
+var ReviewJSONSchema string
+
+// ErrNoJSON is returned when the model output contains no JSON object.
+var ErrNoJSON = errors.New("no JSON object found in review output")
+
+var (
+	validVerdicts = map[string]string{
+		core.VerdictApprove:        core.VerdictApprove,
+		core.VerdictRequestChanges: core.VerdictRequestChanges,
+		core.VerdictComment:        core.VerdictComment,
+	}
+	validSeverities = map[string]string{
+		"critical": "Critical",
+		"high":     "High",
+		"medium":   "Medium",
+		"low":      "Low",
+	}
+)
+
+// jsonReview is the object described by ReviewJSONSchema. It has exactly
+// the schema's properties, so that decoding with DisallowUnknownFields
+// enforces its additionalProperties: false.
+type jsonReview struct {
+	Title       string           `json:"title"`
+	Verdict     string           `json:"verdict"`
+	Confidence  int              `json:"confidence"`
+	Summary     string           `json:"summary"`
+	Suggestions []jsonSuggestion `json:"suggestions"`
+}
+
+// jsonSuggestion is an item of the schema's suggestions array.
+type jsonSuggestion struct {
+	FilePath        string `json:"file_path"`
+	StartLine       int    `json:"start_line"`
+	LineNumber      int    `json:"line_number"`
+	Severity        string `json:"severity"`
+	Category        string `json:"category"`
+	CWE             string `json:"cwe"`
+	Confidence      int    `json:"confidence"`
+	Reproducibility string `json:"reproducibility"`
+	Source          string `json:"source"`
+	Comment         string `json:"comment"`
+	CodeSuggestion  string `json:"code_suggestion"`
+}
+
+func (r *jsonReview) structured() *core.StructuredReview {
+	review := &core.StructuredReview{
+		Title:       r.Title,
+		Verdict:     r.Verdict,
+		Confidence:  r.Confidence,
+		Summary:     r.Summary,
+		Suggestions: make([]core.Suggestion, 0, len(r.Suggestions)),
+	}
+	for _, s := range r.Suggestions {
+		review.Suggestions = append(review.Suggestions, core.Suggestion{
+			FilePath:        s.FilePath,
+			StartLine:       s.StartLine,
+			LineNumber:      s.LineNumber,
+			Severity:        s.Severity,
+			Category:        s.Category,
+			CWE:             s.CWE,
+			Confidence:      s.Confidence,
+			Reproducibility: s.Reproducibility,
+			Source:          s.Source,
+			Comment:         s.Comment,
+			CodeSuggestion:  s.CodeSuggestion,
+		})
+	}
+	return review
+}
+
+// ParseJSONReview decodes a JSON review and validates it against
+// ReviewJSONSchema. Markdown fences and prose around the object are
+// tolerated; fields the schema does not define are rejected. All other
+// schema violations are reported together in a single joined error.
+func ParseJSONReview(raw string) (*core.StructuredReview, error) {
+	text := StripMarkdownFence(strings.TrimSpace(raw))
+	start := strings.IndexByte(text, '{')
+	if start < 0 {
+		return nil, ErrNoJSON
+	}
+
+	var decoded jsonReview
+	dec := json.NewDecoder(strings.NewReader(text[start:]))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON review: %w", err)
+	}
+	review := decoded.structured()
+	if err := validateJSONReview(review); err != nil {
+		return nil, fmt.Errorf("JSON review does not match schema: %w", err)
+	}
+	return review, nil
+}
+
+// validateJSONReview checks review against the schema constraints and
+// normalizes enum casing in place.
+func validateJSONReview(review *core.StructuredReview) error {
+	var errs []error
+	addErr := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if v, ok := validVerdicts[strings.ToUpper(strings.TrimSpace(review.Verdict))]; ok {
+		review.Verdict = v
+	} else {
+		addErr("verdict: %q is not one of APPROVE, REQUEST_CHANGES, COMMENT", review.Verdict)
+	}
+	if review.Confidence < 0 || review.Confidence > 100 {
+		addErr("confidence: %d is outside 0-100", review.Confidence)
+	}
+	if strings.TrimSpace(review.Summary) == "" {
+		addErr("summary: must not be empty")
+	}
+
+	for i := range review.Suggestions {
+		s := &review.Suggestions[i]
+		field := func(name string) string { return fmt.Sprintf("suggestions[%d].%s", i, name) }
+
+		if clean := sanitizePath(s.FilePath); clean == "" {
+			addErr("%s: %q is not a relative repository path", field("file_path"), s.FilePath)
+		} else {
+			s.FilePath = clean
+		}
+		if s.LineNumber < 1 {
+			addErr("%s: must be >= 1", field("line_number"))
+		}
+		if s.StartLine < 0 || (s.StartLine > 0 && s.StartLine > s.LineNumber) {
+			addErr("%s: %d must be between 1 and line_number", field("start_line"), s.StartLine)
+		}
+		if sev, ok := validSeverities[strings.ToLower(strings.TrimSpace(s.Severity))]; ok {
+			s.Severity = sev
+		} else {
+			addErr("%s: %q is not one of Critical, High, Medium, Low", field("severity"), s.Severity)
+		}
+		if strings.TrimSpace(s.Category) == "" {
+			addErr("%s: must not be empty", field("category"))
+		}
+		if strings.TrimSpace(s.Comment) == "" {
+			addErr("%s: must not be empty", field("comment"))
+		}
+		if strings.TrimSpace(s.Source) == "" {
+			addErr("%s: must not be empty", field("source"))
+		}
+		if s.Confidence < 0 || s.Confidence > 100 {
+			addErr("%s: %d is outside 0-100", field("confidence"), s.Confidence)
+		}
+	}
+
+	return errors.Join(errs...)
+}
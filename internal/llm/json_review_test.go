@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validJSONReview = `{
+  "verdict": "request_changes",
+  "confidence": 90,
+  "summary": "# REVIEW SUMMARY\nOne bug.",
+  "suggestions": [
+    {
+      "file_path": "internal/foo.go",
+      "start_line": 10,
+      "line_number": 12,
+      "severity": "high",
+      "category": "Bug",
+      "confidence": 85,
+      "source": "diff:L10",
+      "comment": "**Observation:** nil deref",
+      "code_suggestion": "if x == nil {\n\treturn nil\n}"
+    }
+  ]
+}`
+
+func TestParseJSONReview(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantErrs  []string
+		wantCount int
+	}{
+		{name: "plain object", input: validJSONReview, wantCount: 1},
+		{name: "fenced with prose", input: "```json\n" + validJSONReview + "\n```", wantCount: 1},
+		{name: "trailing prose", input: validJSONReview + "\n\nHope this helps!", wantCount: 1},
+		{name: "no JSON", input: "<review><summary>x</summary></review>", wantErrs: []string{"no JSON object"}},
+		{name: "truncated", input: validJSONReview[:80], wantErrs: []string{"failed to decode"}},
+		{
+			name:     "unknown field",
+			input:    strings.Replace(validJSONReview, `"verdict"`, `"model": "gpt", "verdict"`, 1),
+			wantErrs: []string{`unknown field "model"`},
+		},
+		{
+			name:     "unknown suggestion field",
+			input:    strings.Replace(validJSONReview, `"category": "Bug"`, `"category": "Bug", "origin": "golangci-lint"`, 1),
+			wantErrs: []string{`unknown field "origin"`},
+		},
+		{
+			name: "schema violations are aggregated",
+			input: `{"verdict": "LGTM", "confidence": 140, "summary": "", "suggestions": [
+				{"file_path": "../etc/passwd", "line_number": 0, "severity": "Urgent", "category": "", "comment": "", "source": ""}
+			]}`,
+			wantErrs: []string{
+				"verdict:",
+				"confidence: 140",
+				"summary: must not be empty",
+				"suggestions[0].file_path",
+				"suggestions[0].line_number",
+				"suggestions[0].severity",
+				"suggestions[0].category",
+				"suggestions[0].comment",
+				"suggestions[0].source",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			review, err := ParseJSONReview(tt.input)
+			if len(tt.wantErrs) > 0 {
+				require.Error(t, err)
+				for _, want := range tt.wantErrs {
+					assert.Contains(t, err.Error(), want)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, review.Suggestions, tt.wantCount)
+			assert.Equal(t, "REQUEST_CHANGES", review.Verdict)
+			assert.Equal(t, "High", review.Suggestions[0].Severity)
+			assert.Equal(t, 10, review.Suggestions[0].StartLine)
+			assert.Equal(t, 12, review.Suggestions[0].LineNumber)
+			assert.True(t, strings.HasPrefix(review.Suggestions[0].CodeSuggestion, "if x == nil"))
+		})
+	}
+}
+
+func TestReviewJSONSchema_IsValidJSON(t *testing.T) {
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(ReviewJSONSchema), &schema))
+	assert.Equal(t, "object", schema["type"])
+}
+
+func TestCodeReviewPrompt_OutputFormat(t *testing.T) {
+	pm, err := NewPromptManager()
+	require.NoError(t, err)
+
+	for _, key := range []PromptKey{CodeReviewPrompt, SecurityReviewPrompt} {
+		xmlPrompt, err := pm.Render(key, map[string]string{})
+		require.NoError(t, err)
+		assert.Contains(t, xmlPrompt, "<review>", key)
+		assert.NotContains(t, xmlPrompt, "OUTPUT FORMAT (JSON)", key)
+
+		jsonPrompt, err := pm.Render(key, map[string]string{"OutputFormat": "json", "ReviewSchema": ReviewJSONSchema})
+		require.NoError(t, err)
+		assert.Contains(t, jsonPrompt, "OUTPUT FORMAT (JSON)", key)
+		assert.Contains(t, jsonPrompt, `"line_number"`, key)
+		assert.NotContains(t, jsonPrompt, "XML GENERATION PROTOCOL", key)
+	}
+}
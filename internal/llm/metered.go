@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/sevigo/goframe/llms"
+	"github.com/sevigo/goframe/schema"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// UsageRecorder receives the token usage of every LLM call made through a
+// [MeteredModel]. The repository and step of the call are taken from ctx.
+type UsageRecorder func(ctx context.Context, usage core.LLMUsage)
+
+// MeteredModel is an llms.Model that reports the token usage of each call.
+type MeteredModel struct {
+	name   string
+	model  llms.Model
+	record UsageRecorder
+}
+
+// NewMeteredModel wraps model so that record receives the usage of its
+// calls. Without a recorder, model is returned unchanged.
+func NewMeteredModel(name string, model llms.Model, record UsageRecorder) llms.Model {
+	if record == nil {
+		return model
+	}
+	return &MeteredModel{name: name, model: model, record: record}
+}
+
+// GenerateContent implements llms.Model.
+func (m *MeteredModel) GenerateContent(ctx context.Context, messages []schema.MessageContent, options ...llms.CallOption) (*schema.ContentResponse, error) {
+	resp, err := m.model.GenerateContent(ctx, messages, options...)
+	if err != nil {
+		return resp, err
+	}
+	usage := usageOf(resp)
+	if usage.PromptTokens == 0 && usage.CompletionTokens == 0 {
+		usage = estimateUsage(messages, resp)
+	}
+	usage.RepoFullName, usage.Step = core.UsageScope(ctx)
+	usage.Model = m.name
+	m.record(ctx, usage)
+	return resp, nil
+}
+
+// Call implements llms.Model through GenerateContent, so that it is metered.
+func (m *MeteredModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// CountTokens implements llms.Tokenizer with the wrapped model's tokenizer,
+// or an estimate when it has none.
+func (m *MeteredModel) CountTokens(ctx context.Context, text string) (int, error) {
+	if t, ok := m.model.(llms.Tokenizer); ok {
+		return t.CountTokens(ctx, text)
+	}
+	return NewEstimatingTokenizer().CountTokens(ctx, text)
+}
+
+// usageOf reads the token counts the provider reported in the generation
+// info of resp. Providers that only report a total, like Gemini, have it
+// counted as prompt tokens.
+func usageOf(resp *schema.ContentResponse) core.LLMUsage {
+	var usage core.LLMUsage
+	if resp == nil {
+		return usage
+	}
+	for _, choice := range resp.Choices {
+		if choice == nil {
+			continue
+		}
+		prompt, completion := intInfo(choice.GenerationInfo, "PromptTokens"), intInfo(choice.GenerationInfo, "CompletionTokens")
+		if prompt == 0 && completion == 0 {
+			prompt = intInfo(choice.GenerationInfo, "TotalTokens")
+		}
+		usage.PromptTokens += prompt
+		usage.CompletionTokens += completion
+	}
+	return usage
+}
+
+func intInfo(info map[string]any, key string) int {
+	switch v := info[key].(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+// estimateUsage estimates the token counts of a call from its text.
+func estimateUsage(messages []schema.MessageContent, resp *schema.ContentResponse) core.LLMUsage {
+	tokenizer := NewEstimatingTokenizer()
+	usage := core.LLMUsage{Estimated: true}
+	for _, msg := range messages {
+		n, _ := tokenizer.CountTokens(context.Background(), msg.String())
+		usage.PromptTokens += n
+	}
+	if resp != nil {
+		for _, choice := range resp.Choices {
+			if choice != nil {
+				n, _ := tokenizer.CountTokens(context.Background(), choice.Content)
+				usage.CompletionTokens += n
+			}
+		}
+	}
+	return usage
+}
@@ -0,0 +1,56 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sevigo/goframe/llms"
+	"github.com/sevigo/goframe/schema"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// infoModel replies with the given generation info, like a provider that
+// reports token counts.
+type infoModel struct{ info map[string]any }
+
+func (m infoModel) GenerateContent(context.Context, []schema.MessageContent, ...llms.CallOption) (*schema.ContentResponse, error) {
+	return &schema.ContentResponse{Choices: []*schema.ContentChoice{{Content: "ok", GenerationInfo: m.info}}}, nil
+}
+
+func (m infoModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+func TestMeteredModel(t *testing.T) {
+	var got []core.LLMUsage
+	record := func(_ context.Context, usage core.LLMUsage) { got = append(got, usage) }
+
+	ctx := core.WithUsageStep(core.WithUsageRepo(context.Background(), "owner/app"), core.UsageStepHyDE)
+	m := NewMeteredModel("qwen", infoModel{info: map[string]any{"PromptTokens": 120, "CompletionTokens": 30}}, record)
+	if _, err := m.Call(ctx, "hi"); err != nil {
+		t.Fatalf("Call() error = %v", err)
+	}
+	want := core.LLMUsage{RepoFullName: "owner/app", Step: core.UsageStepHyDE, Model: "qwen", PromptTokens: 120, CompletionTokens: 30}
+	if len(got) != 1 || got[0] != want {
+		t.Fatalf("recorded %+v, want %+v", got, want)
+	}
+
+	// Providers that report only a total have it counted as prompt tokens.
+	m = NewMeteredModel("gemini", infoModel{info: map[string]any{"TotalTokens": int32(90)}}, record)
+	_, _ = m.Call(context.Background(), "hi")
+	if u := got[1]; u.PromptTokens != 90 || u.CompletionTokens != 0 || u.Estimated {
+		t.Errorf("recorded %+v, want 90 prompt tokens", u)
+	}
+
+	// Without counts the tokens are estimated from the text.
+	m = NewMeteredModel("plain", fakeModel{reply: "a reply of some length"}, record)
+	_, _ = m.Call(context.Background(), "a prompt of some length")
+	if u := got[2]; !u.Estimated || u.PromptTokens == 0 || u.CompletionTokens == 0 {
+		t.Errorf("recorded %+v, want estimated counts", u)
+	}
+
+	if plain := (fakeModel{}); NewMeteredModel("plain", plain, nil) != llms.Model(plain) {
+		t.Error("NewMeteredModel() without a recorder should return the model unchanged")
+	}
+}
@@ -0,0 +1,287 @@
+package llm
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sevigo/goframe/llms"
+	"github.com/sevigo/goframe/schema"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// ErrModerated is the error of calls rejected by the moderation middleware.
+var ErrModerated = errors.New("llm call blocked by content moderation")
+
+// Middleware wraps the model named name with behavior around its calls.
+type Middleware func(name string, next llms.Model) llms.Model
+
+// Pipeline is a middleware chain, outermost first, applied to every model
+// the server calls.
+type Pipeline []Middleware
+
+// NewPipeline builds the chain declared by cfg. Token usage is recorded
+// innermost when record is set.
+func NewPipeline(cfg config.LLMMiddlewareConfig, record UsageRecorder, logger *slog.Logger) (Pipeline, error) {
+	var p Pipeline
+	for _, name := range cfg.Chain {
+		switch name {
+		case config.MiddlewareLogging:
+			p = append(p, Logging(logger))
+		case config.MiddlewareCache:
+			p = append(p, Cache(cfg.Cache.TTL, cfg.Cache.MaxEntries))
+		case config.MiddlewareModeration:
+			mw, err := Moderation(cfg.Moderation.BlockedPatterns)
+			if err != nil {
+				return nil, err
+			}
+			p = append(p, mw)
+		default:
+			return nil, fmt.Errorf("unknown llm middleware %q", name)
+		}
+	}
+	if record != nil {
+		p = append(p, Metering(record))
+	}
+	return p, nil
+}
+
+// Wrap applies the chain to model.
+func (p Pipeline) Wrap(name string, model llms.Model) llms.Model {
+	for i := len(p) - 1; i >= 0; i-- {
+		model = p[i](name, model)
+	}
+	return model
+}
+
+// Metering records the token usage of every call; see [MeteredModel].
+func Metering(record UsageRecorder) Middleware {
+	return func(name string, next llms.Model) llms.Model {
+		return NewMeteredModel(name, next, record)
+	}
+}
+
+type generateFunc func(ctx context.Context, messages []schema.MessageContent, options ...llms.CallOption) (*schema.ContentResponse, error)
+
+// middlewareModel is the llms.Model of a middleware: calls go through
+// generate, which calls next, and tokens are counted by next.
+type middlewareModel struct {
+	next     llms.Model
+	generate generateFunc
+}
+
+// GenerateContent implements llms.Model.
+func (m *middlewareModel) GenerateContent(ctx context.Context, messages []schema.MessageContent, options ...llms.CallOption) (*schema.ContentResponse, error) {
+	return m.generate(ctx, messages, options...)
+}
+
+// Call implements llms.Model through GenerateContent, so that it passes
+// through the middleware.
+func (m *middlewareModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	return llms.GenerateFromSinglePrompt(ctx, m, prompt, options...)
+}
+
+// CountTokens implements llms.Tokenizer with the wrapped model's tokenizer,
+// or an estimate when it has none.
+func (m *middlewareModel) CountTokens(ctx context.Context, text string) (int, error) {
+	if t, ok := m.next.(llms.Tokenizer); ok {
+		return t.CountTokens(ctx, text)
+	}
+	return NewEstimatingTokenizer().CountTokens(ctx, text)
+}
+
+// maxLoggedText caps the prompt and response text in a log record.
+const maxLoggedText = 4000
+
+// Logging logs every request and response at debug level.
+func Logging(logger *slog.Logger) Middleware {
+	return func(name string, next llms.Model) llms.Model {
+		return &middlewareModel{next: next, generate: func(ctx context.Context, messages []schema.MessageContent, options ...llms.CallOption) (*schema.ContentResponse, error) {
+			logger.DebugContext(ctx, "llm request", "model", name, "messages", len(messages), "prompt", truncate(promptText(messages), maxLoggedText))
+			start := time.Now()
+			resp, err := next.GenerateContent(ctx, messages, options...)
+			if err != nil {
+				logger.DebugContext(ctx, "llm request failed", "model", name, "duration", time.Since(start), "error", err)
+				return resp, err
+			}
+			logger.DebugContext(ctx, "llm response", "model", name, "duration", time.Since(start), "response", truncate(responseText(resp), maxLoggedText))
+			return resp, nil
+		}}
+	}
+}
+
+// Moderation rejects calls whose prompt matches one of patterns, before it
+// is sent, and calls whose response does, with [ErrModerated].
+func Moderation(patterns []string) (Middleware, error) {
+	blocked := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid moderation pattern %q: %w", p, err)
+		}
+		blocked[i] = re
+	}
+	match := func(text string) *regexp.Regexp {
+		for _, re := range blocked {
+			if re.MatchString(text) {
+				return re
+			}
+		}
+		return nil
+	}
+	return func(name string, next llms.Model) llms.Model {
+		return &middlewareModel{next: next, generate: func(ctx context.Context, messages []schema.MessageContent, options ...llms.CallOption) (*schema.ContentResponse, error) {
+			if re := match(promptText(messages)); re != nil {
+				return nil, fmt.Errorf("%w: prompt to %s matches %q", ErrModerated, name, re)
+			}
+			resp, err := next.GenerateContent(ctx, messages, options...)
+			if err != nil {
+				return resp, err
+			}
+			if re := match(responseText(resp)); re != nil {
+				return nil, fmt.Errorf("%w: response of %s matches %q", ErrModerated, name, re)
+			}
+			return resp, nil
+		}}
+	}, nil
+}
+
+// Cache answers a call from memory when the same model was called with the
+// same messages and options within ttl, keeping up to maxEntries responses.
+// Streaming calls are never cached. The cache is shared by the models the
+// middleware wraps.
+func Cache(ttl time.Duration, maxEntries int) Middleware {
+	c := newResponseCache(ttl, maxEntries)
+	return func(name string, next llms.Model) llms.Model {
+		return &middlewareModel{next: next, generate: func(ctx context.Context, messages []schema.MessageContent, options ...llms.CallOption) (*schema.ContentResponse, error) {
+			key, ok := cacheKey(name, messages, options)
+			if !ok {
+				return next.GenerateContent(ctx, messages, options...)
+			}
+			if resp, ok := c.get(key); ok {
+				return resp, nil
+			}
+			resp, err := next.GenerateContent(ctx, messages, options...)
+			if err == nil && resp != nil {
+				c.put(key, resp)
+			}
+			return resp, err
+		}}
+	}
+}
+
+// cacheKey hashes a call, and reports false for calls that cannot be
+// cached.
+func cacheKey(name string, messages []schema.MessageContent, options []llms.CallOption) (string, bool) {
+	var opts llms.CallOptions
+	for _, o := range options {
+		o(&opts)
+	}
+	if opts.StreamingFunc != nil {
+		return "", false
+	}
+	data, err := json.Marshal(struct {
+		Model    string                  `json:"model"`
+		Messages []schema.MessageContent `json:"messages"`
+		Options  llms.CallOptions        `json:"options"`
+	}{name, messages, opts})
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// responseCache is an LRU cache of responses that expire after ttl.
+type responseCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List // of *cachedResponse, most recently used first
+}
+
+type cachedResponse struct {
+	key     string
+	resp    *schema.ContentResponse
+	expires time.Time
+}
+
+func newResponseCache(ttl time.Duration, maxEntries int) *responseCache {
+	return &responseCache{ttl: ttl, maxEntries: max(maxEntries, 1), entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+func (c *responseCache) get(key string) (*schema.ContentResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*cachedResponse)
+	if time.Now().After(entry.expires) {
+		c.lru.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return entry.resp, true
+}
+
+func (c *responseCache) put(key string, resp *schema.ContentResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.lru.Remove(el)
+	}
+	c.entries[key] = c.lru.PushFront(&cachedResponse{key: key, resp: resp, expires: time.Now().Add(c.ttl)})
+	for c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedResponse).key)
+	}
+}
+
+// promptText is the text of messages, one message per line.
+func promptText(messages []schema.MessageContent) string {
+	var sb strings.Builder
+	for i, msg := range messages {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(msg.String())
+	}
+	return sb.String()
+}
+
+// responseText is the content of the choices of resp.
+func responseText(resp *schema.ContentResponse) string {
+	if resp == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, choice := range resp.Choices {
+		if choice != nil {
+			sb.WriteString(choice.Content)
+		}
+	}
+	return sb.String()
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
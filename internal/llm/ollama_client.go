@@ -7,6 +7,8 @@ import (
 
 	"github.com/sevigo/goframe/httpclient"
 	"github.com/sevigo/goframe/llms/ollama"
+
+	"github.com/sevigo/code-warden/internal/netutil"
 )
 
 // OllamaClientConfig holds configuration for creating Ollama clients.
@@ -70,5 +72,5 @@ func buildHTTPClient(headerTimeout, requestTimeout time.Duration, logger *slog.L
 		clientCfg.Timeout = 0 // Disable overall timeout, let ResponseHeaderTimeout control
 	}
 
-	return httpclient.NewClient(clientCfg)
+	return netutil.Apply(httpclient.NewClient(clientCfg))
 }
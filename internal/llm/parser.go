@@ -2,12 +2,12 @@ package llm
 
 import (
 	"fmt"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"unicode"
 
 	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/pathutil"
 )
 
 // ParseLegacyMarkdownReview handles older formats without XML tags.
@@ -38,23 +38,20 @@ func sanitizePath(path string) string {
 	}
 
 	// Normalize separators to forward slashes for uniform handling
-	path = strings.ReplaceAll(path, "\\", "/")
+	path = pathutil.ToSlash(path)
 
-	// Reject absolute paths and Windows drive letters
-	if strings.HasPrefix(path, "/") || strings.HasPrefix(path, `.\`) ||
-		(len(path) > 1 && path[1] == ':') {
+	// Reject absolute paths, UNC paths and Windows drive letters
+	if pathutil.IsAbs(path) {
 		return ""
 	}
 
 	// Final validation after cleaning
-	cleaned := filepath.Clean(path)
-	if strings.HasPrefix(cleaned, "/") || strings.HasPrefix(cleaned, `\`) ||
-		strings.HasPrefix(cleaned, `.\`) || strings.HasPrefix(cleaned, "..") ||
-		strings.Contains(cleaned, "/..") || strings.Contains(cleaned, `\..`) {
+	cleaned := pathutil.Clean(path)
+	if pathutil.EscapesRoot(cleaned) {
 		return ""
 	}
 
-	return filepath.ToSlash(cleaned)
+	return cleaned
 }
 
 // parseLegacyMarkdownReview handles older formats without XML tags.
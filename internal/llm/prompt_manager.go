@@ -2,10 +2,16 @@ package llm
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"embed"
+	"encoding/binary"
+	"encoding/hex"
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"text/template"
 )
 
@@ -16,6 +22,7 @@ type PromptKey string
 
 const (
 	CodeReviewPrompt            PromptKey = "code_review"
+	SecurityReviewPrompt        PromptKey = "security_review"
 	CodeGenerationPrompt        PromptKey = "code_generation"
 	ReReviewPrompt              PromptKey = "rereview"
 	ArchSummaryPrompt           PromptKey = "arch_summary"
@@ -29,11 +36,58 @@ const (
 	ReuseVerificationPrompt     PromptKey = "reuse_verification"
 	ProjectContextPrompt        PromptKey = "project_context"
 	GapIdentificationPrompt     PromptKey = "gap_identification"
+	PRWalkthroughPrompt         PromptKey = "pr_walkthrough"
+	MissingTestsPrompt          PromptKey = "missing_tests"
+	ConversationSummaryPrompt   PromptKey = "conversation_summary"
+	SuggestionExplainPrompt     PromptKey = "suggestion_explain"
+	SuggestionFixPrompt         PromptKey = "suggestion_fix"
 )
 
+// experimentSuffix marks the key of the variant template of a prompt
+// experiment, e.g. "code_review@experiment".
+const experimentSuffix = "@experiment"
+
+// Base returns the prompt a key belongs to: the key itself, or the prompt
+// an experiment variant replaces.
+func (k PromptKey) Base() PromptKey {
+	return PromptKey(strings.TrimSuffix(string(k), experimentSuffix))
+}
+
+// IsExperiment reports whether the key is the variant of a prompt experiment.
+func (k PromptKey) IsExperiment() bool {
+	return strings.HasSuffix(string(k), experimentSuffix)
+}
+
+// Experiment renders a share of the uses of a prompt with a variant template,
+// so that the reviews of both can be compared.
+type Experiment struct {
+	Key PromptKey
+	// Source is the variant template.
+	Source string
+	// Percent of the units (see Choose) that get the variant, 0 to 100.
+	Percent int
+}
+
+// PromptInfo describes a registered prompt template and its version.
+type PromptInfo struct {
+	Key     PromptKey `json:"key"`
+	Version string    `json:"version"`
+	// Percent is, for an experiment variant, the share of units it is used
+	// for.
+	Percent int `json:"percent,omitempty"`
+}
+
 type PromptManager struct {
+	mu      sync.RWMutex
 	prompts map[PromptKey]*template.Template
 	raw     map[PromptKey]string
+
+	// builtinRaw holds the embedded templates that overrides replace.
+	builtinRaw map[PromptKey]string
+	// variants holds the templates of experiments by their variant key, and
+	// percents the share of units each variant is used for.
+	variants map[PromptKey]string
+	percents map[PromptKey]int
 }
 
 func NewPromptManager() (*PromptManager, error) {
@@ -68,11 +122,115 @@ func NewPromptManager() (*PromptManager, error) {
 		pm.prompts[key] = tmpl
 		pm.raw[key] = string(content)
 	}
+	pm.builtinRaw = maps.Clone(pm.raw)
 
 	return pm, nil
 }
 
+// SetExperiments replaces the prompt experiments. Each experiment needs an
+// existing prompt; if any variant fails to parse, nothing changes.
+func (pm *PromptManager) SetExperiments(experiments []Experiment) error {
+	variants := make(map[PromptKey]string, len(experiments))
+	percents := make(map[PromptKey]int, len(experiments))
+	parsed := make(map[PromptKey]*template.Template, len(experiments))
+	for _, e := range experiments {
+		if _, ok := pm.builtinRaw[e.Key]; !ok || e.Key.IsExperiment() {
+			return fmt.Errorf("no prompt found for key '%s'", e.Key)
+		}
+		if e.Percent < 0 || e.Percent > 100 {
+			return fmt.Errorf("experiment percent of prompt %s must be between 0 and 100", e.Key)
+		}
+		key := e.Key + experimentSuffix
+		tmpl, err := template.New(string(key)).Parse(e.Source)
+		if err != nil {
+			return fmt.Errorf("could not parse experiment template for prompt %s: %w", e.Key, err)
+		}
+		parsed[key] = tmpl
+		variants[key] = e.Source
+		percents[key] = e.Percent
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	for key := range pm.variants {
+		delete(pm.prompts, key)
+		delete(pm.raw, key)
+	}
+	for key, tmpl := range parsed {
+		pm.prompts[key] = tmpl
+		pm.raw[key] = variants[key]
+	}
+	pm.variants = variants
+	pm.percents = percents
+	return nil
+}
+
+// Choose returns the key to render for one use of a prompt: the variant of
+// its experiment for the configured percent of units, the prompt otherwise.
+// A unit, such as a pull request, always gets the same choice, so that its
+// reviews are comparable over time.
+func (pm *PromptManager) Choose(key PromptKey, unit string) PromptKey {
+	variant := key + experimentSuffix
+	pm.mu.RLock()
+	percent, ok := pm.percents[variant]
+	pm.mu.RUnlock()
+	if !ok || percent <= 0 {
+		return key
+	}
+	sum := sha256.Sum256([]byte(string(key) + "\x00" + unit))
+	if binary.BigEndian.Uint64(sum[:8])%100 < uint64(percent) {
+		return variant
+	}
+	return key
+}
+
+// Prompts lists the registered prompts with their versions, including the
+// variants of experiments, ordered by key.
+func (pm *PromptManager) Prompts() []PromptInfo {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	infos := make([]PromptInfo, 0, len(pm.raw))
+	for key, source := range pm.raw {
+		infos = append(infos, PromptInfo{Key: key, Version: TemplateVersion(source), Percent: pm.percents[key]})
+	}
+	slices.SortFunc(infos, func(a, b PromptInfo) int { return strings.Compare(string(a.Key), string(b.Key)) })
+	return infos
+}
+
+// SetOverrides replaces the embedded templates of the given keys with the
+// override sources, and restores the embedded templates of all other keys.
+// Overrides only apply to existing prompts. If any override fails to parse,
+// nothing changes.
+func (pm *PromptManager) SetOverrides(overrides map[PromptKey]string) error {
+	prompts := make(map[PromptKey]*template.Template, len(pm.builtinRaw))
+	raw := maps.Clone(pm.builtinRaw)
+	for key, content := range overrides {
+		if _, ok := raw[key]; !ok {
+			return fmt.Errorf("no prompt found for key '%s'", key)
+		}
+		raw[key] = content
+	}
+	pm.mu.RLock()
+	maps.Copy(raw, pm.variants)
+	pm.mu.RUnlock()
+	for key, content := range raw {
+		tmpl, err := template.New(string(key)).Parse(content)
+		if err != nil {
+			return fmt.Errorf("could not parse template for prompt %s: %w", key, err)
+		}
+		prompts[key] = tmpl
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.prompts = prompts
+	pm.raw = raw
+	return nil
+}
+
 func (pm *PromptManager) Get(key PromptKey) (*template.Template, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
 	tmpl, ok := pm.prompts[key]
 	if !ok {
 		return nil, fmt.Errorf("no prompt found for key '%s'", key)
@@ -86,6 +244,8 @@ func (pm *PromptManager) Get(key PromptKey) (*template.Template, error) {
 // at runtime. Render(key, nil) is explicitly NOT what you want for this
 // case — it replaces all {{.Field}} placeholders with "<no value>".
 func (pm *PromptManager) Raw(key PromptKey) (string, error) {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
 	s, ok := pm.raw[key]
 	if !ok {
 		return "", fmt.Errorf("no prompt found for key '%s'", key)
@@ -93,6 +253,40 @@ func (pm *PromptManager) Raw(key PromptKey) (string, error) {
 	return s, nil
 }
 
+// Version returns a short content hash of the prompt template. It changes
+// whenever the template source changes, so stored review feedback can be
+// grouped by the exact prompt that produced it. Unknown keys return "".
+func (pm *PromptManager) Version(key PromptKey) string {
+	pm.mu.RLock()
+	defer pm.mu.RUnlock()
+	s, ok := pm.raw[key]
+	if !ok {
+		return ""
+	}
+	return TemplateVersion(s)
+}
+
+// TemplateVersion returns the version of a prompt template source, in the
+// same form as [PromptManager.Version].
+func TemplateVersion(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return hex.EncodeToString(sum[:6])
+}
+
+// RenderTemplate renders a prompt template source that is not managed by a
+// PromptManager, such as a candidate prompt under evaluation.
+func RenderTemplate(source string, data any) (string, error) {
+	tmpl, err := template.New("custom").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("could not parse template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.String(), nil
+}
+
 func (pm *PromptManager) Render(key PromptKey, data any) (string, error) {
 	tmpl, err := pm.Get(key)
 	if err != nil {
@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -27,6 +28,49 @@ func TestPromptManager_Raw(t *testing.T) {
 	}
 }
 
+func TestPromptManager_Version(t *testing.T) {
+	pm, err := NewPromptManager()
+	if err != nil {
+		t.Fatalf("NewPromptManager() error = %v", err)
+	}
+
+	v := pm.Version(CodeReviewPrompt)
+	if len(v) != 12 {
+		t.Errorf("Version() = %q, want 12 hex characters", v)
+	}
+	if v != pm.Version(CodeReviewPrompt) {
+		t.Error("Version() should be stable for the same template")
+	}
+	if v == pm.Version(ReReviewPrompt) {
+		t.Error("different templates should have different versions")
+	}
+	if pm.Version("nonexistent_prompt") != "" {
+		t.Error("Version() of an unknown key should be empty")
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	pm, err := NewPromptManager()
+	if err != nil {
+		t.Fatalf("NewPromptManager() error = %v", err)
+	}
+	raw, err := pm.Raw(ReReviewPrompt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if TemplateVersion(raw) != pm.Version(ReReviewPrompt) {
+		t.Error("TemplateVersion() should match Version() of the same source")
+	}
+
+	out, err := RenderTemplate("Review {{.Diff}}", map[string]string{"Diff": "+x"})
+	if err != nil || out != "Review +x" {
+		t.Errorf("RenderTemplate() = %q, %v", out, err)
+	}
+	if _, err := RenderTemplate("{{.Diff", nil); err == nil {
+		t.Error("RenderTemplate() should fail on a malformed template")
+	}
+}
+
 func TestPromptManager_Raw_NotFound(t *testing.T) {
 	pm, err := NewPromptManager()
 	if err != nil {
@@ -76,3 +120,130 @@ func TestPromptManager_Raw_Vs_Render_Nil(t *testing.T) {
 		t.Error("Raw() and Render(nil) should differ — Render(nil) replaces template vars with <no value>")
 	}
 }
+
+func TestPromptManager_SetOverrides(t *testing.T) {
+	pm, err := NewPromptManager()
+	if err != nil {
+		t.Fatalf("NewPromptManager() error = %v", err)
+	}
+	builtin := pm.Version(CodeReviewPrompt)
+
+	if err := pm.SetOverrides(map[PromptKey]string{CodeReviewPrompt: "Review {{.Diff}}"}); err != nil {
+		t.Fatalf("SetOverrides() error = %v", err)
+	}
+	got, err := pm.Render(CodeReviewPrompt, map[string]string{"Diff": "x"})
+	if err != nil || got != "Review x" {
+		t.Errorf("Render() = %q, %v; want the override", got, err)
+	}
+	if pm.Version(CodeReviewPrompt) == builtin {
+		t.Error("Version() should change with an override")
+	}
+
+	if err := pm.SetOverrides(map[PromptKey]string{CodeReviewPrompt: "{{.Broken"}); err == nil {
+		t.Error("expected error for an invalid template")
+	}
+	if err := pm.SetOverrides(map[PromptKey]string{"nonexistent_prompt": "x"}); err == nil {
+		t.Error("expected error for an unknown prompt key")
+	}
+	if got, _ := pm.Raw(CodeReviewPrompt); got != "Review {{.Diff}}" {
+		t.Error("failed SetOverrides() calls should leave the previous overrides in place")
+	}
+
+	if err := pm.SetOverrides(nil); err != nil {
+		t.Fatalf("SetOverrides(nil) error = %v", err)
+	}
+	if pm.Version(CodeReviewPrompt) != builtin {
+		t.Error("SetOverrides(nil) should restore the embedded template")
+	}
+}
+
+func TestPromptManager_SetExperiments(t *testing.T) {
+	pm, err := NewPromptManager()
+	if err != nil {
+		t.Fatalf("NewPromptManager() error = %v", err)
+	}
+
+	err = pm.SetExperiments([]Experiment{{Key: CodeReviewPrompt, Source: "Variant {{.Diff}}", Percent: 50}})
+	if err != nil {
+		t.Fatalf("SetExperiments() error = %v", err)
+	}
+	variant := CodeReviewPrompt + experimentSuffix
+	if !variant.IsExperiment() || variant.Base() != CodeReviewPrompt || CodeReviewPrompt.IsExperiment() {
+		t.Errorf("IsExperiment()/Base() of %q are wrong", variant)
+	}
+	got, err := pm.Render(variant, map[string]string{"Diff": "x"})
+	if err != nil || got != "Variant x" {
+		t.Errorf("Render(variant) = %q, %v; want the variant", got, err)
+	}
+	if pm.Version(variant) == pm.Version(CodeReviewPrompt) {
+		t.Error("the variant should have its own version")
+	}
+
+	if err := pm.SetOverrides(map[PromptKey]string{ReReviewPrompt: "Again {{.Diff}}"}); err != nil {
+		t.Fatalf("SetOverrides() error = %v", err)
+	}
+	if _, err := pm.Raw(variant); err != nil {
+		t.Error("SetOverrides() should keep the experiment variants")
+	}
+
+	for _, bad := range []Experiment{
+		{Key: "nonexistent_prompt", Source: "x", Percent: 10},
+		{Key: CodeReviewPrompt, Source: "x", Percent: 101},
+		{Key: CodeReviewPrompt, Source: "{{.Broken", Percent: 10},
+	} {
+		if err := pm.SetExperiments([]Experiment{bad}); err == nil {
+			t.Errorf("SetExperiments(%+v) should fail", bad)
+		}
+	}
+	if _, err := pm.Raw(variant); err != nil {
+		t.Error("failed SetExperiments() calls should leave the previous experiments in place")
+	}
+
+	if err := pm.SetExperiments(nil); err != nil {
+		t.Fatalf("SetExperiments(nil) error = %v", err)
+	}
+	if _, err := pm.Raw(variant); err == nil {
+		t.Error("SetExperiments(nil) should remove the variants")
+	}
+}
+
+func TestPromptManager_Choose(t *testing.T) {
+	pm, err := NewPromptManager()
+	if err != nil {
+		t.Fatalf("NewPromptManager() error = %v", err)
+	}
+	if got := pm.Choose(CodeReviewPrompt, "owner/app#1"); got != CodeReviewPrompt {
+		t.Errorf("Choose() without an experiment = %q, want %q", got, CodeReviewPrompt)
+	}
+
+	if err := pm.SetExperiments([]Experiment{{Key: CodeReviewPrompt, Source: "Variant", Percent: 30}}); err != nil {
+		t.Fatalf("SetExperiments() error = %v", err)
+	}
+	variants := 0
+	for pr := range 1000 {
+		unit := fmt.Sprintf("owner/app#%d", pr)
+		key := pm.Choose(CodeReviewPrompt, unit)
+		if key != pm.Choose(CodeReviewPrompt, unit) {
+			t.Fatalf("Choose() should be stable for unit %s", unit)
+		}
+		if key.IsExperiment() {
+			variants++
+		}
+	}
+	if variants < 250 || variants > 350 {
+		t.Errorf("Choose() picked the variant for %d of 1000 units, want about 300", variants)
+	}
+	if got := pm.Choose(ReReviewPrompt, "owner/app#1"); got != ReReviewPrompt {
+		t.Errorf("Choose() of a prompt without experiment = %q", got)
+	}
+
+	var listed bool
+	for _, info := range pm.Prompts() {
+		if info.Key == CodeReviewPrompt+experimentSuffix {
+			listed = info.Percent == 30 && info.Version != ""
+		}
+	}
+	if !listed {
+		t.Error("Prompts() should list the variant with its percent and version")
+	}
+}
@@ -0,0 +1,142 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/sevigo/goframe/llms"
+	"github.com/sevigo/goframe/schema"
+)
+
+// SwitchableModel is an llms.Model whose underlying model can be replaced
+// while it is in use. Components keep a reference to the SwitchableModel and
+// pick up a switch on their next call; calls already in flight finish on the
+// previous model.
+type SwitchableModel struct {
+	mu       sync.RWMutex
+	name     string
+	model    llms.Model
+	observer CallObserver
+	breaker  CircuitBreaker
+}
+
+// CallObserver is told the outcome of every call made through a
+// [SwitchableModel], whichever model is behind it.
+type CallObserver func(err error)
+
+// CircuitBreaker decides whether a call made through a [SwitchableModel] may
+// reach the model; *health.Breaker implements it.
+type CircuitBreaker interface {
+	// Allow returns an error when the call must fail without being made.
+	Allow() error
+	// Record is told the outcome of every allowed call.
+	Record(err error)
+}
+
+// NewSwitchableModel creates a [SwitchableModel] initially backed by model.
+func NewSwitchableModel(name string, model llms.Model) *SwitchableModel {
+	return &SwitchableModel{name: name, model: model}
+}
+
+// Switch replaces the underlying model.
+func (s *SwitchableModel) Switch(name string, model llms.Model) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+	s.model = model
+}
+
+// Observe makes observer receive the outcome of every following call.
+func (s *SwitchableModel) Observe(observer CallObserver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observer = observer
+}
+
+// Guard makes every following call pass through breaker. The breaker
+// outlives switches, so switching away from a failing model does not close
+// its circuit until the new model succeeds.
+func (s *SwitchableModel) Guard(breaker CircuitBreaker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.breaker = breaker
+}
+
+// ModelName returns the name of the current underlying model.
+func (s *SwitchableModel) ModelName() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.name
+}
+
+func (s *SwitchableModel) current() llms.Model {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.model
+}
+
+// allow asks the breaker, if any, whether a call may be made.
+func (s *SwitchableModel) allow() error {
+	s.mu.RLock()
+	breaker := s.breaker
+	s.mu.RUnlock()
+	if breaker == nil {
+		return nil
+	}
+	return breaker.Allow()
+}
+
+func (s *SwitchableModel) observe(err error) {
+	// A moderated call was answered; the model is not failing.
+	if errors.Is(err, ErrModerated) {
+		err = nil
+	}
+	s.mu.RLock()
+	observer, breaker := s.observer, s.breaker
+	s.mu.RUnlock()
+	if breaker != nil {
+		breaker.Record(err)
+	}
+	if observer != nil {
+		observer(err)
+	}
+}
+
+// GenerateContent implements llms.Model.
+func (s *SwitchableModel) GenerateContent(ctx context.Context, messages []schema.MessageContent, options ...llms.CallOption) (*schema.ContentResponse, error) {
+	if err := s.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := s.current().GenerateContent(ctx, messages, options...)
+	s.observe(err)
+	return resp, err
+}
+
+// Call implements llms.Model.
+func (s *SwitchableModel) Call(ctx context.Context, prompt string, options ...llms.CallOption) (string, error) {
+	if err := s.allow(); err != nil {
+		return "", err
+	}
+	resp, err := s.current().Call(ctx, prompt, options...)
+	s.observe(err)
+	return resp, err
+}
+
+// CountTokens implements llms.Tokenizer with the current model's tokenizer,
+// or an estimate when the model has none.
+func (s *SwitchableModel) CountTokens(ctx context.Context, text string) (int, error) {
+	if t, ok := s.current().(llms.Tokenizer); ok {
+		return t.CountTokens(ctx, text)
+	}
+	return NewEstimatingTokenizer().CountTokens(ctx, text)
+}
+
+// ModelName returns the name of the model currently behind model when it is
+// a [SwitchableModel], and fallback otherwise.
+func ModelName(model llms.Model, fallback string) string {
+	if s, ok := model.(*SwitchableModel); ok {
+		return s.ModelName()
+	}
+	return fallback
+}
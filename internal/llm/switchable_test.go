@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sevigo/goframe/llms"
+	"github.com/sevigo/goframe/schema"
+)
+
+type fakeModel struct{ reply string }
+
+func (f fakeModel) GenerateContent(context.Context, []schema.MessageContent, ...llms.CallOption) (*schema.ContentResponse, error) {
+	return &schema.ContentResponse{Choices: []*schema.ContentChoice{{Content: f.reply}}}, nil
+}
+
+func (f fakeModel) Call(context.Context, string, ...llms.CallOption) (string, error) {
+	return f.reply, nil
+}
+
+func TestSwitchableModel(t *testing.T) {
+	ctx := context.Background()
+	s := NewSwitchableModel("a", fakeModel{reply: "from a"})
+
+	if got, _ := s.Call(ctx, "hi"); got != "from a" {
+		t.Errorf("Call() = %q, want %q", got, "from a")
+	}
+	s.Switch("b", fakeModel{reply: "from b"})
+	if got, _ := s.Call(ctx, "hi"); got != "from b" {
+		t.Errorf("Call() after Switch = %q, want %q", got, "from b")
+	}
+	if got := ModelName(s, "a"); got != "b" {
+		t.Errorf("ModelName() = %q, want %q", got, "b")
+	}
+	if got := ModelName(fakeModel{}, "a"); got != "a" {
+		t.Errorf("ModelName() of a plain model = %q, want the fallback", got)
+	}
+	if n, err := s.CountTokens(ctx, "123456"); err != nil || n == 0 {
+		t.Errorf("CountTokens() = %d, %v; want an estimate", n, err)
+	}
+}
+
+func TestSwitchableModel_Observe(t *testing.T) {
+	s := NewSwitchableModel("a", fakeModel{reply: "ok"})
+	var calls int
+	s.Observe(func(err error) {
+		if err != nil {
+			t.Errorf("unexpected error %v", err)
+		}
+		calls++
+	})
+	_, _ = s.Call(context.Background(), "hi")
+	s.Switch("b", fakeModel{reply: "ok"})
+	_, _ = s.GenerateContent(context.Background(), nil)
+	if calls != 2 {
+		t.Errorf("observer saw %d calls, want 2 across a switch", calls)
+	}
+}
+
+type fakeBreaker struct {
+	deny     error
+	recorded int
+}
+
+func (b *fakeBreaker) Allow() error { return b.deny }
+
+func (b *fakeBreaker) Record(error) { b.recorded++ }
+
+func TestSwitchableModel_Guard(t *testing.T) {
+	s := NewSwitchableModel("a", fakeModel{reply: "ok"})
+	breaker := &fakeBreaker{}
+	s.Guard(breaker)
+	if got, err := s.Call(context.Background(), "hi"); err != nil || got != "ok" {
+		t.Fatalf("Call() = %q, %v; want the reply", got, err)
+	}
+
+	breaker.deny = errors.New("circuit open")
+	if _, err := s.GenerateContent(context.Background(), nil); !errors.Is(err, breaker.deny) {
+		t.Errorf("GenerateContent() error = %v, want the breaker's", err)
+	}
+	if breaker.recorded != 1 {
+		t.Errorf("breaker recorded %d calls, want only the allowed one", breaker.recorded)
+	}
+}
@@ -0,0 +1,136 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/sevigo/goframe/embeddings"
+	"github.com/sevigo/goframe/llms"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// warmupPrompt is the text sent to the models. Its answer is discarded.
+const warmupPrompt = "ping"
+
+// Warmer sends the generator and embedder a tiny request in the background,
+// once at startup and then every keep-alive interval, so that providers that
+// load models lazily have them loaded before a review needs them.
+type Warmer struct {
+	cfg       config.WarmupConfig
+	generator llms.Model
+	embedder  embeddings.Embedder
+	logger    *slog.Logger
+
+	stopCh   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWarmer creates a Warmer. Either model may be nil to skip it.
+func NewWarmer(cfg config.WarmupConfig, generator llms.Model, embedder embeddings.Embedder, logger *slog.Logger) *Warmer {
+	return &Warmer{
+		cfg:       cfg,
+		generator: generator,
+		embedder:  embedder,
+		logger:    logger,
+		stopCh:    make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start warms up the models in the background and returns immediately. It
+// does nothing when warm-up is disabled.
+func (w *Warmer) Start() {
+	if !w.cfg.Enabled {
+		close(w.done)
+		return
+	}
+
+	go func() {
+		defer close(w.done)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-w.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		start := time.Now()
+		if err := w.Warm(ctx); err != nil {
+			w.logger.Warn("model warm-up failed, the first review may be slow", "error", err)
+		} else {
+			w.logger.Info("models warmed up", "duration", time.Since(start))
+		}
+		if w.cfg.KeepAliveInterval <= 0 {
+			return
+		}
+
+		ticker := time.NewTicker(w.cfg.KeepAliveInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-w.stopCh:
+				return
+			case <-ticker.C:
+				if err := w.Warm(ctx); err != nil {
+					w.logger.Warn("model keep-alive failed", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop cancels a warm-up in progress and stops the keep-alive requests. It
+// must only be called after Start.
+func (w *Warmer) Stop() {
+	w.stopOnce.Do(func() { close(w.stopCh) })
+	<-w.done
+}
+
+// Warm sends both models their request in parallel, each bounded by the
+// configured timeout, and returns their errors.
+func (w *Warmer) Warm(ctx context.Context) error {
+	var wg sync.WaitGroup
+	var genErr, embErr error
+	if w.generator != nil {
+		wg.Go(func() {
+			genErr = w.ping(ctx, "generator", func(ctx context.Context) error {
+				_, err := llms.GenerateFromSinglePrompt(ctx, w.generator, warmupPrompt, llms.WithMaxTokens(1))
+				return err
+			})
+		})
+	}
+	if w.embedder != nil {
+		wg.Go(func() {
+			embErr = w.ping(ctx, "embedder", func(ctx context.Context) error {
+				_, err := w.embedder.EmbedQuery(ctx, warmupPrompt)
+				return err
+			})
+		})
+	}
+	wg.Wait()
+	return errors.Join(genErr, embErr)
+}
+
+// ping runs call with the configured timeout and logs how long it took.
+func (w *Warmer) ping(ctx context.Context, role string, call func(context.Context) error) error {
+	if w.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.cfg.Timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	if err := call(ctx); err != nil {
+		return fmt.Errorf("%s: %w", role, err)
+	}
+	w.logger.Debug("model warmed up", "role", role, "duration", time.Since(start))
+	return nil
+}
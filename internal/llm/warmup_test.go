@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// pingEmbedder counts its queries and fails them with err.
+type pingEmbedder struct {
+	queries atomic.Int32
+	err     error
+}
+
+func (e *pingEmbedder) EmbedDocuments(context.Context, []string) ([][]float32, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (e *pingEmbedder) EmbedQuery(context.Context, string) ([]float32, error) {
+	e.queries.Add(1)
+	return []float32{1}, e.err
+}
+
+func (e *pingEmbedder) EmbedQueries(context.Context, []string) ([][]float32, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (e *pingEmbedder) GetDimension(context.Context) (int, error) { return 1, nil }
+
+func TestWarmer_Warm(t *testing.T) {
+	gen := &countingModel{reply: "pong"}
+	emb := &pingEmbedder{}
+	w := NewWarmer(config.WarmupConfig{Enabled: true, Timeout: time.Second}, gen, emb, slog.New(slog.DiscardHandler))
+
+	require.NoError(t, w.Warm(context.Background()))
+	assert.Equal(t, 1, gen.calls)
+	assert.EqualValues(t, 1, emb.queries.Load())
+
+	emb.err = errors.New("model not found")
+	err := w.Warm(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "embedder: model not found")
+
+	assert.NoError(t, NewWarmer(config.WarmupConfig{Enabled: true}, nil, nil, slog.New(slog.DiscardHandler)).Warm(context.Background()),
+		"missing models are skipped")
+}
+
+func TestWarmer_KeepAlive(t *testing.T) {
+	emb := &pingEmbedder{}
+	w := NewWarmer(config.WarmupConfig{Enabled: true, Timeout: time.Second, KeepAliveInterval: 10 * time.Millisecond},
+		nil, emb, slog.New(slog.DiscardHandler))
+	w.Start()
+	assert.Eventually(t, func() bool { return emb.queries.Load() >= 3 }, 5*time.Second, 5*time.Millisecond)
+	w.Stop()
+
+	n := emb.queries.Load()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, n, emb.queries.Load(), "no pings after Stop")
+}
+
+func TestWarmer_Disabled(t *testing.T) {
+	emb := &pingEmbedder{}
+	w := NewWarmer(config.WarmupConfig{}, nil, emb, slog.New(slog.DiscardHandler))
+	w.Start()
+	w.Stop()
+	assert.Zero(t, emb.queries.Load())
+}
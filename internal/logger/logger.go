@@ -5,6 +5,9 @@ import (
 	"io"
 	"log/slog"
 	"os"
+
+	"github.com/sevigo/code-warden/internal/redact"
+	"github.com/sevigo/code-warden/internal/trace"
 )
 
 // Config holds the logger configuration.
@@ -16,6 +19,12 @@ type Config struct {
 
 // NewLogger initializes a new slog logger based on the provided configuration.
 func NewLogger(cfg Config, output io.Writer) *slog.Logger {
+	return NewTracingLogger(cfg, output, nil)
+}
+
+// NewTracingLogger is like NewLogger, but also records the log records of
+// reviews in traces, after redacting them. A nil traces records nothing.
+func NewTracingLogger(cfg Config, output io.Writer, traces *trace.Recorder) *slog.Logger {
 	var handler slog.Handler
 
 	if output == nil {
@@ -55,5 +64,8 @@ func NewLogger(cfg Config, output io.Writer) *slog.Logger {
 		})
 	}
 
-	return slog.New(handler)
+	if traces != nil {
+		handler = trace.NewHandler(handler, traces)
+	}
+	return slog.New(redact.NewHandler(handler))
 }
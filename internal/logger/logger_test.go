@@ -62,3 +62,13 @@ func TestNewLogger(t *testing.T) {
 		})
 	}
 }
+
+func TestNewLogger_RedactsSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.NewLogger(logger.Config{Level: "info", Format: "json"}, &buf)
+
+	log.Error("failed to connect", "dsn", "postgres://warden:s3cr3tP4ss@db:5432/app")
+
+	assert.NotContains(t, buf.String(), "s3cr3tP4ss")
+	assert.Contains(t, buf.String(), "[REDACTED:connection_string]")
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/mcp/tools"
 	"github.com/sevigo/code-warden/internal/rag"
+	"github.com/sevigo/code-warden/internal/sandbox"
 	"github.com/sevigo/code-warden/internal/storage"
 )
 
@@ -44,6 +45,7 @@ type Server struct {
 	comparisonModels []string
 	agentMode        bool
 	reviewsDir       string
+	sandbox          sandbox.Runner
 
 	// SSE session management
 	sessionsMu sync.RWMutex
@@ -100,6 +102,8 @@ type Config struct {
 	ComparisonModels []string
 	ReviewsDir       string
 	AgentMode        bool // When true, review_code uses single-model review for faster agent feedback
+	// Sandbox isolates run_command; nil runs commands directly on the host.
+	Sandbox sandbox.Runner
 }
 
 // NewServer creates a new MCP server.
@@ -132,6 +136,7 @@ func NewServer(
 		comparisonModels: config.ComparisonModels,
 		agentMode:        config.AgentMode,
 		reviewsDir:       config.ReviewsDir,
+		sandbox:          config.Sandbox,
 	}
 
 	// Register default tools
@@ -185,6 +190,7 @@ func (s *Server) registerTools() {
 	s.registry.MustRegisterTool(&tools.RunCommand{
 		RepoConfig:  s.repoConfig,
 		ProjectRoot: s.projectRoot,
+		Sandbox:     s.sandbox,
 		Logger:      s.logger,
 	})
 
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/sandbox"
 )
 
 const (
@@ -35,7 +36,9 @@ var defaultVerifyCommands = []string{
 type RunCommand struct {
 	RepoConfig  *core.RepoConfig
 	ProjectRoot string
-	Logger      *slog.Logger
+	// Sandbox isolates the command; nil runs it directly on the host.
+	Sandbox sandbox.Runner
+	Logger  *slog.Logger
 }
 
 // RunCommandResponse is the response for the run_command tool.
@@ -113,8 +116,7 @@ func (t *RunCommand) Execute(ctx context.Context, args map[string]any) (any, err
 	defer cancel()
 
 	parts := strings.Fields(command)
-	cmd := exec.CommandContext(runCtx, parts[0], parts[1:]...) //nolint:gosec // command is validated against whitelist
-	cmd.Dir = projectRoot
+	cmd := sandbox.OrDirect(t.Sandbox).Command(runCtx, projectRoot, parts[0], parts[1:]...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
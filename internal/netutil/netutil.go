@@ -0,0 +1,120 @@
+// Package netutil applies proxy and TLS trust settings to every outbound
+// connection: Go HTTP clients (GitHub, Ollama, Gemini, object storage, Qdrant
+// REST), gRPC (Qdrant, via the proxy environment) and the git CLI.
+package netutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+var (
+	mu        sync.RWMutex
+	proxyFunc = httpproxy.FromEnvironment().ProxyFunc()
+	tlsConfig *tls.Config
+)
+
+// Configure applies cfg process-wide and reconfigures http.DefaultTransport.
+// Configured proxies are exported to the environment so that gRPC and git
+// subprocesses use them too, and a CA bundle is exported to git through
+// GIT_SSL_CAINFO. It must run before outbound clients are created.
+func Configure(cfg config.NetworkConfig) error {
+	for key, value := range map[string]string{
+		"HTTPS_PROXY": cfg.HTTPSProxy,
+		"HTTP_PROXY":  cfg.HTTPProxy,
+		"NO_PROXY":    cfg.NoProxy,
+	} {
+		if value == "" {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+
+	var tc *tls.Config
+	if cfg.CABundle != "" {
+		pool, err := loadCABundle(cfg.CABundle)
+		if err != nil {
+			return err
+		}
+		tc = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		if err := os.Setenv("GIT_SSL_CAINFO", cfg.CABundle); err != nil {
+			return fmt.Errorf("failed to set GIT_SSL_CAINFO: %w", err)
+		}
+	}
+
+	mu.Lock()
+	proxyFunc = httpproxy.FromEnvironment().ProxyFunc()
+	tlsConfig = tc
+	mu.Unlock()
+
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		configureTransport(t)
+	}
+	return nil
+}
+
+// Apply configures client's transport with the process-wide proxy and TLS
+// settings and returns client. Use it for clients built by libraries that
+// create a bare *http.Transport, which ignores the proxy environment.
+// Clients without a transport use http.DefaultTransport, which [Configure]
+// already covers.
+func Apply(client *http.Client) *http.Client {
+	if t, ok := client.Transport.(*http.Transport); ok {
+		configureTransport(t)
+	}
+	return client
+}
+
+// TLSConfig returns a copy of the process-wide TLS client configuration, or
+// nil when no CA bundle is configured.
+func TLSConfig() *tls.Config {
+	mu.RLock()
+	defer mu.RUnlock()
+	if tlsConfig == nil {
+		return nil
+	}
+	return tlsConfig.Clone()
+}
+
+func configureTransport(t *http.Transport) {
+	t.Proxy = proxy
+	if tc := TLSConfig(); tc != nil {
+		t.TLSClientConfig = tc
+	}
+}
+
+func proxy(req *http.Request) (*url.URL, error) {
+	mu.RLock()
+	f := proxyFunc
+	mu.RUnlock()
+	return f(req.URL)
+}
+
+// loadCABundle returns the system roots extended with the certificates in
+// the PEM file at path.
+func loadCABundle(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("failed to load CA bundle: no PEM certificates found in " + path)
+	}
+	return pool, nil
+}
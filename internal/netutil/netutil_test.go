@@ -0,0 +1,82 @@
+package netutil
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+func TestConfigure_Proxy(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "")
+	t.Setenv("HTTP_PROXY", "")
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("GIT_SSL_CAINFO", "")
+
+	require.NoError(t, Configure(config.NetworkConfig{
+		HTTPSProxy: "http://proxy.internal:3128",
+		NoProxy:    "qdrant.internal",
+	}))
+	t.Cleanup(func() { _ = Configure(config.NetworkConfig{}) })
+
+	assert.Equal(t, "http://proxy.internal:3128", os.Getenv("HTTPS_PROXY"))
+
+	client := Apply(&http.Client{Transport: &http.Transport{}})
+	transport := client.Transport.(*http.Transport)
+	require.NotNil(t, transport.Proxy)
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.github.com/repos", nil)
+	require.NoError(t, err)
+	u, err := transport.Proxy(req)
+	require.NoError(t, err)
+	require.NotNil(t, u)
+	assert.Equal(t, "proxy.internal:3128", u.Host)
+
+	req, err = http.NewRequest(http.MethodGet, "https://qdrant.internal:6333/collections", nil)
+	require.NoError(t, err)
+	u, err = transport.Proxy(req)
+	require.NoError(t, err)
+	assert.Nil(t, u, "hosts in no_proxy bypass the proxy")
+}
+
+func TestConfigure_CABundle(t *testing.T) {
+	t.Setenv("GIT_SSL_CAINFO", "")
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, os.WriteFile(bundle, certPEM, 0o600))
+
+	require.NoError(t, Configure(config.NetworkConfig{CABundle: bundle}))
+	t.Cleanup(func() { _ = Configure(config.NetworkConfig{}) })
+	assert.Equal(t, bundle, os.Getenv("GIT_SSL_CAINFO"))
+
+	client := Apply(&http.Client{Transport: &http.Transport{}})
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err, "the CA bundle is trusted")
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	tc := client.Transport.(*http.Transport).TLSClientConfig
+	assert.Equal(t, uint16(tls.VersionTLS12), tc.MinVersion)
+}
+
+func TestConfigure_InvalidCABundle(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(empty, []byte("not a certificate"), 0o600))
+
+	assert.Error(t, Configure(config.NetworkConfig{CABundle: empty}))
+	assert.Error(t, Configure(config.NetworkConfig{CABundle: filepath.Join(t.TempDir(), "missing.pem")}))
+}
@@ -0,0 +1,197 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// digestMinSeverity is the lowest severity of the findings a digest lists.
+const digestMinSeverity = "High"
+
+type digestReview struct {
+	Repo     string
+	PRNumber int
+	URL      string
+	HeadSHA  string
+	Verdict  string
+	Degraded bool
+	Counts   severityCounts
+}
+
+type digestFailure struct {
+	Repo        string
+	PRNumber    int
+	Type        string
+	TriggeredAt time.Time
+}
+
+type digestMessage struct {
+	Since    time.Time
+	Until    time.Time
+	Reviews  []digestReview
+	Findings []finding
+	More     int
+	Failures []digestFailure
+}
+
+func (m *digestMessage) empty() bool {
+	return len(m.Reviews) == 0 && len(m.Failures) == 0
+}
+
+// recipient is an address due a digest and the subscriptions it covers.
+type recipient struct {
+	email string
+	subs  []*storage.EmailSubscription
+}
+
+// wants reports whether the recipient subscribed to digests of repo.
+func (r *recipient) wants(repo string) bool {
+	for _, sub := range r.subs {
+		if covers(sub, repo) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *recipient) ids() []int64 {
+	ids := make([]int64, len(r.subs))
+	for i, sub := range r.subs {
+		ids[i] = sub.ID
+	}
+	return ids
+}
+
+// dueRecipients returns the addresses whose digest of today is due at now:
+// from the configured hour on, to those with a digest subscription that has
+// not been sent one since.
+func dueRecipients(subs []*storage.EmailSubscription, now time.Time, hour int) []*recipient {
+	dueAt := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if now.Before(dueAt) {
+		return nil
+	}
+	byEmail := make(map[string]*recipient)
+	var due []*recipient
+	for _, sub := range subs {
+		if !sub.Digest {
+			continue
+		}
+		r, ok := byEmail[sub.Email]
+		if !ok {
+			r = &recipient{email: sub.Email}
+			byEmail[sub.Email] = r
+			due = append(due, r)
+		}
+		r.subs = append(r.subs, sub)
+	}
+	kept := due[:0]
+	for _, r := range due {
+		for _, sub := range r.subs {
+			if sub.LastDigestAt == nil || sub.LastDigestAt.Before(dueAt) {
+				kept = append(kept, r)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// SendDigests sends the digests that are due, each covering the last 24
+// hours of the repositories its address subscribed to. Addresses with
+// nothing to report get no message but count as sent.
+func (n *Notifier) SendDigests(ctx context.Context) {
+	subs, err := n.store.ListEmailSubscriptions(ctx)
+	if err != nil {
+		n.logger.Warn("failed to list email subscriptions", "error", err)
+		return
+	}
+	now := n.now()
+	due := dueRecipients(subs, now, n.cfg.DigestHour)
+	if len(due) == 0 {
+		return
+	}
+
+	since := now.Add(-digestPeriod)
+	reviews, err := n.store.GetReviewsSince(ctx, since)
+	if err != nil {
+		n.logger.Warn("failed to load reviews for the digest", "error", err)
+		return
+	}
+	failures, err := n.store.ListFailedJobRunsSince(ctx, since)
+	if err != nil {
+		n.logger.Warn("failed to load failed jobs for the digest", "error", err)
+		return
+	}
+	parsed := make([]*core.StructuredReview, len(reviews))
+	for i, rev := range reviews {
+		parsed[i] = parseReview(ctx, n.logger, rev.ReviewContent)
+	}
+
+	for _, r := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		msg := buildDigest(r, reviews, parsed, failures)
+		msg.Since, msg.Until = since, now
+		if !msg.empty() {
+			var body strings.Builder
+			if err := templates.ExecuteTemplate(&body, "digest.html", msg); err != nil {
+				n.logger.Error("failed to render digest email", "error", err)
+				return
+			}
+			subject := fmt.Sprintf("Code Warden digest: %d review(s), %d failed job(s)", len(msg.Reviews), len(msg.Failures))
+			if !n.send(ctx, r.email, subject, body.String()) {
+				continue
+			}
+		}
+		if err := n.store.MarkDigestSent(ctx, r.ids(), now); err != nil {
+			n.logger.Warn("failed to record sent digest", "to", r.email, "error", err)
+		}
+	}
+}
+
+// buildDigest selects the reviews, findings and failures of the
+// repositories r subscribed to. parsed holds the parsed content of reviews.
+func buildDigest(r *recipient, reviews []*core.Review, parsed []*core.StructuredReview, failures []*storage.JobRun) *digestMessage {
+	msg := &digestMessage{}
+	var findings []finding
+	for i, rev := range reviews {
+		if !r.wants(rev.RepoFullName) {
+			continue
+		}
+		url := pullURL(rev.RepoFullName, rev.PRNumber)
+		msg.Reviews = append(msg.Reviews, digestReview{
+			Repo:     rev.RepoFullName,
+			PRNumber: rev.PRNumber,
+			URL:      url,
+			HeadSHA:  rev.HeadSHA,
+			Verdict:  parsed[i].Verdict,
+			Degraded: rev.Degraded,
+			Counts:   countSeverities(parsed[i].Suggestions),
+		})
+		findings = append(findings, topFindings(rev.RepoFullName, rev.PRNumber, url, parsed[i].Suggestions, digestMinSeverity)...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return core.SeverityRank(findings[i].Severity) > core.SeverityRank(findings[j].Severity)
+	})
+	msg.Findings = findings[:min(len(findings), maxFindings)]
+	msg.More = max(len(findings)-maxFindings, 0)
+
+	for _, f := range failures {
+		if r.wants(f.RepoFullName) {
+			msg.Failures = append(msg.Failures, digestFailure{
+				Repo:        f.RepoFullName,
+				PRNumber:    f.PRNumber,
+				Type:        f.Type,
+				TriggeredAt: f.TriggeredAt,
+			})
+		}
+	}
+	return msg
+}
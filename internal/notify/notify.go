@@ -0,0 +1,288 @@
+// Package notify sends email notifications about reviews: a message for
+// every completed review and a daily digest of the reviews run, their most
+// severe findings and the jobs that failed.
+//
+// Who receives which message is stored in the database as subscriptions of
+// an address to a repository, or to all repositories.
+package notify
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	ragreview "github.com/sevigo/code-warden/internal/rag/review"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/stringsutil"
+)
+
+const (
+	// digestInterval is how often the digest loop checks whether digests
+	// are due.
+	digestInterval = 15 * time.Minute
+	// digestPeriod is how far back a digest looks.
+	digestPeriod = 24 * time.Hour
+	// maxFindings caps the findings listed in a message.
+	maxFindings = 10
+	// findingCommentLen caps the comment of a listed finding.
+	findingCommentLen = 300
+	// sendTimeout bounds the delivery of one message.
+	sendTimeout = 30 * time.Second
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"shortSHA": stringsutil.TruncateSHA,
+}).ParseFS(templateFS, "templates/*.html"))
+
+// Store is the part of storage.Store the Notifier uses.
+type Store interface {
+	storage.EmailSubscriptionStore
+	GetReviewsSince(ctx context.Context, since time.Time) ([]*core.Review, error)
+	ListFailedJobRunsSince(ctx context.Context, since time.Time) ([]*storage.JobRun, error)
+}
+
+// Notifier sends the notifications to the subscribers.
+type Notifier struct {
+	cfg    config.EmailConfig
+	store  Store
+	sender Sender
+	logger *slog.Logger
+	now    func() time.Time
+
+	stopCh   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a Notifier that sends through the configured SMTP server.
+func New(cfg *config.Config, store storage.Store, logger *slog.Logger) *Notifier {
+	return newNotifier(cfg.Notifications.Email, store, NewSMTPSender(cfg.Notifications.Email), logger)
+}
+
+func newNotifier(cfg config.EmailConfig, store Store, sender Sender, logger *slog.Logger) *Notifier {
+	return &Notifier{
+		cfg:    cfg,
+		store:  store,
+		sender: sender,
+		logger: logger,
+		now:    time.Now,
+		stopCh: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// severityCounts counts the findings of a review by severity.
+type severityCounts struct {
+	Critical, High, Medium, Low int
+}
+
+// finding is a finding listed in a message.
+type finding struct {
+	Repo     string
+	PRNumber int
+	URL      string
+	File     string
+	Line     int
+	Severity string
+	Category string
+	Comment  string
+}
+
+type reviewMessage struct {
+	Repo     string
+	PRNumber int
+	PRTitle  string
+	URL      string
+	HeadSHA  string
+	Verdict  string
+	Summary  string
+	Degraded bool
+	Counts   severityCounts
+	Findings []finding
+	// More is how many findings were left out of Findings.
+	More int
+}
+
+// ReviewCompleted sends the review of a pull request to the addresses
+// subscribed to per-review messages of its repository. Failures are logged
+// only; notifications must never fail a review.
+func (n *Notifier) ReviewCompleted(ctx context.Context, event *core.GitHubEvent, review *core.StructuredReview) {
+	if n == nil || !n.cfg.Enabled {
+		return
+	}
+	subs, err := n.store.ListEmailSubscriptions(ctx)
+	if err != nil {
+		n.logger.Warn("failed to list email subscriptions", "error", err)
+		return
+	}
+	var recipients []string
+	seen := make(map[string]bool)
+	for _, sub := range subs {
+		if sub.PerReview && covers(sub, event.RepoFullName) && !seen[sub.Email] {
+			seen[sub.Email] = true
+			recipients = append(recipients, sub.Email)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	url := pullURL(event.RepoFullName, event.PRNumber)
+	findings := topFindings(event.RepoFullName, event.PRNumber, url, review.Suggestions, "")
+	msg := reviewMessage{
+		Repo:     event.RepoFullName,
+		PRNumber: event.PRNumber,
+		PRTitle:  event.PRTitle,
+		URL:      url,
+		HeadSHA:  event.HeadSHA,
+		Verdict:  review.Verdict,
+		Summary:  review.Summary,
+		Degraded: review.Degraded,
+		Counts:   countSeverities(review.Suggestions),
+		Findings: findings[:min(len(findings), maxFindings)],
+		More:     max(len(findings)-maxFindings, 0),
+	}
+	var body strings.Builder
+	if err := templates.ExecuteTemplate(&body, "review.html", msg); err != nil {
+		n.logger.Error("failed to render review email", "error", err)
+		return
+	}
+	subject := fmt.Sprintf("[%s] Review of #%d", event.RepoFullName, event.PRNumber)
+	if review.Verdict != "" {
+		subject += ": " + review.Verdict
+	}
+	for _, to := range recipients {
+		n.send(ctx, to, subject, body.String())
+	}
+}
+
+func (n *Notifier) send(ctx context.Context, to, subject, body string) bool {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	if err := n.sender.Send(ctx, to, subject, body); err != nil {
+		n.logger.Warn("failed to send email", "to", to, "subject", subject, "error", err)
+		return false
+	}
+	return true
+}
+
+// Start sends the daily digests in the background. It does nothing when
+// email notifications are disabled.
+func (n *Notifier) Start() {
+	if !n.cfg.Enabled {
+		close(n.done)
+		return
+	}
+
+	go func() {
+		defer close(n.done)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-n.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		ticker := time.NewTicker(digestInterval)
+		defer ticker.Stop()
+		for {
+			n.SendDigests(ctx)
+			select {
+			case <-n.stopCh:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops sending digests and waits for digests being sent. It must only
+// be called after Start.
+func (n *Notifier) Stop() {
+	n.stopOnce.Do(func() { close(n.stopCh) })
+	<-n.done
+}
+
+// covers reports whether a subscription is for repo.
+func covers(sub *storage.EmailSubscription, repo string) bool {
+	return sub.RepoFullName == "" || sub.RepoFullName == repo
+}
+
+func pullURL(repo string, prNumber int) string {
+	return fmt.Sprintf("https://github.com/%s/pull/%d", repo, prNumber)
+}
+
+// countSeverities counts suggestions by severity. Unknown severities count
+// as low.
+func countSeverities(suggestions []core.Suggestion) severityCounts {
+	var c severityCounts
+	for _, s := range suggestions {
+		switch core.SeverityRank(s.Severity) {
+		case 4:
+			c.Critical++
+		case 3:
+			c.High++
+		case 2:
+			c.Medium++
+		default:
+			c.Low++
+		}
+	}
+	return c
+}
+
+// topFindings returns the suggestions ranked at least as minSeverity, the
+// most severe first. An empty minSeverity keeps all of them.
+func topFindings(repo string, prNumber int, url string, suggestions []core.Suggestion, minSeverity string) []finding {
+	minRank := core.SeverityRank(minSeverity)
+	var ranked []core.Suggestion
+	for _, s := range suggestions {
+		if core.SeverityRank(s.Severity) >= minRank {
+			ranked = append(ranked, s)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return core.SeverityRank(ranked[i].Severity) > core.SeverityRank(ranked[j].Severity)
+	})
+	findings := make([]finding, 0, len(ranked))
+	for _, s := range ranked {
+		findings = append(findings, finding{
+			Repo:     repo,
+			PRNumber: prNumber,
+			URL:      url,
+			File:     s.FilePath,
+			Line:     s.LineNumber,
+			Severity: s.Severity,
+			Category: s.Category,
+			Comment:  stringsutil.Truncate(s.Comment, findingCommentLen, "..."),
+		})
+	}
+	return findings
+}
+
+// parseReview parses the raw model output a review was saved as, in the
+// JSON or the XML protocol. Output that parses as neither has no
+// suggestions.
+func parseReview(ctx context.Context, logger *slog.Logger, content string) *core.StructuredReview {
+	parser := ragreview.NewStructuredReviewParser(logger)
+	parser.PreferJSON = !strings.Contains(content, "<review")
+	parsed, err := parser.Parse(ctx, content)
+	if err != nil || parsed == nil {
+		return &core.StructuredReview{}
+	}
+	return parsed
+}
@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+type sentMail struct {
+	to, subject, body string
+}
+
+type fakeSender struct {
+	sent []sentMail
+}
+
+func (s *fakeSender) Send(_ context.Context, to, subject, html string) error {
+	s.sent = append(s.sent, sentMail{to: to, subject: subject, body: html})
+	return nil
+}
+
+func newTestNotifier(t *testing.T, now time.Time) (*Notifier, *mocks.MockStore, *fakeSender) {
+	t.Helper()
+	store := mocks.NewMockStore(gomock.NewController(t))
+	sender := &fakeSender{}
+	cfg := config.EmailConfig{Enabled: true, DigestHour: 8}
+	n := newNotifier(cfg, store, sender, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	n.now = func() time.Time { return now }
+	return n, store, sender
+}
+
+func TestReviewCompleted_SendsToSubscribersOfTheRepository(t *testing.T) {
+	n, store, sender := newTestNotifier(t, time.Now())
+	store.EXPECT().ListEmailSubscriptions(gomock.Any()).Return([]*storage.EmailSubscription{
+		{Email: "all@example.com", PerReview: true},
+		{Email: "app@example.com", RepoFullName: "owner/app", PerReview: true},
+		{Email: "app@example.com", PerReview: true},
+		{Email: "other@example.com", RepoFullName: "owner/other", PerReview: true},
+		{Email: "digest@example.com", RepoFullName: "owner/app", Digest: true},
+	}, nil)
+
+	event := &core.GitHubEvent{RepoFullName: "owner/app", PRNumber: 7, PRTitle: "Add <login>", HeadSHA: "0123456789abcdef"}
+	n.ReviewCompleted(context.Background(), event, &core.StructuredReview{
+		Verdict: "REQUEST_CHANGES",
+		Summary: "Looks risky.",
+		Suggestions: []core.Suggestion{
+			{FilePath: "main.go", LineNumber: 3, Severity: "Low", Comment: "Rename this."},
+			{FilePath: "auth.go", LineNumber: 12, Severity: "Critical", Comment: "SQL injection."},
+		},
+	})
+
+	require.Len(t, sender.sent, 2)
+	assert.Equal(t, "all@example.com", sender.sent[0].to)
+	assert.Equal(t, "app@example.com", sender.sent[1].to)
+	assert.Equal(t, "[owner/app] Review of #7: REQUEST_CHANGES", sender.sent[0].subject)
+	body := sender.sent[0].body
+	assert.Contains(t, body, "https://github.com/owner/app/pull/7")
+	assert.Contains(t, body, "Add &lt;login&gt;")
+	assert.Less(t, strings.Index(body, "auth.go:12"), strings.Index(body, "main.go:3"), "most severe finding first")
+}
+
+func TestReviewCompleted_DisabledSendsNothing(t *testing.T) {
+	n, _, sender := newTestNotifier(t, time.Now())
+	n.cfg.Enabled = false
+
+	n.ReviewCompleted(context.Background(), &core.GitHubEvent{RepoFullName: "owner/app"}, &core.StructuredReview{})
+	assert.Empty(t, sender.sent)
+}
+
+func TestDueRecipients(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 30, 0, 0, time.UTC)
+	sentToday := time.Date(2026, 5, 1, 8, 5, 0, 0, time.UTC)
+	sentYesterday := time.Date(2026, 4, 30, 8, 5, 0, 0, time.UTC)
+	subs := []*storage.EmailSubscription{
+		{ID: 1, Email: "a@example.com", RepoFullName: "owner/app", Digest: true, LastDigestAt: &sentYesterday},
+		{ID: 2, Email: "a@example.com", RepoFullName: "owner/lib", Digest: true, LastDigestAt: &sentToday},
+		{ID: 3, Email: "b@example.com", Digest: true, LastDigestAt: &sentToday},
+		{ID: 4, Email: "c@example.com", Digest: true},
+		{ID: 5, Email: "d@example.com", PerReview: true},
+	}
+
+	due := dueRecipients(subs, now, 8)
+	require.Len(t, due, 2)
+	assert.Equal(t, "a@example.com", due[0].email)
+	assert.Equal(t, []int64{1, 2}, due[0].ids())
+	assert.Equal(t, "c@example.com", due[1].email)
+
+	assert.Empty(t, dueRecipients(subs, now, 10), "not before the digest hour")
+}
+
+func TestSendDigests(t *testing.T) {
+	now := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	n, store, sender := newTestNotifier(t, now)
+	ctx := context.Background()
+	since := now.Add(-digestPeriod)
+
+	store.EXPECT().ListEmailSubscriptions(ctx).Return([]*storage.EmailSubscription{
+		{ID: 1, Email: "app@example.com", RepoFullName: "owner/app", Digest: true},
+		{ID: 2, Email: "quiet@example.com", RepoFullName: "owner/quiet", Digest: true},
+	}, nil)
+	store.EXPECT().GetReviewsSince(ctx, since).Return([]*core.Review{
+		{RepoFullName: "owner/app", PRNumber: 7, HeadSHA: "0123456789abcdef", ReviewContent: `{"summary":"ok","verdict":"COMMENT","suggestions":[` +
+			`{"file_path":"a.go","line_number":1,"severity":"High","category":"Bug","source":"diff:L1","comment":"Unchecked error."},` +
+			`{"file_path":"b.go","line_number":2,"severity":"Low","category":"Style","source":"diff:L2","comment":"Typo."}]}`},
+		{RepoFullName: "owner/other", PRNumber: 9, ReviewContent: `{"summary":"ok","verdict":"APPROVE","suggestions":[]}`},
+	}, nil)
+	store.EXPECT().ListFailedJobRunsSince(ctx, since).Return([]*storage.JobRun{
+		{Type: "review", RepoFullName: "owner/app", PRNumber: 8, TriggeredAt: now.Add(-time.Hour)},
+	}, nil)
+	store.EXPECT().MarkDigestSent(ctx, []int64{1}, now).Return(nil)
+	store.EXPECT().MarkDigestSent(ctx, []int64{2}, now).Return(nil)
+
+	n.SendDigests(ctx)
+
+	require.Len(t, sender.sent, 1, "nothing to report for owner/quiet")
+	mail := sender.sent[0]
+	assert.Equal(t, "app@example.com", mail.to)
+	assert.Equal(t, "Code Warden digest: 1 review(s), 1 failed job(s)", mail.subject)
+	assert.Contains(t, mail.body, "owner/app#7")
+	assert.Contains(t, mail.body, "Unchecked error.")
+	assert.NotContains(t, mail.body, "Typo.", "only high and critical findings are listed")
+	assert.NotContains(t, mail.body, "owner/other")
+	assert.Contains(t, mail.body, "owner/app#8")
+}
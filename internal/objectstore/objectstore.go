@@ -0,0 +1,185 @@
+// Package objectstore provides a minimal blob storage abstraction used for
+// artifacts and collection snapshots that should not live in a clone.
+package objectstore
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+const (
+	gcsEndpoint   = "https://storage.googleapis.com"
+	defaultRegion = "us-east-1"
+)
+
+// ErrNotFound is returned when the requested object does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object as returned by List.
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// Store reads and writes opaque blobs addressed by slash-separated keys.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// List returns every object whose key starts with prefix, in no
+	// particular order.
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// New builds the Store selected by cfg. S3 credentials fall back to the
+// standard AWS_ACCESS_KEY_ID / AWS_SECRET_ACCESS_KEY / AWS_SESSION_TOKEN
+// environment variables when not configured explicitly.
+func New(cfg config.ObjectStoreConfig) (Store, error) {
+	switch cfg.Backend {
+	case "", "local":
+		return NewLocal(cfg.Dir), nil
+	case "s3", "gcs":
+		opts := S3Options{
+			Endpoint:        cfg.Endpoint,
+			Region:          cfg.Region,
+			Bucket:          cfg.Bucket,
+			Prefix:          cfg.Prefix,
+			AccessKeyID:     cmp.Or(cfg.AccessKeyID, os.Getenv("AWS_ACCESS_KEY_ID")),
+			SecretAccessKey: cmp.Or(cfg.SecretAccessKey, os.Getenv("AWS_SECRET_ACCESS_KEY")),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			PathStyle:       cfg.PathStyle,
+		}
+		if cfg.Backend == "gcs" {
+			opts.Endpoint = cmp.Or(opts.Endpoint, gcsEndpoint)
+			opts.Region = cmp.Or(opts.Region, "auto")
+			opts.SessionToken = ""
+		}
+		opts.Region = cmp.Or(opts.Region, os.Getenv("AWS_REGION"), defaultRegion)
+		opts.Endpoint = cmp.Or(opts.Endpoint, fmt.Sprintf("https://s3.%s.amazonaws.com", opts.Region))
+		return NewS3(opts)
+	default:
+		return nil, fmt.Errorf("unsupported object store backend %q", cfg.Backend)
+	}
+}
+
+// localStore keeps objects as files under a root directory.
+type localStore struct {
+	root string
+}
+
+// NewLocal returns a Store backed by the local filesystem rooted at dir.
+// The directory is created lazily on the first write.
+func NewLocal(dir string) Store {
+	return &localStore{root: dir}
+}
+
+func (s *localStore) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.root, clean), nil
+}
+
+// Put writes r to key atomically via a temporary file and rename.
+func (s *localStore) Put(_ context.Context, key string, r io.Reader) error {
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp object: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write object %q: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close object %q: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("failed to commit object %q: %w", key, err)
+	}
+	return nil
+}
+
+// Get opens key for reading. The caller must close the returned reader.
+func (s *localStore) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	src, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to open object %q: %w", key, err)
+	}
+	return f, nil
+}
+
+// Delete removes key. Deleting a missing object is not an error.
+func (s *localStore) Delete(_ context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete object %q: %w", key, err)
+	}
+	return nil
+}
+
+// List walks the root directory and returns objects whose key starts with
+// prefix. In-flight uploads are skipped.
+func (s *localStore) List(_ context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && p == s.root {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, ObjectInfo{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects under %q: %w", prefix, err)
+	}
+	return objects, nil
+}
@@ -0,0 +1,65 @@
+package objectstore
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	s := NewLocal(t.TempDir())
+
+	require.NoError(t, s.Put(ctx, "snapshots/repo/a.snapshot", strings.NewReader("payload")))
+
+	r, err := s.Get(ctx, "snapshots/repo/a.snapshot")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	assert.Equal(t, "payload", string(data))
+
+	require.NoError(t, s.Delete(ctx, "snapshots/repo/a.snapshot"))
+	_, err = s.Get(ctx, "snapshots/repo/a.snapshot")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Deleting a missing object is a no-op.
+	assert.NoError(t, s.Delete(ctx, "snapshots/repo/a.snapshot"))
+}
+
+func TestLocalStore_RejectsTraversal(t *testing.T) {
+	ctx := context.Background()
+	s := NewLocal(t.TempDir())
+
+	for _, key := range []string{"", "../escape", "/etc/passwd", "a/../../escape"} {
+		assert.Error(t, s.Put(ctx, key, strings.NewReader("x")), "key %q", key)
+	}
+}
+
+func TestLocalStore_List(t *testing.T) {
+	ctx := context.Background()
+	s := NewLocal(t.TempDir() + "/missing")
+
+	objects, err := s.List(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, objects, "listing a store that was never written to is empty")
+
+	require.NoError(t, s.Put(ctx, "artifacts/a/b/one.md", strings.NewReader("1")))
+	require.NoError(t, s.Put(ctx, "artifacts/a/c/two.md", strings.NewReader("22")))
+	require.NoError(t, s.Put(ctx, "snapshots/x.snapshot", strings.NewReader("333")))
+
+	objects, err = s.List(ctx, "artifacts/a/b/")
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "artifacts/a/b/one.md", objects[0].Key)
+	assert.Equal(t, int64(1), objects[0].Size)
+	assert.False(t, objects[0].ModTime.IsZero())
+
+	objects, err = s.List(ctx, "artifacts/")
+	require.NoError(t, err)
+	assert.Len(t, objects, 2)
+}
@@ -0,0 +1,92 @@
+// Package orgconfig fetches organization-wide repository defaults from a
+// designated repository of each organization, e.g. "<org>/.code-warden", and
+// caches them for the configured refresh interval.
+package orgconfig
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+// configFile is the path of the defaults file in the organization repository.
+const configFile = ".code-warden.yml"
+
+type entry struct {
+	config    *core.RepoConfig
+	fetchedAt time.Time
+}
+
+// Loader returns the organization defaults for a repository owner.
+type Loader struct {
+	cfg    config.OrgConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]entry
+}
+
+// NewLoader creates a new [Loader].
+func NewLoader(cfg config.OrgConfig, logger *slog.Logger) *Loader {
+	return &Loader{
+		cfg:    cfg,
+		logger: logger,
+		now:    time.Now,
+		cache:  make(map[string]entry),
+	}
+}
+
+// Get returns the parsed defaults of owner's organization repository, or nil
+// when organization defaults are disabled or owner has none. Results,
+// including the absence of a defaults file, are cached for the refresh
+// interval. If a refresh fails, the previously fetched defaults keep applying.
+func (l *Loader) Get(ctx context.Context, gh internalgithub.Client, owner string) *core.RepoConfig {
+	if l == nil || l.cfg.Repo == "" || owner == "" {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cached, ok := l.cache[owner]
+	if ok && l.now().Sub(cached.fetchedAt) < l.cfg.RefreshInterval {
+		return cached.config
+	}
+
+	orgConfig, err := l.fetch(ctx, gh, owner)
+	if err != nil {
+		l.logger.Warn("failed to load organization defaults, using cached copy",
+			"owner", owner, "repo", l.cfg.Repo, "cached", ok, "error", err)
+		if ok {
+			return cached.config
+		}
+		return nil
+	}
+	l.cache[owner] = entry{config: orgConfig, fetchedAt: l.now()}
+	return orgConfig
+}
+
+func (l *Loader) fetch(ctx context.Context, gh internalgithub.Client, owner string) (*core.RepoConfig, error) {
+	data, err := gh.GetFileContent(ctx, owner, l.cfg.Repo, configFile)
+	if errors.Is(err, internalgithub.ErrNotFound) {
+		l.logger.Debug("no organization defaults found", "owner", owner, "repo", l.cfg.Repo)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	orgConfig, err := config.MergeRepoConfig(nil, data)
+	if err != nil {
+		return nil, err
+	}
+	l.logger.Info("loaded organization defaults", "owner", owner, "repo", l.cfg.Repo)
+	return orgConfig, nil
+}
@@ -0,0 +1,93 @@
+package orgconfig
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/config"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+const orgYAML = `
+custom_instructions:
+  - Follow the org style guide.
+exclude_dirs: [generated]
+min_severity: Medium
+`
+
+func newTestLoader(t *testing.T, repo string) (*Loader, *mocks.MockClient, *time.Time) {
+	ctrl := gomock.NewController(t)
+	gh := mocks.NewMockClient(ctrl)
+	l := NewLoader(config.OrgConfig{Repo: repo, RefreshInterval: 15 * time.Minute},
+		slog.New(slog.NewTextHandler(io.Discard, nil)))
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	return l, gh, &now
+}
+
+func TestLoader_Get(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled", func(t *testing.T) {
+		l, gh, _ := newTestLoader(t, "")
+		assert.Nil(t, l.Get(ctx, gh, "acme"))
+	})
+
+	t.Run("caches until refresh interval", func(t *testing.T) {
+		l, gh, now := newTestLoader(t, ".code-warden")
+		gh.EXPECT().GetFileContent(gomock.Any(), "acme", ".code-warden", ".code-warden.yml").
+			Return([]byte(orgYAML), nil).Times(2)
+
+		got := l.Get(ctx, gh, "acme")
+		require.NotNil(t, got)
+		assert.Equal(t, []string{"Follow the org style guide."}, got.CustomInstructions)
+		assert.Equal(t, []string{"generated"}, got.ExcludeDirs)
+		assert.Equal(t, "Medium", got.MinSeverity)
+
+		*now = now.Add(10 * time.Minute)
+		assert.Same(t, got, l.Get(ctx, gh, "acme"), "served from cache")
+
+		*now = now.Add(10 * time.Minute)
+		assert.NotNil(t, l.Get(ctx, gh, "acme"))
+	})
+
+	t.Run("missing file is cached", func(t *testing.T) {
+		l, gh, _ := newTestLoader(t, ".code-warden")
+		gh.EXPECT().GetFileContent(gomock.Any(), "acme", ".code-warden", ".code-warden.yml").
+			Return(nil, internalgithub.ErrNotFound).Times(1)
+
+		assert.Nil(t, l.Get(ctx, gh, "acme"))
+		assert.Nil(t, l.Get(ctx, gh, "acme"))
+	})
+
+	t.Run("keeps stale defaults on error", func(t *testing.T) {
+		l, gh, now := newTestLoader(t, ".code-warden")
+		gomock.InOrder(
+			gh.EXPECT().GetFileContent(gomock.Any(), "acme", ".code-warden", ".code-warden.yml").
+				Return([]byte(orgYAML), nil),
+			gh.EXPECT().GetFileContent(gomock.Any(), "acme", ".code-warden", ".code-warden.yml").
+				Return(nil, errors.New("rate limited")),
+		)
+
+		got := l.Get(ctx, gh, "acme")
+		require.NotNil(t, got)
+		*now = now.Add(time.Hour)
+		assert.Same(t, got, l.Get(ctx, gh, "acme"))
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		l, gh, _ := newTestLoader(t, ".code-warden")
+		gh.EXPECT().GetFileContent(gomock.Any(), "acme", ".code-warden", ".code-warden.yml").
+			Return([]byte("exclude_dirs: {"), nil)
+		assert.Nil(t, l.Get(ctx, gh, "acme"))
+	})
+}
@@ -0,0 +1,105 @@
+// Package pathutil normalizes the paths of repository files, which arrive
+// with Windows or Unix separators from the file system, GitHub, configuration
+// files and LLM output, to one form: relative, cleaned and slash-separated,
+// as git and GitHub name files. It is independent of the OS it runs on.
+package pathutil
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ToSlash replaces every backslash with a slash. Unlike filepath.ToSlash it
+// does so on every OS, because paths written on Windows reach Unix hosts
+// through configuration files and LLM output.
+func ToSlash(p string) string {
+	return strings.ReplaceAll(p, `\`, "/")
+}
+
+// Clean returns the slash-separated, cleaned form of a repository-relative
+// path without a leading "./". The empty path and the root are ".".
+func Clean(p string) string {
+	return path.Clean(ToSlash(p))
+}
+
+// Rel returns target relative to root in the form of Clean.
+func Rel(root, target string) (string, error) {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return "", err
+	}
+	return Clean(rel), nil
+}
+
+// IsAbs reports whether p is absolute on any OS: rooted with a slash or a
+// backslash, a UNC path, or starting with a drive letter like "C:".
+func IsAbs(p string) bool {
+	if strings.HasPrefix(p, "/") || strings.HasPrefix(p, `\`) {
+		return true
+	}
+	return hasDriveLetter(p)
+}
+
+func hasDriveLetter(p string) bool {
+	if len(p) < 2 || p[1] != ':' {
+		return false
+	}
+	c := p[0] | 0x20 // lower case
+	return c >= 'a' && c <= 'z'
+}
+
+// EscapesRoot reports whether the relative path p leaves the directory it is
+// relative to, like "../x" or "a/../../x".
+func EscapesRoot(p string) bool {
+	clean := Clean(p)
+	return clean == ".." || strings.HasPrefix(clean, "../")
+}
+
+// IsLocal reports whether p is a relative path that stays within its root,
+// with either separator.
+func IsLocal(p string) bool {
+	return p != "" && !IsAbs(p) && !EscapesRoot(p)
+}
+
+// Within reports whether the repository-relative path p is dir or lies
+// below it.
+func Within(p, dir string) bool {
+	p, dir = Clean(p), Clean(dir)
+	return p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// FromFileURL converts a file URL, as editors and Windows tools hand out
+// ("file:///C:/src/app", "file://server/share/app", "file:///home/me/app"),
+// to a local path of the running OS. Other strings are returned unchanged.
+func FromFileURL(s string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(s), "file:") {
+		return s, nil
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid file URL %q: %w", s, err)
+	}
+	p := u.Path
+	if p == "" {
+		p = u.Opaque
+	}
+	if p == "" {
+		return "", errors.New("file URL has no path")
+	}
+	switch {
+	case u.Host != "" && u.Host != "localhost":
+		// A UNC path: file://server/share/dir.
+		p = "//" + u.Host + p
+	case hasDriveLetter(strings.TrimPrefix(p, "/")):
+		p = strings.TrimPrefix(p, "/")
+	}
+	if runtime.GOOS == "windows" {
+		return strings.ReplaceAll(p, "/", `\`), nil
+	}
+	return p, nil
+}
@@ -0,0 +1,86 @@
+package pathutil
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClean(t *testing.T) {
+	tests := map[string]string{
+		`internal\llm\parser.go`: "internal/llm/parser.go",
+		`.\cmd\cli\`:             "cmd/cli",
+		"./a//b/../c.go":         "a/c.go",
+		"":                       ".",
+		`src\..\..\x`:            "../x",
+	}
+	for in, want := range tests {
+		assert.Equal(t, want, Clean(in), "Clean(%q)", in)
+	}
+}
+
+func TestRel(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "repo")
+	rel, err := Rel(root, filepath.Join(root, "internal", "app.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "internal/app.go", rel)
+}
+
+func TestIsAbsAndEscapesRoot(t *testing.T) {
+	tests := []struct {
+		path    string
+		abs     bool
+		escapes bool
+	}{
+		{path: "internal/app.go"},
+		{path: `internal\app.go`},
+		{path: "/etc/passwd", abs: true},
+		{path: `\Windows\system32`, abs: true},
+		{path: `\\server\share\x`, abs: true},
+		{path: `C:\src\app`, abs: true},
+		{path: "c:/src/app", abs: true},
+		{path: "../secret", escapes: true},
+		{path: `a\..\..\secret`, escapes: true},
+		{path: "a/../b"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.abs, IsAbs(tt.path), "IsAbs(%q)", tt.path)
+		assert.Equal(t, tt.escapes, EscapesRoot(tt.path), "EscapesRoot(%q)", tt.path)
+		assert.Equal(t, !tt.abs && !tt.escapes, IsLocal(tt.path), "IsLocal(%q)", tt.path)
+	}
+}
+
+func TestWithin(t *testing.T) {
+	assert.True(t, Within(`vendor\lib\a.go`, "vendor"))
+	assert.True(t, Within("vendor", "vendor/"))
+	assert.True(t, Within("a/b/c.go", `a\b`))
+	assert.False(t, Within("vendors/a.go", "vendor"))
+	assert.False(t, Within("a.go", "vendor"))
+}
+
+func TestFromFileURL(t *testing.T) {
+	native := func(p string) string {
+		if runtime.GOOS == "windows" {
+			return filepath.FromSlash(p)
+		}
+		return p
+	}
+	tests := map[string]string{
+		"file:///home/me/app":       native("/home/me/app"),
+		"file:///C:/src/my%20app":   native("C:/src/my app"),
+		"FILE://localhost/srv/repo": native("/srv/repo"),
+		"file://server/share/app":   native("//server/share/app"),
+		"/already/a/path":           "/already/a/path",
+		`C:\src\app`:                `C:\src\app`,
+	}
+	for in, want := range tests {
+		got, err := FromFileURL(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, "FromFileURL(%q)", in)
+	}
+	_, err := FromFileURL("file:")
+	assert.Error(t, err)
+}
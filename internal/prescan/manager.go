@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/sevigo/code-warden/internal/artifacts"
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/gitutil"
@@ -20,15 +21,17 @@ type Manager struct {
 	store     storage.Store
 	repoMgr   repomanager.RepoManager
 	gitClient *gitutil.Client
+	artifacts *artifacts.Store
 	logger    *slog.Logger
 }
 
-func NewManager(cfg *config.Config, store storage.Store, repoMgr repomanager.RepoManager, gitClient *gitutil.Client, logger *slog.Logger) *Manager {
+func NewManager(cfg *config.Config, store storage.Store, repoMgr repomanager.RepoManager, gitClient *gitutil.Client, artifactStore *artifacts.Store, logger *slog.Logger) *Manager {
 	return &Manager{
 		cfg:       cfg,
 		store:     store,
 		repoMgr:   repoMgr,
 		gitClient: gitClient,
+		artifacts: artifactStore,
 		logger:    logger,
 	}
 }
@@ -11,6 +11,7 @@ import (
 
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/pathutil"
 	"github.com/sevigo/code-warden/internal/rag"
 	"github.com/sevigo/code-warden/internal/rag/review"
 	"github.com/sevigo/code-warden/internal/repomanager"
@@ -32,7 +33,10 @@ func NewScanner(m *Manager, rag rag.Service) *Scanner {
 	}
 }
 
-func (s *Scanner) generateAndSaveDocumentation(localPath string) (map[string]any, error) {
+// generateAndSaveDocumentation writes project_structure.md to the artifact
+// store (never into the clone, where it would dirty the worktree) and returns
+// it for the scan state.
+func (s *Scanner) generateAndSaveDocumentation(ctx context.Context, repoFullName, localPath string) (map[string]any, error) {
 	docGen := NewDocGenerator(localPath)
 	structure, err := docGen.GenerateProjectStructure(localPath)
 
@@ -41,15 +45,11 @@ func (s *Scanner) generateAndSaveDocumentation(localPath string) (map[string]any
 		return nil, err
 	}
 
-	// Save locally (0600 per gosec)
-	docPath := filepath.Join(localPath, "project_structure.md")
-	if err := os.WriteFile(docPath, []byte(structure), 0600); err != nil {
+	if err := s.Manager.artifacts.Save(ctx, repoFullName, "project_structure.md", structure); err != nil {
 		s.Manager.logger.Error("Failed to save project structure", "error", err)
-		// We return the error but maybe we should allow partial success?
-		// For now, let's return error as it's cleaner.
 		return nil, err
 	}
-	s.Manager.logger.Info("Generated project documentation", "path", docPath)
+	s.Manager.logger.Info("Generated project documentation", "repo", repoFullName)
 
 	// Prepare for DB
 	return map[string]any{
@@ -82,7 +82,7 @@ func (s *Scanner) Scan(ctx context.Context, input string, force, verbose, genera
 	// 3. Early check for context generation ONLY
 	if generateContextOnly {
 		s.Manager.logger.Info("Running Context Generation ONLY mode")
-		contextDoc, err := s.RAGService.GenerateProjectContext(ctx, repoRecord.QdrantCollectionName, s.Manager.cfg.AI.EmbedderModel)
+		contextDoc, err := s.RAGService.GenerateProjectContext(ctx, repoRecord.QdrantCollectionName, repoRecord.Embedder(s.Manager.cfg.AI.EmbedderModel))
 		if err != nil {
 			return fmt.Errorf("failed to generate project context: %w", err)
 		}
@@ -148,12 +148,15 @@ func (s *Scanner) Scan(ctx context.Context, input string, force, verbose, genera
 	}
 
 	// 6. Post-processing: Documentation & Comparisons
-	docMap, err := s.generateAndSaveDocumentation(localPath)
+	docMap, err := s.generateAndSaveDocumentation(ctx, repoFullName, localPath)
 	if err != nil {
 		s.Manager.logger.Warn("Failed to generate documentation artifacts", "error", err)
 		docMap = make(map[string]any)
 	}
-	s.generateArchitecturalComparisons(ctx, localPath)
+	s.generateArchitecturalComparisons(ctx, repoFullName, localPath)
+	if _, err := s.Manager.artifacts.Prune(ctx, repoFullName); err != nil {
+		s.Manager.logger.Warn("Failed to prune old artifacts", "error", err)
+	}
 
 	if err := stateMgr.SaveState(ctx, StatusCompleted, progress, docMap); err != nil {
 		return err
@@ -173,7 +176,7 @@ func (s *Scanner) Scan(ctx context.Context, input string, force, verbose, genera
 // autoGenerateProjectContext generates and saves a project context document.
 func (s *Scanner) autoGenerateProjectContext(ctx context.Context, repoRecord *storage.Repository) {
 	s.Manager.logger.Info("Auto-generating Project Context after successful scan")
-	contextDoc, err := s.RAGService.GenerateProjectContext(ctx, repoRecord.QdrantCollectionName, s.Manager.cfg.AI.EmbedderModel)
+	contextDoc, err := s.RAGService.GenerateProjectContext(ctx, repoRecord.QdrantCollectionName, repoRecord.Embedder(s.Manager.cfg.AI.EmbedderModel))
 	if err != nil {
 		s.Manager.logger.Warn("failed to update project context automatically", "error", err)
 		return
@@ -193,7 +196,7 @@ func (s *Scanner) autoGenerateProjectContext(ctx context.Context, repoRecord *st
 	s.Manager.logger.Info("✅ Project Context successfully updated in database")
 }
 
-func (s *Scanner) generateArchitecturalComparisons(ctx context.Context, localPath string) {
+func (s *Scanner) generateArchitecturalComparisons(ctx context.Context, repoFullName, localPath string) {
 	if len(s.Manager.cfg.AI.ComparisonModels) == 0 {
 		return
 	}
@@ -220,7 +223,6 @@ func (s *Scanner) generateArchitecturalComparisons(ctx context.Context, localPat
 	for modelName, summaries := range results {
 		sanitizedModel := review.SanitizeModelForFilename(modelName)
 		fileName := fmt.Sprintf("arch_comparison_%s.md", sanitizedModel)
-		filePath := filepath.Join(localPath, fileName)
 
 		var sb strings.Builder
 		fmt.Fprintf(&sb, "# Architectural comparison: %s\n\n", modelName)
@@ -228,7 +230,7 @@ func (s *Scanner) generateArchitecturalComparisons(ctx context.Context, localPat
 			fmt.Fprintf(&sb, "## Directory: %s\n\n%s\n\n", path, summary)
 		}
 
-		if err := os.WriteFile(filePath, []byte(sb.String()), 0600); err != nil {
+		if err := s.Manager.artifacts.Save(ctx, repoFullName, fileName, sb.String()); err != nil {
 			s.Manager.logger.Warn("Failed to save comparison file", "file", fileName, "error", err)
 		}
 	}
@@ -381,7 +383,7 @@ func (s *Scanner) ensureRepoRecord(ctx context.Context, fullName, path string) (
 		return newRec, nil
 	}
 
-	if rec.QdrantCollectionName != repomanager.GenerateCollectionName(fullName) {
+	if !repomanager.IsCollectionOf(rec.QdrantCollectionName, fullName) {
 		s.Manager.logger.Warn("Collection name mismatch, updating",
 			"old_collection", rec.QdrantCollectionName, "new_collection", repomanager.GenerateCollectionName(fullName))
 
@@ -405,26 +407,33 @@ func (s *Scanner) ensureRepoRecord(ctx context.Context, fullName, path string) (
 }
 
 func (s *Scanner) listFiles(root string, repoConfig *core.RepoConfig) ([]string, error) {
+	if repoConfig == nil {
+		repoConfig = core.DefaultRepoConfig()
+	}
+	ignore, err := config.LoadIgnorePatterns(root, !repoConfig.Indexing.DisableGitignore)
+	if err != nil {
+		s.Manager.logger.Warn("Failed to read ignore files, listing without them", "error", err)
+	}
+	paths := repoConfig.PathFilter(ignore)
 	var files []string
-	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		rel, err := filepath.Rel(root, path)
+		rel, err := pathutil.Rel(root, path)
 		if err != nil {
 			return err
 		}
-		rel = strings.ReplaceAll(rel, "\\", "/")
 
 		if info.IsDir() {
-			if s.shouldExcludeDir(info.Name(), repoConfig) {
+			if s.shouldExcludeDir(info.Name(), repoConfig) || (rel != "." && paths.SkipsDir(rel)) {
 				return filepath.SkipDir
 			}
 			return nil
 		}
 
-		if s.shouldExcludeFile(rel, path, repoConfig) {
+		if s.shouldExcludeFile(rel, path, repoConfig) || !paths.Allows(rel) {
 			return nil
 		}
 
@@ -475,9 +484,9 @@ func (s *Scanner) shouldExcludeFile(rel, absPath string, repoConfig *core.RepoCo
 	}
 
 	// Filter by RepoConfig specific files (with proper path normalization)
-	cleanRel := filepath.ToSlash(filepath.Clean(rel))
+	cleanRel := pathutil.Clean(rel)
 	for _, excludeFile := range repoConfig.ExcludeFiles {
-		if cleanRel == filepath.ToSlash(filepath.Clean(excludeFile)) {
+		if cleanRel == pathutil.Clean(excludeFile) {
 			return true
 		}
 	}
@@ -3,8 +3,11 @@ package prescan
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/sevigo/code-warden/internal/core"
 )
 
 func TestValidateRepoPath(t *testing.T) {
@@ -140,3 +143,69 @@ func TestValidateRepoPath_AdvancedSymlinks(t *testing.T) {
 		}
 	})
 }
+
+func TestListFiles_Patterns(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"main.go", "README.md", "src/app.go", "src/app_test.go", "src/testdata/in.json", "vendor/lib.go", "scripts/run.py"} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &Scanner{}
+	files, err := s.listFiles(root, &core.RepoConfig{
+		Include: []string{"src/", "*.md"},
+		Exclude: []string{"testdata/", "*_test.go"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(files)
+	want := []string{"README.md", "src/app.go"}
+	if !slices.Equal(files, want) {
+		t.Errorf("listFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestListFiles_IgnoreFiles(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":    "dist/\n*.log.md\n",
+		".wardenignore": "fixtures/\n",
+		"main.go":       "x",
+		"dist/app.json": "x",
+		"notes.log.md":  "x",
+		"fixtures/a.go": "x",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &Scanner{}
+	got, err := s.listFiles(root, core.DefaultRepoConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"main.go"}; !slices.Equal(got, want) {
+		t.Errorf("listFiles() = %v, want %v", got, want)
+	}
+
+	got, err = s.listFiles(root, &core.RepoConfig{Indexing: core.IndexingConfig{DisableGitignore: true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(got)
+	if want := []string{"dist/app.json", "main.go", "notes.log.md"}; !slices.Equal(got, want) {
+		t.Errorf("listFiles() without .gitignore = %v, want %v", got, want)
+	}
+}
@@ -19,8 +19,10 @@ import (
 	"github.com/sevigo/goframe/vectorstores"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/sevigo/code-warden/internal/pathutil"
 	indexpkg "github.com/sevigo/code-warden/internal/rag/index"
 
+	"github.com/sevigo/code-warden/internal/core"
 	internalgithub "github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/llm"
 	"github.com/sevigo/code-warden/internal/storage"
@@ -46,68 +48,182 @@ type DirectoryInfo struct {
 }
 
 // GenerateArchSummaries generates architectural summaries for directories.
-// If targetPaths is empty, all directories are processed.
+// If targetPaths is empty, all directories are processed. Otherwise only the
+// directories containing targetPaths and their parents are checked, which is
+// how incremental syncs keep the summaries fresh between full scans.
+// Regenerated summaries replace the stored ones, and directories that were
+// deleted or no longer contain code lose their summary.
 func (b *builderImpl) GenerateArchSummaries(ctx context.Context, collectionName, embedderModelName, repoPath string, targetPaths []string) error {
-	b.cfg.Logger.Info("generating architectural summaries",
+	b.cfg.Logger.InfoContext(ctx, "generating architectural summaries",
 		"collection", collectionName,
 		"repoPath", repoPath,
 		"target_paths_count", len(targetPaths),
 	)
 
+	var targetDirs []string
+	if len(targetPaths) > 0 {
+		targetDirs = b.targetDirectories(repoPath, targetPaths)
+	}
+	_, err := b.generateArchSummaries(ctx, collectionName, embedderModelName, repoPath, targetDirs, false)
+	return err
+}
+
+// RegenerateArchSummaries regenerates the architectural summaries of dir and
+// the directories below it, or of every directory when dir is empty, even
+// where the stored summary is up to date. It returns the number of summaries
+// stored.
+func (b *builderImpl) RegenerateArchSummaries(ctx context.Context, collectionName, embedderModelName, repoPath, dir string) (int, error) {
+	b.cfg.Logger.InfoContext(ctx, "regenerating architectural summaries",
+		"collection", collectionName,
+		"repoPath", repoPath,
+		"dir", dir,
+	)
+
+	var targetDirs []string
+	if dir != "" && filepath.Clean(dir) != "." {
+		var err error
+		if targetDirs, err = b.subdirectories(repoPath, dir); err != nil {
+			return 0, err
+		}
+	}
+	return b.generateArchSummaries(ctx, collectionName, embedderModelName, repoPath, targetDirs, true)
+}
+
+// subdirectories returns dir, relative to repoPath, and the directories
+// below it, skipping hidden ones as a full scan does.
+func (b *builderImpl) subdirectories(repoPath, dir string) ([]string, error) {
+	fullPath, err := b.validateAndJoinPath(repoPath, dir)
+	if err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repo path: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+
+	var dirs []string
+	err = filepath.WalkDir(fullPath, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != fullPath && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, relPath)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", dir, err)
+	}
+	return dirs, nil
+}
+
+// generateArchSummaries updates the summaries of targetDirs, or of every
+// directory when targetDirs is empty. With force, summaries are regenerated
+// even when their stored content hash is current. It returns the number of
+// summaries stored.
+func (b *builderImpl) generateArchSummaries(ctx context.Context, collectionName, embedderModelName, repoPath string, targetDirs []string, force bool) (int, error) {
+	ctx = core.WithUsageStep(ctx, core.UsageStepSummary)
 	scopedStore := b.cfg.VectorStore.ForRepo(collectionName, embedderModelName)
-	summaryCache := b.fetchSummaryCache(ctx, scopedStore)
+
+	summaryCache := b.fetchSummaryCache(ctx, scopedStore, archSources(targetDirs))
+	if force {
+		// An empty hash never matches, so every directory is queued, while
+		// the cached sources still reveal summaries of deleted directories.
+		for source := range summaryCache {
+			summaryCache[source] = ""
+		}
+	}
 
 	// Walk filesystem to discover directories and check cache
-	dirsToProcess, cachedCount, err := b.discoverDirectories(repoPath, targetPaths, summaryCache)
+	scan, err := b.discoverDirectories(repoPath, targetDirs, summaryCache)
 	if err != nil {
-		return fmt.Errorf("failed to walk directories: %w", err)
+		return 0, fmt.Errorf("failed to walk directories: %w", err)
 	}
 
-	b.cfg.Logger.Info("architectural summary cache check complete",
-		"cached", cachedCount,
-		"queued", len(dirsToProcess),
+	b.cfg.Logger.InfoContext(ctx, "architectural summary cache check complete",
+		"cached", scan.cached,
+		"queued", len(scan.toProcess),
+		"removed", len(scan.removed),
 	)
 
-	if len(dirsToProcess) == 0 {
-		return nil
+	if len(scan.toProcess) == 0 && len(scan.removed) == 0 {
+		return 0, nil
 	}
 
-	// Hydrate directory metadata and generate summaries
+	workers := b.cfg.AIConfig.SummaryWorkers
+	if workers < 1 {
+		workers = defaultSummaryWorkers
+	}
+	archDocs := b.generateSummariesWithWorkerPool(ctx, scan.toProcess, workers)
 
-	// Generate summaries with a worker pool
-	// Use 5 workers by default for better throughput with LLM API rate limits
-	const defaultArchSummaryWorkers = 5
-	archDocs := b.generateSummariesWithWorkerPool(ctx, dirsToProcess, defaultArchSummaryWorkers)
+	// Drop the old summaries of regenerated directories so that lookups by
+	// source never find an outdated one next to the new one.
+	stale := scan.removed
+	for _, doc := range archDocs {
+		if source, ok := doc.Metadata["source"].(string); ok {
+			stale = append(stale, source)
+		}
+	}
+	if len(stale) > 0 {
+		if err := scopedStore.DeleteDocumentsByFilter(ctx, map[string]any{
+			"chunk_type": "arch",
+			"source":     stale,
+		}); err != nil {
+			return 0, fmt.Errorf("failed to delete stale architectural summaries: %w", err)
+		}
+	}
 
 	if len(archDocs) == 0 {
-		b.cfg.Logger.Warn("no architectural summaries generated")
-		return nil
+		if len(scan.toProcess) > 0 {
+			b.cfg.Logger.WarnContext(ctx, "no architectural summaries generated")
+		}
+		return 0, nil
 	}
 
 	// Store the architectural summaries
 	_, err = scopedStore.AddDocuments(ctx, archDocs)
 	if err != nil {
-		return fmt.Errorf("failed to store architectural summaries: %w", err)
+		return 0, fmt.Errorf("failed to store architectural summaries: %w", err)
 	}
 
-	b.cfg.Logger.Info("architectural summaries generated and stored",
+	b.cfg.Logger.InfoContext(ctx, "architectural summaries generated and stored",
 		"summaries", len(archDocs),
 	)
 
-	return nil
+	return len(archDocs), nil
 }
 
 // fetchSummaryCache loads existing arch summaries from the vector store for cache comparison.
-func (b *builderImpl) fetchSummaryCache(ctx context.Context, scopedStore storage.ScopedVectorStore) map[string]string {
-	searchOpts := []vectorstores.Option{
-		vectorstores.WithFilters(map[string]any{"chunk_type": "arch"}),
-	}
-	if b.cfg.AIConfig.RetrievalScoreThreshold > 0 {
+// When sources is set, only the summaries of those directories are loaded.
+// A directory with more than one summary maps to an empty hash so that it is
+// regenerated, which removes the duplicates.
+func (b *builderImpl) fetchSummaryCache(ctx context.Context, scopedStore storage.ScopedVectorStore, sources []string) map[string]string {
+	filters := map[string]any{"chunk_type": "arch"}
+	limit := 500
+	var searchOpts []vectorstores.Option
+	if len(sources) > 0 {
+		// An exact source filter finds the summaries however many directories
+		// the repository has. The extra room reveals duplicates.
+		filters["source"] = sources
+		limit = 2 * len(sources)
+	} else if b.cfg.AIConfig.RetrievalScoreThreshold > 0 {
 		searchOpts = append(searchOpts, vectorstores.WithScoreThreshold(b.cfg.AIConfig.RetrievalScoreThreshold))
 	}
-	cacheDocs, err := scopedStore.SimilaritySearch(ctx, "summary", 500, searchOpts...)
+	searchOpts = append(searchOpts, vectorstores.WithFilters(filters))
+	cacheDocs, err := scopedStore.SimilaritySearch(ctx, "summary", limit, searchOpts...)
 	if err != nil {
-		b.cfg.Logger.Warn("failed to fetch existing summaries for cache", "error", err)
+		b.cfg.Logger.WarnContext(ctx, "failed to fetch existing summaries for cache", "error", err)
 		return make(map[string]string)
 	}
 
@@ -115,48 +231,23 @@ func (b *builderImpl) fetchSummaryCache(ctx context.Context, scopedStore storage
 	for _, doc := range cacheDocs {
 		source, _ := doc.Metadata["source"].(string)
 		hash, _ := doc.Metadata["content_hash"].(string)
-		if source != "" {
-			summaryCache[source] = hash
+		if source == "" {
+			continue
+		}
+		if _, dup := summaryCache[source]; dup {
+			summaryCache[source] = ""
+			continue
 		}
+		summaryCache[source] = hash
 	}
-	b.cfg.Logger.Debug("built summary cache from qdrant", "count", len(summaryCache))
+	b.cfg.Logger.DebugContext(ctx, "built summary cache from qdrant", "count", len(summaryCache))
 	return summaryCache
 }
 
-// discoverDirectories walks the repo and returns directories needing summary updates.
-//
-//nolint:gocognit
-func (b *builderImpl) discoverDirectories(repoPath string, targetPaths []string, summaryCache map[string]string) (map[string]*DirectoryInfo, int, error) {
-	dirsToProcess := make(map[string]*DirectoryInfo)
-	cachedCount := 0
-
-	// Recursive walk for initial indexing
-	if len(targetPaths) == 0 {
-		err := filepath.WalkDir(repoPath, func(path string, d os.DirEntry, walkErr error) error {
-			if walkErr != nil {
-				return walkErr
-			}
-			if !d.IsDir() {
-				return nil
-			}
-			if strings.HasPrefix(d.Name(), ".") && d.Name() != "." {
-				return filepath.SkipDir
-			}
-
-			relPath, _ := filepath.Rel(repoPath, path)
-			if relPath == "." {
-				relPath = rootDir
-			}
-			relPath = normalizePath(relPath)
-
-			return b.processSingleDir(repoPath, path, relPath, summaryCache, dirsToProcess, &cachedCount)
-		})
-		return dirsToProcess, cachedCount, err
-	}
-
-	// Targeted walk for incremental sync
+// targetDirectories returns the directories containing targetPaths and all
+// their parents up to the repository root ".".
+func (b *builderImpl) targetDirectories(repoPath string, targetPaths []string) []string {
 	uniqueDirs := make(map[string]struct{})
-
 	for _, p := range targetPaths {
 		_, err := b.validateAndJoinPath(repoPath, p)
 		if err != nil {
@@ -178,33 +269,108 @@ func (b *builderImpl) discoverDirectories(repoPath string, targetPaths []string,
 	// Always include root summary in targeted scans as it might change
 	uniqueDirs["."] = struct{}{}
 
-	for relDir := range uniqueDirs {
-		// Securely join using validateAndJoinPath to prevent traversal and handle symlinks correctly
-		fullPath, err := b.validateAndJoinPath(repoPath, relDir)
+	dirs := make([]string, 0, len(uniqueDirs))
+	for dir := range uniqueDirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// archSource returns the source under which the summary of relDir is stored.
+func archSource(relDir string) string {
+	if relDir == "." {
+		return rootDir
+	}
+	return pathutil.ToSlash(relDir)
+}
+
+func archSources(relDirs []string) []string {
+	if len(relDirs) == 0 {
+		return nil
+	}
+	sources := make([]string, len(relDirs))
+	for i, dir := range relDirs {
+		sources[i] = archSource(dir)
+	}
+	return sources
+}
+
+// archScan is the outcome of comparing the directories on disk with the
+// stored summaries.
+type archScan struct {
+	// toProcess are the directories whose summary is missing or outdated.
+	toProcess map[string]*DirectoryInfo
+	// removed are the sources of stored summaries whose directory was
+	// deleted or no longer contains code.
+	removed []string
+	cached  int
+	// keep are the sources whose stored summary stays: directories with code
+	// and directories that could not be scanned.
+	keep map[string]struct{}
+}
+
+// discoverDirectories walks the repo and returns directories needing summary
+// updates. An empty targetDirs walks the whole repository.
+func (b *builderImpl) discoverDirectories(repoPath string, targetDirs []string, summaryCache map[string]string) (*archScan, error) {
+	scan := &archScan{
+		toProcess: make(map[string]*DirectoryInfo),
+		keep:      make(map[string]struct{}),
+	}
+
+	if len(targetDirs) == 0 {
+		// Recursive walk for initial indexing
+		err := filepath.WalkDir(repoPath, func(path string, d os.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			if strings.HasPrefix(d.Name(), ".") && d.Name() != "." {
+				return filepath.SkipDir
+			}
+
+			relPath, _ := filepath.Rel(repoPath, path)
+			return b.processSingleDir(repoPath, path, archSource(relPath), summaryCache, scan)
+		})
 		if err != nil {
-			b.cfg.Logger.Warn("directory traversal detected or invalid path", "path", relDir, "error", err)
-			continue
+			return nil, err
 		}
+	} else {
+		// Targeted walk for incremental sync
+		for _, relDir := range targetDirs {
+			// Securely join using validateAndJoinPath to prevent traversal and handle symlinks correctly
+			fullPath, err := b.validateAndJoinPath(repoPath, relDir)
+			if err != nil {
+				b.cfg.Logger.Warn("directory traversal detected or invalid path", "path", relDir, "error", err)
+				continue
+			}
 
-		if _, err := os.Stat(fullPath); os.IsNotExist(err) {
-			continue // Directory might have been deleted
-		}
+			if _, err := os.Stat(fullPath); os.IsNotExist(err) {
+				continue // Directory might have been deleted
+			}
 
-		displayRelPath := relDir
-		if displayRelPath == "." {
-			displayRelPath = rootDir
+			if err := b.processSingleDir(repoPath, fullPath, archSource(relDir), summaryCache, scan); err != nil {
+				b.cfg.Logger.Warn("targeted scan failed for directory", "path", relDir, "error", err)
+				scan.keep[archSource(relDir)] = struct{}{}
+			}
 		}
-		displayRelPath = normalizePath(displayRelPath)
+	}
 
-		if err := b.processSingleDir(repoPath, fullPath, displayRelPath, summaryCache, dirsToProcess, &cachedCount); err != nil {
-			b.cfg.Logger.Warn("targeted scan failed for directory", "path", relDir, "error", err)
+	// In a targeted scan the cache only holds the targeted directories, so a
+	// cached source without code on disk is stale either way.
+	for source := range summaryCache {
+		if _, ok := scan.keep[source]; !ok {
+			scan.removed = append(scan.removed, source)
 		}
 	}
+	sort.Strings(scan.removed)
 
-	return dirsToProcess, cachedCount, nil
+	return scan, nil
 }
 
-func (b *builderImpl) processSingleDir(repoPath, fullPath, relPath string, summaryCache map[string]string, dirsToProcess map[string]*DirectoryInfo, cachedCount *int) error {
+func (b *builderImpl) processSingleDir(repoPath, fullPath, relPath string, summaryCache map[string]string, scan *archScan) error {
 	info, hash, scanErr := b.scanDirectoryOnDisk(repoPath, fullPath, relPath)
 	if scanErr != nil {
 		return scanErr
@@ -212,24 +378,29 @@ func (b *builderImpl) processSingleDir(repoPath, fullPath, relPath string, summa
 	if info == nil {
 		return nil
 	}
+	scan.keep[relPath] = struct{}{}
 
 	if cachedHash, ok := summaryCache[relPath]; ok && cachedHash == hash {
-		(*cachedCount)++
+		scan.cached++
 		return nil
 	}
 
 	info.ContentHash = hash
-	dirsToProcess[relPath] = info
+	scan.toProcess[relPath] = info
 	return nil
 }
 
-// generateSummariesWithWorkerPool generates summaries using a bounded worker pool.
+// generateSummariesWithWorkerPool generates summaries using a bounded worker
+// pool. Fewer workers call the LLM at once while the provider throttles.
 func (b *builderImpl) generateSummariesWithWorkerPool(ctx context.Context, dirInfos map[string]*DirectoryInfo, workers int) []schema.Document {
 	type result struct {
 		doc schema.Document
 		err error
 	}
 
+	limiter := newThrottle(workers)
+	progress := newSummaryProgress(b.cfg.Logger, "architectural summary progress", len(dirInfos))
+
 	// Create channels
 	jobs := make(chan *DirectoryInfo, len(dirInfos))
 	results := make(chan result, len(dirInfos))
@@ -237,14 +408,17 @@ func (b *builderImpl) generateSummariesWithWorkerPool(ctx context.Context, dirIn
 	// Start workers
 	var wg sync.WaitGroup
 	for range workers {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
+		wg.Go(func() {
 			for info := range jobs {
-				doc, err := b.generateSummaryForDirectory(ctx, info)
+				var doc schema.Document
+				err := b.callThrottled(ctx, limiter, info.Path, func() error {
+					var genErr error
+					doc, genErr = b.generateSummaryForDirectory(ctx, info)
+					return genErr
+				})
 				results <- result{doc: doc, err: err}
 			}
-		}()
+		})
 	}
 
 	// Send jobs
@@ -262,8 +436,9 @@ func (b *builderImpl) generateSummariesWithWorkerPool(ctx context.Context, dirIn
 	// Collect results
 	var archDocs []schema.Document
 	for res := range results {
+		progress.add(ctx, limiter, res.err != nil)
 		if res.err != nil {
-			b.cfg.Logger.Warn("failed to generate summary", "error", res.err)
+			b.cfg.Logger.WarnContext(ctx, "failed to generate summary", "error", res.err)
 			continue
 		}
 		if res.doc.PageContent != "" {
@@ -290,9 +465,9 @@ func (b *builderImpl) generateSummaryForDirectory(ctx context.Context, info *Dir
 	}
 
 	// Generate with LLM
-	response, err := llms.GenerateFromSinglePrompt(ctx, b.cfg.GeneratorLLM, prompt)
+	response, err := b.generateCheckedSummary(ctx, info.Path, prompt)
 	if err != nil {
-		return schema.Document{}, fmt.Errorf("failed to generate summary for %s: %w", info.Path, err)
+		return schema.Document{}, err
 	}
 
 	// Create the architectural summary document
@@ -309,10 +484,10 @@ func (b *builderImpl) generateSummaryForDirectory(ctx context.Context, info *Dir
 	if err == nil {
 		doc.Sparse = sparseVec
 	} else {
-		b.cfg.Logger.Debug("failed to generate sparse vector for arch summary", "path", info.Path, "error", err)
+		b.cfg.Logger.DebugContext(ctx, "failed to generate sparse vector for arch summary", "path", info.Path, "error", err)
 	}
 
-	b.cfg.Logger.Info("generated architectural summary",
+	b.cfg.Logger.InfoContext(ctx, "generated architectural summary",
 		"path", info.Path,
 		"summary_length", len(response),
 	)
@@ -326,7 +501,7 @@ func (b *builderImpl) GetArchContextForPaths(ctx context.Context, scopedStore st
 	// Extract unique directories from paths
 	dirs := make(map[string]struct{})
 	for _, p := range paths {
-		dir := path.Dir(normalizePath(p))
+		dir := path.Dir(pathutil.ToSlash(p))
 		if dir == "." {
 			dir = rootDir
 		}
@@ -358,7 +533,7 @@ func (b *builderImpl) GetArchContextForPaths(ctx context.Context, scopedStore st
 		}
 		docs, err := scopedStore.SimilaritySearch(ctx, dir, 1, archSearchOpts...)
 		if err != nil {
-			b.cfg.Logger.Debug("failed to search arch summaries", "dir", dir, "error", err)
+			b.cfg.Logger.DebugContext(ctx, "failed to search arch summaries", "dir", dir, "error", err)
 			continue
 		}
 
@@ -366,19 +541,19 @@ func (b *builderImpl) GetArchContextForPaths(ctx context.Context, scopedStore st
 			fmt.Fprintf(&archContext, "## %s\n%s\n\n", dir, docs[0].PageContent)
 			seenDirs[dir] = struct{}{}
 		} else {
-			b.cfg.Logger.Debug("no arch summary found for directory", "dir", dir)
+			b.cfg.Logger.DebugContext(ctx, "no arch summary found for directory", "dir", dir)
 		}
 	}
 
-	b.cfg.Logger.Debug("arch context assembled", "dirs_found", len(seenDirs), "dirs_queried", len(dirs))
+	b.cfg.Logger.DebugContext(ctx, "arch context assembled", "dirs_found", len(seenDirs), "dirs_queried", len(dirs))
 	return archContext.String(), nil
 }
 
 //nolint:unparam // error always nil but signature required for errgroup
 func (b *builderImpl) gatherArchContextSafe(ctx context.Context, store storage.ScopedVectorStore, files []internalgithub.ChangedFile) (string, error) {
-	b.cfg.Logger.Info("stage started", "name", "ArchitecturalContext")
+	b.cfg.Logger.InfoContext(ctx, "stage started", "name", "ArchitecturalContext")
 	ac := b.getArchContext(ctx, store, files)
-	b.cfg.Logger.Info("stage completed", "name", "ArchitecturalContext")
+	b.cfg.Logger.InfoContext(ctx, "stage completed", "name", "ArchitecturalContext")
 	return ac, nil
 }
 
@@ -386,16 +561,16 @@ func (b *builderImpl) gatherArchContextSafe(ctx context.Context, store storage.S
 //
 //nolint:unparam // error always nil but signature required for errgroup
 func (b *builderImpl) gatherPackageContextSafe(ctx context.Context, store storage.ScopedVectorStore, files []internalgithub.ChangedFile) (string, error) {
-	b.cfg.Logger.Info("stage started", "name", "PackageContext")
+	b.cfg.Logger.InfoContext(ctx, "stage started", "name", "PackageContext")
 	pc := b.getPackageContext(ctx, store, files)
-	b.cfg.Logger.Info("stage completed", "name", "PackageContext")
+	b.cfg.Logger.InfoContext(ctx, "stage completed", "name", "PackageContext")
 	return pc, nil
 }
 
 func (b *builderImpl) getPackageContext(ctx context.Context, scopedStore storage.ScopedVectorStore, files []internalgithub.ChangedFile) string {
 	dirs := make(map[string]struct{})
 	for _, f := range files {
-		dir := path.Dir(normalizePath(f.Filename))
+		dir := path.Dir(pathutil.ToSlash(f.Filename))
 		if dir == "." {
 			dir = rootDir
 		}
@@ -423,7 +598,7 @@ func (b *builderImpl) getPackageContext(ctx context.Context, scopedStore storage
 		}
 		docs, err := scopedStore.SimilaritySearch(ctx, dir, 1, pkgSearchOpts...)
 		if err != nil {
-			b.cfg.Logger.Warn("failed to search package summaries", "dir", dir, "error", err)
+			b.cfg.Logger.WarnContext(ctx, "failed to search package summaries", "dir", dir, "error", err)
 			continue
 		}
 
@@ -435,9 +610,9 @@ func (b *builderImpl) getPackageContext(ctx context.Context, scopedStore storage
 	}
 
 	if foundCount == 0 && len(dirs) > 0 {
-		b.cfg.Logger.Warn("package context not found for any directories", "dirs_queried", len(dirs))
+		b.cfg.Logger.WarnContext(ctx, "package context not found for any directories", "dirs_queried", len(dirs))
 	} else {
-		b.cfg.Logger.Debug("package context assembled", "dirs_found", foundCount, "dirs_queried", len(dirs))
+		b.cfg.Logger.DebugContext(ctx, "package context assembled", "dirs_found", foundCount, "dirs_queried", len(dirs))
 	}
 	return pkgContext.String()
 }
@@ -446,9 +621,9 @@ func (b *builderImpl) getPackageContext(ctx context.Context, scopedStore storage
 //
 //nolint:unparam // error always nil but signature required for errgroup
 func (b *builderImpl) gatherRelationsContextSafe(ctx context.Context, store storage.ScopedVectorStore, files []internalgithub.ChangedFile) (string, error) {
-	b.cfg.Logger.Info("stage started", "name", "RelationsContext")
+	b.cfg.Logger.InfoContext(ctx, "stage started", "name", "RelationsContext")
 	rc := b.getRelationsContext(ctx, store, files)
-	b.cfg.Logger.Info("stage completed", "name", "RelationsContext")
+	b.cfg.Logger.InfoContext(ctx, "stage completed", "name", "RelationsContext")
 	return rc, nil
 }
 
@@ -462,7 +637,7 @@ func (b *builderImpl) getRelationsContext(ctx context.Context, scopedStore stora
 	foundCount := 0
 
 	for _, f := range files {
-		file := normalizePath(f.Filename)
+		file := pathutil.ToSlash(f.Filename)
 		if _, seen := seenFiles[file]; seen {
 			continue
 		}
@@ -475,7 +650,7 @@ func (b *builderImpl) getRelationsContext(ctx context.Context, scopedStore stora
 		}
 		docs, err := scopedStore.SimilaritySearch(ctx, file, 1, relSearchOpts...)
 		if err != nil {
-			b.cfg.Logger.Warn("failed to search relation summaries", "file", file, "error", err)
+			b.cfg.Logger.WarnContext(ctx, "failed to search relation summaries", "file", file, "error", err)
 			continue
 		}
 
@@ -487,18 +662,13 @@ func (b *builderImpl) getRelationsContext(ctx context.Context, scopedStore stora
 	}
 
 	if foundCount == 0 && len(files) > 0 {
-		b.cfg.Logger.Warn("relations context not found for any files", "files_queried", len(files))
+		b.cfg.Logger.WarnContext(ctx, "relations context not found for any files", "files_queried", len(files))
 	} else {
-		b.cfg.Logger.Debug("relations context assembled", "files_found", foundCount, "files_queried", len(files))
+		b.cfg.Logger.DebugContext(ctx, "relations context assembled", "files_found", foundCount, "files_queried", len(files))
 	}
 	return relContext.String()
 }
 
-// normalizePath converts Windows-style backslashes to forward slashes.
-func normalizePath(p string) string {
-	return strings.ReplaceAll(p, "\\", "/")
-}
-
 func (b *builderImpl) getArchContext(ctx context.Context, scopedStore storage.ScopedVectorStore, files []internalgithub.ChangedFile) string {
 	filePaths := make([]string, len(files))
 	for i, f := range files {
@@ -506,11 +676,11 @@ func (b *builderImpl) getArchContext(ctx context.Context, scopedStore storage.Sc
 	}
 	archContext, err := b.GetArchContextForPaths(ctx, scopedStore, filePaths)
 	if err != nil {
-		b.cfg.Logger.Warn("failed to get architectural context", "error", err)
+		b.cfg.Logger.WarnContext(ctx, "failed to get architectural context", "error", err)
 		return ""
 	}
 	if archContext != "" {
-		b.cfg.Logger.Debug("retrieved architectural context", "folders_count", len(filePaths))
+		b.cfg.Logger.DebugContext(ctx, "retrieved architectural context", "folders_count", len(filePaths))
 	}
 	return archContext
 }
@@ -640,7 +810,8 @@ func dedupeAndSort(items []string, maxLen int) []string {
 //
 
 func (b *builderImpl) GenerateComparisonSummaries(ctx context.Context, models []string, repoPath string, relPaths []string) (map[string]map[string]string, error) {
-	b.cfg.Logger.Info("generating multi-directory comparison summaries", "models", models, "paths", relPaths)
+	ctx = core.WithUsageStep(ctx, core.UsageStepSummary)
+	b.cfg.Logger.InfoContext(ctx, "generating multi-directory comparison summaries", "models", models, "paths", relPaths)
 
 	results := make(map[string]map[string]string)
 	resultsMu := &sync.RWMutex{}
@@ -653,15 +824,22 @@ func (b *builderImpl) GenerateComparisonSummaries(ctx context.Context, models []
 		if llm, err := b.cfg.GetLLM(ctx, modelName); err == nil {
 			llmInstances[modelName] = llm
 		} else {
-			b.cfg.Logger.Warn("failed to pre-fetch LLM", "model", modelName, "error", err)
+			b.cfg.Logger.WarnContext(ctx, "failed to pre-fetch LLM", "model", modelName, "error", err)
 		}
 	}
 
+	concurrency := b.cfg.AIConfig.ComparisonConcurrency
+	if concurrency < 1 {
+		concurrency = defaultComparisonConcurrency
+	}
+	limiter := newThrottle(concurrency)
+	progress := newSummaryProgress(b.cfg.Logger, "comparison summary progress", len(relPaths)*len(models))
+
 	g, ctx := errgroup.WithContext(ctx)
-	sem := make(chan struct{}, 10)
+	g.SetLimit(concurrency)
 	for _, relPath := range relPaths {
 		g.Go(func() error {
-			return b.processDirectorySummaries(ctx, models, llmInstances, repoPath, relPath, results, resultsMu, sem)
+			return b.processDirectorySummaries(ctx, models, llmInstances, repoPath, relPath, results, resultsMu, limiter, progress)
 		})
 	}
 
@@ -672,15 +850,7 @@ func (b *builderImpl) GenerateComparisonSummaries(ctx context.Context, models []
 	return results, nil
 }
 
-func (b *builderImpl) processDirectorySummaries(ctx context.Context, models []string, llmInstances map[string]llms.Model, repoPath, relPath string, results map[string]map[string]string, resultsMu *sync.RWMutex, sem chan struct{}) error {
-	// Acquire semaphore
-	select {
-	case sem <- struct{}{}:
-		defer func() { <-sem }()
-	case <-ctx.Done():
-		return ctx.Err()
-	}
-
+func (b *builderImpl) processDirectorySummaries(ctx context.Context, models []string, llmInstances map[string]llms.Model, repoPath, relPath string, results map[string]map[string]string, resultsMu *sync.RWMutex, limiter *throttle, progress *summaryProgress) error {
 	path, err := b.validateAndJoinPath(repoPath, relPath)
 	if err != nil {
 		return err
@@ -688,7 +858,7 @@ func (b *builderImpl) processDirectorySummaries(ctx context.Context, models []st
 
 	info, _, err := b.scanDirectoryOnDisk(repoPath, path, relPath)
 	if err != nil {
-		b.cfg.Logger.Warn("failed to scan directory for comparison", "path", relPath, "error", err)
+		b.cfg.Logger.WarnContext(ctx, "failed to scan directory for comparison", "path", relPath, "error", err)
 		return nil
 	}
 	if info == nil {
@@ -699,7 +869,8 @@ func (b *builderImpl) processDirectorySummaries(ctx context.Context, models []st
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
-		summary := b.generateSingleSummary(ctx, info, llmInstances[modelName])
+		summary, ok := b.generateSingleSummary(ctx, limiter, info, llmInstances[modelName])
+		progress.add(ctx, limiter, !ok)
 		resultsMu.Lock()
 		results[modelName][relPath] = summary
 		resultsMu.Unlock()
@@ -770,9 +941,9 @@ func (b *builderImpl) validateAndJoinPath(repoPath, relPath string) (string, err
 	return resolvedPath, nil
 }
 
-func (b *builderImpl) generateSingleSummary(ctx context.Context, info *DirectoryInfo, generator llms.Model) string {
+func (b *builderImpl) generateSingleSummary(ctx context.Context, limiter *throttle, info *DirectoryInfo, generator llms.Model) (string, bool) {
 	if generator == nil {
-		return "Error: LLM not initialized"
+		return "Error: LLM not initialized", false
 	}
 
 	promptData := ArchSummaryData{
@@ -784,20 +955,26 @@ func (b *builderImpl) generateSingleSummary(ctx context.Context, info *Directory
 
 	prompt, err := b.cfg.PromptMgr.Render(llm.ArchSummaryPrompt, promptData)
 	if err != nil {
-		return fmt.Sprintf("Error rendering prompt: %v", err)
+		return fmt.Sprintf("Error rendering prompt: %v", err), false
 	}
 
-	summary, err := llms.GenerateFromSinglePrompt(ctx, generator, prompt)
+	var summary string
+	err = b.callThrottled(ctx, limiter, info.Path, func() error {
+		var genErr error
+		summary, genErr = llms.GenerateFromSinglePrompt(ctx, generator, prompt)
+		return genErr
+	})
 	if err != nil {
-		return fmt.Sprintf("Generation Error: %v", err)
+		return fmt.Sprintf("Generation Error: %v", err), false
 	}
-	return summary
+	return summary, true
 }
 
 // GeneratePackageSummaries creates package-level summaries and cross-file relation chunks
 // by analyzing all indexed documents in the vector store.
 func (b *builderImpl) GeneratePackageSummaries(ctx context.Context, collectionName, embedderModelName string) error {
-	b.cfg.Logger.Info("generating package-level summaries", "collection", collectionName)
+	ctx = core.WithUsageStep(ctx, core.UsageStepSummary)
+	b.cfg.Logger.InfoContext(ctx, "generating package-level summaries", "collection", collectionName)
 
 	scopedStore := b.cfg.VectorStore.ForRepo(collectionName, embedderModelName)
 
@@ -805,14 +982,14 @@ func (b *builderImpl) GeneratePackageSummaries(ctx context.Context, collectionNa
 	if err := b.cfg.VectorStore.DeleteDocumentsFromCollectionByFilter(ctx, collectionName, embedderModelName, map[string]any{
 		"chunk_type": map[string]any{"$in": []string{"package", "relations"}},
 	}); err != nil {
-		b.cfg.Logger.Warn("failed to delete old package/relation chunks", "error", err)
+		b.cfg.Logger.WarnContext(ctx, "failed to delete old package/relation chunks", "error", err)
 	}
 
 	tocDocs, err := scopedStore.SimilaritySearch(ctx, "package exports definitions", 500,
 		vectorstores.WithFilters(map[string]any{"chunk_type": "toc"}),
 	)
 	if err != nil {
-		b.cfg.Logger.Warn("failed to fetch TOC documents for package summaries", "error", err)
+		b.cfg.Logger.WarnContext(ctx, "failed to fetch TOC documents for package summaries", "error", err)
 		return nil
 	}
 
@@ -820,7 +997,7 @@ func (b *builderImpl) GeneratePackageSummaries(ctx context.Context, collectionNa
 		vectorstores.WithFilters(map[string]any{"chunk_type": "definition"}),
 	)
 	if err != nil {
-		b.cfg.Logger.Warn("failed to fetch definition documents", "error", err)
+		b.cfg.Logger.WarnContext(ctx, "failed to fetch definition documents", "error", err)
 		return nil
 	}
 
@@ -839,18 +1016,18 @@ func (b *builderImpl) GeneratePackageSummaries(ctx context.Context, collectionNa
 	packageChunks := indexpkg.BuildPackageChunks(ctx, fileDocs, b.cfg.Logger)
 	if len(packageChunks) > 0 {
 		if _, err := scopedStore.AddDocuments(ctx, packageChunks); err != nil {
-			b.cfg.Logger.Warn("failed to store package summaries", "error", err)
+			b.cfg.Logger.WarnContext(ctx, "failed to store package summaries", "error", err)
 		} else {
-			b.cfg.Logger.Info("stored package-level summaries", "count", len(packageChunks))
+			b.cfg.Logger.InfoContext(ctx, "stored package-level summaries", "count", len(packageChunks))
 		}
 	}
 
 	relationChunks := indexpkg.BuildCrossFileRelationChunks(ctx, fileDocs)
 	if len(relationChunks) > 0 {
 		if _, err := scopedStore.AddDocuments(ctx, relationChunks); err != nil {
-			b.cfg.Logger.Warn("failed to store cross-file relations", "error", err)
+			b.cfg.Logger.WarnContext(ctx, "failed to store cross-file relations", "error", err)
 		} else {
-			b.cfg.Logger.Info("stored cross-file relation summaries", "count", len(relationChunks))
+			b.cfg.Logger.InfoContext(ctx, "stored cross-file relation summaries", "count", len(relationChunks))
 		}
 	}
 
@@ -2,6 +2,8 @@ package contextpkg
 
 import (
 	"log/slog"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/sevigo/goframe/schema"
@@ -106,3 +108,57 @@ func TestGetArchContextForPaths_EmptyPaths(t *testing.T) {
 	require.NoError(t, err)
 	assert.Empty(t, result)
 }
+
+// TestGenerateArchSummaries_Targeted verifies that an incremental run only
+// checks the directories of the changed files and removes the summaries of
+// directories that no longer exist.
+func TestGenerateArchSummaries_Targeted(t *testing.T) {
+	repoPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "main.go"), []byte("package main\n"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "pkg"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "pkg", "a.go"), []byte("package pkg\n"), 0o600))
+
+	b := &builderImpl{cfg: Config{Logger: slog.Default()}}
+	_, rootHash, err := b.scanDirectoryOnDisk(repoPath, repoPath, rootDir)
+	require.NoError(t, err)
+	_, pkgHash, err := b.scanDirectoryOnDisk(repoPath, filepath.Join(repoPath, "pkg"), "pkg")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	mockVS := mocks.NewMockVectorStore(ctrl)
+	mockStore := mocks.NewMockScopedVectorStore(ctrl)
+	mockVS.EXPECT().ForRepo("repo", "embedder").Return(mockStore)
+	mockStore.EXPECT().
+		SimilaritySearch(gomock.Any(), "summary", 6, gomock.Any()).
+		Return([]schema.Document{
+			{Metadata: map[string]any{"source": rootDir, "content_hash": rootHash}},
+			{Metadata: map[string]any{"source": "pkg", "content_hash": pkgHash}},
+			{Metadata: map[string]any{"source": "gone", "content_hash": "old"}},
+		}, nil)
+	mockStore.EXPECT().
+		DeleteDocumentsByFilter(gomock.Any(), map[string]any{"chunk_type": "arch", "source": []string{"gone"}}).
+		Return(nil)
+	b.cfg.VectorStore = mockVS
+
+	err = b.GenerateArchSummaries(t.Context(), "repo", "embedder", repoPath, []string{"pkg/a.go", "gone/b.go"})
+	require.NoError(t, err)
+}
+
+// TestFetchSummaryCache_Duplicates verifies that a directory with several
+// stored summaries is treated as outdated.
+func TestFetchSummaryCache_Duplicates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockScopedVectorStore(ctrl)
+	mockStore.EXPECT().
+		SimilaritySearch(gomock.Any(), "summary", 4, gomock.Any()).
+		Return([]schema.Document{
+			{Metadata: map[string]any{"source": "pkg", "content_hash": "a"}},
+			{Metadata: map[string]any{"source": "pkg", "content_hash": "b"}},
+			{Metadata: map[string]any{"source": rootDir, "content_hash": "c"}},
+		}, nil)
+
+	b := &builderImpl{cfg: Config{Logger: slog.Default()}}
+	cache := b.fetchSummaryCache(t.Context(), mockStore, []string{rootDir, "pkg"})
+
+	assert.Equal(t, map[string]string{"pkg": "", rootDir: "c"}, cache)
+}
@@ -2,12 +2,15 @@ package contextpkg
 
 import (
 	"context"
+	"log/slog"
 	"strings"
 	"sync"
 
+	"github.com/sevigo/goframe/contextpacker"
 	"github.com/sevigo/goframe/schema"
 	"github.com/sevigo/goframe/vectorstores"
 
+	"github.com/sevigo/code-warden/internal/archgraph"
 	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/cryptoutil"
 	internalgithub "github.com/sevigo/code-warden/internal/github"
@@ -20,20 +23,29 @@ type ContextResult struct {
 	FullContext        string
 	DefinitionsContext string
 	ImpactRadius       int // number of dependent files (non-test)
+	// FailedStages names the retrieval stages that failed, e.g. because the
+	// vector store went away during the review.
+	FailedStages []string
 }
 
 // Builder defines the interface for building context.
 type Builder interface {
 	// BuildRelevantContextWithImpact is the primary method for building context.
 	// It returns context strings plus impact radius for review profile calculation.
-	BuildRelevantContextWithImpact(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prDescription string) *ContextResult
+	// Zero fields of retrieval use the defaults.
+	BuildRelevantContextWithImpact(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prDescription string, retrieval core.RetrievalSettings) *ContextResult
 	// BuildRelevantContext is kept for backward compatibility. Prefer BuildRelevantContextWithImpact.
 	BuildRelevantContext(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prDescription string) (string, string)
 	BuildContextForPrompt(docs []schema.Document) string
 	GenerateArchSummaries(ctx context.Context, collectionName, embedderModelName, repoPath string, targetPaths []string) error
+	// RegenerateArchSummaries regenerates the summaries of dir and the
+	// directories below it, or of every directory when dir is empty, even
+	// where they are up to date, and returns the number stored.
+	RegenerateArchSummaries(ctx context.Context, collectionName, embedderModelName, repoPath, dir string) (int, error)
 	GenerateComparisonSummaries(ctx context.Context, models []string, repoPath string, relPaths []string) (map[string]map[string]string, error)
 	GenerateProjectContext(ctx context.Context, collectionName, embedderModelName string) (string, error)
 	GeneratePackageSummaries(ctx context.Context, collectionName, embedderModelName string) error
+	ArchGraph(ctx context.Context, collectionName, embedderModelName, repoPath string) (*archgraph.Graph, error)
 }
 
 // builderImpl implements context building logic.
@@ -50,26 +62,57 @@ func NewBuilder(cfg Config) Builder {
 
 // BuildRelevantContext performs similarity searches to gather context for a review.
 func (b *builderImpl) BuildRelevantContext(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prDescription string) (string, string) {
-	result := b.BuildRelevantContextWithImpact(ctx, collectionName, embedderModelName, repoPath, changedFiles, prDescription)
+	result := b.BuildRelevantContextWithImpact(ctx, collectionName, embedderModelName, repoPath, changedFiles, prDescription, core.RetrievalSettings{})
 	return result.FullContext, result.DefinitionsContext
 }
 
+// Retrieval defaults, used for zero fields of core.RetrievalSettings.
+const (
+	defaultDocsPerQuery = 10
+	defaultRerankTopK   = 5
+)
+
+// withRetrievalDefaults fills the zero fields of r. A zero ContextTokens
+// keeps the budget of the configured context packer.
+func withRetrievalDefaults(r core.RetrievalSettings) core.RetrievalSettings {
+	if r.DocsPerQuery <= 0 {
+		r.DocsPerQuery = defaultDocsPerQuery
+	}
+	if r.RerankTopK <= 0 {
+		r.RerankTopK = defaultRerankTopK
+	}
+	if r.RecallSize <= 0 {
+		r.RecallSize = 2 * r.DocsPerQuery
+	}
+	return r
+}
+
 // BuildRelevantContextWithImpact performs similarity searches and returns impact radius for review profile calculation.
-func (b *builderImpl) BuildRelevantContextWithImpact(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prDescription string) *ContextResult {
+func (b *builderImpl) BuildRelevantContextWithImpact(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prDescription string, retrieval core.RetrievalSettings) *ContextResult {
 	if len(changedFiles) == 0 {
 		return &ContextResult{}
 	}
+	retrieval = withRetrievalDefaults(retrieval)
+	if retrieval.ScoreThreshold <= 0 {
+		retrieval.ScoreThreshold = b.cfg.AIConfig.RetrievalScoreThreshold
+	}
+	ctx = core.WithUsageStep(ctx, core.UsageStepRetrieval)
 
 	const defaultMaxContextFiles = 50
 	if len(changedFiles) > defaultMaxContextFiles {
-		b.cfg.Logger.Warn("truncating context files", "total", len(changedFiles), "limit", defaultMaxContextFiles)
+		b.cfg.Logger.WarnContext(ctx, "truncating context files", "total", len(changedFiles), "limit", defaultMaxContextFiles)
 		changedFiles = changedFiles[:defaultMaxContextFiles]
 	}
 
 	scopedStore := b.cfg.VectorStore.ForRepo(collectionName, embedderModelName)
-	results := b.buildContextConcurrently(ctx, collectionName, embedderModelName, repoPath, prDescription, changedFiles, scopedStore)
+	results := b.buildContextConcurrently(ctx, collectionName, embedderModelName, repoPath, prDescription, changedFiles, scopedStore, retrieval)
+	results.impactDocs = filterRetrievedDocs(results.impactDocs, retrieval)
+	results.descriptionDocs = filterRetrievedDocs(results.descriptionDocs, retrieval)
+	for i := range results.hydeResults {
+		results.hydeResults[i] = filterRetrievedDocs(results.hydeResults[i], retrieval)
+	}
 
-	b.cfg.Logger.Debug("raw context gathered",
+	b.cfg.Logger.DebugContext(ctx, "raw context gathered",
 		"arch_found", results.archContext != "",
 		"definitions_found", results.definitionsContext != "",
 		"impact_docs_count", len(results.impactDocs),
@@ -81,6 +124,12 @@ func (b *builderImpl) BuildRelevantContextWithImpact(ctx context.Context, collec
 
 	allDocs := mergeAndDedup(append(results.impactDocs, results.descriptionDocs...), b.getDocKey)
 
+	expander := b.newChunkExpander(repoPath)
+	allDocs = expander.expand(allDocs)
+	for i := range results.hydeResults {
+		results.hydeResults[i] = expander.expand(results.hydeResults[i])
+	}
+
 	var impactContext, descriptionContext string
 	if len(allDocs) > 0 {
 		var seenDocs sync.Map
@@ -88,12 +137,13 @@ func (b *builderImpl) BuildRelevantContextWithImpact(ctx context.Context, collec
 	}
 
 	testCoverageContext := b.formatTestCoverageContext(results.testCoverageDocs)
-	fullContext := b.assembleContext(ctx, results.archContext, results.tocContext, results.fileSummaryContext, impactContext, descriptionContext, results.definitionsContext, testCoverageContext, results.packageContext, results.relationContext, results.hydeResults, results.hydeIndices, changedFiles)
+	fullContext := b.assembleContext(ctx, results.archContext, results.tocContext, results.fileSummaryContext, impactContext, descriptionContext, results.definitionsContext, testCoverageContext, results.packageContext, results.relationContext, results.hydeResults, results.hydeIndices, changedFiles, retrieval.ContextTokens)
 
 	return &ContextResult{
 		FullContext:        fullContext,
 		DefinitionsContext: results.definitionsContext,
 		ImpactRadius:       impactRadius,
+		FailedStages:       results.failedStages,
 	}
 }
 
@@ -109,19 +159,32 @@ type contextResults struct {
 	testCoverageDocs   []schema.Document
 	packageContext     string
 	relationContext    string
+
+	mu           sync.Mutex
+	failedStages []string
+}
+
+// stageFailed logs the failure of a retrieval stage and records it.
+func (r *contextResults) stageFailed(logger *slog.Logger, stage string, err error) {
+	logger.Warn(stage+" context stage failed", "error", err)
+	r.mu.Lock()
+	r.failedStages = append(r.failedStages, stage)
+	r.mu.Unlock()
 }
 
 //nolint:gocognit // concurrent context building requires multiple goroutines with error handling
 func (b *builderImpl) buildContextConcurrently(
 	ctx context.Context, collectionName, embedderModelName, repoPath, prDescription string,
-	changedFiles []internalgithub.ChangedFile, scopedStore storage.ScopedVectorStore,
+	changedFiles []internalgithub.ChangedFile, scopedStore storage.ScopedVectorStore, retrieval core.RetrievalSettings,
 ) *contextResults {
 	results := &contextResults{}
 
 	// Run FileSummaryContext first to collect keywords for HyDE boosting.
 	// This stage is fast (exact filter queries) and must complete before HyDE
 	// to ensure keywords are available.
-	results.fileSummaryContext = b.gatherFileSummaryContext(ctx, scopedStore, changedFiles)
+	if !retrieval.ExcludesChunkType("toc") {
+		results.fileSummaryContext = b.gatherFileSummaryContext(ctx, scopedStore, changedFiles)
+	}
 
 	// Each stage runs independently. A failure in one stage must not cancel the
 	// others — losing arch context because HyDE hit a transient Qdrant error, or
@@ -129,27 +192,31 @@ func (b *builderImpl) buildContextConcurrently(
 	// remaining stages complete with whatever context they can assemble.
 	var wg sync.WaitGroup
 
-	wg.Go(func() {
-		arch, err := b.gatherArchContextSafe(ctx, scopedStore, changedFiles)
-		if err != nil {
-			b.cfg.Logger.Warn("arch context stage failed", "error", err)
-		}
-		results.archContext = arch
-	})
+	if !retrieval.ExcludesChunkType("arch") {
+		wg.Go(func() {
+			arch, err := b.gatherArchContextSafe(ctx, scopedStore, changedFiles)
+			if err != nil {
+				results.stageFailed(b.cfg.Logger, "arch", err)
+			}
+			results.archContext = arch
+		})
+	}
 
-	wg.Go(func() {
-		toc, err := b.gatherTOCContext(ctx, scopedStore, changedFiles)
-		if err != nil {
-			b.cfg.Logger.Warn("TOC context stage failed", "error", err)
-		}
-		results.tocContext = toc
-	})
+	if !retrieval.ExcludesChunkType("toc") {
+		wg.Go(func() {
+			toc, err := b.gatherTOCContext(ctx, scopedStore, changedFiles)
+			if err != nil {
+				results.stageFailed(b.cfg.Logger, "toc", err)
+			}
+			results.tocContext = toc
+		})
+	}
 
-	if b.cfg.AIConfig.EnableHyDE {
+	if retrieval.HyDEEnabled(b.cfg.AIConfig.EnableHyDE) {
 		wg.Go(func() {
-			res, indices, err := b.gatherHyDEContext(ctx, collectionName, embedderModelName, changedFiles)
+			res, indices, err := b.gatherHyDEContext(ctx, collectionName, embedderModelName, changedFiles, retrieval)
 			if err != nil {
-				b.cfg.Logger.Warn("HyDE context stage failed", "error", err)
+				results.stageFailed(b.cfg.Logger, "hyde", err)
 			}
 			results.hydeResults = res
 			results.hydeIndices = indices
@@ -159,44 +226,50 @@ func (b *builderImpl) buildContextConcurrently(
 	wg.Go(func() {
 		docs, err := b.gatherImpactDocs(ctx, scopedStore, repoPath, changedFiles)
 		if err != nil {
-			b.cfg.Logger.Warn("impact context stage failed", "error", err)
+			results.stageFailed(b.cfg.Logger, "impact", err)
 		}
 		results.impactDocs = filterTestDocs(docs)
 	})
 
 	if prDescription != "" {
 		wg.Go(func() {
-			docs, err := b.gatherDescriptionDocs(ctx, collectionName, embedderModelName, prDescription)
+			docs, err := b.gatherDescriptionDocs(ctx, collectionName, embedderModelName, prDescription, retrieval)
 			if err != nil {
-				b.cfg.Logger.Warn("description context stage failed", "error", err)
+				results.stageFailed(b.cfg.Logger, "description", err)
 			}
 			results.descriptionDocs = filterTestDocs(docs)
 		})
 	}
 
-	wg.Go(func() {
-		defs, err := b.gatherDefinitionsContext(ctx, scopedStore, changedFiles)
-		if err != nil {
-			b.cfg.Logger.Warn("definitions context stage failed", "error", err)
-		}
-		results.definitionsContext = defs
-	})
+	if !retrieval.ExcludesChunkType("definition") {
+		wg.Go(func() {
+			defs, err := b.gatherDefinitionsContext(ctx, scopedStore, collectionName, repoPath, changedFiles)
+			if err != nil {
+				results.stageFailed(b.cfg.Logger, "definitions", err)
+			}
+			results.definitionsContext = defs
+		})
+	}
 
-	wg.Go(func() {
-		pkg, err := b.gatherPackageContextSafe(ctx, scopedStore, changedFiles)
-		if err != nil {
-			b.cfg.Logger.Warn("package context stage failed", "error", err)
-		}
-		results.packageContext = pkg
-	})
+	if !retrieval.ExcludesChunkType("package") {
+		wg.Go(func() {
+			pkg, err := b.gatherPackageContextSafe(ctx, scopedStore, changedFiles)
+			if err != nil {
+				results.stageFailed(b.cfg.Logger, "package", err)
+			}
+			results.packageContext = pkg
+		})
+	}
 
-	wg.Go(func() {
-		rel, err := b.gatherRelationsContextSafe(ctx, scopedStore, changedFiles)
-		if err != nil {
-			b.cfg.Logger.Warn("relations context stage failed", "error", err)
-		}
-		results.relationContext = rel
-	})
+	if !retrieval.ExcludesChunkType("relations") {
+		wg.Go(func() {
+			rel, err := b.gatherRelationsContextSafe(ctx, scopedStore, changedFiles)
+			if err != nil {
+				results.stageFailed(b.cfg.Logger, "relations", err)
+			}
+			results.relationContext = rel
+		})
+	}
 
 	wg.Wait()
 
@@ -204,7 +277,7 @@ func (b *builderImpl) buildContextConcurrently(
 	if len(results.definitionsContext) > 0 {
 		docs, err := b.gatherTestCoverageContext(ctx, scopedStore, changedFiles, results.definitionsContext)
 		if err != nil {
-			b.cfg.Logger.Warn("test coverage context failed", "error", err)
+			results.stageFailed(b.cfg.Logger, "test coverage", err)
 		} else {
 			results.testCoverageDocs = docs
 		}
@@ -244,13 +317,13 @@ func (b *builderImpl) filterValidDescriptionDocs(ctx context.Context, descKeys m
 		}
 	}
 
-	b.cfg.Logger.Debug("description snippets validated", "total_candidates", len(toValidate), "valid_count", len(validSources))
+	b.cfg.Logger.DebugContext(ctx, "description snippets validated", "total_candidates", len(toValidate), "valid_count", len(validSources))
 	return validSources
 }
 
-func (b *builderImpl) gatherDescriptionDocs(ctx context.Context, collection, embedder, description string) ([]schema.Document, error) {
-	b.cfg.Logger.Info("stage started", "name", "DescriptionContext")
-	scopedStore := b.cfg.VectorStore.ForRepo(collection, embedder)
+func (b *builderImpl) gatherDescriptionDocs(ctx context.Context, collection, embedder, description string, retrieval core.RetrievalSettings) ([]schema.Document, error) {
+	b.cfg.Logger.InfoContext(ctx, "stage started", "name", "DescriptionContext")
+	scopedStore := withScoreThreshold(b.cfg.VectorStore.ForRepo(collection, embedder), retrieval.ScoreThreshold)
 
 	queryLLM, err := b.cfg.GetLLM(ctx, b.cfg.AIConfig.FastModel)
 	if err != nil {
@@ -260,22 +333,22 @@ func (b *builderImpl) gatherDescriptionDocs(ctx context.Context, collection, emb
 	retriever := vectorstores.MultiQueryRetriever{
 		Store:         scopedStore,
 		LLM:           queryLLM,
-		NumDocuments:  10,
+		NumDocuments:  retrieval.DocsPerQuery,
 		Count:         3,
 		SparseGenFunc: b.generateSparseVectorFunc("DescriptionContext"),
 	}
 
 	allDocs, err := retriever.GetRelevantDocuments(ctx, description)
 	if err != nil {
-		b.cfg.Logger.Warn("multi-query retrieval failed", "error", err)
+		b.cfg.Logger.WarnContext(ctx, "multi-query retrieval failed", "error", err)
 		return nil, err
 	}
 
-	b.cfg.Logger.Info("stage completed", "name", "DescriptionContext", "retrieved", len(allDocs))
+	b.cfg.Logger.InfoContext(ctx, "stage completed", "name", "DescriptionContext", "retrieved", len(allDocs))
 	return allDocs, nil
 }
 
-func (b *builderImpl) assembleContext(ctx context.Context, arch, toc, fileSummary, impact, description, definitions, testCoverage, pkgContext, relContext string, hyde [][]schema.Document, indices []int, files []internalgithub.ChangedFile) string {
+func (b *builderImpl) assembleContext(ctx context.Context, arch, toc, fileSummary, impact, description, definitions, testCoverage, pkgContext, relContext string, hyde [][]schema.Document, indices []int, files []internalgithub.ChangedFile, tokenBudget int) string {
 	docs := b.buildContextDocuments(arch, toc, fileSummary, impact, description, definitions, testCoverage, hyde, indices, files)
 
 	// Prepend package and relations context to the docs
@@ -294,18 +367,19 @@ func (b *builderImpl) assembleContext(ctx context.Context, arch, toc, fileSummar
 		docs = append([]schema.Document{prependDoc}, docs...)
 	}
 
-	if b.cfg.ContextPacker == nil {
-		b.cfg.Logger.Error("context packer not initialized, using limited fallback")
-		return b.fallbackConcat(docs)
+	packer := b.contextPacker(tokenBudget)
+	if packer == nil {
+		b.cfg.Logger.ErrorContext(ctx, "context packer not initialized, using limited fallback")
+		return b.fallbackConcat(docs, tokenBudget)
 	}
 
-	result, err := b.cfg.ContextPacker.Pack(ctx, docs)
+	result, err := packer.Pack(ctx, docs)
 	if err != nil {
-		b.cfg.Logger.Error("context packer failed, using limited fallback - token budget may not be enforced", "error", err)
-		return b.fallbackConcat(docs)
+		b.cfg.Logger.ErrorContext(ctx, "context packer failed, using limited fallback - token budget may not be enforced", "error", err)
+		return b.fallbackConcat(docs, tokenBudget)
 	}
 
-	b.cfg.Logger.Info("relevant context built",
+	b.cfg.Logger.InfoContext(ctx, "relevant context built",
 		"changed_files", len(files),
 		"arch_len", len(arch),
 		"file_summary_len", len(fileSummary),
@@ -323,6 +397,20 @@ func (b *builderImpl) assembleContext(ctx context.Context, arch, toc, fileSummar
 	return result.Content
 }
 
+// contextPacker returns a packer for tokenBudget, or the configured packer
+// when tokenBudget is zero or no packer can be created for it.
+func (b *builderImpl) contextPacker(tokenBudget int) *contextpacker.Packer {
+	if tokenBudget <= 0 || b.cfg.NewContextPacker == nil {
+		return b.cfg.ContextPacker
+	}
+	packer, err := b.cfg.NewContextPacker(tokenBudget)
+	if err != nil {
+		b.cfg.Logger.Warn("failed to create context packer for review budget, using default budget", "budget", tokenBudget, "error", err)
+		return b.cfg.ContextPacker
+	}
+	return packer
+}
+
 // countNonTestFileSources counts unique non-test file sources from documents.
 func countNonTestFileSources(docs []schema.Document) int {
 	seen := make(map[string]struct{})
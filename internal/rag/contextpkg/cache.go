@@ -5,11 +5,14 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/sevigo/goframe/schema"
 
+	"github.com/sevigo/code-warden/internal/archgraph"
+	"github.com/sevigo/code-warden/internal/core"
 	internalgithub "github.com/sevigo/code-warden/internal/github"
 )
 
@@ -33,12 +36,19 @@ func NewContextCache(ttl time.Duration, maxSize int) *ContextCache {
 	}
 }
 
-func (c *ContextCache) cacheKey(collection, embedderModel, repoPath, prDescription string, changedFiles []internalgithub.ChangedFile) string {
+func (c *ContextCache) cacheKey(collection, embedderModel, repoPath, prDescription string, changedFiles []internalgithub.ChangedFile, retrieval core.RetrievalSettings) string {
 	h := sha256.New()
 	h.Write([]byte(collection))
 	h.Write([]byte(embedderModel))
 	h.Write([]byte(repoPath))
 	h.Write([]byte(prDescription))
+	fmt.Fprintf(h, "%d/%d/%d", retrieval.DocsPerQuery, retrieval.RerankTopK, retrieval.ContextTokens)
+	if retrieval.HyDE != nil {
+		fmt.Fprintf(h, "/hyde=%t", *retrieval.HyDE)
+	}
+	fmt.Fprintf(h, "/%d", retrieval.HyDEMaxFiles)
+	fmt.Fprintf(h, "/scope=%s", strings.Join(retrieval.Scope, ","))
+	fmt.Fprintf(h, "/recall=%d/threshold=%g/exclude=%s", retrieval.RecallSize, retrieval.ScoreThreshold, strings.Join(retrieval.ExcludeChunkTypes, ","))
 	for _, f := range changedFiles {
 		h.Write([]byte(f.Filename))
 		h.Write([]byte(f.Patch))
@@ -98,19 +108,19 @@ func NewCachingBuilder(inner Builder, cache *ContextCache) Builder {
 	return &cachingBuilder{inner: inner, cache: cache}
 }
 
-func (b *cachingBuilder) BuildRelevantContextWithImpact(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prDescription string) *ContextResult {
-	key := b.cache.cacheKey(collectionName, embedderModelName, repoPath, prDescription, changedFiles)
+func (b *cachingBuilder) BuildRelevantContextWithImpact(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prDescription string, retrieval core.RetrievalSettings) *ContextResult {
+	key := b.cache.cacheKey(collectionName, embedderModelName, repoPath, prDescription, changedFiles, retrieval)
 	if result, ok := b.cache.Get(key); ok {
 		return result
 	}
 
-	result := b.inner.BuildRelevantContextWithImpact(ctx, collectionName, embedderModelName, repoPath, changedFiles, prDescription)
+	result := b.inner.BuildRelevantContextWithImpact(ctx, collectionName, embedderModelName, repoPath, changedFiles, prDescription, retrieval)
 	b.cache.Set(key, result)
 	return result
 }
 
 func (b *cachingBuilder) BuildRelevantContext(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prDescription string) (string, string) {
-	result := b.BuildRelevantContextWithImpact(ctx, collectionName, embedderModelName, repoPath, changedFiles, prDescription)
+	result := b.BuildRelevantContextWithImpact(ctx, collectionName, embedderModelName, repoPath, changedFiles, prDescription, core.RetrievalSettings{})
 	return result.FullContext, result.DefinitionsContext
 }
 
@@ -122,6 +132,10 @@ func (b *cachingBuilder) GenerateArchSummaries(ctx context.Context, collectionNa
 	return b.inner.GenerateArchSummaries(ctx, collectionName, embedderModelName, repoPath, targetPaths)
 }
 
+func (b *cachingBuilder) RegenerateArchSummaries(ctx context.Context, collectionName, embedderModelName, repoPath, dir string) (int, error) {
+	return b.inner.RegenerateArchSummaries(ctx, collectionName, embedderModelName, repoPath, dir)
+}
+
 func (b *cachingBuilder) GenerateComparisonSummaries(ctx context.Context, models []string, repoPath string, relPaths []string) (map[string]map[string]string, error) {
 	return b.inner.GenerateComparisonSummaries(ctx, models, repoPath, relPaths)
 }
@@ -133,3 +147,7 @@ func (b *cachingBuilder) GenerateProjectContext(ctx context.Context, collectionN
 func (b *cachingBuilder) GeneratePackageSummaries(ctx context.Context, collectionName, embedderModelName string) error {
 	return b.inner.GeneratePackageSummaries(ctx, collectionName, embedderModelName)
 }
+
+func (b *cachingBuilder) ArchGraph(ctx context.Context, collectionName, embedderModelName, repoPath string) (*archgraph.Graph, error) {
+	return b.inner.ArchGraph(ctx, collectionName, embedderModelName, repoPath)
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/sevigo/goframe/schema"
 
+	"github.com/sevigo/code-warden/internal/archgraph"
+	"github.com/sevigo/code-warden/internal/core"
 	internalgithub "github.com/sevigo/code-warden/internal/github"
 )
 
@@ -15,13 +17,13 @@ type mockBuilder struct {
 	result    *ContextResult
 }
 
-func (m *mockBuilder) BuildRelevantContextWithImpact(_ context.Context, _, _, _ string, _ []internalgithub.ChangedFile, _ string) *ContextResult {
+func (m *mockBuilder) BuildRelevantContextWithImpact(_ context.Context, _, _, _ string, _ []internalgithub.ChangedFile, _ string, _ core.RetrievalSettings) *ContextResult {
 	m.callCount++
 	return m.result
 }
 
 func (m *mockBuilder) BuildRelevantContext(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prDescription string) (string, string) {
-	r := m.BuildRelevantContextWithImpact(ctx, collectionName, embedderModelName, repoPath, changedFiles, prDescription)
+	r := m.BuildRelevantContextWithImpact(ctx, collectionName, embedderModelName, repoPath, changedFiles, prDescription, core.RetrievalSettings{})
 	return r.FullContext, r.DefinitionsContext
 }
 
@@ -29,6 +31,9 @@ func (m *mockBuilder) BuildContextForPrompt(_ []schema.Document) string { return
 func (m *mockBuilder) GenerateArchSummaries(_ context.Context, _, _, _ string, _ []string) error {
 	return nil
 }
+func (m *mockBuilder) RegenerateArchSummaries(_ context.Context, _, _, _, _ string) (int, error) {
+	return 0, nil
+}
 func (m *mockBuilder) GenerateComparisonSummaries(_ context.Context, _ []string, _ string, _ []string) (map[string]map[string]string, error) {
 	return nil, nil
 }
@@ -38,6 +43,9 @@ func (m *mockBuilder) GenerateProjectContext(_ context.Context, _, _ string) (st
 func (m *mockBuilder) GeneratePackageSummaries(_ context.Context, _, _ string) error {
 	return nil
 }
+func (m *mockBuilder) ArchGraph(_ context.Context, _, _, _ string) (*archgraph.Graph, error) {
+	return nil, nil
+}
 
 func TestContextCacheHit(t *testing.T) {
 	cache := NewContextCache(5*time.Minute, 10)
@@ -45,8 +53,8 @@ func TestContextCacheHit(t *testing.T) {
 	b := NewCachingBuilder(inner, cache)
 
 	files := []internalgithub.ChangedFile{{Filename: "main.go", Patch: "+hello"}}
-	r1 := b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files, "pr desc")
-	r2 := b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files, "pr desc")
+	r1 := b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files, "pr desc", core.RetrievalSettings{})
+	r2 := b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files, "pr desc", core.RetrievalSettings{})
 
 	if inner.callCount != 1 {
 		t.Fatalf("expected 1 inner call, got %d", inner.callCount)
@@ -64,23 +72,37 @@ func TestContextCacheMissDifferentFiles(t *testing.T) {
 	files1 := []internalgithub.ChangedFile{{Filename: "a.go", Patch: "+a"}}
 	files2 := []internalgithub.ChangedFile{{Filename: "b.go", Patch: "+b"}}
 
-	b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files1, "desc")
-	b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files2, "desc")
+	b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files1, "desc", core.RetrievalSettings{})
+	b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files2, "desc", core.RetrievalSettings{})
 
 	if inner.callCount != 2 {
 		t.Fatalf("expected 2 inner calls for different files, got %d", inner.callCount)
 	}
 }
 
+func TestContextCacheMissDifferentRetrieval(t *testing.T) {
+	cache := NewContextCache(5*time.Minute, 10)
+	inner := &mockBuilder{result: &ContextResult{FullContext: "ctx"}}
+	b := NewCachingBuilder(inner, cache)
+
+	files := []internalgithub.ChangedFile{{Filename: "main.go", Patch: "+a"}}
+	b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files, "desc", core.RetrievalSettings{DocsPerQuery: 6, RerankTopK: 3})
+	b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files, "desc", core.RetrievalSettings{DocsPerQuery: 15, RerankTopK: 8})
+
+	if inner.callCount != 2 {
+		t.Fatalf("expected 2 inner calls for different retrieval settings, got %d", inner.callCount)
+	}
+}
+
 func TestContextCacheExpiration(t *testing.T) {
 	cache := NewContextCache(1*time.Nanosecond, 10)
 	inner := &mockBuilder{result: &ContextResult{FullContext: "ctx"}}
 	b := NewCachingBuilder(inner, cache)
 
 	files := []internalgithub.ChangedFile{{Filename: "main.go"}}
-	b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files, "desc")
+	b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files, "desc", core.RetrievalSettings{})
 	time.Sleep(10 * time.Millisecond)
-	b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files, "desc")
+	b.BuildRelevantContextWithImpact(context.Background(), "col1", "embed", "/repo", files, "desc", core.RetrievalSettings{})
 
 	if inner.callCount != 2 {
 		t.Fatalf("expected 2 calls after expiration, got %d", inner.callCount)
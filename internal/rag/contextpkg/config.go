@@ -33,4 +33,12 @@ type Config struct {
 	ContextPacker  *contextpacker.Packer
 	HyDECache      Cache
 	Logger         *slog.Logger
+
+	// Symbols resolves definitions referenced by a diff that vector search
+	// missed. If nil, only vector search is used.
+	Symbols SymbolLookup
+
+	// NewContextPacker creates a packer for a review's own token budget. If
+	// nil, ContextPacker is used for every review.
+	NewContextPacker func(tokenBudget int) (*contextpacker.Packer, error)
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/mocks"
 )
 
@@ -522,3 +523,31 @@ func TestFilterTestDocs(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterScopeDocs(t *testing.T) {
+	docs := []schema.Document{
+		{Metadata: map[string]any{"source": "services/billing/invoice.go"}},
+		{Metadata: map[string]any{"source": "libs/money/money.go"}},
+		{Metadata: map[string]any{"source": "web/src/app.ts"}},
+	}
+
+	assert.Len(t, filterScopeDocs(docs, nil), 3, "an empty scope keeps all documents")
+
+	got := filterScopeDocs(docs, []string{"services/billing", "libs/money"})
+	require.Len(t, got, 2)
+	assert.Equal(t, "services/billing/invoice.go", got[0].Metadata["source"])
+	assert.Equal(t, "libs/money/money.go", got[1].Metadata["source"])
+}
+
+func TestFilterRetrievedDocs(t *testing.T) {
+	docs := []schema.Document{
+		{Metadata: map[string]any{"source": "services/billing", "chunk_type": "arch"}},
+		{Metadata: map[string]any{"source": "services/billing/invoice.go", "chunk_type": "code"}},
+		{Metadata: map[string]any{"source": "web/src/app.ts", "chunk_type": "code"}},
+	}
+
+	got := filterRetrievedDocs(docs, core.RetrievalSettings{Scope: []string{"services/billing"}, ExcludeChunkTypes: []string{"arch"}})
+	require.Len(t, got, 1)
+	assert.Equal(t, "services/billing/invoice.go", got[0].Metadata["source"])
+	assert.Len(t, filterRetrievedDocs(docs, core.RetrievalSettings{}), 3)
+}
@@ -0,0 +1,152 @@
+package contextpkg
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sevigo/goframe/schema"
+
+	"github.com/sevigo/code-warden/internal/rag/metadata"
+)
+
+// maxExpandedLines caps the size of an expanded chunk so that a chunk inside
+// a very large class or function keeps its raw edges instead.
+const maxExpandedLines = 200
+
+// chunkExpander widens code chunks whose edges cut through a function,
+// method or type to the full enclosing definitions, so the prompt never shows
+// half a function. Boundaries come from the parser's definitions for the file
+// in repoPath; each file is parsed once. Chunks are left as they are when the
+// file on disk no longer matches the indexed chunk, when no parser handles the
+// file, or when the enclosing definition exceeds maxExpandedLines.
+type chunkExpander struct {
+	b        *builderImpl
+	repoPath string
+	files    map[string]*parsedFile
+}
+
+func (b *builderImpl) newChunkExpander(repoPath string) *chunkExpander {
+	return &chunkExpander{b: b, repoPath: repoPath, files: make(map[string]*parsedFile)}
+}
+
+// expand returns docs with expandable chunks widened. docs is not modified.
+func (e *chunkExpander) expand(docs []schema.Document) []schema.Document {
+	if e.repoPath == "" || e.b.cfg.ParserRegistry == nil || len(docs) == 0 {
+		return docs
+	}
+
+	expanded := make([]schema.Document, len(docs))
+	count := 0
+	for i, doc := range docs {
+		expanded[i] = doc
+		source, _ := doc.Metadata["source"].(string)
+		if !isExpandable(doc) || !filepath.IsLocal(source) {
+			continue
+		}
+		file, ok := e.files[source]
+		if !ok {
+			file = e.b.parseFileDefinitions(e.repoPath, source)
+			e.files[source] = file
+		}
+		if file == nil {
+			continue
+		}
+		if d, ok := file.expand(doc); ok {
+			expanded[i] = d
+			count++
+		}
+	}
+	if count > 0 {
+		e.b.cfg.Logger.Debug("expanded chunks to enclosing definitions", "expanded", count, "total", len(docs))
+	}
+	return expanded
+}
+
+// isExpandable reports whether doc is a code chunk with a line range that is
+// not already a whole parent definition.
+func isExpandable(doc schema.Document) bool {
+	if chunkType, _ := doc.Metadata["chunk_type"].(string); chunkType != "" && chunkType != "code" {
+		return false
+	}
+	if parentText, _ := doc.Metadata["full_parent_text"].(string); parentText != "" {
+		return false
+	}
+	return metadata.ExtractLineNumber(doc.Metadata) > 0 && metadata.ExtractInt64(doc.Metadata, "end_line") > 0
+}
+
+// parsedFile holds the lines and definition boundaries of a source file.
+type parsedFile struct {
+	lines []string
+	defs  []schema.CodeEntityDefinition
+}
+
+// parseFileDefinitions reads and parses a file, returning nil when it cannot
+// be read or has no parser or definitions.
+func (b *builderImpl) parseFileDefinitions(repoPath, source string) *parsedFile {
+	parser, err := b.cfg.ParserRegistry.GetParserForExtension(filepath.Ext(source))
+	if err != nil {
+		return nil
+	}
+	content, err := os.ReadFile(filepath.Join(repoPath, source))
+	if err != nil {
+		b.cfg.Logger.Debug("failed to read file for chunk expansion", "file", source, "error", err)
+		return nil
+	}
+	meta, err := parser.ExtractMetadata(string(content), source)
+	if err != nil || len(meta.Definitions) == 0 {
+		return nil
+	}
+	return &parsedFile{lines: strings.Split(string(content), "\n"), defs: meta.Definitions}
+}
+
+// expand returns doc widened to the definitions enclosing its first and last
+// lines, and whether it changed.
+func (f *parsedFile) expand(doc schema.Document) (schema.Document, bool) {
+	start := metadata.ExtractLineNumber(doc.Metadata)
+	end := int(metadata.ExtractInt64(doc.Metadata, "end_line"))
+	if start > end || end > len(f.lines) || !f.matches(start, doc.PageContent) {
+		return doc, false
+	}
+
+	newStart, newEnd := start, end
+	if def, ok := f.enclosing(start); ok {
+		newStart = min(newStart, def.LineStart)
+	}
+	if def, ok := f.enclosing(end); ok {
+		newEnd = max(newEnd, def.LineEnd)
+	}
+	if (newStart == start && newEnd == end) || newEnd-newStart+1 > maxExpandedLines || newEnd > len(f.lines) {
+		return doc, false
+	}
+
+	expanded := doc
+	expanded.PageContent = strings.Join(f.lines[newStart-1:newEnd], "\n")
+	expanded.Metadata = maps.Clone(doc.Metadata)
+	expanded.Metadata["line"] = newStart
+	expanded.Metadata["end_line"] = newEnd
+	return expanded, true
+}
+
+// enclosing returns the smallest definition that contains line.
+func (f *parsedFile) enclosing(line int) (schema.CodeEntityDefinition, bool) {
+	var best schema.CodeEntityDefinition
+	found := false
+	for _, def := range f.defs {
+		if def.LineStart <= 0 || def.LineStart > line || def.LineEnd < line {
+			continue
+		}
+		if !found || def.LineEnd-def.LineStart < best.LineEnd-best.LineStart {
+			best, found = def, true
+		}
+	}
+	return best, found
+}
+
+// matches reports whether the file still has the chunk's first line at start,
+// i.e. the file on disk is the version that was indexed.
+func (f *parsedFile) matches(start int, content string) bool {
+	first, _, _ := strings.Cut(content, "\n")
+	return start <= len(f.lines) && strings.TrimSpace(f.lines[start-1]) == strings.TrimSpace(first)
+}
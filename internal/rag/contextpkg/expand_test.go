@@ -0,0 +1,81 @@
+package contextpkg
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sevigo/goframe/parsers"
+	"github.com/sevigo/goframe/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const expandSource = `package calc
+
+// Add returns the sum.
+func Add(a, b int) int {
+	sum := a + b
+	return sum
+}
+
+func Sub(a, b int) int {
+	diff := a - b
+	return diff
+}
+`
+
+func TestChunkExpander(t *testing.T) {
+	repoPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "calc.go"), []byte(expandSource), 0o600))
+	registry, err := parsers.RegisterLanguagePlugins(slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	b := &builderImpl{cfg: Config{Logger: slog.Default(), ParserRegistry: registry}}
+
+	chunk := func(start, end int, meta map[string]any) schema.Document {
+		lines := strings.Split(expandSource, "\n")
+		md := map[string]any{"source": "calc.go", "chunk_type": "code", "line": start, "end_line": end}
+		for k, v := range meta {
+			md[k] = v
+		}
+		return schema.NewDocument(strings.Join(lines[start-1:end], "\n"), md)
+	}
+
+	docs := []schema.Document{
+		chunk(5, 10, nil), // from inside Add to inside Sub
+		chunk(4, 7, nil),  // exactly Add
+		chunk(5, 6, map[string]any{"chunk_type": "definition"}),
+		chunk(5, 6, map[string]any{"full_parent_text": "func Add(a, b int) int {...}"}),
+	}
+	got := b.newChunkExpander(repoPath).expand(docs)
+
+	require.Len(t, got, len(docs))
+	assert.True(t, strings.HasPrefix(got[0].PageContent, "func Add(a, b int) int {"), "start expanded to Add: %q", got[0].PageContent)
+	assert.True(t, strings.HasSuffix(got[0].PageContent, "\treturn diff\n}"), "end expanded to Sub: %q", got[0].PageContent)
+	assert.Equal(t, 4, got[0].Metadata["line"])
+	assert.Equal(t, 12, got[0].Metadata["end_line"])
+	assert.Equal(t, 5, docs[0].Metadata["line"], "input docs must not be modified")
+	for i := 1; i < len(docs); i++ {
+		assert.Equal(t, docs[i].PageContent, got[i].PageContent, "doc %d should be unchanged", i)
+	}
+}
+
+func TestChunkExpander_StaleFile(t *testing.T) {
+	repoPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "calc.go"), []byte(expandSource), 0o600))
+	registry, err := parsers.RegisterLanguagePlugins(slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	b := &builderImpl{cfg: Config{Logger: slog.Default(), ParserRegistry: registry}}
+
+	// The indexed chunk no longer matches line 5 of the file on disk.
+	doc := schema.NewDocument("\tproduct := a * b", map[string]any{"source": "calc.go", "line": 5, "end_line": 5})
+	got := b.newChunkExpander(repoPath).expand([]schema.Document{doc})
+	assert.Equal(t, doc.PageContent, got[0].PageContent)
+
+	// Paths outside the repository are never read.
+	escape := schema.NewDocument("x", map[string]any{"source": "../calc.go", "line": 1, "end_line": 1})
+	got = b.newChunkExpander(repoPath).expand([]schema.Document{escape})
+	assert.Equal(t, "x", got[0].PageContent)
+}
@@ -20,7 +20,7 @@ import (
 //
 // The keywords are stored on builderImpl to avoid cross-review contamination.
 func (b *builderImpl) gatherFileSummaryContext(ctx context.Context, store storage.ScopedVectorStore, files []internalgithub.ChangedFile) string {
-	b.cfg.Logger.Info("stage started", "name", "FileSummaryContext")
+	b.cfg.Logger.InfoContext(ctx, "stage started", "name", "FileSummaryContext")
 
 	const maxConcurrent = 10
 	g, ctx := errgroup.WithContext(ctx)
@@ -45,10 +45,10 @@ func (b *builderImpl) gatherFileSummaryContext(ctx context.Context, store storag
 	}
 
 	if err := g.Wait(); err != nil {
-		b.cfg.Logger.Warn("file summary context stage interrupted", "error", err)
+		b.cfg.Logger.WarnContext(ctx, "file summary context stage interrupted", "error", err)
 	}
 
-	b.cfg.Logger.Info("stage completed", "name", "FileSummaryContext", "files_with_summary", found, "keywords_collected", len(keywords))
+	b.cfg.Logger.InfoContext(ctx, "stage completed", "name", "FileSummaryContext", "files_with_summary", found, "keywords_collected", len(keywords))
 	b.setFileKeywords(keywords)
 
 	return sb.String()
@@ -70,7 +70,7 @@ func (b *builderImpl) fetchFileSummary(ctx context.Context, store storage.Scoped
 		}),
 	)
 	if err != nil {
-		b.cfg.Logger.Debug("file summary fetch failed", "file", file.Filename, "error", err)
+		b.cfg.Logger.DebugContext(ctx, "file summary fetch failed", "file", file.Filename, "error", err)
 		return nil
 	}
 	if len(docs) == 0 {
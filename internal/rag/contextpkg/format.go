@@ -8,11 +8,14 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/sevigo/goframe/embeddings/sparse"
 	"github.com/sevigo/goframe/schema"
 
+	"github.com/sevigo/code-warden/internal/core"
 	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/rag/metadata"
 )
 
 // BuildContextForPrompt formats retrieved documents into a prompt-ready string.
@@ -62,6 +65,9 @@ func (b *builderImpl) BuildContextForPrompt(docs []schema.Document) string {
 				fmt.Fprintf(&contextBuilder, "Identifier: %s\n", identifier)
 			}
 		}
+		if history := formatHistory(entry.docs); history != "" {
+			fmt.Fprintf(&contextBuilder, "History: %s\n", history)
+		}
 
 		contextBuilder.WriteString("\n")
 		contextBuilder.WriteString(b.mergeChunksForFile(entry.docs))
@@ -70,6 +76,29 @@ func (b *builderImpl) BuildContextForPrompt(docs []schema.Document) string {
 	return contextBuilder.String()
 }
 
+// formatHistory describes the git churn recorded on a file's chunks at index
+// time, so the reviewer can call out frequently-changed hotspots.
+func formatHistory(docs []schema.Document) string {
+	var churn, lastModified int64
+	hotspot := false
+	for _, doc := range docs {
+		churn = max(churn, metadata.ExtractInt64(doc.Metadata, "churn"))
+		lastModified = max(lastModified, metadata.ExtractInt64(doc.Metadata, "last_modified"))
+		if h, _ := doc.Metadata["hotspot"].(bool); h {
+			hotspot = true
+		}
+	}
+	if lastModified == 0 {
+		return ""
+	}
+	history := fmt.Sprintf("%d commits in the last year, last modified %s",
+		churn, time.Unix(lastModified, 0).UTC().Format("2006-01-02"))
+	if hotspot {
+		history += " (frequently-changed hotspot)"
+	}
+	return history
+}
+
 func (b *builderImpl) mergeChunksForFile(docs []schema.Document) string {
 	if len(docs) == 1 {
 		return b.getDocContent(docs[0])
@@ -125,9 +154,12 @@ func findOverlapStart(prev, curr string) int {
 	return 0
 }
 
-func (b *builderImpl) fallbackConcat(docs []schema.Document) string {
+func (b *builderImpl) fallbackConcat(docs []schema.Document, tokenBudget int) string {
 	const charsPerToken = 4
-	maxChars := b.cfg.AIConfig.ContextTokenBudget * charsPerToken
+	if tokenBudget <= 0 {
+		tokenBudget = b.cfg.AIConfig.ContextTokenBudget
+	}
+	maxChars := tokenBudget * charsPerToken
 	if maxChars <= 0 {
 		maxChars = 64000
 	}
@@ -221,6 +253,37 @@ func filterTestDocs(docs []schema.Document) []schema.Document {
 	return filtered
 }
 
+// filterScopeDocs drops documents whose source lies outside scope, the
+// directories of the sub-projects under review. An empty scope keeps all.
+func filterScopeDocs(docs []schema.Document, scope []string) []schema.Document {
+	if len(scope) == 0 {
+		return docs
+	}
+	filtered := make([]schema.Document, 0, len(docs))
+	for _, doc := range docs {
+		if source, _ := doc.Metadata["source"].(string); core.InScope(source, scope) {
+			filtered = append(filtered, doc)
+		}
+	}
+	return filtered
+}
+
+// filterRetrievedDocs drops the documents of searches that retrieval leaves
+// out: those outside its scope and those of excluded chunk types.
+func filterRetrievedDocs(docs []schema.Document, retrieval core.RetrievalSettings) []schema.Document {
+	docs = filterScopeDocs(docs, retrieval.Scope)
+	if len(retrieval.ExcludeChunkTypes) == 0 {
+		return docs
+	}
+	filtered := make([]schema.Document, 0, len(docs))
+	for _, doc := range docs {
+		if chunkType, _ := doc.Metadata["chunk_type"].(string); !retrieval.ExcludesChunkType(chunkType) {
+			filtered = append(filtered, doc)
+		}
+	}
+	return filtered
+}
+
 func mergeAndDedup(docs []schema.Document, keyFn func(schema.Document) string) []schema.Document {
 	seen := make(map[string]schema.Document, len(docs))
 	for _, d := range docs {
@@ -296,7 +359,7 @@ func (b *builderImpl) generateSparseVectorFunc(stageName string) func(ctx contex
 		}
 
 		if len(validQueries) == 0 {
-			b.cfg.Logger.Warn("all queries invalid for sparse vector generation, returning empty slice", "stage", stageName)
+			b.cfg.Logger.WarnContext(ctx, "all queries invalid for sparse vector generation, returning empty slice", "stage", stageName)
 			return nil, nil
 		}
 
@@ -304,7 +367,7 @@ func (b *builderImpl) generateSparseVectorFunc(stageName string) func(ctx contex
 		for i, q := range validQueries {
 			v, err := sparse.GenerateSparseVector(ctx, q)
 			if err != nil {
-				b.cfg.Logger.Warn("failed to generate sparse vector, using dense only", "stage", stageName, "query", truncateForLog(q, 50), "error", err)
+				b.cfg.Logger.WarnContext(ctx, "failed to generate sparse vector, using dense only", "stage", stageName, "query", truncateForLog(q, 50), "error", err)
 				vecs[i] = nil
 				continue
 			}
@@ -0,0 +1,75 @@
+package contextpkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sevigo/goframe/vectorstores"
+
+	"github.com/sevigo/code-warden/internal/archgraph"
+	"github.com/sevigo/code-warden/internal/pathutil"
+)
+
+// maxGraphSummaries bounds the arch summaries fetched for a graph, matching
+// the cache lookup in GenerateArchSummaries.
+const maxGraphSummaries = 500
+
+// ArchGraph builds the architecture graph of the repository at repoPath from
+// its stored arch summaries and the imports of the files on disk. Directories
+// without a summary yet are still part of the graph.
+func (b *builderImpl) ArchGraph(ctx context.Context, collectionName, embedderModelName, repoPath string) (*archgraph.Graph, error) {
+	scopedStore := b.cfg.VectorStore.ForRepo(collectionName, embedderModelName)
+	docs, err := scopedStore.SimilaritySearch(ctx, "summary", maxGraphSummaries,
+		vectorstores.WithFilters(map[string]any{"chunk_type": "arch"}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch architectural summaries: %w", err)
+	}
+	summaries := make(map[string]string, len(docs))
+	for _, doc := range docs {
+		if source, _ := doc.Metadata["source"].(string); source != "" {
+			summaries[source] = doc.PageContent
+		}
+	}
+
+	var dirs []archgraph.Directory
+	err = filepath.WalkDir(repoPath, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") && path != repoPath {
+			return filepath.SkipDir
+		}
+
+		relPath, _ := filepath.Rel(repoPath, path)
+		if relPath == "." {
+			relPath = rootDir
+		}
+		relPath = pathutil.ToSlash(relPath)
+
+		info, _, err := b.scanDirectoryOnDisk(repoPath, path, relPath)
+		if err != nil {
+			return err
+		}
+		if info == nil {
+			return nil
+		}
+		dirs = append(dirs, archgraph.Directory{Path: relPath, Summary: summaries[relPath], Imports: info.Imports})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directories: %w", err)
+	}
+
+	b.cfg.Logger.DebugContext(ctx, "architecture graph built", "collection", collectionName, "directories", len(dirs), "summaries", len(summaries))
+	return archgraph.Build(dirs), nil
+}
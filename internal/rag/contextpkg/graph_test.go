@@ -0,0 +1,44 @@
+package contextpkg
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sevigo/goframe/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/archgraph"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+func TestArchGraph(t *testing.T) {
+	repo := t.TempDir()
+	for _, f := range []string{"main.go", "internal/core/core.go", "docs/README.md", ".github/workflows/ci.go"} {
+		path := filepath.Join(repo, f)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("package x\n"), 0o600))
+	}
+
+	ctrl := gomock.NewController(t)
+	vectorStore := mocks.NewMockVectorStore(ctrl)
+	scopedStore := mocks.NewMockScopedVectorStore(ctrl)
+	vectorStore.EXPECT().ForRepo("repo_acme_api", "embedder").Return(scopedStore)
+	scopedStore.EXPECT().SimilaritySearch(gomock.Any(), "summary", maxGraphSummaries, gomock.Any()).Return([]schema.Document{
+		{PageContent: "Domain types.", Metadata: map[string]any{"source": "internal/core", "chunk_type": "arch"}},
+	}, nil)
+
+	b := &builderImpl{cfg: Config{Logger: slog.Default(), VectorStore: vectorStore}}
+	g, err := b.ArchGraph(t.Context(), "repo_acme_api", "embedder", repo)
+	require.NoError(t, err)
+
+	// docs holds no code and hidden directories are skipped.
+	assert.Equal(t, []archgraph.Node{
+		{Path: "internal/core", Summary: "Domain types."},
+		{Path: archgraph.RootDir},
+	}, g.Nodes)
+	assert.Equal(t, []archgraph.Edge{{From: archgraph.RootDir, To: "internal/core", Kind: archgraph.EdgeContains}}, g.Edges)
+}
@@ -9,10 +9,12 @@ import (
 	"sync"
 
 	"github.com/sevigo/goframe/embeddings/sparse"
+	"github.com/sevigo/goframe/llms"
 	"github.com/sevigo/goframe/schema"
 	"github.com/sevigo/goframe/vectorstores"
 	"golang.org/x/sync/errgroup"
 
+	"github.com/sevigo/code-warden/internal/core"
 	internalgithub "github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/llm"
 	indexpkg "github.com/sevigo/code-warden/internal/rag/index"
@@ -72,34 +74,38 @@ func (d dynamicSparseRetriever) GetRelevantDocuments(ctx context.Context, query
 	var searchOpts []vectorstores.Option
 	sparseVec, err := sparse.GenerateSparseVector(ctx, query)
 	if err != nil {
-		d.builder.cfg.Logger.Warn("sparse vector generation failed, falling back to dense", "error", err)
+		d.builder.cfg.Logger.WarnContext(ctx, "sparse vector generation failed, falling back to dense", "error", err)
 	} else {
 		searchOpts = append(searchOpts, vectorstores.WithSparseQuery(sparseVec))
 	}
 	return d.store.SimilaritySearch(ctx, semanticQuery, d.numDocs, searchOpts...)
 }
 
-func (b *builderImpl) gatherHyDEContext(ctx context.Context, collection, embedder string, files []internalgithub.ChangedFile) ([][]schema.Document, []int, error) {
-	b.cfg.Logger.Info("stage started", "name", "HyDE")
+func (b *builderImpl) gatherHyDEContext(ctx context.Context, collection, embedder string, files []internalgithub.ChangedFile, retrieval core.RetrievalSettings) ([][]schema.Document, []int, error) {
+	b.cfg.Logger.InfoContext(ctx, "stage started", "name", "HyDE")
 
-	scopedStore := b.cfg.VectorStore.ForRepo(collection, embedder)
+	scopedStore := withScoreThreshold(b.cfg.VectorStore.ForRepo(collection, embedder), retrieval.ScoreThreshold)
+
+	// Fetch more documents than are kept so the BM25 pre-filter and the
+	// reranker have candidates to choose from.
+	numCandidates := retrieval.RecallSize
 
 	var baseRetriever schema.Retriever
 	queryLLM, err := b.cfg.GetLLM(ctx, b.cfg.AIConfig.FastModel)
 	if err == nil {
-		b.cfg.Logger.Debug("HyDE base retriever: MultiQueryRetriever", "model", b.cfg.AIConfig.FastModel)
+		b.cfg.Logger.DebugContext(ctx, "HyDE base retriever: MultiQueryRetriever", "model", b.cfg.AIConfig.FastModel)
 		baseRetriever = vectorstores.MultiQueryRetriever{
 			Store:         scopedStore,
 			LLM:           queryLLM,
-			NumDocuments:  20,
+			NumDocuments:  numCandidates,
 			Count:         2,
 			SparseGenFunc: b.generateSparseVectorFunc("HyDE"),
 		}
 	} else {
-		b.cfg.Logger.Warn("failed to get LLM for HyDE multi-query, falling back to single-query retriever", "error", err)
+		b.cfg.Logger.WarnContext(ctx, "failed to get LLM for HyDE multi-query, falling back to single-query retriever", "error", err)
 		baseRetriever = dynamicSparseRetriever{
 			store:   scopedStore,
-			numDocs: 20,
+			numDocs: numCandidates,
 			builder: b,
 		}
 	}
@@ -107,7 +113,7 @@ func (b *builderImpl) gatherHyDEContext(ctx context.Context, collection, embedde
 	rerankingRetriever := vectorstores.RerankingRetriever{
 		Retriever: baseRetriever,
 		Reranker:  b.cfg.Reranker,
-		TopK:      5,
+		TopK:      retrieval.RerankTopK,
 		MinScore:  b.cfg.AIConfig.RerankMinScore,
 		CandidateFilter: func(query string, docs []schema.Document) []schema.Document {
 			// Augment BM25 filter with file keywords for better recall
@@ -120,11 +126,10 @@ func (b *builderImpl) gatherHyDEContext(ctx context.Context, collection, embedde
 				}
 				enrichedQuery = enrichedQuery + " " + strings.Join(keywords, " ")
 			}
-			return preFilterBM25(enrichedQuery, docs, 10)
+			return preFilterBM25(enrichedQuery, docs, retrieval.DocsPerQuery)
 		},
 	}
 
-	const maxHyDEConcurrency = 5
 	type hydeResult struct {
 		index int
 		docs  []schema.Document
@@ -135,23 +140,19 @@ func (b *builderImpl) gatherHyDEContext(ctx context.Context, collection, embedde
 		results   []hydeResult
 	)
 
+	concurrency := b.cfg.AIConfig.HyDEConcurrency
+	if concurrency < 1 {
+		concurrency = defaultHyDEConcurrency
+	}
 	g, ctx := errgroup.WithContext(ctx)
-	g.SetLimit(maxHyDEConcurrency)
+	g.SetLimit(concurrency)
 
-	for i, file := range files {
-		if file.Patch == "" {
-			continue
-		}
-		// Skip non-code files: YAML, JSON, Markdown etc. have no meaningful
-		// indexed code to retrieve, so querying with their raw patch content
-		// only returns semantically irrelevant results.
-		if !indexpkg.IsLogicFile(file.Filename) {
-			b.cfg.Logger.Debug("HyDE: skipping non-code file", "file", file.Filename)
-			continue
-		}
-
-		idx := i
-		f := file
+	maxFiles := retrieval.HyDEMaxFiles
+	if maxFiles <= 0 {
+		maxFiles = b.cfg.AIConfig.HyDEMaxFiles
+	}
+	for _, idx := range b.selectHyDEFiles(files, maxFiles) {
+		f := files[idx]
 		lang := languageFromFilename(f.Filename)
 
 		g.Go(func() error {
@@ -166,7 +167,7 @@ func (b *builderImpl) gatherHyDEContext(ctx context.Context, collection, embedde
 	}
 
 	if err := g.Wait(); err != nil {
-		b.cfg.Logger.Warn("HyDE collection cancelled", "error", err)
+		b.cfg.Logger.WarnContext(ctx, "HyDE collection cancelled", "error", err)
 		return nil, nil, err
 	}
 
@@ -181,10 +182,44 @@ func (b *builderImpl) gatherHyDEContext(ctx context.Context, collection, embedde
 		finalIndices[i] = res.index
 	}
 
-	b.cfg.Logger.Info("stage completed", "name", "HyDE", "files_processed", len(results))
+	b.cfg.Logger.InfoContext(ctx, "stage completed", "name", "HyDE", "files_processed", len(results))
 	return finalResults, finalIndices, nil
 }
 
+// defaultHyDEConcurrency applies when ai.hyde_concurrency is unset.
+const defaultHyDEConcurrency = 5
+
+// selectHyDEFiles returns the indices of the files HyDE generates code for,
+// in their original order: code files with a patch, at most maxFiles of them
+// with the largest patches first. A maxFiles of zero selects all of them.
+func (b *builderImpl) selectHyDEFiles(files []internalgithub.ChangedFile, maxFiles int) []int {
+	var selected []int
+	for i, file := range files {
+		if file.Patch == "" {
+			continue
+		}
+		// Skip non-code files: YAML, JSON, Markdown etc. have no meaningful
+		// indexed code to retrieve, so querying with their raw patch content
+		// only returns semantically irrelevant results.
+		if !indexpkg.IsLogicFile(file.Filename) {
+			b.cfg.Logger.Debug("HyDE: skipping non-code file", "file", file.Filename)
+			continue
+		}
+		selected = append(selected, i)
+	}
+	if maxFiles <= 0 || len(selected) <= maxFiles {
+		return selected
+	}
+
+	sort.SliceStable(selected, func(i, j int) bool {
+		return len(files[selected[i]].Patch) > len(files[selected[j]].Patch)
+	})
+	b.cfg.Logger.Info("HyDE: limiting files", "eligible", len(selected), "max_files", maxFiles)
+	selected = selected[:maxFiles]
+	sort.Ints(selected)
+	return selected
+}
+
 // retrieveHyDEDocsForFile fetches HyDE context documents for a single changed file.
 // For logic files it uses a per-file HyDE retriever whose generator captures the
 // file's language and path; for non-code files it falls back to a plain query.
@@ -218,15 +253,15 @@ func (b *builderImpl) retrieveHyDEDocsForFile(ctx context.Context, reranker vect
 	}
 
 	if err != nil {
-		b.cfg.Logger.Warn("HyDE generation/retrieval failed for file", "file", f.Filename, "error", err)
+		b.cfg.Logger.WarnContext(ctx, "HyDE generation/retrieval failed for file", "file", f.Filename, "error", err)
 		return nil
 	}
 
 	docs = filterTestDocs(docs)
 	if len(docs) > 0 {
-		b.cfg.Logger.Debug("HyDE docs found", "file", f.Filename, "count", len(docs))
+		b.cfg.Logger.DebugContext(ctx, "HyDE docs found", "file", f.Filename, "count", len(docs))
 	} else {
-		b.cfg.Logger.Debug("no HyDE docs found", "file", f.Filename)
+		b.cfg.Logger.DebugContext(ctx, "no HyDE docs found", "file", f.Filename)
 	}
 	return docs
 }
@@ -237,12 +272,13 @@ func (b *builderImpl) retrieveHyDEDocsForFile(ctx context.Context, reranker vect
 // Cache key uses null byte separators to prevent collision since null bytes cannot
 // appear in file paths or model names.
 func (b *builderImpl) generateHyDESnippetForFile(ctx context.Context, patch, filePath, language string) (string, error) {
-	cacheKey := b.hashPatch(b.cfg.AIConfig.FastModel + "\x00" + filePath + "\x00" + patch)
+	model, modelName := b.hydeModel(ctx)
+	cacheKey := b.hashPatch(modelName + "\x00" + filePath + "\x00" + patch)
 
 	if b.cfg.HyDECache != nil {
 		if cached, ok := b.cfg.HyDECache.Load(cacheKey); ok {
 			if snippet, valid := cached.(string); valid {
-				b.cfg.Logger.Debug("HyDE cache hit", "file", filePath)
+				b.cfg.Logger.DebugContext(ctx, "HyDE cache hit", "file", filePath)
 				return snippet, nil
 			}
 		}
@@ -257,13 +293,27 @@ func (b *builderImpl) generateHyDESnippetForFile(ctx context.Context, patch, fil
 		return "", err
 	}
 
-	snippet, err := b.cfg.GeneratorLLM.Call(ctx, prompt)
+	snippet, err := model.Call(core.WithUsageStep(ctx, core.UsageStepHyDE), prompt)
 	if err == nil && snippet != "" && b.cfg.HyDECache != nil {
 		b.cfg.HyDECache.Store(cacheKey, snippet)
 	}
 	return snippet, err
 }
 
+// hydeModel returns the model that writes HyDE snippets and its name:
+// ai.hyde_model when set and available, otherwise the generator.
+func (b *builderImpl) hydeModel(ctx context.Context) (llms.Model, string) {
+	name := b.cfg.AIConfig.HyDEModel
+	if name != "" && b.cfg.GetLLM != nil {
+		model, err := b.cfg.GetLLM(ctx, name)
+		if err == nil {
+			return model, name
+		}
+		b.cfg.Logger.WarnContext(ctx, "failed to get HyDE model, using the generator", "model", name, "error", err)
+	}
+	return b.cfg.GeneratorLLM, b.cfg.AIConfig.GeneratorModel
+}
+
 func stripPatchNoise(query string) string {
 	if query == "" {
 		return ""
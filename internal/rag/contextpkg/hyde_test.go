@@ -1,14 +1,20 @@
 package contextpkg
 
 import (
+	"context"
 	"log/slog"
 	"testing"
 
+	"github.com/sevigo/goframe/llms"
 	"github.com/sevigo/goframe/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 
 	"github.com/sevigo/code-warden/internal/config"
-
-	"github.com/stretchr/testify/assert"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/mocks"
 )
 
 func TestLanguageFromFilename(t *testing.T) {
@@ -78,7 +84,7 @@ func (c *simpleCache) Store(key string, value any) {
 func TestGenerateHyDESnippetForFile_CacheHit(t *testing.T) {
 	b := &builderImpl{cfg: Config{
 		Logger:    slog.Default(),
-		AIConfig:  config.AIConfig{FastModel: "test-model"},
+		AIConfig:  config.AIConfig{GeneratorModel: "test-model"},
 		HyDECache: &simpleCache{m: make(map[string]any)},
 	}}
 
@@ -86,7 +92,7 @@ func TestGenerateHyDESnippetForFile_CacheHit(t *testing.T) {
 	patch := "+func Process() error { return nil }"
 	filePath := "internal/service.go"
 	// Cache key format: model\x00filePath\x00patch
-	cacheKey := b.hashPatch(b.cfg.AIConfig.FastModel + "\x00" + filePath + "\x00" + patch)
+	cacheKey := b.hashPatch(b.cfg.AIConfig.GeneratorModel + "\x00" + filePath + "\x00" + patch)
 	cache.Store(cacheKey, "cached hypothetical snippet")
 
 	// GeneratorLLM is nil — if the cache miss path were taken, this would panic.
@@ -96,6 +102,46 @@ func TestGenerateHyDESnippetForFile_CacheHit(t *testing.T) {
 	assert.Equal(t, "cached hypothetical snippet", result)
 }
 
+func TestGenerateHyDESnippetForFile_HyDEModel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	promptMgr, err := llm.NewPromptManager()
+	require.NoError(t, err)
+	small := mocks.NewMockModel(ctrl)
+	small.EXPECT().Call(gomock.Any(), gomock.Any()).Return("hypothetical", nil)
+
+	var requested string
+	b := &builderImpl{cfg: Config{
+		Logger:       slog.Default(),
+		AIConfig:     config.AIConfig{GeneratorModel: "big", HyDEModel: "small"},
+		PromptMgr:    promptMgr,
+		GeneratorLLM: mocks.NewMockModel(ctrl), // no calls expected
+		GetLLM: func(_ context.Context, name string) (llms.Model, error) {
+			requested = name
+			return small, nil
+		},
+	}}
+
+	result, err := b.generateHyDESnippetForFile(t.Context(), "+func A() {}", "a.go", "Go")
+
+	require.NoError(t, err)
+	assert.Equal(t, "hypothetical", result)
+	assert.Equal(t, "small", requested)
+}
+
+func TestSelectHyDEFiles(t *testing.T) {
+	b := &builderImpl{cfg: Config{Logger: slog.Default()}}
+	files := []internalgithub.ChangedFile{
+		{Filename: "a.go", Patch: "+1"},
+		{Filename: "README.md", Patch: "+a long documentation change"},
+		{Filename: "b.go", Patch: "+123456"},
+		{Filename: "c.go"},
+		{Filename: "d.go", Patch: "+1234"},
+	}
+
+	assert.Equal(t, []int{0, 2, 4}, b.selectHyDEFiles(files, 0))
+	assert.Equal(t, []int{2, 4}, b.selectHyDEFiles(files, 2), "largest patches first, in original order")
+}
+
 func TestPreFilterBM25(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -20,9 +20,9 @@ type depRequest struct {
 }
 
 func (b *builderImpl) gatherImpactDocs(ctx context.Context, store storage.ScopedVectorStore, repoPath string, files []internalgithub.ChangedFile) ([]schema.Document, error) {
-	b.cfg.Logger.Info("stage started", "name", "ImpactAnalysis")
+	b.cfg.Logger.InfoContext(ctx, "stage started", "name", "ImpactAnalysis")
 	docs, err := b.getImpactDocs(ctx, store, repoPath, files)
-	b.cfg.Logger.Info("stage completed", "name", "ImpactAnalysis", "docs", len(docs))
+	b.cfg.Logger.InfoContext(ctx, "stage completed", "name", "ImpactAnalysis", "docs", len(docs))
 	return docs, err
 }
 
@@ -108,7 +108,7 @@ func (b *builderImpl) fetchImpactResults(ctx context.Context, retriever *vectors
 
 			network, err := retriever.GetContextNetwork(ctx, dr.Pkg, dr.Imports)
 			if err != nil {
-				b.cfg.Logger.Warn("impact: failed to fetch context network", "file", dr.File.Filename, "pkg", dr.Pkg, "error", err)
+				b.cfg.Logger.WarnContext(ctx, "impact: failed to fetch context network", "file", dr.File.Filename, "pkg", dr.Pkg, "error", err)
 				return
 			}
 
@@ -120,7 +120,7 @@ func (b *builderImpl) fetchImpactResults(ctx context.Context, retriever *vectors
 			combined := append(network.Dependents, network.Dependencies...) //nolint:gocritic // intentional new slice
 			depMu.Lock()
 			depResults[dr.File.Filename] = combined
-			b.cfg.Logger.Debug("impact graph fetched",
+			b.cfg.Logger.DebugContext(ctx, "impact graph fetched",
 				"file", dr.File.Filename,
 				"dependents", len(network.Dependents),
 				"dependencies", len(network.Dependencies),
@@ -10,6 +10,7 @@ import (
 	"github.com/sevigo/goframe/schema"
 	"github.com/sevigo/goframe/vectorstores"
 
+	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/llm"
 	"github.com/sevigo/code-warden/internal/storage"
 )
@@ -17,7 +18,8 @@ import (
 // GenerateProjectContext fetches all directory-level architectural summaries
 // and synthesizes them into a global project context document.
 func (b *builderImpl) GenerateProjectContext(ctx context.Context, collectionName, embedderModelName string) (string, error) {
-	b.cfg.Logger.Info("generating project context document from arch summaries",
+	ctx = core.WithUsageStep(ctx, core.UsageStepSummary)
+	b.cfg.Logger.InfoContext(ctx, "generating project context document from arch summaries",
 		"collection", collectionName,
 	)
 
@@ -55,7 +57,7 @@ func (b *builderImpl) createArchSummaryMapFunc(scopedStore storage.ScopedVectorS
 		}
 
 		if len(docs) == 0 {
-			b.cfg.Logger.Warn("no architectural summaries found to generate context from")
+			b.cfg.Logger.WarnContext(ctx, "no architectural summaries found to generate context from")
 			return nil, nil
 		}
 
@@ -84,7 +86,7 @@ func (b *builderImpl) createProjectContextReduceFunc() func(ctx context.Context,
 			return "", fmt.Errorf("failed to generate project context: %w", err)
 		}
 
-		b.cfg.Logger.Info("project context document generated successfully",
+		b.cfg.Logger.InfoContext(ctx, "project context document generated successfully",
 			"incoming_summaries", len(docs),
 			"output_length", len(response),
 		)
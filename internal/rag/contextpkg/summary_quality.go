@@ -0,0 +1,107 @@
+package contextpkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sevigo/goframe/llms"
+)
+
+const (
+	// minSummaryWords is the fewest words an architectural summary may have.
+	minSummaryWords = 15
+	// minEchoLineLength is the shortest summary line checked for being
+	// copied from the prompt; shorter ones, like headings, match by chance.
+	minEchoLineLength = 20
+	// maxEchoedLineShare is the largest share of summary lines that may
+	// appear verbatim in the prompt.
+	maxEchoedLineShare = 0.5
+	// retrySummaryTemperature is the temperature of the second attempt at
+	// a summary that failed the quality check.
+	retrySummaryTemperature = 0.7
+)
+
+// errSummaryRejected is the error of a summary that failed checkSummary.
+var errSummaryRejected = errors.New("summary rejected")
+
+// checkSummary rejects summaries too short to describe a directory and
+// summaries that mostly repeat the prompt, such as its file and symbol lists,
+// instead of summarizing it.
+func checkSummary(summary, prompt string) error {
+	if words := len(strings.Fields(summary)); words < minSummaryWords {
+		return fmt.Errorf("%w: %d words, expected at least %d", errSummaryRejected, words, minSummaryWords)
+	}
+
+	var checked, echoed int
+	for line := range strings.SplitSeq(summary, "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) < minEchoLineLength {
+			continue
+		}
+		checked++
+		if strings.Contains(prompt, line) {
+			echoed++
+		}
+	}
+	if checked > 0 && float64(echoed)/float64(checked) > maxEchoedLineShare {
+		return fmt.Errorf("%w: %d of %d lines repeat the prompt", errSummaryRejected, echoed, checked)
+	}
+	return nil
+}
+
+// generateCheckedSummary generates the summary of path from prompt and
+// retries summaries that fail checkSummary: first with the generator at a
+// higher temperature, then with ai.summary_fallback_model when set. Errors
+// of the LLM itself are returned at once, so callThrottled can handle them.
+func (b *builderImpl) generateCheckedSummary(ctx context.Context, path, prompt string) (string, error) {
+	var rejected error
+	for attempt := 1; ; attempt++ {
+		model, modelName, options := b.summaryAttempt(ctx, attempt)
+		if model == nil {
+			break
+		}
+
+		summary, err := llms.GenerateFromSinglePrompt(ctx, model, prompt, options...)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate summary for %s: %w", path, err)
+		}
+		if rejected = checkSummary(summary, prompt); rejected == nil {
+			return summary, nil
+		}
+		b.cfg.Logger.WarnContext(ctx, "architectural summary failed the quality check",
+			"path", path,
+			"model", modelName,
+			"attempt", attempt,
+			"reason", rejected,
+		)
+	}
+	if rejected == nil {
+		return "", fmt.Errorf("no model to summarize %s", path)
+	}
+	return "", fmt.Errorf("no summary for %s passed the quality check: %w", path, rejected)
+}
+
+// summaryAttempt returns the model and options of an attempt at a summary,
+// or a nil model when there is no such attempt.
+func (b *builderImpl) summaryAttempt(ctx context.Context, attempt int) (llms.Model, string, []llms.CallOption) {
+	switch attempt {
+	case 1:
+		return b.cfg.GeneratorLLM, b.cfg.AIConfig.GeneratorModel, nil
+	case 2:
+		return b.cfg.GeneratorLLM, b.cfg.AIConfig.GeneratorModel, []llms.CallOption{llms.WithTemperature(retrySummaryTemperature)}
+	case 3:
+		name := b.cfg.AIConfig.SummaryFallbackModel
+		if name == "" || name == b.cfg.AIConfig.GeneratorModel || b.cfg.GetLLM == nil {
+			return nil, "", nil
+		}
+		model, err := b.cfg.GetLLM(ctx, name)
+		if err != nil {
+			b.cfg.Logger.WarnContext(ctx, "failed to load the summary fallback model", "model", name, "error", err)
+			return nil, "", nil
+		}
+		return model, name, nil
+	}
+	return nil, "", nil
+}
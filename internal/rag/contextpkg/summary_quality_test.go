@@ -0,0 +1,154 @@
+package contextpkg
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sevigo/goframe/llms"
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+const goodSummary = "The billing package computes invoices from usage records, applies discounts and taxes, and hands finished invoices to the payment gateway."
+
+func contentResponse(text string) *schema.ContentResponse {
+	return &schema.ContentResponse{Choices: []*schema.ContentChoice{{Content: text}}}
+}
+
+func TestCheckSummary(t *testing.T) {
+	prompt := "Summarize the directory internal/billing.\nFiles:\ninternal/billing/invoice.go\ninternal/billing/discount.go\nSymbols:\nfunc ComputeInvoice(records []Record) Invoice"
+	tests := []struct {
+		name    string
+		summary string
+		wantErr bool
+	}{
+		{name: "good", summary: goodSummary, wantErr: false},
+		{name: "too short", summary: "Billing code.", wantErr: true},
+		{name: "empty", summary: "  ", wantErr: true},
+		{
+			name:    "echoes the prompt",
+			summary: "Files:\ninternal/billing/invoice.go\ninternal/billing/discount.go\nfunc ComputeInvoice(records []Record) Invoice\nThis directory contains the billing code of the service.",
+			wantErr: true,
+		},
+		{
+			name:    "quotes a symbol",
+			summary: goodSummary + "\nIts entry point is func ComputeInvoice(records []Record) Invoice.\nDiscounts are applied before taxes are added to each invoice line.",
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSummary(tt.summary, prompt)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errSummaryRejected)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestGenerateCheckedSummary_Retries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	generator := mocks.NewMockModel(ctrl)
+	fallback := mocks.NewMockModel(ctrl)
+
+	// The first attempt is too short and the retry at a higher temperature
+	// echoes the prompt, so the fallback model is asked.
+	var temperatures []float64
+	generator.EXPECT().GenerateContent(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ []schema.MessageContent, options ...llms.CallOption) (*schema.ContentResponse, error) {
+			opts := llms.CallOptions{}
+			for _, o := range options {
+				o(&opts)
+			}
+			temperatures = append(temperatures, opts.Temperature)
+			if len(temperatures) == 1 {
+				return contentResponse("Billing."), nil
+			}
+			return contentResponse(strings.Repeat("a line copied from the prompt\n", 3)), nil
+		}).Times(2)
+	fallback.EXPECT().GenerateContent(gomock.Any(), gomock.Any()).Return(contentResponse(goodSummary), nil)
+
+	var requested string
+	b := &builderImpl{cfg: Config{
+		Logger:       slog.New(slog.DiscardHandler),
+		AIConfig:     config.AIConfig{GeneratorModel: "big", SummaryFallbackModel: "other"},
+		GeneratorLLM: generator,
+		GetLLM: func(_ context.Context, name string) (llms.Model, error) {
+			requested = name
+			return fallback, nil
+		},
+	}}
+
+	summary, err := b.generateCheckedSummary(t.Context(), "billing", "prompt:\na line copied from the prompt")
+	require.NoError(t, err)
+	assert.Equal(t, goodSummary, summary)
+	assert.Equal(t, "other", requested)
+	assert.Equal(t, retrySummaryTemperature, temperatures[1])
+}
+
+func TestGenerateCheckedSummary_GivesUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	generator := mocks.NewMockModel(ctrl)
+	generator.EXPECT().GenerateContent(gomock.Any(), gomock.Any(), gomock.Any()).Return(contentResponse("Too short."), nil).Times(2)
+
+	b := &builderImpl{cfg: Config{
+		Logger:       slog.New(slog.DiscardHandler),
+		GeneratorLLM: generator,
+	}}
+
+	_, err := b.generateCheckedSummary(t.Context(), "billing", "prompt")
+	assert.ErrorIs(t, err, errSummaryRejected, "without a fallback model two attempts are made")
+}
+
+// TestRegenerateArchSummaries verifies that a directory and the ones below
+// it are regenerated although their stored summaries are up to date.
+func TestRegenerateArchSummaries(t *testing.T) {
+	repoPath := t.TempDir()
+	for _, dir := range []string{"pkg/sub", "other"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoPath, dir), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, dir, "a.go"), []byte("package x\n"), 0o600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "pkg", "b.go"), []byte("package pkg\n"), 0o600))
+
+	promptMgr, err := llm.NewPromptManager()
+	require.NoError(t, err)
+	b := &builderImpl{cfg: Config{Logger: slog.New(slog.DiscardHandler), PromptMgr: promptMgr}}
+	_, pkgHash, err := b.scanDirectoryOnDisk(repoPath, filepath.Join(repoPath, "pkg"), "pkg")
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	generator := mocks.NewMockModel(ctrl)
+	generator.EXPECT().GenerateContent(gomock.Any(), gomock.Any()).Return(contentResponse(goodSummary), nil).Times(2)
+	mockVS := mocks.NewMockVectorStore(ctrl)
+	mockStore := mocks.NewMockScopedVectorStore(ctrl)
+	mockVS.EXPECT().ForRepo("repo", "embedder").Return(mockStore)
+	mockStore.EXPECT().
+		SimilaritySearch(gomock.Any(), "summary", 4, gomock.Any()).
+		Return([]schema.Document{{Metadata: map[string]any{"source": "pkg", "content_hash": pkgHash}}}, nil)
+	mockStore.EXPECT().
+		DeleteDocumentsByFilter(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, filter map[string]any, _ ...vectorstores.Option) error {
+			assert.ElementsMatch(t, []string{"pkg", "pkg/sub"}, filter["source"])
+			return nil
+		})
+	mockStore.EXPECT().AddDocuments(gomock.Any(), gomock.Len(2)).Return(nil, nil)
+	b.cfg.GeneratorLLM = generator
+	b.cfg.VectorStore = mockVS
+
+	n, err := b.RegenerateArchSummaries(t.Context(), "repo", "embedder", repoPath, "pkg")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
@@ -0,0 +1,117 @@
+package contextpkg
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// maxGraphDefinitionLines caps the lines read for a definition found in the
+// symbol graph. Definitions found by the regex fallback have no end line and
+// are read up to this cap as well.
+const maxGraphDefinitionLines = 60
+
+// SymbolLookup finds where symbols are defined in the repository that uses a
+// collection. storage.Store implements it.
+type SymbolLookup interface {
+	FindSymbolDefinitions(ctx context.Context, collectionName string, symbols []string) ([]storage.SymbolDefinition, error)
+}
+
+// resolveFromSymbolGraph looks up the symbols that vector search did not
+// resolve in the symbol graph and reads their definitions from repoPath.
+// Symbols defined in more than one file are left out: without the vector
+// score there is no telling which definition the diff means.
+func (b *builderImpl) resolveFromSymbolGraph(
+	ctx context.Context, collectionName, repoPath string,
+	symbols []string, resolved []resolvedDefinition,
+	seenDocs map[string]struct{}, mu *sync.RWMutex,
+) []resolvedDefinition {
+	if b.cfg.Symbols == nil || repoPath == "" {
+		return nil
+	}
+	found := make(map[string]struct{}, len(resolved))
+	for _, def := range resolved {
+		found[def.Symbol] = struct{}{}
+	}
+	var missing []string
+	for _, sym := range symbols {
+		if _, ok := found[sym]; !ok {
+			missing = append(missing, sym)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	defs, err := b.cfg.Symbols.FindSymbolDefinitions(ctx, collectionName, missing)
+	if err != nil {
+		b.cfg.Logger.WarnContext(ctx, "symbol graph lookup failed", "error", err)
+		return nil
+	}
+	bySymbol := make(map[string][]storage.SymbolDefinition)
+	for _, def := range defs {
+		bySymbol[def.Symbol] = append(bySymbol[def.Symbol], def)
+	}
+
+	var results []resolvedDefinition
+	for _, sym := range missing {
+		candidates := bySymbol[sym]
+		if len(candidates) == 0 || !sameFile(candidates) {
+			continue
+		}
+		def := candidates[0]
+		docKey := fmt.Sprintf("%s-%s", def.FilePath, def.Symbol)
+		mu.Lock()
+		_, seen := seenDocs[docKey]
+		seenDocs[docKey] = struct{}{}
+		mu.Unlock()
+		if seen {
+			continue
+		}
+		content, ok := readDefinition(repoPath, def)
+		if !ok {
+			continue
+		}
+		results = append(results, resolvedDefinition{Symbol: sym, Source: def.FilePath, Content: content})
+	}
+	b.cfg.Logger.InfoContext(ctx, "symbol graph resolution complete", "missing", len(missing), "resolved", len(results))
+	return results
+}
+
+// sameFile reports whether all defs are in the same file, as overloads or
+// methods of the same name on several types in one file are.
+func sameFile(defs []storage.SymbolDefinition) bool {
+	for _, def := range defs[1:] {
+		if def.FilePath != defs[0].FilePath {
+			return false
+		}
+	}
+	return true
+}
+
+// readDefinition reads the lines of def from the file in repoPath, capped at
+// maxGraphDefinitionLines.
+func readDefinition(repoPath string, def storage.SymbolDefinition) (string, bool) {
+	if !filepath.IsLocal(def.FilePath) || def.LineStart <= 0 {
+		return "", false
+	}
+	data, err := os.ReadFile(filepath.Join(repoPath, def.FilePath))
+	if err != nil {
+		return "", false
+	}
+	lines := strings.Split(string(data), "\n")
+	if def.LineStart > len(lines) {
+		return "", false
+	}
+	end := def.LineEnd
+	if end < def.LineStart || end-def.LineStart >= maxGraphDefinitionLines {
+		end = def.LineStart + maxGraphDefinitionLines - 1
+	}
+	end = min(end, len(lines))
+	return strings.Join(lines[def.LineStart-1:end], "\n"), true
+}
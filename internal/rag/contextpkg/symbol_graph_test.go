@@ -0,0 +1,72 @@
+package contextpkg
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// fakeSymbols returns the definitions of the symbols it is asked for.
+type fakeSymbols struct {
+	defs    []storage.SymbolDefinition
+	queried []string
+}
+
+func (f *fakeSymbols) FindSymbolDefinitions(_ context.Context, _ string, symbols []string) ([]storage.SymbolDefinition, error) {
+	f.queried = symbols
+	want := make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		want[s] = true
+	}
+	var out []storage.SymbolDefinition
+	for _, d := range f.defs {
+		if want[d.Symbol] {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func TestResolveFromSymbolGraph(t *testing.T) {
+	repoPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "pkg"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "pkg", "limits.go"),
+		[]byte("package pkg\n\n// Limit caps requests.\nfunc Limit(n int) int {\n\treturn n\n}\n"), 0o644))
+
+	symbols := &fakeSymbols{defs: []storage.SymbolDefinition{
+		{FilePath: "pkg/limits.go", Symbol: "Limit", Kind: "func", LineStart: 4, LineEnd: 6},
+		{FilePath: "a/run.go", Symbol: "Run", LineStart: 1},
+		{FilePath: "b/run.go", Symbol: "Run", LineStart: 1},
+	}}
+	b := &builderImpl{cfg: Config{Logger: slog.Default(), Symbols: symbols}}
+	resolved := []resolvedDefinition{{Symbol: "Config", Source: "config.go"}}
+
+	got := b.resolveFromSymbolGraph(t.Context(), "coll", repoPath, []string{"Config", "Limit", "Run"}, resolved, make(map[string]struct{}), &sync.RWMutex{})
+
+	assert.Equal(t, []string{"Limit", "Run"}, symbols.queried, "resolved symbols are not looked up again")
+	require.Len(t, got, 1, "symbols defined in several files are ambiguous")
+	assert.Equal(t, "pkg/limits.go", got[0].Source)
+	assert.Equal(t, "func Limit(n int) int {\n\treturn n\n}", got[0].Content)
+}
+
+func TestReadDefinition(t *testing.T) {
+	repoPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "a.go"), []byte("l1\nl2\nl3\n"), 0o644))
+
+	content, ok := readDefinition(repoPath, storage.SymbolDefinition{FilePath: "a.go", LineStart: 2})
+	assert.True(t, ok)
+	assert.Equal(t, "l2\nl3\n", content, "a missing end line reads to the cap")
+
+	_, ok = readDefinition(repoPath, storage.SymbolDefinition{FilePath: "../a.go", LineStart: 1})
+	assert.False(t, ok)
+	_, ok = readDefinition(repoPath, storage.SymbolDefinition{FilePath: "a.go", LineStart: 10})
+	assert.False(t, ok)
+}
@@ -36,7 +36,7 @@ const maxSymbolWorkers = 10
 const maxDefinitionChars = 15000 // Cap definitions to ~15k chars (~4k tokens)
 
 //nolint:unparam // error always nil but signature required for errgroup
-func (b *builderImpl) gatherDefinitionsContext(ctx context.Context, scopedStore storage.ScopedVectorStore, changedFiles []internalgithub.ChangedFile) (string, error) {
+func (b *builderImpl) gatherDefinitionsContext(ctx context.Context, scopedStore storage.ScopedVectorStore, collectionName, repoPath string, changedFiles []internalgithub.ChangedFile) (string, error) {
 	if len(changedFiles) == 0 {
 		return "", nil
 	}
@@ -46,16 +46,16 @@ func (b *builderImpl) gatherDefinitionsContext(ctx context.Context, scopedStore
 
 	symbolList := b.extractDepth0Symbols(changedFiles)
 	if len(symbolList) == 0 {
-		b.cfg.Logger.Info("stage skipped", "name", "SymbolResolution", "reason", "no_symbols_found")
+		b.cfg.Logger.InfoContext(ctx, "stage skipped", "name", "SymbolResolution", "reason", "no_symbols_found")
 		return "", nil
 	}
 
-	b.cfg.Logger.Debug("extracted symbols from diff", "symbols", symbolList)
-	b.cfg.Logger.Info("stage started", "name", "SymbolResolution", "depth0_symbols", len(symbolList))
+	b.cfg.Logger.DebugContext(ctx, "extracted symbols from diff", "symbols", symbolList)
+	b.cfg.Logger.InfoContext(ctx, "stage started", "name", "SymbolResolution", "depth0_symbols", len(symbolList))
 
 	defRetriever, err := vectorstores.NewDefinitionRetriever(scopedStore)
 	if err != nil {
-		b.cfg.Logger.Warn("failed to create definition retriever, skipping symbol resolution", "error", err)
+		b.cfg.Logger.WarnContext(ctx, "failed to create definition retriever, skipping symbol resolution", "error", err)
 		return "", nil
 	}
 
@@ -65,7 +65,10 @@ func (b *builderImpl) gatherDefinitionsContext(ctx context.Context, scopedStore
 	}
 
 	depth1Defs := b.resolveSymbolsConcurrently(ctx, symbolList, scopedStore, defRetriever, seenDocs, mu)
-	b.cfg.Logger.Info("depth-1 resolution complete", "resolved", len(depth1Defs))
+	// Vector search misses definitions whose chunk ranks below others; the
+	// symbol graph knows exactly where the rest are defined.
+	depth1Defs = append(depth1Defs, b.resolveFromSymbolGraph(ctx, collectionName, repoPath, symbolList, depth1Defs, seenDocs, mu)...)
+	b.cfg.Logger.InfoContext(ctx, "depth-1 resolution complete", "resolved", len(depth1Defs))
 
 	depth2Defs := b.resolveDepth2Symbols(ctx, depth1Defs, seenSymbols, scopedStore, defRetriever, seenDocs, mu)
 
@@ -113,9 +116,9 @@ func (b *builderImpl) resolveDepth2Symbols(
 		return nil
 	}
 
-	b.cfg.Logger.Info("depth-2 resolution started", "transitive_symbols", len(candidates))
+	b.cfg.Logger.InfoContext(ctx, "depth-2 resolution started", "transitive_symbols", len(candidates))
 	results := b.resolveSymbolsConcurrently(ctx, candidates, store, defRetriever, seenDocs, mu)
-	b.cfg.Logger.Info("depth-2 resolution complete", "resolved", len(results))
+	b.cfg.Logger.InfoContext(ctx, "depth-2 resolution complete", "resolved", len(results))
 	return results
 }
 
@@ -340,7 +343,7 @@ func (b *builderImpl) resolveSymbolDefinition(ctx context.Context, symbol string
 	// Fallback: semantic search for symbols whose identifier wasn't indexed exactly.
 	docs, err := defRetriever.GetDefinition(ctx, symbol)
 	if err != nil {
-		b.cfg.Logger.Debug("failed to search for definition", "symbol", symbol, "error", err)
+		b.cfg.Logger.DebugContext(ctx, "failed to search for definition", "symbol", symbol, "error", err)
 		return "", "", false
 	}
 
@@ -162,7 +162,7 @@ func TestGatherDefinitionsContext_EmptyInput(t *testing.T) {
 		cfg: Config{Logger: slog.Default()},
 	}
 
-	result, err := r.gatherDefinitionsContext(t.Context(), nil, []internalgithub.ChangedFile{})
+	result, err := r.gatherDefinitionsContext(t.Context(), nil, "", "", []internalgithub.ChangedFile{})
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -183,7 +183,7 @@ func TestGatherDefinitionsContext_NoPatch(t *testing.T) {
 		{Filename: "utils.go", Patch: ""},
 	}
 
-	result, err := r.gatherDefinitionsContext(t.Context(), nil, changedFiles)
+	result, err := r.gatherDefinitionsContext(t.Context(), nil, "", "", changedFiles)
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
@@ -221,7 +221,7 @@ func TestGatherDefinitionsContext_WithSymbols(t *testing.T) {
 		},
 	}
 
-	result, err := r.gatherDefinitionsContext(t.Context(), mockSVS, changedFiles)
+	result, err := r.gatherDefinitionsContext(t.Context(), mockSVS, "", "", changedFiles)
 
 	if err != nil {
 		t.Errorf("expected no error, got %v", err)
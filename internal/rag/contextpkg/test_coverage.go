@@ -13,6 +13,7 @@ import (
 
 	internalgithub "github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/testgap"
 )
 
 const maxCoverageChunks = 10
@@ -25,25 +26,25 @@ func (b *builderImpl) gatherTestCoverageContext(
 	changedFiles []internalgithub.ChangedFile,
 	definitionsContext string,
 ) ([]schema.Document, error) {
-	b.cfg.Logger.Info("stage started", "name", "TestCoverage")
+	b.cfg.Logger.InfoContext(ctx, "stage started", "name", "TestCoverage")
 
 	// Extract symbols from definitions context
 	symbols := extractSymbolsFromDefinitions(definitionsContext)
 	if len(symbols) == 0 {
-		b.cfg.Logger.Info("stage completed", "name", "TestCoverage", "reason", "no_symbols_found")
+		b.cfg.Logger.InfoContext(ctx, "stage completed", "name", "TestCoverage", "reason", "no_symbols_found")
 		return nil, nil
 	}
 
 	// Get source files from changed files
 	sourceFiles := make(map[string]bool)
 	for _, f := range changedFiles {
-		if f.Patch != "" && !isTestFilePath(f.Filename) {
+		if f.Patch != "" && !testgap.IsTestFile(f.Filename) {
 			sourceFiles[f.Filename] = true
 		}
 	}
 
 	if len(sourceFiles) == 0 {
-		b.cfg.Logger.Info("stage completed", "name", "TestCoverage", "reason", "no_source_files")
+		b.cfg.Logger.InfoContext(ctx, "stage completed", "name", "TestCoverage", "reason", "no_source_files")
 		return nil, nil
 	}
 
@@ -61,7 +62,7 @@ func (b *builderImpl) gatherTestCoverageContext(
 		g.Go(func() error {
 			docs, err := b.searchTestChunksForSymbol(ctx, scopedStore, sym, sourceFiles)
 			if err != nil {
-				b.cfg.Logger.Debug("failed to search test chunks", "symbol", sym, "error", err)
+				b.cfg.Logger.DebugContext(ctx, "failed to search test chunks", "symbol", sym, "error", err)
 				return nil
 			}
 
@@ -94,7 +95,7 @@ func (b *builderImpl) gatherTestCoverageContext(
 		deduped = deduped[:maxCoverageChunks]
 	}
 
-	b.cfg.Logger.Info("stage completed", "name", "TestCoverage",
+	b.cfg.Logger.InfoContext(ctx, "stage completed", "name", "TestCoverage",
 		"symbols_searched", len(symbols),
 		"test_chunks_found", len(deduped))
 
@@ -120,7 +121,7 @@ func (b *builderImpl) searchTestChunksForSymbol(
 	if sparseVec, sparseErr := sparse.GenerateSparseVector(ctx, symbol); sparseErr == nil {
 		opts = append(opts, vectorstores.WithSparseQuery(sparseVec))
 	} else {
-		b.cfg.Logger.Debug("sparse vector generation failed for test coverage, using dense only", "symbol", symbol, "error", sparseErr)
+		b.cfg.Logger.DebugContext(ctx, "sparse vector generation failed for test coverage, using dense only", "symbol", symbol, "error", sparseErr)
 	}
 	docs, err := scopedStore.SimilaritySearch(ctx, symbol, 5, opts...)
 	if err != nil {
@@ -230,17 +231,6 @@ func extractFuncSymbol(line string, symbols map[string]bool) {
 	}
 }
 
-func isTestFilePath(path string) bool {
-	return strings.HasSuffix(path, "_test.go") ||
-		strings.HasSuffix(path, ".test.ts") ||
-		strings.HasSuffix(path, ".test.tsx") ||
-		strings.HasSuffix(path, ".spec.ts") ||
-		strings.HasSuffix(path, ".spec.tsx") ||
-		strings.HasSuffix(path, ".test.js") ||
-		strings.HasSuffix(path, ".spec.js") ||
-		strings.HasSuffix(path, "_test.py")
-}
-
 // formatTestCoverageContext formats test coverage documents into context.
 func (b *builderImpl) formatTestCoverageContext(docs []schema.Document) string {
 	if len(docs) == 0 {
@@ -0,0 +1,45 @@
+package contextpkg
+
+import (
+	"context"
+
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// thresholdStore drops search results below a minimum similarity. Plain
+// searches go through SimilaritySearchWithScores, since the query cache of
+// the scoped store does not tell thresholds apart.
+type thresholdStore struct {
+	storage.ScopedVectorStore
+	threshold float32
+}
+
+// withScoreThreshold returns store limited to results scoring at least
+// threshold, or store itself when threshold is not positive.
+func withScoreThreshold(store storage.ScopedVectorStore, threshold float32) storage.ScopedVectorStore {
+	if threshold <= 0 {
+		return store
+	}
+	return thresholdStore{ScopedVectorStore: store, threshold: threshold}
+}
+
+func (s thresholdStore) SimilaritySearch(ctx context.Context, query string, numDocs int, opts ...vectorstores.Option) ([]schema.Document, error) {
+	scored, err := s.SimilaritySearchWithScores(ctx, query, numDocs, append(opts, vectorstores.WithScoreThreshold(s.threshold))...)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]schema.Document, 0, len(scored))
+	for _, d := range scored {
+		if d.Score >= s.threshold {
+			docs = append(docs, d.Document)
+		}
+	}
+	return docs, nil
+}
+
+func (s thresholdStore) SimilaritySearchBatch(ctx context.Context, queries []string, numDocs int, opts ...vectorstores.Option) ([][]schema.Document, error) {
+	return s.ScopedVectorStore.SimilaritySearchBatch(ctx, queries, numDocs, append(opts, vectorstores.WithScoreThreshold(s.threshold))...)
+}
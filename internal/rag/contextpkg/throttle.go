@@ -0,0 +1,197 @@
+package contextpkg
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Summary generation defaults, used when the AI config leaves them unset.
+const (
+	defaultSummaryWorkers        = 5
+	defaultComparisonConcurrency = 10
+)
+
+const (
+	// maxSummaryAttempts is how often a summary is attempted while the LLM
+	// provider rate limits or times out.
+	maxSummaryAttempts = 3
+	// throttleRecovery is the number of successful calls after which a
+	// throttled limit grows by one again.
+	throttleRecovery = 5
+	// summaryProgressInterval is how often summary progress is logged.
+	summaryProgressInterval = 15 * time.Second
+)
+
+// summaryRetryBackoff is the wait before the first retry of a throttled
+// call; later retries wait proportionally longer. A variable for tests.
+var summaryRetryBackoff = 5 * time.Second
+
+// throttle bounds the concurrent LLM calls of summary generation. It starts
+// at max calls, halves the limit whenever the provider rate limits or times
+// out, and raises it by one after every throttleRecovery successful calls,
+// so a pool sized for a fast provider settles at what it can take.
+type throttle struct {
+	max int
+
+	mu        sync.Mutex
+	limit     int
+	active    int
+	successes int
+	// freed is closed, and replaced, whenever a call may start.
+	freed chan struct{}
+}
+
+func newThrottle(maxCalls int) *throttle {
+	maxCalls = max(maxCalls, 1)
+	return &throttle{max: maxCalls, limit: maxCalls, freed: make(chan struct{})}
+}
+
+// acquire waits until a call may start.
+func (t *throttle) acquire(ctx context.Context) error {
+	for {
+		t.mu.Lock()
+		if t.active < t.limit {
+			t.active++
+			t.mu.Unlock()
+			return nil
+		}
+		freed := t.freed
+		t.mu.Unlock()
+
+		select {
+		case <-freed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// release ends a call and adapts the limit to its outcome.
+func (t *throttle) release(throttled bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active--
+	switch {
+	case throttled:
+		t.limit = max(t.limit/2, 1)
+		t.successes = 0
+	case t.limit < t.max:
+		t.successes++
+		if t.successes >= throttleRecovery {
+			t.limit++
+			t.successes = 0
+		}
+	}
+	close(t.freed)
+	t.freed = make(chan struct{})
+}
+
+// Limit returns the current number of concurrent calls allowed.
+func (t *throttle) Limit() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.limit
+}
+
+// isThrottleError reports whether err means the LLM provider is overloaded:
+// a 429 or quota error, or a call that timed out while ctx itself was still
+// live. Providers only report these as text, so the message is matched.
+func isThrottleError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"429", "too many requests", "rate limit", "resource_exhausted", "resource exhausted", "timeout", "timed out"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// callThrottled runs call within t, retrying it with a growing backoff
+// while the provider throttles it, up to maxSummaryAttempts times.
+func (b *builderImpl) callThrottled(ctx context.Context, t *throttle, path string, call func() error) error {
+	for attempt := 1; ; attempt++ {
+		if err := t.acquire(ctx); err != nil {
+			return err
+		}
+		err := call()
+		throttled := isThrottleError(ctx, err)
+		t.release(throttled)
+		if !throttled || attempt == maxSummaryAttempts {
+			return err
+		}
+
+		backoff := summaryRetryBackoff * time.Duration(attempt)
+		b.cfg.Logger.WarnContext(ctx, "LLM provider is throttling summary generation, retrying with fewer workers",
+			"path", path,
+			"attempt", attempt,
+			"workers", t.Limit(),
+			"backoff", backoff,
+			"error", err,
+		)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// summaryProgress logs the progress of a batch of summaries, with an
+// estimate of the time left, at most every summaryProgressInterval.
+type summaryProgress struct {
+	logger  *slog.Logger
+	msg     string
+	total   int
+	started time.Time
+
+	mu      sync.Mutex
+	done    int
+	failed  int
+	lastLog time.Time
+}
+
+func newSummaryProgress(logger *slog.Logger, msg string, total int) *summaryProgress {
+	now := time.Now()
+	return &summaryProgress{logger: logger, msg: msg, total: total, started: now, lastLog: now}
+}
+
+// add records a finished summary and logs the progress when it is due.
+func (p *summaryProgress) add(ctx context.Context, t *throttle, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if failed {
+		p.failed++
+	}
+	now := time.Now()
+	if p.done < p.total && now.Sub(p.lastLog) < summaryProgressInterval {
+		return
+	}
+	p.lastLog = now
+
+	elapsed := now.Sub(p.started)
+	eta := time.Duration(float64(elapsed) / float64(p.done) * float64(p.total-p.done))
+	p.logger.InfoContext(ctx, p.msg,
+		"done", p.done,
+		"total", p.total,
+		"failed", p.failed,
+		"workers", t.Limit(),
+		"elapsed", elapsed.Round(time.Second),
+		"eta", eta.Round(time.Second),
+	)
+}
@@ -0,0 +1,104 @@
+package contextpkg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestThrottle_AdaptsLimit(t *testing.T) {
+	th := newThrottle(8)
+	ctx := context.Background()
+
+	require.NoError(t, th.acquire(ctx))
+	th.release(true)
+	assert.Equal(t, 4, th.Limit(), "a throttled call halves the limit")
+	require.NoError(t, th.acquire(ctx))
+	th.release(true)
+	require.NoError(t, th.acquire(ctx))
+	th.release(true)
+	require.NoError(t, th.acquire(ctx))
+	th.release(true)
+	assert.Equal(t, 1, th.Limit(), "the limit never drops below one")
+
+	for range throttleRecovery {
+		require.NoError(t, th.acquire(ctx))
+		th.release(false)
+	}
+	assert.Equal(t, 2, th.Limit(), "successful calls raise the limit again")
+
+	for range 10 * throttleRecovery {
+		require.NoError(t, th.acquire(ctx))
+		th.release(false)
+	}
+	assert.Equal(t, 8, th.Limit(), "the limit never exceeds the maximum")
+}
+
+func TestThrottle_AcquireWaits(t *testing.T) {
+	th := newThrottle(1)
+	require.NoError(t, th.acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, th.acquire(ctx), context.DeadlineExceeded, "no slot is free")
+
+	acquired := make(chan error)
+	go func() { acquired <- th.acquire(context.Background()) }()
+	th.release(false)
+	assert.NoError(t, <-acquired)
+}
+
+func TestIsThrottleError(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, isThrottleError(ctx, errors.New("ollama: status 429: too many requests")))
+	assert.True(t, isThrottleError(ctx, errors.New("googleapi: Error 429: RESOURCE_EXHAUSTED")))
+	assert.True(t, isThrottleError(ctx, fmt.Errorf("generate: %w", context.DeadlineExceeded)))
+	assert.False(t, isThrottleError(ctx, errors.New("model not found")))
+	assert.False(t, isThrottleError(ctx, nil))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.False(t, isThrottleError(cancelled, context.Canceled), "our own cancellation is not throttling")
+}
+
+func TestCallThrottled_Retries(t *testing.T) {
+	summaryRetryBackoff = time.Millisecond
+	t.Cleanup(func() { summaryRetryBackoff = 5 * time.Second })
+
+	b := &builderImpl{cfg: Config{Logger: slog.New(slog.DiscardHandler)}}
+	th := newThrottle(4)
+
+	var calls atomic.Int32
+	err := b.callThrottled(context.Background(), th, "pkg", func() error {
+		if calls.Add(1) < 3 {
+			return errors.New("429 Too Many Requests")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, calls.Load())
+	assert.Equal(t, 1, th.Limit())
+
+	calls.Store(0)
+	err = b.callThrottled(context.Background(), th, "pkg", func() error {
+		calls.Add(1)
+		return errors.New("rate limit exceeded")
+	})
+	require.Error(t, err)
+	assert.EqualValues(t, maxSummaryAttempts, calls.Load(), "gives up after maxSummaryAttempts")
+
+	calls.Store(0)
+	err = b.callThrottled(context.Background(), th, "pkg", func() error {
+		calls.Add(1)
+		return errors.New("model not found")
+	})
+	require.Error(t, err)
+	assert.EqualValues(t, 1, calls.Load(), "other errors are not retried")
+}
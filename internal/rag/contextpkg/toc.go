@@ -19,7 +19,7 @@ import (
 // Each TOC chunk is a single Qdrant point (chunk_type="toc", source=relPath)
 // so the fetch is a cheap exact-filter query — one round-trip per file.
 func (b *builderImpl) gatherTOCContext(ctx context.Context, store storage.ScopedVectorStore, files []internalgithub.ChangedFile) (string, error) {
-	b.cfg.Logger.Info("stage started", "name", "TOCContext")
+	b.cfg.Logger.InfoContext(ctx, "stage started", "name", "TOCContext")
 
 	var sb strings.Builder
 	found := 0
@@ -42,11 +42,11 @@ func (b *builderImpl) gatherTOCContext(ctx context.Context, store storage.Scoped
 			}),
 		)
 		if err != nil {
-			b.cfg.Logger.Debug("TOC fetch failed", "file", f.Filename, "error", err)
+			b.cfg.Logger.DebugContext(ctx, "TOC fetch failed", "file", f.Filename, "error", err)
 			continue
 		}
 		if len(docs) == 0 {
-			b.cfg.Logger.Debug("TOC chunk not found", "file", f.Filename)
+			b.cfg.Logger.DebugContext(ctx, "TOC chunk not found", "file", f.Filename)
 			continue
 		}
 
@@ -55,6 +55,6 @@ func (b *builderImpl) gatherTOCContext(ctx context.Context, store storage.Scoped
 		found++
 	}
 
-	b.cfg.Logger.Info("stage completed", "name", "TOCContext", "files_with_toc", found, "files_total", len(files))
+	b.cfg.Logger.InfoContext(ctx, "stage completed", "name", "TOCContext", "files_with_toc", found, "files_total", len(files))
 	return sb.String(), nil
 }
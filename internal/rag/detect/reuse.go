@@ -90,11 +90,11 @@ func (d *ReuseDetector) DetectRedundancies(
 	}
 
 	if len(newFunctions) == 0 {
-		d.logger.Info("no new functions found in diff")
+		d.logger.InfoContext(ctx, "no new functions found in diff")
 		return nil, nil
 	}
 
-	d.logger.Info("detecting redundancies", "new_functions", len(newFunctions))
+	d.logger.InfoContext(ctx, "detecting redundancies", "new_functions", len(newFunctions))
 
 	// Process functions concurrently using errgroup
 	var mu sync.Mutex
@@ -108,7 +108,7 @@ func (d *ReuseDetector) DetectRedundancies(
 		g.Go(func() error {
 			suggestion, err := d.processFunction(ctx, collectionName, embedderModel, fn)
 			if err != nil {
-				d.logger.Warn("failed to process function for redundancy detection",
+				d.logger.WarnContext(ctx, "failed to process function for redundancy detection",
 					"function", fn.Name,
 					"file", fn.FilePath,
 					"error", err,
@@ -129,7 +129,7 @@ func (d *ReuseDetector) DetectRedundancies(
 		return nil, fmt.Errorf("redundancy detection failed: %w", err)
 	}
 
-	d.logger.Info("redundancy detection complete", "suggestions_found", len(suggestions))
+	d.logger.InfoContext(ctx, "redundancy detection complete", "suggestions_found", len(suggestions))
 	return suggestions, nil
 }
 
@@ -231,7 +231,7 @@ func (d *ReuseDetector) processFunction(
 		return nil, fmt.Errorf("intent extraction failed: %w", err)
 	}
 
-	d.logger.Debug("extracted intent for function",
+	d.logger.DebugContext(ctx, "extracted intent for function",
 		"function", fn.Name,
 		"intent", intentQuery,
 	)
@@ -325,7 +325,7 @@ func (d *ReuseDetector) verifyRedundancy(
 
 		prompt, err := d.promptMgr.Render(llm.ReuseVerificationPrompt, promptData)
 		if err != nil {
-			d.logger.Warn("failed to render verification prompt", "error", err)
+			d.logger.WarnContext(ctx, "failed to render verification prompt", "error", err)
 			continue
 		}
 
@@ -335,13 +335,13 @@ func (d *ReuseDetector) verifyRedundancy(
 			chains.WithOutputParser(parser),
 		)
 		if err != nil {
-			d.logger.Warn("failed to create LLM chain", "error", err)
+			d.logger.WarnContext(ctx, "failed to create LLM chain", "error", err)
 			continue
 		}
 
 		verdict, err := chain.Call(ctx, nil)
 		if err != nil {
-			d.logger.Warn("verification LLM call failed", "error", err)
+			d.logger.WarnContext(ctx, "verification LLM call failed", "error", err)
 			continue
 		}
 
@@ -0,0 +1,150 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+
+	"github.com/sevigo/code-warden/internal/cryptoutil"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// contentHashKey is the metadata key of the hash that identifies a chunk's
+// embedded text and position; see chunkHash.
+const contentHashKey = "content_hash"
+
+// maxStoredChunksPerFile bounds the lookup of the chunks a file has in the
+// index. Files with more are re-embedded in full.
+const maxStoredChunksPerFile = 1000
+
+// chunkHash returns the hash of what decides whether a stored chunk can be
+// kept: its embedded text, its type and its lines. Chunks whose file summary
+// changed differ in their text and are embedded again.
+func chunkHash(doc schema.Document) string {
+	chunkType, _ := doc.Metadata["chunk_type"].(string)
+	return cryptoutil.HashString(chunkType + "\x00" +
+		metadataString(doc.Metadata["line"]) + "\x00" +
+		metadataString(doc.Metadata["end_line"]) + "\x00" +
+		doc.PageContent)
+}
+
+func metadataString(v any) string {
+	switch n := v.(type) {
+	case nil:
+		return ""
+	case int:
+		return strconv.Itoa(n)
+	case int64:
+		return strconv.FormatInt(n, 10)
+	default:
+		return fmt.Sprint(n)
+	}
+}
+
+// setContentHashes stores the chunk hash of each document in its metadata.
+func setContentHashes(docs []schema.Document) {
+	for i := range docs {
+		docs[i].Metadata[contentHashKey] = chunkHash(docs[i])
+	}
+}
+
+// chunkDiff is how the new chunks of a changed file compare to those stored.
+type chunkDiff struct {
+	// embed are the chunks that are not stored yet.
+	embed []schema.Document
+	// reused counts the chunks that are stored unchanged.
+	reused int
+	// stale are the hashes of stored chunks the file no longer has.
+	stale []string
+	// legacy is set when the file has chunks stored without a hash, which
+	// are replaced in full.
+	legacy bool
+}
+
+// diffChunks compares docs, the new chunks of file, with the chunks stored
+// for it. Only chunk types that ProcessFile produces are considered, since
+// summaries of other stages share the source of the file. When the lookup
+// fails every chunk is embedded.
+func (i *Indexer) diffChunks(ctx context.Context, store storage.ScopedVectorStore, file string, docs []schema.Document) chunkDiff {
+	types := make(map[string]bool)
+	for _, doc := range docs {
+		if chunkType, ok := doc.Metadata["chunk_type"].(string); ok {
+			types[chunkType] = true
+		}
+	}
+
+	stored, err := store.SimilaritySearchWithScores(ctx, file, maxStoredChunksPerFile,
+		vectorstores.WithFilters(map[string]any{"source": file}))
+	if err != nil {
+		i.cfg.Logger.WarnContext(ctx, "failed to look up stored chunks, embedding all", "file", file, "error", err)
+		return chunkDiff{embed: docs}
+	}
+	if len(stored) >= maxStoredChunksPerFile {
+		return chunkDiff{embed: docs, legacy: true}
+	}
+
+	storedHashes := make(map[string]bool, len(stored))
+	legacy := false
+	for _, s := range stored {
+		chunkType, _ := s.Document.Metadata["chunk_type"].(string)
+		if !types[chunkType] {
+			continue
+		}
+		hash, ok := s.Document.Metadata[contentHashKey].(string)
+		if !ok || hash == "" {
+			legacy = true
+			continue
+		}
+		storedHashes[hash] = true
+	}
+	if legacy {
+		return chunkDiff{embed: docs, legacy: true}
+	}
+
+	var diff chunkDiff
+	current := make(map[string]bool, len(docs))
+	for _, doc := range docs {
+		hash, _ := doc.Metadata[contentHashKey].(string)
+		current[hash] = true
+		if storedHashes[hash] {
+			diff.reused++
+			continue
+		}
+		diff.embed = append(diff.embed, doc)
+	}
+	for hash := range storedHashes {
+		if !current[hash] {
+			diff.stale = append(diff.stale, hash)
+		}
+	}
+	return diff
+}
+
+// removeStoredChunks deletes the chunks of file the diff replaces: the stale
+// ones, or all chunks of the types in docs for a legacy file.
+func (i *Indexer) removeStoredChunks(ctx context.Context, store storage.ScopedVectorStore, file string, docs []schema.Document, diff chunkDiff) error {
+	filter := map[string]any{"source": file}
+	switch {
+	case diff.legacy:
+		var types []string
+		seen := make(map[string]bool)
+		for _, doc := range docs {
+			if chunkType, ok := doc.Metadata["chunk_type"].(string); ok && !seen[chunkType] {
+				seen[chunkType] = true
+				types = append(types, chunkType)
+			}
+		}
+		if len(types) == 0 {
+			return nil
+		}
+		filter["chunk_type"] = types
+	case len(diff.stale) > 0:
+		filter[contentHashKey] = diff.stale
+	default:
+		return nil
+	}
+	return store.DeleteDocumentsByFilter(ctx, filter)
+}
@@ -0,0 +1,81 @@
+package index
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/mocks"
+)
+
+func hashedDocs(contents ...string) []schema.Document {
+	docs := make([]schema.Document, len(contents))
+	for i, content := range contents {
+		docs[i] = schema.NewDocument(content, map[string]any{"source": "a.go", "chunk_type": "code", "line": i + 1})
+	}
+	setContentHashes(docs)
+	return docs
+}
+
+func TestChunkHash(t *testing.T) {
+	docs := hashedDocs("func A() {}", "func A() {}")
+	assert.NotEqual(t, docs[0].Metadata[contentHashKey], docs[1].Metadata[contentHashKey], "the position is part of the hash")
+
+	again := hashedDocs("func A() {}")
+	assert.Equal(t, docs[0].Metadata[contentHashKey], again[0].Metadata[contentHashKey])
+}
+
+func TestDiffChunks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockScopedVectorStore(ctrl)
+	indexer := New(Config{Logger: slog.Default()})
+	ctx := context.Background()
+
+	old := hashedDocs("func A() {}", "func B() {}")
+	current := hashedDocs("func A() {}", "func C() {}")
+	stored := []vectorstores.DocumentWithScore{
+		{Document: old[0]},
+		{Document: old[1]},
+		// Summaries of other stages share the source and are left alone.
+		{Document: schema.NewDocument("summary", map[string]any{"source": "a.go", "chunk_type": "arch"})},
+	}
+
+	t.Run("unchanged chunks are reused", func(t *testing.T) {
+		store.EXPECT().SimilaritySearchWithScores(ctx, "a.go", maxStoredChunksPerFile, gomock.Any()).Return(stored, nil)
+		diff := indexer.diffChunks(ctx, store, "a.go", current)
+		assert.Equal(t, 1, diff.reused)
+		require.Len(t, diff.embed, 1)
+		assert.Equal(t, "func C() {}", diff.embed[0].PageContent)
+		assert.Equal(t, []string{old[1].Metadata[contentHashKey].(string)}, diff.stale)
+
+		store.EXPECT().DeleteDocumentsByFilter(ctx, map[string]any{"source": "a.go", contentHashKey: diff.stale}).Return(nil)
+		require.NoError(t, indexer.removeStoredChunks(ctx, store, "a.go", current, diff))
+	})
+
+	t.Run("chunks without a hash are replaced", func(t *testing.T) {
+		legacy := schema.NewDocument("func A() {}", map[string]any{"source": "a.go", "chunk_type": "code"})
+		store.EXPECT().SimilaritySearchWithScores(ctx, "a.go", maxStoredChunksPerFile, gomock.Any()).
+			Return([]vectorstores.DocumentWithScore{{Document: legacy}}, nil)
+		diff := indexer.diffChunks(ctx, store, "a.go", current)
+		assert.True(t, diff.legacy)
+		assert.Len(t, diff.embed, 2)
+
+		store.EXPECT().DeleteDocumentsByFilter(ctx, map[string]any{"source": "a.go", "chunk_type": []string{"code"}}).Return(nil)
+		require.NoError(t, indexer.removeStoredChunks(ctx, store, "a.go", current, diff))
+	})
+
+	t.Run("failed lookups embed everything", func(t *testing.T) {
+		store.EXPECT().SimilaritySearchWithScores(ctx, "a.go", maxStoredChunksPerFile, gomock.Any()).Return(nil, errors.New("unavailable"))
+		diff := indexer.diffChunks(ctx, store, "a.go", current)
+		assert.Len(t, diff.embed, 2)
+		assert.Empty(t, diff.stale)
+		require.NoError(t, indexer.removeStoredChunks(ctx, store, "a.go", current, diff))
+	})
+}
@@ -35,7 +35,7 @@ func (i *Indexer) ProcessDocsFile(ctx context.Context, repoPath, file string) []
 
 	contentBytes, err := os.ReadFile(fullPath)
 	if err != nil {
-		i.cfg.Logger.Error("failed to read docs file", "file", file, "error", err)
+		i.cfg.Logger.ErrorContext(ctx, "failed to read docs file", "file", file, "error", err)
 		return nil
 	}
 
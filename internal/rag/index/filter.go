@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/pathutil"
 )
 
 // FilterFilesByExtensions removes files whose extension matches an excluded extension.
@@ -55,21 +56,11 @@ func FilterFilesByDirectories(files []string, excludeDirs []string) []string {
 
 	filtered := make([]string, 0, len(files))
 	for _, file := range files {
-		cleanFile := filepath.ToSlash(filepath.Clean(strings.TrimPrefix(file, string(filepath.Separator))))
+		cleanFile := strings.TrimPrefix(pathutil.ToSlash(file), "/")
 
 		isExcluded := false
 		for _, excludeDir := range excludeDirs {
-			cleanExcludeDir := filepath.Clean(excludeDir)
-
-			// Check if the file path is exactly the excluded directory
-			if cleanFile == cleanExcludeDir {
-				isExcluded = true
-				break
-			}
-
-			// Check if the file path starts with the excluded directory followed by a separator
-			// Use forward slash for cross-platform consistency
-			if strings.HasPrefix(cleanFile, cleanExcludeDir+"/") {
+			if pathutil.Within(cleanFile, excludeDir) {
 				isExcluded = true
 				break
 			}
@@ -91,12 +82,12 @@ func FilterFilesBySpecificFiles(files []string, excludeFiles []string) []string
 
 	excludeMap := make(map[string]struct{}, len(excludeFiles))
 	for _, f := range excludeFiles {
-		excludeMap[filepath.ToSlash(filepath.Clean(f))] = struct{}{}
+		excludeMap[pathutil.Clean(f)] = struct{}{}
 	}
 
 	filtered := make([]string, 0, len(files))
 	for _, file := range files {
-		if _, isExcluded := excludeMap[filepath.ToSlash(filepath.Clean(file))]; !isExcluded {
+		if _, isExcluded := excludeMap[pathutil.Clean(file)]; !isExcluded {
 			filtered = append(filtered, file)
 		}
 	}
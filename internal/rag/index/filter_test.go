@@ -79,6 +79,12 @@ func TestFiltering(t *testing.T) {
 				exclude: []string{"vendor", "node_modules"},
 				want:    []string{},
 			},
+			{
+				name:    "windows separators",
+				files:   []string{`third_party\gen\a.go`, "third_party/gen/b.go", "third_party/c.go"},
+				exclude: []string{`third_party\gen`},
+				want:    []string{"third_party/c.go"},
+			},
 		}
 
 		for _, tt := range tests {
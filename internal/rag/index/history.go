@@ -0,0 +1,205 @@
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sevigo/goframe/schema"
+)
+
+const (
+	// churnWindow bounds how far back commits count towards a file's churn.
+	// Older commits still contribute to the last-modified date.
+	churnWindow = 365 * 24 * time.Hour
+
+	// HotspotChurnThreshold is the churn count at which a file is flagged
+	// as a frequently-changed hotspot.
+	HotspotChurnThreshold = 10
+
+	// maxBlameCacheEntries caps the blame cache; it is reset when exceeded.
+	maxBlameCacheEntries = 10000
+)
+
+// FileHistory summarizes the git history of a single file.
+type FileHistory struct {
+	LastModified time.Time
+	Churn        int
+}
+
+// repoHistory holds per-file history for a repository at a given HEAD.
+type repoHistory struct {
+	head  string
+	files map[string]FileHistory
+}
+
+// historyCache extracts git history for chunk metadata enrichment.
+// File-level churn is loaded with a single `git log` per repository HEAD,
+// and per-line blame timestamps are cached by file content hash so
+// re-indexing an unchanged file never shells out to git again.
+type historyCache struct {
+	mu    sync.Mutex
+	repos map[string]*repoHistory
+	blame map[string][]int64
+}
+
+func newHistoryCache() *historyCache {
+	return &historyCache{
+		repos: make(map[string]*repoHistory),
+		blame: make(map[string][]int64),
+	}
+}
+
+// fileHistory returns the history for file, loading the repository log on
+// first use or when HEAD has moved. The second return is false when the
+// path is not a git repository or the file has no commits.
+func (h *historyCache) fileHistory(ctx context.Context, repoPath, file string) (FileHistory, bool) {
+	head, err := runGit(ctx, repoPath, "rev-parse", "HEAD")
+	if err != nil {
+		return FileHistory{}, false
+	}
+	head = strings.TrimSpace(head)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	rh, ok := h.repos[repoPath]
+	if !ok || rh.head != head {
+		out, err := runGit(ctx, repoPath, "log", "--no-merges", "--no-renames", "--format=format:%x1e%ct", "--name-only")
+		if err != nil {
+			return FileHistory{}, false
+		}
+		rh = &repoHistory{head: head, files: parseGitLog(out, time.Now().Add(-churnWindow))}
+		h.repos[repoPath] = rh
+	}
+
+	fh, ok := rh.files[file]
+	return fh, ok
+}
+
+// lineTimes returns the committer time of each line of file (index 0 is line 1).
+func (h *historyCache) lineTimes(ctx context.Context, repoPath, file, contentHash string) []int64 {
+	key := repoPath + "\x00" + file + "\x00" + contentHash
+
+	h.mu.Lock()
+	times, ok := h.blame[key]
+	h.mu.Unlock()
+	if ok {
+		return times
+	}
+
+	out, err := runGit(ctx, repoPath, "blame", "--porcelain", "--", file)
+	if err != nil {
+		return nil
+	}
+	times = parseBlamePorcelain(out)
+
+	h.mu.Lock()
+	if len(h.blame) >= maxBlameCacheEntries {
+		h.blame = make(map[string][]int64)
+	}
+	h.blame[key] = times
+	h.mu.Unlock()
+	return times
+}
+
+// parseGitLog parses `git log --format=format:%x1e%ct --name-only` output.
+// Commits newer than since count towards churn.
+func parseGitLog(out string, since time.Time) map[string]FileHistory {
+	files := make(map[string]FileHistory)
+	for _, record := range strings.Split(out, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		if len(lines) < 2 {
+			continue
+		}
+		ts, err := strconv.ParseInt(strings.TrimSpace(lines[0]), 10, 64)
+		if err != nil {
+			continue
+		}
+		committed := time.Unix(ts, 0)
+		for _, name := range lines[1:] {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			fh := files[name]
+			if committed.After(fh.LastModified) {
+				fh.LastModified = committed
+			}
+			if committed.After(since) {
+				fh.Churn++
+			}
+			files[name] = fh
+		}
+	}
+	return files
+}
+
+// parseBlamePorcelain parses `git blame --porcelain` output into per-line
+// committer timestamps. Commit headers are only emitted on first use of a
+// commit, so timestamps are remembered by SHA.
+func parseBlamePorcelain(out string) []int64 {
+	commitTimes := make(map[string]int64)
+	var times []int64
+	var current string
+
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			times = append(times, commitTimes[current])
+		case strings.HasPrefix(line, "committer-time "):
+			if ts, err := strconv.ParseInt(strings.TrimPrefix(line, "committer-time "), 10, 64); err == nil {
+				commitTimes[current] = ts
+			}
+		default:
+			if fields := strings.Fields(line); len(fields) >= 3 && len(fields[0]) == 40 {
+				current = fields[0]
+			}
+		}
+	}
+	return times
+}
+
+// applyHistory attaches last_modified (unix seconds), churn, and hotspot
+// metadata to a chunk. When blame data covers the chunk's line range the
+// newest line wins; otherwise the file-level date is used.
+func applyHistory(doc *schema.Document, fh FileHistory, lineTimes []int64) {
+	lastModified := fh.LastModified.Unix()
+	start, _ := doc.Metadata["line"].(int)
+	end, _ := doc.Metadata["end_line"].(int)
+	if start > 0 && end >= start && end <= len(lineTimes) {
+		var newest int64
+		for _, ts := range lineTimes[start-1 : end] {
+			newest = max(newest, ts)
+		}
+		if newest > 0 {
+			lastModified = newest
+		}
+	}
+
+	doc.Metadata["last_modified"] = lastModified
+	doc.Metadata["churn"] = fh.Churn
+	if fh.Churn >= HotspotChurnThreshold {
+		doc.Metadata["hotspot"] = true
+	}
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %s: %w", args[0], strings.TrimSpace(stderr.String()), err)
+	}
+	return string(out), nil
+}
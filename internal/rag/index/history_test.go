@@ -0,0 +1,72 @@
+package index
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/sevigo/goframe/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseGitLog(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-24 * time.Hour).Unix()
+	old := now.Add(-2 * churnWindow).Unix()
+
+	out := "\x1e" + itoa(recent) + "\n\nmain.go\nutil.go\n" +
+		"\x1e" + itoa(recent-60) + "\n\nmain.go\n" +
+		"\x1e" + itoa(old) + "\n\nutil.go\nlegacy.go\n"
+
+	files := parseGitLog(out, now.Add(-churnWindow))
+
+	assert.Equal(t, 2, files["main.go"].Churn)
+	assert.Equal(t, recent, files["main.go"].LastModified.Unix())
+	assert.Equal(t, 1, files["util.go"].Churn)
+	assert.Equal(t, recent, files["util.go"].LastModified.Unix())
+	assert.Equal(t, 0, files["legacy.go"].Churn)
+	assert.Equal(t, old, files["legacy.go"].LastModified.Unix())
+}
+
+func TestParseBlamePorcelain(t *testing.T) {
+	shaA := "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	shaB := "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+	out := shaA + " 1 1 2\n" +
+		"author A\n" +
+		"committer-time 100\n" +
+		"filename main.go\n" +
+		"\tpackage main\n" +
+		shaA + " 2 2\n" +
+		"\t\n" +
+		shaB + " 3 3 1\n" +
+		"committer-time 200\n" +
+		"filename main.go\n" +
+		"\tfunc main() {}\n"
+
+	assert.Equal(t, []int64{100, 100, 200}, parseBlamePorcelain(out))
+}
+
+func TestApplyHistory(t *testing.T) {
+	fileDate := time.Unix(50, 0)
+
+	t.Run("uses newest blamed line in chunk range", func(t *testing.T) {
+		doc := schema.NewDocument("x", map[string]any{"line": 2, "end_line": 3})
+		applyHistory(&doc, FileHistory{LastModified: fileDate, Churn: 3}, []int64{100, 300, 200, 400})
+
+		assert.Equal(t, int64(300), doc.Metadata["last_modified"])
+		assert.Equal(t, 3, doc.Metadata["churn"])
+		assert.NotContains(t, doc.Metadata, "hotspot")
+	})
+
+	t.Run("falls back to file date and flags hotspots", func(t *testing.T) {
+		doc := schema.NewDocument("x", map[string]any{})
+		applyHistory(&doc, FileHistory{LastModified: fileDate, Churn: HotspotChurnThreshold}, nil)
+
+		assert.Equal(t, int64(50), doc.Metadata["last_modified"])
+		assert.Equal(t, true, doc.Metadata["hotspot"])
+	})
+}
+
+func itoa(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -18,6 +19,7 @@ import (
 	"github.com/sevigo/goframe/parsers"
 	"github.com/sevigo/goframe/schema"
 	"github.com/sevigo/goframe/textsplitter"
+	"golang.org/x/sync/errgroup"
 
 	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/cryptoutil"
@@ -35,22 +37,44 @@ type Config struct {
 	EmbedderModel  string
 	LLM            llms.Model
 	PromptMgr      *llm.PromptManager
+	// Workers is how many files UpdateRepoContext reads and chunks in
+	// parallel (default 4).
+	Workers int
+	// MaxFileSizeKB and MaxFiles are the server's indexing limits, which the
+	// indexing settings of a repository can lower but not raise. Zero means
+	// no server limit.
+	MaxFileSizeKB int
+	MaxFiles      int
 }
 
+// defaultWorkers is the parallelism of UpdateRepoContext when Config.Workers
+// is not set.
+const defaultWorkers = 4
+
 // Indexer handles document ingestion and semantic chunking.
 type Indexer struct {
-	cfg Config
+	cfg     Config
+	history *historyCache
 }
 
 // New creates a new [Indexer] instance.
 func New(cfg Config) *Indexer {
-	return &Indexer{cfg: cfg}
+	return &Indexer{cfg: cfg, history: newHistoryCache()}
+}
+
+// WithEmbedder returns an Indexer that embeds with model instead of the
+// configured embedder model, sharing the caches of i.
+func (i *Indexer) WithEmbedder(model string) *Indexer {
+	cfg := i.cfg
+	cfg.EmbedderModel = model
+	return &Indexer{cfg: cfg, history: i.history}
 }
 
 // ProgressFunc is called periodically during indexing with the number of
-// files processed so far and the total discovered so far (total grows as
-// the file stream is consumed, so it may increase over time).
-// Implementations must be safe to call from multiple goroutines.
+// files done so far and the total. A file is done once it is skipped or its
+// chunks are embedded and stored, so progress advances with each stored
+// batch of chunks. Implementations must be safe to call from multiple
+// goroutines.
 type ProgressFunc func(done, total int)
 
 // SetupRepoContext indexes a repository for the first time or re-indexes
@@ -63,7 +87,8 @@ type ProgressFunc func(done, total int)
 //
 //nolint:cyclop,gocyclo,gocognit,funlen // orchestrates complex smart-scan workflow
 func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, repoPath string, progressFn ProgressFunc) error {
-	i.cfg.Logger.Info("performing smart indexing with GoFrame GitLoader",
+	ctx = core.WithUsageStep(core.WithUsageRepo(ctx, repo.FullName), core.UsageStepSummary)
+	i.cfg.Logger.InfoContext(ctx, "performing smart indexing with GoFrame GitLoader",
 		"path", repoPath,
 		"collection", repo.QdrantCollectionName,
 	)
@@ -76,6 +101,9 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 
 	// Count total files upfront for accurate progress reporting (apply same filters as loader)
 	totalFiles := 0
+	// stats is only touched by this walk and, later, the result collector.
+	stats := core.NewIndexStatsBuilder()
+	candidates := make(map[string]struct{}) // files expected from the loader
 	if walkErr := filepath.WalkDir(repoPath, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
@@ -93,27 +121,35 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 		if strings.HasPrefix(d.Name(), ".") {
 			return nil
 		}
+		rel, relErr := filepath.Rel(repoPath, path)
+		if relErr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
 		// Skip excluded extensions and invalid extensions
 		ext := strings.ToLower(filepath.Ext(path))
 		if !core.IsValidExtension(ext) {
+			stats.Skipped(rel, core.SkipUnsupportedExtension)
 			return nil
 		}
 		for _, excludeExt := range repoConfig.ExcludeExts {
 			if strings.TrimPrefix(strings.ToLower(excludeExt), ".") == strings.TrimPrefix(ext, ".") {
+				stats.Skipped(rel, core.SkipExcludedExtension)
 				return nil
 			}
 		}
+		candidates[rel] = struct{}{}
 		totalFiles++
 		return nil
 	}); walkErr != nil {
-		i.cfg.Logger.Warn("failed to count files for progress", "error", walkErr)
+		i.cfg.Logger.WarnContext(ctx, "failed to count files for progress", "error", walkErr)
 	}
-	i.cfg.Logger.Info("counted files for indexing", "total", totalFiles)
+	i.cfg.Logger.InfoContext(ctx, "counted files for indexing", "total", totalFiles)
 
 	// Smart Scan: Fetch existing file states for fast skipping
 	existingFiles, err := i.cfg.Store.GetFilesForRepo(ctx, repo.ID)
 	if err != nil {
-		i.cfg.Logger.Warn("failed to fetch existing file states", "error", err)
+		i.cfg.Logger.WarnContext(ctx, "failed to fetch existing file states", "error", err)
 		existingFiles = make(map[string]storage.FileRecord)
 	}
 
@@ -131,7 +167,7 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 		documentloaders.WithExcludeDirs(finalExcludeDirs),
 		documentloaders.WithExcludeExts(repoConfig.ExcludeExts),
 		documentloaders.WithWorkerCount(4),
-		documentloaders.WithGeneratedCodeDetection(true),
+		documentloaders.WithGeneratedCodeDetection(!repoConfig.Indexing.IncludeGenerated),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to initialize git loader: %w", err)
@@ -142,6 +178,12 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 	var skippedCount int64   // atomic counter for progress
 	var totalSeen int64      // atomically incremented as files are discovered
 
+	// The loader only recognises generated files its parsers know about; the
+	// filter adds name patterns, binary sniffing and the size cap.
+	filter := newFileFilter(i.indexing(repoConfig), repoConfig, i.ignorePatterns(ctx, repoPath, repoConfig))
+	var filteredTracked []string // filtered files that were indexed before
+	var filteredTrackedMu sync.Mutex
+
 	// Keep track of all files processed by the loader to identify deletions later
 	filesProcessedByLoader := make(map[string]struct{})
 	var filesProcessedByLoaderMu sync.Mutex
@@ -162,6 +204,8 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 		processed    bool
 		skipped      bool
 		filePath     string // for progress reporting
+		skipReason   skipReason
+		chunks       int // chunks of the file in the index
 	}
 
 	// Use larger buffer to prevent pipeline deadlock
@@ -186,6 +230,16 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 					if !ok {
 						return
 					}
+					if reason := filter.skip(work.filePath, work.file); reason != skipNone {
+						if _, exists := existingFilesCopy[work.file]; exists {
+							filteredTrackedMu.Lock()
+							filteredTracked = append(filteredTracked, work.file)
+							filteredTrackedMu.Unlock()
+						}
+						resultChan <- fileResult{processed: true, skipped: true, filePath: work.file, skipReason: reason}
+						continue
+					}
+
 					// Compute hash
 					var hash string
 					var hashErr error
@@ -193,19 +247,14 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 						hash, hashErr = ComputeFileHash(work.filePath)
 					}
 					if hashErr != nil {
-						i.cfg.Logger.Warn("hash failed, will re-process", "file", work.file, "error", hashErr)
+						i.cfg.Logger.WarnContext(ctx, "hash failed, will re-process", "file", work.file, "error", hashErr)
 					}
 
 					// Skip unchanged files
 					if hash != "" {
 						if rec, exists := existingFilesCopy[work.file]; exists && rec.FileHash == hash {
-							// Report progress for skipped file
-							if progressFn != nil {
-								done := int(atomic.LoadInt64(&processedCount) + atomic.LoadInt64(&skippedCount) + 1)
-								progressFn(done, totalFiles)
-							}
 							atomic.AddInt64(&skippedCount, 1)
-							resultChan <- fileResult{processed: true, skipped: true, filePath: work.file}
+							resultChan <- fileResult{processed: true, skipped: true, filePath: work.file, chunks: rec.ChunkCount}
 							continue
 						}
 					}
@@ -221,10 +270,11 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 							RepositoryID: repo.ID,
 							FilePath:     work.file,
 							FileHash:     hash,
+							ChunkCount:   len(docs),
 						}
 					}
 
-					resultChan <- fileResult{docsToInsert: docs, fileToUpdate: fileRec, processed: true, filePath: work.file}
+					resultChan <- fileResult{docsToInsert: docs, fileToUpdate: fileRec, processed: true, filePath: work.file, chunks: len(docs)}
 					atomic.AddInt64(&processedCount, 1)
 				}
 			}
@@ -236,11 +286,57 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 
 	const progressInterval = 10 // report every N files to avoid excessive DB writes
 
+	// Progress counts a file as done once it is skipped or its chunks are
+	// stored, as embedding takes far longer than chunking; a large initial
+	// index otherwise reports all files done long before it ends.
+	var (
+		settledFiles int // skipped files and files whose chunks are stored
+		pendingFiles int // files whose chunks wait in batchDocs
+		batches      int
+		reported     int
+	)
+	// The walk does not count every file the loader delivers, like hidden
+	// ones, so reports are capped at totalFiles.
+	reportProgress := func(force bool) {
+		done := min(settledFiles, totalFiles)
+		if progressFn != nil && done != reported && (force || done-reported >= progressInterval) {
+			reported = done
+			progressFn(done, totalFiles)
+		}
+	}
+	flushBatch := func() {
+		batches++
+		if err := i.storeBatch(ctx, scopedStore, batches, batchDocs); err != nil {
+			i.cfg.Logger.ErrorContext(ctx, "failed to add vectors in batch", "batch", batches, "error", err)
+		} else {
+			if err := i.cfg.Store.UpsertFiles(ctx, repo.ID, batchFiles); err != nil {
+				i.cfg.Logger.ErrorContext(ctx, "failed to update file state in DB", "error", err)
+			}
+			i.saveSymbols(ctx, repo.ID, batchFiles, batchDocs)
+		}
+		// Clear batches but keep capacity
+		batchDocs = batchDocs[:0]
+		batchFiles = batchFiles[:0]
+		settledFiles += pendingFiles
+		pendingFiles = 0
+		reportProgress(true)
+	}
+
 	collectorDone := make(chan struct{})
 	go func() {
 		defer close(collectorDone)
 		for res := range resultChan {
 			resultsMu.Lock()
+			if res.skipReason != skipNone {
+				stats.Skipped(res.filePath, string(res.skipReason))
+			} else {
+				stats.Indexed(res.filePath, res.chunks)
+			}
+			if res.skipped {
+				settledFiles++
+			} else {
+				pendingFiles++
+			}
 			// Accumulate for batch insert
 			batchDocs = append(batchDocs, res.docsToInsert...)
 			if res.fileToUpdate.FilePath != "" {
@@ -249,25 +345,10 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 
 			// Flush batch when full
 			if len(batchDocs) >= batchSize {
-				if _, err := scopedStore.AddDocuments(ctx, batchDocs); err != nil {
-					i.cfg.Logger.Error("failed to add vectors in batch", "error", err)
-				} else {
-					if err := i.cfg.Store.UpsertFiles(ctx, repo.ID, batchFiles); err != nil {
-						i.cfg.Logger.Error("failed to update file state in DB", "error", err)
-					}
-				}
-				// Clear batches but keep capacity
-				batchDocs = batchDocs[:0]
-				batchFiles = batchFiles[:0]
+				flushBatch()
 			}
-
-			done := int(atomic.LoadInt64(&processedCount) + atomic.LoadInt64(&skippedCount))
+			reportProgress(false)
 			resultsMu.Unlock()
-
-			// Report progress periodically using the pre-calculated totalFiles
-			if progressFn != nil && done%progressInterval == 0 {
-				progressFn(done, totalFiles)
-			}
 		}
 	}()
 
@@ -312,19 +393,18 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 
 	// Flush remaining batch (no mutex needed - collector goroutine has finished)
 	if len(batchDocs) > 0 {
-		if _, err := scopedStore.AddDocuments(ctx, batchDocs); err != nil {
-			i.cfg.Logger.Error("failed to add vectors in final batch", "error", err)
-		} else {
-			if err := i.cfg.Store.UpsertFiles(ctx, repo.ID, batchFiles); err != nil {
-				i.cfg.Logger.Error("failed to update file state in final DB batch", "error", err)
-			}
-		}
+		flushBatch()
 	}
+	// Files the walk counted but the loader dropped are settled too.
+	settledFiles = totalFiles
+	reportProgress(true)
 
 	// Cleanup: Delete records for files that are genuinely absent from disk AND were not processed by loader.
 	// We check the filesystem directly rather than relying on filesProcessedByLoader alone,
 	// but we respect filesProcessedByLoader as "exists" to avoid unnecessary stat calls.
-	var pathsToDelete []string
+	// Files indexed before but skipped now, e.g. after a generator started
+	// writing them, are pruned like deleted ones.
+	pathsToDelete := filteredTracked
 	for path := range existingFiles {
 		// Optimization: If loader processed it, it definitely exists.
 		if _, seen := filesProcessedByLoader[path]; seen {
@@ -338,9 +418,9 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 	}
 
 	if len(pathsToDelete) > 0 {
-		i.cfg.Logger.Info("pruning deleted files from tracking", "count", len(pathsToDelete))
+		i.cfg.Logger.InfoContext(ctx, "pruning deleted files from tracking", "count", len(pathsToDelete))
 		if err := i.cfg.Store.DeleteFiles(ctx, repo.ID, pathsToDelete); err != nil {
-			i.cfg.Logger.Warn("failed to delete stale file records", "error", err)
+			i.cfg.Logger.WarnContext(ctx, "failed to delete stale file records", "error", err)
 		}
 		// Also remove from Qdrant?
 		// We assume Qdrant clean up is handled via re-indexing or manual pruned?
@@ -348,16 +428,29 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 		// Deleting from Qdrant requires `DeleteDocumentsByFilter` ("source" in pathsToDelete).
 		if len(pathsToDelete) > 0 && repo.QdrantCollectionName != "" {
 			if err := i.cfg.VectorStore.DeleteDocumentsFromCollectionByFilter(ctx, repo.QdrantCollectionName, i.cfg.EmbedderModel, map[string]any{"source": map[string]any{"$in": pathsToDelete}}); err != nil {
-				i.cfg.Logger.Warn("failed to delete vectors for removed files", "error", err)
+				i.cfg.Logger.WarnContext(ctx, "failed to delete vectors for removed files", "error", err)
 			}
 		}
 	}
 
-	i.cfg.Logger.Info("repository setup complete",
+	// Files the walk expected but the loader never delivered were dropped by
+	// the loader itself.
+	for file := range candidates {
+		if _, seen := filesProcessedByLoader[file]; !seen {
+			stats.Skipped(file, core.SkipNotLoaded)
+		}
+	}
+	indexStats := stats.Build(time.Since(startTime))
+	if err := i.cfg.Store.SaveIndexStats(ctx, repo.ID, indexStats); err != nil {
+		i.cfg.Logger.WarnContext(ctx, "failed to save index stats", "error", err)
+	}
+
+	i.warnFileLimit(repo.FullName, filter)
+	i.cfg.Logger.InfoContext(ctx, "repository setup complete", append([]any{
 		"indexed_files", processedCount,
 		"skipped_files", skippedCount,
 		"duration", time.Since(startTime).Round(time.Second),
-	)
+	}, filter.logArgs()...)...)
 
 	return nil
 }
@@ -366,6 +459,7 @@ func (i *Indexer) SetupRepoContext(ctx context.Context, repoConfig *core.RepoCon
 //
 //nolint:gocognit,nestif,funlen // incremental sync has inherently complex control flow
 func (i *Indexer) UpdateRepoContext(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, repoPath string, filesToProcess, filesToDelete []string, progressFn ProgressFunc) error {
+	ctx = core.WithUsageStep(core.WithUsageRepo(ctx, repo.FullName), core.UsageStepSummary)
 	if repoConfig == nil {
 		repoConfig = core.DefaultRepoConfig()
 	}
@@ -387,20 +481,46 @@ func (i *Indexer) UpdateRepoContext(ctx context.Context, repoConfig *core.RepoCo
 	filesToProcess = FilterFilesBySpecificFiles(filesToProcess, repoConfig.ExcludeFiles)
 	filesToDelete = FilterFilesBySpecificFiles(filesToDelete, repoConfig.ExcludeFiles)
 
-	i.cfg.Logger.Info("updating repository context after filtering",
+	// A changed file that is now generated, binary or too large may still
+	// have chunks from an earlier version, so it is deleted instead.
+	filter := newFileFilter(i.indexing(repoConfig), repoConfig, i.ignorePatterns(ctx, repoPath, repoConfig))
+	if filter.maxFiles > 0 {
+		tracked, err := i.cfg.Store.GetFilesForRepo(ctx, repo.ID)
+		if err != nil {
+			i.cfg.Logger.WarnContext(ctx, "failed to fetch indexed files, not enforcing max_files", "error", err)
+			filter.maxFiles = 0
+		} else {
+			filter.countTracked(tracked, filesToDelete)
+		}
+	}
+	kept := make([]string, 0, len(filesToProcess))
+	for _, f := range filesToProcess {
+		if filter.skip(filepath.Join(repoPath, f), f) != skipNone {
+			filesToDelete = append(filesToDelete, f)
+			continue
+		}
+		kept = append(kept, f)
+	}
+	filesToProcess = kept
+	i.warnFileLimit(repo.FullName, filter)
+
+	i.cfg.Logger.InfoContext(ctx, "updating repository context after filtering", append([]any{
 		"collection", repo.QdrantCollectionName,
 		"process", len(filesToProcess),
 		"delete", len(filesToDelete),
-	)
+	}, filter.logArgs()...)...)
 
 	totalItems := len(filesToProcess) + len(filesToDelete)
 	processedItems := 0
 
 	// Handle deleted files first
 	if len(filesToDelete) > 0 {
-		i.cfg.Logger.Info("deleting embeddings for removed files", "count", len(filesToDelete))
+		i.cfg.Logger.InfoContext(ctx, "deleting embeddings for removed files", "count", len(filesToDelete))
 		if err := i.cfg.VectorStore.DeleteDocumentsFromCollection(ctx, repo.QdrantCollectionName, i.cfg.EmbedderModel, filesToDelete); err != nil {
-			i.cfg.Logger.Error("failed to delete some embeddings", "error", err)
+			i.cfg.Logger.ErrorContext(ctx, "failed to delete some embeddings", "error", err)
+		}
+		if err := i.cfg.Store.ReplaceSymbols(ctx, repo.ID, filesToDelete, nil, nil); err != nil {
+			i.cfg.Logger.WarnContext(ctx, "failed to remove symbols of removed files", "error", err)
 		}
 		processedItems += len(filesToDelete)
 		if progressFn != nil {
@@ -413,113 +533,212 @@ func (i *Indexer) UpdateRepoContext(ctx context.Context, repoConfig *core.RepoCo
 		return nil
 	}
 
-	// Process files in parallel using a worker pool
+	scopedStore := i.cfg.VectorStore.ForRepo(repo.QdrantCollectionName, i.cfg.EmbedderModel)
+
+	// Files are read, parsed and chunked by a bounded pool of workers while
+	// the chunks stream into AddDocuments batches. Chunks that are stored
+	// unchanged are not embedded again; see diffChunks.
 	type fileResult struct {
-		docs []schema.Document
+		file  string
+		docs  []schema.Document
+		embed []schema.Document
+		// reused counts the chunks that are stored unchanged.
+		reused int
 	}
 
-	const numWorkers = 4
-	fileChan := make(chan string, len(filesToProcess))
-	resultChan := make(chan fileResult, len(filesToProcess))
+	workers := i.cfg.Workers
+	if workers < 1 {
+		workers = defaultWorkers
+	}
+	fileChan := make(chan string)
+	resultChan := make(chan fileResult, workers*2)
 
-	var wg sync.WaitGroup
-	for range numWorkers {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		defer close(fileChan)
+		for _, f := range filesToProcess {
+			select {
+			case fileChan <- f:
+			case <-gCtx.Done():
+				return gCtx.Err()
+			}
+		}
+		return nil
+	})
+	var workersWG sync.WaitGroup
+	for range workers {
+		workersWG.Add(1)
+		g.Go(func() error {
+			defer workersWG.Done()
 			for f := range fileChan {
-				docs := i.ProcessFile(ctx, repoPath, f)
-				resultChan <- fileResult{docs: docs}
+				docs := i.ProcessFile(gCtx, repoPath, f)
+				diff := i.diffChunks(gCtx, scopedStore, f, docs)
+				if err := i.removeStoredChunks(gCtx, scopedStore, f, docs, diff); err != nil {
+					i.cfg.Logger.WarnContext(ctx, "failed to remove outdated chunks", "file", f, "error", err)
+				}
+				select {
+				case resultChan <- fileResult{file: f, docs: docs, embed: diff.embed, reused: diff.reused}:
+				case <-gCtx.Done():
+					return gCtx.Err()
+				}
 			}
-		}()
-	}
-
-	for _, f := range filesToProcess {
-		fileChan <- f
+			return nil
+		})
 	}
-	close(fileChan)
-
 	go func() {
-		wg.Wait()
+		workersWG.Wait()
 		close(resultChan)
 	}()
 
-	// avgChunksPerFile is based on observed average file sizes and chunking strategy (~4 chunks/file).
-	// Adjust if profiling reveals consistent over/under-allocation.
-	const avgChunksPerFile = 4
-
-	// Pre-allocate with an estimated capacity to reduce GC pressure during indexing.
-	allDocs := make([]schema.Document, 0, len(filesToProcess)*avgChunksPerFile)
+	const batchSize = 500
+	startTime := time.Now()
+	var (
+		allDocs         []schema.Document
+		batch           []schema.Document
+		successfulFiles = make(map[string]int) // source -> stored chunks
+		failedFiles     = make(map[string]bool)
+		// pending counts the chunks of each file that are not stored yet;
+		// progress counts a file as done once its last chunk is stored, as
+		// embedding takes far longer than chunking.
+		pending        = make(map[string]int)
+		doneFiles      int
+		embeddedChunks int
+		reusedChunks   int
+		batchFailures  int
+		batches        int
+		reported       = -1
+	)
+	reportProgress := func() {
+		if progressFn != nil && processedItems+doneFiles != reported {
+			reported = processedItems + doneFiles
+			progressFn(reported, totalItems)
+		}
+	}
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		batches++
+		err := i.storeBatch(ctx, scopedStore, batches, batch)
+		if err != nil {
+			i.cfg.Logger.ErrorContext(ctx, "failed to add documents in batch", "error", err, "batch_start", embeddedChunks)
+			batchFailures++
+		}
+		for _, doc := range batch {
+			source, ok := doc.Metadata["source"].(string)
+			if !ok {
+				continue
+			}
+			if err != nil {
+				failedFiles[source] = true
+			} else {
+				successfulFiles[source]++
+			}
+			if pending[source]--; pending[source] == 0 {
+				delete(pending, source)
+				doneFiles++
+			}
+		}
+		embeddedChunks += len(batch)
+		batch = batch[:0]
+		reportProgress()
+	}
 	for res := range resultChan {
 		allDocs = append(allDocs, res.docs...)
-		processedItems++
-		if progressFn != nil && (processedItems%10 == 0 || processedItems == totalItems) {
-			progressFn(processedItems, totalItems)
+		if len(res.docs) > 0 {
+			successfulFiles[res.file] = res.reused
+		}
+		reusedChunks += res.reused
+		if len(res.embed) == 0 {
+			doneFiles++
+			continue
+		}
+		pending[res.file] += len(res.embed)
+		for _, doc := range res.embed {
+			batch = append(batch, doc)
+			if len(batch) >= batchSize {
+				flush()
+			}
 		}
 	}
+	flush()
+	if err := g.Wait(); err != nil {
+		return fmt.Errorf("failed to process changed files: %w", err)
+	}
+	reportProgress()
 
-	if len(allDocs) > 0 {
-		i.cfg.Logger.Info("adding/updating documents in vector store", "count", len(allDocs))
-		scopedStore := i.cfg.VectorStore.ForRepo(repo.QdrantCollectionName, i.cfg.EmbedderModel)
-
-		successfulFiles := make(map[string]bool)
-		batchFailures := 0
+	// A file with chunks that failed to store is not recorded, so the next
+	// scan embeds it again.
+	for f := range failedFiles {
+		delete(successfulFiles, f)
+	}
 
-		const batchSize = 500
-		for startIndex := 0; startIndex < len(allDocs); startIndex += batchSize {
-			endIndex := startIndex + batchSize
-			if endIndex > len(allDocs) {
-				endIndex = len(allDocs)
-			}
+	elapsed := time.Since(startTime)
+	i.cfg.Logger.InfoContext(ctx, "vector insertion complete",
+		"files", len(filesToProcess),
+		"chunks", len(allDocs),
+		"total_docs", embeddedChunks,
+		"unchanged_docs", reusedChunks,
+		"successful_files", len(successfulFiles),
+		"batch_failures", batchFailures,
+		"workers", workers,
+		"duration", elapsed.Round(time.Millisecond),
+		"files_per_sec", perSecond(len(filesToProcess), elapsed),
+		"chunks_per_sec", perSecond(len(allDocs), elapsed),
+	)
 
-			batch := allDocs[startIndex:endIndex]
-			if _, err := scopedStore.AddDocuments(ctx, batch); err != nil {
-				i.cfg.Logger.Error("failed to add documents in batch", "error", err, "batch_start", startIndex)
-				batchFailures++
+	if len(successfulFiles) > 0 {
+		var fileRecords []storage.FileRecord
+		for f := range successfulFiles {
+			fullPath := filepath.Join(repoPath, f)
+			hash, err := ComputeFileHash(fullPath)
+			if err != nil {
+				i.cfg.Logger.WarnContext(ctx, "failed to hash file for tracking", "file", f, "error", err)
 				continue
 			}
-
-			for _, doc := range batch {
-				if source, ok := doc.Metadata["source"].(string); ok {
-					successfulFiles[source] = true
-				}
-			}
+			fileRecords = append(fileRecords, storage.FileRecord{
+				RepositoryID: repo.ID,
+				FilePath:     f,
+				FileHash:     hash,
+				ChunkCount:   successfulFiles[f],
+			})
 		}
 
-		i.cfg.Logger.Info("vector insertion complete",
-			"total_docs", len(allDocs),
-			"successful_files", len(successfulFiles),
-			"batch_failures", batchFailures,
-		)
-
-		if len(successfulFiles) > 0 {
-			var fileRecords []storage.FileRecord
-			for f := range successfulFiles {
-				fullPath := filepath.Join(repoPath, f)
-				hash, err := ComputeFileHash(fullPath)
-				if err != nil {
-					i.cfg.Logger.Warn("failed to hash file for tracking", "file", f, "error", err)
-					continue
-				}
-				fileRecords = append(fileRecords, storage.FileRecord{
-					RepositoryID: repo.ID,
-					FilePath:     f,
-					FileHash:     hash,
-				})
-			}
-
-			if len(fileRecords) > 0 {
-				if err := i.cfg.Store.UpsertFiles(ctx, repo.ID, fileRecords); err != nil {
-					i.cfg.Logger.Error("failed to update file hashes in DB - vectors may be re-indexed on next scan",
-						"error", err, "file_count", len(fileRecords))
-				}
+		if len(fileRecords) > 0 {
+			if err := i.cfg.Store.UpsertFiles(ctx, repo.ID, fileRecords); err != nil {
+				i.cfg.Logger.ErrorContext(ctx, "failed to update file hashes in DB - vectors may be re-indexed on next scan",
+					"error", err, "file_count", len(fileRecords))
 			}
+			i.saveSymbols(ctx, repo.ID, fileRecords, allDocs)
 		}
 	}
 
 	return nil
 }
 
+// perSecond returns the rate of n events in elapsed, rounded to one decimal.
+func perSecond(n int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return math.Round(float64(n)/elapsed.Seconds()*10) / 10
+}
+
+// storeBatch embeds and stores one batch of chunks. It logs how long the
+// batch took: embedding dominates the time of a large index and is
+// otherwise silent until the index is complete.
+func (i *Indexer) storeBatch(ctx context.Context, store storage.ScopedVectorStore, batch int, docs []schema.Document) error {
+	return storage.AddDocumentsWithProgress(ctx, store, docs, func(processed, total int, elapsed time.Duration) {
+		i.cfg.Logger.InfoContext(ctx, "stored batch of chunks",
+			"batch", batch,
+			"chunks", processed,
+			"of", total,
+			"duration", elapsed.Round(time.Millisecond),
+			"chunks_per_sec", perSecond(processed, elapsed),
+		)
+	})
+}
+
 // ProcessFile reads, parses, and chunks a single file for indexing.
 // Returns code chunks and definition chunks.
 // Chunks are enriched with a file-level summary for better semantic retrieval.
@@ -531,7 +750,7 @@ func (i *Indexer) ProcessFile(ctx context.Context, repoPath, file string) []sche
 	// Read file for chunking
 	contentBytes, err := os.ReadFile(fullPath)
 	if err != nil {
-		i.cfg.Logger.Error("failed to read file for processing", "file", file, "error", err)
+		i.cfg.Logger.ErrorContext(ctx, "failed to read file for processing", "file", file, "error", err)
 		return nil
 	}
 
@@ -555,7 +774,7 @@ func (i *Indexer) ProcessFile(ctx context.Context, repoPath, file string) []sche
 
 	splitDocs, err := i.cfg.Splitter.SplitDocuments(ctx, []schema.Document{doc})
 	if err != nil {
-		i.cfg.Logger.Error("failed to split document with code-aware splitter", "file", file, "error", err)
+		i.cfg.Logger.ErrorContext(ctx, "failed to split document with code-aware splitter", "file", file, "error", err)
 		return nil
 	}
 
@@ -571,12 +790,23 @@ func (i *Indexer) ProcessFile(ctx context.Context, repoPath, file string) []sche
 	// Build line offset map for computing line numbers
 	lineOffsets := buildLineOffsets(validContent)
 
+	// Git history (last-modified + churn) lets retrieval and reviews spot hotspots.
+	var fileHist FileHistory
+	var hasHistory bool
+	var lineTimes []int64
+	if !isDocsFile {
+		fileHist, hasHistory = i.history.fileHistory(ctx, repoPath, file)
+		if hasHistory {
+			lineTimes = i.history.lineTimes(ctx, repoPath, file, hashContent(validContent))
+		}
+	}
+
 	// Filter boilerplate chunks (import blocks, package-only lines, etc.) before
 	// processing so they don't occupy vector-store slots or dilute search results.
 	filtered := splitDocs[:0]
 	for _, chunk := range splitDocs {
 		if isLikelyBoilerplate(chunk.PageContent) {
-			i.cfg.Logger.Debug("skipping boilerplate chunk", "file", file)
+			i.cfg.Logger.DebugContext(ctx, "skipping boilerplate chunk", "file", file)
 			continue
 		}
 		filtered = append(filtered, chunk)
@@ -597,9 +827,9 @@ func (i *Indexer) ProcessFile(ctx context.Context, repoPath, file string) []sche
 		sparseVec, err := sparse.GenerateSparseVector(ctx, splitDocs[idx].PageContent)
 		if err == nil {
 			splitDocs[idx].Sparse = sparseVec
-			i.cfg.Logger.Debug("sparse vector generated for chunk", "file", file, "chunk", idx, "sparse_indices", len(sparseVec.Indices))
+			i.cfg.Logger.DebugContext(ctx, "sparse vector generated for chunk", "file", file, "chunk", idx, "sparse_indices", len(sparseVec.Indices))
 		} else {
-			i.cfg.Logger.Debug("sparse vector generation failed for chunk, using dense only", "file", file, "chunk", idx, "error", err)
+			i.cfg.Logger.DebugContext(ctx, "sparse vector generation failed for chunk, using dense only", "file", file, "chunk", idx, "error", err)
 		}
 
 		// Set chunk_type and metadata based on file type
@@ -617,6 +847,9 @@ func (i *Indexer) ProcessFile(ctx context.Context, repoPath, file string) []sche
 			splitDocs[idx].Metadata["line"] = line
 			splitDocs[idx].Metadata["end_line"] = endLine
 		}
+		if hasHistory {
+			applyHistory(&splitDocs[idx], fileHist, lineTimes)
+		}
 
 		// Extract symbols from chunk.
 		// Prefer the parser's AST-aware ExtractUsedSymbols which understands
@@ -633,7 +866,7 @@ func (i *Indexer) ProcessFile(ctx context.Context, repoPath, file string) []sche
 		if len(symbols) == 0 {
 			symbols = extractSymbolsFromChunk(splitDocs[idx].PageContent, ext)
 		}
-		i.cfg.Logger.Debug("symbol extraction complete", "file", file, "chunk", idx, "symbols", len(symbols), "parser", usedParser)
+		i.cfg.Logger.DebugContext(ctx, "symbol extraction complete", "file", file, "chunk", idx, "symbols", len(symbols), "parser", usedParser)
 		if len(symbols) > 0 {
 			splitDocs[idx].Metadata["symbols"] = symbols
 			// Primary symbol is the first exported one
@@ -681,9 +914,9 @@ func (i *Indexer) ProcessFile(ctx context.Context, repoPath, file string) []sche
 			sparseVec, err := sparse.GenerateSparseVector(ctx, defDocs[idx].PageContent)
 			if err == nil {
 				defDocs[idx].Sparse = sparseVec
-				i.cfg.Logger.Debug("sparse vector generated for definition", "file", file, "definition", idx, "sparse_indices", len(sparseVec.Indices))
+				i.cfg.Logger.DebugContext(ctx, "sparse vector generated for definition", "file", file, "definition", idx, "sparse_indices", len(sparseVec.Indices))
 			} else {
-				i.cfg.Logger.Debug("sparse vector generation failed for definition, using dense only", "file", file, "definition", idx, "error", err)
+				i.cfg.Logger.DebugContext(ctx, "sparse vector generation failed for definition, using dense only", "file", file, "definition", idx, "error", err)
 			}
 		}
 
@@ -691,6 +924,7 @@ func (i *Indexer) ProcessFile(ctx context.Context, repoPath, file string) []sche
 		allDocs = append(allDocs, i.buildTOCDocs(ctx, file, defDocs, fileSummary, fileKeywords)...)
 	}
 
+	setContentHashes(allDocs)
 	return allDocs
 }
 
@@ -701,7 +935,7 @@ func (i *Indexer) buildTOCDocs(ctx context.Context, file string, defDocs []schem
 	if len(defDocs) == 0 {
 		return nil
 	}
-	i.cfg.Logger.Debug("extracted definitions from file", "file", file, "definitions", len(defDocs))
+	i.cfg.Logger.DebugContext(ctx, "extracted definitions from file", "file", file, "definitions", len(defDocs))
 	toc := buildTOCChunk(file, defDocs)
 	if toc == nil {
 		return nil
@@ -715,11 +949,11 @@ func (i *Indexer) buildTOCDocs(ctx context.Context, file string, defDocs []schem
 	}
 	if sparseVec, err := sparse.GenerateSparseVector(ctx, toc.PageContent); err == nil {
 		toc.Sparse = sparseVec
-		i.cfg.Logger.Debug("sparse vector generated for TOC", "file", file, "sparse_indices", len(sparseVec.Indices))
+		i.cfg.Logger.DebugContext(ctx, "sparse vector generated for TOC", "file", file, "sparse_indices", len(sparseVec.Indices))
 	} else {
-		i.cfg.Logger.Debug("sparse vector generation failed for TOC, using dense only", "file", file, "error", err)
+		i.cfg.Logger.DebugContext(ctx, "sparse vector generation failed for TOC, using dense only", "file", file, "error", err)
 	}
-	i.cfg.Logger.Debug("built TOC chunk", "file", file, "symbols", len(defDocs))
+	i.cfg.Logger.DebugContext(ctx, "built TOC chunk", "file", file, "symbols", len(defDocs))
 	return []schema.Document{*toc}
 }
 
@@ -939,13 +1173,13 @@ func (i *Indexer) generateFileSummary(ctx context.Context, filePath, content str
 
 	prompt, err := i.cfg.PromptMgr.Render(llm.FileSummaryPrompt, promptData)
 	if err != nil {
-		i.cfg.Logger.Debug("failed to render file summary prompt", "file", filePath, "error", err)
+		i.cfg.Logger.DebugContext(ctx, "failed to render file summary prompt", "file", filePath, "error", err)
 		return fileSummaryResult{}
 	}
 
 	response, err := llms.GenerateFromSinglePrompt(ctx, i.cfg.LLM, prompt)
 	if err != nil {
-		i.cfg.Logger.Debug("failed to generate file summary", "file", filePath, "error", err)
+		i.cfg.Logger.DebugContext(ctx, "failed to generate file summary", "file", filePath, "error", err)
 		return fileSummaryResult{}
 	}
 
@@ -963,7 +1197,7 @@ func (i *Indexer) generateFileSummary(ctx context.Context, filePath, content str
 	globalFileSummaryCache.cache[contentHash] = result
 	globalFileSummaryCache.mu.Unlock()
 
-	i.cfg.Logger.Info("generated file summary", "file", filePath, "summary", summary, "keywords", keywords)
+	i.cfg.Logger.InfoContext(ctx, "generated file summary", "file", filePath, "summary", summary, "keywords", keywords)
 	return result
 }
 
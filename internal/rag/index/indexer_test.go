@@ -2,6 +2,7 @@ package index
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -10,10 +11,12 @@ import (
 
 	"github.com/sevigo/goframe/parsers"
 	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/storage"
 	"github.com/sevigo/code-warden/mocks"
 )
@@ -38,6 +41,7 @@ func TestSetupRepoContext_Basic(t *testing.T) {
 	testFile := filepath.Join(repoDir, "main.go")
 	err := os.WriteFile(testFile, []byte("package main\n\nfunc main() {}\n"), 0644)
 	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "logo.png"), []byte{0x89, 'P', 'N', 'G'}, 0644))
 
 	repo := &storage.Repository{
 		ID:                   1,
@@ -49,6 +53,13 @@ func TestSetupRepoContext_Basic(t *testing.T) {
 	mockVS.EXPECT().ForRepo(repo.QdrantCollectionName, "test_model").Return(mockSVS)
 	mockSVS.EXPECT().AddDocuments(gomock.Any(), gomock.Any()).Return([]string{"id1"}, nil)
 	mockStore.EXPECT().UpsertFiles(gomock.Any(), repo.ID, gomock.Any()).Return(nil)
+	mockStore.EXPECT().ReplaceSymbols(gomock.Any(), repo.ID, []string{"main.go"}, gomock.Any(), gomock.Any()).Return(nil)
+	var stats *core.IndexStats
+	mockStore.EXPECT().SaveIndexStats(gomock.Any(), repo.ID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, s *core.IndexStats) error {
+			stats = s
+			return nil
+		})
 
 	cfg := Config{
 		Store:          mockStore,
@@ -62,6 +73,64 @@ func TestSetupRepoContext_Basic(t *testing.T) {
 
 	err = indexer.SetupRepoContext(context.Background(), nil, repo, repoDir, nil)
 	assert.NoError(t, err)
+
+	require.NotNil(t, stats)
+	assert.Equal(t, 2, stats.Files)
+	assert.Equal(t, 1, stats.IndexedFiles)
+	assert.Positive(t, stats.Chunks)
+	assert.Equal(t, map[string]int{core.SkipUnsupportedExtension: 1}, stats.Skipped)
+}
+
+// TestSetupRepoContext_ProgressFollowsStorage verifies that files only
+// count as done once their chunks are stored.
+func TestSetupRepoContext_ProgressFollowsStorage(t *testing.T) {
+	t.Run("counted files", func(t *testing.T) {
+		testSetupRepoContextProgress(t, []string{"a.go", "b.go"}, []string{"stored", "2/2"})
+	})
+	// The walk skips hidden files, which the loader still delivers.
+	t.Run("more stored files than counted", func(t *testing.T) {
+		testSetupRepoContextProgress(t, []string{"a.go", "b.go", ".c.go"}, []string{"stored", "2/2"})
+	})
+}
+
+func testSetupRepoContextProgress(t *testing.T, files, want []string) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	mockStore := mocks.NewMockStore(ctrl)
+	mockVS := mocks.NewMockVectorStore(ctrl)
+	mockSVS := mocks.NewMockScopedVectorStore(ctrl)
+
+	repoDir := t.TempDir()
+	for _, name := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, name), []byte("package main\n\nfunc "+strings.TrimSuffix(strings.TrimPrefix(name, "."), ".go")+"() {}\n"), 0o600))
+	}
+	repo := &storage.Repository{ID: 1, QdrantCollectionName: "test_coll"}
+
+	var events []string
+	mockStore.EXPECT().GetFilesForRepo(gomock.Any(), repo.ID).Return(map[string]storage.FileRecord{}, nil)
+	mockVS.EXPECT().ForRepo(repo.QdrantCollectionName, "test_model").Return(mockSVS)
+	mockSVS.EXPECT().AddDocuments(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, docs []schema.Document, _ ...vectorstores.Option) ([]string, error) {
+			events = append(events, "stored")
+			return make([]string, len(docs)), nil
+		})
+	mockStore.EXPECT().UpsertFiles(gomock.Any(), repo.ID, gomock.Any()).Return(nil)
+	mockStore.EXPECT().ReplaceSymbols(gomock.Any(), repo.ID, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockStore.EXPECT().SaveIndexStats(gomock.Any(), repo.ID, gomock.Any()).Return(nil)
+
+	indexer := New(Config{
+		Store:          mockStore,
+		VectorStore:    mockVS,
+		Splitter:       &mockSplitter{},
+		ParserRegistry: parsers.NewRegistry(slog.Default()),
+		Logger:         slog.New(slog.DiscardHandler),
+		EmbedderModel:  "test_model",
+	})
+	err := indexer.SetupRepoContext(context.Background(), nil, repo, repoDir, func(done, total int) {
+		events = append(events, fmt.Sprintf("%d/%d", done, total))
+	})
+	require.NoError(t, err)
+	assert.Equal(t, want, events)
 }
 
 func TestSetupRepoContext_SmartScan(t *testing.T) {
@@ -89,6 +158,7 @@ func TestSetupRepoContext_SmartScan(t *testing.T) {
 
 	// ForRepo IS called once to initialize scopedStore
 	mockVS.EXPECT().ForRepo(gomock.Any(), gomock.Any()).Return(mocks.NewMockScopedVectorStore(ctrl))
+	mockStore.EXPECT().SaveIndexStats(gomock.Any(), repo.ID, gomock.Any()).Return(nil)
 
 	cfg := Config{
 		Store:          mockStore,
@@ -129,6 +199,7 @@ func TestSetupRepoContext_Pruning(t *testing.T) {
 	// Pruning expectations
 	mockStore.EXPECT().DeleteFiles(gomock.Any(), repo.ID, []string{staleFile}).Return(nil)
 	mockVS.EXPECT().DeleteDocumentsFromCollectionByFilter(gomock.Any(), repo.QdrantCollectionName, "test_model", gomock.Any()).Return(nil)
+	mockStore.EXPECT().SaveIndexStats(gomock.Any(), repo.ID, gomock.Any()).Return(nil)
 
 	cfg := Config{
 		Store:          mockStore,
@@ -285,8 +356,21 @@ func TestUpdateRepoContext(t *testing.T) {
 	// Expectations
 	mockVS.EXPECT().DeleteDocumentsFromCollection(gomock.Any(), repo.QdrantCollectionName, "test_model", filesToDelete).Return(nil)
 	mockVS.EXPECT().ForRepo(repo.QdrantCollectionName, "test_model").Return(mockSVS)
-	mockSVS.EXPECT().AddDocuments(gomock.Any(), gomock.Any()).Return([]string{"id2"}, nil)
+	mockSVS.EXPECT().SimilaritySearchWithScores(gomock.Any(), "new.go", gomock.Any(), gomock.Any()).Return(nil, nil)
+	stored := false
+	mockSVS.EXPECT().AddDocuments(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(context.Context, []schema.Document, ...vectorstores.Option) ([]string, error) {
+			stored = true
+			return []string{"id2"}, nil
+		})
 	mockStore.EXPECT().UpsertFiles(gomock.Any(), repo.ID, gomock.Any()).Return(nil)
+	mockStore.EXPECT().ReplaceSymbols(gomock.Any(), repo.ID, filesToDelete, nil, nil).Return(nil)
+	var defs []storage.SymbolDefinition
+	mockStore.EXPECT().ReplaceSymbols(gomock.Any(), repo.ID, filesToProcess, gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, _ []string, d []storage.SymbolDefinition, _ []storage.SymbolReference) error {
+			defs = d
+			return nil
+		})
 
 	cfg := Config{
 		Store:          mockStore,
@@ -298,6 +382,77 @@ func TestUpdateRepoContext(t *testing.T) {
 	}
 	indexer := New(cfg)
 
-	err := indexer.UpdateRepoContext(context.Background(), nil, repo, repoDir, filesToProcess, filesToDelete, nil)
+	// A changed file is done once its chunks are stored.
+	var progress [][2]int
+	err := indexer.UpdateRepoContext(context.Background(), nil, repo, repoDir, filesToProcess, filesToDelete, func(done, total int) {
+		if done == total {
+			assert.True(t, stored, "progress complete before the chunks were stored")
+		}
+		progress = append(progress, [2]int{done, total})
+	})
 	assert.NoError(t, err)
+	assert.Equal(t, [][2]int{{1, 2}, {2, 2}}, progress)
+	require.Len(t, defs, 1)
+	assert.Equal(t, "DoWork", defs[0].Symbol)
+	assert.Equal(t, "new.go", defs[0].FilePath)
+}
+
+func TestUpdateRepoContext_Parallel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStore := mocks.NewMockStore(ctrl)
+	mockVS := mocks.NewMockVectorStore(ctrl)
+	mockSVS := mocks.NewMockScopedVectorStore(ctrl)
+
+	repoDir := t.TempDir()
+	repo := &storage.Repository{ID: 1, QdrantCollectionName: "test_coll"}
+
+	var filesToProcess []string
+	for n := range 20 {
+		file := fmt.Sprintf("pkg%d.go", n)
+		require.NoError(t, os.WriteFile(filepath.Join(repoDir, file), []byte(fmt.Sprintf("package pkg\n\nfunc Work%d() {}\n", n)), 0644))
+		filesToProcess = append(filesToProcess, file)
+	}
+
+	mockVS.EXPECT().ForRepo(repo.QdrantCollectionName, "test_model").Return(mockSVS)
+	mockSVS.EXPECT().SimilaritySearchWithScores(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil).Times(len(filesToProcess))
+	var storedChunks int
+	mockSVS.EXPECT().AddDocuments(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, docs []schema.Document, _ ...vectorstores.Option) ([]string, error) {
+			storedChunks += len(docs)
+			return nil, nil
+		}).MinTimes(1)
+	var records []storage.FileRecord
+	mockStore.EXPECT().UpsertFiles(gomock.Any(), repo.ID, gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ int64, r []storage.FileRecord) error {
+			records = r
+			return nil
+		})
+	mockStore.EXPECT().ReplaceSymbols(gomock.Any(), repo.ID, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	indexer := New(Config{
+		Store:          mockStore,
+		VectorStore:    mockVS,
+		Splitter:       &mockSplitter{},
+		ParserRegistry: parsers.NewRegistry(slog.Default()),
+		Logger:         slog.Default(),
+		EmbedderModel:  "test_model",
+		Workers:        3,
+	})
+
+	var last int
+	err := indexer.UpdateRepoContext(context.Background(), nil, repo, repoDir, filesToProcess, nil, func(done, total int) {
+		assert.GreaterOrEqual(t, done, last, "progress never goes back")
+		assert.Equal(t, len(filesToProcess), total)
+		last = done
+	})
+	require.NoError(t, err)
+	assert.Equal(t, len(filesToProcess), last)
+	assert.Len(t, records, len(filesToProcess))
+	chunks := 0
+	for _, r := range records {
+		chunks += r.ChunkCount
+	}
+	assert.Equal(t, storedChunks, chunks)
 }
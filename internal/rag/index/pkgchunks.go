@@ -155,7 +155,7 @@ func buildPackageDocuments(ctx context.Context, packageMap map[string]*PackageIn
 	}
 
 	if logger != nil {
-		logger.Debug("built package chunks", "count", len(packageChunks))
+		logger.DebugContext(ctx, "built package chunks", "count", len(packageChunks))
 	}
 
 	return packageChunks
@@ -0,0 +1,286 @@
+package index
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+const (
+	// defaultMaxFileSizeKB caps the size of indexed files when the repository
+	// does not set max_file_size_kb. Larger files are nearly always data,
+	// fixtures or bundles that would flood the index with useless chunks.
+	defaultMaxFileSizeKB = 1024
+	// sniffBytes is how much of a file is inspected for binary content, the
+	// same amount git looks at.
+	sniffBytes = 8000
+	// headerLines is how many leading lines are searched for a generated-code
+	// marker.
+	headerLines = 20
+)
+
+// defaultGeneratedPatterns are glob patterns of files produced by code
+// generators. They are matched against the base name of a file.
+var defaultGeneratedPatterns = []string{
+	"*.pb.go", "*_pb.go", "*.pb.gw.go", "*_grpc.pb.go",
+	"*_gen.go", "*.gen.go", "*_generated.go", "zz_generated*.go",
+	"*.gen.ts", "*.gen.js", "*.generated.ts", "*.generated.js",
+	"*_pb2.py", "*_pb2_grpc.py",
+	"*.min.js",
+}
+
+// generatedMarkers are lower-case comments that generators put into the
+// header of their output, e.g. Go's "// Code generated ... DO NOT EDIT.".
+var generatedMarkers = []string{
+	"code generated", "@generated", "<auto-generated", "automatically generated",
+	"this file was generated", "this file is generated",
+}
+
+// skipReason is why a file is left out of the index.
+type skipReason string
+
+const (
+	skipNone       skipReason = ""
+	skipGenerated  skipReason = core.SkipGenerated
+	skipBinary     skipReason = core.SkipBinary
+	skipTooLarge   skipReason = core.SkipTooLarge
+	skipSubProject skipReason = core.SkipSubProjectExcluded
+	skipPattern    skipReason = core.SkipExcludedPattern
+	skipFileLimit  skipReason = core.SkipFileLimit
+)
+
+// fileFilter decides which files are not worth embedding: files the include
+// and exclude patterns leave out, generated code, binary content, files above
+// a size cap, files the exclude lists of their sub-project leave out and files
+// beyond the cap on the number of indexed files. It counts the files it skips and is safe for concurrent use.
+type fileFilter struct {
+	maxSize          int64
+	maxFiles         int64 // zero means unlimited
+	includeGenerated bool
+	patterns         []string
+	paths            *core.PathFilter // nil without include or exclude patterns
+	// layout holds only the sub-projects of the repository.
+	layout core.RepoConfig
+	// tracked holds files already in the index, which keep their place
+	// under maxFiles; see countTracked.
+	tracked map[string]storage.FileRecord
+
+	admitted   atomic.Int64 // files counted against maxFiles
+	pattern    atomic.Int64
+	generated  atomic.Int64
+	binary     atomic.Int64
+	tooLarge   atomic.Int64
+	subProject atomic.Int64
+	fileLimit  atomic.Int64
+}
+
+// indexing returns the indexing settings of repoConfig with the server's
+// limits applied.
+func (i *Indexer) indexing(repoConfig *core.RepoConfig) core.IndexingConfig {
+	cfg := repoConfig.Indexing
+	cfg.MaxFileSizeKB = core.EffectiveLimit(cfg.MaxFileSizeKB, i.cfg.MaxFileSizeKB)
+	cfg.MaxFiles = core.EffectiveLimit(cfg.MaxFiles, i.cfg.MaxFiles)
+	return cfg
+}
+
+// ignorePatterns returns the patterns of the .gitignore, unless the
+// repository disables it, and .wardenignore at the root of repoPath. Files
+// that cannot be read are logged and contribute no patterns.
+func (i *Indexer) ignorePatterns(ctx context.Context, repoPath string, repoConfig *core.RepoConfig) []string {
+	patterns, err := config.LoadIgnorePatterns(repoPath, !repoConfig.Indexing.DisableGitignore)
+	if err != nil {
+		i.cfg.Logger.WarnContext(ctx, "failed to read ignore files, indexing without them", "path", repoPath, "error", err)
+	}
+	return patterns
+}
+
+// warnFileLimit logs when filter left files out of the index of repo
+// because of the file limit, which leaves parts of the repository without
+// context.
+func (i *Indexer) warnFileLimit(repo string, filter *fileFilter) {
+	if n := filter.fileLimit.Load(); n > 0 {
+		i.cfg.Logger.Warn("repository exceeds the file limit, files were left out of the index",
+			"repo", repo, "max_files", filter.maxFiles, "skipped", n)
+	}
+}
+
+// newFileFilter builds the filter for a repository's indexing settings, its
+// include and exclude patterns, the patterns of its ignore files and its
+// sub-projects. repoConfig may be nil.
+func newFileFilter(cfg core.IndexingConfig, repoConfig *core.RepoConfig, ignore []string) *fileFilter {
+	var subProjects []core.SubProject
+	if repoConfig != nil {
+		subProjects = repoConfig.SubProjects
+	}
+	maxSizeKB := cfg.MaxFileSizeKB
+	if maxSizeKB <= 0 {
+		maxSizeKB = defaultMaxFileSizeKB
+	}
+	return &fileFilter{
+		maxSize:          int64(maxSizeKB) * 1024,
+		maxFiles:         int64(max(cfg.MaxFiles, 0)),
+		includeGenerated: cfg.IncludeGenerated,
+		patterns:         append(append([]string{}, defaultGeneratedPatterns...), cfg.GeneratedPatterns...),
+		paths:            repoConfig.PathFilter(ignore),
+		layout:           core.RepoConfig{SubProjects: subProjects},
+	}
+}
+
+// countTracked charges the files already in the index, except those about
+// to be deleted, against maxFiles, so that an incremental update only admits
+// new files while there is room for them.
+func (f *fileFilter) countTracked(tracked map[string]storage.FileRecord, deleted []string) {
+	f.tracked = tracked
+	n := len(tracked)
+	for _, file := range deleted {
+		if _, ok := tracked[file]; ok {
+			n--
+		}
+	}
+	f.admitted.Store(int64(n))
+}
+
+// skip returns why file, relative to the repository and found at fullPath,
+// should be left out of the index, and counts it, or skipNone. Files that
+// cannot be inspected are kept so that ProcessFile reports the error.
+func (f *fileFilter) skip(fullPath, file string) skipReason {
+	reason, err := f.check(fullPath, file)
+	if err != nil || reason == skipNone {
+		if f.overFileLimit(file) {
+			f.fileLimit.Add(1)
+			return skipFileLimit
+		}
+		return skipNone
+	}
+	switch reason {
+	case skipPattern:
+		f.pattern.Add(1)
+	case skipGenerated:
+		f.generated.Add(1)
+	case skipBinary:
+		f.binary.Add(1)
+	case skipTooLarge:
+		f.tooLarge.Add(1)
+	case skipSubProject:
+		f.subProject.Add(1)
+	}
+	return reason
+}
+
+// check returns why file should be skipped, or skipNone.
+func (f *fileFilter) check(fullPath, file string) (skipReason, error) {
+	if !f.paths.Allows(file) {
+		return skipPattern, nil
+	}
+	if p := f.layout.SubProjectFor(file); p != nil && p.Excludes(file) {
+		return skipSubProject, nil
+	}
+	if !f.includeGenerated && f.matchesGeneratedPattern(file) {
+		return skipGenerated, nil
+	}
+
+	fh, err := os.Open(fullPath)
+	if err != nil {
+		return skipNone, fmt.Errorf("failed to open %s: %w", file, err)
+	}
+	defer fh.Close()
+
+	info, err := fh.Stat()
+	if err != nil {
+		return skipNone, fmt.Errorf("failed to stat %s: %w", file, err)
+	}
+	if info.Size() > f.maxSize {
+		return skipTooLarge, nil
+	}
+
+	head := make([]byte, sniffBytes)
+	n, err := io.ReadFull(fh, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return skipNone, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	head = head[:n]
+	if isBinary(head) {
+		return skipBinary, nil
+	}
+	if !f.includeGenerated && hasGeneratedHeader(head) {
+		return skipGenerated, nil
+	}
+	return skipNone, nil
+}
+
+// overFileLimit reports whether indexing file, which passed the other
+// checks, would exceed maxFiles, and otherwise counts it.
+func (f *fileFilter) overFileLimit(file string) bool {
+	if f.maxFiles == 0 {
+		return false
+	}
+	if _, ok := f.tracked[file]; ok {
+		return false
+	}
+	if f.admitted.Add(1) > f.maxFiles {
+		f.admitted.Add(-1)
+		return true
+	}
+	return false
+}
+
+func (f *fileFilter) matchesGeneratedPattern(file string) bool {
+	for _, pattern := range f.patterns {
+		if core.MatchGlob(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// skipped returns the number of files skipped so far.
+func (f *fileFilter) skipped() int64 {
+	return f.pattern.Load() + f.generated.Load() + f.binary.Load() + f.tooLarge.Load() + f.subProject.Load() + f.fileLimit.Load()
+}
+
+// logArgs returns the skip counts as structured log arguments.
+func (f *fileFilter) logArgs() []any {
+	return []any{
+		"skipped_by_pattern", f.pattern.Load(),
+		"skipped_generated", f.generated.Load(),
+		"skipped_binary", f.binary.Load(),
+		"skipped_too_large", f.tooLarge.Load(),
+		"skipped_by_sub_project", f.subProject.Load(),
+		"skipped_file_limit", f.fileLimit.Load(),
+	}
+}
+
+// isBinary reports whether head looks binary. Like git, it treats any NUL
+// byte as a sign of binary data.
+func isBinary(head []byte) bool {
+	return bytes.IndexByte(head, 0) >= 0
+}
+
+// hasGeneratedHeader reports whether one of the first lines of head carries a
+// generated-code marker.
+func hasGeneratedHeader(head []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(head))
+	scanner.Buffer(make([]byte, 0, len(head)), len(head)+1)
+	for range headerLines {
+		if !scanner.Scan() {
+			return false
+		}
+		line := strings.ToLower(scanner.Text())
+		for _, marker := range generatedMarkers {
+			if strings.Contains(line, marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
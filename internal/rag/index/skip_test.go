@@ -0,0 +1,118 @@
+package index
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+func TestFileFilter(t *testing.T) {
+	repoDir := t.TempDir()
+	files := map[string]string{
+		"main.go":              "package main\n\nfunc main() {}\n",
+		"api/service.pb.go":    "package api\n",
+		"web/client.gen.ts":    "export const x = 1;\n",
+		"models/models.go":     "// Code generated by sqlc. DO NOT EDIT.\n// versions:\n\npackage models\n",
+		"late_marker.go":       strings.Repeat("// comment\n", headerLines) + "// Code generated by hand-rolled tool. DO NOT EDIT.\n",
+		"data.json":            "{\"a\":\x00\"b\"}",
+		"fixtures/big.json":    strings.Repeat("x", 2048),
+		"internal/db_mock.go":  "package internal\n",
+		"docs/README.md":       "# Docs\n\nDo not edit generated files by hand.\n",
+		"internal/handlers.go": "package internal\n\n// handler code\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repoDir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	tests := []struct {
+		file string
+		cfg  core.IndexingConfig
+		want skipReason
+	}{
+		{file: "main.go", want: skipNone},
+		{file: "api/service.pb.go", want: skipGenerated},
+		{file: "web/client.gen.ts", want: skipGenerated},
+		{file: "models/models.go", want: skipGenerated},
+		{file: "late_marker.go", want: skipNone},
+		{file: "data.json", want: skipBinary},
+		{file: "fixtures/big.json", cfg: core.IndexingConfig{MaxFileSizeKB: 1}, want: skipTooLarge},
+		{file: "fixtures/big.json", want: skipNone},
+		{file: "internal/db_mock.go", cfg: core.IndexingConfig{GeneratedPatterns: []string{"*_mock.go"}}, want: skipGenerated},
+		{file: "api/service.pb.go", cfg: core.IndexingConfig{IncludeGenerated: true}, want: skipNone},
+		{file: "models/models.go", cfg: core.IndexingConfig{IncludeGenerated: true}, want: skipNone},
+		{file: "data.json", cfg: core.IndexingConfig{IncludeGenerated: true}, want: skipBinary},
+		{file: "internal/handlers.go", want: skipNone},
+		{file: "docs/README.md", want: skipNone},
+	}
+	for _, tt := range tests {
+		got, err := newFileFilter(tt.cfg, nil, nil).check(filepath.Join(repoDir, tt.file), tt.file)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got, "%s with %+v", tt.file, tt.cfg)
+	}
+
+	t.Run("counts skipped files", func(t *testing.T) {
+		filter := newFileFilter(core.IndexingConfig{MaxFileSizeKB: 1}, nil, nil)
+		for name := range files {
+			filter.skip(filepath.Join(repoDir, name), name)
+		}
+		assert.Equal(t, int64(3), filter.generated.Load())
+		assert.Equal(t, int64(1), filter.binary.Load())
+		assert.Equal(t, int64(1), filter.tooLarge.Load())
+		assert.Equal(t, int64(5), filter.skipped())
+	})
+
+	t.Run("sub-project exclude lists", func(t *testing.T) {
+		filter := newFileFilter(core.IndexingConfig{}, &core.RepoConfig{SubProjects: []core.SubProject{
+			{Path: "web", ExcludeDirs: []string{"fixtures"}, ExcludeExts: []string{".snap"}},
+		}}, nil)
+		assert.Equal(t, skipSubProject, filter.skip(filepath.Join(repoDir, "web/fixtures/a.ts"), "web/fixtures/a.ts"))
+		assert.Equal(t, skipSubProject, filter.skip(filepath.Join(repoDir, "web/app.snap"), "web/app.snap"))
+		assert.Equal(t, int64(2), filter.subProject.Load())
+		assert.Equal(t, int64(2), filter.skipped())
+	})
+
+	t.Run("include and exclude patterns", func(t *testing.T) {
+		filter := newFileFilter(core.IndexingConfig{}, &core.RepoConfig{
+			Include: []string{"api/", "internal/"},
+			Exclude: []string{"*.pb.go"},
+		}, nil)
+		assert.Equal(t, skipPattern, filter.skip(filepath.Join(repoDir, "main.go"), "main.go"), "not included")
+		assert.Equal(t, skipPattern, filter.skip(filepath.Join(repoDir, "api/service.pb.go"), "api/service.pb.go"), "patterns are checked first")
+		assert.Equal(t, skipNone, filter.skip(filepath.Join(repoDir, "internal/handlers.go"), "internal/handlers.go"))
+		assert.Equal(t, int64(2), filter.pattern.Load())
+		assert.Zero(t, filter.generated.Load())
+
+		filter = newFileFilter(core.IndexingConfig{}, &core.RepoConfig{Exclude: []string{"!docs/README.md"}}, []string{"docs/", "*.pb.go"})
+		assert.Equal(t, skipPattern, filter.skip(filepath.Join(repoDir, "api/service.pb.go"), "api/service.pb.go"), "ignore file patterns")
+		assert.Equal(t, skipNone, filter.skip(filepath.Join(repoDir, "docs/README.md"), "docs/README.md"), "exclude patterns apply after ignore files")
+	})
+
+	t.Run("file limit", func(t *testing.T) {
+		filter := newFileFilter(core.IndexingConfig{MaxFiles: 2}, nil, nil)
+		for _, name := range []string{"main.go", "api/service.pb.go", "internal/handlers.go", "docs/README.md"} {
+			filter.skip(filepath.Join(repoDir, name), name)
+		}
+		assert.Equal(t, int64(1), filter.fileLimit.Load(), "skipped files do not count against the limit")
+		assert.Equal(t, int64(2), filter.skipped())
+
+		filter = newFileFilter(core.IndexingConfig{MaxFiles: 2}, nil, nil)
+		filter.countTracked(map[string]storage.FileRecord{"main.go": {}, "docs/README.md": {}, "old.go": {}}, []string{"old.go"})
+		assert.Equal(t, skipNone, filter.skip(filepath.Join(repoDir, "main.go"), "main.go"), "indexed files keep their place")
+		assert.Equal(t, skipFileLimit, filter.skip(filepath.Join(repoDir, "internal/handlers.go"), "internal/handlers.go"))
+	})
+
+	t.Run("unreadable files are kept", func(t *testing.T) {
+		filter := newFileFilter(core.IndexingConfig{}, nil, nil)
+		assert.Equal(t, skipNone, filter.skip(filepath.Join(repoDir, "missing.go"), "missing.go"))
+		assert.Zero(t, filter.skipped())
+	})
+}
@@ -0,0 +1,76 @@
+package index
+
+import (
+	"context"
+
+	"github.com/sevigo/goframe/schema"
+
+	"github.com/sevigo/code-warden/internal/rag/metadata"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// collectSymbols builds the symbol graph rows of docs, as produced by
+// ProcessFile: definition chunks define their identifier, code chunks
+// reference the symbols they use.
+func collectSymbols(docs []schema.Document) ([]storage.SymbolDefinition, []storage.SymbolReference) {
+	var (
+		defs []storage.SymbolDefinition
+		refs []storage.SymbolReference
+	)
+	for _, doc := range docs {
+		source, _ := doc.Metadata["source"].(string)
+		if source == "" {
+			continue
+		}
+
+		line := metadata.ExtractLineNumber(doc.Metadata)
+		switch chunkType, _ := doc.Metadata["chunk_type"].(string); chunkType {
+		case "definition":
+			name, _ := doc.Metadata["identifier"].(string)
+			if name == "" {
+				continue
+			}
+			kind, _ := doc.Metadata["kind"].(string)
+			defs = append(defs, storage.SymbolDefinition{
+				FilePath:  source,
+				Symbol:    name,
+				Kind:      kind,
+				LineStart: line,
+				LineEnd:   int(metadata.ExtractInt64(doc.Metadata, "end_line")),
+			})
+		case "code":
+			symbols, _ := doc.Metadata["symbols"].([]string)
+			for _, sym := range symbols {
+				refs = append(refs, storage.SymbolReference{FilePath: source, Line: line, Symbol: sym})
+			}
+		}
+	}
+	return defs, refs
+}
+
+// saveSymbols replaces the symbol graph of files with the one of docs, which
+// must contain every document of those files and may contain others. Failures
+// are logged: the graph only adds context on top of the vector index.
+func (i *Indexer) saveSymbols(ctx context.Context, repoID int64, files []storage.FileRecord, docs []schema.Document) {
+	if len(files) == 0 {
+		return
+	}
+	paths := make([]string, 0, len(files))
+	keep := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		paths = append(paths, f.FilePath)
+		keep[f.FilePath] = struct{}{}
+	}
+	var fileDocs []schema.Document
+	for _, doc := range docs {
+		if source, _ := doc.Metadata["source"].(string); source != "" {
+			if _, ok := keep[source]; ok {
+				fileDocs = append(fileDocs, doc)
+			}
+		}
+	}
+	defs, refs := collectSymbols(fileDocs)
+	if err := i.cfg.Store.ReplaceSymbols(ctx, repoID, paths, defs, refs); err != nil {
+		i.cfg.Logger.WarnContext(ctx, "failed to update symbol graph", "files", len(paths), "error", err)
+	}
+}
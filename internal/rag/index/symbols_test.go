@@ -0,0 +1,24 @@
+package index
+
+import (
+	"testing"
+
+	"github.com/sevigo/goframe/schema"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+func TestCollectSymbols(t *testing.T) {
+	docs := []schema.Document{
+		{Metadata: map[string]any{"source": "svc.go", "chunk_type": "code", "line": 3, "symbols": []string{"Store", "Logger"}}},
+		{Metadata: map[string]any{"source": "svc.go", "chunk_type": "definition", "identifier": "Service", "kind": "struct", "line": 5, "end_line": 9}},
+		{Metadata: map[string]any{"source": "svc.go", "chunk_type": "toc"}},
+		{Metadata: map[string]any{"chunk_type": "code", "symbols": []string{"Orphan"}}},
+	}
+
+	defs, refs := collectSymbols(docs)
+
+	assert.Equal(t, []storage.SymbolDefinition{{FilePath: "svc.go", Symbol: "Service", Kind: "struct", LineStart: 5, LineEnd: 9}}, defs)
+	assert.Equal(t, []storage.SymbolReference{{FilePath: "svc.go", Line: 3, Symbol: "Store"}, {FilePath: "svc.go", Line: 3, Symbol: "Logger"}}, refs)
+}
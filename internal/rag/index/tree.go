@@ -0,0 +1,124 @@
+package index
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/sevigo/goframe/documentloaders"
+	"github.com/sevigo/goframe/schema"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// IndexTree indexes every file of the tree at repoPath into collectionName,
+// replacing whatever the collection held before, and returns the number of
+// files and of documents indexed.
+//
+// Unlike SetupRepoContext it keeps no per-file records in the database, so it
+// suits temporary collections such as the snapshot of a past commit that are
+// never updated incrementally.
+func (i *Indexer) IndexTree(ctx context.Context, repoConfig *core.RepoConfig, collectionName, repoPath string) (files, chunks int, err error) {
+	if repoConfig == nil {
+		repoConfig = core.DefaultRepoConfig()
+	}
+	i.cfg.Logger.InfoContext(ctx, "indexing tree into standalone collection", "path", repoPath, "collection", collectionName)
+
+	// A leftover collection from an earlier, possibly interrupted, run would
+	// mix in stale chunks. A missing collection is not an error here.
+	if err := i.cfg.VectorStore.DeleteCollection(ctx, collectionName); err != nil {
+		i.cfg.Logger.DebugContext(ctx, "no previous collection to delete", "collection", collectionName, "error", err)
+	}
+
+	loader, err := documentloaders.NewGit(repoPath, i.cfg.ParserRegistry,
+		documentloaders.WithExcludeDirs(BuildExcludeDirs(repoConfig)),
+		documentloaders.WithExcludeExts(repoConfig.ExcludeExts),
+		documentloaders.WithWorkerCount(4),
+		documentloaders.WithGeneratedCodeDetection(!repoConfig.Indexing.IncludeGenerated),
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to initialize git loader: %w", err)
+	}
+
+	const numWorkers = 4
+	const batchSize = 500
+
+	fileChan := make(chan string, numWorkers*2)
+	docsChan := make(chan []schema.Document, numWorkers*2)
+
+	var wg sync.WaitGroup
+	for range numWorkers {
+		wg.Go(func() {
+			for file := range fileChan {
+				if ctx.Err() != nil {
+					continue
+				}
+				docsChan <- i.ProcessFile(ctx, repoPath, file)
+			}
+		})
+	}
+
+	scopedStore := i.cfg.VectorStore.ForRepo(collectionName, i.cfg.EmbedderModel)
+	var addErr error
+	collectorDone := make(chan struct{})
+	go func() {
+		defer close(collectorDone)
+		var batch []schema.Document
+		flush := func() {
+			if len(batch) == 0 || addErr != nil {
+				return
+			}
+			if _, err := scopedStore.AddDocuments(ctx, batch); err != nil {
+				addErr = fmt.Errorf("failed to add documents to %s: %w", collectionName, err)
+				return
+			}
+			chunks += len(batch)
+			batch = batch[:0]
+		}
+		for docs := range docsChan {
+			batch = append(batch, docs...)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		}
+		flush()
+	}()
+
+	filter := newFileFilter(i.indexing(repoConfig), repoConfig, i.ignorePatterns(ctx, repoPath, repoConfig))
+	streamErr := loader.LoadAndProcessStream(ctx, func(_ context.Context, docs []schema.Document) error {
+		seen := make(map[string]struct{})
+		for _, doc := range docs {
+			source, _ := doc.Metadata["source"].(string)
+			if _, already := seen[source]; source == "" || already {
+				continue
+			}
+			seen[source] = struct{}{}
+			if filter.skip(filepath.Join(repoPath, source), source) != skipNone {
+				continue
+			}
+			files++
+			select {
+			case fileChan <- source:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+
+	close(fileChan)
+	wg.Wait()
+	close(docsChan)
+	<-collectorDone
+
+	if streamErr != nil {
+		return files, chunks, fmt.Errorf("failed to load files from repository: %w", streamErr)
+	}
+	if addErr != nil {
+		return files, chunks, addErr
+	}
+	i.warnFileLimit(collectionName, filter)
+	i.cfg.Logger.InfoContext(ctx, "tree indexed", append([]any{"collection", collectionName, "files", files, "chunks", chunks}, filter.logArgs()...)...)
+	return files, chunks, ctx.Err()
+}
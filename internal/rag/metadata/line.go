@@ -26,3 +26,17 @@ func ExtractLineNumber(metadata map[string]any) int {
 	}
 	return 0
 }
+
+// ExtractInt64 extracts an integer value stored under key, handling the
+// int, int64, and float64 representations produced by the vector store.
+func ExtractInt64(metadata map[string]any, key string) int64 {
+	switch val := metadata[key].(type) {
+	case int:
+		return int64(val)
+	case int64:
+		return val
+	case float64:
+		return int64(val)
+	}
+	return 0
+}
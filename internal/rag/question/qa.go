@@ -1,10 +1,14 @@
 package question
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/sevigo/goframe/chains"
@@ -13,6 +17,7 @@ import (
 	"github.com/sevigo/goframe/schema"
 	"github.com/sevigo/goframe/vectorstores"
 
+	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/llm"
 	"github.com/sevigo/code-warden/internal/rag/metadata"
 	"github.com/sevigo/code-warden/internal/storage"
@@ -21,6 +26,8 @@ import (
 const (
 	archResultLimit = 4
 	similarityLimit = 15
+	// maxCitations caps the sources listed with an answer.
+	maxCitations = 10
 )
 
 var pathPattern = regexp.MustCompile(`(?:^|\s|["'` + "`" + `])([\w/.-]+\.[a-zA-Z0-9]+|[\w/.-]+/[\w/.-]+)(?:$|\s|["'` + "`" + `])`)
@@ -57,47 +64,175 @@ type hybridRetriever struct {
 	archDocs  []schema.Document
 	sparse    *schema.SparseVector
 	baseLimit int
+
+	// retrieved holds the documents of the last retrieval with their
+	// similarity scores; the answer cites them.
+	retrieved []vectorstores.DocumentWithScore
 }
 
 func (r *hybridRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]schema.Document, error) {
-	var docs []schema.Document
+	var scored []vectorstores.DocumentWithScore
 	var err error
 
 	if r.sparse != nil {
-		docs, err = r.store.SimilaritySearch(ctx, query, r.baseLimit, vectorstores.WithSparseQuery(r.sparse))
+		scored, err = r.store.SimilaritySearchWithScores(ctx, query, r.baseLimit, vectorstores.WithSparseQuery(r.sparse))
 	} else {
-		docs, err = r.store.SimilaritySearch(ctx, query, r.baseLimit)
+		scored, err = r.store.SimilaritySearchWithScores(ctx, query, r.baseLimit)
 	}
 	if err != nil {
 		// FALLBACK: If vector DB errors out on query, gracefully return what we have (e.g. archDocs)
-		if len(r.archDocs) > 0 {
-			return deduplicateDocs(r.archDocs), nil
+		if len(r.archDocs) == 0 {
+			return nil, err
 		}
-		return nil, err
+		scored = nil
 	}
 
-	result := make([]schema.Document, 0, len(r.archDocs)+len(docs))
-	result = append(result, r.archDocs...)
-	result = append(result, docs...)
-	return deduplicateDocs(result), nil
-}
+	all := make([]vectorstores.DocumentWithScore, 0, len(r.archDocs)+len(scored))
+	for _, doc := range r.archDocs {
+		all = append(all, vectorstores.DocumentWithScore{Document: doc})
+	}
+	all = append(all, scored...)
 
-func deduplicateDocs(docs []schema.Document) []schema.Document {
 	seen := make(map[string]bool)
-	var result []schema.Document
-	for _, doc := range docs {
-		source, _ := doc.Metadata["source"].(string)
-		line := metadata.ExtractLineNumber(doc.Metadata)
-		chunkType, _ := doc.Metadata["chunk_type"].(string)
+	r.retrieved = nil
+	result := make([]schema.Document, 0, len(all))
+	for _, d := range all {
+		if key := docKey(d.Document); !seen[key] {
+			seen[key] = true
+			r.retrieved = append(r.retrieved, d)
+			result = append(result, d.Document)
+		}
+	}
+	return result, nil
+}
 
-		key := fmt.Sprintf("%s:%d:%s", source, line, chunkType)
+// citations lists the sources of the last retrieval, best scores first.
+func (r *hybridRetriever) citations() []core.Citation {
+	return citeDocuments(r.retrieved)
+}
 
-		// For chunks that lack line numbers (like architecture summaries or definitions),
-		// include the page content in the key to prevent accidental squashing of different chunks.
-		if line == 0 {
-			key = fmt.Sprintf("%s:0:%s:%s", source, chunkType, doc.PageContent)
+// citeDocuments lists the sources of docs, best scores first. A document
+// tagged with the "repo" metadata of multiRetriever is cited in that
+// repository.
+func citeDocuments(retrieved []vectorstores.DocumentWithScore) []core.Citation {
+	docs := slices.Clone(retrieved)
+	slices.SortStableFunc(docs, func(a, b vectorstores.DocumentWithScore) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	var citations []core.Citation
+	seen := make(map[core.Citation]bool)
+	for _, d := range docs {
+		source, _ := d.Document.Metadata["source"].(string)
+		if source == "" {
+			continue
+		}
+		repo, _ := d.Document.Metadata[repoMetadataKey].(string)
+		c := core.Citation{
+			Repo:      repo,
+			Source:    source,
+			LineStart: metadata.ExtractLineNumber(d.Document.Metadata),
+			LineEnd:   int(metadata.ExtractInt64(d.Document.Metadata, "end_line")),
 		}
+		// Chunks of different types can cover the same lines.
+		if seen[c] {
+			continue
+		}
+		seen[c] = true
+		c.Score = d.Score
+		citations = append(citations, c)
+		if len(citations) == maxCitations {
+			break
+		}
+	}
+	return citations
+}
+
+// repoMetadataKey tags the documents multiRetriever retrieves with their
+// repository.
+const repoMetadataKey = "repo"
+
+// repoRetriever is the retriever of one repository of a multiRetriever.
+type repoRetriever struct {
+	repo      string
+	retriever *hybridRetriever
+}
+
+// multiRetriever retrieves documents from several repositories. The
+// architecture summaries of each come first, followed by the chunks most
+// similar to the query across all of them, best scores first.
+type multiRetriever struct {
+	repos     []repoRetriever
+	baseLimit int
+
+	// retrieved holds the documents of the last retrieval, tagged with
+	// their repository; the answer cites them.
+	retrieved []vectorstores.DocumentWithScore
+}
+
+// GetRelevantDocuments returns the merged documents with each source
+// prefixed by its repository, so that the model can tell the repositories
+// apart. It fails only when no repository could be searched.
+func (r *multiRetriever) GetRelevantDocuments(ctx context.Context, query string) ([]schema.Document, error) {
+	var arch, scored []vectorstores.DocumentWithScore
+	var errs []error
+	for _, rr := range r.repos {
+		if _, err := rr.retriever.GetRelevantDocuments(ctx, query); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", rr.repo, err))
+			continue
+		}
+		for _, d := range rr.retriever.retrieved {
+			d.Document = tagDocument(d.Document, rr.repo)
+			if d.Score == 0 {
+				arch = append(arch, d)
+			} else {
+				scored = append(scored, d)
+			}
+		}
+	}
+	if len(errs) == len(r.repos) {
+		return nil, errors.Join(errs...)
+	}
+
+	slices.SortStableFunc(scored, func(a, b vectorstores.DocumentWithScore) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+	if len(scored) > r.baseLimit {
+		scored = scored[:r.baseLimit]
+	}
+	r.retrieved = append(arch, scored...)
+
+	result := make([]schema.Document, 0, len(r.retrieved))
+	for _, d := range r.retrieved {
+		doc := d.Document
+		doc.Metadata = maps.Clone(doc.Metadata)
+		source, _ := doc.Metadata["source"].(string)
+		repo, _ := doc.Metadata[repoMetadataKey].(string)
+		doc.Metadata["source"] = repo + ": " + source
+		result = append(result, doc)
+	}
+	return result, nil
+}
+
+func (r *multiRetriever) citations() []core.Citation {
+	return citeDocuments(r.retrieved)
+}
+
+// tagDocument returns doc with its metadata copied and tagged with repo.
+func tagDocument(doc schema.Document, repo string) schema.Document {
+	doc.Metadata = maps.Clone(doc.Metadata)
+	if doc.Metadata == nil {
+		doc.Metadata = make(map[string]any)
+	}
+	doc.Metadata[repoMetadataKey] = repo
+	return doc
+}
 
+func deduplicateDocs(docs []schema.Document) []schema.Document {
+	seen := make(map[string]bool)
+	var result []schema.Document
+	for _, doc := range docs {
+		key := docKey(doc)
 		if !seen[key] {
 			seen[key] = true
 			result = append(result, doc)
@@ -106,18 +241,35 @@ func deduplicateDocs(docs []schema.Document) []schema.Document {
 	return result
 }
 
-func (s *QAService) AnswerQuestion(ctx context.Context, collectionName, embedderModelName, question string, history []string) (string, error) {
-	s.cfg.Logger.Info("answering question", "collection", collectionName)
+func docKey(doc schema.Document) string {
+	source, _ := doc.Metadata["source"].(string)
+	line := metadata.ExtractLineNumber(doc.Metadata)
+	chunkType, _ := doc.Metadata["chunk_type"].(string)
+
+	// For chunks that lack line numbers (like architecture summaries or definitions),
+	// include the page content in the key to prevent accidental squashing of different chunks.
+	if line == 0 {
+		return fmt.Sprintf("%s:0:%s:%s", source, chunkType, doc.PageContent)
+	}
+	return fmt.Sprintf("%s:%d:%s", source, line, chunkType)
+}
+
+// AnswerQuestion answers question from the chunks of the collection most
+// similar to it and cites them. With a validator, the chunks are cited even
+// when the validator judged them irrelevant and the answer was generated
+// without them.
+func (s *QAService) AnswerQuestion(ctx context.Context, collectionName, embedderModelName, question string, history []string) (*core.Answer, error) {
+	s.cfg.Logger.InfoContext(ctx, "answering question", "collection", collectionName)
 
 	scopedStore := s.cfg.VectorStore.ForRepo(collectionName, embedderModelName)
 
 	relevantDocs := s.retrieveRelevantDocs(ctx, scopedStore, question)
-	s.cfg.Logger.Debug("retrieved initial relevant docs", "count", len(relevantDocs))
+	s.cfg.Logger.DebugContext(ctx, "retrieved initial relevant docs", "count", len(relevantDocs))
 
 	sparseQuery, err := sparse.GenerateSparseVector(ctx, question)
-	var retriever schema.Retriever
+	var retriever *hybridRetriever
 	if err != nil {
-		s.cfg.Logger.Warn("failed to generate sparse query", "error", err)
+		s.cfg.Logger.WarnContext(ctx, "failed to generate sparse query", "error", err)
 		retriever = &hybridRetriever{
 			store:     scopedStore,
 			archDocs:  relevantDocs,
@@ -132,11 +284,56 @@ func (s *QAService) AnswerQuestion(ctx context.Context, collectionName, embedder
 		}
 	}
 
+	var text string
 	if s.cfg.ValidatorLLM != nil {
-		return s.answerWithValidation(ctx, retriever, question, history)
+		text, err = s.answerWithValidation(ctx, retriever, question, history)
+	} else {
+		text, err = s.answerWithoutValidation(ctx, retriever, question, history)
 	}
+	if err != nil {
+		return nil, err
+	}
+	return &core.Answer{Text: text, Citations: retriever.citations()}, nil
+}
 
-	return s.answerWithoutValidation(ctx, retriever, question, history)
+// AnswerQuestionAcross answers question from the chunks most similar to it
+// across the collections of repos and cites them with their repository.
+func (s *QAService) AnswerQuestionAcross(ctx context.Context, repos []core.RepoCollection, embedderModelName, question string, history []string) (*core.Answer, error) {
+	s.cfg.Logger.InfoContext(ctx, "answering question across repositories", "repos", len(repos))
+
+	sparseQuery, err := sparse.GenerateSparseVector(ctx, question)
+	if err != nil {
+		s.cfg.Logger.WarnContext(ctx, "failed to generate sparse query", "error", err)
+		sparseQuery = nil
+	}
+	retriever := &multiRetriever{baseLimit: similarityLimit}
+	for _, repo := range repos {
+		model := embedderModelName
+		if repo.EmbedderModel != "" {
+			model = repo.EmbedderModel
+		}
+		scopedStore := s.cfg.VectorStore.ForRepo(repo.Collection, model)
+		retriever.repos = append(retriever.repos, repoRetriever{
+			repo: repo.Repo,
+			retriever: &hybridRetriever{
+				store:     scopedStore,
+				archDocs:  s.retrieveRelevantDocs(ctx, scopedStore, question),
+				sparse:    sparseQuery,
+				baseLimit: similarityLimit,
+			},
+		})
+	}
+
+	var text string
+	if s.cfg.ValidatorLLM != nil {
+		text, err = s.answerWithValidation(ctx, retriever, question, history)
+	} else {
+		text, err = s.answerWithoutValidation(ctx, retriever, question, history)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &core.Answer{Text: text, Citations: retriever.citations()}, nil
 }
 
 func (s *QAService) retrieveRelevantDocs(ctx context.Context, store storage.ScopedVectorStore, question string) []schema.Document {
@@ -159,11 +356,11 @@ func (s *QAService) retrieveRelevantDocs(ctx context.Context, store storage.Scop
 		return docs
 	}
 
-	s.cfg.Logger.Debug("keyword detected, retrieving additional definition chunks")
+	s.cfg.Logger.DebugContext(ctx, "keyword detected, retrieving additional definition chunks")
 	defDocs, err := store.SimilaritySearch(ctx, question, archResultLimit,
 		vectorstores.WithFilters(map[string]any{"chunk_type": "definition"}))
 	if err != nil {
-		s.cfg.Logger.Warn("failed to retrieve definition chunks", "error", err)
+		s.cfg.Logger.WarnContext(ctx, "failed to retrieve definition chunks", "error", err)
 		return docs
 	}
 
@@ -176,7 +373,7 @@ func (s *QAService) retrieveArchSummaries(ctx context.Context, store storage.Sco
 		docs, err := store.SimilaritySearch(ctx, question, archResultLimit,
 			vectorstores.WithFilters(map[string]any{"chunk_type": "arch"}))
 		if err != nil {
-			s.cfg.Logger.Warn("failed to retrieve general arch summaries", "error", err)
+			s.cfg.Logger.WarnContext(ctx, "failed to retrieve general arch summaries", "error", err)
 			return nil
 		}
 		return docs
@@ -193,7 +390,7 @@ func (s *QAService) retrieveArchSummaries(ctx context.Context, store storage.Sco
 				"source":     path,
 			}))
 		if err != nil {
-			s.cfg.Logger.Warn("failed to retrieve arch summary for path", "path", path, "error", err)
+			s.cfg.Logger.WarnContext(ctx, "failed to retrieve arch summary for path", "path", path, "error", err)
 			continue
 		}
 		allArchDocs = append(allArchDocs, docs...)
@@ -219,7 +416,7 @@ func (s *QAService) extractPaths(question string) []string {
 // before passing to the generator. History is prepended to the question so the
 // model has conversational context even though the chain doesn't natively support it.
 func (s *QAService) answerWithValidation(ctx context.Context, retriever schema.Retriever, question string, history []string) (string, error) {
-	s.cfg.Logger.Debug("answering with validation")
+	s.cfg.Logger.DebugContext(ctx, "answering with validation")
 
 	questionWithHistory := question
 	if len(history) > 0 {
@@ -241,7 +438,7 @@ func (s *QAService) answerWithValidation(ctx context.Context, retriever schema.R
 		return "", fmt.Errorf("validating QA chain failed: %w", err)
 	}
 
-	s.cfg.Logger.Debug("answer with validation generated", "answer_len", len(answer))
+	s.cfg.Logger.DebugContext(ctx, "answer with validation generated", "answer_len", len(answer))
 	return answer, nil
 }
 
@@ -250,9 +447,9 @@ func (s *QAService) answerWithoutValidation(ctx context.Context, retriever schem
 		retriever,
 		s.cfg.GeneratorLLM,
 		chains.WithPromptBuilder(func(q string, docs []schema.Document) (string, error) {
-			s.cfg.Logger.Debug("retrieved docs for question", "count", len(docs))
+			s.cfg.Logger.DebugContext(ctx, "retrieved docs for question", "count", len(docs))
 			for i, doc := range docs {
-				s.cfg.Logger.Debug("retrieved doc metadata", "idx", i, "source", doc.Metadata["source"])
+				s.cfg.Logger.DebugContext(ctx, "retrieved doc metadata", "idx", i, "source", doc.Metadata["source"])
 			}
 
 			contextString := s.cfg.ContextFormat(docs)
@@ -273,6 +470,24 @@ func (s *QAService) answerWithoutValidation(ctx context.Context, retriever schem
 		return "", fmt.Errorf("QA chain failed: %w", err)
 	}
 
-	s.cfg.Logger.Debug("answer without validation generated", "answer_len", len(answer))
+	s.cfg.Logger.DebugContext(ctx, "answer without validation generated", "answer_len", len(answer))
 	return answer, nil
 }
+
+// SummarizeConversation condenses turns of a conversation, given as
+// "User: ..." and "AI: ..." lines, into a short summary that extends summary,
+// the summary of the turns before them.
+func (s *QAService) SummarizeConversation(ctx context.Context, summary string, turns []string) (string, error) {
+	prompt, err := s.cfg.PromptMgr.Render(llm.ConversationSummaryPrompt, map[string]string{
+		"Summary":      summary,
+		"Conversation": strings.Join(turns, "\n"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render conversation summary prompt: %w", err)
+	}
+	out, err := llms.GenerateFromSinglePrompt(ctx, s.cfg.GeneratorLLM, prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize conversation: %w", err)
+	}
+	return strings.TrimSpace(out), nil
+}
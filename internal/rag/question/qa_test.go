@@ -6,10 +6,12 @@ import (
 	"testing"
 
 	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/llm"
 	"github.com/sevigo/code-warden/mocks"
 )
@@ -44,13 +46,20 @@ func TestAnswerQuestion(t *testing.T) {
 	// First call: arch summaries retrieval using question for relevance
 	mockSVS.EXPECT().SimilaritySearch(gomock.Any(), question, gomock.Any(), gomock.Any()).Return([]schema.Document{}, nil)
 	// Second call: actual similarity search for the question
-	mockSVS.EXPECT().SimilaritySearch(gomock.Any(), question, gomock.Any(), gomock.Any()).Return([]schema.Document{{PageContent: "doc1"}}, nil)
+	mockSVS.EXPECT().SimilaritySearchWithScores(gomock.Any(), question, gomock.Any(), gomock.Any()).Return([]vectorstores.DocumentWithScore{
+		{Document: schema.Document{PageContent: "doc1", Metadata: map[string]any{"source": "a.go", "line": 3, "end_line": 9}}, Score: 0.5},
+		{Document: schema.Document{PageContent: "doc2", Metadata: map[string]any{"source": "b.go", "line": 1, "end_line": 4}}, Score: 0.9},
+	}, nil)
 
 	mockLLM.EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return("The answer", nil)
 
 	ans, err := svc.AnswerQuestion(context.Background(), collection, model, question, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, "The answer", ans)
+	assert.Equal(t, "The answer", ans.Text)
+	assert.Equal(t, []core.Citation{
+		{Source: "b.go", LineStart: 1, LineEnd: 4, Score: 0.9},
+		{Source: "a.go", LineStart: 3, LineEnd: 9, Score: 0.5},
+	}, ans.Citations)
 }
 
 func TestAnswerWithValidation(t *testing.T) {
@@ -85,12 +94,79 @@ func TestAnswerWithValidation(t *testing.T) {
 	// First call: arch summaries retrieval using question for relevance
 	mockSVS.EXPECT().SimilaritySearch(gomock.Any(), question, gomock.Any(), gomock.Any()).Return([]schema.Document{}, nil)
 	// Second call: actual similarity search for the question
-	mockSVS.EXPECT().SimilaritySearch(gomock.Any(), question, gomock.Any(), gomock.Any()).Return([]schema.Document{{PageContent: "relevant doc"}}, nil)
+	mockSVS.EXPECT().SimilaritySearchWithScores(gomock.Any(), question, gomock.Any(), gomock.Any()).Return([]vectorstores.DocumentWithScore{{Document: schema.Document{PageContent: "relevant doc"}}}, nil)
 
 	mockValLLM.EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return("yes", nil)
 	mockGenLLM.EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return("Final Answer", nil)
 
 	ans, err := svc.AnswerQuestion(context.Background(), collection, model, question, nil)
 	assert.NoError(t, err)
-	assert.Equal(t, "Final Answer", ans)
+	assert.Equal(t, "Final Answer", ans.Text)
+	assert.Empty(t, ans.Citations, "chunks without a source are not cited")
+}
+
+func TestHybridRetrieverCitations(t *testing.T) {
+	r := &hybridRetriever{retrieved: []vectorstores.DocumentWithScore{
+		{Document: schema.Document{Metadata: map[string]any{"source": "internal/auth", "chunk_type": "arch"}}},
+		{Document: schema.Document{Metadata: map[string]any{"source": "auth.go", "line": float64(10), "end_line": float64(20), "chunk_type": "code"}}, Score: 0.7},
+		{Document: schema.Document{Metadata: map[string]any{"source": "auth.go", "line": float64(10), "end_line": float64(20), "chunk_type": "definition"}}, Score: 0.6},
+	}}
+	assert.Equal(t, []core.Citation{
+		{Source: "auth.go", LineStart: 10, LineEnd: 20, Score: 0.7},
+		{Source: "internal/auth"},
+	}, r.citations())
+}
+
+func TestAnswerQuestionAcross(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockVS := mocks.NewMockVectorStore(ctrl)
+	apiStore := mocks.NewMockScopedVectorStore(ctrl)
+	webStore := mocks.NewMockScopedVectorStore(ctrl)
+	mockLLM := mocks.NewMockModel(ctrl)
+
+	pm, err := llm.NewPromptManager()
+	require.NoError(t, err)
+
+	var sources []string
+	svc := NewService(Config{
+		VectorStore:  mockVS,
+		GeneratorLLM: mockLLM,
+		PromptMgr:    pm,
+		Logger:       slog.Default(),
+		ContextFormat: func(docs []schema.Document) string {
+			for _, doc := range docs {
+				sources = append(sources, doc.Metadata["source"].(string))
+			}
+			return "some context"
+		},
+	})
+
+	question := "How do the services authenticate?"
+	model := "model"
+
+	mockVS.EXPECT().ForRepo("api-coll", model).Return(apiStore)
+	mockVS.EXPECT().ForRepo("web-coll", model).Return(webStore)
+	apiStore.EXPECT().SimilaritySearch(gomock.Any(), question, gomock.Any(), gomock.Any()).Return(nil, nil)
+	webStore.EXPECT().SimilaritySearch(gomock.Any(), question, gomock.Any(), gomock.Any()).Return(nil, nil)
+	apiStore.EXPECT().SimilaritySearchWithScores(gomock.Any(), question, gomock.Any(), gomock.Any()).Return([]vectorstores.DocumentWithScore{
+		{Document: schema.Document{PageContent: "jwt", Metadata: map[string]any{"source": "auth/jwt.go", "line": 3, "end_line": 9}}, Score: 0.6},
+	}, nil)
+	webStore.EXPECT().SimilaritySearchWithScores(gomock.Any(), question, gomock.Any(), gomock.Any()).Return([]vectorstores.DocumentWithScore{
+		{Document: schema.Document{PageContent: "login", Metadata: map[string]any{"source": "src/login.ts", "line": 1, "end_line": 4}}, Score: 0.8},
+	}, nil)
+	mockLLM.EXPECT().Call(gomock.Any(), gomock.Any(), gomock.Any()).Return("The answer", nil)
+
+	ans, err := svc.AnswerQuestionAcross(context.Background(), []core.RepoCollection{
+		{Repo: "acme/api", Collection: "api-coll"},
+		{Repo: "acme/web", Collection: "web-coll"},
+	}, model, question, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "The answer", ans.Text)
+	assert.Equal(t, []core.Citation{
+		{Repo: "acme/web", Source: "src/login.ts", LineStart: 1, LineEnd: 4, Score: 0.8},
+		{Repo: "acme/api", Source: "auth/jwt.go", LineStart: 3, LineEnd: 9, Score: 0.6},
+	}, ans.Citations)
+	assert.Equal(t, []string{"acme/web: src/login.ts", "acme/api: auth/jwt.go"}, sources)
 }
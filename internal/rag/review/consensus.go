@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -15,25 +14,26 @@ import (
 	"github.com/sevigo/code-warden/internal/core"
 	internalgithub "github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/rag/contextpkg"
 	"github.com/sevigo/code-warden/internal/storage"
 )
 
 // consensusMapFunc creates a map function for the MapReduceChain that generates a review with a specific model.
-func (s *Service) consensusMapFunc(event *core.GitHubEvent, promptData map[string]string, resultsTracker *[]ComparisonResult, mu *sync.Mutex, dir, ts string) func(ctx context.Context, modelName string) (ComparisonResult, error) {
+func (s *Service) consensusMapFunc(event *core.GitHubEvent, promptData map[string]string, resultsTracker *[]ComparisonResult, mu *sync.Mutex, ts string) func(ctx context.Context, modelName string) (ComparisonResult, error) {
 	return func(ctx context.Context, modelName string) (ComparisonResult, error) {
 		modelStart := time.Now()
 		llmModel, err := s.cfg.GetLLM(ctx, modelName)
 		if err != nil {
-			s.cfg.Logger.Warn("failed to get model for consensus", "model", modelName, "error", err)
+			s.cfg.Logger.WarnContext(ctx, "failed to get model for consensus", "model", modelName, "error", err)
 			return ComparisonResult{Model: modelName, Error: err}, nil
 		}
-		prompt, err := s.cfg.PromptMgr.Render(llm.CodeReviewPrompt, promptData)
+		prompt, err := s.cfg.PromptMgr.Render(s.choosePrompt(reviewPrompt(event), event), promptData)
 		if err != nil {
-			s.cfg.Logger.Warn("failed to render prompt for model", "model", modelName, "error", err)
+			s.cfg.Logger.WarnContext(ctx, "failed to render prompt for model", "model", modelName, "error", err)
 			return ComparisonResult{Model: modelName, Error: err}, nil
 		}
 		timeout := s.getConsensusTimeout()
-		tCtx, cancel := context.WithTimeout(ctx, timeout)
+		tCtx, cancel := context.WithTimeout(core.WithUsageStep(ctx, core.UsageStepConsensus), timeout)
 		defer cancel()
 
 		resp, err := llmModel.Call(tCtx, prompt)
@@ -46,16 +46,16 @@ func (s *Service) consensusMapFunc(event *core.GitHubEvent, promptData map[strin
 
 		// Save artifact immediately so we don't miss late arrivals
 		if err == nil && strings.TrimSpace(resp) != "" {
-			SaveReviewArtifact(s.cfg.Logger, dir, result, event, ts)
+			SaveReviewArtifact(ctx, s.cfg.Logger, s.cfg.Artifacts, result, event, ts)
 		}
 
 		if err != nil {
-			s.cfg.Logger.Warn("model review failed",
+			s.cfg.Logger.WarnContext(ctx, "model review failed",
 				"model", modelName,
 				"error", err,
 				"duration", modelTime.String())
 		} else {
-			s.cfg.Logger.Info("model review completed",
+			s.cfg.Logger.InfoContext(ctx, "model review completed",
 				"model", modelName,
 				"review_len", len(resp),
 				"duration", modelTime.String())
@@ -64,31 +64,31 @@ func (s *Service) consensusMapFunc(event *core.GitHubEvent, promptData map[strin
 	}
 }
 
-func (s *Service) consensusReduceFunc(repoConfig *core.RepoConfig, event *core.GitHubEvent, contextString string, changedFiles []internalgithub.ChangedFile, contextBuildTime time.Duration, reviewsDir string) func(ctx context.Context, results []ComparisonResult) (string, error) {
+func (s *Service) consensusReduceFunc(repoConfig *core.RepoConfig, event *core.GitHubEvent, contextString string, changedFiles []internalgithub.ChangedFile, contextBuildTime time.Duration) func(ctx context.Context, results []ComparisonResult) (string, error) {
 	return func(ctx context.Context, results []ComparisonResult) (string, error) {
-		s.cfg.Logger.Info("quorum reached, starting consensus synthesis",
+		s.cfg.Logger.InfoContext(ctx, "quorum reached, starting consensus synthesis",
 			"models_participating", len(results),
 			"models", getSuccessfulModels(results))
 		synthStart := time.Now()
-		rawConsensus, validReviews, err := s.synthesizeConsensus(ctx, repoConfig, event, results, contextString, changedFiles, contextBuildTime, reviewsDir)
+		rawConsensus, validReviews, err := s.synthesizeConsensus(core.WithUsageStep(ctx, core.UsageStepSynthesis), repoConfig, event, results, contextString, changedFiles, contextBuildTime)
 		synthTime := time.Since(synthStart)
 
 		if err != nil {
 			// Graceful degradation: if synthesis fails, use the best available review
-			s.cfg.Logger.Warn("consensus synthesis failed, falling back to best single review",
+			s.cfg.Logger.WarnContext(ctx, "consensus synthesis failed, falling back to best single review",
 				"error", err,
 				"synthesis_time", synthTime.String())
 
 			fallbackReview, fallbackModel := s.selectBestReview(results)
 			if fallbackReview != "" {
-				s.cfg.Logger.Info("using fallback review", "model", fallbackModel, "review_len", len(fallbackReview))
+				s.cfg.Logger.InfoContext(ctx, "using fallback review", "model", fallbackModel, "review_len", len(fallbackReview))
 				fallbackDisclaimer := fmt.Sprintf("\n\n> ⚠️ **Fallback Mode**\n> Consensus synthesis failed. Using review from: %s.\n> *Mistakes are possible. Please verify critical issues.*", fallbackModel)
 				return fallbackReview + fallbackDisclaimer, nil
 			}
 			return "", fmt.Errorf("consensus synthesis failed and no valid reviews available: %w", err)
 		}
 
-		s.cfg.Logger.Info("consensus synthesis completed",
+		s.cfg.Logger.InfoContext(ctx, "consensus synthesis completed",
 			"valid_reviews", len(validReviews),
 			"synthesis_time", synthTime.String())
 
@@ -112,22 +112,40 @@ func (s *Service) GenerateConsensusReview(ctx context.Context, repoConfig *core.
 		return nil, "", fmt.Errorf("need at least 1 comparison model, got %d", len(models))
 	}
 
+	core.ReportReviewStage(ctx, core.StageRetrieving, 0, 0)
+	degraded, err := s.checkVectorStore(ctx, repo, event)
+	if err != nil {
+		return nil, "", err
+	}
+
 	// Use context builder with impact tracking for profile calculation
-	contextResult := s.cfg.BuildContextWithImpact(ctx, repo.QdrantCollectionName, s.cfg.EmbedderModel, repo.ClonePath, changedFiles, event.PRTitle+"\n"+event.PRBody)
+	retrieval := s.retrievalFor(repoConfig, changedFiles)
+	contextResult := &contextpkg.ContextResult{}
+	if !degraded {
+		contextResult = s.cfg.BuildContextWithImpact(ctx, repo.QdrantCollectionName, s.embedderModel(repo), repo.ClonePath, s.redactPatches(repoConfig, event, changedFiles), event.PRTitle+"\n"+event.PRBody, retrieval.RetrievalSettings)
+		if degraded, err = s.checkRetrieval(contextResult, event); err != nil {
+			return nil, "", err
+		}
+	}
 	contextString := contextResult.FullContext
 	definitionsContext := contextResult.DefinitionsContext
 	impactRadius := contextResult.ImpactRadius
 
 	// Detect duplications by generating embeddings for the exact added lines
-	if dupCtx := s.checkCodeDuplication(ctx, repo.QdrantCollectionName, changedFiles); dupCtx != "" {
-		contextString += "\n\n" + dupCtx
+	if !degraded {
+		if dupCtx := s.checkCodeDuplication(ctx, repo, changedFiles); dupCtx != "" {
+			contextString += "\n\n" + dupCtx
+		}
 	}
 
+	promptDiff := diff
+	s.redactSecrets(repoConfig, event, &promptDiff, &contextString, &definitionsContext)
+
 	contextBuildTime := time.Since(startTime)
 
-	s.cfg.Logger.Info("stage started", "name", "ConsensusGathering", "models_count", len(models),
+	s.cfg.Logger.InfoContext(ctx, "stage started", "name", "ConsensusGathering", "models_count", len(models),
 		"context_build_time", contextBuildTime.String())
-	s.cfg.Logger.Debug("consensus context gathered",
+	s.cfg.Logger.DebugContext(ctx, "consensus context gathered",
 		"context_len", len(contextString),
 		"definitions_len", len(definitionsContext),
 		"impact_radius", impactRadius,
@@ -136,7 +154,7 @@ func (s *Service) GenerateConsensusReview(ctx context.Context, repoConfig *core.
 	// Warn if no context was retrieved
 	contextWasEmpty := contextIsEmpty(contextString, definitionsContext)
 	if contextWasEmpty {
-		s.cfg.Logger.Warn("HIGH HALLUCINATION RISK: no context retrieved from vector store - consensus review will be based solely on diff",
+		s.cfg.Logger.WarnContext(ctx, "HIGH HALLUCINATION RISK: no context retrieved from vector store - consensus review will be based solely on diff",
 			"repo", event.RepoFullName,
 			"pr", event.PRNumber,
 			"changed_files", len(changedFiles),
@@ -152,7 +170,7 @@ func (s *Service) GenerateConsensusReview(ctx context.Context, repoConfig *core.
 	docsOnly := core.IsDocsOnly(changedFilePaths)
 	complexity := core.CalculateProfile(linesAdded, linesDeleted, len(changedFiles), impactRadius, testCoverage, docsOnly, changedFilePaths)
 
-	s.cfg.Logger.Info("consensus review profile calculated",
+	s.cfg.Logger.InfoContext(ctx, "consensus review profile calculated",
 		"profile", complexity.Profile,
 		"score", complexity.Score,
 		"impact_radius", complexity.ImpactRadius,
@@ -162,31 +180,28 @@ func (s *Service) GenerateConsensusReview(ctx context.Context, repoConfig *core.
 
 	// Prepare for artifact saving
 	timestamp := time.Now().Format("20060102_150405_000000000")
-	reviewsDir := filepath.Join(filepath.Dir(repo.ClonePath), "reviews")
-	if err := EnsureReviewsDir(s.cfg.Logger, reviewsDir); err != nil {
-		s.cfg.Logger.Warn("failed to ensure reviews directory, artifacts might not be saved", "error", err)
-	}
 
 	// Render profile instruction for consensus
 	profileInstruction, err := s.cfg.PromptMgr.Render("review_profile", complexity)
 	if err != nil {
-		s.cfg.Logger.Warn("failed to render review profile for consensus, using default", "error", err)
+		s.cfg.Logger.WarnContext(ctx, "failed to render review profile for consensus, using default", "error", err)
 		profileInstruction = ""
 	}
 
-	promptData := s.buildReviewPromptDataWithProfile(event, repoConfig, contextString, definitionsContext, diff, changedFiles, profileInstruction)
+	promptData := s.buildReviewPromptDataWithProfile(event, repoConfig, contextString, definitionsContext, promptDiff, changedFiles, profileInstruction)
 
 	// Track model results for fallback
 	var modelResults []ComparisonResult
 	var modelResultsMu sync.Mutex
 
 	chain := chains.NewMapReduceChain(
-		s.consensusMapFunc(event, promptData, &modelResults, &modelResultsMu, reviewsDir, timestamp),
-		s.consensusReduceFunc(repoConfig, event, contextString, changedFiles, contextBuildTime, reviewsDir),
-		chains.WithMaxConcurrency[string, ComparisonResult, string](2),
+		s.consensusMapFunc(event, promptData, &modelResults, &modelResultsMu, timestamp),
+		s.consensusReduceFunc(repoConfig, event, contextString, changedFiles, contextBuildTime),
+		chains.WithMaxConcurrency[string, ComparisonResult, string](s.getConsensusMaxWorkers()),
 		chains.WithQuorum[string, ComparisonResult, string](s.cfg.ConsensusQuorum),
 	)
 
+	core.ReportReviewStage(ctx, core.StageGenerating, 0, 0)
 	rawConsensus, err := chain.Call(ctx, models)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to gather consensus reviews: %w", err)
@@ -195,21 +210,25 @@ func (s *Service) GenerateConsensusReview(ctx context.Context, repoConfig *core.
 	parser := NewStructuredReviewParser(s.cfg.Logger)
 	structuredReview, err := parser.Parse(ctx, rawConsensus)
 	if err != nil {
-		s.cfg.Logger.Error("FATAL: failed to parse consensus review - final report structure is broken. Check LLM output for tagging errors.", "error", err, "pr", event.PRNumber)
+		s.cfg.Logger.ErrorContext(ctx, "FATAL: failed to parse consensus review - final report structure is broken. Check LLM output for tagging errors.", "error", err, "pr", event.PRNumber)
 		structuredReview = &core.StructuredReview{Summary: rawConsensus}
 	} else {
 		if err := s.validateStructuredReview(ctx, event, structuredReview); err != nil {
 			return nil, "", err
 		}
 		// Add disclaimer to summary if context was empty (mirroring GenerateReview)
-		if contextWasEmpty {
+		switch {
+		case degraded:
+			structuredReview.Summary = degradedNote + structuredReview.Summary
+		case contextWasEmpty:
 			structuredReview.Summary = "**Note:** This consensus review was generated without repository context. Verify findings against actual codebase.\n\n" + structuredReview.Summary
 		}
 	}
 
 	successfulModels := getSuccessfulModels(modelResults)
+	structuredReview.ModelRatings = normalizeModelRatings(structuredReview.ModelRatings, successfulModels)
 	totalTime := time.Since(startTime)
-	s.cfg.Logger.Info("consensus review completed",
+	s.cfg.Logger.InfoContext(ctx, "consensus review completed",
 		"total_time", totalTime.String(),
 		"models_requested", len(models),
 		"models_eventually_succeeded", len(successfulModels),
@@ -237,7 +256,31 @@ func (s *Service) GenerateConsensusReview(ctx context.Context, repoConfig *core.
 	// Add profile metadata to consensus result
 	structuredReview.ReviewProfile = string(complexity.Profile)
 	structuredReview.ComplexityScore = complexity.Score
+	if !degraded {
+		structuredReview.Retrieval = &retrieval
+	}
+	structuredReview.Degraded = degraded
 	structuredReview.ImpactRadius = complexity.ImpactRadius
+	structuredReview.Model = "consensus:" + modelsList
+	structuredReview.PromptVersion = s.promptVersion(llm.ConsensusReviewPrompt)
+	// The per-model review prompt is archived; Output is the synthesis of
+	// the models' answers to it.
+	modelPrompt := s.choosePrompt(reviewPrompt(event), event)
+	structuredReview.Inputs = []core.PromptInputs{{
+		PromptKey:     string(modelPrompt),
+		PromptVersion: s.promptVersion(modelPrompt),
+		Model:         modelsList,
+		ReviewProfile: structuredReview.ReviewProfile,
+		Data:          promptData,
+		Output:        rawConsensus,
+	}}
+	if event.Type == core.SecurityReview {
+		structuredReview.Title = securityReviewTitle
+	}
+	s.checkCommits(structuredReview, repoConfig, event)
+	s.findMissingTests(ctx, structuredReview, repoConfig, reviewRepo(repo, degraded), event, changedFiles)
+	s.checkDependencies(ctx, structuredReview, repoConfig, event, changedFiles)
+	s.mergeStaticAnalysis(structuredReview, event, changedFiles)
 
 	return structuredReview, rawConsensus, nil
 }
@@ -255,6 +298,32 @@ func (s *Service) selectBestReview(results []ComparisonResult) (string, string)
 	return bestReview, bestModel
 }
 
+// normalizeModelRatings drops ratings for models that did not contribute a
+// review, keeps the first rating per model, clamps scores to 1-10 and orders
+// the result by score, best first.
+func normalizeModelRatings(ratings []core.ModelRating, models []string) []core.ModelRating {
+	known := make(map[string]bool, len(models))
+	for _, m := range models {
+		known[m] = true
+	}
+
+	var out []core.ModelRating
+	for _, r := range ratings {
+		r.Model = strings.TrimSpace(r.Model)
+		if !known[r.Model] {
+			continue
+		}
+		known[r.Model] = false
+		r.Score = min(max(r.Score, 1), 10)
+		r.Rationale = strings.TrimSpace(r.Rationale)
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Score > out[j].Score
+	})
+	return out
+}
+
 func getSuccessfulModels(results []ComparisonResult) []string {
 	var models []string
 	for _, res := range results {
@@ -271,14 +340,14 @@ func (s *Service) validateStructuredReview(ctx context.Context, event *core.GitH
 		return ctx.Err()
 	}
 	if review.Verdict == "" {
-		s.cfg.Logger.Warn("consensus review generated without a verdict", "pr", event.PRNumber)
+		s.cfg.Logger.WarnContext(ctx, "consensus review generated without a verdict", "pr", event.PRNumber)
 		review.Verdict = core.VerdictComment
 	}
 	if review.Summary == "" {
-		s.cfg.Logger.Warn("consensus review generated without a summary", "pr", event.PRNumber)
+		s.cfg.Logger.WarnContext(ctx, "consensus review generated without a summary", "pr", event.PRNumber)
 	}
 	if review.Verdict == core.VerdictRequestChanges && len(review.Suggestions) == 0 {
-		s.cfg.Logger.Error("CONSENSUS INCONSISTENCY: verdict is REQUEST_CHANGES but no suggestions were captured", "pr", event.PRNumber)
+		s.cfg.Logger.ErrorContext(ctx, "CONSENSUS INCONSISTENCY: verdict is REQUEST_CHANGES but no suggestions were captured", "pr", event.PRNumber)
 	}
 	return nil
 }
@@ -296,7 +365,7 @@ func (s *Service) validateConsensusParams(repo *storage.Repository, event *core.
 	return nil
 }
 
-func (s *Service) synthesizeConsensus(ctx context.Context, repoConfig *core.RepoConfig, event *core.GitHubEvent, results []ComparisonResult, context string, changedFiles []internalgithub.ChangedFile, contextBuildTime time.Duration, reviewsDir string) (string, []string, error) {
+func (s *Service) synthesizeConsensus(ctx context.Context, repoConfig *core.RepoConfig, event *core.GitHubEvent, results []ComparisonResult, context string, changedFiles []internalgithub.ChangedFile, contextBuildTime time.Duration) (string, []string, error) {
 	var validReviews []string
 	var reviewsBuilder strings.Builder
 	timestampStart := time.Now()
@@ -327,7 +396,7 @@ func (s *Service) synthesizeConsensus(ctx context.Context, repoConfig *core.Repo
 		"Reviews":            reviewsBuilder.String(),
 		"Context":            context,
 		"ChangedFiles":       formatChangedFiles(changedFiles),
-		"CustomInstructions": strings.Join(repoConfig.CustomInstructions, "\n"),
+		"CustomInstructions": customInstructions(repoConfig, changedFiles),
 	}
 
 	rawConsensus, err := s.generateResponseWithPrompt(ctx, event, llm.ConsensusReviewPrompt, promptData)
@@ -336,8 +405,13 @@ func (s *Service) synthesizeConsensus(ctx context.Context, repoConfig *core.Repo
 	}
 
 	totalSynthesisTime := time.Since(timestampStart)
-	s.cfg.Logger.Debug("consensus synthesis complete", "valid_reviews", len(validReviews), "duration", totalSynthesisTime.String())
+	s.cfg.Logger.DebugContext(ctx, "consensus synthesis complete", "valid_reviews", len(validReviews), "duration", totalSynthesisTime.String())
 
-	SaveConsensusArtifact(s.cfg.Logger, reviewsDir, rawConsensus, timestamp, event, totalSynthesisTime, validReviews, contextBuildTime)
+	SaveConsensusArtifact(ctx, s.cfg.Logger, s.cfg.Artifacts, rawConsensus, timestamp, event, totalSynthesisTime, validReviews, contextBuildTime)
+	if s.cfg.Artifacts != nil {
+		if _, err := s.cfg.Artifacts.Prune(ctx, event.RepoFullName); err != nil {
+			s.cfg.Logger.WarnContext(ctx, "failed to prune old review artifacts", "error", err)
+		}
+	}
 	return rawConsensus, validReviews, nil
 }
@@ -66,3 +66,21 @@ func TestValidateConsensusParams(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeModelRatings(t *testing.T) {
+	ratings := []core.ModelRating{
+		{Model: "llama", Score: 4, Rationale: " noisy "},
+		{Model: " qwen ", Score: 12, Rationale: "found the race"},
+		{Model: "hallucinated-model", Score: 10},
+		{Model: "qwen", Score: 1, Rationale: "duplicate"},
+		{Model: "gemma", Score: 0},
+	}
+
+	got := normalizeModelRatings(ratings, []string{"qwen", "llama", "gemma"})
+
+	assert.Equal(t, []core.ModelRating{
+		{Model: "qwen", Score: 10, Rationale: "found the race"},
+		{Model: "llama", Score: 4, Rationale: "noisy"},
+		{Model: "gemma", Score: 1},
+	}, got)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/sevigo/goframe/llms"
 	"github.com/sevigo/goframe/vectorstores"
 
+	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/llm"
 	"github.com/sevigo/code-warden/internal/storage"
 )
@@ -78,11 +79,11 @@ func (inv *Investigator) Investigate(
 	ctx context.Context,
 	collectionName, diff, mainContext, definitionsContext string,
 ) string {
-	inv.logger.Info("phase 2 started", "collection", collectionName)
+	inv.logger.InfoContext(ctx, "phase 2 started", "collection", collectionName)
 
 	fastLLM, err := inv.getLLM(ctx, inv.fastModel)
 	if err != nil {
-		inv.logger.Warn("phase 2 skipped: failed to get fast LLM", "model", inv.fastModel, "error", err)
+		inv.logger.WarnContext(ctx, "phase 2 skipped: failed to get fast LLM", "model", inv.fastModel, "error", err)
 		return ""
 	}
 
@@ -90,11 +91,11 @@ func (inv *Investigator) Investigate(
 
 	gaps, err := inv.identifyGaps(ctx, fastLLM, diff, mainContext, definitionsContext)
 	if err != nil {
-		inv.logger.Warn("phase 2 skipped: gap identification failed", "error", err)
+		inv.logger.WarnContext(ctx, "phase 2 skipped: gap identification failed", "error", err)
 		return ""
 	}
 	if len(gaps) == 0 {
-		inv.logger.Info("phase 2 completed: no gaps identified")
+		inv.logger.InfoContext(ctx, "phase 2 completed: no gaps identified")
 		return ""
 	}
 
@@ -102,7 +103,7 @@ func (inv *Investigator) Investigate(
 		gaps = gaps[:maxGapCalls]
 	}
 
-	inv.logger.Info("phase 2 executing gap queries", "count", len(gaps))
+	inv.logger.InfoContext(ctx, "phase 2 executing gap queries", "count", len(gaps))
 
 	var sections []string
 	totalChars := 0
@@ -112,7 +113,7 @@ func (inv *Investigator) Investigate(
 			sectionText := fmt.Sprintf("### Gap %d: %s\n%s", i+1, gap.Reason, result)
 			// Enforce character budget to prevent context overflow
 			if totalChars+len(sectionText) > maxPhase2Chars {
-				inv.logger.Info("phase 2: character budget exhausted, stopping early", "budget", maxPhase2Chars)
+				inv.logger.InfoContext(ctx, "phase 2: character budget exhausted, stopping early", "budget", maxPhase2Chars)
 				break
 			}
 			sections = append(sections, sectionText)
@@ -121,11 +122,11 @@ func (inv *Investigator) Investigate(
 	}
 
 	if len(sections) == 0 {
-		inv.logger.Info("phase 2 completed: queries returned no results")
+		inv.logger.InfoContext(ctx, "phase 2 completed: queries returned no results")
 		return ""
 	}
 
-	inv.logger.Info("phase 2 completed", "gaps_filled", len(sections), "total_chars", totalChars)
+	inv.logger.InfoContext(ctx, "phase 2 completed", "gaps_filled", len(sections), "total_chars", totalChars)
 	return "# Additional Context (Phase 2 Investigation)\n\n" + strings.Join(sections, "\n\n")
 }
 
@@ -146,7 +147,7 @@ func (inv *Investigator) identifyGaps(
 		return nil, fmt.Errorf("failed to render gap identification prompt: %w", err)
 	}
 
-	response, err := fastLLM.Call(ctx, prompt)
+	response, err := fastLLM.Call(core.WithUsageStep(ctx, core.UsageStepInvestigation), prompt)
 	if err != nil {
 		return nil, fmt.Errorf("fast LLM call failed: %w", err)
 	}
@@ -183,7 +184,7 @@ func (inv *Investigator) executeGap(ctx context.Context, store storage.ScopedVec
 	case "find_usages":
 		return inv.executeFindUsages(ctx, store, gap.Args)
 	default:
-		inv.logger.Warn("phase 2: unknown tool requested", "tool", gap.Tool)
+		inv.logger.WarnContext(ctx, "phase 2: unknown tool requested", "tool", gap.Tool)
 		return ""
 	}
 }
@@ -202,7 +203,7 @@ func (inv *Investigator) executeSearchCode(ctx context.Context, store storage.Sc
 
 	docs, err := store.SimilaritySearchWithScores(ctx, query, limit, opts...)
 	if err != nil {
-		inv.logger.Debug("phase 2 search_code failed", "query", query, "error", err)
+		inv.logger.DebugContext(ctx, "phase 2 search_code failed", "query", query, "error", err)
 		return ""
 	}
 
@@ -233,7 +234,7 @@ func (inv *Investigator) executeGetSymbol(ctx context.Context, store storage.Sco
 			vectorstores.WithFilters(map[string]any{"chunk_type": "definition"}),
 		)
 		if err != nil || len(docs) == 0 {
-			inv.logger.Debug("phase 2 get_symbol: not found", "name", name)
+			inv.logger.DebugContext(ctx, "phase 2 get_symbol: not found", "name", name)
 			return ""
 		}
 	}
@@ -258,7 +259,7 @@ func (inv *Investigator) executeFindUsages(ctx context.Context, store storage.Sc
 		}),
 	)
 	if err != nil {
-		inv.logger.Debug("phase 2 find_usages failed", "symbol", symbol, "error", err)
+		inv.logger.DebugContext(ctx, "phase 2 find_usages failed", "symbol", symbol, "error", err)
 		return ""
 	}
 
@@ -0,0 +1,60 @@
+package review
+
+import (
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+// patchContent returns the lines of patch as they read after the change,
+// the added and context lines without their diff markers.
+func patchContent(patch string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"), strings.HasPrefix(line, "@@"):
+		case strings.HasPrefix(line, "+"), strings.HasPrefix(line, " "):
+			sb.WriteString(line[1:])
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String()
+}
+
+// fileLanguages returns the detected language of each changed file with a
+// known language.
+func fileLanguages(changedFiles []internalgithub.ChangedFile) map[string]string {
+	languages := make(map[string]string, len(changedFiles))
+	for _, file := range changedFiles {
+		if lang := core.DetectLanguage(file.Filename, patchContent(file.Patch)); lang != "" {
+			languages[file.Filename] = lang
+		}
+	}
+	return languages
+}
+
+// languageBreakdown returns the languages of changedFiles, the largest share
+// of changed lines first.
+func languageBreakdown(changedFiles []internalgithub.ChangedFile) []core.LanguageShare {
+	var b core.LanguageBreakdownBuilder
+	for _, file := range changedFiles {
+		added, deleted := calculateLinesChanged([]internalgithub.ChangedFile{file})
+		b.Add(core.DetectLanguage(file.Filename, patchContent(file.Patch)), added+deleted)
+	}
+	return b.Build()
+}
+
+// promptLanguages returns the primary language of a change for the prompt,
+// the language of most changed lines or the repository's language when none
+// is known, and the breakdown of its languages when there is more than one.
+func promptLanguages(event *core.GitHubEvent, changedFiles []internalgithub.ChangedFile) (primary, breakdown string) {
+	shares := languageBreakdown(changedFiles)
+	if len(shares) == 0 {
+		return event.Language, ""
+	}
+	if len(shares) > 1 {
+		breakdown = core.FormatLanguageShares(shares)
+	}
+	return shares[0].Language, breakdown
+}
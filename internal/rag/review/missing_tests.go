@@ -0,0 +1,176 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sevigo/goframe/llms"
+	"github.com/sevigo/goframe/vectorstores"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/testgap"
+)
+
+const (
+	// maxMissingTestFiles limits how many files the missing tests stage asks
+	// the model about.
+	maxMissingTestFiles = 8
+	// maxMissingTestCases limits the suggested test cases per file.
+	maxMissingTestCases = 5
+	// maxTestPatterns limits the existing tests shown as examples.
+	maxTestPatterns = 3
+	// maxMissingTestPatch truncates each file's diff in the prompt.
+	maxMissingTestPatch = 4000
+)
+
+type missingTestsOutput struct {
+	MissingTests []core.MissingTest `json:"missing_tests"`
+}
+
+// findMissingTests adds the "Missing tests" section to a review: it pairs the
+// changed files with their tests by language convention and, for files whose
+// tests were not changed, asks the model for test cases in the style of the
+// repository's existing tests. Failures are logged and leave the review as is.
+func (s *Service) findMissingTests(ctx context.Context, review *core.StructuredReview, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, changedFiles []internalgithub.ChangedFile) {
+	if repoConfig.DisableMissingTests || event.Type == core.SecurityReview {
+		return
+	}
+	gaps := testgap.Find(changedFiles, func(path string) bool {
+		if repo.ClonePath == "" {
+			return false
+		}
+		_, err := os.Stat(filepath.Join(repo.ClonePath, filepath.FromSlash(path)))
+		return err == nil
+	})
+	if len(gaps) == 0 {
+		return
+	}
+	if len(gaps) > maxMissingTestFiles {
+		gaps = gaps[:maxMissingTestFiles]
+	}
+
+	files := formatTestGaps(gaps)
+	patterns := s.testPatterns(ctx, repo, gaps)
+	s.redactSecrets(repoConfig, event, &files, &patterns)
+
+	language, languages := promptLanguages(event, changedFiles)
+	prompt, err := s.cfg.PromptMgr.Render(llm.MissingTestsPrompt, map[string]string{
+		"Title":     event.PRTitle,
+		"Language":  language,
+		"Languages": languages,
+		"Files":     files,
+		"Patterns":  patterns,
+		"MaxCases":  strconv.Itoa(maxMissingTestCases),
+	})
+	if err != nil {
+		s.cfg.Logger.WarnContext(ctx, "missing tests stage skipped", "repo", event.RepoFullName, "error", err)
+		return
+	}
+	response, err := llms.GenerateFromSinglePrompt(ctx, s.cfg.GeneratorLLM, prompt)
+	if err != nil {
+		s.cfg.Logger.WarnContext(ctx, "missing tests stage failed", "repo", event.RepoFullName, "error", err)
+		return
+	}
+	suggestions, err := parseMissingTests(response)
+	if err != nil {
+		s.cfg.Logger.WarnContext(ctx, "missing tests stage failed", "repo", event.RepoFullName, "error", err)
+		return
+	}
+	review.MissingTests = matchMissingTests(gaps, suggestions)
+}
+
+// testPatterns retrieves existing tests similar to the changed code so the
+// suggestions follow the repository's test style.
+func (s *Service) testPatterns(ctx context.Context, repo *storage.Repository, gaps []testgap.Gap) string {
+	if s.cfg.VectorStore == nil || repo.QdrantCollectionName == "" {
+		return ""
+	}
+	scopedStore := s.cfg.VectorStore.ForRepo(repo.QdrantCollectionName, s.embedderModel(repo))
+
+	var sb strings.Builder
+	seen := make(map[string]bool)
+	for _, gap := range gaps {
+		docs, err := scopedStore.SimilaritySearch(ctx, gap.File+"\n"+truncateStr(gap.Patch, 1000), 2, vectorstores.WithFilter("is_test", true))
+		if err != nil {
+			s.cfg.Logger.DebugContext(ctx, "failed to search test patterns", "file", gap.File, "error", err)
+			continue
+		}
+		for _, doc := range docs {
+			source, _ := doc.Metadata["source"].(string)
+			if seen[source] || len(seen) == maxTestPatterns {
+				continue
+			}
+			seen[source] = true
+			fmt.Fprintf(&sb, "### %s\n```\n%s\n```\n\n", source, truncateStr(doc.PageContent, 1500))
+		}
+		if len(seen) == maxTestPatterns {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// formatTestGaps lists each gap with its test file and diff for the prompt.
+func formatTestGaps(gaps []testgap.Gap) string {
+	var sb strings.Builder
+	for _, gap := range gaps {
+		fmt.Fprintf(&sb, "### `%s`\nTest file: `%s` (%s)\n```diff\n%s\n```\n\n", gap.File, gap.TestFile, gap.Status, truncateStr(gap.Patch, maxMissingTestPatch))
+	}
+	return sb.String()
+}
+
+// parseMissingTests parses the model's JSON answer, tolerating code fences.
+func parseMissingTests(response string) ([]core.MissingTest, error) {
+	response = strings.TrimSpace(response)
+	if strings.HasPrefix(response, "```") {
+		if idx := strings.Index(response, "\n"); idx >= 0 {
+			response = response[idx+1:]
+		}
+		if idx := strings.LastIndex(response, "```"); idx >= 0 {
+			response = response[:idx]
+		}
+		response = strings.TrimSpace(response)
+	}
+
+	var output missingTestsOutput
+	if err := json.Unmarshal([]byte(response), &output); err != nil {
+		return nil, fmt.Errorf("failed to parse missing tests output: %w", err)
+	}
+	return output.MissingTests, nil
+}
+
+// matchMissingTests keeps the suggestions for files that have a gap, in diff
+// order, and takes the test file and its existence from the gap rather than
+// from the model.
+func matchMissingTests(gaps []testgap.Gap, suggestions []core.MissingTest) []core.MissingTest {
+	var result []core.MissingTest
+	for _, gap := range gaps {
+		i := slices.IndexFunc(suggestions, func(m core.MissingTest) bool { return m.File == gap.File })
+		if i < 0 {
+			continue
+		}
+		cases := slices.DeleteFunc(slices.Clone(suggestions[i].Cases), func(c string) bool { return strings.TrimSpace(c) == "" })
+		if len(cases) == 0 {
+			continue
+		}
+		if len(cases) > maxMissingTestCases {
+			cases = cases[:maxMissingTestCases]
+		}
+		result = append(result, core.MissingTest{
+			File:     gap.File,
+			TestFile: gap.TestFile,
+			Exists:   gap.Status == testgap.StatusNotUpdated,
+			Cases:    cases,
+		})
+	}
+	return result
+}
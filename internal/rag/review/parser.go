@@ -19,6 +19,9 @@ import (
 type StructuredReviewParser struct {
 	logger *slog.Logger
 	Raw    string
+	// PreferJSON tries the JSON protocol first and falls back to the XML and
+	// legacy parsers when the model emits malformed or invalid JSON.
+	PreferJSON bool
 }
 
 // NewStructuredReviewParser creates a new StructuredReviewParser.
@@ -28,11 +31,29 @@ func NewStructuredReviewParser(logger *slog.Logger) *StructuredReviewParser {
 
 // Parse extracts the structured review from the LLM output.
 func (p *StructuredReviewParser) Parse(ctx context.Context, outputStr string) (*core.StructuredReview, error) {
+	parsed, err := p.parse(ctx, outputStr)
+	if err != nil || parsed == nil {
+		return parsed, err
+	}
+	for i := range parsed.Suggestions {
+		parsed.Suggestions[i].CWE = core.NormalizeCWE(parsed.Suggestions[i].CWE)
+	}
+	return parsed, nil
+}
+
+func (p *StructuredReviewParser) parse(ctx context.Context, outputStr string) (*core.StructuredReview, error) {
 	p.Raw = outputStr
+	if p.PreferJSON {
+		parsed, err := llm.ParseJSONReview(outputStr)
+		if err == nil {
+			return parsed, nil
+		}
+		p.logger.WarnContext(ctx, "failed to parse JSON review, falling back to XML parser", "error", err)
+	}
 	xmlParser := output.NewXMLParser[*core.StructuredReview]("review")
 	parsed, err := xmlParser.Parse(ctx, outputStr)
 	if err != nil {
-		p.logger.Warn("failed to parse XML review, trying manual tag extraction", "error", err)
+		p.logger.WarnContext(ctx, "failed to parse XML review, trying manual tag extraction", "error", err)
 		return llm.ParseLegacyMarkdownReview(outputStr)
 	}
 	return parsed, nil
@@ -1,6 +1,8 @@
 package review
 
 import (
+	"context"
+	"log/slog"
 	"strings"
 	"testing"
 )
@@ -134,3 +136,76 @@ func TestSanitizeModelForFilename(t *testing.T) {
 		}
 	})
 }
+
+func TestStructuredReviewParser_JSONFallsBackToXML(t *testing.T) {
+	parser := NewStructuredReviewParser(slog.Default())
+	parser.PreferJSON = true
+
+	jsonOut := `{"verdict": "APPROVE", "confidence": 90, "summary": "Looks good.", "suggestions": []}`
+	review, err := parser.Parse(context.Background(), jsonOut)
+	if err != nil {
+		t.Fatalf("unexpected error parsing JSON review: %v", err)
+	}
+	if review.Verdict != "APPROVE" || review.Summary != "Looks good." {
+		t.Errorf("unexpected JSON review: %+v", review)
+	}
+
+	xmlOut := `<review><verdict>COMMENT</verdict><summary>From XML.</summary><suggestions></suggestions></review>`
+	review, err = parser.Parse(context.Background(), xmlOut)
+	if err != nil {
+		t.Fatalf("expected fallback to XML parser, got error: %v", err)
+	}
+	if review.Verdict != "COMMENT" || !strings.Contains(review.Summary, "From XML.") {
+		t.Errorf("unexpected fallback review: %+v", review)
+	}
+}
+
+func TestStructuredReviewParser_ModelRatings(t *testing.T) {
+	parser := NewStructuredReviewParser(slog.Default())
+
+	xmlOut := `<review>
+  <verdict>COMMENT</verdict>
+  <summary>Merged.</summary>
+  <suggestions></suggestions>
+  <model_ratings>
+    <rating><model>qwen</model><score>8</score><rationale>Accurate.</rationale></rating>
+    <rating><model>llama</model><score>3</score><rationale>Hallucinated a file.</rationale></rating>
+  </model_ratings>
+</review>`
+	review, err := parser.Parse(context.Background(), xmlOut)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(review.ModelRatings) != 2 {
+		t.Fatalf("expected 2 model ratings, got %+v", review.ModelRatings)
+	}
+	if r := review.ModelRatings[1]; r.Model != "llama" || r.Score != 3 || r.Rationale != "Hallucinated a file." {
+		t.Errorf("unexpected rating: %+v", r)
+	}
+}
+
+func TestStructuredReviewParser_CWE(t *testing.T) {
+	parser := NewStructuredReviewParser(slog.Default())
+
+	xmlOut := `<review>
+  <verdict>REQUEST_CHANGES</verdict>
+  <summary>SQL injection.</summary>
+  <suggestions>
+    <suggestion><file>db.go</file><line>12</line><severity>Critical</severity><category>Injection</category><cwe>cwe 89</cwe><comment>Query built from input.</comment></suggestion>
+    <suggestion><file>db.go</file><line>20</line><severity>Low</severity><category>Injection</category><cwe>n/a</cwe><comment>Unclear.</comment></suggestion>
+  </suggestions>
+</review>`
+	review, err := parser.Parse(context.Background(), xmlOut)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(review.Suggestions) != 2 {
+		t.Fatalf("expected 2 suggestions, got %+v", review.Suggestions)
+	}
+	if got := review.Suggestions[0].CWE; got != "CWE-89" {
+		t.Errorf("expected normalized CWE-89, got %q", got)
+	}
+	if got := review.Suggestions[1].CWE; got != "" {
+		t.Errorf("expected invalid CWE to be dropped, got %q", got)
+	}
+}
@@ -0,0 +1,86 @@
+package review
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sevigo/goframe/llms"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/llm"
+)
+
+// RegenerateReview sends the archived prompt inputs of a review again to
+// modelName, or to the generator model when it is empty. A non-empty
+// promptTemplate replaces the archived prompt template. It returns the
+// archived review and the regenerated one, parsed and filtered the same way
+// so that they can be compared.
+func (s *Service) RegenerateReview(ctx context.Context, inputs core.PromptInputs, modelName, promptTemplate string) (*core.StructuredReview, *core.StructuredReview, error) {
+	model, name, err := s.regenerationModel(ctx, modelName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var prompt, version string
+	if promptTemplate != "" {
+		prompt, err = llm.RenderTemplate(promptTemplate, inputs.Data)
+		version = llm.TemplateVersion(promptTemplate)
+	} else {
+		key := llm.PromptKey(inputs.PromptKey)
+		if key.IsExperiment() && s.cfg.PromptMgr.Version(key) == "" {
+			s.cfg.Logger.InfoContext(ctx, "prompt experiment of the review has ended, regenerating with its prompt", "prompt", key.Base())
+			key = key.Base()
+		}
+		prompt, err = s.cfg.PromptMgr.Render(key, inputs.Data)
+		version = s.promptVersion(key)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render prompt %s: %w", inputs.PromptKey, err)
+	}
+
+	s.cfg.Logger.InfoContext(ctx, "regenerating review", "prompt", inputs.PromptKey, "prompt_version", version, "model", name)
+	raw, err := model.Call(ctx, prompt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to regenerate review with %s: %w", name, err)
+	}
+
+	original := s.parseArchived(ctx, inputs, inputs.Output)
+	original.Model = inputs.Model
+	original.PromptVersion = inputs.PromptVersion
+	regenerated := s.parseArchived(ctx, inputs, raw)
+	regenerated.Model = name
+	regenerated.PromptVersion = version
+	return original, regenerated, nil
+}
+
+// regenerationModel returns the model called modelName, or the generator.
+func (s *Service) regenerationModel(ctx context.Context, modelName string) (llms.Model, string, error) {
+	if modelName == "" {
+		return s.cfg.GeneratorLLM, llm.ModelName(s.cfg.GeneratorLLM, s.cfg.GeneratorModel), nil
+	}
+	model, err := s.cfg.GetLLM(ctx, modelName)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load model %s: %w", modelName, err)
+	}
+	return model, modelName, nil
+}
+
+// parseArchived parses a raw answer to archived prompt inputs. Suggestions of
+// full reviews pass the filter of their review profile against the archived
+// diff, as in the original review. Unparsable answers become the summary.
+func (s *Service) parseArchived(ctx context.Context, inputs core.PromptInputs, raw string) *core.StructuredReview {
+	parser := NewStructuredReviewParser(s.cfg.Logger)
+	parser.PreferJSON = inputs.Data["OutputFormat"] == config.ReviewOutputJSON
+	review, err := parser.Parse(ctx, raw)
+	if err != nil || review == nil {
+		return &core.StructuredReview{Summary: raw, Suggestions: []core.Suggestion{}}
+	}
+	if diff := inputs.Data["Diff"]; diff != "" && inputs.ReviewProfile != "" {
+		validator := NewSuggestionValidator(diff, ParseDiff(diff))
+		filter := NewFilterForProfile(core.ReviewProfile(inputs.ReviewProfile))
+		review = filter.FilterAndRank(review, validator, s.cfg.Logger.Debug)
+	}
+	review.ReviewProfile = inputs.ReviewProfile
+	return review
+}
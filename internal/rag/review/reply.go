@@ -0,0 +1,111 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sevigo/goframe/llms"
+
+	"github.com/sevigo/code-warden/internal/applyfix"
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// ErrNoPatch is returned by ReplyToSuggestion when the model answered a
+// "/warden fix" without a code block to suggest.
+var ErrNoPatch = errors.New("model returned no patch")
+
+// ReplyToSuggestion answers a "/warden explain" or "/warden fix" reply, as
+// set by event.ReplyCommand, to the suggestion in thread. An explanation is
+// Markdown; a fix is a short rationale and a GitHub suggestion block that
+// replaces the lines of the thread. Without repo the reply is based on the
+// diff hunk alone.
+func (s *Service) ReplyToSuggestion(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, thread *core.SuggestionThread) (string, error) {
+	if repoConfig == nil {
+		repoConfig = core.DefaultRepoConfig()
+	}
+	key := llm.SuggestionExplainPrompt
+	if event.ReplyCommand == core.ReplyFix {
+		key = llm.SuggestionFixPrompt
+	}
+
+	suggestion := stripMarkers(thread.Suggestion)
+	hunk := thread.DiffHunk
+	code := strings.Join(applyfix.ThreadLines(thread.DiffHunk, thread.StartLine, thread.Line), "\n")
+	var contextString, definitionsContext string
+	if repo != nil {
+		changedFiles := []internalgithub.ChangedFile{{Filename: thread.Path, Patch: thread.DiffHunk}}
+		retrieval := s.retrievalFor(repoConfig, changedFiles)
+		contextResult := s.cfg.BuildContextWithImpact(ctx, repo.QdrantCollectionName, s.embedderModel(repo), repo.ClonePath, s.redactPatches(repoConfig, event, changedFiles), suggestion, retrieval.RetrievalSettings)
+		contextString = contextResult.FullContext
+		definitionsContext = contextResult.DefinitionsContext
+	}
+	s.redactSecrets(repoConfig, event, &hunk, &code, &contextString, &definitionsContext)
+
+	lines := strconv.Itoa(thread.Line)
+	if thread.StartLine > 0 && thread.StartLine < thread.Line {
+		lines = fmt.Sprintf("%d-%d", thread.StartLine, thread.Line)
+	}
+	prompt, err := s.cfg.PromptMgr.Render(key, map[string]string{
+		"Title":        event.PRTitle,
+		"Language":     event.Language,
+		"Path":         thread.Path,
+		"Lines":        lines,
+		"Suggestion":   suggestion,
+		"DiffHunk":     hunk,
+		"Code":         code,
+		"Context":      contextString,
+		"Definitions":  definitionsContext,
+		"Instructions": event.UserInstructions,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	response, err := llms.GenerateFromSinglePrompt(ctx, s.cfg.GeneratorLLM, prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate %s reply: %w", event.ReplyCommand, err)
+	}
+	if event.ReplyCommand != core.ReplyFix {
+		return strings.TrimSpace(response), nil
+	}
+	return formatFixReply(response)
+}
+
+// formatFixReply turns the first fenced code block of response into a
+// GitHub suggestion block, keeping the text before it as the rationale.
+func formatFixReply(response string) (string, error) {
+	start := strings.Index(response, "```")
+	if start < 0 {
+		return "", ErrNoPatch
+	}
+	rationale := strings.TrimSpace(response[:start])
+	rest := response[start+3:]
+	// Skip the info string, e.g. "go" in ```go.
+	nl := strings.Index(rest, "\n")
+	end := strings.Index(rest, "```")
+	if nl < 0 || end < nl {
+		return "", ErrNoPatch
+	}
+	code := strings.TrimRight(rest[nl+1:end], "\n")
+
+	var sb strings.Builder
+	if rationale != "" {
+		sb.WriteString(rationale + "\n\n")
+	}
+	sb.WriteString("```suggestion\n" + code + "\n```")
+	return sb.String(), nil
+}
+
+// stripMarkers drops the hidden Code-Warden markers from a posted comment.
+func stripMarkers(body string) string {
+	if i := strings.Index(body, "<!-- code-warden:"); i >= 0 {
+		body = body[:i]
+	}
+	return strings.TrimSpace(body)
+}
@@ -0,0 +1,86 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/rag/contextpkg"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+func TestReplyToSuggestion(t *testing.T) {
+	promptMgr, err := llm.NewPromptManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	thread := &core.SuggestionThread{
+		Path:       "internal/store/repos.go",
+		StartLine:  11,
+		Line:       12,
+		Suggestion: "The error is dropped.\n\n<!-- code-warden:suggestion model=m -->",
+		DiffHunk:   "@@ -8,3 +8,5 @@ func Load() {\n \tdb := open()\n-\tdb.Ping()\n+\trows, _ := db.Query(q)\n+\tdefer rows.Close()",
+	}
+	newService := func(model *recordingModel) *Service {
+		return NewService(Config{
+			PromptMgr:    promptMgr,
+			GeneratorLLM: model,
+			Logger:       slog.Default(),
+			BuildContextWithImpact: func(_ context.Context, _, _, _ string, _ []internalgithub.ChangedFile, _ string, _ core.RetrievalSettings) *contextpkg.ContextResult {
+				return &contextpkg.ContextResult{FullContext: "internal/store: database access"}
+			},
+		})
+	}
+
+	t.Run("explain", func(t *testing.T) {
+		model := &recordingModel{reply: "  Query errors are lost, so callers see empty results.\n"}
+		event := &core.GitHubEvent{ReplyCommand: core.ReplyExplain, UserInstructions: "why not log it?"}
+		got, err := newService(model).ReplyToSuggestion(context.Background(), nil, &storage.Repository{}, event, thread)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "Query errors are lost, so callers see empty results." {
+			t.Errorf("unexpected reply %q", got)
+		}
+		for _, want := range []string{"lines 11-12", "The error is dropped.", "internal/store: database access", "why not log it?"} {
+			if !strings.Contains(model.prompt, want) {
+				t.Errorf("prompt is missing %q", want)
+			}
+		}
+		if strings.Contains(model.prompt, "code-warden:suggestion") {
+			t.Error("prompt must not contain the feedback marker")
+		}
+	})
+
+	t.Run("fix", func(t *testing.T) {
+		model := &recordingModel{reply: "Return the error.\n\n```go\n\trows, err := db.Query(q)\n\tif err != nil {\n\t\treturn err\n\t}\n```\n"}
+		event := &core.GitHubEvent{ReplyCommand: core.ReplyFix}
+		got, err := newService(model).ReplyToSuggestion(context.Background(), nil, nil, event, thread)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "Return the error.\n\n```suggestion\n\trows, err := db.Query(q)\n\tif err != nil {\n\t\treturn err\n\t}\n```"
+		if got != want {
+			t.Errorf("reply = %q, want %q", got, want)
+		}
+		if !strings.Contains(model.prompt, "```\n\trows, _ := db.Query(q)\n\tdefer rows.Close()\n```") {
+			t.Errorf("prompt does not hold the lines to replace:\n%s", model.prompt)
+		}
+		if !strings.Contains(model.prompt, "No architectural context available") {
+			t.Error("a reply without repository must not retrieve context")
+		}
+	})
+
+	t.Run("fix without a code block", func(t *testing.T) {
+		model := &recordingModel{reply: "I cannot fix this without more context."}
+		event := &core.GitHubEvent{ReplyCommand: core.ReplyFix}
+		if _, err := newService(model).ReplyToSuggestion(context.Background(), nil, nil, event, thread); !errors.Is(err, ErrNoPatch) {
+			t.Errorf("error = %v, want ErrNoPatch", err)
+		}
+	})
+}
@@ -44,37 +44,46 @@ func getDocContent(doc schema.Document) string {
 
 // GenerateReReview generates a follow-up review by comparing the new diff
 // against the original review's suggestions, using feedback-driven retrieval.
-func (s *Service) GenerateReReview(ctx context.Context, repo *storage.Repository, event *core.GitHubEvent, originalReview *core.Review, ghClient internalgithub.Client, changedFiles []internalgithub.ChangedFile) (*core.StructuredReview, string, error) {
-	s.cfg.Logger.Info("preparing data for a re-review", "repo", event.RepoFullName, "pr", event.PRNumber)
+// repoConfig may be nil.
+func (s *Service) GenerateReReview(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, originalReview *core.Review, ghClient internalgithub.Client, changedFiles []internalgithub.ChangedFile) (*core.StructuredReview, string, error) {
+	if repoConfig == nil {
+		repoConfig = core.DefaultRepoConfig()
+	}
+	s.cfg.Logger.InfoContext(ctx, "preparing data for a re-review", "repo", event.RepoFullName, "pr", event.PRNumber)
 
 	newDiff, err := ghClient.GetPullRequestDiff(ctx, event.RepoOwner, event.RepoName, event.PRNumber)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to get new PR diff: %w", err)
 	}
 	if strings.TrimSpace(newDiff) == "" {
-		s.cfg.Logger.Info("no new code changes found to re-review", "pr", event.PRNumber)
+		s.cfg.Logger.InfoContext(ctx, "no new code changes found to re-review", "pr", event.PRNumber)
 		return &core.StructuredReview{
 			Summary: "This pull request contains no new code changes to re-review.",
 		}, "This pull request contains no new code changes to re-review.", nil
 	}
 
 	// Build standard context
-	contextResult := s.cfg.BuildContextWithImpact(ctx, repo.QdrantCollectionName, s.cfg.EmbedderModel, repo.ClonePath, changedFiles, buildPRDescription(event))
+	core.ReportReviewStage(ctx, core.StageRetrieving, 0, 0)
+	retrieval := s.retrievalFor(repoConfig, changedFiles)
+	contextResult := s.cfg.BuildContextWithImpact(ctx, repo.QdrantCollectionName, s.embedderModel(repo), repo.ClonePath, s.redactPatches(repoConfig, event, changedFiles), buildPRDescription(event), retrieval.RetrievalSettings)
 	standardContext := contextResult.FullContext
 	definitionsContext := contextResult.DefinitionsContext
 
 	// Extract search queries from original review
 	feedbackQueries := s.extractCommentsFromReview(ctx, originalReview.ReviewContent)
-	s.cfg.Logger.Info("extracted feedback-driven search queries", "count", len(feedbackQueries))
+	s.cfg.Logger.InfoContext(ctx, "extracted feedback-driven search queries", "count", len(feedbackQueries))
 
 	// Feedback-driven searches
-	feedbackContext := s.buildFeedbackDrivenContext(ctx, repo.QdrantCollectionName, s.cfg.EmbedderModel, feedbackQueries, event.UserInstructions)
+	feedbackContext := s.buildFeedbackDrivenContext(ctx, repo.QdrantCollectionName, s.embedderModel(repo), feedbackQueries, event.UserInstructions)
 
 	// Combine contexts
 	combinedContext := s.combineReReviewContext(standardContext, feedbackContext)
+	s.redactSecrets(repoConfig, event, &newDiff, &combinedContext, &definitionsContext)
 
+	language, languages := promptLanguages(event, changedFiles)
 	promptData := core.ReReviewData{
-		Language:         event.Language,
+		Language:         language,
+		Languages:        languages,
 		OriginalReview:   originalReview.ReviewContent,
 		NewDiff:          newDiff,
 		UserInstructions: event.UserInstructions,
@@ -82,7 +91,9 @@ func (s *Service) GenerateReReview(ctx context.Context, repo *storage.Repository
 		Definitions:      definitionsContext,
 	}
 
-	rawReview, err := s.generateResponseWithPrompt(ctx, event, llm.ReReviewPrompt, promptData)
+	core.ReportReviewStage(ctx, core.StageGenerating, 0, 0)
+	promptKey := s.choosePrompt(llm.ReReviewPrompt, event)
+	rawReview, err := s.generateResponseWithPrompt(ctx, event, promptKey, promptData)
 	if err != nil {
 		return nil, "", err
 	}
@@ -90,7 +101,7 @@ func (s *Service) GenerateReReview(ctx context.Context, repo *storage.Repository
 	parser := NewStructuredReviewParser(s.cfg.Logger)
 	structuredReview, err := parser.Parse(ctx, rawReview)
 	if err != nil {
-		s.cfg.Logger.Warn("failed to parse legacy re-review, using raw output", "error", err)
+		s.cfg.Logger.WarnContext(ctx, "failed to parse legacy re-review, using raw output", "error", err)
 		structuredReview = &core.StructuredReview{Summary: rawReview}
 	}
 
@@ -100,6 +111,23 @@ func (s *Service) GenerateReReview(ctx context.Context, repo *storage.Repository
 	if structuredReview.Verdict == "" {
 		structuredReview.Verdict = core.VerdictComment
 	}
+	structuredReview.Model = llm.ModelName(s.cfg.GeneratorLLM, s.cfg.GeneratorModel)
+	structuredReview.PromptVersion = s.promptVersion(promptKey)
+	structuredReview.Inputs = []core.PromptInputs{{
+		PromptKey:     string(promptKey),
+		PromptVersion: structuredReview.PromptVersion,
+		Model:         structuredReview.Model,
+		Data: map[string]string{
+			"Language":         promptData.Language,
+			"Languages":        promptData.Languages,
+			"OriginalReview":   promptData.OriginalReview,
+			"NewDiff":          promptData.NewDiff,
+			"UserInstructions": promptData.UserInstructions,
+			"Context":          promptData.Context,
+			"Definitions":      promptData.Definitions,
+		},
+		Output: rawReview,
+	}}
 
 	return structuredReview, rawReview, nil
 }
@@ -111,7 +139,7 @@ func (s *Service) buildFeedbackDrivenContext(ctx context.Context, collectionName
 	}
 
 	if s.cfg.VectorStore == nil {
-		s.cfg.Logger.Warn("vector store not configured; skipping feedback-driven search")
+		s.cfg.Logger.WarnContext(ctx, "vector store not configured; skipping feedback-driven search")
 		return ""
 	}
 	scopedStore := s.cfg.VectorStore.ForRepo(collectionName, embedderModelName)
@@ -158,7 +186,7 @@ func (s *Service) buildFeedbackDrivenContext(ctx context.Context, collectionName
 		contextBuilder.WriteString(result)
 	}
 
-	s.cfg.Logger.Info("feedback-driven context built", "queries", len(feedbackQueries), "unique_docs", len(seenDocs))
+	s.cfg.Logger.InfoContext(ctx, "feedback-driven context built", "queries", len(feedbackQueries), "unique_docs", len(seenDocs))
 
 	if len(seenDocs) == 0 {
 		return ""
@@ -178,12 +206,12 @@ func (s *Service) performReReviewSearch(ctx context.Context, scopedStore storage
 	}
 
 	if queryType == "user instructions" {
-		s.cfg.Logger.Info("performing targeted search for user instructions", "instructions", query)
+		s.cfg.Logger.InfoContext(ctx, "performing targeted search for user instructions", "instructions", query)
 	}
 
 	docs := s.performSearch(ctx, scopedStore, query, queryType)
 	if len(docs) == 0 {
-		s.cfg.Logger.Debug("no documents found for query", "query", query[:min(50, len(query))], "type", queryType)
+		s.cfg.Logger.DebugContext(ctx, "no documents found for query", "query", query[:min(50, len(query))], "type", queryType)
 		return
 	}
 
@@ -229,7 +257,7 @@ func (s *Service) performSearch(ctx context.Context, scopedStore storage.ScopedV
 	for attempt := range maxRetries {
 		select {
 		case <-ctx.Done():
-			s.cfg.Logger.Debug("search cancelled by context", "queryType", queryType)
+			s.cfg.Logger.DebugContext(ctx, "search cancelled by context", "queryType", queryType)
 			return nil
 		default:
 		}
@@ -237,19 +265,19 @@ func (s *Service) performSearch(ctx context.Context, scopedStore storage.ScopedV
 		var searchOpts []vectorstores.Option
 		sparseVec, err := sparse.GenerateSparseVector(ctx, query)
 		if err != nil {
-			s.cfg.Logger.Debug("sparse vector generation failed, using dense only", "query", query[:min(50, len(query))], "error", err)
+			s.cfg.Logger.DebugContext(ctx, "sparse vector generation failed, using dense only", "query", query[:min(50, len(query))], "error", err)
 		} else {
 			searchOpts = append(searchOpts, vectorstores.WithSparseQuery(sparseVec))
 		}
 
 		docs, err := scopedStore.SimilaritySearch(ctx, query, 5, searchOpts...)
 		if err == nil {
-			s.cfg.Logger.Debug("re-review search complete", "queryType", queryType, "docs_found", len(docs), "attempt", attempt+1)
+			s.cfg.Logger.DebugContext(ctx, "re-review search complete", "queryType", queryType, "docs_found", len(docs), "attempt", attempt+1)
 			return docs
 		}
 
 		lastErr = err
-		s.cfg.Logger.Warn("re-review search failed, will retry",
+		s.cfg.Logger.WarnContext(ctx, "re-review search failed, will retry",
 			"queryType", queryType,
 			"attempt", attempt+1,
 			"max_retries", maxRetries,
@@ -267,7 +295,7 @@ func (s *Service) performSearch(ctx context.Context, scopedStore storage.ScopedV
 		}
 	}
 
-	s.cfg.Logger.Warn("re-review search failed after all retries", "queryType", queryType, "error", lastErr)
+	s.cfg.Logger.WarnContext(ctx, "re-review search failed after all retries", "queryType", queryType, "error", lastErr)
 	return nil
 }
 
@@ -293,7 +321,7 @@ func (s *Service) extractCommentsFromReview(ctx context.Context, reviewContent s
 	parser := NewStructuredReviewParser(s.cfg.Logger)
 	parsedReview, err := parser.Parse(ctx, reviewContent)
 	if err != nil {
-		s.cfg.Logger.Warn("extractCommentsFromReview: failed to parse review", "error", err)
+		s.cfg.Logger.WarnContext(ctx, "extractCommentsFromReview: failed to parse review", "error", err)
 		return queries
 	}
 
@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/sevigo/goframe/chains"
 	"github.com/sevigo/goframe/prompts"
 
+	"github.com/sevigo/code-warden/internal/commitcheck"
 	"github.com/sevigo/code-warden/internal/core"
 	internalgithub "github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/rag/contextpkg"
 	"github.com/sevigo/code-warden/internal/rag/metadata"
 	"github.com/sevigo/code-warden/internal/storage"
 )
@@ -24,8 +27,17 @@ const (
 
 	// Minimum cosine similarity to consider a match as potential duplicate
 	duplicationSimilarityThreshold = 0.85
+
+	// vectorStorePingTimeout bounds the reachability check of the vector
+	// store before retrieval, so an outage does not stall the review.
+	vectorStorePingTimeout = 5 * time.Second
 )
 
+// degradedNote heads the summary of a review written while the vector store
+// was unavailable.
+const degradedNote = "> [!WARNING]\n> **Reviewed without repository context.** The code index was unavailable, so this review is based on the diff alone. " +
+	"Findings that depend on code outside the diff may be missing or wrong; run `/review` again once the index is back.\n\n"
+
 // buildPRDescription builds the PR description string passed to BuildContext,
 // including the PR title, body, and commit messages (first line each).
 func buildPRDescription(event *core.GitHubEvent) string {
@@ -46,6 +58,32 @@ func buildPRDescription(event *core.GitHubEvent) string {
 	return sb.String()
 }
 
+// checkCommits adds the commit hygiene section to a review when the
+// repository enables it. Security reviews leave commit messages alone.
+func (s *Service) checkCommits(review *core.StructuredReview, repoConfig *core.RepoConfig, event *core.GitHubEvent) {
+	if repoConfig == nil || !repoConfig.CommitHygiene.Enabled || event.Type == core.SecurityReview || len(event.CommitMessages) == 0 {
+		return
+	}
+	hygiene, err := commitcheck.Check(event.CommitMessages, repoConfig.CommitHygiene)
+	if err != nil {
+		s.cfg.Logger.Warn("commit hygiene check is incomplete", "repo", event.RepoFullName, "error", err)
+	}
+	review.CommitHygiene = hygiene
+}
+
+// checkDependencies adds the dependency section to a review when the pull
+// request adds or upgrades dependencies in manifest files.
+func (s *Service) checkDependencies(ctx context.Context, review *core.StructuredReview, repoConfig *core.RepoConfig, event *core.GitHubEvent, changedFiles []internalgithub.ChangedFile) {
+	if s.cfg.CheckDependencies == nil || repoConfig.DisableDependencyCheck {
+		return
+	}
+	report, err := s.cfg.CheckDependencies(ctx, changedFiles)
+	if err != nil {
+		s.cfg.Logger.WarnContext(ctx, "dependency check is incomplete", "repo", event.RepoFullName, "error", err)
+	}
+	review.Dependencies = report
+}
+
 // extractAddedChunks parses a git patch and returns blocks of consecutively added lines.
 func extractAddedChunks(patch string) []string {
 	var chunks []string
@@ -75,7 +113,7 @@ func extractAddedChunks(patch string) []string {
 }
 
 // checkCodeDuplication queries the VectorDB for semantic duplicates of the newly added code chunks.
-func (s *Service) checkCodeDuplication(ctx context.Context, collectionName string, changedFiles []internalgithub.ChangedFile) string {
+func (s *Service) checkCodeDuplication(ctx context.Context, repo *storage.Repository, changedFiles []internalgithub.ChangedFile) string {
 	if s.cfg.VectorStore == nil {
 		return ""
 	}
@@ -98,7 +136,7 @@ func (s *Service) checkCodeDuplication(ctx context.Context, collectionName strin
 		allChunks = allChunks[:maxChunksToCheck]
 	}
 
-	scopedStore := s.cfg.VectorStore.ForRepo(collectionName, s.cfg.EmbedderModel)
+	scopedStore := s.cfg.VectorStore.ForRepo(repo.QdrantCollectionName, s.embedderModel(repo))
 
 	var duplicates strings.Builder
 	foundCount := 0
@@ -152,6 +190,67 @@ func calculateLinesChanged(changedFiles []internalgithub.ChangedFile) (added, de
 	return added, deleted
 }
 
+// checkVectorStore reports whether the review must go without repository
+// context because the vector store is unreachable. It returns an error instead
+// when degraded reviews are disabled.
+func (s *Service) checkVectorStore(ctx context.Context, repo *storage.Repository, event *core.GitHubEvent) (bool, error) {
+	if s.cfg.VectorStore == nil || repo.QdrantCollectionName == "" {
+		return false, nil
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, vectorStorePingTimeout)
+	defer cancel()
+	err := s.cfg.VectorStore.Ping(pingCtx, repo.QdrantCollectionName, s.embedderModel(repo))
+	if err == nil {
+		return false, nil
+	}
+	if !s.cfg.DegradedReviews {
+		return false, fmt.Errorf("vector store is unavailable: %w", err)
+	}
+	s.cfg.Logger.WarnContext(ctx, "vector store unavailable, reviewing the diff without repository context",
+		"repo", event.RepoFullName,
+		"pr", event.PRNumber,
+		"error", err,
+	)
+	return true, nil
+}
+
+// checkRetrieval reports whether the review must go without repository
+// context because its retrieval failed: the vector store answered the ping
+// but the searches failed and left no context. It returns an error instead
+// when degraded reviews are disabled.
+func (s *Service) checkRetrieval(result *contextpkg.ContextResult, event *core.GitHubEvent) (bool, error) {
+	if len(result.FailedStages) == 0 || !contextIsEmpty(result.FullContext, result.DefinitionsContext) {
+		return false, nil
+	}
+	if !s.cfg.DegradedReviews {
+		return false, fmt.Errorf("repository context retrieval failed (%s)", strings.Join(result.FailedStages, ", "))
+	}
+	s.cfg.Logger.Warn("repository context retrieval failed, reviewing the diff without repository context",
+		"repo", event.RepoFullName,
+		"pr", event.PRNumber,
+		"failed_stages", result.FailedStages,
+	)
+	return true, nil
+}
+
+// retrievalFor returns the retrieval settings for a review of changedFiles.
+// The impact radius is only known after retrieval, so the profile is
+// estimated from the diff alone; high-risk paths still select thorough.
+func (s *Service) retrievalFor(repoConfig *core.RepoConfig, changedFiles []internalgithub.ChangedFile) core.RetrievalRecord {
+	linesAdded, linesDeleted := calculateLinesChanged(changedFiles)
+	paths := extractFilenames(changedFiles)
+	estimate := core.CalculateProfile(linesAdded, linesDeleted, len(changedFiles), 0, core.HasTestCoverage(paths), core.IsDocsOnly(paths), paths)
+
+	record := core.RetrievalRecord{Profile: estimate.Profile}
+	if s.cfg.RetrievalFor != nil {
+		record.RetrievalSettings = s.cfg.RetrievalFor(estimate.Profile, repoConfig)
+	}
+	if repoConfig != nil {
+		record.Scope = repoConfig.RetrievalScope(paths)
+	}
+	return record
+}
+
 // GenerateReview generates a structured code review using the RAG pipeline.
 //
 //nolint:funlen // Complex function that orchestrates the review pipeline
@@ -160,9 +259,9 @@ func (s *Service) GenerateReview(ctx context.Context, repoConfig *core.RepoConfi
 		repoConfig = core.DefaultRepoConfig()
 	}
 
-	s.cfg.Logger.Info("preparing data for a full review", "repo", event.RepoFullName, "pr", event.PRNumber, "embedder", s.cfg.EmbedderModel)
+	s.cfg.Logger.InfoContext(ctx, "preparing data for a full review", "repo", event.RepoFullName, "pr", event.PRNumber, "embedder", s.embedderModel(repo))
 	if diff == "" {
-		s.cfg.Logger.Info("no code changes in pull request", "pr", event.PRNumber)
+		s.cfg.Logger.InfoContext(ctx, "no code changes in pull request", "pr", event.PRNumber)
 		noChangesReview := &core.StructuredReview{
 			Summary:     "This pull request contains no code changes. Looks good to me!",
 			Suggestions: []core.Suggestion{},
@@ -173,33 +272,53 @@ func (s *Service) GenerateReview(ctx context.Context, repoConfig *core.RepoConfi
 	// If changedFiles is empty (internal review), extract them from the diff
 	if len(changedFiles) == 0 {
 		changedFiles = ParseDiff(diff)
-		s.cfg.Logger.Info("extracted changed files from diff for internal review", "count", len(changedFiles))
+		s.cfg.Logger.InfoContext(ctx, "extracted changed files from diff for internal review", "count", len(changedFiles))
+	}
+
+	core.ReportReviewStage(ctx, core.StageRetrieving, 0, 0)
+	degraded, err := s.checkVectorStore(ctx, repo, event)
+	if err != nil {
+		return nil, "", err
 	}
 
 	// Use context builder with impact tracking
-	contextResult := s.cfg.BuildContextWithImpact(ctx, repo.QdrantCollectionName, s.cfg.EmbedderModel, repo.ClonePath, changedFiles, buildPRDescription(event))
+	retrieval := s.retrievalFor(repoConfig, changedFiles)
+	contextResult := &contextpkg.ContextResult{}
+	if !degraded {
+		contextResult = s.cfg.BuildContextWithImpact(ctx, repo.QdrantCollectionName, s.embedderModel(repo), repo.ClonePath, s.redactPatches(repoConfig, event, changedFiles), buildPRDescription(event), retrieval.RetrievalSettings)
+		if degraded, err = s.checkRetrieval(contextResult, event); err != nil {
+			return nil, "", err
+		}
+	}
 	contextString := contextResult.FullContext
 	definitionsContext := contextResult.DefinitionsContext
 	impactRadius := contextResult.ImpactRadius
 
+	// Scrub secrets from everything that leaves the host. The original diff is
+	// kept for suggestion validation against real line content.
+	promptDiff := diff
+	s.redactSecrets(repoConfig, event, &promptDiff, &contextString, &definitionsContext)
+
 	// Phase 2: LLM-directed gap filling (only when Phase 1 returned meaningful context)
-	if s.cfg.Investigate != nil && !contextIsEmpty(contextString, definitionsContext) {
-		additionalContext := s.cfg.Investigate(ctx, repo.QdrantCollectionName, diff, contextString, definitionsContext)
+	if s.cfg.Investigate != nil && !degraded && !contextIsEmpty(contextString, definitionsContext) {
+		additionalContext := s.cfg.Investigate(ctx, repo.QdrantCollectionName, promptDiff, contextString, definitionsContext)
 		if additionalContext != "" {
 			contextString += "\n\n" + additionalContext
 		}
 	}
 
 	// Detect duplications by generating embeddings for the exact added lines
-	duplicationContext := s.checkCodeDuplication(ctx, repo.QdrantCollectionName, changedFiles)
-	if duplicationContext != "" {
-		contextString = contextString + "\n\n" + duplicationContext
+	if !degraded {
+		if duplicationContext := s.checkCodeDuplication(ctx, repo, changedFiles); duplicationContext != "" {
+			contextString = contextString + "\n\n" + duplicationContext
+		}
 	}
+	s.redactSecrets(repoConfig, event, &contextString)
 
 	// Check for empty context to warn about hallucination risk
 	contextEmpty := contextIsEmpty(contextString, definitionsContext)
 	if contextEmpty {
-		s.cfg.Logger.Warn("HIGH HALLUCINATION RISK: no context retrieved from vector store - review will be based solely on diff without repository context",
+		s.cfg.Logger.WarnContext(ctx, "HIGH HALLUCINATION RISK: no context retrieved from vector store - review will be based solely on diff without repository context",
 			"repo", event.RepoFullName,
 			"pr", event.PRNumber,
 			"changed_files", len(changedFiles),
@@ -216,7 +335,7 @@ func (s *Service) GenerateReview(ctx context.Context, repoConfig *core.RepoConfi
 	docsOnly := core.IsDocsOnly(changedFilePaths)
 	complexity := core.CalculateProfile(linesAdded, linesDeleted, len(changedFiles), impactRadius, testCoverage, docsOnly, changedFilePaths)
 
-	s.cfg.Logger.Info("review profile calculated",
+	s.cfg.Logger.InfoContext(ctx, "review profile calculated",
 		"profile", complexity.Profile,
 		"score", complexity.Score,
 		"impact_radius", complexity.ImpactRadius,
@@ -230,18 +349,19 @@ func (s *Service) GenerateReview(ctx context.Context, repoConfig *core.RepoConfi
 	// Render profile instruction
 	profileInstruction, err := s.cfg.PromptMgr.Render("review_profile", complexity)
 	if err != nil {
-		s.cfg.Logger.Warn("failed to render review profile, using default", "error", err)
+		s.cfg.Logger.WarnContext(ctx, "failed to render review profile, using default", "error", err)
 		profileInstruction = "" // Will use default thorough profile
 	}
 
-	promptData := s.buildReviewPromptDataWithProfile(event, repoConfig, contextString, definitionsContext, diff, changedFiles, profileInstruction)
+	promptData := s.buildReviewPromptDataWithProfile(event, repoConfig, contextString, definitionsContext, promptDiff, changedFiles, profileInstruction)
 
-	promptStr, err := s.cfg.PromptMgr.Render(llm.CodeReviewPrompt, promptData)
+	promptKey := s.choosePrompt(reviewPrompt(event), event)
+	promptStr, err := s.cfg.PromptMgr.Render(promptKey, promptData)
 	if err != nil {
 		return nil, "", err
 	}
 
-	parser := NewStructuredReviewParser(s.cfg.Logger)
+	parser := s.newReviewParser()
 	chain, err := chains.NewLLMChain(
 		s.cfg.GeneratorLLM,
 		prompts.NewPromptTemplate(promptStr),
@@ -251,6 +371,7 @@ func (s *Service) GenerateReview(ctx context.Context, repoConfig *core.RepoConfi
 		return nil, "", fmt.Errorf("failed to create LLM chain: %w", err)
 	}
 
+	core.ReportReviewStage(ctx, core.StageGenerating, 0, 0)
 	structuredReview, err := chain.Call(ctx, nil)
 	if err != nil {
 		return nil, "", err
@@ -268,16 +389,51 @@ func (s *Service) GenerateReview(ctx context.Context, repoConfig *core.RepoConfi
 	// Add complexity score to result for UI display
 	structuredReview.ReviewProfile = string(complexity.Profile)
 	structuredReview.ComplexityScore = complexity.Score
+	if !degraded {
+		structuredReview.Retrieval = &retrieval
+	}
+	structuredReview.Degraded = degraded
 	structuredReview.ImpactRadius = complexity.ImpactRadius
+	structuredReview.Model = llm.ModelName(s.cfg.GeneratorLLM, s.cfg.GeneratorModel)
+	structuredReview.PromptVersion = s.promptVersion(promptKey)
+	structuredReview.Inputs = []core.PromptInputs{{
+		PromptKey:     string(promptKey),
+		PromptVersion: structuredReview.PromptVersion,
+		Model:         structuredReview.Model,
+		ReviewProfile: structuredReview.ReviewProfile,
+		Data:          promptData,
+		Output:        parser.Raw,
+	}}
+	if event.Type == core.SecurityReview {
+		structuredReview.Title = securityReviewTitle
+	}
+	s.checkCommits(structuredReview, repoConfig, event)
+	s.findMissingTests(ctx, structuredReview, repoConfig, reviewRepo(repo, degraded), event, changedFiles)
+	s.checkDependencies(ctx, structuredReview, repoConfig, event, changedFiles)
+	s.mergeStaticAnalysis(structuredReview, event, changedFiles)
 
 	// Add disclaimer to summary if context was empty
-	if contextEmpty {
+	switch {
+	case degraded:
+		structuredReview.Summary = degradedNote + structuredReview.Summary
+	case contextEmpty:
 		structuredReview.Summary = "**Note:** This review was generated without repository context. Verify findings against actual codebase.\n\n" + structuredReview.Summary
 	}
 
 	return structuredReview, parser.Raw, nil
 }
 
+// reviewRepo returns repo for the review stages after the main review. In a
+// degraded review the copy has no collection, so they skip the vector store.
+func reviewRepo(repo *storage.Repository, degraded bool) *storage.Repository {
+	if !degraded {
+		return repo
+	}
+	offline := *repo
+	offline.QdrantCollectionName = ""
+	return &offline
+}
+
 // extractFilenames returns just the filenames from changed files.
 func extractFilenames(changedFiles []internalgithub.ChangedFile) []string {
 	filenames := make([]string, len(changedFiles))
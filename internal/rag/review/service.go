@@ -4,20 +4,29 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/sevigo/goframe/llms"
 
+	"github.com/sevigo/code-warden/internal/artifacts"
+	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
 	internalgithub "github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/pathutil"
 	"github.com/sevigo/code-warden/internal/rag/contextpkg"
+	"github.com/sevigo/code-warden/internal/redact"
 	"github.com/sevigo/code-warden/internal/storage"
 )
 
 // ContextBuilderWithImpactFunc generates the context and returns impact information.
-type ContextBuilderWithImpactFunc func(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prContext string) *contextpkg.ContextResult
+type ContextBuilderWithImpactFunc func(ctx context.Context, collectionName, embedderModelName, repoPath string, changedFiles []internalgithub.ChangedFile, prContext string, retrieval core.RetrievalSettings) *contextpkg.ContextResult
+
+// RetrievalFunc returns the retrieval settings for a review profile, taking
+// the repository's overrides into account. repoConfig may be nil.
+type RetrievalFunc func(profile core.ReviewProfile, repoConfig *core.RepoConfig) core.RetrievalSettings
 
 // LLMFactory returns an LLM instance for a given model name.
 type LLMFactory func(ctx context.Context, modelName string) (llms.Model, error)
@@ -27,6 +36,11 @@ type LLMFactory func(ctx context.Context, modelName string) (llms.Model, error)
 // Implementations must be failure-safe and never return an error.
 type InvestigateFunc func(ctx context.Context, collectionName, diff, mainContext, definitionsContext string) string
 
+// DependencyCheckFunc reports known vulnerabilities and flagged licenses of
+// the dependencies changed in a pull request. It returns nil when no
+// dependency changed; the report may be partial when it also returns an error.
+type DependencyCheckFunc func(ctx context.Context, changedFiles []internalgithub.ChangedFile) (*core.DependencyReport, error)
+
 // Config holds dependencies for the Service.
 type Config struct {
 	VectorStore            storage.VectorStore
@@ -37,10 +51,36 @@ type Config struct {
 	ConsensusTimeout       string
 	ConsensusQuorum        float64
 	BuildContextWithImpact ContextBuilderWithImpactFunc
-	EmbedderModel          string
+	// EmbedderModel is the configured embedder model, used for repositories
+	// that have none recorded.
+	EmbedderModel string
+	// GeneratorModel is recorded on each review so feedback can be attributed
+	// to it, unless GeneratorLLM is an llm.SwitchableModel that names its
+	// current model.
+	GeneratorModel string
+	// ReviewOutputFormat selects the review output protocol ("xml" or "json").
+	ReviewOutputFormat string
+	// ConsensusMaxWorkers limits how many models generate reviews in parallel
+	// in consensus mode. Defaults to 2 when zero.
+	ConsensusMaxWorkers int
+	// Artifacts receives raw per-model and consensus reviews. If nil, they
+	// are not saved.
+	Artifacts *artifacts.Store
 	// Investigate is called after BuildContext to fill context gaps (Phase 2 agentic review).
 	// If nil, Phase 2 is skipped.
 	Investigate InvestigateFunc
+	// CheckDependencies looks up the dependencies added or upgraded in
+	// manifest files. If nil, reviews have no dependency section.
+	CheckDependencies DependencyCheckFunc
+	// MaxLintFindings caps the lint findings of event.StaticAnalysis that
+	// the model did not report and that are added to a review.
+	MaxLintFindings int
+	// RetrievalFor selects the retrieval depth and context size per review
+	// profile. If nil, the context builder's defaults apply.
+	RetrievalFor RetrievalFunc
+	// DegradedReviews reviews the diff without repository context when the
+	// vector store is unreachable. Otherwise such reviews fail.
+	DegradedReviews bool
 }
 
 // Service orchestrates code review generation.
@@ -56,12 +96,53 @@ func NewService(cfg Config) *Service {
 // formatChangedFiles returns a markdown-formatted list of changed file paths.
 func formatChangedFiles(files []internalgithub.ChangedFile) string {
 	var builder strings.Builder
+	languages := fileLanguages(files)
 	for _, file := range files {
+		if lang, ok := languages[file.Filename]; ok {
+			fmt.Fprintf(&builder, "- `%s` (%s)\n", file.Filename, lang)
+			continue
+		}
 		fmt.Fprintf(&builder, "- `%s`\n", file.Filename)
 	}
 	return builder.String()
 }
 
+// customInstructions returns the repository's custom instructions followed by
+// the instructions of each path rule that matches a changed file, prefixed
+// with the files they apply to, and the language and instructions of each
+// touched sub-project.
+func customInstructions(repoConfig *core.RepoConfig, changedFiles []internalgithub.ChangedFile) string {
+	lines := slices.Clone(repoConfig.CustomInstructions)
+	for _, rule := range repoConfig.Rules {
+		if len(rule.Instructions) == 0 {
+			continue
+		}
+		var matched []string
+		for _, file := range changedFiles {
+			if rule.Matches(file.Filename) {
+				matched = append(matched, "`"+file.Filename+"`")
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		for _, instruction := range rule.Instructions {
+			lines = append(lines, fmt.Sprintf("For %s: %s", strings.Join(matched, ", "), instruction))
+		}
+	}
+	groups, _ := repoConfig.SubProjectFiles(extractFilenames(changedFiles))
+	for _, g := range groups {
+		p := g.SubProject
+		if p.Language != "" {
+			lines = append(lines, fmt.Sprintf("The %s sub-project (`%s/`) is written in %s.", p.DisplayName(), pathutil.Clean(p.Path), p.Language))
+		}
+		for _, instruction := range p.CustomInstructions {
+			lines = append(lines, fmt.Sprintf("For the %s sub-project (`%s/`): %s", p.DisplayName(), pathutil.Clean(p.Path), instruction))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // contextIsEmpty checks if both context strings are empty.
 // This helps detect high hallucination risk.
 func contextIsEmpty(contextString, definitionsContext string) bool {
@@ -83,20 +164,126 @@ func (s *Service) getConsensusTimeout() time.Duration {
 	return d
 }
 
+// getConsensusMaxWorkers returns how many consensus models may run in parallel.
+func (s *Service) getConsensusMaxWorkers() int {
+	const defaultWorkers = 2
+	if s.cfg.ConsensusMaxWorkers < 1 {
+		return defaultWorkers
+	}
+	return s.cfg.ConsensusMaxWorkers
+}
+
+// redactSecrets replaces likely secrets in the given prompt inputs in place,
+// unless the repository opted out with disable_secret_redaction.
+func (s *Service) redactSecrets(repoConfig *core.RepoConfig, event *core.GitHubEvent, inputs ...*string) {
+	if repoConfig.DisableSecretRedaction {
+		return
+	}
+	total := 0
+	for _, in := range inputs {
+		var n int
+		*in, n = redact.Secrets(*in)
+		total += n
+	}
+	if total > 0 {
+		s.cfg.Logger.Warn("redacted likely secrets from review prompt",
+			"repo", event.RepoFullName,
+			"pr", event.PRNumber,
+			"count", total,
+		)
+	}
+}
+
+// redactPatches returns changedFiles with likely secrets replaced in their
+// patches, for context building: HyDE sends the patches to a model before
+// the context is redacted. The originals are left alone, as suggestions are
+// validated against the real lines.
+func (s *Service) redactPatches(repoConfig *core.RepoConfig, event *core.GitHubEvent, changedFiles []internalgithub.ChangedFile) []internalgithub.ChangedFile {
+	if repoConfig.DisableSecretRedaction {
+		return changedFiles
+	}
+	redacted := slices.Clone(changedFiles)
+	patches := make([]*string, len(redacted))
+	for i := range redacted {
+		patches[i] = &redacted[i].Patch
+	}
+	s.redactSecrets(repoConfig, event, patches...)
+	return redacted
+}
+
 // buildReviewPromptDataWithProfile populates template variables including the review profile instruction.
 // This is used by both single-model and consensus review paths.
 func (s *Service) buildReviewPromptDataWithProfile(event *core.GitHubEvent, repoConfig *core.RepoConfig, contextString, definitionsContext, diff string, changedFiles []internalgithub.ChangedFile, profileInstruction string) map[string]string {
+	language, languages := promptLanguages(event, changedFiles)
+	staticAnalysis := formatStaticAnalysis(changedLineFindings(event, changedFiles))
+	s.redactSecrets(repoConfig, event, &staticAnalysis)
 	return map[string]string{
 		"Title":                    event.PRTitle,
 		"Description":              event.PRBody,
-		"Language":                 event.Language,
-		"CustomInstructions":       strings.Join(repoConfig.CustomInstructions, "\n"),
+		"Language":                 language,
+		"Languages":                languages,
+		"CustomInstructions":       customInstructions(repoConfig, changedFiles),
 		"ChangedFiles":             formatChangedFiles(changedFiles),
 		"Context":                  contextString,
 		"Definitions":              definitionsContext,
 		"Diff":                     diff,
 		"ReviewProfileInstruction": profileInstruction,
+		"StaticAnalysis":           staticAnalysis,
+		"OutputFormat":             s.outputFormat(),
+		"ReviewSchema":             llm.ReviewJSONSchema,
+	}
+}
+
+// embedderModel returns the embedder model to search the collection of repo
+// with: the one it was built with.
+func (s *Service) embedderModel(repo *storage.Repository) string {
+	return repo.Embedder(s.cfg.EmbedderModel)
+}
+
+// outputFormat returns the configured review output protocol.
+func (s *Service) outputFormat() string {
+	if s.cfg.ReviewOutputFormat == config.ReviewOutputJSON {
+		return config.ReviewOutputJSON
+	}
+	return config.ReviewOutputXML
+}
+
+// promptVersion returns the version of key as recorded on reviews, with the
+// output protocol appended for the review prompts so JSON and XML runs are
+// compared separately.
+func (s *Service) promptVersion(key llm.PromptKey) string {
+	v := s.cfg.PromptMgr.Version(key)
+	if base := key.Base(); (base == llm.CodeReviewPrompt || base == llm.SecurityReviewPrompt) && s.outputFormat() == config.ReviewOutputJSON {
+		v += "+json"
 	}
+	return v
+}
+
+// choosePrompt returns the prompt to render for the pull request of event:
+// the variant of an experiment on key for its share of pull requests, key
+// otherwise.
+func (s *Service) choosePrompt(key llm.PromptKey, event *core.GitHubEvent) llm.PromptKey {
+	return s.cfg.PromptMgr.Choose(key, fmt.Sprintf("%s#%d", event.RepoFullName, event.PRNumber))
+}
+
+// securityReviewTitle heads the summary of reviews requested with /security.
+const securityReviewTitle = "🛡️ Security Review Summary"
+
+// reviewPrompt returns the prompt for the per-model review of event: the
+// security prompt for /security, the general code review prompt otherwise.
+func reviewPrompt(event *core.GitHubEvent) llm.PromptKey {
+	if event.Type == core.SecurityReview {
+		return llm.SecurityReviewPrompt
+	}
+	return llm.CodeReviewPrompt
+}
+
+// newReviewParser returns a parser for code_review prompt output in the
+// configured protocol.
+func (s *Service) newReviewParser() *StructuredReviewParser {
+	parser := NewStructuredReviewParser(s.cfg.Logger)
+	parser.PreferJSON = s.outputFormat() == config.ReviewOutputJSON
+	return parser
 }
 
 // generateResponseWithPrompt renders a prompt template and calls the generator LLM.
@@ -106,7 +293,7 @@ func (s *Service) generateResponseWithPrompt(ctx context.Context, event *core.Gi
 		return "", fmt.Errorf("could not render prompt '%s': %w", promptKey, err)
 	}
 
-	s.cfg.Logger.Info("calling LLM for response generation",
+	s.cfg.Logger.InfoContext(ctx, "calling LLM for response generation",
 		"repo", event.RepoFullName,
 		"pr", event.PRNumber,
 		"prompt_key", promptKey,
@@ -117,6 +304,6 @@ func (s *Service) generateResponseWithPrompt(ctx context.Context, event *core.Gi
 		return "", fmt.Errorf("LLM generation failed for prompt '%s': %w", promptKey, err)
 	}
 
-	s.cfg.Logger.Info("LLM response generated successfully", "chars", len(response))
+	s.cfg.Logger.InfoContext(ctx, "LLM response generated successfully", "chars", len(response))
 	return response, nil
 }
@@ -0,0 +1,106 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+// maxPromptLintFindings caps the lint findings shown to the model.
+const maxPromptLintFindings = 50
+
+// lintCategory is the category of suggestions made from lint findings that
+// the model did not report itself.
+const lintCategory = "Static Analysis"
+
+// changedLineFindings returns the lint findings of event that are on lines
+// added by changedFiles. Findings on unchanged code predate the pull request.
+func changedLineFindings(event *core.GitHubEvent, changedFiles []internalgithub.ChangedFile) []core.LintFinding {
+	if len(event.StaticAnalysis) == 0 {
+		return nil
+	}
+	added := buildFileLinesMap(changedFiles)
+	var findings []core.LintFinding
+	for _, f := range event.StaticAnalysis {
+		if added[f.FilePath][f.Line] {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// formatStaticAnalysis lists findings for the review prompt, one per line.
+func formatStaticAnalysis(findings []core.LintFinding) string {
+	var sb strings.Builder
+	for i, f := range findings {
+		if i == maxPromptLintFindings {
+			fmt.Fprintf(&sb, "- ... and %d more\n", len(findings)-i)
+			break
+		}
+		fmt.Fprintf(&sb, "- `%s:%d` [%s] %s\n", f.FilePath, f.Line, f.Origin(), f.Message)
+	}
+	return sb.String()
+}
+
+// mergeStaticAnalysis tags the suggestions of review that cover a lint
+// finding with its origin and adds the findings the model left out as
+// suggestions of their own, up to s.cfg.MaxLintFindings of them.
+func (s *Service) mergeStaticAnalysis(review *core.StructuredReview, event *core.GitHubEvent, changedFiles []internalgithub.ChangedFile) {
+	findings := changedLineFindings(event, changedFiles)
+	if len(findings) == 0 {
+		return
+	}
+
+	added := 0
+	for _, f := range findings {
+		if i := coveringSuggestion(review.Suggestions, f); i >= 0 {
+			if review.Suggestions[i].Origin == "" {
+				review.Suggestions[i].Origin = f.Origin()
+			}
+			continue
+		}
+		if added >= s.cfg.MaxLintFindings {
+			continue
+		}
+		review.Suggestions = append(review.Suggestions, core.Suggestion{
+			FilePath:   f.FilePath,
+			LineNumber: f.Line,
+			Severity:   f.Severity,
+			Category:   lintCategory,
+			Comment:    lintComment(f),
+			Source:     "external:" + f.Tool,
+			Origin:     f.Origin(),
+		})
+		added++
+	}
+	s.cfg.Logger.Info("merged static analysis findings", "repo", event.RepoFullName, "pr", event.PRNumber,
+		"findings", len(findings), "added", added)
+}
+
+// coveringSuggestion returns the index of the suggestion on the line of f,
+// or -1.
+func coveringSuggestion(suggestions []core.Suggestion, f core.LintFinding) int {
+	for i, sug := range suggestions {
+		if sug.FilePath != f.FilePath {
+			continue
+		}
+		start := sug.StartLine
+		if start <= 0 {
+			start = sug.LineNumber
+		}
+		if f.Line >= start && f.Line <= sug.LineNumber {
+			return i
+		}
+	}
+	return -1
+}
+
+// lintComment is the comment of a suggestion made from f.
+func lintComment(f core.LintFinding) string {
+	if f.Rule == "" {
+		return fmt.Sprintf("**%s:** %s", f.Tool, f.Message)
+	}
+	return fmt.Sprintf("**%s** (`%s`): %s", f.Tool, f.Rule, f.Message)
+}
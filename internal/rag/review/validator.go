@@ -18,16 +18,18 @@ var severityRank = map[string]int{
 }
 
 type SuggestionValidator struct {
-	diffContent  string
-	changedFiles []internalgithub.ChangedFile
-	fileLines    map[string]map[int]bool
+	diffContent   string
+	changedFiles  []internalgithub.ChangedFile
+	fileLines     map[string]map[int]bool
+	fileLanguages map[string]string
 }
 
 func NewSuggestionValidator(diffContent string, changedFiles []internalgithub.ChangedFile) *SuggestionValidator {
 	return &SuggestionValidator{
-		diffContent:  diffContent,
-		changedFiles: changedFiles,
-		fileLines:    buildFileLinesMap(changedFiles),
+		diffContent:   diffContent,
+		changedFiles:  changedFiles,
+		fileLines:     buildFileLinesMap(changedFiles),
+		fileLanguages: fileLanguages(changedFiles),
 	}
 }
 
@@ -110,6 +112,20 @@ func (v *SuggestionValidator) ValidateSource(sug *core.Suggestion) bool {
 	return false
 }
 
+// ValidateLanguage reports whether the code suggestion of sug fits the
+// language of its file. Suggestions without code, or for files of unknown
+// language, are valid.
+func (v *SuggestionValidator) ValidateLanguage(sug *core.Suggestion) bool {
+	if sug.CodeSuggestion == "" {
+		return true
+	}
+	lang, ok := v.fileLanguages[strings.TrimPrefix(sug.FilePath, "./")]
+	if !ok {
+		return true
+	}
+	return !core.LanguageMismatch(lang, sug.CodeSuggestion)
+}
+
 func (v *SuggestionValidator) lineExistsInDiff(filename string, line int) bool {
 	lines, exists := v.fileLines[filename]
 	if !exists {
@@ -170,6 +186,7 @@ func (f *SuggestionFilter) FilterAndRank(
 		validateLineNumber(sug, validator, f.ValidateLineNums, logFunc)
 		validateSourceCitation(sug, validator, f.ValidateSources, logFunc)
 		validateStartLine(sug, logFunc)
+		validateCodeLanguage(sug, validator, logFunc)
 
 		if f.Deduplicate {
 			key := makeDedupKey(sug)
@@ -243,6 +260,23 @@ func validateSourceCitation(sug *core.Suggestion, validator *SuggestionValidator
 	}
 }
 
+// validateCodeLanguage drops a code suggestion written in another language
+// than its file, a sign the model confused the files of a polyglot change.
+// The comment is kept.
+func validateCodeLanguage(sug *core.Suggestion, validator *SuggestionValidator, logFunc func(msg string, args ...any)) {
+	if validator == nil || validator.ValidateLanguage(sug) {
+		return
+	}
+	if logFunc != nil {
+		logFunc("dropping code suggestion in another language than its file",
+			"file", sug.FilePath,
+			"line", sug.LineNumber,
+			"snippet_language", core.SnippetLanguage(sug.CodeSuggestion),
+		)
+	}
+	sug.CodeSuggestion = ""
+}
+
 func makeDedupKey(sug *core.Suggestion) string {
 	return strings.ToLower(sug.FilePath + ":" + strconv.Itoa(sug.LineNumber) + ":" + categoryKey(sug.Comment) + ":" + sug.Category)
 }
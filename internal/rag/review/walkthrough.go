@@ -0,0 +1,83 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sevigo/goframe/llms"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// walkthroughTitle heads the walkthrough posted for /explain.
+const walkthroughTitle = "## 🧭 PR Walkthrough"
+
+// GenerateWalkthrough explains a pull request to someone new to the codebase:
+// what changed, why it likely changed and which subsystems are involved. It
+// retrieves the same context as a review but uses its own prompt, and returns
+// Markdown without suggestions.
+func (s *Service) GenerateWalkthrough(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, diff string, changedFiles []internalgithub.ChangedFile) (string, error) {
+	if repoConfig == nil {
+		repoConfig = core.DefaultRepoConfig()
+	}
+	if diff == "" {
+		return walkthroughTitle + "\n\nThis pull request contains no code changes.", nil
+	}
+	if len(changedFiles) == 0 {
+		changedFiles = ParseDiff(diff)
+	}
+
+	s.cfg.Logger.InfoContext(ctx, "preparing data for a PR walkthrough", "repo", event.RepoFullName, "pr", event.PRNumber)
+	core.ReportReviewStage(ctx, core.StageRetrieving, 0, 0)
+	retrieval := s.retrievalFor(repoConfig, changedFiles)
+	contextResult := s.cfg.BuildContextWithImpact(ctx, repo.QdrantCollectionName, s.embedderModel(repo), repo.ClonePath, s.redactPatches(repoConfig, event, changedFiles), buildPRDescription(event), retrieval.RetrievalSettings)
+	contextString := contextResult.FullContext
+	definitionsContext := contextResult.DefinitionsContext
+	promptDiff := diff
+	s.redactSecrets(repoConfig, event, &promptDiff, &contextString, &definitionsContext)
+
+	language, languages := promptLanguages(event, changedFiles)
+	prompt, err := s.cfg.PromptMgr.Render(llm.PRWalkthroughPrompt, map[string]string{
+		"Title":          event.PRTitle,
+		"Description":    event.PRBody,
+		"Language":       language,
+		"Languages":      languages,
+		"CommitMessages": formatCommitMessages(event.CommitMessages),
+		"ChangedFiles":   formatChangedFiles(changedFiles),
+		"Context":        contextString,
+		"Definitions":    definitionsContext,
+		"Diff":           promptDiff,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	core.ReportReviewStage(ctx, core.StageGenerating, 0, 0)
+	response, err := llms.GenerateFromSinglePrompt(ctx, s.cfg.GeneratorLLM, prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate walkthrough: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(walkthroughTitle + "\n\n")
+	if contextIsEmpty(contextResult.FullContext, contextResult.DefinitionsContext) {
+		sb.WriteString("**Note:** No repository context was available, so this walkthrough is based on the diff alone.\n\n")
+	}
+	sb.WriteString(strings.TrimSpace(response))
+	sb.WriteString("\n\n---\n*Explanation only — run `/review` for findings.*\n")
+	return sb.String(), nil
+}
+
+// formatCommitMessages lists the subject line of each commit.
+func formatCommitMessages(messages []string) string {
+	var sb strings.Builder
+	for _, msg := range messages {
+		subject, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+		fmt.Fprintf(&sb, "- %s\n", strings.TrimSpace(subject))
+	}
+	return sb.String()
+}
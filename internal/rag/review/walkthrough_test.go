@@ -0,0 +1,88 @@
+package review
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/sevigo/goframe/llms"
+	"github.com/sevigo/goframe/schema"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/rag/contextpkg"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// recordingModel returns reply and records the prompt it was sent.
+type recordingModel struct {
+	reply  string
+	prompt string
+}
+
+func (m *recordingModel) GenerateContent(_ context.Context, messages []schema.MessageContent, _ ...llms.CallOption) (*schema.ContentResponse, error) {
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if text, ok := part.(schema.TextContent); ok {
+				m.prompt += text.Text
+			}
+		}
+	}
+	return &schema.ContentResponse{Choices: []*schema.ContentChoice{{Content: m.reply}}}, nil
+}
+
+func (m *recordingModel) Call(_ context.Context, prompt string, _ ...llms.CallOption) (string, error) {
+	m.prompt = prompt
+	return m.reply, nil
+}
+
+func TestGenerateWalkthrough(t *testing.T) {
+	promptMgr, err := llm.NewPromptManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	model := &recordingModel{reply: "### What changed\nPagination was added."}
+	var embedder string
+	s := NewService(Config{
+		PromptMgr:     promptMgr,
+		GeneratorLLM:  model,
+		Logger:        slog.Default(),
+		EmbedderModel: "nomic-embed-text",
+		BuildContextWithImpact: func(_ context.Context, _, embedderModel, _ string, _ []internalgithub.ChangedFile, _ string, _ core.RetrievalSettings) *contextpkg.ContextResult {
+			embedder = embedderModel
+			return &contextpkg.ContextResult{FullContext: "internal/server: HTTP handlers"}
+		},
+	})
+	event := &core.GitHubEvent{
+		RepoFullName:   "acme/api",
+		PRTitle:        "Paginate repos",
+		CommitMessages: []string{"Add pagination\n\nBody"},
+	}
+	changedFiles := []internalgithub.ChangedFile{{Filename: "internal/server/repos.go"}}
+
+	got, err := s.GenerateWalkthrough(context.Background(), nil, &storage.Repository{EmbedderModel: "bge-m3"}, event, "+page := 1", changedFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if embedder != "bge-m3" {
+		t.Errorf("context retrieved with embedder %q, want the repository's bge-m3", embedder)
+	}
+	if !strings.HasPrefix(got, walkthroughTitle) || !strings.Contains(got, "Pagination was added.") {
+		t.Errorf("unexpected walkthrough:\n%s", got)
+	}
+	if strings.Contains(got, "No repository context") {
+		t.Error("walkthrough with context must not carry the no-context note")
+	}
+	for _, want := range []string{"Paginate repos", "- Add pagination\n", "`internal/server/repos.go`", "internal/server: HTTP handlers", "+page := 1", "This is NOT a code review"} {
+		if !strings.Contains(model.prompt, want) {
+			t.Errorf("prompt is missing %q", want)
+		}
+	}
+
+	empty, err := s.GenerateWalkthrough(context.Background(), nil, &storage.Repository{}, event, "", nil)
+	if err != nil || !strings.Contains(empty, "no code changes") {
+		t.Errorf("GenerateWalkthrough() with an empty diff = %q, %v", empty, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"strings"
 	"sync"
 	"time"
@@ -23,10 +24,14 @@ import (
 	"github.com/sevigo/goframe/textsplitter"
 	"github.com/sevigo/goframe/vectorstores"
 
+	"github.com/sevigo/code-warden/internal/archgraph"
+	"github.com/sevigo/code-warden/internal/artifacts"
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/depcheck"
 	internalgithub "github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/netutil"
 	"github.com/sevigo/code-warden/internal/rag/contextpkg"
 	indexpkg "github.com/sevigo/code-warden/internal/rag/index"
 	questionpkg "github.com/sevigo/code-warden/internal/rag/question"
@@ -40,15 +45,50 @@ type Service interface {
 	SetupRepoContext(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, repoPath string, progressFn indexpkg.ProgressFunc) error
 	UpdateRepoContext(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, repoPath string, filesToProcess, filesToDelete []string, progressFn indexpkg.ProgressFunc) error
 	SyncRepoIndex(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, updateResult *core.UpdateResult, progressFn indexpkg.ProgressFunc) error
+	// IndexTree indexes the tree at repoPath into a standalone collection,
+	// replacing its contents, without touching any repository's file records.
+	// It backs temporary collections such as snapshots of past commits.
+	IndexTree(ctx context.Context, repoConfig *core.RepoConfig, collectionName, repoPath string) error
+	// BuildCollection indexes the clone of repo into collectionName with
+	// embedderModel, replacing its contents, and adds the architecture and
+	// package summaries. Unlike SetupRepoContext it leaves the repository
+	// and its file records as they are. It returns the number of files and
+	// documents indexed.
+	BuildCollection(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, collectionName, embedderModel string) (files, chunks int, err error)
+	// DeleteCollection drops a collection created with IndexTree.
+	DeleteCollection(ctx context.Context, collectionName string) error
 	GenerateReview(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, diff string, changedFiles []internalgithub.ChangedFile) (*core.StructuredReview, string, error)
-	GenerateReReview(ctx context.Context, repo *storage.Repository, event *core.GitHubEvent, originalReview *core.Review, ghClient internalgithub.Client, changedFiles []internalgithub.ChangedFile) (*core.StructuredReview, string, error)
-	AnswerQuestion(ctx context.Context, collectionName, embedderModelName, question string, history []string) (string, error)
+	GenerateReReview(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, originalReview *core.Review, ghClient internalgithub.Client, changedFiles []internalgithub.ChangedFile) (*core.StructuredReview, string, error)
+	AnswerQuestion(ctx context.Context, collectionName, embedderModelName, question string, history []string) (*core.Answer, error)
+	// AnswerQuestionAcross answers a question from the collections of
+	// several repositories and attributes its citations to them.
+	AnswerQuestionAcross(ctx context.Context, repos []core.RepoCollection, embedderModelName, question string, history []string) (*core.Answer, error)
+	// SummarizeConversation condenses the turns of a chat, extending the
+	// summary of the turns before them.
+	SummarizeConversation(ctx context.Context, summary string, turns []string) (string, error)
 	ExplainPath(ctx context.Context, collectionName, embedderModelName, path string) (string, error)
 	ProcessFile(ctx context.Context, repoPath, file string) []schema.Document
 	GenerateComparisonSummaries(ctx context.Context, models []string, repoPath string, relPaths []string) (map[string]map[string]string, error)
+	// GenerateWalkthrough explains a pull request to someone new to the
+	// codebase, as Markdown without suggestions.
+	GenerateWalkthrough(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, diff string, changedFiles []internalgithub.ChangedFile) (string, error)
+	// ReplyToSuggestion answers a "/warden explain" or "/warden fix" reply
+	// to a posted suggestion, as Markdown to post in its thread.
+	ReplyToSuggestion(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, thread *core.SuggestionThread) (string, error)
 	GenerateConsensusReview(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, models []string, diff string, changedFiles []internalgithub.ChangedFile) (*core.StructuredReview, string, error)
+	// RegenerateReview replays the archived prompt inputs of a review with
+	// modelName (default: the generator) and, if set, another prompt
+	// template. It returns the archived and the regenerated review.
+	RegenerateReview(ctx context.Context, inputs core.PromptInputs, modelName, promptTemplate string) (*core.StructuredReview, *core.StructuredReview, error)
 	GenerateProjectContext(ctx context.Context, collectionName, embedderModelName string) (string, error)
 	GenerateArchSummaries(ctx context.Context, collectionName, embedderModelName, repoPath string, targetPaths []string) error
+	// RegenerateArchSummaries regenerates the architectural summaries of dir
+	// and the directories below it, or of the whole repository when dir is
+	// empty, even where they are up to date. It returns the number stored.
+	RegenerateArchSummaries(ctx context.Context, collectionName, embedderModelName, repoPath, dir string) (int, error)
+	// ArchGraph returns the directories of the repository at repoPath with
+	// their architectural summaries and the imports between them.
+	ArchGraph(ctx context.Context, collectionName, embedderModelName, repoPath string) (*archgraph.Graph, error)
 	GetTextSplitter() textsplitter.TextSplitter
 	// GeneratorLLM returns the underlying LLM model used for generation.
 	// Used by the native in-process agent to drive its ReAct loop directly.
@@ -58,6 +98,10 @@ type Service interface {
 	// instance is returned.  Used by the native agent to load a dedicated
 	// implementation model separate from the review model.
 	GetLLM(ctx context.Context, modelName string) (llms.Model, error)
+	// SetGeneratorModel switches the generator returned by GeneratorLLM, and
+	// used by reviews and Q&A, to modelName. Calls already in flight finish on
+	// the previous model. An empty modelName restores the configured model.
+	SetGeneratorModel(ctx context.Context, modelName string) error
 }
 
 // ttlCacheEntry holds a cached value with an expiry timestamp.
@@ -130,6 +174,8 @@ type ragService struct {
 	vectorStore    storage.VectorStore
 	store          storage.Store
 	generatorLLM   llms.Model
+	generator      *llm.SwitchableModel // generatorLLM, switchable at runtime
+	baseGenerator  llms.Model           // the configured generator model
 	reranker       schema.Reranker
 	parserRegistry parsers.ParserRegistry
 	splitter       textsplitter.TextSplitter
@@ -139,7 +185,8 @@ type ragService struct {
 	indexer        *indexpkg.Indexer
 	reviewService  *reviewpkg.Service
 	logger         *slog.Logger
-	llmCache       *ttlCache // modelName -> LLM instance
+	llmCache       *ttlCache    // modelName -> LLM instance
+	pipeline       llm.Pipeline // applied to every model created
 }
 
 // NewService creates and returns a new RAG [Service].
@@ -150,12 +197,29 @@ func NewService(
 	promptMgr *llm.PromptManager,
 	vs storage.VectorStore,
 	dbStore storage.Store,
+	artifactStore *artifacts.Store,
 	gen llms.Model,
+	genObserver llm.CallObserver,
+	genBreaker llm.CircuitBreaker,
+	pipeline llm.Pipeline,
 	reranker schema.Reranker,
 	pr parsers.ParserRegistry,
 	splitter textsplitter.TextSplitter,
 	logger *slog.Logger,
 ) (Service, error) {
+	// Components hold the switchable wrapper so that a generator model change
+	// made at runtime applies to their next call.
+	gen = pipeline.Wrap(cfg.AI.GeneratorModel, gen)
+	baseGenerator := gen
+	generator := llm.NewSwitchableModel(cfg.AI.GeneratorModel, gen)
+	if genObserver != nil {
+		generator.Observe(genObserver)
+	}
+	if genBreaker != nil {
+		generator.Guard(genBreaker)
+	}
+	gen = generator
+
 	// Register code-aware sparse provider for hybrid search.
 	// Uses camelCase/snake_case splitting + FNV hashing instead of the BGE text tokenizer,
 	// which treats identifiers like processPayment and XMLParser as better search signals.
@@ -207,6 +271,9 @@ func NewService(
 		EmbedderModel:  cfg.AI.EmbedderModel,
 		LLM:            gen,
 		PromptMgr:      promptMgr,
+		Workers:        cfg.AI.IndexWorkers,
+		MaxFileSizeKB:  cfg.Limits.MaxFileSizeKB,
+		MaxFiles:       cfg.Limits.MaxFiles,
 	}
 
 	r := &ragService{
@@ -215,6 +282,8 @@ func NewService(
 		vectorStore:    vs,
 		store:          dbStore,
 		generatorLLM:   gen,
+		generator:      generator,
+		baseGenerator:  baseGenerator,
 		reranker:       reranker,
 		parserRegistry: pr,
 		splitter:       splitter,
@@ -223,6 +292,7 @@ func NewService(
 		qaService:      questionpkg.NewService(qaCfg),
 		indexer:        indexpkg.New(indexerCfg),
 		llmCache:       newTTLCache(1*time.Hour, 20),
+		pipeline:       pipeline,
 	}
 
 	contextCfg := contextpkg.Config{
@@ -234,8 +304,12 @@ func NewService(
 		GetLLM:         r.getOrCreateLLM,
 		Reranker:       reranker,
 		ContextPacker:  contextPacker,
-		HyDECache:      newTTLCache(30*time.Minute, 500),
-		Logger:         logger.With("component", "context_builder"),
+		NewContextPacker: func(tokenBudget int) (*contextpacker.Packer, error) {
+			return newContextPacker(gen, tokenBudget, logger)
+		},
+		HyDECache: newTTLCache(30*time.Minute, 500),
+		Logger:    logger.With("component", "context_builder"),
+		Symbols:   dbStore,
 	}
 	r.contextBuilder = contextpkg.NewCachingBuilder(
 		contextpkg.NewBuilder(contextCfg),
@@ -250,8 +324,15 @@ func NewService(
 		Logger:                 logger,
 		ConsensusTimeout:       cfg.AI.ConsensusTimeout,
 		ConsensusQuorum:        cfg.AI.ConsensusQuorum,
+		ConsensusMaxWorkers:    cfg.AI.ConsensusMaxWorkers,
 		BuildContextWithImpact: r.contextBuilder.BuildRelevantContextWithImpact,
 		EmbedderModel:          cfg.AI.EmbedderModel,
+		GeneratorModel:         cfg.AI.GeneratorModel,
+		ReviewOutputFormat:     cfg.AI.ReviewOutputFormat,
+		Artifacts:              artifactStore,
+		RetrievalFor:           cfg.AI.RetrievalFor,
+		DegradedReviews:        cfg.AI.DegradedReviews,
+		MaxLintFindings:        cfg.StaticAnalysis.MaxFindings,
 	}
 
 	// Wire Phase 2 investigator when a fast model is configured.
@@ -267,6 +348,11 @@ func NewService(
 		reviewCfg.Investigate = investigator.Investigate
 	}
 
+	if cfg.Dependencies.Enabled {
+		checker := depcheck.NewChecker(cfg.Dependencies, &http.Client{Timeout: 30 * time.Second})
+		reviewCfg.CheckDependencies = checker.Check
+	}
+
 	r.reviewService = reviewpkg.NewService(reviewCfg)
 
 	return r, nil
@@ -310,7 +396,7 @@ func (r *ragService) GetLLM(ctx context.Context, modelName string) (llms.Model,
 func (r *ragService) getOrCreateLLM(ctx context.Context, modelName string) (llms.Model, error) {
 	// Return the initialized generator if model matches
 	if modelName == r.cfg.AI.GeneratorModel {
-		return r.generatorLLM, nil
+		return r.baseGenerator, nil
 	}
 
 	// Check cache first
@@ -329,7 +415,7 @@ func (r *ragService) getOrCreateLLM(ctx context.Context, modelName string) (llms
 			}
 		}
 
-		r.logger.Info("creating LLM instance", "model", modelName)
+		r.logger.InfoContext(ctx, "creating LLM instance", "model", modelName)
 
 		var newLLM llms.Model
 		var err error
@@ -340,7 +426,7 @@ func (r *ragService) getOrCreateLLM(ctx context.Context, modelName string) (llms
 			// Fallback/Default to Ollama
 			headerTimeout, pErr := time.ParseDuration(r.cfg.AI.HTTPResponseHeaderTimeout)
 			if pErr != nil {
-				r.logger.Warn("invalid http_response_header_timeout, using default",
+				r.logger.WarnContext(ctx, "invalid http_response_header_timeout, using default",
 					"configured", r.cfg.AI.HTTPResponseHeaderTimeout,
 					"error", pErr,
 				)
@@ -356,7 +442,7 @@ func (r *ragService) getOrCreateLLM(ctx context.Context, modelName string) (llms
 				ollama.WithServerURL(r.cfg.AI.OllamaHost),
 				ollama.WithAPIKey(r.cfg.AI.OllamaAPIKey),
 				ollama.WithModel(modelName),
-				ollama.WithHTTPClient(httpclient.NewClient(clientCfg)),
+				ollama.WithHTTPClient(netutil.Apply(httpclient.NewClient(clientCfg))),
 				ollama.WithRetryAttempts(3),
 				ollama.WithRetryDelay(2*time.Second),
 			)
@@ -367,6 +453,7 @@ func (r *ragService) getOrCreateLLM(ctx context.Context, modelName string) (llms
 		}
 
 		// Store in cache for future use
+		newLLM = r.pipeline.Wrap(modelName, newLLM)
 		r.llmCache.Store(modelName, newLLM)
 		return newLLM, nil
 	})
@@ -380,15 +467,43 @@ func (r *ragService) getOrCreateLLM(ctx context.Context, modelName string) (llms
 	return llmModel, nil
 }
 
-// AnswerQuestion retrieves relevant documents and generates an answer via LLM.
-func (r *ragService) AnswerQuestion(ctx context.Context, collectionName, embedderModelName, question string, history []string) (string, error) {
+// SetGeneratorModel switches the generator to modelName, creating the model
+// through getOrCreateLLM.
+func (r *ragService) SetGeneratorModel(ctx context.Context, modelName string) error {
+	if modelName == "" {
+		modelName = r.cfg.AI.GeneratorModel
+	}
+	model, err := r.getOrCreateLLM(ctx, modelName)
+	if err != nil {
+		return err
+	}
+	r.generator.Switch(modelName, model)
+	r.logger.InfoContext(ctx, "switched generator model", "model", modelName)
+	return nil
+}
+
+// AnswerQuestion retrieves relevant documents and generates an answer via LLM
+// that cites them.
+func (r *ragService) AnswerQuestion(ctx context.Context, collectionName, embedderModelName, question string, history []string) (*core.Answer, error) {
+	return r.newQAService(ctx).AnswerQuestion(ctx, collectionName, embedderModelName, question, history)
+}
+
+// AnswerQuestionAcross retrieves relevant documents from each repository and
+// generates an answer via LLM that cites them.
+func (r *ragService) AnswerQuestionAcross(ctx context.Context, repos []core.RepoCollection, embedderModelName, question string, history []string) (*core.Answer, error) {
+	return r.newQAService(ctx).AnswerQuestionAcross(ctx, repos, embedderModelName, question, history)
+}
+
+// newQAService creates the question service with the validator LLM, when
+// one is configured.
+func (r *ragService) newQAService(ctx context.Context) *questionpkg.QAService {
 	// Dynamically fetch the validator LLM if configured
 	var validatorLLM llms.Model
 	var err error
 	if r.cfg.AI.FastModel != "" {
 		validatorLLM, err = r.getOrCreateLLM(ctx, r.cfg.AI.FastModel)
 		if err != nil {
-			r.logger.Warn("failed to create validator LLM for QA, falling back to basic QA", "error", err)
+			r.logger.WarnContext(ctx, "failed to create validator LLM for QA, falling back to basic QA", "error", err)
 			validatorLLM = nil
 		}
 	}
@@ -402,12 +517,21 @@ func (r *ragService) AnswerQuestion(ctx context.Context, collectionName, embedde
 		ContextFormat: r.contextBuilder.BuildContextForPrompt,
 	}
 
-	svc := questionpkg.NewService(qaCfg)
-	return svc.AnswerQuestion(ctx, collectionName, embedderModelName, question, history)
+	return questionpkg.NewService(qaCfg)
+}
+
+// SummarizeConversation condenses chat turns with the generator LLM.
+func (r *ragService) SummarizeConversation(ctx context.Context, summary string, turns []string) (string, error) {
+	svc := questionpkg.NewService(questionpkg.Config{
+		GeneratorLLM: r.generatorLLM,
+		PromptMgr:    r.promptMgr,
+		Logger:       r.logger,
+	})
+	return svc.SummarizeConversation(ctx, summary, turns)
 }
 
 func (r *ragService) ExplainPath(ctx context.Context, collectionName, embedderModelName, path string) (string, error) {
-	r.logger.Info("explaining path", "collection", collectionName, "path", path)
+	r.logger.InfoContext(ctx, "explaining path", "collection", collectionName, "path", path)
 	scopedStore := r.vectorStore.ForRepo(collectionName, embedderModelName)
 
 	docs, err := scopedStore.SimilaritySearch(ctx, path, 1,
@@ -435,56 +559,118 @@ func (r *ragService) ExplainPath(ctx context.Context, collectionName, embedderMo
 }
 
 func (r *ragService) SetupRepoContext(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, repoPath string, progressFn indexpkg.ProgressFunc) error {
-	err := r.indexer.SetupRepoContext(ctx, repoConfig, repo, repoPath, progressFn)
+	model := r.selectEmbedder(ctx, repoConfig, repo)
+	err := r.indexer.WithEmbedder(model).SetupRepoContext(ctx, repoConfig, repo, repoPath, progressFn)
 	if err != nil {
 		return err
 	}
-	if err := r.GenerateArchSummaries(ctx, repo.QdrantCollectionName, r.cfg.AI.EmbedderModel, repoPath, nil); err != nil {
-		r.logger.Warn("failed to generate architectural summaries, continuing without them", "error", err)
+	if err := r.GenerateArchSummaries(ctx, repo.QdrantCollectionName, model, repoPath, nil); err != nil {
+		r.logger.WarnContext(ctx, "failed to generate architectural summaries, continuing without them", "error", err)
 	}
 
-	if err := r.contextBuilder.GeneratePackageSummaries(ctx, repo.QdrantCollectionName, r.cfg.AI.EmbedderModel); err != nil {
-		r.logger.Warn("failed to generate package summaries, continuing without them", "error", err)
+	if err := r.contextBuilder.GeneratePackageSummaries(ctx, repo.QdrantCollectionName, model); err != nil {
+		r.logger.WarnContext(ctx, "failed to generate package summaries, continuing without them", "error", err)
 	}
 
-	r.logger.Info("📉 Synthesizing global Project Context document", "repo", repo.FullName)
-	projectContext, err := r.GenerateProjectContext(ctx, repo.QdrantCollectionName, r.cfg.AI.EmbedderModel)
+	r.logger.InfoContext(ctx, "📉 Synthesizing global Project Context document", "repo", repo.FullName)
+	projectContext, err := r.GenerateProjectContext(ctx, repo.QdrantCollectionName, model)
 	if err != nil {
-		r.logger.Warn("failed to synthesize project context, continuing without it", "error", err)
+		r.logger.WarnContext(ctx, "failed to synthesize project context, continuing without it", "error", err)
 	} else if projectContext != "" {
 		repo.GeneratedContext = projectContext
 		repo.ContextUpdatedAt = sql.NullTime{Time: time.Now(), Valid: true}
 		if err := r.store.UpdateRepository(ctx, repo); err != nil {
-			r.logger.Error("failed to save generated context to database", "error", err)
+			r.logger.ErrorContext(ctx, "failed to save generated context to database", "error", err)
 		} else {
-			r.logger.Info("✅ Global Project Context document saved to database", "length", len(projectContext))
+			r.logger.InfoContext(ctx, "✅ Global Project Context document saved to database", "length", len(projectContext))
 		}
 	}
 
 	// Generate design documents using warden agent (if enabled)
 	if r.cfg.Warden.Enabled && r.cfg.Warden.DesignDocs {
 		if err := r.generateDesignDocuments(ctx, repo); err != nil {
-			r.logger.Warn("failed to generate design documents, continuing without them", "error", err)
+			r.logger.WarnContext(ctx, "failed to generate design documents, continuing without them", "error", err)
 		}
 	}
 
 	return nil
 }
 
+// selectEmbedder returns the embedder model to index repo with. It is the
+// model the collection was built with, or ai.embedder_model. A model chosen
+// by the repository's .code-warden.yml is adopted while nothing is indexed
+// yet; an index built with another model must be moved with
+// "warden-cli vector migrate" instead, as a collection cannot mix models.
+func (r *ragService) selectEmbedder(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository) string {
+	current := repo.Embedder(r.cfg.AI.EmbedderModel)
+	if repoConfig == nil || repoConfig.EmbedderModel == "" || repoConfig.EmbedderModel == current {
+		return current
+	}
+	files, err := r.store.GetFilesForRepo(ctx, repo.ID)
+	if err != nil || len(files) > 0 {
+		r.logger.WarnContext(ctx, "repository config selects another embedder model than its index was built with, run warden-cli vector migrate to switch",
+			"repo", repo.FullName, "index_model", current, "config_model", repoConfig.EmbedderModel)
+		return current
+	}
+	previous := repo.EmbedderModel
+	repo.EmbedderModel = repoConfig.EmbedderModel
+	if err := r.store.UpdateRepository(ctx, repo); err != nil {
+		r.logger.ErrorContext(ctx, "failed to record embedder model of repository", "repo", repo.FullName, "error", err)
+		repo.EmbedderModel = previous
+		return current
+	}
+	r.logger.InfoContext(ctx, "indexing repository with its configured embedder model", "repo", repo.FullName, "model", repoConfig.EmbedderModel)
+	return repoConfig.EmbedderModel
+}
+
+// IndexTree indexes a tree into a standalone collection. Architecture and
+// project summaries are not generated for it.
+func (r *ragService) IndexTree(ctx context.Context, repoConfig *core.RepoConfig, collectionName, repoPath string) error {
+	_, _, err := r.indexer.IndexTree(ctx, repoConfig, collectionName, repoPath)
+	return err
+}
+
+// BuildCollection indexes the clone of repo into collectionName with
+// embedderModel, replacing its contents, and adds the architecture and
+// package summaries. It returns the number of files and documents indexed.
+func (r *ragService) BuildCollection(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, collectionName, embedderModel string) (files, chunks int, err error) {
+	ctx = core.WithUsageStep(core.WithUsageRepo(ctx, repo.FullName), core.UsageStepSummary)
+	files, chunks, err = r.indexer.WithEmbedder(embedderModel).IndexTree(ctx, repoConfig, collectionName, repo.ClonePath)
+	if err != nil {
+		return files, chunks, err
+	}
+	if err := r.GenerateArchSummaries(ctx, collectionName, embedderModel, repo.ClonePath, nil); err != nil {
+		r.logger.WarnContext(ctx, "failed to generate architectural summaries, continuing without them", "collection", collectionName, "error", err)
+	}
+	if err := r.contextBuilder.GeneratePackageSummaries(ctx, collectionName, embedderModel); err != nil {
+		r.logger.WarnContext(ctx, "failed to generate package summaries, continuing without them", "collection", collectionName, "error", err)
+	}
+	return files, chunks, nil
+}
+
+// DeleteCollection drops a standalone collection.
+func (r *ragService) DeleteCollection(ctx context.Context, collectionName string) error {
+	if err := r.vectorStore.DeleteCollection(ctx, collectionName); err != nil {
+		return fmt.Errorf("failed to delete collection %s: %w", collectionName, err)
+	}
+	return nil
+}
+
 func (r *ragService) UpdateRepoContext(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, repoPath string, filesToProcess, filesToDelete []string, progressFn indexpkg.ProgressFunc) error {
-	err := r.indexer.UpdateRepoContext(ctx, repoConfig, repo, repoPath, filesToProcess, filesToDelete, progressFn)
+	model := r.selectEmbedder(ctx, repoConfig, repo)
+	err := r.indexer.WithEmbedder(model).UpdateRepoContext(ctx, repoConfig, repo, repoPath, filesToProcess, filesToDelete, progressFn)
 	if err != nil {
 		return err
 	}
 	// Trigger targeted arch summary re-generation
-	if err := r.GenerateArchSummaries(ctx, repo.QdrantCollectionName, r.cfg.AI.EmbedderModel, repoPath, append(filesToProcess, filesToDelete...)); err != nil {
-		r.logger.Warn("failed to update architectural summaries after sync", "error", err)
+	if err := r.GenerateArchSummaries(ctx, repo.QdrantCollectionName, model, repoPath, append(filesToProcess, filesToDelete...)); err != nil {
+		r.logger.WarnContext(ctx, "failed to update architectural summaries after sync", "error", err)
 	}
 
 	// Regenerate package summaries after incremental update
 	// This fetches all TOC/definition chunks and rebuilds package-level summaries
-	if err := r.contextBuilder.GeneratePackageSummaries(ctx, repo.QdrantCollectionName, r.cfg.AI.EmbedderModel); err != nil {
-		r.logger.Warn("failed to regenerate package summaries after sync", "error", err)
+	if err := r.contextBuilder.GeneratePackageSummaries(ctx, repo.QdrantCollectionName, model); err != nil {
+		r.logger.WarnContext(ctx, "failed to regenerate package summaries after sync", "error", err)
 	}
 
 	return nil
@@ -495,17 +681,17 @@ func (r *ragService) UpdateRepoContext(ctx context.Context, repoConfig *core.Rep
 func (r *ragService) SyncRepoIndex(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, updateResult *core.UpdateResult, progressFn indexpkg.ProgressFunc) error {
 	switch {
 	case updateResult.IsInitialClone:
-		r.logger.Info("performing initial full indexing", "repo", repo.FullName)
+		r.logger.InfoContext(ctx, "performing initial full indexing", "repo", repo.FullName)
 		return r.SetupRepoContext(ctx, repoConfig, repo, updateResult.RepoPath, progressFn)
 	case len(updateResult.FilesToAddOrUpdate) > 0 || len(updateResult.FilesToDelete) > 0:
-		r.logger.Info("performing incremental indexing",
+		r.logger.InfoContext(ctx, "performing incremental indexing",
 			"repo", repo.FullName,
 			"added_or_updated", len(updateResult.FilesToAddOrUpdate),
 			"deleted", len(updateResult.FilesToDelete),
 		)
 		return r.UpdateRepoContext(ctx, repoConfig, repo, updateResult.RepoPath, updateResult.FilesToAddOrUpdate, updateResult.FilesToDelete, progressFn)
 	default:
-		r.logger.Info("no changes detected, skipping indexing", "repo", repo.FullName)
+		r.logger.InfoContext(ctx, "no changes detected, skipping indexing", "repo", repo.FullName)
 		return nil
 	}
 }
@@ -518,14 +704,29 @@ func (r *ragService) GenerateReview(ctx context.Context, repoConfig *core.RepoCo
 	return r.reviewService.GenerateReview(ctx, repoConfig, repo, event, diff, changedFiles)
 }
 
-func (r *ragService) GenerateReReview(ctx context.Context, repo *storage.Repository, event *core.GitHubEvent, originalReview *core.Review, ghClient internalgithub.Client, changedFiles []internalgithub.ChangedFile) (*core.StructuredReview, string, error) {
-	return r.reviewService.GenerateReReview(ctx, repo, event, originalReview, ghClient, changedFiles)
+func (r *ragService) GenerateReReview(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, originalReview *core.Review, ghClient internalgithub.Client, changedFiles []internalgithub.ChangedFile) (*core.StructuredReview, string, error) {
+	return r.reviewService.GenerateReReview(ctx, repoConfig, repo, event, originalReview, ghClient, changedFiles)
 }
 
 func (r *ragService) GenerateConsensusReview(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, models []string, diff string, changedFiles []internalgithub.ChangedFile) (*core.StructuredReview, string, error) {
 	return r.reviewService.GenerateConsensusReview(ctx, repoConfig, repo, event, models, diff, changedFiles)
 }
 
+// RegenerateReview replays archived prompt inputs with another model or prompt.
+func (r *ragService) RegenerateReview(ctx context.Context, inputs core.PromptInputs, modelName, promptTemplate string) (*core.StructuredReview, *core.StructuredReview, error) {
+	return r.reviewService.RegenerateReview(ctx, inputs, modelName, promptTemplate)
+}
+
+// GenerateWalkthrough explains a pull request to someone new to the codebase.
+func (r *ragService) GenerateWalkthrough(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, diff string, changedFiles []internalgithub.ChangedFile) (string, error) {
+	return r.reviewService.GenerateWalkthrough(ctx, repoConfig, repo, event, diff, changedFiles)
+}
+
+// ReplyToSuggestion answers a "/warden explain" or "/warden fix" reply to a suggestion.
+func (r *ragService) ReplyToSuggestion(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, event *core.GitHubEvent, thread *core.SuggestionThread) (string, error) {
+	return r.reviewService.ReplyToSuggestion(ctx, repoConfig, repo, event, thread)
+}
+
 // GenerateComparisonSummaries generates architectural summaries for multiple directories.
 func (r *ragService) GenerateComparisonSummaries(ctx context.Context, models []string, repoPath string, relPaths []string) (map[string]map[string]string, error) {
 	return r.contextBuilder.GenerateComparisonSummaries(ctx, models, repoPath, relPaths)
@@ -536,6 +737,16 @@ func (r *ragService) GenerateArchSummaries(ctx context.Context, collectionName,
 	return r.contextBuilder.GenerateArchSummaries(ctx, collectionName, embedderModelName, repoPath, targetPaths)
 }
 
+// RegenerateArchSummaries force-regenerates architectural summaries.
+func (r *ragService) RegenerateArchSummaries(ctx context.Context, collectionName, embedderModelName, repoPath, dir string) (int, error) {
+	return r.contextBuilder.RegenerateArchSummaries(ctx, collectionName, embedderModelName, repoPath, dir)
+}
+
+// ArchGraph builds the architecture graph of the repository.
+func (r *ragService) ArchGraph(ctx context.Context, collectionName, embedderModelName, repoPath string) (*archgraph.Graph, error) {
+	return r.contextBuilder.ArchGraph(ctx, collectionName, embedderModelName, repoPath)
+}
+
 // GenerateProjectContext synthesizes all architectural summaries into a global project context.
 func (r *ragService) GenerateProjectContext(ctx context.Context, collectionName, embedderModelName string) (string, error) {
 	return r.contextBuilder.GenerateProjectContext(ctx, collectionName, embedderModelName)
@@ -550,11 +761,12 @@ func (r *ragService) generateDesignDocuments(ctx context.Context, repo *storage.
 	repoOwner := parts[0]
 	repoName := parts[1]
 
-	r.logger.Info("🔍 Starting design document generation", "repo", repo.FullName)
+	r.logger.InfoContext(ctx, "🔍 Starting design document generation", "repo", repo.FullName)
+	model := repo.Embedder(r.cfg.AI.EmbedderModel)
 
 	// Create search callback
 	searchCallback := func(ctx context.Context, collectionName, query string, limit int, chunkType string) ([]map[string]any, error) {
-		scopedStore := r.vectorStore.ForRepo(collectionName, r.cfg.AI.EmbedderModel)
+		scopedStore := r.vectorStore.ForRepo(collectionName, model)
 
 		var opts []vectorstores.Option
 		if chunkType != "" {
@@ -584,7 +796,7 @@ func (r *ragService) generateDesignDocuments(ctx context.Context, repo *storage.
 
 	// Create structure callback
 	structureCallback := func(ctx context.Context, collectionName, root string) (string, error) {
-		return r.ExplainPath(ctx, collectionName, r.cfg.AI.EmbedderModel, root)
+		return r.ExplainPath(ctx, collectionName, model, root)
 	}
 
 	// Create warden integration
@@ -596,7 +808,7 @@ func (r *ragService) generateDesignDocuments(ctx context.Context, repo *storage.
 	integration, err := warden.NewIntegration(warden.IntegrationConfig{
 		LLM:           r.generatorLLM,
 		VectorStore:   r.vectorStore,
-		EmbedderModel: r.cfg.AI.EmbedderModel,
+		EmbedderModel: model,
 		MaxIterations: maxIterations,
 		Logger:        r.logger.With("component", "warden"),
 		SearchCode:    searchCallback,
@@ -613,7 +825,7 @@ func (r *ragService) generateDesignDocuments(ctx context.Context, repo *storage.
 	}
 
 	if docs != nil {
-		r.logger.Info("✅ Design documents generated",
+		r.logger.InfoContext(ctx, "✅ Design documents generated",
 			"repo", repo.FullName,
 			"count", len(docs.Documents))
 	}
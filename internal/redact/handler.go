@@ -0,0 +1,79 @@
+package redact
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler is a [slog.Handler] that redacts secrets from log messages and
+// from string, error and [fmt.Stringer] attribute values before passing
+// records to the wrapped handler.
+type Handler struct {
+	next slog.Handler
+}
+
+// NewHandler returns a [Handler] that writes redacted records to next.
+func NewHandler(next slog.Handler) *Handler {
+	return &Handler{next: next}
+}
+
+// Enabled reports whether the wrapped handler handles records at level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle redacts r and passes it to the wrapped handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	msg, _ := Secrets(r.Message)
+	out := slog.NewRecord(r.Time, r.Level, msg, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+// WithAttrs redacts attrs and returns a handler that includes them.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &Handler{next: h.next.WithAttrs(redacted)}
+}
+
+// WithGroup returns a handler that qualifies later attributes with name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		if s, n := Secrets(v.String()); n > 0 {
+			return slog.String(a.Key, s)
+		}
+	case slog.KindGroup:
+		group := v.Group()
+		redacted := make([]any, len(group))
+		for i, ga := range group {
+			redacted[i] = redactAttr(ga)
+		}
+		return slog.Group(a.Key, redacted...)
+	case slog.KindAny:
+		var text string
+		switch x := v.Any().(type) {
+		case error:
+			text = x.Error()
+		case interface{ String() string }:
+			text = x.String()
+		default:
+			return slog.Attr{Key: a.Key, Value: v}
+		}
+		if s, n := Secrets(text); n > 0 {
+			return slog.String(a.Key, s)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,124 @@
+// Package redact removes likely secrets from text before it is sent to an LLM,
+// written to logs or posted to GitHub.
+package redact
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// minSecretEntropy is the Shannon entropy (bits per character) above which a
+// value assigned to a secret-looking key is treated as a real credential
+// rather than a placeholder such as "changeme" or "${API_KEY}".
+const minSecretEntropy = 3.0
+
+type rule struct {
+	kind string
+	re   *regexp.Regexp
+	// group is the submatch to replace; 0 replaces the whole match.
+	group int
+	// checkEntropy requires the replaced value to look random.
+	checkEntropy bool
+}
+
+var rules = []rule{
+	{kind: "private_key", re: regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY-----`)},
+	{kind: "aws_access_key", re: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "github_token", re: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{kind: "slack_token", re: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+	{kind: "google_api_key", re: regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`)},
+	{kind: "stripe_key", re: regexp.MustCompile(`\b[sr]k_live_[0-9A-Za-z]{24,}\b`)},
+	{kind: "api_key", re: regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_\-]{20,}\b`)},
+	{kind: "jwt", re: regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}\b`)},
+	{kind: "bearer_token", re: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/\-]{16,}=*)`), group: 1},
+	{kind: "connection_string", re: regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.\-]*://[^\s:/@'"]+:([^\s@/'"]+)@`), group: 1},
+	// Key/value DSNs such as "host=db user=warden password=secret".
+	{kind: "dsn_password", re: regexp.MustCompile(`(?i)\bpassword=([^\s'"&;]+)`), group: 1},
+	{
+		kind:         "secret",
+		re:           regexp.MustCompile(`(?i)(?:password|passwd|secret|api[_\-]?key|access[_\-]?key|auth[_\-]?token|client[_\-]?secret|token)["']?\s*[:=]\s*["']([^"'\s]{8,})["']`),
+		group:        1,
+		checkEntropy: true,
+	},
+}
+
+// Secrets replaces likely secrets in text with [REDACTED:<kind>] placeholders
+// and returns the redacted text together with the number of replacements.
+func Secrets(text string) (string, int) {
+	if text == "" {
+		return text, 0
+	}
+
+	count := 0
+	for _, r := range rules {
+		text = r.re.ReplaceAllStringFunc(text, func(match string) string {
+			if r.group == 0 {
+				count++
+				return placeholder(r.kind)
+			}
+			sub := r.re.FindStringSubmatchIndex(match)
+			if len(sub) < 2*(r.group+1) || sub[2*r.group] < 0 {
+				return match
+			}
+			start, end := sub[2*r.group], sub[2*r.group+1]
+			value := match[start:end]
+			if strings.HasPrefix(value, "[REDACTED:") {
+				return match
+			}
+			if r.checkEntropy && entropy(value) < minSecretEntropy {
+				return match
+			}
+			count++
+			return match[:start] + placeholder(r.kind) + match[end:]
+		})
+	}
+	return text, count
+}
+
+func placeholder(kind string) string {
+	return "[REDACTED:" + kind + "]"
+}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	freq := make(map[rune]float64)
+	n := 0.0
+	for _, r := range s {
+		freq[r]++
+		n++
+	}
+	var h float64
+	for _, c := range freq {
+		p := c / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// Error returns err with secrets redacted from its message, for errors that
+// are shown outside the process, e.g. in GitHub check runs and comments. The
+// result still unwraps to err, so errors.Is and errors.As keep working.
+// Errors without secrets are returned unchanged.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg, n := Secrets(err.Error())
+	if n == 0 {
+		return err
+	}
+	return &redactedError{msg: msg, err: err}
+}
+
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+func (e *redactedError) Unwrap() error { return e.err }
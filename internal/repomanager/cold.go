@@ -0,0 +1,140 @@
+package repomanager
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/objectstore"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// FreezeRepo moves a repository's vector collection into cold storage: the
+// collection is snapshotted to the object store and then dropped from Qdrant.
+// The next lookup or sync of the repository restores it transparently.
+func (m *manager) FreezeRepo(ctx context.Context, repoFullName string) error {
+	if !m.cfg.Storage.UsesQdrant() {
+		return fmt.Errorf("cold storage requires the qdrant vector store, not %q", m.cfg.Storage.VectorStoreProvider)
+	}
+
+	mu := m.lockFor(repoFullName)
+	mu.Lock()
+	defer mu.Unlock()
+
+	rec, err := m.store.GetRepositoryByFullName(ctx, repoFullName)
+	if err != nil {
+		return fmt.Errorf("query repository for freeze: %w", err)
+	}
+	if rec.IsCold() {
+		m.logger.InfoContext(ctx, "repository already in cold storage", "repo", repoFullName, "key", rec.ColdSnapshotKey)
+		return nil
+	}
+
+	start := time.Now()
+	key := fmt.Sprintf("snapshots/%s/%d.snapshot", rec.QdrantCollectionName, start.Unix())
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(m.snapshots.Snapshot(ctx, rec.QdrantCollectionName, pw))
+	}()
+	if err := m.objects.Put(ctx, key, pr); err != nil {
+		pr.CloseWithError(err)
+		return fmt.Errorf("store snapshot for %s: %w", repoFullName, err)
+	}
+
+	// Persist the cold marker before dropping the collection so a crash in
+	// between leaves a restorable record rather than a lost index.
+	rec.ColdSnapshotKey = key
+	rec.ColdSince = sql.NullTime{Time: start, Valid: true}
+	if err := m.store.UpdateRepository(ctx, rec); err != nil {
+		_ = m.objects.Delete(ctx, key)
+		return fmt.Errorf("mark repository cold: %w", err)
+	}
+	if err := m.vectorStore.DeleteCollection(ctx, rec.QdrantCollectionName); err != nil {
+		m.logger.WarnContext(ctx, "snapshot stored but failed to drop collection", "repo", repoFullName, "error", err)
+	}
+
+	m.logger.InfoContext(ctx, "repository moved to cold storage",
+		"repo", repoFullName,
+		"collection", rec.QdrantCollectionName,
+		"key", key,
+		"duration", time.Since(start).Round(time.Millisecond),
+	)
+	return nil
+}
+
+// ThawRepo restores a cold repository's collection. It is a no-op for warm
+// repositories.
+func (m *manager) ThawRepo(ctx context.Context, repoFullName string) error {
+	_, err := m.restore(ctx, repoFullName)
+	return err
+}
+
+// restore returns the record of repoFullName after restoring its collection
+// from cold storage, if it was frozen.
+func (m *manager) restore(ctx context.Context, repoFullName string) (*storage.Repository, error) {
+	mu := m.lockFor(repoFullName)
+	mu.Lock()
+	defer mu.Unlock()
+
+	rec, err := m.store.GetRepositoryByFullName(ctx, repoFullName)
+	if err != nil {
+		return nil, fmt.Errorf("query repository for thaw: %w", err)
+	}
+	if rec == nil {
+		return nil, nil
+	}
+	if err := m.thaw(ctx, rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// thaw restores rec's collection from its snapshot. Callers must hold the
+// repository lock. If the snapshot is missing, the repository is marked warm
+// with no indexed SHA so the next sync performs a full re-index.
+func (m *manager) thaw(ctx context.Context, rec *storage.Repository) error {
+	if !rec.IsCold() {
+		return nil
+	}
+	if !m.cfg.Storage.UsesQdrant() {
+		return fmt.Errorf("repository %s is in cold storage, which requires the qdrant vector store, not %q", rec.FullName, m.cfg.Storage.VectorStoreProvider)
+	}
+
+	start := time.Now()
+	key := rec.ColdSnapshotKey
+	m.logger.InfoContext(ctx, "restoring repository from cold storage", "repo", rec.FullName, "key", key)
+
+	r, err := m.objects.Get(ctx, key)
+	switch {
+	case errors.Is(err, objectstore.ErrNotFound):
+		m.logger.WarnContext(ctx, "cold snapshot missing, falling back to full re-index", "repo", rec.FullName, "key", key)
+		rec.LastIndexedSHA = ""
+	case err != nil:
+		return fmt.Errorf("open cold snapshot for %s: %w", rec.FullName, err)
+	default:
+		restoreErr := m.snapshots.Restore(ctx, rec.QdrantCollectionName, r)
+		r.Close()
+		if restoreErr != nil {
+			return fmt.Errorf("restore collection for %s: %w", rec.FullName, restoreErr)
+		}
+	}
+
+	rec.ColdSnapshotKey = ""
+	rec.ColdSince = sql.NullTime{}
+	if err := m.store.UpdateRepository(ctx, rec); err != nil {
+		return fmt.Errorf("mark repository warm: %w", err)
+	}
+	if err := m.objects.Delete(ctx, key); err != nil {
+		m.logger.WarnContext(ctx, "failed to delete cold snapshot after restore", "key", key, "error", err)
+	}
+
+	m.logger.InfoContext(ctx, "repository restored from cold storage",
+		"repo", rec.FullName,
+		"duration", time.Since(start).Round(time.Millisecond),
+	)
+	return nil
+}
@@ -0,0 +1,99 @@
+package repomanager
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/objectstore"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+type fakeSnapshotter struct {
+	restored map[string]string
+}
+
+func (f *fakeSnapshotter) Snapshot(_ context.Context, collection string, w io.Writer) error {
+	_, err := io.WriteString(w, "snapshot of "+collection)
+	return err
+}
+
+func (f *fakeSnapshotter) Restore(_ context.Context, collection string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if f.restored == nil {
+		f.restored = make(map[string]string)
+	}
+	f.restored[collection] = string(data)
+	return nil
+}
+
+func newColdManager(t *testing.T, provider string) (*manager, *mockStore, *fakeSnapshotter) {
+	t.Helper()
+	ctx := context.Background()
+	objects := objectstore.NewLocal(t.TempDir())
+	key := "snapshots/repo-owner-app/1.snapshot"
+	if err := objects.Put(ctx, key, strings.NewReader("snapshot of repo-owner-app")); err != nil {
+		t.Fatal(err)
+	}
+	store := &mockStore{}
+	_ = store.CreateRepository(ctx, &storage.Repository{
+		FullName:             "owner/app",
+		QdrantCollectionName: "repo-owner-app",
+		LastIndexedSHA:       "abc123",
+		ColdSnapshotKey:      key,
+		ColdSince:            sql.NullTime{Time: time.Now(), Valid: true},
+	})
+	snapshots := &fakeSnapshotter{}
+	return &manager{
+		cfg:         &config.Config{Storage: config.StorageConfig{VectorStoreProvider: provider}},
+		store:       store,
+		vectorStore: &mockVectorStore{},
+		snapshots:   snapshots,
+		objects:     objects,
+		logger:      slog.New(slog.NewTextHandler(os.Stdout, nil)),
+	}, store, snapshots
+}
+
+func TestGetRepoRecord_RestoresColdRepository(t *testing.T) {
+	m, store, snapshots := newColdManager(t, "")
+
+	rec, err := m.GetRepoRecord(context.Background(), "owner/app")
+	if err != nil {
+		t.Fatalf("GetRepoRecord failed: %v", err)
+	}
+	if rec.IsCold() || store.repos["owner/app"].IsCold() {
+		t.Error("repository should be warm after the lookup")
+	}
+	if rec.LastIndexedSHA != "abc123" {
+		t.Errorf("LastIndexedSHA = %q, want abc123", rec.LastIndexedSHA)
+	}
+	if got := snapshots.restored["repo-owner-app"]; got != "snapshot of repo-owner-app" {
+		t.Errorf("restored snapshot = %q", got)
+	}
+}
+
+func TestThaw_RequiresQdrant(t *testing.T) {
+	m, store, snapshots := newColdManager(t, config.VectorStorePgvector)
+
+	if _, err := m.GetRepoRecord(context.Background(), "owner/app"); err == nil {
+		t.Fatal("expected an error restoring a cold repository without qdrant")
+	}
+	if !store.repos["owner/app"].IsCold() {
+		t.Error("repository should stay cold")
+	}
+	if len(snapshots.restored) != 0 {
+		t.Errorf("no snapshot should be restored, got %v", snapshots.restored)
+	}
+	if err := m.FreezeRepo(context.Background(), "owner/app"); err == nil {
+		t.Error("expected FreezeRepo to refuse a backend other than qdrant")
+	}
+}
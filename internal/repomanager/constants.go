@@ -4,6 +4,12 @@ import "regexp"
 
 const (
 	maxCollectionNameLength = 255
+	// CollectionNamePrefix starts the names of all repository collections.
+	CollectionNamePrefix = "repo-"
+	// embedderSuffix and a hash of the model end the names of collections
+	// built for another embedder model; see EmbedderCollectionName.
+	embedderSuffix     = "--e"
+	embedderHashLength = 8
 )
 
 var collectionNameRegexp = regexp.MustCompile("[^a-z0-9_-]+")
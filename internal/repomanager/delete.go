@@ -0,0 +1,66 @@
+package repomanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DeleteRepo unregisters a repository. The record is deleted first, so a
+// repository that fails to delete is left untouched; the collection, or the
+// cold snapshot of a frozen repository, and the managed clone are removed
+// after it. Failures to remove those are joined into the returned error and
+// leave orphans for the vector collection GC or a manual cleanup, but the
+// repository stays unregistered.
+//
+// Clones outside storage.repo_path, such as local repositories added from the
+// terminal, belong to the user and are never removed.
+func (m *manager) DeleteRepo(ctx context.Context, repoFullName string) error {
+	mu := m.lockFor(repoFullName)
+	mu.Lock()
+	defer mu.Unlock()
+
+	rec, err := m.store.GetRepositoryByFullName(ctx, repoFullName)
+	if err != nil {
+		return fmt.Errorf("query repository for delete: %w", err)
+	}
+	if err := m.store.DeleteRepository(ctx, rec.ID); err != nil {
+		return fmt.Errorf("delete repository record: %w", err)
+	}
+
+	var errs []error
+	if rec.IsCold() {
+		if err := m.objects.Delete(ctx, rec.ColdSnapshotKey); err != nil {
+			errs = append(errs, fmt.Errorf("delete cold snapshot %s: %w", rec.ColdSnapshotKey, err))
+		}
+	} else if err := m.vectorStore.DeleteCollection(ctx, rec.QdrantCollectionName); err != nil {
+		errs = append(errs, fmt.Errorf("delete collection %s: %w", rec.QdrantCollectionName, err))
+	}
+
+	if m.isManagedClone(rec.ClonePath) {
+		if err := os.RemoveAll(rec.ClonePath); err != nil {
+			errs = append(errs, fmt.Errorf("remove clone %s: %w", rec.ClonePath, err))
+		}
+	} else {
+		m.logger.InfoContext(ctx, "leaving unmanaged clone in place", "repo", repoFullName, "path", rec.ClonePath)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		m.logger.WarnContext(ctx, "repository unregistered, but some of its data was not removed", "repo", repoFullName, "error", err)
+		return err
+	}
+	m.logger.InfoContext(ctx, "repository deleted", "repo", repoFullName, "collection", rec.QdrantCollectionName, "path", rec.ClonePath)
+	return nil
+}
+
+// isManagedClone reports whether path lies inside storage.repo_path.
+func (m *manager) isManagedClone(path string) bool {
+	if path == "" || m.cfg.Storage.RepoPath == "" {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Clean(m.cfg.Storage.RepoPath), filepath.Clean(path))
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,79 @@
+package repomanager
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+func TestDeleteRepo(t *testing.T) {
+	ctx := context.Background()
+	repoPath := t.TempDir()
+	managed := filepath.Join(repoPath, "owner", "app")
+	local := t.TempDir()
+	if err := os.MkdirAll(managed, 0o750); err != nil {
+		t.Fatal(err)
+	}
+
+	store := &mockStore{}
+	_ = store.CreateRepository(ctx, &storage.Repository{FullName: "owner/app", ClonePath: managed, QdrantCollectionName: "repo-owner-app"})
+	_ = store.CreateRepository(ctx, &storage.Repository{FullName: "me/local", ClonePath: local, QdrantCollectionName: "repo-me-local"})
+	vectors := &mockVectorStore{}
+	m := &manager{
+		cfg:         &config.Config{Storage: config.StorageConfig{RepoPath: repoPath}},
+		store:       store,
+		vectorStore: vectors,
+		logger:      slog.New(slog.NewTextHandler(os.Stdout, nil)),
+	}
+
+	if err := m.DeleteRepo(ctx, "owner/app"); err != nil {
+		t.Fatalf("DeleteRepo failed: %v", err)
+	}
+	if _, ok := store.repos["owner/app"]; ok {
+		t.Error("repository record should be deleted")
+	}
+	if _, err := os.Stat(managed); !os.IsNotExist(err) {
+		t.Errorf("managed clone should be removed, stat error: %v", err)
+	}
+
+	// A clone outside storage.repo_path is the user's and stays.
+	if err := m.DeleteRepo(ctx, "me/local"); err != nil {
+		t.Fatalf("DeleteRepo failed: %v", err)
+	}
+	if _, err := os.Stat(local); err != nil {
+		t.Errorf("unmanaged clone should stay: %v", err)
+	}
+	if want := []string{"repo-owner-app", "repo-me-local"}; !slices.Equal(vectors.deleted, want) {
+		t.Errorf("deleted collections = %v, want %v", vectors.deleted, want)
+	}
+
+	if err := m.DeleteRepo(ctx, "owner/app"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("deleting an unknown repository: got %v, want ErrNotFound", err)
+	}
+}
+
+func TestIsManagedClone(t *testing.T) {
+	m := &manager{cfg: &config.Config{Storage: config.StorageConfig{RepoPath: "/data/repos"}}}
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{path: "/data/repos/owner/app", want: true},
+		{path: "/data/repos", want: false},
+		{path: "/data/repos-other/app", want: false},
+		{path: "/data/repos/../secrets", want: false},
+		{path: "", want: false},
+	}
+	for _, tt := range tests {
+		if got := m.isManagedClone(tt.path); got != tt.want {
+			t.Errorf("isManagedClone(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -5,4 +5,5 @@ import "errors"
 var (
 	ErrRepoNotFound      = errors.New("git repository not found on disk")
 	ErrRepoNameDetection = errors.New("cannot detect repo name from remotes")
+	ErrDirtyWorktree     = errors.New("managed clone has uncommitted changes")
 )
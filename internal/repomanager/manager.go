@@ -2,6 +2,7 @@ package repomanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -14,6 +15,8 @@ import (
 	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/gitutil"
+	"github.com/sevigo/code-warden/internal/objectstore"
+	"github.com/sevigo/code-warden/internal/pathutil"
 	"github.com/sevigo/code-warden/internal/storage"
 )
 
@@ -23,17 +26,33 @@ type manager struct {
 	logger      *slog.Logger
 	vectorStore storage.VectorStore
 	gitClient   *gitutil.Client
+	snapshots   storage.CollectionSnapshotter
+	objects     objectstore.Store
 	repoMux     sync.Map
 }
 
 //go:generate mockgen -destination=../../mocks/mock_repomanager.go -package=mocks github.com/sevigo/code-warden/internal/repomanager RepoManager
 type RepoManager interface {
 	SyncRepo(ctx context.Context, event *core.GitHubEvent, token string) (*core.UpdateResult, error)
+	// GetRepoRecord returns a repository's record, restoring its collection
+	// first if the repository is in cold storage.
 	GetRepoRecord(ctx context.Context, repoFullName string) (*storage.Repository, error)
 	UpdateRepoSHA(ctx context.Context, repoFullName, newSHA string) error
 	ScanLocalRepo(ctx context.Context, repoPath, repoFullName string, force bool) (*core.UpdateResult, error)
+	// GetRepoRecordByPath is GetRepoRecord for the repository cloned at repoPath.
 	GetRepoRecordByPath(ctx context.Context, repoPath string) (*storage.Repository, error)
 	LoadRepoConfig(repoPath string) (*core.RepoConfig, error)
+	// FreezeRepo snapshots a repository's collection to cold storage and drops it from Qdrant.
+	FreezeRepo(ctx context.Context, repoFullName string) error
+	// ThawRepo restores a cold repository's collection. SyncRepo, ScanLocalRepo
+	// and the GetRepoRecord lookups do this automatically.
+	ThawRepo(ctx context.Context, repoFullName string) error
+	// DeleteRepo removes a repository's record, collection and managed clone.
+	DeleteRepo(ctx context.Context, repoFullName string) error
+	// PinSnapshot checks out the commit of a sync into a temporary worktree,
+	// so a review keeps reading the synced files after the clone moves on.
+	// release removes the worktree again.
+	PinSnapshot(ctx context.Context, result *core.UpdateResult) (path string, release func(), err error)
 	// Clear Locks removes all cached repository locks to free memory.
 	ClearLocks()
 }
@@ -44,6 +63,7 @@ func New(
 	store storage.Store,
 	vectorStore storage.VectorStore,
 	gitClient *gitutil.Client,
+	objects objectstore.Store,
 	logger *slog.Logger,
 ) RepoManager {
 	return &manager{
@@ -52,6 +72,8 @@ func New(
 		logger:      logger,
 		vectorStore: vectorStore,
 		gitClient:   gitClient,
+		snapshots:   storage.NewQdrantSnapshotter(cfg.Storage.QdrantHTTPURL),
+		objects:     objects,
 	}
 }
 
@@ -81,7 +103,7 @@ func (m *manager) SyncRepo(ctx context.Context, ev *core.GitHubEvent, token stri
 	if token == "" && ev.InstallationID > 0 {
 		_, instToken, err := github.CreateInstallationClient(ctx, m.cfg, ev.InstallationID, m.logger)
 		if err != nil {
-			m.logger.Warn("failed to create installation token, falling back to config token",
+			m.logger.WarnContext(ctx, "failed to create installation token, falling back to config token",
 				"repo", ev.RepoFullName,
 				"installation_id", ev.InstallationID,
 				"error", err)
@@ -105,10 +127,32 @@ func (m *manager) SyncRepo(ctx context.Context, ev *core.GitHubEvent, token stri
 	// 6. If still no token, proceed anyway - public repos don't need authentication
 	// Private repos will fail at clone time with a clear error message
 	if token == "" {
-		m.logger.Info("no token available, proceeding with public clone attempt", "repo", ev.RepoFullName)
+		m.logger.InfoContext(ctx, "no token available, proceeding with public clone attempt", "repo", ev.RepoFullName)
 	}
 
-	return m.syncRepo(ctx, ev, token)
+	result, err := m.syncRepo(ctx, ev, token)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := m.gitClient.TreeSHA(ctx, result.RepoPath, result.DefaultBranchSHA)
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to resolve the tree of the synced commit", "repo", ev.RepoFullName, "sha", result.DefaultBranchSHA, "error", err)
+	}
+	result.TreeSHA = tree
+	return result, nil
+}
+
+// PinSnapshot checks out result.DefaultBranchSHA into a temporary worktree
+// of the clone at result.RepoPath.
+func (m *manager) PinSnapshot(ctx context.Context, result *core.UpdateResult) (string, func(), error) {
+	if result.DefaultBranchSHA == "" {
+		return "", nil, errors.New("sync result has no commit to pin")
+	}
+	path, release, err := m.gitClient.AddWorktree(ctx, result.RepoPath, result.DefaultBranchSHA)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to pin snapshot of %s: %w", result.RepoPath, err)
+	}
+	return path, release, nil
 }
 
 // tryGetInstallationToken attempts to find and create an installation token for the repo.
@@ -116,18 +160,18 @@ func (m *manager) SyncRepo(ctx context.Context, ev *core.GitHubEvent, token stri
 func (m *manager) tryGetInstallationToken(ctx context.Context, ev *core.GitHubEvent) string {
 	installationID, err := github.GetInstallationIDForRepo(ctx, m.cfg, ev.RepoFullName, m.logger)
 	if err != nil {
-		m.logger.Debug("could not find GitHub App installation for repo", "repo", ev.RepoFullName, "error", err)
+		m.logger.DebugContext(ctx, "could not find GitHub App installation for repo", "repo", ev.RepoFullName, "error", err)
 		return ""
 	}
 
 	ev.InstallationID = installationID
 	_, instToken, err := github.CreateInstallationClient(ctx, m.cfg, installationID, m.logger)
 	if err != nil {
-		m.logger.Warn("failed to create installation token after lookup", "repo", ev.RepoFullName, "error", err)
+		m.logger.WarnContext(ctx, "failed to create installation token after lookup", "repo", ev.RepoFullName, "error", err)
 		return ""
 	}
 
-	m.logger.Info("obtained installation token via GitHub App lookup", "repo", ev.RepoFullName, "installation_id", installationID)
+	m.logger.InfoContext(ctx, "obtained installation token via GitHub App lookup", "repo", ev.RepoFullName, "installation_id", installationID)
 	return instToken
 }
 
@@ -136,6 +180,10 @@ func isPlaceholderToken(token string) bool {
 }
 
 func (m *manager) ScanLocalRepo(ctx context.Context, repoPath, repoFullName string, force bool) (*core.UpdateResult, error) {
+	repoPath, err := pathutil.FromFileURL(repoPath)
+	if err != nil {
+		return nil, err
+	}
 	mu := m.lockFor(repoPath)
 	mu.Lock()
 	defer mu.Unlock()
@@ -144,7 +192,11 @@ func (m *manager) ScanLocalRepo(ctx context.Context, repoPath, repoFullName stri
 }
 
 func (m *manager) GetRepoRecord(ctx context.Context, repoFullName string) (*storage.Repository, error) {
-	return m.store.GetRepositoryByFullName(ctx, repoFullName)
+	rec, err := m.store.GetRepositoryByFullName(ctx, repoFullName)
+	if err != nil || rec == nil || !rec.IsCold() {
+		return rec, err
+	}
+	return m.restore(ctx, rec.FullName)
 }
 
 func (m *manager) GetRepoRecordByPath(ctx context.Context, repoPath string) (*storage.Repository, error) {
@@ -152,7 +204,11 @@ func (m *manager) GetRepoRecordByPath(ctx context.Context, repoPath string) (*st
 	if err != nil {
 		return nil, err
 	}
-	return m.store.GetRepositoryByClonePath(ctx, absPath)
+	rec, err := m.store.GetRepositoryByClonePath(ctx, absPath)
+	if err != nil || rec == nil || !rec.IsCold() {
+		return rec, err
+	}
+	return m.restore(ctx, rec.FullName)
 }
 
 func (m *manager) LoadRepoConfig(repoPath string) (*core.RepoConfig, error) {
@@ -207,11 +263,11 @@ func (m *manager) listRepoFiles(repoPath string) ([]string, error) {
 		if d.IsDir() || strings.Contains(path, ".git") {
 			return nil
 		}
-		rel, relErr := filepath.Rel(repoPath, path)
+		rel, relErr := pathutil.Rel(repoPath, path)
 		if relErr != nil {
 			return relErr
 		}
-		files = append(files, strings.ReplaceAll(rel, "\\", "/"))
+		files = append(files, rel)
 		return nil
 	})
 	return files, err
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/go-git/go-git/v5"
+
+	"github.com/sevigo/code-warden/internal/cryptoutil"
 )
 
 // getRepoFullName extracts “owner/repo” from any remote URL (HTTPS or SSH).
@@ -44,9 +46,37 @@ func GenerateCollectionName(repoFullName string) string {
 	safeRepo := strings.ToLower(strings.ReplaceAll(repoFullName, "/", "-"))
 	safeRepo = collectionNameRegexp.ReplaceAllString(safeRepo, "")
 
-	name := "repo-" + safeRepo
+	name := CollectionNamePrefix + safeRepo
 	if len(name) > maxCollectionNameLength {
 		return name[:maxCollectionNameLength]
 	}
 	return name
 }
+
+// EmbedderCollectionName returns the name of the collection of repoFullName
+// embedded with model, used by collections built next to the default one
+// when a repository moves to another embedder model.
+func EmbedderCollectionName(repoFullName, model string) string {
+	return embedderCollectionBase(repoFullName) + embedderSuffix + cryptoutil.HashString(model)[:embedderHashLength]
+}
+
+// IsCollectionOf reports whether name is the default collection of
+// repoFullName or one built for another embedder model.
+func IsCollectionOf(name, repoFullName string) bool {
+	if name == GenerateCollectionName(repoFullName) {
+		return true
+	}
+	base := embedderCollectionBase(repoFullName)
+	hash, ok := strings.CutPrefix(name, base+embedderSuffix)
+	return ok && len(hash) == embedderHashLength && strings.Trim(hash, "0123456789abcdef") == ""
+}
+
+// embedderCollectionBase returns the default collection name of
+// repoFullName, shortened to leave room for the embedder suffix.
+func embedderCollectionBase(repoFullName string) string {
+	base := GenerateCollectionName(repoFullName)
+	if maxBase := maxCollectionNameLength - len(embedderSuffix) - embedderHashLength; len(base) > maxBase {
+		return base[:maxBase]
+	}
+	return base
+}
@@ -0,0 +1,27 @@
+package repomanager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbedderCollectionName(t *testing.T) {
+	name := EmbedderCollectionName("Owner/Repo", "nomic-embed-text")
+	assert.True(t, strings.HasPrefix(name, "repo-owner-repo--e"))
+	assert.Len(t, name, len("repo-owner-repo--e")+8)
+	assert.Equal(t, name, EmbedderCollectionName("Owner/Repo", "nomic-embed-text"))
+	assert.NotEqual(t, name, EmbedderCollectionName("Owner/Repo", "bge-m3"))
+
+	long := EmbedderCollectionName(strings.Repeat("a", 300), "bge-m3")
+	assert.Len(t, long, maxCollectionNameLength)
+}
+
+func TestIsCollectionOf(t *testing.T) {
+	assert.True(t, IsCollectionOf("repo-owner-repo", "owner/repo"))
+	assert.True(t, IsCollectionOf(EmbedderCollectionName("owner/repo", "bge-m3"), "owner/repo"))
+	assert.False(t, IsCollectionOf(EmbedderCollectionName("owner/other", "bge-m3"), "owner/repo"))
+	assert.False(t, IsCollectionOf("repo-owner-repo--eXYZ", "owner/repo"))
+	assert.False(t, IsCollectionOf("repo-owner-repo-old", "owner/repo"))
+}
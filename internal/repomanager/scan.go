@@ -27,15 +27,15 @@ func (m *manager) scanLocalRepo(
 	// Non-fatal: if either step fails (e.g. offline, no auth, dirty worktree) we
 	// continue with the existing local state and log a warning.
 	if fetchErr := m.gitClient.Fetch(ctx, repoPath, ""); fetchErr != nil {
-		m.logger.Warn("scanLocalRepo: fetch from origin failed, using local state",
+		m.logger.WarnContext(ctx, "scanLocalRepo: fetch from origin failed, using local state",
 			"repo", repoPath, "error", fetchErr)
 	} else {
-		m.logger.Info("scanLocalRepo: fetched latest from origin", "repo", repoPath)
+		m.logger.InfoContext(ctx, "scanLocalRepo: fetched latest from origin", "repo", repoPath)
 		if mergeErr := m.gitClient.MergeFF(ctx, repoPath); mergeErr != nil {
-			m.logger.Warn("scanLocalRepo: fast-forward merge failed, using local state",
+			m.logger.WarnContext(ctx, "scanLocalRepo: fast-forward merge failed, using local state",
 				"repo", repoPath, "error", mergeErr)
 		} else {
-			m.logger.Info("scanLocalRepo: fast-forwarded local branch to origin", "repo", repoPath)
+			m.logger.InfoContext(ctx, "scanLocalRepo: fast-forwarded local branch to origin", "repo", repoPath)
 		}
 	}
 
@@ -50,7 +50,7 @@ func (m *manager) scanLocalRepo(
 
 	if repoFullName == "" {
 		if rec, err := m.store.GetRepositoryByClonePath(ctx, repoPath); err == nil && rec != nil {
-			m.logger.Info("found repo record by path", "repo", rec.FullName)
+			m.logger.InfoContext(ctx, "found repo record by path", "repo", rec.FullName)
 			repoFullName = rec.FullName
 		} else {
 			if repoFullName, err = m.getRepoFullName(gitRepo); err != nil {
@@ -59,6 +59,12 @@ func (m *manager) scanLocalRepo(
 		}
 	}
 
+	// Restore a frozen collection first: an incremental scan updates it in
+	// place and a forced one must not leave a stale snapshot behind.
+	if _, err := m.restore(ctx, repoFullName); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, err
+	}
+
 	if force {
 		return m.fullLocalScan(ctx, repoPath, repoFullName, headSHA)
 	}
@@ -102,7 +108,7 @@ func (m *manager) incrementalLocalScan(
 	}
 
 	if rec.LastIndexedSHA == headSHA {
-		m.logger.Info("nothing changed since last index", "repo", repoFullName)
+		m.logger.InfoContext(ctx, "nothing changed since last index", "repo", repoFullName)
 		return &core.UpdateResult{
 			FilesToAddOrUpdate: []string{},
 			FilesToDelete:      []string{},
@@ -116,7 +122,7 @@ func (m *manager) incrementalLocalScan(
 	added, modified, deleted, err := m.gitClient.Diff(gitRepo, rec.LastIndexedSHA, headSHA)
 	if err != nil {
 		// As a safety net fall back to a full scan.
-		m.logger.Warn("diff failed → full scan", "err", err)
+		m.logger.WarnContext(ctx, "diff failed → full scan", "err", err)
 		return m.fullLocalScan(ctx, repoPath, repoFullName, headSHA)
 	}
 
@@ -6,11 +6,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
 
+	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/gitutil"
 	"github.com/sevigo/code-warden/internal/storage"
 )
 
@@ -34,6 +37,9 @@ func (m *manager) syncRepo(ctx context.Context, ev *core.GitHubEvent, token stri
 	if repoRec == nil {
 		return m.cloneAndIndex(ctx, ev, token, clonePath)
 	}
+	if err := m.thaw(ctx, repoRec); err != nil {
+		return nil, err
+	}
 	return m.incrementalUpdate(ctx, ev, token, repoRec)
 }
 
@@ -42,7 +48,7 @@ func (m *manager) cloneAndIndex(
 	ev *core.GitHubEvent,
 	token, clonePath string,
 ) (*core.UpdateResult, error) {
-	m.logger.Info("initial clone of default branch", "repo", ev.RepoFullName)
+	m.logger.InfoContext(ctx, "initial clone of default branch", "repo", ev.RepoFullName)
 	if err := os.MkdirAll(filepath.Dir(clonePath), 0o750); err != nil {
 		return nil, fmt.Errorf("create parent dir: %w", err)
 	}
@@ -51,14 +57,22 @@ func (m *manager) cloneAndIndex(
 	cloneCtx, cancel := context.WithTimeout(ctx, cloneTimeout)
 	defer cancel()
 
+	cloneURL, useSSH, err := m.remoteURL(ev)
+	if err != nil {
+		return nil, err
+	}
+	if useSSH {
+		token = ""
+	}
+
 	// Clone the default branch only — never the PR head.
 	// The PR diff is fetched separately via the GitHub API and passed in-memory to the LLM.
-	_, err := m.gitClient.Clone(cloneCtx, ev.RepoCloneURL, clonePath, token)
+	_, err = m.gitClient.Clone(cloneCtx, cloneURL, clonePath, token)
 	if err != nil {
 		// If we have a token and clone failed, try without token (public repo fallback)
 		if token != "" {
-			m.logger.Warn("clone with token failed, trying without token (public repo fallback)", "repo", ev.RepoFullName, "error", err)
-			_, err = m.gitClient.Clone(cloneCtx, ev.RepoCloneURL, clonePath, "")
+			m.logger.WarnContext(ctx, "clone with token failed, trying without token (public repo fallback)", "repo", ev.RepoFullName, "error", err)
+			_, err = m.gitClient.Clone(cloneCtx, cloneURL, clonePath, "")
 		}
 		if err != nil {
 			m.cleanupRepoDir(clonePath)
@@ -127,27 +141,27 @@ func (m *manager) incrementalUpdate(
 	gitRepo, err := m.gitClient.Open(rec.ClonePath)
 	if err != nil {
 		if errors.Is(err, git.ErrRepositoryNotExists) {
-			m.logger.Warn("repo missing on disk, falling back to fresh clone", "path", rec.ClonePath)
+			m.logger.WarnContext(ctx, "repo missing on disk, falling back to fresh clone", "path", rec.ClonePath)
 			return m.cloneAndIndex(ctx, ev, token, rec.ClonePath)
 		}
-		return nil, err
+		return m.recoverCorruptClone(ctx, ev, token, rec, err)
 	}
 
 	// Fetch and checkout the DEFAULT BRANCH ONLY — not the PR's HeadSHA.
 	// This keeps the on-disk working tree and the Qdrant index in sync with main.
 	if err := m.ensureDefaultBranch(ctx, ev, token, rec.ClonePath); err != nil {
-		return nil, err
+		return m.recoverCorruptClone(ctx, ev, token, rec, err)
 	}
 
 	// Get the current default branch SHA after fetch.
 	defaultBranchSHA, err := m.gitClient.GetHeadSHA(ctx, rec.ClonePath)
 	if err != nil {
-		return nil, fmt.Errorf("get default branch SHA: %w", err)
+		return m.recoverCorruptClone(ctx, ev, token, rec, fmt.Errorf("get default branch SHA: %w", err))
 	}
 
 	// If no previous SHA recorded, treat as full re-index
 	if rec.LastIndexedSHA == "" {
-		m.logger.Info("no previous index SHA, listing all files for full re-index", "repo", ev.RepoFullName)
+		m.logger.InfoContext(ctx, "no previous index SHA, listing all files for full re-index", "repo", ev.RepoFullName)
 		files, err := m.listRepoFiles(rec.ClonePath)
 		if err != nil {
 			return nil, fmt.Errorf("list files: %w", err)
@@ -164,7 +178,7 @@ func (m *manager) incrementalUpdate(
 
 	// Check if the default branch has actually moved since our last index.
 	if rec.LastIndexedSHA == defaultBranchSHA {
-		m.logger.Info("default branch unchanged, no Qdrant update needed",
+		m.logger.InfoContext(ctx, "default branch unchanged, no Qdrant update needed",
 			"repo", ev.RepoFullName,
 			"sha", defaultBranchSHA,
 		)
@@ -179,7 +193,7 @@ func (m *manager) incrementalUpdate(
 	// Default branch moved: compute the incremental diff (LastIndexedSHA → defaultBranchSHA).
 	added, modified, deleted, err := m.gitClient.Diff(gitRepo, rec.LastIndexedSHA, defaultBranchSHA)
 	if err != nil {
-		m.logger.Warn("git diff failed, falling back to full re-index",
+		m.logger.WarnContext(ctx, "git diff failed, falling back to full re-index",
 			"repo", ev.RepoFullName,
 			"last_indexed_sha", rec.LastIndexedSHA,
 			"default_branch_sha", defaultBranchSHA,
@@ -187,7 +201,7 @@ func (m *manager) incrementalUpdate(
 		)
 		// Cleanup corrupted state before re-cloning
 		if err := os.RemoveAll(rec.ClonePath); err != nil {
-			m.logger.Error("failed to remove repo directory before reclone", "path", rec.ClonePath, "err", err)
+			m.logger.ErrorContext(ctx, "failed to remove repo directory before reclone", "path", rec.ClonePath, "err", err)
 			// Continue even if cleanup fails
 		}
 		return m.cloneAndIndex(ctx, ev, token, rec.ClonePath)
@@ -204,6 +218,117 @@ func (m *manager) incrementalUpdate(
 	}, nil
 }
 
+// ensureCleanWorktree applies storage.worktree_policy to a managed clone with
+// uncommitted changes, e.g. files left behind by a crashed job. Under "reset"
+// the changes are discarded; under "fail" ErrDirtyWorktree is returned and the
+// clone is left as-is for inspection.
+func (m *manager) ensureCleanWorktree(ctx context.Context, repoFullName, clonePath string) error {
+	dirty, err := m.gitClient.DirtyFiles(ctx, clonePath)
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to check worktree status, continuing", "repo", repoFullName, "err", err)
+		return nil
+	}
+	if len(dirty) == 0 {
+		return nil
+	}
+
+	const maxLogged = 10
+	sample := dirty[:min(len(dirty), maxLogged)]
+	if m.cfg.Storage.WorktreePolicy == config.WorktreeFail {
+		return fmt.Errorf("%w: %s has %d changed file(s), e.g. %s",
+			ErrDirtyWorktree, clonePath, len(dirty), strings.Join(sample, ", "))
+	}
+
+	m.logger.WarnContext(ctx, "managed clone has uncommitted changes, resetting",
+		"repo", repoFullName, "files", len(dirty), "sample", sample)
+	if err := m.gitClient.CleanWorktree(ctx, clonePath); err != nil {
+		return fmt.Errorf("reset dirty worktree: %w", err)
+	}
+	return nil
+}
+
+// recoverCorruptClone replaces a damaged clone with a fresh one when cause is a
+// [gitutil.CorruptionError], and records the recovery in the audit trail.
+// Other errors are returned unchanged.
+func (m *manager) recoverCorruptClone(
+	ctx context.Context,
+	ev *core.GitHubEvent,
+	token string,
+	rec *storage.Repository,
+	cause error,
+) (*core.UpdateResult, error) {
+	reason, ok := gitutil.CorruptionReason(cause)
+	if !ok {
+		return nil, cause
+	}
+
+	m.logger.WarnContext(ctx, "managed clone is corrupted, re-cloning",
+		"repo", ev.RepoFullName,
+		"path", rec.ClonePath,
+		"reason", reason,
+		"error", cause,
+	)
+	res, err := m.cloneAndIndex(ctx, ev, token, rec.ClonePath)
+
+	audit := &storage.CloneRecovery{
+		RepoFullName: ev.RepoFullName,
+		ClonePath:    rec.ClonePath,
+		Reason:       reason,
+		Error:        cause.Error(),
+		Succeeded:    err == nil,
+	}
+	if auditErr := m.store.RecordCloneRecovery(ctx, audit); auditErr != nil {
+		m.logger.WarnContext(ctx, "failed to record clone recovery", "repo", ev.RepoFullName, "err", auditErr)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("re-clone after corrupted clone (%s): %w", reason, err)
+	}
+	return res, nil
+}
+
+// remoteURL returns the URL managed clones of ev's repository use, and
+// whether it is an SSH remote (git.ssh_repos), which authenticates with the
+// configured key or agent instead of the installation token.
+func (m *manager) remoteURL(ev *core.GitHubEvent) (string, bool, error) {
+	if !m.cfg.Git.UseSSH(ev.RepoFullName) {
+		return ev.RepoCloneURL, false, nil
+	}
+	sshURL, err := gitutil.SSHURL(ev.RepoCloneURL, m.cfg.Git.SSHUser)
+	if err != nil {
+		return "", false, fmt.Errorf("ssh remote for %s: %w", ev.RepoFullName, err)
+	}
+	return sshURL, true, nil
+}
+
+// ensureRemote points origin at the transport configured for ev's repository,
+// so a repository can move between HTTPS and SSH without a re-clone. It
+// returns the token to fetch with, which is empty for SSH remotes.
+func (m *manager) ensureRemote(ctx context.Context, ev *core.GitHubEvent, token, clonePath string) (string, error) {
+	want, useSSH, err := m.remoteURL(ev)
+	if err != nil {
+		return "", err
+	}
+	if useSSH {
+		token = ""
+	}
+
+	current, err := m.gitClient.RemoteURL(ctx, clonePath)
+	if err != nil {
+		m.logger.WarnContext(ctx, "failed to read clone remote, keeping it", "repo", ev.RepoFullName, "err", err)
+		return token, nil
+	}
+	if want == "" || gitutil.IsSSHURL(current) == useSSH {
+		return token, nil
+	}
+
+	m.logger.InfoContext(ctx, "switching clone remote transport", "repo", ev.RepoFullName, "ssh", useSSH)
+	if err := m.gitClient.SetRemoteURL(ctx, clonePath, want); err != nil {
+		return "", fmt.Errorf("switch remote: %w", err)
+	}
+	return token, nil
+}
+
 func (m *manager) cleanupRepoDir(path string) {
 	if err := os.RemoveAll(path); err != nil {
 		m.logger.Warn("cleanup failed", "path", path, "err", err)
@@ -213,11 +338,19 @@ func (m *manager) cleanupRepoDir(path string) {
 // ensureDefaultBranch fetches origin and resets the local branch to match the remote upstream.
 // It does NOT check out the PR's HeadSHA — that is intentional.
 func (m *manager) ensureDefaultBranch(ctx context.Context, ev *core.GitHubEvent, token, clonePath string) error {
+	if err := m.ensureCleanWorktree(ctx, ev.RepoFullName, clonePath); err != nil {
+		return err
+	}
+	token, err := m.ensureRemote(ctx, ev, token, clonePath)
+	if err != nil {
+		return err
+	}
+
 	currentSHA, err := m.gitClient.GetHeadSHA(ctx, clonePath)
 	needsFullFetch := currentSHA == "" || err != nil
 
 	if needsFullFetch {
-		m.logger.Warn("failed to get current HEAD SHA, forcing fetch", "repo", ev.RepoFullName, "err", err)
+		m.logger.WarnContext(ctx, "failed to get current HEAD SHA, forcing fetch", "repo", ev.RepoFullName, "err", err)
 	}
 
 	fetchErr := m.gitClient.Fetch(ctx, clonePath, token)
@@ -227,19 +360,23 @@ func (m *manager) ensureDefaultBranch(ctx context.Context, ev *core.GitHubEvent,
 		return fmt.Errorf("git fetch default branch: %w", fetchErr)
 	}
 
-	// If fetch failed but we already had a valid working tree, we can limp along with a warning.
+	// If fetch failed but we already had a valid working tree, we can limp along with a
+	// warning, unless the failure shows the clone itself is damaged.
+	if _, corrupt := gitutil.CorruptionReason(fetchErr); corrupt {
+		return fmt.Errorf("git fetch default branch: %w", fetchErr)
+	}
 	if fetchErr != nil {
-		m.logger.Warn("git fetch failed, using existing local state", "repo", ev.RepoFullName, "err", fetchErr)
+		m.logger.WarnContext(ctx, "git fetch failed, using existing local state", "repo", ev.RepoFullName, "err", fetchErr)
 		return nil
 	}
 
 	// Fetch succeeded. Ensure the working tree is advanced to the newly fetched upstream commit.
 	resetErr := m.gitClient.ResetToUpstream(ctx, clonePath)
 	if resetErr != nil {
-		if needsFullFetch {
+		if _, corrupt := gitutil.CorruptionReason(resetErr); needsFullFetch || corrupt {
 			return fmt.Errorf("git reset upstream: %w", resetErr)
 		}
-		m.logger.Warn("git reset upstream failed, index might be slightly stale", "repo", ev.RepoFullName, "err", resetErr)
+		m.logger.WarnContext(ctx, "git reset upstream failed, index might be slightly stale", "repo", ev.RepoFullName, "err", resetErr)
 	}
 
 	return nil
@@ -0,0 +1,205 @@
+// Package report serializes a structured review into machine-readable formats
+// for CI pipelines: JUnit XML (Jenkins, GitLab test reports), Checkstyle XML
+// (code-quality and annotation plugins) and JSON.
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// Supported output formats.
+const (
+	FormatText       = "text"
+	FormatJSON       = "json"
+	FormatJUnit      = "junit"
+	FormatCheckstyle = "checkstyle"
+)
+
+// Formats lists the supported output formats, FormatText first.
+var Formats = []string{FormatText, FormatJSON, FormatJUnit, FormatCheckstyle}
+
+// ParseSeverity returns the canonical spelling of severity ("high" → "High").
+func ParseSeverity(severity string) (string, error) {
+	rank := core.SeverityRank(severity)
+	if rank == 0 {
+		return "", fmt.Errorf("unknown severity %q, expected one of %s", severity, strings.Join(core.Severities, ", "))
+	}
+	return core.Severities[rank-1], nil
+}
+
+// CountAtLeast returns the number of suggestions in review with a severity of
+// minSeverity or higher.
+func CountAtLeast(review *core.StructuredReview, minSeverity string) int {
+	minRank := core.SeverityRank(minSeverity)
+	count := 0
+	for _, s := range review.Suggestions {
+		if core.SeverityRank(s.Severity) >= minRank {
+			count++
+		}
+	}
+	return count
+}
+
+// Write serializes review to w in format. name identifies the review in the
+// report, e.g. "owner/repo#123".
+func Write(w io.Writer, format string, review *core.StructuredReview, name string) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(review)
+	case FormatJUnit:
+		return writeXML(w, junitReport(review, name))
+	case FormatCheckstyle:
+		return writeXML(w, checkstyleReport(review))
+	default:
+		return fmt.Errorf("unsupported report format %q, expected one of %s", format, strings.Join(Formats[1:], ", "))
+	}
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	SystemOut string          `xml:"system-out,omitempty"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitReport reports each suggestion as a failed test case named after its
+// location. A review without suggestions is a single passing test case.
+func junitReport(review *core.StructuredReview, name string) junitTestSuites {
+	suite := junitTestSuite{Name: name, SystemOut: review.Summary}
+	for _, s := range review.Suggestions {
+		text := s.Comment
+		if s.CodeSuggestion != "" {
+			text += "\n\nSuggested change:\n" + s.CodeSuggestion
+		}
+		suite.Cases = append(suite.Cases, junitTestCase{
+			ClassName: s.FilePath,
+			Name:      location(s) + " " + s.Category,
+			Failure: &junitFailure{
+				Message: firstLine(s.Comment),
+				Type:    s.Severity,
+				Text:    text,
+			},
+		})
+	}
+	suite.Failures = len(suite.Cases)
+	if len(suite.Cases) == 0 {
+		suite.Cases = []junitTestCase{{ClassName: name, Name: "code review"}}
+	}
+	suite.Tests = len(suite.Cases)
+
+	return junitTestSuites{Tests: suite.Tests, Failures: suite.Failures, Suites: []junitTestSuite{suite}}
+}
+
+type checkstyleReportXML struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// checkstyleReport groups suggestions by file, ordered by path and line.
+func checkstyleReport(review *core.StructuredReview) checkstyleReportXML {
+	byFile := make(map[string][]checkstyleError)
+	for _, s := range review.Suggestions {
+		byFile[s.FilePath] = append(byFile[s.FilePath], checkstyleError{
+			Line:     s.LineNumber,
+			Severity: checkstyleSeverity(s.Severity),
+			Message:  s.Comment,
+			Source:   "code-warden." + strings.ReplaceAll(s.Category, " ", ""),
+		})
+	}
+
+	paths := make([]string, 0, len(byFile))
+	for path := range byFile {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	report := checkstyleReportXML{Version: "4.3"}
+	for _, path := range paths {
+		errs := byFile[path]
+		slices.SortStableFunc(errs, func(a, b checkstyleError) int { return a.Line - b.Line })
+		report.Files = append(report.Files, checkstyleFile{Name: path, Errors: errs})
+	}
+	return report
+}
+
+// checkstyleSeverity maps review severities onto checkstyle's error, warning
+// and info levels.
+func checkstyleSeverity(severity string) string {
+	switch core.SeverityRank(severity) {
+	case 3, 4:
+		return "error"
+	case 2:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+func location(s core.Suggestion) string {
+	if s.LineNumber <= 0 {
+		return s.FilePath
+	}
+	return s.FilePath + ":" + strconv.Itoa(s.LineNumber)
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
@@ -0,0 +1,100 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+func testReview() *core.StructuredReview {
+	return &core.StructuredReview{
+		Summary: "Two issues found.",
+		Suggestions: []core.Suggestion{
+			{FilePath: "internal/api/handler.go", LineNumber: 42, Severity: "High", Category: "Security", Comment: "User input reaches the query unescaped.\nUse a parameterized query."},
+			{FilePath: "internal/api/handler.go", LineNumber: 7, Severity: "Low", Category: "Best Practice", Comment: "Exported function lacks a doc comment & example."},
+			{FilePath: "cmd/main.go", LineNumber: 3, Severity: "Medium", Category: "Bug", Comment: "Error is ignored."},
+		},
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	got, err := ParseSeverity("HIGH")
+	require.NoError(t, err)
+	assert.Equal(t, "High", got)
+	_, err = ParseSeverity("urgent")
+	assert.Error(t, err)
+
+	review := testReview()
+	assert.Equal(t, 3, CountAtLeast(review, "Low"))
+	assert.Equal(t, 2, CountAtLeast(review, "Medium"))
+	assert.Equal(t, 1, CountAtLeast(review, "High"))
+	assert.Equal(t, 0, CountAtLeast(review, "Critical"))
+}
+
+func TestWrite_JUnit(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatJUnit, testReview(), "acme/api#12"))
+
+	var got junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, 3, got.Tests)
+	assert.Equal(t, 3, got.Failures)
+	require.Len(t, got.Suites, 1)
+	suite := got.Suites[0]
+	assert.Equal(t, "acme/api#12", suite.Name)
+	assert.Equal(t, "Two issues found.", suite.SystemOut)
+	require.Len(t, suite.Cases, 3)
+	assert.Equal(t, "internal/api/handler.go", suite.Cases[0].ClassName)
+	assert.Equal(t, "internal/api/handler.go:42 Security", suite.Cases[0].Name)
+	require.NotNil(t, suite.Cases[0].Failure)
+	assert.Equal(t, "High", suite.Cases[0].Failure.Type)
+	assert.Equal(t, "User input reaches the query unescaped.", suite.Cases[0].Failure.Message)
+
+	buf.Reset()
+	require.NoError(t, Write(&buf, FormatJUnit, &core.StructuredReview{Summary: "LGTM"}, "acme/api#13"))
+	got = junitTestSuites{}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, 1, got.Tests, "a clean review is a single passing test")
+	assert.Equal(t, 0, got.Failures)
+	assert.Nil(t, got.Suites[0].Cases[0].Failure)
+}
+
+func TestWrite_Checkstyle(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatCheckstyle, testReview(), "acme/api#12"))
+	assert.Contains(t, buf.String(), "&amp; example", "messages are escaped")
+
+	var got checkstyleReportXML
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "4.3", got.Version)
+	require.Len(t, got.Files, 2)
+	assert.Equal(t, "cmd/main.go", got.Files[0].Name)
+	assert.Equal(t, "warning", got.Files[0].Errors[0].Severity)
+
+	handler := got.Files[1]
+	require.Len(t, handler.Errors, 2)
+	assert.Equal(t, 7, handler.Errors[0].Line, "errors are ordered by line")
+	assert.Equal(t, "info", handler.Errors[0].Severity)
+	assert.Equal(t, "code-warden.BestPractice", handler.Errors[0].Source)
+	assert.Equal(t, "error", handler.Errors[1].Severity)
+}
+
+func TestWrite_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, FormatJSON, testReview(), "acme/api#12"))
+
+	var got core.StructuredReview
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, testReview().Suggestions, got.Suggestions)
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	assert.Error(t, Write(&bytes.Buffer{}, "sarif", testReview(), "acme/api#12"))
+	assert.Error(t, Write(&bytes.Buffer{}, FormatText, testReview(), "acme/api#12"), "text is rendered by the CLI")
+}
@@ -0,0 +1,137 @@
+// Package repostats summarizes a repository: what its vector collection holds
+// and what its reviews found.
+package repostats
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/review"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// maxTopCategories caps the categories listed in ReviewStats.
+const maxTopCategories = 5
+
+// Store is the part of storage.Store the statistics are read from.
+type Store interface {
+	GetReviewsForRepo(ctx context.Context, repoFullName string) ([]*core.Review, error)
+	GetScanState(ctx context.Context, repoID int64) (*storage.ScanState, error)
+}
+
+// Vectors is the part of storage.VectorStore the statistics are read from.
+type Vectors interface {
+	CollectionStats(ctx context.Context, collectionName string) (*storage.CollectionStats, error)
+}
+
+// Stats describes a repository.
+type Stats struct {
+	Repo           string `json:"repo"`
+	Collection     string `json:"collection"`
+	EmbedderModel  string `json:"embedder_model"`
+	LastIndexedSHA string `json:"last_indexed_sha"`
+	// LastIndexedAt is when the last scan of the repository finished.
+	LastIndexedAt time.Time `json:"last_indexed_at,omitzero"`
+	// Cold reports that the collection was moved to object storage, so the
+	// vector store holds none of its documents.
+	Cold bool `json:"cold,omitempty"`
+	// Index counts the documents of the collection. It is nil when the
+	// vector store could not be asked, with the reason in IndexError.
+	Index      *storage.CollectionStats `json:"index,omitempty"`
+	IndexError string                   `json:"index_error,omitempty"`
+	Reviews    ReviewStats              `json:"reviews"`
+}
+
+// ReviewStats summarizes the saved reviews of a repository.
+type ReviewStats struct {
+	Count          int     `json:"count"`
+	Suggestions    int     `json:"suggestions"`
+	AvgSuggestions float64 `json:"avg_suggestions"`
+	// TopCategories are the most frequent suggestion categories, most
+	// frequent first.
+	TopCategories []CategoryCount `json:"top_categories"`
+}
+
+// CategoryCount is the number of suggestions of a category.
+type CategoryCount struct {
+	Category string `json:"category"`
+	Count    int    `json:"count"`
+}
+
+// Collect gathers the statistics of repo, whose collection was built with
+// embedderModel. A vector store that cannot count the documents is reported
+// in Stats.IndexError rather than failing the call.
+func Collect(ctx context.Context, store Store, vectors Vectors, repo *storage.Repository, embedderModel string, logger *slog.Logger) (*Stats, error) {
+	stats := &Stats{
+		Repo:           repo.FullName,
+		Collection:     repo.QdrantCollectionName,
+		EmbedderModel:  repo.Embedder(embedderModel),
+		LastIndexedSHA: repo.LastIndexedSHA,
+		Cold:           repo.IsCold(),
+	}
+
+	state, err := store.GetScanState(ctx, repo.ID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get scan state: %w", err)
+	}
+	if state != nil {
+		stats.LastIndexedAt = state.UpdatedAt
+	}
+
+	if index, err := vectors.CollectionStats(ctx, repo.QdrantCollectionName); err != nil {
+		logger.WarnContext(ctx, "failed to count collection documents", "repo", repo.FullName, "error", err)
+		stats.IndexError = err.Error()
+	} else {
+		stats.Index = index
+	}
+
+	reviews, err := store.GetReviewsForRepo(ctx, repo.FullName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews: %w", err)
+	}
+	stats.Reviews = summarizeReviews(ctx, reviews, logger)
+	return stats, nil
+}
+
+// summarizeReviews counts the suggestions of reviews by category. Categories
+// are compared case-insensitively and named as first seen.
+func summarizeReviews(ctx context.Context, reviews []*core.Review, logger *slog.Logger) ReviewStats {
+	summary := ReviewStats{Count: len(reviews), TopCategories: []CategoryCount{}}
+	counts := make(map[string]*CategoryCount)
+	for _, rev := range reviews {
+		parsed := review.ParseSaved(ctx, logger, rev.ReviewContent)
+		summary.Suggestions += len(parsed.Suggestions)
+		for _, s := range parsed.Suggestions {
+			name := strings.TrimSpace(s.Category)
+			if name == "" {
+				continue
+			}
+			key := strings.ToLower(name)
+			if counts[key] == nil {
+				counts[key] = &CategoryCount{Category: name}
+			}
+			counts[key].Count++
+		}
+	}
+	if summary.Count > 0 {
+		summary.AvgSuggestions = float64(summary.Suggestions) / float64(summary.Count)
+	}
+
+	for _, c := range counts {
+		summary.TopCategories = append(summary.TopCategories, *c)
+	}
+	slices.SortFunc(summary.TopCategories, func(a, b CategoryCount) int {
+		return cmp.Or(b.Count-a.Count, strings.Compare(a.Category, b.Category))
+	})
+	if len(summary.TopCategories) > maxTopCategories {
+		summary.TopCategories = summary.TopCategories[:maxTopCategories]
+	}
+	return summary
+}
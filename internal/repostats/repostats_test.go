@@ -0,0 +1,90 @@
+package repostats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+type fakeStore struct {
+	reviews []*core.Review
+	state   *storage.ScanState
+}
+
+func (s *fakeStore) GetReviewsForRepo(context.Context, string) ([]*core.Review, error) {
+	return s.reviews, nil
+}
+
+func (s *fakeStore) GetScanState(context.Context, int64) (*storage.ScanState, error) {
+	if s.state == nil {
+		return nil, storage.ErrNotFound
+	}
+	return s.state, nil
+}
+
+type fakeVectors struct {
+	stats *storage.CollectionStats
+	err   error
+}
+
+func (v fakeVectors) CollectionStats(context.Context, string) (*storage.CollectionStats, error) {
+	return v.stats, v.err
+}
+
+func jsonReview(categories ...string) *core.Review {
+	content := `{"verdict": "comment", "summary": "ok", "suggestions": [`
+	for i, c := range categories {
+		if i > 0 {
+			content += ","
+		}
+		content += `{"file_path": "a.go", "line_number": 1, "severity": "low", "category": "` + c + `", "source": "diff:L1", "comment": "x"}`
+	}
+	return &core.Review{ReviewContent: content + `]}`}
+}
+
+func TestCollect(t *testing.T) {
+	scanned := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{
+		reviews: []*core.Review{
+			jsonReview("Bug", "Security", "bug"),
+			jsonReview("Performance", "Bug"),
+			jsonReview(),
+			{ReviewContent: "not a review"},
+		},
+		state: &storage.ScanState{UpdatedAt: scanned},
+	}
+	index := &storage.CollectionStats{Documents: 12, ByChunkType: map[string]int{"code": 10, "toc": 2}}
+	repo := &storage.Repository{ID: 1, FullName: "owner/app", QdrantCollectionName: "repo-owner-app", LastIndexedSHA: "abc123"}
+
+	stats, err := Collect(context.Background(), store, fakeVectors{stats: index}, repo, "nomic-embed-text", slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	assert.Equal(t, "owner/app", stats.Repo)
+	assert.Equal(t, "nomic-embed-text", stats.EmbedderModel)
+	assert.Equal(t, "abc123", stats.LastIndexedSHA)
+	assert.Equal(t, scanned, stats.LastIndexedAt)
+	assert.Equal(t, index, stats.Index)
+	assert.Equal(t, 4, stats.Reviews.Count)
+	assert.Equal(t, 5, stats.Reviews.Suggestions)
+	assert.InDelta(t, 1.25, stats.Reviews.AvgSuggestions, 0.001)
+	assert.Equal(t, []CategoryCount{{"Bug", 3}, {"Performance", 1}, {"Security", 1}}, stats.Reviews.TopCategories)
+}
+
+func TestCollect_VectorStoreFailure(t *testing.T) {
+	repo := &storage.Repository{FullName: "owner/app", QdrantCollectionName: "repo-owner-app", EmbedderModel: "bge-m3"}
+	stats, err := Collect(context.Background(), &fakeStore{}, fakeVectors{err: errors.New("connection refused")}, repo, "nomic-embed-text", slog.New(slog.DiscardHandler))
+	require.NoError(t, err, "an unreachable vector store only leaves out the index")
+	assert.Nil(t, stats.Index)
+	assert.Equal(t, "connection refused", stats.IndexError)
+	assert.Equal(t, "bge-m3", stats.EmbedderModel)
+	assert.True(t, stats.LastIndexedAt.IsZero())
+	assert.Zero(t, stats.Reviews.AvgSuggestions)
+	assert.Empty(t, stats.Reviews.TopCategories)
+}
@@ -0,0 +1,155 @@
+package review
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// matchDistance is how many lines apart two suggestions on the same file may
+// be and still count as the same finding.
+const matchDistance = 3
+
+// Comparison sets a stored review side by side with a regenerated one.
+type Comparison struct {
+	// Group names the diff group of a split review.
+	Group       string        `json:"group,omitempty"`
+	Original    ReviewSummary `json:"original"`
+	Regenerated ReviewSummary `json:"regenerated"`
+	// Common pairs the suggestions both reviews make at about the same place.
+	Common          []SuggestionPair  `json:"common"`
+	OnlyOriginal    []core.Suggestion `json:"only_original"`
+	OnlyRegenerated []core.Suggestion `json:"only_regenerated"`
+}
+
+// ReviewSummary describes one side of a [Comparison].
+type ReviewSummary struct {
+	Model         string `json:"model"`
+	PromptVersion string `json:"prompt_version"`
+	Verdict       string `json:"verdict"`
+	Confidence    int    `json:"confidence"`
+	Suggestions   int    `json:"suggestions"`
+	// Severities counts the suggestions by severity.
+	Severities map[string]int `json:"severities"`
+}
+
+// SuggestionPair is a finding made by both reviews.
+type SuggestionPair struct {
+	Original    core.Suggestion `json:"original"`
+	Regenerated core.Suggestion `json:"regenerated"`
+}
+
+// Compare matches the suggestions of two reviews of the same inputs. Each
+// suggestion of original is paired with the nearest unpaired suggestion of
+// regenerated on the same file, at most matchDistance lines away.
+func Compare(original, regenerated *core.StructuredReview) *Comparison {
+	c := &Comparison{
+		Original:    summarize(original),
+		Regenerated: summarize(regenerated),
+	}
+	paired := make([]bool, len(regenerated.Suggestions))
+	for _, o := range original.Suggestions {
+		best := -1
+		for i, r := range regenerated.Suggestions {
+			if paired[i] || r.FilePath != o.FilePath || lineDistance(o, r) > matchDistance {
+				continue
+			}
+			if best < 0 || lineDistance(o, r) < lineDistance(o, regenerated.Suggestions[best]) {
+				best = i
+			}
+		}
+		if best < 0 {
+			c.OnlyOriginal = append(c.OnlyOriginal, o)
+			continue
+		}
+		paired[best] = true
+		c.Common = append(c.Common, SuggestionPair{Original: o, Regenerated: regenerated.Suggestions[best]})
+	}
+	for i, r := range regenerated.Suggestions {
+		if !paired[i] {
+			c.OnlyRegenerated = append(c.OnlyRegenerated, r)
+		}
+	}
+	return c
+}
+
+func summarize(r *core.StructuredReview) ReviewSummary {
+	s := ReviewSummary{
+		Model:         r.Model,
+		PromptVersion: r.PromptVersion,
+		Verdict:       r.Verdict,
+		Confidence:    r.Confidence,
+		Suggestions:   len(r.Suggestions),
+		Severities:    make(map[string]int),
+	}
+	for _, sug := range r.Suggestions {
+		s.Severities[normalizeSeverity(sug.Severity)]++
+	}
+	return s
+}
+
+// normalizeSeverity returns severity in the spelling of core.Severities, or
+// as given when it is unknown.
+func normalizeSeverity(severity string) string {
+	if rank := core.SeverityRank(severity); rank > 0 {
+		return core.Severities[rank-1]
+	}
+	return severity
+}
+
+func lineDistance(a, b core.Suggestion) int {
+	d := a.LineNumber - b.LineNumber
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// Markdown renders the comparison as a side-by-side table followed by the
+// findings of both, either or only one review.
+func (c *Comparison) Markdown() string {
+	var b strings.Builder
+	if c.Group != "" {
+		fmt.Fprintf(&b, "## Group %s\n\n", c.Group)
+	}
+	b.WriteString("| | Original | Regenerated |\n|---|---|---|\n")
+	row := func(name, original, regenerated string) {
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", name, original, regenerated)
+	}
+	o, r := c.Original, c.Regenerated
+	row("Model", o.Model, r.Model)
+	row("Prompt version", o.PromptVersion, r.PromptVersion)
+	row("Verdict", o.Verdict, r.Verdict)
+	row("Confidence", fmt.Sprint(o.Confidence), fmt.Sprint(r.Confidence))
+	row("Suggestions", fmt.Sprint(o.Suggestions), fmt.Sprint(r.Suggestions))
+	for i := len(core.Severities) - 1; i >= 0; i-- {
+		sev := core.Severities[i]
+		row(sev, fmt.Sprint(o.Severities[sev]), fmt.Sprint(r.Severities[sev]))
+	}
+
+	fmt.Fprintf(&b, "\n### Found by both (%d)\n\n", len(c.Common))
+	for _, p := range c.Common {
+		fmt.Fprintf(&b, "- `%s:%d` %s → %s: %s\n", p.Original.FilePath, p.Original.LineNumber,
+			normalizeSeverity(p.Original.Severity), normalizeSeverity(p.Regenerated.Severity), firstLine(p.Regenerated.Comment))
+	}
+	writeSuggestions(&b, "Only in original", c.OnlyOriginal)
+	writeSuggestions(&b, "Only in regenerated", c.OnlyRegenerated)
+	return b.String()
+}
+
+func writeSuggestions(b *strings.Builder, title string, suggestions []core.Suggestion) {
+	fmt.Fprintf(b, "\n### %s (%d)\n\n", title, len(suggestions))
+	for _, s := range suggestions {
+		fmt.Fprintf(b, "- `%s:%d` %s: %s\n", s.FilePath, s.LineNumber, normalizeSeverity(s.Severity), firstLine(s.Comment))
+	}
+}
+
+// firstLine returns the first line of a comment.
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
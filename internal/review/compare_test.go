@@ -0,0 +1,57 @@
+package review
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+func TestCompare(t *testing.T) {
+	original := &core.StructuredReview{
+		Model: "qwen", PromptVersion: "aaa", Verdict: core.VerdictRequestChanges, Confidence: 80,
+		Suggestions: []core.Suggestion{
+			{FilePath: "a.go", LineNumber: 10, Severity: "high", Comment: "nil check missing\nmore detail"},
+			{FilePath: "a.go", LineNumber: 40, Severity: "Low", Comment: "naming"},
+			{FilePath: "b.go", LineNumber: 5, Severity: "Medium", Comment: "leak"},
+		},
+	}
+	regenerated := &core.StructuredReview{
+		Model: "kimi", PromptVersion: "bbb", Verdict: core.VerdictComment, Confidence: 70,
+		Suggestions: []core.Suggestion{
+			{FilePath: "a.go", LineNumber: 15, Severity: "High", Comment: "too far away"},
+			{FilePath: "a.go", LineNumber: 12, Severity: "Critical", Comment: "nil dereference"},
+			{FilePath: "b.go", LineNumber: 6, Severity: "Medium", Comment: "goroutine leak"},
+		},
+	}
+
+	c := Compare(original, regenerated)
+
+	if len(c.Common) != 2 || c.Common[0].Regenerated.Comment != "nil dereference" || c.Common[1].Regenerated.LineNumber != 6 {
+		t.Errorf("common = %+v, want the nearest match on the same file", c.Common)
+	}
+	if len(c.OnlyOriginal) != 1 || c.OnlyOriginal[0].LineNumber != 40 {
+		t.Errorf("only original = %+v", c.OnlyOriginal)
+	}
+	if len(c.OnlyRegenerated) != 1 || c.OnlyRegenerated[0].LineNumber != 15 {
+		t.Errorf("only regenerated = %+v", c.OnlyRegenerated)
+	}
+	if c.Original.Severities["High"] != 1 || c.Regenerated.Severities["Critical"] != 1 || c.Original.Suggestions != 3 {
+		t.Errorf("unexpected summaries: %+v / %+v", c.Original, c.Regenerated)
+	}
+
+	md := c.Markdown()
+	for _, want := range []string{
+		"| Model | qwen | kimi |",
+		"| Verdict | REQUEST_CHANGES | COMMENT |",
+		"| Critical | 0 | 1 |",
+		"### Found by both (2)",
+		"- `a.go:10` High → Critical: nil dereference",
+		"### Only in original (1)",
+		"### Only in regenerated (1)",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown is missing %q:\n%s", want, md)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package review
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/core"
+	ragReview "github.com/sevigo/code-warden/internal/rag/review"
+	"github.com/sevigo/code-warden/internal/suppress"
+)
+
+// Delta sorts the suggestions of current, a review of new commits, against
+// previous, the review of the pull request at previousSHA. Suggestions are
+// matched by suppress.Fingerprint, their file, category and normalized
+// comment, so a finding whose lines moved is not new.
+func Delta(previous, current *core.StructuredReview, previousSHA string) *core.ReviewDelta {
+	delta := &core.ReviewDelta{PreviousSHA: previousSHA}
+	before := make(map[string]bool, len(previous.Suggestions))
+	for _, s := range previous.Suggestions {
+		before[suppress.Fingerprint(s)] = true
+	}
+	now := make(map[string]bool, len(current.Suggestions))
+	for _, s := range current.Suggestions {
+		hash := suppress.Fingerprint(s)
+		if now[hash] {
+			continue
+		}
+		now[hash] = true
+		if before[hash] {
+			delta.Open = append(delta.Open, s)
+		} else {
+			delta.New = append(delta.New, s)
+		}
+	}
+	for _, s := range previous.Suggestions {
+		hash := suppress.Fingerprint(s)
+		if !now[hash] {
+			now[hash] = true // report duplicates of the previous review once
+			delta.Resolved = append(delta.Resolved, s)
+		}
+	}
+	return delta
+}
+
+// OnlyNew returns the suggestions that delta counts as new.
+func OnlyNew(delta *core.ReviewDelta, suggestions []core.Suggestion) []core.Suggestion {
+	fresh := make(map[string]bool, len(delta.New))
+	for _, s := range delta.New {
+		fresh[suppress.Fingerprint(s)] = true
+	}
+	var kept []core.Suggestion
+	for _, s := range suggestions {
+		if fresh[suppress.Fingerprint(s)] {
+			kept = append(kept, s)
+		}
+	}
+	return kept
+}
+
+// ParseSaved parses the raw model output a review was saved as, in the JSON
+// or the XML protocol. Output that parses as neither has no suggestions.
+func ParseSaved(ctx context.Context, logger *slog.Logger, content string) *core.StructuredReview {
+	parser := ragReview.NewStructuredReviewParser(logger)
+	parser.PreferJSON = !strings.Contains(content, "<review")
+	parsed, err := parser.Parse(ctx, content)
+	if err != nil || parsed == nil {
+		return &core.StructuredReview{}
+	}
+	return parsed
+}
@@ -0,0 +1,40 @@
+package review
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+func TestDelta(t *testing.T) {
+	previous := &core.StructuredReview{Suggestions: []core.Suggestion{
+		{FilePath: "api.go", LineNumber: 10, Category: "Bug", Comment: "Unchecked error."},
+		{FilePath: "db.go", LineNumber: 3, Category: "Style", Comment: "Typo in name."},
+		{FilePath: "db.go", LineNumber: 9, Category: "Style", Comment: "Typo in name."},
+	}}
+	current := &core.StructuredReview{Suggestions: []core.Suggestion{
+		// Same finding, lines moved and whitespace changed.
+		{FilePath: "api.go", LineNumber: 14, Category: "bug", Comment: "Unchecked   error."},
+		{FilePath: "auth.go", LineNumber: 40, Category: "Security", Comment: "SQL injection."},
+	}}
+
+	delta := Delta(previous, current, "abc123")
+	assert.Equal(t, "abc123", delta.PreviousSHA)
+	assert.Equal(t, []core.Suggestion{current.Suggestions[1]}, delta.New)
+	assert.Equal(t, []core.Suggestion{current.Suggestions[0]}, delta.Open, "open findings are reported at their current location")
+	assert.Equal(t, []core.Suggestion{previous.Suggestions[1]}, delta.Resolved, "duplicates of a resolved finding are listed once")
+
+	assert.Equal(t, []core.Suggestion{current.Suggestions[1]}, OnlyNew(delta, current.Suggestions))
+}
+
+func TestParseSaved(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	parsed := ParseSaved(context.Background(), logger, `{"summary": "ok", "verdict": "COMMENT", "suggestions": [{"file_path": "a.go", "line_number": 2, "severity": "Low", "category": "Style", "comment": "Rename.", "source": "diff:L2"}]}`)
+	assert.Len(t, parsed.Suggestions, 1)
+
+	assert.Empty(t, ParseSaved(context.Background(), logger, "not a review").Suggestions)
+}
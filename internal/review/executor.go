@@ -7,14 +7,18 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/sevigo/code-warden/internal/artifacts"
 	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/cryptoutil"
 	internalgithub "github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/rag"
 	ragReview "github.com/sevigo/code-warden/internal/rag/review"
 	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/suppress"
 )
 
 // Config holds configuration for the review executor.
@@ -23,10 +27,34 @@ type Config struct {
 	// If empty, a single-model review is performed.
 	ComparisonModels []string
 
-	// ReviewsDir is the directory to save review artifacts.
-	// If empty, no artifacts are saved.
+	// ReviewsDir is the local directory to save review artifacts, namespaced
+	// per repository under artifacts/<owner>/<repo>/. If empty, no artifacts
+	// are saved.
 	ReviewsDir string
 
+	// IgnoreBaseline keeps findings accepted in the repository's baseline
+	// file, e.g. to regenerate the baseline. Inline suppression comments
+	// still apply.
+	IgnoreBaseline bool
+
+	// MaxDiffTokens is the estimated diff size above which a review is split
+	// into groups of related directories that are reviewed separately and
+	// merged. Zero disables splitting.
+	MaxDiffTokens int
+
+	// MaxDiffKB caps the size of a reviewed diff; the repository's
+	// max_diff_kb can lower it. Files that do not fit are left out and
+	// listed in the review summary. Zero means unlimited.
+	MaxDiffKB int
+
+	// SplitConcurrency limits the group reviews that run in parallel.
+	// Defaults to 2 when zero.
+	SplitConcurrency int
+
+	// Progress, if set, is called after each group review of a split diff
+	// with the number of groups done, the total and the finished group.
+	Progress func(done, total int, group string)
+
 	// Logger for structured logging.
 	Logger *slog.Logger
 }
@@ -59,6 +87,7 @@ type Result struct {
 type Executor struct {
 	ragService rag.Service
 	config     Config
+	artifacts  *artifacts.Store
 }
 
 // NewExecutor creates a new review executor.
@@ -66,10 +95,14 @@ func NewExecutor(ragService rag.Service, config Config) *Executor {
 	if config.Logger == nil {
 		config.Logger = slog.Default()
 	}
-	return &Executor{
+	e := &Executor{
 		ragService: ragService,
 		config:     config,
 	}
+	if config.ReviewsDir != "" {
+		e.artifacts = artifacts.NewLocal(config.ReviewsDir, config.Logger)
+	}
+	return e
 }
 
 // Execute runs a code review and returns the result.
@@ -80,14 +113,14 @@ func (e *Executor) Execute(ctx context.Context, params Params) (*Result, error)
 
 	if params.Diff == "" {
 		raw := "No code changes."
-		if e.config.ReviewsDir != "" {
+		if e.artifacts != nil {
 			ts := time.Now().Format("20060102-150405")
 			result := ragReview.ComparisonResult{
 				Model:    "review",
 				Review:   raw,
 				Duration: time.Since(startTime),
 			}
-			ragReview.SaveReviewArtifact(e.config.Logger, e.config.ReviewsDir, result, params.Event, ts)
+			ragReview.SaveReviewArtifact(ctx, e.config.Logger, e.artifacts, result, params.Event, ts)
 		}
 
 		return &Result{
@@ -106,32 +139,31 @@ func (e *Executor) Execute(ctx context.Context, params Params) (*Result, error)
 	var rawReview string
 	var err error
 
-	if len(e.config.ComparisonModels) > 0 {
-		e.config.Logger.Info("using consensus review", "models", e.config.ComparisonModels)
-		structuredReview, rawReview, err = e.ragService.GenerateConsensusReview(
-			ctx,
-			params.RepoConfig,
-			params.Repo,
-			params.Event,
-			e.config.ComparisonModels,
-			params.Diff,
-			params.ChangedFiles,
-		)
-	} else {
-		e.config.Logger.Info("using single-model review")
-		structuredReview, rawReview, err = e.ragService.GenerateReview(
-			ctx,
-			params.RepoConfig,
-			params.Repo,
-			params.Event,
-			params.Diff,
-			params.ChangedFiles,
-		)
+	params, notReviewed, limitKB := e.limitDiff(params)
+	if len(notReviewed) > 0 {
+		e.config.Logger.Warn("diff exceeds max_diff_kb, leaving files out of the review",
+			"max_diff_kb", limitKB, "not_reviewed", len(notReviewed))
+	}
+
+	groups := e.splitGroups(params)
+	switch {
+	case params.Diff == "":
+		// No file fits within the limit on its own.
+		structuredReview = &core.StructuredReview{Verdict: core.VerdictComment, Suggestions: []core.Suggestion{}}
+	case len(groups) > 1:
+		e.config.Logger.Info("diff exceeds max_diff_tokens, reviewing in groups",
+			"diff_tokens", estimateTokens(params.Diff), "max_diff_tokens", e.config.MaxDiffTokens, "groups", len(groups))
+		structuredReview, rawReview, err = e.executeSplit(ctx, params, groups)
+	default:
+		structuredReview, rawReview, err = e.generate(ctx, params)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("review generation failed: %w", err)
 	}
+	if structuredReview != nil && len(notReviewed) > 0 {
+		structuredReview.Summary = notReviewedNote(notReviewed, limitKB) + structuredReview.Summary
+	}
 
 	// Validate result
 	if structuredReview == nil || (structuredReview.Summary == "" && len(structuredReview.Suggestions) == 0) {
@@ -140,14 +172,30 @@ func (e *Executor) Execute(ctx context.Context, params Params) (*Result, error)
 	}
 
 	// Save review artifact if configured
-	if e.config.ReviewsDir != "" && rawReview != "" {
+	if e.artifacts != nil && rawReview != "" {
 		ts := time.Now().Format("20060102-150405")
 		result := ragReview.ComparisonResult{
 			Model:    "review",
 			Review:   rawReview,
 			Duration: time.Since(startTime),
 		}
-		ragReview.SaveReviewArtifact(e.config.Logger, e.config.ReviewsDir, result, params.Event, ts)
+		ragReview.SaveReviewArtifact(ctx, e.config.Logger, e.artifacts, result, params.Event, ts)
+	}
+
+	if params.RepoConfig != nil {
+		if dropped := filterBySeverity(structuredReview, params.RepoConfig); dropped > 0 {
+			e.config.Logger.Info("dropped suggestions below min_severity", "dropped", dropped)
+		}
+	}
+
+	repoPath := ""
+	if params.Repo != nil {
+		repoPath = params.Repo.ClonePath
+	}
+	Suppress(structuredReview, suppress.ParseDiff(params.Diff), repoPath, e.config.IgnoreBaseline, e.config.Logger)
+
+	if params.RepoConfig != nil && len(params.RepoConfig.SubProjects) > 0 {
+		structuredReview.SubProjects = params.RepoConfig.SummarizeSubProjects(changedFileNames(params), structuredReview.Suggestions)
 	}
 
 	e.config.Logger.Info("review completed",
@@ -170,6 +218,144 @@ func (e *Executor) Execute(ctx context.Context, params Params) (*Result, error)
 	return res, nil
 }
 
+// generate runs a single-model or consensus review of params.
+func (e *Executor) generate(ctx context.Context, params Params) (*core.StructuredReview, string, error) {
+	if len(e.config.ComparisonModels) > 0 {
+		e.config.Logger.Info("using consensus review", "models", e.config.ComparisonModels)
+		return e.ragService.GenerateConsensusReview(
+			ctx,
+			params.RepoConfig,
+			params.Repo,
+			params.Event,
+			e.config.ComparisonModels,
+			params.Diff,
+			params.ChangedFiles,
+		)
+	}
+	e.config.Logger.Info("using single-model review")
+	return e.ragService.GenerateReview(
+		ctx,
+		params.RepoConfig,
+		params.Repo,
+		params.Event,
+		params.Diff,
+		params.ChangedFiles,
+	)
+}
+
+// maxNotReviewedListed caps the files named in the note on files left out
+// of a review.
+const maxNotReviewedListed = 20
+
+// limitDiff leaves the files of params.Diff that do not fit within the diff
+// size limit out of the review, taking files in diff order. It returns the
+// params of the remaining files, the names of the others and the limit in KB.
+func (e *Executor) limitDiff(params Params) (Params, []string, int) {
+	limitKB := e.config.MaxDiffKB
+	if params.RepoConfig != nil {
+		limitKB = core.EffectiveLimit(params.RepoConfig.MaxDiffKB, limitKB)
+	}
+	limit := limitKB * 1024
+	if limit <= 0 || len(params.Diff) <= limit {
+		return params, nil, limitKB
+	}
+
+	var (
+		kept    []fileDiff
+		dropped []string
+		size    int
+	)
+	for _, f := range splitFileDiffs(params.Diff) {
+		if size+len(f.diff) > limit {
+			dropped = append(dropped, f.file)
+			continue
+		}
+		kept = append(kept, f)
+		size += len(f.diff)
+	}
+	files := params.ChangedFiles
+	if len(files) == 0 {
+		files = ragReview.ParseDiff(params.Diff)
+	}
+	group := newDiffGroup(kept, files)
+	params.Diff = group.Diff
+	params.ChangedFiles = group.ChangedFiles
+	return params, dropped, limitKB
+}
+
+// notReviewedNote is the summary note on the files left out of a review by
+// the diff size limit.
+func notReviewedNote(files []string, limitKB int) string {
+	names := files
+	if len(names) > maxNotReviewedListed {
+		names = names[:maxNotReviewedListed]
+	}
+	var list strings.Builder
+	for i, name := range names {
+		if i > 0 {
+			list.WriteString(", ")
+		}
+		fmt.Fprintf(&list, "`%s`", name)
+	}
+	if more := len(files) - len(names); more > 0 {
+		fmt.Fprintf(&list, " and %d more", more)
+	}
+	return fmt.Sprintf("**Note:** The diff exceeds the review size limit of %d KB, so %d file(s) were not reviewed: %s.\n\n",
+		limitKB, len(files), list.String())
+}
+
+// splitGroups returns the groups to review an oversized diff in, or nil when
+// the diff fits within MaxDiffTokens.
+func (e *Executor) splitGroups(params Params) []diffGroup {
+	if e.config.MaxDiffTokens <= 0 || estimateTokens(params.Diff) <= e.config.MaxDiffTokens {
+		return nil
+	}
+	return splitDiff(params.Diff, params.ChangedFiles, e.config.MaxDiffTokens)
+}
+
+// changedFileNames returns the names of the files params changes.
+func changedFileNames(params Params) []string {
+	files := params.ChangedFiles
+	if len(files) == 0 {
+		files = ragReview.ParseDiff(params.Diff)
+	}
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.Filename
+	}
+	return names
+}
+
+// filterBySeverity removes suggestions ranked below the min_severity that
+// repoConfig sets for their file and returns how many were removed. An empty
+// or unknown min_severity keeps every suggestion.
+func filterBySeverity(review *core.StructuredReview, repoConfig *core.RepoConfig) int {
+	before := len(review.Suggestions)
+	review.Suggestions = slices.DeleteFunc(review.Suggestions, func(s core.Suggestion) bool {
+		return core.SeverityRank(s.Severity) < core.SeverityRank(repoConfig.MinSeverityFor(s.FilePath))
+	})
+	return before - len(review.Suggestions)
+}
+
+// Suppress removes the suggestions of review that are silenced by inline
+// "warden:ignore" comments or, unless ignoreBaseline is set, accepted in the
+// baseline file of the repository at repoPath. An unreadable baseline is
+// logged and skipped so that it never blocks a review.
+func Suppress(review *core.StructuredReview, inline suppress.Inline, repoPath string, ignoreBaseline bool, logger *slog.Logger) {
+	var baseline *suppress.Baseline
+	if !ignoreBaseline && repoPath != "" {
+		var err error
+		if baseline, err = suppress.LoadBaseline(repoPath); err != nil {
+			logger.Warn("ignoring review baseline", "error", err)
+		}
+	}
+
+	suppressed, baselined := suppress.Apply(review, inline, baseline)
+	if suppressed > 0 || baselined > 0 {
+		logger.Info("dropped suppressed suggestions", "inline", suppressed, "baseline", baselined)
+	}
+}
+
 // hashDiff creates a SHA-256 hex hash of the diff for tracking changes.
 func hashDiff(diff string) string {
 	return cryptoutil.HashString(diff)
@@ -1,7 +1,14 @@
 package review
 
 import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/suppress"
 )
 
 func TestHashDiff(t *testing.T) {
@@ -29,3 +36,70 @@ func TestHashDiff(t *testing.T) {
 		t.Errorf("empty diff should produce valid hash, got %d chars", len(hashEmpty))
 	}
 }
+
+func TestFilterBySeverity(t *testing.T) {
+	newReview := func() *core.StructuredReview {
+		return &core.StructuredReview{Suggestions: []core.Suggestion{
+			{FilePath: "cmd/main.go", Severity: "Low"},
+			{FilePath: "cmd/main.go", Severity: "Critical"},
+			{FilePath: "internal/auth/token.go", Severity: "Low"},
+			{FilePath: "cmd/main.go", Severity: "Medium"},
+			{FilePath: "cmd/main.go", Severity: "High"},
+		}}
+	}
+
+	review := newReview()
+	repoConfig := &core.RepoConfig{
+		MinSeverity: "high",
+		Rules:       []core.PathRule{{Paths: []string{"internal/auth/**"}, MinSeverity: "Low"}},
+	}
+	if dropped := filterBySeverity(review, repoConfig); dropped != 2 {
+		t.Errorf("expected 2 dropped suggestions, got %d", dropped)
+	}
+	var kept []string
+	for _, s := range review.Suggestions {
+		kept = append(kept, s.FilePath+":"+s.Severity)
+	}
+	if want := "cmd/main.go:Critical internal/auth/token.go:Low cmd/main.go:High"; strings.Join(kept, " ") != want {
+		t.Errorf("unexpected remaining suggestions: %v", kept)
+	}
+
+	// Empty or unknown thresholds keep everything
+	for _, minSeverity := range []string{"", "urgent"} {
+		review := newReview()
+		if dropped := filterBySeverity(review, &core.RepoConfig{MinSeverity: minSeverity}); dropped != 0 || len(review.Suggestions) != 5 {
+			t.Errorf("min severity %q: dropped %d, kept %d", minSeverity, dropped, len(review.Suggestions))
+		}
+	}
+}
+
+func TestSuppress(t *testing.T) {
+	repoPath := t.TempDir()
+	accepted := core.Suggestion{FilePath: "main.go", LineNumber: 30, Category: "Bug", Comment: "known issue"}
+	if err := suppress.NewBaseline([]core.Suggestion{accepted}).WriteFile(filepath.Join(repoPath, suppress.BaselineFile)); err != nil {
+		t.Fatal(err)
+	}
+	diff := "+++ b/main.go\n@@ -1,1 +1,2 @@\n x()\n+y() // warden:ignore style\n"
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	newReview := func() *core.StructuredReview {
+		return &core.StructuredReview{Suggestions: []core.Suggestion{
+			{FilePath: "main.go", LineNumber: 2, Category: "Style", Comment: "naming"},
+			accepted,
+			{FilePath: "main.go", LineNumber: 2, Category: "Bug", Comment: "new issue"},
+		}}
+	}
+
+	review := newReview()
+	Suppress(review, suppress.ParseDiff(diff), repoPath, false, logger)
+	if len(review.Suggestions) != 1 || review.Suggestions[0].Comment != "new issue" {
+		t.Errorf("unexpected remaining suggestions: %v", review.Suggestions)
+	}
+
+	// Regenerating the baseline keeps accepted findings
+	review = newReview()
+	Suppress(review, suppress.ParseDiff(diff), repoPath, true, logger)
+	if len(review.Suggestions) != 2 {
+		t.Errorf("expected 2 suggestions with the baseline ignored, got %d", len(review.Suggestions))
+	}
+}
@@ -0,0 +1,305 @@
+package review
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+const (
+	// charsPerToken estimates the token count of a diff.
+	charsPerToken = 4
+	// defaultSplitConcurrency limits the group reviews that run in parallel
+	// when Config.SplitConcurrency is zero.
+	defaultSplitConcurrency = 2
+	// maxGroupNameDirs limits the directories listed in a group's name.
+	maxGroupNameDirs = 3
+)
+
+// estimateTokens returns a rough token count for s.
+func estimateTokens(s string) int {
+	return len(s) / charsPerToken
+}
+
+// diffGroup is a part of an oversized diff that is reviewed on its own.
+type diffGroup struct {
+	// Name lists the directories of the group, e.g. "internal/auth, cmd".
+	Name         string
+	Diff         string
+	ChangedFiles []internalgithub.ChangedFile
+}
+
+// fileDiff is the section of a unified diff that belongs to one file.
+type fileDiff struct {
+	file string
+	diff string
+}
+
+// splitFileDiffs splits a unified diff into its per-file sections. Text before
+// the first "diff --git" header is dropped.
+func splitFileDiffs(diff string) []fileDiff {
+	var (
+		files   []fileDiff
+		current *fileDiff
+		body    strings.Builder
+	)
+	flush := func() {
+		if current != nil {
+			current.diff = body.String()
+			files = append(files, *current)
+		}
+		body.Reset()
+	}
+	for line := range strings.SplitAfterSeq(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") {
+			flush()
+			current = &fileDiff{}
+			if parts := strings.Fields(line); len(parts) >= 4 {
+				current.file = strings.TrimPrefix(parts[3], "b/")
+			}
+		}
+		if current != nil {
+			body.WriteString(line)
+		}
+	}
+	flush()
+	return files
+}
+
+// splitDiff groups the files of diff by directory into groups of at most
+// maxTokens, keeping the files of a directory together when they fit. A
+// directory larger than maxTokens is split by file, and a single file larger
+// than maxTokens becomes a group of its own. changedFiles are distributed to
+// the groups of their files.
+func splitDiff(diff string, changedFiles []internalgithub.ChangedFile, maxTokens int) []diffGroup {
+	byDir := make(map[string][]fileDiff)
+	for _, f := range splitFileDiffs(diff) {
+		dir := path.Dir(f.file)
+		byDir[dir] = append(byDir[dir], f)
+	}
+	dirs := make([]string, 0, len(byDir))
+	for dir := range byDir {
+		dirs = append(dirs, dir)
+	}
+	// Sorting keeps neighbouring packages in the same group.
+	slices.Sort(dirs)
+
+	var (
+		groups  []diffGroup
+		current []fileDiff
+		tokens  int
+	)
+	flush := func() {
+		if len(current) > 0 {
+			groups = append(groups, newDiffGroup(current, changedFiles))
+		}
+		current, tokens = nil, 0
+	}
+	for _, dir := range dirs {
+		files := byDir[dir]
+		dirTokens := 0
+		for _, f := range files {
+			dirTokens += estimateTokens(f.diff)
+		}
+		if dirTokens > maxTokens {
+			flush()
+			for _, f := range files {
+				fileTokens := estimateTokens(f.diff)
+				if tokens+fileTokens > maxTokens {
+					flush()
+				}
+				current = append(current, f)
+				tokens += fileTokens
+			}
+			flush()
+			continue
+		}
+		if tokens+dirTokens > maxTokens {
+			flush()
+		}
+		current = append(current, files...)
+		tokens += dirTokens
+	}
+	flush()
+	return groups
+}
+
+// newDiffGroup builds the group of files.
+func newDiffGroup(files []fileDiff, changedFiles []internalgithub.ChangedFile) diffGroup {
+	var (
+		diff  strings.Builder
+		dirs  []string
+		names = make(map[string]bool, len(files))
+	)
+	for _, f := range files {
+		diff.WriteString(f.diff)
+		names[f.file] = true
+		if dir := path.Dir(f.file); !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	name := strings.Join(dirs, ", ")
+	if len(dirs) > maxGroupNameDirs {
+		name = fmt.Sprintf("%s and %d more", strings.Join(dirs[:maxGroupNameDirs], ", "), len(dirs)-maxGroupNameDirs)
+	}
+
+	var groupFiles []internalgithub.ChangedFile
+	for _, cf := range changedFiles {
+		if names[cf.Filename] {
+			groupFiles = append(groupFiles, cf)
+		}
+	}
+	return diffGroup{Name: name, Diff: diff.String(), ChangedFiles: groupFiles}
+}
+
+// groupReview is the outcome of the review of one group.
+type groupReview struct {
+	group  diffGroup
+	review *core.StructuredReview
+	raw    string
+	err    error
+}
+
+// executeSplit reviews the groups concurrently and merges their reviews. It
+// fails only when every group fails; failed groups are listed in the summary.
+func (e *Executor) executeSplit(ctx context.Context, params Params, groups []diffGroup) (*core.StructuredReview, string, error) {
+	limit := e.config.SplitConcurrency
+	if limit < 1 {
+		limit = defaultSplitConcurrency
+	}
+
+	results := make([]groupReview, len(groups))
+	var (
+		mu   sync.Mutex
+		done int
+	)
+	core.ReportReviewStage(ctx, core.StageGenerating, 0, len(groups))
+	// The groups report through Progress; their stages would interleave.
+	g, gctx := errgroup.WithContext(core.WithReviewProgress(ctx, nil))
+	g.SetLimit(limit)
+	for i, group := range groups {
+		g.Go(func() error {
+			groupParams := params
+			groupParams.Diff = group.Diff
+			groupParams.ChangedFiles = group.ChangedFiles
+			review, raw, err := e.generate(gctx, groupParams)
+			if err == nil && (review == nil || (review.Summary == "" && len(review.Suggestions) == 0)) {
+				err = errors.New("generated review is empty or invalid")
+			}
+			results[i] = groupReview{group: group, review: review, raw: raw, err: err}
+			if err != nil {
+				e.config.Logger.Warn("group review failed", "group", group.Name, "error", err)
+			}
+
+			mu.Lock()
+			done++
+			if e.config.Progress != nil {
+				e.config.Progress(done, len(groups), group.Name)
+			}
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	var errs []error
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("group %s: %w", r.group.Name, r.err))
+		}
+	}
+	if len(errs) == len(results) {
+		return nil, "", errors.Join(errs...)
+	}
+	review, raw := mergeReviews(results, estimateTokens(params.Diff))
+	return review, raw, nil
+}
+
+// mergeReviews combines the reviews of the groups of a split diff into one
+// review: summaries become sections per group, suggestions and findings are
+// concatenated, and the verdict, profile and scores take the most severe
+// value of any group.
+func mergeReviews(results []groupReview, diffTokens int) (*core.StructuredReview, string) {
+	merged := &core.StructuredReview{Suggestions: []core.Suggestion{}}
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "This pull request is too large to review at once (about %d tokens of diff), so it was reviewed in %d groups of related directories.\n", diffTokens, len(results))
+
+	var raws []string
+	for _, r := range results {
+		fmt.Fprintf(&summary, "\n#### %s\n\n", r.group.Name)
+		if r.err != nil {
+			summary.WriteString("⚠️ The review of this group failed; its files were not reviewed.\n")
+			continue
+		}
+		review := r.review
+		summary.WriteString(strings.TrimSpace(review.Summary) + "\n")
+		raws = append(raws, r.raw)
+
+		merged.Suggestions = append(merged.Suggestions, review.Suggestions...)
+		merged.MissingTests = append(merged.MissingTests, review.MissingTests...)
+		merged.Verdict = mergeVerdict(merged.Verdict, review.Verdict)
+		if review.Confidence > 0 && (merged.Confidence == 0 || review.Confidence < merged.Confidence) {
+			merged.Confidence = review.Confidence
+		}
+		if profileRank(review.ReviewProfile) > profileRank(merged.ReviewProfile) {
+			merged.ReviewProfile = review.ReviewProfile
+		}
+		merged.ComplexityScore = max(merged.ComplexityScore, review.ComplexityScore)
+		merged.ImpactRadius = max(merged.ImpactRadius, review.ImpactRadius)
+		merged.Model = cmp.Or(merged.Model, review.Model)
+		merged.PromptVersion = cmp.Or(merged.PromptVersion, review.PromptVersion)
+		for _, in := range review.Inputs {
+			in.Group = r.group.Name
+			merged.Inputs = append(merged.Inputs, in)
+		}
+		if merged.CommitHygiene == nil {
+			merged.CommitHygiene = review.CommitHygiene
+		}
+		if merged.Retrieval == nil {
+			merged.Retrieval = review.Retrieval
+		}
+		if review.Dependencies != nil {
+			if merged.Dependencies == nil {
+				merged.Dependencies = &core.DependencyReport{}
+			}
+			merged.Dependencies.Checked += review.Dependencies.Checked
+			merged.Dependencies.Findings = append(merged.Dependencies.Findings, review.Dependencies.Findings...)
+		}
+	}
+	merged.Summary = summary.String()
+	return merged, strings.Join(raws, "\n\n")
+}
+
+// mergeVerdict returns the stricter of two verdicts.
+func mergeVerdict(a, b string) string {
+	rank := map[string]int{core.VerdictApprove: 1, core.VerdictComment: 2, core.VerdictRequestChanges: 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
+
+// profileRank orders review profiles from quick to thorough.
+func profileRank(profile string) int {
+	switch core.ReviewProfile(profile) {
+	case core.ProfileQuick:
+		return 1
+	case core.ProfileStandard:
+		return 2
+	case core.ProfileThorough:
+		return 3
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,198 @@
+package review
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/rag"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// fileDiffOf returns a diff section for file with n added lines.
+func fileDiffOf(file string, n int) string {
+	var b strings.Builder
+	b.WriteString("diff --git a/" + file + " b/" + file + "\n--- a/" + file + "\n+++ b/" + file + "\n@@ -0,0 +1 @@\n")
+	for range n {
+		b.WriteString("+line of code that is long enough\n")
+	}
+	return b.String()
+}
+
+func groupNames(groups []diffGroup) []string {
+	names := make([]string, len(groups))
+	for i, g := range groups {
+		names[i] = g.Name
+	}
+	return names
+}
+
+func TestSplitDiff(t *testing.T) {
+	// Each added line is 34 bytes, about 8 tokens.
+	diff := fileDiffOf("internal/auth/token.go", 10) +
+		fileDiffOf("cmd/main.go", 10) +
+		fileDiffOf("internal/auth/token_test.go", 10) +
+		fileDiffOf("internal/big/a.go", 20) +
+		fileDiffOf("internal/big/b.go", 20) +
+		fileDiffOf("README.md", 2)
+	changedFiles := []internalgithub.ChangedFile{{Filename: "cmd/main.go"}, {Filename: "internal/auth/token.go"}, {Filename: "internal/auth/token_test.go"}}
+
+	groups := splitDiff(diff, changedFiles, 250)
+
+	// Small directories share a group, internal/big exceeds the limit and is
+	// split by file.
+	want := "., cmd|internal/auth|internal/big|internal/big"
+	if got := strings.Join(groupNames(groups), "|"); got != want {
+		t.Fatalf("group names = %q, want %q", got, want)
+	}
+	if !strings.Contains(groups[1].Diff, "b/internal/auth/token.go") || !strings.Contains(groups[1].Diff, "b/internal/auth/token_test.go") {
+		t.Errorf("files of a directory should stay together:\n%s", groups[1].Diff)
+	}
+	if len(groups[1].ChangedFiles) != 2 || len(groups[0].ChangedFiles) != 1 || groups[3].ChangedFiles != nil {
+		t.Errorf("changed files not distributed by group: %+v", groups)
+	}
+
+	var total int
+	for _, g := range groups {
+		total += len(g.Diff)
+	}
+	if total != len(diff) {
+		t.Errorf("groups cover %d bytes of the %d byte diff", total, len(diff))
+	}
+}
+
+func TestMergeReviews(t *testing.T) {
+	results := []groupReview{
+		{
+			group: diffGroup{Name: "cmd"},
+			review: &core.StructuredReview{
+				Summary: "CLI looks fine.", Verdict: core.VerdictApprove, Confidence: 90, ReviewProfile: "quick", Model: "qwen",
+				Suggestions:  []core.Suggestion{{FilePath: "cmd/main.go", Comment: "a"}},
+				Dependencies: &core.DependencyReport{Checked: 1},
+			},
+			raw: "<review>cmd</review>",
+		},
+		{group: diffGroup{Name: "docs"}, err: errors.New("timeout")},
+		{
+			group: diffGroup{Name: "internal/auth"},
+			review: &core.StructuredReview{
+				Summary: "Token check is broken.", Verdict: core.VerdictRequestChanges, Confidence: 70, ReviewProfile: "thorough",
+				Suggestions:  []core.Suggestion{{FilePath: "internal/auth/token.go", Comment: "b"}},
+				Dependencies: &core.DependencyReport{Checked: 2, Findings: []core.DependencyFinding{{Name: "x"}}},
+			},
+			raw: "<review>auth</review>",
+		},
+	}
+
+	merged, raw := mergeReviews(results, 50000)
+
+	if merged.Verdict != core.VerdictRequestChanges || merged.Confidence != 70 || merged.ReviewProfile != "thorough" || merged.Model != "qwen" {
+		t.Errorf("unexpected merged metadata: %+v", merged)
+	}
+	if len(merged.Suggestions) != 2 || merged.Dependencies.Checked != 3 || len(merged.Dependencies.Findings) != 1 {
+		t.Errorf("suggestions or dependencies not combined: %+v", merged)
+	}
+	for _, want := range []string{"about 50000 tokens", "3 groups", "#### cmd\n\nCLI looks fine.", "#### docs\n\n⚠️", "#### internal/auth\n\nToken check is broken."} {
+		if !strings.Contains(merged.Summary, want) {
+			t.Errorf("summary is missing %q:\n%s", want, merged.Summary)
+		}
+	}
+	if raw != "<review>cmd</review>\n\n<review>auth</review>" {
+		t.Errorf("raw = %q", raw)
+	}
+}
+
+// groupService records the diffs it reviews and fails those of fail.go.
+type groupService struct {
+	rag.Service
+	mu    sync.Mutex
+	diffs []string
+}
+
+func (s *groupService) GenerateReview(_ context.Context, _ *core.RepoConfig, _ *storage.Repository, _ *core.GitHubEvent, diff string, _ []internalgithub.ChangedFile) (*core.StructuredReview, string, error) {
+	s.mu.Lock()
+	s.diffs = append(s.diffs, diff)
+	s.mu.Unlock()
+	if strings.Contains(diff, "fail.go") {
+		return nil, "", errors.New("model unavailable")
+	}
+	return &core.StructuredReview{Summary: "ok", Verdict: core.VerdictComment}, "raw", nil
+}
+
+func TestExecuteSplit(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := &groupService{}
+	var progress []int
+	e := NewExecutor(svc, Config{
+		MaxDiffTokens: 100,
+		Progress:      func(done, total int, _ string) { progress = append(progress, done, total) },
+		Logger:        logger,
+	})
+	diff := fileDiffOf("a/x.go", 15) + fileDiffOf("b/y.go", 15) + fileDiffOf("c/fail.go", 15)
+
+	result, err := e.Execute(context.Background(), Params{Event: &core.GitHubEvent{}, Diff: diff})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(svc.diffs) != 3 {
+		t.Errorf("expected 3 group reviews, got %d", len(svc.diffs))
+	}
+	if !strings.Contains(result.Review.Summary, "#### c\n\n⚠️") || result.DiffHash != hashDiff(diff) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	if len(progress) != 6 || progress[4] != 3 || progress[5] != 3 {
+		t.Errorf("progress = %v, want three updates ending at 3 of 3", progress)
+	}
+
+	// A diff below the limit is reviewed at once.
+	svc.diffs = nil
+	if _, err := e.Execute(context.Background(), Params{Event: &core.GitHubEvent{}, Diff: fileDiffOf("a/x.go", 2)}); err != nil {
+		t.Fatal(err)
+	}
+	if len(svc.diffs) != 1 {
+		t.Errorf("expected a single review, got %d", len(svc.diffs))
+	}
+
+	// The review fails when every group fails.
+	if _, err := e.Execute(context.Background(), Params{Event: &core.GitHubEvent{}, Diff: fileDiffOf("a/fail.go", 15) + fileDiffOf("b/fail.go", 15)}); err == nil {
+		t.Error("expected an error when every group fails")
+	}
+}
+
+func TestExecuteDiffLimit(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	svc := &groupService{}
+	e := NewExecutor(svc, Config{MaxDiffKB: 2, Logger: logger})
+	// About 1.1 KB each: the second file does not fit, the small third does.
+	diff := fileDiffOf("a/x.go", 30) + fileDiffOf("b/y.go", 30) + fileDiffOf("c/z.go", 2)
+
+	result, err := e.Execute(context.Background(), Params{Event: &core.GitHubEvent{}, Diff: diff})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(svc.diffs) != 1 || strings.Contains(svc.diffs[0], "b/y.go") || !strings.Contains(svc.diffs[0], "b/c/z.go") {
+		t.Errorf("reviewed diffs = %q, want a/x.go and c/z.go", svc.diffs)
+	}
+	if !strings.Contains(result.Review.Summary, "limit of 2 KB, so 1 file(s) were not reviewed: `b/y.go`") {
+		t.Errorf("summary does not list the file left out: %q", result.Review.Summary)
+	}
+	if result.DiffHash != hashDiff(diff) {
+		t.Error("the hash should be of the whole diff")
+	}
+
+	// The repository can lower the limit so that no file fits.
+	svc.diffs = nil
+	result, err = e.Execute(context.Background(), Params{Event: &core.GitHubEvent{}, Diff: fileDiffOf("a/x.go", 60), RepoConfig: &core.RepoConfig{MaxDiffKB: 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(svc.diffs) != 0 || !strings.Contains(result.Review.Summary, "limit of 1 KB") {
+		t.Errorf("expected no review and a note, got %d reviews and %q", len(svc.diffs), result.Review.Summary)
+	}
+}
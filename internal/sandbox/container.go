@@ -0,0 +1,86 @@
+package sandbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strconv"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// containerWorkdir is where the workspace is mounted inside the container.
+const containerWorkdir = "/workspace"
+
+// containerRunner runs each command in a fresh docker or podman container
+// that sees only the workspace, without network access or capabilities.
+type containerRunner struct {
+	engine string
+	image  string
+	flags  []string // run flags shared by all commands
+}
+
+func newContainerRunner(cfg config.SandboxConfig) (*containerRunner, error) {
+	engine := cfg.Engine
+	if engine == "" {
+		engine = "docker"
+	}
+	enginePath, err := exec.LookPath(engine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find container engine %q: %w", engine, err)
+	}
+
+	flags := []string{"--rm", "--cap-drop=ALL", "--security-opt=no-new-privileges"}
+	if !cfg.Network {
+		flags = append(flags, "--network=none")
+	}
+	if cfg.SeccompProfile != "" {
+		flags = append(flags, "--security-opt=seccomp="+cfg.SeccompProfile)
+	}
+	if cfg.Runtime != "" {
+		flags = append(flags, "--runtime="+cfg.Runtime)
+	}
+	if cfg.MemoryMB > 0 {
+		flags = append(flags, "--memory="+strconv.Itoa(cfg.MemoryMB)+"m")
+	}
+	if cfg.CPUSeconds > 0 {
+		flags = append(flags, "--ulimit=cpu="+strconv.Itoa(cfg.CPUSeconds))
+	}
+	if cfg.MaxProcesses > 0 {
+		flags = append(flags, "--pids-limit="+strconv.Itoa(cfg.MaxProcesses))
+	}
+	if cfg.User != "" {
+		flags = append(flags, "--user="+cfg.User)
+	}
+	return &containerRunner{engine: enginePath, image: cfg.Image, flags: flags}, nil
+}
+
+// Command implements [Runner]. dir is mounted at /workspace, which is the
+// command's working directory. Cancelling ctx kills the container, not only
+// the engine CLI.
+func (r *containerRunner) Command(ctx context.Context, dir, name string, args ...string) *exec.Cmd {
+	container := "code-warden-" + randomSuffix()
+	argv := slices.Concat(
+		[]string{"run", "--name=" + container},
+		r.flags,
+		[]string{"--volume=" + dir + ":" + containerWorkdir, "--workdir=" + containerWorkdir, r.image, name},
+		args,
+	)
+
+	cmd := exec.CommandContext(ctx, r.engine, argv...) //nolint:gosec // G204: command from repo config
+	cmd.Dir = dir
+	cmd.Cancel = func() error {
+		_ = exec.Command(r.engine, "kill", container).Run() //nolint:gosec // G204: engine and name are ours
+		return cmd.Process.Kill()
+	}
+	return cmd
+}
+
+func randomSuffix() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
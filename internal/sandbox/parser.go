@@ -0,0 +1,303 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"time"
+
+	"github.com/sevigo/goframe/parsers"
+	"github.com/sevigo/goframe/schema"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// parseWorkerArg is the first argument of a code-warden binary started as a
+// parse worker.
+const parseWorkerArg = "__parse-worker"
+
+// Operations of a parse request, one per parsing method of
+// [schema.ParserPlugin].
+const (
+	opChunk     = "chunk"
+	opMetadata  = "metadata"
+	opGenerated = "generated"
+	opSymbols   = "symbols"
+)
+
+type parseRequest struct {
+	Plugin  string                      `json:"plugin"`
+	Op      string                      `json:"op"`
+	Path    string                      `json:"path,omitempty"`
+	Content string                      `json:"content"`
+	Options *schema.CodeChunkingOptions `json:"options,omitempty"`
+}
+
+type parseResponse struct {
+	Chunks    []schema.CodeChunk   `json:"chunks,omitempty"`
+	Metadata  *schema.FileMetadata `json:"metadata,omitempty"`
+	Generated bool                 `json:"generated,omitempty"`
+	Symbols   []string             `json:"symbols,omitempty"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// ServeParseWorker turns the process into a parse worker when it was started
+// as one by [IsolateParsers], and exits when its input is closed. Otherwise
+// it returns immediately. Binaries call it first thing in main.
+func ServeParseWorker() {
+	if len(os.Args) < 2 || os.Args[1] != parseWorkerArg {
+		return
+	}
+	registry, err := parsers.RegisterLanguagePlugins(slog.New(slog.DiscardHandler))
+	if err == nil {
+		err = serveParse(registry, os.Stdin, os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse worker:", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+// serveParse answers the line-delimited JSON requests read from r on w.
+func serveParse(registry parsers.ParserRegistry, r io.Reader, w io.Writer) error {
+	dec := json.NewDecoder(r)
+	enc := json.NewEncoder(w)
+	for {
+		var req parseRequest
+		if err := dec.Decode(&req); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if err := enc.Encode(handleParse(registry, req)); err != nil {
+			return err
+		}
+	}
+}
+
+// handleParse runs req with the plugin it names. A panicking parser fails
+// the request, not the worker.
+func handleParse(registry parsers.ParserRegistry, req parseRequest) (resp parseResponse) {
+	defer func() {
+		if r := recover(); r != nil {
+			resp = parseResponse{Error: fmt.Sprintf("parser panicked: %v", r)}
+		}
+	}()
+	plugin, err := registry.GetParser(req.Plugin)
+	if err != nil {
+		return parseResponse{Error: err.Error()}
+	}
+	switch req.Op {
+	case opChunk:
+		resp.Chunks, err = plugin.Chunk(req.Content, req.Path, req.Options)
+	case opMetadata:
+		var meta schema.FileMetadata
+		meta, err = plugin.ExtractMetadata(req.Content, req.Path)
+		resp.Metadata = &meta
+	case opGenerated:
+		resp.Generated = plugin.IsGenerated(req.Content, req.Path)
+	case opSymbols:
+		resp.Symbols = plugin.ExtractUsedSymbols(req.Content)
+	default:
+		err = fmt.Errorf("unknown operation %q", req.Op)
+	}
+	if err != nil {
+		return parseResponse{Error: err.Error()}
+	}
+	return resp
+}
+
+// NewParserRunner returns the Runner of [New] for parse workers, without
+// cfg.CPUSeconds: RLIMIT_CPU counts the CPU time of the whole process, so a
+// long-lived worker would be killed in the middle of a file once the files it
+// parsed added up to the limit. cfg.ParseTimeout bounds each file instead.
+func NewParserRunner(cfg config.SandboxConfig) (Runner, error) {
+	cfg.CPUSeconds = 0
+	return New(cfg)
+}
+
+// IsolateParsers returns a registry with the plugins of registry whose
+// parsing runs in cfg.ParseWorkers worker processes started through r, and
+// a function that stops the workers. Name, Extensions and CanHandle, which
+// only look at file names, still run in the server.
+func IsolateParsers(registry parsers.ParserRegistry, r Runner, cfg config.SandboxConfig, logger *slog.Logger) (parsers.ParserRegistry, func(), error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find parse worker executable: %w", err)
+	}
+	pool := newParsePool([]string{exe, parseWorkerArg}, OrDirect(r), cfg.ParseWorkers, cfg.ParseTimeout, logger)
+
+	isolated := parsers.NewRegistry(logger)
+	for _, plugin := range registry.GetAllParsers() {
+		if err := isolated.RegisterParser(isolatedPlugin{ParserPlugin: plugin, pool: pool}); err != nil {
+			pool.Close()
+			return nil, nil, fmt.Errorf("failed to register isolated parser %s: %w", plugin.Name(), err)
+		}
+	}
+	logger.Info("parsing repository files in sandboxed workers", "workers", cfg.ParseWorkers)
+	return isolated, pool.Close, nil
+}
+
+// isolatedPlugin forwards the parsing methods of a plugin to the workers.
+type isolatedPlugin struct {
+	schema.ParserPlugin
+	pool *parsePool
+}
+
+func (p isolatedPlugin) Chunk(content, path string, opts *schema.CodeChunkingOptions) ([]schema.CodeChunk, error) {
+	resp, err := p.pool.call(parseRequest{Plugin: p.Name(), Op: opChunk, Path: path, Content: content, Options: opts})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Chunks, nil
+}
+
+func (p isolatedPlugin) ExtractMetadata(content, path string) (schema.FileMetadata, error) {
+	resp, err := p.pool.call(parseRequest{Plugin: p.Name(), Op: opMetadata, Path: path, Content: content})
+	if err != nil || resp.Metadata == nil {
+		return schema.FileMetadata{}, err
+	}
+	return *resp.Metadata, nil
+}
+
+// IsGenerated reports false when the worker fails, so the file is parsed
+// (and fails) like any other.
+func (p isolatedPlugin) IsGenerated(content, path string) bool {
+	resp, err := p.pool.call(parseRequest{Plugin: p.Name(), Op: opGenerated, Path: path, Content: content})
+	return err == nil && resp.Generated
+}
+
+func (p isolatedPlugin) ExtractUsedSymbols(content string) []string {
+	resp, err := p.pool.call(parseRequest{Plugin: p.Name(), Op: opSymbols, Content: content})
+	if err != nil {
+		return nil
+	}
+	return resp.Symbols
+}
+
+// parsePool hands requests to a fixed number of workers, started on first
+// use and restarted after they fail or time out.
+type parsePool struct {
+	argv    []string
+	runner  Runner
+	timeout time.Duration
+	logger  *slog.Logger
+	workers chan *parseWorker // nil entries are workers not started yet
+	closed  atomic.Bool
+}
+
+func newParsePool(argv []string, r Runner, size int, timeout time.Duration, logger *slog.Logger) *parsePool {
+	p := &parsePool{argv: argv, runner: r, timeout: timeout, logger: logger, workers: make(chan *parseWorker, size)}
+	for range size {
+		p.workers <- nil
+	}
+	return p
+}
+
+// call sends req to an idle worker and returns its response.
+func (p *parsePool) call(req parseRequest) (parseResponse, error) {
+	if p.closed.Load() {
+		return parseResponse{}, errors.New("parse workers are stopped")
+	}
+	w := <-p.workers
+	defer func() { p.workers <- w }()
+
+	if w == nil {
+		var err error
+		if w, err = p.start(); err != nil {
+			return parseResponse{}, err
+		}
+	}
+	resp, err := w.call(req, p.timeout)
+	if err != nil {
+		p.logger.Warn("parse worker failed, restarting it", "plugin", req.Plugin, "path", req.Path, "error", err)
+		w.stop()
+		w = nil
+		return parseResponse{}, fmt.Errorf("parse worker failed: %w", err)
+	}
+	if resp.Error != "" {
+		return parseResponse{}, errors.New(resp.Error)
+	}
+	return resp, nil
+}
+
+func (p *parsePool) start() (*parseWorker, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := p.runner.Command(ctx, os.TempDir(), p.argv[0], p.argv[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start parse worker: %w", err)
+	}
+	return &parseWorker{cmd: cmd, cancel: cancel, stdin: stdin, enc: json.NewEncoder(stdin), dec: json.NewDecoder(stdout)}, nil
+}
+
+// Close stops the workers once they finish their current requests.
+func (p *parsePool) Close() {
+	if p.closed.Swap(true) {
+		return
+	}
+	for range cap(p.workers) {
+		if w := <-p.workers; w != nil {
+			w.stop()
+		}
+	}
+}
+
+// parseWorker is one worker process.
+type parseWorker struct {
+	cmd    *exec.Cmd
+	cancel context.CancelFunc
+	stdin  io.Closer
+	enc    *json.Encoder
+	dec    *json.Decoder
+}
+
+// call sends req and waits up to timeout for the response. A worker that
+// times out is killed; the caller still stops it.
+func (w *parseWorker) call(req parseRequest, timeout time.Duration) (parseResponse, error) {
+	var resp parseResponse
+	done := make(chan error, 1)
+	go func() {
+		if err := w.enc.Encode(req); err != nil {
+			done <- err
+			return
+		}
+		done <- w.dec.Decode(&resp)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return resp, err
+	case <-timer.C:
+		w.cancel()
+		<-done
+		return parseResponse{}, fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// stop kills the worker and waits for it to exit.
+func (w *parseWorker) stop() {
+	_ = w.stdin.Close()
+	w.cancel()
+	_ = w.cmd.Wait()
+}
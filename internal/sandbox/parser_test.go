@@ -0,0 +1,92 @@
+package sandbox
+
+import (
+	"log/slog"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/sevigo/goframe/parsers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// TestMain lets IsolateParsers start the test binary as its parse worker.
+func TestMain(m *testing.M) {
+	ServeParseWorker()
+	os.Exit(m.Run())
+}
+
+const goSource = `// Package shop sells things.
+package shop
+
+import "fmt"
+
+// Price formats a price in cents.
+func Price(cents int) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+`
+
+func TestIsolateParsers(t *testing.T) {
+	logger := slog.New(slog.DiscardHandler)
+	registry, err := parsers.RegisterLanguagePlugins(logger)
+	require.NoError(t, err)
+
+	isolated, stop, err := IsolateParsers(registry, Direct{}, config.SandboxConfig{ParseWorkers: 2, ParseTimeout: 30 * time.Second}, logger)
+	require.NoError(t, err)
+	defer stop()
+
+	local, err := registry.GetParserForExtension(".go")
+	require.NoError(t, err)
+	remote, err := isolated.GetParserForExtension(".go")
+	require.NoError(t, err)
+	assert.Equal(t, local.Name(), remote.Name())
+
+	want, err := local.Chunk(goSource, "shop/price.go", nil)
+	require.NoError(t, err)
+	got, err := remote.Chunk(goSource, "shop/price.go", nil)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	wantMeta, err := local.ExtractMetadata(goSource, "shop/price.go")
+	require.NoError(t, err)
+	gotMeta, err := remote.ExtractMetadata(goSource, "shop/price.go")
+	require.NoError(t, err)
+	assert.Equal(t, wantMeta, gotMeta)
+
+	assert.ElementsMatch(t, local.ExtractUsedSymbols(goSource), remote.ExtractUsedSymbols(goSource))
+	assert.True(t, remote.IsGenerated("// Code generated by mockgen. DO NOT EDIT.\npackage shop\n", "shop/mock.go"))
+
+	stop()
+	_, err = remote.Chunk(goSource, "shop/price.go", nil)
+	assert.Error(t, err, "stopped workers take no more requests")
+}
+
+func TestParsePool_KillsHungWorker(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	// The "worker" reads requests and never answers.
+	pool := newParsePool([]string{"sh", "-c", "cat >/dev/null"}, Direct{}, 1, 200*time.Millisecond, slog.New(slog.DiscardHandler))
+	defer pool.Close()
+
+	for range 2 {
+		start := time.Now()
+		_, err := pool.call(parseRequest{Plugin: "go", Op: opChunk, Content: goSource})
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 10*time.Second)
+	}
+}
+
+func TestHandleParse(t *testing.T) {
+	registry, err := parsers.RegisterLanguagePlugins(slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, handleParse(registry, parseRequest{Plugin: "cobol", Op: opChunk}).Error)
+	assert.NotEmpty(t, handleParse(registry, parseRequest{Plugin: "go", Op: "compile"}).Error)
+	assert.Empty(t, handleParse(registry, parseRequest{Plugin: "go", Op: opSymbols, Content: goSource}).Error)
+}
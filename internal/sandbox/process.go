@@ -0,0 +1,70 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"slices"
+	"strconv"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// processRunner runs commands in their own process group with a scrubbed
+// environment, resource limits applied through prlimit(1) and, optionally,
+// as an unprivileged user.
+type processRunner struct {
+	prlimit []string // prlimit invocation up to "--"; empty without limits
+	account *user.User
+}
+
+func newProcessRunner(cfg config.SandboxConfig) (*processRunner, error) {
+	r := &processRunner{}
+
+	var limits []string
+	if cfg.MemoryMB > 0 {
+		limits = append(limits, "--as="+strconv.Itoa(cfg.MemoryMB*1024*1024))
+	}
+	if cfg.CPUSeconds > 0 {
+		limits = append(limits, "--cpu="+strconv.Itoa(cfg.CPUSeconds))
+	}
+	if cfg.MaxProcesses > 0 {
+		limits = append(limits, "--nproc="+strconv.Itoa(cfg.MaxProcesses))
+	}
+	if len(limits) > 0 {
+		prlimit, err := exec.LookPath("prlimit")
+		if err != nil {
+			return nil, fmt.Errorf("failed to find prlimit for sandbox limits: %w", err)
+		}
+		r.prlimit = append([]string{prlimit}, limits...)
+	}
+
+	if cfg.User != "" {
+		account, err := user.Lookup(cfg.User)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up sandbox user: %w", err)
+		}
+		r.account = account
+	}
+	return r, nil
+}
+
+// Command implements [Runner].
+func (r *processRunner) Command(ctx context.Context, dir, name string, args ...string) *exec.Cmd {
+	argv := append([]string{name}, args...)
+	if len(r.prlimit) > 0 {
+		argv = slices.Concat(r.prlimit, []string{"--"}, argv)
+	}
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...) //nolint:gosec // G204: command from repo config
+	cmd.Dir = dir
+	cmd.Env = scrubEnv(os.Environ())
+	if r.account != nil {
+		cmd.Env = setEnv(cmd.Env, "HOME", r.account.HomeDir)
+		cmd.Env = setEnv(cmd.Env, "USER", r.account.Username)
+	}
+	isolate(cmd, r.account)
+	return cmd
+}
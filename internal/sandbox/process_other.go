@@ -0,0 +1,12 @@
+//go:build !unix
+
+package sandbox
+
+import (
+	"os/exec"
+	"os/user"
+)
+
+// isolate is a no-op where process groups and credentials are unavailable;
+// the environment is still scrubbed and resource limits still apply.
+func isolate(*exec.Cmd, *user.User) {}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package sandbox
+
+import (
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+// isolate starts cmd in its own process group, killed as a whole when the
+// command's context is done, and runs it as account when set.
+func isolate(cmd *exec.Cmd, account *user.User) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if account != nil {
+		uid, _ := strconv.ParseUint(account.Uid, 10, 32)
+		gid, _ := strconv.ParseUint(account.Gid, 10, 32)
+		cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+	}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,85 @@
+// Package sandbox runs commands taken from untrusted repositories, such as
+// verify_commands and format_command, in isolation from the host. Callers
+// build commands through a [Runner] instead of os/exec so the configured
+// isolation applies to every repository-controlled execution.
+package sandbox
+
+import (
+	"context"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// Runner builds commands that execute under the runner's isolation.
+type Runner interface {
+	// Command returns a command that runs name with args in dir, the
+	// repository workspace. Callers set output and run it like any
+	// *exec.Cmd.
+	Command(ctx context.Context, dir, name string, args ...string) *exec.Cmd
+}
+
+// New returns the Runner for cfg.Backend.
+func New(cfg config.SandboxConfig) (Runner, error) {
+	switch cfg.Backend {
+	case config.SandboxProcess:
+		return newProcessRunner(cfg)
+	case config.SandboxContainer:
+		return newContainerRunner(cfg)
+	default:
+		return Direct{}, nil
+	}
+}
+
+// OrDirect returns r, or [Direct] when r is nil.
+func OrDirect(r Runner) Runner {
+	if r == nil {
+		return Direct{}
+	}
+	return r
+}
+
+// Direct runs commands on the host without isolation, like exec.CommandContext.
+type Direct struct{}
+
+// Command implements [Runner].
+func (Direct) Command(ctx context.Context, dir, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...) //nolint:gosec // G204: command from repo config
+	cmd.Dir = dir
+	return cmd
+}
+
+// allowedEnv lists the host environment variables passed to sandboxed
+// commands, besides Go toolchain settings. Everything else, including API
+// keys and tokens, is withheld.
+var allowedEnv = []string{
+	"PATH", "HOME", "USER", "LANG", "LC_ALL", "LC_CTYPE", "TZ", "TERM", "TMPDIR",
+	"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY", "http_proxy", "https_proxy", "no_proxy",
+	"SSL_CERT_FILE", "SSL_CERT_DIR",
+	"CARGO_HOME", "RUSTUP_HOME", "JAVA_HOME", "NODE_PATH", "NPM_CONFIG_CACHE", "PIP_CACHE_DIR",
+}
+
+// goEnvRegex matches Go toolchain variables such as GOPATH, GOCACHE and
+// GOFLAGS but not GOOGLE_API_KEY.
+var goEnvRegex = regexp.MustCompile(`^GO[A-Z0-9]+$`)
+
+// scrubEnv returns the entries of env that sandboxed commands may see.
+func scrubEnv(env []string) []string {
+	out := make([]string, 0, len(allowedEnv))
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		if slices.Contains(allowedEnv, key) || goEnvRegex.MatchString(key) {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// setEnv replaces or adds key in env.
+func setEnv(env []string, key, value string) []string {
+	env = slices.DeleteFunc(env, func(kv string) bool { return strings.HasPrefix(kv, key+"=") })
+	return append(env, key+"="+value)
+}
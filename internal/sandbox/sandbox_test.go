@@ -0,0 +1,108 @@
+package sandbox
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	r, err := New(config.SandboxConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, Direct{}, r)
+
+	_, err = New(config.SandboxConfig{Backend: config.SandboxProcess, User: "no-such-user-for-sandbox"})
+	assert.Error(t, err)
+
+	_, err = New(config.SandboxConfig{Backend: config.SandboxContainer, Engine: "no-such-engine", Image: "alpine"})
+	assert.Error(t, err)
+
+	assert.Equal(t, Direct{}, OrDirect(nil))
+}
+
+func TestScrubEnv(t *testing.T) {
+	got := scrubEnv([]string{
+		"PATH=/usr/bin",
+		"GOPATH=/go",
+		"GOFLAGS=-mod=mod",
+		"GOOGLE_API_KEY=secret",
+		"GITHUB_TOKEN=secret",
+		"DATABASE_PASSWORD=secret",
+		"HTTPS_PROXY=http://proxy:3128",
+	})
+	assert.Equal(t, []string{"PATH=/usr/bin", "GOPATH=/go", "GOFLAGS=-mod=mod", "HTTPS_PROXY=http://proxy:3128"}, got)
+}
+
+func TestProcessRunner(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	if _, err := exec.LookPath("prlimit"); err != nil {
+		t.Skip("prlimit not installed")
+	}
+	t.Setenv("GITHUB_TOKEN", "ghs_secret")
+
+	r, err := New(config.SandboxConfig{Backend: config.SandboxProcess, CPUSeconds: 7})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	out, err := r.Command(context.Background(), dir, "sh", "-c", `pwd; echo "token=$GITHUB_TOKEN"; ulimit -t`).Output()
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], dir)
+	assert.Equal(t, "token=", lines[1], "secrets are not passed to sandboxed commands")
+	assert.Equal(t, "7", lines[2])
+}
+
+func TestNewParserRunner_NoCPULimit(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	if _, err := exec.LookPath("prlimit"); err != nil {
+		t.Skip("prlimit not installed")
+	}
+
+	r, err := NewParserRunner(config.SandboxConfig{Backend: config.SandboxProcess, CPUSeconds: 7, MemoryMB: 1024})
+	require.NoError(t, err)
+
+	out, err := r.Command(context.Background(), t.TempDir(), "sh", "-c", "ulimit -t; ulimit -v").Output()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"unlimited", "1048576"}, strings.Fields(string(out)), "parse workers keep the other limits")
+}
+
+func TestProcessRunner_CancelKillsProcessGroup(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not installed")
+	}
+	r, err := New(config.SandboxConfig{Backend: config.SandboxProcess})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	cmd := r.Command(ctx, t.TempDir(), "sh", "-c", "sleep 30 & wait")
+
+	start := time.Now()
+	assert.Error(t, cmd.Run())
+	assert.Less(t, time.Since(start), 10*time.Second, "the background sleep is killed with its group")
+}
+
+func TestContainerRunner_Command(t *testing.T) {
+	r := &containerRunner{engine: "/usr/bin/docker", image: "golang:1.26"}
+	r.flags = []string{"--rm", "--network=none"}
+
+	cmd := r.Command(context.Background(), "/work/acme", "make", "test")
+	args := cmd.Args[1:]
+	require.GreaterOrEqual(t, len(args), 7)
+	assert.Equal(t, "run", args[0])
+	assert.True(t, strings.HasPrefix(args[1], "--name=code-warden-"))
+	assert.Equal(t, []string{"--rm", "--network=none", "--volume=/work/acme:/workspace", "--workdir=/workspace", "golang:1.26", "make", "test"}, args[2:])
+	assert.NotNil(t, cmd.Cancel)
+}
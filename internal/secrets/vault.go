@@ -0,0 +1,134 @@
+// Package secrets stores credentials in the database encrypted with the keys
+// of security.encryption_key, so that neither a database dump nor a replica
+// reveals them.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/cryptoutil"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// Vault encrypts credentials with AES-GCM before they are stored and
+// decrypts them when read. A nil *Vault, used when no encryption key is
+// configured, stores nothing and finds nothing.
+type Vault struct {
+	store storage.CredentialStore
+	keys  *cryptoutil.Keyring
+	now   func() time.Time
+}
+
+// NewVault creates a Vault with the keys of cfg, or returns nil when no
+// encryption key is configured.
+func NewVault(cfg *config.Config, store storage.CredentialStore) (*Vault, error) {
+	primary, previous, err := cfg.Security.EncryptionKeys()
+	if err != nil || primary == nil {
+		return nil, err
+	}
+	keys, err := cryptoutil.NewKeyring(primary, previous...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create keyring: %w", err)
+	}
+	return &Vault{store: store, keys: keys, now: time.Now}, nil
+}
+
+// Put encrypts value and stores it as name, replacing what was stored
+// before. A zero expiresAt means the value does not expire.
+func (v *Vault) Put(ctx context.Context, name, value string, expiresAt time.Time) error {
+	if v == nil {
+		return nil
+	}
+	// The name is authenticated with the value, so that a row copied to
+	// another name fails to decrypt.
+	sealed, err := v.keys.Seal([]byte(value), []byte(name))
+	if err != nil {
+		return err
+	}
+	credential := &storage.Credential{Name: name, Ciphertext: sealed}
+	if !expiresAt.IsZero() {
+		credential.ExpiresAt = &expiresAt
+	}
+	return v.store.SaveCredential(ctx, credential)
+}
+
+// Get returns the decrypted value of name, and false when there is none or
+// it has expired.
+func (v *Vault) Get(ctx context.Context, name string) (string, bool, error) {
+	if v == nil {
+		return "", false, nil
+	}
+	credential, err := v.store.GetCredential(ctx, name)
+	if errors.Is(err, storage.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if credential.ExpiresAt != nil && !v.now().Before(*credential.ExpiresAt) {
+		return "", false, nil
+	}
+	value, err := v.keys.Open(credential.Ciphertext, []byte(name))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to decrypt credential %s: %w", name, err)
+	}
+	return string(value), true, nil
+}
+
+// RotationResult counts what Rotate did.
+type RotationResult struct {
+	// Rotated credentials were re-encrypted with the primary key.
+	Rotated int `json:"rotated"`
+	// Current credentials were already encrypted with the primary key.
+	Current int `json:"current"`
+	// Expired credentials were deleted instead of re-encrypted.
+	Expired int64 `json:"expired"`
+}
+
+// Rotate deletes expired credentials and re-encrypts the others that were
+// encrypted with a previous key, after which the previous keys can be
+// removed from the configuration.
+func (v *Vault) Rotate(ctx context.Context) (RotationResult, error) {
+	var result RotationResult
+	if v == nil {
+		return result, errors.New("no encryption key is configured")
+	}
+	expired, err := v.store.DeleteExpiredCredentials(ctx, v.now())
+	if err != nil {
+		return result, err
+	}
+	result.Expired = expired
+
+	credentials, err := v.store.ListCredentials(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, credential := range credentials {
+		id, err := cryptoutil.SealedKeyID(credential.Ciphertext)
+		if err != nil {
+			return result, fmt.Errorf("credential %s: %w", credential.Name, err)
+		}
+		if id == v.keys.PrimaryKeyID() {
+			result.Current++
+			continue
+		}
+		value, err := v.keys.Open(credential.Ciphertext, []byte(credential.Name))
+		if err != nil {
+			return result, fmt.Errorf("failed to decrypt credential %s: %w", credential.Name, err)
+		}
+		sealed, err := v.keys.Seal(value, []byte(credential.Name))
+		if err != nil {
+			return result, err
+		}
+		credential.Ciphertext = sealed
+		if err := v.store.SaveCredential(ctx, credential); err != nil {
+			return result, err
+		}
+		result.Rotated++
+	}
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// memoryCredentials is an in-memory storage.CredentialStore.
+type memoryCredentials map[string]storage.Credential
+
+func (m memoryCredentials) SaveCredential(_ context.Context, c *storage.Credential) error {
+	m[c.Name] = *c
+	return nil
+}
+
+func (m memoryCredentials) GetCredential(_ context.Context, name string) (*storage.Credential, error) {
+	c, ok := m[name]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &c, nil
+}
+
+func (m memoryCredentials) ListCredentials(_ context.Context) ([]*storage.Credential, error) {
+	var list []*storage.Credential
+	for _, c := range m {
+		list = append(list, &c)
+	}
+	return list, nil
+}
+
+func (m memoryCredentials) DeleteExpiredCredentials(_ context.Context, now time.Time) (int64, error) {
+	var n int64
+	for name, c := range m {
+		if c.ExpiresAt != nil && c.ExpiresAt.Before(now) {
+			delete(m, name)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func key(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune(b)), 32)))
+}
+
+func TestVault(t *testing.T) {
+	ctx := context.Background()
+	store := memoryCredentials{}
+	cfg := &config.Config{Security: config.SecurityConfig{EncryptionKey: key('a')}}
+	vault, err := NewVault(cfg, store)
+	require.NoError(t, err)
+
+	require.NoError(t, vault.Put(ctx, "github/installation/1/token", "ghs_secret", time.Time{}))
+	require.NoError(t, vault.Put(ctx, "expired", "old", time.Now().Add(-time.Minute)))
+	assert.NotContains(t, store["github/installation/1/token"].Ciphertext, "ghs_secret")
+
+	value, ok, err := vault.Get(ctx, "github/installation/1/token")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "ghs_secret", value)
+	_, ok, err = vault.Get(ctx, "expired")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	_, ok, err = vault.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Rotate to a new key, keeping the old one to decrypt.
+	cfg.Security = config.SecurityConfig{EncryptionKey: key('b'), PreviousEncryptionKeys: []string{key('a')}}
+	rotated, err := NewVault(cfg, store)
+	require.NoError(t, err)
+	result, err := rotated.Rotate(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, RotationResult{Rotated: 1, Expired: 1}, result)
+
+	// The old key is no longer needed.
+	cfg.Security.PreviousEncryptionKeys = nil
+	newOnly, err := NewVault(cfg, store)
+	require.NoError(t, err)
+	value, _, err = newOnly.Get(ctx, "github/installation/1/token")
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_secret", value)
+	_, _, err = vault.Get(ctx, "github/installation/1/token")
+	require.Error(t, err, "the old key cannot decrypt rotated credentials")
+}
+
+func TestNewVault_NoKey(t *testing.T) {
+	vault, err := NewVault(&config.Config{}, memoryCredentials{})
+	require.NoError(t, err)
+	assert.Nil(t, vault)
+	require.NoError(t, vault.Put(context.Background(), "name", "value", time.Time{}))
+	_, ok, err := vault.Get(context.Background(), "name")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
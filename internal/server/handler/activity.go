@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"embed"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/health"
+	ragreview "github.com/sevigo/code-warden/internal/rag/review"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/stringsutil"
+)
+
+const (
+	// activityReviewLimit is how many reviews the activity page lists.
+	activityReviewLimit = 50
+	// activityCookie holds the dashboard token once it was given as a query
+	// parameter, so that reloads of the page need not repeat it.
+	activityCookie = "warden_dashboard"
+)
+
+//go:embed templates/activity.html
+var activityFS embed.FS
+
+var activityTemplate = template.Must(template.ParseFS(activityFS, "templates/activity.html"))
+
+// ActivityHandler serves the review activity page, a server-rendered
+// overview of recent reviews, repositories and the job queue.
+type ActivityHandler struct {
+	store      storage.Store
+	dispatcher core.JobDispatcher
+	monitor    *health.Monitor
+	logger     *slog.Logger
+}
+
+func NewActivityHandler(store storage.Store, dispatcher core.JobDispatcher, monitor *health.Monitor, logger *slog.Logger) *ActivityHandler {
+	return &ActivityHandler{store: store, dispatcher: dispatcher, monitor: monitor, logger: logger}
+}
+
+// RequireDashboardToken rejects requests that carry token neither as a
+// bearer token, the activity cookie nor a "token" query parameter. A valid
+// query parameter is stored in the cookie.
+func RequireDashboardToken(token string) func(http.Handler) http.Handler {
+	valid := func(got string) bool {
+		return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && valid(bearer) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if c, err := r.Cookie(activityCookie); err == nil && valid(c.Value) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if q := r.URL.Query().Get("token"); valid(q) {
+				http.SetCookie(w, &http.Cookie{
+					Name:     activityCookie,
+					Value:    q,
+					Path:     "/activity",
+					HttpOnly: true,
+					Secure:   r.TLS != nil,
+					SameSite: http.SameSiteStrictMode,
+				})
+				next.ServeHTTP(w, r)
+				return
+			}
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		})
+	}
+}
+
+type activityPage struct {
+	GeneratedAt  time.Time
+	Paused       bool
+	PauseReasons string
+	Queue        core.QueueStats
+	Severities   []severityTotal
+	Repos        []activityRepo
+	Reviews      []activityReview
+}
+
+type severityTotal struct {
+	Name  string
+	Count int
+}
+
+type activityRepo struct {
+	FullName   string
+	IndexedSHA string
+	LastReview time.Time
+}
+
+type activityReview struct {
+	Repo      string
+	PRNumber  int
+	URL       string
+	HeadSHA   string
+	CreatedAt time.Time
+	Verdict   string
+	Degraded  bool
+	Counts    map[string]int
+}
+
+// Page renders the activity page.
+func (h *ActivityHandler) Page(w http.ResponseWriter, r *http.Request) {
+	page, err := h.collect(r.Context())
+	if err != nil {
+		h.logger.Error("failed to collect review activity", "error", err)
+		http.Error(w, "failed to load review activity", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := activityTemplate.Execute(w, page); err != nil {
+		h.logger.Error("failed to render activity page", "error", err)
+	}
+}
+
+func (h *ActivityHandler) collect(ctx context.Context) (*activityPage, error) {
+	repos, err := h.store.GetAllRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	reviews, err := h.store.GetRecentReviews(ctx, activityReviewLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent reviews: %w", err)
+	}
+
+	admission := admissionStatus(h.monitor)
+	page := &activityPage{
+		GeneratedAt:  time.Now(),
+		Paused:       admission.Paused,
+		PauseReasons: strings.Join(admission.Reasons, "; "),
+	}
+	if h.dispatcher != nil {
+		page.Queue = h.dispatcher.QueueStats()
+	}
+
+	totals := map[string]int{}
+	lastReview := make(map[string]time.Time)
+	for _, rev := range reviews {
+		parsed := parseReviewContent(ctx, h.logger, rev.ReviewContent)
+		counts := countSeverities(parsed)
+		for name, n := range counts {
+			totals[name] += n
+		}
+		if rev.CreatedAt.After(lastReview[rev.RepoFullName]) {
+			lastReview[rev.RepoFullName] = rev.CreatedAt
+		}
+		page.Reviews = append(page.Reviews, activityReview{
+			Repo:      rev.RepoFullName,
+			PRNumber:  rev.PRNumber,
+			URL:       fmt.Sprintf("https://github.com/%s/pull/%d", rev.RepoFullName, rev.PRNumber),
+			HeadSHA:   stringsutil.TruncateSHA(rev.HeadSHA),
+			CreatedAt: rev.CreatedAt,
+			Verdict:   parsed.Verdict,
+			Degraded:  rev.Degraded,
+			Counts:    counts,
+		})
+	}
+	for _, name := range []string{severityCritical, severityHigh, severityMedium, severityLow} {
+		page.Severities = append(page.Severities, severityTotal{Name: name, Count: totals[name]})
+	}
+	for _, repo := range repos {
+		page.Repos = append(page.Repos, activityRepo{
+			FullName:   repo.FullName,
+			IndexedSHA: stringsutil.TruncateSHA(repo.LastIndexedSHA),
+			LastReview: lastReview[repo.FullName],
+		})
+	}
+	return page, nil
+}
+
+// parseReviewContent parses the raw model output a review was saved as, in
+// the JSON or the XML protocol. Output that parses as neither has no
+// suggestions.
+func parseReviewContent(ctx context.Context, logger *slog.Logger, content string) *core.StructuredReview {
+	parser := ragreview.NewStructuredReviewParser(logger)
+	parser.PreferJSON = !strings.Contains(content, "<review")
+	parsed, err := parser.Parse(ctx, content)
+	if err != nil || parsed == nil {
+		return &core.StructuredReview{}
+	}
+	return parsed
+}
+
+// countSeverities counts the suggestions of a review by severity. Severities
+// other than the four known ones count as low.
+func countSeverities(review *core.StructuredReview) map[string]int {
+	counts := map[string]int{severityCritical: 0, severityHigh: 0, severityMedium: 0, severityLow: 0}
+	for _, s := range review.Suggestions {
+		switch sev := strings.ToLower(strings.TrimSpace(s.Severity)); sev {
+		case severityCritical, severityHigh, severityMedium:
+			counts[sev]++
+		default:
+			counts[severityLow]++
+		}
+	}
+	return counts
+}
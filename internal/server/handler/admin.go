@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/settings"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+const (
+	// actorHeader names the administrator making a change, for the audit trail.
+	actorHeader  = "X-Actor"
+	defaultActor = "admin"
+
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// AdminHandler serves the admin API for changing models and prompts at runtime.
+type AdminHandler struct {
+	cfg      *config.Config
+	settings *settings.Manager
+	store    storage.SettingsStore
+	logger   *slog.Logger
+}
+
+func NewAdminHandler(cfg *config.Config, settingsMgr *settings.Manager, store storage.SettingsStore, logger *slog.Logger) *AdminHandler {
+	return &AdminHandler{cfg: cfg, settings: settingsMgr, store: store, logger: logger}
+}
+
+// RequireToken rejects requests that do not carry token as a bearer token.
+func RequireToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (h *AdminHandler) writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.logger.Error("failed to encode JSON response", "error", err)
+	}
+}
+
+// GetSettings returns the configured defaults, the overrides and the
+// effective values that new jobs use, and the versions of the prompts.
+func (h *AdminHandler) GetSettings(w http.ResponseWriter, _ *http.Request) {
+	ai := h.settings.AI()
+	h.writeJSON(w, map[string]any{
+		"defaults": map[string]any{
+			"generator_model":   h.cfg.AI.GeneratorModel,
+			"comparison_models": h.cfg.AI.ComparisonModels,
+			"embedder_model":    h.cfg.AI.EmbedderModel,
+		},
+		"effective": map[string]any{
+			"generator_model":   ai.GeneratorModel,
+			"comparison_models": ai.ComparisonModels,
+			"embedder_model":    ai.EmbedderModel,
+		},
+		"overrides": h.settings.Overrides(),
+		"prompts":   h.settings.Prompts(),
+	})
+}
+
+// PutSetting sets the override of a setting from a {"value": ...} body.
+func (h *AdminHandler) PutSetting(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(body.Value) == 0 || string(body.Value) == "null" {
+		http.Error(w, "value is required; use DELETE to restore the default", http.StatusBadRequest)
+		return
+	}
+	h.set(w, r, body.Value)
+}
+
+// DeleteSetting removes the override of a setting, restoring the default.
+func (h *AdminHandler) DeleteSetting(w http.ResponseWriter, r *http.Request) {
+	h.set(w, r, nil)
+}
+
+func (h *AdminHandler) set(w http.ResponseWriter, r *http.Request, value json.RawMessage) {
+	key := chi.URLParam(r, "key")
+	actor := r.Header.Get(actorHeader)
+	if actor == "" {
+		actor = defaultActor
+	}
+
+	err := h.settings.Set(r.Context(), key, value, actor)
+	if errors.Is(err, settings.ErrInvalid) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		h.logger.Error("failed to change runtime setting", "key", key, "error", err)
+		http.Error(w, "failed to change setting", http.StatusInternalServerError)
+		return
+	}
+	h.writeJSON(w, map[string]bool{"ok": true})
+}
+
+// ListAudit returns the most recent setting changes. The optional "limit"
+// query parameter caps the number of entries.
+func (h *AdminHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	limit := defaultAuditLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxAuditLimit)
+	}
+
+	entries, err := h.store.ListSettingsAudit(r.Context(), limit)
+	if err != nil {
+		h.logger.Error("failed to list settings audit", "error", err)
+		http.Error(w, "failed to list settings audit", http.StatusInternalServerError)
+		return
+	}
+
+	type auditDTO struct {
+		Key       string          `json:"key"`
+		OldValue  json.RawMessage `json:"old_value"`
+		NewValue  json.RawMessage `json:"new_value"`
+		Actor     string          `json:"actor"`
+		CreatedAt time.Time       `json:"created_at"`
+	}
+
+	out := make([]auditDTO, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, auditDTO{
+			Key:       e.Key,
+			OldValue:  rawJSON(e.OldValue),
+			NewValue:  rawJSON(e.NewValue),
+			Actor:     e.Actor,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	h.writeJSON(w, out)
+}
+
+// rawJSON returns a stored JSON value for embedding in a response, with
+// missing values as null.
+func rawJSON(v *string) json.RawMessage {
+	if v == nil {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(*v)
+}
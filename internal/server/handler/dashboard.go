@@ -15,6 +15,7 @@ import (
 
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/health"
 	"github.com/sevigo/code-warden/internal/storage"
 )
 
@@ -28,13 +29,16 @@ const (
 
 // DashboardHandler serves dashboard, stats, reviews, jobs, and config endpoints.
 type DashboardHandler struct {
-	cfg    *config.Config
-	store  storage.Store
-	logger *slog.Logger
+	cfg        *config.Config
+	store      storage.Store
+	vectors    storage.VectorStore
+	dispatcher core.JobDispatcher
+	monitor    *health.Monitor
+	logger     *slog.Logger
 }
 
-func NewDashboardHandler(cfg *config.Config, store storage.Store, logger *slog.Logger) *DashboardHandler {
-	return &DashboardHandler{cfg: cfg, store: store, logger: logger}
+func NewDashboardHandler(cfg *config.Config, store storage.Store, vectors storage.VectorStore, dispatcher core.JobDispatcher, monitor *health.Monitor, logger *slog.Logger) *DashboardHandler {
+	return &DashboardHandler{cfg: cfg, store: store, vectors: vectors, dispatcher: dispatcher, monitor: monitor, logger: logger}
 }
 
 func (h *DashboardHandler) writeJSON(w http.ResponseWriter, v any) {
@@ -55,7 +59,7 @@ func (h *DashboardHandler) SetupStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	dbStatus, dbLatency := h.pingDatabase(ctx)
-	qdrantStatus, qdrantLatency := pingURL(h.cfg.Storage.QdrantHost, "/healthz", true)
+	qdrantStatus, qdrantLatency := h.pingVectorStore(dbStatus, dbLatency)
 	llmStatus, llmLatency := h.pingLLM()
 
 	installURL := ""
@@ -73,7 +77,7 @@ func (h *DashboardHandler) SetupStatus(w http.ResponseWriter, r *http.Request) {
 		},
 		"services": map[string]any{
 			"database": map[string]any{"status": dbStatus, "latency_ms": dbLatency},
-			"qdrant":   map[string]any{"status": qdrantStatus, "latency_ms": qdrantLatency},
+			"qdrant":   map[string]any{"status": qdrantStatus, "latency_ms": qdrantLatency, "provider": h.cfg.Storage.VectorStoreProvider},
 			"llm":      map[string]any{"status": llmStatus, "latency_ms": llmLatency, "provider": h.cfg.AI.LLMProvider},
 		},
 		"ready": configured && dbStatus == "ok" && qdrantStatus == "ok" && llmStatus == "ok",
@@ -91,6 +95,21 @@ func (h *DashboardHandler) pingDatabase(ctx context.Context) (string, int64) {
 	return "ok", latency
 }
 
+// pingVectorStore checks the vector store: Qdrant and Weaviate over HTTP,
+// pgvector through the database status.
+func (h *DashboardHandler) pingVectorStore(dbStatus string, dbLatency int64) (string, int64) {
+	switch {
+	case h.cfg.Storage.UsesQdrant():
+		return pingURL(h.cfg.Storage.QdrantHost, "/healthz", true)
+	case h.cfg.Storage.VectorStoreProvider == config.VectorStoreWeaviate:
+		return pingURL(strings.TrimSuffix(h.cfg.Storage.WeaviateURL, "/"), "/v1/.well-known/ready", false)
+	case h.cfg.Storage.VectorStoreProvider == config.VectorStorePgvector:
+		return dbStatus, dbLatency
+	default:
+		return "ok", 0
+	}
+}
+
 // pingURL performs a GET health check against a service URL.
 // When grpcPort is true, ":6334" is converted to ":6333" (Qdrant gRPC→HTTP).
 func pingURL(host, path string, grpcPort bool) (string, int64) {
@@ -174,6 +193,21 @@ func (h *DashboardHandler) GlobalStats(w http.ResponseWriter, r *http.Request) {
 		reviewStats = &storage.ReviewStats{}
 	}
 
+	cloneRecoveries, err := h.store.CountCloneRecoveries(ctx, time.Now().AddDate(0, 0, -7))
+	if err != nil {
+		h.logger.Error("failed to count clone recoveries", "error", err)
+	}
+
+	var queue core.QueueStats
+	if h.dispatcher != nil {
+		queue = h.dispatcher.QueueStats()
+	}
+
+	var queryCache storage.QueryCacheStats
+	if reporter, ok := h.vectors.(storage.QueryCacheReporter); ok {
+		queryCache = reporter.QueryCacheStats()
+	}
+
 	h.writeJSON(w, map[string]any{
 		"total_repos":       totalRepos,
 		"indexed_repos":     indexedRepos,
@@ -187,8 +221,12 @@ func (h *DashboardHandler) GlobalStats(w http.ResponseWriter, r *http.Request) {
 			"low":      0,
 		},
 		"avg_findings_per_review": 0.0,
-		"jobs_running":            0,
-		"jobs_queued":             0,
+		"jobs_running":            queue.Running,
+		"jobs_queued":             queue.Queued,
+		"clone_recoveries_7d":     cloneRecoveries,
+		"degraded_reviews_7d":     reviewStats.DegradedThisWeek,
+		"admission":               admissionStatus(h.monitor),
+		"query_cache":             queryCache,
 	})
 }
 
@@ -242,6 +280,33 @@ func (h *DashboardHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, out)
 }
 
+// ActiveJobs lists the queued and running jobs of the dispatcher. Their IDs
+// are the ones CancelJob takes, not the IDs of ListJobs.
+func (h *DashboardHandler) ActiveJobs(w http.ResponseWriter, _ *http.Request) {
+	if h.dispatcher == nil {
+		h.writeJSON(w, []any{})
+		return
+	}
+	h.writeJSON(w, h.dispatcher.ActiveJobs())
+}
+
+// CancelJob cancels a queued or running job by its ActiveJobs ID; it is
+// served under /jobs/active so that it cannot be mistaken for the ID of a
+// job run. A running review concludes its check run as cancelled; a job that
+// is not queued or running is not found.
+func (h *DashboardHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	if h.dispatcher == nil || !h.dispatcher.CancelJob(id) {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	h.writeJSON(w, map[string]any{"ok": true, "id": id})
+}
+
 // ── Reviews ─────────────────────────────────────────────────────────────────
 
 func (h *DashboardHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
@@ -271,6 +336,7 @@ func (h *DashboardHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
 		PRNumber       int       `json:"pr_number"`
 		PRTitle        string    `json:"pr_title"`
 		HeadSHA        string    `json:"head_sha"`
+		TreeSHA        string    `json:"tree_sha,omitempty"`
 		Status         string    `json:"status"`
 		SeverityCounts any       `json:"severity_counts"`
 		TotalFindings  int       `json:"total_findings"`
@@ -307,6 +373,7 @@ func (h *DashboardHandler) ListReviews(w http.ResponseWriter, r *http.Request) {
 			PRNumber:       rev.PRNumber,
 			PRTitle:        formatPRTitle(rev.PRNumber),
 			HeadSHA:        rev.HeadSHA,
+			TreeSHA:        rev.TreeSHA,
 			Status:         "reviewed",
 			SeverityCounts: counts,
 			TotalFindings:  total,
@@ -438,6 +505,99 @@ func (h *DashboardHandler) SubmitFeedback(w http.ResponseWriter, r *http.Request
 	h.writeJSON(w, map[string]bool{"ok": true})
 }
 
+// FeedbackMetrics returns suggestion acceptance grouped by model and prompt
+// version. The optional "repo" query parameter limits it to one repository.
+func (h *DashboardHandler) FeedbackMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics, err := h.store.GetFeedbackMetrics(r.Context(), r.URL.Query().Get("repo"))
+	if err != nil {
+		h.logger.Error("failed to get feedback metrics", "error", err)
+		h.writeJSON(w, []any{})
+		return
+	}
+
+	type metricDTO struct {
+		Model          string  `json:"model"`
+		PromptVersion  string  `json:"prompt_version"`
+		Suggestions    int     `json:"suggestions"`
+		Helpful        int     `json:"helpful"`
+		Wrong          int     `json:"wrong"`
+		AcceptanceRate float64 `json:"acceptance_rate"`
+	}
+
+	out := make([]metricDTO, 0, len(metrics))
+	for _, m := range metrics {
+		out = append(out, metricDTO{
+			Model:          m.Model,
+			PromptVersion:  m.PromptVersion,
+			Suggestions:    m.Suggestions,
+			Helpful:        m.Helpful,
+			Wrong:          m.Wrong,
+			AcceptanceRate: m.AcceptanceRate,
+		})
+	}
+	h.writeJSON(w, out)
+}
+
+// ── Usage ───────────────────────────────────────────────────────────────────
+
+// defaultUsageDays is the number of days GET /usage reports without ?days.
+const defaultUsageDays = 30
+
+// Usage returns the LLM token usage per day, repository and model, with the
+// estimated cost of models that have a price in usage.prices. ?repo limits it
+// to one repository, ?days sets the window and ?by_step=true splits it by
+// review step.
+func (h *DashboardHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	days := defaultUsageDays
+	if v, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && v > 0 {
+		days = v
+	}
+	byStep, _ := strconv.ParseBool(r.URL.Query().Get("by_step"))
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+
+	totals, err := h.store.GetLLMUsage(r.Context(), storage.LLMUsageFilter{
+		RepoFullName: r.URL.Query().Get("repo"),
+		Since:        since,
+		ByStep:       byStep,
+	})
+	if err != nil {
+		h.logger.Error("failed to get llm usage", "error", err)
+		h.writeJSON(w, []any{})
+		return
+	}
+
+	type usageDTO struct {
+		Day              string   `json:"day"`
+		RepoFullName     string   `json:"repo_full_name"`
+		Model            string   `json:"model"`
+		Step             string   `json:"step,omitempty"`
+		Calls            int64    `json:"calls"`
+		PromptTokens     int64    `json:"prompt_tokens"`
+		CompletionTokens int64    `json:"completion_tokens"`
+		EstimatedCalls   int64    `json:"estimated_calls"`
+		Cost             *float64 `json:"cost"`
+	}
+
+	out := make([]usageDTO, 0, len(totals))
+	for _, t := range totals {
+		dto := usageDTO{
+			Day:              t.Day.Format(time.DateOnly),
+			RepoFullName:     t.RepoFullName,
+			Model:            t.Model,
+			Step:             t.Step,
+			Calls:            t.Calls,
+			PromptTokens:     t.PromptTokens,
+			CompletionTokens: t.CompletionTokens,
+			EstimatedCalls:   t.EstimatedCalls,
+		}
+		if cost, ok := h.cfg.Usage.Cost(t.Model, t.PromptTokens, t.CompletionTokens); ok {
+			dto.Cost = &cost
+		}
+		out = append(out, dto)
+	}
+	h.writeJSON(w, out)
+}
+
 // ── Content Parsers ──────────────────────────────────────────────────────────
 
 // parseSeverityCounts scans review_content XML for <severity> tags.
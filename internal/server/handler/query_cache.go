@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// InvalidateQueryCache drops the cached similarity searches of the repository
+// named by the "repo" query parameter, for writers outside the server that
+// replace its collection, such as warden-cli index import.
+func InvalidateQueryCache(store storage.Store, cache storage.QueryCacheInvalidator, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		fullName := r.URL.Query().Get("repo")
+		if fullName == "" {
+			http.Error(w, "missing repo", http.StatusBadRequest)
+			return
+		}
+		repo, err := store.GetRepositoryByFullName(ctx, fullName)
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "repository not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to get repository", "repo", fullName, "error", err)
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+		cache.InvalidateQueryCache(repo.QdrantCollectionName)
+		logger.InfoContext(ctx, "query cache invalidated", "repo", fullName, "collection", repo.QdrantCollectionName)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]any{"ok": true, "repo": fullName}); err != nil {
+			logger.ErrorContext(ctx, "failed to encode response", "error", err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/health"
+)
+
+// readyzTimeout bounds the dependency checks of one readiness request.
+const readyzTimeout = 5 * time.Second
+
+// Healthz returns the liveness handler. It only reports that the process
+// serves requests; a failing dependency must not get the server restarted.
+func Healthz(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		writeProbeJSON(w, http.StatusOK, map[string]any{"status": "ok"}, logger)
+	}
+}
+
+// Readyz returns the readiness handler. It probes the dependencies of
+// checker and answers 503 "unavailable" when one fails. Otherwise it reports
+// "ready", "paused" with the reasons while review jobs are held because
+// their dependencies are unhealthy, or "degraded" while the circuit breaker
+// of a backend is open.
+func Readyz(monitor *health.Monitor, checker *health.Checker, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var checks []health.Check
+		healthy := true
+		if checker != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), readyzTimeout)
+			checks, healthy = checker.Check(ctx)
+			cancel()
+		}
+		status := admissionStatus(monitor)
+		state, code := "ready", http.StatusOK
+		switch {
+		case !healthy:
+			state, code = "unavailable", http.StatusServiceUnavailable
+		case status.Paused:
+			state = "paused"
+		case slices.ContainsFunc(status.Breakers, func(b health.BreakerStatus) bool { return b.State != health.BreakerClosed }):
+			state = "degraded"
+		}
+		writeProbeJSON(w, code, map[string]any{
+			"status":    state,
+			"checks":    checks,
+			"admission": status,
+		}, logger)
+	}
+}
+
+func writeProbeJSON(w http.ResponseWriter, code int, body any, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Error("failed to encode JSON response", "error", err)
+	}
+}
+
+// admissionStatus returns the status of monitor, or a healthy status when
+// there is no monitor.
+func admissionStatus(monitor *health.Monitor) health.Status {
+	if monitor == nil {
+		return health.Status{}
+	}
+	return monitor.Status()
+}
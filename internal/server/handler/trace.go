@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/sevigo/code-warden/internal/trace"
+)
+
+// ReviewTrace returns the handler of GET /reviews/{id}/trace. It answers the
+// ordered log timeline of the review whose ID the webhook response reported
+// in its X-Review-ID header, or 404 once the trace has been evicted.
+func ReviewTrace(traces *trace.Recorder, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t, ok := traces.Get(chi.URLParam(r, "id"))
+		if !ok {
+			http.Error(w, "review trace not found", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(t); err != nil {
+			logger.ErrorContext(r.Context(), "failed to encode review trace", "error", err)
+		}
+	}
+}
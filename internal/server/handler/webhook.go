@@ -3,15 +3,19 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
 
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/google/go-github/v73/github"
 
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+	"github.com/sevigo/code-warden/internal/jobs"
 )
 
 // WebhookHandler processes incoming webhooks from GitHub.
@@ -19,41 +23,73 @@ type WebhookHandler struct {
 	cfg        *config.Config
 	dispatcher core.JobDispatcher
 	canceller  core.SessionCanceller // optional; nil when agent is disabled
+	autoReview *jobs.Debouncer
+	verifier   *internalgithub.WebhookVerifier
 	logger     *slog.Logger
 }
 
 // NewWebhookHandler creates a new webhook handler with the given configuration and dispatcher.
-func NewWebhookHandler(cfg *config.Config, dispatcher core.JobDispatcher, canceller core.SessionCanceller, logger *slog.Logger) *WebhookHandler {
+// Replayed deliveries are only rejected when deliveries is not nil.
+func NewWebhookHandler(cfg *config.Config, dispatcher core.JobDispatcher, canceller core.SessionCanceller, deliveries internalgithub.WebhookDeliveries, logger *slog.Logger) *WebhookHandler {
 	return &WebhookHandler{
 		cfg:        cfg,
 		dispatcher: dispatcher,
 		canceller:  canceller,
+		autoReview: jobs.NewDebouncer(dispatcher, cfg.GitHub.AutoReview.Debounce, logger),
+		verifier:   internalgithub.NewWebhookVerifier(cfg.GitHub.WebhookSecret, cfg.GitHub.Webhook, deliveries, logger),
 		logger:     logger,
 	}
 }
 
 // Handle processes GitHub webhook requests.
 func (h *WebhookHandler) Handle(w http.ResponseWriter, r *http.Request) {
-	// Validate payload signature
-	payload, err := github.ValidatePayload(r, []byte(h.cfg.GitHub.WebhookSecret))
+	// The jobs of the delivery log under this ID, so their trace can be
+	// fetched from /api/v1/reviews/{id}/trace.
+	reviewID := core.NewReviewID()
+	ctx := core.WithReviewID(r.Context(), reviewID)
+	w.Header().Set("X-Review-ID", reviewID)
+
+	delivery, err := h.verifier.Verify(r.WithContext(ctx))
 	if err != nil {
-		h.logger.Error("invalid webhook payload signature", "error", err)
-		http.Error(w, "Invalid signature", http.StatusUnauthorized)
+		var whErr *internalgithub.WebhookError
+		if !errors.As(err, &whErr) {
+			whErr = &internalgithub.WebhookError{Status: http.StatusBadRequest, Reason: "invalid webhook", Err: err}
+		}
+		h.logger.WarnContext(ctx, "rejected webhook delivery", "status", whErr.Status, "delivery", r.Header.Get(github.DeliveryIDHeader), "error", err)
+		http.Error(w, whErr.Reason, whErr.Status)
 		return
 	}
 
-	event, err := github.ParseWebHook(github.WebHookType(r), payload)
+	event, err := github.ParseWebHook(delivery.Event, delivery.Payload)
 	if err != nil {
-		h.logger.Error("could not parse webhook", "error", err)
+		h.logger.ErrorContext(ctx, "could not parse webhook", "error", err)
 		http.Error(w, "Could not parse webhook", http.StatusBadRequest)
 		return
 	}
 
+	// A delivery that failed on our side is forgotten, so that GitHub can
+	// deliver it again instead of having it rejected as a replay.
+	ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+	defer func() {
+		if ww.Status() >= http.StatusInternalServerError {
+			h.verifier.Forget(ctx, delivery)
+		}
+	}()
+	w = ww
+
 	switch e := event.(type) {
 	case *github.IssueCommentEvent:
-		h.handleIssueComment(r.Context(), w, e)
+		h.handleIssueComment(ctx, w, e)
+	case *github.PullRequestEvent:
+		h.handlePullRequest(ctx, w, e)
+	case *github.PullRequestReviewCommentEvent:
+		h.handleReviewComment(ctx, w, e)
+	case *github.InstallationEvent:
+		h.handleInstallation(ctx, w, e)
+	case *github.InstallationRepositoriesEvent:
+		h.handleInstallationRepositories(ctx, w, e)
 	default:
-		h.logger.Debug("ignoring unhandled webhook event type", "type", github.WebHookType(r))
+		h.logger.DebugContext(ctx, "ignoring unhandled webhook event type", "type", delivery.Event)
 		_, _ = fmt.Fprint(w, "Event type not handled")
 	}
 }
@@ -62,7 +98,7 @@ func (h *WebhookHandler) handleIssueComment(ctx context.Context, w http.Response
 	// Ignore comment deletions - only process created and edited comments
 	action := event.GetAction()
 	if action != "created" && action != "edited" {
-		h.logger.Debug("ignoring issue comment", "reason", "action is "+action, "repo", event.GetRepo().GetFullName())
+		h.logger.DebugContext(ctx, "ignoring issue comment", "reason", "action is "+action, "repo", event.GetRepo().GetFullName())
 		_, _ = fmt.Fprint(w, "Comment action ignored")
 		return
 	}
@@ -78,14 +114,14 @@ func (h *WebhookHandler) handleIssueComment(ctx context.Context, w http.Response
 	if !event.GetIssue().IsPullRequest() {
 		implementEvent, err := core.ImplementEventFromIssueComment(event)
 		if err != nil {
-			h.logger.Debug("ignoring issue comment", "reason", err.Error(), "repo", event.GetRepo().GetFullName())
+			h.logger.DebugContext(ctx, "ignoring issue comment", "reason", err.Error(), "repo", event.GetRepo().GetFullName())
 			_, _ = fmt.Fprint(w, "Comment ignored")
 			return
 		}
 
 		// Check if agent functionality is enabled
 		if !h.cfg.Agent.Enabled {
-			h.logger.Warn("agent functionality is disabled, ignoring /implement command",
+			h.logger.WarnContext(ctx, "agent functionality is disabled, ignoring /implement command",
 				"repo", implementEvent.RepoFullName,
 				"issue", implementEvent.IssueNumber)
 			_, _ = fmt.Fprint(w, "Agent functionality is disabled. Enable it in config to use /implement.")
@@ -93,12 +129,12 @@ func (h *WebhookHandler) handleIssueComment(ctx context.Context, w http.Response
 		}
 
 		if err := h.dispatcher.Dispatch(ctx, implementEvent); err != nil {
-			h.logger.Error("failed to dispatch implement job", "error", err, "repo", implementEvent.RepoFullName)
+			h.logger.ErrorContext(ctx, "failed to dispatch implement job", "error", err, "repo", implementEvent.RepoFullName)
 			http.Error(w, "Failed to start implement job", http.StatusInternalServerError)
 			return
 		}
 
-		h.logger.Info("implement job dispatched successfully", "repo", implementEvent.RepoFullName, "issue", implementEvent.IssueNumber)
+		h.logger.InfoContext(ctx, "implement job dispatched successfully", "repo", implementEvent.RepoFullName, "issue", implementEvent.IssueNumber)
 		w.WriteHeader(http.StatusAccepted)
 		_, _ = fmt.Fprint(w, "Implement job accepted")
 		return
@@ -107,22 +143,179 @@ func (h *WebhookHandler) handleIssueComment(ctx context.Context, w http.Response
 	// Handle /review and /rereview commands on PRs
 	reviewEvent, err := core.EventFromIssueComment(event)
 	if err != nil {
-		h.logger.Debug("ignoring issue comment", "reason", err.Error(), "repo", event.GetRepo().GetFullName())
+		h.logger.DebugContext(ctx, "ignoring issue comment", "reason", err.Error(), "repo", event.GetRepo().GetFullName())
 		_, _ = fmt.Fprint(w, "Comment ignored")
 		return
 	}
 
 	if err := h.dispatcher.Dispatch(ctx, reviewEvent); err != nil {
-		h.logger.Error("failed to dispatch review job", "error", err, "repo", reviewEvent.RepoFullName)
+		h.logger.ErrorContext(ctx, "failed to dispatch review job", "error", err, "repo", reviewEvent.RepoFullName)
 		http.Error(w, "Failed to start review job", http.StatusInternalServerError)
 		return
 	}
 
-	h.logger.Info("review job dispatched successfully", "repo", reviewEvent.RepoFullName, "pr", reviewEvent.PRNumber)
+	h.logger.InfoContext(ctx, "review job dispatched successfully", "repo", reviewEvent.RepoFullName, "pr", reviewEvent.PRNumber)
 	w.WriteHeader(http.StatusAccepted)
 	_, _ = fmt.Fprint(w, "Review job accepted")
 }
 
+// handlePullRequest queues an auto-review when a pull request is opened or
+// pushed to, as configured in github.auto_review. Reviews wait for the
+// debounce delay, so that a burst of pushes is reviewed once.
+func (h *WebhookHandler) handlePullRequest(ctx context.Context, w http.ResponseWriter, event *github.PullRequestEvent) {
+	cfg := &h.cfg.GitHub.AutoReview
+	action := event.GetAction()
+	repoName := event.GetRepo().GetFullName()
+	if !cfg.Triggers(repoName, action) {
+		h.logger.DebugContext(ctx, "ignoring pull request event", "action", action, "repo", repoName)
+		_, _ = fmt.Fprint(w, "Pull request action ignored")
+		return
+	}
+	if event.GetPullRequest().GetDraft() && !cfg.Drafts {
+		h.logger.DebugContext(ctx, "ignoring draft pull request", "repo", repoName, "pr", event.GetNumber())
+		_, _ = fmt.Fprint(w, "Draft pull request ignored")
+		return
+	}
+
+	reviewEvent, err := core.EventFromPullRequest(event)
+	if err != nil {
+		h.logger.DebugContext(ctx, "ignoring pull request event", "reason", err.Error(), "repo", repoName)
+		_, _ = fmt.Fprint(w, "Pull request event ignored")
+		return
+	}
+
+	if err := h.autoReview.Schedule(ctx, reviewEvent); err != nil {
+		h.logger.ErrorContext(ctx, "failed to queue auto-review", "error", err, "repo", reviewEvent.RepoFullName)
+		http.Error(w, "Failed to queue review", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "auto-review queued", "repo", reviewEvent.RepoFullName, "pr", reviewEvent.PRNumber,
+		"action", action, "sha", reviewEvent.HeadSHA, "debounce", cfg.Debounce)
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprint(w, "Review queued")
+}
+
+// handleReviewComment records "/warden helpful|wrong" replies to inline
+// review suggestions and answers "/warden explain|fix" replies.
+func (h *WebhookHandler) handleReviewComment(ctx context.Context, w http.ResponseWriter, event *github.PullRequestReviewCommentEvent) {
+	if _, _, ok := core.ParseReplyCommand(event.GetComment().GetBody()); ok {
+		h.handleSuggestionReply(ctx, w, event)
+		return
+	}
+
+	feedbackEvent, err := core.FeedbackEventFromReviewComment(event)
+	if err != nil {
+		h.logger.DebugContext(ctx, "ignoring review comment", "reason", err.Error(), "repo", event.GetRepo().GetFullName())
+		_, _ = fmt.Fprint(w, "Comment ignored")
+		return
+	}
+
+	if err := h.dispatcher.Dispatch(ctx, feedbackEvent); err != nil {
+		h.logger.ErrorContext(ctx, "failed to dispatch feedback job", "error", err, "repo", feedbackEvent.RepoFullName)
+		http.Error(w, "Failed to record feedback", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "feedback job dispatched successfully", "repo", feedbackEvent.RepoFullName, "pr", feedbackEvent.PRNumber, "signal", feedbackEvent.FeedbackSignal)
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprint(w, "Feedback accepted")
+}
+
+// handleSuggestionReply dispatches a job answering a "/warden explain|fix"
+// reply in its thread.
+func (h *WebhookHandler) handleSuggestionReply(ctx context.Context, w http.ResponseWriter, event *github.PullRequestReviewCommentEvent) {
+	replyEvent, err := core.ReplyEventFromReviewComment(event)
+	if err != nil {
+		h.logger.DebugContext(ctx, "ignoring review comment", "reason", err.Error(), "repo", event.GetRepo().GetFullName())
+		_, _ = fmt.Fprint(w, "Comment ignored")
+		return
+	}
+
+	if err := h.dispatcher.Dispatch(ctx, replyEvent); err != nil {
+		h.logger.ErrorContext(ctx, "failed to dispatch reply job", "error", err, "repo", replyEvent.RepoFullName)
+		http.Error(w, "Failed to queue reply", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.InfoContext(ctx, "reply job dispatched successfully", "repo", replyEvent.RepoFullName, "pr", replyEvent.PRNumber, "command", replyEvent.ReplyCommand)
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprint(w, "Reply queued")
+}
+
+// handleInstallation checks the permissions of a new installation, or of one
+// that accepted changed permissions, so that missing ones show up in the log
+// right away instead of as 403s in the middle of the first review. The
+// repositories of a new installation are then onboarded.
+func (h *WebhookHandler) handleInstallation(ctx context.Context, w http.ResponseWriter, event *github.InstallationEvent) {
+	action := event.GetAction()
+	if action != "created" && action != "new_permissions_accepted" {
+		h.logger.DebugContext(ctx, "ignoring installation event", "action", action)
+		_, _ = fmt.Fprint(w, "Installation action ignored")
+		return
+	}
+
+	installation := event.GetInstallation()
+	account := installation.GetAccount().GetLogin()
+	message := "Installation permissions verified"
+	if err := internalgithub.CheckPermissions(installation.GetID(), installation.GetPermissions()); err != nil {
+		h.logger.ErrorContext(ctx, "GitHub App installation is missing permissions, reviews will fail until they are granted",
+			"account", account, "action", action, "error", err)
+		message = "Installation is missing permissions"
+	} else {
+		h.logger.InfoContext(ctx, "GitHub App installation has the required permissions", "account", account, "installation_id", installation.GetID(), "action", action)
+	}
+
+	if action == "created" {
+		h.onboard(ctx, installation, event.Repositories)
+	}
+	_, _ = fmt.Fprint(w, message)
+}
+
+// handleInstallationRepositories onboards the repositories an existing
+// installation was granted access to.
+func (h *WebhookHandler) handleInstallationRepositories(ctx context.Context, w http.ResponseWriter, event *github.InstallationRepositoriesEvent) {
+	if action := event.GetAction(); action != "added" {
+		h.logger.DebugContext(ctx, "ignoring installation repositories event", "action", action)
+		_, _ = fmt.Fprint(w, "Installation repositories action ignored")
+		return
+	}
+
+	n := h.onboard(ctx, event.GetInstallation(), event.RepositoriesAdded)
+	_, _ = fmt.Fprintf(w, "Onboarding %d repositories", n)
+}
+
+// onboard dispatches an onboarding job for each repository allowed by
+// github.onboarding.repos and returns how many were dispatched. Without
+// onboarding, new repositories are only registered by their first review.
+func (h *WebhookHandler) onboard(ctx context.Context, installation *github.Installation, repos []*github.Repository) int {
+	if !h.cfg.GitHub.Onboarding.Enabled {
+		h.logger.DebugContext(ctx, "onboarding is disabled, ignoring new repositories", "count", len(repos))
+		return 0
+	}
+
+	events, err := core.OnboardEventsFromInstallation(installation, repos)
+	if err != nil {
+		h.logger.WarnContext(ctx, "ignoring installation repositories", "reason", err.Error())
+		return 0
+	}
+
+	dispatched := 0
+	for _, event := range events {
+		if !h.cfg.GitHub.Onboarding.Allows(event.RepoFullName) {
+			h.logger.InfoContext(ctx, "repository is not in github.onboarding.repos, skipping onboarding", "repo", event.RepoFullName)
+			continue
+		}
+		if err := h.dispatcher.Dispatch(ctx, event); err != nil {
+			h.logger.ErrorContext(ctx, "failed to dispatch onboarding job", "error", err, "repo", event.RepoFullName)
+			continue
+		}
+		dispatched++
+	}
+	h.logger.InfoContext(ctx, "onboarding jobs dispatched", "account", installation.GetAccount().GetLogin(), "count", dispatched)
+	return dispatched
+}
+
 // handleCancelCommand checks if body is a /cancel command and cancels the session.
 // Returns true if the command was handled (caller should return).
 func (h *WebhookHandler) handleCancelCommand(w http.ResponseWriter, body string) bool {
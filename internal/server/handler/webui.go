@@ -8,38 +8,51 @@ import (
 	"log/slog"
 	"net/http"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	"github.com/sevigo/code-warden/internal/archgraph"
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/gitutil"
 	"github.com/sevigo/code-warden/internal/rag"
 	indexpkg "github.com/sevigo/code-warden/internal/rag/index"
 	"github.com/sevigo/code-warden/internal/repomanager"
+	"github.com/sevigo/code-warden/internal/repostats"
 	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/timetravel"
 )
 
 type WebUIHandler struct {
 	store      storage.Store
+	vectors    storage.VectorStore // optional; counts the documents of collections
 	ragService rag.Service
 	repoMgr    repomanager.RepoManager
 	gitClient  *gitutil.Client
+	dispatcher core.JobDispatcher // optional; cancels the jobs of deleted repositories
+	timeTravel *timetravel.Service
 	cfg        *config.Config
 	logger     *slog.Logger
 }
 
-func NewWebUIHandler(store storage.Store, ragService rag.Service, repoMgr repomanager.RepoManager, gitClient *gitutil.Client, cfg *config.Config, logger *slog.Logger) *WebUIHandler {
-	return &WebUIHandler{
+func NewWebUIHandler(store storage.Store, vectors storage.VectorStore, ragService rag.Service, repoMgr repomanager.RepoManager, gitClient *gitutil.Client, dispatcher core.JobDispatcher, cfg *config.Config, logger *slog.Logger) *WebUIHandler {
+	h := &WebUIHandler{
 		store:      store,
+		vectors:    vectors,
 		ragService: ragService,
 		repoMgr:    repoMgr,
 		gitClient:  gitClient,
+		dispatcher: dispatcher,
 		cfg:        cfg,
 		logger:     logger,
 	}
+	if ragService != nil && gitClient != nil {
+		h.timeTravel = timetravel.New(cfg, ragService, gitClient, logger)
+	}
+	return h
 }
 
 type RepositoryResponse struct {
@@ -48,6 +61,7 @@ type RepositoryResponse struct {
 	ClonePath            string `json:"clone_path"`
 	QdrantCollectionName string `json:"qdrant_collection_name"`
 	LastIndexedSHA       string `json:"last_indexed_sha"`
+	EmbedderModel        string `json:"embedder_model,omitempty"`
 	CreatedAt            string `json:"created_at"`
 	UpdatedAt            string `json:"updated_at"`
 }
@@ -76,15 +90,22 @@ type ArtifactsInfo struct {
 
 type RegisterRepoRequest struct {
 	FullName string `json:"full_name"`
+	// EmbedderModel indexes the repository with another model than
+	// ai.embedder_model.
+	EmbedderModel string `json:"embedder_model,omitempty"`
 }
 
 type ChatRequest struct {
 	Question string   `json:"question"`
 	History  []string `json:"history"`
+	// At answers the question for a past commit, branch or tag. A question
+	// of the form "/ask --at <ref> <question>" sets it as well.
+	At string `json:"at,omitempty"`
 }
 
 type ChatResponse struct {
-	Answer string `json:"answer"`
+	Answer    string          `json:"answer"`
+	Citations []core.Citation `json:"citations,omitempty"`
 }
 
 type ExplainRequest struct {
@@ -95,6 +116,13 @@ type ExplainResponse struct {
 	Content string `json:"content"`
 }
 
+// GraphResponse is the JSON form of a repository's architecture graph, with
+// the Mermaid source the dashboard renders.
+type GraphResponse struct {
+	*archgraph.Graph
+	Mermaid string `json:"mermaid"`
+}
+
 func parseRepoID(r *http.Request) (int64, error) {
 	var id int64
 	_, err := fmt.Sscanf(chi.URLParam(r, "repoId"), "%d", &id)
@@ -106,6 +134,13 @@ type RepoStatsResponse struct {
 	FilesCount     int    `json:"files_count"`
 	LastIndexedSHA string `json:"last_indexed_sha"`
 	LastScanDate   string `json:"last_scan_date"`
+	// Index is the coverage of the last full index, if one recorded it.
+	Index *core.IndexStats `json:"index,omitempty"`
+	// Collection counts the documents of the vector collection by chunk
+	// type; CollectionError says why it is missing.
+	Collection      *storage.CollectionStats `json:"collection,omitempty"`
+	CollectionError string                   `json:"collection_error,omitempty"`
+	Reviews         *repostats.ReviewStats   `json:"reviews,omitempty"`
 }
 
 func (h *WebUIHandler) GetRepoStats(w http.ResponseWriter, r *http.Request) {
@@ -148,6 +183,21 @@ func (h *WebUIHandler) GetRepoStats(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if indexStats, err := h.store.GetIndexStats(ctx, repo.ID); err == nil {
+		stats.Index = indexStats
+	}
+
+	if h.vectors != nil {
+		details, err := repostats.Collect(ctx, h.store, h.vectors, repo, h.cfg.AI.EmbedderModel, h.logger)
+		if err != nil {
+			h.logger.Warn("failed to collect repository stats", "repo", repo.FullName, "error", err)
+		} else {
+			stats.Collection = details.Index
+			stats.CollectionError = details.IndexError
+			stats.Reviews = &details.Reviews
+		}
+	}
+
 	h.json(w, stats)
 }
 
@@ -191,6 +241,51 @@ func (h *WebUIHandler) GetRepo(w http.ResponseWriter, r *http.Request) {
 	h.json(w, toRepositoryResponse(repo))
 }
 
+// DeleteRepoResponse reports an unregistered repository.
+type DeleteRepoResponse struct {
+	FullName      string `json:"full_name"`
+	CancelledJobs int    `json:"cancelled_jobs"`
+}
+
+// DeleteRepo unregisters a repository. Its queued and running jobs are
+// cancelled first, then its record, collection and managed clone are removed.
+func (h *WebUIHandler) DeleteRepo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	repoIDStr := chi.URLParam(r, "repoId")
+	var repoID int64
+	if _, err := fmt.Sscanf(repoIDStr, "%d", &repoID); err != nil {
+		http.Error(w, "invalid repo id", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := h.store.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "repository not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to get repository", "error", err)
+		http.Error(w, "failed to get repository", http.StatusInternalServerError)
+		return
+	}
+
+	cancelled := 0
+	if h.dispatcher != nil {
+		cancelled = h.dispatcher.CancelRepo(repo.FullName)
+	}
+	if err := h.repoMgr.DeleteRepo(ctx, repo.FullName); err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "repository not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to delete repository", "repo", repo.FullName, "error", err)
+		http.Error(w, fmt.Sprintf("failed to delete repository: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	h.json(w, DeleteRepoResponse{FullName: repo.FullName, CancelledJobs: cancelled})
+}
+
 func (h *WebUIHandler) RegisterRepo(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	var req RegisterRepoRequest
@@ -210,6 +305,7 @@ func (h *WebUIHandler) RegisterRepo(w http.ResponseWriter, r *http.Request) {
 		FullName:             req.FullName,
 		ClonePath:            clonePath,
 		QdrantCollectionName: collectionName,
+		EmbedderModel:        strings.TrimSpace(req.EmbedderModel),
 	}
 
 	if err := h.store.CreateRepository(ctx, repo); err != nil {
@@ -434,14 +530,50 @@ func (h *WebUIHandler) Chat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	answer, err := h.ragService.AnswerQuestion(ctx, repo.QdrantCollectionName, h.cfg.AI.EmbedderModel, req.Question, req.History)
+	if req.At == "" {
+		if ref, question, ok := timetravel.ParseAsk(req.Question); ok {
+			req.At, req.Question = ref, question
+		}
+	}
+	if req.At != "" {
+		h.chatAt(w, r, repo, req)
+		return
+	}
+
+	if repo = h.warm(w, r, repo); repo == nil {
+		return
+	}
+
+	answer, err := h.ragService.AnswerQuestion(ctx, repo.QdrantCollectionName, repo.Embedder(h.cfg.AI.EmbedderModel), req.Question, req.History)
 	if err != nil {
 		h.logger.Error("failed to answer question", "error", err)
 		http.Error(w, "failed to answer question", http.StatusInternalServerError)
 		return
 	}
 
-	h.json(w, ChatResponse{Answer: answer})
+	h.json(w, ChatResponse{Answer: answer.Text, Citations: answer.Citations})
+}
+
+// chatAt answers a chat question against a snapshot of a past commit.
+func (h *WebUIHandler) chatAt(w http.ResponseWriter, r *http.Request, repo *storage.Repository, req ChatRequest) {
+	if h.timeTravel == nil {
+		http.Error(w, "questions about past commits are not available", http.StatusNotImplemented)
+		return
+	}
+
+	// Earlier answers describe the current code, not the past commit.
+	answer, err := h.timeTravel.AnswerQuestion(r.Context(), repo, req.At, req.Question, nil)
+	if err != nil {
+		if errors.Is(err, timetravel.ErrUnknownRef) {
+			http.Error(w, fmt.Sprintf("unknown ref %q; it must be a commit, branch or tag in the clone", req.At), http.StatusBadRequest)
+			return
+		}
+		h.logger.Error("failed to answer question at past commit", "ref", req.At, "error", err)
+		http.Error(w, "failed to answer question", http.StatusInternalServerError)
+		return
+	}
+
+	h.json(w, ChatResponse{Answer: answer.Text, Citations: answer.Citations})
 }
 
 func (h *WebUIHandler) Explain(w http.ResponseWriter, r *http.Request) {
@@ -475,7 +607,11 @@ func (h *WebUIHandler) Explain(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	content, err := h.ragService.ExplainPath(ctx, repo.QdrantCollectionName, h.cfg.AI.EmbedderModel, req.Path)
+	if repo = h.warm(w, r, repo); repo == nil {
+		return
+	}
+
+	content, err := h.ragService.ExplainPath(ctx, repo.QdrantCollectionName, repo.Embedder(h.cfg.AI.EmbedderModel), req.Path)
 	if err != nil {
 		h.logger.Error("failed to explain path", "error", err)
 		http.Error(w, "failed to explain path", http.StatusInternalServerError)
@@ -485,6 +621,77 @@ func (h *WebUIHandler) Explain(w http.ResponseWriter, r *http.Request) {
 	h.json(w, ExplainResponse{Content: content})
 }
 
+// Graph returns the architecture graph of a repository: its directories,
+// their architectural summaries and the imports between them. The format
+// query parameter selects "dot", "mermaid" or "markdown" text instead of JSON.
+func (h *WebUIHandler) Graph(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	repoID, err := parseRepoID(r)
+	if err != nil {
+		http.Error(w, "invalid repo id", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := h.store.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			http.Error(w, "repository not found", http.StatusNotFound)
+			return
+		}
+		h.logger.Error("failed to get repository", "error", err)
+		http.Error(w, "failed to get repository", http.StatusInternalServerError)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "json" {
+		format = ""
+	}
+	if format != "" && !slices.Contains(archgraph.Formats, format) {
+		http.Error(w, "format must be json, "+strings.Join(archgraph.Formats, ", "), http.StatusBadRequest)
+		return
+	}
+
+	if repo = h.warm(w, r, repo); repo == nil {
+		return
+	}
+
+	graph, err := h.ragService.ArchGraph(ctx, repo.QdrantCollectionName, repo.Embedder(h.cfg.AI.EmbedderModel), repo.ClonePath)
+	if err != nil {
+		h.logger.Error("failed to build architecture graph", "repo", repo.FullName, "error", err)
+		http.Error(w, "failed to build architecture graph", http.StatusInternalServerError)
+		return
+	}
+
+	if format == "" {
+		h.json(w, GraphResponse{Graph: graph, Mermaid: graph.Mermaid()})
+		return
+	}
+	out, err := graph.Render(format, repo.FullName)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte(out))
+}
+
+// warm returns repo with its collection restored from cold storage, for
+// handlers that query it. On failure it writes the error response and
+// returns nil.
+func (h *WebUIHandler) warm(w http.ResponseWriter, r *http.Request, repo *storage.Repository) *storage.Repository {
+	if !repo.IsCold() {
+		return repo
+	}
+	restored, err := h.repoMgr.GetRepoRecord(r.Context(), repo.FullName)
+	if err != nil {
+		h.logger.Error("failed to restore repository from cold storage", "repo", repo.FullName, "error", err)
+		http.Error(w, "failed to restore repository from cold storage", http.StatusInternalServerError)
+		return nil
+	}
+	return restored
+}
+
 func (h *WebUIHandler) SSEEvents(w http.ResponseWriter, r *http.Request) {
 	repoIDStr := r.URL.Query().Get("repo_id")
 	var repoID int64
@@ -543,6 +750,7 @@ func toRepositoryResponse(repo *storage.Repository) RepositoryResponse {
 		ClonePath:            repo.ClonePath,
 		QdrantCollectionName: repo.QdrantCollectionName,
 		LastIndexedSHA:       repo.LastIndexedSHA,
+		EmbedderModel:        repo.EmbedderModel,
 		CreatedAt:            repo.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:            repo.UpdatedAt.Format(time.RFC3339),
 	}
@@ -11,19 +11,26 @@ import (
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/gitutil"
+	"github.com/sevigo/code-warden/internal/health"
 	"github.com/sevigo/code-warden/internal/rag"
 	"github.com/sevigo/code-warden/internal/repomanager"
 	"github.com/sevigo/code-warden/internal/server/handler"
+	"github.com/sevigo/code-warden/internal/settings"
 	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/trace"
 )
 
 // NewRouter creates and configures a new HTTP router with middleware and API routes.
 func NewRouter(cfg *config.Config, dispatcher core.JobDispatcher, logger *slog.Logger) *chi.Mux {
-	return NewRouterWithStore(cfg, dispatcher, nil, nil, nil, nil, nil, logger)
+	return NewRouterWithStore(cfg, dispatcher, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 }
 
 // NewRouterWithStore creates a router with storage for web UI endpoints.
-func NewRouterWithStore(cfg *config.Config, dispatcher core.JobDispatcher, canceller core.SessionCanceller, store storage.Store, ragService rag.Service, repoMgr repomanager.RepoManager, gitClient *gitutil.Client, logger *slog.Logger) *chi.Mux {
+// The admin API is served when settingsMgr is set and cfg.Server.AdminToken
+// is configured. /readyz probes the dependencies of checker and reports the
+// admission state of monitor, each when set. The review traces of traces
+// are served when it is set.
+func NewRouterWithStore(cfg *config.Config, dispatcher core.JobDispatcher, canceller core.SessionCanceller, store storage.Store, vectors storage.VectorStore, ragService rag.Service, repoMgr repomanager.RepoManager, gitClient *gitutil.Client, settingsMgr *settings.Manager, monitor *health.Monitor, checker *health.Checker, traces *trace.Recorder, logger *slog.Logger) *chi.Mux {
 	r := chi.NewRouter()
 
 	// Configure middleware stack
@@ -38,25 +45,36 @@ func NewRouterWithStore(cfg *config.Config, dispatcher core.JobDispatcher, cance
 		_, _ = w.Write([]byte("OK"))
 	})
 
+	// Kubernetes probes. Liveness only checks the process; readiness fails
+	// with 503 when a dependency is unreachable, but stays 200 while
+	// admission is paused because webhooks are still accepted and held in
+	// the queue.
+	r.Get("/healthz", handler.Healthz(logger))
+	r.Get("/readyz", handler.Readyz(monitor, checker, logger))
+
 	// API routes
 	r.Route("/api/v1", func(r chi.Router) {
-		webhookHandler := handler.NewWebhookHandler(cfg, dispatcher, canceller, logger)
+		webhookHandler := handler.NewWebhookHandler(cfg, dispatcher, canceller, store, logger)
 		// Short timeout for webhook delivery acknowledgement
 		r.With(middleware.Timeout(30*time.Second)).Post("/webhook/github", webhookHandler.Handle)
 
 		// Web UI API routes
 		if store != nil {
-			webUIHandler := handler.NewWebUIHandler(store, ragService, repoMgr, gitClient, cfg, logger)
-			dashboardHandler := handler.NewDashboardHandler(cfg, store, logger)
+			webUIHandler := handler.NewWebUIHandler(store, vectors, ragService, repoMgr, gitClient, dispatcher, cfg, logger)
+			dashboardHandler := handler.NewDashboardHandler(cfg, store, vectors, dispatcher, monitor, logger)
 
 			// Fast endpoints — short timeout is fine
 			r.With(middleware.Timeout(30*time.Second)).Get("/repos", webUIHandler.ListRepos)
 			r.With(middleware.Timeout(30*time.Second)).Post("/repos", webUIHandler.RegisterRepo)
 			r.With(middleware.Timeout(30*time.Second)).Get("/repos/{repoId}", webUIHandler.GetRepo)
+			r.With(middleware.Timeout(30*time.Second)).Delete("/repos/{repoId}", webUIHandler.DeleteRepo)
 			r.With(middleware.Timeout(30*time.Second)).Post("/repos/{repoId}/scan", webUIHandler.TriggerScan)
 			r.With(middleware.Timeout(30*time.Second)).Get("/repos/{repoId}/status", webUIHandler.GetScanStatus)
 			r.With(middleware.Timeout(30*time.Second)).Get("/repos/{repoId}/stats", webUIHandler.GetRepoStats)
 
+			// Parses every file of the clone — longer timeout for large repositories
+			r.With(middleware.Timeout(2*time.Minute)).Get("/repos/{repoId}/graph", webUIHandler.Graph)
+
 			// LLM endpoints — 10 min timeout (Ollama can be slow)
 			r.With(middleware.Timeout(10*time.Minute)).Post("/repos/{repoId}/chat", webUIHandler.Chat)
 			r.With(middleware.Timeout(10*time.Minute)).Post("/repos/{repoId}/explain", webUIHandler.Explain)
@@ -69,12 +87,41 @@ func NewRouterWithStore(cfg *config.Config, dispatcher core.JobDispatcher, cance
 			r.With(middleware.Timeout(30*time.Second)).Get("/config", dashboardHandler.GetConfig)
 			r.With(middleware.Timeout(30*time.Second)).Get("/stats/global", dashboardHandler.GlobalStats)
 			r.With(middleware.Timeout(30*time.Second)).Get("/jobs", dashboardHandler.ListJobs)
+			r.With(middleware.Timeout(30*time.Second)).Get("/jobs/active", dashboardHandler.ActiveJobs)
+			r.With(middleware.Timeout(30*time.Second)).Delete("/jobs/active/{id}", dashboardHandler.CancelJob)
 			r.With(middleware.Timeout(30*time.Second)).Get("/repos/{repoId}/reviews", dashboardHandler.ListReviews)
 			r.With(middleware.Timeout(30*time.Second)).Get("/repos/{repoId}/reviews/{prNumber}", dashboardHandler.GetReview)
 			r.With(middleware.Timeout(30*time.Second)).Post("/repos/{repoId}/reviews/{prNumber}/feedback", dashboardHandler.SubmitFeedback)
+			r.With(middleware.Timeout(30*time.Second)).Get("/feedback/metrics", dashboardHandler.FeedbackMetrics)
+			r.With(middleware.Timeout(30*time.Second)).Get("/usage", dashboardHandler.Usage)
+			if traces != nil {
+				r.With(middleware.Timeout(30*time.Second)).Get("/reviews/{id}/trace", handler.ReviewTrace(traces, logger))
+			}
+
+			// Admin endpoints — runtime model and prompt settings
+			if settingsMgr != nil && cfg.Server.AdminToken != "" {
+				adminHandler := handler.NewAdminHandler(cfg, settingsMgr, store, logger)
+				r.Route("/admin", func(r chi.Router) {
+					r.Use(handler.RequireToken(cfg.Server.AdminToken))
+					r.Use(middleware.Timeout(30 * time.Second))
+					r.Get("/settings", adminHandler.GetSettings)
+					r.Get("/settings/audit", adminHandler.ListAudit)
+					r.Put("/settings/{key}", adminHandler.PutSetting)
+					r.Delete("/settings/{key}", adminHandler.DeleteSetting)
+					if cache, ok := vectors.(storage.QueryCacheInvalidator); ok {
+						r.Delete("/query-cache", handler.InvalidateQueryCache(store, cache, logger))
+					}
+				})
+			}
 		}
 	})
 
+	// Server-rendered review activity page
+	if store != nil && cfg.Server.DashboardToken != "" {
+		activityHandler := handler.NewActivityHandler(store, dispatcher, monitor, logger)
+		r.With(handler.RequireDashboardToken(cfg.Server.DashboardToken), middleware.Timeout(30*time.Second)).Get("/activity", activityHandler.Page)
+	}
+
 	// Serve static UI files (built React app)
 	if store != nil {
 		fs := http.FileServer(http.Dir("./ui/dist"))
@@ -12,9 +12,12 @@ import (
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/gitutil"
+	"github.com/sevigo/code-warden/internal/health"
 	"github.com/sevigo/code-warden/internal/rag"
 	"github.com/sevigo/code-warden/internal/repomanager"
+	"github.com/sevigo/code-warden/internal/settings"
 	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/trace"
 )
 
 // Server wraps an HTTP server with graceful shutdown capabilities.
@@ -26,12 +29,12 @@ type Server struct {
 
 // NewServer creates a new HTTP server with the given configuration and job dispatcher.
 func NewServer(ctx context.Context, cfg *config.Config, dispatcher core.JobDispatcher, logger *slog.Logger) *Server {
-	return NewServerWithStore(ctx, cfg, dispatcher, nil, nil, nil, nil, nil, logger)
+	return NewServerWithStore(ctx, cfg, dispatcher, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 }
 
 // NewServerWithStore creates a new HTTP server with storage for web UI endpoints.
-func NewServerWithStore(ctx context.Context, cfg *config.Config, dispatcher core.JobDispatcher, canceller core.SessionCanceller, store storage.Store, ragService rag.Service, repoMgr repomanager.RepoManager, gitClient *gitutil.Client, logger *slog.Logger) *Server {
-	router := NewRouterWithStore(cfg, dispatcher, canceller, store, ragService, repoMgr, gitClient, logger)
+func NewServerWithStore(ctx context.Context, cfg *config.Config, dispatcher core.JobDispatcher, canceller core.SessionCanceller, store storage.Store, vectors storage.VectorStore, ragService rag.Service, repoMgr repomanager.RepoManager, gitClient *gitutil.Client, settingsMgr *settings.Manager, monitor *health.Monitor, checker *health.Checker, traces *trace.Recorder, logger *slog.Logger) *Server {
+	router := NewRouterWithStore(cfg, dispatcher, canceller, store, vectors, ragService, repoMgr, gitClient, settingsMgr, monitor, checker, traces, logger)
 
 	return &Server{
 		ctx: ctx,
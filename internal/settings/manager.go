@@ -0,0 +1,257 @@
+// Package settings manages runtime overrides of the model and prompt
+// configuration that administrators change through the admin API. Overrides
+// are stored in the database, take precedence over the configuration file and
+// environment, and apply to jobs started after the change. Every change is
+// recorded with its author in an audit trail.
+package settings
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// Setting keys accepted by [Manager.Set].
+const (
+	// KeyGeneratorModel is the generator model name, a JSON string.
+	KeyGeneratorModel = "generator_model"
+	// KeyComparisonModels is the consensus model set, a JSON array of names.
+	KeyComparisonModels = "comparison_models"
+	// KeyPrompts is the prompt bundle, a JSON object that maps prompt names
+	// such as "code_review" to template sources replacing the built-in ones.
+	KeyPrompts = "prompts"
+)
+
+// keyEmbedderModel is recognized only to explain why it cannot be changed.
+const keyEmbedderModel = "embedder_model"
+
+// ErrInvalid is returned for unknown keys and values that fail validation.
+var ErrInvalid = errors.New("invalid setting")
+
+// GeneratorSwitcher switches the generator model in use, e.g. rag.Service.
+type GeneratorSwitcher interface {
+	SetGeneratorModel(ctx context.Context, modelName string) error
+}
+
+// Manager holds the runtime overrides and applies them to the components
+// that use them.
+type Manager struct {
+	cfg       *config.Config
+	store     storage.SettingsStore
+	prompts   *llm.PromptManager
+	generator GeneratorSwitcher
+	logger    *slog.Logger
+
+	mu               sync.Mutex
+	values           map[string]json.RawMessage
+	generatorModel   string
+	comparisonModels []string
+}
+
+// NewManager creates a new [Manager] without overrides. Call [Manager.Load]
+// to apply the stored ones.
+func NewManager(cfg *config.Config, store storage.SettingsStore, prompts *llm.PromptManager, generator GeneratorSwitcher, logger *slog.Logger) *Manager {
+	return &Manager{
+		cfg:       cfg,
+		store:     store,
+		prompts:   prompts,
+		generator: generator,
+		logger:    logger,
+		values:    make(map[string]json.RawMessage),
+	}
+}
+
+// Load applies the stored overrides. Overrides that no longer validate, for
+// example a prompt that was removed from the build, are logged and skipped.
+func (m *Manager) Load(ctx context.Context) error {
+	stored, err := m.store.ListRuntimeSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load runtime settings: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range stored {
+		value := json.RawMessage(s.Value)
+		if err := m.apply(ctx, s.Key, value); err != nil {
+			m.logger.Warn("skipping stored runtime setting", "key", s.Key, "updated_by", s.UpdatedBy, "error", err)
+			continue
+		}
+		m.values[s.Key] = value
+		m.logger.Info("applied runtime setting", "key", s.Key, "updated_by", s.UpdatedBy)
+	}
+	return nil
+}
+
+// Overrides returns the current overrides by key.
+func (m *Manager) Overrides() map[string]json.RawMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return maps.Clone(m.values)
+}
+
+// Set validates value, applies it as the override of key and records the
+// change by actor. A nil value removes the override, restoring the
+// configured default. If the change cannot be saved, it is rolled back.
+func (m *Manager) Set(ctx context.Context, key string, value json.RawMessage, actor string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous, hadPrevious := m.values[key]
+	if err := m.apply(ctx, key, value); err != nil {
+		return err
+	}
+
+	var stored *string
+	if value != nil {
+		s := string(value)
+		stored = &s
+	}
+	if err := m.store.SetRuntimeSetting(ctx, key, stored, actor); err != nil {
+		if !hadPrevious {
+			previous = nil
+		}
+		if rerr := m.apply(ctx, key, previous); rerr != nil {
+			m.logger.Error("failed to roll back runtime setting", "key", key, "error", rerr)
+		}
+		return err
+	}
+
+	if value == nil {
+		delete(m.values, key)
+	} else {
+		m.values[key] = value
+	}
+	m.logger.Info("runtime setting changed", "key", key, "actor", actor, "reset", value == nil)
+	return nil
+}
+
+// Prompts lists the prompt templates in use with their versions, including
+// the variants of prompt experiments.
+func (m *Manager) Prompts() []llm.PromptInfo {
+	return m.prompts.Prompts()
+}
+
+// AI returns the AI configuration with the model overrides applied.
+func (m *Manager) AI() config.AIConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.ai()
+}
+
+func (m *Manager) ai() config.AIConfig {
+	ai := m.cfg.AI
+	if m.generatorModel != "" {
+		ai.GeneratorModel = m.generatorModel
+	}
+	if m.comparisonModels != nil {
+		ai.ComparisonModels = slices.Clone(m.comparisonModels)
+	}
+	return ai
+}
+
+// apply validates value for key and makes it effective. A nil value
+// restores the default.
+func (m *Manager) apply(ctx context.Context, key string, value json.RawMessage) error {
+	switch key {
+	case KeyGeneratorModel:
+		return m.applyGeneratorModel(ctx, value)
+	case KeyComparisonModels:
+		return m.applyComparisonModels(value)
+	case KeyPrompts:
+		return m.applyPrompts(value)
+	case keyEmbedderModel:
+		return fmt.Errorf("%w: %s cannot change at runtime because indexed repositories must be re-embedded with the new model", ErrInvalid, key)
+	default:
+		return fmt.Errorf("%w: unknown key %q", ErrInvalid, key)
+	}
+}
+
+func (m *Manager) applyGeneratorModel(ctx context.Context, value json.RawMessage) error {
+	var name string
+	if value != nil {
+		if err := json.Unmarshal(value, &name); err != nil {
+			return fmt.Errorf("%w: %s must be a string: %w", ErrInvalid, KeyGeneratorModel, err)
+		}
+		if name = strings.TrimSpace(name); name == "" {
+			return fmt.Errorf("%w: %s cannot be empty", ErrInvalid, KeyGeneratorModel)
+		}
+	}
+
+	ai := m.ai()
+	ai.GeneratorModel = cmp.Or(name, m.cfg.AI.GeneratorModel)
+	if err := m.validate(ai); err != nil {
+		return err
+	}
+	if err := m.generator.SetGeneratorModel(ctx, name); err != nil {
+		return fmt.Errorf("failed to switch generator model: %w", err)
+	}
+	m.generatorModel = name
+	return nil
+}
+
+func (m *Manager) applyComparisonModels(value json.RawMessage) error {
+	var models []string
+	if value != nil {
+		if err := json.Unmarshal(value, &models); err != nil {
+			return fmt.Errorf("%w: %s must be an array of model names: %w", ErrInvalid, KeyComparisonModels, err)
+		}
+		if models == nil {
+			models = []string{}
+		}
+	}
+
+	ai := m.ai()
+	ai.ComparisonModels = m.cfg.AI.ComparisonModels
+	if models != nil {
+		ai.ComparisonModels = models
+	}
+	if err := m.validate(ai); err != nil {
+		return err
+	}
+	m.comparisonModels = models
+	return nil
+}
+
+func (m *Manager) applyPrompts(value json.RawMessage) error {
+	var bundle map[string]string
+	if value != nil {
+		if err := json.Unmarshal(value, &bundle); err != nil {
+			return fmt.Errorf("%w: %s must be an object of prompt templates: %w", ErrInvalid, KeyPrompts, err)
+		}
+	}
+
+	overrides := make(map[llm.PromptKey]string, len(bundle))
+	for key, content := range bundle {
+		overrides[llm.PromptKey(key)] = content
+	}
+	if err := m.prompts.SetOverrides(overrides); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return nil
+}
+
+// validate checks an AI configuration with overrides applied against the
+// rules the configuration file must satisfy.
+func (m *Manager) validate(ai config.AIConfig) error {
+	if err := ai.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	if ai.LocalOnly {
+		if err := ai.ValidateLocalOnly(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalid, err)
+		}
+	}
+	return nil
+}
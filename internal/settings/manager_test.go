@@ -0,0 +1,126 @@
+package settings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/llm"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+type fakeSwitcher struct {
+	model string
+	err   error
+}
+
+func (f *fakeSwitcher) SetGeneratorModel(_ context.Context, modelName string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.model = modelName
+	return nil
+}
+
+func newTestManager(t *testing.T) (*Manager, *mocks.MockStore, *fakeSwitcher, *llm.PromptManager) {
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStore(ctrl)
+	prompts, err := llm.NewPromptManager()
+	require.NoError(t, err)
+	switcher := &fakeSwitcher{}
+	cfg := &config.Config{AI: config.AIConfig{
+		GeneratorModel:      "base-model",
+		ComparisonModels:    []string{"a", "b"},
+		HyDEConcurrency:     1,
+		ConsensusMaxWorkers: 2,
+	}}
+	m := NewManager(cfg, store, prompts, switcher, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return m, store, switcher, prompts
+}
+
+func ptr(s string) *string { return &s }
+
+func TestManager_Set(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("generator model", func(t *testing.T) {
+		m, store, switcher, _ := newTestManager(t)
+		store.EXPECT().SetRuntimeSetting(ctx, KeyGeneratorModel, ptr(`"other-model"`), "alice").Return(nil)
+
+		require.NoError(t, m.Set(ctx, KeyGeneratorModel, json.RawMessage(`"other-model"`), "alice"))
+		assert.Equal(t, "other-model", switcher.model)
+		assert.Equal(t, "other-model", m.AI().GeneratorModel)
+		assert.JSONEq(t, `"other-model"`, string(m.Overrides()[KeyGeneratorModel]))
+	})
+
+	t.Run("reset restores default", func(t *testing.T) {
+		m, store, switcher, _ := newTestManager(t)
+		store.EXPECT().SetRuntimeSetting(ctx, KeyComparisonModels, gomock.Any(), "alice").Return(nil)
+		store.EXPECT().SetRuntimeSetting(ctx, KeyComparisonModels, nil, "bob").Return(nil)
+
+		require.NoError(t, m.Set(ctx, KeyComparisonModels, json.RawMessage(`["c"]`), "alice"))
+		assert.Equal(t, []string{"c"}, m.AI().ComparisonModels)
+
+		require.NoError(t, m.Set(ctx, KeyComparisonModels, nil, "bob"))
+		assert.Equal(t, []string{"a", "b"}, m.AI().ComparisonModels)
+		assert.Empty(t, m.Overrides())
+		assert.Empty(t, switcher.model, "generator untouched")
+	})
+
+	t.Run("prompt bundle", func(t *testing.T) {
+		m, store, _, prompts := newTestManager(t)
+		store.EXPECT().SetRuntimeSetting(ctx, KeyPrompts, gomock.Any(), "alice").Return(nil)
+
+		require.NoError(t, m.Set(ctx, KeyPrompts, json.RawMessage(`{"code_review": "Review {{.Diff}}"}`), "alice"))
+		raw, err := prompts.Raw(llm.CodeReviewPrompt)
+		require.NoError(t, err)
+		assert.Equal(t, "Review {{.Diff}}", raw)
+	})
+
+	t.Run("invalid values are not saved", func(t *testing.T) {
+		m, _, _, _ := newTestManager(t)
+		for key, value := range map[string]string{
+			KeyGeneratorModel:   `""`,
+			KeyComparisonModels: `["c", "c"]`,
+			KeyPrompts:          `{"no_such_prompt": "x"}`,
+			"embedder_model":    `"other-embedder"`,
+			"unknown":           `1`,
+		} {
+			err := m.Set(ctx, key, json.RawMessage(value), "alice")
+			assert.ErrorIs(t, err, ErrInvalid, key)
+		}
+	})
+
+	t.Run("rolls back when saving fails", func(t *testing.T) {
+		m, store, switcher, _ := newTestManager(t)
+		store.EXPECT().SetRuntimeSetting(ctx, KeyGeneratorModel, gomock.Any(), "alice").Return(errors.New("db down"))
+
+		require.Error(t, m.Set(ctx, KeyGeneratorModel, json.RawMessage(`"other-model"`), "alice"))
+		assert.Empty(t, switcher.model, "switched back to the configured model")
+		assert.Equal(t, "base-model", m.AI().GeneratorModel)
+	})
+}
+
+func TestManager_Load(t *testing.T) {
+	ctx := context.Background()
+	m, store, switcher, _ := newTestManager(t)
+	store.EXPECT().ListRuntimeSettings(ctx).Return([]*storage.RuntimeSetting{
+		{Key: KeyGeneratorModel, Value: `"other-model"`, UpdatedBy: "alice"},
+		{Key: KeyPrompts, Value: `{"no_such_prompt": "x"}`, UpdatedBy: "alice"},
+	}, nil)
+
+	require.NoError(t, m.Load(ctx))
+	assert.Equal(t, "other-model", switcher.model)
+	assert.Equal(t, []string{KeyGeneratorModel}, slices.Collect(maps.Keys(m.Overrides())), "invalid stored settings are skipped")
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Roles of chat messages.
+const (
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+)
+
+// ChatSession is a conversation about a repository in the terminal chat.
+// Summary condenses its oldest SummarizedMessages messages, which are no
+// longer sent to the model verbatim.
+type ChatSession struct {
+	ID                 int64     `db:"id"`
+	RepositoryID       int64     `db:"repository_id"`
+	Title              string    `db:"title"`
+	Summary            string    `db:"summary"`
+	SummarizedMessages int       `db:"summarized_messages"`
+	CreatedAt          time.Time `db:"created_at"`
+	UpdatedAt          time.Time `db:"updated_at"`
+	// MessageCount is only set by ListChatSessions.
+	MessageCount int `db:"message_count"`
+}
+
+// ChatMessage is a question or an answer of a chat session.
+type ChatMessage struct {
+	ID        int64     `db:"id"`
+	SessionID int64     `db:"session_id"`
+	Role      string    `db:"role"`
+	Content   string    `db:"content"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// ChatStore defines persistence operations for chat sessions. It is a
+// sub-interface implemented by postgresStore.
+type ChatStore interface {
+	// CreateChatSession inserts a session and sets its ID and timestamps.
+	CreateChatSession(ctx context.Context, session *ChatSession) error
+	// GetChatSession returns a session, or ErrNotFound.
+	GetChatSession(ctx context.Context, id int64) (*ChatSession, error)
+	// ListChatSessions returns up to limit sessions of a repository, the
+	// most recently active first.
+	ListChatSessions(ctx context.Context, repoID int64, limit int) ([]*ChatSession, error)
+	// AddChatMessage appends a message to its session and sets its ID and
+	// CreatedAt.
+	AddChatMessage(ctx context.Context, msg *ChatMessage) error
+	// GetChatMessages returns the messages of a session in order.
+	GetChatMessages(ctx context.Context, sessionID int64) ([]*ChatMessage, error)
+	// UpdateChatSummary replaces the summary of a session and the number of
+	// messages it covers.
+	UpdateChatSummary(ctx context.Context, sessionID int64, summary string, summarizedMessages int) error
+}
+
+// CreateChatSession inserts a chat_sessions row.
+func (s *postgresStore) CreateChatSession(ctx context.Context, session *ChatSession) error {
+	query := `
+		INSERT INTO chat_sessions (repository_id, title)
+		VALUES ($1, $2)
+		RETURNING id, created_at, updated_at`
+	row := s.db.QueryRowContext(ctx, query, session.RepositoryID, session.Title)
+	if err := row.Scan(&session.ID, &session.CreatedAt, &session.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to create chat session: %w", err)
+	}
+	return nil
+}
+
+// GetChatSession selects a chat_sessions row.
+func (s *postgresStore) GetChatSession(ctx context.Context, id int64) (*ChatSession, error) {
+	var session ChatSession
+	query := `
+		SELECT id, repository_id, title, summary, summarized_messages, created_at, updated_at
+		FROM chat_sessions WHERE id = $1`
+	if err := s.db.GetContext(ctx, &session, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get chat session: %w", err)
+	}
+	return &session, nil
+}
+
+// ListChatSessions selects the chat_sessions rows of a repository with the
+// number of their messages.
+func (s *postgresStore) ListChatSessions(ctx context.Context, repoID int64, limit int) ([]*ChatSession, error) {
+	query := `
+		SELECT s.id, s.repository_id, s.title, s.summary, s.summarized_messages, s.created_at, s.updated_at,
+		       (SELECT COUNT(*) FROM chat_messages m WHERE m.session_id = s.id) AS message_count
+		FROM chat_sessions s
+		WHERE s.repository_id = $1
+		ORDER BY s.updated_at DESC, s.id DESC
+		LIMIT $2`
+	var sessions []*ChatSession
+	if err := s.db.SelectContext(ctx, &sessions, query, repoID, limit); err != nil {
+		return nil, fmt.Errorf("failed to list chat sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// AddChatMessage inserts a chat_messages row and marks its session as
+// active.
+func (s *postgresStore) AddChatMessage(ctx context.Context, msg *ChatMessage) error {
+	query := `
+		WITH touched AS (
+			UPDATE chat_sessions SET updated_at = NOW() WHERE id = $1
+		)
+		INSERT INTO chat_messages (session_id, role, content)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+	row := s.db.QueryRowContext(ctx, query, msg.SessionID, msg.Role, msg.Content)
+	if err := row.Scan(&msg.ID, &msg.CreatedAt); err != nil {
+		return fmt.Errorf("failed to add chat message: %w", err)
+	}
+	return nil
+}
+
+// GetChatMessages selects the chat_messages rows of a session.
+func (s *postgresStore) GetChatMessages(ctx context.Context, sessionID int64) ([]*ChatMessage, error) {
+	var msgs []*ChatMessage
+	query := `
+		SELECT id, session_id, role, content, created_at
+		FROM chat_messages WHERE session_id = $1
+		ORDER BY id`
+	if err := s.db.SelectContext(ctx, &msgs, query, sessionID); err != nil {
+		return nil, fmt.Errorf("failed to get chat messages: %w", err)
+	}
+	return msgs, nil
+}
+
+// UpdateChatSummary updates the summary columns of a chat_sessions row.
+func (s *postgresStore) UpdateChatSummary(ctx context.Context, sessionID int64, summary string, summarizedMessages int) error {
+	query := `UPDATE chat_sessions SET summary = $2, summarized_messages = $3 WHERE id = $1`
+	res, err := s.db.ExecContext(ctx, query, sessionID, summary, summarizedMessages)
+	if err != nil {
+		return fmt.Errorf("failed to update chat summary: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
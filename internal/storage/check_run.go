@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CheckRunInProgress is the status of a check run that has not concluded.
+const CheckRunInProgress = "in_progress"
+
+// CheckRun records a GitHub check run created by Code-Warden, so that runs
+// left in progress by a crashed job can be found and concluded later.
+type CheckRun struct {
+	// ID is the GitHub check run ID.
+	ID             int64  `db:"id"`
+	InstallationID int64  `db:"installation_id"`
+	RepoOwner      string `db:"repo_owner"`
+	RepoName       string `db:"repo_name"`
+	Name           string `db:"name"`
+	HeadSHA        string `db:"head_sha"`
+	// PRNumber and JobKind name the job that created the check run, JobKind
+	// as core.ReviewType.String. PRNumber is zero for jobs without a pull
+	// request.
+	PRNumber int    `db:"pr_number"`
+	JobKind  string `db:"job_kind"`
+	// Attempt counts how often the job was queued again after an earlier
+	// check run of it went stale.
+	Attempt int `db:"attempt"`
+	// Status is CheckRunInProgress or "completed".
+	Status      string     `db:"status"`
+	Conclusion  string     `db:"conclusion"`
+	StartedAt   time.Time  `db:"started_at"`
+	CompletedAt *time.Time `db:"completed_at"`
+}
+
+// CheckRunStore defines persistence operations for check runs.
+// It is a sub-interface implemented by postgresStore.
+type CheckRunStore interface {
+	// InsertCheckRun records a check run that was just created on GitHub.
+	InsertCheckRun(ctx context.Context, run *CheckRun) error
+	// CompleteCheckRun marks a check run as completed with a conclusion.
+	CompleteCheckRun(ctx context.Context, id int64, conclusion string) error
+	// ListInProgressCheckRuns returns the check runs still in progress that
+	// were started before the given time, oldest first.
+	ListInProgressCheckRuns(ctx context.Context, startedBefore time.Time) ([]*CheckRun, error)
+}
+
+// InsertCheckRun inserts a check_runs row. Recording the same check run
+// twice is a no-op.
+func (s *postgresStore) InsertCheckRun(ctx context.Context, run *CheckRun) error {
+	query := `
+		INSERT INTO check_runs (id, installation_id, repo_owner, repo_name, name, head_sha, pr_number, job_kind, attempt, status)
+		VALUES (:id, :installation_id, :repo_owner, :repo_name, :name, :head_sha, :pr_number, :job_kind, :attempt, :status)
+		ON CONFLICT (id) DO NOTHING`
+
+	if _, err := s.db.NamedExecContext(ctx, query, run); err != nil {
+		return fmt.Errorf("failed to record check run %d: %w", run.ID, err)
+	}
+	return nil
+}
+
+// CompleteCheckRun sets the status of a check_runs row to completed.
+func (s *postgresStore) CompleteCheckRun(ctx context.Context, id int64, conclusion string) error {
+	query := `
+		UPDATE check_runs
+		SET status = 'completed', conclusion = $1, completed_at = NOW()
+		WHERE id = $2`
+
+	if _, err := s.db.ExecContext(ctx, query, conclusion, id); err != nil {
+		return fmt.Errorf("failed to complete check run %d: %w", id, err)
+	}
+	return nil
+}
+
+// ListInProgressCheckRuns selects in-progress check_runs rows started before
+// the given time.
+func (s *postgresStore) ListInProgressCheckRuns(ctx context.Context, startedBefore time.Time) ([]*CheckRun, error) {
+	query := `
+		SELECT id, installation_id, repo_owner, repo_name, name, head_sha, pr_number, job_kind, attempt, status, conclusion, started_at, completed_at
+		FROM check_runs
+		WHERE status = 'in_progress' AND started_at < $1
+		ORDER BY started_at`
+
+	var runs []*CheckRun
+	if err := s.db.SelectContext(ctx, &runs, query, startedBefore); err != nil {
+		return nil, fmt.Errorf("failed to list in-progress check runs: %w", err)
+	}
+	return runs, nil
+}
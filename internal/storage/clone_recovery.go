@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CloneRecovery is an audit record of a corrupted clone that was replaced
+// with a fresh clone.
+type CloneRecovery struct {
+	ID           int64  `db:"id"`
+	RepoFullName string `db:"repo_full_name"`
+	ClonePath    string `db:"clone_path"`
+	// Reason classifies the corruption (e.g. "missing_objects", "stale_lock").
+	Reason string `db:"reason"`
+	// Error is the git error that triggered the recovery.
+	Error string `db:"error"`
+	// Succeeded reports whether the re-clone succeeded.
+	Succeeded bool      `db:"succeeded"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// CloneRecoveryStore defines persistence operations for clone recoveries.
+// It is a sub-interface implemented by postgresStore.
+type CloneRecoveryStore interface {
+	// RecordCloneRecovery appends an audit record for a clone recovery.
+	RecordCloneRecovery(ctx context.Context, rec *CloneRecovery) error
+	// CountCloneRecoveries returns the number of recoveries since the given time.
+	CountCloneRecoveries(ctx context.Context, since time.Time) (int, error)
+}
+
+// RecordCloneRecovery inserts a clone_recoveries row.
+func (s *postgresStore) RecordCloneRecovery(ctx context.Context, rec *CloneRecovery) error {
+	query := `
+		INSERT INTO clone_recoveries (repo_full_name, clone_path, reason, error, succeeded)
+		VALUES (:repo_full_name, :clone_path, :reason, :error, :succeeded)`
+
+	if _, err := s.db.NamedExecContext(ctx, query, rec); err != nil {
+		return fmt.Errorf("failed to record clone recovery for %s: %w", rec.RepoFullName, err)
+	}
+	return nil
+}
+
+// CountCloneRecoveries counts clone_recoveries rows created since the given time.
+func (s *postgresStore) CountCloneRecoveries(ctx context.Context, since time.Time) (int, error) {
+	var count int
+	if err := s.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM clone_recoveries WHERE created_at >= $1`, since); err != nil {
+		return 0, fmt.Errorf("failed to count clone recoveries: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,244 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// exportBatchSize is the number of points read or written per request.
+const exportBatchSize = 256
+
+// CollectionExporter copies the points of vector collections, vectors and
+// payload, to and from a stream, so a collection can be loaded into another
+// vector database without embedding the documents again.
+type CollectionExporter interface {
+	// Export writes the layout and the points of collection to w and returns
+	// the number of points written.
+	Export(ctx context.Context, collection string, w io.Writer) (int, error)
+	// Import replaces collection with the layout and points read from r, as
+	// written by Export, and returns the number of points read.
+	Import(ctx context.Context, collection string, r io.Reader) (int, error)
+}
+
+// collectionLayout is the first value of an export stream: what Qdrant needs
+// to recreate the collection before the points are added.
+type collectionLayout struct {
+	Vectors       json.RawMessage         `json:"vectors"`
+	SparseVectors json.RawMessage         `json:"sparse_vectors,omitempty"`
+	PayloadSchema map[string]payloadIndex `json:"payload_schema,omitempty"`
+}
+
+// payloadIndex is an indexed payload field of a collection.
+type payloadIndex struct {
+	DataType string          `json:"data_type"`
+	Params   json.RawMessage `json:"params,omitempty"`
+}
+
+// exportedPoint is a point as the Qdrant REST API represents it. The values
+// are kept raw, so named vectors and any id type survive the round trip.
+type exportedPoint struct {
+	ID      json.RawMessage `json:"id"`
+	Vector  json.RawMessage `json:"vector"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// qdrantExporter exports collections with the Qdrant REST API, one JSON
+// value per line: the collection layout, then the points.
+type qdrantExporter struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewQdrantExporter returns a CollectionExporter for the Qdrant REST
+// endpoint at baseURL (e.g. "http://localhost:6333").
+func NewQdrantExporter(baseURL string) CollectionExporter {
+	return &qdrantExporter{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+func (q *qdrantExporter) collectionURL(collection string, parts ...string) string {
+	u := q.baseURL + "/collections/" + url.PathEscape(collection)
+	for _, p := range parts {
+		u += "/" + p
+	}
+	return u
+}
+
+// Export writes the layout of collection and then its points, a page of the
+// scroll API at a time.
+func (q *qdrantExporter) Export(ctx context.Context, collection string, w io.Writer) (int, error) {
+	var info struct {
+		Result struct {
+			Config struct {
+				Params struct {
+					Vectors       json.RawMessage `json:"vectors"`
+					SparseVectors json.RawMessage `json:"sparse_vectors"`
+				} `json:"params"`
+			} `json:"config"`
+			PayloadSchema map[string]payloadIndex `json:"payload_schema"`
+		} `json:"result"`
+	}
+	if err := q.do(ctx, http.MethodGet, q.collectionURL(collection), nil, &info); err != nil {
+		return 0, fmt.Errorf("failed to read collection %s: %w", collection, err)
+	}
+
+	enc := json.NewEncoder(w)
+	layout := collectionLayout{
+		Vectors:       info.Result.Config.Params.Vectors,
+		SparseVectors: info.Result.Config.Params.SparseVectors,
+		PayloadSchema: info.Result.PayloadSchema,
+	}
+	if err := enc.Encode(layout); err != nil {
+		return 0, fmt.Errorf("failed to write collection layout: %w", err)
+	}
+
+	count := 0
+	var offset json.RawMessage
+	for {
+		req := map[string]any{"limit": exportBatchSize, "with_payload": true, "with_vector": true}
+		if offset != nil {
+			req["offset"] = offset
+		}
+		var page struct {
+			Result struct {
+				Points         []exportedPoint `json:"points"`
+				NextPageOffset json.RawMessage `json:"next_page_offset"`
+			} `json:"result"`
+		}
+		if err := q.do(ctx, http.MethodPost, q.collectionURL(collection, "points", "scroll"), req, &page); err != nil {
+			return count, fmt.Errorf("failed to scroll collection %s: %w", collection, err)
+		}
+		for _, p := range page.Result.Points {
+			if err := enc.Encode(p); err != nil {
+				return count, fmt.Errorf("failed to write point: %w", err)
+			}
+			count++
+		}
+
+		next := page.Result.NextPageOffset
+		if len(next) == 0 || bytes.Equal(next, []byte("null")) {
+			return count, nil
+		}
+		offset = next
+	}
+}
+
+// Import drops collection, recreates it with the exported layout and payload
+// indexes, and upserts the points in batches.
+func (q *qdrantExporter) Import(ctx context.Context, collection string, r io.Reader) (int, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var layout collectionLayout
+	if err := dec.Decode(&layout); err != nil {
+		return 0, fmt.Errorf("failed to read collection layout: %w", err)
+	}
+	if len(layout.Vectors) == 0 {
+		return 0, errors.New("export has no vector configuration")
+	}
+
+	if err := q.do(ctx, http.MethodDelete, q.collectionURL(collection), nil, nil); err != nil && !errors.Is(err, errQdrantNotFound) {
+		return 0, fmt.Errorf("failed to drop collection %s: %w", collection, err)
+	}
+	create := map[string]json.RawMessage{"vectors": layout.Vectors}
+	if len(layout.SparseVectors) > 0 && !bytes.Equal(layout.SparseVectors, []byte("null")) {
+		create["sparse_vectors"] = layout.SparseVectors
+	}
+	if err := q.do(ctx, http.MethodPut, q.collectionURL(collection), create, nil); err != nil {
+		return 0, fmt.Errorf("failed to create collection %s: %w", collection, err)
+	}
+	for field, index := range layout.PayloadSchema {
+		schema := index.Params
+		if len(schema) == 0 || bytes.Equal(schema, []byte("null")) {
+			schema, _ = json.Marshal(index.DataType)
+		}
+		body := map[string]any{"field_name": field, "field_schema": schema}
+		if err := q.do(ctx, http.MethodPut, q.collectionURL(collection, "index")+"?wait=true", body, nil); err != nil {
+			return 0, fmt.Errorf("failed to index payload field %s: %w", field, err)
+		}
+	}
+
+	count := 0
+	batch := make([]exportedPoint, 0, exportBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := q.do(ctx, http.MethodPut, q.collectionURL(collection, "points")+"?wait=true", map[string]any{"points": batch}, nil); err != nil {
+			return fmt.Errorf("failed to upsert points into %s: %w", collection, err)
+		}
+		count += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	for {
+		var p exportedPoint
+		err := dec.Decode(&p)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("failed to read point: %w", err)
+		}
+		batch = append(batch, p)
+		if len(batch) == exportBatchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// errQdrantNotFound is returned by do for a 404 response.
+var errQdrantNotFound = errors.New("not found")
+
+// do sends body as JSON and decodes the response into out, if not nil.
+func (q *qdrantExporter) do(ctx context.Context, method, target string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errQdrantNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("qdrant %s %s: status %d: %s", method, target, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode qdrant response: %w", err)
+		}
+	}
+	return nil
+}
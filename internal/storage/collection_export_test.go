@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQdrantExporter_RoundTrip(t *testing.T) {
+	var created map[string]json.RawMessage
+	var indexed []string
+	var upserted []json.RawMessage
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /collections/src", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"result":{"config":{"params":{"vectors":{"size":3,"distance":"Cosine"}}},
+			"payload_schema":{"chunk_type":{"data_type":"keyword","points":2}}}}`)
+	})
+	mux.HandleFunc("POST /collections/src/points/scroll", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["offset"] == nil {
+			_, _ = io.WriteString(w, `{"result":{"points":[{"id":1,"vector":[0.1,0.2,0.3],"payload":{"chunk_type":"code"}}],"next_page_offset":2}}`)
+			return
+		}
+		assert.EqualValues(t, 2, req["offset"])
+		_, _ = io.WriteString(w, `{"result":{"points":[{"id":2,"vector":[0.4,0.5,0.6],"payload":{"chunk_type":"doc"}}],"next_page_offset":null}}`)
+	})
+	mux.HandleFunc("DELETE /collections/dst", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("PUT /collections/dst", func(_ http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&created)
+	})
+	mux.HandleFunc("PUT /collections/dst/index", func(_ http.ResponseWriter, r *http.Request) {
+		var req struct {
+			FieldName   string `json:"field_name"`
+			FieldSchema string `json:"field_schema"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		indexed = append(indexed, req.FieldName+":"+req.FieldSchema)
+	})
+	mux.HandleFunc("PUT /collections/dst/points", func(_ http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Points []json.RawMessage `json:"points"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		upserted = append(upserted, req.Points...)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	e := NewQdrantExporter(srv.URL + "/")
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	n, err := e.Export(ctx, "src", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	n, err = e.Import(ctx, "dst", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.JSONEq(t, `{"size":3,"distance":"Cosine"}`, string(created["vectors"]))
+	assert.NotContains(t, created, "sparse_vectors")
+	assert.Equal(t, []string{"chunk_type:keyword"}, indexed)
+	require.Len(t, upserted, 2)
+	assert.JSONEq(t, `{"id":2,"vector":[0.4,0.5,0.6],"payload":{"chunk_type":"doc"}}`, string(upserted[1]))
+}
+
+func TestQdrantExporter_MissingCollection(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := NewQdrantExporter(srv.URL).Export(context.Background(), "missing", io.Discard)
+	assert.ErrorContains(t, err, "failed to read collection missing")
+}
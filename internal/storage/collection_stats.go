@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// UntypedChunk is the chunk type counted for documents without chunk_type
+// metadata.
+const UntypedChunk = "untyped"
+
+// maxChunkTypes caps the chunk types counted per collection.
+const maxChunkTypes = 100
+
+// CollectionStats describes the documents stored in a vector collection.
+type CollectionStats struct {
+	Documents int `json:"documents"`
+	// ByChunkType counts the documents by their chunk_type metadata. It is
+	// nil for backends that cannot count by metadata.
+	ByChunkType map[string]int `json:"by_chunk_type,omitempty"`
+	// SizeBytes is the storage the collection takes, for backends that
+	// report it.
+	SizeBytes int64 `json:"size_bytes,omitempty"`
+}
+
+// collectionStatsFunc counts the documents of a collection of one backend.
+type collectionStatsFunc func(ctx context.Context, collectionName string) (*CollectionStats, error)
+
+// CollectionStats counts the documents of a collection in the vector
+// database. A collection that does not exist has none.
+func (q *vectorStore) CollectionStats(ctx context.Context, collectionName string) (*CollectionStats, error) {
+	if err := q.validateCollectionName(collectionName); err != nil {
+		return nil, err
+	}
+	if q.stats == nil {
+		return nil, fmt.Errorf("failed to count documents of %s: %w", collectionName, errors.ErrUnsupported)
+	}
+	stats, err := guarded(q.vectorBreaker, func() (*CollectionStats, error) { return q.stats(ctx, collectionName) })
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents of %s: %w", collectionName, err)
+	}
+	return stats, nil
+}
+
+// qdrantRESTURL returns the REST endpoint of the Qdrant server whose gRPC
+// endpoint is host, on the default ports 6333 and 6334.
+func qdrantRESTURL(host string) string {
+	if rest, ok := strings.CutSuffix(host, ":6334"); ok {
+		host = rest + ":6333"
+	}
+	if !strings.HasPrefix(host, "http") {
+		host = "http://" + host
+	}
+	return strings.TrimSuffix(host, "/")
+}
+
+// qdrantStats counts the documents of Qdrant collections with the REST API,
+// which the goframe client does not expose: an exact count, and a facet of
+// chunk_type, which goframe indexes as a keyword.
+type qdrantStats struct {
+	baseURL string
+	http    *http.Client
+}
+
+func newQdrantStats(host string) *qdrantStats {
+	return &qdrantStats{baseURL: qdrantRESTURL(host), http: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (s *qdrantStats) collectionStats(ctx context.Context, collectionName string) (*CollectionStats, error) {
+	path := "/collections/" + url.PathEscape(collectionName)
+
+	var count struct {
+		Result struct {
+			Count int `json:"count"`
+		} `json:"result"`
+	}
+	found, err := s.post(ctx, path+"/points/count", map[string]any{"exact": true}, &count)
+	if err != nil || !found {
+		return &CollectionStats{}, err
+	}
+
+	var facet struct {
+		Result struct {
+			Hits []struct {
+				Value any `json:"value"`
+				Count int `json:"count"`
+			} `json:"hits"`
+		} `json:"result"`
+	}
+	if _, err := s.post(ctx, path+"/facet", map[string]any{"key": "chunk_type", "limit": maxChunkTypes, "exact": true}, &facet); err != nil {
+		return nil, err
+	}
+
+	stats := &CollectionStats{Documents: count.Result.Count, ByChunkType: make(map[string]int)}
+	typed := 0
+	for _, hit := range facet.Result.Hits {
+		stats.ByChunkType[fmt.Sprint(hit.Value)] += hit.Count
+		typed += hit.Count
+	}
+	if untyped := stats.Documents - typed; untyped > 0 {
+		stats.ByChunkType[UntypedChunk] = untyped
+	}
+	return stats, nil
+}
+
+// post sends a JSON request and decodes the response into out. It reports
+// false when the collection does not exist.
+func (s *qdrantStats) post(ctx context.Context, path string, body, out any) (bool, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return false, fmt.Errorf("failed to create qdrant request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to call qdrant %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return false, fmt.Errorf("qdrant returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode qdrant response: %w", err)
+	}
+	return true, nil
+}
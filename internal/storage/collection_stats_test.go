@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQdrantRESTURL(t *testing.T) {
+	assert.Equal(t, "http://qdrant:6333", qdrantRESTURL("qdrant:6334"))
+	assert.Equal(t, "https://q.example.com", qdrantRESTURL("https://q.example.com/"))
+}
+
+func TestQdrantStats(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /collections/repo-a/points/count", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"count":10},"status":"ok"}`))
+	})
+	mux.HandleFunc("POST /collections/repo-a/facet", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		assert.Equal(t, "chunk_type", req["key"])
+		_, _ = w.Write([]byte(`{"result":{"hits":[{"value":"code","count":6},{"value":"toc","count":3}]},"status":"ok"}`))
+	})
+	mux.HandleFunc("POST /collections/repo-missing/points/count", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, `{"status":{"error":"Not found"}}`, http.StatusNotFound)
+	})
+	mux.HandleFunc("POST /collections/repo-broken/points/count", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	stats := newQdrantStats(srv.URL)
+	got, err := stats.collectionStats(context.Background(), "repo-a")
+	require.NoError(t, err)
+	assert.Equal(t, &CollectionStats{Documents: 10, ByChunkType: map[string]int{"code": 6, "toc": 3, UntypedChunk: 1}}, got)
+
+	got, err = stats.collectionStats(context.Background(), "repo-missing")
+	require.NoError(t, err)
+	assert.Zero(t, got.Documents, "a missing collection has no documents")
+
+	_, err = stats.collectionStats(context.Background(), "repo-broken")
+	assert.Error(t, err)
+}
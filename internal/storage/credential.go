@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Credential is a secret stored encrypted; see secrets.Vault. The store never
+// sees the plaintext.
+type Credential struct {
+	Name       string `db:"name"`
+	Ciphertext string `db:"ciphertext"`
+	// ExpiresAt is when the secret stops being valid; nil if it does not
+	// expire.
+	ExpiresAt *time.Time `db:"expires_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+// CredentialStore defines persistence operations for encrypted credentials.
+// It is a sub-interface implemented by postgresStore.
+type CredentialStore interface {
+	// SaveCredential inserts or replaces the credential of the same name.
+	SaveCredential(ctx context.Context, credential *Credential) error
+	// GetCredential returns the credential name, or ErrNotFound.
+	GetCredential(ctx context.Context, name string) (*Credential, error)
+	// ListCredentials returns all credentials, for key rotation.
+	ListCredentials(ctx context.Context) ([]*Credential, error)
+	// DeleteExpiredCredentials removes the credentials that expired before
+	// now and returns how many were removed.
+	DeleteExpiredCredentials(ctx context.Context, now time.Time) (int64, error)
+}
+
+// SaveCredential upserts a credentials row.
+func (s *postgresStore) SaveCredential(ctx context.Context, credential *Credential) error {
+	query := `
+		INSERT INTO credentials (name, ciphertext, expires_at, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (name) DO UPDATE
+		SET ciphertext = EXCLUDED.ciphertext, expires_at = EXCLUDED.expires_at, updated_at = NOW()`
+	if _, err := s.db.ExecContext(ctx, query, credential.Name, credential.Ciphertext, credential.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to save credential %s: %w", credential.Name, err)
+	}
+	return nil
+}
+
+// GetCredential selects a credentials row by name.
+func (s *postgresStore) GetCredential(ctx context.Context, name string) (*Credential, error) {
+	var credential Credential
+	err := s.db.GetContext(ctx, &credential,
+		`SELECT name, ciphertext, expires_at, updated_at FROM credentials WHERE name = $1`, name)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get credential %s: %w", name, err)
+	}
+	return &credential, nil
+}
+
+// ListCredentials selects all credentials rows.
+func (s *postgresStore) ListCredentials(ctx context.Context) ([]*Credential, error) {
+	var credentials []*Credential
+	if err := s.db.SelectContext(ctx, &credentials,
+		`SELECT name, ciphertext, expires_at, updated_at FROM credentials ORDER BY name`,
+	); err != nil {
+		return nil, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	return credentials, nil
+}
+
+// DeleteExpiredCredentials deletes the credentials rows that expired before
+// now.
+func (s *postgresStore) DeleteExpiredCredentials(ctx context.Context, now time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM credentials WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired credentials: %w", err)
+	}
+	return res.RowsAffected()
+}
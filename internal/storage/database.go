@@ -35,10 +35,28 @@ type Repository struct {
 	LastReviewDate       time.Time    `json:"last_review_date" db:"last_review_date"`
 	GeneratedContext     string       `json:"generated_context" db:"generated_context"`
 	ContextUpdatedAt     sql.NullTime `json:"context_updated_at" db:"context_updated_at"`
+	ColdSnapshotKey      string       `json:"cold_snapshot_key" db:"cold_snapshot_key"`
+	ColdSince            sql.NullTime `json:"cold_since" db:"cold_since"`
+	EmbedderModel        string       `json:"embedder_model,omitempty" db:"embedder_model"` // Model the collection was built with; empty means ai.embedder_model
 	CreatedAt            time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time    `json:"updated_at" db:"updated_at"`
 }
 
+// IsCold reports whether the repository's vector collection has been moved
+// to object storage and must be restored before use.
+func (r *Repository) IsCold() bool {
+	return r.ColdSnapshotKey != ""
+}
+
+// Embedder returns the embedder model the repository's collection was built
+// with, or fallback, the configured model, when none is recorded.
+func (r *Repository) Embedder(fallback string) string {
+	if r.EmbedderModel != "" {
+		return r.EmbedderModel
+	}
+	return fallback
+}
+
 // FileRecord represents a tracked file in a repository.
 type FileRecord struct {
 	ID            int64     `db:"id"`
@@ -46,6 +64,8 @@ type FileRecord struct {
 	FilePath      string    `db:"file_path"`
 	FileHash      string    `db:"file_hash"`
 	LastIndexedAt time.Time `db:"last_indexed_at"`
+	// ChunkCount is the number of documents stored for the file.
+	ChunkCount int `db:"chunk_count"`
 }
 
 // ScanState represents the state of a scan process.
@@ -76,6 +96,9 @@ type JobRun struct {
 type ReviewStats struct {
 	TotalReviews    int
 	ReviewsThisWeek int
+	// DegradedThisWeek counts the reviews of the last 7 days written without
+	// repository context because the vector store was unavailable.
+	DegradedThisWeek int
 }
 
 // Store defines the interface for all database operations.
@@ -84,16 +107,50 @@ type ReviewStats struct {
 type Store interface {
 	// Agent session persistence (see agent_session.go).
 	AgentSessionStore
+	// Review feedback persistence (see feedback.go).
+	FeedbackStore
+	// Clone recovery audit trail (see clone_recovery.go).
+	CloneRecoveryStore
+	// Runtime setting overrides and their audit trail (see settings.go).
+	SettingsStore
+	// Check runs created on GitHub (see check_run.go).
+	CheckRunStore
+	// Index coverage of repositories (see index_stats.go).
+	IndexStatsStore
+	// Symbol graph of repositories (see symbol.go).
+	SymbolStore
+	// Prompt inputs of saved reviews (see review_inputs.go).
+	ReviewInputsStore
+	// Vector collections no repository refers to (see orphaned_collection.go).
+	OrphanedCollectionStore
+	// Terminal chat sessions and their messages (see chat.go).
+	ChatStore
+	// Email notification subscriptions (see email_subscription.go).
+	EmailSubscriptionStore
+	// Token usage of LLM calls (see llm_usage.go).
+	LLMUsageStore
+	// Encrypted credentials (see credential.go).
+	CredentialStore
+	// Moves of repositories to another embedder model (see embedding_migration.go).
+	EmbeddingMigrationStore
+	// IDs of accepted webhook deliveries (see webhook_delivery.go).
+	WebhookDeliveryStore
 	SaveReview(ctx context.Context, review *core.Review) error
 	GetLatestReviewForPR(ctx context.Context, repoFullName string, prNumber int) (*core.Review, error)
 	GetAllReviewsForPR(ctx context.Context, repoFullName string, prNumber int) ([]*core.Review, error)
 	GetReviewsForRepo(ctx context.Context, repoFullName string) ([]*core.Review, error)
+	GetRecentReviews(ctx context.Context, limit int) ([]*core.Review, error)
+	GetReviewsSince(ctx context.Context, since time.Time) ([]*core.Review, error)
 	GetReviewStats(ctx context.Context) (*ReviewStats, error)
 	CreateRepository(ctx context.Context, repo *Repository) error
 	GetRepositoryByFullName(ctx context.Context, fullName string) (*Repository, error)
 	GetRepositoryByClonePath(ctx context.Context, clonePath string) (*Repository, error)
 	GetRepositoryByID(ctx context.Context, id int64) (*Repository, error)
 	UpdateRepository(ctx context.Context, repo *Repository) error
+	// DeleteRepository deletes a repository record together with its file,
+	// scan state, index stats, symbol and chat rows. Reviews and job runs
+	// stay.
+	DeleteRepository(ctx context.Context, id int64) error
 
 	GetAllRepositories(ctx context.Context) ([]*Repository, error)
 
@@ -110,6 +167,7 @@ type Store interface {
 	InsertJobRun(ctx context.Context, job *JobRun) (int64, error)
 	UpdateJobRun(ctx context.Context, id int64, status string, completedAt time.Time, durationMs int64) error
 	ListJobRuns(ctx context.Context, limit, offset int) ([]*JobRun, error)
+	ListFailedJobRunsSince(ctx context.Context, since time.Time) ([]*JobRun, error)
 }
 
 type postgresStore struct {
@@ -121,14 +179,16 @@ func NewStore(db *sqlx.DB) Store {
 	return &postgresStore{db: db}
 }
 
-// SaveReview inserts a new review record into the database.
+// SaveReview inserts a new review record into the database and sets its ID
+// and CreatedAt.
 // Returns ErrDuplicateReview if a review already exists for the same repo/PR/SHA combination.
 func (s *postgresStore) SaveReview(ctx context.Context, review *core.Review) error {
 	query := `
-		INSERT INTO reviews (repo_full_name, pr_number, head_sha, review_content)
-		VALUES ($1, $2, $3, $4)`
-	_, err := s.db.ExecContext(ctx, query, review.RepoFullName, review.PRNumber, review.HeadSHA, review.ReviewContent)
-	if err != nil {
+		INSERT INTO reviews (repo_full_name, pr_number, head_sha, review_content, degraded, tree_sha, prompt_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+	row := s.db.QueryRowContext(ctx, query, review.RepoFullName, review.PRNumber, review.HeadSHA, review.ReviewContent, review.Degraded, review.TreeSHA, review.PromptVersion)
+	if err := row.Scan(&review.ID, &review.CreatedAt); err != nil {
 		// Check for PostgreSQL unique constraint violation (error code 23505)
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
@@ -142,7 +202,7 @@ func (s *postgresStore) SaveReview(ctx context.Context, review *core.Review) err
 // GetLatestReviewForPR retrieves the most recent review for a given pull request.
 func (s *postgresStore) GetLatestReviewForPR(ctx context.Context, repoFullName string, prNumber int) (*core.Review, error) {
 	query := `
-		SELECT id, repo_full_name, pr_number, head_sha, review_content, created_at 
+		SELECT id, repo_full_name, pr_number, head_sha, review_content, tree_sha, created_at 
 		FROM reviews 
 		WHERE repo_full_name = $1 AND pr_number = $2 
 		ORDER BY created_at DESC 
@@ -151,7 +211,7 @@ func (s *postgresStore) GetLatestReviewForPR(ctx context.Context, repoFullName s
 	row := s.db.QueryRowContext(ctx, query, repoFullName, prNumber)
 
 	var r core.Review
-	err := row.Scan(&r.ID, &r.RepoFullName, &r.PRNumber, &r.HeadSHA, &r.ReviewContent, &r.CreatedAt)
+	err := row.Scan(&r.ID, &r.RepoFullName, &r.PRNumber, &r.HeadSHA, &r.ReviewContent, &r.TreeSHA, &r.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -164,8 +224,8 @@ func (s *postgresStore) GetLatestReviewForPR(ctx context.Context, repoFullName s
 // CreateRepository inserts a new repository record into the database.
 func (s *postgresStore) CreateRepository(ctx context.Context, repo *Repository) error {
 	query := `
-		INSERT INTO repositories (full_name, clone_path, qdrant_collection_name, last_indexed_sha, generated_context, context_updated_at, installation_id) 
-		VALUES (:full_name, :clone_path, :qdrant_collection_name, :last_indexed_sha, :generated_context, :context_updated_at, :installation_id) 
+		INSERT INTO repositories (full_name, clone_path, qdrant_collection_name, last_indexed_sha, generated_context, context_updated_at, installation_id, embedder_model) 
+		VALUES (:full_name, :clone_path, :qdrant_collection_name, :last_indexed_sha, :generated_context, :context_updated_at, :installation_id, :embedder_model) 
 		RETURNING id, created_at, updated_at`
 	stmt, err := s.db.PrepareNamedContext(ctx, query)
 	if err != nil {
@@ -178,7 +238,7 @@ func (s *postgresStore) CreateRepository(ctx context.Context, repo *Repository)
 // GetRepositoryByFullName retrieves a repository by its full name.
 func (s *postgresStore) GetRepositoryByFullName(ctx context.Context, fullName string) (*Repository, error) {
 	query := `
-SELECT id, full_name, clone_path, qdrant_collection_name, last_indexed_sha, generated_context, context_updated_at, created_at, updated_at, installation_id 
+SELECT id, full_name, clone_path, qdrant_collection_name, last_indexed_sha, generated_context, context_updated_at, cold_snapshot_key, cold_since, embedder_model, created_at, updated_at, installation_id 
 FROM repositories 
 WHERE full_name = $1`
 	var repo Repository
@@ -203,6 +263,9 @@ func (s *postgresStore) UpdateRepository(ctx context.Context, repo *Repository)
 			generated_context = :generated_context,
 			context_updated_at = :context_updated_at,
 			installation_id = :installation_id,
+			cold_snapshot_key = :cold_snapshot_key,
+			cold_since = :cold_since,
+			embedder_model = :embedder_model,
 			updated_at = NOW() 
 		WHERE id = :id`
 
@@ -213,10 +276,23 @@ func (s *postgresStore) UpdateRepository(ctx context.Context, repo *Repository)
 	return nil
 }
 
+// DeleteRepository deletes a repository record, or returns ErrNotFound when
+// there is none. The rows that reference it are deleted by cascade.
+func (s *postgresStore) DeleteRepository(ctx context.Context, id int64) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM repositories WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete repository %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 // GetAllReviewsForPR retrieves all reviews for a specific pull request from the database.
 func (s *postgresStore) GetAllReviewsForPR(ctx context.Context, repoFullName string, prNumber int) ([]*core.Review, error) {
 	query := `
-		SELECT id, repo_full_name, pr_number, head_sha, review_content, created_at 
+		SELECT id, repo_full_name, pr_number, head_sha, review_content, tree_sha, created_at 
 		FROM reviews 
 		WHERE repo_full_name = $1 AND pr_number = $2 
 		ORDER BY created_at ASC`
@@ -232,7 +308,7 @@ func (s *postgresStore) GetAllReviewsForPR(ctx context.Context, repoFullName str
 // GetAllRepositories retrieves all non-deleted repositories from the database.
 func (s *postgresStore) GetAllRepositories(ctx context.Context) ([]*Repository, error) {
 	query := `
-		SELECT id, full_name, clone_path, qdrant_collection_name, last_indexed_sha, generated_context, context_updated_at, created_at, updated_at, installation_id
+		SELECT id, full_name, clone_path, qdrant_collection_name, last_indexed_sha, generated_context, context_updated_at, cold_snapshot_key, cold_since, embedder_model, created_at, updated_at, installation_id
 		FROM repositories
 		ORDER BY full_name ASC`
 
@@ -247,7 +323,7 @@ func (s *postgresStore) GetAllRepositories(ctx context.Context) ([]*Repository,
 // GetRepositoryByClonePath retrieves a repository by its local clone path.
 func (s *postgresStore) GetRepositoryByClonePath(ctx context.Context, clonePath string) (*Repository, error) {
 	query := `
-		SELECT id, full_name, clone_path, qdrant_collection_name, last_indexed_sha, generated_context, context_updated_at, created_at, updated_at, installation_id
+		SELECT id, full_name, clone_path, qdrant_collection_name, last_indexed_sha, generated_context, context_updated_at, cold_snapshot_key, cold_since, embedder_model, created_at, updated_at, installation_id
 		FROM repositories
 		WHERE clone_path = $1`
 
@@ -265,7 +341,7 @@ func (s *postgresStore) GetRepositoryByClonePath(ctx context.Context, clonePath
 // GetRepositoryByID retrieves a repository by its primary key ID.
 func (s *postgresStore) GetRepositoryByID(ctx context.Context, id int64) (*Repository, error) {
 	query := `
-		SELECT id, full_name, clone_path, qdrant_collection_name, last_indexed_sha, generated_context, context_updated_at, created_at, updated_at, installation_id
+		SELECT id, full_name, clone_path, qdrant_collection_name, last_indexed_sha, generated_context, context_updated_at, cold_snapshot_key, cold_since, embedder_model, created_at, updated_at, installation_id
 		FROM repositories
 		WHERE id = $1`
 
@@ -282,7 +358,7 @@ func (s *postgresStore) GetRepositoryByID(ctx context.Context, id int64) (*Repos
 
 // GetFilesForRepo returns a map of file_path -> FileRecord for a repository.
 func (s *postgresStore) GetFilesForRepo(ctx context.Context, repoID int64) (map[string]FileRecord, error) {
-	query := `SELECT id, repository_id, file_path, file_hash, last_indexed_at FROM repository_files WHERE repository_id = $1`
+	query := `SELECT id, repository_id, file_path, file_hash, last_indexed_at, chunk_count FROM repository_files WHERE repository_id = $1`
 	rows, err := s.db.QueryxContext(ctx, query, repoID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list files for repo %d: %w", repoID, err)
@@ -344,10 +420,10 @@ func (s *postgresStore) upsertFilesBatch(ctx context.Context, repoID int64, file
 
 	// Prepare statement for bulk upsert
 	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO repository_files (repository_id, file_path, file_hash, last_indexed_at)
-		VALUES ($1, $2, $3, NOW())
+		INSERT INTO repository_files (repository_id, file_path, file_hash, chunk_count, last_indexed_at)
+		VALUES ($1, $2, $3, $4, NOW())
 		ON CONFLICT (repository_id, file_path) 
-		DO UPDATE SET file_hash = EXCLUDED.file_hash, last_indexed_at = NOW()
+		DO UPDATE SET file_hash = EXCLUDED.file_hash, chunk_count = EXCLUDED.chunk_count, last_indexed_at = NOW()
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare upsert stmt: %w", err)
@@ -355,7 +431,7 @@ func (s *postgresStore) upsertFilesBatch(ctx context.Context, repoID int64, file
 	defer stmt.Close()
 
 	for _, f := range files {
-		if _, err := stmt.ExecContext(ctx, repoID, f.FilePath, f.FileHash); err != nil {
+		if _, err := stmt.ExecContext(ctx, repoID, f.FilePath, f.FileHash, f.ChunkCount); err != nil {
 			return fmt.Errorf("failed to upsert file %s: %w", f.FilePath, err)
 		}
 	}
@@ -387,6 +463,9 @@ func (s *postgresStore) DeleteFiles(ctx context.Context, repoID int64, paths []s
 		if err != nil {
 			return fmt.Errorf("failed to delete files batch for repo %d: %w", repoID, err)
 		}
+		if err := deleteSymbols(ctx, s.db, repoID, batch); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -440,7 +519,7 @@ func (s *postgresStore) UpsertScanState(ctx context.Context, state *ScanState) e
 // GetReviewsForRepo retrieves all reviews for a repository ordered by most recent first.
 func (s *postgresStore) GetReviewsForRepo(ctx context.Context, repoFullName string) ([]*core.Review, error) {
 	query := `
-		SELECT id, repo_full_name, pr_number, head_sha, review_content, created_at
+		SELECT id, repo_full_name, pr_number, head_sha, review_content, tree_sha, prompt_version, created_at
 		FROM reviews
 		WHERE repo_full_name = $1
 		ORDER BY created_at DESC`
@@ -453,17 +532,51 @@ func (s *postgresStore) GetReviewsForRepo(ctx context.Context, repoFullName stri
 	return reviews, nil
 }
 
+// GetRecentReviews retrieves the latest reviews across all repositories,
+// newest first.
+func (s *postgresStore) GetRecentReviews(ctx context.Context, limit int) ([]*core.Review, error) {
+	query := `
+		SELECT id, repo_full_name, pr_number, head_sha, review_content, degraded, tree_sha, prompt_version, created_at
+		FROM reviews
+		ORDER BY created_at DESC
+		LIMIT $1`
+
+	var reviews []*core.Review
+	err := s.db.SelectContext(ctx, &reviews, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+// GetReviewsSince retrieves the reviews saved at or after since, oldest first.
+func (s *postgresStore) GetReviewsSince(ctx context.Context, since time.Time) ([]*core.Review, error) {
+	query := `
+		SELECT id, repo_full_name, pr_number, head_sha, review_content, degraded, tree_sha, prompt_version, created_at
+		FROM reviews
+		WHERE created_at >= $1
+		ORDER BY created_at ASC`
+
+	var reviews []*core.Review
+	err := s.db.SelectContext(ctx, &reviews, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reviews since %s: %w", since.Format(time.RFC3339), err)
+	}
+	return reviews, nil
+}
+
 // GetReviewStats returns aggregate review counts for the global stats endpoint.
 func (s *postgresStore) GetReviewStats(ctx context.Context) (*ReviewStats, error) {
 	query := `
 		SELECT
 			COUNT(*) AS total_reviews,
-			COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days') AS reviews_this_week
+			COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days') AS reviews_this_week,
+			COUNT(*) FILTER (WHERE created_at >= NOW() - INTERVAL '7 days' AND degraded) AS degraded_this_week
 		FROM reviews`
 
 	var stats ReviewStats
 	row := s.db.QueryRowContext(ctx, query)
-	if err := row.Scan(&stats.TotalReviews, &stats.ReviewsThisWeek); err != nil {
+	if err := row.Scan(&stats.TotalReviews, &stats.ReviewsThisWeek, &stats.DegradedThisWeek); err != nil {
 		return nil, fmt.Errorf("failed to get review stats: %w", err)
 	}
 	return &stats, nil
@@ -515,3 +628,20 @@ func (s *postgresStore) ListJobRuns(ctx context.Context, limit, offset int) ([]*
 	}
 	return jobs, nil
 }
+
+// ListFailedJobRunsSince retrieves the job runs that failed or timed out and
+// were triggered at or after since, oldest first.
+func (s *postgresStore) ListFailedJobRunsSince(ctx context.Context, since time.Time) ([]*JobRun, error) {
+	query := `
+		SELECT id, type, repo_full_name, pr_number, status, triggered_by, triggered_at, completed_at, duration_ms
+		FROM job_runs
+		WHERE status IN ('failed', 'timed_out') AND triggered_at >= $1
+		ORDER BY triggered_at ASC`
+
+	var jobs []*JobRun
+	err := s.db.SelectContext(ctx, &jobs, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list failed job runs: %w", err)
+	}
+	return jobs, nil
+}
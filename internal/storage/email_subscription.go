@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// EmailSubscription is the notification settings of an email address for a
+// repository, or for all repositories when RepoFullName is empty.
+type EmailSubscription struct {
+	ID           int64  `db:"id"`
+	Email        string `db:"email"`
+	RepoFullName string `db:"repo_full_name"`
+	// PerReview sends a message for every completed review.
+	PerReview bool `db:"per_review"`
+	// Digest includes the repository in the daily digest.
+	Digest bool `db:"digest"`
+	// LastDigestAt is when the last digest was sent to the address, or nil.
+	LastDigestAt *time.Time `db:"last_digest_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+// EmailSubscriptionStore defines persistence operations for email
+// notification subscriptions. It is a sub-interface implemented by
+// postgresStore.
+type EmailSubscriptionStore interface {
+	// UpsertEmailSubscription creates the subscription of an address for a
+	// repository or replaces its settings, and sets its ID.
+	UpsertEmailSubscription(ctx context.Context, sub *EmailSubscription) error
+	// DeleteEmailSubscription removes the subscription of an address for a
+	// repository, or returns ErrNotFound.
+	DeleteEmailSubscription(ctx context.Context, email, repoFullName string) error
+	// ListEmailSubscriptions returns all subscriptions, by address and
+	// repository.
+	ListEmailSubscriptions(ctx context.Context) ([]*EmailSubscription, error)
+	// MarkDigestSent records that a digest was sent for the subscriptions.
+	MarkDigestSent(ctx context.Context, ids []int64, at time.Time) error
+}
+
+// UpsertEmailSubscription inserts or updates an email_subscriptions row.
+func (s *postgresStore) UpsertEmailSubscription(ctx context.Context, sub *EmailSubscription) error {
+	query := `
+		INSERT INTO email_subscriptions (email, repo_full_name, per_review, digest)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (email, repo_full_name) DO UPDATE
+		SET per_review = EXCLUDED.per_review, digest = EXCLUDED.digest
+		RETURNING id, last_digest_at, created_at`
+	row := s.db.QueryRowContext(ctx, query, sub.Email, sub.RepoFullName, sub.PerReview, sub.Digest)
+	if err := row.Scan(&sub.ID, &sub.LastDigestAt, &sub.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save email subscription: %w", err)
+	}
+	return nil
+}
+
+// DeleteEmailSubscription deletes an email_subscriptions row.
+func (s *postgresStore) DeleteEmailSubscription(ctx context.Context, email, repoFullName string) error {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM email_subscriptions WHERE email = $1 AND repo_full_name = $2`, email, repoFullName)
+	if err != nil {
+		return fmt.Errorf("failed to delete email subscription: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListEmailSubscriptions selects all email_subscriptions rows.
+func (s *postgresStore) ListEmailSubscriptions(ctx context.Context) ([]*EmailSubscription, error) {
+	query := `
+		SELECT id, email, repo_full_name, per_review, digest, last_digest_at, created_at
+		FROM email_subscriptions
+		ORDER BY email, repo_full_name`
+	var subs []*EmailSubscription
+	if err := s.db.SelectContext(ctx, &subs, query); err != nil {
+		return nil, fmt.Errorf("failed to list email subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// MarkDigestSent sets last_digest_at of email_subscriptions rows.
+func (s *postgresStore) MarkDigestSent(ctx context.Context, ids []int64, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, `UPDATE email_subscriptions SET last_digest_at = $1 WHERE id = ANY($2)`, at, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to mark digest sent: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Statuses of an EmbeddingMigration, in the order it passes them.
+const (
+	// MigrationBuilding means the target collection is being indexed.
+	MigrationBuilding = "building"
+	// MigrationBuilt means the target collection is complete and awaits
+	// verification.
+	MigrationBuilt = "built"
+	// MigrationFlipped means the repository uses the target collection and
+	// the source collection is yet to be deleted.
+	MigrationFlipped = "flipped"
+	// MigrationDone means the source collection is gone.
+	MigrationDone = "done"
+)
+
+// ErrMigrationConflict is returned by FlipEmbeddingMigration when the
+// repository no longer uses the source collection of the migration.
+var ErrMigrationConflict = errors.New("repository collection changed during the migration")
+
+// EmbeddingMigration records the move of a repository's vector collection to
+// another embedder model. The new collection is built next to the old one,
+// so a migration that stops can resume from its status.
+type EmbeddingMigration struct {
+	RepositoryID     int64     `json:"repository_id" db:"repository_id"`
+	SourceCollection string    `json:"source_collection" db:"source_collection"`
+	SourceModel      string    `json:"source_model" db:"source_model"`
+	TargetCollection string    `json:"target_collection" db:"target_collection"`
+	TargetModel      string    `json:"target_model" db:"target_model"`
+	Status           string    `json:"status" db:"status"`
+	Files            int       `json:"files" db:"files"`   // Files indexed into the target collection
+	Chunks           int       `json:"chunks" db:"chunks"` // Documents stored in the target collection
+	Error            string    `json:"error,omitempty" db:"error"`
+	StartedAt        time.Time `json:"started_at" db:"started_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// EmbeddingMigrationStore defines persistence operations for embedder model
+// migrations. It is a sub-interface implemented by postgresStore.
+type EmbeddingMigrationStore interface {
+	// GetEmbeddingMigration returns the latest migration of a repository,
+	// or ErrNotFound.
+	GetEmbeddingMigration(ctx context.Context, repoID int64) (*EmbeddingMigration, error)
+	// ListEmbeddingMigrations returns the migrations that are not done.
+	ListEmbeddingMigrations(ctx context.Context) ([]*EmbeddingMigration, error)
+	// SaveEmbeddingMigration inserts or replaces the migration of a
+	// repository.
+	SaveEmbeddingMigration(ctx context.Context, m *EmbeddingMigration) error
+	// FlipEmbeddingMigration points the repository at the target collection
+	// and model and marks the migration flipped, in one transaction. It
+	// returns ErrMigrationConflict when the repository no longer uses the
+	// source collection.
+	FlipEmbeddingMigration(ctx context.Context, m *EmbeddingMigration) error
+}
+
+const embeddingMigrationColumns = `repository_id, source_collection, source_model, target_collection, target_model,
+	status, files, chunks, error, started_at, updated_at`
+
+// GetEmbeddingMigration selects the embedding_migrations row of a repository.
+func (s *postgresStore) GetEmbeddingMigration(ctx context.Context, repoID int64) (*EmbeddingMigration, error) {
+	var m EmbeddingMigration
+	err := s.db.GetContext(ctx, &m, `SELECT `+embeddingMigrationColumns+` FROM embedding_migrations WHERE repository_id = $1`, repoID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get embedding migration of repository %d: %w", repoID, err)
+	}
+	return &m, nil
+}
+
+// ListEmbeddingMigrations selects the embedding_migrations rows not done.
+func (s *postgresStore) ListEmbeddingMigrations(ctx context.Context) ([]*EmbeddingMigration, error) {
+	var migrations []*EmbeddingMigration
+	query := `SELECT ` + embeddingMigrationColumns + ` FROM embedding_migrations WHERE status <> $1 ORDER BY started_at`
+	if err := s.db.SelectContext(ctx, &migrations, query, MigrationDone); err != nil {
+		return nil, fmt.Errorf("failed to list embedding migrations: %w", err)
+	}
+	return migrations, nil
+}
+
+// SaveEmbeddingMigration upserts an embedding_migrations row.
+func (s *postgresStore) SaveEmbeddingMigration(ctx context.Context, m *EmbeddingMigration) error {
+	query := `
+		INSERT INTO embedding_migrations (repository_id, source_collection, source_model, target_collection, target_model, status, files, chunks, error)
+		VALUES (:repository_id, :source_collection, :source_model, :target_collection, :target_model, :status, :files, :chunks, :error)
+		ON CONFLICT (repository_id) DO UPDATE SET
+			source_collection = EXCLUDED.source_collection,
+			source_model      = EXCLUDED.source_model,
+			target_collection = EXCLUDED.target_collection,
+			target_model      = EXCLUDED.target_model,
+			status            = EXCLUDED.status,
+			files             = EXCLUDED.files,
+			chunks            = EXCLUDED.chunks,
+			error             = EXCLUDED.error,
+			started_at        = CASE WHEN embedding_migrations.status = 'done' THEN NOW() ELSE embedding_migrations.started_at END,
+			updated_at        = NOW()`
+	if _, err := s.db.NamedExecContext(ctx, query, m); err != nil {
+		return fmt.Errorf("failed to save embedding migration of repository %d: %w", m.RepositoryID, err)
+	}
+	return nil
+}
+
+// FlipEmbeddingMigration updates the repositories and embedding_migrations
+// rows of a migration in a single transaction.
+func (s *postgresStore) FlipEmbeddingMigration(ctx context.Context, m *EmbeddingMigration) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.ErrorContext(ctx, "transaction rollback failed in FlipEmbeddingMigration", "error", err)
+		}
+	}()
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE repositories SET qdrant_collection_name = $1, embedder_model = $2, updated_at = NOW()
+		WHERE id = $3 AND qdrant_collection_name = $4`,
+		m.TargetCollection, m.TargetModel, m.RepositoryID, m.SourceCollection)
+	if err != nil {
+		return fmt.Errorf("failed to point repository %d at %s: %w", m.RepositoryID, m.TargetCollection, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrMigrationConflict
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE embedding_migrations SET status = $1, updated_at = NOW() WHERE repository_id = $2`,
+		MigrationFlipped, m.RepositoryID,
+	); err != nil {
+		return fmt.Errorf("failed to mark embedding migration of repository %d flipped: %w", m.RepositoryID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit flip of repository %d: %w", m.RepositoryID, err)
+	}
+	m.Status = MigrationFlipped
+	return nil
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Feedback sources recorded in review_feedback.source.
+const (
+	FeedbackSourceReaction = "reaction"
+	FeedbackSourceReply    = "reply"
+)
+
+// ReviewFeedback is a single helpful/wrong signal from a user on an inline
+// review suggestion, tagged with the model and prompt version that produced it.
+type ReviewFeedback struct {
+	ID            int64     `db:"id"`
+	RepoFullName  string    `db:"repo_full_name"`
+	PRNumber      int       `db:"pr_number"`
+	CommentID     int64     `db:"comment_id"`
+	FilePath      string    `db:"file_path"`
+	Line          int       `db:"line"`
+	Severity      string    `db:"severity"`
+	Category      string    `db:"category"`
+	Model         string    `db:"model"`
+	PromptVersion string    `db:"prompt_version"`
+	UserLogin     string    `db:"user_login"`
+	Source        string    `db:"source"`
+	Signal        string    `db:"signal"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// FeedbackMetric aggregates feedback for one model and prompt version.
+type FeedbackMetric struct {
+	Model         string `db:"model"`
+	PromptVersion string `db:"prompt_version"`
+	Suggestions   int    `db:"suggestions"`
+	Helpful       int    `db:"helpful"`
+	Wrong         int    `db:"wrong"`
+	// AcceptanceRate is Helpful / (Helpful + Wrong), or 0 without votes.
+	AcceptanceRate float64 `db:"-"`
+}
+
+// FeedbackStore defines persistence operations for review feedback.
+// It is a sub-interface implemented by postgresStore.
+type FeedbackStore interface {
+	// UpsertReviewFeedback records a signal, replacing any earlier signal
+	// from the same user and source on the same comment.
+	UpsertReviewFeedback(ctx context.Context, fb *ReviewFeedback) error
+	// ReplaceReactionFeedback replaces all reaction-sourced feedback for a
+	// comment with rows, so removed reactions are forgotten.
+	ReplaceReactionFeedback(ctx context.Context, commentID int64, rows []*ReviewFeedback) error
+	// GetFeedbackMetrics returns acceptance metrics grouped by model and
+	// prompt version. An empty repoFullName aggregates across all repositories.
+	GetFeedbackMetrics(ctx context.Context, repoFullName string) ([]*FeedbackMetric, error)
+}
+
+const upsertReviewFeedbackQuery = `
+INSERT INTO review_feedback
+  (repo_full_name, pr_number, comment_id, file_path, line, severity, category,
+   model, prompt_version, user_login, source, signal)
+VALUES
+  (:repo_full_name, :pr_number, :comment_id, :file_path, :line, :severity, :category,
+   :model, :prompt_version, :user_login, :source, :signal)
+ON CONFLICT (comment_id, user_login, source)
+DO UPDATE SET signal = EXCLUDED.signal, created_at = NOW()`
+
+// UpsertReviewFeedback inserts or updates a review_feedback row.
+func (s *postgresStore) UpsertReviewFeedback(ctx context.Context, fb *ReviewFeedback) error {
+	if _, err := s.db.NamedExecContext(ctx, upsertReviewFeedbackQuery, fb); err != nil {
+		return fmt.Errorf("failed to upsert review feedback for comment %d: %w", fb.CommentID, err)
+	}
+	return nil
+}
+
+// ReplaceReactionFeedback deletes the reaction rows of a comment and inserts
+// rows in a single transaction.
+func (s *postgresStore) ReplaceReactionFeedback(ctx context.Context, commentID int64, rows []*ReviewFeedback) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.ErrorContext(ctx, "transaction rollback failed in ReplaceReactionFeedback", "error", err)
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM review_feedback WHERE comment_id = $1 AND source = $2`,
+		commentID, FeedbackSourceReaction,
+	); err != nil {
+		return fmt.Errorf("failed to delete reaction feedback for comment %d: %w", commentID, err)
+	}
+
+	for _, fb := range rows {
+		if _, err := tx.NamedExecContext(ctx, upsertReviewFeedbackQuery, fb); err != nil {
+			return fmt.Errorf("failed to insert reaction feedback for comment %d: %w", commentID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetFeedbackMetrics aggregates review_feedback by model and prompt version.
+func (s *postgresStore) GetFeedbackMetrics(ctx context.Context, repoFullName string) ([]*FeedbackMetric, error) {
+	query := `
+		SELECT model, prompt_version,
+			COUNT(DISTINCT comment_id) AS suggestions,
+			COUNT(*) FILTER (WHERE signal = 'helpful') AS helpful,
+			COUNT(*) FILTER (WHERE signal = 'wrong') AS wrong
+		FROM review_feedback
+		WHERE $1 = '' OR repo_full_name = $1
+		GROUP BY model, prompt_version
+		ORDER BY model, prompt_version`
+
+	var metrics []*FeedbackMetric
+	if err := s.db.SelectContext(ctx, &metrics, query, repoFullName); err != nil {
+		return nil, fmt.Errorf("failed to get feedback metrics: %w", err)
+	}
+	for _, m := range metrics {
+		if votes := m.Helpful + m.Wrong; votes > 0 {
+			m.AcceptanceRate = float64(m.Helpful) / float64(votes)
+		}
+	}
+	return metrics, nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// IndexStatsStore defines persistence operations for the index coverage of
+// repositories. It is a sub-interface implemented by postgresStore.
+type IndexStatsStore interface {
+	// SaveIndexStats replaces the stats of a repository with those of its
+	// latest full index.
+	SaveIndexStats(ctx context.Context, repoID int64, stats *core.IndexStats) error
+	// GetIndexStats returns the stats of a repository, or ErrNotFound when it
+	// has not been fully indexed since they were introduced.
+	GetIndexStats(ctx context.Context, repoID int64) (*core.IndexStats, error)
+}
+
+// SaveIndexStats upserts the index_stats row of a repository.
+func (s *postgresStore) SaveIndexStats(ctx context.Context, repoID int64, stats *core.IndexStats) error {
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return fmt.Errorf("failed to marshal index stats: %w", err)
+	}
+	query := `
+		INSERT INTO index_stats (repository_id, stats, computed_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (repository_id)
+		DO UPDATE SET stats = EXCLUDED.stats, computed_at = EXCLUDED.computed_at`
+
+	if _, err := s.db.ExecContext(ctx, query, repoID, data, stats.ComputedAt); err != nil {
+		return fmt.Errorf("failed to save index stats for repository %d: %w", repoID, err)
+	}
+	return nil
+}
+
+// GetIndexStats reads the index_stats row of a repository.
+func (s *postgresStore) GetIndexStats(ctx context.Context, repoID int64) (*core.IndexStats, error) {
+	var data []byte
+	err := s.db.GetContext(ctx, &data, `SELECT stats FROM index_stats WHERE repository_id = $1`, repoID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get index stats for repository %d: %w", repoID, err)
+	}
+	var stats core.IndexStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal index stats: %w", err)
+	}
+	return &stats, nil
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// LLMUsageFilter selects the usage GetLLMUsage aggregates.
+type LLMUsageFilter struct {
+	// RepoFullName limits the usage to one repository; empty for all.
+	RepoFullName string
+	// Since is the first day to include.
+	Since time.Time
+	// ByStep splits the totals by review step.
+	ByStep bool
+}
+
+// LLMUsageTotal is the token usage of a repository and model on one day.
+type LLMUsageTotal struct {
+	Day          time.Time `db:"day"`
+	RepoFullName string    `db:"repo_full_name"`
+	Model        string    `db:"model"`
+	// Step is empty unless the totals are split by step.
+	Step             string `db:"step"`
+	Calls            int64  `db:"calls"`
+	PromptTokens     int64  `db:"prompt_tokens"`
+	CompletionTokens int64  `db:"completion_tokens"`
+	// EstimatedCalls counts the calls whose tokens were estimated because
+	// the provider reported none.
+	EstimatedCalls int64 `db:"estimated_calls"`
+}
+
+// LLMUsageStore defines persistence operations for LLM token usage.
+// It is a sub-interface implemented by postgresStore.
+type LLMUsageStore interface {
+	// SaveLLMUsage records the token usage of one LLM call.
+	SaveLLMUsage(ctx context.Context, usage *core.LLMUsage) error
+	// GetLLMUsage returns the usage per day, repository and model, newest
+	// day first.
+	GetLLMUsage(ctx context.Context, filter LLMUsageFilter) ([]*LLMUsageTotal, error)
+}
+
+// SaveLLMUsage inserts an llm_usage row.
+func (s *postgresStore) SaveLLMUsage(ctx context.Context, usage *core.LLMUsage) error {
+	query := `
+		INSERT INTO llm_usage (repo_full_name, step, model, prompt_tokens, completion_tokens, estimated)
+		VALUES (:repo_full_name, :step, :model, :prompt_tokens, :completion_tokens, :estimated)`
+	if _, err := s.db.NamedExecContext(ctx, query, usage); err != nil {
+		return fmt.Errorf("failed to save llm usage of model %s: %w", usage.Model, err)
+	}
+	return nil
+}
+
+// GetLLMUsage aggregates llm_usage by day (UTC), repository and model, and
+// by step if the filter asks for it.
+func (s *postgresStore) GetLLMUsage(ctx context.Context, filter LLMUsageFilter) ([]*LLMUsageTotal, error) {
+	query := `
+		SELECT date_trunc('day', created_at AT TIME ZONE 'UTC') AS day,
+			repo_full_name, model,
+			CASE WHEN $3 THEN step ELSE '' END AS step,
+			COUNT(*) AS calls,
+			COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens,
+			COALESCE(SUM(completion_tokens), 0) AS completion_tokens,
+			COUNT(*) FILTER (WHERE estimated) AS estimated_calls
+		FROM llm_usage
+		WHERE ($1 = '' OR repo_full_name = $1) AND created_at >= $2
+		GROUP BY 1, 2, 3, 4
+		ORDER BY day DESC, repo_full_name, model, step`
+
+	var totals []*LLMUsageTotal
+	if err := s.db.SelectContext(ctx, &totals, query, filter.RepoFullName, filter.Since, filter.ByStep); err != nil {
+		return nil, fmt.Errorf("failed to get llm usage: %w", err)
+	}
+	return totals, nil
+}
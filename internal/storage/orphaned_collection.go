@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// OrphanedCollection records a vector collection that no repository refers
+// to, so that it is deleted only after it stayed orphaned for a while.
+type OrphanedCollection struct {
+	Name        string    `db:"name"`
+	FirstSeenAt time.Time `db:"first_seen_at"`
+}
+
+// OrphanedCollectionStore defines persistence operations for orphaned vector
+// collections. It is a sub-interface implemented by postgresStore.
+type OrphanedCollectionStore interface {
+	// ListOrphanedCollections returns the recorded orphans, by name.
+	ListOrphanedCollections(ctx context.Context) ([]*OrphanedCollection, error)
+	// MarkOrphanedCollections records collections as orphaned. Collections
+	// already recorded keep the time they were first seen.
+	MarkOrphanedCollections(ctx context.Context, names []string) error
+	// UnmarkOrphanedCollections forgets collections that were deleted or
+	// are referred to again.
+	UnmarkOrphanedCollections(ctx context.Context, names []string) error
+}
+
+// ListOrphanedCollections selects all orphaned_collections rows.
+func (s *postgresStore) ListOrphanedCollections(ctx context.Context) ([]*OrphanedCollection, error) {
+	var orphans []*OrphanedCollection
+	if err := s.db.SelectContext(ctx, &orphans, `SELECT name, first_seen_at FROM orphaned_collections ORDER BY name`); err != nil {
+		return nil, fmt.Errorf("failed to list orphaned collections: %w", err)
+	}
+	return orphans, nil
+}
+
+// MarkOrphanedCollections inserts orphaned_collections rows.
+func (s *postgresStore) MarkOrphanedCollections(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	query := `
+		INSERT INTO orphaned_collections (name)
+		SELECT unnest($1::text[])
+		ON CONFLICT (name) DO NOTHING`
+	if _, err := s.db.ExecContext(ctx, query, pq.Array(names)); err != nil {
+		return fmt.Errorf("failed to mark orphaned collections: %w", err)
+	}
+	return nil
+}
+
+// UnmarkOrphanedCollections deletes orphaned_collections rows.
+func (s *postgresStore) UnmarkOrphanedCollections(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM orphaned_collections WHERE name = ANY($1)`, pq.Array(names)); err != nil {
+		return fmt.Errorf("failed to unmark orphaned collections: %w", err)
+	}
+	return nil
+}
@@ -1,93 +1,190 @@
 package storage
 
 import (
+	"container/list"
 	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
 )
 
+// QueryCacheStats reports the effectiveness of the similarity search cache.
+type QueryCacheStats struct {
+	Hits      int64   `json:"hits"`
+	Misses    int64   `json:"misses"`
+	Evictions int64   `json:"evictions"`
+	Entries   int     `json:"entries"`
+	HitRate   float64 `json:"hit_rate"`
+}
+
+// QueryCacheReporter is implemented by vector stores that cache similarity
+// searches.
+type QueryCacheReporter interface {
+	QueryCacheStats() QueryCacheStats
+}
+
+// QueryCacheInvalidator is implemented by vector stores that cache similarity
+// searches, so that the server can drop the results of a collection another
+// process replaced, such as warden-cli index import.
+type QueryCacheInvalidator interface {
+	InvalidateQueryCache(collection string)
+}
+
 type cacheEntry struct {
+	key       string
 	docs      []schema.Document
 	expiresAt time.Time
 }
 
+// queryCache is an LRU cache of similarity search results that expire after
+// ttl, keyed by collection, embedder, query, result count and search
+// options. A nil *queryCache caches nothing.
 type queryCache struct {
 	mu      sync.RWMutex
-	entries map[string]cacheEntry
+	entries map[string]*list.Element
+	lru     *list.List // of *cacheEntry, most recently used first
 	ttl     time.Duration
 	maxSize int
+
+	hits, misses, evictions int64
 }
 
+// newQueryCache returns a cache of up to maxSize results, or nil when
+// maxSize or ttl is not positive.
 func newQueryCache(ttl time.Duration, maxSize int) *queryCache {
+	if ttl <= 0 || maxSize <= 0 {
+		return nil
+	}
 	return &queryCache{
-		entries: make(map[string]cacheEntry, maxSize),
+		entries: make(map[string]*list.Element, maxSize),
+		lru:     list.New(),
 		ttl:     ttl,
 		maxSize: maxSize,
 	}
 }
 
-func (c *queryCache) key(collection, query string, numDocs int) string {
-	h := sha256.Sum256([]byte(query))
-	return fmt.Sprintf("%s|%x|%d", collection, h[:8], numDocs)
+// key identifies a search, and reports false for searches that cannot be
+// cached: those with an embedder override or options that do not encode.
+func (c *queryCache) key(collection, embedder, query string, numDocs int, opts []vectorstores.Option) (string, bool) {
+	parsed := vectorstores.ParseOptions(opts...)
+	if parsed.Embedder != nil {
+		return "", false
+	}
+	options, err := json.Marshal(struct {
+		NameSpace      string               `json:"ns,omitempty"`
+		ScoreThreshold float32              `json:"threshold,omitempty"`
+		Filters        map[string]any       `json:"filters,omitempty"`
+		SparseQuery    *schema.SparseVector `json:"sparse,omitempty"`
+	}{parsed.NameSpace, parsed.ScoreThreshold, parsed.Filters, parsed.SparseQuery})
+	if err != nil {
+		return "", false
+	}
+	h := sha256.New()
+	h.Write([]byte(embedder))
+	h.Write([]byte{0})
+	h.Write([]byte(query))
+	h.Write([]byte{0})
+	h.Write(options)
+	return fmt.Sprintf("%s|%x|%d", collection, h.Sum(nil)[:12], numDocs), true
 }
 
-func (c *queryCache) get(collection, query string, numDocs int) ([]schema.Document, bool) {
-	k := c.key(collection, query, numDocs)
-	c.mu.RLock()
-	entry, ok := c.entries[k]
-	c.mu.RUnlock()
-	if !ok || time.Now().After(entry.expiresAt) {
+func (c *queryCache) get(collection, embedder, query string, numDocs int, opts []vectorstores.Option) ([]schema.Document, bool) {
+	if c == nil {
+		return nil, false
+	}
+	k, ok := c.key(collection, embedder, query, numDocs, opts)
+	if !ok {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[k]
+	if !ok {
+		c.misses++
 		return nil, false
 	}
+	entry := el.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.lru.Remove(el)
+		delete(c.entries, k)
+		c.misses++
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	c.hits++
 	out := make([]schema.Document, len(entry.docs))
 	copy(out, entry.docs)
 	return out, true
 }
 
-func (c *queryCache) set(collection, query string, numDocs int, docs []schema.Document) {
-	if len(docs) == 0 {
+func (c *queryCache) set(collection, embedder, query string, numDocs int, opts []vectorstores.Option, docs []schema.Document) {
+	if c == nil || len(docs) == 0 {
 		return
 	}
-	k := c.key(collection, query, numDocs)
-	now := time.Now()
+	k, ok := c.key(collection, embedder, query, numDocs, opts)
+	if !ok {
+		return
+	}
+	cp := make([]schema.Document, len(docs))
+	copy(cp, docs)
+	entry := &cacheEntry{key: k, docs: cp, expiresAt: time.Now().Add(c.ttl)}
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
-
-	if _, exists := c.entries[k]; !exists && len(c.entries) >= c.maxSize {
-		var oldestKey string
-		oldestAt := time.Time{}
-		for ek, e := range c.entries {
-			if e.expiresAt.Before(oldestAt) || oldestAt.IsZero() {
-				oldestKey = ek
-				oldestAt = e.expiresAt
-			}
-		}
-		delete(c.entries, oldestKey)
+	if el, exists := c.entries[k]; exists {
+		el.Value = entry
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[k] = c.lru.PushFront(entry)
+	for c.lru.Len() > c.maxSize {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+		c.evictions++
 	}
-
-	cp := make([]schema.Document, len(docs))
-	copy(cp, docs)
-	c.entries[k] = cacheEntry{docs: cp, expiresAt: now.Add(c.ttl)}
 }
 
+// invalidate drops the results of collection, whose documents changed.
 func (c *queryCache) invalidate(collection string) {
+	if c == nil {
+		return
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	prefix := collection + "|"
-	for k := range c.entries {
+	for k, el := range c.entries {
 		if strings.HasPrefix(k, prefix) {
+			c.lru.Remove(el)
 			delete(c.entries, k)
 		}
 	}
 }
 
 func (c *queryCache) clear() {
+	if c == nil {
+		return
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.entries = make(map[string]cacheEntry, c.maxSize)
+	c.entries = make(map[string]*list.Element, c.maxSize)
+	c.lru.Init()
+}
+
+func (c *queryCache) stats() QueryCacheStats {
+	if c == nil {
+		return QueryCacheStats{}
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	s := QueryCacheStats{Hits: c.hits, Misses: c.misses, Evictions: c.evictions, Entries: len(c.entries)}
+	if total := c.hits + c.misses; total > 0 {
+		s.HitRate = float64(c.hits) / float64(total)
+	}
+	return s
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
 )
 
 func TestQueryCacheSetGet(t *testing.T) {
@@ -14,9 +15,9 @@ func TestQueryCacheSetGet(t *testing.T) {
 		{PageContent: "world"},
 	}
 
-	c.set("col1", "query1", 5, docs)
+	c.set("col1", "", "query1", 5, nil, docs)
 
-	got, ok := c.get("col1", "query1", 5)
+	got, ok := c.get("col1", "", "query1", 5, nil)
 	if !ok {
 		t.Fatal("expected cache hit")
 	}
@@ -31,7 +32,7 @@ func TestQueryCacheSetGet(t *testing.T) {
 func TestQueryCacheMiss(t *testing.T) {
 	c := newQueryCache(5*time.Minute, 100)
 
-	_, ok := c.get("col1", "nonexistent", 5)
+	_, ok := c.get("col1", "", "nonexistent", 5, nil)
 	if ok {
 		t.Fatal("expected cache miss")
 	}
@@ -41,10 +42,10 @@ func TestQueryCacheExpiration(t *testing.T) {
 	c := newQueryCache(1*time.Nanosecond, 100)
 	docs := []schema.Document{{PageContent: "stale"}}
 
-	c.set("col1", "q", 1, docs)
+	c.set("col1", "", "q", 1, nil, docs)
 	time.Sleep(10 * time.Millisecond)
 
-	_, ok := c.get("col1", "q", 1)
+	_, ok := c.get("col1", "", "q", 1, nil)
 	if ok {
 		t.Fatal("expected expired entry to miss")
 	}
@@ -54,9 +55,9 @@ func TestQueryCacheDifferentCollection(t *testing.T) {
 	c := newQueryCache(5*time.Minute, 100)
 	docs := []schema.Document{{PageContent: "data"}}
 
-	c.set("col1", "query", 5, docs)
+	c.set("col1", "", "query", 5, nil, docs)
 
-	_, ok := c.get("col2", "query", 5)
+	_, ok := c.get("col2", "", "query", 5, nil)
 	if ok {
 		t.Fatal("expected miss for different collection")
 	}
@@ -67,12 +68,12 @@ func TestQueryCacheIsolation(t *testing.T) {
 	docs := []schema.Document{{PageContent: "original"}}
 	mutated := []schema.Document{{PageContent: "changed"}}
 
-	c.set("col1", "q", 1, docs)
+	c.set("col1", "", "q", 1, nil, docs)
 
-	got, _ := c.get("col1", "q", 1)
+	got, _ := c.get("col1", "", "q", 1, nil)
 	got[0].PageContent = "changed"
 
-	got2, _ := c.get("col1", "q", 1)
+	got2, _ := c.get("col1", "", "q", 1, nil)
 	if got2[0].PageContent == "changed" {
 		t.Fatal("cache entry mutated through returned slice")
 	}
@@ -81,49 +82,49 @@ func TestQueryCacheIsolation(t *testing.T) {
 
 func TestQueryCacheInvalidate(t *testing.T) {
 	c := newQueryCache(5*time.Minute, 100)
-	c.set("col1", "q1", 5, []schema.Document{{PageContent: "a"}})
-	c.set("col1", "q2", 5, []schema.Document{{PageContent: "b"}})
-	c.set("col2", "q1", 5, []schema.Document{{PageContent: "c"}})
+	c.set("col1", "", "q1", 5, nil, []schema.Document{{PageContent: "a"}})
+	c.set("col1", "", "q2", 5, nil, []schema.Document{{PageContent: "b"}})
+	c.set("col2", "", "q1", 5, nil, []schema.Document{{PageContent: "c"}})
 
 	c.invalidate("col1")
 
-	if _, ok := c.get("col1", "q1", 5); ok {
+	if _, ok := c.get("col1", "", "q1", 5, nil); ok {
 		t.Fatal("col1/q1 should be invalidated")
 	}
-	if _, ok := c.get("col1", "q2", 5); ok {
+	if _, ok := c.get("col1", "", "q2", 5, nil); ok {
 		t.Fatal("col1/q2 should be invalidated")
 	}
-	if _, ok := c.get("col2", "q1", 5); !ok {
+	if _, ok := c.get("col2", "", "q1", 5, nil); !ok {
 		t.Fatal("col2/q1 should still exist")
 	}
 }
 
 func TestQueryCacheInvalidate_NoPrefixCollision(t *testing.T) {
 	c := newQueryCache(5*time.Minute, 100)
-	c.set("myrepo", "q1", 5, []schema.Document{{PageContent: "a"}})
-	c.set("myrepo-extra", "q1", 5, []schema.Document{{PageContent: "b"}})
+	c.set("myrepo", "", "q1", 5, nil, []schema.Document{{PageContent: "a"}})
+	c.set("myrepo-extra", "", "q1", 5, nil, []schema.Document{{PageContent: "b"}})
 
 	c.invalidate("myrepo")
 
-	if _, ok := c.get("myrepo", "q1", 5); ok {
+	if _, ok := c.get("myrepo", "", "q1", 5, nil); ok {
 		t.Fatal("myrepo/q1 should be invalidated")
 	}
-	if _, ok := c.get("myrepo-extra", "q1", 5); !ok {
+	if _, ok := c.get("myrepo-extra", "", "q1", 5, nil); !ok {
 		t.Fatal("myrepo-extra/q1 should still exist — prefix collision bug")
 	}
 }
 
 func TestQueryCacheClear(t *testing.T) {
 	c := newQueryCache(5*time.Minute, 100)
-	c.set("col1", "q1", 5, []schema.Document{{PageContent: "a"}})
-	c.set("col2", "q2", 5, []schema.Document{{PageContent: "b"}})
+	c.set("col1", "", "q1", 5, nil, []schema.Document{{PageContent: "a"}})
+	c.set("col2", "", "q2", 5, nil, []schema.Document{{PageContent: "b"}})
 
 	c.clear()
 
-	if _, ok := c.get("col1", "q1", 5); ok {
+	if _, ok := c.get("col1", "", "q1", 5, nil); ok {
 		t.Fatal("expected miss after clear")
 	}
-	if _, ok := c.get("col2", "q2", 5); ok {
+	if _, ok := c.get("col2", "", "q2", 5, nil); ok {
 		t.Fatal("expected miss after clear")
 	}
 }
@@ -131,7 +132,7 @@ func TestQueryCacheClear(t *testing.T) {
 func TestQueryCacheEviction(t *testing.T) {
 	c := newQueryCache(5*time.Minute, 3)
 	for i := range 5 {
-		c.set("col", string(rune('a'+i)), 1, []schema.Document{{PageContent: string(rune('a' + i))}})
+		c.set("col", "", string(rune('a'+i)), 1, nil, []schema.Document{{PageContent: string(rune('a' + i))}})
 	}
 
 	c.mu.RLock()
@@ -146,15 +147,15 @@ func TestQueryCacheEviction(t *testing.T) {
 func TestQueryCacheOverwriteNoEviction(t *testing.T) {
 	c := newQueryCache(5*time.Minute, 3)
 	docs := []schema.Document{{PageContent: "v1"}}
-	c.set("col", "q1", 1, docs)
-	c.set("col", "q2", 1, docs)
-	c.set("col", "q3", 1, docs)
+	c.set("col", "", "q1", 1, nil, docs)
+	c.set("col", "", "q2", 1, nil, docs)
+	c.set("col", "", "q3", 1, nil, docs)
 
 	if len(c.entries) != 3 {
 		t.Fatalf("expected 3 entries, got %d", len(c.entries))
 	}
 
-	c.set("col", "q1", 1, []schema.Document{{PageContent: "v2"}})
+	c.set("col", "", "q1", 1, nil, []schema.Document{{PageContent: "v2"}})
 
 	c.mu.RLock()
 	count := len(c.entries)
@@ -164,8 +165,66 @@ func TestQueryCacheOverwriteNoEviction(t *testing.T) {
 		t.Fatalf("overwrite should not trigger eviction, expected 3 entries, got %d", count)
 	}
 
-	got, ok := c.get("col", "q1", 1)
+	got, ok := c.get("col", "", "q1", 1, nil)
 	if !ok || got[0].PageContent != "v2" {
 		t.Fatal("overwritten entry should have new value")
 	}
 }
+
+func TestQueryCacheKeyIncludesEmbedderAndOptions(t *testing.T) {
+	c := newQueryCache(5*time.Minute, 100)
+	filter := []vectorstores.Option{vectorstores.WithFilters(map[string]any{"chunk_type": "definition"})}
+	c.set("col", "nomic", "q", 5, filter, []schema.Document{{PageContent: "def"}})
+
+	if _, ok := c.get("col", "bge", "q", 5, filter); ok {
+		t.Fatal("expected miss for a different embedder")
+	}
+	if _, ok := c.get("col", "nomic", "q", 5, nil); ok {
+		t.Fatal("expected miss without the filter")
+	}
+	if _, ok := c.get("col", "nomic", "q", 5, []vectorstores.Option{vectorstores.WithFilters(map[string]any{"chunk_type": "definition"})}); !ok {
+		t.Fatal("expected hit for the same filter")
+	}
+
+	override := []vectorstores.Option{vectorstores.WithEmbedder(wordEmbedder{})}
+	c.set("col", "nomic", "other", 5, override, []schema.Document{{PageContent: "x"}})
+	if _, ok := c.get("col", "nomic", "other", 5, override); ok {
+		t.Fatal("searches with an embedder override must not be cached")
+	}
+}
+
+func TestQueryCacheLRUAndStats(t *testing.T) {
+	c := newQueryCache(5*time.Minute, 2)
+	docs := []schema.Document{{PageContent: "d"}}
+	c.set("col", "", "a", 1, nil, docs)
+	c.set("col", "", "b", 1, nil, docs)
+	c.get("col", "", "a", 1, nil)
+	c.set("col", "", "c", 1, nil, docs)
+
+	if _, ok := c.get("col", "", "b", 1, nil); ok {
+		t.Fatal("the least recently used entry should be evicted")
+	}
+	if _, ok := c.get("col", "", "a", 1, nil); !ok {
+		t.Fatal("a recently used entry should be kept")
+	}
+
+	s := c.stats()
+	if s.Hits != 2 || s.Misses != 1 || s.Evictions != 1 || s.Entries != 2 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	if s.HitRate < 0.66 || s.HitRate > 0.67 {
+		t.Errorf("hit rate = %v, want 2/3", s.HitRate)
+	}
+}
+
+func TestQueryCacheDisabled(t *testing.T) {
+	c := newQueryCache(5*time.Minute, 0)
+	c.set("col", "", "q", 1, nil, []schema.Document{{PageContent: "d"}})
+	if _, ok := c.get("col", "", "q", 1, nil); ok {
+		t.Fatal("a disabled cache should miss")
+	}
+	c.invalidate("col")
+	if s := c.stats(); s != (QueryCacheStats{}) {
+		t.Errorf("stats of a disabled cache = %+v", s)
+	}
+}
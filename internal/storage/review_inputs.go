@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// ReviewInputsStore archives the prompt inputs of saved reviews so they can
+// be generated again. It is a sub-interface implemented by postgresStore.
+type ReviewInputsStore interface {
+	// GetReview returns the review with id, or ErrNotFound.
+	GetReview(ctx context.Context, id int64) (*core.Review, error)
+	// SaveReviewInputs stores the prompt inputs of a saved review.
+	SaveReviewInputs(ctx context.Context, reviewID int64, inputs []core.PromptInputs) error
+	// GetReviewInputs returns the prompt inputs of a review, or ErrNotFound
+	// when none were archived.
+	GetReviewInputs(ctx context.Context, reviewID int64) ([]core.PromptInputs, error)
+	// DeleteReviewInputsBefore deletes the inputs archived before cutoff and
+	// returns how many were deleted. The reviews themselves are kept.
+	DeleteReviewInputsBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// GetReview reads a review by its ID.
+func (s *postgresStore) GetReview(ctx context.Context, id int64) (*core.Review, error) {
+	query := `
+		SELECT id, repo_full_name, pr_number, head_sha, review_content, degraded, tree_sha, prompt_version, created_at
+		FROM reviews
+		WHERE id = $1`
+
+	var r core.Review
+	if err := s.db.GetContext(ctx, &r, query, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get review %d: %w", id, err)
+	}
+	return &r, nil
+}
+
+// SaveReviewInputs inserts the review_inputs row of a review.
+func (s *postgresStore) SaveReviewInputs(ctx context.Context, reviewID int64, inputs []core.PromptInputs) error {
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal review inputs: %w", err)
+	}
+	query := `
+		INSERT INTO review_inputs (review_id, inputs)
+		VALUES ($1, $2)
+		ON CONFLICT (review_id) DO UPDATE SET inputs = EXCLUDED.inputs`
+
+	if _, err := s.db.ExecContext(ctx, query, reviewID, data); err != nil {
+		return fmt.Errorf("failed to save inputs of review %d: %w", reviewID, err)
+	}
+	return nil
+}
+
+// GetReviewInputs reads the review_inputs row of a review.
+func (s *postgresStore) GetReviewInputs(ctx context.Context, reviewID int64) ([]core.PromptInputs, error) {
+	var data []byte
+	err := s.db.GetContext(ctx, &data, `SELECT inputs FROM review_inputs WHERE review_id = $1`, reviewID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get inputs of review %d: %w", reviewID, err)
+	}
+	var inputs []core.PromptInputs
+	if err := json.Unmarshal(data, &inputs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal review inputs: %w", err)
+	}
+	return inputs, nil
+}
+
+// DeleteReviewInputsBefore deletes the review_inputs rows older than cutoff.
+func (s *postgresStore) DeleteReviewInputsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM review_inputs WHERE created_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete review inputs: %w", err)
+	}
+	return res.RowsAffected()
+}
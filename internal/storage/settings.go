@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// RuntimeSetting is an administrator override of a configuration value,
+// stored as a JSON document.
+type RuntimeSetting struct {
+	Key       string    `db:"key"`
+	Value     string    `db:"value"`
+	UpdatedBy string    `db:"updated_by"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// SettingAudit records one change to a runtime setting. OldValue is nil
+// when the setting was created and NewValue is nil when it was removed.
+type SettingAudit struct {
+	ID        int64     `db:"id"`
+	Key       string    `db:"key"`
+	OldValue  *string   `db:"old_value"`
+	NewValue  *string   `db:"new_value"`
+	Actor     string    `db:"actor"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// SettingsStore defines persistence operations for runtime settings and
+// their audit trail. It is a sub-interface implemented by postgresStore.
+type SettingsStore interface {
+	// ListRuntimeSettings returns all stored overrides.
+	ListRuntimeSettings(ctx context.Context) ([]*RuntimeSetting, error)
+	// SetRuntimeSetting stores value as the JSON override of key, or removes
+	// the override when value is nil, and records the change in the audit
+	// trail in the same transaction.
+	SetRuntimeSetting(ctx context.Context, key string, value *string, actor string) error
+	// ListSettingsAudit returns the most recent setting changes, newest first.
+	ListSettingsAudit(ctx context.Context, limit int) ([]*SettingAudit, error)
+}
+
+// ListRuntimeSettings selects all runtime_settings rows.
+func (s *postgresStore) ListRuntimeSettings(ctx context.Context) ([]*RuntimeSetting, error) {
+	var settings []*RuntimeSetting
+	if err := s.db.SelectContext(ctx, &settings,
+		`SELECT key, value, updated_by, updated_at FROM runtime_settings ORDER BY key`,
+	); err != nil {
+		return nil, fmt.Errorf("failed to list runtime settings: %w", err)
+	}
+	return settings, nil
+}
+
+// SetRuntimeSetting upserts or deletes a runtime_settings row and appends a
+// settings_audit row in a single transaction.
+func (s *postgresStore) SetRuntimeSetting(ctx context.Context, key string, value *string, actor string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.ErrorContext(ctx, "transaction rollback failed in SetRuntimeSetting", "error", err)
+		}
+	}()
+
+	var oldValue *string
+	err = tx.GetContext(ctx, &oldValue, `SELECT value FROM runtime_settings WHERE key = $1 FOR UPDATE`, key)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get runtime setting %s: %w", key, err)
+	}
+
+	if value == nil {
+		_, err = tx.ExecContext(ctx, `DELETE FROM runtime_settings WHERE key = $1`, key)
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO runtime_settings (key, value, updated_by)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (key)
+			DO UPDATE SET value = EXCLUDED.value, updated_by = EXCLUDED.updated_by, updated_at = NOW()`,
+			key, *value, actor)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save runtime setting %s: %w", key, err)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO settings_audit (key, old_value, new_value, actor) VALUES ($1, $2, $3, $4)`,
+		key, oldValue, value, actor,
+	); err != nil {
+		return fmt.Errorf("failed to audit runtime setting %s: %w", key, err)
+	}
+
+	return tx.Commit()
+}
+
+// ListSettingsAudit selects the latest settings_audit rows.
+func (s *postgresStore) ListSettingsAudit(ctx context.Context, limit int) ([]*SettingAudit, error) {
+	var entries []*SettingAudit
+	if err := s.db.SelectContext(ctx, &entries, `
+		SELECT id, key, old_value, new_value, actor, created_at
+		FROM settings_audit
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1`, limit,
+	); err != nil {
+		return nil, fmt.Errorf("failed to list settings audit: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CollectionSnapshotter moves whole vector collections in and out of the
+// vector database so rarely used repositories can be kept in cold storage.
+type CollectionSnapshotter interface {
+	// Snapshot creates a snapshot of collection and streams it to w.
+	Snapshot(ctx context.Context, collection string, w io.Writer) error
+	// Restore recreates collection from a snapshot stream.
+	Restore(ctx context.Context, collection string, r io.Reader) error
+}
+
+// qdrantSnapshotter talks to the Qdrant REST API, which (unlike the gRPC API
+// used for search) supports downloading and uploading snapshot files.
+type qdrantSnapshotter struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewQdrantSnapshotter returns a CollectionSnapshotter for the Qdrant REST
+// endpoint at baseURL (e.g. "http://localhost:6333").
+func NewQdrantSnapshotter(baseURL string) CollectionSnapshotter {
+	return &qdrantSnapshotter{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: 30 * time.Minute},
+	}
+}
+
+func (q *qdrantSnapshotter) collectionURL(collection string, parts ...string) string {
+	u := q.baseURL + "/collections/" + url.PathEscape(collection) + "/snapshots"
+	for _, p := range parts {
+		u += "/" + url.PathEscape(p)
+	}
+	return u
+}
+
+// Snapshot creates a server-side snapshot, streams it to w, then deletes the
+// server-side copy so it does not consume Qdrant disk.
+func (q *qdrantSnapshotter) Snapshot(ctx context.Context, collection string, w io.Writer) error {
+	var created struct {
+		Result struct {
+			Name string `json:"name"`
+		} `json:"result"`
+	}
+	if err := q.do(ctx, http.MethodPost, q.collectionURL(collection)+"?wait=true", nil, "", &created); err != nil {
+		return fmt.Errorf("failed to create snapshot for %s: %w", collection, err)
+	}
+	name := created.Result.Name
+	if name == "" {
+		return fmt.Errorf("qdrant returned no snapshot name for %s", collection)
+	}
+	defer func() {
+		_ = q.do(context.WithoutCancel(ctx), http.MethodDelete, q.collectionURL(collection, name), nil, "", nil)
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, q.collectionURL(collection, name), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download snapshot %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download snapshot %s: status %d", name, resp.StatusCode)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("failed to stream snapshot %s: %w", name, err)
+	}
+	return nil
+}
+
+// Restore uploads a snapshot, recreating the collection. The snapshot data
+// takes priority over any existing collection with the same name.
+func (q *qdrantSnapshotter) Restore(ctx context.Context, collection string, r io.Reader) error {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		part, err := mw.CreateFormFile("snapshot", collection+".snapshot")
+		if err == nil {
+			_, err = io.Copy(part, r)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	target := q.collectionURL(collection, "upload") + "?wait=true&priority=snapshot"
+	if err := q.do(ctx, http.MethodPost, target, pr, mw.FormDataContentType(), nil); err != nil {
+		return fmt.Errorf("failed to restore snapshot for %s: %w", collection, err)
+	}
+	return nil
+}
+
+func (q *qdrantSnapshotter) do(ctx context.Context, method, target string, body io.Reader, contentType string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := q.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("qdrant %s %s: status %d: %s", method, target, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode qdrant response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQdrantSnapshotter_RoundTrip(t *testing.T) {
+	var deleted, restored bool
+	var uploaded []byte
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /collections/repo/snapshots", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"result":{"name":"snap-1"}}`)
+	})
+	mux.HandleFunc("GET /collections/repo/snapshots/snap-1", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "snapshot-bytes")
+	})
+	mux.HandleFunc("DELETE /collections/repo/snapshots/snap-1", func(_ http.ResponseWriter, _ *http.Request) {
+		deleted = true
+	})
+	mux.HandleFunc("POST /collections/repo/snapshots/upload", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "snapshot", r.URL.Query().Get("priority"))
+		f, _, err := r.FormFile("snapshot")
+		if !assert.NoError(t, err) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		defer f.Close()
+		uploaded, _ = io.ReadAll(f)
+		restored = true
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := NewQdrantSnapshotter(srv.URL + "/")
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	require.NoError(t, s.Snapshot(ctx, "repo", &buf))
+	assert.Equal(t, "snapshot-bytes", buf.String())
+	assert.True(t, deleted, "server-side snapshot should be removed after download")
+
+	require.NoError(t, s.Restore(ctx, "repo", &buf))
+	assert.True(t, restored)
+	assert.Equal(t, "snapshot-bytes", string(uploaded))
+}
+
+func TestQdrantSnapshotter_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "collection not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	err := NewQdrantSnapshotter(srv.URL).Snapshot(context.Background(), "missing", io.Discard)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "collection not found")
+}
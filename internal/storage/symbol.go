@@ -0,0 +1,137 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// maxSymbolLookups bounds the definitions returned by one lookup, so that a
+// common name cannot flood the review context.
+const maxSymbolLookups = 200
+
+// SymbolDefinition is a symbol defined in an indexed file.
+type SymbolDefinition struct {
+	FilePath  string `db:"file_path"`
+	Symbol    string `db:"symbol"`
+	Kind      string `db:"kind"`
+	LineStart int    `db:"line_start"`
+	LineEnd   int    `db:"line_end"`
+}
+
+// SymbolReference is an edge from a chunk of an indexed file, identified by
+// its first line, to a symbol it uses.
+type SymbolReference struct {
+	FilePath string `db:"file_path"`
+	Line     int    `db:"line"`
+	Symbol   string `db:"symbol"`
+}
+
+// SymbolStore defines persistence operations for the symbol graph of
+// repositories. It is a sub-interface implemented by postgresStore.
+type SymbolStore interface {
+	// ReplaceSymbols replaces the definitions and references of files with
+	// the given ones, which must belong to those files.
+	ReplaceSymbols(ctx context.Context, repoID int64, files []string, defs []SymbolDefinition, refs []SymbolReference) error
+	// FindSymbolDefinitions returns where the given symbols are defined in
+	// the repository that uses collectionName, ordered by symbol and file.
+	FindSymbolDefinitions(ctx context.Context, collectionName string, symbols []string) ([]SymbolDefinition, error)
+}
+
+// ReplaceSymbols deletes the symbol rows of files and inserts the new ones in
+// a single transaction.
+func (s *postgresStore) ReplaceSymbols(ctx context.Context, repoID int64, files []string, defs []SymbolDefinition, refs []SymbolReference) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			slog.ErrorContext(ctx, "transaction rollback failed in ReplaceSymbols", "error", err)
+		}
+	}()
+
+	if err := deleteSymbols(ctx, tx, repoID, files); err != nil {
+		return err
+	}
+
+	defStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO symbol_definitions (repository_id, file_path, symbol, kind, line_start, line_end)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT DO NOTHING`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare symbol definition stmt: %w", err)
+	}
+	defer defStmt.Close()
+	for _, d := range defs {
+		if _, err := defStmt.ExecContext(ctx, repoID, d.FilePath, d.Symbol, d.Kind, d.LineStart, d.LineEnd); err != nil {
+			return fmt.Errorf("failed to insert definition of %s in %s: %w", d.Symbol, d.FilePath, err)
+		}
+	}
+
+	refStmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO symbol_references (repository_id, file_path, line, symbol)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare symbol reference stmt: %w", err)
+	}
+	defer refStmt.Close()
+	for _, r := range refs {
+		if _, err := refStmt.ExecContext(ctx, repoID, r.FilePath, r.Line, r.Symbol); err != nil {
+			return fmt.Errorf("failed to insert reference to %s in %s: %w", r.Symbol, r.FilePath, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// deleteSymbols deletes the symbol rows of files in batches.
+func deleteSymbols(ctx context.Context, ex sqlx.ExtContext, repoID int64, files []string) error {
+	const batchSize = 1000
+	for i := 0; i < len(files); i += batchSize {
+		batch := files[i:min(i+batchSize, len(files))]
+		for _, table := range []string{"symbol_definitions", "symbol_references"} {
+			query, args, err := sqlx.In("DELETE FROM "+table+" WHERE repository_id = ? AND file_path IN (?)", repoID, batch)
+			if err != nil {
+				return fmt.Errorf("failed to build delete query: %w", err)
+			}
+			if _, err := ex.ExecContext(ctx, ex.Rebind(query), args...); err != nil {
+				return fmt.Errorf("failed to delete symbols of repo %d: %w", repoID, err)
+			}
+		}
+	}
+	return nil
+}
+
+// FindSymbolDefinitions looks up symbol_definitions through the repository
+// that owns collectionName.
+func (s *postgresStore) FindSymbolDefinitions(ctx context.Context, collectionName string, symbols []string) ([]SymbolDefinition, error) {
+	if len(symbols) == 0 {
+		return nil, nil
+	}
+	query, args, err := sqlx.In(`
+		SELECT d.file_path, d.symbol, d.kind, d.line_start, d.line_end
+		FROM symbol_definitions d
+		JOIN repositories r ON r.id = d.repository_id
+		WHERE r.qdrant_collection_name = ? AND d.symbol IN (?)
+		ORDER BY d.symbol, d.file_path, d.line_start
+		LIMIT ?`, collectionName, symbols, maxSymbolLookups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build symbol query: %w", err)
+	}
+
+	var defs []SymbolDefinition
+	if err := s.db.SelectContext(ctx, &defs, s.db.Rebind(query), args...); err != nil {
+		return nil, fmt.Errorf("failed to find symbol definitions: %w", err)
+	}
+	return defs, nil
+}
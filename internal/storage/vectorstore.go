@@ -3,7 +3,9 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -16,6 +18,8 @@ import (
 	"github.com/sevigo/goframe/vectorstores/qdrant"
 
 	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/health"
+	"github.com/sevigo/code-warden/internal/netutil"
 )
 
 // VectorStore interface updated for multi-model support
@@ -41,6 +45,24 @@ type VectorStore interface {
 	DeleteCollection(ctx context.Context, collectionName string) error
 	DeleteDocumentsFromCollection(ctx context.Context, collectionName, embedderModelName string, documentIDs []string) error
 	DeleteDocumentsFromCollectionByFilter(ctx context.Context, collectionName, embedderModelName string, filters map[string]any) error
+
+	// Ping reports whether the vector store server is reachable through the
+	// client of a collection.
+	Ping(ctx context.Context, collectionName, embedderModelName string) error
+
+	// StoredCollections lists every collection in the vector database, not
+	// only those opened by this process as ListCollections does.
+	StoredCollections(ctx context.Context) ([]string, error)
+
+	// CollectionStats counts the documents of a collection in the vector
+	// database, by chunk type where the backend can.
+	CollectionStats(ctx context.Context, collectionName string) (*CollectionStats, error)
+}
+
+// healthChecker is implemented by collection clients that can tell whether
+// their server is reachable.
+type healthChecker interface {
+	Health(ctx context.Context) error
 }
 
 // ScopedVectorStore is a VectorStore scoped to a specific collection and embedder model.
@@ -51,52 +73,86 @@ type ScopedVectorStore interface {
 	EmbedderModel() string
 }
 
-// Ensure qdrantVectorStore implements VectorStore
-var _ VectorStore = (*qdrantVectorStore)(nil)
+// Ensure vectorStore implements VectorStore
+var _ VectorStore = (*vectorStore)(nil)
 
-// qdrantVectorStore implements VectorStore using Qdrant with client caching.
-type qdrantVectorStore struct {
+// storedCollectionsClient names the collection of the client that lists all
+// collections; the collection itself is never used.
+const storedCollectionsClient = "code-warden-admin"
+
+// collectionOpener creates the client of one collection of a vector database.
+type collectionOpener func(collectionName string, embedder embeddings.Embedder) (vectorstores.VectorStore, error)
+
+// vectorStore implements VectorStore on top of per-collection clients of a
+// vector database, caching clients, embedders and scoped stores.
+type vectorStore struct {
 	qdrantHost   string
+	open         collectionOpener
+	stats        collectionStatsFunc
 	logger       *slog.Logger
 	mu           sync.Mutex
 	embedderMu   sync.RWMutex
 	clients      map[string]vectorstores.VectorStore
 	embedders    map[string]embeddings.Embedder
+	newEmbedder  EmbedderFactory
 	batchConfig  *qdrant.BatchConfig
 	cfg          *config.Config
 	qdrantOpts   []qdrant.Option
 	scopedMu     sync.RWMutex
 	scopedStores map[string]*scopedVectorStore
 	queryCache   *queryCache
+	// vectorBreaker and embedderBreaker guard the clients and embedders;
+	// see WithBreakers.
+	vectorBreaker   *health.Breaker
+	embedderBreaker *health.Breaker
 }
 
-// QdrantStoreOption defines a functional option for configuring the Qdrant vector store.
-type QdrantStoreOption func(*qdrantVectorStore)
+// VectorStoreOption defines a functional option for configuring the vector store.
+type VectorStoreOption func(*vectorStore)
 
 // WithBatchConfig sets the batch processing configuration for the vector store.
-func WithBatchConfig(config *qdrant.BatchConfig) QdrantStoreOption {
-	return func(s *qdrantVectorStore) {
+func WithBatchConfig(config *qdrant.BatchConfig) VectorStoreOption {
+	return func(s *vectorStore) {
 		s.batchConfig = config
 	}
 }
 
 // WithInitialEmbedder pre-populates the vector store with a configured embedder.
-func WithInitialEmbedder(modelName string, embedder embeddings.Embedder) QdrantStoreOption {
-	return func(s *qdrantVectorStore) {
+func WithInitialEmbedder(modelName string, embedder embeddings.Embedder) VectorStoreOption {
+	return func(s *vectorStore) {
 		s.logger.Info("Pre-registering initial embedder", "model", modelName)
 		s.embedders[modelName] = embedder
 	}
 }
 
+// EmbedderFactory creates the embedder of a model.
+type EmbedderFactory func(modelName string) (embeddings.Embedder, error)
+
+// WithEmbedderFactory sets how the embedder of a model is created when a
+// collection is first used with it. Without a factory, embedders are Ollama
+// clients with default settings.
+func WithEmbedderFactory(factory EmbedderFactory) VectorStoreOption {
+	return func(s *vectorStore) {
+		s.newEmbedder = factory
+	}
+}
+
 // WithQdrantOptions sets additional Qdrant options for connection configuration.
-func WithQdrantOptions(opts ...qdrant.Option) QdrantStoreOption {
-	return func(s *qdrantVectorStore) {
+func WithQdrantOptions(opts ...qdrant.Option) VectorStoreOption {
+	return func(s *vectorStore) {
 		s.qdrantOpts = append(s.qdrantOpts, opts...)
 	}
 }
 
 // NewQdrantVectorStore creates a new Qdrant-backed vector store.
-func NewQdrantVectorStore(cfg *config.Config, logger *slog.Logger, opts ...QdrantStoreOption) VectorStore {
+func NewQdrantVectorStore(cfg *config.Config, logger *slog.Logger, opts ...VectorStoreOption) VectorStore {
+	s := newVectorStore(cfg, logger, opts...)
+	s.open = s.openQdrant
+	s.stats = newQdrantStats(cfg.Storage.QdrantHost).collectionStats
+	return s
+}
+
+func newVectorStore(cfg *config.Config, logger *slog.Logger, opts ...VectorStoreOption) *vectorStore {
 	defaultConfig := &qdrant.BatchConfig{
 		BatchSize:               256,
 		MaxConcurrency:          4,
@@ -107,7 +163,7 @@ func NewQdrantVectorStore(cfg *config.Config, logger *slog.Logger, opts ...Qdran
 		RetryJitter:             qdrant.DefaultRetryJitter,
 		MaxRetryDelay:           qdrant.DefaultMaxRetryDelay,
 	}
-	s := &qdrantVectorStore{
+	s := &vectorStore{
 		qdrantHost:   cfg.Storage.QdrantHost,
 		logger:       logger,
 		clients:      make(map[string]vectorstores.VectorStore),
@@ -115,7 +171,7 @@ func NewQdrantVectorStore(cfg *config.Config, logger *slog.Logger, opts ...Qdran
 		batchConfig:  defaultConfig,
 		cfg:          cfg,
 		scopedStores: make(map[string]*scopedVectorStore),
-		queryCache:   newQueryCache(10*time.Minute, 512),
+		queryCache:   newQueryCache(cfg.Storage.QueryCache.TTL, cfg.Storage.QueryCache.MaxEntries),
 	}
 	for _, opt := range opts {
 		opt(s)
@@ -124,7 +180,7 @@ func NewQdrantVectorStore(cfg *config.Config, logger *slog.Logger, opts ...Qdran
 }
 
 // getOrCreateEmbedder creates and caches embedder clients on the fly.
-func (q *qdrantVectorStore) getOrCreateEmbedder(modelName string) (embeddings.Embedder, error) {
+func (q *vectorStore) getOrCreateEmbedder(modelName string) (embeddings.Embedder, error) {
 	// Try read lock first (non-blocking for concurrent reads)
 	q.embedderMu.RLock()
 	if embedder, exists := q.embedders[modelName]; exists {
@@ -144,11 +200,20 @@ func (q *qdrantVectorStore) getOrCreateEmbedder(modelName string) (embeddings.Em
 
 	q.logger.Info("Creating and caching new embedder client", "model", modelName)
 
+	if q.newEmbedder != nil {
+		embedder, err := q.newEmbedder(modelName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create embedder for %s: %w", modelName, err)
+		}
+		q.embedders[modelName] = embedder
+		return embedder, nil
+	}
+
 	// Currently only Ollama is supported; can be extended later.
 	baseEmbedder, err := ollama.New(
 		ollama.WithServerURL(q.cfg.AI.OllamaHost),
 		ollama.WithModel(modelName),
-		ollama.WithHTTPClient(httpclient.DefaultClient),
+		ollama.WithHTTPClient(netutil.Apply(httpclient.DefaultClient)),
 		ollama.WithRetryAttempts(3),
 		ollama.WithRetryDelay(2*time.Second),
 	)
@@ -165,8 +230,8 @@ func (q *qdrantVectorStore) getOrCreateEmbedder(modelName string) (embeddings.Em
 	return wrappedEmbedder, nil
 }
 
-// getStoreForCollection retrieves or creates a Qdrant client for the specified collection.
-func (q *qdrantVectorStore) getStoreForCollection(collectionName string, embedderModelName string) (vectorstores.VectorStore, error) {
+// getStoreForCollection retrieves or creates a client for the specified collection.
+func (q *vectorStore) getStoreForCollection(collectionName string, embedderModelName string) (vectorstores.VectorStore, error) {
 	if err := q.validateCollectionName(collectionName); err != nil {
 		return nil, err
 	}
@@ -184,6 +249,18 @@ func (q *qdrantVectorStore) getStoreForCollection(collectionName string, embedde
 		return nil, fmt.Errorf("cannot create store without a valid embedder for model %s: %w", embedderModelName, err)
 	}
 
+	newClient, err := q.open(collectionName, q.guardEmbedder(embedder))
+	if err != nil {
+		return nil, err
+	}
+	newClient = q.guardClient(newClient)
+
+	q.clients[collectionName] = newClient
+	return newClient, nil
+}
+
+// openQdrant creates the Qdrant client of a collection.
+func (q *vectorStore) openQdrant(collectionName string, embedder embeddings.Embedder) (vectorstores.VectorStore, error) {
 	opts := []qdrant.Option{
 		qdrant.WithHost(q.qdrantHost),
 		qdrant.WithEmbedder(embedder),
@@ -219,32 +296,31 @@ func (q *qdrantVectorStore) getStoreForCollection(collectionName string, embedde
 		}
 	}
 
-	q.clients[collectionName] = newClient
 	return newClient, nil
 }
 
-func (q *qdrantVectorStore) SetBatchConfig(config qdrant.BatchConfig) error {
+func (q *vectorStore) SetBatchConfig(config qdrant.BatchConfig) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 	q.batchConfig = &config
 	for _, client := range q.clients {
-		if store, ok := client.(*qdrant.Store); ok {
+		if store, ok := unwrapClient(client).(*qdrant.Store); ok {
 			store.SetBatchConfig(config)
 		}
 	}
 	return nil
 }
 
-// Close closes all cached Qdrant clients and releases resources.
-func (q *qdrantVectorStore) Close() error {
+// Close closes all cached collection clients and releases resources.
+func (q *vectorStore) Close() error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
 	var lastErr error
 	for name, client := range q.clients {
-		if store, ok := client.(*qdrant.Store); ok {
+		if store, ok := client.(io.Closer); ok {
 			if err := store.Close(); err != nil {
-				q.logger.Warn("failed to close qdrant client", "collection", name, "error", err)
+				q.logger.Warn("failed to close vector store client", "collection", name, "error", err)
 				lastErr = err
 			}
 		}
@@ -252,12 +328,12 @@ func (q *qdrantVectorStore) Close() error {
 
 	// Clear the clients map
 	q.clients = make(map[string]vectorstores.VectorStore)
-	q.logger.Info("closed all qdrant clients")
+	q.logger.Info("closed all vector store clients")
 
 	return lastErr
 }
 
-func (q *qdrantVectorStore) AddDocumentsToCollection(ctx context.Context, collectionName, embedderModelName string, docs []schema.Document, progressFn func(processed, total int, duration time.Duration)) error {
+func (q *vectorStore) AddDocumentsToCollection(ctx context.Context, collectionName, embedderModelName string, docs []schema.Document, progressFn func(processed, total int, duration time.Duration)) error {
 	if len(docs) == 0 {
 		return nil
 	}
@@ -267,17 +343,28 @@ func (q *qdrantVectorStore) AddDocumentsToCollection(ctx context.Context, collec
 		return fmt.Errorf("failed to get store for collection %s: %w", collectionName, err)
 	}
 
-	qdrantStore, ok := store.(*qdrant.Store)
+	defer q.queryCache.invalidate(collectionName)
+	qdrantStore, ok := unwrapClient(store).(*qdrant.Store)
 	if !ok {
-		return fmt.Errorf("failed to cast store to *qdrant.Store; cannot use batching feature")
+		// Other backends have no batching pipeline; report the whole set at once.
+		start := time.Now()
+		if _, err := store.AddDocuments(ctx, docs, vectorstores.WithCollectionName(collectionName)); err != nil {
+			return err
+		}
+		if progressFn != nil {
+			progressFn(len(docs), len(docs), time.Since(start))
+		}
+		return nil
 	}
 
-	_, err = qdrantStore.AddDocumentsBatch(ctx, docs, progressFn, vectorstores.WithCollectionName(collectionName))
-	return err
+	return q.vectorBreaker.Do(func() error {
+		_, err := qdrantStore.AddDocumentsBatch(ctx, docs, progressFn, vectorstores.WithCollectionName(collectionName))
+		return err
+	})
 }
 
 // SearchCollection is the renamed SimilaritySearch
-func (q *qdrantVectorStore) SearchCollection(ctx context.Context, collectionName, embedderModelName, query string, numDocs int) ([]schema.Document, error) {
+func (q *vectorStore) SearchCollection(ctx context.Context, collectionName, embedderModelName, query string, numDocs int) ([]schema.Document, error) {
 	q.logger.DebugContext(ctx, "Starting similarity search", "collection", collectionName, "embedder", embedderModelName)
 
 	if strings.TrimSpace(query) == "" {
@@ -286,10 +373,14 @@ func (q *qdrantVectorStore) SearchCollection(ctx context.Context, collectionName
 	if numDocs <= 0 {
 		return nil, fmt.Errorf("numDocs must be positive, got %d", numDocs)
 	}
+	if docs, ok := q.queryCache.get(collectionName, embedderModelName, query, numDocs, nil); ok {
+		q.logger.DebugContext(ctx, "Similarity search answered from cache", "collection", collectionName, "results_found", len(docs))
+		return docs, nil
+	}
 
 	store, err := q.getStoreForCollection(collectionName, embedderModelName)
 	if err != nil {
-		q.logger.Error("Can't get vector store",
+		q.logger.ErrorContext(ctx, "Can't get vector store",
 			"error", err,
 			"collectionName", collectionName,
 			"embedderModelName", embedderModelName,
@@ -306,10 +397,11 @@ func (q *qdrantVectorStore) SearchCollection(ctx context.Context, collectionName
 	}
 
 	q.logger.InfoContext(ctx, "Similarity search completed successfully", "results_found", len(results), "duration", time.Since(startTime))
+	q.queryCache.set(collectionName, embedderModelName, query, numDocs, nil, results)
 	return results, nil
 }
 
-func (q *qdrantVectorStore) SearchCollectionBatch(ctx context.Context, collectionName, embedderModelName string, queries []string, numDocs int, opts ...vectorstores.Option) ([][]schema.Document, error) {
+func (q *vectorStore) SearchCollectionBatch(ctx context.Context, collectionName, embedderModelName string, queries []string, numDocs int, opts ...vectorstores.Option) ([][]schema.Document, error) {
 	if len(queries) == 0 {
 		return nil, nil
 	}
@@ -327,15 +419,19 @@ func (q *qdrantVectorStore) SearchCollectionBatch(ctx context.Context, collectio
 	return store.SimilaritySearchBatch(ctx, queries, numDocs, opts...)
 }
 
-func (q *qdrantVectorStore) DeleteCollection(ctx context.Context, collectionName string) error {
+// DeleteCollection deletes a collection, through its cached client or, for
+// collections this process never opened, a client with the default embedder.
+func (q *vectorStore) DeleteCollection(ctx context.Context, collectionName string) error {
 	q.mu.Lock()
 	client, ok := q.clients[collectionName]
-	if !ok {
-		q.mu.Unlock()
-		return fmt.Errorf("no active client for collection %s, cannot delete", collectionName)
-	}
 	// Don't delete from cache yet - delete first, then remove from cache
 	q.mu.Unlock()
+	if !ok {
+		var err error
+		if client, err = q.getStoreForCollection(collectionName, q.cfg.AI.EmbedderModel); err != nil {
+			return fmt.Errorf("failed to open collection %s for deletion: %w", collectionName, err)
+		}
+	}
 
 	if err := client.DeleteCollection(ctx, collectionName); err != nil {
 		return fmt.Errorf("failed to delete collection %s: %w", collectionName, err)
@@ -345,10 +441,11 @@ func (q *qdrantVectorStore) DeleteCollection(ctx context.Context, collectionName
 	q.mu.Lock()
 	delete(q.clients, collectionName)
 	q.mu.Unlock()
+	q.queryCache.invalidate(collectionName)
 	return nil
 }
 
-func (q *qdrantVectorStore) DeleteDocumentsFromCollection(ctx context.Context, collectionName, embedderModelName string, documentIDs []string) error {
+func (q *vectorStore) DeleteDocumentsFromCollection(ctx context.Context, collectionName, embedderModelName string, documentIDs []string) error {
 	if len(documentIDs) == 0 {
 		return nil
 	}
@@ -359,18 +456,32 @@ func (q *qdrantVectorStore) DeleteDocumentsFromCollection(ctx context.Context, c
 	}
 
 	filters := map[string]any{"source": map[string]any{"$in": documentIDs}}
+	defer q.queryCache.invalidate(collectionName)
 	return store.DeleteDocumentsByFilter(ctx, filters)
 }
 
-func (q *qdrantVectorStore) DeleteDocumentsFromCollectionByFilter(ctx context.Context, collectionName, embedderModelName string, filters map[string]any) error {
+func (q *vectorStore) DeleteDocumentsFromCollectionByFilter(ctx context.Context, collectionName, embedderModelName string, filters map[string]any) error {
 	store, err := q.getStoreForCollection(collectionName, embedderModelName)
 	if err != nil {
 		return err
 	}
+	defer q.queryCache.invalidate(collectionName)
 	return store.DeleteDocumentsByFilter(ctx, filters)
 }
 
-func (q *qdrantVectorStore) ListCollections(_ context.Context) ([]string, error) {
+func (q *vectorStore) Ping(ctx context.Context, collectionName, embedderModelName string) error {
+	store, err := q.getStoreForCollection(collectionName, embedderModelName)
+	if err != nil {
+		return err
+	}
+	checker, ok := store.(healthChecker)
+	if !ok {
+		return nil
+	}
+	return checker.Health(ctx)
+}
+
+func (q *vectorStore) ListCollections(_ context.Context) ([]string, error) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 	cols := make([]string, 0, len(q.clients))
@@ -380,7 +491,29 @@ func (q *qdrantVectorStore) ListCollections(_ context.Context) ([]string, error)
 	return cols, nil
 }
 
-func (q *qdrantVectorStore) validateCollectionName(collectionName string) error {
+// StoredCollections asks the vector database for its collections through a
+// client that is not cached.
+func (q *vectorStore) StoredCollections(ctx context.Context) ([]string, error) {
+	embedder, err := q.getOrCreateEmbedder(q.cfg.AI.EmbedderModel)
+	if err != nil {
+		return nil, err
+	}
+	client, err := q.open(storedCollectionsClient, q.guardEmbedder(embedder))
+	if err != nil {
+		return nil, err
+	}
+	client = q.guardClient(client)
+	if closer, ok := client.(io.Closer); ok {
+		defer closer.Close()
+	}
+	names, err := client.ListCollections(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stored collections: %w", err)
+	}
+	return names, nil
+}
+
+func (q *vectorStore) validateCollectionName(collectionName string) error {
 	if strings.TrimSpace(collectionName) == "" {
 		return fmt.Errorf("collection name cannot be empty")
 	}
@@ -395,7 +528,7 @@ func extractCollectionName(opts ...vectorstores.Option) string {
 	return parsed.CollectionName
 }
 
-func (q *qdrantVectorStore) AddDocuments(ctx context.Context, docs []schema.Document, opts ...vectorstores.Option) ([]string, error) {
+func (q *vectorStore) AddDocuments(ctx context.Context, docs []schema.Document, opts ...vectorstores.Option) ([]string, error) {
 	collectionName := extractCollectionName(opts...)
 	if collectionName == "" {
 		return nil, fmt.Errorf("collection name required via WithCollectionName option for AddDocuments")
@@ -404,17 +537,18 @@ func (q *qdrantVectorStore) AddDocuments(ctx context.Context, docs []schema.Docu
 	// Use default embedder from config
 	embedderModel := q.cfg.AI.EmbedderModel
 
-	q.logger.Debug("AddDocuments via generic interface", "collection", collectionName, "embedder", embedderModel, "docs", len(docs))
+	q.logger.DebugContext(ctx, "AddDocuments via generic interface", "collection", collectionName, "embedder", embedderModel, "docs", len(docs))
 
 	store, err := q.getStoreForCollection(collectionName, embedderModel)
 	if err != nil {
 		return nil, err
 	}
 
+	defer q.queryCache.invalidate(collectionName)
 	return store.AddDocuments(ctx, docs, opts...)
 }
 
-func (q *qdrantVectorStore) SimilaritySearch(ctx context.Context, query string, numDocs int, opts ...vectorstores.Option) ([]schema.Document, error) {
+func (q *vectorStore) SimilaritySearch(ctx context.Context, query string, numDocs int, opts ...vectorstores.Option) ([]schema.Document, error) {
 	collectionName := extractCollectionName(opts...)
 	if collectionName == "" {
 		return nil, fmt.Errorf("collection name required via WithCollectionName option for SimilaritySearch")
@@ -423,7 +557,7 @@ func (q *qdrantVectorStore) SimilaritySearch(ctx context.Context, query string,
 	// Use default embedder from config
 	embedderModel := q.cfg.AI.EmbedderModel
 
-	q.logger.Debug("SimilaritySearch via generic interface", "collection", collectionName, "embedder", embedderModel)
+	q.logger.DebugContext(ctx, "SimilaritySearch via generic interface", "collection", collectionName, "embedder", embedderModel)
 
 	store, err := q.getStoreForCollection(collectionName, embedderModel)
 	if err != nil {
@@ -433,7 +567,7 @@ func (q *qdrantVectorStore) SimilaritySearch(ctx context.Context, query string,
 	return store.SimilaritySearch(ctx, query, numDocs, opts...)
 }
 
-func (q *qdrantVectorStore) SimilaritySearchWithScores(ctx context.Context, query string, numDocs int, opts ...vectorstores.Option) ([]vectorstores.DocumentWithScore, error) {
+func (q *vectorStore) SimilaritySearchWithScores(ctx context.Context, query string, numDocs int, opts ...vectorstores.Option) ([]vectorstores.DocumentWithScore, error) {
 	collectionName := extractCollectionName(opts...)
 	if collectionName == "" {
 		return nil, fmt.Errorf("collection name required via WithCollectionName option")
@@ -448,7 +582,7 @@ func (q *qdrantVectorStore) SimilaritySearchWithScores(ctx context.Context, quer
 	return store.SimilaritySearchWithScores(ctx, query, numDocs, opts...)
 }
 
-func (q *qdrantVectorStore) DeleteDocumentsByFilter(ctx context.Context, filters map[string]any, opts ...vectorstores.Option) error {
+func (q *vectorStore) DeleteDocumentsByFilter(ctx context.Context, filters map[string]any, opts ...vectorstores.Option) error {
 	collectionName := extractCollectionName(opts...)
 	if collectionName == "" {
 		return fmt.Errorf("collection name required via WithCollectionName option")
@@ -460,10 +594,11 @@ func (q *qdrantVectorStore) DeleteDocumentsByFilter(ctx context.Context, filters
 		return err
 	}
 
+	defer q.queryCache.invalidate(collectionName)
 	return store.DeleteDocumentsByFilter(ctx, filters, opts...)
 }
 
-func (q *qdrantVectorStore) SimilaritySearchBatch(ctx context.Context, queries []string, numDocs int, opts ...vectorstores.Option) ([][]schema.Document, error) {
+func (q *vectorStore) SimilaritySearchBatch(ctx context.Context, queries []string, numDocs int, opts ...vectorstores.Option) ([][]schema.Document, error) {
 	collectionName := extractCollectionName(opts...)
 	if collectionName == "" {
 		return nil, fmt.Errorf("collection name required via WithCollectionName option")
@@ -473,9 +608,20 @@ func (q *qdrantVectorStore) SimilaritySearchBatch(ctx context.Context, queries [
 	return q.SearchCollectionBatch(ctx, collectionName, embedderModel, queries, numDocs, opts...)
 }
 
+// QueryCacheStats reports the hits and misses of the similarity search
+// cache since the server started.
+func (q *vectorStore) QueryCacheStats() QueryCacheStats {
+	return q.queryCache.stats()
+}
+
+// InvalidateQueryCache drops the cached searches of collection.
+func (q *vectorStore) InvalidateQueryCache(collection string) {
+	q.queryCache.invalidate(collection)
+}
+
 // ForRepo returns a scoped store for a specific repository collection and embedder model.
 // Cached scoped stores are returned for better performance on hot paths.
-func (q *qdrantVectorStore) ForRepo(collectionName, embedderModel string) ScopedVectorStore {
+func (q *vectorStore) ForRepo(collectionName, embedderModel string) ScopedVectorStore {
 	// Create cache key
 	cacheKey := collectionName + "|" + embedderModel
 
@@ -508,9 +654,9 @@ func (q *qdrantVectorStore) ForRepo(collectionName, embedderModel string) Scoped
 	return scoped
 }
 
-// scopedVectorStore wraps qdrantVectorStore with pre-configured collection and embedder.
+// scopedVectorStore wraps vectorStore with pre-configured collection and embedder.
 type scopedVectorStore struct {
-	parent         *qdrantVectorStore
+	parent         *vectorStore
 	collectionName string
 	embedderModel  string
 	queryCache     *queryCache
@@ -519,6 +665,27 @@ type scopedVectorStore struct {
 // Ensure scopedVectorStore implements ScopedVectorStore
 var _ ScopedVectorStore = (*scopedVectorStore)(nil)
 
+// AddDocumentsWithProgress adds docs to store and reports the documents
+// stored, of all docs, and the time taken to progressFn. Stores that cannot
+// report progress, like test doubles, report all docs once they are added.
+func AddDocumentsWithProgress(ctx context.Context, store ScopedVectorStore, docs []schema.Document, progressFn func(processed, total int, duration time.Duration)) error {
+	type batchAdder interface {
+		AddDocumentsBatch(ctx context.Context, docs []schema.Document, progressFn func(processed, total int, duration time.Duration)) ([]string, error)
+	}
+	if adder, ok := store.(batchAdder); ok {
+		_, err := adder.AddDocumentsBatch(ctx, docs, progressFn)
+		return err
+	}
+	start := time.Now()
+	if _, err := store.AddDocuments(ctx, docs); err != nil {
+		return err
+	}
+	if progressFn != nil {
+		progressFn(len(docs), len(docs), time.Since(start))
+	}
+	return nil
+}
+
 // CollectionName returns the scoped collection name.
 func (s *scopedVectorStore) CollectionName() string {
 	return s.collectionName
@@ -531,7 +698,13 @@ func (s *scopedVectorStore) EmbedderModel() string {
 
 // AddDocuments delegates to the parent's AddDocumentsToCollection.
 func (s *scopedVectorStore) AddDocuments(ctx context.Context, docs []schema.Document, _ ...vectorstores.Option) ([]string, error) {
-	err := s.parent.AddDocumentsToCollection(ctx, s.collectionName, s.embedderModel, docs, nil)
+	return s.AddDocumentsBatch(ctx, docs, nil)
+}
+
+// AddDocumentsBatch is AddDocuments with the progress reports of
+// AddDocumentsToCollection.
+func (s *scopedVectorStore) AddDocumentsBatch(ctx context.Context, docs []schema.Document, progressFn func(processed, total int, duration time.Duration)) ([]string, error) {
+	err := s.parent.AddDocumentsToCollection(ctx, s.collectionName, s.embedderModel, docs, progressFn)
 	if err != nil {
 		return nil, err
 	}
@@ -547,17 +720,17 @@ func (s *scopedVectorStore) AddDocuments(ctx context.Context, docs []schema.Docu
 
 // SimilaritySearch delegates to the parent's generic interface with query caching.
 func (s *scopedVectorStore) SimilaritySearch(ctx context.Context, query string, numDocs int, opts ...vectorstores.Option) ([]schema.Document, error) {
-	if docs, ok := s.queryCache.get(s.collectionName, query, numDocs); ok {
+	if docs, ok := s.queryCache.get(s.collectionName, s.embedderModel, query, numDocs, opts); ok {
 		return docs, nil
 	}
 
-	opts = append(opts, vectorstores.WithCollectionName(s.collectionName))
-	docs, err := s.parent.SimilaritySearch(ctx, query, numDocs, opts...)
+	searchOpts := append(slices.Clip(opts), vectorstores.WithCollectionName(s.collectionName))
+	docs, err := s.parent.SimilaritySearch(ctx, query, numDocs, searchOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	s.queryCache.set(s.collectionName, query, numDocs, docs)
+	s.queryCache.set(s.collectionName, s.embedderModel, query, numDocs, opts, docs)
 	return docs, nil
 }
 
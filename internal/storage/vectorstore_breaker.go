@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"context"
+	"io"
+
+	"github.com/sevigo/goframe/embeddings"
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+
+	"github.com/sevigo/code-warden/internal/health"
+)
+
+// WithBreakers makes the calls of the collection clients pass through the
+// circuit breaker vectors and those of the embedders through embedder, so
+// that they fail fast while the vector database or the embedding server is
+// down. Either may be nil.
+func WithBreakers(vectors, embedder *health.Breaker) VectorStoreOption {
+	return func(s *vectorStore) {
+		s.vectorBreaker = vectors
+		s.embedderBreaker = embedder
+	}
+}
+
+// guardClient wraps client in the vector breaker, if any.
+func (q *vectorStore) guardClient(client vectorstores.VectorStore) vectorstores.VectorStore {
+	if q.vectorBreaker == nil {
+		return client
+	}
+	return &breakerClient{VectorStore: client, breaker: q.vectorBreaker}
+}
+
+// guardEmbedder wraps embedder in the embedder breaker, if any.
+func (q *vectorStore) guardEmbedder(embedder embeddings.Embedder) embeddings.Embedder {
+	if q.embedderBreaker == nil {
+		return embedder
+	}
+	return &breakerEmbedder{Embedder: embedder, breaker: q.embedderBreaker, backend: q.embedderBreaker.Name()}
+}
+
+// unwrapClient returns the client a breakerClient wraps, for the
+// backend-specific features that need the concrete client.
+func unwrapClient(client vectorstores.VectorStore) vectorstores.VectorStore {
+	if b, ok := client.(*breakerClient); ok {
+		return b.VectorStore
+	}
+	return client
+}
+
+// guarded runs call through breaker.
+func guarded[T any](breaker *health.Breaker, call func() (T, error)) (T, error) {
+	var result T
+	err := breaker.Do(func() error {
+		var err error
+		result, err = call()
+		return err
+	})
+	return result, err
+}
+
+// breakerClient is a collection client whose calls pass through a breaker.
+type breakerClient struct {
+	vectorstores.VectorStore
+	breaker *health.Breaker
+}
+
+func (c *breakerClient) AddDocuments(ctx context.Context, docs []schema.Document, opts ...vectorstores.Option) ([]string, error) {
+	return guarded(c.breaker, func() ([]string, error) { return c.VectorStore.AddDocuments(ctx, docs, opts...) })
+}
+
+func (c *breakerClient) SimilaritySearch(ctx context.Context, query string, numDocs int, opts ...vectorstores.Option) ([]schema.Document, error) {
+	return guarded(c.breaker, func() ([]schema.Document, error) {
+		return c.VectorStore.SimilaritySearch(ctx, query, numDocs, opts...)
+	})
+}
+
+func (c *breakerClient) SimilaritySearchBatch(ctx context.Context, queries []string, numDocs int, opts ...vectorstores.Option) ([][]schema.Document, error) {
+	return guarded(c.breaker, func() ([][]schema.Document, error) {
+		return c.VectorStore.SimilaritySearchBatch(ctx, queries, numDocs, opts...)
+	})
+}
+
+func (c *breakerClient) SimilaritySearchWithScores(ctx context.Context, query string, numDocs int, opts ...vectorstores.Option) ([]vectorstores.DocumentWithScore, error) {
+	return guarded(c.breaker, func() ([]vectorstores.DocumentWithScore, error) {
+		return c.VectorStore.SimilaritySearchWithScores(ctx, query, numDocs, opts...)
+	})
+}
+
+func (c *breakerClient) ListCollections(ctx context.Context) ([]string, error) {
+	return guarded(c.breaker, func() ([]string, error) { return c.VectorStore.ListCollections(ctx) })
+}
+
+func (c *breakerClient) DeleteCollection(ctx context.Context, collectionName string) error {
+	return c.breaker.Do(func() error { return c.VectorStore.DeleteCollection(ctx, collectionName) })
+}
+
+func (c *breakerClient) DeleteDocumentsByFilter(ctx context.Context, filters map[string]any, opts ...vectorstores.Option) error {
+	return c.breaker.Do(func() error { return c.VectorStore.DeleteDocumentsByFilter(ctx, filters, opts...) })
+}
+
+// Health implements healthChecker for clients that can check their server.
+func (c *breakerClient) Health(ctx context.Context) error {
+	checker, ok := c.VectorStore.(healthChecker)
+	if !ok {
+		return nil
+	}
+	return c.breaker.Do(func() error { return checker.Health(ctx) })
+}
+
+// Close implements io.Closer for clients that hold connections.
+func (c *breakerClient) Close() error {
+	if closer, ok := c.VectorStore.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// breakerEmbedder is an embedder whose calls pass through a breaker. Its
+// errors are marked as errors of the embedder, so that the vector breaker
+// does not count them when they surface through a collection client.
+type breakerEmbedder struct {
+	embeddings.Embedder
+	breaker *health.Breaker
+	backend string
+}
+
+func (e *breakerEmbedder) mark(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &health.BackendError{Backend: e.backend, Err: err}
+}
+
+func (e *breakerEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return guarded(e.breaker, func() ([][]float32, error) {
+		vectors, err := e.Embedder.EmbedDocuments(ctx, texts)
+		return vectors, e.mark(err)
+	})
+}
+
+func (e *breakerEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return guarded(e.breaker, func() ([]float32, error) {
+		vector, err := e.Embedder.EmbedQuery(ctx, text)
+		return vector, e.mark(err)
+	})
+}
+
+func (e *breakerEmbedder) EmbedQueries(ctx context.Context, texts []string) ([][]float32, error) {
+	return guarded(e.breaker, func() ([][]float32, error) {
+		vectors, err := e.Embedder.EmbedQueries(ctx, texts)
+		return vectors, e.mark(err)
+	})
+}
+
+func (e *breakerEmbedder) GetDimension(ctx context.Context) (int, error) {
+	return guarded(e.breaker, func() (int, error) {
+		dim, err := e.Embedder.GetDimension(ctx)
+		return dim, e.mark(err)
+	})
+}
@@ -0,0 +1,217 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sevigo/goframe/embeddings"
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+)
+
+// memoryVectors holds the documents of all collections of a memory store.
+type memoryVectors struct {
+	mu          sync.RWMutex
+	collections map[string]map[string]memoryDocument
+}
+
+type memoryDocument struct {
+	doc    schema.Document
+	vector []float32
+}
+
+func newMemoryVectors() *memoryVectors {
+	return &memoryVectors{collections: make(map[string]map[string]memoryDocument)}
+}
+
+// collectionStats counts the documents of a collection by chunk type.
+func (m *memoryVectors) collectionStats(_ context.Context, collectionName string) (*CollectionStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stats := &CollectionStats{Documents: len(m.collections[collectionName]), ByChunkType: make(map[string]int)}
+	for _, stored := range m.collections[collectionName] {
+		chunkType, _ := stored.doc.Metadata["chunk_type"].(string)
+		stats.ByChunkType[cmp.Or(chunkType, UntypedChunk)]++
+	}
+	return stats, nil
+}
+
+// memoryCollection is the client of one collection of a memory store. Search
+// is exact cosine similarity over all documents of the collection.
+type memoryCollection struct {
+	data     *memoryVectors
+	name     string
+	embedder embeddings.Embedder
+}
+
+var _ vectorstores.VectorStore = (*memoryCollection)(nil)
+
+func (m *memoryCollection) collection(opts vectorstores.Options) string {
+	if opts.CollectionName != "" {
+		return opts.CollectionName
+	}
+	return m.name
+}
+
+func (m *memoryCollection) AddDocuments(ctx context.Context, docs []schema.Document, options ...vectorstores.Option) ([]string, error) {
+	valid := make([]schema.Document, 0, len(docs))
+	texts := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		if text := strings.TrimSpace(doc.PageContent); text != "" {
+			valid = append(valid, doc)
+			texts = append(texts, text)
+		}
+	}
+	if len(valid) == 0 {
+		return []string{}, nil
+	}
+
+	vectors, err := m.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed documents: %w", err)
+	}
+	if len(vectors) != len(valid) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d documents", len(vectors), len(valid))
+	}
+
+	name := m.collection(vectorstores.ParseOptions(options...))
+	m.data.mu.Lock()
+	defer m.data.mu.Unlock()
+	stored, ok := m.data.collections[name]
+	if !ok {
+		stored = make(map[string]memoryDocument)
+		m.data.collections[name] = stored
+	}
+	ids := make([]string, len(valid))
+	for i, doc := range valid {
+		ids[i] = documentID(doc)
+		stored[ids[i]] = memoryDocument{doc: doc, vector: vectors[i]}
+	}
+	return ids, nil
+}
+
+func (m *memoryCollection) SimilaritySearch(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]schema.Document, error) {
+	results, err := m.SimilaritySearchWithScores(ctx, query, numDocuments, options...)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]schema.Document, len(results))
+	for i, r := range results {
+		docs[i] = r.Document
+	}
+	return docs, nil
+}
+
+func (m *memoryCollection) SimilaritySearchBatch(ctx context.Context, queries []string, numDocuments int, options ...vectorstores.Option) ([][]schema.Document, error) {
+	results := make([][]schema.Document, len(queries))
+	for i, query := range queries {
+		docs, err := m.SimilaritySearch(ctx, query, numDocuments, options...)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = docs
+	}
+	return results, nil
+}
+
+func (m *memoryCollection) SimilaritySearchWithScores(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]vectorstores.DocumentWithScore, error) {
+	vector, err := m.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	opts := vectorstores.ParseOptions(options...)
+
+	m.data.mu.RLock()
+	defer m.data.mu.RUnlock()
+	var results []vectorstores.DocumentWithScore
+	for _, stored := range m.data.collections[m.collection(opts)] {
+		if !matchesFilters(stored.doc.Metadata, opts.Filters) {
+			continue
+		}
+		score := cosineSimilarity(vector, stored.vector)
+		if opts.ScoreThreshold > 0 && score < opts.ScoreThreshold {
+			continue
+		}
+		results = append(results, vectorstores.DocumentWithScore{Document: stored.doc, Score: score})
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > numDocuments {
+		results = results[:numDocuments]
+	}
+	return results, nil
+}
+
+func (m *memoryCollection) ListCollections(_ context.Context) ([]string, error) {
+	m.data.mu.RLock()
+	defer m.data.mu.RUnlock()
+	names := make([]string, 0, len(m.data.collections))
+	for name := range m.data.collections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (m *memoryCollection) DeleteCollection(_ context.Context, collectionName string) error {
+	if collectionName == "" {
+		collectionName = m.name
+	}
+	m.data.mu.Lock()
+	defer m.data.mu.Unlock()
+	delete(m.data.collections, collectionName)
+	return nil
+}
+
+func (m *memoryCollection) DeleteDocumentsByFilter(_ context.Context, filters map[string]any, options ...vectorstores.Option) error {
+	if len(filters) == 0 {
+		return fmt.Errorf("cannot delete with an empty filter")
+	}
+	name := m.collection(vectorstores.ParseOptions(options...))
+	m.data.mu.Lock()
+	defer m.data.mu.Unlock()
+	for id, stored := range m.data.collections[name] {
+		if matchesFilters(stored.doc.Metadata, filters) {
+			delete(m.data.collections[name], id)
+		}
+	}
+	return nil
+}
+
+// matchesFilters reports whether metadata has every field of filters set to
+// one of the accepted values. Filters of unsupported types are ignored, as
+// Qdrant does.
+func matchesFilters(metadata, filters map[string]any) bool {
+	for key, value := range filters {
+		values, ok := filterValues(value)
+		if !ok {
+			continue
+		}
+		field, ok := metadata[key]
+		if !ok || !slices.Contains(values, fmt.Sprint(field)) {
+			return false
+		}
+	}
+	return true
+}
+
+func cosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -0,0 +1,220 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sevigo/goframe/embeddings"
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// wordEmbedder embeds a text as the counts of a few words.
+type wordEmbedder struct{}
+
+var embedWords = []string{"alpha", "beta", "gamma"}
+
+func (wordEmbedder) EmbedQuery(_ context.Context, text string) ([]float32, error) {
+	v := make([]float32, len(embedWords))
+	for i, w := range embedWords {
+		v[i] = float32(strings.Count(text, w))
+	}
+	return v, nil
+}
+
+func (e wordEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	return e.EmbedQueries(ctx, texts)
+}
+
+func (e wordEmbedder) EmbedQueries(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i], _ = e.EmbedQuery(ctx, text)
+	}
+	return vectors, nil
+}
+
+func (wordEmbedder) GetDimension(context.Context) (int, error) { return len(embedWords), nil }
+
+func TestNewVectorStore_Memory(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{Storage: config.StorageConfig{VectorStoreProvider: config.VectorStoreMemory}}
+	store, err := NewVectorStore(cfg, nil, slog.Default(), WithInitialEmbedder("words", wordEmbedder{}))
+	require.NoError(t, err)
+
+	var progressed int
+	docs := []schema.Document{
+		{PageContent: "alpha alpha", Metadata: map[string]any{"source": "a.go", "chunk_type": "code", "line": 3}},
+		{PageContent: "beta", Metadata: map[string]any{"source": "b.go", "chunk_type": "code"}},
+		{PageContent: "alpha beta", Metadata: map[string]any{"source": "c.go", "chunk_type": "definition"}},
+		{PageContent: "   "},
+	}
+	require.NoError(t, store.AddDocumentsToCollection(ctx, "repo", "words", docs, func(processed, _ int, _ time.Duration) {
+		progressed = processed
+	}))
+	assert.Equal(t, len(docs), progressed)
+
+	stats, err := store.CollectionStats(ctx, "repo")
+	require.NoError(t, err)
+	assert.Equal(t, &CollectionStats{Documents: 3, ByChunkType: map[string]int{"code": 2, "definition": 1}}, stats)
+
+	scoped := store.ForRepo("repo", "words")
+	results, err := scoped.SimilaritySearch(ctx, "alpha", 2)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "a.go", results[0].Metadata["source"])
+	assert.Equal(t, "c.go", results[1].Metadata["source"])
+
+	results, err = scoped.SimilaritySearch(ctx, "alpha", 5, vectorstores.WithFilters(map[string]any{"chunk_type": "definition"}))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "c.go", results[0].Metadata["source"])
+
+	results, err = scoped.SimilaritySearch(ctx, "alpha gamma", 5, vectorstores.WithFilter("line", 3))
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "a.go", results[0].Metadata["source"])
+
+	require.NoError(t, store.DeleteDocumentsFromCollectionByFilter(ctx, "repo", "words",
+		map[string]any{"source": map[string]any{"$in": []string{"a.go", "c.go"}}}))
+	results, err = store.SearchCollection(ctx, "repo", "words", "alpha beta", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "b.go", results[0].Metadata["source"])
+
+	assert.Error(t, store.DeleteDocumentsFromCollectionByFilter(ctx, "repo", "words", map[string]any{}))
+	assert.NoError(t, store.Ping(ctx, "repo", "words"))
+}
+
+func TestVectorStore_QueryCache(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{Storage: config.StorageConfig{
+		VectorStoreProvider: config.VectorStoreMemory,
+		QueryCache:          config.QueryCacheConfig{MaxEntries: 10, TTL: time.Minute},
+	}}
+	store, err := NewVectorStore(cfg, nil, slog.Default(), WithInitialEmbedder("words", wordEmbedder{}))
+	require.NoError(t, err)
+	docs := []schema.Document{
+		{PageContent: "alpha", Metadata: map[string]any{"source": "a.go"}},
+		{PageContent: "alpha beta", Metadata: map[string]any{"source": "b.go"}},
+	}
+	require.NoError(t, store.AddDocumentsToCollection(ctx, "repo", "words", docs, nil))
+
+	scoped := store.ForRepo("repo", "words")
+	for range 2 {
+		results, err := scoped.SimilaritySearch(ctx, "alpha", 5)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+	}
+	stats := store.(QueryCacheReporter).QueryCacheStats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+
+	require.NoError(t, store.DeleteDocumentsFromCollection(ctx, "repo", "words", []string{"a.go"}))
+	results, err := scoped.SimilaritySearch(ctx, "alpha", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1, "changing the collection drops its cached results")
+	assert.Equal(t, "b.go", results[0].Metadata["source"])
+}
+
+// addOnlyStore is a ScopedVectorStore without AddDocumentsBatch.
+type addOnlyStore struct {
+	ScopedVectorStore
+	added int
+}
+
+func (s *addOnlyStore) AddDocuments(_ context.Context, docs []schema.Document, _ ...vectorstores.Option) ([]string, error) {
+	s.added += len(docs)
+	return nil, nil
+}
+
+func TestAddDocumentsWithProgress(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{Storage: config.StorageConfig{VectorStoreProvider: config.VectorStoreMemory}}
+	store, err := NewVectorStore(cfg, nil, slog.Default(), WithInitialEmbedder("words", wordEmbedder{}))
+	require.NoError(t, err)
+	docs := []schema.Document{
+		{PageContent: "alpha", Metadata: map[string]any{"source": "a.go"}},
+		{PageContent: "beta", Metadata: map[string]any{"source": "b.go"}},
+	}
+
+	var processed, total int
+	report := func(p, t int, _ time.Duration) { processed, total = p, t }
+	require.NoError(t, AddDocumentsWithProgress(ctx, store.ForRepo("repo", "words"), docs, report))
+	assert.Equal(t, 2, processed)
+	assert.Equal(t, 2, total)
+	stats, err := store.CollectionStats(ctx, "repo")
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Documents)
+
+	processed, total = 0, 0
+	fallback := &addOnlyStore{}
+	require.NoError(t, AddDocumentsWithProgress(ctx, fallback, docs, report))
+	assert.Equal(t, 2, fallback.added)
+	assert.Equal(t, 2, processed, "stores without batching report all documents at once")
+	assert.Equal(t, 2, total)
+}
+
+func TestNewVectorStore_UnknownProvider(t *testing.T) {
+	cfg := &config.Config{Storage: config.StorageConfig{VectorStoreProvider: "milvus"}}
+	_, err := NewVectorStore(cfg, nil, slog.Default())
+	assert.Error(t, err)
+}
+
+func TestAppendFilterSQL(t *testing.T) {
+	var sql strings.Builder
+	sql.WriteString("WHERE collection = $1")
+	args, err := appendFilterSQL(&sql, []any{"repo"}, map[string]any{
+		"source":     map[string]any{"$in": []string{"a.go", "b.go"}},
+		"chunk_type": "definition",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "WHERE collection = $1 AND metadata->>$2 = ANY($3) AND metadata->>$4 = ANY($5)", sql.String())
+	require.Len(t, args, 5)
+	assert.Equal(t, "chunk_type", args[1])
+	assert.Equal(t, "source", args[3])
+
+	_, err = appendFilterSQL(&sql, []any{"repo"}, map[string]any{"source": "a.go", "score": 3.5})
+	assert.ErrorContains(t, err, `unsupported filter "score" of type float64`)
+}
+
+func TestDecodeMetadata(t *testing.T) {
+	metadata, err := decodeMetadata([]byte(`{"source":"a.go","line":42,"score":0.5,"lines":[1,2]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "a.go", metadata["source"])
+	assert.Equal(t, int64(42), metadata["line"])
+	assert.InDelta(t, 0.5, metadata["score"], 1e-9)
+	assert.Equal(t, []any{int64(1), int64(2)}, metadata["lines"])
+
+	metadata, err = decodeMetadata([]byte(`null`))
+	require.NoError(t, err)
+	assert.NotNil(t, metadata)
+
+	assert.Equal(t, "[0.5,-1,2.25]", formatVector([]float32{0.5, -1, 2.25}))
+}
+
+func TestVectorStore_EmbedderFactory(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{Storage: config.StorageConfig{VectorStoreProvider: config.VectorStoreMemory}}
+	var created []string
+	store, err := NewVectorStore(cfg, nil, slog.Default(), WithEmbedderFactory(func(model string) (embeddings.Embedder, error) {
+		created = append(created, model)
+		return wordEmbedder{}, nil
+	}))
+	require.NoError(t, err)
+	assert.Empty(t, created, "embedders are created on first use")
+
+	doc := []schema.Document{{PageContent: "alpha", Metadata: map[string]any{"source": "a.go"}}}
+	require.NoError(t, store.AddDocumentsToCollection(ctx, "repo-a", "model-a", doc, nil))
+	require.NoError(t, store.AddDocumentsToCollection(ctx, "repo-b", "model-b", doc, nil))
+	require.NoError(t, store.AddDocumentsToCollection(ctx, "repo-c", "model-a", doc, nil))
+	assert.Equal(t, []string{"model-a", "model-b"}, created)
+}
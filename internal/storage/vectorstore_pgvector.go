@@ -0,0 +1,377 @@
+package storage
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/sevigo/goframe/embeddings"
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+)
+
+// pgvectorBatchSize is how many documents are embedded and inserted at once.
+const pgvectorBatchSize = 64
+
+// pgvectorSchemaSQL creates the table of all pgvector collections. The
+// embedding column has no fixed dimension so that collections of different
+// embedders can share it; search is exact, which is fine at the size of the
+// deployments that run without Qdrant.
+const pgvectorSchemaSQL = `
+CREATE EXTENSION IF NOT EXISTS vector;
+CREATE TABLE IF NOT EXISTS vector_documents (
+    collection TEXT NOT NULL,
+    id TEXT NOT NULL,
+    content TEXT NOT NULL,
+    metadata JSONB NOT NULL DEFAULT '{}',
+    embedding vector NOT NULL,
+    PRIMARY KEY (collection, id)
+);
+CREATE INDEX IF NOT EXISTS idx_vector_documents_metadata ON vector_documents USING GIN (metadata);`
+
+// pgvectorSchema creates the vector_documents table once per process. A
+// failed attempt is retried on the next use.
+type pgvectorSchema struct {
+	db    *sqlx.DB
+	mu    sync.Mutex
+	ready bool
+}
+
+func (p *pgvectorSchema) ensure(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ready {
+		return nil
+	}
+	if _, err := p.db.ExecContext(ctx, pgvectorSchemaSQL); err != nil {
+		return fmt.Errorf("failed to create pgvector schema (is the vector extension installed?): %w", err)
+	}
+	p.ready = true
+	return nil
+}
+
+// pgvectorCollection is the client of one collection in the vector_documents
+// table.
+type pgvectorCollection struct {
+	db       *sqlx.DB
+	schema   *pgvectorSchema
+	name     string
+	embedder embeddings.Embedder
+	logger   *slog.Logger
+}
+
+var _ vectorstores.VectorStore = (*pgvectorCollection)(nil)
+
+func (p *pgvectorCollection) collection(opts vectorstores.Options) string {
+	if opts.CollectionName != "" {
+		return opts.CollectionName
+	}
+	return p.name
+}
+
+// Health pings the database.
+func (p *pgvectorCollection) Health(ctx context.Context) error {
+	return p.db.PingContext(ctx)
+}
+
+func (p *pgvectorCollection) AddDocuments(ctx context.Context, docs []schema.Document, options ...vectorstores.Option) ([]string, error) {
+	if err := p.schema.ensure(ctx); err != nil {
+		return nil, err
+	}
+	name := p.collection(vectorstores.ParseOptions(options...))
+
+	valid := make([]schema.Document, 0, len(docs))
+	for _, doc := range docs {
+		if strings.TrimSpace(doc.PageContent) != "" {
+			valid = append(valid, doc)
+		}
+	}
+	ids := make([]string, 0, len(valid))
+	for start := 0; start < len(valid); start += pgvectorBatchSize {
+		batch := valid[start:min(start+pgvectorBatchSize, len(valid))]
+		batchIDs, err := p.addBatch(ctx, name, batch)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, batchIDs...)
+	}
+	return ids, nil
+}
+
+func (p *pgvectorCollection) addBatch(ctx context.Context, name string, docs []schema.Document) ([]string, error) {
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = strings.TrimSpace(doc.PageContent)
+	}
+	vectors, err := p.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed documents: %w", err)
+	}
+	if len(vectors) != len(docs) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d documents", len(vectors), len(docs))
+	}
+
+	tx, err := p.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	const query = `
+		INSERT INTO vector_documents (collection, id, content, metadata, embedding)
+		VALUES ($1, $2, $3, $4, $5::vector)
+		ON CONFLICT (collection, id) DO UPDATE SET
+			content = EXCLUDED.content,
+			metadata = EXCLUDED.metadata,
+			embedding = EXCLUDED.embedding`
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		if doc.Metadata == nil {
+			doc.Metadata = map[string]any{}
+		}
+		metadata, err := json.Marshal(doc.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal document metadata: %w", err)
+		}
+		ids[i] = documentID(doc)
+		if _, err := tx.ExecContext(ctx, query, name, ids[i], doc.PageContent, metadata, formatVector(vectors[i])); err != nil {
+			return nil, fmt.Errorf("failed to insert document into %s: %w", name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit documents: %w", err)
+	}
+	return ids, nil
+}
+
+func (p *pgvectorCollection) SimilaritySearch(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]schema.Document, error) {
+	results, err := p.SimilaritySearchWithScores(ctx, query, numDocuments, options...)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]schema.Document, len(results))
+	for i, r := range results {
+		docs[i] = r.Document
+	}
+	return docs, nil
+}
+
+func (p *pgvectorCollection) SimilaritySearchBatch(ctx context.Context, queries []string, numDocuments int, options ...vectorstores.Option) ([][]schema.Document, error) {
+	results := make([][]schema.Document, len(queries))
+	for i, query := range queries {
+		docs, err := p.SimilaritySearch(ctx, query, numDocuments, options...)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = docs
+	}
+	return results, nil
+}
+
+// SimilaritySearchWithScores ranks the documents by cosine distance to the
+// query. Sparse vectors of hybrid search are ignored.
+func (p *pgvectorCollection) SimilaritySearchWithScores(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]vectorstores.DocumentWithScore, error) {
+	if err := p.schema.ensure(ctx); err != nil {
+		return nil, err
+	}
+	vector, err := p.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+	opts := vectorstores.ParseOptions(options...)
+
+	args := []any{p.collection(opts), formatVector(vector)}
+	var sql strings.Builder
+	sql.WriteString(`SELECT id, content, metadata, 1 - (embedding <=> $2::vector) FROM vector_documents WHERE collection = $1`)
+	args, err = appendFilterSQL(&sql, args, opts.Filters)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ScoreThreshold > 0 {
+		args = append(args, opts.ScoreThreshold)
+		fmt.Fprintf(&sql, " AND 1 - (embedding <=> $2::vector) >= $%d", len(args))
+	}
+	args = append(args, numDocuments)
+	fmt.Fprintf(&sql, " ORDER BY embedding <=> $2::vector LIMIT $%d", len(args))
+
+	rows, err := p.db.QueryContext(ctx, sql.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", p.collection(opts), err)
+	}
+	defer rows.Close()
+
+	var results []vectorstores.DocumentWithScore
+	for rows.Next() {
+		var (
+			id, content string
+			metadata    []byte
+			score       float64
+		)
+		if err := rows.Scan(&id, &content, &metadata, &score); err != nil {
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		doc := schema.Document{PageContent: content}
+		if doc.Metadata, err = decodeMetadata(metadata); err != nil {
+			p.logger.WarnContext(ctx, "failed to decode document metadata", "collection", p.collection(opts), "id", id, "error", err)
+		}
+		results = append(results, vectorstores.DocumentWithScore{Document: doc, Score: float32(score)})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read search results: %w", err)
+	}
+	return results, nil
+}
+
+func (p *pgvectorCollection) ListCollections(ctx context.Context) ([]string, error) {
+	if err := p.schema.ensure(ctx); err != nil {
+		return nil, err
+	}
+	var names []string
+	if err := p.db.SelectContext(ctx, &names, `SELECT DISTINCT collection FROM vector_documents ORDER BY collection`); err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	return names, nil
+}
+
+func (p *pgvectorCollection) DeleteCollection(ctx context.Context, collectionName string) error {
+	if err := p.schema.ensure(ctx); err != nil {
+		return err
+	}
+	if collectionName == "" {
+		collectionName = p.name
+	}
+	if _, err := p.db.ExecContext(ctx, `DELETE FROM vector_documents WHERE collection = $1`, collectionName); err != nil {
+		return fmt.Errorf("failed to delete collection %s: %w", collectionName, err)
+	}
+	return nil
+}
+
+func (p *pgvectorCollection) DeleteDocumentsByFilter(ctx context.Context, filters map[string]any, options ...vectorstores.Option) error {
+	if err := p.schema.ensure(ctx); err != nil {
+		return err
+	}
+	name := p.collection(vectorstores.ParseOptions(options...))
+	args := []any{name}
+	var sql strings.Builder
+	sql.WriteString(`DELETE FROM vector_documents WHERE collection = $1`)
+	args, err := appendFilterSQL(&sql, args, filters)
+	if err != nil {
+		return err
+	}
+	if len(args) == 1 {
+		return fmt.Errorf("cannot delete with an empty filter")
+	}
+	if _, err := p.db.ExecContext(ctx, sql.String(), args...); err != nil {
+		return fmt.Errorf("failed to delete documents from %s: %w", name, err)
+	}
+	return nil
+}
+
+// collectionStats counts the documents of a collection by chunk type and
+// sums the size of their rows.
+func (p *pgvectorSchema) collectionStats(ctx context.Context, collectionName string) (*CollectionStats, error) {
+	if err := p.ensure(ctx); err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		ChunkType string `db:"chunk_type"`
+		Documents int    `db:"documents"`
+		SizeBytes int64  `db:"size_bytes"`
+	}
+	query := `
+		SELECT COALESCE(metadata->>'chunk_type', '') AS chunk_type, COUNT(*) AS documents, SUM(pg_column_size(d.*)) AS size_bytes
+		FROM vector_documents d
+		WHERE collection = $1
+		GROUP BY 1`
+	if err := p.db.SelectContext(ctx, &rows, query, collectionName); err != nil {
+		return nil, err
+	}
+	stats := &CollectionStats{ByChunkType: make(map[string]int, len(rows))}
+	for _, row := range rows {
+		stats.ByChunkType[cmp.Or(row.ChunkType, UntypedChunk)] += row.Documents
+		stats.Documents += row.Documents
+		stats.SizeBytes += row.SizeBytes
+	}
+	return stats, nil
+}
+
+// appendFilterSQL adds a condition on the metadata column for every filter
+// to sql and returns args with their parameters. A filter of an unsupported
+// type is an error rather than left out, which would widen a delete.
+func appendFilterSQL(sql *strings.Builder, args []any, filters map[string]any) ([]any, error) {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values, ok := filterValues(filters[key])
+		if !ok {
+			return nil, fmt.Errorf("unsupported filter %q of type %T", key, filters[key])
+		}
+		args = append(args, key, pq.Array(values))
+		fmt.Fprintf(sql, " AND metadata->>$%d = ANY($%d)", len(args)-1, len(args))
+	}
+	return args, nil
+}
+
+// formatVector renders v as a pgvector literal.
+func formatVector(v []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, x := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(x), 'f', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// decodeMetadata unmarshals a metadata column with integers as int64, the way
+// Qdrant returns them. The map is never nil.
+func decodeMetadata(data []byte) (map[string]any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var metadata map[string]any
+	if err := decoder.Decode(&metadata); err != nil {
+		return make(map[string]any), err
+	}
+	if metadata == nil {
+		return make(map[string]any), nil
+	}
+	for key, value := range metadata {
+		metadata[key] = fromJSONNumber(value)
+	}
+	return metadata, nil
+}
+
+func fromJSONNumber(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case []any:
+		for i := range v {
+			v[i] = fromJSONNumber(v[i])
+		}
+	case map[string]any:
+		for key := range v {
+			v[key] = fromJSONNumber(v[key])
+		}
+	}
+	return value
+}
@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sevigo/goframe/embeddings"
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// NewVectorStore creates the vector store selected by
+// storage.vector_store_provider. The pgvector store keeps its embeddings in
+// db, the database of the Store.
+func NewVectorStore(cfg *config.Config, db *sqlx.DB, logger *slog.Logger, opts ...VectorStoreOption) (VectorStore, error) {
+	switch cfg.Storage.VectorStoreProvider {
+	case "", config.VectorStoreQdrant:
+		return NewQdrantVectorStore(cfg, logger, opts...), nil
+	case config.VectorStorePgvector:
+		return NewPgvectorStore(cfg, db, logger, opts...), nil
+	case config.VectorStoreWeaviate:
+		return NewWeaviateStore(cfg, logger, opts...), nil
+	case config.VectorStoreMemory:
+		return NewMemoryVectorStore(cfg, logger, opts...), nil
+	default:
+		return nil, fmt.Errorf("unsupported vector store provider %q", cfg.Storage.VectorStoreProvider)
+	}
+}
+
+// NewPgvectorStore creates a vector store that keeps embeddings in the
+// vector_documents table of db. The table and the vector extension are
+// created on first use, so databases without pgvector only fail when the
+// provider is actually selected.
+func NewPgvectorStore(cfg *config.Config, db *sqlx.DB, logger *slog.Logger, opts ...VectorStoreOption) VectorStore {
+	s := newVectorStore(cfg, logger, opts...)
+	tables := &pgvectorSchema{db: db}
+	s.stats = tables.collectionStats
+	s.open = func(collectionName string, embedder embeddings.Embedder) (vectorstores.VectorStore, error) {
+		return &pgvectorCollection{
+			db:       db,
+			schema:   tables,
+			name:     collectionName,
+			embedder: embedder,
+			logger:   logger,
+		}, nil
+	}
+	return s
+}
+
+// NewWeaviateStore creates a vector store that keeps every collection in a
+// class of the Weaviate cluster at storage.weaviate_url. Embeddings are
+// computed by Code-Warden, so the classes need no Weaviate vectorizer.
+func NewWeaviateStore(cfg *config.Config, logger *slog.Logger, opts ...VectorStoreOption) VectorStore {
+	s := newVectorStore(cfg, logger, opts...)
+	client := newWeaviateClient(cfg.Storage.WeaviateURL, cfg.Storage.WeaviateAPIKey)
+	s.stats = client.collectionStats
+	s.open = func(collectionName string, embedder embeddings.Embedder) (vectorstores.VectorStore, error) {
+		return &weaviateCollection{
+			client:   client,
+			name:     collectionName,
+			embedder: embedder,
+			logger:   logger,
+		}, nil
+	}
+	return s
+}
+
+// NewMemoryVectorStore creates a vector store that keeps embeddings in memory.
+// It is meant for tests and throwaway runs; nothing survives a restart.
+func NewMemoryVectorStore(cfg *config.Config, logger *slog.Logger, opts ...VectorStoreOption) VectorStore {
+	s := newVectorStore(cfg, logger, opts...)
+	data := newMemoryVectors()
+	s.stats = data.collectionStats
+	s.open = func(collectionName string, embedder embeddings.Embedder) (vectorstores.VectorStore, error) {
+		return &memoryCollection{data: data, name: collectionName, embedder: embedder}, nil
+	}
+	return s
+}
+
+// documentID returns the "id" metadata of doc, as Qdrant does, or a new
+// random ID.
+func documentID(doc schema.Document) string {
+	if id, ok := doc.Metadata["id"].(string); ok && id != "" {
+		return id
+	}
+	return rand.Text()
+}
+
+// filterValues returns the values a metadata field may take to match a filter
+// value: the value itself, any of a list, or any of {"$in": list}. It reports
+// false for values it cannot match against.
+func filterValues(value any) ([]string, bool) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, true
+	case int:
+		return []string{strconv.Itoa(v)}, true
+	case int64:
+		return []string{strconv.FormatInt(v, 10)}, true
+	case bool:
+		return []string{strconv.FormatBool(v)}, true
+	case []string:
+		return v, true
+	case []int:
+		values := make([]string, len(v))
+		for i, n := range v {
+			values[i] = strconv.Itoa(n)
+		}
+		return values, true
+	case []int64:
+		values := make([]string, len(v))
+		for i, n := range v {
+			values[i] = strconv.FormatInt(n, 10)
+		}
+		return values, true
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, elem := range v {
+			if elem != nil {
+				values = append(values, fmt.Sprint(elem))
+			}
+		}
+		return values, true
+	case map[string]any:
+		if in, ok := v["$in"]; ok && len(v) == 1 {
+			return filterValues(in)
+		}
+	}
+	return nil, false
+}
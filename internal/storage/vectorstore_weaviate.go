@@ -0,0 +1,563 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sevigo/goframe/embeddings"
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+)
+
+// weaviateBatchSize is how many documents are embedded and sent at once.
+const weaviateBatchSize = 64
+
+// weaviateClassPrefix starts the names of the classes that hold Code-Warden
+// collections, which tells them apart from other classes of a shared cluster.
+const weaviateClassPrefix = "CodeWarden_"
+
+// weaviateClassNameRegexp matches the characters Weaviate does not allow in
+// class names.
+var weaviateClassNameRegexp = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// weaviateClient talks to the REST and GraphQL API of a Weaviate cluster and
+// remembers which classes exist. It is shared by all collections of a store.
+type weaviateClient struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+
+	mu      sync.Mutex
+	classes map[string]bool
+}
+
+func newWeaviateClient(baseURL, apiKey string) *weaviateClient {
+	return &weaviateClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: 60 * time.Second},
+		classes: make(map[string]bool),
+	}
+}
+
+// weaviateStatusError is a non-2xx response of Weaviate.
+type weaviateStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *weaviateStatusError) Error() string {
+	return fmt.Sprintf("weaviate returned %d: %s", e.StatusCode, e.Body)
+}
+
+func isWeaviateStatus(err error, code int) bool {
+	var statusErr *weaviateStatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == code
+}
+
+// do sends a JSON request and decodes the response into out, if not nil.
+func (c *weaviateClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal weaviate request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create weaviate request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call weaviate %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &weaviateStatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode weaviate response: %w", err)
+	}
+	return nil
+}
+
+// classExists reports whether the class of a collection exists. Only existing
+// classes are remembered, so one created elsewhere is picked up later.
+func (c *weaviateClient) classExists(ctx context.Context, class string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.classExistsLocked(ctx, class)
+}
+
+func (c *weaviateClient) classExistsLocked(ctx context.Context, class string) (bool, error) {
+	if c.classes[class] {
+		return true, nil
+	}
+	err := c.do(ctx, http.MethodGet, "/v1/schema/"+url.PathEscape(class), nil, nil)
+	switch {
+	case isWeaviateStatus(err, http.StatusNotFound):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+	c.classes[class] = true
+	return true, nil
+}
+
+// ensureClass creates the class of a collection unless it exists. The class
+// keeps its vectors itself and stores the collection name as description.
+func (c *weaviateClient) ensureClass(ctx context.Context, class, collection string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ok, err := c.classExistsLocked(ctx, class); err != nil || ok {
+		return err
+	}
+
+	definition := map[string]any{
+		"class":             class,
+		"description":       collection,
+		"vectorizer":        "none",
+		"vectorIndexConfig": map[string]any{"distance": "cosine"},
+		"properties": []map[string]any{
+			{"name": "content", "dataType": []string{"text"}, "indexFilterable": false, "indexSearchable": false},
+			{"name": "metadata", "dataType": []string{"text"}, "indexFilterable": false, "indexSearchable": false},
+			{"name": "filters", "dataType": []string{"text[]"}, "tokenization": "field", "indexSearchable": false},
+		},
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/schema", definition, nil); err != nil {
+		// Another process may have created the class in the meantime.
+		if ok, existsErr := c.classExistsLocked(ctx, class); existsErr == nil && ok {
+			return nil
+		}
+		return fmt.Errorf("failed to create weaviate class %s: %w", class, err)
+	}
+	c.classes[class] = true
+	return nil
+}
+
+func (c *weaviateClient) forgetClass(class string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.classes, class)
+}
+
+// collectionStats counts the objects of the class of a collection. Weaviate
+// cannot group them by a metadata value, so there are no chunk type counts.
+func (c *weaviateClient) collectionStats(ctx context.Context, collectionName string) (*CollectionStats, error) {
+	class := weaviateClassName(collectionName)
+	if ok, err := c.classExists(ctx, class); err != nil || !ok {
+		return &CollectionStats{}, err
+	}
+	var resp struct {
+		Data struct {
+			Aggregate map[string][]struct {
+				Meta struct {
+					Count int `json:"count"`
+				} `json:"meta"`
+			} `json:"Aggregate"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	graphQL := fmt.Sprintf("{Aggregate{%s{meta{count}}}}", class)
+	if err := c.do(ctx, http.MethodPost, "/v1/graphql", map[string]string{"query": graphQL}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Errors) > 0 {
+		return nil, errors.New(resp.Errors[0].Message)
+	}
+	stats := &CollectionStats{}
+	if groups := resp.Data.Aggregate[class]; len(groups) > 0 {
+		stats.Documents = groups[0].Meta.Count
+	}
+	return stats, nil
+}
+
+// weaviateCollection is the client of one collection, kept in a Weaviate
+// class of its own. Metadata is stored as JSON, and its scalar values also
+// as "key=value" entries of the filters property, which filters match on.
+type weaviateCollection struct {
+	client   *weaviateClient
+	name     string
+	embedder embeddings.Embedder
+	logger   *slog.Logger
+}
+
+var _ vectorstores.VectorStore = (*weaviateCollection)(nil)
+
+func (w *weaviateCollection) collection(opts vectorstores.Options) string {
+	if opts.CollectionName != "" {
+		return opts.CollectionName
+	}
+	return w.name
+}
+
+// Health reports whether Weaviate is ready to serve requests.
+func (w *weaviateCollection) Health(ctx context.Context) error {
+	return w.client.do(ctx, http.MethodGet, "/v1/.well-known/ready", nil, nil)
+}
+
+func (w *weaviateCollection) AddDocuments(ctx context.Context, docs []schema.Document, options ...vectorstores.Option) ([]string, error) {
+	name := w.collection(vectorstores.ParseOptions(options...))
+	class := weaviateClassName(name)
+
+	valid := make([]schema.Document, 0, len(docs))
+	for _, doc := range docs {
+		if strings.TrimSpace(doc.PageContent) != "" {
+			valid = append(valid, doc)
+		}
+	}
+	if len(valid) == 0 {
+		return []string{}, nil
+	}
+	if err := w.client.ensureClass(ctx, class, name); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(valid))
+	for start := 0; start < len(valid); start += weaviateBatchSize {
+		batch := valid[start:min(start+weaviateBatchSize, len(valid))]
+		batchIDs, err := w.addBatch(ctx, name, class, batch)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, batchIDs...)
+	}
+	return ids, nil
+}
+
+func (w *weaviateCollection) addBatch(ctx context.Context, name, class string, docs []schema.Document) ([]string, error) {
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = strings.TrimSpace(doc.PageContent)
+	}
+	vectors, err := w.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed documents: %w", err)
+	}
+	if len(vectors) != len(docs) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d documents", len(vectors), len(docs))
+	}
+
+	ids := make([]string, len(docs))
+	objects := make([]map[string]any, len(docs))
+	for i, doc := range docs {
+		if doc.Metadata == nil {
+			doc.Metadata = map[string]any{}
+		}
+		metadata, err := json.Marshal(doc.Metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal document metadata: %w", err)
+		}
+		ids[i] = documentID(doc)
+		objects[i] = map[string]any{
+			"class":  class,
+			"id":     weaviateObjectID(name, ids[i]),
+			"vector": vectors[i],
+			"properties": map[string]any{
+				"content":  doc.PageContent,
+				"metadata": string(metadata),
+				"filters":  weaviateFilterEntries(doc.Metadata),
+			},
+		}
+	}
+
+	var results []struct {
+		Result struct {
+			Errors *struct {
+				Error []struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			} `json:"errors"`
+		} `json:"result"`
+	}
+	if err := w.client.do(ctx, http.MethodPost, "/v1/batch/objects", map[string]any{"objects": objects}, &results); err != nil {
+		return nil, fmt.Errorf("failed to add documents to %s: %w", name, err)
+	}
+	for _, r := range results {
+		if r.Result.Errors != nil && len(r.Result.Errors.Error) > 0 {
+			return nil, fmt.Errorf("failed to add documents to %s: %s", name, r.Result.Errors.Error[0].Message)
+		}
+	}
+	return ids, nil
+}
+
+func (w *weaviateCollection) SimilaritySearch(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]schema.Document, error) {
+	results, err := w.SimilaritySearchWithScores(ctx, query, numDocuments, options...)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]schema.Document, len(results))
+	for i, r := range results {
+		docs[i] = r.Document
+	}
+	return docs, nil
+}
+
+func (w *weaviateCollection) SimilaritySearchBatch(ctx context.Context, queries []string, numDocuments int, options ...vectorstores.Option) ([][]schema.Document, error) {
+	results := make([][]schema.Document, len(queries))
+	for i, query := range queries {
+		docs, err := w.SimilaritySearch(ctx, query, numDocuments, options...)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = docs
+	}
+	return results, nil
+}
+
+// SimilaritySearchWithScores ranks the documents by cosine distance to the
+// query with a nearVector GraphQL query. A collection that was never written
+// to has no class yet and no results.
+func (w *weaviateCollection) SimilaritySearchWithScores(ctx context.Context, query string, numDocuments int, options ...vectorstores.Option) ([]vectorstores.DocumentWithScore, error) {
+	opts := vectorstores.ParseOptions(options...)
+	name := w.collection(opts)
+	class := weaviateClassName(name)
+
+	conditions, matchable := weaviateConditions(opts.Filters)
+	if !matchable {
+		return nil, nil
+	}
+	if ok, err := w.client.classExists(ctx, class); err != nil || !ok {
+		return nil, err
+	}
+
+	vector, err := w.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	var near strings.Builder
+	fmt.Fprintf(&near, "vector: %s", formatVector(vector))
+	if opts.ScoreThreshold > 0 {
+		fmt.Fprintf(&near, ", distance: %g", 1-opts.ScoreThreshold)
+	}
+	args := fmt.Sprintf("nearVector: {%s}, limit: %d", near.String(), numDocuments)
+	if where := weaviateWhereGraphQL(conditions); where != "" {
+		args += ", where: " + where
+	}
+	graphQL := fmt.Sprintf("{Get{%s(%s){content metadata _additional{distance}}}}", class, args)
+
+	var resp struct {
+		Data struct {
+			Get map[string][]struct {
+				Content    string `json:"content"`
+				Metadata   string `json:"metadata"`
+				Additional struct {
+					Distance float64 `json:"distance"`
+				} `json:"_additional"`
+			} `json:"Get"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := w.client.do(ctx, http.MethodPost, "/v1/graphql", map[string]string{"query": graphQL}, &resp); err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", name, err)
+	}
+	if len(resp.Errors) > 0 {
+		return nil, fmt.Errorf("failed to search %s: %s", name, resp.Errors[0].Message)
+	}
+
+	objects := resp.Data.Get[class]
+	results := make([]vectorstores.DocumentWithScore, 0, len(objects))
+	for _, obj := range objects {
+		doc := schema.Document{PageContent: obj.Content}
+		if doc.Metadata, err = decodeMetadata([]byte(obj.Metadata)); err != nil {
+			w.logger.WarnContext(ctx, "failed to decode document metadata", "collection", name, "error", err)
+		}
+		results = append(results, vectorstores.DocumentWithScore{Document: doc, Score: float32(1 - obj.Additional.Distance)})
+	}
+	return results, nil
+}
+
+// ListCollections returns the collections with a class in Weaviate.
+func (w *weaviateCollection) ListCollections(ctx context.Context) ([]string, error) {
+	var resp struct {
+		Classes []struct {
+			Class       string `json:"class"`
+			Description string `json:"description"`
+		} `json:"classes"`
+	}
+	if err := w.client.do(ctx, http.MethodGet, "/v1/schema", nil, &resp); err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+	var names []string
+	for _, c := range resp.Classes {
+		if c.Description != "" && c.Class == weaviateClassName(c.Description) {
+			names = append(names, c.Description)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (w *weaviateCollection) DeleteCollection(ctx context.Context, collectionName string) error {
+	if collectionName == "" {
+		collectionName = w.name
+	}
+	class := weaviateClassName(collectionName)
+	err := w.client.do(ctx, http.MethodDelete, "/v1/schema/"+url.PathEscape(class), nil, nil)
+	if err != nil && !isWeaviateStatus(err, http.StatusNotFound) {
+		return fmt.Errorf("failed to delete collection %s: %w", collectionName, err)
+	}
+	w.client.forgetClass(class)
+	return nil
+}
+
+// DeleteDocumentsByFilter deletes the matching objects in batches; Weaviate
+// deletes at most its query limit of objects per request.
+func (w *weaviateCollection) DeleteDocumentsByFilter(ctx context.Context, filters map[string]any, options ...vectorstores.Option) error {
+	name := w.collection(vectorstores.ParseOptions(options...))
+	class := weaviateClassName(name)
+
+	conditions, matchable := weaviateConditions(filters)
+	if matchable && len(conditions) == 0 {
+		return fmt.Errorf("cannot delete with an empty filter")
+	}
+	if !matchable {
+		return nil
+	}
+	if ok, err := w.client.classExists(ctx, class); err != nil || !ok {
+		return err
+	}
+
+	request := map[string]any{
+		"match":  map[string]any{"class": class, "where": weaviateWhereJSON(conditions)},
+		"output": "minimal",
+	}
+	for {
+		var resp struct {
+			Results struct {
+				Matches    int `json:"matches"`
+				Limit      int `json:"limit"`
+				Successful int `json:"successful"`
+				Failed     int `json:"failed"`
+			} `json:"results"`
+		}
+		if err := w.client.do(ctx, http.MethodDelete, "/v1/batch/objects", request, &resp); err != nil {
+			return fmt.Errorf("failed to delete documents from %s: %w", name, err)
+		}
+		if resp.Results.Failed > 0 {
+			return fmt.Errorf("failed to delete %d documents from %s", resp.Results.Failed, name)
+		}
+		if resp.Results.Successful == 0 || resp.Results.Matches < resp.Results.Limit {
+			return nil
+		}
+	}
+}
+
+// weaviateClassName maps a collection name to a valid class name.
+func weaviateClassName(collection string) string {
+	return weaviateClassPrefix + weaviateClassNameRegexp.ReplaceAllString(collection, "_")
+}
+
+// weaviateObjectID derives the UUID Weaviate requires from a document ID, so
+// re-adding a document replaces it. It is a version 8 (custom) UUID.
+func weaviateObjectID(collection, id string) string {
+	sum := sha256.Sum256([]byte(collection + "\x00" + id))
+	sum[6] = (sum[6] & 0x0f) | 0x80
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// weaviateFilterEntries renders the metadata values filters can match as
+// "key=value" entries, in the form filterValues produces.
+func weaviateFilterEntries(metadata map[string]any) []string {
+	entries := []string{}
+	for key, value := range metadata {
+		values, _ := filterValues(value)
+		for _, v := range values {
+			entries = append(entries, key+"="+v)
+		}
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+// weaviateConditions turns filters into the entries of which an object has to
+// contain at least one per filter. It reports false when a filter can match
+// nothing; filters of unsupported types are ignored, as Qdrant does.
+func weaviateConditions(filters map[string]any) ([][]string, bool) {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var conditions [][]string
+	for _, key := range keys {
+		values, ok := filterValues(filters[key])
+		if !ok {
+			continue
+		}
+		if len(values) == 0 {
+			return nil, false
+		}
+		entries := make([]string, len(values))
+		for i, v := range values {
+			entries[i] = key + "=" + v
+		}
+		conditions = append(conditions, entries)
+	}
+	return conditions, true
+}
+
+// weaviateWhereJSON renders conditions as a where filter of the REST API.
+func weaviateWhereJSON(conditions [][]string) map[string]any {
+	operands := make([]map[string]any, len(conditions))
+	for i, entries := range conditions {
+		operands[i] = map[string]any{"path": []string{"filters"}, "operator": "ContainsAny", "valueTextArray": entries}
+	}
+	if len(operands) == 1 {
+		return operands[0]
+	}
+	return map[string]any{"operator": "And", "operands": operands}
+}
+
+// weaviateWhereGraphQL renders conditions as a where argument of a GraphQL
+// query, or "" without conditions. JSON string literals are valid GraphQL
+// strings.
+func weaviateWhereGraphQL(conditions [][]string) string {
+	operands := make([]string, len(conditions))
+	for i, entries := range conditions {
+		values, _ := json.Marshal(entries)
+		operands[i] = fmt.Sprintf(`{path: ["filters"], operator: ContainsAny, valueText: %s}`, values)
+	}
+	switch len(operands) {
+	case 0:
+		return ""
+	case 1:
+		return operands[0]
+	default:
+		return fmt.Sprintf("{operator: And, operands: [%s]}", strings.Join(operands, ", "))
+	}
+}
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sevigo/goframe/schema"
+	"github.com/sevigo/goframe/vectorstores"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+)
+
+// fakeWeaviate records the requests of a weaviateCollection and answers them
+// the way Weaviate does.
+type fakeWeaviate struct {
+	mu      sync.Mutex
+	classes map[string]map[string]any
+	objects []map[string]any
+	queries []string
+	deletes []map[string]any
+	auth    string
+}
+
+func (f *fakeWeaviate) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = r.Header.Get("Authorization")
+
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	switch {
+	case r.Method == http.MethodGet && len(r.URL.Path) > len("/v1/schema/"):
+		if _, ok := f.classes[r.URL.Path[len("/v1/schema/"):]]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/schema":
+		f.classes[body["class"].(string)] = body
+		_, _ = w.Write([]byte(`{}`))
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/batch/objects":
+		var results []map[string]any
+		for _, obj := range body["objects"].([]any) {
+			f.objects = append(f.objects, obj.(map[string]any))
+			results = append(results, map[string]any{"result": map[string]any{}})
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/graphql":
+		f.queries = append(f.queries, body["query"].(string))
+		_, _ = w.Write([]byte(`{"data":{"Get":{"CodeWarden_repo_owner_app":[
+			{"content":"alpha beta","metadata":"{\"source\":\"c.go\",\"line\":7}","_additional":{"distance":0.25}}]}}}`))
+	case r.Method == http.MethodDelete && r.URL.Path == "/v1/batch/objects":
+		f.deletes = append(f.deletes, body)
+		_, _ = w.Write([]byte(`{"results":{"matches":2,"limit":10000,"successful":2,"failed":0}}`))
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestNewVectorStore_Weaviate(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeWeaviate{classes: make(map[string]map[string]any)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	cfg := &config.Config{Storage: config.StorageConfig{
+		VectorStoreProvider: config.VectorStoreWeaviate,
+		WeaviateURL:         server.URL + "/",
+		WeaviateAPIKey:      "secret",
+	}}
+	store, err := NewVectorStore(cfg, nil, slog.Default(), WithInitialEmbedder("words", wordEmbedder{}))
+	require.NoError(t, err)
+
+	// A collection that was never written to has nothing to search.
+	results, err := store.SearchCollection(ctx, "repo-owner-app", "words", "alpha", 3)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Empty(t, fake.queries)
+
+	docs := []schema.Document{
+		{PageContent: "alpha alpha", Metadata: map[string]any{"id": "a", "source": "a.go", "chunk_type": "code"}},
+		{PageContent: "alpha beta", Metadata: map[string]any{"id": "c", "source": "c.go", "chunk_type": "definition", "line": 7}},
+		{PageContent: " "},
+	}
+	require.NoError(t, store.AddDocumentsToCollection(ctx, "repo-owner-app", "words", docs, func(int, int, time.Duration) {}))
+
+	require.Contains(t, fake.classes, "CodeWarden_repo_owner_app")
+	assert.Equal(t, "repo-owner-app", fake.classes["CodeWarden_repo_owner_app"]["description"])
+	assert.Equal(t, "Bearer secret", fake.auth)
+	require.Len(t, fake.objects, 2)
+	assert.Equal(t, weaviateObjectID("repo-owner-app", "c"), fake.objects[1]["id"])
+	assert.Equal(t, []any{float64(2), float64(0), float64(0)}, fake.objects[0]["vector"])
+	properties := fake.objects[1]["properties"].(map[string]any)
+	assert.Equal(t, []any{"chunk_type=definition", "id=c", "line=7", "source=c.go"}, properties["filters"])
+
+	scored, err := store.ForRepo("repo-owner-app", "words").SimilaritySearchWithScores(ctx, "alpha", 5,
+		vectorstores.WithFilters(map[string]any{"chunk_type": "definition"}), vectorstores.WithScoreThreshold(0.5))
+	require.NoError(t, err)
+	require.Len(t, fake.queries, 1)
+	assert.Equal(t, `{Get{CodeWarden_repo_owner_app(nearVector: {vector: [1,0,0], distance: 0.5}, limit: 5, `+
+		`where: {path: ["filters"], operator: ContainsAny, valueText: ["chunk_type=definition"]}){content metadata _additional{distance}}}}`,
+		fake.queries[0])
+	require.Len(t, scored, 1)
+	assert.Equal(t, "c.go", scored[0].Document.Metadata["source"])
+	assert.Equal(t, int64(7), scored[0].Document.Metadata["line"])
+	assert.InDelta(t, 0.75, scored[0].Score, 1e-6)
+
+	require.NoError(t, store.DeleteDocumentsFromCollection(ctx, "repo-owner-app", "words", []string{"a.go", "c.go"}))
+	require.Len(t, fake.deletes, 1)
+	assert.Equal(t, map[string]any{
+		"class": "CodeWarden_repo_owner_app",
+		"where": map[string]any{"path": []any{"filters"}, "operator": "ContainsAny", "valueTextArray": []any{"source=a.go", "source=c.go"}},
+	}, fake.deletes[0]["match"])
+	assert.Error(t, store.DeleteDocumentsFromCollectionByFilter(ctx, "repo-owner-app", "words", map[string]any{}))
+}
+
+func TestWeaviateWhereGraphQL(t *testing.T) {
+	conditions, ok := weaviateConditions(map[string]any{
+		"source":     map[string]any{"$in": []string{"a.go", `b "quoted".go`}},
+		"chunk_type": "definition",
+		"unknown":    3.5,
+	})
+	require.True(t, ok)
+	assert.Equal(t, `{operator: And, operands: [`+
+		`{path: ["filters"], operator: ContainsAny, valueText: ["chunk_type=definition"]}, `+
+		`{path: ["filters"], operator: ContainsAny, valueText: ["source=a.go","source=b \"quoted\".go"]}]}`,
+		weaviateWhereGraphQL(conditions))
+
+	_, ok = weaviateConditions(map[string]any{"source": []string{}})
+	assert.False(t, ok)
+}
+
+func TestWeaviateNames(t *testing.T) {
+	assert.Equal(t, "CodeWarden_repo_owner_my_app_v2", weaviateClassName("repo-owner-my.app_v2"))
+	id := weaviateObjectID("repo", "a.go")
+	assert.Regexp(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-8[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`), id)
+	assert.Equal(t, id, weaviateObjectID("repo", "a.go"))
+	assert.NotEqual(t, id, weaviateObjectID("other", "a.go"))
+}
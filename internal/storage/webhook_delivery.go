@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WebhookDeliveryStore remembers the GitHub webhook deliveries that were
+// accepted, by their X-GitHub-Delivery ID, so replays of them can be
+// rejected. It is a sub-interface implemented by postgresStore.
+type WebhookDeliveryStore interface {
+	// ClaimWebhookDelivery records a delivery ID and reports false when it
+	// was already recorded at or after notBefore.
+	ClaimWebhookDelivery(ctx context.Context, id string, notBefore time.Time) (bool, error)
+	// ReleaseWebhookDelivery forgets a delivery ID, so the delivery is
+	// accepted again.
+	ReleaseWebhookDelivery(ctx context.Context, id string) error
+	// DeleteWebhookDeliveriesBefore forgets the deliveries received before
+	// cutoff and returns how many it forgot.
+	DeleteWebhookDeliveriesBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// ClaimWebhookDelivery inserts a webhook_deliveries row, or renews a row
+// older than notBefore.
+func (s *postgresStore) ClaimWebhookDelivery(ctx context.Context, id string, notBefore time.Time) (bool, error) {
+	query := `
+		INSERT INTO webhook_deliveries (delivery_id) VALUES ($1)
+		ON CONFLICT (delivery_id) DO UPDATE SET received_at = NOW()
+		WHERE webhook_deliveries.received_at < $2`
+
+	res, err := s.db.ExecContext(ctx, query, id, notBefore)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery %s: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook delivery %s: %w", id, err)
+	}
+	return n > 0, nil
+}
+
+// ReleaseWebhookDelivery deletes a webhook_deliveries row.
+func (s *postgresStore) ReleaseWebhookDelivery(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE delivery_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to release webhook delivery %s: %w", id, err)
+	}
+	return nil
+}
+
+// DeleteWebhookDeliveriesBefore deletes the webhook_deliveries rows received
+// before cutoff.
+func (s *postgresStore) DeleteWebhookDeliveriesBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM webhook_deliveries WHERE received_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+	return res.RowsAffected()
+}
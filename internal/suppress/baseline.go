@@ -0,0 +1,128 @@
+package suppress
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/cryptoutil"
+)
+
+// BaselineFile is the name of the baseline file at the repository root.
+const BaselineFile = ".code-warden-baseline.json"
+
+const baselineVersion = 1
+
+// Baseline lists findings a repository has accepted. Matching findings are
+// left out of reviews, so adopting Code-Warden on an existing codebase does
+// not flood every pull request with known issues.
+type Baseline struct {
+	Version  int             `json:"version"`
+	Findings []BaselineEntry `json:"findings"`
+}
+
+// BaselineEntry is an accepted finding. Only the hash is used for matching;
+// the other fields make the file readable in review.
+type BaselineEntry struct {
+	Hash     string `json:"hash"`
+	File     string `json:"file"`
+	Category string `json:"category"`
+	Comment  string `json:"comment"`
+}
+
+// Fingerprint identifies a finding by its file, category and comment. Line
+// numbers are left out so that accepted findings stay matched when code
+// above them moves.
+func Fingerprint(s core.Suggestion) string {
+	comment := strings.ToLower(strings.Join(strings.Fields(s.Comment), " "))
+	return cryptoutil.HashString(s.FilePath + "\x00" + normalizeCategory(s.Category) + "\x00" + comment)
+}
+
+// NewBaseline accepts the given findings, sorted by file and without duplicates.
+func NewBaseline(suggestions []core.Suggestion) *Baseline {
+	b := &Baseline{Version: baselineVersion, Findings: []BaselineEntry{}}
+	seen := make(map[string]bool, len(suggestions))
+	for _, s := range suggestions {
+		hash := Fingerprint(s)
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+		b.Findings = append(b.Findings, BaselineEntry{
+			Hash:     hash,
+			File:     s.FilePath,
+			Category: s.Category,
+			Comment:  s.Comment,
+		})
+	}
+	slices.SortFunc(b.Findings, func(x, y BaselineEntry) int {
+		return cmp.Or(cmp.Compare(x.File, y.File), cmp.Compare(x.Hash, y.Hash))
+	})
+	return b
+}
+
+// LoadBaseline reads the baseline file of the repository at repoPath. It
+// returns nil without an error when the repository has no baseline.
+func LoadBaseline(repoPath string) (*Baseline, error) {
+	data, err := os.ReadFile(filepath.Join(repoPath, BaselineFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", BaselineFile, err)
+	}
+	var b Baseline
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", BaselineFile, err)
+	}
+	return &b, nil
+}
+
+// WriteFile writes the baseline as indented JSON to path.
+func (b *Baseline) WriteFile(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil { //nolint:gosec // G306: the baseline is committed with the source
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Contains reports whether s is an accepted finding. A nil baseline
+// contains nothing.
+func (b *Baseline) Contains(s core.Suggestion) bool {
+	if b == nil || len(b.Findings) == 0 {
+		return false
+	}
+	hash := Fingerprint(s)
+	return slices.ContainsFunc(b.Findings, func(e BaselineEntry) bool { return e.Hash == hash })
+}
+
+// Apply removes the findings of review that are silenced inline or accepted
+// in the baseline and returns how many were removed for each reason.
+func Apply(review *core.StructuredReview, inline Inline, baseline *Baseline) (suppressed, baselined int) {
+	if review == nil {
+		return 0, 0
+	}
+	kept := review.Suggestions[:0]
+	for _, s := range review.Suggestions {
+		switch {
+		case inline.Suppresses(s):
+			suppressed++
+		case baseline.Contains(s):
+			baselined++
+		default:
+			kept = append(kept, s)
+		}
+	}
+	review.Suggestions = kept
+	return suppressed, baselined
+}
@@ -0,0 +1,68 @@
+package suppress
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+func TestFingerprint(t *testing.T) {
+	s := core.Suggestion{FilePath: "a.go", LineNumber: 10, Category: "Best Practice", Comment: "Handle the  error\nreturned here."}
+
+	moved := s
+	moved.LineNumber = 42
+	moved.Category = "best_practice"
+	moved.Comment = "handle the error returned here."
+	assert.Equal(t, Fingerprint(s), Fingerprint(moved), "line, case and whitespace do not matter")
+
+	otherFile := s
+	otherFile.FilePath = "b.go"
+	assert.NotEqual(t, Fingerprint(s), Fingerprint(otherFile))
+}
+
+func TestBaseline_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	suggestions := []core.Suggestion{
+		{FilePath: "z.go", LineNumber: 3, Category: "Bug", Comment: "nil dereference"},
+		{FilePath: "a.go", LineNumber: 1, Category: "Style", Comment: "long line"},
+		{FilePath: "a.go", LineNumber: 9, Category: "Style", Comment: "long line"},
+	}
+
+	b := NewBaseline(suggestions)
+	require.Len(t, b.Findings, 2, "duplicates are dropped")
+	assert.Equal(t, "a.go", b.Findings[0].File)
+	require.NoError(t, b.WriteFile(filepath.Join(dir, BaselineFile)))
+
+	loaded, err := LoadBaseline(dir)
+	require.NoError(t, err)
+	assert.Equal(t, b, loaded)
+	assert.True(t, loaded.Contains(suggestions[0]))
+	assert.False(t, loaded.Contains(core.Suggestion{FilePath: "z.go", Category: "Bug", Comment: "race"}))
+}
+
+func TestLoadBaseline_Missing(t *testing.T) {
+	b, err := LoadBaseline(t.TempDir())
+	require.NoError(t, err)
+	assert.Nil(t, b)
+	assert.False(t, b.Contains(core.Suggestion{FilePath: "a.go"}))
+}
+
+func TestApply(t *testing.T) {
+	accepted := core.Suggestion{FilePath: "auth.go", LineNumber: 40, Category: "Bug", Comment: "known issue"}
+	review := &core.StructuredReview{Suggestions: []core.Suggestion{
+		{FilePath: "auth.go", LineNumber: 12, Category: "Security", Comment: "hardcoded token"},
+		accepted,
+		{FilePath: "auth.go", LineNumber: 50, Category: "Bug", Comment: "new issue"},
+	}}
+
+	suppressed, baselined := Apply(review, ParseDiff(testDiff), NewBaseline([]core.Suggestion{accepted}))
+
+	assert.Equal(t, 1, suppressed)
+	assert.Equal(t, 1, baselined)
+	require.Len(t, review.Suggestions, 1)
+	assert.Equal(t, "new issue", review.Suggestions[0].Comment)
+}
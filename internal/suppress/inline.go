@@ -0,0 +1,127 @@
+// Package suppress filters review findings that a repository has silenced,
+// either inline with a "warden:ignore" comment next to the flagged code or by
+// accepting them in a baseline file committed to the repository.
+package suppress
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+// Marker starts a suppression comment, e.g. "// warden:ignore security". It
+// silences findings on its own line and the line below. Without categories
+// it silences every finding there; several categories are comma-separated.
+const Marker = "warden:ignore"
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// Inline holds the suppression comments on the new side of a diff by file
+// and line. A line maps to its normalized categories, or to an empty slice
+// when it silences all categories.
+type Inline map[string]map[int][]string
+
+// ParseDiff collects the suppression comments of the added and context lines
+// of a unified diff. Only code visible in the diff is considered, so a
+// comment must be within the changed hunks to take effect.
+func ParseDiff(diff string) Inline {
+	inline := make(Inline)
+	file := ""
+	line := -1
+	for text := range strings.Lines(diff) {
+		text = strings.TrimSuffix(text, "\n")
+		switch {
+		case strings.HasPrefix(text, "+++ "):
+			file = strings.TrimPrefix(strings.TrimPrefix(text, "+++ "), "b/")
+			line = -1
+		case strings.HasPrefix(text, "@@"):
+			line = -1
+			if m := hunkHeaderRegex.FindStringSubmatch(text); m != nil {
+				line, _ = strconv.Atoi(m[1])
+			}
+		case line < 0 || file == "":
+			continue
+		case strings.HasPrefix(text, "+"), strings.HasPrefix(text, " "):
+			if categories, ok := parseMarker(text[1:]); ok {
+				if inline[file] == nil {
+					inline[file] = make(map[int][]string)
+				}
+				inline[file][line] = categories
+			}
+			line++
+		}
+	}
+	return inline
+}
+
+// FromChangedFiles collects the suppression comments of GitHub file patches.
+func FromChangedFiles(files []internalgithub.ChangedFile) Inline {
+	var diff strings.Builder
+	for _, f := range files {
+		diff.WriteString("+++ b/" + f.Filename + "\n")
+		diff.WriteString(f.Patch)
+		if !strings.HasSuffix(f.Patch, "\n") {
+			diff.WriteString("\n")
+		}
+	}
+	return ParseDiff(diff.String())
+}
+
+// Suppresses reports whether a suppression comment covers s: one on any line
+// the suggestion spans or on the line directly above it, matching its
+// category.
+func (in Inline) Suppresses(s core.Suggestion) bool {
+	lines := in[s.FilePath]
+	if len(lines) == 0 {
+		return false
+	}
+	first := s.LineNumber
+	if s.StartLine > 0 && s.StartLine < first {
+		first = s.StartLine
+	}
+	category := normalizeCategory(s.Category)
+	for line := first - 1; line <= s.LineNumber; line++ {
+		categories, ok := lines[line]
+		if ok && (len(categories) == 0 || slices.Contains(categories, category)) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseMarker returns the normalized categories of the suppression comment
+// in a source line, if it has one.
+func parseMarker(text string) ([]string, bool) {
+	_, rest, ok := strings.Cut(text, Marker)
+	if !ok || (rest != "" && !unicode.IsSpace(rune(rest[0]))) {
+		return nil, false
+	}
+	// Drop the closing delimiter of block comments, e.g. "/* ... */".
+	for _, end := range []string{"*/", "-->", "#}"} {
+		rest, _, _ = strings.Cut(rest, end)
+	}
+
+	categories := []string{}
+	for c := range strings.SplitSeq(rest, ",") {
+		if c = normalizeCategory(c); c != "" {
+			categories = append(categories, c)
+		}
+	}
+	return categories, true
+}
+
+// normalizeCategory folds case and drops separators, so "Best Practice",
+// "best-practice" and "best_practice" are the same category.
+func normalizeCategory(category string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, category)
+}
@@ -0,0 +1,83 @@
+package suppress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/sevigo/code-warden/internal/core"
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+const testDiff = `diff --git a/auth.go b/auth.go
+--- a/auth.go
++++ b/auth.go
+@@ -10,4 +10,6 @@ func Login() {
+ 	user := lookup()
++	// warden:ignore security, Best Practice
++	token := "dev-token"
+ 	check(user)
++	run() // warden:ignore
+ }
+diff --git a/db.sql b/db.sql
+--- a/db.sql
++++ b/db.sql
+@@ -1,0 +1,2 @@
++/* warden:ignore style */
++SELECT * FROM users;
+`
+
+func TestParseDiff(t *testing.T) {
+	inline := ParseDiff(testDiff)
+
+	assert.Equal(t, Inline{
+		"auth.go": {11: {"security", "bestpractice"}, 14: {}},
+		"db.sql":  {1: {"style"}},
+	}, inline)
+}
+
+func TestParseDiff_IgnoresRemovedLinesAndLookalikes(t *testing.T) {
+	diff := `+++ b/main.go
+@@ -1,3 +1,2 @@
+-// warden:ignore
++// warden:ignored is not a marker
+ x := 1
+`
+	assert.Empty(t, ParseDiff(diff))
+}
+
+func TestFromChangedFiles(t *testing.T) {
+	inline := FromChangedFiles([]internalgithub.ChangedFile{
+		{Filename: "a.go", Patch: "@@ -1,1 +1,2 @@\n x()\n+y() // warden:ignore bug"},
+		{Filename: "b.go", Patch: "@@ -5,1 +5,1 @@\n+z() // warden:ignore\n"},
+	})
+
+	assert.Equal(t, Inline{
+		"a.go": {2: {"bug"}},
+		"b.go": {5: {}},
+	}, inline)
+}
+
+func TestInline_Suppresses(t *testing.T) {
+	inline := ParseDiff(testDiff)
+
+	tests := []struct {
+		name string
+		s    core.Suggestion
+		want bool
+	}{
+		{"line below marker", core.Suggestion{FilePath: "auth.go", LineNumber: 12, Category: "Security"}, true},
+		{"category spelled differently", core.Suggestion{FilePath: "auth.go", LineNumber: 12, Category: "best-practice"}, true},
+		{"other category", core.Suggestion{FilePath: "auth.go", LineNumber: 12, Category: "Bug"}, false},
+		{"marker without categories", core.Suggestion{FilePath: "auth.go", LineNumber: 14, Category: "Bug"}, true},
+		{"multi-line span covers marker", core.Suggestion{FilePath: "auth.go", StartLine: 12, LineNumber: 15, Category: "Bug"}, true},
+		{"too far below marker", core.Suggestion{FilePath: "auth.go", LineNumber: 13, Category: "Security"}, false},
+		{"block comment", core.Suggestion{FilePath: "db.sql", LineNumber: 2, Category: "Style"}, true},
+		{"other file", core.Suggestion{FilePath: "main.go", LineNumber: 12, Category: "Security"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, inline.Suppresses(tt.s))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// Package testgap finds changed production files whose tests were not
+// changed with them, using per-language naming conventions to pair a source
+// file with its test file.
+package testgap
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+// Gap statuses.
+const (
+	// StatusMissing means no test file exists for the changed file.
+	StatusMissing = "missing"
+	// StatusNotUpdated means a test file exists but the PR does not change it.
+	StatusNotUpdated = "not_updated"
+)
+
+// Gap is a changed production file without a changed test file.
+type Gap struct {
+	// File is the changed production file.
+	File string
+	// TestFile is the existing test file, or the conventional name of a new
+	// one when Status is StatusMissing.
+	TestFile string
+	Status   string
+	// Patch is the diff of File.
+	Patch string
+}
+
+// Find returns the gaps among changedFiles. exists reports whether a path,
+// relative to the repository root, exists in the repository; it is used to
+// tell missing tests from tests that were not updated. Test files, files
+// whose change only removes lines and files of languages without a known test
+// convention are skipped.
+func Find(changedFiles []internalgithub.ChangedFile, exists func(string) bool) []Gap {
+	changed := make(map[string]bool, len(changedFiles))
+	for _, f := range changedFiles {
+		changed[f.Filename] = true
+	}
+
+	var gaps []Gap
+	for _, f := range changedFiles {
+		if !addsLines(f.Patch) || IsTestFile(f.Filename) {
+			continue
+		}
+		candidates := TestFilesFor(f.Filename)
+		if len(candidates) == 0 || slices.ContainsFunc(candidates, func(c string) bool { return changed[c] }) {
+			continue
+		}
+		gap := Gap{File: f.Filename, TestFile: candidates[0], Status: StatusMissing, Patch: f.Patch}
+		if i := slices.IndexFunc(candidates, exists); i >= 0 {
+			gap.TestFile = candidates[i]
+			gap.Status = StatusNotUpdated
+		}
+		gaps = append(gaps, gap)
+	}
+	return gaps
+}
+
+// IsTestFile reports whether file is a test file by the conventions of its
+// language.
+func IsTestFile(file string) bool {
+	base := path.Base(file)
+	stem, ext := splitExt(base)
+	switch ext {
+	case ".go":
+		return strings.HasSuffix(stem, "_test")
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs":
+		return strings.HasSuffix(stem, ".test") || strings.HasSuffix(stem, ".spec") ||
+			slices.Contains(strings.Split(path.Dir(file), "/"), "__tests__")
+	case ".py":
+		return strings.HasPrefix(stem, "test_") || strings.HasSuffix(stem, "_test") || stem == "conftest"
+	case ".java", ".kt":
+		return strings.HasSuffix(stem, "Test") || strings.HasSuffix(stem, "Tests") ||
+			strings.Contains("/"+file, "/src/test/")
+	case ".rb":
+		return strings.HasSuffix(stem, "_spec") || strings.HasSuffix(stem, "_test")
+	default:
+		return false
+	}
+}
+
+// TestFilesFor returns the conventional test files of a source file, the
+// most common convention first. It returns nil for languages without a known
+// convention and for files that need no tests of their own, such as Python
+// package markers and TypeScript declarations.
+func TestFilesFor(file string) []string {
+	dir, base := path.Split(file)
+	stem, ext := splitExt(base)
+	switch ext {
+	case ".go":
+		return []string{dir + stem + "_test.go"}
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs":
+		if strings.HasSuffix(stem, ".d") || stem == "index" {
+			return nil
+		}
+		return []string{
+			dir + stem + ".test" + ext,
+			dir + stem + ".spec" + ext,
+			dir + "__tests__/" + stem + ".test" + ext,
+		}
+	case ".py":
+		if stem == "__init__" || stem == "__main__" {
+			return nil
+		}
+		return []string{
+			dir + "test_" + stem + ".py",
+			dir + stem + "_test.py",
+			dir + "tests/test_" + stem + ".py",
+			"tests/test_" + stem + ".py",
+		}
+	case ".java", ".kt":
+		candidates := []string{dir + stem + "Test" + ext}
+		if strings.Contains("/"+dir, "/src/main/") {
+			testDir := strings.Replace("/"+dir, "/src/main/", "/src/test/", 1)[1:]
+			candidates = append([]string{testDir + stem + "Test" + ext}, candidates...)
+		}
+		return candidates
+	case ".rb":
+		return []string{"spec/" + strings.TrimPrefix(dir, "lib/") + stem + "_spec.rb", "test/" + strings.TrimPrefix(dir, "lib/") + stem + "_test.rb"}
+	default:
+		return nil
+	}
+}
+
+// addsLines reports whether a patch adds at least one line.
+func addsLines(patch string) bool {
+	for line := range strings.SplitSeq(patch, "\n") {
+		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
+			return true
+		}
+	}
+	return false
+}
+
+func splitExt(base string) (stem, ext string) {
+	ext = path.Ext(base)
+	return strings.TrimSuffix(base, ext), strings.ToLower(ext)
+}
@@ -0,0 +1,77 @@
+package testgap
+
+import (
+	"reflect"
+	"testing"
+
+	internalgithub "github.com/sevigo/code-warden/internal/github"
+)
+
+func TestIsTestFile(t *testing.T) {
+	tests := map[string]bool{
+		"internal/api/page_test.go":               true,
+		"internal/api/page.go":                    false,
+		"web/src/list.spec.ts":                    true,
+		"web/src/list.test.tsx":                   true,
+		"web/src/__tests__/list.ts":               true,
+		"web/src/list.ts":                         false,
+		"app/test_models.py":                      true,
+		"app/models_test.py":                      true,
+		"app/conftest.py":                         true,
+		"app/models.py":                           false,
+		"src/test/java/com/acme/Widget.java":      true,
+		"src/main/java/com/acme/WidgetTests.java": true,
+		"src/main/java/com/acme/Widget.java":      false,
+		"spec/models/user_spec.rb":                true,
+		"README.md":                               false,
+	}
+	for file, want := range tests {
+		if got := IsTestFile(file); got != want {
+			t.Errorf("IsTestFile(%q) = %v, want %v", file, got, want)
+		}
+	}
+}
+
+func TestTestFilesFor(t *testing.T) {
+	tests := []struct {
+		file string
+		want []string
+	}{
+		{"internal/api/page.go", []string{"internal/api/page_test.go"}},
+		{"web/src/list.ts", []string{"web/src/list.test.ts", "web/src/list.spec.ts", "web/src/__tests__/list.test.ts"}},
+		{"web/src/index.ts", nil},
+		{"web/src/types.d.ts", nil},
+		{"app/models.py", []string{"app/test_models.py", "app/models_test.py", "app/tests/test_models.py", "tests/test_models.py"}},
+		{"app/__init__.py", nil},
+		{"src/main/java/com/acme/Widget.java", []string{"src/test/java/com/acme/WidgetTest.java", "src/main/java/com/acme/WidgetTest.java"}},
+		{"lib/models/user.rb", []string{"spec/models/user_spec.rb", "test/models/user_test.rb"}},
+		{"docs/guide.md", nil},
+	}
+	for _, tt := range tests {
+		if got := TestFilesFor(tt.file); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("TestFilesFor(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	changedFiles := []internalgithub.ChangedFile{
+		{Filename: "internal/api/page.go", Patch: "+a"},
+		{Filename: "internal/api/list.go", Patch: "+b"},
+		{Filename: "internal/api/list_test.go", Patch: "+c"},
+		{Filename: "app/models.py", Patch: "+d"},
+		{Filename: "internal/api/old.go", Patch: "-e"},
+		{Filename: "internal/api/binary.go"},
+		{Filename: "README.md", Patch: "+f"},
+	}
+	existing := map[string]bool{"tests/test_models.py": true}
+
+	got := Find(changedFiles, func(p string) bool { return existing[p] })
+	want := []Gap{
+		{File: "internal/api/page.go", TestFile: "internal/api/page_test.go", Status: StatusMissing, Patch: "+a"},
+		{File: "app/models.py", TestFile: "tests/test_models.py", Status: StatusNotUpdated, Patch: "+d"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Find() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,222 @@
+// Package timetravel answers questions about a repository as it was at an
+// earlier commit, branch or tag. The tree at that commit is checked out into
+// a temporary worktree and indexed on demand into a snapshot collection next
+// to the repository's own, so the live index is never touched.
+package timetravel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// maxSnapshots bounds the snapshot collections kept at once. The least
+// recently asked one is deleted when another snapshot is indexed.
+const maxSnapshots = 3
+
+// ErrUnknownRef is returned when a ref cannot be resolved in the local clone.
+var ErrUnknownRef = errors.New("unknown git ref")
+
+// askAtRegex matches "/ask --at <ref> <question>".
+var askAtRegex = regexp.MustCompile(`^/ask\s+--at[\s=](\S+)\s+(\S[\s\S]*)$`)
+
+// ParseAsk splits an "/ask --at <ref> <question>" command. ok is false when
+// input is not such a command.
+func ParseAsk(input string) (ref, question string, ok bool) {
+	m := askAtRegex.FindStringSubmatch(strings.TrimSpace(input))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], strings.TrimSpace(m[2]), true
+}
+
+// Git is the part of gitutil.Client used to check out past trees.
+type Git interface {
+	ResolveCommit(ctx context.Context, path, ref string) (string, error)
+	AddWorktree(ctx context.Context, path, sha string) (string, func(), error)
+}
+
+// RAG is the part of rag.Service used to index and query snapshots.
+type RAG interface {
+	IndexTree(ctx context.Context, repoConfig *core.RepoConfig, collectionName, repoPath string) error
+	DeleteCollection(ctx context.Context, collectionName string) error
+	AnswerQuestion(ctx context.Context, collectionName, embedderModelName, question string, history []string) (*core.Answer, error)
+}
+
+// Service answers questions against snapshots of past commits.
+type Service struct {
+	rag           RAG
+	git           Git
+	embedderModel string
+	logger        *slog.Logger
+
+	group singleflight.Group
+	mu    sync.Mutex
+	// snapshots lists the indexed snapshot collections, least recently
+	// used first.
+	snapshots []string
+}
+
+// New creates a new Service.
+func New(cfg *config.Config, ragService RAG, git Git, logger *slog.Logger) *Service {
+	return &Service{
+		rag:           ragService,
+		git:           git,
+		embedderModel: cfg.AI.EmbedderModel,
+		logger:        logger,
+	}
+}
+
+// snapshotSeparator joins a repository collection and a commit in the name of
+// a snapshot collection.
+const snapshotSeparator = "_at_"
+
+// CollectionName returns the snapshot collection of a repository collection
+// at a commit.
+func CollectionName(collectionName, sha string) string {
+	if len(sha) > 12 {
+		sha = sha[:12]
+	}
+	return collectionName + snapshotSeparator + sha
+}
+
+// SourceCollection returns the repository collection of a snapshot
+// collection, or false for names CollectionName does not produce.
+func SourceCollection(name string) (string, bool) {
+	i := strings.LastIndex(name, snapshotSeparator)
+	if i <= 0 || i+len(snapshotSeparator) == len(name) {
+		return "", false
+	}
+	return name[:i], true
+}
+
+// AnswerQuestion answers question about repo as of ref, which may be a SHA,
+// branch or tag known to the local clone. The first question about a commit
+// indexes its tree, which takes about as long as indexing the repository.
+func (s *Service) AnswerQuestion(ctx context.Context, repo *storage.Repository, ref, question string, history []string) (*core.Answer, error) {
+	sha, err := s.git.ResolveCommit(ctx, repo.ClonePath, ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUnknownRef, err)
+	}
+
+	collection, err := s.snapshot(ctx, repo, sha)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("answering question at past commit", "repo", repo.FullName, "ref", ref, "sha", sha)
+	answer, err := s.rag.AnswerQuestion(ctx, collection, s.embedderModel, question, history)
+	if err != nil {
+		return nil, err
+	}
+	answer.Text = fmt.Sprintf("_Answered for `%s` (%s)._\n\n%s", ref, shortSHA(sha), answer.Text)
+	return answer, nil
+}
+
+// snapshot returns the snapshot collection of repo at sha, indexing it first
+// when it is not available yet. Concurrent requests for the same snapshot
+// share one indexing run.
+func (s *Service) snapshot(ctx context.Context, repo *storage.Repository, sha string) (string, error) {
+	collection := CollectionName(repo.QdrantCollectionName, sha)
+	if s.touch(collection) {
+		return collection, nil
+	}
+
+	_, err, _ := s.group.Do(collection, func() (any, error) {
+		if s.touch(collection) {
+			return nil, nil
+		}
+		if err := s.index(ctx, repo, sha, collection); err != nil {
+			return nil, err
+		}
+		s.add(ctx, collection)
+		return nil, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return collection, nil
+}
+
+func (s *Service) index(ctx context.Context, repo *storage.Repository, sha, collection string) error {
+	s.logger.Info("indexing past commit", "repo", repo.FullName, "sha", sha, "collection", collection)
+
+	worktree, cleanup, err := s.git.AddWorktree(ctx, repo.ClonePath, sha)
+	if err != nil {
+		return fmt.Errorf("failed to check out %s: %w", shortSHA(sha), err)
+	}
+	defer cleanup()
+
+	// The .code-warden.yml of that commit decides what was indexed back then.
+	repoConfig := config.LoadRepoConfigWithDefaults(worktree, repo.FullName, nil, s.logger)
+	if err := s.rag.IndexTree(ctx, repoConfig, collection, worktree); err != nil {
+		if delErr := s.rag.DeleteCollection(context.WithoutCancel(ctx), collection); delErr != nil {
+			s.logger.Warn("failed to delete partial snapshot", "collection", collection, "error", delErr)
+		}
+		return fmt.Errorf("failed to index %s at %s: %w", repo.FullName, shortSHA(sha), err)
+	}
+	return nil
+}
+
+// touch marks a snapshot as most recently used and reports whether it exists.
+func (s *Service) touch(collection string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := slices.Index(s.snapshots, collection)
+	if i < 0 {
+		return false
+	}
+	s.snapshots = append(slices.Delete(s.snapshots, i, i+1), collection)
+	return true
+}
+
+// add records a new snapshot and deletes the least recently used ones beyond
+// maxSnapshots.
+func (s *Service) add(ctx context.Context, collection string) {
+	s.mu.Lock()
+	s.snapshots = append(s.snapshots, collection)
+	var evicted []string
+	if n := len(s.snapshots) - maxSnapshots; n > 0 {
+		evicted = slices.Clone(s.snapshots[:n])
+		s.snapshots = slices.Delete(s.snapshots, 0, n)
+	}
+	s.mu.Unlock()
+
+	for _, c := range evicted {
+		if err := s.rag.DeleteCollection(context.WithoutCancel(ctx), c); err != nil {
+			s.logger.Warn("failed to delete snapshot collection", "collection", c, "error", err)
+		}
+	}
+}
+
+// Close deletes all snapshot collections.
+func (s *Service) Close(ctx context.Context) {
+	s.mu.Lock()
+	snapshots := s.snapshots
+	s.snapshots = nil
+	s.mu.Unlock()
+
+	for _, c := range snapshots {
+		if err := s.rag.DeleteCollection(ctx, c); err != nil {
+			s.logger.Warn("failed to delete snapshot collection", "collection", c, "error", err)
+		}
+	}
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,138 @@
+package timetravel
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+func TestParseAsk(t *testing.T) {
+	tests := []struct {
+		input        string
+		wantRef      string
+		wantQuestion string
+		wantOK       bool
+	}{
+		{"/ask --at v1.2.0 how did auth work?", "v1.2.0", "how did auth work?", true},
+		{"/ask --at=abc123   what is main?\nmore", "abc123", "what is main?\nmore", true},
+		{"  /ask   --at release/1.x  question ", "release/1.x", "question", true},
+		{"/ask --at v1.2.0", "", "", false},
+		{"/ask how does auth work?", "", "", false},
+		{"how does auth work?", "", "", false},
+	}
+	for _, tt := range tests {
+		ref, question, ok := ParseAsk(tt.input)
+		assert.Equal(t, tt.wantOK, ok, tt.input)
+		assert.Equal(t, tt.wantRef, ref, tt.input)
+		assert.Equal(t, tt.wantQuestion, question, tt.input)
+	}
+}
+
+// fakeSHA returns a 40 character "SHA" that starts with ref.
+func fakeSHA(ref string) string {
+	return ref + strings.Repeat("0", 40-len(ref))
+}
+
+type fakeGit struct {
+	worktrees int
+	cleanups  int
+}
+
+func (g *fakeGit) ResolveCommit(_ context.Context, _, ref string) (string, error) {
+	if ref == "missing" {
+		return "", errors.New("not found")
+	}
+	return fakeSHA(ref), nil
+}
+
+func (g *fakeGit) AddWorktree(_ context.Context, _, _ string) (string, func(), error) {
+	g.worktrees++
+	return os.TempDir(), func() { g.cleanups++ }, nil
+}
+
+type fakeRAG struct {
+	indexed []string
+	deleted []string
+	asked   []string
+	failOn  string
+}
+
+func (r *fakeRAG) IndexTree(_ context.Context, _ *core.RepoConfig, collectionName, _ string) error {
+	if collectionName == r.failOn {
+		return errors.New("embedder down")
+	}
+	r.indexed = append(r.indexed, collectionName)
+	return nil
+}
+
+func (r *fakeRAG) DeleteCollection(_ context.Context, collectionName string) error {
+	r.deleted = append(r.deleted, collectionName)
+	return nil
+}
+
+func (r *fakeRAG) AnswerQuestion(_ context.Context, collectionName, _, _ string, _ []string) (*core.Answer, error) {
+	r.asked = append(r.asked, collectionName)
+	return &core.Answer{Text: "answer", Citations: []core.Citation{{Source: "main.go", LineStart: 1, LineEnd: 9}}}, nil
+}
+
+func TestService_AnswerQuestion(t *testing.T) {
+	git := &fakeGit{}
+	rag := &fakeRAG{}
+	s := New(&config.Config{}, rag, git, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	repo := &storage.Repository{FullName: "acme/api", ClonePath: "/repos/acme/api", QdrantCollectionName: "repo_acme_api"}
+	ctx := context.Background()
+
+	answer, err := s.AnswerQuestion(ctx, repo, "v1", "how?", nil)
+	require.NoError(t, err)
+	assert.Contains(t, answer.Text, "`v1`")
+	assert.Contains(t, answer.Text, "answer")
+	assert.Equal(t, []core.Citation{{Source: "main.go", LineStart: 1, LineEnd: 9}}, answer.Citations)
+
+	// A second question about the same commit reuses the snapshot.
+	_, err = s.AnswerQuestion(ctx, repo, "v1", "why?", nil)
+	require.NoError(t, err)
+	v1 := CollectionName("repo_acme_api", fakeSHA("v1"))
+	assert.Equal(t, []string{v1}, rag.indexed)
+	assert.Equal(t, []string{v1, v1}, rag.asked)
+	assert.Equal(t, 1, git.worktrees)
+	assert.Equal(t, 1, git.cleanups)
+
+	// Indexing more than maxSnapshots commits evicts the least recently used.
+	for _, ref := range []string{"v2", "v3", "v4"} {
+		_, err = s.AnswerQuestion(ctx, repo, ref, "what?", nil)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{v1}, rag.deleted)
+
+	_, err = s.AnswerQuestion(ctx, repo, "missing", "what?", nil)
+	require.ErrorIs(t, err, ErrUnknownRef)
+
+	rag.failOn = CollectionName("repo_acme_api", fakeSHA("v5"))
+	_, err = s.AnswerQuestion(ctx, repo, "v5", "what?", nil)
+	require.Error(t, err)
+	assert.Contains(t, rag.deleted, rag.failOn, "a partial snapshot is deleted")
+
+	s.Close(ctx)
+	assert.Len(t, rag.deleted, 5)
+}
+
+func TestSourceCollection(t *testing.T) {
+	source, ok := SourceCollection(CollectionName("repo-acme-api_at_home", "0123456789abcdef"))
+	assert.True(t, ok)
+	assert.Equal(t, "repo-acme-api_at_home", source)
+
+	for _, name := range []string{"repo-acme-api", "_at_0123456789ab", "repo-acme-api_at_"} {
+		_, ok := SourceCollection(name)
+		assert.False(t, ok, name)
+	}
+}
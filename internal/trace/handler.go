@@ -0,0 +1,110 @@
+package trace
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+// Handler is a [slog.Handler] that copies the records logged with a review
+// ID in their context (see [core.WithReviewID]) into a [Recorder], and adds
+// the ID to them as "review_id" before passing them to the wrapped handler.
+// Records of a review are recorded at every level, including the debug
+// records the wrapped handler drops.
+type Handler struct {
+	next   slog.Handler
+	rec    *Recorder
+	attrs  []slog.Attr // attributes added with WithAttrs, keys qualified
+	prefix string      // groups opened with WithGroup, joined by "."
+}
+
+// NewHandler returns a [Handler] that records into rec and writes to next.
+func NewHandler(next slog.Handler, rec *Recorder) *Handler {
+	return &Handler{next: next, rec: rec}
+}
+
+// Enabled reports whether the wrapped handler handles records at level, or
+// whether ctx belongs to a review.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return core.ReviewID(ctx) != "" || h.next.Enabled(ctx, level)
+}
+
+// Handle records r if ctx belongs to a review and passes it on if the
+// wrapped handler handles its level.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	id := core.ReviewID(ctx)
+	if id == "" {
+		return h.next.Handle(ctx, r)
+	}
+
+	attrs := make(map[string]any, len(h.attrs)+r.NumAttrs())
+	for _, a := range h.attrs {
+		addAttr(attrs, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(attrs, h.prefix, a)
+		return true
+	})
+	if len(attrs) == 0 {
+		attrs = nil
+	}
+	h.rec.Add(id, Entry{Time: r.Time, Level: r.Level.String(), Message: r.Message, Attrs: attrs})
+
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+	r = r.Clone()
+	r.AddAttrs(slog.String("review_id", id))
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a handler that includes attrs.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	qualified := make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	qualified = append(qualified, h.attrs...)
+	for _, a := range attrs {
+		qualified = append(qualified, slog.Attr{Key: h.prefix + a.Key, Value: a.Value})
+	}
+	return &Handler{next: h.next.WithAttrs(attrs), rec: h.rec, attrs: qualified, prefix: h.prefix}
+}
+
+// WithGroup returns a handler that qualifies later attributes with name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &Handler{next: h.next.WithGroup(name), rec: h.rec, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// addAttr adds a to attrs under its key qualified by prefix, flattening
+// groups. Durations and values that may not encode as JSON, such as
+// errors, are recorded as text.
+func addAttr(attrs map[string]any, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		p := prefix
+		if a.Key != "" {
+			p += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			addAttr(attrs, p, ga)
+		}
+		return
+	case slog.KindDuration:
+		attrs[prefix+a.Key] = v.String()
+		return
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			attrs[prefix+a.Key] = err.Error()
+			return
+		}
+		attrs[prefix+a.Key] = v.String()
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	attrs[prefix+a.Key] = v.Any()
+}
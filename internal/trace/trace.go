@@ -0,0 +1,86 @@
+// Package trace keeps the log records of recent reviews, correlated by the
+// review ID of their context, so that slow or failed reviews can be
+// debugged through the API without searching the server logs.
+package trace
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Entry is one log record of a review.
+type Entry struct {
+	Time    time.Time      `json:"time"`
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+}
+
+// Trace is the timeline of a review, oldest entry first.
+type Trace struct {
+	ReviewID string  `json:"review_id"`
+	Entries  []Entry `json:"entries"`
+	// Dropped counts the entries recorded after the trace was full.
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// Recorder holds the traces of the most recent reviews in memory. Once it
+// holds maxReviews traces, starting another evicts the oldest.
+type Recorder struct {
+	maxReviews int
+	maxEntries int
+
+	mu     sync.Mutex
+	traces map[string]*Trace
+	order  []string // review IDs, oldest first
+}
+
+// NewRecorder returns a Recorder that keeps up to maxEntries entries of each
+// of the last maxReviews reviews.
+func NewRecorder(maxReviews, maxEntries int) *Recorder {
+	return &Recorder{
+		maxReviews: max(maxReviews, 1),
+		maxEntries: max(maxEntries, 1),
+		traces:     make(map[string]*Trace),
+	}
+}
+
+// Add appends e to the trace of the review id.
+func (r *Recorder) Add(id string, e Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t, ok := r.traces[id]
+	if !ok {
+		if len(r.order) >= r.maxReviews {
+			delete(r.traces, r.order[0])
+			r.order = r.order[1:]
+		}
+		t = &Trace{ReviewID: id}
+		r.traces[id] = t
+		r.order = append(r.order, id)
+	}
+	if len(t.Entries) >= r.maxEntries {
+		t.Dropped++
+		return
+	}
+	t.Entries = append(t.Entries, e)
+}
+
+// Get returns the trace of the review id, ordered by time, and false if
+// there is none or it was evicted.
+func (r *Recorder) Get(id string) (Trace, bool) {
+	r.mu.Lock()
+	t, ok := r.traces[id]
+	var out Trace
+	if ok {
+		out = Trace{ReviewID: t.ReviewID, Entries: slices.Clone(t.Entries), Dropped: t.Dropped}
+	}
+	r.mu.Unlock()
+	if !ok {
+		return Trace{}, false
+	}
+	// Entries from concurrent goroutines can arrive slightly out of order.
+	slices.SortStableFunc(out.Entries, func(a, b Entry) int { return a.Time.Compare(b.Time) })
+	return out, true
+}
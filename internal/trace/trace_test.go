@@ -0,0 +1,60 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sevigo/code-warden/internal/core"
+)
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder(2, 2)
+	now := time.Now()
+	r.Add("a", Entry{Time: now.Add(time.Second), Message: "second"})
+	r.Add("a", Entry{Time: now, Message: "first"})
+	r.Add("a", Entry{Time: now, Message: "dropped"})
+
+	tr, ok := r.Get("a")
+	require.True(t, ok)
+	require.Len(t, tr.Entries, 2)
+	assert.Equal(t, "first", tr.Entries[0].Message, "entries are ordered by time")
+	assert.Equal(t, 1, tr.Dropped)
+
+	r.Add("b", Entry{Time: now})
+	r.Add("c", Entry{Time: now})
+	_, ok = r.Get("a")
+	assert.False(t, ok, "the oldest trace is evicted")
+	_, ok = r.Get("c")
+	assert.True(t, ok)
+}
+
+func TestHandler(t *testing.T) {
+	var buf bytes.Buffer
+	rec := NewRecorder(10, 100)
+	base := slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo})
+	logger := slog.New(NewHandler(base, rec)).With("component", "jobs").WithGroup("job")
+
+	ctx := core.WithReviewID(context.Background(), "r1")
+	logger.DebugContext(ctx, "cloning", "repo", "o/r")
+	logger.InfoContext(ctx, "done", "took", 2*time.Second, "error", errors.New("boom"))
+	logger.Info("untraced")
+
+	tr, ok := rec.Get("r1")
+	require.True(t, ok)
+	require.Len(t, tr.Entries, 2, "debug records of a review are recorded")
+	assert.Equal(t, "DEBUG", tr.Entries[0].Level)
+	assert.Equal(t, map[string]any{"component": "jobs", "job.repo": "o/r"}, tr.Entries[0].Attrs)
+	assert.Equal(t, map[string]any{"component": "jobs", "job.took": "2s", "job.error": "boom"}, tr.Entries[1].Attrs)
+
+	out := buf.String()
+	assert.NotContains(t, out, "cloning", "the wrapped handler's level still applies")
+	assert.Contains(t, out, "job.review_id=r1")
+	assert.Contains(t, out, "untraced")
+}
@@ -0,0 +1,266 @@
+// Package vectorgc deletes vector collections that no repository refers to
+// any more, such as those of deleted or renamed repositories.
+//
+// A collection is orphaned when it is named like a repository collection but
+// neither it nor, for time-travel snapshots, its source collection is the
+// collection of a repository in the database or the target of an unfinished
+// embedder model migration. Orphans are recorded when first
+// seen and deleted only once they stayed orphaned for the grace period.
+package vectorgc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/repomanager"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/timetravel"
+)
+
+// Store is the part of storage.Store the Collector uses.
+type Store interface {
+	storage.OrphanedCollectionStore
+	GetAllRepositories(ctx context.Context) ([]*storage.Repository, error)
+	ListEmbeddingMigrations(ctx context.Context) ([]*storage.EmbeddingMigration, error)
+}
+
+// Options controls a collection pass.
+type Options struct {
+	// DryRun reports what would be recorded and deleted without changing
+	// anything.
+	DryRun bool
+	// GracePeriod is how long a collection must have been orphaned before it
+	// is deleted.
+	GracePeriod time.Duration
+}
+
+// Orphan is a collection no repository refers to.
+type Orphan struct {
+	Name        string    `json:"name"`
+	FirstSeenAt time.Time `json:"first_seen_at"`
+	// Due reports whether the grace period of the orphan has passed.
+	Due bool `json:"due"`
+	// Deleted reports whether the pass deleted the collection.
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Report is the outcome of a collection pass.
+type Report struct {
+	DryRun bool `json:"dry_run"`
+	// Collections counts the repository collections in the vector store.
+	Collections int      `json:"collections"`
+	Orphans     []Orphan `json:"orphans"`
+}
+
+// Due returns how many orphans are past the grace period.
+func (r *Report) Due() int {
+	n := 0
+	for _, o := range r.Orphans {
+		if o.Due {
+			n++
+		}
+	}
+	return n
+}
+
+// Deleted returns how many orphans the pass deleted.
+func (r *Report) Deleted() int {
+	n := 0
+	for _, o := range r.Orphans {
+		if o.Deleted {
+			n++
+		}
+	}
+	return n
+}
+
+// Collector finds and deletes orphaned collections, on demand or in the
+// background every storage.collection_gc.interval.
+type Collector struct {
+	cfg     config.CollectionGCConfig
+	store   Store
+	vectors storage.VectorStore
+	logger  *slog.Logger
+	now     func() time.Time
+
+	stopCh   chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// New creates a new Collector.
+func New(cfg *config.Config, store storage.Store, vectors storage.VectorStore, logger *slog.Logger) *Collector {
+	return &Collector{
+		cfg:     cfg.Storage.CollectionGC,
+		store:   store,
+		vectors: vectors,
+		logger:  logger,
+		now:     time.Now,
+		stopCh:  make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start collects orphaned collections in the background every configured
+// interval. It does nothing when the interval is zero.
+func (c *Collector) Start() {
+	if c.cfg.Interval <= 0 {
+		close(c.done)
+		return
+	}
+
+	go func() {
+		defer close(c.done)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-c.stopCh:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		ticker := time.NewTicker(c.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stopCh:
+				return
+			case <-ticker.C:
+				c.collect(ctx)
+			}
+		}
+	}()
+}
+
+// Stop stops the background collection and waits for a pass in progress to
+// end. It must only be called after Start.
+func (c *Collector) Stop() {
+	c.stopOnce.Do(func() { close(c.stopCh) })
+	<-c.done
+}
+
+func (c *Collector) collect(ctx context.Context) {
+	report, err := c.Run(ctx, Options{GracePeriod: c.cfg.GracePeriod})
+	if err != nil {
+		c.logger.Warn("failed to collect orphaned vector collections", "error", err)
+	}
+	if report != nil && report.Deleted() > 0 {
+		c.logger.Info("deleted orphaned vector collections", "count", report.Deleted())
+	}
+}
+
+// Run lists the collections of the vector store, records new orphans, forgets
+// collections that are no longer orphaned and deletes the orphans past the
+// grace period. A collection that fails to delete is reported, and retried
+// on the next pass.
+func (c *Collector) Run(ctx context.Context, opts Options) (*Report, error) {
+	names, err := c.vectors.StoredCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	repos, err := c.store.GetAllRepositories(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+	migrations, err := c.store.ListEmbeddingMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := c.store.ListOrphanedCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := make(map[string]bool, len(repos))
+	for _, repo := range repos {
+		referenced[repo.QdrantCollectionName] = true
+	}
+	// A collection being built by a migration is not referenced by its
+	// repository until the migration flips it.
+	for _, m := range migrations {
+		referenced[m.TargetCollection] = true
+	}
+	firstSeen := make(map[string]time.Time, len(recorded))
+	for _, o := range recorded {
+		firstSeen[o.Name] = o.FirstSeenAt
+	}
+
+	now := c.now()
+	report := &Report{DryRun: opts.DryRun}
+	var newOrphans []string
+	orphaned := make(map[string]bool)
+	for _, name := range names {
+		if !strings.HasPrefix(name, repomanager.CollectionNamePrefix) {
+			continue
+		}
+		report.Collections++
+		if isReferenced(name, referenced) {
+			continue
+		}
+		orphaned[name] = true
+		seen, ok := firstSeen[name]
+		if !ok {
+			seen = now
+			newOrphans = append(newOrphans, name)
+		}
+		report.Orphans = append(report.Orphans, Orphan{
+			Name:        name,
+			FirstSeenAt: seen,
+			Due:         now.Sub(seen) >= opts.GracePeriod,
+		})
+	}
+	sort.Slice(report.Orphans, func(i, j int) bool { return report.Orphans[i].Name < report.Orphans[j].Name })
+
+	var stale []string
+	for name := range firstSeen {
+		if !orphaned[name] {
+			stale = append(stale, name)
+		}
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	if err := c.store.MarkOrphanedCollections(ctx, newOrphans); err != nil {
+		return report, err
+	}
+	var errs []error
+	for i := range report.Orphans {
+		orphan := &report.Orphans[i]
+		if !orphan.Due {
+			continue
+		}
+		if err := c.vectors.DeleteCollection(ctx, orphan.Name); err != nil {
+			orphan.Error = err.Error()
+			errs = append(errs, err)
+			continue
+		}
+		orphan.Deleted = true
+		stale = append(stale, orphan.Name)
+		c.logger.Info("deleted orphaned vector collection", "collection", orphan.Name, "orphaned_since", orphan.FirstSeenAt)
+	}
+	if err := c.store.UnmarkOrphanedCollections(ctx, stale); err != nil {
+		errs = append(errs, err)
+	}
+	return report, errors.Join(errs...)
+}
+
+// isReferenced reports whether a collection, or the source collection of a
+// time-travel snapshot, belongs to a repository.
+func isReferenced(name string, referenced map[string]bool) bool {
+	if referenced[name] {
+		return true
+	}
+	source, ok := timetravel.SourceCollection(name)
+	return ok && referenced[source]
+}
@@ -0,0 +1,85 @@
+package vectorgc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+func newTestCollector(t *testing.T, now time.Time) (*Collector, *mocks.MockStore, *mocks.MockVectorStore) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	store := mocks.NewMockStore(ctrl)
+	vectors := mocks.NewMockVectorStore(ctrl)
+	c := New(&config.Config{}, store, vectors, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	c.now = func() time.Time { return now }
+
+	vectors.EXPECT().StoredCollections(gomock.Any()).Return([]string{
+		"repo-owner-app",                 // referenced
+		"repo-owner-app_at_0123456789ab", // snapshot of a referenced collection
+		"repo-owner-app--e0123abcd",      // being built by a migration
+		"repo-owner-old",                 // orphaned a week ago
+		"repo-owner-new",                 // orphaned just now
+		"repo-owner-old_at_0123456789ab", // snapshot of an orphan
+		"other-team-vectors",             // not named by Code-Warden
+	}, nil)
+	store.EXPECT().GetAllRepositories(gomock.Any()).Return([]*storage.Repository{
+		{FullName: "owner/app", QdrantCollectionName: "repo-owner-app"},
+	}, nil)
+	store.EXPECT().ListEmbeddingMigrations(gomock.Any()).Return([]*storage.EmbeddingMigration{
+		{SourceCollection: "repo-owner-app", TargetCollection: "repo-owner-app--e0123abcd", Status: storage.MigrationBuilding},
+	}, nil)
+	store.EXPECT().ListOrphanedCollections(gomock.Any()).Return([]*storage.OrphanedCollection{
+		{Name: "repo-owner-old", FirstSeenAt: now.Add(-8 * 24 * time.Hour)},
+		{Name: "repo-owner-old_at_0123456789ab", FirstSeenAt: now.Add(-8 * 24 * time.Hour)},
+		{Name: "repo-owner-renamed", FirstSeenAt: now.Add(-time.Hour)},
+	}, nil)
+	return c, store, vectors
+}
+
+func TestRun_DryRunChangesNothing(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	c, _, _ := newTestCollector(t, now)
+
+	report, err := c.Run(context.Background(), Options{DryRun: true, GracePeriod: 7 * 24 * time.Hour})
+	require.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 6, report.Collections)
+	require.Len(t, report.Orphans, 3)
+	assert.Equal(t, "repo-owner-new", report.Orphans[0].Name)
+	assert.False(t, report.Orphans[0].Due)
+	assert.Equal(t, now, report.Orphans[0].FirstSeenAt)
+	assert.Equal(t, "repo-owner-old", report.Orphans[1].Name)
+	assert.True(t, report.Orphans[1].Due)
+	assert.True(t, report.Orphans[2].Due)
+	assert.Zero(t, report.Deleted())
+}
+
+func TestRun_DeletesOrphansPastGracePeriod(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	c, store, vectors := newTestCollector(t, now)
+	ctx := context.Background()
+
+	store.EXPECT().MarkOrphanedCollections(ctx, []string{"repo-owner-new"}).Return(nil)
+	vectors.EXPECT().DeleteCollection(ctx, "repo-owner-old").Return(nil)
+	vectors.EXPECT().DeleteCollection(ctx, "repo-owner-old_at_0123456789ab").Return(errors.New("qdrant unavailable"))
+	store.EXPECT().UnmarkOrphanedCollections(ctx, gomock.InAnyOrder([]string{"repo-owner-renamed", "repo-owner-old"})).Return(nil)
+
+	report, err := c.Run(ctx, Options{GracePeriod: 7 * 24 * time.Hour})
+	require.ErrorContains(t, err, "qdrant unavailable")
+	assert.Equal(t, 1, report.Deleted())
+	assert.True(t, report.Orphans[1].Deleted)
+	assert.Equal(t, "qdrant unavailable", report.Orphans[2].Error)
+}
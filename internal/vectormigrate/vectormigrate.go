@@ -0,0 +1,258 @@
+// Package vectormigrate moves the vector collection of a repository to
+// another embedder model without a window in which the repository has no
+// usable index.
+//
+// A migration builds the new collection next to the old one, verifies that it
+// covers the files and documents recorded for the repository, points the
+// repository at it in one transaction and only then deletes the old
+// collection. Each step is recorded, so a migration that stops, whether it
+// failed or was interrupted, resumes where it left off when run again.
+package vectormigrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/repomanager"
+	"github.com/sevigo/code-warden/internal/storage"
+)
+
+// minCoverage is the share of the files and documents recorded for the
+// repository the new collection must hold to be used. Files that changed
+// since the last sync may chunk differently, so the counts need not match
+// exactly.
+const minCoverage = 0.95
+
+var (
+	// ErrAlreadyMigrated is returned when the repository already uses the
+	// target model.
+	ErrAlreadyMigrated = errors.New("repository already uses the embedder model")
+	// ErrNoMigration is returned by Abort when no migration is in progress.
+	ErrNoMigration = errors.New("no embedder migration in progress")
+)
+
+// Store is the part of storage.Store the Migrator uses.
+type Store interface {
+	storage.EmbeddingMigrationStore
+	GetFilesForRepo(ctx context.Context, repoID int64) (map[string]storage.FileRecord, error)
+}
+
+// Builder indexes a repository into a collection; see rag.Service.
+type Builder interface {
+	BuildCollection(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, collectionName, embedderModel string) (files, chunks int, err error)
+}
+
+// Options controls a migration.
+type Options struct {
+	// TargetModel is the embedder model to move the repository to.
+	TargetModel string
+	// KeepOld leaves the old collection in place once the repository uses
+	// the new one. The collection GC deletes it after its grace period.
+	KeepOld bool
+}
+
+// Migrator runs embedder model migrations.
+type Migrator struct {
+	store        Store
+	builder      Builder
+	vectors      storage.VectorStore
+	defaultModel string
+	logger       *slog.Logger
+}
+
+// New creates a new Migrator.
+func New(cfg *config.Config, store Store, builder Builder, vectors storage.VectorStore, logger *slog.Logger) *Migrator {
+	return &Migrator{
+		store:        store,
+		builder:      builder,
+		vectors:      vectors,
+		defaultModel: cfg.AI.EmbedderModel,
+		logger:       logger,
+	}
+}
+
+// Migrate moves repo to opts.TargetModel, or resumes the migration of repo
+// to it that is in progress. It returns the migration as far as it got.
+func (m *Migrator) Migrate(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, opts Options) (*storage.EmbeddingMigration, error) {
+	if opts.TargetModel == "" {
+		return nil, errors.New("no target embedder model given")
+	}
+	mig, err := m.store.GetEmbeddingMigration(ctx, repo.ID)
+	switch {
+	case errors.Is(err, storage.ErrNotFound) || (err == nil && mig.Status == storage.MigrationDone):
+		if mig, err = m.start(ctx, repo, opts.TargetModel); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case mig.TargetModel != opts.TargetModel:
+		return mig, fmt.Errorf("a migration of %s to %s is in progress, finish or abort it first", repo.FullName, mig.TargetModel)
+	default:
+		m.logger.Info("resuming embedder migration", "repo", repo.FullName, "status", mig.Status, "target_collection", mig.TargetCollection)
+	}
+
+	if mig.Status == storage.MigrationBuilding {
+		if err := m.build(ctx, repoConfig, repo, mig); err != nil {
+			return mig, err
+		}
+	}
+	if mig.Status == storage.MigrationBuilt {
+		if err := m.flip(ctx, repo, mig); err != nil {
+			return mig, err
+		}
+	}
+	if mig.Status == storage.MigrationFlipped {
+		if err := m.finish(ctx, mig, opts.KeepOld); err != nil {
+			return mig, err
+		}
+	}
+	return mig, nil
+}
+
+// Abort stops the migration of repo in progress and deletes the collection
+// it built. A migration that already switched the repository can only be
+// finished.
+func (m *Migrator) Abort(ctx context.Context, repo *storage.Repository) error {
+	mig, err := m.store.GetEmbeddingMigration(ctx, repo.ID)
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && mig.Status == storage.MigrationDone) {
+		return ErrNoMigration
+	}
+	if err != nil {
+		return err
+	}
+	if mig.Status == storage.MigrationFlipped {
+		return fmt.Errorf("%s already uses %s, run the migration again to finish it", repo.FullName, mig.TargetCollection)
+	}
+	if err := m.vectors.DeleteCollection(ctx, mig.TargetCollection); err != nil {
+		m.logger.Warn("failed to delete collection of aborted migration, the collection GC will remove it", "collection", mig.TargetCollection, "error", err)
+	}
+	mig.Status = storage.MigrationDone
+	mig.Error = "aborted"
+	return m.store.SaveEmbeddingMigration(ctx, mig)
+}
+
+func (m *Migrator) start(ctx context.Context, repo *storage.Repository, targetModel string) (*storage.EmbeddingMigration, error) {
+	sourceModel := repo.Embedder(m.defaultModel)
+	if sourceModel == targetModel {
+		return nil, fmt.Errorf("%w %s: %s", ErrAlreadyMigrated, targetModel, repo.FullName)
+	}
+	mig := &storage.EmbeddingMigration{
+		RepositoryID:     repo.ID,
+		SourceCollection: repo.QdrantCollectionName,
+		SourceModel:      sourceModel,
+		TargetCollection: repomanager.EmbedderCollectionName(repo.FullName, targetModel),
+		TargetModel:      targetModel,
+		Status:           storage.MigrationBuilding,
+	}
+	if mig.TargetCollection == mig.SourceCollection {
+		// Moving back to a model the repository used before: the default
+		// collection name is free again.
+		mig.TargetCollection = repomanager.GenerateCollectionName(repo.FullName)
+	}
+	if err := m.store.SaveEmbeddingMigration(ctx, mig); err != nil {
+		return nil, err
+	}
+	m.logger.Info("starting embedder migration", "repo", repo.FullName,
+		"from_model", sourceModel, "to_model", targetModel, "target_collection", mig.TargetCollection)
+	return mig, nil
+}
+
+// build indexes the repository into the target collection, from scratch, as
+// a build that stopped may have left it incomplete.
+func (m *Migrator) build(ctx context.Context, repoConfig *core.RepoConfig, repo *storage.Repository, mig *storage.EmbeddingMigration) error {
+	if repo.IsCold() {
+		return fmt.Errorf("%s is archived, restore it before migrating", repo.FullName)
+	}
+	if repo.QdrantCollectionName != mig.SourceCollection {
+		return m.fail(ctx, mig, storage.ErrMigrationConflict)
+	}
+	files, chunks, err := m.builder.BuildCollection(ctx, repoConfig, repo, mig.TargetCollection, mig.TargetModel)
+	if err != nil {
+		return m.fail(ctx, mig, fmt.Errorf("failed to build %s: %w", mig.TargetCollection, err))
+	}
+	mig.Files, mig.Chunks = files, chunks
+	mig.Status = storage.MigrationBuilt
+	mig.Error = ""
+	if err := m.store.SaveEmbeddingMigration(ctx, mig); err != nil {
+		return err
+	}
+	m.logger.Info("built collection for embedder migration", "collection", mig.TargetCollection, "files", files, "chunks", chunks)
+	return nil
+}
+
+// flip verifies the target collection and points the repository at it.
+func (m *Migrator) flip(ctx context.Context, repo *storage.Repository, mig *storage.EmbeddingMigration) error {
+	if err := m.verify(ctx, repo, mig); err != nil {
+		// The collection is rebuilt by the next run.
+		mig.Status = storage.MigrationBuilding
+		return m.fail(ctx, mig, err)
+	}
+	if err := m.store.FlipEmbeddingMigration(ctx, mig); err != nil {
+		return m.fail(ctx, mig, err)
+	}
+	repo.QdrantCollectionName = mig.TargetCollection
+	repo.EmbedderModel = mig.TargetModel
+	m.logger.Info("repository switched to new embedder collection", "repo", repo.FullName, "collection", mig.TargetCollection, "model", mig.TargetModel)
+	return nil
+}
+
+// verify checks that the target collection covers the files and documents
+// recorded for the repository and answers a search.
+func (m *Migrator) verify(ctx context.Context, repo *storage.Repository, mig *storage.EmbeddingMigration) error {
+	records, err := m.store.GetFilesForRepo(ctx, repo.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get indexed files: %w", err)
+	}
+	wantChunks := 0
+	for _, record := range records {
+		wantChunks += record.ChunkCount
+	}
+	if !covers(mig.Files, len(records)) {
+		return fmt.Errorf("%s holds %d files, the repository has %d indexed", mig.TargetCollection, mig.Files, len(records))
+	}
+	if !covers(mig.Chunks, wantChunks) {
+		return fmt.Errorf("%s holds %d documents, the repository has %d indexed", mig.TargetCollection, mig.Chunks, wantChunks)
+	}
+	if mig.Chunks == 0 {
+		return nil
+	}
+	docs, err := m.vectors.ForRepo(mig.TargetCollection, mig.TargetModel).SimilaritySearch(ctx, repo.FullName, 1)
+	if err != nil {
+		return fmt.Errorf("failed to search %s: %w", mig.TargetCollection, err)
+	}
+	if len(docs) == 0 {
+		return fmt.Errorf("a search of %s found nothing", mig.TargetCollection)
+	}
+	return nil
+}
+
+// covers reports whether got is at least minCoverage of want.
+func covers(got, want int) bool {
+	return float64(got) >= minCoverage*float64(want)
+}
+
+// finish deletes the source collection, unless it is kept, and completes
+// the migration.
+func (m *Migrator) finish(ctx context.Context, mig *storage.EmbeddingMigration, keepOld bool) error {
+	if keepOld {
+		m.logger.Info("keeping old collection, the collection GC deletes it after its grace period", "collection", mig.SourceCollection)
+	} else if err := m.vectors.DeleteCollection(ctx, mig.SourceCollection); err != nil {
+		return m.fail(ctx, mig, fmt.Errorf("failed to delete %s: %w", mig.SourceCollection, err))
+	}
+	mig.Status = storage.MigrationDone
+	mig.Error = ""
+	return m.store.SaveEmbeddingMigration(ctx, mig)
+}
+
+// fail records err on the migration and returns it.
+func (m *Migrator) fail(ctx context.Context, mig *storage.EmbeddingMigration, err error) error {
+	mig.Error = err.Error()
+	if saveErr := m.store.SaveEmbeddingMigration(ctx, mig); saveErr != nil {
+		m.logger.Error("failed to record embedder migration error", "repo_id", mig.RepositoryID, "error", saveErr)
+	}
+	return err
+}
@@ -0,0 +1,197 @@
+package vectormigrate
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/sevigo/goframe/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/sevigo/code-warden/internal/config"
+	"github.com/sevigo/code-warden/internal/core"
+	"github.com/sevigo/code-warden/internal/repomanager"
+	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/mocks"
+)
+
+type fakeBuilder struct {
+	files, chunks int
+	err           error
+	built         []string
+}
+
+func (b *fakeBuilder) BuildCollection(_ context.Context, _ *core.RepoConfig, _ *storage.Repository, collectionName, _ string) (int, int, error) {
+	b.built = append(b.built, collectionName)
+	return b.files, b.chunks, b.err
+}
+
+type testMigrator struct {
+	*Migrator
+	store   *mocks.MockStore
+	vectors *mocks.MockVectorStore
+	scoped  *mocks.MockScopedVectorStore
+	builder *fakeBuilder
+}
+
+func newTestMigrator(t *testing.T) *testMigrator {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	tm := &testMigrator{
+		store:   mocks.NewMockStore(ctrl),
+		vectors: mocks.NewMockVectorStore(ctrl),
+		scoped:  mocks.NewMockScopedVectorStore(ctrl),
+		builder: &fakeBuilder{files: 2, chunks: 10},
+	}
+	cfg := &config.Config{AI: config.AIConfig{EmbedderModel: "nomic-embed-text"}}
+	tm.Migrator = New(cfg, tm.store, tm.builder, tm.vectors, slog.New(slog.NewTextHandler(os.Stdout, nil)))
+	return tm
+}
+
+func testRepo() *storage.Repository {
+	return &storage.Repository{ID: 7, FullName: "owner/app", ClonePath: "/tmp/app", QdrantCollectionName: "repo-owner-app"}
+}
+
+func indexedFiles() map[string]storage.FileRecord {
+	return map[string]storage.FileRecord{
+		"main.go": {FilePath: "main.go", ChunkCount: 6},
+		"util.go": {FilePath: "util.go", ChunkCount: 4},
+	}
+}
+
+// saved records the statuses of the saved migrations.
+func saved(store *mocks.MockStore, statuses *[]string) {
+	store.EXPECT().SaveEmbeddingMigration(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, mig *storage.EmbeddingMigration) error {
+			*statuses = append(*statuses, mig.Status)
+			return nil
+		}).AnyTimes()
+}
+
+func TestMigrate(t *testing.T) {
+	tm := newTestMigrator(t)
+	ctx := context.Background()
+	repo := testRepo()
+	target := repomanager.EmbedderCollectionName("owner/app", "bge-m3")
+
+	var statuses []string
+	saved(tm.store, &statuses)
+	tm.store.EXPECT().GetEmbeddingMigration(ctx, int64(7)).Return(nil, storage.ErrNotFound)
+	tm.store.EXPECT().GetFilesForRepo(ctx, int64(7)).Return(indexedFiles(), nil)
+	tm.vectors.EXPECT().ForRepo(target, "bge-m3").Return(tm.scoped)
+	tm.scoped.EXPECT().SimilaritySearch(ctx, "owner/app", 1).Return([]schema.Document{{PageContent: "package main"}}, nil)
+	tm.store.EXPECT().FlipEmbeddingMigration(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, mig *storage.EmbeddingMigration) error {
+			assert.Equal(t, "repo-owner-app", mig.SourceCollection)
+			assert.Equal(t, "nomic-embed-text", mig.SourceModel)
+			mig.Status = storage.MigrationFlipped
+			return nil
+		})
+	tm.vectors.EXPECT().DeleteCollection(ctx, "repo-owner-app").Return(nil)
+
+	mig, err := tm.Migrate(ctx, nil, repo, Options{TargetModel: "bge-m3"})
+	require.NoError(t, err)
+	assert.Equal(t, storage.MigrationDone, mig.Status)
+	assert.Equal(t, []string{target}, tm.builder.built)
+	assert.Equal(t, []string{storage.MigrationBuilding, storage.MigrationBuilt, storage.MigrationDone}, statuses)
+	assert.Equal(t, target, repo.QdrantCollectionName)
+	assert.Equal(t, "bge-m3", repo.EmbedderModel)
+}
+
+func TestMigrate_IncompleteCollectionIsRebuilt(t *testing.T) {
+	tm := newTestMigrator(t)
+	ctx := context.Background()
+	tm.builder.chunks = 5
+
+	var statuses []string
+	saved(tm.store, &statuses)
+	tm.store.EXPECT().GetEmbeddingMigration(ctx, int64(7)).Return(nil, storage.ErrNotFound)
+	tm.store.EXPECT().GetFilesForRepo(ctx, int64(7)).Return(indexedFiles(), nil)
+
+	mig, err := tm.Migrate(ctx, nil, testRepo(), Options{TargetModel: "bge-m3"})
+	require.ErrorContains(t, err, "holds 5 documents, the repository has 10")
+	assert.Equal(t, storage.MigrationBuilding, mig.Status, "the next run builds the collection again")
+	assert.Equal(t, err.Error(), mig.Error)
+}
+
+func TestMigrate_ResumesAfterFlip(t *testing.T) {
+	tm := newTestMigrator(t)
+	ctx := context.Background()
+	repo := testRepo()
+	repo.QdrantCollectionName = "repo-owner-app--e0123abcd"
+
+	var statuses []string
+	saved(tm.store, &statuses)
+	tm.store.EXPECT().GetEmbeddingMigration(ctx, int64(7)).Return(&storage.EmbeddingMigration{
+		RepositoryID:     7,
+		SourceCollection: "repo-owner-app",
+		TargetCollection: "repo-owner-app--e0123abcd",
+		TargetModel:      "bge-m3",
+		Status:           storage.MigrationFlipped,
+		Error:            "failed to delete repo-owner-app: qdrant unavailable",
+	}, nil)
+	tm.vectors.EXPECT().DeleteCollection(ctx, "repo-owner-app").Return(nil)
+
+	mig, err := tm.Migrate(ctx, nil, repo, Options{TargetModel: "bge-m3"})
+	require.NoError(t, err)
+	assert.Equal(t, storage.MigrationDone, mig.Status)
+	assert.Empty(t, mig.Error)
+	assert.Empty(t, tm.builder.built, "a flipped migration is not built again")
+}
+
+func TestMigrate_Refusals(t *testing.T) {
+	ctx := context.Background()
+
+	tm := newTestMigrator(t)
+	tm.store.EXPECT().GetEmbeddingMigration(ctx, int64(7)).Return(nil, storage.ErrNotFound)
+	_, err := tm.Migrate(ctx, nil, testRepo(), Options{TargetModel: "nomic-embed-text"})
+	require.ErrorIs(t, err, ErrAlreadyMigrated)
+
+	tm = newTestMigrator(t)
+	tm.store.EXPECT().GetEmbeddingMigration(ctx, int64(7)).Return(&storage.EmbeddingMigration{
+		TargetModel: "mxbai-embed-large", Status: storage.MigrationBuilt,
+	}, nil)
+	_, err = tm.Migrate(ctx, nil, testRepo(), Options{TargetModel: "bge-m3"})
+	require.ErrorContains(t, err, "migration of owner/app to mxbai-embed-large is in progress")
+}
+
+func TestMigrate_BuildFailureIsRecorded(t *testing.T) {
+	tm := newTestMigrator(t)
+	ctx := context.Background()
+	tm.builder.err = errors.New("embedder unreachable")
+
+	var statuses []string
+	saved(tm.store, &statuses)
+	tm.store.EXPECT().GetEmbeddingMigration(ctx, int64(7)).Return(nil, storage.ErrNotFound)
+
+	mig, err := tm.Migrate(ctx, nil, testRepo(), Options{TargetModel: "bge-m3"})
+	require.ErrorContains(t, err, "embedder unreachable")
+	assert.Equal(t, storage.MigrationBuilding, mig.Status)
+	assert.Contains(t, mig.Error, "embedder unreachable")
+}
+
+func TestAbort(t *testing.T) {
+	tm := newTestMigrator(t)
+	ctx := context.Background()
+
+	tm.store.EXPECT().GetEmbeddingMigration(ctx, int64(7)).Return(&storage.EmbeddingMigration{
+		TargetCollection: "repo-owner-app--e0123abcd", Status: storage.MigrationBuilt,
+	}, nil)
+	tm.vectors.EXPECT().DeleteCollection(ctx, "repo-owner-app--e0123abcd").Return(nil)
+	tm.store.EXPECT().SaveEmbeddingMigration(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, mig *storage.EmbeddingMigration) error {
+			assert.Equal(t, storage.MigrationDone, mig.Status)
+			return nil
+		})
+	require.NoError(t, tm.Abort(ctx, testRepo()))
+
+	tm.store.EXPECT().GetEmbeddingMigration(ctx, int64(7)).Return(&storage.EmbeddingMigration{Status: storage.MigrationFlipped}, nil)
+	require.ErrorContains(t, tm.Abort(ctx, testRepo()), "run the migration again to finish it")
+
+	tm.store.EXPECT().GetEmbeddingMigration(ctx, int64(7)).Return(nil, storage.ErrNotFound)
+	require.ErrorIs(t, tm.Abort(ctx, testRepo()), ErrNoMigration)
+}
@@ -11,18 +11,29 @@ import (
 	"fmt"
 	"github.com/jmoiron/sqlx"
 	"github.com/sevigo/code-warden/internal/app"
+	"github.com/sevigo/code-warden/internal/artifacts"
 	"github.com/sevigo/code-warden/internal/config"
 	"github.com/sevigo/code-warden/internal/core"
 	"github.com/sevigo/code-warden/internal/db"
+	"github.com/sevigo/code-warden/internal/github"
 	"github.com/sevigo/code-warden/internal/gitutil"
 	"github.com/sevigo/code-warden/internal/globalmcp"
+	"github.com/sevigo/code-warden/internal/health"
 	"github.com/sevigo/code-warden/internal/jobs"
 	"github.com/sevigo/code-warden/internal/llm"
 	"github.com/sevigo/code-warden/internal/logger"
+	"github.com/sevigo/code-warden/internal/netutil"
+	"github.com/sevigo/code-warden/internal/notify"
+	"github.com/sevigo/code-warden/internal/objectstore"
 	"github.com/sevigo/code-warden/internal/rag"
 	"github.com/sevigo/code-warden/internal/repomanager"
+	"github.com/sevigo/code-warden/internal/sandbox"
+	"github.com/sevigo/code-warden/internal/secrets"
 	"github.com/sevigo/code-warden/internal/server"
+	"github.com/sevigo/code-warden/internal/settings"
 	"github.com/sevigo/code-warden/internal/storage"
+	"github.com/sevigo/code-warden/internal/trace"
+	"github.com/sevigo/code-warden/internal/vectorgc"
 	"github.com/sevigo/goframe/embeddings"
 	"github.com/sevigo/goframe/httpclient"
 	"github.com/sevigo/goframe/llms"
@@ -41,7 +52,7 @@ import (
 // Injectors from wire.go:
 
 func InitializeApp(ctx context.Context) (*app.App, func(), error) {
-	configConfig, err := config.LoadConfig()
+	configConfig, err := provideConfig()
 	if err != nil {
 		return nil, nil, err
 	}
@@ -54,16 +65,27 @@ func InitializeApp(ctx context.Context) (*app.App, func(), error) {
 	store := storage.NewStore(sqlxDB)
 	loggerConfig := provideLoggerConfig(configConfig)
 	writer := provideLogWriter(configConfig)
-	logger := provideSlogLogger(loggerConfig, writer)
-	embedder, err := provideEmbedder(ctx, configConfig, logger)
+	recorder := provideTraceRecorder(configConfig)
+	logger := provideSlogLogger(loggerConfig, writer, recorder)
+	embedderFactory, err := provideEmbedderFactory(ctx, configConfig, logger)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
-	vectorStore := provideVectorStore(configConfig, embedder, logger)
-	client := gitutil.NewClient(logger)
-	repoManager := repomanager.New(configConfig, store, vectorStore, client, logger)
-	promptManager, err := llm.NewPromptManager()
+	monitor := health.NewMonitor(configConfig, logger)
+	vectorStore, err := provideVectorStore(configConfig, sqlxDB, embedderFactory, monitor, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	client := provideGitClient(configConfig, logger)
+	objectstoreStore, err := provideObjectStore(configConfig)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	repoManager := repomanager.New(configConfig, store, vectorStore, client, objectstoreStore, logger)
+	promptManager, err := providePromptManager(configConfig)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
@@ -73,37 +95,67 @@ func InitializeApp(ctx context.Context) (*app.App, func(), error) {
 		cleanup()
 		return nil, nil, err
 	}
-	reranker, err := provideReranker(ctx, configConfig, logger, promptManager)
+	usageRecorder := provideUsageRecorder(configConfig, store, logger)
+	pipeline, err := provideLLMPipeline(configConfig, usageRecorder, logger)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	reranker, err := provideReranker(ctx, configConfig, logger, promptManager, pipeline)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
-	parserRegistry, err := provideParserRegistry(logger)
+	parserRegistry, cleanup2, err := provideParserRegistry(configConfig, logger)
 	if err != nil {
 		cleanup()
 		return nil, nil, err
 	}
 	textSplitter, err := provideTextSplitter(parserRegistry, model, logger)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	service, err := rag.NewService(configConfig, promptManager, vectorStore, store, model, reranker, parserRegistry, textSplitter, logger)
+	artifactsStore := provideArtifactStore(configConfig, objectstoreStore, logger)
+	callObserver := provideGeneratorObserver(monitor)
+	circuitBreaker := provideGeneratorBreaker(monitor)
+	service, err := rag.NewService(configConfig, promptManager, vectorStore, store, artifactsStore, model, callObserver, circuitBreaker, pipeline, reranker, parserRegistry, textSplitter, logger)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
 	workspaceRegistry := provideWorkspaceRegistry(logger)
-	job := jobs.NewReviewJob(configConfig, service, store, vectorStore, repoManager, logger, workspaceRegistry)
-	jobDispatcher := jobs.NewDispatcher(ctx, job, configConfig, logger)
-	serverServer := server.NewServerWithStore(ctx, configConfig, jobDispatcher, job, store, service, repoManager, client, logger)
-	globalmcpServer, err := provideGlobalMCPServer(ctx, configConfig, logger, workspaceRegistry, store, vectorStore, service)
+	manager, err := provideSettingsManager(ctx, configConfig, store, promptManager, service, logger)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	notifier := notify.New(configConfig, store, logger)
+	job := jobs.NewReviewJob(configConfig, service, store, vectorStore, repoManager, logger, workspaceRegistry, manager, notifier)
+	jobDispatcher := jobs.NewDispatcher(ctx, job, configConfig, monitor, logger)
+	checker := provideHealthChecker(configConfig, sqlxDB)
+	serverServer := server.NewServerWithStore(ctx, configConfig, jobDispatcher, job, store, vectorStore, service, repoManager, client, manager, monitor, checker, recorder, logger)
+	globalmcpServer, err := provideGlobalMCPServer(ctx, configConfig, logger, workspaceRegistry, store, vectorStore, service, repoManager)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	checkRunReaper := jobs.NewCheckRunReaper(configConfig, store, job, jobDispatcher, logger)
+	collector := vectorgc.New(configConfig, store, vectorStore, logger)
+	vault, err := provideVault(configConfig, store, logger)
 	if err != nil {
+		cleanup2()
 		cleanup()
 		return nil, nil, err
 	}
-	appApp := app.NewApp(configConfig, dbDB, store, vectorStore, repoManager, jobDispatcher, service, serverServer, client, globalmcpServer, logger)
+	warmer := provideWarmer(configConfig, model, embedderFactory, logger)
+	appApp := app.NewApp(configConfig, dbDB, store, vectorStore, repoManager, jobDispatcher, service, serverServer, client, globalmcpServer, artifactsStore, checkRunReaper, monitor, collector, notifier, vault, warmer, logger)
 	return appApp, func() {
+		cleanup2()
 		cleanup()
 	}, nil
 }
@@ -131,11 +183,35 @@ func parseRequestTimeout(s string, logger *slog.Logger) time.Duration {
 	return d
 }
 
+// provideConfig loads the configuration and applies its network settings
+// before any outbound client is created.
+func provideConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := netutil.Configure(cfg.Network); err != nil {
+		return nil, fmt.Errorf("failed to configure network: %w", err)
+	}
+	return cfg, nil
+}
+
+func provideGitClient(cfg *config.Config, logger *slog.Logger) *gitutil.Client {
+	client := gitutil.NewClient(logger)
+	client.SSH = gitutil.SSHOptions{
+		KeyPath:        cfg.Git.SSHKeyPath,
+		KnownHostsPath: cfg.Git.SSHKnownHosts,
+	}
+	return client
+}
+
 func provideSQLXDB(db2 *db.DB) *sqlx.DB {
 	return db2.DB
 }
 
-func provideVectorStore(cfg *config.Config, embedder embeddings.Embedder, logger *slog.Logger) storage.VectorStore {
+// provideVectorStore creates the vector store of storage.vector_store_provider
+// with batching tuned for the embedder provider.
+func provideVectorStore(cfg *config.Config, db *sqlx.DB, embedders storage.EmbedderFactory, monitor *health.Monitor, logger *slog.Logger) (storage.VectorStore, error) {
 	var batchConfig *qdrant.BatchConfig
 	if cfg.AI.EmbedderProvider == "gemini" {
 		batchConfig = &qdrant.BatchConfig{
@@ -161,9 +237,14 @@ func provideVectorStore(cfg *config.Config, embedder embeddings.Embedder, logger
 		}
 	}
 
-	return storage.NewQdrantVectorStore(
+	vectorBackend := cfg.Storage.VectorStoreProvider
+	if vectorBackend == "" {
+		vectorBackend = config.VectorStoreQdrant
+	}
+	return storage.NewVectorStore(
 		cfg,
-		logger, storage.WithBatchConfig(batchConfig), storage.WithInitialEmbedder(cfg.AI.EmbedderModel, embedder), storage.WithQdrantOptions(qdrant.WithTimeout(60*time.Second), qdrant.WithKeepaliveTime(15*time.Second), qdrant.WithKeepaliveTimeout(5*time.Second), qdrant.WithPoolSize(20)),
+		db,
+		logger, storage.WithBatchConfig(batchConfig), storage.WithEmbedderFactory(embedders), storage.WithQdrantOptions(qdrant.WithTimeout(60*time.Second), qdrant.WithKeepaliveTime(15*time.Second), qdrant.WithKeepaliveTimeout(5*time.Second), qdrant.WithPoolSize(20)),
 	)
 }
 
@@ -192,7 +273,7 @@ func provideGeneratorLLM(ctx context.Context, cfg *config.Config, logger *slog.L
 			clientCfg.Timeout = 0
 		}
 
-		opts := []ollama.Option{ollama.WithServerURL(cfg.AI.OllamaHost), ollama.WithAPIKey(cfg.AI.OllamaAPIKey), ollama.WithHTTPClient(httpclient.NewClient(clientCfg)), ollama.WithModel(cfg.AI.GeneratorModel), ollama.WithLogger(logger), ollama.WithRetryAttempts(3), ollama.WithRetryDelay(2 * time.Second)}
+		opts := []ollama.Option{ollama.WithServerURL(cfg.AI.OllamaHost), ollama.WithAPIKey(cfg.AI.OllamaAPIKey), ollama.WithHTTPClient(netutil.Apply(httpclient.NewClient(clientCfg))), ollama.WithModel(cfg.AI.GeneratorModel), ollama.WithLogger(logger), ollama.WithRetryAttempts(3), ollama.WithRetryDelay(2 * time.Second)}
 
 		if cfg.AI.EnableThinking {
 			opts = append(opts, ollama.WithThinking(true))
@@ -210,13 +291,106 @@ func provideGeneratorLLM(ctx context.Context, cfg *config.Config, logger *slog.L
 	}
 }
 
-func provideEmbedder(ctx context.Context, cfg *config.Config, logger *slog.Logger) (embeddings.Embedder, error) {
+// usageSaveTimeout bounds saving the token usage of an LLM call, which
+// outlives the call's context so that cancelled jobs are still accounted.
+const usageSaveTimeout = 5 * time.Second
+
+// maxTraceEntries caps the log records kept per review trace.
+const maxTraceEntries = 2000
+
+// provideUsageRecorder records the token usage of LLM calls in the database,
+// unless usage accounting is disabled, and logs each call for the trace of
+// the review that made it.
+func provideUsageRecorder(cfg *config.Config, store storage.Store, logger2 *slog.Logger) llm.UsageRecorder {
+	if !cfg.Usage.Enabled && cfg.Server.TraceReviews == 0 {
+		return nil
+	}
+	return func(ctx context.Context, usage core.LLMUsage) {
+		logger2.DebugContext(ctx, "llm call finished", "model", usage.Model, "step", usage.Step,
+			"prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens)
+		if !cfg.Usage.Enabled {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), usageSaveTimeout)
+		defer cancel()
+		if err := store.SaveLLMUsage(ctx, &usage); err != nil {
+			logger2.Warn("failed to record llm usage", "error", err, "model", usage.Model, "repo", usage.RepoFullName)
+		}
+	}
+}
+
+// provideLLMPipeline builds the middleware chain of llm_middleware, with
+// token accounting innermost, that every model is wrapped in.
+func provideLLMPipeline(cfg *config.Config, usage llm.UsageRecorder, logger2 *slog.Logger) (llm.Pipeline, error) {
+	pipeline, err := llm.NewPipeline(cfg.LLMMiddleware, usage, logger2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create llm middleware: %w", err)
+	}
+	if len(cfg.LLMMiddleware.Chain) > 0 {
+		logger2.Info("llm middleware enabled", "chain", cfg.LLMMiddleware.Chain)
+	}
+	return pipeline, nil
+}
+
+// provideVault creates the credential vault and lets GitHub installation
+// tokens be cached in it. Without an encryption key nothing is stored.
+func provideVault(cfg *config.Config, store storage.Store, logger2 *slog.Logger) (*secrets.Vault, error) {
+	vault, err := secrets.NewVault(cfg, store)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credential vault: %w", err)
+	}
+	if vault == nil {
+		logger2.Info("no security.encryption_key configured, credentials are not persisted")
+		return nil, nil
+	}
+	github.UseTokenCache(vault)
+	return vault, nil
+}
+
+// provideHealthChecker creates the dependency checker of /readyz.
+func provideHealthChecker(cfg *config.Config, db *sqlx.DB) *health.Checker {
+	return health.NewChecker(cfg, db.PingContext)
+}
+
+// provideGeneratorBreaker returns the circuit breaker of the generator LLM,
+// or nil when circuit breakers are disabled.
+func provideGeneratorBreaker(monitor *health.Monitor) llm.CircuitBreaker {
+	if b := monitor.Breaker("generator"); b != nil {
+		return b
+	}
+	return nil
+}
+
+// provideGeneratorObserver feeds the outcome of every generator call to the
+// health monitor.
+func provideGeneratorObserver(monitor *health.Monitor) llm.CallObserver {
+	return monitor.RecordLLM
+}
+
+// provideEmbedderFactory returns how the vector store creates the embedder
+// of a model on first use, so that repositories indexed with different
+// models are served side by side.
+func provideEmbedderFactory(ctx context.Context, cfg *config.Config, logger *slog.Logger) (storage.EmbedderFactory, error) {
+	switch cfg.AI.EmbedderProvider {
+	case "gemini", "ollama":
+	default:
+		return nil, fmt.Errorf("unsupported embedder provider: %s", cfg.AI.EmbedderProvider)
+	}
+	// Embedders outlive the context of the caller that first needs them.
+	ctx = context.WithoutCancel(ctx)
+	return func(model string) (embeddings.Embedder, error) {
+		return newEmbedder(ctx, cfg, logger, model)
+	}, nil
+}
+
+// newEmbedder creates the embedder of model with the configured provider.
+func newEmbedder(ctx context.Context, cfg *config.Config, logger *slog.Logger, model string) (embeddings.Embedder, error) {
 	var embedderLLM embeddings.Embedder
 	var err error
 
 	switch cfg.AI.EmbedderProvider {
 	case "gemini":
-		embedderLLM, err = gemini.New(ctx, gemini.WithEmbeddingModel(cfg.AI.EmbedderModel), gemini.WithAPIKey(cfg.AI.GeminiAPIKey))
+		embedderLLM, err = gemini.New(ctx, gemini.WithEmbeddingModel(model), gemini.WithAPIKey(cfg.AI.GeminiAPIKey))
 	case "ollama":
 		headerTimeout := parseHeaderTimeout(cfg.AI.HTTPResponseHeaderTimeout, logger)
 		requestTimeout := parseRequestTimeout(cfg.AI.HTTPRequestTimeout, logger)
@@ -224,7 +398,7 @@ func provideEmbedder(ctx context.Context, cfg *config.Config, logger *slog.Logge
 		logger.Info("configuring Ollama HTTP client for embedder",
 			"response_header_timeout", headerTimeout,
 			"request_timeout", requestTimeout,
-			"model", cfg.AI.EmbedderModel,
+			"model", model,
 		)
 
 		clientCfg := httpclient.NewConfig(httpclient.WithResponseHeaderTimeout(headerTimeout))
@@ -235,7 +409,7 @@ func provideEmbedder(ctx context.Context, cfg *config.Config, logger *slog.Logge
 			clientCfg.Timeout = 0
 		}
 
-		opts := []ollama.Option{ollama.WithServerURL(cfg.AI.OllamaHost), ollama.WithAPIKey(cfg.AI.OllamaAPIKey), ollama.WithModel(cfg.AI.EmbedderModel), ollama.WithHTTPClient(httpclient.NewClient(clientCfg)), ollama.WithLogger(logger), ollama.WithRetryAttempts(3), ollama.WithRetryDelay(2 * time.Second)}
+		opts := []ollama.Option{ollama.WithServerURL(cfg.AI.OllamaHost), ollama.WithAPIKey(cfg.AI.OllamaAPIKey), ollama.WithModel(model), ollama.WithHTTPClient(netutil.Apply(httpclient.NewClient(clientCfg))), ollama.WithLogger(logger), ollama.WithRetryAttempts(3), ollama.WithRetryDelay(2 * time.Second)}
 
 		if cfg.AI.ModelKeepAlive != "" {
 			opts = append(opts, ollama.WithKeepAlive(cfg.AI.ModelKeepAlive))
@@ -251,8 +425,35 @@ func provideEmbedder(ctx context.Context, cfg *config.Config, logger *slog.Logge
 	return embeddings.NewEmbedder(embedderLLM)
 }
 
-func provideParserRegistry(logger *slog.Logger) (parsers.ParserRegistry, error) {
-	return parsers.RegisterLanguagePlugins(logger)
+// provideWarmer creates the warm-up of the generator and the embedder of
+// ai.embedder_model. An embedder that cannot be created is only logged, like
+// any other warm-up failure.
+func provideWarmer(cfg *config.Config, generator llms.Model, embedders storage.EmbedderFactory, logger2 *slog.Logger) *llm.Warmer {
+	if !cfg.AI.Warmup.Enabled {
+		return llm.NewWarmer(cfg.AI.Warmup, nil, nil, logger2)
+	}
+	embedder, err := embedders(cfg.AI.EmbedderModel)
+	if err != nil {
+		logger2.Warn("failed to create embedder for warm-up", "model", cfg.AI.EmbedderModel, "error", err)
+	}
+	return llm.NewWarmer(cfg.AI.Warmup, generator, embedder, logger2)
+}
+
+// provideParserRegistry returns the language parsers, moved into sandboxed
+// worker processes when sandbox.isolate_parsing is set.
+func provideParserRegistry(cfg *config.Config, logger *slog.Logger) (parsers.ParserRegistry, func(), error) {
+	registry, err := parsers.RegisterLanguagePlugins(logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !cfg.Sandbox.IsolateParsing {
+		return registry, func() {}, nil
+	}
+	runner, err := sandbox.NewParserRunner(cfg.Sandbox)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create parser sandbox: %w", err)
+	}
+	return sandbox.IsolateParsers(registry, runner, cfg.Sandbox, logger)
 }
 
 func provideTextSplitter(registry parsers.ParserRegistry, model llms.Model, logger *slog.Logger) (textsplitter.TextSplitter, error) {
@@ -268,6 +469,14 @@ func provideTextSplitter(registry parsers.ParserRegistry, model llms.Model, logg
 	return splitter, nil
 }
 
+func provideObjectStore(cfg *config.Config) (objectstore.Store, error) {
+	return objectstore.New(cfg.Storage.ObjectStore)
+}
+
+func provideArtifactStore(cfg *config.Config, objects objectstore.Store, logger *slog.Logger) *artifacts.Store {
+	return artifacts.New(objects, cfg.Storage.Artifacts, logger)
+}
+
 func provideLoggerConfig(cfg *config.Config) logger.Config {
 	return cfg.Logging
 }
@@ -294,11 +503,20 @@ func provideLogWriter(cfg *config.Config) io.Writer {
 	}
 }
 
-func provideSlogLogger(loggerConfig logger.Config, writer io.Writer) *slog.Logger {
-	return logger.NewLogger(loggerConfig, writer)
+// provideTraceRecorder keeps the log records of the last
+// server.trace_reviews reviews, or returns nil when tracing is disabled.
+func provideTraceRecorder(cfg *config.Config) *trace.Recorder {
+	if cfg.Server.TraceReviews == 0 {
+		return nil
+	}
+	return trace.NewRecorder(cfg.Server.TraceReviews, maxTraceEntries)
+}
+
+func provideSlogLogger(loggerConfig logger.Config, writer io.Writer, traces *trace.Recorder) *slog.Logger {
+	return logger.NewTracingLogger(loggerConfig, writer, traces)
 }
 
-func provideGlobalMCPServer(ctx context.Context, cfg *config.Config, logger2 *slog.Logger, registry *globalmcp.WorkspaceRegistry, store storage.Store, vectorStore storage.VectorStore, ragService rag.Service) (*globalmcp.Server, error) {
+func provideGlobalMCPServer(ctx context.Context, cfg *config.Config, logger2 *slog.Logger, registry *globalmcp.WorkspaceRegistry, store storage.Store, vectorStore storage.VectorStore, ragService rag.Service, repoMgr repomanager.RepoManager) (*globalmcp.Server, error) {
 	if cfg.Agent.DefaultWorkspace == "" {
 		logger2.
 			Info("No default workspace configured, using proxy-only MCP server")
@@ -309,14 +527,14 @@ func provideGlobalMCPServer(ctx context.Context, cfg *config.Config, logger2 *sl
 			"workspace", cfg.Agent.DefaultWorkspace,
 			"repo", cfg.Agent.DefaultWorkspaceRepo)
 
-	repo, err := getOrCreateDefaultRepo(ctx, store, cfg.Agent.DefaultWorkspaceRepo, cfg.Agent.DefaultWorkspace, logger2)
+	repo, err := getOrCreateDefaultRepo(ctx, store, repoMgr, cfg.Agent.DefaultWorkspaceRepo, cfg.Agent.DefaultWorkspace, logger2)
 	if err != nil {
 		logger2.
 			Error("Failed to setup default workspace", "error", err)
 		return nil, fmt.Errorf("failed to setup default workspace: %w", err)
 	}
 
-	scopedStore := vectorStore.ForRepo(repo.QdrantCollectionName, cfg.AI.EmbedderModel)
+	scopedStore := vectorStore.ForRepo(repo.QdrantCollectionName, repo.Embedder(cfg.AI.EmbedderModel))
 
 	standaloneCfg := &globalmcp.StandaloneConfig{
 		Store:       store,
@@ -329,8 +547,9 @@ func provideGlobalMCPServer(ctx context.Context, cfg *config.Config, logger2 *sl
 	return globalmcp.NewStandaloneServer(cfg, logger2, registry, standaloneCfg), nil
 }
 
-func getOrCreateDefaultRepo(ctx context.Context, store storage.Store, repoFullName, repoPath string, logger2 *slog.Logger) (*storage.Repository, error) {
-	repo, err := store.GetRepositoryByFullName(ctx, repoFullName)
+func getOrCreateDefaultRepo(ctx context.Context, store storage.Store, repoMgr repomanager.RepoManager, repoFullName, repoPath string, logger2 *slog.Logger) (*storage.Repository, error) {
+	// The lookup restores a collection that was moved to cold storage.
+	repo, err := repoMgr.GetRepoRecord(ctx, repoFullName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check for existing repository: %w", err)
 	}
@@ -357,11 +576,42 @@ func getOrCreateDefaultRepo(ctx context.Context, store storage.Store, repoFullNa
 	return repo, nil
 }
 
+// providePromptManager creates the prompt manager and registers the variant
+// templates of the configured prompt experiments.
+func providePromptManager(cfg *config.Config) (*llm.PromptManager, error) {
+	pm, err := llm.NewPromptManager()
+	if err != nil {
+		return nil, err
+	}
+	experiments := make([]llm.Experiment, 0, len(cfg.AI.PromptExperiments))
+	for _, e := range cfg.AI.PromptExperiments {
+		source, err := os.ReadFile(e.VariantFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt experiment variant: %w", err)
+		}
+		experiments = append(experiments, llm.Experiment{Key: llm.PromptKey(e.Prompt), Source: string(source), Percent: e.Percent})
+	}
+	if err := pm.SetExperiments(experiments); err != nil {
+		return nil, err
+	}
+	return pm, nil
+}
+
+// provideSettingsManager creates the runtime settings manager and applies the
+// overrides stored through the admin API on top of the configuration.
+func provideSettingsManager(ctx context.Context, cfg *config.Config, store storage.Store, promptMgr *llm.PromptManager, ragService rag.Service, logger2 *slog.Logger) (*settings.Manager, error) {
+	mgr := settings.NewManager(cfg, store, promptMgr, ragService, logger2)
+	if err := mgr.Load(ctx); err != nil {
+		return nil, err
+	}
+	return mgr, nil
+}
+
 func provideWorkspaceRegistry(logger2 *slog.Logger) *globalmcp.WorkspaceRegistry {
 	return globalmcp.NewWorkspaceRegistry(logger2)
 }
 
-func provideReranker(ctx context.Context, cfg *config.Config, logger2 *slog.Logger, promptMgr *llm.PromptManager) (schema.Reranker, error) {
+func provideReranker(ctx context.Context, cfg *config.Config, logger2 *slog.Logger, promptMgr *llm.PromptManager, pipeline llm.Pipeline) (schema.Reranker, error) {
 	if !cfg.AI.EnableReranking {
 		logger2.
 			Info("Reranking is disabled, using NoOpReranker")
@@ -387,16 +637,17 @@ func provideReranker(ctx context.Context, cfg *config.Config, logger2 *slog.Logg
 		clientCfg.Timeout = 0
 	}
 
-	opts := []ollama.Option{ollama.WithServerURL(cfg.AI.OllamaHost), ollama.WithModel(cfg.AI.RerankerModel), ollama.WithHTTPClient(httpclient.NewClient(clientCfg)), ollama.WithLogger(logger2), ollama.WithRetryAttempts(3), ollama.WithRetryDelay(2 * time.Second)}
+	opts := []ollama.Option{ollama.WithServerURL(cfg.AI.OllamaHost), ollama.WithModel(cfg.AI.RerankerModel), ollama.WithHTTPClient(netutil.Apply(httpclient.NewClient(clientCfg))), ollama.WithLogger(logger2), ollama.WithRetryAttempts(3), ollama.WithRetryDelay(2 * time.Second)}
 
 	if cfg.AI.ModelKeepAlive != "" {
 		opts = append(opts, ollama.WithKeepAlive(cfg.AI.ModelKeepAlive))
 	}
 
-	rerankLLM, err := ollama.New(opts...)
+	ollamaLLM, err := ollama.New(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create reranker LLM: %w", err)
 	}
+	rerankLLM := pipeline.Wrap(cfg.AI.RerankerModel, ollamaLLM)
 
 	prompt, err := promptMgr.Raw("rerank_precision")
 	if err != nil {
@@ -175,7 +175,7 @@ func TestProvideSlogLogger(t *testing.T) {
 		Output: "stdout",
 	}
 
-	logger := provideSlogLogger(config, os.Stdout)
+	logger := provideSlogLogger(config, os.Stdout, nil)
 
 	if logger == nil {
 		t.Error("expected non-nil logger")
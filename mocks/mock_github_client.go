@@ -100,6 +100,21 @@ func (mr *MockClientMockRecorder) UpdateComment(ctx, owner, repo, commentID, bod
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateComment", reflect.TypeOf((*MockClient)(nil).UpdateComment), ctx, owner, repo, commentID, body)
 }
 
+// CreateIssue mocks base method.
+func (m *MockClient) CreateIssue(ctx context.Context, owner, repo, title, body string) (*github0.Issue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIssue", ctx, owner, repo, title, body)
+	ret0, _ := ret[0].(*github0.Issue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateIssue indicates an expected call of CreateIssue.
+func (mr *MockClientMockRecorder) CreateIssue(ctx, owner, repo, title, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIssue", reflect.TypeOf((*MockClient)(nil).CreateIssue), ctx, owner, repo, title, body)
+}
+
 // CreatePullRequest mocks base method.
 func (m *MockClient) CreatePullRequest(ctx context.Context, owner, repo string, opts github0.PullRequestOptions) (*github.PullRequest, error) {
 	m.ctrl.T.Helper()
@@ -159,6 +174,21 @@ func (mr *MockClientMockRecorder) GetChangedFiles(ctx, owner, repo, number any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChangedFiles", reflect.TypeOf((*MockClient)(nil).GetChangedFiles), ctx, owner, repo, number)
 }
 
+// GetFileContent mocks base method.
+func (m *MockClient) GetFileContent(ctx context.Context, owner, repo, path string) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFileContent", ctx, owner, repo, path)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFileContent indicates an expected call of GetFileContent.
+func (mr *MockClientMockRecorder) GetFileContent(ctx, owner, repo, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFileContent", reflect.TypeOf((*MockClient)(nil).GetFileContent), ctx, owner, repo, path)
+}
+
 // GetIssue mocks base method.
 func (m *MockClient) GetIssue(ctx context.Context, owner, repo string, number int) (*github0.Issue, error) {
 	m.ctrl.T.Helper()
@@ -219,6 +249,36 @@ func (mr *MockClientMockRecorder) GetPullRequestDiff(ctx, owner, repo, number an
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPullRequestDiff", reflect.TypeOf((*MockClient)(nil).GetPullRequestDiff), ctx, owner, repo, number)
 }
 
+// GetReviewComment mocks base method.
+func (m *MockClient) GetReviewComment(ctx context.Context, owner, repo string, commentID int64) (*github0.ReviewComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReviewComment", ctx, owner, repo, commentID)
+	ret0, _ := ret[0].(*github0.ReviewComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReviewComment indicates an expected call of GetReviewComment.
+func (mr *MockClientMockRecorder) GetReviewComment(ctx, owner, repo, commentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReviewComment", reflect.TypeOf((*MockClient)(nil).GetReviewComment), ctx, owner, repo, commentID)
+}
+
+// ListIssueComments mocks base method.
+func (m *MockClient) ListIssueComments(ctx context.Context, owner, repo string, number int) ([]github0.IssueComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListIssueComments", ctx, owner, repo, number)
+	ret0, _ := ret[0].([]github0.IssueComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListIssueComments indicates an expected call of ListIssueComments.
+func (mr *MockClientMockRecorder) ListIssueComments(ctx, owner, repo, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIssueComments", reflect.TypeOf((*MockClient)(nil).ListIssueComments), ctx, owner, repo, number)
+}
+
 // ListIssues mocks base method.
 func (m *MockClient) ListIssues(ctx context.Context, owner, repo string, opts github0.IssueOptions) ([]github0.Issue, error) {
 	m.ctrl.T.Helper()
@@ -234,6 +294,79 @@ func (mr *MockClientMockRecorder) ListIssues(ctx, owner, repo, opts any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListIssues", reflect.TypeOf((*MockClient)(nil).ListIssues), ctx, owner, repo, opts)
 }
 
+// ListPullRequests mocks base method.
+func (m *MockClient) ListPullRequests(ctx context.Context, owner, repo, state string) ([]*github.PullRequest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPullRequests", ctx, owner, repo, state)
+	ret0, _ := ret[0].([]*github.PullRequest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPullRequests indicates an expected call of ListPullRequests.
+func (mr *MockClientMockRecorder) ListPullRequests(ctx, owner, repo, state any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPullRequests", reflect.TypeOf((*MockClient)(nil).ListPullRequests), ctx, owner, repo, state)
+}
+
+// ListReviewCommentReactions mocks base method.
+func (m *MockClient) ListReviewCommentReactions(ctx context.Context, owner, repo string, commentID int64) ([]github0.Reaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReviewCommentReactions", ctx, owner, repo, commentID)
+	ret0, _ := ret[0].([]github0.Reaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReviewCommentReactions indicates an expected call of ListReviewCommentReactions.
+func (mr *MockClientMockRecorder) ListReviewCommentReactions(ctx, owner, repo, commentID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReviewCommentReactions", reflect.TypeOf((*MockClient)(nil).ListReviewCommentReactions), ctx, owner, repo, commentID)
+}
+
+// ListReviewComments mocks base method.
+func (m *MockClient) ListReviewComments(ctx context.Context, owner, repo string, number int) ([]github0.ReviewComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListReviewComments", ctx, owner, repo, number)
+	ret0, _ := ret[0].([]github0.ReviewComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReviewComments indicates an expected call of ListReviewComments.
+func (mr *MockClientMockRecorder) ListReviewComments(ctx, owner, repo, number any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReviewComments", reflect.TypeOf((*MockClient)(nil).ListReviewComments), ctx, owner, repo, number)
+}
+
+// MinimizeComment mocks base method.
+func (m *MockClient) MinimizeComment(ctx context.Context, nodeID, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MinimizeComment", ctx, nodeID, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MinimizeComment indicates an expected call of MinimizeComment.
+func (mr *MockClientMockRecorder) MinimizeComment(ctx, nodeID, reason any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MinimizeComment", reflect.TypeOf((*MockClient)(nil).MinimizeComment), ctx, nodeID, reason)
+}
+
+// ReplyToReviewComment mocks base method.
+func (m *MockClient) ReplyToReviewComment(ctx context.Context, owner, repo string, number int, commentID int64, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplyToReviewComment", ctx, owner, repo, number, commentID, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplyToReviewComment indicates an expected call of ReplyToReviewComment.
+func (mr *MockClientMockRecorder) ReplyToReviewComment(ctx, owner, repo, number, commentID, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplyToReviewComment", reflect.TypeOf((*MockClient)(nil).ReplyToReviewComment), ctx, owner, repo, number, commentID, body)
+}
+
 // UpdateCheckRun mocks base method.
 func (m *MockClient) UpdateCheckRun(ctx context.Context, owner, repo string, checkRunID int64, opts github.UpdateCheckRunOptions) (*github.CheckRun, error) {
 	m.ctrl.T.Helper()
@@ -248,3 +381,17 @@ func (mr *MockClientMockRecorder) UpdateCheckRun(ctx, owner, repo, checkRunID, o
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCheckRun", reflect.TypeOf((*MockClient)(nil).UpdateCheckRun), ctx, owner, repo, checkRunID, opts)
 }
+
+// UpdateReviewComment mocks base method.
+func (m *MockClient) UpdateReviewComment(ctx context.Context, owner, repo string, commentID int64, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReviewComment", ctx, owner, repo, commentID, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReviewComment indicates an expected call of UpdateReviewComment.
+func (mr *MockClientMockRecorder) UpdateReviewComment(ctx, owner, repo, commentID, body any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReviewComment", reflect.TypeOf((*MockClient)(nil).UpdateReviewComment), ctx, owner, repo, commentID, body)
+}
@@ -54,6 +54,34 @@ func (mr *MockRepoManagerMockRecorder) ClearLocks() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearLocks", reflect.TypeOf((*MockRepoManager)(nil).ClearLocks))
 }
 
+// DeleteRepo mocks base method.
+func (m *MockRepoManager) DeleteRepo(ctx context.Context, repoFullName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRepo", ctx, repoFullName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRepo indicates an expected call of DeleteRepo.
+func (mr *MockRepoManagerMockRecorder) DeleteRepo(ctx, repoFullName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRepo", reflect.TypeOf((*MockRepoManager)(nil).DeleteRepo), ctx, repoFullName)
+}
+
+// FreezeRepo mocks base method.
+func (m *MockRepoManager) FreezeRepo(ctx context.Context, repoFullName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FreezeRepo", ctx, repoFullName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FreezeRepo indicates an expected call of FreezeRepo.
+func (mr *MockRepoManagerMockRecorder) FreezeRepo(ctx, repoFullName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FreezeRepo", reflect.TypeOf((*MockRepoManager)(nil).FreezeRepo), ctx, repoFullName)
+}
+
 // GetRepoRecord mocks base method.
 func (m *MockRepoManager) GetRepoRecord(ctx context.Context, repoFullName string) (*storage.Repository, error) {
 	m.ctrl.T.Helper()
@@ -69,6 +97,22 @@ func (mr *MockRepoManagerMockRecorder) GetRepoRecord(ctx, repoFullName any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepoRecord", reflect.TypeOf((*MockRepoManager)(nil).GetRepoRecord), ctx, repoFullName)
 }
 
+// PinSnapshot mocks base method.
+func (m *MockRepoManager) PinSnapshot(ctx context.Context, result *core.UpdateResult) (string, func(), error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PinSnapshot", ctx, result)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(func())
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// PinSnapshot indicates an expected call of PinSnapshot.
+func (mr *MockRepoManagerMockRecorder) PinSnapshot(ctx, result any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PinSnapshot", reflect.TypeOf((*MockRepoManager)(nil).PinSnapshot), ctx, result)
+}
+
 // ScanLocalRepo mocks base method.
 func (m *MockRepoManager) ScanLocalRepo(ctx context.Context, repoPath, repoFullName string, force bool) (*core.UpdateResult, error) {
 	m.ctrl.T.Helper()
@@ -99,6 +143,20 @@ func (mr *MockRepoManagerMockRecorder) SyncRepo(ctx, event, token any) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SyncRepo", reflect.TypeOf((*MockRepoManager)(nil).SyncRepo), ctx, event, token)
 }
 
+// ThawRepo mocks base method.
+func (m *MockRepoManager) ThawRepo(ctx context.Context, repoFullName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ThawRepo", ctx, repoFullName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ThawRepo indicates an expected call of ThawRepo.
+func (mr *MockRepoManagerMockRecorder) ThawRepo(ctx, repoFullName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ThawRepo", reflect.TypeOf((*MockRepoManager)(nil).ThawRepo), ctx, repoFullName)
+}
+
 // UpdateRepoSHA mocks base method.
 func (m *MockRepoManager) UpdateRepoSHA(ctx context.Context, repoFullName, newSHA string) error {
 	m.ctrl.T.Helper()
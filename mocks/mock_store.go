@@ -43,6 +43,64 @@ func (m *MockStore) EXPECT() *MockStoreMockRecorder {
 	return m.recorder
 }
 
+// AddChatMessage mocks base method.
+func (m *MockStore) AddChatMessage(arg0 context.Context, arg1 *storage.ChatMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddChatMessage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddChatMessage indicates an expected call of AddChatMessage.
+func (mr *MockStoreMockRecorder) AddChatMessage(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddChatMessage", reflect.TypeOf((*MockStore)(nil).AddChatMessage), arg0, arg1)
+}
+
+// ClaimWebhookDelivery mocks base method.
+func (m *MockStore) ClaimWebhookDelivery(arg0 context.Context, arg1 string, arg2 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDelivery", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDelivery indicates an expected call of ClaimWebhookDelivery.
+func (mr *MockStoreMockRecorder) ClaimWebhookDelivery(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDelivery", reflect.TypeOf((*MockStore)(nil).ClaimWebhookDelivery), arg0, arg1, arg2)
+}
+
+// CompleteCheckRun mocks base method.
+func (m *MockStore) CompleteCheckRun(ctx context.Context, id int64, conclusion string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteCheckRun", ctx, id, conclusion)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteCheckRun indicates an expected call of CompleteCheckRun.
+func (mr *MockStoreMockRecorder) CompleteCheckRun(ctx, id, conclusion any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteCheckRun", reflect.TypeOf((*MockStore)(nil).CompleteCheckRun), ctx, id, conclusion)
+}
+
+// CountCloneRecoveries mocks base method.
+func (m *MockStore) CountCloneRecoveries(ctx context.Context, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCloneRecoveries", ctx, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCloneRecoveries indicates an expected call of CountCloneRecoveries.
+func (mr *MockStoreMockRecorder) CountCloneRecoveries(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCloneRecoveries", reflect.TypeOf((*MockStore)(nil).CountCloneRecoveries), ctx, since)
+}
+
 // CreateAgentSession mocks base method.
 func (m *MockStore) CreateAgentSession(ctx context.Context, s *storage.AgentSession) error {
 	m.ctrl.T.Helper()
@@ -57,6 +115,20 @@ func (mr *MockStoreMockRecorder) CreateAgentSession(ctx, s any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAgentSession", reflect.TypeOf((*MockStore)(nil).CreateAgentSession), ctx, s)
 }
 
+// CreateChatSession mocks base method.
+func (m *MockStore) CreateChatSession(arg0 context.Context, arg1 *storage.ChatSession) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateChatSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateChatSession indicates an expected call of CreateChatSession.
+func (mr *MockStoreMockRecorder) CreateChatSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateChatSession", reflect.TypeOf((*MockStore)(nil).CreateChatSession), arg0, arg1)
+}
+
 // CreateRepository mocks base method.
 func (m *MockStore) CreateRepository(ctx context.Context, repo *storage.Repository) error {
 	m.ctrl.T.Helper()
@@ -71,6 +143,35 @@ func (mr *MockStoreMockRecorder) CreateRepository(ctx, repo any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRepository", reflect.TypeOf((*MockStore)(nil).CreateRepository), ctx, repo)
 }
 
+// DeleteEmailSubscription mocks base method.
+func (m *MockStore) DeleteEmailSubscription(ctx context.Context, email string, repoFullName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEmailSubscription", ctx, email, repoFullName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEmailSubscription indicates an expected call of DeleteEmailSubscription.
+func (mr *MockStoreMockRecorder) DeleteEmailSubscription(ctx, email, repoFullName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmailSubscription", reflect.TypeOf((*MockStore)(nil).DeleteEmailSubscription), ctx, email, repoFullName)
+}
+
+// DeleteExpiredCredentials mocks base method.
+func (m *MockStore) DeleteExpiredCredentials(ctx context.Context, now time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredCredentials", ctx, now)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredCredentials indicates an expected call of DeleteExpiredCredentials.
+func (mr *MockStoreMockRecorder) DeleteExpiredCredentials(ctx, now any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredCredentials", reflect.TypeOf((*MockStore)(nil).DeleteExpiredCredentials), ctx, now)
+}
+
 // DeleteFiles mocks base method.
 func (m *MockStore) DeleteFiles(ctx context.Context, repoID int64, paths []string) error {
 	m.ctrl.T.Helper()
@@ -85,6 +186,79 @@ func (mr *MockStoreMockRecorder) DeleteFiles(ctx, repoID, paths any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFiles", reflect.TypeOf((*MockStore)(nil).DeleteFiles), ctx, repoID, paths)
 }
 
+// DeleteRepository mocks base method.
+func (m *MockStore) DeleteRepository(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRepository", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRepository indicates an expected call of DeleteRepository.
+func (mr *MockStoreMockRecorder) DeleteRepository(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRepository", reflect.TypeOf((*MockStore)(nil).DeleteRepository), ctx, id)
+}
+
+// DeleteReviewInputsBefore mocks base method.
+func (m *MockStore) DeleteReviewInputsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReviewInputsBefore", ctx, cutoff)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteReviewInputsBefore indicates an expected call of DeleteReviewInputsBefore.
+func (mr *MockStoreMockRecorder) DeleteReviewInputsBefore(ctx, cutoff any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReviewInputsBefore", reflect.TypeOf((*MockStore)(nil).DeleteReviewInputsBefore), ctx, cutoff)
+}
+
+// DeleteWebhookDeliveriesBefore mocks base method.
+func (m *MockStore) DeleteWebhookDeliveriesBefore(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhookDeliveriesBefore", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhookDeliveriesBefore indicates an expected call of DeleteWebhookDeliveriesBefore.
+func (mr *MockStoreMockRecorder) DeleteWebhookDeliveriesBefore(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhookDeliveriesBefore", reflect.TypeOf((*MockStore)(nil).DeleteWebhookDeliveriesBefore), arg0, arg1)
+}
+
+// FindSymbolDefinitions mocks base method.
+func (m *MockStore) FindSymbolDefinitions(ctx context.Context, collectionName string, symbols []string) ([]storage.SymbolDefinition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindSymbolDefinitions", ctx, collectionName, symbols)
+	ret0, _ := ret[0].([]storage.SymbolDefinition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindSymbolDefinitions indicates an expected call of FindSymbolDefinitions.
+func (mr *MockStoreMockRecorder) FindSymbolDefinitions(ctx, collectionName, symbols any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindSymbolDefinitions", reflect.TypeOf((*MockStore)(nil).FindSymbolDefinitions), ctx, collectionName, symbols)
+}
+
+// FlipEmbeddingMigration mocks base method.
+func (m *MockStore) FlipEmbeddingMigration(ctx context.Context, migration *storage.EmbeddingMigration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlipEmbeddingMigration", ctx, migration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FlipEmbeddingMigration indicates an expected call of FlipEmbeddingMigration.
+func (mr *MockStoreMockRecorder) FlipEmbeddingMigration(ctx, migration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlipEmbeddingMigration", reflect.TypeOf((*MockStore)(nil).FlipEmbeddingMigration), ctx, migration)
+}
+
 // GetAgentSession mocks base method.
 func (m *MockStore) GetAgentSession(ctx context.Context, id string) (*storage.AgentSession, error) {
 	m.ctrl.T.Helper()
@@ -130,6 +304,81 @@ func (mr *MockStoreMockRecorder) GetAllReviewsForPR(ctx, repoFullName, prNumber
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllReviewsForPR", reflect.TypeOf((*MockStore)(nil).GetAllReviewsForPR), ctx, repoFullName, prNumber)
 }
 
+// GetChatMessages mocks base method.
+func (m *MockStore) GetChatMessages(arg0 context.Context, arg1 int64) ([]*storage.ChatMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatMessages", arg0, arg1)
+	ret0, _ := ret[0].([]*storage.ChatMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatMessages indicates an expected call of GetChatMessages.
+func (mr *MockStoreMockRecorder) GetChatMessages(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatMessages", reflect.TypeOf((*MockStore)(nil).GetChatMessages), arg0, arg1)
+}
+
+// GetChatSession mocks base method.
+func (m *MockStore) GetChatSession(arg0 context.Context, arg1 int64) (*storage.ChatSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatSession", arg0, arg1)
+	ret0, _ := ret[0].(*storage.ChatSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatSession indicates an expected call of GetChatSession.
+func (mr *MockStoreMockRecorder) GetChatSession(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatSession", reflect.TypeOf((*MockStore)(nil).GetChatSession), arg0, arg1)
+}
+
+// GetCredential mocks base method.
+func (m *MockStore) GetCredential(ctx context.Context, name string) (*storage.Credential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCredential", ctx, name)
+	ret0, _ := ret[0].(*storage.Credential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCredential indicates an expected call of GetCredential.
+func (mr *MockStoreMockRecorder) GetCredential(ctx, name any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCredential", reflect.TypeOf((*MockStore)(nil).GetCredential), ctx, name)
+}
+
+// GetEmbeddingMigration mocks base method.
+func (m *MockStore) GetEmbeddingMigration(ctx context.Context, repoID int64) (*storage.EmbeddingMigration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmbeddingMigration", ctx, repoID)
+	ret0, _ := ret[0].(*storage.EmbeddingMigration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmbeddingMigration indicates an expected call of GetEmbeddingMigration.
+func (mr *MockStoreMockRecorder) GetEmbeddingMigration(ctx, repoID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmbeddingMigration", reflect.TypeOf((*MockStore)(nil).GetEmbeddingMigration), ctx, repoID)
+}
+
+// GetFeedbackMetrics mocks base method.
+func (m *MockStore) GetFeedbackMetrics(ctx context.Context, repoFullName string) ([]*storage.FeedbackMetric, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFeedbackMetrics", ctx, repoFullName)
+	ret0, _ := ret[0].([]*storage.FeedbackMetric)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFeedbackMetrics indicates an expected call of GetFeedbackMetrics.
+func (mr *MockStoreMockRecorder) GetFeedbackMetrics(ctx, repoFullName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFeedbackMetrics", reflect.TypeOf((*MockStore)(nil).GetFeedbackMetrics), ctx, repoFullName)
+}
+
 // GetFilesForRepo mocks base method.
 func (m *MockStore) GetFilesForRepo(ctx context.Context, repoID int64) (map[string]storage.FileRecord, error) {
 	m.ctrl.T.Helper()
@@ -145,6 +394,36 @@ func (mr *MockStoreMockRecorder) GetFilesForRepo(ctx, repoID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilesForRepo", reflect.TypeOf((*MockStore)(nil).GetFilesForRepo), ctx, repoID)
 }
 
+// GetIndexStats mocks base method.
+func (m *MockStore) GetIndexStats(ctx context.Context, repoID int64) (*core.IndexStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIndexStats", ctx, repoID)
+	ret0, _ := ret[0].(*core.IndexStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIndexStats indicates an expected call of GetIndexStats.
+func (mr *MockStoreMockRecorder) GetIndexStats(ctx, repoID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIndexStats", reflect.TypeOf((*MockStore)(nil).GetIndexStats), ctx, repoID)
+}
+
+// GetLLMUsage mocks base method.
+func (m *MockStore) GetLLMUsage(ctx context.Context, filter storage.LLMUsageFilter) ([]*storage.LLMUsageTotal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLLMUsage", ctx, filter)
+	ret0, _ := ret[0].([]*storage.LLMUsageTotal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLLMUsage indicates an expected call of GetLLMUsage.
+func (mr *MockStoreMockRecorder) GetLLMUsage(ctx, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLLMUsage", reflect.TypeOf((*MockStore)(nil).GetLLMUsage), ctx, filter)
+}
+
 // GetLatestReviewForPR mocks base method.
 func (m *MockStore) GetLatestReviewForPR(ctx context.Context, repoFullName string, prNumber int) (*core.Review, error) {
 	m.ctrl.T.Helper()
@@ -160,6 +439,21 @@ func (mr *MockStoreMockRecorder) GetLatestReviewForPR(ctx, repoFullName, prNumbe
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLatestReviewForPR", reflect.TypeOf((*MockStore)(nil).GetLatestReviewForPR), ctx, repoFullName, prNumber)
 }
 
+// GetRecentReviews mocks base method.
+func (m *MockStore) GetRecentReviews(ctx context.Context, limit int) ([]*core.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecentReviews", ctx, limit)
+	ret0, _ := ret[0].([]*core.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecentReviews indicates an expected call of GetRecentReviews.
+func (mr *MockStoreMockRecorder) GetRecentReviews(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecentReviews", reflect.TypeOf((*MockStore)(nil).GetRecentReviews), ctx, limit)
+}
+
 // GetRepositoryByClonePath mocks base method.
 func (m *MockStore) GetRepositoryByClonePath(ctx context.Context, clonePath string) (*storage.Repository, error) {
 	m.ctrl.T.Helper()
@@ -205,6 +499,36 @@ func (mr *MockStoreMockRecorder) GetRepositoryByID(ctx, id any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRepositoryByID", reflect.TypeOf((*MockStore)(nil).GetRepositoryByID), ctx, id)
 }
 
+// GetReview mocks base method.
+func (m *MockStore) GetReview(ctx context.Context, id int64) (*core.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReview", ctx, id)
+	ret0, _ := ret[0].(*core.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReview indicates an expected call of GetReview.
+func (mr *MockStoreMockRecorder) GetReview(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReview", reflect.TypeOf((*MockStore)(nil).GetReview), ctx, id)
+}
+
+// GetReviewInputs mocks base method.
+func (m *MockStore) GetReviewInputs(ctx context.Context, reviewID int64) ([]core.PromptInputs, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReviewInputs", ctx, reviewID)
+	ret0, _ := ret[0].([]core.PromptInputs)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReviewInputs indicates an expected call of GetReviewInputs.
+func (mr *MockStoreMockRecorder) GetReviewInputs(ctx, reviewID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReviewInputs", reflect.TypeOf((*MockStore)(nil).GetReviewInputs), ctx, reviewID)
+}
+
 // GetReviewStats mocks base method.
 func (m *MockStore) GetReviewStats(ctx context.Context) (*storage.ReviewStats, error) {
 	m.ctrl.T.Helper()
@@ -235,6 +559,21 @@ func (mr *MockStoreMockRecorder) GetReviewsForRepo(ctx, repoFullName any) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReviewsForRepo", reflect.TypeOf((*MockStore)(nil).GetReviewsForRepo), ctx, repoFullName)
 }
 
+// GetReviewsSince mocks base method.
+func (m *MockStore) GetReviewsSince(ctx context.Context, since time.Time) ([]*core.Review, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReviewsSince", ctx, since)
+	ret0, _ := ret[0].([]*core.Review)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReviewsSince indicates an expected call of GetReviewsSince.
+func (mr *MockStoreMockRecorder) GetReviewsSince(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReviewsSince", reflect.TypeOf((*MockStore)(nil).GetReviewsSince), ctx, since)
+}
+
 // GetScanState mocks base method.
 func (m *MockStore) GetScanState(ctx context.Context, repoID int64) (*storage.ScanState, error) {
 	m.ctrl.T.Helper()
@@ -250,6 +589,20 @@ func (mr *MockStoreMockRecorder) GetScanState(ctx, repoID any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScanState", reflect.TypeOf((*MockStore)(nil).GetScanState), ctx, repoID)
 }
 
+// InsertCheckRun mocks base method.
+func (m *MockStore) InsertCheckRun(ctx context.Context, run *storage.CheckRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertCheckRun", ctx, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertCheckRun indicates an expected call of InsertCheckRun.
+func (mr *MockStoreMockRecorder) InsertCheckRun(ctx, run any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCheckRun", reflect.TypeOf((*MockStore)(nil).InsertCheckRun), ctx, run)
+}
+
 // InsertJobRun mocks base method.
 func (m *MockStore) InsertJobRun(ctx context.Context, job *storage.JobRun) (int64, error) {
 	m.ctrl.T.Helper()
@@ -280,6 +633,96 @@ func (mr *MockStoreMockRecorder) ListAgentSessions(ctx, repoOwner, repoName, lim
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAgentSessions", reflect.TypeOf((*MockStore)(nil).ListAgentSessions), ctx, repoOwner, repoName, limit)
 }
 
+// ListChatSessions mocks base method.
+func (m *MockStore) ListChatSessions(arg0 context.Context, arg1 int64, arg2 int) ([]*storage.ChatSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListChatSessions", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*storage.ChatSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListChatSessions indicates an expected call of ListChatSessions.
+func (mr *MockStoreMockRecorder) ListChatSessions(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListChatSessions", reflect.TypeOf((*MockStore)(nil).ListChatSessions), arg0, arg1, arg2)
+}
+
+// ListCredentials mocks base method.
+func (m *MockStore) ListCredentials(ctx context.Context) ([]*storage.Credential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCredentials", ctx)
+	ret0, _ := ret[0].([]*storage.Credential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCredentials indicates an expected call of ListCredentials.
+func (mr *MockStoreMockRecorder) ListCredentials(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCredentials", reflect.TypeOf((*MockStore)(nil).ListCredentials), ctx)
+}
+
+// ListEmailSubscriptions mocks base method.
+func (m *MockStore) ListEmailSubscriptions(ctx context.Context) ([]*storage.EmailSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEmailSubscriptions", ctx)
+	ret0, _ := ret[0].([]*storage.EmailSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEmailSubscriptions indicates an expected call of ListEmailSubscriptions.
+func (mr *MockStoreMockRecorder) ListEmailSubscriptions(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEmailSubscriptions", reflect.TypeOf((*MockStore)(nil).ListEmailSubscriptions), ctx)
+}
+
+// ListEmbeddingMigrations mocks base method.
+func (m *MockStore) ListEmbeddingMigrations(ctx context.Context) ([]*storage.EmbeddingMigration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListEmbeddingMigrations", ctx)
+	ret0, _ := ret[0].([]*storage.EmbeddingMigration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListEmbeddingMigrations indicates an expected call of ListEmbeddingMigrations.
+func (mr *MockStoreMockRecorder) ListEmbeddingMigrations(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListEmbeddingMigrations", reflect.TypeOf((*MockStore)(nil).ListEmbeddingMigrations), ctx)
+}
+
+// ListFailedJobRunsSince mocks base method.
+func (m *MockStore) ListFailedJobRunsSince(ctx context.Context, since time.Time) ([]*storage.JobRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFailedJobRunsSince", ctx, since)
+	ret0, _ := ret[0].([]*storage.JobRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFailedJobRunsSince indicates an expected call of ListFailedJobRunsSince.
+func (mr *MockStoreMockRecorder) ListFailedJobRunsSince(ctx, since any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFailedJobRunsSince", reflect.TypeOf((*MockStore)(nil).ListFailedJobRunsSince), ctx, since)
+}
+
+// ListInProgressCheckRuns mocks base method.
+func (m *MockStore) ListInProgressCheckRuns(ctx context.Context, startedBefore time.Time) ([]*storage.CheckRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListInProgressCheckRuns", ctx, startedBefore)
+	ret0, _ := ret[0].([]*storage.CheckRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListInProgressCheckRuns indicates an expected call of ListInProgressCheckRuns.
+func (mr *MockStoreMockRecorder) ListInProgressCheckRuns(ctx, startedBefore any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListInProgressCheckRuns", reflect.TypeOf((*MockStore)(nil).ListInProgressCheckRuns), ctx, startedBefore)
+}
+
 // ListJobRuns mocks base method.
 func (m *MockStore) ListJobRuns(ctx context.Context, limit, offset int) ([]*storage.JobRun, error) {
 	m.ctrl.T.Helper()
@@ -295,6 +738,191 @@ func (mr *MockStoreMockRecorder) ListJobRuns(ctx, limit, offset any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListJobRuns", reflect.TypeOf((*MockStore)(nil).ListJobRuns), ctx, limit, offset)
 }
 
+// ListOrphanedCollections mocks base method.
+func (m *MockStore) ListOrphanedCollections(ctx context.Context) ([]*storage.OrphanedCollection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrphanedCollections", ctx)
+	ret0, _ := ret[0].([]*storage.OrphanedCollection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrphanedCollections indicates an expected call of ListOrphanedCollections.
+func (mr *MockStoreMockRecorder) ListOrphanedCollections(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrphanedCollections", reflect.TypeOf((*MockStore)(nil).ListOrphanedCollections), ctx)
+}
+
+// ListRuntimeSettings mocks base method.
+func (m *MockStore) ListRuntimeSettings(ctx context.Context) ([]*storage.RuntimeSetting, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRuntimeSettings", ctx)
+	ret0, _ := ret[0].([]*storage.RuntimeSetting)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRuntimeSettings indicates an expected call of ListRuntimeSettings.
+func (mr *MockStoreMockRecorder) ListRuntimeSettings(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRuntimeSettings", reflect.TypeOf((*MockStore)(nil).ListRuntimeSettings), ctx)
+}
+
+// ListSettingsAudit mocks base method.
+func (m *MockStore) ListSettingsAudit(ctx context.Context, limit int) ([]*storage.SettingAudit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSettingsAudit", ctx, limit)
+	ret0, _ := ret[0].([]*storage.SettingAudit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSettingsAudit indicates an expected call of ListSettingsAudit.
+func (mr *MockStoreMockRecorder) ListSettingsAudit(ctx, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSettingsAudit", reflect.TypeOf((*MockStore)(nil).ListSettingsAudit), ctx, limit)
+}
+
+// MarkDigestSent mocks base method.
+func (m *MockStore) MarkDigestSent(ctx context.Context, ids []int64, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDigestSent", ctx, ids, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDigestSent indicates an expected call of MarkDigestSent.
+func (mr *MockStoreMockRecorder) MarkDigestSent(ctx, ids, at any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDigestSent", reflect.TypeOf((*MockStore)(nil).MarkDigestSent), ctx, ids, at)
+}
+
+// MarkOrphanedCollections mocks base method.
+func (m *MockStore) MarkOrphanedCollections(ctx context.Context, names []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOrphanedCollections", ctx, names)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOrphanedCollections indicates an expected call of MarkOrphanedCollections.
+func (mr *MockStoreMockRecorder) MarkOrphanedCollections(ctx, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOrphanedCollections", reflect.TypeOf((*MockStore)(nil).MarkOrphanedCollections), ctx, names)
+}
+
+// RecordCloneRecovery mocks base method.
+func (m *MockStore) RecordCloneRecovery(ctx context.Context, rec *storage.CloneRecovery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordCloneRecovery", ctx, rec)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordCloneRecovery indicates an expected call of RecordCloneRecovery.
+func (mr *MockStoreMockRecorder) RecordCloneRecovery(ctx, rec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordCloneRecovery", reflect.TypeOf((*MockStore)(nil).RecordCloneRecovery), ctx, rec)
+}
+
+// ReleaseWebhookDelivery mocks base method.
+func (m *MockStore) ReleaseWebhookDelivery(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseWebhookDelivery", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseWebhookDelivery indicates an expected call of ReleaseWebhookDelivery.
+func (mr *MockStoreMockRecorder) ReleaseWebhookDelivery(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseWebhookDelivery", reflect.TypeOf((*MockStore)(nil).ReleaseWebhookDelivery), arg0, arg1)
+}
+
+// ReplaceReactionFeedback mocks base method.
+func (m *MockStore) ReplaceReactionFeedback(ctx context.Context, commentID int64, rows []*storage.ReviewFeedback) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceReactionFeedback", ctx, commentID, rows)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceReactionFeedback indicates an expected call of ReplaceReactionFeedback.
+func (mr *MockStoreMockRecorder) ReplaceReactionFeedback(ctx, commentID, rows any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceReactionFeedback", reflect.TypeOf((*MockStore)(nil).ReplaceReactionFeedback), ctx, commentID, rows)
+}
+
+// ReplaceSymbols mocks base method.
+func (m *MockStore) ReplaceSymbols(ctx context.Context, repoID int64, files []string, defs []storage.SymbolDefinition, refs []storage.SymbolReference) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceSymbols", ctx, repoID, files, defs, refs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceSymbols indicates an expected call of ReplaceSymbols.
+func (mr *MockStoreMockRecorder) ReplaceSymbols(ctx, repoID, files, defs, refs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceSymbols", reflect.TypeOf((*MockStore)(nil).ReplaceSymbols), ctx, repoID, files, defs, refs)
+}
+
+// SaveCredential mocks base method.
+func (m *MockStore) SaveCredential(ctx context.Context, credential *storage.Credential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveCredential", ctx, credential)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveCredential indicates an expected call of SaveCredential.
+func (mr *MockStoreMockRecorder) SaveCredential(ctx, credential any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveCredential", reflect.TypeOf((*MockStore)(nil).SaveCredential), ctx, credential)
+}
+
+// SaveEmbeddingMigration mocks base method.
+func (m *MockStore) SaveEmbeddingMigration(ctx context.Context, migration *storage.EmbeddingMigration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEmbeddingMigration", ctx, migration)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEmbeddingMigration indicates an expected call of SaveEmbeddingMigration.
+func (mr *MockStoreMockRecorder) SaveEmbeddingMigration(ctx, migration any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEmbeddingMigration", reflect.TypeOf((*MockStore)(nil).SaveEmbeddingMigration), ctx, migration)
+}
+
+// SaveIndexStats mocks base method.
+func (m *MockStore) SaveIndexStats(ctx context.Context, repoID int64, stats *core.IndexStats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIndexStats", ctx, repoID, stats)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIndexStats indicates an expected call of SaveIndexStats.
+func (mr *MockStoreMockRecorder) SaveIndexStats(ctx, repoID, stats any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIndexStats", reflect.TypeOf((*MockStore)(nil).SaveIndexStats), ctx, repoID, stats)
+}
+
+// SaveLLMUsage mocks base method.
+func (m *MockStore) SaveLLMUsage(ctx context.Context, usage *core.LLMUsage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLLMUsage", ctx, usage)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveLLMUsage indicates an expected call of SaveLLMUsage.
+func (mr *MockStoreMockRecorder) SaveLLMUsage(ctx, usage any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLLMUsage", reflect.TypeOf((*MockStore)(nil).SaveLLMUsage), ctx, usage)
+}
+
 // SaveReview mocks base method.
 func (m *MockStore) SaveReview(ctx context.Context, review *core.Review) error {
 	m.ctrl.T.Helper()
@@ -309,6 +937,48 @@ func (mr *MockStoreMockRecorder) SaveReview(ctx, review any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReview", reflect.TypeOf((*MockStore)(nil).SaveReview), ctx, review)
 }
 
+// SaveReviewInputs mocks base method.
+func (m *MockStore) SaveReviewInputs(ctx context.Context, reviewID int64, inputs []core.PromptInputs) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReviewInputs", ctx, reviewID, inputs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveReviewInputs indicates an expected call of SaveReviewInputs.
+func (mr *MockStoreMockRecorder) SaveReviewInputs(ctx, reviewID, inputs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReviewInputs", reflect.TypeOf((*MockStore)(nil).SaveReviewInputs), ctx, reviewID, inputs)
+}
+
+// SetRuntimeSetting mocks base method.
+func (m *MockStore) SetRuntimeSetting(ctx context.Context, key string, value *string, actor string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRuntimeSetting", ctx, key, value, actor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRuntimeSetting indicates an expected call of SetRuntimeSetting.
+func (mr *MockStoreMockRecorder) SetRuntimeSetting(ctx, key, value, actor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRuntimeSetting", reflect.TypeOf((*MockStore)(nil).SetRuntimeSetting), ctx, key, value, actor)
+}
+
+// UnmarkOrphanedCollections mocks base method.
+func (m *MockStore) UnmarkOrphanedCollections(ctx context.Context, names []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnmarkOrphanedCollections", ctx, names)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UnmarkOrphanedCollections indicates an expected call of UnmarkOrphanedCollections.
+func (mr *MockStoreMockRecorder) UnmarkOrphanedCollections(ctx, names any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnmarkOrphanedCollections", reflect.TypeOf((*MockStore)(nil).UnmarkOrphanedCollections), ctx, names)
+}
+
 // UpdateAgentSession mocks base method.
 func (m *MockStore) UpdateAgentSession(ctx context.Context, s *storage.AgentSession) error {
 	m.ctrl.T.Helper()
@@ -323,6 +993,20 @@ func (mr *MockStoreMockRecorder) UpdateAgentSession(ctx, s any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAgentSession", reflect.TypeOf((*MockStore)(nil).UpdateAgentSession), ctx, s)
 }
 
+// UpdateChatSummary mocks base method.
+func (m *MockStore) UpdateChatSummary(arg0 context.Context, arg1 int64, arg2 string, arg3 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateChatSummary", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateChatSummary indicates an expected call of UpdateChatSummary.
+func (mr *MockStoreMockRecorder) UpdateChatSummary(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateChatSummary", reflect.TypeOf((*MockStore)(nil).UpdateChatSummary), arg0, arg1, arg2, arg3)
+}
+
 // UpdateJobRun mocks base method.
 func (m *MockStore) UpdateJobRun(ctx context.Context, id int64, status string, completedAt time.Time, durationMs int64) error {
 	m.ctrl.T.Helper()
@@ -351,6 +1035,20 @@ func (mr *MockStoreMockRecorder) UpdateRepository(ctx, repo any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRepository", reflect.TypeOf((*MockStore)(nil).UpdateRepository), ctx, repo)
 }
 
+// UpsertEmailSubscription mocks base method.
+func (m *MockStore) UpsertEmailSubscription(ctx context.Context, sub *storage.EmailSubscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertEmailSubscription", ctx, sub)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertEmailSubscription indicates an expected call of UpsertEmailSubscription.
+func (mr *MockStoreMockRecorder) UpsertEmailSubscription(ctx, sub any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertEmailSubscription", reflect.TypeOf((*MockStore)(nil).UpsertEmailSubscription), ctx, sub)
+}
+
 // UpsertFiles mocks base method.
 func (m *MockStore) UpsertFiles(ctx context.Context, repoID int64, files []storage.FileRecord) error {
 	m.ctrl.T.Helper()
@@ -365,6 +1063,20 @@ func (mr *MockStoreMockRecorder) UpsertFiles(ctx, repoID, files any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertFiles", reflect.TypeOf((*MockStore)(nil).UpsertFiles), ctx, repoID, files)
 }
 
+// UpsertReviewFeedback mocks base method.
+func (m *MockStore) UpsertReviewFeedback(ctx context.Context, fb *storage.ReviewFeedback) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertReviewFeedback", ctx, fb)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertReviewFeedback indicates an expected call of UpsertReviewFeedback.
+func (mr *MockStoreMockRecorder) UpsertReviewFeedback(ctx, fb any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertReviewFeedback", reflect.TypeOf((*MockStore)(nil).UpsertReviewFeedback), ctx, fb)
+}
+
 // UpsertScanState mocks base method.
 func (m *MockStore) UpsertScanState(ctx context.Context, state *storage.ScanState) error {
 	m.ctrl.T.Helper()
@@ -93,6 +93,21 @@ func (mr *MockVectorStoreMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockVectorStore)(nil).Close))
 }
 
+// CollectionStats mocks base method.
+func (m *MockVectorStore) CollectionStats(ctx context.Context, collectionName string) (*storage.CollectionStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CollectionStats", ctx, collectionName)
+	ret0, _ := ret[0].(*storage.CollectionStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CollectionStats indicates an expected call of CollectionStats.
+func (mr *MockVectorStoreMockRecorder) CollectionStats(ctx, collectionName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollectionStats", reflect.TypeOf((*MockVectorStore)(nil).CollectionStats), ctx, collectionName)
+}
+
 // DeleteCollection mocks base method.
 func (m *MockVectorStore) DeleteCollection(ctx context.Context, collectionName string) error {
 	m.ctrl.T.Helper()
@@ -183,6 +198,20 @@ func (mr *MockVectorStoreMockRecorder) ListCollections(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCollections", reflect.TypeOf((*MockVectorStore)(nil).ListCollections), ctx)
 }
 
+// Ping mocks base method.
+func (m *MockVectorStore) Ping(ctx context.Context, collectionName, embedderModelName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ping", ctx, collectionName, embedderModelName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ping indicates an expected call of Ping.
+func (mr *MockVectorStoreMockRecorder) Ping(ctx, collectionName, embedderModelName any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockVectorStore)(nil).Ping), ctx, collectionName, embedderModelName)
+}
+
 // SearchCollection mocks base method.
 func (m *MockVectorStore) SearchCollection(ctx context.Context, collectionName, embedderModelName, query string, numDocs int) ([]schema.Document, error) {
 	m.ctrl.T.Helper()
@@ -291,3 +320,18 @@ func (mr *MockVectorStoreMockRecorder) SimilaritySearchWithScores(ctx, query, nu
 	varargs := append([]any{ctx, query, numDocuments}, options...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimilaritySearchWithScores", reflect.TypeOf((*MockVectorStore)(nil).SimilaritySearchWithScores), varargs...)
 }
+
+// StoredCollections mocks base method.
+func (m *MockVectorStore) StoredCollections(ctx context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoredCollections", ctx)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StoredCollections indicates an expected call of StoredCollections.
+func (mr *MockVectorStoreMockRecorder) StoredCollections(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoredCollections", reflect.TypeOf((*MockVectorStore)(nil).StoredCollections), ctx)
+}
@@ -0,0 +1,97 @@
+import { useEffect, useId, useState } from 'react'
+import { useQuery } from '@tanstack/react-query'
+import { Download, Loader2, Network } from 'lucide-react'
+import { api } from '@/lib/api'
+import type { ArchGraph } from '@/lib/api'
+import { useTheme } from '@/lib/useTheme'
+
+// Mermaid is large and only needed here, so it is loaded on first use instead
+// of being bundled.
+const MERMAID_URL = 'https://cdn.jsdelivr.net/npm/mermaid@11/dist/mermaid.esm.min.mjs'
+
+interface Mermaid {
+  initialize: (config: Record<string, unknown>) => void
+  render: (id: string, source: string) => Promise<{ svg: string }>
+}
+
+let mermaidPromise: Promise<Mermaid> | null = null
+
+function loadMermaid(): Promise<Mermaid> {
+  if (!mermaidPromise) {
+    mermaidPromise = import(/* @vite-ignore */ MERMAID_URL).then((m) => m.default as Mermaid)
+  }
+  return mermaidPromise
+}
+
+export default function ArchitectureGraph({ repoId }: { repoId: number }) {
+  const { theme } = useTheme()
+  const renderId = 'arch-' + useId().replace(/:/g, '')
+  const [svg, setSvg] = useState<string>()
+  const [renderError, setRenderError] = useState<string>()
+
+  const { data: graph, isLoading, error } = useQuery<ArchGraph>({
+    queryKey: ['graph', repoId],
+    queryFn: () => api.repos.graph(repoId),
+    staleTime: 5 * 60 * 1000,
+  })
+
+  useEffect(() => {
+    if (!graph?.nodes?.length) return
+    let cancelled = false
+    loadMermaid()
+      .then(async (mermaid) => {
+        mermaid.initialize({ startOnLoad: false, securityLevel: 'strict', theme: theme === 'dark' ? 'dark' : 'neutral' })
+        const { svg } = await mermaid.render(renderId, graph.mermaid)
+        if (!cancelled) setSvg(svg)
+      })
+      .catch((err: unknown) => {
+        if (!cancelled) setRenderError(err instanceof Error ? err.message : 'Failed to render diagram')
+      })
+    return () => {
+      cancelled = true
+    }
+  }, [graph, theme, renderId])
+
+  return (
+    <div className="space-y-3">
+      <div className="flex items-center justify-between">
+        <h2 className="text-sm font-semibold text-[#656a76] uppercase tracking-wider">Architecture</h2>
+        {graph?.nodes?.length ? (
+          <div className="flex items-center gap-3">
+            {(['markdown', 'dot', 'mermaid'] as const).map((format) => (
+              <a
+                key={format}
+                href={api.repos.graphExportUrl(repoId, format)}
+                download={`architecture.${format === 'markdown' ? 'md' : format === 'dot' ? 'dot' : 'mmd'}`}
+                className="flex items-center gap-1 text-sm text-[#2264d6] hover:underline dark:text-[#2b89ff]"
+              >
+                <Download className="h-3.5 w-3.5" />
+                {format === 'markdown' ? 'Report' : format.toUpperCase()}
+              </a>
+            ))}
+          </div>
+        ) : null}
+      </div>
+
+      <div className="bg-white dark:bg-[#15181e] rounded-[8px] border border-[#e1e3e6] dark:border-[#2d2f36] overflow-auto p-4">
+        {isLoading || (graph?.nodes?.length && !svg && !renderError) ? (
+          <div className="flex items-center justify-center gap-2 py-8 text-sm text-[#8c919b]">
+            <Loader2 className="h-4 w-4 animate-spin" />
+            Building architecture graph...
+          </div>
+        ) : error || renderError ? (
+          <div className="py-8 text-center text-sm text-rose-600 dark:text-rose-400">
+            {renderError ?? 'Failed to load the architecture graph.'}
+          </div>
+        ) : !graph?.nodes?.length ? (
+          <div className="flex flex-col items-center gap-2 py-8 text-sm text-[#8c919b]">
+            <Network className="h-5 w-5" />
+            No directories with code found.
+          </div>
+        ) : (
+          <div className="flex justify-center [&_svg]:max-w-full" dangerouslySetInnerHTML={{ __html: svg ?? '' }} />
+        )}
+      </div>
+    </div>
+  )
+}
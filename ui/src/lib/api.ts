@@ -27,20 +27,69 @@ export interface ScanState {
   updated_at: string
 }
 
+export interface LanguageStats {
+  language: string
+  files: number
+  indexed_files: number
+  chunks: number
+  avg_chunks_per_file: number
+}
+
+export interface IndexStats {
+  computed_at: string
+  duration_ms: number
+  files: number
+  indexed_files: number
+  chunks: number
+  avg_chunks_per_file: number
+  skipped: Record<string, number>
+  languages: LanguageStats[]
+}
+
 export interface RepoStats {
   chunks_count: number
   files_count: number
   last_indexed_sha: string
   last_scan_date: string
+  index?: IndexStats
+}
+
+export interface GraphNode {
+  path: string
+  summary?: string
+}
+
+export interface GraphEdge {
+  from: string
+  to: string
+  kind: 'contains' | 'imports'
+}
+
+export interface ArchGraph {
+  nodes: GraphNode[] | null
+  edges: GraphEdge[] | null
+  mermaid: string
 }
 
 export interface ChatRequest {
   question: string
   history: string[]
+  // Past commit, branch or tag to answer for.
+  at?: string
+}
+
+// A chunk of the repository an answer was based on. Architecture summaries
+// have no lines.
+export interface Citation {
+  source: string
+  line_start?: number
+  line_end?: number
+  score?: number
 }
 
 export interface ChatResponse {
   answer: string
+  citations?: Citation[]
 }
 
 export interface ExplainRequest {
@@ -89,6 +138,8 @@ export interface ReviewSummary {
   pr_number: number
   pr_title: string
   head_sha: string
+  // Tree of the repository snapshot the review read; absent for older reviews.
+  tree_sha?: string
   status: string
   severity_counts: {
     critical: number
@@ -144,6 +195,19 @@ export interface GlobalStats {
   avg_findings_per_review: number
   jobs_running: number
   jobs_queued: number
+  clone_recoveries_7d: number
+  degraded_reviews_7d: number
+  admission: AdmissionStatus
+}
+
+export interface AdmissionStatus {
+  paused: boolean
+  since?: string
+  reasons?: string[]
+  llm_calls: number
+  llm_error_rate: number
+  qdrant_latency_ms: number
+  qdrant_error?: string
 }
 
 export interface JobRun {
@@ -197,6 +261,10 @@ export const api = {
       fetchApi<ScanState | null>(`/repos/${id}/status`),
     stats: (id: number) =>
       fetchApi<RepoStats>(`/repos/${id}/stats`),
+    graph: (id: number) =>
+      fetchApi<ArchGraph>(`/repos/${id}/graph`),
+    graphExportUrl: (id: number, format: 'dot' | 'mermaid' | 'markdown') =>
+      `${API_BASE}/repos/${id}/graph?format=${format}`,
   },
 
   chat: {
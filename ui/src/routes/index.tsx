@@ -301,6 +301,7 @@ export default function Dashboard() {
   const { data: globalStats } = useQuery<GlobalStats>({
     queryKey: ['global-stats'],
     queryFn: api.stats.global,
+    refetchInterval: 30_000,
   })
 
   const { data: jobs, isLoading: jobsLoading } = useQuery<JobRun[]>({
@@ -371,6 +372,22 @@ export default function Dashboard() {
         </div>
       </motion.div>
 
+      {/* Admission paused */}
+      {globalStats?.admission?.paused && (
+        <motion.div
+          variants={fadeUp}
+          className="flex items-start gap-3 rounded-lg border border-amber-500/30 bg-amber-500/10 px-4 py-3"
+        >
+          <AlertTriangle className="h-4 w-4 mt-0.5 text-amber-500 shrink-0" />
+          <div className="text-sm">
+            <p className="font-medium text-foreground">Review jobs are on hold</p>
+            <p className="text-[#656a76] mt-0.5">
+              {(globalStats.admission.reasons ?? []).join('; ')}. Queued reviews run once the services recover.
+            </p>
+          </div>
+        </motion.div>
+      )}
+
       {/* KPI Cards */}
       <motion.div variants={stagger} className="grid grid-cols-2 lg:grid-cols-4 gap-3">
         <KPICard
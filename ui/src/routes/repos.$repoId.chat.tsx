@@ -8,12 +8,13 @@ import { Prism as SyntaxHighlighter } from 'react-syntax-highlighter'
 import { oneDark } from 'react-syntax-highlighter/dist/esm/styles/prism'
 import { ScrollArea } from '@/components/ui/scroll-area'
 import { api } from '@/lib/api'
-import type { Repository } from '@/lib/api'
+import type { Citation, Repository } from '@/lib/api'
 
 interface Message {
   id: string
   role: 'user' | 'assistant'
   content: string
+  citations?: Citation[]
   isError?: boolean
 }
 
@@ -119,6 +120,25 @@ const msgVariants = {
   exit: { opacity: 0 },
 }
 
+function Sources({ citations }: { citations: Citation[] }) {
+  return (
+    <div className="mt-3 pt-2 border-t border-border/30">
+      <p className="text-[11px] uppercase tracking-wide text-muted-foreground/60 mb-1">Sources</p>
+      <ul className="space-y-0.5">
+        {citations.map((c) => {
+          const lines = c.line_start ? `:${c.line_start}${c.line_end && c.line_end > c.line_start ? `-${c.line_end}` : ''}` : ''
+          return (
+            <li key={`${c.source}${lines}`} className="text-xs font-mono text-muted-foreground">
+              {c.source}{lines}
+              {c.score ? <span className="text-muted-foreground/50"> · {c.score.toFixed(2)}</span> : null}
+            </li>
+          )
+        })}
+      </ul>
+    </div>
+  )
+}
+
 function TypingDots() {
   return (
     <div className="flex gap-3 items-start">
@@ -156,7 +176,7 @@ export default function ChatPage() {
       return api.chat.ask(id, { question, history })
     },
     onSuccess: (res) => {
-      setMessages((prev) => [...prev, { id: Date.now().toString(), role: 'assistant', content: res.answer }])
+      setMessages((prev) => [...prev, { id: Date.now().toString(), role: 'assistant', content: res.answer, citations: res.citations }])
     },
     onError: (err) => {
       setMessages((prev) => [...prev, {
@@ -329,6 +349,7 @@ export default function ChatPage() {
                         ) : (
                           <div className="text-[15px] text-foreground/90 leading-[1.7]">
                             <ReactMarkdown components={markdownComponents}>{msg.content}</ReactMarkdown>
+                            {msg.citations && msg.citations.length > 0 && <Sources citations={msg.citations} />}
                           </div>
                         )}
                       </div>
@@ -371,7 +392,8 @@ export default function ChatPage() {
           <p className="text-[11px] text-muted-foreground/40 mt-2 text-center">
             <kbd className="font-mono bg-accent/30 px-1.5 py-0.5 rounded-md text-xs">Enter</kbd> to send ·{' '}
             <kbd className="font-mono bg-accent/30 px-1.5 py-0.5 rounded-md text-xs">Shift+Enter</kbd> new line ·{' '}
-            <code className="font-mono text-muted-foreground/50">/explain &lt;path&gt;</code>
+            <code className="font-mono text-muted-foreground/50">/explain &lt;path&gt;</code> ·{' '}
+            <code className="font-mono text-muted-foreground/50">/ask --at &lt;ref&gt; &lt;question&gt;</code>
           </p>
         </form>
       </div>
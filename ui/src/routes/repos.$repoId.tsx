@@ -19,8 +19,9 @@ import {
 import { Button } from '@/components/ui/button'
 import { Card } from '@/components/ui/card'
 import StatusBadge from '@/components/StatusBadge'
+import ArchitectureGraph from '@/components/ArchitectureGraph'
 import { api } from '@/lib/api'
-import type { Repository, ScanState, RepoStats, ReviewSummary } from '@/lib/api'
+import type { Repository, ScanState, RepoStats, IndexStats, ReviewSummary } from '@/lib/api'
 import { useScanProgress } from '@/lib/useScanProgress'
 import { groupReviews } from '@/lib/review-utils'
 import type { GroupedReview } from '@/lib/review-utils'
@@ -84,6 +85,60 @@ function PipelineStages({ status }: { status: string | undefined }) {
   )
 }
 
+const SKIP_REASONS: Record<string, string> = {
+  unsupported_extension: 'Unsupported extension',
+  excluded_extension: 'Excluded extension',
+  generated: 'Generated',
+  binary: 'Binary',
+  too_large: 'Too large',
+  not_loaded: 'Not loaded',
+}
+
+function IndexCoverage({ stats }: { stats: IndexStats }) {
+  const skipped = Object.entries(stats.skipped ?? {}).sort((a, b) => b[1] - a[1])
+  return (
+    <Card className="p-4 space-y-4">
+      <div className="flex items-center justify-between">
+        <h2 className="text-sm font-semibold text-[#656a76] uppercase tracking-wider">Index Coverage</h2>
+        <span className="text-xs text-[#8c919b]">
+          {stats.indexed_files.toLocaleString()} of {stats.files.toLocaleString()} files · {stats.avg_chunks_per_file.toFixed(1)} chunks per file
+        </span>
+      </div>
+      <table className="w-full text-sm">
+        <thead>
+          <tr className="text-left text-xs text-[#8c919b]">
+            <th className="font-medium pb-2">Language</th>
+            <th className="font-medium pb-2 text-right">Files</th>
+            <th className="font-medium pb-2 text-right">Indexed</th>
+            <th className="font-medium pb-2 text-right">Chunks</th>
+            <th className="font-medium pb-2 text-right">Avg chunks</th>
+          </tr>
+        </thead>
+        <tbody className="font-mono">
+          {stats.languages.slice(0, 10).map(lang => (
+            <tr key={lang.language} className="border-t border-[#e1e3e6] dark:border-[#2d2f36]">
+              <td className="py-1.5 font-sans">{lang.language}</td>
+              <td className="py-1.5 text-right">{lang.files.toLocaleString()}</td>
+              <td className="py-1.5 text-right">{lang.indexed_files.toLocaleString()}</td>
+              <td className="py-1.5 text-right">{lang.chunks.toLocaleString()}</td>
+              <td className="py-1.5 text-right">{lang.avg_chunks_per_file.toFixed(1)}</td>
+            </tr>
+          ))}
+        </tbody>
+      </table>
+      {skipped.length > 0 && (
+        <div className="flex flex-wrap gap-2">
+          {skipped.map(([reason, count]) => (
+            <span key={reason} className="text-xs rounded-[4px] bg-[#f1f2f3] px-2 py-1 text-[#656a76] dark:bg-[#1e2025]">
+              {SKIP_REASONS[reason] ?? reason}: {count.toLocaleString()}
+            </span>
+          ))}
+        </div>
+      )}
+    </Card>
+  )
+}
+
 function StatCard({ 
   icon: Icon, 
   label, 
@@ -426,6 +481,18 @@ export default function RepoDetailPage() {
         </motion.div>
       )}
 
+      {isCompleted && stats?.index && (
+        <motion.div variants={fadeUp}>
+          <IndexCoverage stats={stats.index} />
+        </motion.div>
+      )}
+
+      {isCompleted && (
+        <motion.div variants={fadeUp}>
+          <ArchitectureGraph repoId={id} />
+        </motion.div>
+      )}
+
       {isCompleted && (
         <motion.div variants={fadeUp} className="space-y-3">
           <div className="flex items-center justify-between">